@@ -1,6 +1,7 @@
 package client_labels_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -8,6 +9,8 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"testing"
 	"time"
 
@@ -44,16 +47,32 @@ type UpdateAttributesTestSuite struct {
 
 func (suite *UpdateAttributesTestSuite) SetupTest() {
 	helpers.CleanUp(suite.T(), "./rc-test-resurces", "./rd-test-resources")
-	err := os.WriteFile("./client_attributes.json", []byte("{\"tags\":[\"vm\"],\"labels\":{}}"), 0600)
-	suite.NoError(err)
+	confFile := suite.writeTempConfig()
 	suite.ctx = context.Background()
 	ctx, cancel := context.WithTimeout(suite.ctx, time.Minute*5)
 	defer cancel()
-	suite.serverProcess, suite.clientProcess = helpers.StartClientAndServerAndWaitForConnection(ctx, suite.T(), helpers.FindProjectRoot(suite.T()))
+	suite.serverProcess, suite.clientProcess = helpers.StartClientAndServerAndWaitForConnection(ctx, suite.T(), helpers.FindProjectRoot(suite.T()), "-c", confFile)
 
 	suite.clientID = helpers.CallURL[RspID](&suite.Suite, apiHost+"/api/v1/clients?fields[clients]=id").Data[0].ID
 }
 
+// writeTempConfig writes the client config and the attributes file to a temp dir, the client updates the attributes
+// file, so the one in the repo is kept unchanged.
+func (suite *UpdateAttributesTestSuite) writeTempConfig() string {
+	dir := suite.T().TempDir()
+	attributesFile := filepath.Join(dir, "client_attributes.json")
+	err := os.WriteFile(attributesFile, []byte("{\"tags\":[\"vm\"],\"labels\":{}}"), 0600)
+	suite.Require().NoError(err)
+
+	conf, err := os.ReadFile("./rport.conf")
+	suite.Require().NoError(err)
+	conf = bytes.Replace(conf, []byte(`"./client_attributes.json"`), []byte(strconv.Quote(attributesFile)), 1)
+	confFile := filepath.Join(dir, "rport.conf")
+	err = os.WriteFile(confFile, conf, 0600)
+	suite.Require().NoError(err)
+	return confFile
+}
+
 func (suite *UpdateAttributesTestSuite) TearDownTest() {
 	helpers.LogAndIgnore(suite.clientProcess.Process.Kill())
 	helpers.LogAndIgnore(suite.serverProcess.Process.Kill())
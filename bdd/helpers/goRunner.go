@@ -17,7 +17,8 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func StartClientAndServerAndWaitForConnection(ctx context.Context, t *testing.T, projectRoot string) (*exec.Cmd, *exec.Cmd) {
+// StartClientAndServerAndWaitForConnection starts rportd and rport in the current dir, clientArgs are passed to rport.
+func StartClientAndServerAndWaitForConnection(ctx context.Context, t *testing.T, projectRoot string, clientArgs ...string) (*exec.Cmd, *exec.Cmd) {
 
 	internalCtx, cancelFn := context.WithCancel(ctx)
 
@@ -36,7 +37,7 @@ func StartClientAndServerAndWaitForConnection(ctx context.Context, t *testing.T,
 	err := WaitForText(internalCtx, rdOutChan, "API Listening") // wait for server to initialize and boot - takes looooong time
 	assert.Nil(t, err)
 
-	rc, rcOutChan, rcErrChan := Run(t, "", path.Join(projectRoot, "cmd/rport/main.go"))
+	rc, rcOutChan, rcErrChan := Run(t, "", path.Join(projectRoot, "cmd/rport/main.go"), clientArgs...)
 	go func() {
 		for line := range rcErrChan {
 			if strings.Contains(line, "go: downloading") {
@@ -77,8 +78,8 @@ func WaitForText(ctx context.Context, ch chan string, txt string) error {
 
 }
 
-func Run(t *testing.T, pwd string, cmd string) (*exec.Cmd, chan string, chan string) {
-	rd := exec.Command("go", "run", cmd)
+func Run(t *testing.T, pwd string, cmd string, args ...string) (*exec.Cmd, chan string, chan string) {
+	rd := exec.Command("go", append([]string{"run", cmd}, args...)...)
 	rd.Dir = pwd
 
 	outPipe, err := rd.StdoutPipe()
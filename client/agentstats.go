@@ -0,0 +1,62 @@
+package chclient
+
+import (
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+// agentStatsCollector collects the resource usage of the client process to be reported to the server on heartbeat.
+type agentStatsCollector struct {
+	proc   *process.Process
+	logger *logger.Logger
+}
+
+func newAgentStatsCollector(l *logger.Logger) *agentStatsCollector {
+	proc, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		l.Errorf("Failed to get own process, agent stats are reported without memory and cpu usage: %v", err)
+	}
+	c := &agentStatsCollector{
+		proc:   proc,
+		logger: l,
+	}
+	// the first call initializes the cpu times, following calls return the usage since the previous one
+	c.Collect()
+	return c
+}
+
+// Collect returns the current resource usage, values not available on the current os are left empty.
+func (c *agentStatsCollector) Collect() *models.AgentStats {
+	stats := &models.AgentStats{
+		CollectedAt: time.Now(),
+		Goroutines:  runtime.NumGoroutine(),
+	}
+	if c.proc == nil {
+		return stats
+	}
+
+	if mem, err := c.proc.MemoryInfo(); err != nil {
+		c.logger.Debugf("Failed to get memory usage of the client process: %v", err)
+	} else {
+		stats.RSSBytes = mem.RSS
+	}
+
+	if cpu, err := c.proc.Percent(0); err != nil {
+		c.logger.Debugf("Failed to get cpu usage of the client process: %v", err)
+	} else {
+		stats.CPUPercent = cpu
+	}
+
+	if fds, err := c.proc.NumFDs(); err == nil {
+		openFDs := int(fds)
+		stats.OpenFDs = &openFDs
+	}
+
+	return stats
+}
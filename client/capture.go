@@ -0,0 +1,172 @@
+package chclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/realvnc-labs/rport/share/comm"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+const captureStdErrLimit = 4096
+
+// captureCommand is used to stub the capture tool in tests
+var captureCommand = exec.CommandContext
+
+// HandleCaptureRequest starts a packet capture and streams the pcap data to the server in background.
+// The capture is stopped after the max duration or max bytes of the request or the client config, whichever is lower.
+func (c *Client) HandleCaptureRequest(ctx context.Context, reqPayload []byte) (*comm.RunCmdResponse, error) {
+	cfg := c.configHolder.PacketCapture
+	if !cfg.Enabled {
+		return nil, errors.New("packet capture is disabled")
+	}
+
+	req := comm.CaptureRequest{}
+	err := json.Unmarshal(reqPayload, &req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode capture request: %s", err)
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.MaxDuration > 0 && req.MaxDuration > cfg.MaxDuration {
+		req.MaxDuration = cfg.MaxDuration
+	}
+	if cfg.MaxBytes > 0 && req.MaxBytes > cfg.MaxBytes {
+		req.MaxBytes = cfg.MaxBytes
+	}
+
+	job := req.Job
+	jobBytes, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+	stream, reqs, err := c.getConn().OpenChannel(models.ChannelCapture, jobBytes)
+	if err != nil {
+		return nil, err
+	}
+	go ssh.DiscardRequests(reqs)
+
+	captureCtx, stop := context.WithTimeout(ctx, req.MaxDuration)
+	cmd := captureCommand(captureCtx, cfg.Tcpdump, captureArgs(&req)...)
+	out := &captureWriter{Writer: stream, limit: req.MaxBytes, stop: stop}
+	stdErr := &CapacityBuffer{capacity: captureStdErrLimit}
+	cmd.Stdout = out
+	cmd.Stderr = stdErr
+
+	c.Debugf("Starting packet capture [jid=%q]: %s", job.JID, cmd.String())
+
+	startedAt := now()
+	err = cmd.Start()
+	if err != nil {
+		stop()
+		stream.Close()
+		return nil, fmt.Errorf("failed to start packet capture: %s", err)
+	}
+
+	go func() {
+		defer stop()
+
+		execErr := cmd.Wait()
+		stream.Close()
+
+		// the capture tool is killed when a limit is reached, that's the regular end of a capture
+		stopped := captureCtx.Err() != nil || out.LimitReached()
+		now := now()
+		job.FinishedAt = &now
+		job.StartedAt = startedAt
+		job.PID = &cmd.Process.Pid
+		if execErr != nil && !stopped {
+			job.Status = models.JobStatusFailed
+			job.Error = fmt.Sprintf("%s: %s", execErr, stdErr.Bytes())
+			c.Errorf("packet capture failed [jid=%q]: %s", job.JID, job.Error)
+		} else {
+			job.Status = models.JobStatusSuccessful
+		}
+		job.Result = &models.JobResult{
+			StdErr:  string(stdErr.Bytes()),
+			Summary: fmt.Sprintf("captured %d bytes", out.Written()),
+		}
+
+		jobBytes, err := json.Marshal(job)
+		if err != nil {
+			c.Errorf("failed to send packet capture result for [jid=%q]: failed to encode job result: %s", job.JID, err)
+			return
+		}
+		_, _, err = c.getConn().SendRequest(comm.RequestTypeCmdResult, false, jobBytes)
+		if err != nil {
+			c.Errorf("failed to send packet capture result to server[jid=%q]: %s", job.JID, err)
+		}
+		c.Debugf("finished packet capture [jid=%q]", job.JID)
+	}()
+
+	return &comm.RunCmdResponse{
+		Pid:       cmd.Process.Pid,
+		StartedAt: startedAt,
+	}, nil
+}
+
+// captureArgs returns the tcpdump args to write unbuffered pcap data to stdout. The filter is passed as a single
+// argument, tcpdump joins the remaining args to the filter expression anyway.
+func captureArgs(req *comm.CaptureRequest) []string {
+	args := []string{"-i", req.Interface, "-n", "-U", "-w", "-"}
+	if req.Filter != "" {
+		args = append(args, req.Filter)
+	}
+	return args
+}
+
+// captureWriter stops the capture once the limit is reached or the server closed the stream.
+type captureWriter struct {
+	io.Writer
+	limit int64
+	stop  func()
+
+	written      int64
+	limitReached bool
+	mu           sync.Mutex
+}
+
+func (w *captureWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if left := w.limit - w.written; int64(len(p)) >= left {
+		p = p[:left]
+		w.limitReached = true
+		w.stop()
+	}
+	n, err := w.Writer.Write(p)
+	w.written += int64(n)
+	if err != nil {
+		// the server closes the stream if its limits are reached
+		w.limitReached = true
+		w.stop()
+		return n, err
+	}
+	if w.limitReached {
+		return n, errCaptureLimitReached
+	}
+	return n, nil
+}
+
+var errCaptureLimitReached = errors.New("packet capture size limit reached")
+
+func (w *captureWriter) Written() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.written
+}
+
+func (w *captureWriter) LimitReached() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.limitReached
+}
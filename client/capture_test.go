@@ -0,0 +1,86 @@
+package chclient
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/share/comm"
+	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/test"
+)
+
+func TestHandleCaptureRequest(t *testing.T) {
+	var gotName string
+	var gotArgs []string
+	captureCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		gotName = name
+		gotArgs = args
+		return exec.CommandContext(ctx, "sh", "-c", "printf 0123456789")
+	}
+	defer func() {
+		captureCommand = exec.CommandContext
+	}()
+
+	connMock := test.NewConnMock()
+	done := make(chan bool)
+	connMock.DoneChannel = done
+	configCopy := getDefaultValidMinConfig()
+	configCopy.PacketCapture.Enabled = true
+	configCopy.PacketCapture.Tcpdump = "/usr/sbin/tcpdump"
+	configCopy.PacketCapture.MaxDuration = time.Minute
+	configCopy.PacketCapture.MaxBytes = 4
+	c := Client{
+		sshConnection: connMock,
+		Logger:        testLog,
+		configHolder:  &configCopy,
+	}
+
+	reqPayload, err := json.Marshal(comm.CaptureRequest{
+		Job:         models.Job{JID: "5f02b216-3f8a-42be-b66c-f4c1d0ea3809", IsCapture: true},
+		Interface:   "eth0",
+		Filter:      "port 53",
+		MaxDuration: time.Hour,
+		MaxBytes:    1024,
+	})
+	require.NoError(t, err)
+
+	resp, err := c.HandleCaptureRequest(context.Background(), reqPayload)
+	require.NoError(t, err)
+	assert.NotZero(t, resp.Pid)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("packet capture result was not sent")
+	}
+
+	assert.Equal(t, "/usr/sbin/tcpdump", gotName)
+	assert.Equal(t, []string{"-i", "eth0", "-n", "-U", "-w", "-", "port 53"}, gotArgs)
+	assert.Equal(t, "0123", strings.Join(connMock.ChannelMocks[models.ChannelCapture].Writes, ""))
+
+	name, _, payload := connMock.InputSendRequest()
+	assert.Equal(t, comm.RequestTypeCmdResult, name)
+	job := models.Job{}
+	require.NoError(t, json.Unmarshal(payload, &job))
+	assert.Equal(t, models.JobStatusSuccessful, job.Status)
+	assert.Equal(t, "captured 4 bytes", job.Result.Summary)
+}
+
+func TestHandleCaptureRequestDisabled(t *testing.T) {
+	configCopy := getDefaultValidMinConfig()
+	c := Client{
+		sshConnection: test.NewConnMock(),
+		Logger:        testLog,
+		configHolder:  &configCopy,
+	}
+
+	_, err := c.HandleCaptureRequest(context.Background(), []byte(`{}`))
+	assert.EqualError(t, err, "packet capture is disabled")
+}
@@ -27,9 +27,12 @@ import (
 	"golang.org/x/net/proxy"
 
 	"github.com/realvnc-labs/rport/client/monitoring"
+	"github.com/realvnc-labs/rport/client/monitoring/services"
+	"github.com/realvnc-labs/rport/client/security"
 	"github.com/realvnc-labs/rport/client/system"
 	"github.com/realvnc-labs/rport/client/updates"
 	chshare "github.com/realvnc-labs/rport/share"
+	"github.com/realvnc-labs/rport/share/clientconfig"
 	"github.com/realvnc-labs/rport/share/comm"
 	"github.com/realvnc-labs/rport/share/files"
 	"github.com/realvnc-labs/rport/share/logger"
@@ -61,10 +64,15 @@ type Client struct {
 	cmdExec            system.CmdExecutor
 	systemInfo         system.SysInfo
 	updates            *updates.Updates
+	security           *security.Snapshot
 	monitor            *monitoring.Monitor
 	serverCapabilities *models.Capabilities
 	filesAPI           files.FileAPI
 	watchdog           *Watchdog
+	agentStats         *agentStatsCollector
+	reverseTunnels     *reverseTunnels
+	// mode is the current mode of the client, it starts with the configured mode and can be switched by the server
+	mode string
 
 	mu sync.RWMutex
 }
@@ -102,10 +110,14 @@ func NewClient(config *ClientConfigHolder, filesAPI files.FileAPI) (*Client, err
 		cmdExec:      cmdExec,
 		systemInfo:   systemInfo,
 		updates:      updates.New(logger, config.Client.UpdatesInterval),
+		security:     security.New(logger, config.Client.SecuritySnapshotInterval),
 		monitor:      monitoring.NewMonitor(logger, config.Monitoring, systemInfo),
 		filesAPI:     filesAPI,
 		watchdog:     watchdog,
+		agentStats:   newAgentStatsCollector(logger),
+		mode:         config.Client.Mode,
 	}
+	client.reverseTunnels = newReverseTunnels(logger, client.openReverseTunnelChannel)
 
 	client.sshConfig = &ssh.ClientConfig{
 		User:            config.Client.AuthUser,
@@ -155,7 +167,12 @@ func (c *Client) Start(ctx context.Context) error {
 	//connection loop
 	go c.connectionLoop(ctx, true)
 
-	c.updates.Start(ctx)
+	if c.isCheckInOnly() {
+		c.Infof("Running in check-in only mode, tunnels, commands and file uploads are rejected")
+	} else {
+		c.updates.Start(ctx)
+	}
+	c.security.Start(ctx)
 
 	return nil
 }
@@ -172,6 +189,64 @@ func (c *Client) setConn(sshConnection ssh.Conn) {
 	c.sshConnection = sshConnection
 }
 
+func (c *Client) getMode() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.mode
+}
+
+func (c *Client) isCheckInOnly() bool {
+	return c.getMode() == clientconfig.ModeCheckIn
+}
+
+// setMode switches the mode until the client is restarted, the config file is not changed.
+func (c *Client) setMode(ctx context.Context, payload []byte) error {
+	var req comm.SetModeRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return err
+	}
+	if req.Mode != clientconfig.ModeFull && req.Mode != clientconfig.ModeCheckIn {
+		return fmt.Errorf("invalid mode %q", req.Mode)
+	}
+
+	c.mu.Lock()
+	prev := c.mode
+	c.mode = req.Mode
+	c.mu.Unlock()
+	if prev == req.Mode {
+		return nil
+	}
+
+	c.Infof("Switched from %s to %s mode", prev, req.Mode)
+	if req.Mode == clientconfig.ModeFull {
+		c.updates.Start(ctx)
+	} else {
+		c.updates.Stop()
+	}
+	return nil
+}
+
+// setMonitoringProfile applies the monitoring profile pushed by the server and restarts the monitoring.
+func (c *Client) setMonitoringProfile(ctx context.Context, payload []byte) (*comm.SetMonitoringProfileResponse, error) {
+	var req comm.SetMonitoringProfileRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+
+	c.monitor.ApplyProfile(&req)
+	if req.Name == "" {
+		c.Infof("Monitoring profile removed, using the local monitoring config")
+	} else {
+		c.Infof("Applied monitoring profile %q (version %s)", req.Name, req.Version)
+	}
+
+	if c.serverCapabilities != nil && c.serverCapabilities.MonitoringVersion > 0 {
+		c.monitor.Stop()
+		c.monitor.Start(ctx)
+	}
+	return &comm.SetMonitoringProfileResponse{Version: req.Version}, nil
+}
+
 func printMemStats(c *Client) {
 	var rtm runtime.MemStats
 	runtime.ReadMemStats(&rtm)
@@ -194,9 +269,17 @@ func (c *Client) keepAliveLoop(ctx context.Context) {
 		conn := c.getConn()
 
 		if conn != nil {
+			pingReq := comm.PingRequest{
+				AgentStats: c.agentStats.Collect(),
+			}
+			if ipv4, ipv6, err := c.localIPAddresses(); err != nil {
+				c.Logger.Debugf("Failed to get local ip addresses: %v", err)
+			} else {
+				pingReq.Addresses = &comm.PingAddresses{IPv4: ipv4, IPv6: ipv6}
+			}
 
 			res, err := comm.WithRetry(func() (res *sendResponse, err error) {
-				ok, _, rtt, err := comm.PingConnectionWithTimeout(ctx, conn, c.configHolder.Connection.KeepAliveTimeout, c.Logger)
+				ok, _, rtt, err := comm.PingConnectionWithTimestamp(ctx, conn, c.configHolder.Connection.KeepAliveTimeout, pingReq, c.Logger)
 				return &sendResponse{
 					replyOk:   ok,
 					rtt:       rtt,
@@ -287,6 +370,7 @@ func (c *Client) connectionLoop(ctx context.Context, withInitialSendRequestDelay
 		c.setConn(sshClientConn.Connection)
 
 		c.updates.SetConn(sshClientConn.Connection)
+		c.security.SetConn(sshClientConn.Connection)
 		c.monitor.SetConn(sshClientConn.Connection)
 
 		// watch for shutting down due to ctx.Done
@@ -303,6 +387,7 @@ func (c *Client) connectionLoop(ctx context.Context, withInitialSendRequestDelay
 
 		c.setConn(nil)
 		c.updates.SetConn(nil)
+		c.security.SetConn(nil)
 		c.monitor.SetConn(nil)
 		c.monitor.Stop()
 		cancelSwitchback()
@@ -602,6 +687,10 @@ func (c *Client) handlePutCapabilitiesRequest(ctx context.Context, payload []byt
 		return
 	}
 	c.Debugf("Server has capabilities: %s", string(payload))
+	if caps.ProtocolVersion == 0 {
+		// servers not supporting the negotiation
+		caps.ProtocolVersion, caps.Features = models.LegacyProtocolVersion, models.LegacyFeatures
+	}
 	c.serverCapabilities = caps
 	c.afterPutCapabilities(ctx)
 }
@@ -614,6 +703,11 @@ func (c *Client) handleSSHRequests(ctx context.Context, sshClientConn *sshClient
 		c.Logger.Debugf("payload: %v", string(r.Payload))
 		var err error
 		var resp interface{}
+		if c.isCheckInOnly() && isRejectedInCheckInMode(r.Type) {
+			c.Errorf("Rejecting %q request in check-in only mode", r.Type)
+			comm.ReplyError(c.Logger, r, errCheckInOnly)
+			continue
+		}
 		switch r.Type {
 
 		case comm.RequestTypeUpdateClientAttributes:
@@ -624,6 +718,15 @@ func (c *Client) handleSSHRequests(ctx context.Context, sshClientConn *sshClient
 		case comm.RequestTypeRunCmd:
 			resp, err = c.HandleRunCmdRequest(ctx, r.Payload)
 			// fall through for err and resp handling
+		case comm.RequestTypeCapture:
+			resp, err = c.HandleCaptureRequest(ctx, r.Payload)
+			// fall through for err and resp handling
+		case comm.RequestTypeGetInterpreters:
+			resp = system.DetectInterpreters(ctx, c.configHolder.InterpreterAliases)
+			// fall through for resp handling
+		case comm.RequestTypeGetServices:
+			resp, err = services.List(ctx)
+			// fall through for err and resp handling
 		case comm.RequestTypeRefreshUpdatesStatus:
 			c.updates.Refresh()
 			// fall through to reply success with empty resp
@@ -643,6 +746,18 @@ func (c *Client) handleSSHRequests(ctx context.Context, sshClientConn *sshClient
 		case comm.RequestTypeCheckTunnelAllowed:
 			resp, err = c.checkTunnelAllowed(r.Payload)
 			// fall through for err and resp handling
+		case comm.RequestTypeSetMode:
+			err = c.setMode(ctx, r.Payload)
+			// fall through for err and resp handling
+		case comm.RequestTypeSetMonitoringProfile:
+			resp, err = c.setMonitoringProfile(ctx, r.Payload)
+			// fall through for err and resp handling
+		case comm.RequestTypeStartReverseTunnel:
+			err = c.startReverseTunnel(r.Payload)
+			// fall through for err and resp handling
+		case comm.RequestTypeStopReverseTunnel:
+			err = c.stopReverseTunnel(r.Payload)
+			// fall through for err and resp handling
 		case comm.RequestTypePing:
 			// use empty reply (and NOT empty resp with success reply)
 			_ = r.Reply(true, nil)
@@ -665,6 +780,23 @@ func (c *Client) handleSSHRequests(ctx context.Context, sshClientConn *sshClient
 	c.Logger.Debugf("handleSSHRequests finished")
 }
 
+var errCheckInOnly = errors.New("client is in check-in only mode")
+
+// isRejectedInCheckInMode returns true for requests of features disabled in check-in only mode.
+func isRejectedInCheckInMode(requestType string) bool {
+	switch requestType {
+	case comm.RequestTypeCheckPort,
+		comm.RequestTypeRunCmd,
+		comm.RequestTypeCapture,
+		comm.RequestTypeRefreshUpdatesStatus,
+		comm.RequestTypeUpload,
+		comm.RequestTypeCheckTunnelAllowed,
+		comm.RequestTypeStartReverseTunnel:
+		return true
+	}
+	return false
+}
+
 func checkPort(payload []byte) (*comm.CheckPortResponse, error) {
 	req, err := comm.DecodeCheckPortRequest(payload)
 	if err != nil {
@@ -689,6 +821,11 @@ func (c *Client) checkTunnelAllowed(payload []byte) (*comm.CheckTunnelAllowedRes
 		return nil, err
 	}
 
+	// the destinations of SOCKS5 tunnels are not known yet, socks5Handler checks them for each connection
+	if req.Remote == models.RemoteSOCKS5 {
+		return &comm.CheckTunnelAllowedResponse{IsAllowed: true}, nil
+	}
+
 	allowed, err := TunnelIsAllowed(c.configHolder.Client.TunnelAllowed, req.Remote)
 	if err != nil {
 		return nil, err
@@ -718,6 +855,7 @@ func (c *Client) Wait(ctx context.Context) (err error) {
 func (c *Client) Close() error {
 	c.stopRunning()
 	c.watchdog.Close()
+	c.reverseTunnels.Close()
 	return c.CloseConnection()
 }
 
@@ -747,15 +885,29 @@ func (c *Client) connectStreams(chans <-chan ssh.NewChannel) {
 		remote := string(ch.ExtraData())
 		protocol := models.ProtocolTCP
 		c.Debugf("handling connect stream: remote=%s, protocol=%s", remote, protocol)
+		isSOCKS5 := remote == models.RemoteSOCKS5
 		parts := strings.SplitN(remote, "/", 2)
-		if len(parts) == 2 {
+		if len(parts) == 2 && !isSOCKS5 {
 			remote = parts[0]
 			protocol = parts[1]
 		}
 
-		allowed, err := TunnelIsAllowed(c.configHolder.Client.TunnelAllowed, remote)
-		if err != nil {
-			c.Errorf("Could not check if remote is allowed: %v", err)
+		if c.isCheckInOnly() {
+			c.Errorf("Rejecting stream to %q in check-in only mode", remote)
+			if err := ch.Reject(ssh.Prohibited, errCheckInOnly.Error()); err != nil {
+				c.Errorf("Failed to reject stream: %v", err)
+			}
+			continue
+		}
+
+		// the destinations of SOCKS5 tunnels are not known yet, socks5Handler checks them for each connection
+		allowed := isSOCKS5
+		if !isSOCKS5 {
+			var err error
+			allowed, err = TunnelIsAllowed(c.configHolder.Client.TunnelAllowed, remote)
+			if err != nil {
+				c.Errorf("Could not check if remote is allowed: %v", err)
+			}
 		}
 		if !allowed {
 			c.Errorf(`Rejecting stream to %q based on "tunnel_allowed" config: %v`, remote, c.configHolder.Client.TunnelAllowed)
@@ -773,11 +925,18 @@ func (c *Client) connectStreams(chans <-chan ssh.NewChannel) {
 		}
 		go ssh.DiscardRequests(reqs)
 
-		switch protocol {
-		case models.ProtocolTCP:
+		switch {
+		case isSOCKS5:
+			h := newSOCKS5Handler(c.Logger.Fork("socks5 conn#%d", c.connStats.New()), &c.connStats, c.configHolder.Client.TunnelAllowed)
+			go func() {
+				if err := h.Handle(stream); err != nil {
+					h.Debugf("Error with SOCKS5: %v", err)
+				}
+			}()
+		case protocol == models.ProtocolTCP:
 			l := c.Logger.Fork("tcp conn#%d", c.connStats.New())
 			go chshare.HandleTCPStream(l, &c.connStats, stream, remote)
-		case models.ProtocolUDP:
+		case protocol == models.ProtocolUDP:
 			go func() {
 				err := newUDPHandler(c.Logger.Fork("udp#%s", remote), remote).Handle(stream)
 				if err != nil {
@@ -846,6 +1005,12 @@ func (c *Client) connectionRequest(ctx context.Context) (*chshare.ConnectionRequ
 		CPUModelName:           system.UnknownValue,
 		CPUVendor:              system.UnknownValue,
 		ClientConfiguration:    c.configHolder.Config,
+		Mode:                   c.getMode(),
+		Capabilities:           models.NewClientCapabilities(),
+	}
+	if connReq.Mode == clientconfig.ModeCheckIn {
+		// configured tunnels are created when the client reconnects after it was switched to full mode
+		connReq.Remotes = nil
 	}
 
 	var err error
@@ -23,6 +23,7 @@ import (
 	"github.com/realvnc-labs/rport/client/system"
 	chshare "github.com/realvnc-labs/rport/share"
 	"github.com/realvnc-labs/rport/share/clientconfig"
+	"github.com/realvnc-labs/rport/share/comm"
 	"github.com/realvnc-labs/rport/share/logger"
 	"github.com/realvnc-labs/rport/share/models"
 	"github.com/realvnc-labs/rport/share/random"
@@ -198,6 +199,7 @@ func TestConnectionRequest(t *testing.T) {
 				Labels:                 map[string]string{"lab1": "val1"},
 				Remotes:                []*models.Remote{remote1, remote2},
 				ClientConfiguration:    config.Config,
+				Capabilities:           models.NewClientCapabilities(),
 			},
 		}, {
 			Name: "windows, no errors",
@@ -251,6 +253,7 @@ func TestConnectionRequest(t *testing.T) {
 				IPv4:                   []string{"192.0.2.1", "192.0.2.2"},
 				IPv6:                   []string{"2001:db8::1", "2001:db8::2"},
 				ClientConfiguration:    config.Config,
+				Capabilities:           models.NewClientCapabilities(),
 			},
 		}, {
 			Name: "all errors",
@@ -288,6 +291,7 @@ func TestConnectionRequest(t *testing.T) {
 				IPv4:                   nil,
 				IPv6:                   nil,
 				ClientConfiguration:    config.Config,
+				Capabilities:           models.NewClientCapabilities(),
 			},
 		}, {
 			Name: "uname error",
@@ -329,6 +333,7 @@ func TestConnectionRequest(t *testing.T) {
 				IPv4:                   []string{"192.0.2.1", "192.0.2.2"},
 				IPv6:                   []string{"2001:db8::1", "2001:db8::2"},
 				ClientConfiguration:    config.Config,
+				Capabilities:           models.NewClientCapabilities(),
 			},
 		},
 	}
@@ -347,6 +352,64 @@ func TestConnectionRequest(t *testing.T) {
 	}
 }
 
+func TestCheckInMode(t *testing.T) {
+	remote := &models.Remote{
+		LocalPort:  "1234",
+		RemoteHost: "test-remote",
+		RemotePort: "2345",
+	}
+	config := &ClientConfigHolder{
+		Config: &clientconfig.Config{
+			Client: clientconfig.ClientConfig{
+				ID:      "test-client-id",
+				Mode:    clientconfig.ModeCheckIn,
+				Tunnels: []*models.Remote{remote},
+			},
+		},
+	}
+	client, err := NewClient(config, test.NewFileAPIMock())
+	require.NoError(t, err)
+	client.systemInfo = &system.MockSystemInfo{ReturnHostInfo: &host.InfoStat{}}
+
+	connReq, err := client.connectionRequest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, clientconfig.ModeCheckIn, connReq.Mode)
+	assert.Nil(t, connReq.Remotes)
+	assert.True(t, client.isCheckInOnly())
+
+	err = client.setMode(context.Background(), []byte(`{"Mode":"invalid"}`))
+	assert.EqualError(t, err, `invalid mode "invalid"`)
+
+	err = client.setMode(context.Background(), []byte(`{"Mode":"full"}`))
+	require.NoError(t, err)
+	assert.False(t, client.isCheckInOnly())
+
+	connReq, err = client.connectionRequest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, clientconfig.ModeFull, connReq.Mode)
+	assert.Equal(t, []*models.Remote{remote}, connReq.Remotes)
+}
+
+func TestIsRejectedInCheckInMode(t *testing.T) {
+	for _, requestType := range []string{
+		comm.RequestTypeCheckPort,
+		comm.RequestTypeRunCmd,
+		comm.RequestTypeRefreshUpdatesStatus,
+		comm.RequestTypeUpload,
+		comm.RequestTypeCheckTunnelAllowed,
+	} {
+		assert.True(t, isRejectedInCheckInMode(requestType), requestType)
+	}
+	for _, requestType := range []string{
+		comm.RequestTypeSetMode,
+		comm.RequestTypePutCapabilities,
+		comm.RequestTypeUpdateClientAttributes,
+		comm.RequestTypePing,
+	} {
+		assert.False(t, isRejectedInCheckInMode(requestType), requestType)
+	}
+}
+
 // mockServer receives client connections and keeps track whether the connection is established
 type mockServer struct {
 	upgrader  websocket.Upgrader
@@ -137,7 +137,7 @@ func (c *Client) HandleRunCmdRequest(ctx context.Context, reqPayload []byte) (*c
 		defer c.rmScript(scriptPath)
 		defer closeStreamChannels()
 
-		c.Debugf("started to observe cmd [jid=%q,pid=%d]", job.JID, cmd.Process.Pid)
+		c.Debugf("started to observe cmd [jid=%q,pid=%d,correlation_id=%q]", job.JID, cmd.Process.Pid, job.CorrelationID)
 
 		// after timeout stop observing but leave the cmd running
 		done := make(chan error)
@@ -195,7 +195,7 @@ func (c *Client) HandleRunCmdRequest(ctx context.Context, reqPayload []byte) (*c
 			c.Errorf("failed to send command result to server[jid=%q,pid=%d]: %s", job.JID, cmd.Process.Pid, err)
 		}
 
-		c.Debugf("finished to observe cmd [jid=%q,pid=%d]", job.JID, cmd.Process.Pid)
+		c.Debugf("finished to observe cmd [jid=%q,pid=%d,correlation_id=%q]", job.JID, cmd.Process.Pid, job.CorrelationID)
 	}()
 
 	return &comm.RunCmdResponse{
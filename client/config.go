@@ -82,6 +82,21 @@ func (c *ClientConfigHolder) ParseAndValidate(skipScriptsDirValidation bool) err
 		return err
 	}
 
+	if err := c.parseMode(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ClientConfigHolder) parseMode() error {
+	switch c.Client.Mode {
+	case "":
+		c.Client.Mode = clientconfig.ModeFull
+	case clientconfig.ModeFull, clientconfig.ModeCheckIn:
+	default:
+		return fmt.Errorf("invalid mode %q, expected one of: %s, %s", c.Client.Mode, clientconfig.ModeFull, clientconfig.ModeCheckIn)
+	}
 	return nil
 }
 
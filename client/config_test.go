@@ -814,3 +814,41 @@ func TestConfigParseInterpreterAliases(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigParseAndValidateMode(t *testing.T) {
+	testCases := []struct {
+		Mode         string
+		ExpectedMode string
+		ExpectedErr  string
+	}{
+		{
+			Mode:         "",
+			ExpectedMode: clientconfig.ModeFull,
+		}, {
+			Mode:         clientconfig.ModeFull,
+			ExpectedMode: clientconfig.ModeFull,
+		}, {
+			Mode:         clientconfig.ModeCheckIn,
+			ExpectedMode: clientconfig.ModeCheckIn,
+		}, {
+			Mode:        "light",
+			ExpectedErr: `invalid mode "light", expected one of: full, checkin`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Mode, func(t *testing.T) {
+			config := getDefaultValidMinConfig()
+			config.Client.Mode = tc.Mode
+
+			err := config.ParseAndValidate(true)
+
+			if tc.ExpectedErr != "" {
+				assert.EqualError(t, err, tc.ExpectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedMode, config.Client.Mode)
+		})
+	}
+}
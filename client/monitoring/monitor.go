@@ -3,6 +3,7 @@ package monitoring
 import (
 	"context"
 	"encoding/json"
+	"regexp"
 	"sync"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/realvnc-labs/rport/client/monitoring/fs"
 	"github.com/realvnc-labs/rport/client/monitoring/networking"
 	"github.com/realvnc-labs/rport/client/monitoring/processes"
+	"github.com/realvnc-labs/rport/client/monitoring/services"
 	"github.com/realvnc-labs/rport/client/system"
 	"github.com/realvnc-labs/rport/share/clientconfig"
 	"github.com/realvnc-labs/rport/share/comm"
@@ -24,6 +26,8 @@ type Monitor struct {
 	stopFn            func()
 	logger            *logger.Logger
 	config            clientconfig.MonitoringConfig
+	localConfig       clientconfig.MonitoringConfig
+	profile           *comm.SetMonitoringProfileRequest
 	measurement       *models.Measurement
 	systemInfo        system.SysInfo
 	fileSystemWatcher *fs.FileSystemWatcher
@@ -41,11 +45,14 @@ func NewMonitor(logger *logger.Logger, config clientconfig.MonitoringConfig, sys
 	}, logger)
 	processHandler := processes.NewProcessHandler(config, logger)
 	netHandler := networking.NewNetHandler(&config)
-	return &Monitor{logger: logger, config: config, systemInfo: systemInfo, fileSystemWatcher: fsWatcher, processHandler: processHandler, netHandler: netHandler}
+	return &Monitor{logger: logger, config: config, localConfig: config, systemInfo: systemInfo, fileSystemWatcher: fsWatcher, processHandler: processHandler, netHandler: netHandler}
 }
 
 func (m *Monitor) Start(ctx context.Context) {
-	if !m.config.Enabled {
+	m.mtx.RLock()
+	enabled := m.config.Enabled
+	m.mtx.RUnlock()
+	if !enabled {
 		return
 	}
 
@@ -73,11 +80,52 @@ func (m *Monitor) refreshLoop(ctx context.Context) {
 		case <-ctx.Done():
 			m.logger.Errorf("Monitoring ended by context.Done")
 			return
-		case <-time.After(m.config.Interval):
+		case <-time.After(m.interval()):
 		}
 	}
 }
 
+func (m *Monitor) interval() time.Duration {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	return m.config.Interval
+}
+
+// ApplyProfile overrides the local monitoring config with a profile pushed by the server, a profile without name
+// restores the local config. The monitoring must be restarted for changes of the enabled state to take effect.
+func (m *Monitor) ApplyProfile(profile *comm.SetMonitoringProfileRequest) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	config := m.localConfig
+	m.profile = nil
+	if profile.Name != "" {
+		m.profile = profile
+		config.Enabled = profile.Enabled
+		if profile.Interval > 0 {
+			config.Interval = profile.Interval
+		}
+		if len(profile.Metrics) > 0 {
+			config.PMEnabled = m.metricEnabled(comm.MonitoringMetricProcesses)
+		}
+	}
+	m.config = config
+	m.processHandler = processes.NewProcessHandler(config, m.logger)
+}
+
+// metricEnabled returns false if the metric is not selected by the monitoring profile.
+func (m *Monitor) metricEnabled(metric string) bool {
+	if m.profile == nil || len(m.profile.Metrics) == 0 {
+		return true
+	}
+	for _, enabled := range m.profile.Metrics {
+		if enabled == metric {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *Monitor) refreshMeasurement(ctx context.Context) {
 	m.mtx.Lock()
 	m.measurement = m.createMeasurement(ctx)
@@ -91,23 +139,30 @@ func (m *Monitor) createMeasurement(ctx context.Context) *models.Measurement {
 
 	newMeasurement.Timestamp = time.Now().UTC()
 
-	cpuPercent, err := m.systemInfo.CPUPercent(ctx)
-	if err == nil {
-		newMeasurement.CPUUsagePercent = cpuPercent
-	} else {
-		m.logger.Debugf("Cannot measure cpu_usage_percent:" + err.Error())
+	if m.metricEnabled(comm.MonitoringMetricCPU) {
+		cpuPercent, err := m.systemInfo.CPUPercent(ctx)
+		if err == nil {
+			newMeasurement.CPUUsagePercent = cpuPercent
+		} else {
+			m.logger.Debugf("Cannot measure cpu_usage_percent:" + err.Error())
+		}
 	}
+	// memory stats are also used to calculate the memory usage of processes
 	memStats, err := m.systemInfo.MemoryStats(ctx)
 	if err == nil {
-		newMeasurement.MemoryUsagePercent = memStats.UsedPercent
+		if m.metricEnabled(comm.MonitoringMetricMemory) {
+			newMeasurement.MemoryUsagePercent = memStats.UsedPercent
+		}
 	} else {
 		m.logger.Debugf("Cannot measure memory_usage_percent:" + err.Error())
 	}
-	cpuPercentIOWait, err := m.systemInfo.CPUPercentIOWait(ctx)
-	if err == nil {
-		newMeasurement.IoUsagePercent = cpuPercentIOWait
-	} else {
-		m.logger.Debugf("Cannot measure io_usage_percent:" + err.Error())
+	if m.metricEnabled(comm.MonitoringMetricIO) {
+		cpuPercentIOWait, err := m.systemInfo.CPUPercentIOWait(ctx)
+		if err == nil {
+			newMeasurement.IoUsagePercent = cpuPercentIOWait
+		} else {
+			m.logger.Debugf("Cannot measure io_usage_percent:" + err.Error())
+		}
 	}
 
 	processes, err := m.processHandler.GetProcessesJSON(memStats)
@@ -117,21 +172,64 @@ func (m *Monitor) createMeasurement(ctx context.Context) *models.Measurement {
 		m.logger.Debugf("Cannot measure processes:" + err.Error())
 	}
 
-	fsMap, err := m.fileSystemWatcher.Results()
+	if m.metricEnabled(comm.MonitoringMetricMountpoints) {
+		fsMap, err := m.fileSystemWatcher.Results()
+		if err == nil {
+			newMeasurement.Mountpoints = fsMap.ToJSON()
+		} else {
+			m.logger.Debugf("Cannot measure mountpoints:" + err.Error())
+		}
+	}
+
+	if m.metricEnabled(comm.MonitoringMetricNet) {
+		netLan, netWan, err := m.netHandler.GetNets()
+		if err == nil {
+			newMeasurement.NetLan = netLan
+			newMeasurement.NetWan = netWan
+		} else {
+			m.logger.Debugf("Cannot measure network bandwidth:" + err.Error())
+		}
+	}
+
+	if m.profile != nil {
+		newMeasurement.CustomMetrics = m.watchedMetrics(ctx)
+	}
+	return newMeasurement
+}
+
+var invalidMetricNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.\-]`)
+
+// watchedMetrics returns the number of running processes and the state of services watched by the monitoring
+// profile as custom metrics, e.g. "process.nginx.running" = 2 and "service.sshd.active" = 1.
+func (m *Monitor) watchedMetrics(ctx context.Context) map[string]float64 {
+	metrics := make(map[string]float64)
+
+	counts, err := processes.CountByName(ctx, m.profile.WatchProcesses)
 	if err == nil {
-		newMeasurement.Mountpoints = fsMap.ToJSON()
+		for name, count := range counts {
+			metrics["process."+invalidMetricNameChars.ReplaceAllString(name, "_")+".running"] = float64(count)
+		}
 	} else {
-		m.logger.Debugf("Cannot measure mountpoints:" + err.Error())
+		m.logger.Debugf("Cannot measure watched processes:" + err.Error())
 	}
 
-	netLan, netWan, err := m.netHandler.GetNets()
+	states, err := services.States(ctx, m.profile.WatchServices)
 	if err == nil {
-		newMeasurement.NetLan = netLan
-		newMeasurement.NetWan = netWan
+		for name, active := range states {
+			var value float64
+			if active {
+				value = 1
+			}
+			metrics["service."+invalidMetricNameChars.ReplaceAllString(name, "_")+".active"] = value
+		}
 	} else {
-		m.logger.Debugf("Cannot measure network bandwidth:" + err.Error())
+		m.logger.Debugf("Cannot measure watched services:" + err.Error())
 	}
-	return newMeasurement
+
+	if len(metrics) == 0 {
+		return nil
+	}
+	return metrics
 }
 
 // sends system measurement data to server using ssh-connection
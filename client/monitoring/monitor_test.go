@@ -0,0 +1,44 @@
+package monitoring
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/realvnc-labs/rport/share/clientconfig"
+	"github.com/realvnc-labs/rport/share/comm"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+var testLog = logger.NewLogger("monitoring", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+
+func TestApplyProfile(t *testing.T) {
+	localConfig := clientconfig.MonitoringConfig{
+		Enabled:   false,
+		Interval:  60 * time.Second,
+		PMEnabled: true,
+	}
+	m := NewMonitor(testLog, localConfig, nil)
+
+	m.ApplyProfile(&comm.SetMonitoringProfileRequest{
+		Name:     "servers",
+		Version:  "abc",
+		Enabled:  true,
+		Interval: 5 * time.Minute,
+		Metrics:  []string{comm.MonitoringMetricCPU, comm.MonitoringMetricNet},
+	})
+
+	assert.True(t, m.config.Enabled)
+	assert.Equal(t, 5*time.Minute, m.interval())
+	assert.False(t, m.config.PMEnabled)
+	assert.True(t, m.metricEnabled(comm.MonitoringMetricCPU))
+	assert.False(t, m.metricEnabled(comm.MonitoringMetricMountpoints))
+
+	// reset to the local config
+	m.ApplyProfile(&comm.SetMonitoringProfileRequest{})
+
+	assert.Equal(t, localConfig, m.config)
+	assert.True(t, m.metricEnabled(comm.MonitoringMetricMountpoints))
+}
@@ -0,0 +1,39 @@
+package processes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// CountByName returns the number of running processes for each of the given names. Names are compared
+// case-insensitive, with or without the .exe suffix on windows.
+func CountByName(ctx context.Context, names []string) (map[string]int, error) {
+	counts := make(map[string]int, len(names))
+	for _, name := range names {
+		counts[name] = 0
+	}
+	if len(names) == 0 {
+		return counts, nil
+	}
+
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range procs {
+		procName, err := p.NameWithContext(ctx)
+		if err != nil {
+			// the process might be terminated already
+			continue
+		}
+		procName = strings.TrimSuffix(strings.ToLower(procName), ".exe")
+		for _, name := range names {
+			if strings.TrimSuffix(strings.ToLower(name), ".exe") == procName {
+				counts[name]++
+			}
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+const checkTimeout = 10 * time.Second
+
+// States returns for each of the given services whether it's active.
+func States(ctx context.Context, names []string) (map[string]bool, error) {
+	states := make(map[string]bool, len(names))
+	for _, name := range names {
+		active, err := isActive(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		states[name] = active
+	}
+	return states, nil
+}
+
+// List returns all services known to the service manager of the system sorted by name.
+func List(ctx context.Context) ([]models.Service, error) {
+	list, err := list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list, nil
+}
+
+// parseSystemctl parses the output of "systemctl list-units --type=service --all --no-legend --no-pager --plain".
+// The state is the sub state of the unit, e.g. "running" or "exited".
+func parseSystemctl(output []byte) []models.Service {
+	var list []models.Service
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// failed units are prefixed by a bullet
+		if len(fields) > 0 && (fields[0] == "●" || fields[0] == "*") {
+			fields = fields[1:]
+		}
+		if len(fields) < 4 {
+			continue
+		}
+		list = append(list, models.Service{
+			Name:  strings.TrimSuffix(fields[0], ".service"),
+			State: fields[3],
+		})
+	}
+	return list
+}
+
+// parseLaunchctl parses the output of "launchctl list", jobs with a pid are running.
+func parseLaunchctl(output []byte) []models.Service {
+	var list []models.Service
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[0] == "PID" {
+			continue
+		}
+		state := "running"
+		if fields[0] == "-" {
+			state = "stopped"
+		}
+		list = append(list, models.Service{
+			Name:  fields[2],
+			State: state,
+		})
+	}
+	return list
+}
+
+// parseSCQuery parses the output of "sc query state= all".
+func parseSCQuery(output []byte) []models.Service {
+	var list []models.Service
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "SERVICE_NAME":
+			list = append(list, models.Service{Name: strings.TrimSpace(value)})
+		case "STATE":
+			// e.g. "4  RUNNING"
+			fields := strings.Fields(value)
+			if len(list) > 0 && len(fields) > 1 {
+				list[len(list)-1].State = strings.ToLower(fields[1])
+			}
+		}
+	}
+	return list
+}
+
+// run executes the command and returns its output. A non-zero exit code is not considered an error.
+func run(ctx context.Context, name string, args ...string) (output []byte, exitCode int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	output, err = exec.CommandContext(ctx, name, args...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return output, exitErr.ExitCode(), nil
+	}
+	return output, 0, err
+}
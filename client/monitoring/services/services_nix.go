@@ -0,0 +1,44 @@
+//go:build !windows
+// +build !windows
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func isActive(ctx context.Context, name string) (bool, error) {
+	if runtime.GOOS == "darwin" {
+		_, exitCode, err := run(ctx, "launchctl", "list", name)
+		return err == nil && exitCode == 0, err
+	}
+
+	_, exitCode, err := run(ctx, "systemctl", "is-active", "--quiet", name)
+	return err == nil && exitCode == 0, err
+}
+
+func list(ctx context.Context) ([]models.Service, error) {
+	if runtime.GOOS == "darwin" {
+		output, exitCode, err := run(ctx, "launchctl", "list")
+		if err != nil {
+			return nil, err
+		}
+		if exitCode != 0 {
+			return nil, fmt.Errorf("launchctl exited with code %d", exitCode)
+		}
+		return parseLaunchctl(output), nil
+	}
+
+	output, exitCode, err := run(ctx, "systemctl", "list-units", "--type=service", "--all", "--no-legend", "--no-pager", "--plain")
+	if err != nil {
+		return nil, err
+	}
+	if exitCode != 0 {
+		return nil, fmt.Errorf("systemctl exited with code %d", exitCode)
+	}
+	return parseSystemctl(output), nil
+}
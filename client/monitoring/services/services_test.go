@@ -0,0 +1,51 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func TestParseSystemctl(t *testing.T) {
+	output := `cron.service                 loaded    active   running Regular background program processing daemon
+● nginx.service              loaded    failed   failed  A high performance web server
+systemd-fsck-root.service    loaded    active   exited  File System Check on Root Device
+ufw.service                  not-found inactive dead    ufw.service
+`
+	assert.Equal(t, []models.Service{
+		{Name: "cron", State: "running"},
+		{Name: "nginx", State: "failed"},
+		{Name: "systemd-fsck-root", State: "exited"},
+		{Name: "ufw", State: "dead"},
+	}, parseSystemctl([]byte(output)))
+}
+
+func TestParseLaunchctl(t *testing.T) {
+	output := "PID\tStatus\tLabel\n412\t0\tcom.apple.Finder\n-\t78\tcom.openssh.sshd\n"
+	assert.Equal(t, []models.Service{
+		{Name: "com.apple.Finder", State: "running"},
+		{Name: "com.openssh.sshd", State: "stopped"},
+	}, parseLaunchctl([]byte(output)))
+}
+
+func TestParseSCQuery(t *testing.T) {
+	output := `
+SERVICE_NAME: Dhcp
+DISPLAY_NAME: DHCP Client
+        TYPE               : 20  WIN32_SHARE_PROCESS
+        STATE              : 4  RUNNING
+                                (STOPPABLE, NOT_PAUSABLE, ACCEPTS_SHUTDOWN)
+        WIN32_EXIT_CODE    : 0  (0x0)
+
+SERVICE_NAME: wuauserv
+DISPLAY_NAME: Windows Update
+        TYPE               : 20  WIN32_SHARE_PROCESS
+        STATE              : 1  STOPPED
+`
+	assert.Equal(t, []models.Service{
+		{Name: "Dhcp", State: "running"},
+		{Name: "wuauserv", State: "stopped"},
+	}, parseSCQuery([]byte(output)))
+}
@@ -0,0 +1,31 @@
+//go:build windows
+// +build windows
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func isActive(ctx context.Context, name string) (bool, error) {
+	output, exitCode, err := run(ctx, "sc", "query", name)
+	if err != nil || exitCode != 0 {
+		return false, err
+	}
+	return bytes.Contains(output, []byte("RUNNING")), nil
+}
+
+func list(ctx context.Context) ([]models.Service, error) {
+	output, exitCode, err := run(ctx, "sc", "query", "state=", "all")
+	if err != nil {
+		return nil, err
+	}
+	if exitCode != 0 {
+		return nil, fmt.Errorf("sc exited with code %d", exitCode)
+	}
+	return parseSCQuery(output), nil
+}
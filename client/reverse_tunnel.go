@@ -0,0 +1,158 @@
+package chclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+
+	chshare "github.com/realvnc-labs/rport/share"
+	"github.com/realvnc-labs/rport/share/comm"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+var errReverseTunnelsDisabled = errors.New("reverse tunnels are disabled")
+
+// reverseTunnels keeps the listeners of the reverse tunnels started by the server. The listeners survive reconnects,
+// accepted connections are forwarded through the current connection to the server.
+type reverseTunnels struct {
+	logger      *logger.Logger
+	openChannel func(id string) (ssh.Channel, error)
+
+	listeners map[string]*reverseTunnelListener
+	mu        sync.Mutex
+}
+
+type reverseTunnelListener struct {
+	net.Listener
+	local string
+}
+
+func newReverseTunnels(logger *logger.Logger, openChannel func(id string) (ssh.Channel, error)) *reverseTunnels {
+	return &reverseTunnels{
+		logger:      logger,
+		openChannel: openChannel,
+		listeners:   make(map[string]*reverseTunnelListener),
+	}
+}
+
+// Start listens on the local address of the reverse tunnel. It succeeds if the tunnel is already listening on it.
+func (r *reverseTunnels) Start(req comm.StartReverseTunnelRequest) error {
+	local := net.JoinHostPort(req.LocalHost, req.LocalPort)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if l, ok := r.listeners[req.ID]; ok {
+		if l.local == local {
+			return nil
+		}
+		l.Close()
+		delete(r.listeners, req.ID)
+	}
+
+	ln, err := net.Listen("tcp", local)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", local, err)
+	}
+	r.listeners[req.ID] = &reverseTunnelListener{Listener: ln, local: local}
+	r.logger.Infof("reverse tunnel %s listening on %s", req.ID, local)
+
+	go r.accept(req.ID, ln)
+	return nil
+}
+
+// Stop closes the listener of the reverse tunnel, established connections are kept.
+func (r *reverseTunnels) Stop(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if l, ok := r.listeners[id]; ok {
+		l.Close()
+		delete(r.listeners, id)
+		r.logger.Infof("reverse tunnel %s stopped", id)
+	}
+}
+
+// Close stops all reverse tunnels.
+func (r *reverseTunnels) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, l := range r.listeners {
+		l.Close()
+		delete(r.listeners, id)
+	}
+}
+
+func (r *reverseTunnels) accept(id string, ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			r.logger.Debugf("reverse tunnel %s stopped accepting: %v", id, err)
+			return
+		}
+		go r.forward(id, conn)
+	}
+}
+
+func (r *reverseTunnels) forward(id string, conn net.Conn) {
+	ch, err := r.openChannel(id)
+	if err != nil {
+		r.logger.Errorf("reverse tunnel %s: failed to open channel for %s: %v", id, conn.RemoteAddr(), err)
+		conn.Close()
+		return
+	}
+	sent, received := chshare.Pipe(conn, ch)
+	r.logger.Debugf("reverse tunnel %s: closed connection of %s (sent %d, received %d)", id, conn.RemoteAddr(), sent, received)
+}
+
+func (c *Client) openReverseTunnelChannel(id string) (ssh.Channel, error) {
+	conn := c.getConn()
+	if conn == nil {
+		return nil, errors.New("not connected to the server")
+	}
+	ch, reqs, err := conn.OpenChannel(models.ChannelReverseTunnel, []byte(id))
+	if err != nil {
+		return nil, err
+	}
+	go ssh.DiscardRequests(reqs)
+	return ch, nil
+}
+
+func (c *Client) startReverseTunnel(payload []byte) error {
+	cfg := c.configHolder.ReverseTunnels
+	if !cfg.Enabled {
+		return errReverseTunnelsDisabled
+	}
+
+	var req comm.StartReverseTunnelRequest
+	err := json.Unmarshal(payload, &req)
+	if err != nil {
+		return err
+	}
+	if !reverseTunnelHostAllowed(cfg.ListenHosts, req.LocalHost) {
+		return fmt.Errorf("listening on %q is not allowed based on \"listen_hosts\" config: %v", req.LocalHost, cfg.ListenHosts)
+	}
+	return c.reverseTunnels.Start(req)
+}
+
+func (c *Client) stopReverseTunnel(payload []byte) error {
+	var req comm.StopReverseTunnelRequest
+	err := json.Unmarshal(payload, &req)
+	if err != nil {
+		return err
+	}
+	c.reverseTunnels.Stop(req.ID)
+	return nil
+}
+
+func reverseTunnelHostAllowed(listenHosts []string, host string) bool {
+	for _, h := range listenHosts {
+		if h == host {
+			return true
+		}
+	}
+	return false
+}
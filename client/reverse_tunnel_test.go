@@ -0,0 +1,127 @@
+package chclient
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/realvnc-labs/rport/share/clientconfig"
+	"github.com/realvnc-labs/rport/share/comm"
+)
+
+type pipeChannel struct {
+	net.Conn
+}
+
+func (c pipeChannel) CloseWrite() error {
+	return nil
+}
+
+func (c pipeChannel) SendRequest(string, bool, []byte) (bool, error) {
+	return false, nil
+}
+
+func (c pipeChannel) Stderr() io.ReadWriter {
+	return nil
+}
+
+func TestReverseTunnelsForward(t *testing.T) {
+	opened := make(chan string, 1)
+	server, channel := net.Pipe()
+	defer server.Close()
+	rt := newReverseTunnels(testLog, func(id string) (ssh.Channel, error) {
+		opened <- id
+		return pipeChannel{Conn: channel}, nil
+	})
+	defer rt.Close()
+
+	req := comm.StartReverseTunnelRequest{ID: "rt1", LocalHost: "127.0.0.1", LocalPort: "0"}
+	require.NoError(t, rt.Start(req))
+	// starting the same tunnel again succeeds, e.g. on reconnect
+	require.NoError(t, rt.Start(req))
+	require.Len(t, rt.listeners, 1)
+
+	conn, err := net.Dial("tcp", rt.listeners["rt1"].Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, "rt1", <-opened)
+
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(server, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+
+	_, err = server.Write([]byte("pong"))
+	require.NoError(t, err)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "pong", string(buf))
+
+	addr := rt.listeners["rt1"].Addr().String()
+	rt.Stop("rt1")
+	assert.Empty(t, rt.listeners)
+	_, err = net.Dial("tcp", addr)
+	assert.Error(t, err)
+}
+
+func TestStartReverseTunnel(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Config        clientconfig.ReverseTunnelConfig
+		LocalHost     string
+		ExpectedError string
+	}{
+		{
+			Name:          "disabled",
+			Config:        clientconfig.ReverseTunnelConfig{Enabled: false, ListenHosts: []string{"127.0.0.1"}},
+			LocalHost:     "127.0.0.1",
+			ExpectedError: "reverse tunnels are disabled",
+		},
+		{
+			Name:          "host not allowed",
+			Config:        clientconfig.ReverseTunnelConfig{Enabled: true, ListenHosts: []string{"127.0.0.1"}},
+			LocalHost:     "0.0.0.0",
+			ExpectedError: `listening on "0.0.0.0" is not allowed based on "listen_hosts" config: [127.0.0.1]`,
+		},
+		{
+			Name:      "allowed",
+			Config:    clientconfig.ReverseTunnelConfig{Enabled: true, ListenHosts: []string{"127.0.0.1"}},
+			LocalHost: "127.0.0.1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			c := &Client{
+				configHolder: &ClientConfigHolder{
+					Config: &clientconfig.Config{ReverseTunnels: tc.Config},
+				},
+				Logger: testLog,
+			}
+			c.reverseTunnels = newReverseTunnels(testLog, c.openReverseTunnelChannel)
+			defer c.reverseTunnels.Close()
+
+			payload, err := json.Marshal(comm.StartReverseTunnelRequest{ID: "rt1", LocalHost: tc.LocalHost, LocalPort: "0"})
+			require.NoError(t, err)
+
+			err = c.startReverseTunnel(payload)
+			if tc.ExpectedError != "" {
+				assert.EqualError(t, err, tc.ExpectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			payload, err = json.Marshal(comm.StopReverseTunnelRequest{ID: "rt1"})
+			require.NoError(t, err)
+			require.NoError(t, c.stopReverseTunnel(payload))
+			assert.Empty(t, c.reverseTunnels.listeners)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/realvnc-labs/rport/share/comm"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+// maxLastLogins is the number of entries of the login history sent to the server.
+const maxLastLogins = 20
+
+// Snapshot collects the security snapshot periodically and sends it to the server.
+type Snapshot struct {
+	// mtx protects both conn and snapshot
+	mtx      sync.RWMutex
+	conn     ssh.Conn
+	snapshot *models.SecuritySnapshot
+
+	interval time.Duration
+	logger   *logger.Logger
+	stopFn   context.CancelFunc
+
+	// collect is replaced in tests
+	collect func(ctx context.Context) *models.SecuritySnapshot
+}
+
+func New(logger *logger.Logger, interval time.Duration) *Snapshot {
+	return &Snapshot{
+		interval: interval,
+		logger:   logger,
+		collect:  collect,
+	}
+}
+
+func (s *Snapshot) Start(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+
+	ctx, s.stopFn = context.WithCancel(ctx)
+
+	go s.refreshLoop(ctx)
+}
+
+func (s *Snapshot) Stop() {
+	if s.stopFn == nil {
+		return
+	}
+
+	s.stopFn()
+}
+
+func (s *Snapshot) refreshLoop(ctx context.Context) {
+	tick := time.NewTicker(s.interval)
+	defer tick.Stop()
+	for {
+		s.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			s.logger.Debugf("security snapshot refreshLoop finished")
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+func (s *Snapshot) refresh(ctx context.Context) {
+	snapshot := s.collect(ctx)
+	snapshot.Refreshed = time.Now()
+	for _, err := range snapshot.Errors {
+		s.logger.Infof("Collecting security snapshot: %s", err)
+	}
+	s.logger.Debugf("Security snapshot refreshed, %d logged in users", len(snapshot.LoggedInUsers))
+
+	s.mtx.Lock()
+	s.snapshot = snapshot
+	s.mtx.Unlock()
+
+	go s.send()
+}
+
+// send sends the snapshot in background, it's called both after the snapshot is refreshed or conn set
+func (s *Snapshot) send() {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	if s.conn == nil || s.snapshot == nil {
+		return
+	}
+
+	data, err := json.Marshal(s.snapshot)
+	if err != nil {
+		s.logger.Errorf("Could not marshal json for security snapshot: %v", err)
+		return
+	}
+
+	_, _, err = s.conn.SendRequest(comm.RequestTypeSecuritySnapshot, false, data)
+	if err != nil {
+		s.logger.Errorf("Could not send security snapshot: %v", err)
+	}
+}
+
+func (s *Snapshot) SetConn(c ssh.Conn) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.conn = c
+	go s.send()
+}
+
+// collect gathers the parts of the snapshot, a part that fails is reported as error without failing the others.
+func collect(ctx context.Context) *models.SecuritySnapshot {
+	snapshot := &models.SecuritySnapshot{}
+
+	users, err := loggedInUsers(ctx)
+	if err != nil {
+		snapshot.Errors = append(snapshot.Errors, "logged in users: "+err.Error())
+	}
+	snapshot.LoggedInUsers = users
+
+	lastLogins, err := lastLogins(maxLastLogins)
+	if err != nil {
+		snapshot.Errors = append(snapshot.Errors, "last logins: "+err.Error())
+	}
+	snapshot.LastLogins = lastLogins
+
+	sshd, err := readSSHDSummary(sshdConfigFiles)
+	if err != nil {
+		snapshot.Errors = append(snapshot.Errors, "sshd config: "+err.Error())
+	}
+	snapshot.SSHD = sshd
+
+	return snapshot
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+// +build !windows
+
+package security
+
+import (
+	"context"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/host"
+
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+var sshdConfigFiles = []string{"/etc/ssh/sshd_config", "/usr/local/etc/ssh/sshd_config"}
+
+func loggedInUsers(ctx context.Context) ([]models.UserLogin, error) {
+	users, err := host.UsersWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]models.UserLogin, 0, len(users))
+	for _, u := range users {
+		res = append(res, models.UserLogin{
+			User:     u.User,
+			Terminal: u.Terminal,
+			Host:     u.Host,
+			Started:  time.Unix(int64(u.Started), 0),
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,17 @@
+//go:build windows
+// +build windows
+
+package security
+
+import (
+	"context"
+
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+var sshdConfigFiles = []string{`C:\ProgramData\ssh\sshd_config`}
+
+// loggedInUsers is not supported on windows, the sessions are not available without running commands.
+func loggedInUsers(ctx context.Context) ([]models.UserLogin, error) {
+	return nil, nil
+}
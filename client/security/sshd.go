@@ -0,0 +1,85 @@
+package security
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+// readSSHDSummary reads the first of the given sshd config files that exists, nil is returned if none exists.
+func readSSHDSummary(paths []string) (*models.SSHDSummary, error) {
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		summary, err := parseSSHDConfig(f)
+		if err != nil {
+			return nil, err
+		}
+		summary.ConfigFile = path
+		return summary, nil
+	}
+	return nil, nil
+}
+
+// parseSSHDConfig reads the settings of the global section, Match blocks and included files are ignored. Like sshd it
+// uses the first value of a keyword, only Port, ListenAddress, AllowUsers and AllowGroups accumulate their values.
+func parseSSHDConfig(r io.Reader) (*models.SSHDSummary, error) {
+	summary := &models.SSHDSummary{}
+	first := map[string]*string{
+		"permitrootlogin":        &summary.PermitRootLogin,
+		"passwordauthentication": &summary.PasswordAuthentication,
+		"pubkeyauthentication":   &summary.PubkeyAuthentication,
+		"permitemptypasswords":   &summary.PermitEmptyPasswords,
+		"x11forwarding":          &summary.X11Forwarding,
+		"maxauthtries":           &summary.MaxAuthTries,
+	}
+	accumulated := map[string]*[]string{
+		"port":          &summary.Ports,
+		"listenaddress": &summary.ListenAddresses,
+		"allowusers":    &summary.AllowUsers,
+		"allowgroups":   &summary.AllowGroups,
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keyword, value := splitSSHDConfigLine(line)
+		keyword = strings.ToLower(keyword)
+		if keyword == "match" {
+			break
+		}
+		if v, ok := first[keyword]; ok && *v == "" {
+			*v = value
+		}
+		if v, ok := accumulated[keyword]; ok {
+			*v = append(*v, strings.Fields(value)...)
+		}
+	}
+	return summary, scanner.Err()
+}
+
+// splitSSHDConfigLine splits a line into keyword and value, they are separated by whitespace or an equal sign.
+func splitSSHDConfigLine(line string) (string, string) {
+	i := strings.IndexAny(line, " \t=")
+	if i < 0 {
+		return line, ""
+	}
+	value := strings.TrimLeft(line[i:], " \t")
+	value = strings.TrimPrefix(value, "=")
+	return line[:i], strings.Trim(strings.TrimSpace(value), `"`)
+}
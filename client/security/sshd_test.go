@@ -0,0 +1,60 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func TestParseSSHDConfig(t *testing.T) {
+	config := `
+# comment
+Port 22
+Port=2222
+ListenAddress 0.0.0.0
+permitRootLogin prohibit-password
+PermitRootLogin yes
+PasswordAuthentication   no
+X11Forwarding = "yes"
+AllowUsers alice bob
+AllowUsers carol
+MaxAuthTries 3
+
+Match User deploy
+	PasswordAuthentication yes
+	AllowUsers deploy
+`
+	got, err := parseSSHDConfig(strings.NewReader(config))
+	require.NoError(t, err)
+	assert.Equal(t, &models.SSHDSummary{
+		Ports:                  []string{"22", "2222"},
+		ListenAddresses:        []string{"0.0.0.0"},
+		PermitRootLogin:        "prohibit-password",
+		PasswordAuthentication: "no",
+		X11Forwarding:          "yes",
+		MaxAuthTries:           "3",
+		AllowUsers:             []string{"alice", "bob", "carol"},
+	}, got)
+}
+
+func TestReadSSHDSummary(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sshd_config")
+	require.NoError(t, os.WriteFile(path, []byte("PermitRootLogin no\n"), 0600))
+
+	got, err := readSSHDSummary([]string{filepath.Join(dir, "missing"), path})
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, path, got.ConfigFile)
+	assert.Equal(t, "no", got.PermitRootLogin)
+
+	got, err = readSSHDSummary([]string{filepath.Join(dir, "missing")})
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
@@ -0,0 +1,85 @@
+//go:build linux
+// +build linux
+
+package security
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+const (
+	wtmpFile = "/var/log/wtmp"
+	// wtmpRecordSize is the size of struct utmp of glibc, it's the same on 32 and 64 bit systems
+	wtmpRecordSize = 384
+	// wtmpMaxScan limits the records read from the end of the file to keep the snapshot lightweight
+	wtmpMaxScan = 10000
+	// utmpUserProcess is the type of records of user logins
+	utmpUserProcess = 7
+)
+
+// lastLogins returns the latest user logins of the wtmp file, the latest first.
+func lastLogins(max int) ([]models.UserLogin, error) {
+	return readWtmp(wtmpFile, max)
+}
+
+func readWtmp(path string, max int) ([]models.UserLogin, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	res := []models.UserLogin{}
+	record := make([]byte, wtmpRecordSize)
+	count := info.Size() / wtmpRecordSize
+	for i := count - 1; i >= 0 && count-i <= wtmpMaxScan && len(res) < max; i-- {
+		if _, err := f.ReadAt(record, i*wtmpRecordSize); err != nil {
+			return nil, err
+		}
+		if login, ok := parseWtmpRecord(record); ok {
+			res = append(res, login)
+		}
+	}
+	return res, nil
+}
+
+// parseWtmpRecord parses a struct utmp, only records of user logins are returned. The byte order is little endian as on
+// the platforms the client is built for.
+func parseWtmpRecord(b []byte) (models.UserLogin, bool) {
+	if binary.LittleEndian.Uint16(b[0:2]) != utmpUserProcess {
+		return models.UserLogin{}, false
+	}
+	user := cString(b[44:76])
+	if user == "" {
+		return models.UserLogin{}, false
+	}
+	sec := int32(binary.LittleEndian.Uint32(b[340:344]))
+	return models.UserLogin{
+		User:     user,
+		Terminal: cString(b[8:40]),
+		Host:     cString(b[76:332]),
+		Started:  time.Unix(int64(sec), 0),
+	}, true
+}
+
+func cString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
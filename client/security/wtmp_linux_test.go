@@ -0,0 +1,55 @@
+//go:build linux
+// +build linux
+
+package security
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func wtmpRecord(typ uint16, user, line, host string, started time.Time) []byte {
+	b := make([]byte, wtmpRecordSize)
+	binary.LittleEndian.PutUint16(b[0:2], typ)
+	copy(b[8:40], line)
+	copy(b[44:76], user)
+	copy(b[76:332], host)
+	binary.LittleEndian.PutUint32(b[340:344], uint32(started.Unix()))
+	return b
+}
+
+func TestReadWtmp(t *testing.T) {
+	t1 := time.Unix(1700000000, 0)
+	var data []byte
+	data = append(data, wtmpRecord(utmpUserProcess, "alice", "pts/0", "10.0.0.1", t1)...)
+	data = append(data, wtmpRecord(8, "", "pts/0", "", t1.Add(time.Minute))...) // logout
+	data = append(data, wtmpRecord(2, "reboot", "~", "", t1.Add(2*time.Minute))...)
+	data = append(data, wtmpRecord(utmpUserProcess, "bob", "tty1", "", t1.Add(3*time.Minute))...)
+	data = append(data, wtmpRecord(utmpUserProcess, "root", "pts/1", "192.168.1.5", t1.Add(4*time.Minute))...)
+
+	path := filepath.Join(t.TempDir(), "wtmp")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	got, err := readWtmp(path, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []models.UserLogin{
+		{User: "root", Terminal: "pts/1", Host: "192.168.1.5", Started: t1.Add(4 * time.Minute)},
+		{User: "bob", Terminal: "tty1", Started: t1.Add(3 * time.Minute)},
+	}, got)
+
+	got, err = readWtmp(path, maxLastLogins)
+	require.NoError(t, err)
+	assert.Len(t, got, 3)
+
+	got, err = readWtmp(filepath.Join(t.TempDir(), "missing"), maxLastLogins)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package security
+
+import "github.com/realvnc-labs/rport/share/models"
+
+// lastLogins is only supported on linux, nil is returned on other os.
+func lastLogins(max int) ([]models.UserLogin, error) {
+	return nil, nil
+}
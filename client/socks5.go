@@ -0,0 +1,226 @@
+package chclient
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/jpillora/sizestr"
+
+	chshare "github.com/realvnc-labs/rport/share"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+// SOCKS5 protocol values, see RFC 1928. Only the CONNECT command without authentication is supported, the tunnel
+// itself is authenticated by the server.
+const (
+	socks5Version = 0x05
+
+	socks5AuthNone         = 0x00
+	socks5AuthNoAcceptable = 0xff
+
+	socks5CmdConnect = 0x01
+
+	socks5AtypIPv4   = 0x01
+	socks5AtypDomain = 0x03
+	socks5AtypIPv6   = 0x04
+
+	socks5RepSucceeded        = 0x00
+	socks5RepGeneralFailure   = 0x01
+	socks5RepNotAllowed       = 0x02
+	socks5RepHostUnreachable  = 0x04
+	socks5RepCmdNotSupported  = 0x07
+	socks5RepAtypNotSupported = 0x08
+)
+
+const socks5DialTimeout = 10 * time.Second
+
+var errSOCKS5NoAcceptableAuth = errors.New("no acceptable authentication method")
+
+// socks5Handler serves a SOCKS5 proxy on the connections of a SOCKS5 tunnel, so one tunnel reaches any host in the
+// network of the client. The destinations are checked against the "tunnel_allowed" config. Unlike other tunnels, the
+// destination is not known when the tunnel is opened, so the check is done here for each connection.
+type socks5Handler struct {
+	*logger.Logger
+	connStats     *chshare.ConnStats
+	tunnelAllowed []string
+	lookupIP      func(host string) ([]net.IP, error)
+	dial          func(network, addr string, timeout time.Duration) (net.Conn, error)
+}
+
+func newSOCKS5Handler(logger *logger.Logger, connStats *chshare.ConnStats, tunnelAllowed []string) *socks5Handler {
+	return &socks5Handler{
+		Logger:        logger,
+		connStats:     connStats,
+		tunnelAllowed: tunnelAllowed,
+		lookupIP:      net.LookupIP,
+		dial:          net.DialTimeout,
+	}
+}
+
+func (h *socks5Handler) Handle(stream io.ReadWriteCloser) error {
+	defer stream.Close()
+
+	if err := h.negotiateAuth(stream); err != nil {
+		return err
+	}
+
+	dst, err := h.connect(stream)
+	if err != nil {
+		return err
+	}
+
+	h.connStats.Open()
+	h.Debugf("%s: Open %s", h.connStats, dst.RemoteAddr())
+	s, r := chshare.Pipe(stream, dst)
+	h.connStats.Close()
+	h.Debugf("%s: Close (sent %s received %s)", h.connStats, sizestr.ToString(s), sizestr.ToString(r))
+	return nil
+}
+
+func (h *socks5Handler) negotiateAuth(stream io.ReadWriter) error {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(stream, header); err != nil {
+		return fmt.Errorf("failed to read greeting: %w", err)
+	}
+	if header[0] != socks5Version {
+		return fmt.Errorf("unsupported SOCKS version %d", header[0])
+	}
+
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(stream, methods); err != nil {
+		return fmt.Errorf("failed to read authentication methods: %w", err)
+	}
+	for _, m := range methods {
+		if m == socks5AuthNone {
+			_, err := stream.Write([]byte{socks5Version, socks5AuthNone})
+			return err
+		}
+	}
+
+	_, _ = stream.Write([]byte{socks5Version, socks5AuthNoAcceptable})
+	return errSOCKS5NoAcceptableAuth
+}
+
+// connect reads the request and returns the connection to the requested destination.
+func (h *socks5Handler) connect(stream io.ReadWriter) (net.Conn, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(stream, header); err != nil {
+		return nil, fmt.Errorf("failed to read request: %w", err)
+	}
+	if header[0] != socks5Version {
+		return nil, fmt.Errorf("unsupported SOCKS version %d", header[0])
+	}
+
+	addr, rep, err := readSOCKS5Addr(stream, header[3])
+	if err != nil {
+		_ = writeSOCKS5Reply(stream, rep, nil)
+		return nil, err
+	}
+	if header[1] != socks5CmdConnect {
+		_ = writeSOCKS5Reply(stream, socks5RepCmdNotSupported, nil)
+		return nil, fmt.Errorf("unsupported SOCKS command %d", header[1])
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		_ = writeSOCKS5Reply(stream, socks5RepGeneralFailure, nil)
+		return nil, err
+	}
+	// the host is resolved only once and the checked addresses are dialed, so a domain can't resolve to an allowed
+	// address for the check and to a different one for the connection
+	ips, err := h.lookupIP(host)
+	if err != nil {
+		_ = writeSOCKS5Reply(stream, socks5RepHostUnreachable, nil)
+		return nil, fmt.Errorf("failed to resolve %q: %w", host, err)
+	}
+
+	allowed, err := ipsAreAllowed(h.tunnelAllowed, ips, port)
+	if err != nil {
+		h.Errorf("Could not check if %q is allowed: %v", addr, err)
+	}
+	if !allowed {
+		_ = writeSOCKS5Reply(stream, socks5RepNotAllowed, nil)
+		return nil, fmt.Errorf(`connection to %q not allowed with "tunnel_allowed" config: %v`, addr, h.tunnelAllowed)
+	}
+
+	var dst net.Conn
+	for _, ip := range ips {
+		dst, err = h.dial("tcp", net.JoinHostPort(ip.String(), port), socks5DialTimeout)
+		if err == nil {
+			break
+		}
+	}
+	if dst == nil {
+		_ = writeSOCKS5Reply(stream, socks5RepHostUnreachable, nil)
+		return nil, fmt.Errorf("failed to connect to %q: %w", addr, err)
+	}
+
+	if err := writeSOCKS5Reply(stream, socks5RepSucceeded, dst.LocalAddr()); err != nil {
+		dst.Close()
+		return nil, err
+	}
+	return dst, nil
+}
+
+// readSOCKS5Addr returns the "host:port" destination of a request, on error the reply code to send.
+func readSOCKS5Addr(r io.Reader, atyp byte) (string, byte, error) {
+	var host string
+	switch atyp {
+	case socks5AtypIPv4, socks5AtypIPv6:
+		size := net.IPv4len
+		if atyp == socks5AtypIPv6 {
+			size = net.IPv6len
+		}
+		ip := make([]byte, size)
+		if _, err := io.ReadFull(r, ip); err != nil {
+			return "", socks5RepGeneralFailure, err
+		}
+		host = net.IP(ip).String()
+	case socks5AtypDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(r, length); err != nil {
+			return "", socks5RepGeneralFailure, err
+		}
+		domain := make([]byte, length[0])
+		if _, err := io.ReadFull(r, domain); err != nil {
+			return "", socks5RepGeneralFailure, err
+		}
+		host = string(domain)
+	default:
+		return "", socks5RepAtypNotSupported, fmt.Errorf("unsupported address type %d", atyp)
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(r, port); err != nil {
+		return "", socks5RepGeneralFailure, err
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), 0, nil
+}
+
+// writeSOCKS5Reply sends the reply with the bound address, an empty ipv4 address is sent if it's not known.
+func writeSOCKS5Reply(w io.Writer, rep byte, bound net.Addr) error {
+	ip := net.IPv4zero.To4()
+	port := 0
+	if tcpAddr, ok := bound.(*net.TCPAddr); ok {
+		ip = tcpAddr.IP
+		port = tcpAddr.Port
+	}
+
+	atyp := byte(socks5AtypIPv4)
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	} else {
+		atyp = socks5AtypIPv6
+		ip = ip.To16()
+	}
+
+	reply := append([]byte{socks5Version, rep, 0x00, atyp}, ip...)
+	reply = binary.BigEndian.AppendUint16(reply, uint16(port))
+	_, err := w.Write(reply)
+	return err
+}
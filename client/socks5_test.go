@@ -0,0 +1,222 @@
+package chclient
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chshare "github.com/realvnc-labs/rport/share"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+func newEchoServer(t *testing.T) *net.TCPAddr {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return l.Addr().(*net.TCPAddr)
+}
+
+func socks5ConnectRequest(atyp byte, addr []byte, port int) []byte {
+	req := append([]byte{socks5Version, socks5CmdConnect, 0x00, atyp}, addr...)
+	return binary.BigEndian.AppendUint16(req, uint16(port))
+}
+
+func TestSOCKS5Handler(t *testing.T) {
+	echoAddr := newEchoServer(t)
+	testLog := logger.NewLogger("socks5-test", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+
+	testCases := []struct {
+		name          string
+		tunnelAllowed []string
+		auth          []byte
+		request       []byte
+		wantAuthReply []byte
+		wantRep       byte
+	}{
+		{
+			name:          "connect ipv4",
+			auth:          []byte{socks5Version, 1, socks5AuthNone},
+			request:       socks5ConnectRequest(socks5AtypIPv4, echoAddr.IP.To4(), echoAddr.Port),
+			wantAuthReply: []byte{socks5Version, socks5AuthNone},
+			wantRep:       socks5RepSucceeded,
+		},
+		{
+			name:          "connect domain",
+			auth:          []byte{socks5Version, 2, 0x02, socks5AuthNone},
+			request:       socks5ConnectRequest(socks5AtypDomain, append([]byte{9}, "localhost"...), echoAddr.Port),
+			wantAuthReply: []byte{socks5Version, socks5AuthNone},
+			wantRep:       socks5RepSucceeded,
+		},
+		{
+			name:          "allowed by tunnel_allowed",
+			tunnelAllowed: []string{"127.0.0.0/8"},
+			auth:          []byte{socks5Version, 1, socks5AuthNone},
+			request:       socks5ConnectRequest(socks5AtypIPv4, echoAddr.IP.To4(), echoAddr.Port),
+			wantAuthReply: []byte{socks5Version, socks5AuthNone},
+			wantRep:       socks5RepSucceeded,
+		},
+		{
+			name:          "not allowed by tunnel_allowed",
+			tunnelAllowed: []string{"10.0.0.0/8"},
+			auth:          []byte{socks5Version, 1, socks5AuthNone},
+			request:       socks5ConnectRequest(socks5AtypIPv4, echoAddr.IP.To4(), echoAddr.Port),
+			wantAuthReply: []byte{socks5Version, socks5AuthNone},
+			wantRep:       socks5RepNotAllowed,
+		},
+		{
+			name:          "unsupported command",
+			auth:          []byte{socks5Version, 1, socks5AuthNone},
+			request:       append([]byte{socks5Version, 0x02, 0x00, socks5AtypIPv4, 127, 0, 0, 1}, 0, 80),
+			wantAuthReply: []byte{socks5Version, socks5AuthNone},
+			wantRep:       socks5RepCmdNotSupported,
+		},
+		{
+			name:          "unsupported address type",
+			auth:          []byte{socks5Version, 1, socks5AuthNone},
+			request:       []byte{socks5Version, socks5CmdConnect, 0x00, 0x05},
+			wantAuthReply: []byte{socks5Version, socks5AuthNone},
+			wantRep:       socks5RepAtypNotSupported,
+		},
+		{
+			name:          "no acceptable auth",
+			auth:          []byte{socks5Version, 1, 0x02},
+			wantAuthReply: []byte{socks5Version, socks5AuthNoAcceptable},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			src, stream := net.Pipe()
+			defer src.Close()
+			h := newSOCKS5Handler(testLog, &chshare.ConnStats{}, tc.tunnelAllowed)
+			done := make(chan error, 1)
+			go func() {
+				done <- h.Handle(stream)
+			}()
+
+			_, err := src.Write(tc.auth)
+			require.NoError(t, err)
+			authReply := make([]byte, 2)
+			_, err = io.ReadFull(src, authReply)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantAuthReply, authReply)
+			if tc.request == nil {
+				assert.Equal(t, errSOCKS5NoAcceptableAuth, <-done)
+				return
+			}
+
+			_, err = src.Write(tc.request)
+			require.NoError(t, err)
+			reply := make([]byte, 10)
+			_, err = io.ReadFull(src, reply)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantRep, reply[1])
+			if tc.wantRep != socks5RepSucceeded {
+				assert.Error(t, <-done)
+				return
+			}
+
+			_, err = src.Write([]byte("ping"))
+			require.NoError(t, err)
+			got := make([]byte, 4)
+			_, err = io.ReadFull(src, got)
+			require.NoError(t, err)
+			assert.Equal(t, "ping", string(got))
+
+			src.Close()
+			assert.NoError(t, <-done)
+		})
+	}
+}
+
+func TestSOCKS5HandlerDialsCheckedAddress(t *testing.T) {
+	echoAddr := newEchoServer(t)
+	testLog := logger.NewLogger("socks5-test", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+
+	testCases := []struct {
+		name     string
+		domain   string
+		wantRep  byte
+		wantDial string
+	}{
+		{
+			name:     "allowed destination",
+			domain:   "allowed.test",
+			wantRep:  socks5RepSucceeded,
+			wantDial: echoAddr.String(),
+		},
+		{
+			name:    "disallowed destination",
+			domain:  "internal.test",
+			wantRep: socks5RepNotAllowed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			src, stream := net.Pipe()
+			defer src.Close()
+			h := newSOCKS5Handler(testLog, &chshare.ConnStats{}, []string{"127.0.0.0/8"})
+			lookups := 0
+			h.lookupIP = func(host string) ([]net.IP, error) {
+				lookups++
+				// a second lookup would resolve to a disallowed address
+				if host == "internal.test" || lookups > 1 {
+					return []net.IP{net.ParseIP("10.0.0.1")}, nil
+				}
+				return []net.IP{echoAddr.IP}, nil
+			}
+			var dialed []string
+			h.dial = func(network, addr string, timeout time.Duration) (net.Conn, error) {
+				dialed = append(dialed, addr)
+				return net.DialTimeout(network, addr, timeout)
+			}
+			done := make(chan error, 1)
+			go func() {
+				done <- h.Handle(stream)
+			}()
+
+			_, err := src.Write([]byte{socks5Version, 1, socks5AuthNone})
+			require.NoError(t, err)
+			authReply := make([]byte, 2)
+			_, err = io.ReadFull(src, authReply)
+			require.NoError(t, err)
+
+			_, err = src.Write(socks5ConnectRequest(socks5AtypDomain, append([]byte{byte(len(tc.domain))}, tc.domain...), echoAddr.Port))
+			require.NoError(t, err)
+			reply := make([]byte, 10)
+			_, err = io.ReadFull(src, reply)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantRep, reply[1])
+
+			src.Close()
+			err = <-done
+			if tc.wantRep != socks5RepSucceeded {
+				assert.Error(t, err)
+				assert.Empty(t, dialed)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, []string{tc.wantDial}, dialed)
+			assert.Equal(t, 1, lookups)
+		})
+	}
+}
@@ -5,6 +5,17 @@ package system
 
 import chshare "github.com/realvnc-labs/rport/share"
 
+var interpreterCandidates = []interpreterCandidate{
+	{name: "sh"},
+	{name: "bash", versionArgs: []string{"--version"}},
+	{name: "zsh", versionArgs: []string{"--version"}},
+	{name: "pwsh", versionArgs: []string{"--version"}},
+	{name: "python3", versionArgs: []string{"--version"}},
+	{name: "python", versionArgs: []string{"--version"}},
+	{name: "perl", versionArgs: []string{"--version"}},
+	{name: chshare.Tacoscript, versionArgs: []string{"--version"}},
+}
+
 func (i Interpreter) Get() string {
 	if i.InterpreterAliases != nil && i.InterpreterNameFromInput != "" {
 		if mappedInterpreter, ok := i.InterpreterAliases[i.InterpreterNameFromInput]; ok {
@@ -11,6 +11,15 @@ import (
 	chshare "github.com/realvnc-labs/rport/share"
 )
 
+var interpreterCandidates = []interpreterCandidate{
+	{name: chshare.CmdShell, versionArgs: []string{"/c", "ver"}},
+	{name: chshare.PowerShell, versionArgs: []string{"-NoProfile", "-NonInteractive", "-Command", "$PSVersionTable.PSVersion.ToString()"}},
+	{name: "pwsh", versionArgs: []string{"--version"}},
+	{name: chshare.Tacoscript, versionArgs: []string{"--version"}},
+	{name: "python", versionArgs: []string{"--version"}},
+	{name: "bash", versionArgs: []string{"--version"}},
+}
+
 func (i Interpreter) Get() string {
 	interpreterNameFromInput := i.InterpreterNameFromInput
 
@@ -0,0 +1,91 @@
+package system
+
+import (
+	"context"
+	"os/exec"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+const interpreterVersionTimeout = 5 * time.Second
+
+type interpreterCandidate struct {
+	name string
+	// versionArgs print the version of the interpreter, the version is not detected if empty
+	versionArgs []string
+}
+
+var versionRegex = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// lookPath and interpreterVersionOutput are used to stub the interpreter detection in tests
+var lookPath = exec.LookPath
+
+var interpreterVersionOutput = func(ctx context.Context, path string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, path, args...).CombinedOutput()
+}
+
+// DetectInterpreters returns the known interpreters found in the PATH with their versions
+// and the interpreter aliases pointing to an existing executable.
+func DetectInterpreters(ctx context.Context, aliases map[string]string) []models.Interpreter {
+	found := make([]*models.Interpreter, len(interpreterCandidates))
+	wg := sync.WaitGroup{}
+	for i, candidate := range interpreterCandidates {
+		path, err := lookPath(candidate.name)
+		if err != nil {
+			continue
+		}
+		found[i] = &models.Interpreter{
+			Name: candidate.name,
+			Path: path,
+		}
+		if len(candidate.versionArgs) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(interpreter *models.Interpreter, versionArgs []string) {
+			defer wg.Done()
+			interpreter.Version = interpreterVersion(ctx, interpreter.Path, versionArgs)
+		}(found[i], candidate.versionArgs)
+	}
+	wg.Wait()
+
+	interpreters := make([]models.Interpreter, 0, len(found)+len(aliases))
+	for _, interpreter := range found {
+		if interpreter != nil {
+			interpreters = append(interpreters, *interpreter)
+		}
+	}
+
+	aliasInterpreters := make([]models.Interpreter, 0, len(aliases))
+	for alias, value := range aliases {
+		path, err := lookPath(value)
+		if err != nil {
+			continue
+		}
+		aliasInterpreters = append(aliasInterpreters, models.Interpreter{
+			Name:  alias,
+			Path:  path,
+			Alias: true,
+		})
+	}
+	sort.Slice(aliasInterpreters, func(i, j int) bool {
+		return aliasInterpreters[i].Name < aliasInterpreters[j].Name
+	})
+
+	return append(interpreters, aliasInterpreters...)
+}
+
+func interpreterVersion(ctx context.Context, path string, versionArgs []string) string {
+	ctx, cancel := context.WithTimeout(ctx, interpreterVersionTimeout)
+	defer cancel()
+
+	out, err := interpreterVersionOutput(ctx, path, versionArgs...)
+	if err != nil {
+		return ""
+	}
+	return versionRegex.FindString(string(out))
+}
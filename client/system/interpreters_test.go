@@ -0,0 +1,67 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func TestDetectInterpreters(t *testing.T) {
+	defer func(candidates []interpreterCandidate) {
+		interpreterCandidates = candidates
+		lookPath = exec.LookPath
+	}(interpreterCandidates)
+	defer func(output func(ctx context.Context, path string, args ...string) ([]byte, error)) {
+		interpreterVersionOutput = output
+	}(interpreterVersionOutput)
+
+	interpreterCandidates = []interpreterCandidate{
+		{name: "sh"},
+		{name: "bash", versionArgs: []string{"--version"}},
+		{name: "zsh", versionArgs: []string{"--version"}},
+		{name: "python3", versionArgs: []string{"--version"}},
+		{name: "perl", versionArgs: []string{"--version"}},
+	}
+	paths := map[string]string{
+		"sh":                  "/usr/bin/sh",
+		"bash":                "/usr/bin/bash",
+		"python3":             "/usr/bin/python3",
+		"perl":                "/usr/bin/perl",
+		"/opt/py3.11/python3": "/opt/py3.11/python3",
+	}
+	lookPath = func(file string) (string, error) {
+		if path, ok := paths[file]; ok {
+			return path, nil
+		}
+		return "", exec.ErrNotFound
+	}
+	outputs := map[string]string{
+		"/usr/bin/bash":    "GNU bash, version 5.1.16(1)-release (x86_64-pc-linux-gnu)\nCopyright (C) 2020 Free Software Foundation, Inc.",
+		"/usr/bin/python3": "Python 3.10.12",
+	}
+	interpreterVersionOutput = func(ctx context.Context, path string, args ...string) ([]byte, error) {
+		assert.Equal(t, []string{"--version"}, args)
+		if out, ok := outputs[path]; ok {
+			return []byte(out), nil
+		}
+		return nil, errors.New("exit status 1")
+	}
+
+	interpreters := DetectInterpreters(context.Background(), map[string]string{
+		"py311":   "/opt/py3.11/python3",
+		"missing": "/opt/missing/bin/ruby",
+	})
+
+	assert.Equal(t, []models.Interpreter{
+		{Name: "sh", Path: "/usr/bin/sh"},
+		{Name: "bash", Path: "/usr/bin/bash", Version: "5.1.16"},
+		{Name: "python3", Path: "/usr/bin/python3", Version: "3.10.12"},
+		{Name: "perl", Path: "/usr/bin/perl"},
+		{Name: "py311", Path: "/opt/py3.11/python3", Alias: true},
+	}, interpreters)
+}
@@ -25,6 +25,15 @@ func TunnelIsAllowed(tunnelAllowed []string, remote string) (bool, error) {
 		return false, err
 	}
 
+	return ipsAreAllowed(tunnelAllowed, ips, remotePort)
+}
+
+// ipsAreAllowed checks already resolved addresses, all of them must be allowed.
+func ipsAreAllowed(tunnelAllowed []string, ips []net.IP, remotePort string) (bool, error) {
+	if len(tunnelAllowed) == 0 {
+		return true, nil
+	}
+
 iploop:
 	for _, ip := range ips {
 		for _, ta := range tunnelAllowed {
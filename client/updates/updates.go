@@ -30,6 +30,7 @@ type Updates struct {
 
 	pkgMgr PackageManager
 	logger *logger.Logger
+	stopFn context.CancelFunc
 }
 
 func New(logger *logger.Logger, interval time.Duration) *Updates {
@@ -45,9 +46,19 @@ func (u *Updates) Start(ctx context.Context) {
 		return
 	}
 
+	ctx, u.stopFn = context.WithCancel(ctx)
+
 	go u.refreshLoop(ctx)
 }
 
+func (u *Updates) Stop() {
+	if u.stopFn == nil {
+		return
+	}
+
+	u.stopFn()
+}
+
 func (u *Updates) getPackageManager(ctx context.Context) PackageManager {
 	if u.pkgMgr != nil {
 		return u.pkgMgr
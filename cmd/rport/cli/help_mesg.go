@@ -132,6 +132,14 @@ var ClientHelp = `
     --updates-interval, How often after the rport client has started pending updates are summarized.
     Defaults: 4h
 
+    --security-snapshot-interval, How often the logged in users, last logins and sshd settings are reported.
+    Set 0 to disable.
+    Defaults: 1h
+
+    --mode, Set to "checkin" to only keep the connection to the server for inventory and monitoring.
+    Tunnels, commands, scripts and file uploads are rejected until the server switches the client to "full".
+    Defaults: full
+
     --fallback-server, Set fallback server(s) to which the client tries to connect if the main server is not reachable.
 
     --server-switchback-interval, If connected to fallback server, try every interval to switch back to the main server.
@@ -23,10 +23,12 @@ func BindPFlagsToViperConfig(pFlags *pflag.FlagSet, viperCfg *viper.Viper) {
 	_ = viperCfg.BindPFlag("client.tags", pFlags.Lookup("tag"))
 	_ = viperCfg.BindPFlag("client.allow_root", pFlags.Lookup("allow-root"))
 	_ = viperCfg.BindPFlag("client.updates_interval", pFlags.Lookup("updates-interval"))
+	_ = viperCfg.BindPFlag("client.security_snapshot_interval", pFlags.Lookup("security-snapshot-interval"))
 	_ = viperCfg.BindPFlag("client.fallback_servers", pFlags.Lookup("fallback-server"))
 	_ = viperCfg.BindPFlag("client.server_switchback_interval", pFlags.Lookup("server-switchback-interval"))
 	_ = viperCfg.BindPFlag("client.data_dir", pFlags.Lookup("data-dir"))
 	_ = viperCfg.BindPFlag("client.bind_interface", pFlags.Lookup("bind-interface"))
+	_ = viperCfg.BindPFlag("client.mode", pFlags.Lookup("mode"))
 
 	_ = viperCfg.BindPFlag("logging.log_file", pFlags.Lookup("log-file"))
 	_ = viperCfg.BindPFlag("logging.log_level", pFlags.Lookup("log-level"))
@@ -92,6 +94,7 @@ func SetPFlags(pFlags *pflag.FlagSet) {
 	pFlags.String("data-dir", chclient.DefaultDataDir, "")
 	pFlags.Int("remote-commands-send-back-limit", 0, "")
 	pFlags.Duration("updates-interval", 0, "")
+	pFlags.Duration("security-snapshot-interval", 0, "")
 	pFlags.StringArray("fallback-server", []string{}, "")
 	pFlags.Duration("server-switchback-interval", 0, "")
 	pFlags.Bool("monitoring-enabled", false, "")
@@ -108,6 +111,7 @@ func SetPFlags(pFlags *pflag.FlagSet) {
 	pFlags.StringArray("file-reception-protected", []string{}, "")
 	pFlags.Bool("file-reception-enabled", true, "")
 	pFlags.String("bind-interface", "", "")
+	pFlags.String("mode", "", "")
 }
 
 func SetViperConfigDefaults(viperCfg *viper.Viper) {
@@ -125,8 +129,17 @@ func SetViperConfigDefaults(viperCfg *viper.Viper) {
 	viperCfg.SetDefault("remote-commands.enabled", true)
 	viperCfg.SetDefault("remote-scripts.enabled", false)
 
+	viperCfg.SetDefault("packet-capture.enabled", false)
+	viperCfg.SetDefault("packet-capture.tcpdump", "tcpdump")
+	viperCfg.SetDefault("packet-capture.max_duration", 5*time.Minute)
+	viperCfg.SetDefault("packet-capture.max_bytes", 50*1024*1024)
+
+	viperCfg.SetDefault("reverse-tunnels.enabled", false)
+	viperCfg.SetDefault("reverse-tunnels.listen_hosts", []string{"127.0.0.1"})
+
 	viperCfg.SetDefault("client.server_switchback_interval", 2*time.Minute)
 	viperCfg.SetDefault("client.updates_interval", 4*time.Hour)
+	viperCfg.SetDefault("client.security_snapshot_interval", time.Hour)
 	viperCfg.SetDefault("client.data_dir", chclient.DefaultDataDir)
 	viperCfg.SetDefault("client.attributes_file_path", "")
 
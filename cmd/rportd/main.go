@@ -10,7 +10,6 @@ import (
 	"runtime/pprof"
 	"strings"
 	"syscall"
-	"time"
 
 	"github.com/realvnc-labs/rport/cmd/rportd/servicemanagement"
 	"github.com/realvnc-labs/rport/share/logger"
@@ -20,8 +19,6 @@ import (
 	"github.com/spf13/viper"
 
 	chserver "github.com/realvnc-labs/rport/server"
-	"github.com/realvnc-labs/rport/server/api/message"
-	auditlog "github.com/realvnc-labs/rport/server/auditlog/config"
 	"github.com/realvnc-labs/rport/server/chconfig"
 	chshare "github.com/realvnc-labs/rport/share"
 	"github.com/realvnc-labs/rport/share/files"
@@ -31,35 +28,10 @@ const (
 	writeCPUProfile    = false
 	writeMemoryProfile = false
 
-	DefaultKeepDisconnectedClients          = time.Hour
-	DefaultPurgeDisconnectedClientsInterval = 1 * time.Minute
-	DefaultCheckClientsConnectionInterval   = 5 * time.Minute
-	DefaultCheckClientsConnectionTimeout    = 30 * time.Second
-	DefaultMaxRequestBytes                  = 10 * 1024       // 10 KB
-	DefaultMaxRequestBytesClient            = 512 * 1024      // 512KB
-	DefaultMaxFilePushBytes                 = int64(10 << 20) // 10M
-	DefaultCheckPortTimeout                 = 2 * time.Second
-	DefaultUsedPorts                        = "20000-30000"
-	DefaultExcludedPorts                    = "1-1024"
-	DefaultServerAddress                    = "0.0.0.0:8080"
-	DefaultLogLevel                         = "info"
-	DefaultRunRemoteCmdTimeoutSec           = 60
-	DefaultMonitoringDataStorageDuration    = "7d"
-	DefaultPairingURL                       = "https://pairing.rport.io"
+	// EnvPrefix is the prefix of env vars to configure rportd, e.g. RPORTD_API_ADDRESS
+	EnvPrefix = "RPORTD"
 )
 
-var (
-	DefaultMaxConcurrentSSHConnectionHandshakes = calcMaxConcurrentSSHConnectionHandshakes()
-)
-
-func calcMaxConcurrentSSHConnectionHandshakes() (max int) {
-	maxProcs := runtime.GOMAXPROCS(0)
-	if maxProcs == 1 {
-		return maxProcs
-	}
-	return maxProcs / 2
-}
-
 var serverHelp = `
   Usage: rportd [options]
 
@@ -78,6 +50,9 @@ var serverHelp = `
     ./rportd user
     commands for user management, run './rportd user help' for more options
 
+    ./rportd systemd -c /etc/rport/rportd.conf --socket-activation
+    prints systemd units to run rportd with socket activation, run './rportd systemd --help' for more options
+
   Options:
 
     --addr, -a, Defines the IP address and port the HTTP server listens on.
@@ -132,6 +107,10 @@ var serverHelp = `
     You can use the client-auth-id as client-id to slim down the client configuration.
     Defaults: false
 
+    --client-id-policy, Defines how the server derives the id of a connecting client.
+    Either "client", "machine_id_hash", "hostname" or "uuid".
+    Defaults: client
+
     --proxy, Specifies another HTTP server to proxy requests to when
     rportd receives a normal HTTP request. Useful for hiding rportd in
     plain sight.
@@ -245,6 +224,13 @@ var serverHelp = `
 
     --version, Print version info and exit
 
+  Environment variables:
+    Every option of the config file can be set by an env var RPORTD_<SECTION>_<KEY> in upper case,
+    e.g. RPORTD_API_JWT_SECRET sets "jwt_secret" in the [api] section. Dashes are replaced by underscores.
+    Append __FILE to read the value from a file, e.g. RPORTD_API_JWT_SECRET__FILE=/run/secrets/jwt_secret.
+    Options that are maps are given as JSON objects.
+    Precedence: command arguments, env variables, config file, defaults.
+
   Signals:
     The rportd process is listening for SIGUSR2 to print process stats
 
@@ -308,6 +294,7 @@ func init() {
 	lFlags.Bool("auth-write", false, "")
 	lFlags.Bool("auth-multiuse-creds", false, "")
 	lFlags.Bool("equate-clientauthid-clientid", false, "")
+	lFlags.String("client-id-policy", "", "")
 	lFlags.Int("run-remote-cmd-timeout-sec", 0, "")
 	lFlags.Bool("allow-root", false, "")
 	lFlags.Int64("monitoring-data-storage-days", 0, "")
@@ -331,49 +318,7 @@ func init() {
 	viperCfg = viper.New()
 	viperCfg.SetConfigType("toml")
 
-	viperCfg.SetDefault("logging.log_level", DefaultLogLevel)
-	viperCfg.SetDefault("server.address", DefaultServerAddress)
-	viperCfg.SetDefault("server.used_ports", []string{DefaultUsedPorts})
-	viperCfg.SetDefault("server.excluded_ports", []string{DefaultExcludedPorts})
-	viperCfg.SetDefault("server.data_dir", chserver.DefaultDataDirectory)
-	viperCfg.SetDefault("server.sqlite_wal", true)
-	viperCfg.SetDefault("server.keep_disconnected_clients", DefaultKeepDisconnectedClients)
-	viperCfg.SetDefault("server.max_concurrent_ssh_handshakes", DefaultMaxConcurrentSSHConnectionHandshakes)
-	viperCfg.SetDefault("server.purge_disconnected_clients_interval", DefaultPurgeDisconnectedClientsInterval)
-	viperCfg.SetDefault("server.check_clients_connection_interval", DefaultCheckClientsConnectionInterval)
-	viperCfg.SetDefault("server.check_clients_connection_timeout", DefaultCheckClientsConnectionTimeout)
-	viperCfg.SetDefault("server.max_request_bytes_client", DefaultMaxRequestBytesClient)
-	viperCfg.SetDefault("server.check_port_timeout", DefaultCheckPortTimeout)
-	viperCfg.SetDefault("server.auth_write", true)
-	viperCfg.SetDefault("server.auth_multiuse_creds", true)
-	viperCfg.SetDefault("server.run_remote_cmd_timeout_sec", DefaultRunRemoteCmdTimeoutSec)
-	viperCfg.SetDefault("server.client_login_wait", 2)
-	viperCfg.SetDefault("server.max_failed_login", 5)
-	viperCfg.SetDefault("server.pairing_url", DefaultPairingURL)
-	viperCfg.SetDefault("server.ban_time", 3600)
-	viperCfg.SetDefault("server.jobs_max_results", 10000)
-	viperCfg.SetDefault("server.tls_min", "1.3")
-	viperCfg.SetDefault("api.user_header", "Authentication-User")
-	viperCfg.SetDefault("api.default_user_group", "Administrators")
-	viperCfg.SetDefault("api.user_login_wait", 2)
-	viperCfg.SetDefault("api.max_failed_login", 10)
-	viperCfg.SetDefault("api.ban_time", 600)
-	viperCfg.SetDefault("api.two_fa_token_ttl_seconds", 600)
-	viperCfg.SetDefault("api.two_fa_send_timeout", 10*time.Second)
-	viperCfg.SetDefault("api.two_fa_send_to_type", message.ValidationNone)
-	viperCfg.SetDefault("api.enable_audit_log", true)
-	viperCfg.SetDefault("api.totp_enabled", false)
-	viperCfg.SetDefault("api.audit_log_rotation", auditlog.RotationMonthly)
-	viperCfg.SetDefault("monitoring.data_storage_duration", DefaultMonitoringDataStorageDuration)
-	viperCfg.SetDefault("monitoring.enabled", true)
-	viperCfg.SetDefault("api.max_request_bytes", DefaultMaxRequestBytes)
-	viperCfg.SetDefault("api.max_filepush_size", DefaultMaxFilePushBytes)
-	viperCfg.SetDefault("api.enable_ws_test_endpoints", false)
-	viperCfg.SetDefault("api.totp_login_session_ttl", time.Minute*10)
-	viperCfg.SetDefault("api.totp_account_name", "RPort")
-	viperCfg.SetDefault("api.password_min_length", 14)
-	viperCfg.SetDefault("api.password_zxcvbn_minscore", 0)
-	viperCfg.SetDefault("api.tls_min", "1.3")
+	chserver.SetConfigDefaults(viperCfg)
 }
 
 func bindPFlags() {
@@ -388,6 +333,7 @@ func bindPFlags() {
 	_ = viperCfg.BindPFlag("server.auth_table", pFlags.Lookup("auth-table"))
 	_ = viperCfg.BindPFlag("server.auth_multiuse_creds", pFlags.Lookup("auth-multiuse-creds"))
 	_ = viperCfg.BindPFlag("server.equate_clientauthid_clientid", pFlags.Lookup("equate-clientauthid-clientid"))
+	_ = viperCfg.BindPFlag("server.client_id_policy", pFlags.Lookup("client-id-policy"))
 	_ = viperCfg.BindPFlag("server.auth_write", pFlags.Lookup("auth-write"))
 	_ = viperCfg.BindPFlag("server.proxy", pFlags.Lookup("proxy"))
 	_ = viperCfg.BindPFlag("server.used_ports", pFlags.Lookup("use-ports"))
@@ -443,7 +389,7 @@ func decodeAndValidateConfig(mLog *logger.MemLogger) error {
 		viperCfg.SetConfigName("rportd.conf")
 	}
 
-	if err := chshare.DecodeViperConfig(viperCfg, cfg, nil); err != nil {
+	if err := chshare.DecodeViperConfig(viperCfg, cfg, nil, chshare.WithEnvPrefix(EnvPrefix)); err != nil {
 		return err
 	}
 
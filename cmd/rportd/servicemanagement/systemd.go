@@ -0,0 +1,156 @@
+package servicemanagement
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/template"
+
+	chshare "github.com/realvnc-labs/rport/share"
+)
+
+const (
+	SystemdServiceUnit      = "rportd.service"
+	SystemdServerSocketUnit = "rportd-server.socket"
+	SystemdAPISocketUnit    = "rportd-api.socket"
+)
+
+type SystemdUnitOptions struct {
+	Executable string
+	ConfigPath string
+	User       string
+	// SocketActivation generates socket units, so systemd listens on the client and the API address and passes the
+	// sockets to rportd.
+	SocketActivation bool
+	ServerAddress    string
+	APIAddress       string
+}
+
+var systemdServiceTemplate = template.Must(template.New("service").Parse(`[Unit]
+Description={{.Description}}
+ConditionFileIsExecutable={{.Executable}}
+After=network-online.target{{range .Sockets}} {{.}}{{end}}
+Wants=network-online.target
+{{- range .Sockets}}
+Requires={{.}}
+{{- end}}
+
+[Service]
+ExecStart={{.Executable}} -c {{.ConfigPath}}
+{{- if .User}}
+User={{.User}}
+{{- end}}
+Restart=always
+RestartSec=120
+LimitNOFILE=1048576
+AmbientCapabilities=CAP_NET_BIND_SERVICE
+
+[Install]
+WantedBy=multi-user.target
+`))
+
+var systemdSocketTemplate = template.Must(template.New("socket").Parse(`[Unit]
+Description={{.Description}}
+
+[Socket]
+ListenStream={{.ListenStream}}
+FileDescriptorName={{.Name}}
+Service={{.Service}}
+
+[Install]
+WantedBy=sockets.target
+`))
+
+// GenerateSystemdUnits returns the content of the systemd units to run rportd by their file names.
+func GenerateSystemdUnits(o SystemdUnitOptions) (map[string]string, error) {
+	executable, err := filepath.Abs(o.Executable)
+	if err != nil {
+		return nil, err
+	}
+	configPath, err := filepath.Abs(o.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	units := make(map[string]string)
+	var sockets []string
+	if o.SocketActivation {
+		socketAddrs := map[string]struct {
+			name        string
+			description string
+			address     string
+		}{
+			SystemdServerSocketUnit: {name: chshare.ActivatedServerSocket, description: "client connections", address: o.ServerAddress},
+			SystemdAPISocketUnit:    {name: chshare.ActivatedAPISocket, description: "API", address: o.APIAddress},
+		}
+		for unit, s := range socketAddrs {
+			if s.address == "" {
+				continue
+			}
+			listenStream, err := systemdListenStream(s.address)
+			if err != nil {
+				return nil, err
+			}
+			content, err := execTemplate(systemdSocketTemplate, map[string]string{
+				"Description":  fmt.Sprintf("%s %s socket", svcConfig.DisplayName, s.description),
+				"ListenStream": listenStream,
+				"Name":         s.name,
+				"Service":      SystemdServiceUnit,
+			})
+			if err != nil {
+				return nil, err
+			}
+			units[unit] = content
+			sockets = append(sockets, unit)
+		}
+		sort.Strings(sockets)
+	}
+
+	content, err := execTemplate(systemdServiceTemplate, map[string]interface{}{
+		"Description": svcConfig.DisplayName,
+		"Executable":  executable,
+		"ConfigPath":  configPath,
+		"User":        o.User,
+		"Sockets":     sockets,
+	})
+	if err != nil {
+		return nil, err
+	}
+	units[SystemdServiceUnit] = content
+
+	return units, nil
+}
+
+// WriteSystemdUnits writes the units to the given directory, usually /etc/systemd/system.
+func WriteSystemdUnits(dir string, units map[string]string) error {
+	for name, content := range units {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// systemdListenStream converts a listen address like ":8080" to the format of ListenStream.
+func systemdListenStream(address string) (string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q: %w", address, err)
+	}
+	if host == "" {
+		// listen on all interfaces
+		return port, nil
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+func execTemplate(t *template.Template, data interface{}) (string, error) {
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,111 @@
+package servicemanagement
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSystemdUnits(t *testing.T) {
+	testCases := []struct {
+		name          string
+		options       SystemdUnitOptions
+		wantUnits     []string
+		wantInService []string
+		wantErr       string
+	}{
+		{
+			name: "service only",
+			options: SystemdUnitOptions{
+				Executable: "/usr/local/bin/rportd",
+				ConfigPath: "/etc/rport/rportd.conf",
+				User:       "rport",
+			},
+			wantUnits: []string{SystemdServiceUnit},
+			wantInService: []string{
+				"ExecStart=/usr/local/bin/rportd -c /etc/rport/rportd.conf\n",
+				"User=rport\n",
+			},
+		},
+		{
+			name: "socket activation",
+			options: SystemdUnitOptions{
+				Executable:       "/usr/local/bin/rportd",
+				ConfigPath:       "/etc/rport/rportd.conf",
+				SocketActivation: true,
+				ServerAddress:    ":8080",
+				APIAddress:       "127.0.0.1:3000",
+			},
+			wantUnits: []string{SystemdServiceUnit, SystemdServerSocketUnit, SystemdAPISocketUnit},
+			wantInService: []string{
+				"After=network-online.target rportd-api.socket rportd-server.socket\n",
+				"Requires=rportd-api.socket\nRequires=rportd-server.socket\n",
+			},
+		},
+		{
+			name: "socket activation without api",
+			options: SystemdUnitOptions{
+				Executable:       "/usr/local/bin/rportd",
+				ConfigPath:       "/etc/rport/rportd.conf",
+				SocketActivation: true,
+				ServerAddress:    "0.0.0.0:8080",
+			},
+			wantUnits:     []string{SystemdServiceUnit, SystemdServerSocketUnit},
+			wantInService: []string{"Requires=rportd-server.socket\n"},
+		},
+		{
+			name: "invalid address",
+			options: SystemdUnitOptions{
+				Executable:       "/usr/local/bin/rportd",
+				ConfigPath:       "/etc/rport/rportd.conf",
+				SocketActivation: true,
+				ServerAddress:    "8080",
+			},
+			wantErr: `invalid address "8080": address 8080: missing port in address`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			units, err := GenerateSystemdUnits(tc.options)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(units))
+			for name := range units {
+				names = append(names, name)
+			}
+			assert.ElementsMatch(t, tc.wantUnits, names)
+			for _, want := range tc.wantInService {
+				assert.Contains(t, units[SystemdServiceUnit], want)
+			}
+			assert.NotContains(t, units[SystemdServiceUnit], "\n\n\n")
+		})
+	}
+}
+
+func TestGenerateSystemdSocketUnit(t *testing.T) {
+	units, err := GenerateSystemdUnits(SystemdUnitOptions{
+		Executable:       "/usr/local/bin/rportd",
+		ConfigPath:       "/etc/rport/rportd.conf",
+		SocketActivation: true,
+		ServerAddress:    ":8080",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `[Unit]
+Description=Rport Server client connections socket
+
+[Socket]
+ListenStream=8080
+FileDescriptorName=server
+Service=rportd.service
+
+[Install]
+WantedBy=sockets.target
+`, units[SystemdServerSocketUnit])
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/realvnc-labs/rport/cmd/rportd/simulator"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+var (
+	simulateCmd = &cobra.Command{
+		Use:   "simulate",
+		Short: "simulate clients to load test a server",
+		Long: `Spawn fake clients speaking the real client protocol with configurable churn, tunnels and job responses.
+Simulated clients echo the data of every tunnel and answer commands and scripts without executing them.
+The server must accept the given client credentials for multiple clients (server.auth_multiuse_creds).`,
+		Example: "rportd simulate --server 127.0.0.1:8080 --auth clientAuth1:1234 --clients 10000 --ramp-up 5m --lifetime 1h --tunnel 22",
+		Run: func(*cobra.Command, []string) {
+			level, err := logger.ParseLogLevel(*simLogLevelFlag)
+			if err != nil {
+				log.Fatal(err)
+			}
+			output := logger.NewLogOutput("")
+			if err := output.Start(); err != nil {
+				log.Fatal(err)
+			}
+
+			sim, err := simulator.New(simConfig, logger.NewLogger("simulator", output, level))
+			if err != nil {
+				log.Fatalf("Invalid simulation config: %v. See rportd simulate --help", err)
+			}
+
+			ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer cancel()
+
+			if err := sim.Run(ctx); err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+
+	simConfig       simulator.Config
+	simLogLevelFlag *string
+)
+
+func init() {
+	RootCmd.AddCommand(simulateCmd)
+
+	flags := simulateCmd.Flags()
+	flags.StringVar(&simConfig.Server, "server", "", "url of the server to connect to [required]")
+	flags.StringVar(&simConfig.Auth, "auth", "", "client credentials <user>:<password> used by all clients [required]")
+	flags.StringVar(&simConfig.Fingerprint, "fingerprint", "", "fingerprint of the server key, not verified if empty")
+	flags.IntVar(&simConfig.Clients, "clients", simulator.DefaultClients, "number of simulated clients")
+	flags.StringVar(&simConfig.IDPrefix, "id-prefix", simulator.DefaultIDPrefix, "prefix of the client ids and names")
+	flags.DurationVar(&simConfig.RampUp, "ramp-up", simulator.DefaultRampUp, "spread the initial connects over the given duration")
+	flags.DurationVar(&simConfig.Lifetime, "lifetime", 0, "average connection lifetime before a client reconnects, 0 disables churn")
+	flags.DurationVar(&simConfig.ReconnectDelay, "reconnect-delay", simulator.DefaultReconnectDelay, "average delay before a client reconnects")
+	flags.StringSliceVar(&simConfig.Tunnels, "tunnel", nil, "tunnel requested by every client on connect, can be repeated")
+	flags.DurationVar(&simConfig.KeepAlive, "keepalive", simulator.DefaultKeepAlive, "interval of client to server pings, 0 disables them")
+	flags.DurationVar(&simConfig.JobDuration, "job-duration", simulator.DefaultJobDuration, "average duration of simulated commands and scripts")
+	flags.Float64Var(&simConfig.JobFailureRate, "job-failure-rate", 0, "ratio of failed simulated commands and scripts, between 0 and 1")
+	flags.StringVar(&simConfig.JobOutput, "job-output", simulator.DefaultJobOutput, "stdout returned by simulated commands and scripts")
+	flags.DurationVar(&simConfig.StatsInterval, "stats-interval", simulator.DefaultStatsInterval, "interval of the stats log line, 0 disables it")
+	simLogLevelFlag = flags.String("log-level", "info", "log level: error, info or debug")
+
+	for _, name := range []string{"server", "auth"} {
+		if err := simulateCmd.MarkFlagRequired(name); err != nil {
+			// can only happen when changing the code
+			panic(err)
+		}
+	}
+
+	// reset default usage func
+	simulateCmd.SetUsageFunc((&cobra.Command{}).UsageFunc())
+}
@@ -0,0 +1,337 @@
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/crypto/ssh"
+
+	chshare "github.com/realvnc-labs/rport/share"
+	"github.com/realvnc-labs/rport/share/clientconfig"
+	"github.com/realvnc-labs/rport/share/comm"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/random"
+)
+
+const (
+	dialTimeout        = 30 * time.Second
+	sendRequestTimeout = 60 * time.Second
+)
+
+// client is a fake client that connects with the real protocol and answers the server requests without touching
+// the local system.
+type client struct {
+	*logger.Logger
+	sim  *Simulator
+	id   string
+	name string
+}
+
+func newClient(sim *Simulator, n int) *client {
+	id := fmt.Sprintf("%s%05d", sim.cfg.IDPrefix, n)
+	return &client{
+		Logger: sim.Logger.Fork(id),
+		sim:    sim,
+		id:     id,
+		name:   id,
+	}
+}
+
+// run keeps the client connected until the context is canceled, disconnecting and reconnecting it if churn is
+// enabled.
+func (c *client) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		err := c.connectAndServe(ctx)
+		if err != nil && ctx.Err() == nil {
+			c.Debugf("Connection failed: %v", err)
+		}
+		if !sleep(ctx, jitter(c.sim.cfg.ReconnectDelay)) {
+			return
+		}
+	}
+}
+
+func (c *client) connectAndServe(ctx context.Context) error {
+	stats := &c.sim.stats
+
+	conn, chans, reqs, err := c.connect()
+	if err != nil {
+		atomic.AddInt64(&stats.ConnectErrors, 1)
+		return err
+	}
+	defer conn.Close()
+
+	go c.handleRequests(ctx, conn, reqs)
+	go c.handleStreams(chans)
+
+	if err := c.sendConnectionRequest(ctx, conn); err != nil {
+		atomic.AddInt64(&stats.ConnectErrors, 1)
+		return err
+	}
+
+	atomic.AddInt64(&stats.Connects, 1)
+	atomic.AddInt64(&stats.Connected, 1)
+	defer func() {
+		atomic.AddInt64(&stats.Connected, -1)
+		atomic.AddInt64(&stats.Disconnects, 1)
+	}()
+
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if c.sim.cfg.KeepAlive > 0 {
+		go c.keepAlive(connCtx, conn)
+	}
+
+	closed := make(chan error, 1)
+	go func() { closed <- conn.Wait() }()
+
+	var lifetime <-chan time.Time
+	if c.sim.cfg.Lifetime > 0 {
+		t := time.NewTimer(jitter(c.sim.cfg.Lifetime))
+		defer t.Stop()
+		lifetime = t.C
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-lifetime:
+		c.Debugf("Lifetime reached, disconnecting")
+		return nil
+	case err := <-closed:
+		return fmt.Errorf("connection closed: %v", err)
+	}
+}
+
+func (c *client) connect() (ssh.Conn, <-chan ssh.NewChannel, <-chan *ssh.Request, error) {
+	d := &websocket.Dialer{
+		ReadBufferSize:   1024,
+		WriteBufferSize:  1024,
+		HandshakeTimeout: dialTimeout,
+		Subprotocols:     []string{chshare.ProtocolVersion},
+	}
+	wsConn, _, err := d.Dial(c.sim.cfg.Server, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	sshConfig := &ssh.ClientConfig{
+		User:            c.sim.cfg.authUser,
+		Auth:            []ssh.AuthMethod{ssh.Password(c.sim.cfg.authPass)},
+		ClientVersion:   "SSH-" + chshare.ProtocolVersion + "-client",
+		HostKeyCallback: c.verifyServer,
+		Timeout:         dialTimeout,
+	}
+	conn, chans, reqs, err := ssh.NewClientConn(chshare.NewWebSocketConn(wsConn), "", sshConfig)
+	if err != nil {
+		wsConn.Close()
+		return nil, nil, nil, err
+	}
+	return conn, chans, reqs, nil
+}
+
+func (c *client) verifyServer(hostname string, remote net.Addr, key ssh.PublicKey) error {
+	got := chshare.FingerprintKey(key)
+	if c.sim.cfg.Fingerprint != "" && !strings.HasPrefix(got, c.sim.cfg.Fingerprint) {
+		return fmt.Errorf("invalid fingerprint (%s)", got)
+	}
+	return nil
+}
+
+func (c *client) sendConnectionRequest(ctx context.Context, conn ssh.Conn) error {
+	sessionID, err := random.UUID4()
+	if err != nil {
+		return err
+	}
+
+	req, err := chshare.EncodeConnectionRequest(c.connectionRequest(sessionID))
+	if err != nil {
+		return fmt.Errorf("could not encode connection request: %v", err)
+	}
+
+	ok, resp, err := comm.SendRequestWithTimeout(ctx, conn, "new_connection", true, req, sendRequestTimeout, c.Logger)
+	if err != nil {
+		return fmt.Errorf("connection request failed: %v", err)
+	}
+	if !ok {
+		return errors.New(string(resp))
+	}
+	return nil
+}
+
+func (c *client) connectionRequest(sessionID string) *chshare.ConnectionRequest {
+	return &chshare.ConnectionRequest{
+		ID:                     c.id,
+		Name:                   c.name,
+		SessionID:              sessionID,
+		OS:                     "Linux simulated",
+		OSFullName:             "Simulated Linux",
+		OSVersion:              chshare.BuildVersion,
+		OSVirtualizationSystem: "simulated",
+		OSVirtualizationRole:   "guest",
+		OSArch:                 "amd64",
+		OSFamily:               "simulated",
+		OSKernel:               "linux",
+		Version:                chshare.BuildVersion,
+		Hostname:               c.name,
+		CPUFamily:              "simulated",
+		CPUModel:               "simulated",
+		CPUModelName:           "simulated",
+		CPUVendor:              "simulated",
+		NumCPUs:                1,
+		MemoryTotal:            1 << 30,
+		Timezone:               "UTC (UTC+00:00)",
+		IPv4:                   []string{"192.0.2.1"},
+		Tags:                   []string{"simulated"},
+		Remotes:                c.sim.cfg.remotes,
+		ClientConfiguration: &clientconfig.Config{
+			Client: clientconfig.ClientConfig{
+				ID:   c.id,
+				Name: c.name,
+				Mode: clientconfig.ModeFull,
+			},
+			Connection: clientconfig.ConnectionConfig{
+				KeepAlive: c.sim.cfg.KeepAlive,
+			},
+			RemoteCommands: clientconfig.CommandsConfig{
+				Enabled: true,
+			},
+			RemoteScripts: clientconfig.ScriptsConfig{
+				Enabled: true,
+			},
+		},
+		Mode: clientconfig.ModeFull,
+	}
+}
+
+func (c *client) keepAlive(ctx context.Context, conn ssh.Conn) {
+	for sleep(ctx, jitter(c.sim.cfg.KeepAlive)) {
+		ok, _, _, err := comm.PingConnectionWithTimeout(ctx, conn, sendRequestTimeout, c.Logger)
+		if err != nil || !ok {
+			c.Debugf("Keepalive failed: %v", err)
+			conn.Close()
+			return
+		}
+	}
+}
+
+func (c *client) handleRequests(ctx context.Context, conn ssh.Conn, reqs <-chan *ssh.Request) {
+	for r := range reqs {
+		atomic.AddInt64(&c.sim.stats.Requests, 1)
+
+		var resp interface{}
+		var err error
+		switch r.Type {
+		case comm.RequestTypePing:
+			_ = r.Reply(true, nil)
+			continue
+		case comm.RequestTypeRunCmd:
+			resp, err = c.runJob(ctx, conn, r.Payload)
+		case comm.RequestTypeCheckPort:
+			resp = &comm.CheckPortResponse{Open: true}
+		case comm.RequestTypeCheckTunnelAllowed:
+			resp = &comm.CheckTunnelAllowedResponse{IsAllowed: true}
+		case comm.RequestTypeGetInterpreters:
+			resp = []models.Interpreter{}
+		case comm.RequestTypeSetMonitoringProfile:
+			var req comm.SetMonitoringProfileRequest
+			err = json.Unmarshal(r.Payload, &req)
+			resp = &comm.SetMonitoringProfileResponse{Version: req.Version}
+		case comm.RequestTypeUpdateClientAttributes,
+			comm.RequestTypePutCapabilities,
+			comm.RequestTypeRefreshUpdatesStatus,
+			comm.RequestTypeSetMode:
+			// accepted without doing anything
+		default:
+			c.Debugf("Unsupported request: %q", r.Type)
+			comm.ReplyError(c.Logger, r, fmt.Errorf("%q is not supported by simulated clients", r.Type))
+			continue
+		}
+
+		if err != nil {
+			comm.ReplyError(c.Logger, r, err)
+			continue
+		}
+		comm.ReplySuccessJSON(c.Logger, r, resp)
+	}
+}
+
+// runJob accepts a command or script and sends the result after the simulated job duration.
+func (c *client) runJob(ctx context.Context, conn ssh.Conn, payload []byte) (*comm.RunCmdResponse, error) {
+	job := models.Job{}
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return nil, fmt.Errorf("failed to decode requested job: %s", err)
+	}
+	atomic.AddInt64(&c.sim.stats.JobsStarted, 1)
+
+	pid := rand.Intn(65535) + 1
+	startedAt := time.Now()
+	go func() {
+		if !sleep(ctx, jitter(c.sim.cfg.JobDuration)) {
+			return
+		}
+
+		finishedAt := time.Now()
+		job.StartedAt = startedAt
+		job.FinishedAt = &finishedAt
+		job.PID = &pid
+		job.Status = models.JobStatusSuccessful
+		job.Result = &models.JobResult{StdOut: c.sim.cfg.JobOutput}
+		if rand.Float64() < c.sim.cfg.JobFailureRate {
+			job.Status = models.JobStatusFailed
+			job.Error = "simulated failure"
+		}
+
+		atomic.AddInt64(&c.sim.stats.JobsFinished, 1)
+
+		jobBytes, err := json.Marshal(job)
+		if err != nil {
+			c.Errorf("Failed to encode job result [jid=%q]: %v", job.JID, err)
+			return
+		}
+		if _, _, err := conn.SendRequest(comm.RequestTypeCmdResult, false, jobBytes); err != nil {
+			c.Debugf("Failed to send job result [jid=%q]: %v", job.JID, err)
+		}
+	}()
+
+	return &comm.RunCmdResponse{
+		Pid:       pid,
+		StartedAt: startedAt,
+	}, nil
+}
+
+// handleStreams accepts all tunnel streams and echoes the received data, so tunnels can be used for throughput tests.
+func (c *client) handleStreams(chans <-chan ssh.NewChannel) {
+	for ch := range chans {
+		stream, reqs, err := ch.Accept()
+		if err != nil {
+			c.Debugf("Failed to accept stream: %v", err)
+			continue
+		}
+		go ssh.DiscardRequests(reqs)
+
+		atomic.AddInt64(&c.sim.stats.Streams, 1)
+		go func() {
+			defer stream.Close()
+			_, _ = io.Copy(stream, stream)
+		}()
+	}
+}
+
+// jitter returns a random duration between 50% and 150% of the given one.
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d)))
+}
@@ -0,0 +1,211 @@
+// Package simulator spawns fake clients speaking the real client protocol to load test a rport server.
+package simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+const (
+	DefaultClients        = 100
+	DefaultIDPrefix       = "sim-"
+	DefaultRampUp         = 10 * time.Second
+	DefaultReconnectDelay = 5 * time.Second
+	DefaultKeepAlive      = time.Minute
+	DefaultJobDuration    = time.Second
+	DefaultJobOutput      = "simulated output"
+	DefaultStatsInterval  = 10 * time.Second
+)
+
+type Config struct {
+	// Server is the url of the server the clients connect to.
+	Server string
+	// Fingerprint of the server key, the key is not verified if empty.
+	Fingerprint string
+	// Auth holds the client credentials "<user>:<password>", shared by all clients.
+	Auth string
+	// Clients is the number of simulated clients.
+	Clients int
+	// IDPrefix is prepended to the sequence number of a client to build its id and name.
+	IDPrefix string
+	// RampUp spreads the initial connects of the clients over the given duration.
+	RampUp time.Duration
+	// Lifetime is the average duration a client stays connected before it disconnects (churn), 0 disables churn.
+	Lifetime time.Duration
+	// ReconnectDelay is the average duration a client waits before it reconnects.
+	ReconnectDelay time.Duration
+	// Tunnels are the remotes every client asks for on connect, e.g. "22" or "2222:22".
+	Tunnels []string
+	// KeepAlive is the interval of pings sent by every client, 0 disables them.
+	KeepAlive time.Duration
+	// JobDuration is the average duration of a simulated command or script.
+	JobDuration time.Duration
+	// JobFailureRate is the ratio of simulated jobs that fail, between 0 and 1.
+	JobFailureRate float64
+	// JobOutput is returned as stdout of every simulated job.
+	JobOutput string
+	// StatsInterval is the interval of the stats log line, 0 disables it.
+	StatsInterval time.Duration
+
+	authUser string
+	authPass string
+	remotes  []*models.Remote
+}
+
+// ParseAndValidate checks the config and prepares the values used by the clients.
+func (c *Config) ParseAndValidate() error {
+	if c.Server == "" {
+		return errors.New("server is required")
+	}
+	c.Server = serverURL(c.Server)
+
+	user, pass, ok := strings.Cut(c.Auth, ":")
+	if !ok || user == "" {
+		return errors.New(`auth must be set as "<user>:<password>"`)
+	}
+	c.authUser, c.authPass = user, pass
+
+	if c.Clients <= 0 {
+		return errors.New("number of clients must be greater than 0")
+	}
+	if c.JobFailureRate < 0 || c.JobFailureRate > 1 {
+		return fmt.Errorf("job failure rate must be between 0 and 1, got %v", c.JobFailureRate)
+	}
+
+	c.remotes = nil
+	for _, t := range c.Tunnels {
+		r, err := models.NewRemote(t)
+		if err != nil {
+			return fmt.Errorf("invalid tunnel %q: %v", t, err)
+		}
+		c.remotes = append(c.remotes, r)
+	}
+	return nil
+}
+
+// serverURL applies the websocket scheme the same way the client does.
+func serverURL(server string) string {
+	if !strings.Contains(server, "://") {
+		server = "http://" + server
+	}
+	return strings.Replace(server, "http", "ws", 1)
+}
+
+// Stats are the counters of all simulated clients.
+type Stats struct {
+	Connected     int64
+	Connects      int64
+	ConnectErrors int64
+	Disconnects   int64
+	Requests      int64
+	JobsStarted   int64
+	JobsFinished  int64
+	Streams       int64
+}
+
+func (s *Stats) snapshot() Stats {
+	return Stats{
+		Connected:     atomic.LoadInt64(&s.Connected),
+		Connects:      atomic.LoadInt64(&s.Connects),
+		ConnectErrors: atomic.LoadInt64(&s.ConnectErrors),
+		Disconnects:   atomic.LoadInt64(&s.Disconnects),
+		Requests:      atomic.LoadInt64(&s.Requests),
+		JobsStarted:   atomic.LoadInt64(&s.JobsStarted),
+		JobsFinished:  atomic.LoadInt64(&s.JobsFinished),
+		Streams:       atomic.LoadInt64(&s.Streams),
+	}
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf(
+		"connected=%d connects=%d connect_errors=%d disconnects=%d requests=%d jobs_started=%d jobs_finished=%d streams=%d",
+		s.Connected, s.Connects, s.ConnectErrors, s.Disconnects, s.Requests, s.JobsStarted, s.JobsFinished, s.Streams,
+	)
+}
+
+type Simulator struct {
+	*logger.Logger
+	cfg   Config
+	stats Stats
+}
+
+func New(cfg Config, l *logger.Logger) (*Simulator, error) {
+	if err := cfg.ParseAndValidate(); err != nil {
+		return nil, err
+	}
+	return &Simulator{
+		Logger: l,
+		cfg:    cfg,
+	}, nil
+}
+
+// Stats returns the current counters.
+func (s *Simulator) Stats() Stats {
+	return s.stats.snapshot()
+}
+
+// Run starts all clients and blocks until the context is canceled and all clients are disconnected.
+func (s *Simulator) Run(ctx context.Context) error {
+	s.Infof("Starting %d simulated clients connecting to %s", s.cfg.Clients, s.cfg.Server)
+
+	if s.cfg.StatsInterval > 0 {
+		go s.logStats(ctx)
+	}
+
+	wg := &sync.WaitGroup{}
+	for i := 1; i <= s.cfg.Clients; i++ {
+		c := newClient(s, i)
+		delay := time.Duration(0)
+		if s.cfg.RampUp > 0 {
+			delay = s.cfg.RampUp * time.Duration(i-1) / time.Duration(s.cfg.Clients)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if sleep(ctx, delay) {
+				c.run(ctx)
+			}
+		}()
+	}
+	wg.Wait()
+
+	s.Infof("All simulated clients stopped: %s", s.Stats())
+	return nil
+}
+
+func (s *Simulator) logStats(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.StatsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Infof("%s", s.Stats())
+		}
+	}
+}
+
+// sleep waits for the given duration and returns false if the context was canceled in the meantime.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,273 @@
+package simulator
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	chshare "github.com/realvnc-labs/rport/share"
+	"github.com/realvnc-labs/rport/share/comm"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+var testLog = logger.NewLogger("simulator-test", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+
+// testServer accepts client connections like the rport server, runs a job and opens a tunnel stream on every client.
+type testServer struct {
+	t          *testing.T
+	sshConfig  *ssh.ServerConfig
+	connReqs   chan *chshare.ConnectionRequest
+	jobResults chan *models.Job
+	echoes     chan string
+}
+
+func newTestServer(t *testing.T) *testServer {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	require.NoError(t, err)
+
+	s := &testServer{
+		t:          t,
+		connReqs:   make(chan *chshare.ConnectionRequest, 10),
+		jobResults: make(chan *models.Job, 10),
+		echoes:     make(chan string, 10),
+	}
+	s.sshConfig = &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == "client1" && string(password) == "secret" {
+				return nil, nil
+			}
+			return nil, assert.AnError
+		},
+	}
+	s.sshConfig.AddHostKey(signer)
+	return s
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{Subprotocols: []string{chshare.ProtocolVersion}}
+	wsConn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	conn, chans, reqs, err := ssh.NewServerConn(chshare.NewWebSocketConn(wsConn), s.sshConfig)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	go func() {
+		for ch := range chans {
+			_ = ch.Reject(ssh.Prohibited, "not supported")
+		}
+	}()
+
+	for r := range reqs {
+		switch r.Type {
+		case "new_connection":
+			connReq, err := chshare.DecodeConnectionRequest(r.Payload)
+			if err != nil {
+				_ = r.Reply(false, []byte(err.Error()))
+				return
+			}
+			_ = r.Reply(true, []byte("[]"))
+			s.connReqs <- connReq
+			go s.exercise(conn)
+		case comm.RequestTypeCmdResult:
+			job := &models.Job{}
+			if err := json.Unmarshal(r.Payload, job); err == nil {
+				s.jobResults <- job
+			}
+		case comm.RequestTypePing:
+			_ = r.Reply(true, nil)
+		}
+	}
+}
+
+func (s *testServer) exercise(conn ssh.Conn) {
+	job := models.Job{JID: "job-1", CorrelationID: "request-1", Command: "whoami"}
+	resp := &comm.RunCmdResponse{}
+	if err := comm.SendRequestAndGetResponse(conn, comm.RequestTypeRunCmd, job, resp, testLog); err != nil {
+		s.t.Errorf("run_cmd failed: %v", err)
+		return
+	}
+
+	stream, reqs, err := conn.OpenChannel("session", []byte("127.0.0.1:22"))
+	if err != nil {
+		s.t.Errorf("open stream failed: %v", err)
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	defer stream.Close()
+
+	if _, err := stream.Write([]byte("hello")); err != nil {
+		s.t.Errorf("write to stream failed: %v", err)
+		return
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(stream, buf); err != nil {
+		s.t.Errorf("read from stream failed: %v", err)
+		return
+	}
+	s.echoes <- string(buf)
+}
+
+func TestSimulatorRun(t *testing.T) {
+	server := newTestServer(t)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	sim, err := New(Config{
+		Server:         ts.URL,
+		Auth:           "client1:secret",
+		Clients:        2,
+		IDPrefix:       "sim-",
+		ReconnectDelay: time.Second,
+		Tunnels:        []string{"22"},
+		JobDuration:    10 * time.Millisecond,
+		JobOutput:      "simulated output",
+	}, testLog)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, sim.Run(ctx))
+	}()
+
+	gotIDs := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		connReq := receive(t, server.connReqs)
+		gotIDs[connReq.ID] = true
+		assert.Equal(t, connReq.ID, connReq.Name)
+		assert.NotEmpty(t, connReq.SessionID)
+		require.Len(t, connReq.Remotes, 1)
+		assert.Equal(t, "22", connReq.Remotes[0].RemotePort)
+		require.NotNil(t, connReq.ClientConfiguration)
+		assert.True(t, connReq.ClientConfiguration.RemoteCommands.Enabled)
+	}
+	assert.Equal(t, map[string]bool{"sim-00001": true, "sim-00002": true}, gotIDs)
+
+	for i := 0; i < 2; i++ {
+		job := receive(t, server.jobResults)
+		assert.Equal(t, "job-1", job.JID)
+		assert.Equal(t, "request-1", job.CorrelationID)
+		assert.Equal(t, models.JobStatusSuccessful, job.Status)
+		require.NotNil(t, job.Result)
+		assert.Equal(t, "simulated output", job.Result.StdOut)
+		assert.NotNil(t, job.FinishedAt)
+
+		assert.Equal(t, "hello", receive(t, server.echoes))
+	}
+
+	stats := sim.Stats()
+	assert.EqualValues(t, 2, stats.Connected)
+	assert.EqualValues(t, 2, stats.Connects)
+	assert.EqualValues(t, 2, stats.JobsStarted)
+	assert.EqualValues(t, 2, stats.JobsFinished)
+	assert.EqualValues(t, 2, stats.Streams)
+
+	cancel()
+	receive(t, done)
+	assert.EqualValues(t, 0, sim.Stats().Connected)
+}
+
+func TestSimulatorChurn(t *testing.T) {
+	server := newTestServer(t)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	sim, err := New(Config{
+		Server:         ts.URL,
+		Auth:           "client1:secret",
+		Clients:        1,
+		Lifetime:       50 * time.Millisecond,
+		ReconnectDelay: 10 * time.Millisecond,
+		JobDuration:    time.Millisecond,
+	}, testLog)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = sim.Run(ctx) }()
+
+	first := receive(t, server.connReqs)
+	second := receive(t, server.connReqs)
+	assert.Equal(t, first.ID, second.ID)
+	assert.NotEqual(t, first.SessionID, second.SessionID)
+}
+
+func TestConfigParseAndValidate(t *testing.T) {
+	testCases := []struct {
+		name       string
+		cfg        Config
+		wantServer string
+		wantErr    string
+	}{
+		{
+			name:       "valid",
+			cfg:        Config{Server: "127.0.0.1:8080", Auth: "user:pass", Clients: 1, Tunnels: []string{"2222:22"}},
+			wantServer: "ws://127.0.0.1:8080",
+		}, {
+			name:       "https",
+			cfg:        Config{Server: "https://rport.example.com", Auth: "user:pass", Clients: 1},
+			wantServer: "wss://rport.example.com",
+		}, {
+			name:    "missing server",
+			cfg:     Config{Auth: "user:pass", Clients: 1},
+			wantErr: "server is required",
+		}, {
+			name:    "invalid auth",
+			cfg:     Config{Server: "127.0.0.1:8080", Auth: "user", Clients: 1},
+			wantErr: `auth must be set as "<user>:<password>"`,
+		}, {
+			name:    "no clients",
+			cfg:     Config{Server: "127.0.0.1:8080", Auth: "user:pass"},
+			wantErr: "number of clients must be greater than 0",
+		}, {
+			name:    "invalid failure rate",
+			cfg:     Config{Server: "127.0.0.1:8080", Auth: "user:pass", Clients: 1, JobFailureRate: 2},
+			wantErr: "job failure rate must be between 0 and 1, got 2",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.ParseAndValidate()
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantServer, tc.cfg.Server)
+			assert.Len(t, tc.cfg.remotes, len(tc.cfg.Tunnels))
+		})
+	}
+}
+
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+	var zero T
+	return zero
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/realvnc-labs/rport/cmd/rportd/servicemanagement"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+var (
+	systemdCmd = &cobra.Command{
+		Use:   "systemd",
+		Short: "generate systemd units",
+		Long: "Generate the systemd service unit to run rportd and optionally socket units for socket activation. " +
+			"The units are printed unless --dir is given.",
+		Example: "rportd systemd -c /etc/rport/rportd.conf --socket-activation --dir /etc/systemd/system",
+		Run: func(*cobra.Command, []string) {
+			if *cfgPath == "" {
+				log.Fatal("The config file must be given with --config")
+			}
+			mLog := logger.NewMemLogger()
+			err := decodeAndValidateConfig(&mLog)
+			if err != nil {
+				log.Fatalf("Invalid config: %v. See rportd --help", err)
+			}
+
+			executable, err := os.Executable()
+			if err != nil {
+				log.Fatal(err)
+			}
+			units, err := servicemanagement.GenerateSystemdUnits(servicemanagement.SystemdUnitOptions{
+				Executable:       executable,
+				ConfigPath:       *cfgPath,
+				User:             *systemdUserFlag,
+				SocketActivation: *systemdSocketActivationFlag,
+				ServerAddress:    cfg.Server.ListenAddress,
+				APIAddress:       cfg.API.Address,
+			})
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			if *systemdDirFlag == "" {
+				names := make([]string, 0, len(units))
+				for name := range units {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				for _, name := range names {
+					fmt.Printf("# %s\n%s\n", name, units[name])
+				}
+				return
+			}
+
+			if err := servicemanagement.WriteSystemdUnits(*systemdDirFlag, units); err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("Systemd units written to %s. Run 'systemctl daemon-reload' to load them.\n", *systemdDirFlag)
+		},
+	}
+
+	systemdUserFlag             *string
+	systemdSocketActivationFlag *bool
+	systemdDirFlag              *string
+)
+
+func init() {
+	RootCmd.AddCommand(systemdCmd)
+
+	systemdUserFlag = systemdCmd.Flags().String("user", "rport", "user to run rportd under")
+	systemdSocketActivationFlag = systemdCmd.Flags().Bool("socket-activation", false, "generate socket units for the client and the API listener")
+	systemdDirFlag = systemdCmd.Flags().String("dir", "", "directory to write the units to, e.g. /etc/systemd/system")
+}
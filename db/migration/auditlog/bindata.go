@@ -2,6 +2,12 @@
 // sources:
 // 001_init.down.sql (23B)
 // 001_init.up.sql (928B)
+// 002_labels.down.sql (0)
+// 002_labels.up.sql (43B)
+// 003_request_id.down.sql (0)
+// 003_request_id.up.sql (47B)
+// 004_indexes.down.sql (190B)
+// 004_indexes.up.sql (518B)
 
 package auditlog
 
@@ -109,6 +115,126 @@ func _001_initUpSql() (*asset, error) {
 	return a, nil
 }
 
+var __002_labelsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x03\x00\x00\x00\x00\x00\x00\x00\x00\x00")
+
+func _002_labelsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__002_labelsDownSql,
+		"002_labels.down.sql",
+	)
+}
+
+func _002_labelsDownSql() (*asset, error) {
+	bytes, err := _002_labelsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "002_labels.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(1792029334, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xe3, 0xb0, 0xc4, 0x42, 0x98, 0xfc, 0x1c, 0x14, 0x9a, 0xfb, 0xf4, 0xc8, 0x99, 0x6f, 0xb9, 0x24, 0x27, 0xae, 0x41, 0xe4, 0x64, 0x9b, 0x93, 0x4c, 0xa4, 0x95, 0x99, 0x1b, 0x78, 0x52, 0xb8, 0x55}}
+	return a, nil
+}
+
+var __002_labelsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x2c\x4d\xc9\x2c\xc9\xc9\x4f\x57\x70\x74\x71\x51\xc8\x49\x4c\x4a\xcd\x29\x56\x08\x71\x8d\x08\x51\xf0\x0b\xf5\xf1\xb1\xe6\x02\x00\x8e\x36\x01\x78\x2b\x00\x00\x00")
+
+func _002_labelsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__002_labelsUpSql,
+		"002_labels.up.sql",
+	)
+}
+
+func _002_labelsUpSql() (*asset, error) {
+	bytes, err := _002_labelsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "002_labels.up.sql", size: 43, mode: os.FileMode(0644), modTime: time.Unix(1792029334, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x31, 0x12, 0xed, 0x4d, 0xe5, 0xc7, 0xe9, 0x48, 0xe, 0xa, 0x67, 0xcf, 0xc5, 0xfa, 0xbe, 0xf6, 0xc6, 0x98, 0xdf, 0xa0, 0x5c, 0x79, 0xd, 0xbe, 0x1c, 0xa4, 0x66, 0xc8, 0x39, 0xe9, 0x9e, 0x75}}
+	return a, nil
+}
+
+var __003_request_idDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x03\x00\x00\x00\x00\x00\x00\x00\x00\x00")
+
+func _003_request_idDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__003_request_idDownSql,
+		"003_request_id.down.sql",
+	)
+}
+
+func _003_request_idDownSql() (*asset, error) {
+	bytes, err := _003_request_idDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "003_request_id.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(1792042577, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xe3, 0xb0, 0xc4, 0x42, 0x98, 0xfc, 0x1c, 0x14, 0x9a, 0xfb, 0xf4, 0xc8, 0x99, 0x6f, 0xb9, 0x24, 0x27, 0xae, 0x41, 0xe4, 0x64, 0x9b, 0x93, 0x4c, 0xa4, 0x95, 0x99, 0x1b, 0x78, 0x52, 0xb8, 0x55}}
+	return a, nil
+}
+
+var __003_request_idUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x2c\x4d\xc9\x2c\xc9\xc9\x4f\x57\x70\x74\x71\x51\x28\x4a\x2d\x2c\x4d\x2d\x2e\x89\xcf\x4c\x51\x08\x71\x8d\x08\x51\xf0\x0b\xf5\xf1\xb1\xe6\x02\x00\x0d\xfd\x64\x31\x2f\x00\x00\x00")
+
+func _003_request_idUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__003_request_idUpSql,
+		"003_request_id.up.sql",
+	)
+}
+
+func _003_request_idUpSql() (*asset, error) {
+	bytes, err := _003_request_idUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "003_request_id.up.sql", size: 47, mode: os.FileMode(0644), modTime: time.Unix(1792042577, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa2, 0xf6, 0x73, 0xe2, 0xad, 0x6, 0x5c, 0x40, 0x4e, 0x94, 0xd5, 0x8c, 0xf8, 0x35, 0xd6, 0x68, 0x37, 0xa5, 0xe8, 0x92, 0x70, 0xed, 0x2a, 0x1d, 0x0, 0x14, 0x8b, 0x3a, 0x33, 0x44, 0xb7, 0xed}}
+	return a, nil
+}
+
+var __004_indexesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x09\xf2\x0f\x50\xf0\xf4\x73\x71\x8d\x50\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x50\x4a\x2c\x4d\xc9\x2c\xc9\xc9\x4f\x8f\x2f\x2d\x4e\x2d\xca\x4b\xcc\x4d\x8d\x2f\xc9\xcc\x4d\x2d\x2e\x49\xcc\x2d\x50\xb2\xe6\x72\xc1\xaf\xa7\x28\x35\x37\xbf\x24\x35\x3e\x93\x08\xa5\x89\xc9\x25\x99\xf9\x79\xa4\x18\x9e\x98\x96\x96\x9a\x5c\x92\x9a\x12\x9f\x99\x02\x54\x0c\x00\xb4\x51\xfe\x0b\xbe\x00\x00\x00")
+
+func _004_indexesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__004_indexesDownSql,
+		"004_indexes.down.sql",
+	)
+}
+
+func _004_indexesDownSql() (*asset, error) {
+	bytes, err := _004_indexesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "004_indexes.down.sql", size: 190, mode: os.FileMode(0644), modTime: time.Unix(1792051119, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x3c, 0xb0, 0xd8, 0x1, 0x50, 0xad, 0xea, 0x52, 0xcf, 0x20, 0xd3, 0xac, 0xfc, 0xd9, 0x7b, 0x6f, 0x42, 0xc5, 0x7c, 0x97, 0x23, 0xf7, 0x92, 0xa6, 0xf7, 0xe2, 0x4d, 0xfb, 0xc1, 0x3, 0x91, 0x80}}
+	return a, nil
+}
+
+var __004_indexesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x9d\x8e\xc1\x0a\x82\x40\x14\x45\xf7\x7e\xc5\xc3\x55\x41\xf6\x03\xad\x22\x27\x70\x63\x91\x2e\xdc\xe9\xe0\x3c\xeb\x81\xf3\x26\x9c\x11\xfa\xfc\x06\x32\x13\x4a\x84\x66\x79\xdf\x99\x73\x6f\x14\x41\x7e\x43\x20\x26\x47\xb2\x85\xde\x62\xc7\x52\x23\x48\x56\xd0\xa1\x36\x0e\x4b\xba\xfb\xb3\xc2\x07\x5a\x9f\x34\xd8\x21\xd7\xa8\x80\x0d\x47\xf8\x20\xeb\x88\xaf\x50\x9b\xb6\xd7\x6c\xb7\x41\x7c\x39\x9d\x21\x49\x63\x51\x40\x72\x04\x51\x24\x59\x9e\x41\x28\x7b\x45\xae\x35\xd7\xf2\xad\x0f\x77\x4b\xe4\xd8\xed\xd1\xe0\x70\x11\xfb\x5c\x0c\xf4\xb7\xad\x74\xa4\xd1\x3a\xa9\xef\x21\x9c\x52\xa8\xde\x40\x05\xab\x00\xfc\x0b\xc7\x5a\xd8\x67\x87\xcd\x2b\x9b\xfc\xf1\x61\xb0\x9e\xaf\xf9\x4c\xf9\x6d\x9f\xdc\x17\x4c\xb2\x76\x64\x78\x71\xee\x0b\xfb\x6f\xac\x6c\x1a\xac\x1d\xaa\x92\xd4\x9c\x7d\x4a\x0c\xb6\x27\x8e\x4f\xa3\x03\x06\x02\x00\x00")
+
+func _004_indexesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__004_indexesUpSql,
+		"004_indexes.up.sql",
+	)
+}
+
+func _004_indexesUpSql() (*asset, error) {
+	bytes, err := _004_indexesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "004_indexes.up.sql", size: 518, mode: os.FileMode(0644), modTime: time.Unix(1792051119, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xb6, 0xbd, 0x7d, 0xe, 0x4, 0x38, 0x57, 0xf, 0xef, 0xdc, 0x57, 0xac, 0xda, 0x4, 0x85, 0x1d, 0x80, 0xed, 0xa0, 0x90, 0xc3, 0xed, 0x31, 0x2b, 0xb2, 0xb0, 0x3b, 0xa9, 0xd1, 0xcf, 0xe2, 0xc9}}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -200,8 +326,14 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
-	"001_init.down.sql": _001_initDownSql,
-	"001_init.up.sql":   _001_initUpSql,
+	"001_init.down.sql":       _001_initDownSql,
+	"001_init.up.sql":         _001_initUpSql,
+	"002_labels.down.sql":     _002_labelsDownSql,
+	"002_labels.up.sql":       _002_labelsUpSql,
+	"003_request_id.down.sql": _003_request_idDownSql,
+	"003_request_id.up.sql":   _003_request_idUpSql,
+	"004_indexes.down.sql":    _004_indexesDownSql,
+	"004_indexes.up.sql":      _004_indexesUpSql,
 }
 
 // AssetDebug is true if the assets were built with the debug flag enabled.
@@ -250,8 +382,14 @@ type bintree struct {
 }
 
 var _bintree = &bintree{nil, map[string]*bintree{
-	"001_init.down.sql": {_001_initDownSql, map[string]*bintree{}},
-	"001_init.up.sql":   {_001_initUpSql, map[string]*bintree{}},
+	"001_init.down.sql":       {_001_initDownSql, map[string]*bintree{}},
+	"001_init.up.sql":         {_001_initUpSql, map[string]*bintree{}},
+	"002_labels.down.sql":     {_002_labelsDownSql, map[string]*bintree{}},
+	"002_labels.up.sql":       {_002_labelsUpSql, map[string]*bintree{}},
+	"003_request_id.down.sql": {_003_request_idDownSql, map[string]*bintree{}},
+	"003_request_id.up.sql":   {_003_request_idUpSql, map[string]*bintree{}},
+	"004_indexes.down.sql":    {_004_indexesDownSql, map[string]*bintree{}},
+	"004_indexes.up.sql":      {_004_indexesUpSql, map[string]*bintree{}},
 }}
 
 // RestoreAsset restores an asset under the given directory.
@@ -0,0 +1 @@
+ALTER TABLE auditlog ADD labels TEXT NULL;
@@ -0,0 +1 @@
+ALTER TABLE auditlog ADD request_id TEXT NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "auditlog_username_timestamp";
+DROP INDEX IF EXISTS "auditlog_remote_ip";
+DROP INDEX IF EXISTS "auditlog_action_timestamp";
+DROP INDEX IF EXISTS "auditlog_affected_id";
@@ -0,0 +1,21 @@
+-- The initial username and remote_ip indexes referenced non-existing columns.
+DROP INDEX IF EXISTS "auditlog_username";
+DROP INDEX IF EXISTS "auditlog_remote_ip";
+
+CREATE INDEX "auditlog_username_timestamp" ON `auditlog` (
+    "username" ASC,
+    "timestamp" ASC
+);
+
+CREATE INDEX "auditlog_remote_ip" ON `auditlog` (
+    "remote_ip" ASC
+);
+
+CREATE INDEX "auditlog_action_timestamp" ON `auditlog` (
+    "action" ASC,
+    "timestamp" ASC
+);
+
+CREATE INDEX "auditlog_affected_id" ON `auditlog` (
+    "affected_id" ASC
+);
@@ -0,0 +1,14 @@
+CREATE TABLE traffic (
+    day TEXT NOT NULL, -- YYYY-MM-DD in UTC
+    client_id TEXT NOT NULL,
+    username TEXT NOT NULL,
+    bytes_in INTEGER NOT NULL DEFAULT 0,
+    bytes_out INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, client_id, username)
+);
+
+CREATE INDEX idx_traffic_client_id
+    ON traffic (client_id, day);
+
+CREATE INDEX idx_traffic_username
+    ON traffic (username, day);
@@ -0,0 +1,12 @@
+CREATE TABLE samples (
+    timestamp DATETIME NOT NULL,
+    clients INTEGER NOT NULL,
+    connected_clients INTEGER NOT NULL,
+    max_clients INTEGER NOT NULL,
+    tunnels INTEGER NOT NULL,
+    ports_total INTEGER NOT NULL,
+    ports_used INTEGER NOT NULL
+);
+
+CREATE INDEX idx_samples_timestamp
+    ON samples (timestamp DESC);
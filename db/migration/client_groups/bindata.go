@@ -4,6 +4,12 @@
 // 001_init.up.sql (130B)
 // 002_add_allowed_user_groups.down.sql (0)
 // 002_add_allowed_user_groups.up.sql (79B)
+// 003_add_client_group_history.down.sql (85B)
+// 003_add_client_group_history.up.sql (371B)
+// 004_add_banner.down.sql (47B)
+// 004_add_banner.up.sql (64B)
+// 005_add_tunnel_bind_address.down.sql (61B)
+// 005_add_tunnel_bind_address.up.sql (78B)
 
 package client_groups
 
@@ -151,6 +157,126 @@ func _002_add_allowed_user_groupsUpSql() (*asset, error) {
 	return a, nil
 }
 
+var __003_add_client_group_historyDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x09\xf2\x0f\x50\xf0\xf4\x73\x71\x8d\x50\x48\xce\xc9\x4c\xcd\x2b\x89\x4f\x2f\xca\x2f\x2d\x88\xcf\xc8\x2c\x2e\xc9\x2f\xaa\x84\xf2\x32\x53\xe2\x4b\x32\x73\x53\x8b\x4b\x12\x73\x0b\xac\xb9\x5c\x40\x7a\x42\x1c\x9d\x7c\x5c\xb1\xea\xb1\xe6\x02\x00\x7e\x13\x46\xce\x55\x00\x00\x00")
+
+func _003_add_client_group_historyDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__003_add_client_group_historyDownSql,
+		"003_add_client_group_history.down.sql",
+	)
+}
+
+func _003_add_client_group_historyDownSql() (*asset, error) {
+	bytes, err := _003_add_client_group_historyDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "003_add_client_group_history.down.sql", size: 85, mode: os.FileMode(0644), modTime: time.Unix(1792043594, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x64, 0x4, 0x84, 0x9e, 0xee, 0x20, 0xb, 0xb2, 0x13, 0x47, 0xb4, 0x94, 0xf, 0x23, 0x7b, 0x64, 0xb4, 0x81, 0x68, 0x3e, 0xc2, 0x98, 0x6b, 0x5d, 0x12, 0x9b, 0xfa, 0xfa, 0xc1, 0x7a, 0xc2, 0xc1}}
+	return a, nil
+}
+
+var __003_add_client_group_historyUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x6d\x90\xc1\x0a\xc2\x30\x10\x44\xef\xfd\x8a\x3d\x2a\xf8\x07\x9e\x6a\x5d\x24\xd8\xa6\x12\x52\xa8\xa7\x50\xec\xaa\x81\x36\x29\x69\xaa\xf8\xf7\x06\x4b\x15\x24\x0b\x7b\x9a\x37\x0c\x33\x99\xc0\x54\x22\xc8\x74\x97\x23\x5c\x3a\x4d\xc6\xab\x9b\xb3\xd3\xa0\xee\x7a\xf4\xd6\xbd\x60\x95\x40\x38\xdd\x02\xe3\x12\x0f\x28\xe0\x24\x58\x91\x8a\x33\x1c\xf1\x0c\x69\x25\x4b\xc6\x33\x81\x05\x72\xb9\xf9\x90\xb3\x3b\xf0\x12\x6b\x09\xbc\x0c\x5f\xe5\xf9\xac\x79\xdd\xd3\xe8\x9b\x7e\x80\x7d\x48\x95\xac\xc0\x3f\x60\x1a\xc9\x99\xa6\xa7\x98\xb9\xb9\x78\x6d\x4d\x4c\xb1\x5d\xab\x5a\xba\x6a\xa3\xbf\xc4\x2c\x18\x7a\xc6\x05\x47\x0f\x72\x9e\x5a\x75\x75\xb6\x5f\x9a\x25\xeb\x6d\x92\xcd\x7b\x30\xbe\xc7\x3a\xba\x87\x5a\xfa\xa9\x5f\x99\x92\x47\xd1\xd5\x82\x6e\x7e\xc5\x43\xc4\x1b\x9a\xd7\xc1\x2d\x73\x01\x00\x00")
+
+func _003_add_client_group_historyUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__003_add_client_group_historyUpSql,
+		"003_add_client_group_history.up.sql",
+	)
+}
+
+func _003_add_client_group_historyUpSql() (*asset, error) {
+	bytes, err := _003_add_client_group_historyUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "003_add_client_group_history.up.sql", size: 371, mode: os.FileMode(0644), modTime: time.Unix(1792043594, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x25, 0x85, 0xe, 0xeb, 0x86, 0xa3, 0x3b, 0x4, 0x3e, 0x6, 0x1a, 0xbb, 0xfd, 0x28, 0x3c, 0x35, 0x58, 0x97, 0x9, 0x63, 0x79, 0x89, 0xb8, 0xc7, 0x1, 0x15, 0xb9, 0x49, 0x84, 0xdd, 0xe6, 0xe8}}
+	return a, nil
+}
+
+var __004_add_bannerDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x05\xc1\x81\x09\x00\x20\x08\x04\xc0\x55\x9e\xd6\x68\x98\xb0\x92\x08\x4c\xc5\x6c\xff\xee\x48\x92\x03\x49\x5d\x18\x65\xc8\x66\xcd\xb6\xc2\x9e\xdf\x82\x19\xe6\x18\x26\xef\x28\x3a\xa9\x72\xd4\x0f\xae\x4a\xf6\x80\x2f\x00\x00\x00")
+
+func _004_add_bannerDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__004_add_bannerDownSql,
+		"004_add_banner.down.sql",
+	)
+}
+
+func _004_add_bannerDownSql() (*asset, error) {
+	bytes, err := _004_add_bannerDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "004_add_banner.down.sql", size: 47, mode: os.FileMode(0644), modTime: time.Unix(1792044485, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xfa, 0xa9, 0xc8, 0xdb, 0x18, 0x4b, 0x8c, 0xa7, 0xcd, 0xd9, 0xbf, 0x8b, 0xfa, 0x54, 0x66, 0xf3, 0xfd, 0x60, 0x43, 0x2f, 0x10, 0x5b, 0x4b, 0x4d, 0xf0, 0xc6, 0x9a, 0xa8, 0xdc, 0x62, 0x43, 0x7c}}
+	return a, nil
+}
+
+var __004_add_bannerUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x4b\xcc\x29\x49\x2d\x52\x28\x49\x4c\xca\x49\x55\x50\x4a\xce\xc9\x4c\xcd\x2b\x89\x4f\x2f\xca\x2f\x2d\x28\x56\x52\x48\x4c\x49\x51\x48\x4a\xcc\xcb\x03\x2a\x08\x71\x8d\x08\x51\xf0\xf3\x07\xe2\x50\x1f\x1f\x05\x17\x57\x37\xc7\x50\x9f\x10\x05\x75\x75\x6b\x00\xc0\x55\xd3\x32\x40\x00\x00\x00")
+
+func _004_add_bannerUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__004_add_bannerUpSql,
+		"004_add_banner.up.sql",
+	)
+}
+
+func _004_add_bannerUpSql() (*asset, error) {
+	bytes, err := _004_add_bannerUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "004_add_banner.up.sql", size: 64, mode: os.FileMode(0644), modTime: time.Unix(1792044485, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xcf, 0xfd, 0x72, 0xe3, 0x45, 0xd8, 0x29, 0xa0, 0x91, 0x6b, 0x53, 0x6, 0xf4, 0xe8, 0x5a, 0x26, 0xea, 0x4d, 0x3d, 0x81, 0xe8, 0xad, 0xe3, 0xaf, 0xf5, 0x4f, 0xfb, 0xbf, 0xe8, 0x25, 0xe5, 0x45}}
+	return a, nil
+}
+
+var __005_add_tunnel_bind_addressDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x05\xc1\xdb\x09\xc0\x30\x08\x00\xc0\xff\x4e\x21\x59\x23\xc3\x88\x89\x52\x02\x56\x83\x8f\xfd\x7b\x47\x5a\x12\x50\xb4\x54\x60\x6c\x3d\x62\x85\x6f\x78\xdf\x1c\xc0\xe1\x17\xb6\x6b\x7f\x06\xd5\x66\xa2\xb8\x8e\x31\x12\x73\x48\xe6\x7c\x7e\xd9\x49\x27\x1b\x3d\x00\x00\x00")
+
+func _005_add_tunnel_bind_addressDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__005_add_tunnel_bind_addressDownSql,
+		"005_add_tunnel_bind_address.down.sql",
+	)
+}
+
+func _005_add_tunnel_bind_addressDownSql() (*asset, error) {
+	bytes, err := _005_add_tunnel_bind_addressDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "005_add_tunnel_bind_address.down.sql", size: 61, mode: os.FileMode(0644), modTime: time.Unix(1792053156, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xfc, 0x93, 0xca, 0x9b, 0x1a, 0xe1, 0xd2, 0xe3, 0x39, 0xe4, 0x3d, 0xf3, 0xdd, 0xaa, 0xeb, 0x72, 0x20, 0x4, 0x80, 0x21, 0xf7, 0x9, 0x88, 0x7e, 0x95, 0xdf, 0xba, 0xdc, 0xab, 0x4d, 0xe6, 0xe5}}
+	return a, nil
+}
+
+var __005_add_tunnel_bind_addressUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x4b\xcc\x29\x49\x2d\x52\x28\x49\x4c\xca\x49\x55\x50\x4a\xce\xc9\x4c\xcd\x2b\x89\x4f\x2f\xca\x2f\x2d\x28\x56\x52\x48\x4c\x49\x51\x28\x29\xcd\xcb\x4b\xcd\x89\x4f\xca\xcc\x4b\x89\x07\xf2\x8b\x52\x8b\x8b\x15\x42\x5c\x23\x42\x14\xfc\xfc\x81\x38\xd4\xc7\x47\xc1\xc5\xd5\xcd\x31\xd4\x27\x44\x41\x5d\xdd\x9a\x0b\x00\xfb\x33\x49\x97\x4e\x00\x00\x00")
+
+func _005_add_tunnel_bind_addressUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__005_add_tunnel_bind_addressUpSql,
+		"005_add_tunnel_bind_address.up.sql",
+	)
+}
+
+func _005_add_tunnel_bind_addressUpSql() (*asset, error) {
+	bytes, err := _005_add_tunnel_bind_addressUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "005_add_tunnel_bind_address.up.sql", size: 78, mode: os.FileMode(0644), modTime: time.Unix(1792053156, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xcb, 0xdb, 0x42, 0x28, 0x94, 0xe7, 0xdc, 0x59, 0x3a, 0xa6, 0xfe, 0x58, 0xd8, 0x32, 0x7c, 0xa6, 0xf4, 0xf5, 0xeb, 0x70, 0xff, 0x39, 0x1f, 0x7b, 0xc2, 0x0, 0x25, 0xe9, 0xd, 0x82, 0xc6, 0x96}}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -242,10 +368,16 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
-	"001_init.down.sql":                    _001_initDownSql,
-	"001_init.up.sql":                      _001_initUpSql,
-	"002_add_allowed_user_groups.down.sql": _002_add_allowed_user_groupsDownSql,
-	"002_add_allowed_user_groups.up.sql":   _002_add_allowed_user_groupsUpSql,
+	"001_init.down.sql":                     _001_initDownSql,
+	"001_init.up.sql":                       _001_initUpSql,
+	"002_add_allowed_user_groups.down.sql":  _002_add_allowed_user_groupsDownSql,
+	"002_add_allowed_user_groups.up.sql":    _002_add_allowed_user_groupsUpSql,
+	"003_add_client_group_history.down.sql": _003_add_client_group_historyDownSql,
+	"003_add_client_group_history.up.sql":   _003_add_client_group_historyUpSql,
+	"004_add_banner.down.sql":               _004_add_bannerDownSql,
+	"004_add_banner.up.sql":                 _004_add_bannerUpSql,
+	"005_add_tunnel_bind_address.down.sql":  _005_add_tunnel_bind_addressDownSql,
+	"005_add_tunnel_bind_address.up.sql":    _005_add_tunnel_bind_addressUpSql,
 }
 
 // AssetDebug is true if the assets were built with the debug flag enabled.
@@ -294,10 +426,16 @@ type bintree struct {
 }
 
 var _bintree = &bintree{nil, map[string]*bintree{
-	"001_init.down.sql":                    {_001_initDownSql, map[string]*bintree{}},
-	"001_init.up.sql":                      {_001_initUpSql, map[string]*bintree{}},
-	"002_add_allowed_user_groups.down.sql": {_002_add_allowed_user_groupsDownSql, map[string]*bintree{}},
-	"002_add_allowed_user_groups.up.sql":   {_002_add_allowed_user_groupsUpSql, map[string]*bintree{}},
+	"001_init.down.sql":                     {_001_initDownSql, map[string]*bintree{}},
+	"001_init.up.sql":                       {_001_initUpSql, map[string]*bintree{}},
+	"002_add_allowed_user_groups.down.sql":  {_002_add_allowed_user_groupsDownSql, map[string]*bintree{}},
+	"002_add_allowed_user_groups.up.sql":    {_002_add_allowed_user_groupsUpSql, map[string]*bintree{}},
+	"003_add_client_group_history.down.sql": {_003_add_client_group_historyDownSql, map[string]*bintree{}},
+	"003_add_client_group_history.up.sql":   {_003_add_client_group_historyUpSql, map[string]*bintree{}},
+	"004_add_banner.down.sql":               {_004_add_bannerDownSql, map[string]*bintree{}},
+	"004_add_banner.up.sql":                 {_004_add_bannerUpSql, map[string]*bintree{}},
+	"005_add_tunnel_bind_address.down.sql":  {_005_add_tunnel_bind_addressDownSql, map[string]*bintree{}},
+	"005_add_tunnel_bind_address.up.sql":    {_005_add_tunnel_bind_addressUpSql, map[string]*bintree{}},
 }}
 
 // RestoreAsset restores an asset under the given directory.
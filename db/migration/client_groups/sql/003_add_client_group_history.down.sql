@@ -0,0 +1,2 @@
+DROP INDEX client_group_history_group_id_timestamp;
+DROP TABLE client_group_history;
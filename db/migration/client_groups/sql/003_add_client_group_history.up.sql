@@ -0,0 +1,11 @@
+CREATE TABLE client_group_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    group_id TEXT NOT NULL,
+    timestamp DATETIME NOT NULL,
+    username TEXT NOT NULL,
+    action TEXT NOT NULL,
+    old_definition TEXT,
+    new_definition TEXT,
+    reverted_from INTEGER
+);
+CREATE INDEX client_group_history_group_id_timestamp ON client_group_history(group_id, timestamp);
@@ -0,0 +1 @@
+alter table "client_groups" drop column banner;
\ No newline at end of file
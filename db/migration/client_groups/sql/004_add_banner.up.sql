@@ -0,0 +1 @@
+alter table "client_groups" add banner TEXT NOT NULL DEFAULT '';
\ No newline at end of file
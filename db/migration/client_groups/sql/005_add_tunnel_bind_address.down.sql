@@ -0,0 +1 @@
+alter table "client_groups" drop column tunnel_bind_address;
@@ -0,0 +1 @@
+alter table "client_groups" add tunnel_bind_address TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+DROP INDEX idx_snapshots_timestamp;
+DROP INDEX idx_snapshots_client_id;
+DROP TABLE snapshots;
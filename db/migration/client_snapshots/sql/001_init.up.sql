@@ -0,0 +1,13 @@
+CREATE TABLE snapshots (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    client_id TEXT NOT NULL,
+    timestamp DATETIME NOT NULL,
+    trigger TEXT NOT NULL,
+    state TEXT NOT NULL
+);
+
+CREATE INDEX idx_snapshots_client_id
+    ON snapshots (client_id, timestamp);
+
+CREATE INDEX idx_snapshots_timestamp
+    ON snapshots (timestamp);
@@ -6,6 +6,10 @@
 // 002_stored_tunnels.up.sql (251B)
 // 003_add_tunnel_fields.down.sql (0)
 // 003_add_tunnel_fields.up.sql (104B)
+// 004_client_changes.down.sql (27B)
+// 004_client_changes.up.sql (271B)
+// 005_gateway_targets.down.sql (67B)
+// 005_gateway_targets.up.sql (404B)
 
 package clients
 
@@ -73,7 +77,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var __001_initDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x09\xf2\x0f\x50\xf0\xf4\x73\x71\x8d\x50\xc8\x4c\xa9\x88\x4f\xc9\x2c\x4e\xce\xcf\xcb\x4b\x4d\x2e\x49\x4d\x89\x2f\xc9\xcc\x4d\x8d\x4f\xce\xc9\x4c\xcd\x2b\xb1\xe6\xe2\xc2\xa7\x12\x55\x51\x88\xa3\x93\x8f\xab\x02\x44\xac\xd8\x9a\x0b\x10\x00\x00\xff\xff\x49\xd7\x0b\xc9\x63\x00\x00\x00")
+var __001_initDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x72\x09\xf2\x0f\x50\xf0\xf4\x73\x71\x8d\x50\xc8\x4c\xa9\x88\x4f\xc9\x2c\x4e\xce\xcf\xcb\x4b\x4d\x2e\x49\x4d\x89\x2f\xc9\xcc\x4d\x8d\x4f\xce\xc9\x4c\xcd\x2b\xb1\xe6\xe2\xc2\xa7\x12\x55\x51\x88\xa3\x93\x8f\xab\x02\x44\xac\xd8\x9a\x0b\x30\x00\x49\xd7\x0b\xc9\x63\x00\x00\x00")
 
 func _001_initDownSqlBytes() ([]byte, error) {
 	return bindataRead(
@@ -88,12 +92,12 @@ func _001_initDownSql() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "001_init.down.sql", size: 99, mode: os.FileMode(0644), modTime: time.Unix(1685339920, 0)}
+	info := bindataFileInfo{name: "001_init.down.sql", size: 99, mode: os.FileMode(0644), modTime: time.Unix(1689734542, 0)}
 	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xcd, 0xac, 0x7d, 0x7a, 0x69, 0xc0, 0x2e, 0x3, 0xab, 0xa5, 0x5e, 0xdd, 0x7f, 0xe1, 0xa5, 0x36, 0xba, 0x42, 0x8a, 0xc2, 0x59, 0x2b, 0x3b, 0xc5, 0xdf, 0xad, 0x4e, 0x98, 0x11, 0xc7, 0x83, 0x8c}}
 	return a, nil
 }
 
-var __001_initUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x84\x8f\x41\x6a\x85\x30\x14\x45\xc7\x66\x15\x77\x58\xc1\x1d\x74\x94\xea\x83\x86\x6a\x52\xd2\x27\xea\x28\x88\x09\x34\x60\xed\xc0\x14\xba\xfc\xd2\x5a\xb1\xf6\xf3\xf9\xe3\xdc\x9c\x73\x5e\x69\x49\x32\x81\xe5\x43\x4d\x98\xe6\x18\x96\xb4\xe2\x4e\x00\x40\xf4\x60\xea\x19\xcf\x56\x35\xd2\x0e\x78\xa2\x01\xda\x30\x74\x5b\xd7\x85\xc8\xb6\xb1\x1b\x3f\xd2\xab\xdb\xa7\xc7\xf3\x37\xc0\xc7\x75\x7a\x5f\x96\x30\xa5\xe0\xdd\x98\x50\x49\x26\x56\x0d\x15\x22\xf3\x21\x8d\x71\x5e\xcf\xbf\x44\x8e\x4e\xf1\xa3\x69\x19\xd6\x74\xaa\xba\x17\xe2\x37\x4f\xe9\x8a\x7a\x44\xff\xe9\x4e\xcc\x2d\xe1\xc7\x65\xf4\x51\x7f\xe1\xa5\x97\xb2\xc0\xb9\x37\xbf\x09\x4f\xf1\x2d\xec\x86\xec\x2f\x7e\x3f\xe3\xbf\x27\xbf\x26\xfa\x0a\x00\x00\xff\xff\x7e\x6a\x27\xc9\x64\x01\x00\x00")
+var __001_initUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x84\x8f\xc1\x6a\x84\x30\x14\x45\xd7\xe6\x2b\xee\xb2\x82\x7f\xd0\x55\xaa\x0f\x1a\xaa\x49\x49\x9f\xa8\xab\x20\x26\xd0\x80\xb5\x0b\x53\xe8\xe7\x17\x6a\xc5\x3a\xc3\x30\xeb\xdc\x9c\x73\x5e\x69\x49\x32\x81\xe5\x53\x4d\x98\xe6\x18\x96\xb4\xe2\x41\x00\x40\xf4\x60\xea\x19\xaf\x56\x35\xd2\x0e\x78\xa1\x01\xda\x30\x74\x5b\xd7\x85\xc8\xb6\xb1\x1b\xbf\xd2\xbb\xdb\xa7\xc7\x33\x00\xf8\xb8\x4e\x9f\xcb\x12\xa6\x14\xbc\x1b\x13\x2a\xc9\xc4\xaa\xa1\x42\x64\x3e\xa4\x31\xce\xeb\xf9\x97\xc8\xd1\x29\x7e\x36\x2d\xc3\x9a\x4e\x55\x8f\x42\xfc\xe5\x29\x5d\x51\x8f\xe8\xbf\xdd\x89\xb9\x25\xfc\xba\x8c\x3e\xea\xaf\xbc\xf4\x56\x16\x38\xf7\xe6\x77\xe1\x29\x7e\x84\xdd\x90\xfd\xc7\xef\x67\x5c\x7a\xf2\x5b\xa2\x9f\x01\x00\x7e\x6a\x27\xc9\x64\x01\x00\x00")
 
 func _001_initUpSqlBytes() ([]byte, error) {
 	return bindataRead(
@@ -108,12 +112,12 @@ func _001_initUpSql() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "001_init.up.sql", size: 356, mode: os.FileMode(0644), modTime: time.Unix(1685339920, 0)}
+	info := bindataFileInfo{name: "001_init.up.sql", size: 356, mode: os.FileMode(0644), modTime: time.Unix(1689734542, 0)}
 	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xc3, 0x2b, 0xa4, 0xcd, 0x5a, 0x2b, 0x3, 0x1a, 0x8b, 0x97, 0x4d, 0xb1, 0x36, 0xc6, 0x80, 0x38, 0x46, 0x9d, 0x65, 0x4e, 0x84, 0xf1, 0x50, 0x2d, 0x65, 0xca, 0xdc, 0x5a, 0xb7, 0xef, 0x7d, 0x1b}}
 	return a, nil
 }
 
-var __002_stored_tunnelsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\x28\x2e\xc9\x2f\x4a\x4d\x89\x2f\x29\xcd\xcb\x4b\xcd\x29\xb6\xe6\x02\x04\x00\x00\xff\xff\x25\xf8\x88\xbe\x1b\x00\x00\x00")
+var __002_stored_tunnelsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\x28\x2e\xc9\x2f\x4a\x4d\x89\x2f\x29\xcd\xcb\x4b\xcd\x29\xb6\xe6\x02\x0c\x00\x25\xf8\x88\xbe\x1b\x00\x00\x00")
 
 func _002_stored_tunnelsDownSqlBytes() ([]byte, error) {
 	return bindataRead(
@@ -128,12 +132,12 @@ func _002_stored_tunnelsDownSql() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "002_stored_tunnels.down.sql", size: 27, mode: os.FileMode(0644), modTime: time.Unix(1685339920, 0)}
+	info := bindataFileInfo{name: "002_stored_tunnels.down.sql", size: 27, mode: os.FileMode(0644), modTime: time.Unix(1689734542, 0)}
 	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x9b, 0x91, 0x9f, 0x67, 0xc1, 0xc6, 0x9c, 0x33, 0x35, 0xc4, 0xe8, 0xaa, 0xc8, 0x4f, 0x65, 0xdc, 0x44, 0xbc, 0xa7, 0xf8, 0x25, 0x26, 0x9a, 0x21, 0xb9, 0x30, 0xc9, 0x6a, 0x11, 0x5a, 0x9d, 0xc2}}
 	return a, nil
 }
 
-var __002_stored_tunnelsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x64\x8c\x4b\x6a\xc3\x30\x14\x45\xe7\x5e\xc5\x1d\x26\xd0\x1d\x74\xa4\xc8\xaf\x10\x2a\x2b\x45\x79\x81\x66\x24\x84\xf5\xa0\x02\x47\x0e\x92\xba\xff\x82\x3f\x83\x92\xe1\x3d\xf7\x70\xb4\x23\xc5\x04\x56\x27\x43\xa8\x6d\x2e\x12\x7d\xfb\xcd\x59\xa6\x8a\x43\x07\x00\x29\x82\xe9\x9b\xf1\xe5\xce\x83\x72\x77\x7c\xd2\x1d\xf6\xc2\xb0\x37\x63\xde\x16\x63\x9c\x92\xe4\xe6\x77\x71\x3f\xe1\xe8\x83\x1c\x59\x4d\xd7\x4d\xa9\x87\x14\x8f\xb8\x58\xf4\x64\x88\x09\x5a\x5d\xb5\xea\x69\xab\x14\x09\x4d\xa2\x0f\x0d\xbd\x62\xe2\xf3\xb0\x1d\x39\x3c\x64\x29\xaf\xb3\x8e\x3f\xf2\x0f\x14\x79\xcc\x4d\x7c\x7a\xbe\xb2\xe7\x5c\x1a\xec\x6d\x38\x91\x5b\x79\x18\xa7\xc5\xea\x8e\xef\xdd\x5f\x00\x00\x00\xff\xff\x04\x1b\x73\xc3\xfb\x00\x00\x00")
+var __002_stored_tunnelsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x64\x8c\x4b\x6a\xc3\x30\x14\x45\xe7\x5e\xc5\x1d\x26\xd0\x1d\x74\xa4\xc8\xaf\x10\x2a\x2b\x45\x79\x81\x66\x24\x84\xf5\xa0\x02\x47\x0e\x92\xba\xff\x82\x3f\x83\x92\xe1\x3d\xf7\x70\xb4\x23\xc5\x04\x56\x27\x43\xa8\x6d\x2e\x12\x7d\xfb\xcd\x59\xa6\x8a\x43\x07\x00\x29\x82\xe9\x9b\xf1\xe5\xce\x83\x72\x77\x7c\xd2\x1d\xf6\xc2\xb0\x37\x63\xde\x16\x63\x9c\x92\xe4\xe6\x77\x71\x3f\xe1\xe8\x83\x1c\x59\x4d\xd7\x4d\xa9\x87\x14\x8f\xb8\x58\xf4\x64\x88\x09\x5a\x5d\xb5\xea\x69\xab\x14\x09\x4d\xa2\x0f\x0d\xbd\x62\xe2\xf3\xb0\x1d\x39\x3c\x64\x29\xaf\xb3\x8e\x3f\xf2\x0f\x14\x79\xcc\x4d\x7c\x7a\xbe\xb2\xe7\x5c\x1a\xec\x6d\x38\x91\x5b\x79\x18\xa7\xc5\xea\x8e\xef\xdd\xdf\x00\x04\x1b\x73\xc3\xfb\x00\x00\x00")
 
 func _002_stored_tunnelsUpSqlBytes() ([]byte, error) {
 	return bindataRead(
@@ -148,12 +152,12 @@ func _002_stored_tunnelsUpSql() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "002_stored_tunnels.up.sql", size: 251, mode: os.FileMode(0644), modTime: time.Unix(1685339920, 0)}
+	info := bindataFileInfo{name: "002_stored_tunnels.up.sql", size: 251, mode: os.FileMode(0644), modTime: time.Unix(1689734542, 0)}
 	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x28, 0xac, 0x2a, 0x25, 0x9b, 0xf5, 0xe, 0xdd, 0xa3, 0x6b, 0x3c, 0x5c, 0xf, 0x55, 0x6c, 0x1d, 0x6f, 0x71, 0x6, 0x96, 0xa8, 0x52, 0x61, 0x14, 0x8c, 0xf8, 0xbd, 0x3f, 0x22, 0x40, 0x7, 0x11}}
 	return a, nil
 }
 
-var __003_add_tunnel_fieldsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x01\x00\x00\xff\xff\x00\x00\x00\x00\x00\x00\x00\x00")
+var __003_add_tunnel_fieldsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x03\x00\x00\x00\x00\x00\x00\x00\x00\x00")
 
 func _003_add_tunnel_fieldsDownSqlBytes() ([]byte, error) {
 	return bindataRead(
@@ -168,12 +172,12 @@ func _003_add_tunnel_fieldsDownSql() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "003_add_tunnel_fields.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(1685339920, 0)}
+	info := bindataFileInfo{name: "003_add_tunnel_fields.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(1689734542, 0)}
 	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xe3, 0xb0, 0xc4, 0x42, 0x98, 0xfc, 0x1c, 0x14, 0x9a, 0xfb, 0xf4, 0xc8, 0x99, 0x6f, 0xb9, 0x24, 0x27, 0xae, 0x41, 0xe4, 0x64, 0x9b, 0x93, 0x4c, 0xa4, 0x95, 0x99, 0x1b, 0x78, 0x52, 0xb8, 0x55}}
 	return a, nil
 }
 
-var __003_add_tunnel_fieldsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x28\x2e\xc9\x2f\x4a\x4d\x89\x2f\x29\xcd\xcb\x4b\xcd\x29\x56\x70\x74\x71\x51\x28\x28\x4d\xca\xc9\x4c\x8e\x2f\xc8\x2f\x2a\x51\xf0\x0b\xf5\x75\x72\x0d\xb2\xe6\x22\xa0\x25\xad\xb4\xa8\x24\x23\xb5\x28\x3e\xbf\xa0\x24\x33\x3f\xaf\x58\x21\xc4\x35\x22\xc4\x9a\x0b\x10\x00\x00\xff\xff\x0a\x14\xe8\x34\x68\x00\x00\x00")
+var __003_add_tunnel_fieldsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x28\x2e\xc9\x2f\x4a\x4d\x89\x2f\x29\xcd\xcb\x4b\xcd\x29\x56\x70\x74\x71\x51\x28\x28\x4d\xca\xc9\x4c\x8e\x2f\xc8\x2f\x2a\x51\xf0\x0b\xf5\x75\x72\x0d\xb2\xe6\x22\xa0\x25\xad\xb4\xa8\x24\x23\xb5\x28\x3e\xbf\xa0\x24\x33\x3f\xaf\x58\x21\xc4\x35\x22\xc4\x9a\x0b\x30\x00\x0a\x14\xe8\x34\x68\x00\x00\x00")
 
 func _003_add_tunnel_fieldsUpSqlBytes() ([]byte, error) {
 	return bindataRead(
@@ -188,11 +192,91 @@ func _003_add_tunnel_fieldsUpSql() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "003_add_tunnel_fields.up.sql", size: 104, mode: os.FileMode(0644), modTime: time.Unix(1685339920, 0)}
+	info := bindataFileInfo{name: "003_add_tunnel_fields.up.sql", size: 104, mode: os.FileMode(0644), modTime: time.Unix(1689734542, 0)}
 	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xc8, 0xb4, 0x56, 0x2e, 0x4f, 0x67, 0x61, 0xf6, 0x5e, 0xa9, 0xbe, 0x37, 0xcd, 0xfb, 0x4, 0x18, 0xa, 0xd8, 0xd5, 0xac, 0x95, 0x52, 0x7c, 0x6c, 0xfc, 0x48, 0xbe, 0x4c, 0xb0, 0x70, 0x1e, 0xbe}}
 	return a, nil
 }
 
+var __004_client_changesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\x48\xce\xc9\x4c\xcd\x2b\x89\x4f\xce\x48\xcc\x4b\x4f\x2d\xb6\xe6\x02\x0c\x00\xc3\x79\x09\xe3\x1b\x00\x00\x00")
+
+func _004_client_changesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__004_client_changesDownSql,
+		"004_client_changes.down.sql",
+	)
+}
+
+func _004_client_changesDownSql() (*asset, error) {
+	bytes, err := _004_client_changesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "004_client_changes.down.sql", size: 27, mode: os.FileMode(0644), modTime: time.Unix(1792004129, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x96, 0x36, 0x31, 0xcb, 0x7a, 0x59, 0x8d, 0x58, 0x98, 0x69, 0x9a, 0xa3, 0x3, 0x23, 0x6c, 0x84, 0xb9, 0xe9, 0x21, 0xe, 0x14, 0x95, 0xfb, 0x32, 0x3e, 0xfa, 0xc6, 0x13, 0x93, 0x7b, 0x21, 0x1c}}
+	return a, nil
+}
+
+var __004_client_changesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x6c\x8f\xc1\xca\x82\x40\x14\x85\xf7\xf3\x14\x67\xf9\x0b\xff\x1b\xb8\x9a\xf4\x12\x43\x7a\x8d\xe1\x0a\xba\x12\xd1\xa1\x06\xd2\x24\x25\xea\xed\x03\x33\x21\x72\x7b\xef\x77\xce\xe1\x8b\x2c\x69\x21\x88\xde\x25\x84\xe6\xe2\x5d\x3f\x55\xcd\xb9\xee\x4f\x6e\xc4\x9f\x02\x80\x9b\xbb\xfb\xd1\x5f\x7b\x18\x16\xda\x93\xc5\xd1\x9a\x54\xdb\x12\x07\x2a\xa1\x73\xc9\x0c\x47\x96\x52\x62\xf9\x9f\xf9\xa5\xc4\xb7\x10\x2a\x04\x9c\x09\x38\x4f\x92\xf7\x73\x7a\x0e\x6e\xeb\xfe\x99\xdc\x8a\xf8\xce\x8d\x53\xdd\x0d\x88\xb5\x90\x98\x94\x56\x40\x05\xa1\x52\x8b\x81\xe1\x98\x0a\xf8\xf6\x51\x7d\x5b\x54\x6b\x7e\x6e\xcb\xf8\xc7\x72\x05\x82\x50\xbd\x06\x00\x48\x4e\xa7\xed\x0f\x01\x00\x00")
+
+func _004_client_changesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__004_client_changesUpSql,
+		"004_client_changes.up.sql",
+	)
+}
+
+func _004_client_changesUpSql() (*asset, error) {
+	bytes, err := _004_client_changesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "004_client_changes.up.sql", size: 271, mode: os.FileMode(0644), modTime: time.Unix(1792004129, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xfa, 0x36, 0xa1, 0x5e, 0xe3, 0xd8, 0x6a, 0x2d, 0x7a, 0x8c, 0xbb, 0x2b, 0xa7, 0xf6, 0x4e, 0xbd, 0x5b, 0xb7, 0x9a, 0x9c, 0xa2, 0x1e, 0x5d, 0x73, 0xc2, 0x7b, 0xa3, 0x49, 0x6e, 0xe7, 0x73, 0x8c}}
+	return a, nil
+}
+
+var __005_gateway_targetsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x09\xf2\x0f\x50\xf0\xf4\x73\x71\x8d\x50\xc8\x4c\xa9\x88\x4f\x4f\x2c\x49\x2d\x4f\xac\x8c\x2f\x49\x2c\x4a\x4f\x2d\x29\x8e\x4f\xce\xc9\x4c\xcd\x2b\xb1\xe6\x72\x01\x29\x0b\x71\x74\xf2\x71\x55\x40\x53\x62\xcd\x05\x00\x8d\x78\x35\x73\x43\x00\x00\x00")
+
+func _005_gateway_targetsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__005_gateway_targetsDownSql,
+		"005_gateway_targets.down.sql",
+	)
+}
+
+func _005_gateway_targetsDownSql() (*asset, error) {
+	bytes, err := _005_gateway_targetsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "005_gateway_targets.down.sql", size: 67, mode: os.FileMode(0644), modTime: time.Unix(1792031192, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x30, 0xce, 0xbd, 0x75, 0x14, 0xf2, 0x90, 0x2e, 0xa3, 0x2e, 0x94, 0x85, 0x3, 0x68, 0xe2, 0xda, 0x6c, 0xdb, 0xc6, 0xe6, 0xc6, 0xc0, 0xde, 0x49, 0xb0, 0x81, 0x39, 0x10, 0xa3, 0x88, 0x37, 0xb4}}
+	return a, nil
+}
+
+var __005_gateway_targetsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x6d\x90\xcb\x0e\xc2\x20\x10\x45\xf7\xfd\x8a\x59\xda\xc4\x3f\x70\x45\xe9\x98\x34\xb6\x68\x28\x26\xba\x22\xc4\x92\x4a\x7c\xd4\x00\xbe\xfe\x5e\xb4\xad\x51\x2b\x3b\xee\xdc\xb9\x33\x73\x28\x47\x22\x10\x04\x49\x72\x84\x5a\x79\x7d\x55\x77\xe9\x95\xad\xb5\x77\x30\x8a\x20\x3c\x53\x81\xc0\x95\x80\x05\xcf\x0a\xc2\xd7\x30\xc3\x35\xb0\xb9\x00\xb6\xcc\xf3\xf1\xcb\xd1\xf7\x6d\xf6\x46\x1f\xbd\xec\x1b\x7a\x13\x70\x9c\x22\x47\x46\xb1\x84\xd6\xe2\x46\xa6\x8a\x61\xce\x20\xc5\x1c\xc3\x78\x4a\x4a\x4a\x52\x6c\xd3\x36\x56\x87\xbc\x4a\x2a\x0f\x69\xd8\x4d\x64\x45\x57\x38\xaa\x83\xfe\x4e\x6e\xf5\x6d\xe3\xfc\x3f\xfd\xd4\x58\x1f\xbe\x45\x82\xfc\xa7\x72\x76\xda\xbe\xd3\x3a\xb3\x72\xee\xda\xd8\xea\x53\xb2\xe6\x12\x36\x91\x3b\x7d\xff\x50\x9f\xd3\xde\x52\x14\x4f\xa2\x88\xb6\x0c\x33\x96\xe2\x2a\xd0\xba\xc9\x1f\x8e\x1d\x97\xe7\xbd\x03\xc2\x03\x74\x21\xf0\x01\x76\xda\xc5\xea\x94\x01\x00\x00")
+
+func _005_gateway_targetsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__005_gateway_targetsUpSql,
+		"005_gateway_targets.up.sql",
+	)
+}
+
+func _005_gateway_targetsUpSql() (*asset, error) {
+	bytes, err := _005_gateway_targetsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "005_gateway_targets.up.sql", size: 404, mode: os.FileMode(0644), modTime: time.Unix(1792031192, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x54, 0xb6, 0x36, 0xac, 0xce, 0x5e, 0x5e, 0xd0, 0xa1, 0x49, 0x8e, 0xce, 0xff, 0xe3, 0x42, 0x30, 0x95, 0xb8, 0x2e, 0x40, 0x8, 0x22, 0x61, 0xf7, 0x61, 0x82, 0xdb, 0x18, 0x50, 0xc1, 0x51, 0xcc}}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -290,6 +374,10 @@ var _bindata = map[string]func() (*asset, error){
 	"002_stored_tunnels.up.sql":      _002_stored_tunnelsUpSql,
 	"003_add_tunnel_fields.down.sql": _003_add_tunnel_fieldsDownSql,
 	"003_add_tunnel_fields.up.sql":   _003_add_tunnel_fieldsUpSql,
+	"004_client_changes.down.sql":    _004_client_changesDownSql,
+	"004_client_changes.up.sql":      _004_client_changesUpSql,
+	"005_gateway_targets.down.sql":   _005_gateway_targetsDownSql,
+	"005_gateway_targets.up.sql":     _005_gateway_targetsUpSql,
 }
 
 // AssetDebug is true if the assets were built with the debug flag enabled.
@@ -344,6 +432,10 @@ var _bintree = &bintree{nil, map[string]*bintree{
 	"002_stored_tunnels.up.sql":      {_002_stored_tunnelsUpSql, map[string]*bintree{}},
 	"003_add_tunnel_fields.down.sql": {_003_add_tunnel_fieldsDownSql, map[string]*bintree{}},
 	"003_add_tunnel_fields.up.sql":   {_003_add_tunnel_fieldsUpSql, map[string]*bintree{}},
+	"004_client_changes.down.sql":    {_004_client_changesDownSql, map[string]*bintree{}},
+	"004_client_changes.up.sql":      {_004_client_changesUpSql, map[string]*bintree{}},
+	"005_gateway_targets.down.sql":   {_005_gateway_targetsDownSql, map[string]*bintree{}},
+	"005_gateway_targets.up.sql":     {_005_gateway_targetsUpSql, map[string]*bintree{}},
 }}
 
 // RestoreAsset restores an asset under the given directory.
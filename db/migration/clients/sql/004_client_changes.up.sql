@@ -0,0 +1,10 @@
+CREATE TABLE client_changes (
+    revision INTEGER PRIMARY KEY AUTOINCREMENT,
+    client_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    changes TEXT NOT NULL,
+    timestamp DATETIME NOT NULL
+);
+
+CREATE INDEX idx_client_changes_timestamp
+    ON client_changes (timestamp);
@@ -0,0 +1,2 @@
+DROP INDEX idx_gateway_targets_client;
+DROP TABLE gateway_targets;
@@ -0,0 +1,14 @@
+CREATE TABLE gateway_targets (
+    id TEXT PRIMARY KEY NOT NULL,
+    gateway_client_id TEXT NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    created_at DATETIME,
+    name TEXT NOT NULL,
+    host TEXT NOT NULL,
+    port NUMBER NOT NULL,
+    username TEXT,
+    password TEXT,
+    private_key TEXT,
+    host_key TEXT
+);
+
+CREATE INDEX idx_gateway_targets_client ON gateway_targets (gateway_client_id);
@@ -0,0 +1,6 @@
+CREATE TABLE feature_flags (
+    name TEXT PRIMARY KEY NOT NULL,
+    rollout TEXT NOT NULL,
+    updated_by TEXT NOT NULL,
+    updated_at DATETIME NOT NULL
+);
@@ -6,6 +6,14 @@
 // 002_schedules.up.sql (228B)
 // 003_multi_job_schedule_id.down.sql (0)
 // 003_multi_job_schedule_id.up.sql (50B)
+// 004_multi_job_dispatch.down.sql (31B)
+// 004_multi_job_dispatch.up.sql (281B)
+// 005_runbooks.down.sql (54B)
+// 005_runbooks.up.sql (559B)
+// 006_runbook_remediation_results.down.sql (364B)
+// 006_runbook_remediation_results.up.sql (613B)
+// 007_multi_job_summaries.down.sql (32B)
+// 007_multi_job_summaries.up.sql (233B)
 
 package jobs
 
@@ -193,6 +201,166 @@ func _003_multi_job_schedule_idUpSql() (*asset, error) {
 	return a, nil
 }
 
+var __004_multi_job_dispatchDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xc8\x2d\xcd\x29\xc9\x8c\xcf\xca\x4f\x8a\x4f\xc9\x2c\x2e\x48\x2c\x49\xce\xb0\xe6\x02\x00\x83\xb8\x9e\xd4\x1f\x00\x00\x00")
+
+func _004_multi_job_dispatchDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__004_multi_job_dispatchDownSql,
+		"004_multi_job_dispatch.down.sql",
+	)
+}
+
+func _004_multi_job_dispatchDownSql() (*asset, error) {
+	bytes, err := _004_multi_job_dispatchDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "004_multi_job_dispatch.down.sql", size: 31, mode: os.FileMode(0644), modTime: time.Unix(1792051499, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xfc, 0xfb, 0x9c, 0xea, 0x94, 0x9c, 0x1b, 0xa1, 0x5c, 0x3d, 0xa5, 0x7d, 0x42, 0x9, 0xa6, 0x7, 0x33, 0xc6, 0x20, 0x34, 0xd6, 0x28, 0xf4, 0xa4, 0x17, 0xdc, 0x9a, 0x3c, 0x42, 0x11, 0xf6, 0x5f}}
+	return a, nil
+}
+
+var __004_multi_job_dispatchUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x75\xcf\x41\x0e\x82\x30\x10\x05\xd0\x3d\xa7\x98\x25\x24\xdc\xc0\x55\x2d\x83\x36\x96\xd6\x94\x12\x64\x45\x10\x9a\x58\x82\x4a\x6c\x4d\x3c\xbe\x28\x0b\x4c\xd4\x49\x66\xf5\x7e\xf2\x67\xa8\x42\xa2\x11\x34\x59\x73\x84\xf3\x7d\xf0\xb6\xee\xaf\xc7\xba\xb3\x6e\x6c\x7c\x7b\x82\x30\x80\x69\x16\xb0\x1d\x68\x3c\x68\x10\x72\xda\x82\xf3\xf8\xed\xed\x60\xcd\xc5\xff\x41\xe7\x1b\x6f\x7e\x81\x79\x8c\xf6\x66\x5c\xdd\x78\x48\xa6\x23\x34\xcb\x70\x86\xbd\x62\x19\x51\x15\xec\xb0\x82\xf0\xb3\x3b\x5e\x9a\xa2\x39\x9a\x4a\x85\x6c\x23\xbe\xa3\x11\x28\x4c\x51\xa1\xa0\x98\x2f\xf7\xbb\xb0\x7f\x91\x14\x90\x20\xc7\xe9\x71\x4a\x72\x4a\x12\x0c\x22\x28\x99\xde\xca\x42\x83\x92\x25\x4b\x56\xc1\x13\x0b\x9d\x07\x73\x19\x01\x00\x00")
+
+func _004_multi_job_dispatchUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__004_multi_job_dispatchUpSql,
+		"004_multi_job_dispatch.up.sql",
+	)
+}
+
+func _004_multi_job_dispatchUpSql() (*asset, error) {
+	bytes, err := _004_multi_job_dispatchUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "004_multi_job_dispatch.up.sql", size: 281, mode: os.FileMode(0644), modTime: time.Unix(1792051499, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa8, 0xd1, 0xee, 0x90, 0x94, 0xac, 0x6c, 0x77, 0xa8, 0x8b, 0x4c, 0xa, 0x73, 0xd8, 0x53, 0x24, 0xea, 0x7d, 0x72, 0x78, 0xb0, 0xbd, 0x68, 0xe6, 0xfd, 0x61, 0x2d, 0xe1, 0x84, 0x5, 0xc4, 0x22}}
+	return a, nil
+}
+
+var __005_runbooksDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\x28\x2a\xcd\x4b\xca\xcf\xcf\x8e\x2f\x4a\xcd\x4d\x4d\xc9\x4c\x2c\xc9\xcc\xcf\x2b\xb6\xe6\x72\xc1\x50\x00\x14\x04\x00\xcd\xe7\x39\xc7\x36\x00\x00\x00")
+
+func _005_runbooksDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__005_runbooksDownSql,
+		"005_runbooks.down.sql",
+	)
+}
+
+func _005_runbooksDownSql() (*asset, error) {
+	bytes, err := _005_runbooksDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "005_runbooks.down.sql", size: 54, mode: os.FileMode(0644), modTime: time.Unix(1792052425, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x52, 0x13, 0x1f, 0x31, 0x4c, 0xb3, 0x57, 0xbb, 0x6c, 0xc3, 0xfa, 0xb8, 0x86, 0xd0, 0xd1, 0xfb, 0x87, 0x4f, 0x7c, 0x3f, 0x3c, 0xdb, 0x57, 0x9, 0x71, 0xa5, 0x34, 0xd, 0xd7, 0x2b, 0x79, 0x7a}}
+	return a, nil
+}
+
+var __005_runbooksUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x8d\x51\xcb\x6e\x83\x30\x10\xbc\xfb\x2b\xf6\x16\x90\xf2\x07\x39\x39\x66\x69\xad\x10\x93\x3a\x46\x69\x4e\x88\x87\x0f\x6e\x01\x47\xc6\x39\xf4\xef\x4b\x8b\x68\xa1\x0a\x52\xf7\x38\x33\x3b\x9a\xd9\x65\x12\xa9\x42\x50\x74\x9f\x20\xb8\x7b\x57\x5a\xfb\xde\x43\x40\x60\x18\x53\x83\xc2\x57\x05\x27\xc9\x8f\x54\x5e\xe1\x80\x57\x10\xa9\x02\x91\x25\xc9\xf6\x5b\x51\x39\x5d\x78\x5d\xe7\x85\x87\x68\xb0\x51\xfc\x88\x2b\x8a\xf2\x63\xf4\x5a\xb2\x5d\xd1\xea\x25\x0e\x99\xe0\x2f\x19\x8e\x74\x5f\x39\x73\xf3\xf9\x94\x63\xb9\x5b\x6b\x5f\x98\xa6\x5f\x52\x24\xdc\x11\xf6\xa0\x52\xee\x74\xab\x6b\x53\x78\x63\xbb\xa9\xde\xcd\xd9\xb2\xd1\xed\x8a\xfd\xb4\xf8\x98\xad\x1a\xa3\xbb\xb5\x64\xed\xbd\xf1\x26\x7f\xb3\xe5\xc4\x8f\xb0\x76\xce\xba\x3f\x6d\x23\x8c\x69\x96\x28\xd8\x6c\xfe\x7b\xd0\xf9\x33\x82\xdf\x0a\xdb\x59\xe0\x70\x54\xc6\xa9\x44\xfe\x24\x46\xe5\x8c\x05\x89\x31\x4a\x14\x0c\xcf\x3f\x1f\x0f\xbe\xf0\x54\x0c\x79\x12\x1c\x6e\xc7\xe8\x99\xd1\x08\x49\x08\x17\xae\x9e\xd3\x4c\x81\x4c\x2f\x3c\xda\x91\x4f\x2d\x60\x4a\x28\x2f\x02\x00\x00")
+
+func _005_runbooksUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__005_runbooksUpSql,
+		"005_runbooks.up.sql",
+	)
+}
+
+func _005_runbooksUpSql() (*asset, error) {
+	bytes, err := _005_runbooksUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "005_runbooks.up.sql", size: 559, mode: os.FileMode(0644), modTime: time.Unix(1792052425, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x99, 0xb, 0xe4, 0xd8, 0xcc, 0xa, 0xc2, 0xe7, 0x24, 0x70, 0xdb, 0x9b, 0x76, 0xc8, 0xf3, 0x9e, 0x15, 0x60, 0x16, 0x84, 0x19, 0xad, 0xa4, 0x17, 0x50, 0xab, 0xcb, 0x28, 0x4c, 0xf3, 0xc4, 0x82}}
+	return a, nil
+}
+
+var __006_runbook_remediation_resultsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x95\xd0\xc1\x0a\xc2\x30\x10\x04\xd0\x7b\xbf\x22\xff\xd1\x53\x35\x39\x14\x62\x2b\xa5\x82\xb7\x25\x4d\x56\x5c\xad\x89\x64\xb3\xff\x2f\x2d\x1e\x05\xc9\x7d\xde\xc0\x8c\x9e\xc6\xb3\xea\x07\x6d\xae\x2a\x4b\x5c\x52\x7a\x42\xc6\x17\x06\x72\x85\x52\x64\xe0\xe2\x8a\x70\xdb\xe8\x3f\xb9\x2c\x2b\x82\x5f\x09\x63\x69\x9b\xce\xce\x66\x52\x73\x77\xb0\xe6\x67\x5a\xed\x6d\xc7\xd1\x5e\x4e\x83\xba\x51\x24\xbe\x63\x00\x57\x2b\x59\xbc\x47\x66\x48\x52\xde\x52\x8d\xbf\xc3\xaa\xd0\x23\x2d\x40\xa1\x12\xed\xd7\x6c\xea\x03\xda\xa9\xba\x61\x6c\x01\x00\x00")
+
+func _006_runbook_remediation_resultsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__006_runbook_remediation_resultsDownSql,
+		"006_runbook_remediation_results.down.sql",
+	)
+}
+
+func _006_runbook_remediation_resultsDownSql() (*asset, error) {
+	bytes, err := _006_runbook_remediation_resultsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "006_runbook_remediation_results.down.sql", size: 364, mode: os.FileMode(0644), modTime: time.Unix(1792052806, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xfa, 0xfd, 0xe7, 0xcc, 0xa5, 0x6f, 0x64, 0x5c, 0x14, 0x3, 0xef, 0x14, 0xc2, 0x46, 0xb6, 0xaf, 0x57, 0xe2, 0xed, 0x55, 0x13, 0xed, 0x19, 0xae, 0xe7, 0x86, 0x25, 0x8a, 0x69, 0xa7, 0x7b, 0xf9}}
+	return a, nil
+}
+
+var __006_runbook_remediation_resultsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xa5\x51\xc1\x6a\xc3\x30\x0c\xbd\xe7\x2b\x74\x73\x07\xfb\x83\xb0\x83\x57\x6b\x2c\xe0\xb9\xa3\x71\x58\x6f\xc6\x4d\x5c\xe6\x35\xb5\x4b\x6c\xb3\xdf\x9f\xd3\xa4\xb7\x0c\x02\x3d\x08\x24\x9e\xf4\x9e\x9e\x44\xb9\xc4\x3d\x48\xfa\xca\x11\x86\xe4\x8e\xde\x9f\xd5\x60\x2e\xa6\xb3\x3a\x5a\xef\x02\x50\xc6\x32\xd0\x1b\x65\x3b\x90\x78\x90\x20\x76\x39\x1a\xce\x81\xe1\x1b\x6d\xb8\x04\x42\xca\x82\xae\xa1\xf9\xf1\xc7\x3b\xcb\xca\x89\x10\x75\x4c\xe1\x51\xdd\x90\xda\xd6\x84\xa0\x7c\x8a\xd7\x14\x1f\x65\x3b\x59\x67\xc3\xb7\xe9\x94\x8e\xc0\xa8\x44\x59\x7d\x60\x59\x34\x9f\x63\xbe\x3c\x55\xa3\xbc\x3b\x79\x81\x2d\xad\x11\xbe\xde\x51\xc0\x25\xf5\xd1\xaa\xf9\x28\x55\x3d\xad\x23\x47\x84\x9c\xb4\xed\x4d\x47\x00\x79\x6e\x26\xc9\x9d\x9d\xff\x75\xb9\x14\xac\x2c\xb6\x7b\x1c\x95\x2a\xc1\xf0\xb0\xa8\xa7\x6e\xef\x6a\x7b\x6b\x5c\x84\x9d\x58\xde\x69\x33\xff\xf4\x19\xa6\xc6\x29\x1d\x8c\x8e\x37\x67\x4f\x6b\x74\x66\x4f\xff\x4a\x4c\x78\xa6\xfa\x03\x88\x3f\x5e\x1e\x65\x02\x00\x00")
+
+func _006_runbook_remediation_resultsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__006_runbook_remediation_resultsUpSql,
+		"006_runbook_remediation_results.up.sql",
+	)
+}
+
+func _006_runbook_remediation_resultsUpSql() (*asset, error) {
+	bytes, err := _006_runbook_remediation_resultsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "006_runbook_remediation_results.up.sql", size: 613, mode: os.FileMode(0644), modTime: time.Unix(1792052811, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xd4, 0x94, 0x2f, 0x97, 0x24, 0x7e, 0xa5, 0x70, 0xfe, 0x9f, 0xb4, 0xd5, 0xc6, 0x97, 0x90, 0x24, 0x3b, 0x7, 0xf4, 0x95, 0x21, 0xf0, 0x4, 0x24, 0xdd, 0x4, 0x19, 0xb2, 0x2c, 0x46, 0xdb, 0xf}}
+	return a, nil
+}
+
+var __007_multi_job_summariesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xc8\x2d\xcd\x29\xc9\x8c\xcf\xca\x4f\x8a\x2f\x2e\xcd\xcd\x4d\x2c\xca\x4c\x2d\xb6\xe6\x02\x00\x2d\xbf\x06\xa9\x20\x00\x00\x00")
+
+func _007_multi_job_summariesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__007_multi_job_summariesDownSql,
+		"007_multi_job_summaries.down.sql",
+	)
+}
+
+func _007_multi_job_summariesDownSql() (*asset, error) {
+	bytes, err := _007_multi_job_summariesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "007_multi_job_summaries.down.sql", size: 32, mode: os.FileMode(0644), modTime: time.Unix(1792053410, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xfc, 0x92, 0x49, 0xb5, 0xa3, 0xdc, 0xbf, 0xbb, 0xd2, 0xea, 0xf0, 0x2e, 0xd5, 0x27, 0xbc, 0x63, 0x99, 0xe, 0xea, 0xc, 0xe3, 0x64, 0x14, 0xf0, 0xf0, 0x80, 0x80, 0x2e, 0xf0, 0xb7, 0x59, 0x54}}
+	return a, nil
+}
+
+var __007_multi_job_summariesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x5d\x8f\x41\x0e\x82\x30\x10\x45\xf7\x9c\x62\x96\x90\x78\x03\x57\xb5\x1d\xb4\xb1\xb4\xa6\x0c\x41\x57\x04\xa5\x8b\x12\x88\x09\x2d\xf7\x17\x71\x81\xfa\xb7\xef\xe7\xcd\x7c\x6e\x91\x11\x02\xb1\x83\x42\x18\xe7\x21\xfa\xa6\x7f\xde\x9b\x30\x8f\x63\x3b\x79\x17\x20\x4d\x60\xc9\x46\x7c\x07\x84\x57\x82\x8b\x95\x05\xb3\x37\x38\xe3\x0d\xb4\x21\xd0\x95\x52\xbb\xb5\xfb\x98\x5c\x1b\x5d\xd7\xb4\x11\xc4\xe2\x26\x59\xe0\x5f\xa3\x73\xb1\xf5\x43\xf8\x88\x7e\x51\x6e\x2c\xca\xa3\x5e\xb5\xe9\xf7\xd5\x0c\x2c\xe6\x68\x51\x73\x2c\xb7\x77\x42\xda\xbf\x91\xd1\x20\x50\xe1\x32\x84\xb3\x92\x33\x81\x49\x06\xb5\xa4\x93\xa9\x08\xac\xa9\xa5\xd8\x27\x2f\x74\xec\xe6\xf8\xe9\x00\x00\x00")
+
+func _007_multi_job_summariesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__007_multi_job_summariesUpSql,
+		"007_multi_job_summaries.up.sql",
+	)
+}
+
+func _007_multi_job_summariesUpSql() (*asset, error) {
+	bytes, err := _007_multi_job_summariesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "007_multi_job_summaries.up.sql", size: 233, mode: os.FileMode(0644), modTime: time.Unix(1792053410, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x52, 0x7b, 0x9c, 0x34, 0x2f, 0x2, 0x13, 0x93, 0x72, 0x36, 0xe0, 0x8d, 0xce, 0x98, 0x48, 0xf1, 0xf5, 0x7d, 0x4b, 0x50, 0xf2, 0x3f, 0x55, 0xc2, 0xc9, 0x29, 0xdc, 0xeb, 0xa5, 0xf5, 0xb7, 0x66}}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -284,12 +452,20 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
-	"001_init.down.sql":                  _001_initDownSql,
-	"001_init.up.sql":                    _001_initUpSql,
-	"002_schedules.down.sql":             _002_schedulesDownSql,
-	"002_schedules.up.sql":               _002_schedulesUpSql,
-	"003_multi_job_schedule_id.down.sql": _003_multi_job_schedule_idDownSql,
-	"003_multi_job_schedule_id.up.sql":   _003_multi_job_schedule_idUpSql,
+	"001_init.down.sql":                        _001_initDownSql,
+	"001_init.up.sql":                          _001_initUpSql,
+	"002_schedules.down.sql":                   _002_schedulesDownSql,
+	"002_schedules.up.sql":                     _002_schedulesUpSql,
+	"003_multi_job_schedule_id.down.sql":       _003_multi_job_schedule_idDownSql,
+	"003_multi_job_schedule_id.up.sql":         _003_multi_job_schedule_idUpSql,
+	"004_multi_job_dispatch.down.sql":          _004_multi_job_dispatchDownSql,
+	"004_multi_job_dispatch.up.sql":            _004_multi_job_dispatchUpSql,
+	"005_runbooks.down.sql":                    _005_runbooksDownSql,
+	"005_runbooks.up.sql":                      _005_runbooksUpSql,
+	"006_runbook_remediation_results.down.sql": _006_runbook_remediation_resultsDownSql,
+	"006_runbook_remediation_results.up.sql":   _006_runbook_remediation_resultsUpSql,
+	"007_multi_job_summaries.down.sql":         _007_multi_job_summariesDownSql,
+	"007_multi_job_summaries.up.sql":           _007_multi_job_summariesUpSql,
 }
 
 // AssetDebug is true if the assets were built with the debug flag enabled.
@@ -338,12 +514,20 @@ type bintree struct {
 }
 
 var _bintree = &bintree{nil, map[string]*bintree{
-	"001_init.down.sql":                  {_001_initDownSql, map[string]*bintree{}},
-	"001_init.up.sql":                    {_001_initUpSql, map[string]*bintree{}},
-	"002_schedules.down.sql":             {_002_schedulesDownSql, map[string]*bintree{}},
-	"002_schedules.up.sql":               {_002_schedulesUpSql, map[string]*bintree{}},
-	"003_multi_job_schedule_id.down.sql": {_003_multi_job_schedule_idDownSql, map[string]*bintree{}},
-	"003_multi_job_schedule_id.up.sql":   {_003_multi_job_schedule_idUpSql, map[string]*bintree{}},
+	"001_init.down.sql":                        {_001_initDownSql, map[string]*bintree{}},
+	"001_init.up.sql":                          {_001_initUpSql, map[string]*bintree{}},
+	"002_schedules.down.sql":                   {_002_schedulesDownSql, map[string]*bintree{}},
+	"002_schedules.up.sql":                     {_002_schedulesUpSql, map[string]*bintree{}},
+	"003_multi_job_schedule_id.down.sql":       {_003_multi_job_schedule_idDownSql, map[string]*bintree{}},
+	"003_multi_job_schedule_id.up.sql":         {_003_multi_job_schedule_idUpSql, map[string]*bintree{}},
+	"004_multi_job_dispatch.down.sql":          {_004_multi_job_dispatchDownSql, map[string]*bintree{}},
+	"004_multi_job_dispatch.up.sql":            {_004_multi_job_dispatchUpSql, map[string]*bintree{}},
+	"005_runbooks.down.sql":                    {_005_runbooksDownSql, map[string]*bintree{}},
+	"005_runbooks.up.sql":                      {_005_runbooksUpSql, map[string]*bintree{}},
+	"006_runbook_remediation_results.down.sql": {_006_runbook_remediation_resultsDownSql, map[string]*bintree{}},
+	"006_runbook_remediation_results.up.sql":   {_006_runbook_remediation_resultsUpSql, map[string]*bintree{}},
+	"007_multi_job_summaries.down.sql":         {_007_multi_job_summariesDownSql, map[string]*bintree{}},
+	"007_multi_job_summaries.up.sql":           {_007_multi_job_summariesUpSql, map[string]*bintree{}},
 }}
 
 // RestoreAsset restores an asset under the given directory.
@@ -0,0 +1 @@
+DROP TABLE multi_job_dispatch;
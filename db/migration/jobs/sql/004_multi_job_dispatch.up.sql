@@ -0,0 +1,8 @@
+CREATE TABLE multi_job_dispatch (
+    multi_job_id TEXT NOT NULL,
+    client_id TEXT NOT NULL,
+    state TEXT NOT NULL,
+    expires_at DATETIME,
+    PRIMARY KEY (multi_job_id, client_id),
+    FOREIGN KEY (multi_job_id) REFERENCES multi_jobs(jid) ON DELETE CASCADE
+) WITHOUT ROWID;
@@ -0,0 +1,2 @@
+DROP TABLE runbook_remediations;
+DROP TABLE runbooks;
@@ -0,0 +1,18 @@
+CREATE TABLE runbooks (
+    id TEXT PRIMARY KEY NOT NULL,
+    created_at DATETIME NOT NULL,
+    created_by TEXT NOT NULL,
+    name TEXT NOT NULL UNIQUE,
+    script_id TEXT NOT NULL,
+    details TEXT NOT NULL
+);
+CREATE TABLE runbook_remediations (
+    problem_id TEXT NOT NULL,
+    runbook_id TEXT NOT NULL,
+    client_id TEXT NOT NULL,
+    multi_job_id TEXT,
+    error TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (problem_id, runbook_id),
+    FOREIGN KEY (runbook_id) REFERENCES runbooks(id) ON DELETE CASCADE
+) WITHOUT ROWID;
@@ -0,0 +1,7 @@
+DROP INDEX runbook_remediations_status;
+DROP INDEX runbook_remediations_rule_client;
+ALTER TABLE runbook_remediations DROP COLUMN finished_at;
+ALTER TABLE runbook_remediations DROP COLUMN success_output;
+ALTER TABLE runbook_remediations DROP COLUMN status;
+ALTER TABLE runbook_remediations DROP COLUMN job_id;
+ALTER TABLE runbook_remediations DROP COLUMN rule_id;
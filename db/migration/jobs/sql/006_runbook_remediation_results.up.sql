@@ -0,0 +1,8 @@
+ALTER TABLE runbook_remediations ADD rule_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE runbook_remediations ADD job_id TEXT;
+ALTER TABLE runbook_remediations ADD status TEXT NOT NULL DEFAULT '';
+ALTER TABLE runbook_remediations ADD success_output TEXT NOT NULL DEFAULT '';
+ALTER TABLE runbook_remediations ADD finished_at DATETIME;
+UPDATE runbook_remediations SET status = CASE WHEN multi_job_id IS NULL THEN 'failed' ELSE 'unknown' END;
+CREATE INDEX runbook_remediations_rule_client ON runbook_remediations (rule_id, client_id, created_at);
+CREATE INDEX runbook_remediations_status ON runbook_remediations (status);
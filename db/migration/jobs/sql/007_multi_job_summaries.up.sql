@@ -0,0 +1,6 @@
+CREATE TABLE multi_job_summaries (
+    multi_job_id TEXT PRIMARY KEY NOT NULL,
+    created_at DATETIME NOT NULL,
+    details TEXT NOT NULL,
+    FOREIGN KEY (multi_job_id) REFERENCES multi_jobs(jid) ON DELETE CASCADE
+) WITHOUT ROWID;
@@ -6,6 +6,10 @@
 // 002_indexes.up.sql (261B)
 // 003_add_net.down.sql (298B)
 // 003_add_net.up.sql (325B)
+// 004_custom_measurements.down.sql (44B)
+// 004_custom_measurements.up.sql (502B)
+// 005_rollups.down.sql (138B)
+// 005_rollups.up.sql (2648B)
 
 package monitoring
 
@@ -193,6 +197,86 @@ func _003_add_netUpSql() (*asset, error) {
 	return a, nil
 }
 
+var __004_custom_measurementsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x50\x4a\x2e\x2d\x2e\xc9\xcf\x8d\xcf\x4d\x4d\x2c\x2e\x2d\x4a\xcd\x4d\xcd\x2b\x29\x56\xb2\xe6\x02\x00\x6f\x12\x48\xdc\x2c\x00\x00\x00")
+
+func _004_custom_measurementsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__004_custom_measurementsDownSql,
+		"004_custom_measurements.down.sql",
+	)
+}
+
+func _004_custom_measurementsDownSql() (*asset, error) {
+	bytes, err := _004_custom_measurementsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "004_custom_measurements.down.sql", size: 44, mode: os.FileMode(0644), modTime: time.Unix(1792030197, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xca, 0xf5, 0x0, 0xd, 0x55, 0xc5, 0x57, 0x5b, 0xb, 0xf3, 0x2f, 0x39, 0x6d, 0x69, 0x4, 0x3c, 0xe5, 0x85, 0x79, 0x97, 0x26, 0xc0, 0x8f, 0xab, 0x41, 0x58, 0xc7, 0xed, 0x58, 0x7e, 0xfb, 0x66}}
+	return a, nil
+}
+
+var __004_custom_measurementsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x85\x50\xdb\x0a\x82\x30\x00\x7d\xdf\x57\x1c\x7c\x32\x70\x5f\xd0\x93\xd5\x82\x91\x97\xd0\x05\xf6\x64\xcb\x16\x08\x2e\xc3\xcd\xbe\xbf\x49\x14\x45\xa3\xce\xd3\x36\xce\x65\xe7\x50\x0a\xfa\x03\x84\x52\x08\x79\xec\x14\x8c\x1d\xc6\xc6\x8e\x83\xc2\xb9\x1f\xd0\x8c\xc6\xf6\xba\xd6\x4a\x1a\xf7\xa4\xd5\xc5\x9a\x89\xfa\xd3\x6a\x59\xb0\x58\x30\x88\x78\x91\x30\xf0\x35\xb2\x5c\x80\x55\xbc\x14\x25\x02\x8f\x5f\x40\x42\x02\x87\xa0\xe9\x5a\x77\xaf\xdb\x53\x80\x77\x08\x56\x89\xe7\x79\xb2\xca\x76\x49\x12\x3d\x14\xb6\xd5\xca\x58\xa9\xaf\x9f\x8a\x95\x8b\x17\x3c\x65\x1e\xc5\x45\x6a\xf5\x49\xfe\x97\x71\x93\xdd\xe8\x91\xb8\x8e\x89\x5f\xb1\x2d\x78\x1a\x17\x7b\x6c\xd8\x1e\xe1\xab\x53\x84\x29\x3a\xc2\xeb\xcb\x33\x32\x9b\x93\xe7\x56\x3c\x5b\xb1\xca\xbb\x4e\xfd\xd6\x31\xcf\x70\xf0\x50\x0e\x08\xbf\xe6\x88\xcb\xe5\xe4\x7f\x07\x8b\xb1\xd8\x28\xf6\x01\x00\x00")
+
+func _004_custom_measurementsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__004_custom_measurementsUpSql,
+		"004_custom_measurements.up.sql",
+	)
+}
+
+func _004_custom_measurementsUpSql() (*asset, error) {
+	bytes, err := _004_custom_measurementsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "004_custom_measurements.up.sql", size: 502, mode: os.FileMode(0644), modTime: time.Unix(1792030197, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x71, 0x74, 0x37, 0x70, 0xfd, 0xc9, 0xc5, 0xab, 0xd9, 0xb2, 0x96, 0x77, 0x12, 0xc2, 0x7d, 0xf, 0x25, 0xc4, 0x16, 0xc0, 0x1f, 0x7b, 0xec, 0x5d, 0xc4, 0x3, 0xb6, 0x8, 0x2a, 0x15, 0xac, 0xb8}}
+	return a, nil
+}
+
+var __005_rollupsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x50\x2a\xca\xcf\xc9\x29\x2d\x28\x8e\x2f\x28\xca\x4f\x2f\x4a\x2d\x2e\x56\xb2\xe6\x72\xc1\xaa\x30\xb9\xb4\xb8\x24\x3f\x37\x3e\x37\x35\xb1\xb8\xb4\x28\x35\x37\x35\xaf\xa4\x38\x1e\xaa\x19\xa7\x1e\x1c\x8a\x01\x64\xe4\xf6\x54\x8a\x00\x00\x00")
+
+func _005_rollupsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__005_rollupsDownSql,
+		"005_rollups.down.sql",
+	)
+}
+
+func _005_rollupsDownSql() (*asset, error) {
+	bytes, err := _005_rollupsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "005_rollups.down.sql", size: 138, mode: os.FileMode(0644), modTime: time.Unix(1792046057, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x7b, 0x20, 0xfb, 0xa8, 0xe1, 0x7e, 0xfb, 0xfe, 0x53, 0xf3, 0x4b, 0x6a, 0x6c, 0x8, 0xb9, 0x24, 0x10, 0x76, 0x6e, 0x9, 0x40, 0x8e, 0x49, 0xf8, 0xa8, 0xd3, 0x30, 0x2e, 0x8a, 0x1c, 0x3a, 0xa0}}
+	return a, nil
+}
+
+var __005_rollupsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xbd\x56\x4d\x8f\x9b\x30\x10\xbd\xe7\x57\x8c\x38\xed\x4a\xa1\x7f\xa0\xa7\x74\xd7\xad\x50\x13\xb6\x4a\xa8\x94\x3d\xb1\x0e\x99\xa5\xa8\x60\x23\x6c\x2f\xad\xaa\xfd\xef\x35\xb0\x10\x96\x0f\xc7\x49\xa5\xfa\x64\xac\xf7\x3c\x6f\x86\xd1\xf3\xb8\x2e\xb8\x86\xb5\x70\x5d\x08\xe8\x21\x45\x10\xb2\x50\x91\x54\x05\xc2\x33\x2f\x20\x43\x2a\xf4\x3e\x43\x26\x45\x58\xf0\x34\x55\xb9\xa8\xb0\x9b\xde\x39\xd0\x38\x2e\x30\xa6\x12\x8f\x90\x63\x01\x51\x9a\xe8\x63\x48\x98\xe4\x70\x50\xd1\x4f\xd4\x10\xfe\x0c\x7f\x0a\x14\x3c\x55\x32\xe1\xec\x15\x04\x46\x9c\x1d\xc5\x87\xea\x2e\xa3\xae\xbb\x2d\x59\x05\x04\x82\xd5\xa7\x35\x01\xef\x33\xf8\x0f\x01\x90\xbd\xb7\x0b\x76\xe0\x4c\x89\x73\x16\x37\x0b\xd0\xcb\x39\x45\x73\x60\xb0\x3c\x3f\x20\x5f\xc8\xb6\xde\x57\xf7\xf9\xdf\xd7\xeb\x65\xc3\x6a\xb4\x87\xc9\x71\x44\x82\x80\xec\x83\x76\x3f\x60\xc9\x24\x43\x21\x69\x96\x8f\x59\xf7\x5a\x7d\xe0\x6d\xc8\x04\x4b\x68\x42\x8a\x62\xcc\x31\x2b\xcc\x55\xa8\x04\x8d\x31\xd4\xb5\x8e\x2a\xb1\xf4\x25\x6e\xee\xd0\xa5\x5a\xcf\x28\x1c\xb3\xb2\x84\x5d\xc3\xa2\xbf\xce\xb2\x32\xcc\x78\xf1\x7b\x52\xe4\xa5\xac\x46\xe4\xc5\xac\x5a\xa4\x81\x95\xf0\xb9\x12\x5e\xc4\xea\x4a\x78\x19\xab\x2d\xa1\x89\xc5\x50\x86\x29\x65\x61\xc2\x7a\xda\x4e\xac\x31\xea\xa4\xe5\x5d\x07\x4d\x00\xbb\xf0\x26\x20\x57\x72\x18\x78\x22\x6e\x85\x1a\x04\x9e\xbf\x6e\x10\x78\x0c\x2c\xad\xf2\x2d\x6d\xf3\x2d\x6d\xf3\x2d\xad\xf2\x2d\x6d\xf3\x2d\x2d\xf2\xfd\xb6\xf5\x36\xab\xed\x23\x7c\x25\x8f\x70\x73\x72\xaa\x25\x74\xfe\xb3\x84\xce\x54\x6e\x17\xb7\x1f\x17\xad\x11\x7a\xfe\x3d\xd9\x4f\x5b\x5f\xd8\xb3\xa1\x07\x1f\x9e\xa6\x30\x4f\x30\x61\x8f\xab\xdd\xdd\xd8\xc8\xf4\x61\x1d\xd7\xbd\xee\xe1\x88\x94\x90\x3c\x0b\xe7\xde\x8f\xab\x3d\xdf\x70\xef\x9c\xf5\xdb\xdb\xbd\xbd\xc5\x1b\x6c\x9d\xd1\x0c\x1d\xab\x67\x63\xf0\x00\x18\x74\xbe\xd0\x54\xa1\x95\xd1\x37\x48\x1b\x73\x7f\x43\x9e\x37\x74\x9b\x66\xad\x92\x3e\xd3\xb2\x86\x3f\x37\xec\x5c\x03\xf4\xbf\x35\x70\x2b\x2d\x2f\xb8\x9e\x6e\xc4\x78\xea\x39\xa0\x86\xa1\x1e\x6b\x34\x10\x8f\xa1\xca\x43\xc5\x64\x92\xbe\x02\xd5\xa7\xbd\x91\xa8\x9e\x83\xe4\x0f\x6c\x6f\xac\x66\xa1\xfa\xb3\x4b\xe0\xdf\xa6\xa0\xa1\x50\xd3\x04\x34\xd5\x62\xfd\xff\xfb\x56\xc5\x41\x4a\xce\x4c\xc3\x57\xe5\xfd\x0b\x09\x0d\xcc\x09\x58\x0a\x00\x00")
+
+func _005_rollupsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__005_rollupsUpSql,
+		"005_rollups.up.sql",
+	)
+}
+
+func _005_rollupsUpSql() (*asset, error) {
+	bytes, err := _005_rollupsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "005_rollups.up.sql", size: 2648, mode: os.FileMode(0644), modTime: time.Unix(1792046057, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x64, 0xa9, 0x3a, 0xbc, 0xc7, 0x4f, 0x25, 0x4d, 0x8d, 0xae, 0x77, 0x4a, 0xad, 0x10, 0x2f, 0xeb, 0xb5, 0xb7, 0xf, 0xb8, 0x2e, 0x33, 0x38, 0xb6, 0xb7, 0x2e, 0x29, 0x3b, 0x98, 0xdd, 0x25, 0xf1}}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -284,12 +368,16 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
-	"001_init.down.sql":    _001_initDownSql,
-	"001_init.up.sql":      _001_initUpSql,
-	"002_indexes.down.sql": _002_indexesDownSql,
-	"002_indexes.up.sql":   _002_indexesUpSql,
-	"003_add_net.down.sql": _003_add_netDownSql,
-	"003_add_net.up.sql":   _003_add_netUpSql,
+	"001_init.down.sql":                _001_initDownSql,
+	"001_init.up.sql":                  _001_initUpSql,
+	"002_indexes.down.sql":             _002_indexesDownSql,
+	"002_indexes.up.sql":               _002_indexesUpSql,
+	"003_add_net.down.sql":             _003_add_netDownSql,
+	"003_add_net.up.sql":               _003_add_netUpSql,
+	"004_custom_measurements.down.sql": _004_custom_measurementsDownSql,
+	"004_custom_measurements.up.sql":   _004_custom_measurementsUpSql,
+	"005_rollups.down.sql":             _005_rollupsDownSql,
+	"005_rollups.up.sql":               _005_rollupsUpSql,
 }
 
 // AssetDebug is true if the assets were built with the debug flag enabled.
@@ -338,12 +426,16 @@ type bintree struct {
 }
 
 var _bintree = &bintree{nil, map[string]*bintree{
-	"001_init.down.sql":    {_001_initDownSql, map[string]*bintree{}},
-	"001_init.up.sql":      {_001_initUpSql, map[string]*bintree{}},
-	"002_indexes.down.sql": {_002_indexesDownSql, map[string]*bintree{}},
-	"002_indexes.up.sql":   {_002_indexesUpSql, map[string]*bintree{}},
-	"003_add_net.down.sql": {_003_add_netDownSql, map[string]*bintree{}},
-	"003_add_net.up.sql":   {_003_add_netUpSql, map[string]*bintree{}},
+	"001_init.down.sql":                {_001_initDownSql, map[string]*bintree{}},
+	"001_init.up.sql":                  {_001_initUpSql, map[string]*bintree{}},
+	"002_indexes.down.sql":             {_002_indexesDownSql, map[string]*bintree{}},
+	"002_indexes.up.sql":               {_002_indexesUpSql, map[string]*bintree{}},
+	"003_add_net.down.sql":             {_003_add_netDownSql, map[string]*bintree{}},
+	"003_add_net.up.sql":               {_003_add_netUpSql, map[string]*bintree{}},
+	"004_custom_measurements.down.sql": {_004_custom_measurementsDownSql, map[string]*bintree{}},
+	"004_custom_measurements.up.sql":   {_004_custom_measurementsUpSql, map[string]*bintree{}},
+	"005_rollups.down.sql":             {_005_rollupsDownSql, map[string]*bintree{}},
+	"005_rollups.up.sql":               {_005_rollupsUpSql, map[string]*bintree{}},
 }}
 
 // RestoreAsset restores an asset under the given directory.
@@ -0,0 +1,15 @@
+-- ----------------------------
+-- Table structure for custom_measurements
+-- ----------------------------
+CREATE TABLE IF NOT EXISTS "custom_measurements"
+(
+    "client_id"             TEXT        NOT NULL,
+    "timestamp"             DATETIME    NOT NULL,
+    "name"                  TEXT        NOT NULL,
+    "value"                 REAL        NOT NULL,
+    PRIMARY KEY (client_id, name, timestamp)
+);
+
+CREATE INDEX "custom_measurements_timestamp" ON `custom_measurements` (
+    "timestamp" ASC
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "rollups_progress";
+DROP TABLE IF EXISTS "custom_measurements_rollups";
+DROP TABLE IF EXISTS "measurements_rollups";
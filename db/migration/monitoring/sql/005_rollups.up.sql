@@ -0,0 +1,69 @@
+-- ----------------------------
+-- Table structure for measurements_rollups
+-- Measurements aggregated per client into buckets of {resolution} seconds.
+-- ----------------------------
+CREATE TABLE IF NOT EXISTS "measurements_rollups"
+(
+    "resolution"                INTEGER     NOT NULL,
+    "client_id"                 TEXT        NOT NULL,
+    "timestamp"                 DATETIME    NOT NULL,
+    "samples"                   INTEGER     NOT NULL,
+    "cpu_usage_percent_avg"     REAL        NOT NULL,
+    "cpu_usage_percent_min"     REAL        NOT NULL,
+    "cpu_usage_percent_max"     REAL        NOT NULL,
+    "memory_usage_percent_avg"  REAL        NOT NULL,
+    "memory_usage_percent_min"  REAL        NOT NULL,
+    "memory_usage_percent_max"  REAL        NOT NULL,
+    "io_usage_percent_avg"      REAL        NOT NULL,
+    "io_usage_percent_min"      REAL        NOT NULL,
+    "io_usage_percent_max"      REAL        NOT NULL,
+    "net_lan_in_avg"            REAL,
+    "net_lan_in_min"            INTEGER,
+    "net_lan_in_max"            INTEGER,
+    "net_lan_out_avg"           REAL,
+    "net_lan_out_min"           INTEGER,
+    "net_lan_out_max"           INTEGER,
+    "net_wan_in_avg"            REAL,
+    "net_wan_in_min"            INTEGER,
+    "net_wan_in_max"            INTEGER,
+    "net_wan_out_avg"           REAL,
+    "net_wan_out_min"           INTEGER,
+    "net_wan_out_max"           INTEGER,
+    PRIMARY KEY (resolution, client_id, timestamp)
+);
+
+CREATE INDEX "measurements_rollups_timestamp" ON `measurements_rollups` (
+    "resolution" ASC,
+    "timestamp" ASC
+);
+
+-- ----------------------------
+-- Table structure for custom_measurements_rollups
+-- ----------------------------
+CREATE TABLE IF NOT EXISTS "custom_measurements_rollups"
+(
+    "resolution"    INTEGER     NOT NULL,
+    "client_id"     TEXT        NOT NULL,
+    "timestamp"     DATETIME    NOT NULL,
+    "name"          TEXT        NOT NULL,
+    "samples"       INTEGER     NOT NULL,
+    "value_avg"     REAL        NOT NULL,
+    "value_min"     REAL        NOT NULL,
+    "value_max"     REAL        NOT NULL,
+    PRIMARY KEY (resolution, client_id, name, timestamp)
+);
+
+CREATE INDEX "custom_measurements_rollups_timestamp" ON `custom_measurements_rollups` (
+    "resolution" ASC,
+    "timestamp" ASC
+);
+
+-- ----------------------------
+-- Table structure for rollups_progress
+-- Measurements before {rolled_up_until} are aggregated into the rollups of the resolution.
+-- ----------------------------
+CREATE TABLE IF NOT EXISTS "rollups_progress"
+(
+    "resolution"        INTEGER     NOT NULL PRIMARY KEY,
+    "rolled_up_until"   DATETIME    NOT NULL
+);
@@ -0,0 +1,3 @@
+DROP INDEX idx_report_runs_report_id;
+DROP TABLE report_runs;
+DROP TABLE reports;
@@ -0,0 +1,25 @@
+CREATE TABLE reports (
+    id TEXT PRIMARY KEY NOT NULL,
+    created_at DATETIME NOT NULL,
+    created_by TEXT NOT NULL,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL,
+    format TEXT NOT NULL,
+    schedule TEXT NOT NULL DEFAULT '',
+    details TEXT NOT NULL DEFAULT '{}'
+);
+
+CREATE TABLE report_runs (
+    id TEXT PRIMARY KEY NOT NULL,
+    report_id TEXT NOT NULL REFERENCES reports(id) ON DELETE CASCADE,
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME,
+    trigger TEXT NOT NULL,
+    status TEXT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    format TEXT NOT NULL,
+    rows INTEGER NOT NULL DEFAULT 0,
+    content BLOB
+);
+
+CREATE INDEX idx_report_runs_report_id ON report_runs (report_id, started_at);
@@ -0,0 +1,4 @@
+CREATE TABLE user_locales (
+    username TEXT PRIMARY KEY NOT NULL,
+    locale TEXT NOT NULL
+);
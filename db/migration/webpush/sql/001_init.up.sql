@@ -0,0 +1,18 @@
+CREATE TABLE vapid_keys (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    private_key TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE push_subscriptions (
+    id TEXT PRIMARY KEY NOT NULL,
+    username TEXT NOT NULL,
+    endpoint TEXT NOT NULL UNIQUE,
+    p256dh TEXT NOT NULL,
+    auth TEXT NOT NULL,
+    user_agent TEXT NOT NULL DEFAULT '',
+    client_disconnects BOOLEAN NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX push_subscriptions_username ON push_subscriptions(username);
@@ -0,0 +1,145 @@
+// Package pool configures the connection pools of the SQL stores, logs slow queries and collects the pool stats.
+package pool
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+// Options of a connection pool. Zero values keep the defaults of database/sql.
+type Options struct {
+	MaxOpenConnections int
+	MaxIdleConnections int
+	ConnMaxLifetime    time.Duration
+	ConnMaxIdleTime    time.Duration
+}
+
+func (o Options) Validate() error {
+	if o.MaxOpenConnections < 0 {
+		return fmt.Errorf("max open connections must not be negative")
+	}
+	if o.MaxIdleConnections < 0 {
+		return fmt.Errorf("max idle connections must not be negative")
+	}
+	if o.ConnMaxLifetime < 0 {
+		return fmt.Errorf("connection max lifetime must not be negative")
+	}
+	if o.ConnMaxIdleTime < 0 {
+		return fmt.Errorf("connection max idle time must not be negative")
+	}
+	return nil
+}
+
+func (o Options) apply(db *sqlx.DB) {
+	if o.MaxOpenConnections > 0 {
+		db.SetMaxOpenConns(o.MaxOpenConnections)
+	}
+	if o.MaxIdleConnections > 0 {
+		db.SetMaxIdleConns(o.MaxIdleConnections)
+	}
+	if o.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(o.ConnMaxLifetime)
+	}
+	if o.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(o.ConnMaxIdleTime)
+	}
+}
+
+// Stats of a connection pool.
+type Stats struct {
+	MaxOpenConnections int   `json:"max_open_connections"`
+	OpenConnections    int   `json:"open_connections"`
+	InUse              int   `json:"in_use"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"wait_count"`
+	WaitDurationMillis int64 `json:"wait_duration_ms"`
+	MaxIdleClosed      int64 `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64 `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64 `json:"max_lifetime_closed"`
+	SlowQueries        int64 `json:"slow_queries"`
+}
+
+type store struct {
+	name        string
+	db          *sqlx.DB
+	slowQueries atomic.Int64
+}
+
+var (
+	stores   = make(map[string]*store)
+	storesMu sync.Mutex
+
+	slowQueryThreshold time.Duration
+	slowQueryLogger    *logger.Logger
+)
+
+// SetSlowQueryLog enables logging of queries taking longer than the threshold on stores connected afterwards.
+// A threshold of 0 disables it.
+func SetSlowQueryLog(threshold time.Duration, l *logger.Logger) {
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	slowQueryThreshold = threshold
+	slowQueryLogger = l
+}
+
+// Connect opens the store, applies the pool options and registers it for the stats by the given name.
+func Connect(name, driverName, dataSourceName string, o Options) (*sqlx.DB, error) {
+	storesMu.Lock()
+	threshold, l := slowQueryThreshold, slowQueryLogger
+	storesMu.Unlock()
+
+	s := &store{name: name}
+	var err error
+	if threshold > 0 && l != nil {
+		s.db, err = connectWithSlowQueryLog(s, driverName, dataSourceName, threshold, l)
+	} else {
+		s.db, err = sqlx.Connect(driverName, dataSourceName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	o.apply(s.db)
+
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	stores[name] = s
+	return s.db, nil
+}
+
+// AllStats returns the stats of all registered stores by name.
+func AllStats() map[string]Stats {
+	storesMu.Lock()
+	all := make([]*store, 0, len(stores))
+	for _, s := range stores {
+		all = append(all, s)
+	}
+	storesMu.Unlock()
+
+	res := make(map[string]Stats, len(all))
+	for _, s := range all {
+		res[s.name] = newStats(s.db.Stats(), s.slowQueries.Load())
+	}
+	return res
+}
+
+func newStats(dbStats sql.DBStats, slowQueries int64) Stats {
+	return Stats{
+		MaxOpenConnections: dbStats.MaxOpenConnections,
+		OpenConnections:    dbStats.OpenConnections,
+		InUse:              dbStats.InUse,
+		Idle:               dbStats.Idle,
+		WaitCount:          dbStats.WaitCount,
+		WaitDurationMillis: dbStats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      dbStats.MaxIdleClosed,
+		MaxIdleTimeClosed:  dbStats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  dbStats.MaxLifetimeClosed,
+		SlowQueries:        slowQueries,
+	}
+}
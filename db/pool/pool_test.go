@@ -0,0 +1,79 @@
+package pool
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+func TestConnectAppliesOptions(t *testing.T) {
+	SetSlowQueryLog(0, nil)
+	db, err := Connect("options.db", "sqlite3", ":memory:", Options{MaxOpenConnections: 3, ConnMaxLifetime: time.Hour})
+	require.NoError(t, err)
+	defer db.Close()
+
+	stats := AllStats()["options.db"]
+	assert.Equal(t, 3, stats.MaxOpenConnections)
+	assert.Equal(t, int64(0), stats.SlowQueries)
+}
+
+func TestConnectWithSlowQueryLog(t *testing.T) {
+	logFile, err := os.Create(t.TempDir() + "/slow-query.log")
+	require.NoError(t, err)
+	defer logFile.Close()
+	SetSlowQueryLog(time.Nanosecond, logger.NewLogger("slow-query", logger.LogOutput{File: logFile}, logger.LogLevelInfo))
+	defer SetSlowQueryLog(0, nil)
+
+	db, err := Connect("slow.db", "sqlite3", ":memory:", Options{MaxOpenConnections: 1})
+	require.NoError(t, err)
+	defer db.Close()
+
+	// queries, prepared statements and transactions work through the wrapped connection
+	_, err = db.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+	_, err = db.NamedExec("INSERT INTO items (name) VALUES (:name)", map[string]interface{}{"name": "first"})
+	require.NoError(t, err)
+
+	tx, err := db.Beginx()
+	require.NoError(t, err)
+	st, err := tx.Preparex("INSERT INTO items (name) VALUES (?)")
+	require.NoError(t, err)
+	_, err = st.Exec("second")
+	require.NoError(t, err)
+	require.NoError(t, st.Close())
+	require.NoError(t, tx.Commit())
+
+	var names []string
+	require.NoError(t, db.Select(&names, "SELECT name FROM items WHERE id > ? ORDER BY id", 0))
+	assert.Equal(t, []string{"first", "second"}, names)
+
+	var count int
+	st2, err := db.Preparex("SELECT COUNT(*) FROM items")
+	require.NoError(t, err)
+	require.NoError(t, st2.Get(&count))
+	require.NoError(t, st2.Close())
+	assert.Equal(t, 2, count)
+
+	// every query exceeds the threshold of 1ns
+	stats := AllStats()["slow.db"]
+	assert.GreaterOrEqual(t, stats.SlowQueries, int64(5))
+	logged, err := os.ReadFile(logFile.Name())
+	require.NoError(t, err)
+	assert.Contains(t, string(logged), "slow query on slow.db took")
+	assert.Contains(t, string(logged), "SELECT name FROM items WHERE id > ? ORDER BY id")
+	assert.NotContains(t, string(logged), "second")
+}
+
+func TestOptionsValidate(t *testing.T) {
+	assert.NoError(t, Options{}.Validate())
+	assert.EqualError(t, Options{MaxOpenConnections: -1}.Validate(), "max open connections must not be negative")
+	assert.EqualError(t, Options{MaxIdleConnections: -1}.Validate(), "max idle connections must not be negative")
+	assert.EqualError(t, Options{ConnMaxLifetime: -time.Second}.Validate(), "connection max lifetime must not be negative")
+	assert.EqualError(t, Options{ConnMaxIdleTime: -time.Second}.Validate(), "connection max idle time must not be negative")
+}
@@ -0,0 +1,223 @@
+package pool
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+// maxLoggedQueryLength limits the length of the logged queries, args are never logged as they might hold secrets.
+const maxLoggedQueryLength = 500
+
+func connectWithSlowQueryLog(s *store, driverName, dataSourceName string, threshold time.Duration, l *logger.Logger) (*sqlx.DB, error) {
+	// sql.Open doesn't connect, it's used to look up the registered driver only
+	lookup, err := sql.Open(driverName, "")
+	if err != nil {
+		return nil, err
+	}
+	d := lookup.Driver()
+	_ = lookup.Close()
+
+	db := sqlx.NewDb(sql.OpenDB(&connector{
+		driver:         d,
+		dataSourceName: dataSourceName,
+		log:            &slowQueryLog{store: s, threshold: threshold, logger: l},
+	}), driverName)
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+type slowQueryLog struct {
+	store     *store
+	threshold time.Duration
+	logger    *logger.Logger
+}
+
+func (l *slowQueryLog) done(query string, started time.Time) {
+	took := time.Since(started)
+	if took < l.threshold {
+		return
+	}
+	l.store.slowQueries.Add(1)
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > maxLoggedQueryLength {
+		query = query[:maxLoggedQueryLength] + "..."
+	}
+	l.logger.Infof("slow query on %s took %s: %s", l.store.name, took, query)
+}
+
+type connector struct {
+	driver         driver.Driver
+	dataSourceName string
+	log            *slowQueryLog
+}
+
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	dc, err := c.driver.Open(c.dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: dc, log: c.log}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// conn measures the queries of the wrapped connection, optional interfaces not implemented by it fall back to the
+// behavior of database/sql.
+type conn struct {
+	driver.Conn
+	log *slowQueryLog
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var st driver.Stmt
+	var err error
+	if cp, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		st, err = cp.PrepareContext(ctx, query)
+	} else {
+		st, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{Stmt: st, query: query, log: c.log}, nil
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if cb, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return cb.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin() //nolint:staticcheck // fallback for drivers not implementing ConnBeginTx
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	defer c.log.done(query, time.Now())
+	return ec.ExecContext(ctx, query, args)
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	started := time.Now()
+	r, err := qc.QueryContext(ctx, query, args)
+	if err != nil {
+		c.log.done(query, started)
+		return nil, err
+	}
+	return &rows{Rows: r, query: query, started: started, log: c.log}, nil
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if sr, ok := c.Conn.(driver.SessionResetter); ok {
+		return sr.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type stmt struct {
+	driver.Stmt
+	query string
+	log   *slowQueryLog
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	defer s.log.done(s.query, time.Now())
+	if se, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return se.ExecContext(ctx, args)
+	}
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Exec(values) //nolint:staticcheck // fallback for drivers not implementing StmtExecContext
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	started := time.Now()
+	var r driver.Rows
+	var err error
+	if sq, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		r, err = sq.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		values, err = namedValuesToValues(args)
+		if err == nil {
+			r, err = s.Stmt.Query(values) //nolint:staticcheck // fallback for drivers not implementing StmtQueryContext
+		}
+	}
+	if err != nil {
+		s.log.done(s.query, started)
+		return nil, err
+	}
+	return &rows{Rows: r, query: s.query, started: started, log: s.log}, nil
+}
+
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// rows measures a query until its rows are closed, so reading slow results counts as well.
+type rows struct {
+	driver.Rows
+	query   string
+	started time.Time
+	log     *slowQueryLog
+}
+
+func (r *rows) Close() error {
+	err := r.Rows.Close()
+	r.log.done(r.query, r.started)
+	return err
+}
+
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, driver.ErrSkip
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
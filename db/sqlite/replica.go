@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+// ReadReplica runs read queries on a read-only replica of a database, e.g. maintained by an external replication tool.
+// If there is no replica or a query on the replica fails, the query runs on the primary database.
+type ReadReplica struct {
+	primary *sqlx.DB
+	replica *sqlx.DB
+	logger  *logger.Logger
+}
+
+// NewReadReplica returns a ReadReplica, if replica is nil all queries run on the primary database.
+func NewReadReplica(primary, replica *sqlx.DB, l *logger.Logger) *ReadReplica {
+	return &ReadReplica{
+		primary: primary,
+		replica: replica,
+		logger:  l,
+	}
+}
+
+// OpenReadReplica opens the file with the given name in replicaDir read-only.
+// The connection is opened lazily, so a replica created after the server started is used as well.
+func OpenReadReplica(replicaDir, filename string, l *logger.Logger) (*sqlx.DB, error) {
+	replicaPath := path.Join(replicaDir, filename)
+	if _, err := os.Stat(replicaPath); err != nil && l != nil {
+		l.Infof("read replica %s not available, using the primary database until it exists: %v", replicaPath, err)
+	}
+
+	replica, err := sqlx.Open("sqlite3", fmt.Sprintf("file:%s?mode=ro", replicaPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open read replica %s: %v", replicaPath, err)
+	}
+	return replica, nil
+}
+
+func (r *ReadReplica) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	if r.replica != nil {
+		err := r.replica.SelectContext(ctx, dest, query, args...)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		r.logFallback(err)
+	}
+	return r.primary.SelectContext(ctx, dest, query, args...)
+}
+
+func (r *ReadReplica) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	if r.replica != nil {
+		err := r.replica.GetContext(ctx, dest, query, args...)
+		if err == nil || errors.Is(err, sql.ErrNoRows) || ctx.Err() != nil {
+			return err
+		}
+		r.logFallback(err)
+	}
+	return r.primary.GetContext(ctx, dest, query, args...)
+}
+
+func (r *ReadReplica) Close() error {
+	if r.replica == nil {
+		return nil
+	}
+	return r.replica.Close()
+}
+
+func (r *ReadReplica) logFallback(err error) {
+	if r.logger != nil {
+		r.logger.Debugf("query on read replica failed, falling back to the primary database: %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/db/migration/dummy"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+func newDummyDB(t *testing.T, dir, id string) *sqlx.DB {
+	db, err := New(path.Join(dir, "test.db"), dummy.AssetNames(), dummy.Asset, DataSourceOptions{})
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO clients (id, client_auth_id, details) VALUES (?, '', '')", id)
+	require.NoError(t, err)
+	return db
+}
+
+func TestReadReplica(t *testing.T) {
+	ctx := context.Background()
+	testLog := logger.NewLogger("replica", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+	primaryDir := t.TempDir()
+	replicaDir := t.TempDir()
+	primary := newDummyDB(t, primaryDir, "primary")
+	defer primary.Close()
+
+	// replica doesn't exist yet
+	replicaDB, err := OpenReadReplica(replicaDir, "test.db", testLog)
+	require.NoError(t, err)
+	r := NewReadReplica(primary, replicaDB, testLog)
+	defer r.Close()
+	var ids []string
+	require.NoError(t, r.SelectContext(ctx, &ids, "SELECT id FROM clients"))
+	assert.Equal(t, []string{"primary"}, ids)
+
+	replica := newDummyDB(t, replicaDir, "replica")
+	require.NoError(t, replica.Close())
+
+	ids = nil
+	require.NoError(t, r.SelectContext(ctx, &ids, "SELECT id FROM clients"))
+	assert.Equal(t, []string{"replica"}, ids)
+
+	// no rows on the replica is a valid result
+	var id string
+	err = r.GetContext(ctx, &id, "SELECT id FROM clients WHERE id = ?", "primary")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// writes are not allowed on the replica
+	_, err = r.replica.Exec("DELETE FROM clients")
+	assert.Error(t, err)
+}
+
+func TestReadReplicaDisabled(t *testing.T) {
+	primary := newDummyDB(t, t.TempDir(), "primary")
+	defer primary.Close()
+
+	r := NewReadReplica(primary, nil, nil)
+	var id string
+	require.NoError(t, r.GetContext(context.Background(), &id, "SELECT id FROM clients"))
+	assert.Equal(t, "primary", id)
+	assert.NoError(t, r.Close())
+}
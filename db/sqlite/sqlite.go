@@ -3,7 +3,11 @@ package sqlite
 import (
 	"fmt"
 	"math/rand"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
@@ -12,29 +16,105 @@ import (
 	"github.com/jmoiron/sqlx"
 	sql "github.com/mattn/go-sqlite3"
 
+	"github.com/realvnc-labs/rport/db/pool"
 	"github.com/realvnc-labs/rport/share/logger"
 )
 
 const (
-	WALEnabled                  = "_journal_mode=WAL"
 	defaultDelayBetweenAttempts = 200 * time.Millisecond
 	DefaultMaxAttempts          = 5
 	DefaultMaxOpenConnections   = 1
+	DefaultBusyTimeout          = 5 * time.Second
+)
+
+var (
+	JournalModes     = []string{"DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF"}
+	SynchronousModes = []string{"OFF", "NORMAL", "FULL", "EXTRA"}
 )
 
 type DataSourceOptions struct {
 	WALEnabled         bool
 	MaxOpenConnections int
+	// MaxIdleConnections, ConnMaxLifetime and ConnMaxIdleTime tune the connection pool, defaults are used if not set
+	MaxIdleConnections int
+	ConnMaxLifetime    time.Duration
+	ConnMaxIdleTime    time.Duration
+	// JournalMode overrides WALEnabled if set, see JournalModes
+	JournalMode string
+	// BusyTimeout is how long a connection waits for a lock before failing with "database is locked",
+	// the driver default of 5s is used if not set
+	BusyTimeout time.Duration
+	// CacheSize is the number of cached pages, or the cache size in KiB if negative, the sqlite default is used if not set
+	CacheSize int
+	// Synchronous is the sqlite synchronous setting, see SynchronousModes
+	Synchronous string
+}
+
+// Validate returns an error if the journal mode or synchronous setting is unknown.
+func (o DataSourceOptions) Validate() error {
+	if o.JournalMode != "" && !contains(JournalModes, o.JournalMode) {
+		return fmt.Errorf("invalid journal mode %q, expected one of %s", o.JournalMode, strings.Join(JournalModes, ", "))
+	}
+	if o.Synchronous != "" && !contains(SynchronousModes, o.Synchronous) {
+		return fmt.Errorf("invalid synchronous setting %q, expected one of %s", o.Synchronous, strings.Join(SynchronousModes, ", "))
+	}
+	if o.BusyTimeout < 0 {
+		return fmt.Errorf("busy timeout must not be negative")
+	}
+	return o.poolOptions().Validate()
+}
+
+func (o DataSourceOptions) poolOptions() pool.Options {
+	maxConns := o.MaxOpenConnections
+	if maxConns == 0 {
+		maxConns = DefaultMaxOpenConnections
+	}
+	return pool.Options{
+		MaxOpenConnections: maxConns,
+		MaxIdleConnections: o.MaxIdleConnections,
+		ConnMaxLifetime:    o.ConnMaxLifetime,
+		ConnMaxIdleTime:    o.ConnMaxIdleTime,
+	}
+}
+
+// dataSourceParams returns the params of the data source name as supported by the sqlite driver.
+func (o DataSourceOptions) dataSourceParams() string {
+	params := url.Values{}
+	switch {
+	case o.JournalMode != "":
+		params.Set("_journal_mode", strings.ToUpper(o.JournalMode))
+	case o.WALEnabled:
+		params.Set("_journal_mode", "WAL")
+	}
+	if o.BusyTimeout > 0 {
+		params.Set("_busy_timeout", strconv.FormatInt(o.BusyTimeout.Milliseconds(), 10))
+	}
+	if o.CacheSize != 0 {
+		params.Set("_cache_size", strconv.Itoa(o.CacheSize))
+	}
+	if o.Synchronous != "" {
+		params.Set("_synchronous", strings.ToUpper(o.Synchronous))
+	}
+	return params.Encode()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
 }
 
 // New returns a new sqlite DB instance with migrated DB scheme to the latest version.
 // assetNames and asset are used to migrate DB scheme.
 func New(dataSourceName string, assetNames []string, asset func(name string) ([]byte, error), dataSourceOptions DataSourceOptions) (*sqlx.DB, error) {
 	dbPath := dataSourceName
-	if dataSourceOptions.WALEnabled {
-		dataSourceName += "?" + WALEnabled
+	if params := dataSourceOptions.dataSourceParams(); params != "" {
+		dataSourceName += "?" + params
 	}
-	db, err := sqlx.Connect("sqlite3", dataSourceName)
+	db, err := pool.Connect(filepath.Base(dbPath), "sqlite3", dataSourceName, dataSourceOptions.poolOptions())
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to DB: %v", err)
 	}
@@ -44,13 +124,6 @@ func New(dataSourceName string, assetNames []string, asset func(name string) ([]
 		}
 	}
 
-	maxConns := dataSourceOptions.MaxOpenConnections
-	if maxConns == 0 {
-		maxConns = DefaultMaxOpenConnections
-	}
-
-	db.SetMaxOpenConns(maxConns)
-
 	s := bindata.Resource(assetNames,
 		func(name string) ([]byte, error) {
 			return asset(name)
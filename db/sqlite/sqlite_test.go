@@ -3,6 +3,7 @@ package sqlite
 import (
 	"os"
 	"testing"
+	"time"
 
 	sql "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
@@ -32,6 +33,38 @@ func TestSqliteWALDisabled(t *testing.T) {
 	assert.ErrorIs(t, err, os.ErrNotExist)
 }
 
+func TestSqliteTuningOptions(t *testing.T) {
+	dataSourceName := t.TempDir() + "/test-db.sqlite3"
+	db, err := New(dataSourceName, dummy.AssetNames(), dummy.Asset, DataSourceOptions{
+		WALEnabled:  true,
+		JournalMode: "truncate",
+		BusyTimeout: 10 * time.Second,
+		CacheSize:   -4000,
+		Synchronous: "NORMAL",
+	})
+	require.NoError(t, err)
+
+	var journalMode string
+	require.NoError(t, db.Get(&journalMode, "PRAGMA journal_mode"))
+	assert.Equal(t, "truncate", journalMode)
+	var busyTimeout int
+	require.NoError(t, db.Get(&busyTimeout, "PRAGMA busy_timeout"))
+	assert.Equal(t, 10000, busyTimeout)
+	var cacheSize int
+	require.NoError(t, db.Get(&cacheSize, "PRAGMA cache_size"))
+	assert.Equal(t, -4000, cacheSize)
+	var synchronous int
+	require.NoError(t, db.Get(&synchronous, "PRAGMA synchronous"))
+	assert.Equal(t, 1, synchronous)
+}
+
+func TestDataSourceOptionsValidate(t *testing.T) {
+	assert.NoError(t, DataSourceOptions{JournalMode: "wal", Synchronous: "normal"}.Validate())
+	assert.EqualError(t, DataSourceOptions{JournalMode: "fast"}.Validate(), `invalid journal mode "fast", expected one of DELETE, TRUNCATE, PERSIST, MEMORY, WAL, OFF`)
+	assert.EqualError(t, DataSourceOptions{Synchronous: "sometimes"}.Validate(), `invalid synchronous setting "sometimes", expected one of OFF, NORMAL, FULL, EXTRA`)
+	assert.EqualError(t, DataSourceOptions{BusyTimeout: -time.Second}.Validate(), "busy timeout must not be negative")
+}
+
 func TestShouldSucceedWhenNoError(t *testing.T) {
 	testLog := logger.NewLogger("retries", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
 
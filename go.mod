@@ -64,7 +64,7 @@ require (
 	gopkg.in/h2non/gock.v1 v1.1.2
 	gopkg.in/ini.v1 v1.62.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -84,6 +84,8 @@ require (
 
 require filippo.io/bigmod v0.0.1
 
+require github.com/pires/go-proxyproto v0.7.0
+
 require (
 	github.com/cespare/xxhash/v2 v2.1.2 // indirect
 	github.com/dgraph-io/ristretto v0.1.1 // indirect
@@ -94,7 +96,7 @@ require (
 	github.com/golang/protobuf v1.5.2 // indirect
 	github.com/golang/snappy v0.0.4 // indirect
 	github.com/google/flatbuffers v1.12.1 // indirect
-	github.com/klauspost/compress v1.16.5 // indirect
+	github.com/klauspost/compress v1.16.5
 	go.etcd.io/bbolt v1.3.7
 	go.opencensus.io v0.23.0 // indirect
 	google.golang.org/protobuf v1.30.0 // indirect
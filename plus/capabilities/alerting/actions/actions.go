@@ -6,5 +6,6 @@ const (
 	LogType     ActionType = "log"
 	NotifyType  ActionType = "notify"
 	IgnoreType  ActionType = "ignore"
+	RunbookType ActionType = "runbook"
 	UnknownType ActionType = "unknown"
 )
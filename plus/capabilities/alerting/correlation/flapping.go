@@ -0,0 +1,132 @@
+package correlation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/entities/clientupdates"
+)
+
+const (
+	DefaultFlappingWindow    = 10 * time.Minute
+	DefaultFlappingThreshold = 6
+)
+
+// FlapDetector collapses frequent connection state changes of a client into a single flapping state.
+// A client is flapping when its connection state changed at least threshold times within the window.
+// While flapping, connection state changes are not passed to the alerting engine, only updates that keep the
+// state with the flapping flag and the occurrence count. The client stops flapping when the connection
+// state didn't change for a whole window.
+type FlapDetector struct {
+	window    time.Duration
+	threshold int
+
+	clients map[string]*flapState
+	now     func() time.Time
+
+	mu sync.Mutex
+}
+
+type flapState struct {
+	connectionState string
+	changes         []time.Time
+
+	flapping     bool
+	flappedState string
+	occurrences  int
+	last         *clientupdates.Client
+}
+
+func NewFlapDetector(window time.Duration, threshold int) *FlapDetector {
+	return &FlapDetector{
+		window:    window,
+		threshold: threshold,
+		clients:   make(map[string]*flapState),
+		now:       time.Now,
+	}
+}
+
+// Correlate updates the flapping state of the client and returns false if the update should be suppressed.
+// Updates passed on while the client is flapping are modified in place.
+func (d *FlapDetector) Correlate(cl *clientupdates.Client) (forward bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	state, ok := d.clients[cl.ID]
+	if !ok {
+		state = &flapState{}
+		d.clients[cl.ID] = state
+	}
+	state.prune(now.Add(-d.window))
+
+	changed := state.connectionState != "" && state.connectionState != cl.ConnectionState
+	state.connectionState = cl.ConnectionState
+	if changed {
+		state.changes = append(state.changes, now)
+		if state.flapping {
+			state.occurrences++
+		}
+	}
+
+	switch {
+	case state.flapping && changed:
+		state.keep(cl)
+		return false
+	case state.flapping && len(state.changes) == 0:
+		cl.FlapCount = state.occurrences
+		d.clients[cl.ID] = &flapState{connectionState: cl.ConnectionState}
+		return true
+	case !state.flapping && len(state.changes) < d.threshold:
+		return true
+	case !state.flapping:
+		state.flapping = true
+		state.flappedState = cl.ConnectionState
+		state.occurrences = len(state.changes)
+	}
+
+	cl.ConnectionState = state.flappedState
+	cl.Flapping = true
+	cl.FlapCount = state.occurrences
+	state.keep(cl)
+	return true
+}
+
+// Stabilized returns the latest updates of all clients that stopped flapping since the last call.
+// The updates carry the current connection state and the total number of occurrences.
+func (d *FlapDetector) Stabilized() []*clientupdates.Client {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	var res []*clientupdates.Client
+	for id, state := range d.clients {
+		state.prune(now.Add(-d.window))
+		if !state.flapping || len(state.changes) > 0 {
+			continue
+		}
+
+		cl := *state.last
+		cl.Timestamp = now
+		cl.ConnectionState = state.connectionState
+		cl.Flapping = false
+		cl.FlapCount = state.occurrences
+		res = append(res, &cl)
+
+		d.clients[id] = &flapState{connectionState: state.connectionState}
+	}
+	return res
+}
+
+func (s *flapState) prune(since time.Time) {
+	i := 0
+	for i < len(s.changes) && s.changes[i].Before(since) {
+		i++
+	}
+	s.changes = s.changes[i:]
+}
+
+func (s *flapState) keep(cl *clientupdates.Client) {
+	last := cl.Clone()
+	s.last = &last
+}
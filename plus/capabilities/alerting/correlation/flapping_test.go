@@ -0,0 +1,103 @@
+package correlation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/entities/clientupdates"
+)
+
+func newTestDetector(now *time.Time) *FlapDetector {
+	d := NewFlapDetector(10*time.Minute, 4)
+	d.now = func() time.Time {
+		return *now
+	}
+	return d
+}
+
+func update(state string) *clientupdates.Client {
+	return &clientupdates.Client{ID: "client-1", ConnectionState: state}
+}
+
+func TestFlapDetectorForwardsStableClient(t *testing.T) {
+	now := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	d := newTestDetector(&now)
+
+	for _, state := range []string{"connected", "connected", "disconnected", "connected"} {
+		cl := update(state)
+		assert.True(t, d.Correlate(cl))
+		assert.False(t, cl.Flapping)
+		assert.Equal(t, state, cl.ConnectionState)
+		now = now.Add(5 * time.Minute)
+	}
+	assert.Empty(t, d.Stabilized())
+}
+
+func TestFlapDetectorCollapsesFlapping(t *testing.T) {
+	now := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	d := newTestDetector(&now)
+
+	states := []string{"connected", "disconnected", "connected", "disconnected"}
+	for _, state := range states {
+		assert.True(t, d.Correlate(update(state)))
+		now = now.Add(time.Second)
+	}
+
+	// the 4th change within the window starts flapping
+	cl := update("connected")
+	require.True(t, d.Correlate(cl))
+	assert.True(t, cl.Flapping)
+	assert.Equal(t, 4, cl.FlapCount)
+	assert.Equal(t, "connected", cl.ConnectionState)
+
+	// further changes are suppressed but counted
+	for _, state := range []string{"disconnected", "connected", "disconnected"} {
+		now = now.Add(time.Second)
+		assert.False(t, d.Correlate(update(state)))
+	}
+
+	// updates without a state change keep the flapping state
+	now = now.Add(time.Second)
+	cl = update("disconnected")
+	require.True(t, d.Correlate(cl))
+	assert.True(t, cl.Flapping)
+	assert.Equal(t, 7, cl.FlapCount)
+	assert.Equal(t, "connected", cl.ConnectionState)
+
+	assert.Empty(t, d.Stabilized())
+
+	// no changes for a whole window stops flapping
+	now = now.Add(11 * time.Minute)
+	stabilized := d.Stabilized()
+	require.Len(t, stabilized, 1)
+	assert.Equal(t, "client-1", stabilized[0].ID)
+	assert.False(t, stabilized[0].Flapping)
+	assert.Equal(t, 7, stabilized[0].FlapCount)
+	assert.Equal(t, "disconnected", stabilized[0].ConnectionState)
+	assert.Equal(t, now, stabilized[0].Timestamp)
+
+	assert.Empty(t, d.Stabilized())
+	cl = update("connected")
+	assert.True(t, d.Correlate(cl))
+	assert.False(t, cl.Flapping)
+}
+
+func TestFlapDetectorStopsFlappingOnUpdate(t *testing.T) {
+	now := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	d := newTestDetector(&now)
+
+	for _, state := range []string{"connected", "disconnected", "connected", "disconnected", "connected"} {
+		d.Correlate(update(state))
+		now = now.Add(time.Second)
+	}
+
+	now = now.Add(11 * time.Minute)
+	cl := update("connected")
+	require.True(t, d.Correlate(cl))
+	assert.False(t, cl.Flapping)
+	assert.Equal(t, 4, cl.FlapCount)
+	assert.Empty(t, d.Stabilized())
+}
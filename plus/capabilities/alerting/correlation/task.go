@@ -0,0 +1,34 @@
+package correlation
+
+import (
+	"context"
+	"fmt"
+
+	alertingcap "github.com/realvnc-labs/rport/plus/capabilities/alerting"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+type FlappingTask struct {
+	log      *logger.Logger
+	detector *FlapDetector
+	as       alertingcap.Service
+}
+
+// NewFlappingTask returns a task that sends the final update of clients that stopped flapping to the alerting service.
+func NewFlappingTask(log *logger.Logger, detector *FlapDetector, as alertingcap.Service) *FlappingTask {
+	return &FlappingTask{
+		log:      log,
+		detector: detector,
+		as:       as,
+	}
+}
+
+func (t *FlappingTask) Run(ctx context.Context) error {
+	for _, cl := range t.detector.Stabilized() {
+		t.log.Debugf("client %s stopped flapping after %d connection state changes", cl.ID, cl.FlapCount)
+		if err := t.as.PutClientUpdate(cl); err != nil {
+			return fmt.Errorf("failed to send client update to the alerting service: %w", err)
+		}
+	}
+	return nil
+}
@@ -18,6 +18,23 @@ type Client struct {
 	DisconnectedAt  *time.Time `json:"disconnected_at"`
 	LastHeartbeatAt *time.Time `json:"last_heartbeat_at"`
 	ConnectionState string     `json:"connection_state"`
+	Flapping        bool       `json:"flapping"`
+	FlapCount       int        `json:"flap_count"` // connection state changes while flapping
+
+	DuplicateClientIDs []string `json:"duplicate_client_ids"` // other clients sharing the hostname or machine serial
+
+	ClockSkew         *float64 `json:"clock_skew"` // seconds the client clock is ahead of the server clock
+	ClockSkewExceeded bool     `json:"clock_skew_exceeded"`
+
+	// resource usage of the client process itself, nil if not reported
+	AgentRSSBytes      *uint64  `json:"agent_rss_bytes"`
+	AgentCPUPercent    *float64 `json:"agent_cpu_percent"`
+	AgentGoroutines    *int     `json:"agent_goroutines"`
+	AgentOpenFDs       *int     `json:"agent_open_fds"`
+	AgentStatsExceeded bool     `json:"agent_stats_exceeded"`
+
+	EOLDate *time.Time `json:"eol_date"` // end-of-life date of the os, nil if unknown
+	IsEOL   bool       `json:"is_eol"`
 
 	Tags   []string          `json:"tags"`
 	Labels map[string]string `json:"labels"`
@@ -49,6 +66,9 @@ func (c *Client) Clone() (clonedClient Client) {
 	clonedClient.Labels = cloneSimpleStringMap(c.Labels)
 	clonedClient.IPv4 = cloneSimpleArray(c.IPv4)
 	clonedClient.IPv6 = cloneSimpleArray(c.IPv6)
+	if c.DuplicateClientIDs != nil {
+		clonedClient.DuplicateClientIDs = cloneSimpleArray(c.DuplicateClientIDs)
+	}
 	clonedClient.Measures = c.Measures.Clone()
 	if c.DisconnectedAt != nil {
 		at := *c.DisconnectedAt
@@ -58,6 +78,30 @@ func (c *Client) Clone() (clonedClient Client) {
 		at := *c.LastHeartbeatAt
 		clonedClient.LastHeartbeatAt = &at
 	}
+	if c.ClockSkew != nil {
+		skew := *c.ClockSkew
+		clonedClient.ClockSkew = &skew
+	}
+	if c.AgentRSSBytes != nil {
+		rss := *c.AgentRSSBytes
+		clonedClient.AgentRSSBytes = &rss
+	}
+	if c.AgentCPUPercent != nil {
+		cpu := *c.AgentCPUPercent
+		clonedClient.AgentCPUPercent = &cpu
+	}
+	if c.AgentGoroutines != nil {
+		goroutines := *c.AgentGoroutines
+		clonedClient.AgentGoroutines = &goroutines
+	}
+	if c.AgentOpenFDs != nil {
+		openFDs := *c.AgentOpenFDs
+		clonedClient.AgentOpenFDs = &openFDs
+	}
+	if c.EOLDate != nil {
+		eol := *c.EOLDate
+		clonedClient.EOLDate = &eol
+	}
 	return clonedClient
 }
 
@@ -18,6 +18,8 @@ type Measure struct {
 
 	Processes   []Process    `json:"processes"`
 	MountPoints []MountPoint `json:"mountpoints"`
+
+	CustomMetrics map[string]float64 `json:"custom_metrics,omitempty"` // latest values pushed via the api
 }
 
 type NetBytes struct {
@@ -57,6 +59,12 @@ func (m *Measure) Clone() (clonedMeasure Measure) {
 	for _, mp := range m.MountPoints {
 		clonedMeasure.MountPoints = append(clonedMeasure.MountPoints, mp.Clone())
 	}
+	if m.CustomMetrics != nil {
+		clonedMeasure.CustomMetrics = make(map[string]float64, len(m.CustomMetrics))
+		for name, value := range m.CustomMetrics {
+			clonedMeasure.CustomMetrics[name] = value
+		}
+	}
 	return clonedMeasure
 }
 
@@ -78,6 +78,17 @@ type NotifyList []templates.TemplateID
 
 type LogMessage string
 
+// RunbookID references a runbook that is run on the client of a problem to remediate it.
+type RunbookID string
+
+// RemediationSettings are the guardrails of a runbook action.
+type RemediationSettings struct {
+	// MaxPerHour limits how often the runbook is run for the rule on the same client, zero means the default limit.
+	MaxPerHour int `json:"max_per_hour,omitempty"`
+	// SuccessOutput is a regular expression the stdout of the runbook must match to consider the remediation successful.
+	SuccessOutput string `json:"success_output,omitempty"`
+}
+
 type IgnoreList []IgnoreSpec
 
 type IgnoreSpec string
@@ -86,6 +97,9 @@ type Action struct {
 	*NotifyList `json:"notify,omitempty"`
 	*IgnoreList `json:"ignore,omitempty"`
 	LogMessage  `json:"log,omitempty"`
+	RunbookID   `json:"runbook,omitempty"`
+
+	*RemediationSettings `json:"remediation,omitempty"`
 }
 
 func (at *Action) GetActType() (actType actions.ActionType) {
@@ -98,6 +112,9 @@ func (at *Action) GetActType() (actType actions.ActionType) {
 	if at.LogMessage != "" {
 		return actions.LogType
 	}
+	if at.RunbookID != "" {
+		return actions.RunbookType
+	}
 	return actions.UnknownType
 }
 
@@ -114,5 +131,10 @@ func (at *Action) Clone() (clonedAct Action) {
 		clonedAct.IgnoreList = &ignoreList
 	}
 	clonedAct.LogMessage = at.LogMessage
+	clonedAct.RunbookID = at.RunbookID
+	if at.RemediationSettings != nil {
+		settings := *at.RemediationSettings
+		clonedAct.RemediationSettings = &settings
+	}
 	return clonedAct
 }
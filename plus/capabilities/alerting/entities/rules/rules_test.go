@@ -14,6 +14,11 @@ func TestShouldCloneActionList(t *testing.T) {
 		NotifyList: &notifyList,
 		IgnoreList: &ignoreList,
 		LogMessage: logMessage,
+		RunbookID:  "runbook1",
+		RemediationSettings: &RemediationSettings{
+			MaxPerHour:    2,
+			SuccessOutput: "active",
+		},
 	}
 
 	action2 := Action{
@@ -0,0 +1,211 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/entities/validations"
+)
+
+const (
+	FormatText  = "text"
+	FormatHTML  = "html"
+	FormatSlack = "slack"
+
+	OutcomeProblem  = "problem"
+	OutcomeResolved = "resolved"
+)
+
+var (
+	ErrUnknownFormatMsg = "unknown format, expected one of: text, html, slack"
+	ErrInvalidSlackMsg  = "slack format must render to valid json blocks"
+)
+
+var supportedFormats = map[string]bool{
+	FormatText:  true,
+	FormatHTML:  true,
+	FormatSlack: true,
+}
+
+var funcs = map[string]interface{}{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"join": strings.Join,
+}
+
+// Data holds the variables that are available in templates, e.g. {{.Client.Name}} or {{.Rule.Severity}}.
+type Data struct {
+	Outcome string      `json:"outcome"`
+	Rule    RuleData    `json:"rule"`
+	Client  ClientData  `json:"client"`
+	Problem ProblemData `json:"problem"`
+}
+
+type RuleData struct {
+	ID       string `json:"id"`
+	Severity string `json:"severity"`
+}
+
+type ClientData struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	Hostname string            `json:"hostname"`
+	Address  string            `json:"address"`
+	OS       string            `json:"os"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels"`
+}
+
+type ProblemData struct {
+	ID         string     `json:"id"`
+	Active     bool       `json:"active"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+}
+
+// SampleData returns variables used to preview a template when no problem or client is given.
+func SampleData() *Data {
+	return &Data{
+		Outcome: OutcomeProblem,
+		Rule: RuleData{
+			ID:       "sample-rule",
+			Severity: "critical",
+		},
+		Client: ClientData{
+			ID:       "sample-client-id",
+			Name:     "sample-client",
+			Hostname: "sample.example.com",
+			Address:  "192.0.2.10:50412",
+			OS:       "Linux sample 5.15.0-56-generic x86_64 GNU/Linux",
+			Tags:     []string{"sample"},
+			Labels:   map[string]string{"city": "Berlin"},
+		},
+		Problem: ProblemData{
+			ID:        "sample-problem-id",
+			Active:    true,
+			CreatedAt: time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+// Rendered is the result of applying template variables to a template.
+type Rendered struct {
+	Subject string            `json:"subject"`
+	Body    string            `json:"body"`
+	Formats map[string]string `json:"formats,omitempty"`
+}
+
+// ValidateFormats checks the channel formats of the template can be parsed.
+func (t *Template) ValidateFormats() (errs validations.ErrorList) {
+	for _, format := range t.sortedFormats() {
+		prefix := fmt.Sprintf("template %s: formats.%s", t.ID, format)
+		if !supportedFormats[format] {
+			errs = append(errs, validations.ValidationError{Prefix: prefix, Err: errors.New(ErrUnknownFormatMsg)})
+			continue
+		}
+		if err := parseFormat(format, t.Formats[format]); err != nil {
+			errs = append(errs, validations.ValidationError{Prefix: prefix, Err: err})
+		}
+	}
+	return errs
+}
+
+// Render applies the data to the subject, the body and all channel formats of the template.
+func (t *Template) Render(data *Data) (*Rendered, error) {
+	subject, err := renderText(t.Subject, data)
+	if err != nil {
+		return nil, fmt.Errorf("subject: %v", err)
+	}
+
+	bodyFormat := FormatText
+	if t.HTML {
+		bodyFormat = FormatHTML
+	}
+	body, err := renderFormat(bodyFormat, t.Body, data)
+	if err != nil {
+		return nil, fmt.Errorf("body: %v", err)
+	}
+
+	res := &Rendered{
+		Subject: subject,
+		Body:    body,
+	}
+	if len(t.Formats) == 0 {
+		return res, nil
+	}
+
+	res.Formats = make(map[string]string, len(t.Formats))
+	for _, format := range t.sortedFormats() {
+		if !supportedFormats[format] {
+			return nil, fmt.Errorf("formats.%s: %s", format, ErrUnknownFormatMsg)
+		}
+		out, err := renderFormat(format, t.Formats[format], data)
+		if err != nil {
+			return nil, fmt.Errorf("formats.%s: %v", format, err)
+		}
+		res.Formats[format] = out
+	}
+	return res, nil
+}
+
+func (t *Template) sortedFormats() []string {
+	formats := make([]string, 0, len(t.Formats))
+	for format := range t.Formats {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+func parseFormat(format, tmpl string) (err error) {
+	if format == FormatHTML {
+		_, err = htmltemplate.New(format).Funcs(funcs).Parse(tmpl)
+	} else {
+		_, err = texttemplate.New(format).Funcs(funcs).Parse(tmpl)
+	}
+	return err
+}
+
+func renderFormat(format, tmpl string, data *Data) (string, error) {
+	if format != FormatHTML {
+		out, err := renderText(tmpl, data)
+		if err != nil {
+			return "", err
+		}
+		if format == FormatSlack && !json.Valid([]byte(out)) {
+			return "", errors.New(ErrInvalidSlackMsg)
+		}
+		return out, nil
+	}
+
+	parsed, err := htmltemplate.New(format).Funcs(funcs).Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := parsed.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func renderText(tmpl string, data *Data) (string, error) {
+	parsed, err := texttemplate.New("text").Funcs(funcs).Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := parsed.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
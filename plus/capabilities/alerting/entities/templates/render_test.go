@@ -0,0 +1,48 @@
+package templates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderEscapesHTML(t *testing.T) {
+	tmpl := &Template{
+		Subject: "{{.Client.Name}}",
+		Body:    "{{.Client.Name}}",
+		Formats: map[string]string{
+			FormatHTML: "<p>{{.Client.Name}}</p>",
+			FormatText: "{{join .Client.Tags \", \"}}",
+		},
+	}
+	data := SampleData()
+	data.Client.Name = "<script>"
+	data.Client.Tags = []string{"a", "b"}
+
+	rendered, err := tmpl.Render(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, "<script>", rendered.Subject)
+	assert.Equal(t, "<script>", rendered.Body)
+	assert.Equal(t, "<p>&lt;script&gt;</p>", rendered.Formats[FormatHTML])
+	assert.Equal(t, "a, b", rendered.Formats[FormatText])
+}
+
+func TestValidateFormats(t *testing.T) {
+	tmpl := &Template{
+		ID: "t1",
+		Formats: map[string]string{
+			FormatSlack: "{{.Client.Name",
+			"teams":     "{{.Client.Name}}",
+			FormatText:  "{{.Client.Name}}",
+		},
+	}
+
+	errs := tmpl.ValidateFormats()
+
+	require.Len(t, errs, 2)
+	assert.Equal(t, "template t1: formats.slack", errs[0].Prefix)
+	assert.Equal(t, "template t1: formats.teams", errs[1].Prefix)
+	assert.EqualError(t, errs[1].Err, ErrUnknownFormatMsg)
+}
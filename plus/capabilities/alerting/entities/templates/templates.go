@@ -36,6 +36,7 @@ type Template struct {
 	HTML                bool                 `json:"html"`
 	ScriptDataTemplates *ScriptDataTemplates `json:"data,omitempty"`
 	Recipients          []string             `json:"recipients,omitempty"`
+	Formats             map[string]string    `json:"formats,omitempty"` // go-template bodies per channel type: text, html or slack
 }
 
 type TemplateList []*Template
@@ -30,10 +30,24 @@ func transformConnectionInfo(rc *rportclients.Client, cl *clientupdates.Client)
 	cl.DisconnectedAt = &disconnectedAt
 	lastHeartbeatAt := rc.GetLastHeartbeatAtValue()
 	cl.LastHeartbeatAt = &lastHeartbeatAt
+	if skew := rc.GetClockSkew(); skew != nil {
+		clockSkew := *skew
+		cl.ClockSkew = &clockSkew
+	}
+	if stats := rc.GetAgentStats(); stats != nil {
+		rss, cpu, goroutines := stats.RSSBytes, stats.CPUPercent, stats.Goroutines
+		cl.AgentRSSBytes = &rss
+		cl.AgentCPUPercent = &cpu
+		cl.AgentGoroutines = &goroutines
+		if stats.OpenFDs != nil {
+			openFDs := *stats.OpenFDs
+			cl.AgentOpenFDs = &openFDs
+		}
+	}
 }
 
 func transformMeta(rc *rportclients.Client, cl *clientupdates.Client) {
-	cl.Tags = rc.GetTags()
+	cl.Tags = append(append([]string{}, rc.GetTags()...), rc.GetAutoTags()...)
 	cl.Labels = rc.GetLabels()
 	// cl.Groups = rc.Groups
 }
@@ -61,4 +75,8 @@ func transformClientDetails(rc *rportclients.Client, cl *clientupdates.Client) {
 	cl.OSVirtualizationRole = rc.GetOSVirtualizationRole()
 	cl.OSVirtualizationSystem = rc.GetOSVirtualizationSystem()
 	cl.Timezone = rc.GetTimezone()
+	if eol := rc.GetEOLDate(); eol != nil {
+		eolDate := *eol
+		cl.EOLDate = &eolDate
+	}
 }
@@ -4,11 +4,13 @@ import (
 	"net/http"
 
 	"github.com/gorilla/websocket"
+
+	errors2 "github.com/realvnc-labs/rport/server/api/errors"
 )
 
 const (
 	ErrCodeMissingRouteVar = "ERR_CODE_MISSING_ROUTE_VAR"
-	ErrCodeInvalidRequest  = "ERR_CODE_INVALID_REQUEST"
+	ErrCodeInvalidRequest  = errors2.ErrCodeInvalidRequest
 	ErrCodeAlreadyExist    = "ERR_CODE_ALREADY_EXIST"
 )
 
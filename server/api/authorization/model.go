@@ -25,6 +25,7 @@ const (
 	APITokenRead        APITokenScope = "read"
 	APITokenReadWrite   APITokenScope = "read+write"
 	APITokenClientsAuth APITokenScope = "clients-auth"
+	APITokenMetricsPush APITokenScope = "metrics-push"
 )
 
 func Extract(prefixedpwd string) (string, string, error) {
@@ -45,7 +46,8 @@ func IsValidScope(scope APITokenScope) bool {
 	case
 		APITokenRead,
 		APITokenReadWrite,
-		APITokenClientsAuth:
+		APITokenClientsAuth,
+		APITokenMetricsPush:
 		return true
 	}
 	return false
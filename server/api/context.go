@@ -10,6 +10,10 @@ type userCtxKeyType string
 
 const userCtxKey userCtxKeyType = "user"
 
+type requestIDCtxKeyType string
+
+const requestIDCtxKey requestIDCtxKeyType = "request_id"
+
 // WithUser returns a copy of a given context that contains a given username.
 func WithUser(ctx context.Context, username string) context.Context {
 	return context.WithValue(ctx, userCtxKey, username)
@@ -25,3 +29,14 @@ func GetUser(ctx context.Context, log *logger.Logger) string {
 	}
 	return user
 }
+
+// WithRequestID returns a copy of a given context that contains a given request id.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey, requestID)
+}
+
+// GetRequestID returns a request id from a given context, empty if the context doesn't belong to an API request.
+func GetRequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDCtxKey).(string)
+	return requestID
+}
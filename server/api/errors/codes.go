@@ -0,0 +1,48 @@
+package errors
+
+import "net/http"
+
+// Error codes are returned in the "code" field of every error payload. They are part of the API and must never change
+// once released, so automation can rely on them instead of parsing the human-readable titles.
+const (
+	ErrCodeInvalidRequest     = "ERR_CODE_INVALID_REQUEST"
+	ErrCodeUnauthorized       = "ERR_CODE_UNAUTHORIZED"
+	ErrCodeForbidden          = "ERR_CODE_FORBIDDEN"
+	ErrCodeNotFound           = "ERR_CODE_NOT_FOUND"
+	ErrCodeMethodNotAllowed   = "ERR_CODE_METHOD_NOT_ALLOWED"
+	ErrCodeConflict           = "ERR_CODE_CONFLICT"
+	ErrCodeGone               = "ERR_CODE_GONE"
+	ErrCodeTooManyRequests    = "ERR_CODE_TOO_MANY_REQUESTS"
+	ErrCodeInternal           = "ERR_CODE_INTERNAL"
+	ErrCodeServiceUnavailable = "ERR_CODE_SERVICE_UNAVAILABLE"
+
+	ErrCodeClientNotFound    = "ERR_CODE_CLIENT_NOT_FOUND"
+	ErrCodeClientNotActive   = "ERR_CODE_CLIENT_NOT_ACTIVE"
+	ErrCodeClientCheckInOnly = "ERR_CODE_CLIENT_CHECK_IN_ONLY"
+	ErrCodeClientQuarantined = "ERR_CODE_CLIENT_QUARANTINED"
+	ErrCodeLocalPortInUse    = "ERR_CODE_LOCAL_PORT_IN_USE"
+)
+
+var codesByStatus = map[int]string{
+	http.StatusBadRequest:          ErrCodeInvalidRequest,
+	http.StatusUnauthorized:        ErrCodeUnauthorized,
+	http.StatusForbidden:           ErrCodeForbidden,
+	http.StatusNotFound:            ErrCodeNotFound,
+	http.StatusMethodNotAllowed:    ErrCodeMethodNotAllowed,
+	http.StatusConflict:            ErrCodeConflict,
+	http.StatusGone:                ErrCodeGone,
+	http.StatusTooManyRequests:     ErrCodeTooManyRequests,
+	http.StatusInternalServerError: ErrCodeInternal,
+	http.StatusServiceUnavailable:  ErrCodeServiceUnavailable,
+}
+
+// CodeFromStatus returns the generic error code of the given http status, used for errors without a specific code.
+func CodeFromStatus(statusCode int) string {
+	if code, ok := codesByStatus[statusCode]; ok {
+		return code
+	}
+	if statusCode >= http.StatusInternalServerError {
+		return ErrCodeInternal
+	}
+	return ErrCodeInvalidRequest
+}
@@ -2,6 +2,7 @@ package jobs
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -43,5 +44,32 @@ func (p *SqliteProvider) CleanupJobsMultiJobs(ctx context.Context, maxJobs int)
 		return errors.Wrap(err, "deleting jobs")
 	}
 
+	return p.deleteOrphanedResultSummaries(ctx)
+}
+
+// DeleteJobsBefore deletes the jobs started before the given time and the multi jobs left without jobs.
+func (p *SqliteProvider) DeleteJobsBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := p.db.ExecContext(ctx, "DELETE FROM jobs WHERE started_at < ?", before.UTC())
+	if err != nil {
+		return 0, errors.Wrap(err, "deleting jobs")
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = p.db.ExecContext(ctx, "DELETE FROM multi_jobs WHERE started_at < ? AND jid NOT IN (SELECT multi_job_id FROM jobs WHERE multi_job_id IS NOT NULL)", before.UTC())
+	if err != nil {
+		return 0, errors.Wrap(err, "deleting multi jobs")
+	}
+
+	return deleted, p.deleteOrphanedResultSummaries(ctx)
+}
+
+func (p *SqliteProvider) deleteOrphanedResultSummaries(ctx context.Context) error {
+	_, err := p.db.ExecContext(ctx, "DELETE FROM multi_job_summaries WHERE multi_job_id NOT IN (SELECT jid FROM multi_jobs)")
+	if err != nil {
+		return errors.Wrap(err, "deleting multi job summaries")
+	}
 	return nil
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/realvnc-labs/rport/db/migration/jobs"
 	"github.com/realvnc-labs/rport/db/sqlite"
 	"github.com/realvnc-labs/rport/server/test/jb"
+	"github.com/realvnc-labs/rport/share/models"
 )
 
 func TestCleanupJobsMultiJobs(t *testing.T) {
@@ -84,3 +85,48 @@ func TestCleanupJobsMultiJobs(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotNil(t, j)
 }
+
+func TestDeleteJobsBefore(t *testing.T) {
+	ctx := context.Background()
+	jobsDB, err := sqlite.New(":memory:", jobs.AssetNames(), jobs.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	p := NewSqliteProvider(jobsDB, testLog)
+	defer p.Close()
+
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+	mjOld := jb.NewMulti(t).StartedAt(old).Build()
+	mjNew := jb.NewMulti(t).StartedAt(now).Build()
+	require.NoError(t, p.SaveMultiJob(mjOld))
+	require.NoError(t, p.SaveMultiJob(mjNew))
+
+	j1 := jb.New(t).MultiJobID(mjOld.JID).StartedAt(old).Build()
+	j2 := jb.New(t).StartedAt(old).Build()
+	j3 := jb.New(t).MultiJobID(mjNew.JID).StartedAt(now).Build()
+	j4 := jb.New(t).StartedAt(now).Build()
+	for _, j := range []*models.Job{j1, j2, j3, j4} {
+		require.NoError(t, p.SaveJob(j))
+	}
+
+	deleted, err := p.DeleteJobsBefore(ctx, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, deleted)
+
+	mj, err := p.GetMultiJob(ctx, mjOld.JID)
+	require.NoError(t, err)
+	assert.Nil(t, mj)
+	mj, err = p.GetMultiJob(ctx, mjNew.JID)
+	require.NoError(t, err)
+	assert.NotNil(t, mj)
+
+	for _, j := range []*models.Job{j1, j2} {
+		found, err := p.GetByJID(j.ClientID, j.JID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	}
+	for _, j := range []*models.Job{j3, j4} {
+		found, err := p.GetByJID(j.ClientID, j.JID)
+		require.NoError(t, err)
+		assert.NotNil(t, found)
+	}
+}
@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+const (
+	// DispatchStatePending is the state of clients of a multi-client job the job is not sent to yet.
+	DispatchStatePending = "pending"
+	// DispatchStateQueued is the state of unreachable clients the job is sent to once they reconnect.
+	DispatchStateQueued = "queued"
+	// DispatchStateDispatching is the state while the job is sent, if the server stops in this state it's unknown
+	// whether the client received the job.
+	DispatchStateDispatching = "dispatching"
+)
+
+// Dispatch is the state of a client of a multi-client job until the job is sent to the client. It's persisted to
+// resume the dispatch after a restart of the server.
+type Dispatch struct {
+	MultiJobID string     `db:"multi_job_id"`
+	ClientID   string     `db:"client_id"`
+	State      string     `db:"state"`
+	ExpiresAt  *time.Time `db:"expires_at"`
+}
+
+// SaveDispatches creates or updates the dispatch states of clients.
+func (p *SqliteProvider) SaveDispatches(dispatches []*Dispatch) error {
+	if len(dispatches) == 0 {
+		return nil
+	}
+	_, err := sqlite.WithRetryWhenBusy(func() (result sql.Result, err error) {
+		return p.db.NamedExec(`INSERT OR REPLACE INTO multi_job_dispatch (multi_job_id, client_id, state, expires_at)
+		VALUES (:multi_job_id, :client_id, :state, :expires_at)`, dispatches)
+	}, "savedispatches", p.log)
+	return err
+}
+
+// SetDispatchState updates the state of a client of a multi-client job, nothing is done if the client has no state.
+func (p *SqliteProvider) SetDispatchState(multiJobID, clientID, state string) error {
+	_, err := sqlite.WithRetryWhenBusy(func() (result sql.Result, err error) {
+		return p.db.Exec("UPDATE multi_job_dispatch SET state = ? WHERE multi_job_id = ? AND client_id = ?", state, multiJobID, clientID)
+	}, "setdispatchstate", p.log)
+	return err
+}
+
+// DeleteDispatch deletes the state of a client of a multi-client job once the job was sent or expired.
+func (p *SqliteProvider) DeleteDispatch(multiJobID, clientID string) error {
+	_, err := sqlite.WithRetryWhenBusy(func() (result sql.Result, err error) {
+		return p.db.Exec("DELETE FROM multi_job_dispatch WHERE multi_job_id = ? AND client_id = ?", multiJobID, clientID)
+	}, "deletedispatch", p.log)
+	return err
+}
+
+// DeletePendingDispatches deletes the pending clients of a multi-client job, e.g. when it's aborted on an error.
+func (p *SqliteProvider) DeletePendingDispatches(multiJobID string) error {
+	_, err := sqlite.WithRetryWhenBusy(func() (result sql.Result, err error) {
+		return p.db.Exec("DELETE FROM multi_job_dispatch WHERE multi_job_id = ? AND state = ?", multiJobID, DispatchStatePending)
+	}, "deletependingdispatches", p.log)
+	return err
+}
+
+// ListDispatches returns the clients of all multi-client jobs the jobs are not sent to yet.
+func (p *SqliteProvider) ListDispatches(ctx context.Context) ([]*Dispatch, error) {
+	var res []*Dispatch
+	err := p.db.SelectContext(ctx, &res, "SELECT * FROM multi_job_dispatch ORDER BY multi_job_id, client_id")
+	return res, err
+}
+
+// ListRunningJobs returns all jobs waiting for the result. Unlike List it reads from the primary db, a replica might
+// not have the latest state.
+func (p *SqliteProvider) ListRunningJobs(ctx context.Context) ([]*models.Job, error) {
+	var res []*jobSqlite
+	err := p.db.SelectContext(ctx, &res, "SELECT jobs.*, schedule_id FROM jobs LEFT JOIN multi_jobs ON jobs.multi_job_id = multi_jobs.jid WHERE status = ?", models.JobStatusRunning)
+	if err != nil {
+		return nil, err
+	}
+	return convertJobs(res), nil
+}
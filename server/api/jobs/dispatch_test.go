@@ -0,0 +1,72 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/db/migration/jobs"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/test/jb"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func TestDispatchSqliteProvider(t *testing.T) {
+	ctx := context.Background()
+	jobsDB, err := sqlite.New(":memory:", jobs.AssetNames(), jobs.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	p := NewSqliteProvider(jobsDB, testLog)
+	defer p.Close()
+
+	multiJob := jb.NewMulti(t).JID("1111").Build()
+	require.NoError(t, p.SaveMultiJob(multiJob))
+
+	expiresAt := time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, p.SaveDispatches([]*Dispatch{
+		{MultiJobID: "1111", ClientID: "client-1", State: DispatchStatePending},
+		{MultiJobID: "1111", ClientID: "client-2", State: DispatchStatePending},
+		{MultiJobID: "1111", ClientID: "client-3", State: DispatchStateQueued, ExpiresAt: &expiresAt},
+	}))
+	require.NoError(t, p.SetDispatchState("1111", "client-1", DispatchStateDispatching))
+	// unknown clients are ignored
+	require.NoError(t, p.SetDispatchState("1111", "client-4", DispatchStateDispatching))
+
+	got, err := p.ListDispatches(ctx)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, &Dispatch{MultiJobID: "1111", ClientID: "client-1", State: DispatchStateDispatching}, got[0])
+	assert.Equal(t, &Dispatch{MultiJobID: "1111", ClientID: "client-2", State: DispatchStatePending}, got[1])
+	assert.Equal(t, "client-3", got[2].ClientID)
+	assert.Equal(t, DispatchStateQueued, got[2].State)
+	require.NotNil(t, got[2].ExpiresAt)
+	assert.True(t, expiresAt.Equal(*got[2].ExpiresAt))
+
+	require.NoError(t, p.DeletePendingDispatches("1111"))
+	require.NoError(t, p.DeleteDispatch("1111", "client-1"))
+
+	got, err = p.ListDispatches(ctx)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "client-3", got[0].ClientID)
+}
+
+func TestListRunningJobs(t *testing.T) {
+	ctx := context.Background()
+	jobsDB, err := sqlite.New(":memory:", jobs.AssetNames(), jobs.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	p := NewSqliteProvider(jobsDB, testLog)
+	defer p.Close()
+
+	running := jb.New(t).JID("1").Status(models.JobStatusRunning).Build()
+	require.NoError(t, p.SaveJob(running))
+	require.NoError(t, p.SaveJob(jb.New(t).JID("2").Status(models.JobStatusSuccessful).Build()))
+	require.NoError(t, p.SaveJob(jb.New(t).JID("3").Status(models.JobStatusFailed).Build()))
+
+	got, err := p.ListRunningJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "1", got[0].JID)
+}
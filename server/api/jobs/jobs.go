@@ -66,6 +66,8 @@ var jobFields = map[string]bool{
 	"error":        true,
 	"is_sudo":      true,
 	"is_script":    true,
+	"is_capture":   true,
+	"labels":       true,
 }
 var JobSupportedFields = map[string]map[string]bool{
 	"jobs":     jobFields,
@@ -89,19 +91,28 @@ var JobListDefaultFields = map[string][]string{
 }
 
 type SqliteProvider struct {
-	log       *logger.Logger
-	db        *sqlx.DB
+	log *logger.Logger
+	db  *sqlx.DB
+	// reader is used for list and report queries
+	reader    *sqlite.ReadReplica
 	converter *query.SQLConverter
 }
 
 func NewSqliteProvider(db *sqlx.DB, log *logger.Logger) *SqliteProvider {
 	return &SqliteProvider{
 		db:        db,
+		reader:    sqlite.NewReadReplica(db, nil, log),
 		log:       log,
 		converter: query.NewSQLConverter(db.DriverName()),
 	}
 }
 
+// SetReadReplica sets a read-only replica of the jobs db used for list and report queries.
+// unguarded as set during initialization
+func (p *SqliteProvider) SetReadReplica(replica *sqlx.DB) {
+	p.reader = sqlite.NewReadReplica(p.db, replica, p.log)
+}
+
 // TODO: this was added to support test dependencies. we could potentially remove if there's a
 // better way.
 func (p *SqliteProvider) GetDB() (db *sqlx.DB) {
@@ -138,7 +149,7 @@ func (p *SqliteProvider) List(ctx context.Context, options *query.ListOptions) (
 	q, params := p.converter.AppendOptionsToQuery(options, q, nil)
 
 	var res []*jobSqlite
-	err := p.db.SelectContext(ctx, &res, q, params...)
+	err := p.reader.SelectContext(ctx, &res, q, params...)
 	if err != nil {
 		return nil, err
 	}
@@ -153,7 +164,7 @@ func (p *SqliteProvider) Count(ctx context.Context, options *query.ListOptions)
 	q, params := p.converter.AppendOptionsToQuery(&countOptions, q, nil)
 
 	var result int
-	err := p.db.GetContext(ctx, &result, q, params...)
+	err := p.reader.GetContext(ctx, &result, q, params...)
 	if err != nil {
 		return 0, err
 	}
@@ -192,11 +203,15 @@ func (p *SqliteProvider) CreateJob(job *models.Job) error {
 		}
 	} else {
 		p.log.Debugf("Job saved successfully: %v", *job)
+		jobsCreatedTotal.Inc(jobType(job))
 	}
 	return err
 }
 
 func (p *SqliteProvider) Close() error {
+	if err := p.reader.Close(); err != nil {
+		return err
+	}
 	return p.db.Close()
 }
 
@@ -213,16 +228,19 @@ type jobSqlite struct {
 }
 
 type JobDetails struct {
-	Command     string            `json:"command"`
-	Cwd         string            `json:"cwd"`
-	IsSudo      bool              `json:"is_sudo"`
-	IsScript    bool              `json:"is_script"`
-	Interpreter string            `json:"interpreter"`
-	PID         *int              `json:"pid"`
-	TimeoutSec  int               `json:"timeout_sec"`
-	Error       string            `json:"error"`
-	Result      *models.JobResult `json:"result"`
-	ClientName  string            `json:"client_name"`
+	Command        string            `json:"command"`
+	Cwd            string            `json:"cwd"`
+	IsSudo         bool              `json:"is_sudo"`
+	IsScript       bool              `json:"is_script"`
+	IsCapture      bool              `json:"is_capture,omitempty"`
+	Interpreter    string            `json:"interpreter"`
+	PID            *int              `json:"pid"`
+	TimeoutSec     int               `json:"timeout_sec"`
+	Error          string            `json:"error"`
+	Result         *models.JobResult `json:"result"`
+	ClientName     string            `json:"client_name"`
+	CorrelationID  string            `json:"correlation_id,omitempty"`
+	RedactionCount int               `json:"redaction_count,omitempty"`
 }
 
 func (d *JobDetails) Scan(value interface{}) error {
@@ -279,6 +297,9 @@ func (j *jobSqlite) convert() *models.Job {
 		res.Cwd = j.Details.Cwd
 		res.IsSudo = j.Details.IsSudo
 		res.IsScript = j.Details.IsScript
+		res.IsCapture = j.Details.IsCapture
+		res.CorrelationID = j.Details.CorrelationID
+		res.RedactionCount = j.Details.RedactionCount
 	}
 	if j.FinishedAt.Valid {
 		res.FinishedAt = &j.FinishedAt.Time
@@ -297,16 +318,19 @@ func convertToSqlite(job *models.Job) *jobSqlite {
 		CreatedBy: job.CreatedBy,
 		ClientID:  job.ClientID,
 		Details: &JobDetails{
-			Command:     job.Command,
-			Interpreter: job.Interpreter,
-			PID:         job.PID,
-			TimeoutSec:  job.TimeoutSec,
-			Result:      job.Result,
-			Error:       job.Error,
-			ClientName:  job.ClientName,
-			Cwd:         job.Cwd,
-			IsSudo:      job.IsSudo,
-			IsScript:    job.IsScript,
+			Command:        job.Command,
+			Interpreter:    job.Interpreter,
+			PID:            job.PID,
+			TimeoutSec:     job.TimeoutSec,
+			Result:         job.Result,
+			Error:          job.Error,
+			ClientName:     job.ClientName,
+			Cwd:            job.Cwd,
+			IsSudo:         job.IsSudo,
+			IsScript:       job.IsScript,
+			IsCapture:      job.IsCapture,
+			CorrelationID:  job.CorrelationID,
+			RedactionCount: job.RedactionCount,
 		},
 	}
 	if job.MultiJobID != nil {
@@ -0,0 +1,28 @@
+package jobs
+
+import (
+	"github.com/realvnc-labs/rport/server/metrics"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+var (
+	jobsCreatedTotal = metrics.NewCounter("rport_jobs_created_total",
+		"Number of jobs created by type.", "type")
+	jobsFinishedTotal = metrics.NewCounter("rport_jobs_finished_total",
+		"Number of job results received from clients by type and status.", "type", "status")
+)
+
+// CountFinishedJob counts a job result received from a client.
+func CountFinishedJob(job *models.Job) {
+	jobsFinishedTotal.Inc(jobType(job), job.Status)
+}
+
+func jobType(job *models.Job) string {
+	switch {
+	case job.IsCapture:
+		return "capture"
+	case job.IsScript:
+		return "script"
+	}
+	return "command"
+}
@@ -16,12 +16,30 @@ type MultiJobRequest struct {
 	Interpreter         string                `json:"interpreter"`
 	TimeoutSec          int                   `json:"timeout_sec"`
 	ExecuteConcurrently bool                  `json:"execute_concurrently"`
+	Labels              []string              `json:"labels"`
 	AbortOnError        *bool                 `json:"abort_on_error"` // pointer is used because it's default value is true. Otherwise it would be more difficult to check whether this field is missing or not
+	// PreflightCheck pings the clients before the job is started, unreachable clients are handled by OnUnreachable
+	PreflightCheck      bool   `json:"preflight_check"`
+	PreflightTimeoutSec int    `json:"preflight_timeout_sec"`
+	OnUnreachable       string `json:"on_unreachable"`
+	QueueTTLSec         int    `json:"queue_ttl_sec"`
+	// Idempotent jobs are sent again to clients when the server stopped before their result was received
+	Idempotent bool `json:"idempotent"`
 
 	Username       string               `json:"-"`
 	IsScript       bool                 `json:"-"`
 	OrderedClients []*clientdata.Client `json:"-"`
-	ScheduleID     *string              `json:"-"`
+	// QueuedClients are unreachable clients the job is run on once they reconnect
+	QueuedClients []*clientdata.Client `json:"-"`
+	ScheduleID    *string              `json:"-"`
+}
+
+// TargetClients returns the clients the job is run on now and the queued ones.
+func (req *MultiJobRequest) TargetClients() []*clientdata.Client {
+	if len(req.QueuedClients) == 0 {
+		return req.OrderedClients
+	}
+	return append(append([]*clientdata.Client{}, req.OrderedClients...), req.QueuedClients...)
 }
 
 func (req *MultiJobRequest) GetClientIDs() (ids []string) {
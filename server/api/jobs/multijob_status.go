@@ -0,0 +1,81 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+// MultiJobStatusSupportedFilters are applied to the jobs listed in the status of a multi-client job.
+var MultiJobStatusSupportedFilters = map[string]bool{
+	"status":    true,
+	"client_id": true,
+}
+
+var MultiJobStatusDefaultFields = map[string][]string{
+	"fields[jobs]": {
+		"jid",
+		"status",
+		"finished_at",
+		"client_id",
+		"client_name",
+		"error",
+	},
+}
+
+// MultiJobStatus aggregates the jobs of a multi-client job by status.
+type MultiJobStatus struct {
+	JID string `json:"jid"`
+	// ClientCount is the number of clients the job was started on, zero for jobs created by older versions
+	ClientCount int `json:"client_count"`
+	Total       int `json:"total"`
+	Successful  int `json:"successful"`
+	Failed      int `json:"failed"`
+	Running     int `json:"running"`
+	Unknown     int `json:"unknown"`
+	// Pending is the number of clients the job was not sent to yet
+	Pending int `json:"pending"`
+	// Aborted is true if a sequential job stopped on a failure, the pending clients are skipped
+	Aborted bool `json:"aborted"`
+	// Finished is true if no job is running and no client is pending
+	Finished bool `json:"finished"`
+}
+
+// NewMultiJobStatus returns the status of the multi-client job based on the number of its jobs by status.
+func NewMultiJobStatus(multiJob *models.MultiJob, counts map[string]int) *MultiJobStatus {
+	s := &MultiJobStatus{
+		JID:         multiJob.JID,
+		ClientCount: multiJob.ClientCount,
+		Successful:  counts[models.JobStatusSuccessful],
+		Failed:      counts[models.JobStatusFailed],
+		Running:     counts[models.JobStatusRunning],
+		Unknown:     counts[models.JobStatusUnknown],
+	}
+	for _, n := range counts {
+		s.Total += n
+	}
+	if s.ClientCount > s.Total {
+		s.Pending = s.ClientCount - s.Total
+	}
+	s.Aborted = s.Pending > 0 && s.Running == 0 && s.Failed > 0 && multiJob.AbortOnErr && !multiJob.Concurrent
+	s.Finished = s.Running == 0 && (s.Pending == 0 || s.Aborted)
+	return s
+}
+
+// CountMultiJobStatuses returns the number of jobs of the given multi-client job by status.
+func (p *SqliteProvider) CountMultiJobStatuses(ctx context.Context, multiJobID string) (map[string]int, error) {
+	var rows []struct {
+		Status string `db:"status"`
+		Count  int    `db:"count"`
+	}
+	err := p.reader.SelectContext(ctx, &rows, "SELECT status, COUNT(*) AS count FROM jobs WHERE multi_job_id = ? GROUP BY status", multiJobID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, r := range rows {
+		counts[r.Status] = r.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,91 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/db/migration/jobs"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/test/jb"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func TestCountMultiJobStatuses(t *testing.T) {
+	ctx := context.Background()
+	jobsDB, err := sqlite.New(":memory:", jobs.AssetNames(), jobs.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	p := NewSqliteProvider(jobsDB, testLog)
+	defer p.Close()
+
+	jobsToSave := []*models.Job{
+		jb.New(t).ClientID("client-1").MultiJobID("multi-1").Build(),
+		jb.New(t).ClientID("client-2").MultiJobID("multi-1").Status(models.JobStatusFailed).Build(),
+		jb.New(t).ClientID("client-3").MultiJobID("multi-1").Status(models.JobStatusFailed).Build(),
+		jb.New(t).ClientID("client-4").MultiJobID("multi-1").Status(models.JobStatusRunning).Build(),
+		jb.New(t).ClientID("client-1").MultiJobID("multi-2").Build(),
+	}
+	for _, job := range jobsToSave {
+		require.NoError(t, p.SaveJob(job))
+	}
+
+	counts, err := p.CountMultiJobStatuses(ctx, "multi-1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{
+		models.JobStatusSuccessful: 1,
+		models.JobStatusFailed:     2,
+		models.JobStatusRunning:    1,
+	}, counts)
+
+	counts, err = p.CountMultiJobStatuses(ctx, "unknown")
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+}
+
+func TestNewMultiJobStatus(t *testing.T) {
+	testCases := []struct {
+		name     string
+		multiJob *models.MultiJob
+		counts   map[string]int
+		want     MultiJobStatus
+	}{
+		{
+			name:     "running",
+			multiJob: &models.MultiJob{ClientCount: 4},
+			counts:   map[string]int{models.JobStatusSuccessful: 1, models.JobStatusRunning: 2},
+			want:     MultiJobStatus{ClientCount: 4, Total: 3, Successful: 1, Running: 2, Pending: 1},
+		},
+		{
+			name:     "finished",
+			multiJob: &models.MultiJob{ClientCount: 3},
+			counts:   map[string]int{models.JobStatusSuccessful: 1, models.JobStatusFailed: 1, models.JobStatusUnknown: 1},
+			want:     MultiJobStatus{ClientCount: 3, Total: 3, Successful: 1, Failed: 1, Unknown: 1, Finished: true},
+		},
+		{
+			name:     "aborted on error",
+			multiJob: &models.MultiJob{ClientCount: 3, AbortOnErr: true},
+			counts:   map[string]int{models.JobStatusFailed: 1},
+			want:     MultiJobStatus{ClientCount: 3, Total: 1, Failed: 1, Pending: 2, Aborted: true, Finished: true},
+		},
+		{
+			name:     "concurrent not aborted",
+			multiJob: &models.MultiJob{ClientCount: 3, AbortOnErr: true, Concurrent: true},
+			counts:   map[string]int{models.JobStatusFailed: 1},
+			want:     MultiJobStatus{ClientCount: 3, Total: 1, Failed: 1, Pending: 2},
+		},
+		{
+			name:     "without client count",
+			multiJob: &models.MultiJob{},
+			counts:   map[string]int{models.JobStatusSuccessful: 2},
+			want:     MultiJobStatus{Total: 2, Successful: 2, Finished: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, &tc.want, NewMultiJobStatus(tc.multiJob, tc.counts))
+		})
+	}
+}
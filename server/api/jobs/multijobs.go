@@ -79,7 +79,7 @@ func (p *SqliteProvider) GetMultiJobSummaries(ctx context.Context, options *quer
 	q := "SELECT jid, started_at, created_by, schedule_id FROM multi_jobs"
 	q, params := p.converter.ConvertListOptionsToQuery(options, q)
 
-	err := p.db.SelectContext(ctx, &res, q, params...)
+	err := p.reader.SelectContext(ctx, &res, q, params...)
 	if err != nil {
 		return nil, err
 	}
@@ -95,7 +95,7 @@ func (p *SqliteProvider) CountMultiJobs(ctx context.Context, options *query.List
 	q := "SELECT count(*) FROM multi_jobs"
 	q, params := p.converter.ConvertListOptionsToQuery(&countOptions, q)
 
-	err := p.db.GetContext(ctx, &result, q, params...)
+	err := p.reader.GetContext(ctx, &result, q, params...)
 	if err != nil {
 		return 0, err
 	}
@@ -130,16 +130,21 @@ type multiJobSummarySqlite struct {
 }
 
 type multiJobDetailSqlite struct {
-	ClientIDs   []string              `json:"client_ids"`
-	GroupIDs    []string              `json:"group_ids"`
-	ClientTags  *models.JobClientTags `json:"tags"`
-	Command     string                `json:"command"`
-	Interpreter string                `json:"interpreter"`
-	Cwd         string                `json:"cwd"`
-	IsSudo      bool                  `json:"is_sudo"`
-	TimeoutSec  int                   `json:"timeout_sec"`
-	Concurrent  bool                  `json:"concurrent"`
-	AbortOnErr  bool                  `json:"abort_on_err"`
+	ClientIDs     []string              `json:"client_ids"`
+	GroupIDs      []string              `json:"group_ids"`
+	ClientTags    *models.JobClientTags `json:"tags"`
+	Command       string                `json:"command"`
+	Interpreter   string                `json:"interpreter"`
+	Cwd           string                `json:"cwd"`
+	IsSudo        bool                  `json:"is_sudo"`
+	TimeoutSec    int                   `json:"timeout_sec"`
+	Concurrent    bool                  `json:"concurrent"`
+	AbortOnErr    bool                  `json:"abort_on_err"`
+	CorrelationID string                `json:"correlation_id,omitempty"`
+	ClientCount   int                   `json:"client_count,omitempty"`
+	IsScript      bool                  `json:"is_script,omitempty"`
+	Labels        []string              `json:"labels,omitempty"`
+	Idempotent    bool                  `json:"idempotent,omitempty"`
 }
 
 func (d *multiJobDetailSqlite) Scan(value interface{}) error {
@@ -200,6 +205,11 @@ func (j *multiJobSqlite) convert() *models.MultiJob {
 		TimeoutSec:      d.TimeoutSec,
 		Concurrent:      d.Concurrent,
 		AbortOnErr:      d.AbortOnErr,
+		CorrelationID:   d.CorrelationID,
+		ClientCount:     d.ClientCount,
+		IsScript:        d.IsScript,
+		Labels:          d.Labels,
+		Idempotent:      d.Idempotent,
 	}
 }
 
@@ -212,16 +222,21 @@ func convertMultiJobToSqlite(job *models.MultiJob) *multiJobSqlite {
 			ScheduleID: job.ScheduleID,
 		},
 		Details: &multiJobDetailSqlite{
-			ClientIDs:   job.ClientIDs,
-			GroupIDs:    job.GroupIDs,
-			ClientTags:  job.ClientTags,
-			Command:     job.Command,
-			Interpreter: job.Interpreter,
-			Cwd:         job.Cwd,
-			IsSudo:      job.IsSudo,
-			TimeoutSec:  job.TimeoutSec,
-			Concurrent:  job.Concurrent,
-			AbortOnErr:  job.AbortOnErr,
+			ClientIDs:     job.ClientIDs,
+			GroupIDs:      job.GroupIDs,
+			ClientTags:    job.ClientTags,
+			Command:       job.Command,
+			Interpreter:   job.Interpreter,
+			Cwd:           job.Cwd,
+			IsSudo:        job.IsSudo,
+			TimeoutSec:    job.TimeoutSec,
+			Concurrent:    job.Concurrent,
+			AbortOnErr:    job.AbortOnErr,
+			CorrelationID: job.CorrelationID,
+			ClientCount:   job.ClientCount,
+			IsScript:      job.IsScript,
+			Labels:        job.Labels,
+			Idempotent:    job.Idempotent,
 		},
 	}
 }
@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/jobqueue"
+)
+
+const (
+	// OnUnreachableSkip runs the job on the reachable clients only.
+	OnUnreachableSkip = "skip"
+	// OnUnreachableQueue runs the job on the unreachable clients once they reconnect.
+	OnUnreachableQueue = "queue"
+
+	DefaultPreflightTimeoutSec = 5
+	MaxPreflightTimeoutSec     = 60
+)
+
+// UnreachableClient is a client that didn't answer the pre-flight ping.
+type UnreachableClient struct {
+	ClientID   string `json:"client_id"`
+	ClientName string `json:"client_name"`
+	Error      string `json:"error"`
+	// Action is either skip or queue
+	Action string `json:"action"`
+}
+
+// ValidatePreflight checks the pre-flight options and sets the defaults.
+func (req *MultiJobRequest) ValidatePreflight() error {
+	if !req.PreflightCheck {
+		if req.OnUnreachable != "" || req.PreflightTimeoutSec != 0 || req.QueueTTLSec != 0 {
+			return fmt.Errorf("preflight_timeout_sec, on_unreachable and queue_ttl_sec require preflight_check")
+		}
+		return nil
+	}
+
+	if req.OnUnreachable == "" {
+		req.OnUnreachable = OnUnreachableSkip
+	}
+	if req.OnUnreachable != OnUnreachableSkip && req.OnUnreachable != OnUnreachableQueue {
+		return fmt.Errorf("on_unreachable must be either %q or %q", OnUnreachableSkip, OnUnreachableQueue)
+	}
+
+	if req.PreflightTimeoutSec == 0 {
+		req.PreflightTimeoutSec = DefaultPreflightTimeoutSec
+	}
+	if req.PreflightTimeoutSec < 0 || req.PreflightTimeoutSec > MaxPreflightTimeoutSec {
+		return fmt.Errorf("preflight_timeout_sec must be between 1 and %d", MaxPreflightTimeoutSec)
+	}
+
+	if req.QueueTTLSec != 0 && req.OnUnreachable != OnUnreachableQueue {
+		return fmt.Errorf("queue_ttl_sec requires on_unreachable %q", OnUnreachableQueue)
+	}
+	if req.OnUnreachable == OnUnreachableQueue {
+		if req.QueueTTLSec == 0 {
+			req.QueueTTLSec = int(jobqueue.DefaultTTL.Seconds())
+		}
+		if req.QueueTTLSec < 0 || time.Duration(req.QueueTTLSec)*time.Second > jobqueue.MaxTTL {
+			return fmt.Errorf("queue_ttl_sec must be between 1 and %d", int(jobqueue.MaxTTL.Seconds()))
+		}
+	}
+	return nil
+}
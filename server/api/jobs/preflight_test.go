@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePreflight(t *testing.T) {
+	testCases := []struct {
+		name    string
+		req     MultiJobRequest
+		want    MultiJobRequest
+		wantErr string
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name:    "options without preflight check",
+			req:     MultiJobRequest{OnUnreachable: OnUnreachableQueue},
+			wantErr: "preflight_timeout_sec, on_unreachable and queue_ttl_sec require preflight_check",
+		},
+		{
+			name: "defaults",
+			req:  MultiJobRequest{PreflightCheck: true},
+			want: MultiJobRequest{PreflightCheck: true, PreflightTimeoutSec: DefaultPreflightTimeoutSec, OnUnreachable: OnUnreachableSkip},
+		},
+		{
+			name: "queue defaults",
+			req:  MultiJobRequest{PreflightCheck: true, OnUnreachable: OnUnreachableQueue},
+			want: MultiJobRequest{PreflightCheck: true, PreflightTimeoutSec: DefaultPreflightTimeoutSec, OnUnreachable: OnUnreachableQueue, QueueTTLSec: 3600},
+		},
+		{
+			name:    "invalid on_unreachable",
+			req:     MultiJobRequest{PreflightCheck: true, OnUnreachable: "wait"},
+			wantErr: `on_unreachable must be either "skip" or "queue"`,
+		},
+		{
+			name:    "timeout too long",
+			req:     MultiJobRequest{PreflightCheck: true, PreflightTimeoutSec: 61},
+			wantErr: "preflight_timeout_sec must be between 1 and 60",
+		},
+		{
+			name:    "ttl without queue",
+			req:     MultiJobRequest{PreflightCheck: true, QueueTTLSec: 60},
+			wantErr: `queue_ttl_sec requires on_unreachable "queue"`,
+		},
+		{
+			name:    "ttl too long",
+			req:     MultiJobRequest{PreflightCheck: true, OnUnreachable: OnUnreachableQueue, QueueTTLSec: 86401},
+			wantErr: "queue_ttl_sec must be between 1 and 86400",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.req.ValidatePreflight()
+
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, tc.req)
+		})
+	}
+}
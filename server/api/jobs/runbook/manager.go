@@ -0,0 +1,418 @@
+package runbook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/api/jobs"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/script"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/query"
+	"github.com/realvnc-labs/rport/share/random"
+)
+
+var (
+	supportedSorts = map[string]bool{
+		"id":         true,
+		"name":       true,
+		"created_at": true,
+		"created_by": true,
+		"script_id":  true,
+	}
+	supportedFilters = map[string]bool{
+		"id":         true,
+		"name":       true,
+		"created_at": true,
+		"created_by": true,
+		"script_id":  true,
+	}
+	supportedRemediationSorts = map[string]bool{
+		"created_at":  true,
+		"finished_at": true,
+		"status":      true,
+	}
+	supportedRemediationFilters = map[string]bool{
+		"problem_id":   true,
+		"rule_id":      true,
+		"runbook_id":   true,
+		"client_id":    true,
+		"multi_job_id": true,
+		"job_id":       true,
+		"status":       true,
+		"created_at":   true,
+	}
+)
+
+// remediationGracePeriod is added to the timeout of a runbook to wait for the job of a remediation to be started.
+const remediationGracePeriod = time.Minute
+
+type Provider interface {
+	Insert(context.Context, *Runbook) error
+	List(context.Context, *query.ListOptions) ([]*Runbook, error)
+	Get(context.Context, string) (*Runbook, error)
+	Delete(context.Context, string) error
+	SaveRemediation(context.Context, *Remediation) error
+	UpdateRemediation(context.Context, *Remediation) error
+	HasRemediation(ctx context.Context, problemID, runbookID string) (bool, error)
+	CountRemediations(ctx context.Context, ruleID, clientID string, since time.Time) (int, error)
+	ListRemediations(context.Context, *query.ListOptions) ([]*Remediation, error)
+}
+
+type JobRunner interface {
+	StartMultiClientJob(ctx context.Context, multiJobRequest *jobs.MultiJobRequest) (*models.MultiJob, error)
+}
+
+// JobProvider is used to get the results of the jobs started by remediations.
+type JobProvider interface {
+	List(ctx context.Context, options *query.ListOptions) ([]*models.Job, error)
+}
+
+type Manager struct {
+	*logger.Logger
+	jobRunner   JobRunner
+	jobProvider JobProvider
+	provider    Provider
+
+	runRemoteCmdTimeoutSec int
+}
+
+func NewManager(jobRunner JobRunner, jobProvider JobProvider, db *sqlx.DB, logger *logger.Logger, runRemoteCmdTimeoutSec int) *Manager {
+	return &Manager{
+		Logger:      logger,
+		jobRunner:   jobRunner,
+		jobProvider: jobProvider,
+		provider:    newSQLiteProvider(db),
+
+		runRemoteCmdTimeoutSec: runRemoteCmdTimeoutSec,
+	}
+}
+
+// Materialize renders the parameters into the stored script and saves it with the targets as a runbook.
+func (m *Manager) Materialize(ctx context.Context, s *script.Script, req *MaterializeRequest, user string) (*Runbook, error) {
+	if req.Name == "" {
+		return nil, errors.APIError{
+			Message:    "Missing name.",
+			Err:        fmt.Errorf("runbook name cannot be empty"),
+			HTTPStatus: http.StatusBadRequest,
+		}
+	}
+
+	existing, err := m.provider.List(ctx, &query.ListOptions{
+		Filters: []query.FilterOption{{Column: []string{"name"}, Values: []string{req.Name}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, errors.APIError{
+			Message:    fmt.Sprintf("another runbook with the same name '%s' exists", req.Name),
+			HTTPStatus: http.StatusConflict,
+		}
+	}
+
+	rendered, err := RenderParams(s.Script, req.Params)
+	if err != nil {
+		return nil, errors.APIError{
+			Message:    "Invalid parameters.",
+			Err:        err,
+			HTTPStatus: http.StatusBadRequest,
+		}
+	}
+
+	rb := &Runbook{
+		Base: Base{
+			Name:      req.Name,
+			CreatedAt: time.Now(),
+			CreatedBy: user,
+			ScriptID:  s.ID,
+		},
+		Details: Details{
+			Params:              req.Params,
+			ClientIDs:           req.ClientIDs,
+			GroupIDs:            req.GroupIDs,
+			ClientTags:          req.ClientTags,
+			Script:              rendered,
+			ExecuteConcurrently: req.ExecuteConcurrently,
+			AbortOnError:        req.AbortOnError,
+		},
+	}
+	if s.Interpreter != nil {
+		rb.Details.Interpreter = *s.Interpreter
+	}
+	if s.Cwd != nil {
+		rb.Details.Cwd = *s.Cwd
+	}
+	if s.IsSudo != nil {
+		rb.Details.IsSudo = *s.IsSudo
+	}
+	if s.TimoutSec != nil {
+		rb.Details.TimeoutSec = *s.TimoutSec
+	}
+
+	rb.ID, err = random.UUID4()
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.provider.Insert(ctx, rb)
+	if err != nil {
+		return nil, err
+	}
+
+	return rb, nil
+}
+
+func (m *Manager) List(ctx context.Context, r *http.Request) (*api.SuccessPayload, error) {
+	listOptions := query.GetListOptions(r)
+
+	err := query.ValidateListOptions(listOptions, supportedSorts, supportedFilters, nil /*fields*/, &query.PaginationConfig{
+		MaxLimit:     100,
+		DefaultLimit: 20,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := listOptions.Pagination
+	listOptions.Pagination = nil
+
+	entries, err := m.provider.List(ctx, listOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	totalCount := len(entries)
+	start, end := pagination.GetStartEnd(totalCount)
+
+	return &api.SuccessPayload{
+		Data: entries[start:end],
+		Meta: api.NewMeta(totalCount),
+	}, nil
+}
+
+// Get returns the runbook with the given id or nil if not found.
+func (m *Manager) Get(ctx context.Context, id string) (*Runbook, error) {
+	return m.provider.Get(ctx, id)
+}
+
+func (m *Manager) Delete(ctx context.Context, id string) error {
+	return m.provider.Delete(ctx, id)
+}
+
+// Run starts the runbook script on the given clients resolved from the targets of the runbook as the given user.
+func (m *Manager) Run(ctx context.Context, rb *Runbook, username string, orderedClients []*clientdata.Client) (*models.MultiJob, error) {
+	m.Infof("Running runbook: %s", rb.ID)
+
+	req := rb.MultiJobRequest(username)
+	req.OrderedClients = orderedClients
+	return m.jobRunner.StartMultiClientJob(ctx, req)
+}
+
+// Remediate runs the runbook on the client of a problem only, as the user who created the runbook.
+// The run is recorded, so a problem triggers the runbook only once, even if it failed to start or was skipped,
+// because the rule reached the limit of runs per hour on the client.
+func (m *Manager) Remediate(ctx context.Context, rb *Runbook, req *RemediationRequest) (*Remediation, error) {
+	maxPerHour := req.MaxPerHour
+	if maxPerHour <= 0 {
+		maxPerHour = DefaultMaxRemediationsPerHour
+	}
+	cnt, err := m.provider.CountRemediations(ctx, req.RuleID, req.ClientID, time.Now().Add(-time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	if cnt >= maxPerHour {
+		return m.SkipRemediation(ctx, rb, req, fmt.Errorf("rule %s reached the limit of %d remediations per hour on the client", req.RuleID, maxPerHour))
+	}
+
+	m.Infof("Running runbook %s to remediate problem %s on client %s", rb.ID, req.ProblemID, req.ClientID)
+
+	jobReq := rb.MultiJobRequest(rb.CreatedBy)
+	jobReq.ClientIDs = []string{req.ClientID}
+	jobReq.GroupIDs = nil
+	jobReq.ClientTags = nil
+	// labels link the job to the problem, the job list can be filtered by them
+	jobReq.Labels = []string{RemediationLabel, "problem:" + req.ProblemID, "rule:" + req.RuleID}
+
+	multiJob, runErr := m.jobRunner.StartMultiClientJob(ctx, jobReq)
+	remediation := newRemediation(rb, req)
+	if runErr != nil {
+		remediation.Status = RemediationStatusFailed
+		remediation.Error = runErr.Error()
+		now := time.Now()
+		remediation.FinishedAt = &now
+	} else {
+		remediation.Status = RemediationStatusRunning
+		remediation.MultiJobID = &multiJob.JID
+	}
+
+	err = m.provider.SaveRemediation(ctx, remediation)
+	if err != nil {
+		return nil, err
+	}
+
+	return remediation, nil
+}
+
+// SkipRemediation records that the runbook is not run for a problem with the reason, it's not tried again.
+func (m *Manager) SkipRemediation(ctx context.Context, rb *Runbook, req *RemediationRequest, reason error) (*Remediation, error) {
+	m.Infof("Skipping runbook %s to remediate problem %s on client %s: %v", rb.ID, req.ProblemID, req.ClientID, reason)
+
+	remediation := newRemediation(rb, req)
+	remediation.Status = RemediationStatusSkipped
+	remediation.Error = reason.Error()
+	remediation.FinishedAt = &remediation.CreatedAt
+
+	err := m.provider.SaveRemediation(ctx, remediation)
+	if err != nil {
+		return nil, err
+	}
+
+	return remediation, nil
+}
+
+func newRemediation(rb *Runbook, req *RemediationRequest) *Remediation {
+	return &Remediation{
+		ProblemID:     req.ProblemID,
+		RuleID:        req.RuleID,
+		RunbookID:     rb.ID,
+		ClientID:      req.ClientID,
+		SuccessOutput: req.SuccessOutput,
+		CreatedAt:     time.Now(),
+	}
+}
+
+// CheckRemediations evaluates the success criteria of the running remediations whose job finished. A remediation
+// without a job result after the timeout of the runbook is failed.
+func (m *Manager) CheckRemediations(ctx context.Context) error {
+	running, err := m.provider.ListRemediations(ctx, &query.ListOptions{
+		Filters: []query.FilterOption{{Column: []string{"status"}, Values: []string{RemediationStatusRunning}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, remediation := range running {
+		err := m.checkRemediation(ctx, remediation)
+		if err != nil {
+			m.Errorf("Failed to check remediation of problem %s with runbook %s: %v", remediation.ProblemID, remediation.RunbookID, err)
+		}
+	}
+
+	return nil
+}
+
+func (m *Manager) checkRemediation(ctx context.Context, remediation *Remediation) error {
+	clientJobs, err := m.jobProvider.List(ctx, &query.ListOptions{
+		Filters: []query.FilterOption{
+			{Column: []string{"multi_job_id"}, Values: []string{*remediation.MultiJobID}},
+			{Column: []string{"client_id"}, Values: []string{remediation.ClientID}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if len(clientJobs) == 0 {
+		if now.Before(remediation.CreatedAt.Add(m.remediationTimeout(ctx, remediation.RunbookID))) {
+			return nil
+		}
+		remediation.Status = RemediationStatusFailed
+		remediation.Error = "no job was started on the client"
+	} else {
+		job := clientJobs[0]
+		remediation.JobID = &job.JID
+		remediation.Status, remediation.Error = evaluate(job, remediation.SuccessOutput)
+		if remediation.Status == RemediationStatusRunning {
+			return nil
+		}
+	}
+	remediation.FinishedAt = &now
+
+	m.Infof("Remediation of problem %s with runbook %s on client %s finished: %s", remediation.ProblemID, remediation.RunbookID, remediation.ClientID, remediation.Status)
+
+	return m.provider.UpdateRemediation(ctx, remediation)
+}
+
+// remediationTimeout returns how long to wait for the job of a remediation to be started.
+func (m *Manager) remediationTimeout(ctx context.Context, runbookID string) time.Duration {
+	timeoutSec := m.runRemoteCmdTimeoutSec
+	rb, err := m.provider.Get(ctx, runbookID)
+	if err == nil && rb != nil && rb.Details.TimeoutSec > 0 {
+		timeoutSec = rb.Details.TimeoutSec
+	}
+
+	return time.Duration(timeoutSec)*time.Second + remediationGracePeriod
+}
+
+// evaluate returns the status of a remediation from the result of the job and the success criteria,
+// with the reason if it failed.
+func evaluate(job *models.Job, successOutput string) (status string, reason string) {
+	switch job.Status {
+	case models.JobStatusRunning:
+		return RemediationStatusRunning, ""
+	case models.JobStatusSuccessful:
+	default:
+		return RemediationStatusFailed, fmt.Sprintf("job %s: %s", job.Status, job.Error)
+	}
+
+	if successOutput == "" {
+		return RemediationStatusSuccessful, ""
+	}
+	re, err := regexp.Compile(successOutput)
+	if err != nil {
+		return RemediationStatusFailed, fmt.Sprintf("invalid success output %q: %v", successOutput, err)
+	}
+	stdout := ""
+	if job.Result != nil {
+		stdout = job.Result.StdOut
+	}
+	if !re.MatchString(stdout) {
+		return RemediationStatusFailed, fmt.Sprintf("output of the job does not match the success output %q", successOutput)
+	}
+
+	return RemediationStatusSuccessful, ""
+}
+
+// IsRemediated returns true if the runbook was already run for the problem.
+func (m *Manager) IsRemediated(ctx context.Context, problemID, runbookID string) (bool, error) {
+	return m.provider.HasRemediation(ctx, problemID, runbookID)
+}
+
+// ListRemediations returns the runs of runbooks triggered by alerting problems.
+func (m *Manager) ListRemediations(ctx context.Context, r *http.Request) (*api.SuccessPayload, error) {
+	listOptions := query.GetListOptions(r)
+
+	err := query.ValidateListOptions(listOptions, supportedRemediationSorts, supportedRemediationFilters, nil /*fields*/, &query.PaginationConfig{
+		MaxLimit:     100,
+		DefaultLimit: 20,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := listOptions.Pagination
+	listOptions.Pagination = nil
+
+	entries, err := m.provider.ListRemediations(ctx, listOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	totalCount := len(entries)
+	start, end := pagination.GetStartEnd(totalCount)
+
+	return &api.SuccessPayload{
+		Data: entries[start:end],
+		Meta: api.NewMeta(totalCount),
+	}, nil
+}
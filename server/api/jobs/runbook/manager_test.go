@@ -0,0 +1,272 @@
+package runbook
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	jobsmigration "github.com/realvnc-labs/rport/db/migration/jobs"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	apiErrors "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/api/jobs"
+	"github.com/realvnc-labs/rport/server/script"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/ptr"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+var testLog = logger.NewLogger("runbook", logger.LogOutput{File: nil}, logger.LogLevelDebug)
+
+type fakeJobRunner struct {
+	requests []*jobs.MultiJobRequest
+	err      error
+}
+
+func (f *fakeJobRunner) StartMultiClientJob(ctx context.Context, req *jobs.MultiJobRequest) (*models.MultiJob, error) {
+	f.requests = append(f.requests, req)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.MultiJob{MultiJobSummary: models.MultiJobSummary{JID: "multi-job-1"}}, nil
+}
+
+func newTestManager(t *testing.T, runner JobRunner) (*Manager, *jobs.SqliteProvider) {
+	db, err := sqlite.New(":memory:", jobsmigration.AssetNames(), jobsmigration.Asset, sqlite.DataSourceOptions{})
+	require.NoError(t, err)
+	jp := jobs.NewSqliteProvider(db, testLog)
+	t.Cleanup(func() { jp.Close() })
+
+	return NewManager(runner, jp, db, testLog, 60), jp
+}
+
+var testScript = &script.Script{
+	ID:          "script-1",
+	Name:        "restart service",
+	Interpreter: ptr.String("/bin/bash"),
+	Cwd:         ptr.String("/tmp"),
+	IsSudo:      ptr.Bool(true),
+	Script:      "systemctl restart {{.params.service}} && echo {{.client.Name}}",
+	TimoutSec:   ptr.Int(30),
+}
+
+func TestMaterialize(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestManager(t, &fakeJobRunner{})
+
+	rb, err := m.Materialize(ctx, testScript, &MaterializeRequest{
+		Name:      "restart nginx",
+		Params:    map[string]string{"service": "nginx"},
+		ClientIDs: []string{"client-1"},
+	}, "admin")
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, rb.ID)
+	assert.Equal(t, "admin", rb.CreatedBy)
+	assert.Equal(t, "script-1", rb.ScriptID)
+	assert.Equal(t, Details{
+		Params:      map[string]string{"service": "nginx"},
+		ClientIDs:   []string{"client-1"},
+		Script:      "systemctl restart nginx && echo {{.client.Name}}",
+		Interpreter: "/bin/bash",
+		Cwd:         "/tmp",
+		IsSudo:      true,
+		TimeoutSec:  30,
+	}, rb.Details)
+
+	stored, err := m.Get(ctx, rb.ID)
+	require.NoError(t, err)
+	assert.Equal(t, rb.Details, stored.Details)
+	assert.Equal(t, rb.Name, stored.Name)
+
+	_, err = m.Materialize(ctx, testScript, &MaterializeRequest{
+		Name:      "restart nginx",
+		Params:    map[string]string{"service": "nginx"},
+		ClientIDs: []string{"client-1"},
+	}, "admin")
+	assert.Equal(t, apiErrors.APIError{
+		Message:    "another runbook with the same name 'restart nginx' exists",
+		HTTPStatus: http.StatusConflict,
+	}, err)
+
+	_, err = m.Materialize(ctx, testScript, &MaterializeRequest{
+		Name:      "missing params",
+		ClientIDs: []string{"client-1"},
+	}, "admin")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `map has no entry for key "service"`)
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	runner := &fakeJobRunner{}
+	m, _ := newTestManager(t, runner)
+
+	rb, err := m.Materialize(ctx, testScript, &MaterializeRequest{
+		Name:     "restart nginx",
+		Params:   map[string]string{"service": "nginx"},
+		GroupIDs: []string{"group-1"},
+	}, "admin")
+	require.NoError(t, err)
+
+	multiJob, err := m.Run(ctx, rb, "operator", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "multi-job-1", multiJob.JID)
+	require.Len(t, runner.requests, 1)
+	req := runner.requests[0]
+	assert.Equal(t, "operator", req.Username)
+	assert.Equal(t, []string{"group-1"}, req.GroupIDs)
+	assert.True(t, req.IsScript)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("systemctl restart nginx && echo {{.client.Name}}")), req.Script)
+}
+
+func TestRemediate(t *testing.T) {
+	ctx := context.Background()
+	runner := &fakeJobRunner{}
+	m, jp := newTestManager(t, runner)
+
+	rb, err := m.Materialize(ctx, testScript, &MaterializeRequest{
+		Name:     "restart nginx",
+		Params:   map[string]string{"service": "nginx"},
+		GroupIDs: []string{"group-1"},
+	}, "admin")
+	require.NoError(t, err)
+
+	done, err := m.IsRemediated(ctx, "problem-1", rb.ID)
+	require.NoError(t, err)
+	assert.False(t, done)
+
+	remediation, err := m.Remediate(ctx, rb, &RemediationRequest{
+		ProblemID:     "problem-1",
+		RuleID:        "rule-1",
+		ClientID:      "client-1",
+		MaxPerHour:    2,
+		SuccessOutput: "^active",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, RemediationStatusRunning, remediation.Status)
+	assert.Equal(t, ptr.String("multi-job-1"), remediation.MultiJobID)
+	require.Len(t, runner.requests, 1)
+	req := runner.requests[0]
+	assert.Equal(t, "admin", req.Username)
+	assert.Equal(t, []string{"client-1"}, req.ClientIDs)
+	assert.Nil(t, req.GroupIDs)
+	assert.Equal(t, []string{"remediation", "problem:problem-1", "rule:rule-1"}, req.Labels)
+
+	done, err = m.IsRemediated(ctx, "problem-1", rb.ID)
+	require.NoError(t, err)
+	assert.True(t, done)
+
+	runner.err = errors.New("client not active")
+	remediation, err = m.Remediate(ctx, rb, &RemediationRequest{ProblemID: "problem-2", RuleID: "rule-1", ClientID: "client-1", MaxPerHour: 2})
+	require.NoError(t, err)
+	assert.Equal(t, RemediationStatusFailed, remediation.Status)
+	assert.Equal(t, "client not active", remediation.Error)
+
+	// the limit of the rule on the client is reached
+	runner.err = nil
+	remediation, err = m.Remediate(ctx, rb, &RemediationRequest{ProblemID: "problem-3", RuleID: "rule-1", ClientID: "client-1", MaxPerHour: 2})
+	require.NoError(t, err)
+	assert.Equal(t, RemediationStatusSkipped, remediation.Status)
+	assert.Equal(t, "rule rule-1 reached the limit of 2 remediations per hour on the client", remediation.Error)
+	remediation, err = m.Remediate(ctx, rb, &RemediationRequest{ProblemID: "problem-4", RuleID: "rule-1", ClientID: "client-2", MaxPerHour: 2})
+	require.NoError(t, err)
+	assert.Equal(t, RemediationStatusRunning, remediation.Status)
+	assert.Len(t, runner.requests, 3)
+
+	// the job of problem-1 finished
+	finishedAt := time.Now()
+	require.NoError(t, jp.CreateJob(&models.Job{
+		JID:        "job-1",
+		ClientID:   "client-1",
+		MultiJobID: ptr.String("multi-job-1"),
+		Status:     models.JobStatusSuccessful,
+		StartedAt:  finishedAt,
+		FinishedAt: &finishedAt,
+		Result:     &models.JobResult{StdOut: "active (running)"},
+	}))
+	require.NoError(t, m.CheckRemediations(ctx))
+
+	got, err := m.provider.ListRemediations(ctx, &query.ListOptions{
+		Filters: []query.FilterOption{{Column: []string{"problem_id"}, Values: []string{"problem-1"}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, RemediationStatusSuccessful, got[0].Status)
+	assert.Equal(t, ptr.String("job-1"), got[0].JobID)
+	assert.NotNil(t, got[0].FinishedAt)
+
+	// problem-4 has no job of client-2 yet
+	got, err = m.provider.ListRemediations(ctx, &query.ListOptions{
+		Filters: []query.FilterOption{{Column: []string{"status"}, Values: []string{RemediationStatusRunning}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "problem-4", got[0].ProblemID)
+
+	require.NoError(t, m.Delete(ctx, rb.ID))
+	got, err = m.provider.ListRemediations(ctx, &query.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestEvaluate(t *testing.T) {
+	testCases := []struct {
+		name           string
+		job            *models.Job
+		successOutput  string
+		expectedStatus string
+		expectedReason string
+	}{
+		{
+			name:           "running",
+			job:            &models.Job{Status: models.JobStatusRunning},
+			expectedStatus: RemediationStatusRunning,
+		},
+		{
+			name:           "successful",
+			job:            &models.Job{Status: models.JobStatusSuccessful},
+			expectedStatus: RemediationStatusSuccessful,
+		},
+		{
+			name:           "failed",
+			job:            &models.Job{Status: models.JobStatusFailed, Error: "exit status 1"},
+			expectedStatus: RemediationStatusFailed,
+			expectedReason: "job failed: exit status 1",
+		},
+		{
+			name:           "output matches",
+			job:            &models.Job{Status: models.JobStatusSuccessful, Result: &models.JobResult{StdOut: "nginx is active"}},
+			successOutput:  "is active$",
+			expectedStatus: RemediationStatusSuccessful,
+		},
+		{
+			name:           "output does not match",
+			job:            &models.Job{Status: models.JobStatusSuccessful, Result: &models.JobResult{StdOut: "nginx is inactive"}},
+			successOutput:  "is active$",
+			expectedStatus: RemediationStatusFailed,
+			expectedReason: `output of the job does not match the success output "is active$"`,
+		},
+		{
+			name:           "no output",
+			job:            &models.Job{Status: models.JobStatusSuccessful},
+			successOutput:  "active",
+			expectedStatus: RemediationStatusFailed,
+			expectedReason: `output of the job does not match the success output "active"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, reason := evaluate(tc.job, tc.successOutput)
+			assert.Equal(t, tc.expectedStatus, status)
+			assert.Equal(t, tc.expectedReason, reason)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package runbook
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/api/jobs"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+// Runbook is a stored script materialized with a set of parameters and a target, it's executed with one call.
+type Runbook struct {
+	Base
+	Details Details `json:"details" db:"details"`
+}
+
+func (rb *Runbook) GetClientIDs() (ids []string) {
+	return rb.Details.ClientIDs
+}
+
+func (rb *Runbook) GetGroupIDs() (ids []string) {
+	return rb.Details.GroupIDs
+}
+
+func (rb *Runbook) GetClientTags() (clientTags *models.JobClientTags) {
+	return rb.Details.ClientTags
+}
+
+// MultiJobRequest returns the request to run the runbook script on its targets as the given user.
+func (rb *Runbook) MultiJobRequest(username string) *jobs.MultiJobRequest {
+	return &jobs.MultiJobRequest{
+		Username:            username,
+		ClientIDs:           rb.Details.ClientIDs,
+		GroupIDs:            rb.Details.GroupIDs,
+		ClientTags:          rb.Details.ClientTags,
+		Script:              base64.StdEncoding.EncodeToString([]byte(rb.Details.Script)),
+		Interpreter:         rb.Details.Interpreter,
+		Cwd:                 rb.Details.Cwd,
+		IsSudo:              rb.Details.IsSudo,
+		TimeoutSec:          rb.Details.TimeoutSec,
+		ExecuteConcurrently: rb.Details.ExecuteConcurrently,
+		AbortOnError:        rb.Details.AbortOnError,
+		IsScript:            true,
+	}
+}
+
+type Base struct {
+	ID        string    `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	CreatedBy string    `json:"created_by" db:"created_by"`
+	ScriptID  string    `json:"script_id" db:"script_id"`
+}
+
+type Details struct {
+	Params              map[string]string     `json:"params"`
+	ClientIDs           []string              `json:"client_ids"`
+	GroupIDs            []string              `json:"group_ids"`
+	ClientTags          *models.JobClientTags `json:"tags"`
+	Script              string                `json:"script"`
+	Interpreter         string                `json:"interpreter"`
+	Cwd                 string                `json:"cwd"`
+	IsSudo              bool                  `json:"is_sudo"`
+	TimeoutSec          int                   `json:"timeout_sec"`
+	ExecuteConcurrently bool                  `json:"execute_concurrently"`
+	AbortOnError        *bool                 `json:"abort_on_error"`
+}
+
+func (d *Details) Scan(value interface{}) error {
+	if d == nil {
+		return errors.New("'details' cannot be nil")
+	}
+	valueStr, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("expected to have string, got %T", value)
+	}
+	err := json.Unmarshal([]byte(valueStr), d)
+	if err != nil {
+		return fmt.Errorf("failed to decode 'details' field: %v", err)
+	}
+	return nil
+}
+
+func (d Details) Value() (driver.Value, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode 'details' field: %v", err)
+	}
+	return string(b), nil
+}
+
+// MaterializeRequest is the input to create a runbook from a stored script.
+type MaterializeRequest struct {
+	Name                string                `json:"name"`
+	Params              map[string]string     `json:"params"`
+	ClientIDs           []string              `json:"client_ids"`
+	GroupIDs            []string              `json:"group_ids"`
+	ClientTags          *models.JobClientTags `json:"tags"`
+	ExecuteConcurrently bool                  `json:"execute_concurrently"`
+	AbortOnError        *bool                 `json:"abort_on_error"`
+}
+
+func (r *MaterializeRequest) GetClientIDs() (ids []string) {
+	return r.ClientIDs
+}
+
+func (r *MaterializeRequest) GetGroupIDs() (ids []string) {
+	return r.GroupIDs
+}
+
+func (r *MaterializeRequest) GetClientTags() (clientTags *models.JobClientTags) {
+	return r.ClientTags
+}
+
+const (
+	RemediationStatusRunning    = "running"
+	RemediationStatusSuccessful = "successful"
+	RemediationStatusFailed     = "failed"
+	RemediationStatusSkipped    = "skipped"
+	RemediationStatusUnknown    = "unknown"
+)
+
+// RemediationLabel is the label of the jobs started by remediations.
+const RemediationLabel = "remediation"
+
+// DefaultMaxRemediationsPerHour limits the runs of a runbook for the same rule and client, if the rule doesn't set a limit.
+const DefaultMaxRemediationsPerHour = 3
+
+// RemediationRequest is a problem of an alerting rule to be remediated with a runbook and the guardrails of the rule action.
+type RemediationRequest struct {
+	ProblemID     string
+	RuleID        string
+	ClientID      string
+	MaxPerHour    int
+	SuccessOutput string
+}
+
+// Remediation records a run of a runbook triggered by an alerting problem, a problem is remediated only once.
+// It links the problem and the rule to the started multi-client job and the job of the client.
+type Remediation struct {
+	ProblemID     string     `json:"problem_id" db:"problem_id"`
+	RuleID        string     `json:"rule_id" db:"rule_id"`
+	RunbookID     string     `json:"runbook_id" db:"runbook_id"`
+	ClientID      string     `json:"client_id" db:"client_id"`
+	MultiJobID    *string    `json:"multi_job_id" db:"multi_job_id"`
+	JobID         *string    `json:"job_id" db:"job_id"`
+	Status        string     `json:"status" db:"status"`
+	SuccessOutput string     `json:"success_output" db:"success_output"`
+	Error         string     `json:"error" db:"error"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	FinishedAt    *time.Time `json:"finished_at" db:"finished_at"`
+}
@@ -0,0 +1,49 @@
+package runbook
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// paramRegex matches template actions referencing a parameter, other actions like client variables are resolved
+// when the job is dispatched and left untouched.
+var paramRegex = regexp.MustCompile(`\{\{-?\s*\.params\.[^{}]*\}\}`)
+
+// RenderParams replaces the parameters in a script body, e.g. {{.params.service}}, with the given values.
+// A parameter used in the script without a value is an error.
+func RenderParams(body string, params map[string]string) (string, error) {
+	if !strings.Contains(body, "{{") {
+		return body, nil
+	}
+
+	if params == nil {
+		params = map[string]string{}
+	}
+	data := map[string]interface{}{
+		"params": params,
+	}
+	var renderErr error
+	rendered := paramRegex.ReplaceAllStringFunc(body, func(action string) string {
+		if renderErr != nil {
+			return action
+		}
+		tmpl, err := template.New("").Option("missingkey=error").Parse(action)
+		if err != nil {
+			renderErr = fmt.Errorf("invalid parameter %s: %v", action, err)
+			return action
+		}
+		var b strings.Builder
+		err = tmpl.Execute(&b, data)
+		if err != nil {
+			renderErr = fmt.Errorf("failed to render parameter %s: %v", action, err)
+			return action
+		}
+		return b.String()
+	})
+	if renderErr != nil {
+		return "", renderErr
+	}
+	return rendered, nil
+}
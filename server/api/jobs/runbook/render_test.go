@@ -0,0 +1,59 @@
+package runbook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderParams(t *testing.T) {
+	testCases := []struct {
+		name          string
+		body          string
+		params        map[string]string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "no parameters",
+			body:     "systemctl restart nginx",
+			expected: "systemctl restart nginx",
+		},
+		{
+			name:     "parameters",
+			body:     "systemctl {{.params.action}} {{ .params.service }}",
+			params:   map[string]string{"action": "restart", "service": "nginx"},
+			expected: "systemctl restart nginx",
+		},
+		{
+			name:     "client variables and other actions are kept",
+			body:     "echo {{.client.Name}} {{.params.service}}; docker ps --format '{{.Names}}'",
+			params:   map[string]string{"service": "nginx"},
+			expected: "echo {{.client.Name}} nginx; docker ps --format '{{.Names}}'",
+		},
+		{
+			name:          "missing parameter",
+			body:          "systemctl restart {{.params.service}}",
+			expectedError: `failed to render parameter {{.params.service}}: template: :1:9: executing "" at <.params.service>: map has no entry for key "service"`,
+		},
+		{
+			name:          "invalid parameter",
+			body:          "echo {{.params.service | nope}}",
+			params:        map[string]string{"service": "nginx"},
+			expectedError: `invalid parameter {{.params.service | nope}}: template: :1: function "nope" not defined`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rendered, err := RenderParams(tc.body, tc.params)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, rendered)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package runbook
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+type SQLiteProvider struct {
+	db        *sqlx.DB
+	converter *query.SQLConverter
+}
+
+func newSQLiteProvider(db *sqlx.DB) *SQLiteProvider {
+	return &SQLiteProvider{
+		db:        db,
+		converter: query.NewSQLConverter(db.DriverName()),
+	}
+}
+
+func (p *SQLiteProvider) Insert(ctx context.Context, rb *Runbook) error {
+	_, err := p.db.NamedExecContext(ctx,
+		`INSERT INTO runbooks (
+			id,
+			created_at,
+			created_by,
+			name,
+			script_id,
+			details
+		) VALUES (
+			:id,
+			:created_at,
+			:created_by,
+			:name,
+			:script_id,
+			:details
+		)`,
+		rb,
+	)
+
+	return err
+}
+
+func (p *SQLiteProvider) List(ctx context.Context, options *query.ListOptions) ([]*Runbook, error) {
+	values := []*Runbook{}
+
+	q, params := p.converter.ConvertListOptionsToQuery(options, "SELECT * FROM runbooks")
+
+	err := p.db.SelectContext(ctx, &values, q, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func (p *SQLiteProvider) Get(ctx context.Context, id string) (*Runbook, error) {
+	rb := &Runbook{}
+	err := p.db.GetContext(ctx, rb, "SELECT * FROM runbooks WHERE id = ? LIMIT 1", id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return rb, nil
+}
+
+func (p *SQLiteProvider) Delete(ctx context.Context, id string) error {
+	res, err := p.db.ExecContext(ctx, "DELETE FROM runbooks WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affectedRows == 0 {
+		return fmt.Errorf("cannot find entry by id %s", id)
+	}
+
+	_, err = p.db.ExecContext(ctx, "DELETE FROM runbook_remediations WHERE runbook_id = ?", id)
+	return err
+}
+
+// SaveRemediation records the run of a runbook for a problem.
+func (p *SQLiteProvider) SaveRemediation(ctx context.Context, r *Remediation) error {
+	_, err := p.db.NamedExecContext(ctx,
+		`INSERT INTO runbook_remediations (
+			problem_id,
+			rule_id,
+			runbook_id,
+			client_id,
+			multi_job_id,
+			job_id,
+			status,
+			success_output,
+			error,
+			created_at,
+			finished_at
+		) VALUES (
+			:problem_id,
+			:rule_id,
+			:runbook_id,
+			:client_id,
+			:multi_job_id,
+			:job_id,
+			:status,
+			:success_output,
+			:error,
+			:created_at,
+			:finished_at
+		)`,
+		r,
+	)
+
+	return err
+}
+
+// UpdateRemediation saves the result of a remediation.
+func (p *SQLiteProvider) UpdateRemediation(ctx context.Context, r *Remediation) error {
+	_, err := p.db.NamedExecContext(ctx,
+		`UPDATE runbook_remediations SET
+			job_id = :job_id,
+			status = :status,
+			error = :error,
+			finished_at = :finished_at
+		WHERE problem_id = :problem_id AND runbook_id = :runbook_id`,
+		r,
+	)
+
+	return err
+}
+
+// HasRemediation returns true if the runbook was already run for the problem.
+func (p *SQLiteProvider) HasRemediation(ctx context.Context, problemID, runbookID string) (bool, error) {
+	var cnt int
+	err := p.db.GetContext(ctx, &cnt, "SELECT count(*) FROM runbook_remediations WHERE problem_id = ? AND runbook_id = ?", problemID, runbookID)
+	if err != nil {
+		return false, err
+	}
+
+	return cnt > 0, nil
+}
+
+// CountRemediations counts the runbook runs for the rule on the client since the given time, skipped ones are not counted.
+func (p *SQLiteProvider) CountRemediations(ctx context.Context, ruleID, clientID string, since time.Time) (int, error) {
+	var cnt int
+	err := p.db.GetContext(ctx, &cnt, `
+SELECT count(*)
+FROM runbook_remediations
+WHERE
+	rule_id = ?
+AND
+	client_id = ?
+AND
+	created_at >= ?
+AND
+	status != '`+RemediationStatusSkipped+`'
+`, ruleID, clientID, since)
+	if err != nil {
+		return 0, err
+	}
+
+	return cnt, nil
+}
+
+// ListRemediations returns the remediations matching the filters, latest first if no sort is given.
+func (p *SQLiteProvider) ListRemediations(ctx context.Context, options *query.ListOptions) ([]*Remediation, error) {
+	if len(options.Sorts) == 0 {
+		options.Sorts = []query.SortOption{{Column: "created_at", IsASC: false}}
+	}
+
+	values := []*Remediation{}
+	q, params := p.converter.ConvertListOptionsToQuery(options, "SELECT * FROM runbook_remediations")
+	err := p.db.SelectContext(ctx, &values, q, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func (p *SQLiteProvider) Close() error {
+	return p.db.Close()
+}
@@ -147,6 +147,20 @@ func (m *Manager) Get(ctx context.Context, id string) (*Schedule, error) {
 	return m.provider.Get(ctx, id)
 }
 
+// GetByName returns the schedule with the given name or nil if not found.
+func (m *Manager) GetByName(ctx context.Context, name string) (*Schedule, error) {
+	entries, err := m.provider.List(ctx, &query.ListOptions{
+		Filters: []query.FilterOption{{Column: []string{"name"}, Values: []string{name}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return entries[0], nil
+}
+
 func (m *Manager) Create(ctx context.Context, s *Schedule, user string) (*Schedule, error) {
 	var err error
 	s.ID, err = random.UUID4()
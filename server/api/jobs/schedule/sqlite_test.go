@@ -228,3 +228,23 @@ func assertCount(t *testing.T, db *sqlx.DB, expected int, query string) {
 
 	assert.Equal(t, expected, result)
 }
+
+func TestGetByName(t *testing.T) {
+	db, err := sqlite.New(":memory:", jobsmigration.AssetNames(), jobsmigration.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	dbProv := newSQLiteProvider(db)
+	defer dbProv.Close()
+	manager := &Manager{provider: dbProv}
+	ctx := context.Background()
+
+	err = addTestData(db)
+	require.NoError(t, err)
+
+	val, err := manager.GetByName(ctx, "schedule 2")
+	require.NoError(t, err)
+	assert.Equal(t, testData[1], val)
+
+	val, err = manager.GetByName(ctx, "schedule 3")
+	require.NoError(t, err)
+	assert.Nil(t, val)
+}
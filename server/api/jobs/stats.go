@@ -0,0 +1,197 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+// JobStatsSupportedFilters contains the filters applied to the jobs before aggregating them, see jobStatsInnerFilters,
+// and the filters applied to the aggregated stats.
+var JobStatsSupportedFilters = map[string]bool{
+	"started_at[gt]":    true,
+	"started_at[lt]":    true,
+	"started_at[since]": true,
+	"started_at[until]": true,
+	"client_id":         true,
+	"total[gt]":         true,
+	"failed[gt]":        true,
+	"success_rate[gt]":  true,
+	"success_rate[lt]":  true,
+}
+var JobStatsClientSupportedFilters = map[string]bool{
+	"started_at[gt]":    true,
+	"started_at[lt]":    true,
+	"started_at[since]": true,
+	"started_at[until]": true,
+}
+var JobStatsSupportedSorts = map[string]bool{
+	"client_id":        true,
+	"total":            true,
+	"successful":       true,
+	"failed":           true,
+	"success_rate":     true,
+	"avg_duration_sec": true,
+	"last_failure_at":  true,
+}
+
+// jobStatsInnerFilters are applied to the jobs before aggregating them.
+var jobStatsInnerFilters = map[string]bool{
+	"started_at": true,
+}
+
+// jobStatsNumericColumns are casted as the aggregated columns have no type affinity and filter values are strings.
+var jobStatsNumericColumns = map[string]string{
+	"total":        "INTEGER",
+	"failed":       "INTEGER",
+	"success_rate": "REAL",
+}
+
+// JobStats summarizes the job history of a client.
+type JobStats struct {
+	ClientID   string `json:"client_id" db:"client_id"`
+	Total      int    `json:"total" db:"total"`
+	Successful int    `json:"successful" db:"successful"`
+	Failed     int    `json:"failed" db:"failed"`
+	Running    int    `json:"running" db:"running"`
+	Unknown    int    `json:"unknown" db:"unknown"`
+	// SuccessRate is the share of successful jobs of all finished jobs, nil if no job has finished
+	SuccessRate    *float64    `json:"success_rate" db:"success_rate"`
+	AvgDurationSec *float64    `json:"avg_duration_sec" db:"avg_duration_sec"`
+	LastFailureAt  *time.Time  `json:"last_failure_at" db:"-"`
+	LastFailure    *models.Job `json:"last_failure,omitempty" db:"-"`
+}
+
+type jobStatsSqlite struct {
+	JobStats
+	// sqlite returns the result of MAX as string
+	LastFailureAt sql.NullString `db:"last_failure_at"`
+}
+
+const jobStatsQuery = `SELECT
+	client_id,
+	COUNT(*) AS total,
+	SUM(status = 'successful') AS successful,
+	SUM(status = 'failed') AS failed,
+	SUM(status = 'running') AS running,
+	SUM(status = 'unknown') AS unknown,
+	ROUND(CAST(SUM(status = 'successful') AS REAL) / NULLIF(SUM(status IN ('successful', 'failed')), 0), 4) AS success_rate,
+	ROUND(AVG(CASE WHEN finished_at IS NOT NULL THEN (julianday(finished_at) - julianday(started_at)) * 86400 END), 3) AS avg_duration_sec,
+	MAX(CASE WHEN status = 'failed' THEN finished_at END) AS last_failure_at
+FROM jobs`
+
+// ListJobStats returns the job stats per client.
+func (p *SqliteProvider) ListJobStats(ctx context.Context, options *query.ListOptions) ([]*JobStats, error) {
+	if len(options.Sorts) == 0 {
+		options.Sorts = []query.SortOption{
+			{
+				Column: "client_id",
+				IsASC:  true,
+			},
+		}
+	}
+
+	q, params := p.jobStatsQuery(options, "SELECT *")
+	q, params = p.converter.AppendOptionsToQuery(&query.ListOptions{Sorts: options.Sorts, Pagination: options.Pagination}, q, params)
+
+	var list []*jobStatsSqlite
+	err := p.reader.SelectContext(ctx, &list, q, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*JobStats, 0, len(list))
+	for _, cur := range list {
+		res = append(res, cur.convert())
+	}
+	return res, nil
+}
+
+func (p *SqliteProvider) CountJobStats(ctx context.Context, options *query.ListOptions) (int, error) {
+	q, params := p.jobStatsQuery(options, "SELECT COUNT(*)")
+
+	var result int
+	err := p.reader.GetContext(ctx, &result, q, params...)
+	if err != nil {
+		return 0, err
+	}
+	return result, nil
+}
+
+// GetJobStats returns the job stats of the given client including the last failed job, nil if the client has no jobs.
+func (p *SqliteProvider) GetJobStats(ctx context.Context, clientID string, filters []query.FilterOption) (*JobStats, error) {
+	options := &query.ListOptions{
+		Filters: append(filters, query.FilterOption{Column: []string{"client_id"}, Values: []string{clientID}}),
+	}
+	list, err := p.ListJobStats(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	stats := list[0]
+
+	if stats.Failed > 0 {
+		failed, err := p.List(ctx, &query.ListOptions{
+			Filters: []query.FilterOption{
+				{Column: []string{"client_id"}, Values: []string{clientID}},
+				{Column: []string{"status"}, Values: []string{models.JobStatusFailed}},
+			},
+			Sorts:      []query.SortOption{{Column: "finished_at", IsASC: false}},
+			Pagination: query.NewPagination(1, 0),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(failed) > 0 {
+			stats.LastFailure = failed[0]
+		}
+	}
+
+	return stats, nil
+}
+
+func (p *SqliteProvider) jobStatsQuery(options *query.ListOptions, selectClause string) (string, []interface{}) {
+	var inner, outer []query.FilterOption
+	for _, f := range options.Filters {
+		switch {
+		case len(f.Column) == 1 && jobStatsInnerFilters[f.Column[0]]:
+			inner = append(inner, f)
+		case len(f.Column) == 1 && jobStatsNumericColumns[f.Column[0]] != "":
+			f.Column = []string{fmt.Sprintf("CAST(%s AS %s)", f.Column[0], jobStatsNumericColumns[f.Column[0]])}
+			outer = append(outer, f)
+		default:
+			outer = append(outer, f)
+		}
+	}
+
+	q, params := p.converter.AddWhere(inner, jobStatsQuery, nil)
+	q = selectClause + " FROM (" + q + " GROUP BY client_id)"
+	// the outer where clause is built separately as the inner query might contain a where clause already
+	where, params := p.converter.AddWhere(outer, "", params)
+	return q + where, params
+}
+
+func (s *jobStatsSqlite) convert() *JobStats {
+	res := s.JobStats
+	if !s.LastFailureAt.Valid {
+		return &res
+	}
+	// same as the sqlite driver parses timestamp columns
+	value := strings.TrimSuffix(s.LastFailureAt.String, "Z")
+	for _, layout := range sqlite3.SQLiteTimestampFormats {
+		if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			res.LastFailureAt = &t
+			break
+		}
+	}
+	return &res
+}
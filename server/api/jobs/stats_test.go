@@ -0,0 +1,94 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/db/migration/jobs"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/test/jb"
+	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+func TestJobStats(t *testing.T) {
+	ctx := context.Background()
+	jobsDB, err := sqlite.New(":memory:", jobs.AssetNames(), jobs.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	p := NewSqliteProvider(jobsDB, testLog)
+	defer p.Close()
+
+	start := time.Date(2022, 10, 10, 10, 0, 0, 0, time.UTC)
+	lastFailure := start.Add(time.Hour + 4*time.Second)
+	jobsToSave := []*models.Job{
+		jb.New(t).ClientID("client-1").StartedAt(start).FinishedAt(start.Add(2 * time.Second)).Build(),
+		jb.New(t).ClientID("client-1").StartedAt(start).FinishedAt(start.Add(4 * time.Second)).Build(),
+		jb.New(t).ClientID("client-1").Status(models.JobStatusFailed).StartedAt(start).FinishedAt(start.Add(6 * time.Second)).Build(),
+		jb.New(t).ClientID("client-1").JID("last-failure").Status(models.JobStatusFailed).StartedAt(start.Add(time.Hour)).FinishedAt(lastFailure).Build(),
+		jb.New(t).ClientID("client-1").Status(models.JobStatusRunning).StartedAt(start.Add(time.Hour)).Build(),
+		jb.New(t).ClientID("client-2").StartedAt(start).FinishedAt(start.Add(time.Second)).Build(),
+		jb.New(t).ClientID("client-3").Status(models.JobStatusRunning).StartedAt(start).Build(),
+	}
+	for _, job := range jobsToSave {
+		require.NoError(t, p.SaveJob(job))
+	}
+
+	stats, err := p.GetJobStats(ctx, "client-1", nil)
+	require.NoError(t, err)
+	require.NotNil(t, stats)
+	assert.Equal(t, 5, stats.Total)
+	assert.Equal(t, 2, stats.Successful)
+	assert.Equal(t, 2, stats.Failed)
+	assert.Equal(t, 1, stats.Running)
+	assert.Equal(t, 0, stats.Unknown)
+	assert.Equal(t, 0.5, *stats.SuccessRate)
+	assert.Equal(t, 4.0, *stats.AvgDurationSec)
+	assert.Equal(t, lastFailure, *stats.LastFailureAt)
+	require.NotNil(t, stats.LastFailure)
+	assert.Equal(t, "last-failure", stats.LastFailure.JID)
+
+	// jobs started before are ignored
+	stats, err = p.GetJobStats(ctx, "client-1", []query.FilterOption{{
+		Column:   []string{"started_at"},
+		Operator: query.FilterOperatorTypeGT,
+		Values:   []string{start.Add(time.Minute).Format("2006-01-02 15:04:05")},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Total)
+	assert.Equal(t, 0.0, *stats.SuccessRate)
+
+	stats, err = p.GetJobStats(ctx, "unknown", nil)
+	require.NoError(t, err)
+	assert.Nil(t, stats)
+
+	options := &query.ListOptions{
+		Sorts: []query.SortOption{{Column: "success_rate", IsASC: true}},
+	}
+	list, err := p.ListJobStats(ctx, options)
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	// no finished jobs
+	assert.Equal(t, "client-3", list[0].ClientID)
+	assert.Nil(t, list[0].SuccessRate)
+	assert.Nil(t, list[0].AvgDurationSec)
+	assert.Equal(t, "client-1", list[1].ClientID)
+	assert.Equal(t, "client-2", list[2].ClientID)
+	assert.Equal(t, 1.0, *list[2].SuccessRate)
+	assert.Nil(t, list[2].LastFailureAt)
+
+	options = &query.ListOptions{
+		Filters: []query.FilterOption{{Column: []string{"success_rate"}, Operator: query.FilterOperatorTypeLT, Values: []string{"0.9"}}},
+	}
+	list, err = p.ListJobStats(ctx, options)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "client-1", list[0].ClientID)
+
+	count, err := p.CountJobStats(ctx, options)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,312 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+const (
+	// ErrorSimilarityThreshold is the min share of common words of two error signatures to cluster them.
+	ErrorSimilarityThreshold = 0.7
+	// MaxErrorClusters limits the error clusters of a summary, the least frequent errors are counted in OtherErrors.
+	MaxErrorClusters = 20
+	// MaxClusterClientIDs limits the client ids listed per error cluster.
+	MaxClusterClientIDs = 10
+
+	maxSignatureLength = 200
+)
+
+// ResultSummary aggregates the results of the jobs of a multi-client job, so the outputs of many clients don't need
+// to be checked one by one. The summary of a finished multi-client job is stored and not computed again.
+type ResultSummary struct {
+	MultiJobStatus
+	// DurationSec has the stats of the duration of the finished jobs, nil if no job has finished
+	DurationSec *DurationStats `json:"duration_sec"`
+	// ErrorClusters groups the failed jobs by similar errors, the most frequent first
+	ErrorClusters []*ErrorCluster `json:"error_clusters"`
+	// OtherErrors is the number of failed jobs not in the error clusters due to MaxErrorClusters
+	OtherErrors int       `json:"other_errors"`
+	ComputedAt  time.Time `json:"computed_at"`
+}
+
+// DurationStats summarizes the durations of jobs in seconds, percentiles are computed by the nearest-rank method.
+type DurationStats struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+	Avg float64 `json:"avg"`
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+}
+
+// ErrorCluster is a group of failed jobs with similar errors.
+type ErrorCluster struct {
+	// Signature is the normalized error of the cluster, numbers, ids and addresses are replaced by placeholders
+	Signature string `json:"signature"`
+	// Sample is the original error of one of the jobs
+	Sample    string   `json:"sample"`
+	Count     int      `json:"count"`
+	ClientIDs []string `json:"client_ids"`
+}
+
+// NewResultSummary returns the summary of the given jobs of the multi-client job.
+func NewResultSummary(multiJob *models.MultiJob, jobs []*models.Job, now time.Time) *ResultSummary {
+	counts := make(map[string]int)
+	var durations []float64
+	var failed []*models.Job
+	for _, job := range jobs {
+		counts[job.Status]++
+		if job.FinishedAt != nil {
+			durations = append(durations, job.FinishedAt.Sub(job.StartedAt).Seconds())
+		}
+		if job.Status == models.JobStatusFailed || job.Status == models.JobStatusUnknown {
+			failed = append(failed, job)
+		}
+	}
+
+	s := &ResultSummary{
+		MultiJobStatus: *NewMultiJobStatus(multiJob, counts),
+		DurationSec:    newDurationStats(durations),
+		ComputedAt:     now,
+	}
+	s.ErrorClusters, s.OtherErrors = clusterErrors(failed)
+	return s
+}
+
+func newDurationStats(durations []float64) *DurationStats {
+	if len(durations) == 0 {
+		return nil
+	}
+	sort.Float64s(durations)
+
+	var sum float64
+	for _, d := range durations {
+		sum += d
+	}
+	return &DurationStats{
+		Min: round(durations[0]),
+		Max: round(durations[len(durations)-1]),
+		Avg: round(sum / float64(len(durations))),
+		P50: round(percentile(durations, 50)),
+		P90: round(percentile(durations, 90)),
+		P95: round(percentile(durations, 95)),
+		P99: round(percentile(durations, 99)),
+	}
+}
+
+// percentile returns the nearest-rank percentile of the sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func round(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
+
+// clusterErrors groups the jobs by their error signatures, signatures sharing at least ErrorSimilarityThreshold of
+// their words are merged into the cluster of the more frequent one.
+func clusterErrors(failed []*models.Job) ([]*ErrorCluster, int) {
+	bySignature := make(map[string]*ErrorCluster)
+	for _, job := range failed {
+		raw := errorText(job)
+		sig := errorSignature(raw)
+		c, ok := bySignature[sig]
+		if !ok {
+			c = &ErrorCluster{Signature: sig, Sample: raw}
+			bySignature[sig] = c
+		}
+		c.add(job.ClientID, 1)
+	}
+
+	exact := make([]*ErrorCluster, 0, len(bySignature))
+	for _, c := range bySignature {
+		exact = append(exact, c)
+	}
+	sortClusters(exact)
+
+	var clusters []*ErrorCluster
+	var words [][]string
+loop:
+	for _, c := range exact {
+		cWords := strings.Fields(c.Signature)
+		for i, existing := range clusters {
+			if similarity(words[i], cWords) >= ErrorSimilarityThreshold {
+				existing.Count += c.Count
+				for _, id := range c.ClientIDs {
+					existing.add(id, 0)
+				}
+				continue loop
+			}
+		}
+		clusters = append(clusters, c)
+		words = append(words, cWords)
+	}
+	sortClusters(clusters)
+
+	other := 0
+	if len(clusters) > MaxErrorClusters {
+		for _, c := range clusters[MaxErrorClusters:] {
+			other += c.Count
+		}
+		clusters = clusters[:MaxErrorClusters]
+	}
+	if clusters == nil {
+		clusters = []*ErrorCluster{}
+	}
+	return clusters, other
+}
+
+func (c *ErrorCluster) add(clientID string, count int) {
+	c.Count += count
+	if len(c.ClientIDs) < MaxClusterClientIDs {
+		c.ClientIDs = append(c.ClientIDs, clientID)
+	}
+}
+
+func sortClusters(clusters []*ErrorCluster) {
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if clusters[i].Count != clusters[j].Count {
+			return clusters[i].Count > clusters[j].Count
+		}
+		return clusters[i].Signature < clusters[j].Signature
+	})
+}
+
+// errorText returns the first line of the error of the job, of its stderr if the job has no error.
+func errorText(job *models.Job) string {
+	text := job.Error
+	if strings.TrimSpace(text) == "" && job.Result != nil {
+		text = job.Result.StdErr
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			if len(line) > maxSignatureLength {
+				line = line[:maxSignatureLength]
+			}
+			return line
+		}
+	}
+	if job.Status == models.JobStatusUnknown {
+		return "unknown result"
+	}
+	return "no error output"
+}
+
+var signatureReplacements = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{re: regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`), repl: "<uuid>"},
+	{re: regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}(:\d+)?\b`), repl: "<ip>"},
+	{re: regexp.MustCompile(`\b0x[0-9a-fA-F]+\b`), repl: "<hex>"},
+	// hashes and ids mixing digits and hex letters
+	{re: regexp.MustCompile(`\b[0-9a-fA-F]*(\d[0-9a-fA-F]*[a-fA-F]|[a-fA-F][0-9a-fA-F]*\d)[0-9a-fA-F]*\b`), repl: "<hex>"},
+	{re: regexp.MustCompile(`\d+`), repl: "<n>"},
+	{re: regexp.MustCompile(`\s+`), repl: " "},
+}
+
+// errorSignature normalizes the error, so errors differing in numbers, ids or addresses only have the same signature.
+func errorSignature(text string) string {
+	for _, r := range signatureReplacements {
+		text = r.re.ReplaceAllString(text, r.repl)
+	}
+	return strings.TrimSpace(text)
+}
+
+// similarity returns the Jaccard index of the sets of words.
+func similarity(a, b []string) float64 {
+	set := make(map[string]bool, len(a))
+	for _, w := range a {
+		set[w] = true
+	}
+	union := len(set)
+	common := 0
+	seen := make(map[string]bool, len(b))
+	for _, w := range b {
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		if set[w] {
+			common++
+		} else {
+			union++
+		}
+	}
+	if union == 0 {
+		return 1
+	}
+	return float64(common) / float64(union)
+}
+
+func (s *ResultSummary) Scan(value interface{}) error {
+	if s == nil {
+		return errors.New("'details' cannot be nil")
+	}
+	valueStr, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("expected to have string, got %T", value)
+	}
+	err := json.Unmarshal([]byte(valueStr), s)
+	if err != nil {
+		return fmt.Errorf("failed to decode 'details' field: %v", err)
+	}
+	return nil
+}
+
+func (s *ResultSummary) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, errors.New("'details' cannot be nil")
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode 'details' field: %v", err)
+	}
+	return string(b), nil
+}
+
+// ListMultiJobResults returns all jobs of the multi-client job with their results.
+func (p *SqliteProvider) ListMultiJobResults(ctx context.Context, multiJobID string) ([]*models.Job, error) {
+	return p.List(ctx, &query.ListOptions{
+		Filters: []query.FilterOption{
+			{Column: []string{"multi_job_id"}, Values: []string{multiJobID}},
+		},
+	})
+}
+
+// GetResultSummary returns the stored summary of the multi-client job, nil if it's not stored.
+func (p *SqliteProvider) GetResultSummary(ctx context.Context, multiJobID string) (*ResultSummary, error) {
+	res := &ResultSummary{}
+	err := p.reader.GetContext(ctx, res, "SELECT details FROM multi_job_summaries WHERE multi_job_id = ?", multiJobID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+// SaveResultSummary stores the summary of a finished multi-client job.
+func (p *SqliteProvider) SaveResultSummary(ctx context.Context, s *ResultSummary) error {
+	_, err := p.db.ExecContext(ctx, "INSERT OR REPLACE INTO multi_job_summaries (multi_job_id, created_at, details) VALUES (?, ?, ?)", s.JID, s.ComputedAt, s)
+	return err
+}
@@ -0,0 +1,193 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/db/migration/jobs"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/test/jb"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func TestErrorSignature(t *testing.T) {
+	testCases := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "dial tcp 10.0.0.5:443: connect: connection refused",
+			want: "dial tcp <ip>: connect: connection refused",
+		},
+		{
+			text: "E: Could not get lock /var/lib/dpkg/lock-frontend. It is held by process 1234 (apt)",
+			want: "E: Could not get lock /var/lib/dpkg/lock-frontend. It is held by process <n> (apt)",
+		},
+		{
+			text: "container 3f2a9c1b0d not found,   request 6f1c2a3e-8b4d-4f5a-9c6b-7d8e9f0a1b2c",
+			want: "container <hex> not found, request <uuid>",
+		},
+		{
+			text: "segfault at 0x7ffd5e8c",
+			want: "segfault at <hex>",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			assert.Equal(t, tc.want, errorSignature(tc.text))
+		})
+	}
+}
+
+func TestNewResultSummary(t *testing.T) {
+	start := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	newJob := func(clientID, status string, durationSec int, errText, stderr string) *models.Job {
+		job := jb.New(t).ClientID(clientID).Status(status).StartedAt(start).Result(&models.JobResult{StdErr: stderr}).Build()
+		job.FinishedAt = nil
+		if durationSec >= 0 {
+			finished := start.Add(time.Duration(durationSec) * time.Second)
+			job.FinishedAt = &finished
+		}
+		job.Error = errText
+		return job
+	}
+
+	jobList := []*models.Job{
+		newJob("client-1", models.JobStatusSuccessful, 1, "", ""),
+		newJob("client-2", models.JobStatusSuccessful, 2, "", ""),
+		newJob("client-3", models.JobStatusFailed, 3, "", "dial tcp 10.0.0.5:443: connect: connection refused\nexit status 1"),
+		newJob("client-4", models.JobStatusFailed, 4, "", "dial tcp 10.0.0.6:443: connect: connection refused"),
+		newJob("client-5", models.JobStatusFailed, 5, "", "dial tcp 10.0.0.7:443: connect: connection timed out"),
+		newJob("client-6", models.JobStatusFailed, 10, "", "permission denied"),
+		newJob("client-7", models.JobStatusUnknown, 6, "", ""),
+		newJob("client-8", models.JobStatusRunning, -1, "", ""),
+	}
+
+	got := NewResultSummary(&models.MultiJob{MultiJobSummary: models.MultiJobSummary{JID: "multi-1"}, ClientCount: 8}, jobList, start)
+
+	assert.Equal(t, MultiJobStatus{JID: "multi-1", ClientCount: 8, Total: 8, Successful: 2, Failed: 4, Running: 1, Unknown: 1}, got.MultiJobStatus)
+	assert.Equal(t, &DurationStats{Min: 1, Max: 10, Avg: 4.429, P50: 4, P90: 10, P95: 10, P99: 10}, got.DurationSec)
+	assert.Equal(t, []*ErrorCluster{
+		{
+			Signature: "dial tcp <ip>: connect: connection refused",
+			Sample:    "dial tcp 10.0.0.5:443: connect: connection refused",
+			Count:     2,
+			ClientIDs: []string{"client-3", "client-4"},
+		},
+		{
+			Signature: "dial tcp <ip>: connect: connection timed out",
+			Sample:    "dial tcp 10.0.0.7:443: connect: connection timed out",
+			Count:     1,
+			ClientIDs: []string{"client-5"},
+		},
+		{
+			Signature: "permission denied",
+			Sample:    "permission denied",
+			Count:     1,
+			ClientIDs: []string{"client-6"},
+		},
+		{
+			Signature: "unknown result",
+			Sample:    "unknown result",
+			Count:     1,
+			ClientIDs: []string{"client-7"},
+		},
+	}, got.ErrorClusters)
+	assert.Equal(t, 0, got.OtherErrors)
+	assert.Equal(t, start, got.ComputedAt)
+}
+
+func TestClusterSimilarErrors(t *testing.T) {
+	var failed []*models.Job
+	for i, errText := range []string{
+		"E: Unable to locate package nginx-full",
+		"E: Unable to locate package nginx-full",
+		"E: Unable to locate package nginx-extras",
+		"E: Unable to fetch some archives",
+	} {
+		job := jb.New(t).ClientID(fmt.Sprintf("client-%d", i)).Status(models.JobStatusFailed).Build()
+		job.Error = errText
+		failed = append(failed, job)
+	}
+
+	got, other := clusterErrors(failed)
+
+	require.Len(t, got, 2)
+	assert.Equal(t, "E: Unable to locate package nginx-full", got[0].Signature)
+	assert.Equal(t, 3, got[0].Count)
+	assert.ElementsMatch(t, []string{"client-0", "client-1", "client-2"}, got[0].ClientIDs)
+	assert.Equal(t, "E: Unable to fetch some archives", got[1].Signature)
+	assert.Equal(t, 1, got[1].Count)
+	assert.Equal(t, 0, other)
+}
+
+func TestNewResultSummaryLimits(t *testing.T) {
+	var jobList []*models.Job
+	for i := 0; i < MaxErrorClusters+2; i++ {
+		job := jb.New(t).ClientID(fmt.Sprintf("client-%d", i)).Status(models.JobStatusFailed).Build()
+		job.Error = fmt.Sprintf("error %c", 'a'+i)
+		jobList = append(jobList, job)
+	}
+	for i := 0; i < MaxClusterClientIDs+5; i++ {
+		job := jb.New(t).ClientID(fmt.Sprintf("other-%d", i)).Status(models.JobStatusFailed).Build()
+		job.Error = "disk full"
+		jobList = append(jobList, job)
+	}
+
+	got := NewResultSummary(&models.MultiJob{}, jobList, time.Now())
+
+	require.Len(t, got.ErrorClusters, MaxErrorClusters)
+	assert.Equal(t, "disk full", got.ErrorClusters[0].Signature)
+	assert.Equal(t, MaxClusterClientIDs+5, got.ErrorClusters[0].Count)
+	assert.Len(t, got.ErrorClusters[0].ClientIDs, MaxClusterClientIDs)
+	assert.Equal(t, 3, got.OtherErrors)
+}
+
+func TestNewResultSummaryNoJobs(t *testing.T) {
+	got := NewResultSummary(&models.MultiJob{ClientCount: 2}, nil, time.Now())
+
+	assert.Nil(t, got.DurationSec)
+	assert.Equal(t, []*ErrorCluster{}, got.ErrorClusters)
+	assert.Equal(t, 2, got.Pending)
+	assert.False(t, got.Finished)
+}
+
+func TestResultSummaryStorage(t *testing.T) {
+	ctx := context.Background()
+	jobsDB, err := sqlite.New(":memory:", jobs.AssetNames(), jobs.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	p := NewSqliteProvider(jobsDB, testLog)
+	defer p.Close()
+
+	multiJob := jb.NewMulti(t).JID("multi-1").ClientIDs("client-1", "client-2").WithJobs().Build()
+	require.NoError(t, p.SaveMultiJob(multiJob))
+	for _, job := range multiJob.Jobs {
+		require.NoError(t, p.SaveJob(job))
+	}
+
+	results, err := p.ListMultiJobResults(ctx, "multi-1")
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	got, err := p.GetResultSummary(ctx, "multi-1")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	summary := NewResultSummary(multiJob, results, time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC))
+	require.NoError(t, p.SaveResultSummary(ctx, summary))
+
+	got, err = p.GetResultSummary(ctx, "multi-1")
+	require.NoError(t, err)
+	assert.Equal(t, summary, got)
+
+	_, err = p.DeleteJobsBefore(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	got, err = p.GetResultSummary(ctx, "multi-1")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
@@ -0,0 +1,87 @@
+package message
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MultiService delivers messages via one of several services. Users select a service by prefixing the receiver
+// with its delivery method, e.g. "sms:+4915112345678". Receivers without a known prefix use the default service.
+type MultiService struct {
+	defaultService Service
+	services       map[string]Service
+}
+
+func NewMultiService(defaultService Service, options ...Service) (*MultiService, error) {
+	s := &MultiService{
+		defaultService: defaultService,
+		services: map[string]Service{
+			defaultService.DeliveryMethod(): defaultService,
+		},
+	}
+	for _, option := range options {
+		method := option.DeliveryMethod()
+		if _, ok := s.services[method]; ok {
+			return nil, fmt.Errorf("2fa delivery method %q is configured more than once", method)
+		}
+		s.services[method] = option
+	}
+	return s, nil
+}
+
+// resolve returns the service selected by the receiver and the receiver without the delivery method prefix.
+func (s *MultiService) resolve(sendTo string) (Service, string) {
+	if method, receiver, ok := strings.Cut(sendTo, ":"); ok {
+		if service, found := s.services[method]; found {
+			return service, receiver
+		}
+	}
+	return s.defaultService, sendTo
+}
+
+func (s *MultiService) Send(ctx context.Context, data Data) error {
+	service, receiver := s.resolve(data.SendTo)
+	data.SendTo = receiver
+	return service.Send(ctx, data)
+}
+
+// DeliveryMethod returns the delivery method of the default service.
+func (s *MultiService) DeliveryMethod() string {
+	return s.defaultService.DeliveryMethod()
+}
+
+func (s *MultiService) ValidateReceiver(ctx context.Context, receiver string) error {
+	service, receiver := s.resolve(receiver)
+	return service.ValidateReceiver(ctx, receiver)
+}
+
+// DeliveryMethods returns the delivery methods users can select from.
+func (s *MultiService) DeliveryMethods() []string {
+	var options []string
+	for method := range s.services {
+		if method != s.defaultService.DeliveryMethod() {
+			options = append(options, method)
+		}
+	}
+	sort.Strings(options)
+	return append([]string{s.defaultService.DeliveryMethod()}, options...)
+}
+
+// DeliveryMethods returns the delivery methods users can select from.
+func DeliveryMethods(s Service) []string {
+	if ms, ok := s.(*MultiService); ok {
+		return ms.DeliveryMethods()
+	}
+	return []string{s.DeliveryMethod()}
+}
+
+// DeliveryMethodFor returns the delivery method used by the service to send messages to the given receiver.
+func DeliveryMethodFor(s Service, sendTo string) string {
+	if ms, ok := s.(*MultiService); ok {
+		service, _ := ms.resolve(sendTo)
+		return service.DeliveryMethod()
+	}
+	return s.DeliveryMethod()
+}
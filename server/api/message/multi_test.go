@@ -0,0 +1,73 @@
+package message_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/api/message"
+)
+
+type sendRecorder struct {
+	method string
+	sent   []string
+}
+
+func (s *sendRecorder) Send(ctx context.Context, data message.Data) error {
+	s.sent = append(s.sent, data.SendTo)
+	return nil
+}
+
+func (s *sendRecorder) DeliveryMethod() string {
+	return s.method
+}
+
+func (s *sendRecorder) ValidateReceiver(ctx context.Context, receiver string) error {
+	return nil
+}
+
+func TestMultiService(t *testing.T) {
+	email := &sendRecorder{method: "email"}
+	sms := &sendRecorder{method: "sms"}
+	script := &sendRecorder{method: "script"}
+	service, err := message.NewMultiService(email, sms, script)
+	require.NoError(t, err)
+
+	for _, sendTo := range []string{"test@example.com", "sms:+4915112345678", "script:chat-id-1", "unknown:value", "email:other@example.com"} {
+		require.NoError(t, service.Send(context.Background(), message.Data{SendTo: sendTo}))
+	}
+
+	assert.Equal(t, []string{"test@example.com", "unknown:value", "other@example.com"}, email.sent)
+	assert.Equal(t, []string{"+4915112345678"}, sms.sent)
+	assert.Equal(t, []string{"chat-id-1"}, script.sent)
+	assert.Equal(t, "email", service.DeliveryMethod())
+	assert.Equal(t, []string{"email", "script", "sms"}, message.DeliveryMethods(service))
+	assert.Equal(t, []string{"sms"}, message.DeliveryMethods(sms))
+	assert.Equal(t, "sms", message.DeliveryMethodFor(service, "sms:+4915112345678"))
+	assert.Equal(t, "email", message.DeliveryMethodFor(service, "test@example.com"))
+	assert.Equal(t, "sms", message.DeliveryMethodFor(sms, "test@example.com"))
+}
+
+func TestMultiServiceValidateReceiver(t *testing.T) {
+	url := message.NewURLService("https://2fa.example.com", "https://test.example.com", nil)
+	sms := message.NewTwilioService(message.TwilioDefaultAPIURL, "AC123", "secret", "+15005550006")
+	script := message.NewScriptService("/bin/sh", message.ValidationRegex, regexp.MustCompile(`^[0-9]+$`))
+	service, err := message.NewMultiService(url, sms, script)
+	require.NoError(t, err)
+
+	assert.NoError(t, service.ValidateReceiver(context.Background(), "test@example.com"))
+	assert.NoError(t, service.ValidateReceiver(context.Background(), "sms:+4915112345678"))
+	assert.NoError(t, service.ValidateReceiver(context.Background(), "script:12345"))
+	assert.Error(t, service.ValidateReceiver(context.Background(), "sms:test@example.com"))
+	assert.Error(t, service.ValidateReceiver(context.Background(), "script:abc"))
+	assert.Error(t, service.ValidateReceiver(context.Background(), "+4915112345678"))
+}
+
+func TestNewMultiServiceDuplicatedMethod(t *testing.T) {
+	_, err := message.NewMultiService(&sendRecorder{method: "sms"}, &sendRecorder{method: "email"}, &sendRecorder{method: "sms"})
+
+	assert.EqualError(t, err, `2fa delivery method "sms" is configured more than once`)
+}
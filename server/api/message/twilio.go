@@ -0,0 +1,93 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	errors2 "github.com/realvnc-labs/rport/server/api/errors"
+)
+
+const TwilioDefaultAPIURL = "https://api.twilio.com/2010-04-01"
+
+var phoneNumberRegex = regexp.MustCompile(`^\+[1-9]\d{6,14}$`)
+
+// TwilioService sends messages as SMS using the Twilio messaging API or any API compatible with it.
+type TwilioService struct {
+	apiURL     string
+	accountSID string
+	authToken  string
+	from       string
+}
+
+func NewTwilioService(apiURL, accountSID, authToken, from string) *TwilioService {
+	return &TwilioService{
+		apiURL:     strings.TrimSuffix(apiURL, "/"),
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+	}
+}
+
+type twilioErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (s *TwilioService) Send(ctx context.Context, data Data) error {
+	values := url.Values{}
+	values.Set("To", data.SendTo)
+	values.Set("From", s.from)
+	values.Set("Body", fmt.Sprintf("Your RPort 2fa token: %s, requested from %s, valid for %.0f seconds.", data.Token, data.RemoteAddress, data.TTL.Seconds()))
+
+	messagesURL := fmt.Sprintf("%s/Accounts/%s/Messages.json", s.apiURL, url.PathEscape(s.accountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, messagesURL, strings.NewReader(values.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.accountSID, s.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	if resp.StatusCode >= 500 {
+		return errors2.APIError{
+			Message:    "sms service unavailable",
+			HTTPStatus: http.StatusServiceUnavailable,
+		}
+	}
+
+	errResp := twilioErrorResponse{}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err := json.Unmarshal(body, &errResp); err != nil || errResp.Message == "" {
+		return fmt.Errorf("failed to send sms: %s", resp.Status)
+	}
+	return errors2.APIError{
+		Message:    fmt.Sprintf("failed to send sms: %s (code %d)", errResp.Message, errResp.Code),
+		HTTPStatus: http.StatusBadRequest,
+	}
+}
+
+func (s *TwilioService) DeliveryMethod() string {
+	return "sms"
+}
+
+// ValidateReceiver accepts phone numbers in E.164 format only, e.g. +4915112345678.
+func (s *TwilioService) ValidateReceiver(ctx context.Context, phoneNumber string) error {
+	if !phoneNumberRegex.MatchString(phoneNumber) {
+		return fmt.Errorf("%q is not a phone number in E.164 format, e.g. +4915112345678", phoneNumber)
+	}
+	return nil
+}
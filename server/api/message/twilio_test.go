@@ -0,0 +1,102 @@
+package message_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errors2 "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/api/message"
+)
+
+func TestTwilioService(t *testing.T) {
+	var gotPath, gotUser, gotPassword string
+	var form url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		gotPath = r.URL.Path
+		gotUser, gotPassword, _ = r.BasicAuth()
+		form = r.PostForm
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+	service := message.NewTwilioService(ts.URL+"/", "AC123", "secret", "+15005550006")
+
+	err := service.Send(context.Background(), message.Data{
+		SendTo:        "+4915112345678",
+		Token:         "123456",
+		RemoteAddress: "192.0.2.1",
+		TTL:           10 * time.Minute,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/Accounts/AC123/Messages.json", gotPath)
+	assert.Equal(t, "AC123", gotUser)
+	assert.Equal(t, "secret", gotPassword)
+	assert.Equal(t, "+4915112345678", form.Get("To"))
+	assert.Equal(t, "+15005550006", form.Get("From"))
+	assert.Equal(t, "Your RPort 2fa token: 123456, requested from 192.0.2.1, valid for 600 seconds.", form.Get("Body"))
+}
+
+func TestTwilioServiceErrors(t *testing.T) {
+	testCases := []struct {
+		name           string
+		status         int
+		body           string
+		expectedError  string
+		expectedStatus int
+	}{
+		{
+			name:           "invalid receiver",
+			status:         http.StatusBadRequest,
+			body:           `{"code": 21211, "message": "The 'To' number is not a valid phone number.", "status": 400}`,
+			expectedError:  "failed to send sms: The 'To' number is not a valid phone number. (code 21211)",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unavailable",
+			status:         http.StatusBadGateway,
+			expectedError:  "sms service unavailable",
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:          "no details",
+			status:        http.StatusUnauthorized,
+			expectedError: "failed to send sms: 401 Unauthorized",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer ts.Close()
+			service := message.NewTwilioService(ts.URL, "AC123", "secret", "+15005550006")
+
+			err := service.Send(context.Background(), message.Data{SendTo: "+4915112345678"})
+			require.Error(t, err)
+			assert.Equal(t, tc.expectedError, err.Error())
+			if tc.expectedStatus != 0 {
+				apiErr, ok := err.(errors2.APIError)
+				require.True(t, ok)
+				assert.Equal(t, tc.expectedStatus, apiErr.HTTPStatus)
+			}
+		})
+	}
+}
+
+func TestTwilioServiceValidateReceiver(t *testing.T) {
+	service := message.NewTwilioService(message.TwilioDefaultAPIURL, "AC123", "secret", "+15005550006")
+
+	assert.NoError(t, service.ValidateReceiver(context.Background(), "+4915112345678"))
+	assert.Error(t, service.ValidateReceiver(context.Background(), "015112345678"))
+	assert.Error(t, service.ValidateReceiver(context.Background(), "test@example.com"))
+}
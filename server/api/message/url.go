@@ -3,8 +3,10 @@ package message
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -14,18 +16,22 @@ import (
 type URLService struct {
 	senderURL string
 	baseURL   string
+	// regex validates receivers if set, otherwise receivers must be emails
+	regex *regexp.Regexp
 }
 
-func NewURLService(senderURL, baseURL string) *URLService {
+func NewURLService(senderURL, baseURL string, regex *regexp.Regexp) *URLService {
 	return &URLService{
 		senderURL: senderURL,
 		baseURL:   baseURL,
+		regex:     regex,
 	}
 }
 
 func (s *URLService) Send(ctx context.Context, data Data) error {
 	values := url.Values{}
 	values.Set("email", data.SendTo)
+	values.Set("send_to", data.SendTo)
 	values.Set("token", data.Token)
 	values.Set("ttl", strconv.Itoa(int(data.TTL.Seconds())))
 	values.Set("user_agent", data.UserAgent)
@@ -51,6 +57,12 @@ func (s *URLService) DeliveryMethod() string {
 	return "url"
 }
 
-func (s *URLService) ValidateReceiver(ctx context.Context, email string) error {
-	return email2.Validate(email)
+func (s *URLService) ValidateReceiver(ctx context.Context, receiver string) error {
+	if s.regex != nil {
+		if !s.regex.MatchString(receiver) {
+			return fmt.Errorf("does not match %q", s.regex)
+		}
+		return nil
+	}
+	return email2.Validate(receiver)
 }
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"regexp"
 	"testing"
 	"time"
 
@@ -23,7 +24,7 @@ func TestURLService(t *testing.T) {
 		form = r.PostForm
 	}))
 	defer ts.Close()
-	service := message.NewURLService(ts.URL, "https://test.example.com")
+	service := message.NewURLService(ts.URL, "https://test.example.com", nil)
 
 	err := service.Send(context.Background(), message.Data{
 		SendTo:        "test@example.com",
@@ -35,9 +36,20 @@ func TestURLService(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, "test@example.com", form.Get("email"))
+	assert.Equal(t, "test@example.com", form.Get("send_to"))
 	assert.Equal(t, "test-token", form.Get("token"))
 	assert.Equal(t, "600", form.Get("ttl"))
 	assert.Equal(t, "test-user-agent", form.Get("user_agent"))
 	assert.Equal(t, "test-remote-address", form.Get("remote_address"))
 	assert.Equal(t, "https://test.example.com", form.Get("url"))
 }
+
+func TestURLServiceValidateReceiver(t *testing.T) {
+	service := message.NewURLService("https://2fa.example.com", "https://test.example.com", nil)
+	assert.NoError(t, service.ValidateReceiver(context.Background(), "test@example.com"))
+	assert.Error(t, service.ValidateReceiver(context.Background(), "+4915112345678"))
+
+	service = message.NewURLService("https://2fa.example.com", "https://test.example.com", regexp.MustCompile(`^\+\d+$`))
+	assert.NoError(t, service.ValidateReceiver(context.Background(), "+4915112345678"))
+	assert.Error(t, service.ValidateReceiver(context.Background(), "test@example.com"))
+}
@@ -0,0 +1,270 @@
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/flate"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	EncodingZstd    = "zstd"
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+
+	// zstdWindowSize is kept low enough to be decoded by browsers, that limit the window to 8MB.
+	zstdWindowSize = 4 << 20
+)
+
+var encoderPools = map[string]*sync.Pool{
+	EncodingZstd: {
+		New: func() interface{} {
+			enc, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1), zstd.WithWindowSize(zstdWindowSize))
+			return enc
+		},
+	},
+	EncodingGzip: {
+		New: func() interface{} {
+			return gzip.NewWriter(nil)
+		},
+	},
+	EncodingDeflate: {
+		New: func() interface{} {
+			enc, _ := flate.NewWriter(nil, flate.DefaultCompression)
+			return enc
+		},
+	},
+}
+
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// ValidateEncodings returns an error if any of the given encodings is not supported by Compress.
+func ValidateEncodings(encodings []string) error {
+	for _, e := range encodings {
+		if _, ok := encoderPools[e]; !ok {
+			return fmt.Errorf("unsupported compression %q, supported: %q, %q, %q", e, EncodingZstd, EncodingGzip, EncodingDeflate)
+		}
+	}
+	return nil
+}
+
+// Compress returns a middleware that compresses responses with the first of the given encodings accepted by the client.
+// Responses smaller than minSize bytes are sent uncompressed, since compressing them doesn't pay off.
+func Compress(encodings []string, minSize int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if len(encodings) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), encodings)
+			if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Upgrade") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressResponseWriter{
+				ResponseWriter: w,
+				encoding:       encoding,
+				minSize:        minSize,
+			}
+			defer cw.close()
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding returns the first of the supported encodings accepted by the Accept-Encoding header value,
+// or an empty string if none is accepted.
+func negotiateEncoding(acceptEncoding string, supported []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		accepted[name] = parseQValue(params) > 0
+	}
+
+	for _, e := range supported {
+		allowed, ok := accepted[e]
+		if !ok {
+			allowed = accepted["*"]
+		}
+		if allowed {
+			return e
+		}
+	}
+	return ""
+}
+
+func parseQValue(params string) float64 {
+	for _, param := range strings.Split(params, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || strings.TrimSpace(key) != "q" {
+			continue
+		}
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return 0
+		}
+		return q
+	}
+	return 1
+}
+
+// compressResponseWriter buffers the response until minSize is reached to decide whether it should be compressed.
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+
+	status   int
+	buf      []byte
+	decided  bool
+	enc      encoder
+	hijacked bool
+}
+
+func (w *compressResponseWriter) WriteHeader(status int) {
+	if w.status != 0 || w.decided {
+		return
+	}
+	w.status = status
+}
+
+func (w *compressResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	if !w.decided {
+		if !w.compressible() {
+			if err := w.decide(false); err != nil {
+				return 0, err
+			}
+		} else {
+			w.buf = append(w.buf, b...)
+			if len(w.buf) < w.minSize {
+				return len(b), nil
+			}
+			if err := w.decide(true); err != nil {
+				return 0, err
+			}
+			return len(b), nil
+		}
+	}
+
+	if w.enc != nil {
+		return w.enc.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// compressible returns false for responses that have no body, are already encoded or not worth compressing.
+func (w *compressResponseWriter) compressible() bool {
+	switch w.status {
+	case http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent:
+		return false
+	}
+
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" {
+		return false
+	}
+
+	contentType := h.Get("Content-Type")
+	for _, prefix := range []string{"image/", "video/", "audio/", "application/zip", "application/gzip", "application/zstd"} {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *compressResponseWriter) decide(compress bool) error {
+	w.decided = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	if compress {
+		h := w.Header()
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", w.encoding)
+		w.ResponseWriter.WriteHeader(w.status)
+
+		w.enc = encoderPools[w.encoding].Get().(encoder)
+		w.enc.Reset(w.ResponseWriter)
+	} else {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.enc != nil {
+		_, err = w.enc.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// Flush sends the buffered data to the client. A response flushed before reaching minSize is considered to be
+// streamed, so it gets compressed.
+func (w *compressResponseWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(w.compressible())
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *compressResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not implement http.Hijacker")
+	}
+	w.hijacked = true
+	return h.Hijack()
+}
+
+func (w *compressResponseWriter) close() {
+	if w.hijacked {
+		return
+	}
+	if !w.decided && w.status != 0 {
+		_ = w.decide(false)
+	}
+	if w.enc != nil {
+		_ = w.enc.Close()
+		w.enc.Reset(nil)
+		encoderPools[w.encoding].Put(w.enc)
+		w.enc = nil
+	}
+}
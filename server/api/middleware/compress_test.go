@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	supported := []string{EncodingZstd, EncodingGzip}
+
+	testCases := []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{acceptEncoding: "", want: ""},
+		{acceptEncoding: "gzip", want: EncodingGzip},
+		{acceptEncoding: "gzip, deflate, br, zstd", want: EncodingZstd},
+		{acceptEncoding: "zstd;q=0, gzip;q=0.5", want: EncodingGzip},
+		{acceptEncoding: "GZIP", want: EncodingGzip},
+		{acceptEncoding: "br", want: ""},
+		{acceptEncoding: "*", want: EncodingZstd},
+		{acceptEncoding: "zstd;q=0, *", want: EncodingGzip},
+		{acceptEncoding: "identity", want: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.acceptEncoding, func(t *testing.T) {
+			assert.Equal(t, tc.want, negotiateEncoding(tc.acceptEncoding, supported))
+		})
+	}
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat(`{"id":"client","name":"my client"},`, 100)
+	small := `{"id":"client"}`
+
+	testCases := []struct {
+		name           string
+		encodings      []string
+		acceptEncoding string
+		method         string
+		contentType    string
+		status         int
+		body           string
+		wantEncoding   string
+	}{
+		{
+			name:           "gzip",
+			encodings:      []string{EncodingZstd, EncodingGzip},
+			acceptEncoding: "gzip",
+			body:           large,
+			wantEncoding:   EncodingGzip,
+		},
+		{
+			name:           "zstd",
+			encodings:      []string{EncodingZstd, EncodingGzip},
+			acceptEncoding: "gzip, zstd",
+			body:           large,
+			wantEncoding:   EncodingZstd,
+		},
+		{
+			name:           "small response",
+			encodings:      []string{EncodingGzip},
+			acceptEncoding: "gzip",
+			body:           small,
+		},
+		{
+			name:           "not accepted",
+			encodings:      []string{EncodingZstd},
+			acceptEncoding: "gzip",
+			body:           large,
+		},
+		{
+			name:           "compression disabled",
+			acceptEncoding: "gzip",
+			body:           large,
+		},
+		{
+			name:           "already compressed content",
+			encodings:      []string{EncodingGzip},
+			acceptEncoding: "gzip",
+			contentType:    "image/png",
+			body:           large,
+		},
+		{
+			name:           "error status",
+			encodings:      []string{EncodingGzip},
+			acceptEncoding: "gzip",
+			status:         http.StatusBadRequest,
+			body:           large,
+			wantEncoding:   EncodingGzip,
+		},
+		{
+			name:           "head request",
+			encodings:      []string{EncodingGzip},
+			acceptEncoding: "gzip",
+			method:         http.MethodHead,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status := tc.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			handler := Compress(tc.encodings, 1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.contentType != "" {
+					w.Header().Set("Content-Type", tc.contentType)
+				}
+				w.WriteHeader(status)
+				// write in chunks to make sure the response is buffered until the min size is reached
+				for i := 0; i < len(tc.body); i += 100 {
+					end := i + 100
+					if end > len(tc.body) {
+						end = len(tc.body)
+					}
+					_, _ = w.Write([]byte(tc.body[i:end]))
+				}
+			}))
+
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, "/clients", nil)
+			req.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, status, w.Code)
+			assert.Equal(t, tc.wantEncoding, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, tc.body, decode(t, tc.wantEncoding, w.Body.Bytes()))
+		})
+	}
+}
+
+func TestCompressFlush(t *testing.T) {
+	handler := Compress([]string{EncodingGzip}, 1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("first"))
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte("second"))
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.True(t, w.Flushed)
+	assert.Equal(t, EncodingGzip, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "firstsecond", decode(t, EncodingGzip, w.Body.Bytes()))
+}
+
+func decode(t *testing.T, encoding string, body []byte) string {
+	var r io.Reader
+	switch encoding {
+	case "":
+		return string(body)
+	case EncodingGzip:
+		gr, err := gzip.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		r = gr
+	case EncodingZstd:
+		zr, err := zstd.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		defer zr.Close()
+		r = zr
+	}
+	decoded, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(decoded)
+}
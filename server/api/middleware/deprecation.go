@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DeprecationHeader = "Deprecation"
+	SunsetHeader      = "Sunset"
+	LinkHeader        = "Link"
+)
+
+// Deprecation describes deprecated API routes replaced by routes of a newer API version.
+type Deprecation struct {
+	// Since is the date the routes were deprecated.
+	Since time.Time
+	// Sunset is the date the routes are planned to be removed, zero if not planned yet.
+	Sunset time.Time
+	// Prefix is the prefix of the deprecated routes, it's replaced by SuccessorPrefix to get the successor route.
+	Prefix          string
+	SuccessorPrefix string
+}
+
+// Deprecated returns a middleware announcing the deprecation of the routes it wraps with the Deprecation and Sunset
+// headers, see RFC 9745 and RFC 8594, and the successor route in the Link header.
+func Deprecated(d Deprecation) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(DeprecationHeader, fmt.Sprintf("@%d", d.Since.Unix()))
+			if !d.Sunset.IsZero() {
+				w.Header().Set(SunsetHeader, d.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if d.SuccessorPrefix != "" && strings.HasPrefix(r.URL.Path, d.Prefix) {
+				successor := d.SuccessorPrefix + strings.TrimPrefix(r.URL.Path, d.Prefix)
+				w.Header().Add(LinkHeader, fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecated(t *testing.T) {
+	since := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name        string
+		deprecation Deprecation
+		wantSunset  string
+		wantLink    string
+	}{
+		{
+			name:        "with successor",
+			deprecation: Deprecation{Since: since, Prefix: "/api/v1", SuccessorPrefix: "/api/v2"},
+			wantLink:    `</api/v2/clients>; rel="successor-version"`,
+		},
+		{
+			name:        "with sunset",
+			deprecation: Deprecation{Since: since, Sunset: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)},
+			wantSunset:  "Wed, 31 Jan 2024 00:00:00 GMT",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			called := false
+			handler := Deprecated(tc.deprecation)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			}))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/clients?page[limit]=5", nil))
+
+			assert.True(t, called)
+			assert.Equal(t, "@1688169600", w.Header().Get(DeprecationHeader))
+			assert.Equal(t, tc.wantSunset, w.Header().Get(SunsetHeader))
+			assert.Equal(t, tc.wantLink, w.Header().Get(LinkHeader))
+		})
+	}
+}
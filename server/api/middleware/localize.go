@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/i18n"
+)
+
+// Localize returns a middleware that translates the titles of JSON error responses. The locale is negotiated from
+// the Accept-Language header and can be changed by later middlewares via i18n.SetRequestLocale, e.g. once the user is
+// known. Responses in the default locale are passed through unchanged.
+func Localize(defaultLocale string) func(http.Handler) http.Handler {
+	if defaultLocale == "" {
+		defaultLocale = i18n.DefaultLocale
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := i18n.WithRequestLocale(r.Context(), i18n.Negotiate(r.Header.Get("Accept-Language"), defaultLocale))
+			lw := &localizeResponseWriter{
+				ResponseWriter: w,
+				ctx:            ctx,
+			}
+			defer lw.close()
+
+			next.ServeHTTP(lw, r.WithContext(ctx))
+		})
+	}
+}
+
+// localizeResponseWriter buffers JSON error responses to translate them once the handler is done.
+type localizeResponseWriter struct {
+	http.ResponseWriter
+	ctx context.Context
+
+	status    int
+	buffering bool
+	buf       bytes.Buffer
+	hijacked  bool
+}
+
+func (w *localizeResponseWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = status
+
+	if status >= http.StatusBadRequest &&
+		strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") &&
+		i18n.RequestLocale(w.ctx) != i18n.DefaultLocale {
+		w.buffering = true
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *localizeResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.buffering {
+		return w.buf.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *localizeResponseWriter) Flush() {
+	if w.buffering {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *localizeResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not implement http.Hijacker")
+	}
+	w.hijacked = true
+	return h.Hijack()
+}
+
+func (w *localizeResponseWriter) close() {
+	if w.hijacked || !w.buffering {
+		return
+	}
+
+	body := w.buf.Bytes()
+	var payload api.ErrorPayload
+	if err := json.Unmarshal(body, &payload); err == nil && len(payload.Errors) > 0 {
+		locale := i18n.RequestLocale(w.ctx)
+		for i := range payload.Errors {
+			payload.Errors[i].Title = i18n.Translate(locale, payload.Errors[i].Title)
+		}
+		if translated, err := json.Marshal(payload); err == nil {
+			body = translated
+		}
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.ResponseWriter.WriteHeader(w.status)
+	_, _ = w.ResponseWriter.Write(body)
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/realvnc-labs/rport/server/i18n"
+)
+
+func TestLocalize(t *testing.T) {
+	errorBody := `{"errors":[{"code":"","title":"Client with id=\"client-1\" not found.","detail":""}]}`
+
+	testCases := []struct {
+		name           string
+		defaultLocale  string
+		acceptLanguage string
+		userLocale     string
+		contentType    string
+		status         int
+		body           string
+		wantBody       string
+	}{
+		{
+			name:        "default locale",
+			contentType: "application/json; charset=UTF-8",
+			status:      http.StatusNotFound,
+			body:        errorBody,
+			wantBody:    errorBody,
+		}, {
+			name:           "accept language",
+			acceptLanguage: "de-DE,de;q=0.9",
+			contentType:    "application/json; charset=UTF-8",
+			status:         http.StatusNotFound,
+			body:           errorBody,
+			wantBody:       `{"errors":[{"code":"","title":"Client mit id=\"client-1\" nicht gefunden.","detail":""}]}`,
+		}, {
+			name:          "server default locale",
+			defaultLocale: "fr",
+			contentType:   "application/json; charset=UTF-8",
+			status:        http.StatusNotFound,
+			body:          errorBody,
+			wantBody:      `{"errors":[{"code":"","title":"Client avec id=\"client-1\" introuvable.","detail":""}]}`,
+		}, {
+			name:           "user locale overrides accept language",
+			acceptLanguage: "de",
+			userLocale:     "es",
+			contentType:    "application/json; charset=UTF-8",
+			status:         http.StatusNotFound,
+			body:           errorBody,
+			wantBody:       `{"errors":[{"code":"","title":"No se encontró el cliente con id=\"client-1\".","detail":""}]}`,
+		}, {
+			name:           "success response",
+			acceptLanguage: "de",
+			contentType:    "application/json; charset=UTF-8",
+			status:         http.StatusOK,
+			body:           `{"data":"unauthorized"}`,
+			wantBody:       `{"data":"unauthorized"}`,
+		}, {
+			name:           "plain text error",
+			acceptLanguage: "de",
+			contentType:    "text/plain",
+			status:         http.StatusUnauthorized,
+			body:           "unauthorized",
+			wantBody:       "unauthorized",
+		}, {
+			name:           "invalid json",
+			acceptLanguage: "de",
+			contentType:    "application/json",
+			status:         http.StatusBadRequest,
+			body:           `{"errors":`,
+			wantBody:       `{"errors":`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			handler := Localize(tc.defaultLocale)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.userLocale != "" {
+					i18n.SetRequestLocale(r.Context(), tc.userLocale)
+				}
+				w.Header().Set("Content-Type", tc.contentType)
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Language", tc.acceptLanguage)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+			assert.Equal(t, tc.wantBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/metrics"
+)
+
+var apiRequestDuration = metrics.NewHistogram("rport_api_request_duration_seconds",
+	"Latency of API requests by method, route and status code.", metrics.DefaultDurationBuckets, "method", "route", "code")
+
+// RequestMetrics measures the latency of requests by their route template, so paths with ids are counted as one route.
+// It must be used on the router, otherwise the route of the request is not known.
+func RequestMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusResponseWriter{ResponseWriter: w}
+
+		next.ServeHTTP(sw, r)
+
+		route := ""
+		if current := mux.CurrentRoute(r); current != nil {
+			route, _ = current.GetPathTemplate()
+		}
+		status := sw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		apiRequestDuration.Observe(time.Since(start).Seconds(), r.Method, route, strconv.Itoa(status))
+	})
+}
+
+// statusResponseWriter records the status code sent by the handler.
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *statusResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not implement http.Hijacker")
+	}
+	w.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
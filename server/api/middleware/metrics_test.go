@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestMetrics(t *testing.T) {
+	r := mux.NewRouter()
+	r.HandleFunc("/test-metrics/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	r.HandleFunc("/test-metrics", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	r.Use(RequestMetrics)
+
+	for _, path := range []string{"/test-metrics/1", "/test-metrics/2", "/test-metrics"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, uint64(2), apiRequestDuration.Count(http.MethodGet, "/test-metrics/{id}", "404"))
+	assert.Equal(t, uint64(1), apiRequestDuration.Count(http.MethodGet, "/test-metrics", "200"))
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"regexp"
+
+	"github.com/jpillora/requestlog"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/share/random"
+)
+
+// RequestIDHeader is the header used to pass the id of an API request and returned in every response.
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID limits the ids accepted from callers, so they can be logged and returned as is.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID is a middleware that uses the X-Request-ID header of the request or generates a new id if it's missing
+// or invalid. The id is stored in the request context and returned in the X-Request-ID header of the response.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(api.WithRequestID(r.Context(), requestID)))
+	})
+}
+
+func newRequestID() string {
+	id, err := random.UUID4()
+	if err != nil {
+		return random.Hex(32)
+	}
+	return id
+}
+
+// RequestLog returns a middleware that logs every request like requestlog.WrapWith and appends the request id to
+// the log line.
+func RequestLog(opts requestlog.Options) func(http.Handler) http.Handler {
+	if opts.Writer == nil {
+		opts.Writer = requestlog.DefaultOptions.Writer
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestOpts := opts
+			requestOpts.Writer = &requestIDWriter{
+				Writer:    opts.Writer,
+				requestID: api.GetRequestID(r.Context()),
+			}
+			requestlog.WrapWith(next, requestOpts).ServeHTTP(w, r)
+		})
+	}
+}
+
+// requestIDWriter appends the request id to the log line written by requestlog.
+type requestIDWriter struct {
+	io.Writer
+	requestID string
+}
+
+func (w *requestIDWriter) Write(p []byte) (int, error) {
+	if w.requestID == "" {
+		return w.Writer.Write(p)
+	}
+
+	line := bytes.TrimSuffix(p, []byte("\n"))
+	_, err := w.Writer.Write([]byte(string(line) + " [request_id=" + w.requestID + "]\n"))
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jpillora/requestlog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/api"
+)
+
+func TestRequestID(t *testing.T) {
+	testCases := []struct {
+		name      string
+		requestID string
+		wantSame  bool
+	}{
+		{
+			name:     "missing",
+			wantSame: false,
+		}, {
+			name:      "valid",
+			requestID: "f0e4c2f7-6d1a-4c3b-9e0a-1b2c3d4e5f60",
+			wantSame:  true,
+		}, {
+			name:      "invalid chars",
+			requestID: "abc\ninjected",
+			wantSame:  false,
+		}, {
+			name:      "too long",
+			requestID: strings.Repeat("a", 129),
+			wantSame:  false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var gotCtxID string
+			handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotCtxID = api.GetRequestID(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(RequestIDHeader, tc.requestID)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			gotID := w.Header().Get(RequestIDHeader)
+			require.NotEmpty(t, gotID)
+			assert.Equal(t, gotID, gotCtxID)
+			if tc.wantSame {
+				assert.Equal(t, tc.requestID, gotID)
+			} else {
+				assert.NotEqual(t, tc.requestID, gotID)
+				assert.Regexp(t, validRequestID, gotID)
+			}
+		})
+	}
+}
+
+func TestRequestLog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	opts := requestlog.DefaultOptions
+	opts.Writer = buf
+	opts.Colors = &requestlog.Colors{}
+
+	handler := RequestID(RequestLog(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/status", nil)
+	req.Header.Set(RequestIDHeader, "request-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Regexp(t, `GET /api/v1/status 204 .* \[request_id=request-1\]\n$`, buf.String())
+}
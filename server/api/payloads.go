@@ -2,6 +2,8 @@ package api
 
 import (
 	"errors"
+	"net/url"
+	"strconv"
 
 	errors2 "github.com/realvnc-labs/rport/server/api/errors"
 )
@@ -22,6 +24,8 @@ func NewSuccessPayload(data interface{}) SuccessPayload {
 // ErrorPayload represents a uniform format for all error API responses.
 type ErrorPayload struct {
 	Errors []ErrorPayloadItem `json:"errors"`
+	// RequestID is the id of the API request that failed, used to find the related log lines and audit log entries.
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // ErrorPayloadItem represents a uniform format for a single error used in API responses.
@@ -31,6 +35,16 @@ type ErrorPayloadItem struct {
 	Detail string `json:"detail"`
 }
 
+// WithDefaultCode sets the generic code of the given http status on all errors without a specific code.
+func (p ErrorPayload) WithDefaultCode(statusCode int) ErrorPayload {
+	for i := range p.Errors {
+		if p.Errors[i].Code == "" {
+			p.Errors[i].Code = errors2.CodeFromStatus(statusCode)
+		}
+	}
+	return p
+}
+
 func NewErrAPIPayloadFromMessage(code, title, detail string) ErrorPayload {
 	return ErrorPayload{
 		Errors: []ErrorPayloadItem{
@@ -65,11 +79,13 @@ func newAPIErrorPayload(err errors2.APIError) ErrorPayload {
 func newAPIErrorPayloadItem(err errors2.APIError) ErrorPayloadItem {
 	if err.Err != nil && err.Message != "" {
 		return ErrorPayloadItem{
+			Code:   err.ErrCode,
 			Title:  err.Message,
 			Detail: err.Err.Error(),
 		}
 	}
 	return ErrorPayloadItem{
+		Code:   err.ErrCode,
 		Title:  err.Error(),
 		Detail: "",
 	}
@@ -86,18 +102,21 @@ func newAPIErrorsPayload(errors errors2.APIErrors) ErrorPayload {
 }
 
 type ExecuteInput struct {
-	Command     string `json:"command"`
-	Script      string `json:"script"`
-	Interpreter string `json:"interpreter"`
-	Cwd         string `json:"cwd"`
-	IsSudo      bool   `json:"is_sudo"`
-	TimeoutSec  int    `json:"timeout_sec"`
+	Command     string   `json:"command"`
+	Script      string   `json:"script"`
+	Interpreter string   `json:"interpreter"`
+	Cwd         string   `json:"cwd"`
+	IsSudo      bool     `json:"is_sudo"`
+	TimeoutSec  int      `json:"timeout_sec"`
+	Labels      []string `json:"labels"`
 	ClientID    string
 	IsScript    bool
 }
 
 type Meta struct {
 	Count int `json:"count"`
+	// NextCursor is set by endpoints supporting cursor pagination if more results are available.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 func NewMeta(count int) *Meta {
@@ -107,3 +126,61 @@ func NewMeta(count int) *Meta {
 }
 
 type Links interface{}
+
+// ListPayload is the format of list responses of the v2 API.
+type ListPayload struct {
+	Data  interface{} `json:"data"`
+	Meta  PageMeta    `json:"meta"`
+	Links PageLinks   `json:"links"`
+}
+
+// PageMeta describes the page of a v2 list response.
+type PageMeta struct {
+	// Total is the number of all elements matching the filters.
+	Total int `json:"total"`
+	// Count is the number of elements of the page.
+	Count  int `json:"count"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// PageLinks are the links to the current, the next and the previous page of a v2 list response.
+type PageLinks struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// NewListPayload returns the payload of the page of the request URL.
+func NewListPayload(data interface{}, u *url.URL, total, count, limit, offset int) ListPayload {
+	p := ListPayload{
+		Data: data,
+		Meta: PageMeta{
+			Total:  total,
+			Count:  count,
+			Limit:  limit,
+			Offset: offset,
+		},
+		Links: PageLinks{
+			Self: pageURL(u, limit, offset),
+		},
+	}
+	if offset+count < total {
+		p.Links.Next = pageURL(u, limit, offset+limit)
+	}
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		p.Links.Prev = pageURL(u, limit, prev)
+	}
+	return p
+}
+
+func pageURL(u *url.URL, limit, offset int) string {
+	values := u.Query()
+	values.Set("page[limit]", strconv.Itoa(limit))
+	values.Set("page[offset]", strconv.Itoa(offset))
+	return (&url.URL{Path: u.Path, RawQuery: values.Encode()}).String()
+}
@@ -0,0 +1,156 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const DefaultTimeout = 2 * time.Second
+
+// Config of an external authorization endpoint consulted for every authenticated API request.
+type Config struct {
+	// OPAURL is the url of an Open Policy Agent decision, e.g. http://localhost:8181/v1/data/rport/allow.
+	OPAURL string `mapstructure:"opa_url"`
+	// OPATimeout is the maximum time to wait for a decision.
+	OPATimeout time.Duration `mapstructure:"opa_timeout"`
+	// OPAFailOpen allows requests if the endpoint can't be reached or returns an error. By default, they are denied.
+	OPAFailOpen bool `mapstructure:"opa_fail_open"`
+}
+
+func (c *Config) Enabled() bool {
+	return c.OPAURL != ""
+}
+
+func (c *Config) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	u, err := url.Parse(c.OPAURL)
+	if err != nil {
+		return fmt.Errorf("invalid opa_url: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("opa_url must be an http or https url")
+	}
+	if c.OPATimeout <= 0 {
+		return errors.New("opa_timeout must be greater than 0")
+	}
+	return nil
+}
+
+// Input is the context of an API request passed to the policy.
+type Input struct {
+	User    User    `json:"user"`
+	Request Request `json:"request"`
+	Client  *Client `json:"client,omitempty"`
+}
+
+type User struct {
+	Username string   `json:"username"`
+	Groups   []string `json:"groups"`
+}
+
+type Request struct {
+	Method     string              `json:"method"`
+	Path       string              `json:"path"`
+	Route      string              `json:"route"`
+	Vars       map[string]string   `json:"vars"`
+	Query      map[string][]string `json:"query"`
+	RemoteAddr string              `json:"remote_addr"`
+}
+
+// Client holds the attributes of the client targeted by the request.
+type Client struct {
+	ID                string            `json:"id"`
+	Name              string            `json:"name"`
+	Tags              []string          `json:"tags"`
+	AutoTags          []string          `json:"auto_tags"`
+	Labels            map[string]string `json:"labels"`
+	OSFamily          string            `json:"os_family"`
+	OSKernel          string            `json:"os_kernel"`
+	Hostname          string            `json:"hostname"`
+	ClientAuthID      string            `json:"client_auth_id"`
+	AllowedUserGroups []string          `json:"allowed_user_groups"`
+	Connected         bool              `json:"connected"`
+}
+
+// Decision of the policy, Reason is optional.
+type Decision struct {
+	Allow  bool   `json:"allow"`
+	Reason string `json:"reason"`
+}
+
+// OPAClient queries decisions from the Open Policy Agent REST API. The decision can either be a boolean or an object
+// with the fields "allow" and optionally "reason". An undefined decision denies the request.
+type OPAClient struct {
+	config     Config
+	httpClient *http.Client
+}
+
+func NewOPAClient(config Config) *OPAClient {
+	return &OPAClient{
+		config:     config,
+		httpClient: &http.Client{Timeout: config.OPATimeout},
+	}
+}
+
+func (c *OPAClient) FailOpen() bool {
+	return c.config.OPAFailOpen
+}
+
+func (c *OPAClient) Decide(ctx context.Context, input *Input) (*Decision, error) {
+	body, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.OPAURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query opa: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("opa returned status %d: %s", resp.StatusCode, msg)
+	}
+
+	var result struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode opa response: %w", err)
+	}
+
+	return parseDecision(result.Result)
+}
+
+func parseDecision(raw json.RawMessage) (*Decision, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return &Decision{Reason: "policy decision is undefined"}, nil
+	}
+
+	var allow bool
+	if err := json.Unmarshal(raw, &allow); err == nil {
+		return &Decision{Allow: allow}, nil
+	}
+
+	decision := &Decision{}
+	if err := json.Unmarshal(raw, decision); err != nil {
+		return nil, fmt.Errorf("invalid opa decision %s: expected a boolean or an object with allow", raw)
+	}
+	return decision, nil
+}
@@ -0,0 +1,100 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOPAClientDecide(t *testing.T) {
+	testCases := []struct {
+		name          string
+		status        int
+		response      string
+		expected      *Decision
+		expectedError string
+	}{
+		{
+			name:     "boolean allow",
+			status:   http.StatusOK,
+			response: `{"result": true}`,
+			expected: &Decision{Allow: true},
+		},
+		{
+			name:     "boolean deny",
+			status:   http.StatusOK,
+			response: `{"result": false}`,
+			expected: &Decision{Allow: false},
+		},
+		{
+			name:     "object with reason",
+			status:   http.StatusOK,
+			response: `{"result": {"allow": false, "reason": "outside business hours"}}`,
+			expected: &Decision{Allow: false, Reason: "outside business hours"},
+		},
+		{
+			name:     "undefined",
+			status:   http.StatusOK,
+			response: `{}`,
+			expected: &Decision{Allow: false, Reason: "policy decision is undefined"},
+		},
+		{
+			name:          "invalid decision",
+			status:        http.StatusOK,
+			response:      `{"result": "yes"}`,
+			expectedError: `invalid opa decision "yes": expected a boolean or an object with allow`,
+		},
+		{
+			name:          "error status",
+			status:        http.StatusInternalServerError,
+			response:      `policy error`,
+			expectedError: "opa returned status 500: policy error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotInput Input
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					Input Input `json:"input"`
+				}
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				gotInput = body.Input
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.response))
+			}))
+			defer srv.Close()
+
+			c := NewOPAClient(Config{OPAURL: srv.URL, OPATimeout: time.Second})
+			input := &Input{
+				User:    User{Username: "user1", Groups: []string{"Users"}},
+				Request: Request{Method: http.MethodPut, Route: "/api/v1/clients/{client_id}/tunnels", Vars: map[string]string{"client_id": "client-1"}},
+				Client:  &Client{ID: "client-1", Tags: []string{"production"}},
+			}
+
+			decision, err := c.Decide(context.Background(), input)
+
+			assert.Equal(t, *input, gotInput)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, decision)
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, (&Config{}).Validate())
+	assert.NoError(t, (&Config{OPAURL: "http://localhost:8181/v1/data/rport/allow", OPATimeout: time.Second}).Validate())
+	assert.EqualError(t, (&Config{OPAURL: "localhost:8181", OPATimeout: time.Second}).Validate(), "opa_url must be an http or https url")
+	assert.EqualError(t, (&Config{OPAURL: "http://localhost:8181"}).Validate(), "opa_timeout must be greater than 0")
+}
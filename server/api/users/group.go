@@ -6,6 +6,8 @@ import (
 
 const (
 	Administrators = "Administrators"
+	// Auditors can read all resources but are blocked from changing any, see User.IsAuditor.
+	Auditors = "Auditors"
 )
 
 var AdministratorsGroup = Group{
@@ -16,6 +16,7 @@ const (
 	PermissionMonitoring = "monitoring"
 	PermissionUploads    = "uploads"
 	PermissionsAuditLog  = "auditlog"
+	PermissionCaptures   = "captures"
 )
 
 var AllPermissions = []string{
@@ -27,6 +28,7 @@ var AllPermissions = []string{
 	PermissionMonitoring,
 	PermissionUploads,
 	PermissionsAuditLog,
+	PermissionCaptures,
 }
 
 type Permissions struct {
@@ -27,6 +27,22 @@ func (u User) IsAdmin() bool {
 	return false
 }
 
+// IsAuditor returns true for members of the Auditors group. Auditors have read access to all resources, all requests
+// that could change something are rejected, even if the user is an admin as well.
+func (u User) IsAuditor() bool {
+	for _, group := range u.Groups {
+		if group == Auditors {
+			return true
+		}
+	}
+	return false
+}
+
+// CanReadAll returns true if the user may read the resources of all users and clients.
+func (u User) CanReadAll() bool {
+	return u.IsAdmin() || u.IsAuditor()
+}
+
 func PasswordExpired(f bool) *bool {
 	return &f
 }
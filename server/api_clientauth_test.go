@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/middleware"
 	"github.com/realvnc-labs/rport/server/chconfig"
 	"github.com/realvnc-labs/rport/server/clients"
 	"github.com/realvnc-labs/rport/server/clients/clientdata"
@@ -154,7 +155,9 @@ func TestHandleGetClientsAuth(t *testing.T) {
 				}
 			} else {
 				// failure case
-				wantResp = api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, "")
+				errResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, "").WithDefaultCode(tc.wantStatusCode)
+				errResp.RequestID = w.Header().Get(middleware.RequestIDHeader)
+				wantResp = errResp
 			}
 			wantRespBytes, err := json.Marshal(wantResp)
 			require.NoError(err)
@@ -356,7 +359,8 @@ func TestHandlePostClientsAuth(t *testing.T) {
 				assert.Empty(w.Body.String())
 			} else {
 				// failure case
-				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail)
+				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail).WithDefaultCode(tc.wantStatusCode)
+				wantResp.RequestID = w.Header().Get(middleware.RequestIDHeader)
 				wantRespBytes, err := json.Marshal(wantResp)
 				require.NoError(err)
 				require.Equal(string(wantRespBytes), w.Body.String())
@@ -542,7 +546,8 @@ func TestHandleDeleteClientAuth(t *testing.T) {
 				// success case: empty body
 			} else {
 				// failure case
-				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail)
+				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail).WithDefaultCode(tc.wantStatusCode)
+				wantResp.RequestID = w.Header().Get(middleware.RequestIDHeader)
 				wantRespBytes, err := json.Marshal(wantResp)
 				require.NoError(err)
 				wantRespStr = string(wantRespBytes)
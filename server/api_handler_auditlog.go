@@ -2,27 +2,53 @@ package chserver
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/realvnc-labs/rport/server/auditlog"
 )
 
+const auditLogFormatQueryParam = "format"
+
 // handleListAuditLog handles GET /auditlog
+// With format=csv all entries matching the filters are exported as CSV.
 func (al *APIListener) handleListAuditLog(w http.ResponseWriter, req *http.Request) {
 	curUser, err := al.getUserModelForAuth(req.Context())
 	if err != nil {
 		al.jsonError(w, err)
 		return
 	}
-	result, err := al.auditLog.List(req, curUser)
-	if err != nil {
-		var nae *auditlog.NotAllowedError
-		if errors.As(err, &nae) {
-			al.jsonErrorResponseWithError(w, http.StatusForbidden, "filter forbidden", err)
+
+	switch format := req.URL.Query().Get(auditLogFormatQueryParam); format {
+	case "", "json":
+		result, err := al.auditLog.List(req, curUser)
+		if err != nil {
+			al.handleAuditLogError(w, err)
 			return
 		}
-		al.jsonError(w, err)
+		al.writeJSONResponse(w, http.StatusOK, result)
+	case "csv":
+		export, err := al.auditLog.NewExport(req, curUser)
+		if err != nil {
+			al.handleAuditLogError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="auditlog.csv"`)
+		w.WriteHeader(http.StatusOK)
+		if err := export.WriteCSV(req.Context(), w); err != nil {
+			al.Errorf("Failed to write auditlog CSV: %v", err)
+		}
+	default:
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s: %q.", auditLogFormatQueryParam, format))
+	}
+}
+
+func (al *APIListener) handleAuditLogError(w http.ResponseWriter, err error) {
+	var nae *auditlog.NotAllowedError
+	if errors.As(err, &nae) {
+		al.jsonErrorResponseWithError(w, http.StatusForbidden, "filter forbidden", err)
 		return
 	}
-	al.writeJSONResponse(w, http.StatusOK, result)
+	al.jsonError(w, err)
 }
@@ -0,0 +1,106 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/bandwidth"
+)
+
+const (
+	bandwidthFromQueryParam   = "from"
+	bandwidthToQueryParam     = "to"
+	bandwidthTopQueryParam    = "top"
+	bandwidthFormatQueryParam = "format"
+)
+
+// handleGetBandwidthReport handles GET /bandwidth
+// It returns the total tunnel traffic and the top clients and users of the given days, month-to-date by default.
+func (al *APIListener) handleGetBandwidthReport(w http.ResponseWriter, req *http.Request) {
+	from, to, ok := al.parseBandwidthDays(w, req)
+	if !ok {
+		return
+	}
+
+	top := 10
+	if topStr := req.URL.Query().Get(bandwidthTopQueryParam); topStr != "" {
+		var err error
+		top, err = strconv.Atoi(topStr)
+		if err != nil || top < 0 {
+			al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s: %q.", bandwidthTopQueryParam, topStr))
+			return
+		}
+	}
+
+	report, err := al.bandwidth.Report(req.Context(), from, to, top)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(report))
+}
+
+// handleGetBandwidthDaily handles GET /bandwidth/daily
+// It returns the daily tunnel traffic per client and user of the given days. With format=csv it's exported as CSV.
+func (al *APIListener) handleGetBandwidthDaily(w http.ResponseWriter, req *http.Request) {
+	from, to, ok := al.parseBandwidthDays(w, req)
+	if !ok {
+		return
+	}
+
+	format := req.URL.Query().Get(bandwidthFormatQueryParam)
+	if format != "" && format != "json" && format != "csv" {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s: %q.", bandwidthFormatQueryParam, format))
+		return
+	}
+
+	usages, err := al.bandwidth.List(req.Context(), from, to)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	if format != "csv" {
+		al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(usages))
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="bandwidth-%s-%s.csv"`, from, to))
+	w.WriteHeader(http.StatusOK)
+	if err := bandwidth.WriteCSV(w, usages); err != nil {
+		al.Errorf("Failed to write bandwidth CSV: %v", err)
+	}
+}
+
+// parseBandwidthDays returns the days given by the from and to params, by default the first day of the current
+// month and today.
+func (al *APIListener) parseBandwidthDays(w http.ResponseWriter, req *http.Request) (string, string, bool) {
+	from := req.URL.Query().Get(bandwidthFromQueryParam)
+	if from == "" {
+		from = al.bandwidth.MonthStart()
+	}
+	to := req.URL.Query().Get(bandwidthToQueryParam)
+	if to == "" {
+		to = al.bandwidth.Today()
+	}
+
+	for _, param := range []string{bandwidthFromQueryParam, bandwidthToQueryParam} {
+		day := from
+		if param == bandwidthToQueryParam {
+			day = to
+		}
+		if _, err := time.Parse(bandwidth.DayLayout, day); err != nil {
+			al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s: %q, expected YYYY-MM-DD.", param, day))
+			return "", "", false
+		}
+	}
+	if from > to {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s: %q is after %s.", bandwidthFromQueryParam, from, to))
+		return "", "", false
+	}
+	return from, to, true
+}
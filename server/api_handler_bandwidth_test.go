@@ -0,0 +1,89 @@
+package chserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bandwidthmigration "github.com/realvnc-labs/rport/db/migration/bandwidth"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/bandwidth"
+	"github.com/realvnc-labs/rport/server/chconfig"
+)
+
+func TestHandleGetBandwidth(t *testing.T) {
+	testUser := "admin"
+	db, err := sqlite.New(":memory:", bandwidthmigration.AssetNames(), bandwidthmigration.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	service := bandwidth.NewService(bandwidth.NewSQLiteProvider(db), bandwidth.Config{}, testLog)
+	defer service.Close()
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+			bandwidth: service,
+		},
+		userService: users.NewAPIService(users.NewStaticProvider([]*users.User{{
+			Username: testUser,
+			Groups:   []string{users.Administrators},
+		}}), false, 0, -1),
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	service.Add("client-1", "alice", 10, 20)
+	service.Add("client-2", "bob", 1, 2)
+
+	ctx := api.WithUser(context.Background(), testUser)
+	get := func(url string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, url, nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		al.router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("report", func(t *testing.T) {
+		w := get("/api/v1/bandwidth?top=1")
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var gotResp struct {
+			Data bandwidth.Report `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &gotResp))
+		assert.Equal(t, service.MonthStart(), gotResp.Data.From)
+		assert.Equal(t, service.Today(), gotResp.Data.To)
+		assert.Equal(t, int64(33), gotResp.Data.Total.Total)
+		assert.Equal(t, []*bandwidth.Consumer{{ID: "client-1", BytesIn: 10, BytesOut: 20, Total: 30}}, gotResp.Data.TopClients)
+		assert.Equal(t, []*bandwidth.Consumer{{ID: "alice", BytesIn: 10, BytesOut: 20, Total: 30}}, gotResp.Data.TopUsers)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		w := get("/api/v1/bandwidth/daily?format=csv")
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+		today := service.Today()
+		expected := "day,client_id,username,bytes_in,bytes_out\n" +
+			today + ",client-1,alice,10,20\n" +
+			today + ",client-2,bob,1,2\n"
+		assert.Equal(t, expected, w.Body.String())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get("/api/v1/bandwidth?from=May").Code)
+		assert.Equal(t, http.StatusBadRequest, get("/api/v1/bandwidth?from=2023-05-02&to=2023-05-01").Code)
+		assert.Equal(t, http.StatusBadRequest, get("/api/v1/bandwidth?top=-1").Code)
+		assert.Equal(t, http.StatusBadRequest, get("/api/v1/bandwidth/daily?format=xml").Code)
+	})
+}
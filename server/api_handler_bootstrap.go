@@ -0,0 +1,62 @@
+package chserver
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/bootstrap"
+)
+
+// handlePostBootstrap handles POST /bootstrap
+// It applies a bundle in yaml or json format, the response lists the result of each entry.
+func (al *APIListener) handlePostBootstrap(w http.ResponseWriter, req *http.Request) {
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	bundle, err := bootstrap.Parse(data)
+	if err != nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	changes, err := al.newBootstrapApplier().Apply(req.Context(), bundle, api.GetUser(req.Context(), al.Logger))
+
+	al.auditLog.Entry(auditlog.ApplicationBootstrap, auditlog.ActionApply).
+		WithHTTPRequest(req).
+		WithResponse(changes).
+		Save()
+
+	if err != nil {
+		if errors.Is(err, bootstrap.ErrUnsupported) {
+			al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(changes))
+}
+
+// newBootstrapApplier returns an applier for the configured services. Client auths are writeable only if the provider
+// and the config allow it, alert rules require the rport-plus alerting.
+func (al *APIListener) newBootstrapApplier() *bootstrap.Applier {
+	applier := &bootstrap.Applier{
+		Users:           al.userService,
+		TunnelTemplates: al.storedTunnels,
+		Schedules:       al.scheduleManager,
+		Logger:          al.Logger,
+	}
+	if al.isClientsAuthWriteable() {
+		applier.ClientAuths = al.clientAuthProvider
+	}
+	if al.alertingService != nil {
+		applier.AlertRules = al.alertingService
+	}
+	return applier
+}
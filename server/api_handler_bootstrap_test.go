@@ -0,0 +1,90 @@
+package chserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	clientsmigration "github.com/realvnc-labs/rport/db/migration/clients"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients/storedtunnels"
+	"github.com/realvnc-labs/rport/server/clientsauth"
+)
+
+func TestHandlePostBootstrap(t *testing.T) {
+	db, err := sqlite.New(":memory:", clientsmigration.AssetNames(), clientsmigration.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+			clientAuthProvider: clientsauth.NewDatabaseMockProvider(nil, t),
+		},
+		userService: users.NewAPIService(users.NewStaticProvider([]*users.User{
+			{Username: "admin", Groups: []string{users.Administrators}},
+		}), false, 0, -1),
+		storedTunnels: storedtunnels.New(db),
+		Logger:        testLog,
+	}
+	al.initRouter()
+
+	testCases := []struct {
+		Name           string
+		Body           string
+		ExpectedStatus int
+		ExpectedBody   string
+		ExpectedError  string
+	}{
+		{
+			Name:           "invalid bundle",
+			Body:           "tunnel_templates:\n  - name: ssh\n",
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedError:  "invalid bootstrap bundle: tunnel_templates: entry 1: client_id and name are required",
+		},
+		{
+			Name:           "client auths read-only",
+			Body:           "client_auths:\n  - id: site-a\n    password: client-pass\n",
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedError:  "client_auths: not supported, client auths are read-only",
+		},
+		{
+			Name:           "applied",
+			Body:           `{"tunnel_templates": [{"client_id": "web1", "name": "ssh", "scheme": "ssh", "remote_port": 22}]}`,
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody:   `{"data":[{"kind":"tunnel_template","id":"web1/ssh","action":"created"}]}`,
+		},
+		{
+			Name:           "applied again",
+			Body:           "tunnel_templates:\n  - {client_id: web1, name: ssh, scheme: ssh, remote_port: 22}\n",
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody:   `{"data":[{"kind":"tunnel_template","id":"web1/ssh","action":"unchanged"}]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/bootstrap", strings.NewReader(tc.Body))
+			req = req.WithContext(api.WithUser(req.Context(), "admin"))
+			al.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.ExpectedStatus, w.Code, w.Body.String())
+			if tc.ExpectedError != "" {
+				assert.Contains(t, w.Body.String(), tc.ExpectedError)
+				return
+			}
+			assert.JSONEq(t, tc.ExpectedBody, w.Body.String())
+		})
+	}
+}
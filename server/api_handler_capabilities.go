@@ -0,0 +1,90 @@
+package chserver
+
+import (
+	"net/http"
+
+	"github.com/realvnc-labs/rport/server/api"
+	chshare "github.com/realvnc-labs/rport/share"
+)
+
+const capabilitiesAPIVersion = "v1"
+
+// featureVersions lists the versions of API features that changed over time. A version is increased whenever a
+// feature changes in a way external tooling has to adapt to, new features are added with version 1.
+var featureVersions = map[string]int{
+	"client_variables":      1,
+	"client_address_change": 1,
+	"client_list_ws":        1,
+	"multi_job_status":      1,
+	"multi_job_preflight":   1,
+	"bootstrap":             1,
+	"feature_flags":         1,
+	"reverse_tunnels":       1,
+	"server_metrics":        1,
+	"client_group_health":   1,
+	"auditlog_cursor":       1,
+	"auditlog_export":       1,
+	"job_recovery":          1,
+	"security_snapshot":     1,
+	"runbooks":              1,
+	"auto_remediation":      1,
+	"tunnel_bind_address":   1,
+	"job_result_summary":    1,
+	"client_features":       1,
+	"api_v2":                1,
+	"filter_operators":      1,
+	"tunnel_closures":       1,
+	"socks5_tunnels":        1,
+	"prometheus_metrics":    1,
+}
+
+// ServerCapabilities describes how the server is configured, so external tooling can adapt to it.
+type ServerCapabilities struct {
+	Version         string             `json:"version"`
+	APIVersion      string             `json:"api_version"`
+	Subsystems      map[string]bool    `json:"subsystems"`
+	Limits          CapabilitiesLimits `json:"limits"`
+	FeatureVersions map[string]int     `json:"feature_versions"`
+}
+
+type CapabilitiesLimits struct {
+	MaxRequestBytes        int64    `json:"max_request_bytes"`
+	MaxRequestBytesClient  int64    `json:"max_request_bytes_client"`
+	MaxFilePushSize        int64    `json:"max_filepush_size"`
+	UsedPorts              []string `json:"used_ports"`
+	ExcludedPorts          []string `json:"excluded_ports"`
+	RunRemoteCmdTimeoutSec int      `json:"run_remote_cmd_timeout_sec"`
+	JobsMaxResults         int      `json:"jobs_max_results"`
+	MaxTokenLifetimeHours  int      `json:"max_token_lifetime_hours"`
+	PasswordMinLength      int      `json:"password_min_length"`
+}
+
+// handleGetCapabilities handles GET /capabilities
+func (al *APIListener) handleGetCapabilities(w http.ResponseWriter, req *http.Request) {
+	subsystems := al.getFeatures()
+	subsystems["caddy_integration"] = al.config.Caddy.Enabled
+	subsystems["public_status"] = al.config.API.PublicStatusEnabled
+	subsystems["health_probes"] = al.config.API.HealthProbesEnabled
+	subsystems["prometheus_metrics"] = al.config.API.PrometheusMetricsEnabled
+	subsystems["tunnel_approvals"] = al.tunnelApprovals != nil
+	subsystems["exec_hooks"] = len(al.config.Server.ExecHooks) > 0
+	subsystems["firewall"] = al.config.Firewall.Enabled()
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(ServerCapabilities{
+		Version:    chshare.BuildVersion,
+		APIVersion: capabilitiesAPIVersion,
+		Subsystems: subsystems,
+		Limits: CapabilitiesLimits{
+			MaxRequestBytes:        al.config.API.MaxRequestBytes,
+			MaxRequestBytesClient:  al.config.Server.MaxRequestBytesClient,
+			MaxFilePushSize:        al.config.API.MaxFilePushSize,
+			UsedPorts:              al.config.Server.UsedPortsRaw,
+			ExcludedPorts:          al.config.Server.ExcludedPortsRaw,
+			RunRemoteCmdTimeoutSec: al.config.Server.RunRemoteCmdTimeoutSec,
+			JobsMaxResults:         al.config.Server.JobsMaxResults,
+			MaxTokenLifetimeHours:  al.config.API.MaxTokenLifeTimeHours,
+			PasswordMinLength:      al.config.API.PasswordMinLength,
+		},
+		FeatureVersions: featureVersions,
+	}))
+}
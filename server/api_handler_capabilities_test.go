@@ -0,0 +1,65 @@
+package chserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	chshare "github.com/realvnc-labs/rport/share"
+)
+
+func TestHandleGetCapabilities(t *testing.T) {
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes:     2048,
+					MaxFilePushSize:     4096,
+					PublicStatusEnabled: true,
+				},
+				Server: chconfig.ServerConfig{
+					UsedPortsRaw:           []string{"20000-30000"},
+					ExcludedPortsRaw:       []string{"1-1024"},
+					RunRemoteCmdTimeoutSec: 60,
+				},
+				Monitoring: chconfig.MonitoringConfig{
+					Enabled: true,
+				},
+			},
+		},
+		userService: users.NewAPIService(users.NewStaticProvider(nil), false, 0, -1),
+		Logger:      testLog,
+	}
+	al.initRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/capabilities", nil)
+	w := httptest.NewRecorder()
+	al.router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data ServerCapabilities `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, chshare.BuildVersion, resp.Data.Version)
+	assert.Equal(t, "v1", resp.Data.APIVersion)
+	assert.True(t, resp.Data.Subsystems[featureMonitoring])
+	assert.True(t, resp.Data.Subsystems["public_status"])
+	assert.False(t, resp.Data.Subsystems[featureCaptures])
+	assert.False(t, resp.Data.Subsystems["tunnel_approvals"])
+	assert.Equal(t, CapabilitiesLimits{
+		MaxRequestBytes:        2048,
+		MaxFilePushSize:        4096,
+		UsedPorts:              []string{"20000-30000"},
+		ExcludedPorts:          []string{"1-1024"},
+		RunRemoteCmdTimeoutSec: 60,
+	}, resp.Data.Limits)
+	assert.Equal(t, featureVersions, resp.Data.FeatureVersions)
+}
@@ -0,0 +1,58 @@
+package chserver
+
+import (
+	"net/http"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/capacity"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+type capacityPayload struct {
+	Current   *capacity.Sample     `json:"current"`
+	Forecasts []*capacity.Forecast `json:"forecasts"`
+}
+
+// handleGetCapacity handles GET /capacity
+func (al *APIListener) handleGetCapacity(w http.ResponseWriter, req *http.Request) {
+	current, err := al.capacityService.Current()
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	forecasts, err := al.capacityService.Forecasts(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(capacityPayload{
+		Current:   current,
+		Forecasts: forecasts,
+	}))
+}
+
+// handleGetCapacityHistory handles GET /capacity/history
+func (al *APIListener) handleGetCapacityHistory(w http.ResponseWriter, req *http.Request) {
+	options := query.NewOptions(req, capacity.ListDefaultSort, nil, nil)
+	err := query.ValidateListOptions(options, capacity.SupportedSorts, capacity.SupportedFilters, nil, &query.PaginationConfig{
+		DefaultLimit: 100,
+		MaxLimit:     1000,
+	})
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	samples, count, err := al.capacityService.History(req.Context(), options)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, &api.SuccessPayload{
+		Data: samples,
+		Meta: api.NewMeta(count),
+	})
+}
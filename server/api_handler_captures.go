@@ -0,0 +1,245 @@
+package chserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	errors2 "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/capture"
+	"github.com/realvnc-labs/rport/server/routes"
+	"github.com/realvnc-labs/rport/share/comm"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+// captureTimeoutMargin is added to the capture duration for the job timeout, so the client has time to upload the data
+const captureTimeoutMargin = 30 * time.Second
+
+type captureRequest struct {
+	Interface      string `json:"interface"`
+	Filter         string `json:"filter"`
+	MaxDurationSec int    `json:"max_duration_sec"`
+	MaxBytes       int64  `json:"max_bytes"`
+}
+
+// handlePostCapture handles POST /clients/{client_id}/captures
+func (al *APIListener) handlePostCapture(w http.ResponseWriter, req *http.Request) {
+	if al.captures == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, "Packet capture is disabled.")
+		return
+	}
+
+	cid := mux.Vars(req)[routes.ParamClientID]
+	var reqBody captureRequest
+	err := parseRequestBody(req.Body, &reqBody)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	cfg := al.config.Server.Capture
+	captureReq := &comm.CaptureRequest{
+		Interface:   reqBody.Interface,
+		Filter:      reqBody.Filter,
+		MaxDuration: time.Duration(reqBody.MaxDurationSec) * time.Second,
+		MaxBytes:    reqBody.MaxBytes,
+	}
+	if captureReq.MaxDuration == 0 {
+		captureReq.MaxDuration = cfg.MaxDuration
+	}
+	if captureReq.MaxBytes == 0 {
+		captureReq.MaxBytes = cfg.MaxBytes
+	}
+	if captureReq.MaxDuration > cfg.MaxDuration {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("max_duration_sec cannot exceed %d.", int(cfg.MaxDuration.Seconds())))
+		return
+	}
+	if captureReq.MaxBytes > cfg.MaxBytes {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("max_bytes cannot exceed %d.", cfg.MaxBytes))
+		return
+	}
+	if err := captureReq.Validate(); err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusBadRequest, "Invalid packet capture request.", err)
+		return
+	}
+
+	client, err := al.clientService.GetActiveByID(cid)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to find an active client with id=%q.", cid), err)
+		return
+	}
+	if client == nil {
+		al.jsonErrorResponseWithErrCode(w, http.StatusNotFound, errors2.ErrCodeClientNotActive, fmt.Sprintf("Active client with id=%q not found.", cid))
+		return
+	}
+	if client.IsPaused() {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("failed to start packet capture for client with id %s due to client being paused (reason = %s)", client.GetID(), client.GetPausedReason()))
+		return
+	}
+	if client.IsCheckInOnly() {
+		al.jsonErrorResponseWithErrCode(w, http.StatusConflict, errors2.ErrCodeClientCheckInOnly, fmt.Sprintf("failed to start packet capture for client with id %s: %v", client.GetID(), ErrClientCheckInOnly))
+		return
+	}
+	if err := client.CheckQuarantine(); err != nil {
+		al.jsonErrorResponseWithErrCode(w, http.StatusConflict, errors2.ErrCodeClientQuarantined, fmt.Sprintf("failed to start packet capture for client with id %s: %v", client.GetID(), err))
+		return
+	}
+
+	jid, err := generateNewJobID()
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	captureReq.Job = models.Job{
+		JID:        jid,
+		ClientID:   cid,
+		ClientName: client.GetName(),
+		Command:    fmt.Sprintf("tcpdump -i %s %s", captureReq.Interface, captureReq.Filter),
+		CreatedBy:  api.GetUser(req.Context(), al.Logger),
+		TimeoutSec: int((captureReq.MaxDuration + captureTimeoutMargin).Seconds()),
+		IsCapture:  true,
+
+		CorrelationID: api.GetRequestID(req.Context()),
+	}
+
+	// the capture must exist before the client starts to send data
+	err = al.captures.Create(jid, cid, captureReq.MaxBytes)
+	if err != nil {
+		if errors.Is(err, capture.ErrQuotaExceeded) {
+			al.jsonErrorResponseWithTitle(w, http.StatusInsufficientStorage, err.Error())
+			return
+		}
+		al.jsonError(w, err)
+		return
+	}
+
+	sshResp := &comm.RunCmdResponse{}
+	err = comm.SendRequestAndGetResponse(client.GetConnection(), comm.RequestTypeCapture, captureReq, sshResp, al.Log())
+	if err != nil {
+		al.captures.Abort(jid)
+		if _, ok := err.(*comm.ClientError); ok {
+			al.jsonErrorResponseWithTitle(w, http.StatusConflict, err.Error())
+		} else {
+			al.jsonErrorResponseWithError(w, http.StatusInternalServerError, "Failed to start packet capture.", err)
+		}
+		return
+	}
+
+	curJob := captureReq.Job
+	curJob.PID = &sshResp.Pid
+	curJob.StartedAt = sshResp.StartedAt
+	curJob.Status = models.JobStatusRunning
+	if err := al.jobProvider.CreateJob(&curJob); err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, "Failed to persist a new job.", err)
+		return
+	}
+
+	resp := &newJobResponse{
+		JID: curJob.JID,
+	}
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(resp))
+
+	al.auditLog.Entry(auditlog.ApplicationClientCapture, auditlog.ActionExecuteStart).
+		WithHTTPRequest(req).
+		WithClientID(cid).
+		WithRequest(reqBody).
+		WithResponse(resp).
+		WithID(resp.JID).
+		Save()
+
+	al.Debugf("Job[id=%q] created to capture packets on client with id=%q: %q.", curJob.JID, cid, curJob.Command)
+}
+
+// handleGetCapture handles GET /clients/{client_id}/captures/{job_id}
+func (al *APIListener) handleGetCapture(w http.ResponseWriter, req *http.Request) {
+	if al.captures == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, "Packet capture is disabled.")
+		return
+	}
+
+	job := al.getCaptureJob(w, req)
+	if job == nil {
+		return
+	}
+
+	f, err := al.captures.Open(job.JID)
+	if err != nil {
+		if errors.Is(err, capture.ErrNotFound) {
+			al.jsonErrorResponseWithTitle(w, http.StatusNotFound, err.Error())
+			return
+		}
+		al.jsonError(w, err)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationClientCapture, auditlog.ActionRequest).
+		WithHTTPRequest(req).
+		WithClientID(job.ClientID).
+		WithID(job.JID).
+		Save()
+
+	w.Header().Set("Content-Type", capture.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", job.ClientID+"-"+job.JID+".pcap"))
+	http.ServeContent(w, req, "", info.ModTime(), f)
+}
+
+// handleDeleteCapture handles DELETE /clients/{client_id}/captures/{job_id}
+func (al *APIListener) handleDeleteCapture(w http.ResponseWriter, req *http.Request) {
+	if al.captures == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, "Packet capture is disabled.")
+		return
+	}
+
+	job := al.getCaptureJob(w, req)
+	if job == nil {
+		return
+	}
+
+	err := al.captures.Delete(job.JID)
+	if err != nil {
+		if errors.Is(err, capture.ErrNotFound) {
+			al.jsonErrorResponseWithTitle(w, http.StatusNotFound, err.Error())
+			return
+		}
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationClientCapture, auditlog.ActionDelete).
+		WithHTTPRequest(req).
+		WithClientID(job.ClientID).
+		WithID(job.JID).
+		Save()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getCaptureJob returns the packet capture job of the request or writes the error response and returns nil.
+func (al *APIListener) getCaptureJob(w http.ResponseWriter, req *http.Request) *models.Job {
+	vars := mux.Vars(req)
+	cid := vars[routes.ParamClientID]
+	jid := vars[routes.ParamJobID]
+
+	job, err := al.jobProvider.GetByJID(cid, jid)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to find a job[id=%q].", jid), err)
+		return nil
+	}
+	if job == nil || !job.IsCapture {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("Packet capture job[id=%q] not found.", jid))
+		return nil
+	}
+	return job
+}
@@ -0,0 +1,88 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/bandwidth"
+	"github.com/realvnc-labs/rport/server/clientactivity"
+	"github.com/realvnc-labs/rport/server/routes"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+const (
+	clientActivitySinceQueryParam = "since"
+	clientActivityMaxSince        = 90 * 24 * time.Hour
+)
+
+// handleGetClientActivity handles GET /clients/{client_id}/activity, it summarizes per user the tunnels opened,
+// commands and scripts run and files transferred on the client within the given period.
+func (al *APIListener) handleGetClientActivity(w http.ResponseWriter, req *http.Request) {
+	clientID := mux.Vars(req)[routes.ParamClientID]
+	client, err := al.clientService.GetByID(clientID)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if client == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("client with id %q not found", clientID))
+		return
+	}
+
+	since, ok := al.parseDurationQueryParam(w, req, clientActivitySinceQueryParam, 7*24*time.Hour)
+	if !ok {
+		return
+	}
+	if since > clientActivityMaxSince {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s: at most %s is allowed.", clientActivitySinceQueryParam, clientActivityMaxSince))
+		return
+	}
+
+	until := time.Now()
+	from := until.Add(-since)
+	report := clientactivity.NewReport(clientID, from, until)
+
+	if al.auditLog.Enabled() {
+		entries, err := al.auditLog.ListClientEntries(req.Context(), clientID, from, clientactivity.MaxEntries)
+		if err != nil {
+			al.jsonErrorResponseWithError(w, http.StatusInternalServerError, "Failed to get audit log entries.", err)
+			return
+		}
+		report.AddAuditEntries(entries)
+	}
+
+	jobs, err := al.jobProvider.List(req.Context(), &query.ListOptions{
+		Filters: []query.FilterOption{
+			{Column: []string{"client_id"}, Values: []string{clientID}},
+			{Column: []string{"jobs.started_at"}, Operator: query.FilterOperatorTypeSince, Values: []string{from.UTC().Format("2006-01-02 15:04:05")}},
+		},
+		Sorts:      []query.SortOption{{Column: "jobs.started_at", IsASC: false}},
+		Pagination: query.NewPagination(clientactivity.MaxEntries, 0),
+	})
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get client jobs: client_id=%q.", clientID), err)
+		return
+	}
+	report.AddJobs(jobs)
+
+	if al.bandwidth != nil {
+		usages, err := al.bandwidth.List(req.Context(), from.UTC().Format(bandwidth.DayLayout), until.UTC().Format(bandwidth.DayLayout))
+		if err != nil {
+			al.jsonErrorResponseWithError(w, http.StatusInternalServerError, "Failed to get tunnel traffic.", err)
+			return
+		}
+		report.AddTunnelTraffic(usages)
+	}
+
+	var owners []string
+	for _, t := range client.GetTunnels() {
+		owners = append(owners, t.Owner)
+	}
+	report.AddActiveTunnels(owners)
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(report.Finish()))
+}
@@ -0,0 +1,87 @@
+package chserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clientactivity"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func TestHandleGetClientActivity(t *testing.T) {
+	jp := makeJobsProvider(t, DataSourceOptions, testLog)
+	defer jp.Close()
+
+	now := time.Now().UTC()
+	for _, job := range []*models.Job{
+		{JID: "job-1", Status: models.JobStatusSuccessful, ClientID: "client-1", CreatedBy: "alice", StartedAt: now.Add(-time.Hour)},
+		{JID: "job-2", Status: models.JobStatusFailed, ClientID: "client-1", CreatedBy: "alice", StartedAt: now.Add(-2 * time.Hour), IsScript: true},
+		{JID: "job-3", Status: models.JobStatusSuccessful, ClientID: "client-1", CreatedBy: "bob", StartedAt: now.Add(-30 * 24 * time.Hour)},
+		{JID: "job-4", Status: models.JobStatusSuccessful, ClientID: "client-2", CreatedBy: "bob", StartedAt: now.Add(-time.Hour)},
+	} {
+		require.NoError(t, jp.SaveJob(job))
+	}
+
+	c1 := clients.New(t).ID("client-1").Logger(testLog).Build()
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+			clientService: clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil),
+			jobProvider:   jp,
+		},
+		userService: users.NewAPIService(users.NewStaticProvider([]*users.User{
+			{Username: "admin", Groups: []string{users.Administrators}},
+		}), false, 0, -1),
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	do := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = req.WithContext(api.WithUser(req.Context(), "admin"))
+		al.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("/api/v1/clients/client-1/activity")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data clientactivity.Report `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "client-1", resp.Data.ClientID)
+	assert.False(t, resp.Data.AuditLogEnabled)
+	require.Len(t, resp.Data.Users, 1)
+	assert.Equal(t, "alice", resp.Data.Users[0].Username)
+	assert.Equal(t, 1, resp.Data.Users[0].CommandsRun)
+	assert.Equal(t, 1, resp.Data.Users[0].ScriptsRun)
+	assert.Equal(t, 1, resp.Data.Users[0].JobsFailed)
+
+	w = do("/api/v1/clients/client-1/activity?since=960h")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"username":"bob"`)
+
+	w = do("/api/v1/clients/client-1/activity?since=abc")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = do("/api/v1/clients/client-1/activity?since=3000h")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = do("/api/v1/clients/unknown/activity")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,29 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/routes"
+)
+
+// handleGetClientAddressChanges handles GET /clients/{client_id}/address-changes
+// It returns the latest changes of the ipv4 and ipv6 addresses reported by the client, the latest first.
+func (al *APIListener) handleGetClientAddressChanges(w http.ResponseWriter, req *http.Request) {
+	clientID := mux.Vars(req)[routes.ParamClientID]
+
+	client, err := al.clientService.GetByID(clientID)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if client == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("client with id %q not found", clientID))
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(client.GetAddressChanges()))
+}
@@ -0,0 +1,52 @@
+package chserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+)
+
+func TestHandleGetClientAddressChanges(t *testing.T) {
+	c1 := clients.New(t).Logger(testLog).Build()
+	c1.SetAddresses(clientdata.AddressChangeSourceHeartbeat, []string{"10.0.0.2"}, nil)
+	c1.SetAddresses(clientdata.AddressChangeSourceHeartbeat, []string{"10.0.0.3"}, nil)
+	clientService := clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil)
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			clientService: clientService,
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+		},
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	w := httptest.NewRecorder()
+	al.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/clients/%s/address-changes", c1.GetID()), nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var res struct {
+		Data []clientdata.AddressChange `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Len(t, res.Data, 2)
+	assert.Equal(t, []string{"10.0.0.3"}, res.Data[0].Added)
+	assert.Equal(t, []string{"10.0.0.2"}, res.Data[0].Removed)
+	assert.Equal(t, clientdata.AddressChangeSourceHeartbeat, res.Data[0].Source)
+
+	w = httptest.NewRecorder()
+	al.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/clients/unknown/address-changes", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/realvnc-labs/rport/server/api"
+	errors2 "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/auditlog"
 	"github.com/realvnc-labs/rport/server/clients/clientdata"
 	"github.com/realvnc-labs/rport/server/routes"
 	"github.com/realvnc-labs/rport/share/comm"
@@ -70,6 +72,12 @@ func (al *APIListener) handleUpdateClientAttributes(w http.ResponseWriter, req *
 		return
 	}
 
+	attributes.Tags, err = al.clientTags.Validate(attributes.Tags, client.GetClientAuthID())
+	if err != nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	sshResp := &Resp{}
 	err = comm.SendRequestAndGetResponse(client.GetConnection(), comm.RequestTypeUpdateClientAttributes, attributes, sshResp, al.Log())
 	if err != nil {
@@ -83,6 +91,12 @@ func (al *APIListener) handleUpdateClientAttributes(w http.ResponseWriter, req *
 
 	client.SetAttributes(attributes)
 
+	al.auditLog.Entry(auditlog.ApplicationClientAttributes, auditlog.ActionUpdate).
+		WithHTTPRequest(req).
+		WithClient(client).
+		WithRequest(attributes).
+		Save()
+
 	err = al.clientService.GetRepo().Save(client)
 	if err != nil {
 		al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload("client attributes updated, error saving changes to local db, changes will be visible after next client connection"))
@@ -107,7 +121,7 @@ func (al *APIListener) withActiveClient(next http.Handler) http.Handler {
 			return
 		}
 		if client == nil {
-			al.jsonErrorResponseWithTitle(writer, http.StatusNotFound, fmt.Sprintf("Active client with id=%q not found.", cid))
+			al.jsonErrorResponseWithErrCode(writer, http.StatusNotFound, errors2.ErrCodeClientNotActive, fmt.Sprintf("Active client with id=%q not found.", cid))
 			return
 		}
 
@@ -0,0 +1,33 @@
+package chserver
+
+import (
+	"net/http"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+// handleGetClientChanges handles GET /client-changes
+func (al *APIListener) handleGetClientChanges(w http.ResponseWriter, req *http.Request) {
+	options := query.NewOptions(req, clients.ChangesListDefaultSort, nil, nil)
+	err := query.ValidateListOptions(options, clients.ChangesSupportedSorts, clients.ChangesSupportedFilters, nil, &query.PaginationConfig{
+		DefaultLimit: 100,
+		MaxLimit:     1000,
+	})
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	changes, count, err := al.clientService.GetRepo().ListChanges(req.Context(), options)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, &api.SuccessPayload{
+		Data: changes,
+		Meta: api.NewMeta(count),
+	})
+}
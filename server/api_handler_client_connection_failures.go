@@ -0,0 +1,64 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/clientsauth"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+const clientConnectionFailuresSinceQueryParam = "since"
+
+var clientConnectionFailuresSupportedFilters = map[string]bool{
+	"client_auth_id": true,
+	"client_id":      true,
+	"ip":             true,
+	"reason":         true,
+}
+
+// handleListClientConnectionFailures handles GET /client-connection-failures
+// It returns the failed connection attempts of clients, the latest first.
+func (al *APIListener) handleListClientConnectionFailures(w http.ResponseWriter, req *http.Request) {
+	options := query.GetListOptions(req)
+	err := query.ValidateListOptions(options, nil /* sorts */, clientConnectionFailuresSupportedFilters, nil /* fields */, &query.PaginationConfig{
+		MaxLimit:     500,
+		DefaultLimit: 50,
+	})
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	since, ok := al.parseDurationQueryParam(w, req, clientConnectionFailuresSinceQueryParam, clientsauth.HandshakeFailuresRetention)
+	if !ok {
+		return
+	}
+	if since > clientsauth.HandshakeFailuresRetention {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s: failures are kept for %s only.", clientConnectionFailuresSinceQueryParam, clientsauth.HandshakeFailuresRetention))
+		return
+	}
+
+	failures := al.handshakeFailures.List(time.Now().Add(-since))
+	filtered := make([]clientsauth.HandshakeFailure, 0, len(failures))
+	for _, f := range failures {
+		matches, err := query.MatchesFilters(f, options.Filters)
+		if err != nil {
+			al.jsonError(w, err)
+			return
+		}
+		if matches {
+			filtered = append(filtered, f)
+		}
+	}
+
+	totalCount := len(filtered)
+	start, end := options.Pagination.GetStartEnd(totalCount)
+
+	al.writeJSONResponse(w, http.StatusOK, &api.SuccessPayload{
+		Data: filtered[start:end],
+		Meta: api.NewMeta(totalCount),
+	})
+}
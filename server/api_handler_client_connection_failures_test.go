@@ -0,0 +1,112 @@
+package chserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clientsauth"
+	"github.com/realvnc-labs/rport/server/clientversion"
+)
+
+func TestHandleListClientConnectionFailures(t *testing.T) {
+	failures := clientsauth.NewHandshakeFailures()
+	failures.Add(clientsauth.HandshakeFailure{ClientAuthID: "site-a", IP: "192.0.2.1", Reason: clientsauth.HandshakeInvalidPassword})
+	failures.Add(clientsauth.HandshakeFailure{IP: "192.0.2.2", Reason: clientsauth.HandshakeClosedByClient, Error: "unexpected EOF"})
+	failures.Add(clientsauth.HandshakeFailure{ClientAuthID: "site-a", ClientID: "web1", IP: "192.0.2.3", Reason: clientsauth.HandshakeClientIDConflict})
+
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			config:            &chconfig.Config{},
+			handshakeFailures: failures,
+		},
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	testCases := []struct {
+		name        string
+		query       string
+		wantStatus  int
+		wantReasons []clientsauth.HandshakeFailureReason
+		wantCount   int
+	}{
+		{
+			name:       "all",
+			wantStatus: http.StatusOK,
+			wantReasons: []clientsauth.HandshakeFailureReason{
+				clientsauth.HandshakeClientIDConflict,
+				clientsauth.HandshakeClosedByClient,
+				clientsauth.HandshakeInvalidPassword,
+			},
+			wantCount: 3,
+		},
+		{
+			name:        "filter by client auth and paginate",
+			query:       "filter[client_auth_id]=site-a&page[limit]=1",
+			wantStatus:  http.StatusOK,
+			wantReasons: []clientsauth.HandshakeFailureReason{clientsauth.HandshakeClientIDConflict},
+			wantCount:   2,
+		},
+		{
+			name:        "filter by reason",
+			query:       "filter[reason]=closed_by_client",
+			wantStatus:  http.StatusOK,
+			wantReasons: []clientsauth.HandshakeFailureReason{clientsauth.HandshakeClosedByClient},
+			wantCount:   1,
+		},
+		{
+			name:       "unsupported filter",
+			query:      "filter[error]=EOF",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "since exceeds retention",
+			query:      "since=48h",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/client-connection-failures?"+tc.query, nil)
+			w := httptest.NewRecorder()
+			al.router.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantStatus, w.Code, w.Body.String())
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+
+			var gotResp struct {
+				Data []clientsauth.HandshakeFailure `json:"data"`
+				Meta struct {
+					Count int `json:"count"`
+				} `json:"meta"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &gotResp))
+			var gotReasons []clientsauth.HandshakeFailureReason
+			for _, f := range gotResp.Data {
+				gotReasons = append(gotReasons, f.Reason)
+			}
+			assert.Equal(t, tc.wantReasons, gotReasons)
+			assert.Equal(t, tc.wantCount, gotResp.Meta.Count)
+		})
+	}
+}
+
+func TestStartClientFailureReason(t *testing.T) {
+	assert.Equal(t, clientsauth.HandshakeClientIDConflict, startClientFailureReason(fmt.Errorf("%w: web1 [web1]", clients.ErrClientAlreadyConnected)))
+	assert.Equal(t, clientsauth.HandshakeClientAuthInUse, startClientFailureReason(fmt.Errorf("%w: %q", clients.ErrClientAuthIDInUse, "site-a")))
+	assert.Equal(t, clientsauth.HandshakeVersionRejected, startClientFailureReason(&clientversion.OutdatedError{Version: "0.8.0", MinVersion: "0.9.0"}))
+	assert.Equal(t, clientsauth.HandshakeRejected, startClientFailureReason(errors.New("rejected by pre-connect script")))
+}
@@ -0,0 +1,48 @@
+package chserver
+
+import (
+	"net/http"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+// handleGetClientDuplicates handles GET /client-duplicates and returns the clients the user has access to
+// sharing the same hostname or machine serial.
+func (al *APIListener) handleGetClientDuplicates(w http.ResponseWriter, req *http.Request) {
+	options := query.GetListOptions(req)
+	errs := query.ValidateListOptions(options, nil /* sorts */, nil /* filters */, nil /* fields */, &query.PaginationConfig{
+		MaxLimit:     500,
+		DefaultLimit: 50,
+	})
+	if errs != nil {
+		al.jsonError(w, errs)
+		return
+	}
+
+	curUser, err := al.getUserModelForAuth(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	groups, err := al.clientGroupProvider.GetAll(req.Context())
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, "Failed to get client groups.", err)
+		return
+	}
+
+	duplicates := clients.FindDuplicates(al.clientService.GetUserClients(groups, curUser), al.config.Server.DuplicateClientsSerialLabel)
+	if duplicates == nil {
+		duplicates = []clients.Duplicate{}
+	}
+
+	totalCount := len(duplicates)
+	start, end := options.Pagination.GetStartEnd(totalCount)
+
+	al.writeJSONResponse(w, http.StatusOK, &api.SuccessPayload{
+		Data: duplicates[start:end],
+		Meta: api.NewMeta(totalCount),
+	})
+}
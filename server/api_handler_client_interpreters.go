@@ -0,0 +1,81 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	errors2 "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/routes"
+	"github.com/realvnc-labs/rport/share/comm"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+// handleGetClientInterpreters handles GET /clients/{client_id}/interpreters
+// It returns the interpreters reported by the client on the last connect or refresh, null if not reported.
+func (al *APIListener) handleGetClientInterpreters(w http.ResponseWriter, req *http.Request) {
+	clientID := mux.Vars(req)[routes.ParamClientID]
+
+	client, err := al.clientService.GetByID(clientID)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if client == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("client with id %q not found", clientID))
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(client.GetInterpreters()))
+}
+
+// handleRefreshClientInterpreters handles POST /clients/{client_id}/interpreters
+// It queries the interpreters from the client, stores and returns them.
+func (al *APIListener) handleRefreshClientInterpreters(w http.ResponseWriter, req *http.Request) {
+	client, err := al.getClientFromContext(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	var interpreters []models.Interpreter
+	err = comm.SendRequestAndGetResponse(client.GetConnection(), comm.RequestTypeGetInterpreters, nil, &interpreters, al.Log())
+	if err != nil {
+		if _, ok := err.(*comm.ClientError); ok {
+			al.jsonErrorResponseWithTitle(w, http.StatusConflict, err.Error())
+		} else {
+			al.jsonErrorResponseWithError(w, http.StatusInternalServerError, "Failed to get the client interpreters.", err)
+		}
+		return
+	}
+
+	err = al.clientService.SetInterpreters(client.GetID(), interpreters)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(interpreters))
+}
+
+// checkClientsInterpreter returns an error if the interpreter of a job is not found on all given clients.
+func checkClientsInterpreter(interpreter string, clients []*clientdata.Client) error {
+	var missing []string
+	for _, client := range clients {
+		if !client.HasInterpreter(interpreter) {
+			missing = append(missing, client.GetID())
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return errors2.APIError{
+		Message:    fmt.Sprintf("Interpreter %q is not available on clients: %s.", interpreter, strings.Join(missing, ", ")),
+		HTTPStatus: http.StatusBadRequest,
+	}
+}
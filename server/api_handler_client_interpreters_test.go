@@ -0,0 +1,112 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/share/comm"
+	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/test"
+)
+
+func TestHandleClientInterpreters(t *testing.T) {
+	c1 := clients.New(t).Logger(testLog).Build()
+	connMock := test.NewConnMock()
+	connMock.ReturnOk = true
+	connMock.ReturnResponsePayload = []byte(`[{"name":"bash","path":"/usr/bin/bash","version":"5.1.16","alias":false}]`)
+	c1.SetConnection(connMock)
+	clientService := clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil)
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			clientService: clientService,
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+		},
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	url := fmt.Sprintf("/api/v1/clients/%s/interpreters", c1.GetID())
+
+	// not reported yet
+	w := httptest.NewRecorder()
+	al.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":null}`, w.Body.String())
+
+	// refresh
+	w = httptest.NewRecorder()
+	al.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, url, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	expectedJSON := `{"data":[{"name":"bash","path":"/usr/bin/bash","version":"5.1.16","alias":false}]}`
+	assert.JSONEq(t, expectedJSON, w.Body.String())
+	name, _, _ := connMock.InputSendRequest()
+	assert.Equal(t, comm.RequestTypeGetInterpreters, name)
+
+	w = httptest.NewRecorder()
+	al.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, expectedJSON, w.Body.String())
+}
+
+func TestCheckClientsInterpreter(t *testing.T) {
+	c1 := clients.New(t).ID("client-1").Logger(testLog).Build()
+	c1.SetInterpreters([]models.Interpreter{{Name: "bash", Path: "/usr/bin/bash"}, {Name: "python3", Path: "/usr/bin/python3"}})
+	c2 := clients.New(t).ID("client-2").Logger(testLog).Build()
+	c2.SetInterpreters([]models.Interpreter{{Name: "bash", Path: "/bin/bash"}})
+	// interpreters not reported
+	c3 := clients.New(t).ID("client-3").Logger(testLog).Build()
+	targets := []*clientdata.Client{c1, c2, c3}
+
+	assert.NoError(t, checkClientsInterpreter("", targets))
+	assert.NoError(t, checkClientsInterpreter("bash", targets))
+	assert.NoError(t, checkClientsInterpreter("/usr/bin/bash", targets))
+
+	err := checkClientsInterpreter("python3", targets)
+	require.Error(t, err)
+	assert.Equal(t, `Interpreter "python3" is not available on clients: client-2.`, err.Error())
+}
+
+func TestHandlePostCommandUnavailableInterpreter(t *testing.T) {
+	c1 := clients.New(t).Logger(testLog).Build()
+	c1.SetInterpreters([]models.Interpreter{{Name: "cmd", Path: `C:\Windows\System32\cmd.exe`}})
+	connMock := test.NewConnMock()
+	connMock.ReturnOk = true
+	c1.SetConnection(connMock)
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			clientService: clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil),
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+		},
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	body := `{"command": "Get-Date", "interpreter": "powershell"}`
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/clients/%s/commands", c1.GetID()), strings.NewReader(body))
+	w := httptest.NewRecorder()
+	al.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), fmt.Sprintf(`Interpreter \"powershell\" is not available on clients: %s.`, c1.GetID()))
+	name, _, _ := connMock.InputSendRequest()
+	assert.Empty(t, name)
+}
@@ -0,0 +1,60 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/share/clientconfig"
+	"github.com/realvnc-labs/rport/share/comm"
+)
+
+type clientModeRequest struct {
+	Mode string `json:"mode"`
+}
+
+// handlePutClientMode handles PUT /clients/{client_id}/mode
+func (al *APIListener) handlePutClientMode(w http.ResponseWriter, req *http.Request) {
+	var reqBody clientModeRequest
+	err := parseRequestBody(req.Body, &reqBody)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if reqBody.Mode != clientconfig.ModeFull && reqBody.Mode != clientconfig.ModeCheckIn {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest,
+			fmt.Sprintf("Invalid mode %q, expected one of: %s, %s.", reqBody.Mode, clientconfig.ModeFull, clientconfig.ModeCheckIn))
+		return
+	}
+
+	client, err := al.getClientFromContext(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	err = comm.SendRequestAndGetResponse(client.GetConnection(), comm.RequestTypeSetMode, comm.SetModeRequest{Mode: reqBody.Mode}, nil, al.Log())
+	if err != nil {
+		if _, ok := err.(*comm.ClientError); ok {
+			al.jsonErrorResponseWithTitle(w, http.StatusConflict, err.Error())
+		} else {
+			al.jsonErrorResponseWithError(w, http.StatusInternalServerError, "Failed to switch the client mode.", err)
+		}
+		return
+	}
+
+	client.SetMode(reqBody.Mode)
+	err = al.clientService.GetRepo().Save(client)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationClient, auditlog.ActionUpdate).
+		WithHTTPRequest(req).
+		WithID(client.GetID()).
+		WithRequest(reqBody).
+		Save()
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,157 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/share/clientconfig"
+	"github.com/realvnc-labs/rport/share/comm"
+	"github.com/realvnc-labs/rport/share/test"
+)
+
+func TestHandlePutClientMode(t *testing.T) {
+	testCases := []struct {
+		Name            string
+		Body            string
+		SSHError        bool
+		ExpectedStatus  int
+		ExpectedMode    string
+		ExpectedPayload string
+	}{
+		{
+			Name:            "switch to full mode",
+			Body:            `{"mode":"full"}`,
+			ExpectedStatus:  http.StatusNoContent,
+			ExpectedMode:    clientconfig.ModeFull,
+			ExpectedPayload: `{"Mode":"full"}`,
+		},
+		{
+			Name:            "switch to check-in only mode",
+			Body:            `{"mode":"checkin"}`,
+			ExpectedStatus:  http.StatusNoContent,
+			ExpectedMode:    clientconfig.ModeCheckIn,
+			ExpectedPayload: `{"Mode":"checkin"}`,
+		},
+		{
+			Name:           "invalid mode",
+			Body:           `{"mode":"light"}`,
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedMode:   clientconfig.ModeCheckIn,
+		},
+		{
+			Name:            "client rejects mode",
+			Body:            `{"mode":"full"}`,
+			SSHError:        true,
+			ExpectedStatus:  http.StatusConflict,
+			ExpectedMode:    clientconfig.ModeCheckIn,
+			ExpectedPayload: `{"Mode":"full"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			c1 := clients.New(t).Logger(testLog).Build()
+			c1.SetMode(clientconfig.ModeCheckIn)
+			connMock := test.NewConnMock()
+			connMock.ReturnOk = !tc.SSHError
+			c1.SetConnection(connMock)
+			clientService := clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil)
+			al := APIListener{
+				insecureForTests: true,
+				Server: &Server{
+					clientService: clientService,
+					config: &chconfig.Config{
+						API: chconfig.APIConfig{
+							MaxRequestBytes: 1024 * 1024,
+						},
+					},
+				},
+				Logger: testLog,
+			}
+			al.initRouter()
+
+			req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/clients/%s/mode", c1.GetID()), strings.NewReader(tc.Body))
+
+			w := httptest.NewRecorder()
+			al.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.ExpectedStatus, w.Code)
+			assert.Equal(t, tc.ExpectedMode, c1.GetMode())
+			name, _, payload := connMock.InputSendRequest()
+			if tc.ExpectedPayload != "" {
+				assert.Equal(t, comm.RequestTypeSetMode, name)
+				assert.Equal(t, tc.ExpectedPayload, string(payload))
+			} else {
+				assert.Empty(t, name)
+			}
+		})
+	}
+}
+
+func TestCheckInOnlyClientRejectsRequests(t *testing.T) {
+	c1 := clients.New(t).Logger(testLog).Build()
+	c1.SetMode(clientconfig.ModeCheckIn)
+	connMock := test.NewConnMock()
+	connMock.ReturnOk = true
+	c1.SetConnection(connMock)
+	clientService := clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil)
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			clientService: clientService,
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+		},
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	testCases := []struct {
+		Name   string
+		Method string
+		URL    string
+		Body   string
+	}{
+		{
+			Name:   "tunnel",
+			Method: http.MethodPut,
+			URL:    fmt.Sprintf("/api/v1/clients/%s/tunnels?remote=22", c1.GetID()),
+		},
+		{
+			Name:   "command",
+			Method: http.MethodPost,
+			URL:    fmt.Sprintf("/api/v1/clients/%s/commands", c1.GetID()),
+			Body:   `{"command":"/bin/date"}`,
+		},
+		{
+			Name:   "updates status",
+			Method: http.MethodPost,
+			URL:    fmt.Sprintf("/api/v1/clients/%s/updates-status", c1.GetID()),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.Method, tc.URL, strings.NewReader(tc.Body))
+
+			w := httptest.NewRecorder()
+			al.router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusConflict, w.Code)
+			assert.Contains(t, w.Body.String(), "client is in check-in only mode")
+			name, _, _ := connMock.InputSendRequest()
+			assert.Empty(t, name)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/routes"
+)
+
+type clientQuarantineRequest struct {
+	Reason string `json:"reason"`
+}
+
+// handlePutClientQuarantine handles PUT /clients/{client_id}/quarantine
+func (al *APIListener) handlePutClientQuarantine(w http.ResponseWriter, req *http.Request) {
+	cid := mux.Vars(req)[routes.ParamClientID]
+	if cid == "" {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Missing %q route param.", routes.ParamClientID))
+		return
+	}
+
+	var reqBody clientQuarantineRequest
+	err := parseRequestBody(req.Body, &reqBody)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	reqBody.Reason = strings.TrimSpace(reqBody.Reason)
+	if reqBody.Reason == "" {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, "Missing quarantine reason.")
+		return
+	}
+
+	curUser, err := al.getUserModelForAuth(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	client, err := al.clientService.Quarantine(cid, reqBody.Reason, curUser.Username)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationClientQuarantine, auditlog.ActionCreate).
+		WithHTTPRequest(req).
+		WithClient(client).
+		WithRequest(reqBody).
+		Save()
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(client.GetQuarantine()))
+}
+
+// handleDeleteClientQuarantine handles DELETE /clients/{client_id}/quarantine
+func (al *APIListener) handleDeleteClientQuarantine(w http.ResponseWriter, req *http.Request) {
+	cid := mux.Vars(req)[routes.ParamClientID]
+	if cid == "" {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Missing %q route param.", routes.ParamClientID))
+		return
+	}
+
+	client, err := al.clientService.Release(cid)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationClientQuarantine, auditlog.ActionDelete).
+		WithHTTPRequest(req).
+		WithClient(client).
+		Save()
+
+	w.WriteHeader(http.StatusNoContent)
+}
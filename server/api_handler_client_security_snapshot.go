@@ -0,0 +1,35 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/routes"
+)
+
+// handleGetClientSecuritySnapshot handles GET /clients/{client_id}/security-snapshot
+// It returns the latest security snapshot reported by the client, also for disconnected clients.
+func (al *APIListener) handleGetClientSecuritySnapshot(w http.ResponseWriter, req *http.Request) {
+	clientID := mux.Vars(req)[routes.ParamClientID]
+
+	client, err := al.clientService.GetByID(clientID)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if client == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("client with id %q not found", clientID))
+		return
+	}
+
+	snapshot := client.GetSecuritySnapshot()
+	if snapshot == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("client with id %q did not report a security snapshot", clientID))
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(snapshot))
+}
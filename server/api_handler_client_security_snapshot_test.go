@@ -0,0 +1,65 @@
+package chserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func TestHandleGetClientSecuritySnapshot(t *testing.T) {
+	snapshot := &models.SecuritySnapshot{
+		Refreshed: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC),
+		LoggedInUsers: []models.UserLogin{
+			{User: "root", Terminal: "pts/0", Host: "10.0.0.1", Started: time.Date(2022, 1, 1, 9, 0, 0, 0, time.UTC)},
+		},
+		SSHD: &models.SSHDSummary{
+			ConfigFile:      "/etc/ssh/sshd_config",
+			PermitRootLogin: "yes",
+		},
+	}
+	c1 := clients.New(t).ID("client-1").Logger(testLog).Build()
+	c2 := clients.New(t).ID("client-2").Logger(testLog).Build()
+	clientService := clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1, c2}, &hour, testLog), testLog, nil)
+	require.NoError(t, clientService.SetSecuritySnapshot(c1.GetID(), snapshot))
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			clientService: clientService,
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+		},
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	w := httptest.NewRecorder()
+	al.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/clients/%s/security-snapshot", c1.GetID()), nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var res struct {
+		Data *models.SecuritySnapshot `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, snapshot, res.Data)
+
+	w = httptest.NewRecorder()
+	al.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/clients/%s/security-snapshot", c2.GetID()), nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	al.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/clients/unknown/security-snapshot", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
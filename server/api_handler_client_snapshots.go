@@ -0,0 +1,89 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/clientsnapshot"
+	"github.com/realvnc-labs/rport/server/routes"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+// handleGetClientSnapshots handles GET /clients/{client_id}/snapshots
+func (al *APIListener) handleGetClientSnapshots(w http.ResponseWriter, req *http.Request) {
+	options := query.NewOptions(req, clientsnapshot.ListDefaultSort, nil, nil)
+	result, err := al.clientSnapshots.List(req.Context(), mux.Vars(req)[routes.ParamClientID], options)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, result)
+}
+
+// handlePostClientSnapshot handles POST /clients/{client_id}/snapshots
+func (al *APIListener) handlePostClientSnapshot(w http.ResponseWriter, req *http.Request) {
+	clientID := mux.Vars(req)[routes.ParamClientID]
+	snapshot, err := al.clientSnapshots.Take(req.Context(), clientID, clientsnapshot.TriggerManual)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationClientSnapshot, auditlog.ActionCreate).
+		WithHTTPRequest(req).
+		WithClientID(clientID).
+		WithID(snapshot.ID).
+		Save()
+
+	al.writeJSONResponse(w, http.StatusCreated, api.NewSuccessPayload(snapshot))
+}
+
+// handleGetClientSnapshot handles GET /clients/{client_id}/snapshots/{snapshot_id}
+func (al *APIListener) handleGetClientSnapshot(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	id, err := strconv.ParseInt(vars[routes.ParamSnapshotID], 10, 64)
+	if err != nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Invalid snapshot id %q.", vars[routes.ParamSnapshotID]))
+		return
+	}
+
+	snapshot, err := al.clientSnapshots.Get(req.Context(), vars[routes.ParamClientID], id)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(snapshot))
+}
+
+// handleGetClientSnapshotsDiff handles GET /clients/{client_id}/snapshots/diff?from={snapshot_id}&to={snapshot_id}
+func (al *APIListener) handleGetClientSnapshotsDiff(w http.ResponseWriter, req *http.Request) {
+	ids := make(map[string]int64, 2)
+	for _, param := range []string{"from", "to"} {
+		value := req.URL.Query().Get(param)
+		if value == "" {
+			al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Missing query parameter %q.", param))
+			return
+		}
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Invalid snapshot id %q in query parameter %q.", value, param))
+			return
+		}
+		ids[param] = id
+	}
+
+	diff, err := al.clientSnapshots.Diff(req.Context(), mux.Vars(req)[routes.ParamClientID], ids["from"], ids["to"])
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(diff))
+}
@@ -0,0 +1,113 @@
+package chserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	clientsnapshotsmigration "github.com/realvnc-labs/rport/db/migration/client_snapshots"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/clientsnapshot"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func TestHandleClientSnapshots(t *testing.T) {
+	testUser := "admin"
+	c1 := clients.New(t).ID("client-1").Build()
+	clientService := clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil)
+
+	services := []models.Service{{Name: "nginx", State: "running"}}
+	source := newClientSnapshotSource(clientService, testLog)
+	source.getServices = func(client *clientdata.Client) ([]models.Service, error) {
+		return services, nil
+	}
+	db, err := sqlite.New(":memory:", clientsnapshotsmigration.AssetNames(), clientsnapshotsmigration.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	snapshots := clientsnapshot.NewService(clientsnapshot.NewSQLiteProvider(db), source, clientsnapshot.Config{}, testLog)
+	defer snapshots.Close()
+
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+			clientService:   clientService,
+			clientSnapshots: snapshots,
+		},
+		userService: users.NewAPIService(users.NewStaticProvider([]*users.User{{
+			Username: testUser,
+			Groups:   []string{users.Administrators},
+		}}), false, 0, -1),
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	ctx := api.WithUser(context.Background(), testUser)
+	do := func(method, url string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		al.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, "/api/v1/clients/client-1/snapshots")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	c1.SetHostname("web-2.example.com")
+	services = []models.Service{{Name: "nginx", State: "failed"}}
+	w = do(http.MethodPost, "/api/v1/clients/client-1/snapshots")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = do(http.MethodGet, "/api/v1/clients/client-1/snapshots")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Data []*clientsnapshot.Snapshot `json:"data"`
+		Meta *api.Meta                  `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Data, 2)
+	assert.Equal(t, int64(2), list.Data[0].ID)
+	assert.Equal(t, 2, list.Meta.Count)
+
+	w = do(http.MethodGet, "/api/v1/clients/client-1/snapshots/1")
+	require.Equal(t, http.StatusOK, w.Code)
+	var one struct {
+		Data *clientsnapshot.Snapshot `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &one))
+	assert.JSONEq(t, `"`+c1.GetOS()+`"`, string(one.Data.State.Inventory["os"]))
+	assert.Equal(t, []models.Service{{Name: "nginx", State: "running"}}, one.Data.State.Services)
+
+	w = do(http.MethodGet, "/api/v1/clients/client-1/snapshots/diff?from=1&to=2")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var diff struct {
+		Data *clientsnapshot.Diff `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &diff))
+	require.Len(t, diff.Data.Inventory, 1)
+	assert.Equal(t, "hostname", diff.Data.Inventory[0].Field)
+	assert.JSONEq(t, `"web-2.example.com"`, string(diff.Data.Inventory[0].To))
+	assert.Equal(t, []clientsnapshot.ServiceChange{{Name: "nginx", From: "running", To: "failed"}}, diff.Data.Services.Changed)
+
+	w = do(http.MethodGet, "/api/v1/clients/client-1/snapshots/diff?from=1")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = do(http.MethodGet, "/api/v1/clients/client-1/snapshots/diff?from=1&to=3")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = do(http.MethodGet, "/api/v1/clients/client-1/snapshots/abc")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
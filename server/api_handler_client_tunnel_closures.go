@@ -0,0 +1,29 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/routes"
+)
+
+// handleGetClientTunnelClosures handles GET /clients/{client_id}/tunnel-closures
+// It returns the latest tunnels closed by the server, e.g. due to the idle timeout, the latest first.
+func (al *APIListener) handleGetClientTunnelClosures(w http.ResponseWriter, req *http.Request) {
+	clientID := mux.Vars(req)[routes.ParamClientID]
+
+	client, err := al.clientService.GetByID(clientID)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if client == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("client with id %q not found", clientID))
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(client.GetTunnelClosures()))
+}
@@ -0,0 +1,53 @@
+package chserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+)
+
+func TestHandleGetClientTunnelClosures(t *testing.T) {
+	c1 := clients.New(t).Logger(testLog).Build()
+	c1.AddTunnelClosure(clientdata.TunnelClosure{Timestamp: time.Now(), TunnelID: "1", Reason: clientdata.TunnelCloseReasonAutoClose})
+	c1.AddTunnelClosure(clientdata.TunnelClosure{Timestamp: time.Now(), TunnelID: "2", Reason: clientdata.TunnelCloseReasonIdleTimeout})
+	clientService := clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil)
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			clientService: clientService,
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+		},
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	w := httptest.NewRecorder()
+	al.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/clients/%s/tunnel-closures", c1.GetID()), nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var res struct {
+		Data []clientdata.TunnelClosure `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Len(t, res.Data, 2)
+	assert.Equal(t, "2", res.Data[0].TunnelID)
+	assert.Equal(t, clientdata.TunnelCloseReasonIdleTimeout, res.Data[0].Reason)
+	assert.Equal(t, clientdata.TunnelCloseReasonAutoClose, res.Data[1].Reason)
+
+	w = httptest.NewRecorder()
+	al.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/clients/unknown/tunnel-closures", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,129 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/routes"
+	"github.com/realvnc-labs/rport/share/email"
+)
+
+type clientWatchRequest struct {
+	TTL        string   `json:"ttl"`
+	Recipients []string `json:"recipients"`
+}
+
+// handlePostClientWatch handles POST /clients/{client_id}/watches
+// It subscribes the current user to the next reconnect of an offline client.
+func (al *APIListener) handlePostClientWatch(w http.ResponseWriter, req *http.Request) {
+	clientID := mux.Vars(req)[routes.ParamClientID]
+	client, err := al.clientService.GetByID(clientID)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if client == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("client with id %q not found", clientID))
+		return
+	}
+	if client.IsConnected() {
+		al.jsonErrorResponseWithTitle(w, http.StatusConflict, fmt.Sprintf("client with id %q is connected", clientID))
+		return
+	}
+
+	var reqBody clientWatchRequest
+	err = parseRequestBody(req.Body, &reqBody)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	var ttl time.Duration
+	if reqBody.TTL != "" {
+		ttl, err = time.ParseDuration(reqBody.TTL)
+		if err != nil {
+			al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Invalid ttl %q.", reqBody.TTL))
+			return
+		}
+	}
+
+	curUser, err := al.getUserModelForAuth(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	recipients := reqBody.Recipients
+	if len(recipients) == 0 {
+		// fall back to the email the user receives 2fa tokens on
+		sendTo := strings.TrimPrefix(curUser.TwoFASendTo, "email:")
+		if email.Validate(sendTo) == nil {
+			recipients = []string{sendTo}
+		}
+	}
+
+	watch, err := al.clientWatches.Add(client.GetID(), client.GetName(), curUser.Username, recipients, ttl)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationClientWatch, auditlog.ActionCreate).
+		WithHTTPRequest(req).
+		WithClient(client).
+		WithID(watch.ID).
+		WithRequest(reqBody).
+		Save()
+
+	al.writeJSONResponse(w, http.StatusCreated, api.NewSuccessPayload(watch))
+}
+
+// handleGetClientWatches handles GET /client-watches
+// Admins can list the watches of all users with ?all=true.
+func (al *APIListener) handleGetClientWatches(w http.ResponseWriter, req *http.Request) {
+	curUser, err := al.getUserModelForAuth(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	username := curUser.Username
+	if req.URL.Query().Get("all") == "true" {
+		if !curUser.CanReadAll() {
+			al.jsonErrorResponseWithTitle(w, http.StatusForbidden, "Only admins and auditors can list the client watches of all users.")
+			return
+		}
+		username = ""
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(al.clientWatches.List(username)))
+}
+
+// handleDeleteClientWatch handles DELETE /client-watches/{watch_id}
+func (al *APIListener) handleDeleteClientWatch(w http.ResponseWriter, req *http.Request) {
+	curUser, err := al.getUserModelForAuth(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	watch, err := al.clientWatches.Delete(mux.Vars(req)[routes.ParamClientWatchID], curUser.Username, curUser.IsAdmin())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationClientWatch, auditlog.ActionDelete).
+		WithHTTPRequest(req).
+		WithClientID(watch.ClientID).
+		WithID(watch.ID).
+		Save()
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,124 @@
+package chserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/auditlog/config"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/clientwatch"
+)
+
+func TestHandleClientWatches(t *testing.T) {
+	connected := clients.New(t).ID("client-1").Logger(testLog).Build()
+	offline := clients.New(t).ID("client-2").DisconnectedDuration(time.Minute).Logger(testLog).Build()
+	clientService := clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{connected, offline}, &hour, testLog), testLog, nil)
+	auditLog, err := auditlog.New(testLog, clientService, t.TempDir(), config.Config{Enable: true}, sqlite.DataSourceOptions{}, "")
+	require.NoError(t, err)
+	defer auditLog.Close()
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			clientService: clientService,
+			clientWatches: clientwatch.NewService(testLog),
+			auditLog:      auditLog,
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+		},
+		userService: users.NewAPIService(users.NewStaticProvider([]*users.User{
+			{Username: "admin", Groups: []string{users.Administrators}},
+			{Username: "alice", Groups: []string{"ops"}, TwoFASendTo: "email:alice@example.com"},
+			{Username: "bob", Groups: []string{"ops"}, TwoFASendTo: "sms:+4915112345678"},
+		}), false, 0, -1),
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	do := func(method, url, username, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req = req.WithContext(api.WithUser(req.Context(), username))
+		al.router.ServeHTTP(w, req)
+		return w
+	}
+	watches := func(url, username string) []clientwatch.Watch {
+		w := do(http.MethodGet, url, username, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data []clientwatch.Watch `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Data
+	}
+
+	w := do(http.MethodPost, "/api/v1/clients/client-1/watches", "alice", `{}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = do(http.MethodPost, "/api/v1/clients/unknown/watches", "alice", `{}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = do(http.MethodPost, "/api/v1/clients/client-2/watches", "alice", `{"ttl": "soon"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// bob receives 2fa tokens via sms, so recipients are required
+	w = do(http.MethodPost, "/api/v1/clients/client-2/watches", "bob", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = do(http.MethodPost, "/api/v1/clients/client-2/watches", "bob", `{"recipients": ["bob@example.com"], "ttl": "2h"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w = do(http.MethodPost, "/api/v1/clients/client-2/watches", "alice", `{}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		Data clientwatch.Watch `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "client-2", created.Data.ClientID)
+	assert.Equal(t, "alice", created.Data.Username)
+	assert.Equal(t, []string{"alice@example.com"}, created.Data.Recipients)
+
+	aliceWatches := watches("/api/v1/client-watches", "alice")
+	require.Len(t, aliceWatches, 1)
+	assert.Equal(t, created.Data.ID, aliceWatches[0].ID)
+
+	w = do(http.MethodGet, "/api/v1/client-watches?all=true", "alice", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Len(t, watches("/api/v1/client-watches?all=true", "admin"), 2)
+
+	w = do(http.MethodDelete, "/api/v1/client-watches/"+created.Data.ID, "bob", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = do(http.MethodDelete, "/api/v1/client-watches/"+created.Data.ID, "alice", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, watches("/api/v1/client-watches", "alice"))
+
+	entries, err := auditLog.ListClientEntries(context.Background(), "client-2", time.Now().Add(-time.Hour), 10)
+	require.NoError(t, err)
+	var actions []string
+	for _, e := range entries {
+		assert.Equal(t, auditlog.ApplicationClientWatch, e.Application)
+		actions = append(actions, e.Username+" "+e.Action+" "+e.ID)
+	}
+	assert.ElementsMatch(t, []string{
+		"bob " + auditlog.ActionCreate + " " + watches("/api/v1/client-watches", "bob")[0].ID,
+		"alice " + auditlog.ActionCreate + " " + created.Data.ID,
+		"alice " + auditlog.ActionDelete + " " + created.Data.ID,
+	}, actions)
+}
@@ -1,6 +1,7 @@
 package chserver
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"github.com/realvnc-labs/rport/server/api"
 	"github.com/realvnc-labs/rport/server/auditlog"
 	"github.com/realvnc-labs/rport/server/cgroups"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
 	"github.com/realvnc-labs/rport/server/routes"
 	"github.com/realvnc-labs/rport/share/ptr"
 	"github.com/realvnc-labs/rport/share/query"
@@ -30,12 +32,22 @@ func (al *APIListener) handlePostClientGroups(w http.ResponseWriter, req *http.R
 		al.jsonErrorResponseWithError(w, http.StatusBadRequest, "Invalid client group.", err)
 		return
 	}
+	if err := al.validateClientGroupTunnelBind(group); err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusBadRequest, "Invalid client group.", err)
+		return
+	}
 
 	if err := al.clientGroupProvider.Create(req.Context(), &group); err != nil {
 		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, "Failed to persist a new client group.", err)
 		return
 	}
 
+	al.saveClientGroupHistory(req, &cgroups.HistoryEntry{
+		GroupID: group.ID,
+		Action:  cgroups.HistoryActionCreate,
+		After:   group.Definition(),
+	})
+
 	al.auditLog.Entry(auditlog.ApplicationClientGroup, auditlog.ActionCreate).
 		WithHTTPRequest(req).
 		WithRequest(group).
@@ -70,12 +82,33 @@ func (al *APIListener) handlePutClientGroup(w http.ResponseWriter, req *http.Req
 		al.jsonErrorResponseWithError(w, http.StatusBadRequest, "Invalid client group.", err)
 		return
 	}
+	if err := al.validateClientGroupTunnelBind(group); err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusBadRequest, "Invalid client group.", err)
+		return
+	}
+
+	existing, err := al.clientGroupProvider.Get(req.Context(), id)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to find client group[id=%q].", id), err)
+		return
+	}
 
 	if err := al.clientGroupProvider.Update(req.Context(), &group); err != nil {
 		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, "Failed to persist client group.", err)
 		return
 	}
 
+	entry := &cgroups.HistoryEntry{
+		GroupID: id,
+		Action:  cgroups.HistoryActionCreate,
+		After:   group.Definition(),
+	}
+	if existing != nil {
+		entry.Action = cgroups.HistoryActionUpdate
+		entry.Before = existing.Definition()
+	}
+	al.saveClientGroupHistory(req, entry)
+
 	al.auditLog.Entry(auditlog.ApplicationClientGroup, auditlog.ActionUpdate).
 		WithHTTPRequest(req).
 		WithRequest(group).
@@ -107,6 +140,12 @@ func validateInputClientGroup(group cgroups.ClientGroup) error {
 			return err
 		}
 	}
+	if err := group.Params.ValidateHealthParams(); err != nil {
+		return err
+	}
+	if len(group.Banner) > cgroups.MaxBannerLength {
+		return fmt.Errorf("invalid banner: max length %d, got %d", cgroups.MaxBannerLength, len(group.Banner))
+	}
 	return nil
 }
 
@@ -187,7 +226,7 @@ func (al *APIListener) handleGetClientGroups(w http.ResponseWriter, req *http.Re
 	al.clientService.PopulateGroupsWithUserClients(groups, curUser)
 
 	// for non-admins filter out groups with no clients
-	if !curUser.IsAdmin() {
+	if !curUser.CanReadAll() {
 		groups = filterEmptyGroups(groups)
 	}
 
@@ -224,12 +263,26 @@ func (al *APIListener) handleDeleteClientGroup(w http.ResponseWriter, req *http.
 		return
 	}
 
-	err := al.clientGroupProvider.Delete(req.Context(), id)
+	existing, err := al.clientGroupProvider.Get(req.Context(), id)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to find client group[id=%q].", id), err)
+		return
+	}
+
+	err = al.clientGroupProvider.Delete(req.Context(), id)
 	if err != nil {
 		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to delete client group[id=%q].", id), err)
 		return
 	}
 
+	if existing != nil {
+		al.saveClientGroupHistory(req, &cgroups.HistoryEntry{
+			GroupID: id,
+			Action:  cgroups.HistoryActionDelete,
+			Before:  existing.Definition(),
+		})
+	}
+
 	al.auditLog.Entry(auditlog.ApplicationClientGroup, auditlog.ActionDelete).
 		WithHTTPRequest(req).
 		WithID(id).
@@ -304,3 +357,18 @@ func (al *APIListener) countActiveClients(clientIDs []string) (int, error) {
 	}
 	return count, nil
 }
+
+// clientGroupIDs returns the ids of all client groups the client belongs to.
+func (al *APIListener) clientGroupIDs(ctx context.Context, client *clientdata.Client) ([]string, error) {
+	groups, err := al.clientGroupProvider.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var clientGroups []string
+	for _, group := range groups {
+		if client.BelongsTo(group) {
+			clientGroups = append(clientGroups, group.ID)
+		}
+	}
+	return clientGroups, nil
+}
@@ -0,0 +1,132 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	apiErrors "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/cgroups"
+	"github.com/realvnc-labs/rport/server/routes"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+// saveClientGroupHistory records a change of a client group made by the current user. The change itself is already
+// persisted, so failures are only logged.
+func (al *APIListener) saveClientGroupHistory(req *http.Request, entry *cgroups.HistoryEntry) {
+	entry.Timestamp = time.Now().UTC()
+	entry.Username = api.GetUser(req.Context(), al.Logger)
+	if err := al.clientGroupProvider.InsertHistory(req.Context(), entry); err != nil {
+		al.Errorf("Failed to save history of client group [id=%q]: %v", entry.GroupID, err)
+	}
+}
+
+// handleGetClientGroupHistory handles GET /client-groups/{group_id}/history
+func (al *APIListener) handleGetClientGroupHistory(w http.ResponseWriter, req *http.Request) {
+	options := query.NewOptions(req, cgroups.HistoryListDefaultSort, nil, nil)
+	err := query.ValidateListOptions(options, cgroups.HistorySupportedSorts, cgroups.HistorySupportedFilters, nil, &query.PaginationConfig{
+		DefaultLimit: 20,
+		MaxLimit:     100,
+	})
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	options.Filters = append(options.Filters, query.FilterOption{Column: []string{"group_id"}, Values: []string{mux.Vars(req)[routes.ParamGroupID]}})
+
+	entries, err := al.clientGroupProvider.ListHistory(req.Context(), options)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, "Failed to get client group history.", err)
+		return
+	}
+	count, err := al.clientGroupProvider.CountHistory(req.Context(), options)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, "Failed to get client group history.", err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, &api.SuccessPayload{
+		Data: entries,
+		Meta: api.NewMeta(count),
+	})
+}
+
+// handleGetClientGroupHistoryEntry handles GET /client-groups/{group_id}/history/{history_id}
+func (al *APIListener) handleGetClientGroupHistoryEntry(w http.ResponseWriter, req *http.Request) {
+	entry, err := al.getClientGroupHistoryEntry(req)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(entry))
+}
+
+// handlePostClientGroupRevert handles POST /client-groups/{group_id}/history/{history_id}/revert, it restores the
+// definition of the group as it was after the given change.
+func (al *APIListener) handlePostClientGroupRevert(w http.ResponseWriter, req *http.Request) {
+	entry, err := al.getClientGroupHistoryEntry(req)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if entry.After == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("History entry %d is a deletion of the client group, revert to an earlier entry.", entry.ID))
+		return
+	}
+
+	existing, err := al.clientGroupProvider.Get(req.Context(), entry.GroupID)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to find client group[id=%q].", entry.GroupID), err)
+		return
+	}
+
+	group := entry.After.ClientGroup(entry.GroupID)
+	if err := al.clientGroupProvider.Update(req.Context(), group); err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, "Failed to persist client group.", err)
+		return
+	}
+
+	revert := &cgroups.HistoryEntry{
+		GroupID:      entry.GroupID,
+		Action:       cgroups.HistoryActionRevert,
+		After:        entry.After,
+		RevertedFrom: &entry.ID,
+	}
+	if existing != nil {
+		revert.Before = existing.Definition()
+	}
+	al.saveClientGroupHistory(req, revert)
+
+	al.auditLog.Entry(auditlog.ApplicationClientGroup, auditlog.ActionRevert).
+		WithHTTPRequest(req).
+		WithRequest(group).
+		WithID(entry.GroupID).
+		Save()
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(revert))
+	al.Debugf("Client Group [id=%q] reverted to history entry %d.", entry.GroupID, entry.ID)
+}
+
+func (al *APIListener) getClientGroupHistoryEntry(req *http.Request) (*cgroups.HistoryEntry, error) {
+	vars := mux.Vars(req)
+	groupID := vars[routes.ParamGroupID]
+	id, err := strconv.ParseInt(vars[routes.ParamGroupHistoryID], 10, 64)
+	if err != nil {
+		return nil, apiErrors.NewAPIError(http.StatusBadRequest, "", fmt.Sprintf("Invalid history id %q.", vars[routes.ParamGroupHistoryID]), nil)
+	}
+
+	entry, err := al.clientGroupProvider.GetHistory(req.Context(), groupID, id)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, apiErrors.NewAPIError(http.StatusNotFound, "", fmt.Sprintf("History entry %d of client group %q not found.", id, groupID), nil)
+	}
+	return entry, nil
+}
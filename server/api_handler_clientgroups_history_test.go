@@ -0,0 +1,125 @@
+package chserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/cgroups"
+	"github.com/realvnc-labs/rport/server/chconfig"
+)
+
+func TestHandleClientGroupHistory(t *testing.T) {
+	gp := makeGroupsProvider(t, DataSourceOptions)
+	defer gp.Close()
+
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+			clientGroupProvider: gp,
+		},
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req = req.WithContext(api.WithUser(req.Context(), "admin"))
+		al.router.ServeHTTP(w, req)
+		return w
+	}
+	history := func() []cgroups.HistoryEntry {
+		w := do(http.MethodGet, "/api/v1/client-groups/linux/history", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data []cgroups.HistoryEntry `json:"data"`
+			Meta api.Meta               `json:"meta"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, len(resp.Data), resp.Meta.Count)
+		return resp.Data
+	}
+
+	w := do(http.MethodPost, "/api/v1/client-groups", `{"id":"linux","description":"Linux","params":{"os_kernel":["linux"]}}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	w = do(http.MethodPut, "/api/v1/client-groups/linux", `{"id":"linux","description":"Linux servers","params":{"os_kernel":["linux"],"name":["srv-*"]},"allowed_user_groups":["ops"]}`)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	w = do(http.MethodDelete, "/api/v1/client-groups/linux", "")
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	entries := history()
+	require.Len(t, entries, 3)
+	assert.Equal(t, cgroups.HistoryActionDelete, entries[0].Action)
+	assert.Equal(t, "Linux servers", entries[0].Before.Description)
+	assert.Nil(t, entries[0].After)
+	assert.Equal(t, cgroups.HistoryActionUpdate, entries[1].Action)
+	assert.Equal(t, "Linux", entries[1].Before.Description)
+	assert.Equal(t, "Linux servers", entries[1].After.Description)
+	assert.Equal(t, cgroups.HistoryActionCreate, entries[2].Action)
+	assert.Nil(t, entries[2].Before)
+	assert.Equal(t, "Linux", entries[2].After.Description)
+	for _, e := range entries {
+		assert.Equal(t, "admin", e.Username)
+		assert.Equal(t, "linux", e.GroupID)
+		assert.False(t, e.Timestamp.IsZero())
+	}
+
+	w = do(http.MethodGet, "/api/v1/client-groups/linux/history?filter[action]=update", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"meta":{"count":1}`)
+
+	w = do(http.MethodGet, "/api/v1/client-groups/other/history", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"meta":{"count":0}`)
+
+	w = do(http.MethodPost, "/api/v1/client-groups/linux/history/999/revert", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = do(http.MethodGet, "/api/v1/client-groups/linux/history/abc", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = do(http.MethodPost, "/api/v1/client-groups/linux/history/"+strconv.FormatInt(entries[0].ID, 10)+"/revert", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// restores the deleted group as it was after the update
+	w = do(http.MethodPost, "/api/v1/client-groups/linux/history/"+strconv.FormatInt(entries[1].ID, 10)+"/revert", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	group, err := gp.Get(context.Background(), "linux")
+	require.NoError(t, err)
+	require.NotNil(t, group)
+	assert.Equal(t, "Linux servers", group.Description)
+	assert.Equal(t, []string{"ops"}, []string(group.AllowedUserGroups))
+
+	// back to the created definition
+	w = do(http.MethodPost, "/api/v1/client-groups/linux/history/"+strconv.FormatInt(entries[2].ID, 10)+"/revert", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	group, err = gp.Get(context.Background(), "linux")
+	require.NoError(t, err)
+	assert.Equal(t, "Linux", group.Description)
+	assert.Empty(t, group.AllowedUserGroups)
+
+	entries = history()
+	require.Len(t, entries, 5)
+	assert.Equal(t, cgroups.HistoryActionRevert, entries[0].Action)
+	assert.Equal(t, "Linux servers", entries[0].Before.Description)
+	assert.Equal(t, "Linux", entries[0].After.Description)
+	require.NotNil(t, entries[0].RevertedFrom)
+	assert.Equal(t, entries[4].ID, *entries[0].RevertedFrom)
+	assert.Nil(t, entries[1].Before)
+
+	w = do(http.MethodGet, "/api/v1/client-groups/linux/history/"+strconv.FormatInt(entries[0].ID, 10), "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"action":"revert"`)
+}
@@ -6,13 +6,17 @@ import (
 	"net"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"golang.org/x/crypto/ssh"
 
 	"github.com/realvnc-labs/rport/server/api"
 	apierrors "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/api/users"
 	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/bearer"
 	"github.com/realvnc-labs/rport/server/clients"
 	"github.com/realvnc-labs/rport/server/clients/clientdata"
 	"github.com/realvnc-labs/rport/server/clients/clienttunnel"
@@ -24,33 +28,6 @@ import (
 	"github.com/realvnc-labs/rport/share/query"
 )
 
-func getCorrespondingSortFunc(sorts []query.SortOption) (sortFunc func(a []*clientdata.CalculatedClient, desc bool), desc bool, err error) {
-	if len(sorts) < 1 {
-		return clients.SortByID, false, nil
-	}
-	if len(sorts) > 1 {
-		return nil, false, apierrors.APIError{
-			Message:    "Only one sort field is supported for clients.",
-			HTTPStatus: http.StatusBadRequest,
-		}
-	}
-
-	switch sorts[0].Column {
-	case "id":
-		sortFunc = clients.SortByID
-	case "name":
-		sortFunc = clients.SortByName
-	case "os":
-		sortFunc = clients.SortByOS
-	case "hostname":
-		sortFunc = clients.SortByHostname
-	case "version":
-		sortFunc = clients.SortByVersion
-	}
-
-	return sortFunc, !sorts[0].IsASC, nil
-}
-
 func (al *APIListener) handleGetClient(w http.ResponseWriter, req *http.Request) {
 	options := query.GetRetrieveOptions(req)
 	errs := query.ValidateRetrieveOptions(options, clients.OptionsSupportedFields)
@@ -100,6 +77,78 @@ func (al *APIListener) handleDeleteClient(w http.ResponseWriter, req *http.Reque
 	al.Debugf("Client %q deleted.", clientID)
 }
 
+const (
+	disconnectedForQueryParam = "disconnected-for"
+	dryRunQueryParam          = "dry-run"
+)
+
+// handleDeleteClients handles DELETE /clients, it deletes all disconnected clients matching the filters.
+func (al *APIListener) handleDeleteClients(w http.ResponseWriter, req *http.Request) {
+	options := query.NewOptions(req, nil, nil, clients.OptionsListDefaultFields)
+	errs := query.ValidateListOptions(options, nil, clients.OptionsSupportedFilters, clients.OptionsSupportedFields, nil)
+	if errs != nil {
+		al.jsonError(w, errs)
+		return
+	}
+
+	var disconnectedFor time.Duration
+	if disconnectedForStr := req.URL.Query().Get(disconnectedForQueryParam); disconnectedForStr != "" {
+		var err error
+		disconnectedFor, err = time.ParseDuration(disconnectedForStr)
+		if err != nil || disconnectedFor < 0 {
+			al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s: %q.", disconnectedForQueryParam, disconnectedForStr))
+			return
+		}
+	}
+	if len(options.Filters) == 0 && disconnectedFor == 0 {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("At least one filter or %s is required.", disconnectedForQueryParam))
+		return
+	}
+
+	dryRun := false
+	if dryRunStr := req.URL.Query().Get(dryRunQueryParam); dryRunStr != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(dryRunStr)
+		if err != nil {
+			al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s: %q.", dryRunQueryParam, dryRunStr))
+			return
+		}
+	}
+
+	curUser, err := al.getUserModelForAuth(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	groups, err := al.clientGroupProvider.GetAll(req.Context())
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, "Failed to get client groups.", err)
+		return
+	}
+
+	deletedClients, err := al.clientService.DeleteOfflineByFilter(curUser, options.Filters, groups, clientdata.Now().Add(-disconnectedFor), dryRun)
+	if !dryRun {
+		for _, client := range deletedClients {
+			al.auditLog.Entry(auditlog.ApplicationClient, auditlog.ActionDelete).
+				WithHTTPRequest(req).
+				WithID(client.GetID()).
+				Save()
+		}
+		al.Debugf("%d offline clients deleted.", len(deletedClients))
+	}
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, "Failed to delete offline clients.", err)
+		return
+	}
+
+	clients.SortByID(deletedClients, false)
+	al.writeJSONResponse(w, http.StatusOK, &api.SuccessPayload{
+		Data: clients.ConvertToClientsPayload(deletedClients, options.Fields),
+		Meta: api.NewMeta(len(deletedClients)),
+	})
+}
+
 type clientACLRequest struct {
 	AllowedUserGroups []string `json:"allowed_user_groups"`
 }
@@ -141,52 +190,43 @@ func (al *APIListener) handlePostClientACL(w http.ResponseWriter, req *http.Requ
 }
 
 func (al *APIListener) handleGetClients(w http.ResponseWriter, req *http.Request) {
+	options, page, totalCount, err := al.listClients(req)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONListResponse(w, http.StatusOK, len(page), func(i int) interface{} {
+		return clients.ConvertToClientPayload(page[i], options.Fields)
+	}, api.NewMeta(totalCount))
+}
+
+// listClients returns the requested page of the clients of the current user and the total count of matching clients.
+func (al *APIListener) listClients(req *http.Request) (*query.ListOptions, []*clientdata.CalculatedClient, int, error) {
 	options := query.NewOptions(req, nil, nil, clients.OptionsListDefaultFields)
 	errs := query.ValidateListOptions(options, clients.OptionsSupportedSorts, clients.OptionsSupportedFilters, clients.OptionsSupportedFields, &query.PaginationConfig{
 		MaxLimit:     500,
 		DefaultLimit: 50,
 	})
 	if errs != nil {
-		al.jsonError(w, errs)
-		return
-	}
-
-	sortFunc, desc, err := getCorrespondingSortFunc(options.Sorts)
-	if err != nil {
-		al.jsonError(w, err)
-		return
+		return nil, nil, 0, errs
 	}
 
 	curUser, err := al.getUserModelForAuth(req.Context())
 	if err != nil {
-		al.jsonError(w, err)
-		return
+		return nil, nil, 0, err
 	}
 
 	groups, err := al.clientGroupProvider.GetAll(req.Context())
 	if err != nil {
-		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, "Failed to get client groups.", err)
-		return
+		return nil, nil, 0, apierrors.NewAPIError(http.StatusInternalServerError, "", "Failed to get client groups.", err)
 	}
 
-	filteredClients, err := al.clientService.GetFilteredUserClients(curUser, options.Filters, groups)
+	page, totalCount, err := al.clientService.GetUserClientsPage(curUser, options, groups)
 	if err != nil {
-		al.jsonError(w, err)
-		return
+		return nil, nil, 0, err
 	}
-
-	sortFunc(filteredClients, desc)
-
-	totalCount := len(filteredClients)
-	start, end := options.Pagination.GetStartEnd(totalCount)
-	filteredClients = filteredClients[start:end]
-
-	clientsPayload := clients.ConvertToClientsPayload(filteredClients, options.Fields)
-
-	al.writeJSONResponse(w, http.StatusOK, &api.SuccessPayload{
-		Data: clientsPayload,
-		Meta: api.NewMeta(totalCount),
-	})
+	return options, page, totalCount, nil
 }
 
 const (
@@ -195,8 +235,11 @@ const (
 	autoCloseQueryParam          = "auto-close"
 	idleTimeoutMinutesQueryParam = "idle-timeout-minutes"
 	skipIdleTimeoutQueryParam    = "skip-idle-timeout"
+	recordQueryParam             = "record"
+	vaultCredentialsQueryParam   = "vault_credentials_id"
+	ephemeralQueryParam          = "ephemeral"
 
-	ErrCodeLocalPortInUse        = "ERR_CODE_LOCAL_PORT_IN_USE"
+	ErrCodeLocalPortInUse        = apierrors.ErrCodeLocalPortInUse
 	ErrCodeRemotePortNotOpen     = "ERR_CODE_REMOTE_PORT_NOT_OPEN"
 	ErrCodeTunnelExist           = "ERR_CODE_TUNNEL_EXIST"
 	ErrCodeTunnelToPortExist     = "ERR_CODE_TUNNEL_TO_PORT_EXIST"
@@ -228,8 +271,28 @@ func (al *APIListener) handlePutClientTunnel(w http.ResponseWriter, req *http.Re
 		return
 	}
 
+	if client.IsCheckInOnly() {
+		al.jsonErrorResponseWithErrCode(w, http.StatusConflict, apierrors.ErrCodeClientCheckInOnly, fmt.Sprintf("failed to start tunnel for client with id %s: %v", clientID, ErrClientCheckInOnly))
+		return
+	}
+
+	if err := client.CheckQuarantine(); err != nil {
+		al.jsonErrorResponseWithErrCode(w, http.StatusConflict, apierrors.ErrCodeClientQuarantined, fmt.Sprintf("failed to start tunnel for client with id %s: %v", clientID, err))
+		return
+	}
+
+	schemeStr := req.URL.Query().Get("scheme")
+	if len(schemeStr) > URISchemeMaxLength {
+		al.jsonErrorResponseWithDetail(w, http.StatusBadRequest, ErrCodeURISchemeLengthExceed, "Invalid URI scheme.", "Exceeds the max length.")
+		return
+	}
+
 	localAddr := req.URL.Query().Get("local")
 	remoteAddr := req.URL.Query().Get("remote")
+	// without remote the tunnel goes to the default port of the scheme on the client itself
+	if scheme := al.tunnelSchemes.Get(schemeStr); remoteAddr == "" && scheme != nil {
+		remoteAddr = models.LocalHost + ":" + scheme.Port
+	}
 
 	remoteStr := localAddr + ":" + remoteAddr
 	if localAddr == "" {
@@ -249,16 +312,20 @@ func (al *APIListener) handlePutClientTunnel(w http.ResponseWriter, req *http.Re
 
 	client.Log().Debugf("requested remote = %#v", remote)
 
+	if remote.IsProtocol(models.ProtocolUDP) && !client.HasFeature(models.FeatureUDPTunnels) {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Client with id %s doesn't support UDP tunnels.", clientID))
+		return
+	}
+	if remote.SOCKS5 && !client.HasFeature(models.FeatureSOCKS5Tunnels) {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Client with id %s doesn't support SOCKS5 tunnels.", clientID))
+		return
+	}
+
 	name := req.URL.Query().Get("name")
 	if name != "" {
 		remote.Name = name
 	}
 
-	schemeStr := req.URL.Query().Get("scheme")
-	if len(schemeStr) > URISchemeMaxLength {
-		al.jsonErrorResponseWithDetail(w, http.StatusBadRequest, ErrCodeURISchemeLengthExceed, "Invalid URI scheme.", "Exceeds the max length.")
-		return
-	}
 	if schemeStr != "" {
 		remote.Scheme = &schemeStr
 	}
@@ -281,6 +348,24 @@ func (al *APIListener) handlePutClientTunnel(w http.ResponseWriter, req *http.Re
 		return
 	}
 
+	err = al.setRecordOptionForRemote(req, remote)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	err = al.setEphemeralOptionForRemote(req, remote)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	err = al.setBindAddressForRemote(req, client, localAddr, remote)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
 	aclStr := req.URL.Query().Get("acl")
 	if _, err = clienttunnel.ParseTunnelACL(aclStr); err != nil {
 		al.jsonErrorResponseWithErrCode(w, http.StatusBadRequest, ErrCodeInvalidACL, fmt.Sprintf("Invalid ACL: %s", err))
@@ -290,6 +375,20 @@ func (al *APIListener) handlePutClientTunnel(w http.ResponseWriter, req *http.Re
 		remote.ACL = &aclStr
 	}
 
+	err = al.tunnelSchemes.ApplyDefaults(remote)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	if labelsStr := req.URL.Query().Get("labels"); labelsStr != "" {
+		remote.Labels = strings.Split(labelsStr, ",")
+		if err = validation.ValidateLabels(remote.Labels); err != nil {
+			al.jsonErrorResponseWithError(w, http.StatusBadRequest, "Invalid labels.", err)
+			return
+		}
+	}
+
 	allowed, err := clienttunnel.IsAllowed(remote.Remote(), client.GetConnection(), al.Log())
 	if err != nil {
 		al.jsonError(w, err)
@@ -312,7 +411,7 @@ func (al *APIListener) handlePutClientTunnel(w http.ResponseWriter, req *http.Re
 		}
 	}
 
-	if checkPortStr := req.URL.Query().Get("check_port"); checkPortStr != "0" && remote.IsProtocol(models.ProtocolTCP) {
+	if checkPortStr := req.URL.Query().Get("check_port"); checkPortStr != "0" && remote.IsProtocol(models.ProtocolTCP) && !remote.SOCKS5 {
 		err = al.checkRemotePort(*remote, client.GetConnection())
 		if err != nil {
 			al.jsonError(w, err)
@@ -336,6 +435,34 @@ func (al *APIListener) handlePutClientTunnel(w http.ResponseWriter, req *http.Re
 	}
 	remote.Owner = currUser.Username
 
+	err = al.bandwidth.CheckQuota(req.Context(), client.GetID(), currUser.Username)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	err = al.setVaultCredentialsForRemote(req, client, remote, currUser)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	remote.Banner, err = al.sessionBanner(req.Context(), client)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	approverGroups, err := al.tunnelApproverGroups(req, client, remote)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if approverGroups != nil {
+		al.requestTunnelApproval(w, req, client, remote, approverGroups)
+		return
+	}
+
 	// start the new tunnel only
 	tunnels, err := al.clientService.StartClientTunnels(client, []*models.Remote{remote})
 	if err != nil {
@@ -348,6 +475,7 @@ func (al *APIListener) handlePutClientTunnel(w http.ResponseWriter, req *http.Re
 		WithHTTPRequest(req).
 		WithClient(client).
 		WithRequest(remote).
+		WithLabels(remote.Labels).
 		WithResponse(tunnels[0]).
 		WithID(tunnels[0].ID).
 		Save()
@@ -374,6 +502,9 @@ func (al *APIListener) setTunnelProxyOptionsForRemote(req *http.Request, remote
 	if isHTTPProxy && !remote.IsProtocol(models.ProtocolTCP) {
 		return apierrors.NewAPIError(http.StatusBadRequest, "", fmt.Sprintf("tunnel proxy not allowed with protcol %s", remote.Protocol), nil)
 	}
+	if isHTTPProxy && remote.SOCKS5 {
+		return apierrors.NewAPIError(http.StatusBadRequest, "", "tunnel proxy not allowed with SOCKS5 tunnels", nil)
+	}
 
 	if isHTTPProxy && al.config.CaddyEnabled() {
 		downstreamSubdomain, err := al.config.Caddy.SubDomainGenerator.GetRandomSubdomain()
@@ -430,6 +561,12 @@ func (al *APIListener) setAutoCloseIdleOptionsForRemote(req *http.Request, remot
 		skipIdleTimeout = false
 	}
 
+	if idleTimeoutMinutesStr == "" && !skipIdleTimeout && remote.Scheme != nil {
+		if scheme := al.tunnelSchemes.Get(*remote.Scheme); scheme != nil && scheme.IdleTimeoutMinutes != nil {
+			idleTimeoutMinutesStr = strconv.Itoa(*scheme.IdleTimeoutMinutes)
+		}
+	}
+
 	idleTimeout, err := validation.ResolveIdleTunnelTimeoutValue(idleTimeoutMinutesStr, skipIdleTimeout)
 	if err != nil {
 		return err
@@ -445,6 +582,96 @@ func (al *APIListener) setAutoCloseIdleOptionsForRemote(req *http.Request, remot
 	return err
 }
 
+func (al *APIListener) setRecordOptionForRemote(req *http.Request, remote *models.Remote) error {
+	recordStr := req.URL.Query().Get(recordQueryParam)
+	if recordStr == "" {
+		return nil
+	}
+	record, err := strconv.ParseBool(recordStr)
+	if err != nil {
+		return apierrors.NewAPIError(http.StatusBadRequest, "", fmt.Sprintf("Invalid %s param: %v.", recordQueryParam, recordStr), err)
+	}
+	if record && !al.config.Server.SessionRecording.Enabled {
+		return apierrors.NewAPIError(http.StatusBadRequest, "", "session recording not enabled", nil)
+	}
+	if record && remote.Protocol != models.ProtocolTCP {
+		return apierrors.NewAPIError(http.StatusBadRequest, "", fmt.Sprintf("session recording not allowed with protocol %s", remote.Protocol), nil)
+	}
+
+	remote.Record = record
+	return nil
+}
+
+// setEphemeralOptionForRemote ties the tunnel to the api session of the request, the tunnel is closed when the session
+// ends. Requires a bearer token as basic auth and api tokens have no session.
+func (al *APIListener) setEphemeralOptionForRemote(req *http.Request, remote *models.Remote) error {
+	ephemeralStr := req.URL.Query().Get(ephemeralQueryParam)
+	if ephemeralStr == "" {
+		return nil
+	}
+	ephemeral, err := strconv.ParseBool(ephemeralStr)
+	if err != nil {
+		return apierrors.NewAPIError(http.StatusBadRequest, "", fmt.Sprintf("Invalid %s param: %v.", ephemeralQueryParam, ephemeralStr), err)
+	}
+	if !ephemeral {
+		return nil
+	}
+
+	errNoSession := apierrors.NewAPIError(http.StatusBadRequest, "", "ephemeral tunnels require to be logged in with a bearer token", nil)
+	tokenStr, ok := bearer.GetBearerToken(req)
+	if !ok {
+		return errNoSession
+	}
+	tokenCtx, err := bearer.ParseToken(tokenStr, al.config.API.JWTSecret)
+	if err != nil || tokenCtx.AppClaims.SessionID == 0 {
+		return errNoSession
+	}
+
+	remote.Ephemeral = true
+	remote.SessionID = tokenCtx.AppClaims.SessionID
+	return nil
+}
+
+// setVaultCredentialsForRemote references a vault value as password to be injected by the rdp or vnc tunnel proxy.
+// The vault value is resolved again with the groups of the tunnel owner on each connect.
+func (al *APIListener) setVaultCredentialsForRemote(req *http.Request, client *clientdata.Client, remote *models.Remote, user *users.User) error {
+	vaultIDStr := req.URL.Query().Get(vaultCredentialsQueryParam)
+	if vaultIDStr == "" {
+		return nil
+	}
+	vaultID, err := strconv.Atoi(vaultIDStr)
+	if err != nil || vaultID <= 0 {
+		return apierrors.NewAPIError(http.StatusBadRequest, "", fmt.Sprintf("Invalid %s param: %v.", vaultCredentialsQueryParam, vaultIDStr), err)
+	}
+	if !remote.HTTPProxy || remote.Scheme == nil || (*remote.Scheme != "rdp" && *remote.Scheme != "vnc") {
+		return apierrors.NewAPIError(http.StatusBadRequest, "", fmt.Sprintf("%s requires http_proxy with scheme rdp or vnc", vaultCredentialsQueryParam), nil)
+	}
+	// the credentials give everyone who can connect an authenticated session, so the access must be restricted
+	if !hasRestrictedAccess(remote) {
+		return apierrors.NewAPIError(http.StatusBadRequest, "", fmt.Sprintf("%s requires an acl or auth_user and auth_password", vaultCredentialsQueryParam), nil)
+	}
+
+	value, found, err := al.vaultManager.GetOne(req.Context(), vaultID, user)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return apierrors.NewAPIError(http.StatusBadRequest, "", fmt.Sprintf("vault value with id %d not found", vaultID), nil)
+	}
+	if value.ClientID != "" && value.ClientID != client.GetID() {
+		return apierrors.NewAPIError(http.StatusBadRequest, "", fmt.Sprintf("vault value with id %d belongs to another client", vaultID), nil)
+	}
+
+	remote.VaultCredentialsID = vaultID
+	return nil
+}
+
+// hasRestrictedAccess tells whether connecting to the tunnel is restricted by the tunnel ACL or the basic auth of the
+// tunnel proxy.
+func hasRestrictedAccess(remote *models.Remote) bool {
+	return (remote.ACL != nil && *remote.ACL != "") || remote.AuthUser != ""
+}
+
 // TODO: remove this check, do it in client srv in startClientTunnels when https://github.com/realvnc-labs/rport/pull/252 will be in master.
 // APIError needs both httpStatusCode and errorCode. To avoid too many merge conflicts with PR252 temporarily use this check to avoid breaking UI
 func (al *APIListener) checkLocalPort(localPort, protocol string) (err error) {
@@ -557,6 +784,7 @@ func (al *APIListener) handleDeleteClientTunnel(w http.ResponseWriter, req *http
 		WithHTTPRequest(req).
 		WithClient(client).
 		WithID(tunnelID).
+		WithLabels(tunnel.Labels).
 		WithRequest(map[string]interface{}{
 			"force": force,
 		}).
@@ -614,6 +842,7 @@ func (al *APIListener) handlePutClientTunnelACL(w http.ResponseWriter, req *http
 		WithHTTPRequest(req).
 		WithClient(client).
 		WithID(tunnelID).
+		WithLabels(tunnel.Labels).
 		WithRequest(reqBody).
 		Save()
 
@@ -2,12 +2,11 @@ package chserver
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
-	"reflect"
-	"runtime"
 	"strconv"
 	"strings"
 	"testing"
@@ -24,8 +23,8 @@ import (
 	"github.com/realvnc-labs/rport/server/clients"
 	"github.com/realvnc-labs/rport/server/clients/clientdata"
 	"github.com/realvnc-labs/rport/server/clients/clienttunnel"
+	"github.com/realvnc-labs/rport/server/tunnelschemes"
 	"github.com/realvnc-labs/rport/share/models"
-	"github.com/realvnc-labs/rport/share/query"
 	"github.com/realvnc-labs/rport/share/test"
 )
 
@@ -128,7 +127,8 @@ func TestHandleGetClient(t *testing.T) {
                 "auto_close": 0,
                 "created_at":"0001-01-01T00:00:00Z",
                 "id":"1",
-                "tunnel_url":""
+                "tunnel_url":"",
+                "record":false
             },
             {
                 "name": "",
@@ -149,7 +149,8 @@ func TestHandleGetClient(t *testing.T) {
                 "auto_close": 0,
                 "created_at":"0001-01-01T00:00:00Z",
                 "id":"2",
-                "tunnel_url":""
+                "tunnel_url":"",
+                "record":false
             }
         ],
         "connection_state":"connected",
@@ -159,11 +160,20 @@ func TestHandleGetClient(t *testing.T) {
         "cpu_vendor":"GenuineIntel",
         "disconnected_at":null,
         "last_heartbeat_at":null,
+        "agent_stats":null,
         "client_auth_id":"user1",
         "allowed_user_groups":null,
         "updates_status":null,
+        "interpreters":null,
         "client_configuration":null,
-        "groups": []
+        "groups": [],
+        "eol_date":null,
+        "outdated":false,
+        "quarantine":null,
+        "monitoring_profile":null,
+        "protocol_version":0,
+        "features":null,
+        "is_eol":false
     }
 }`
 			assert.Equal(t, tc.ExpectedStatus, w.Code)
@@ -286,92 +296,116 @@ func TestHandleGetClients(t *testing.T) {
 	}
 }
 
-func TestGetCorrespondingSortFuncPositive(t *testing.T) {
-	testCases := []struct {
-		sortStr string
+func TestHandleDeleteClients(t *testing.T) {
+	curUser := &users.User{
+		Username: "admin",
+		Groups:   []string{users.Administrators},
+	}
 
-		wantFunc func(a []*clientdata.CalculatedClient, desc bool)
-		wantDesc bool
+	testCases := []struct {
+		Name              string
+		Query             string
+		ExpectedStatus    int
+		ExpectedIDs       []string
+		ExpectedRemaining int
 	}{
 		{
-			sortStr:  "",
-			wantFunc: clients.SortByID,
-			wantDesc: false,
-		},
-		{
-			sortStr:  "id",
-			wantFunc: clients.SortByID,
-			wantDesc: false,
+			Name:              "disconnected for",
+			Query:             "disconnected-for=30m",
+			ExpectedStatus:    http.StatusOK,
+			ExpectedIDs:       []string{"client-2"},
+			ExpectedRemaining: 3,
 		},
 		{
-			sortStr:  "-id",
-			wantFunc: clients.SortByID,
-			wantDesc: true,
+			Name:              "tag filter",
+			Query:             "filter[tags]=decommissioned",
+			ExpectedStatus:    http.StatusOK,
+			ExpectedIDs:       []string{"client-3"},
+			ExpectedRemaining: 3,
 		},
 		{
-			sortStr:  "name",
-			wantFunc: clients.SortByName,
-			wantDesc: false,
+			Name:              "dry run",
+			Query:             "filter[tags]=decommissioned&disconnected-for=1m&dry-run=true",
+			ExpectedStatus:    http.StatusOK,
+			ExpectedIDs:       []string{"client-3"},
+			ExpectedRemaining: 4,
 		},
 		{
-			sortStr:  "-name",
-			wantFunc: clients.SortByName,
-			wantDesc: true,
+			Name:              "connected clients are kept",
+			Query:             "filter[id]=client-*",
+			ExpectedStatus:    http.StatusOK,
+			ExpectedIDs:       []string{"client-2", "client-3"},
+			ExpectedRemaining: 2,
 		},
 		{
-			sortStr:  "hostname",
-			wantFunc: clients.SortByHostname,
-			wantDesc: false,
+			Name:              "no filters",
+			ExpectedStatus:    http.StatusBadRequest,
+			ExpectedRemaining: 4,
 		},
 		{
-			sortStr:  "-hostname",
-			wantFunc: clients.SortByHostname,
-			wantDesc: true,
+			Name:              "invalid disconnected for",
+			Query:             "disconnected-for=90days",
+			ExpectedStatus:    http.StatusBadRequest,
+			ExpectedRemaining: 4,
 		},
 		{
-			sortStr:  "os",
-			wantFunc: clients.SortByOS,
-			wantDesc: false,
-		},
-		{
-			sortStr:  "-os",
-			wantFunc: clients.SortByOS,
-			wantDesc: true,
+			Name:              "invalid dry run",
+			Query:             "disconnected-for=1m&dry-run=maybe",
+			ExpectedStatus:    http.StatusBadRequest,
+			ExpectedRemaining: 4,
 		},
 	}
 
 	for _, tc := range testCases {
-		tc := tc
-		t.Run(tc.sortStr, func(t *testing.T) {
-			t.Parallel()
-
-			// when
-			sortOptions := query.ParseSortOptions(map[string][]string{"sort": {tc.sortStr}})
-			gotFunc, gotDesc, gotErr := getCorrespondingSortFunc(sortOptions)
+		t.Run(tc.Name, func(t *testing.T) {
+			c1 := clients.New(t).ID("client-1").Logger(testLog).Build()
+			c2 := clients.New(t).ID("client-2").DisconnectedDuration(50 * time.Minute).Logger(testLog).Build()
+			c3 := clients.New(t).ID("client-3").DisconnectedDuration(5 * time.Minute).Logger(testLog).Build()
+			c3.SetTags([]string{"decommissioned"})
+			c4 := clients.New(t).ID("other-4").DisconnectedDuration(5 * time.Minute).Logger(testLog).Build()
+			clientService := clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1, c2, c3, c4}, &hour, testLog), testLog, nil)
+			al := APIListener{
+				insecureForTests: true,
+				Server: &Server{
+					clientService: clientService,
+					config: &chconfig.Config{
+						API: chconfig.APIConfig{
+							MaxRequestBytes: 1024 * 1024,
+						},
+					},
+					clientGroupProvider: mockClientGroupProvider{},
+				},
+				userService: users.NewAPIService(users.NewStaticProvider([]*users.User{curUser}), false, 0, -1),
+				Logger:      testLog,
+			}
+			al.initRouter()
 
-			// then
-			// workaround to compare func vars, see https://github.com/stretchr/testify/issues/182
-			wantFuncName := runtime.FuncForPC(reflect.ValueOf(tc.wantFunc).Pointer()).Name()
-			gotFuncName := runtime.FuncForPC(reflect.ValueOf(gotFunc).Pointer()).Name()
-			msg := fmt.Sprintf("getCorrespondingSortFunc(%q) = (%s, %v, %v), expected: (%s, %v, %v)", tc.sortStr, gotFuncName, gotDesc, gotErr, wantFuncName, tc.wantDesc, nil)
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/clients?"+tc.Query, nil)
+			ctx := api.WithUser(context.Background(), curUser.Username)
+			req = req.WithContext(ctx)
+			w := httptest.NewRecorder()
+			al.router.ServeHTTP(w, req)
 
-			assert.NoErrorf(t, gotErr, msg)
-			assert.Equalf(t, wantFuncName, gotFuncName, msg)
-			assert.Equalf(t, tc.wantDesc, gotDesc, msg)
+			assert.Equal(t, tc.ExpectedStatus, w.Code)
+			assert.Equal(t, tc.ExpectedRemaining, clientService.Count())
+			if tc.ExpectedStatus != http.StatusOK {
+				return
+			}
+			var resp struct {
+				Data []struct {
+					ID string `json:"id"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			var ids []string
+			for _, c := range resp.Data {
+				ids = append(ids, c.ID)
+			}
+			assert.Equal(t, tc.ExpectedIDs, ids)
 		})
 	}
 }
 
-func TestGetCorrespondingSortFuncError(t *testing.T) {
-	// when
-	sortOptions := query.ParseSortOptions(map[string][]string{"sort": {"id", "-name"}})
-	_, _, gotErr := getCorrespondingSortFunc(sortOptions)
-
-	// then
-	require.Error(t, gotErr)
-	assert.Equal(t, gotErr.Error(), "Only one sort field is supported for clients.")
-}
-
 type SimpleMockClientService struct {
 	ExpectedIDs   []string
 	ActiveClients []*clientdata.Client
@@ -410,12 +444,14 @@ func TestHandlePutTunnelWithName(t *testing.T) {
 	mockUsersService := &MockUsersService{
 		UserService: users.NewAPIService(users.NewStaticProvider([]*users.User{user}), false, 0, -1),
 	}
+	vncIdleTimeout := 30
 
 	testCases := []struct {
-		Name          string
-		URL           string
-		ExpectedJSON  string
-		ExpectedError string
+		Name           string
+		URL            string
+		ClientFeatures []string
+		ExpectedJSON   string
+		ExpectedError  string
 	}{
 		{
 			Name: "With Name",
@@ -440,7 +476,8 @@ func TestHandlePutTunnelWithName(t *testing.T) {
 				"auth_user":"",
 				"auth_password":"",
 				"created_at": "0001-01-01T00:00:00Z",
-				"tunnel_url": ""
+				"tunnel_url": "",
+				"record": false
 			}
 		}`,
 		},
@@ -467,7 +504,8 @@ func TestHandlePutTunnelWithName(t *testing.T) {
 				"auth_user":"",
 				"auth_password":"",
 				"created_at": "0001-01-01T00:00:00Z",
-				"tunnel_url": ""
+				"tunnel_url": "",
+				"record": false
 			}
 		}`,
 		},
@@ -494,7 +532,8 @@ func TestHandlePutTunnelWithName(t *testing.T) {
 				"auth_user":"",
 				"auth_password":"",
 				"created_at": "0001-01-01T00:00:00Z",
-				"tunnel_url": ""
+				"tunnel_url": "",
+				"record": false
 			}
 		}`,
 		},
@@ -521,15 +560,105 @@ func TestHandlePutTunnelWithName(t *testing.T) {
 				"auth_user":"admin",
 				"auth_password":"foo",
 				"created_at": "0001-01-01T00:00:00Z",
-				"tunnel_url": ""
+				"tunnel_url": "",
+				"record": false
+			}
+		}`,
+		},
+		{
+			Name: "Remote from Scheme",
+			URL:  "/api/v1/clients/client-1/tunnels?scheme=vnc&local=0.0.0.0%3A3390&check_port=0",
+			ExpectedJSON: `{
+			"data": {
+				"id": "10",
+				"name": "",
+				"owner": "test-user",
+				"protocol": "tcp",
+				"lhost": "0.0.0.0",
+				"lport": "3390",
+				"rhost": "127.0.0.1",
+				"rport": "5901",
+				"lport_random": false,
+				"scheme": "vnc",
+				"acl": "10.0.0.1",
+				"idle_timeout_minutes": 30,
+				"auto_close": 0,
+				"http_proxy": false,
+				"host_header": "",
+				"auth_user":"",
+				"auth_password":"",
+				"created_at": "0001-01-01T00:00:00Z",
+				"tunnel_url": "",
+				"record": false
 			}
 		}`,
 		},
+		{
+			Name:          "Without Remote and unknown Scheme",
+			URL:           "/api/v1/clients/client-1/tunnels?scheme=foo&local=0.0.0.0%3A3390&check_port=0",
+			ExpectedError: "failed to decode",
+		},
 		{
 			Name:          "Auth with error",
 			URL:           "/api/v1/clients/client-1/tunnels?scheme=http&acl=127.0.0.1&local=0.0.0.0%3A3390&remote=0.0.0.0%3A22&check_port=0&auth_user=admin&http_proxy=1",
 			ExpectedError: "auth_user requires auth_password",
 		},
+		{
+			Name:          "Record with session recording disabled",
+			URL:           "/api/v1/clients/client-1/tunnels?scheme=ssh&local=0.0.0.0%3A3390&remote=0.0.0.0%3A22&check_port=0&record=1",
+			ExpectedError: "session recording not enabled",
+		},
+		{
+			Name:          "Ephemeral without bearer token",
+			URL:           "/api/v1/clients/client-1/tunnels?scheme=ssh&local=0.0.0.0%3A3390&remote=0.0.0.0%3A22&check_port=0&ephemeral=1",
+			ExpectedError: "ephemeral tunnels require to be logged in with a bearer token",
+		},
+		{
+			Name:          "UDP without negotiated feature",
+			URL:           "/api/v1/clients/client-1/tunnels?local=0.0.0.0%3A3390&remote=0.0.0.0%3A53&protocol=udp&check_port=0",
+			ExpectedError: "doesn't support UDP tunnels",
+		},
+		{
+			Name:           "SOCKS5",
+			URL:            "/api/v1/clients/client-1/tunnels?local=0.0.0.0%3A3390&remote=socks5%3A%2F%2F&acl=127.0.0.1",
+			ClientFeatures: []string{models.FeatureSOCKS5Tunnels},
+			ExpectedJSON: `{
+			"data": {
+				"id": "10",
+				"name": "",
+				"owner": "test-user",
+				"protocol": "tcp",
+				"lhost": "0.0.0.0",
+				"lport": "3390",
+				"rhost": "",
+				"rport": "",
+				"lport_random": false,
+				"scheme": null,
+				"acl": "127.0.0.1",
+				"idle_timeout_minutes": 5,
+				"auto_close": 0,
+				"http_proxy": false,
+				"host_header": "",
+				"auth_user":"",
+				"auth_password":"",
+				"created_at": "0001-01-01T00:00:00Z",
+				"tunnel_url": "",
+				"record": false,
+				"socks5": true
+			}
+		}`,
+		},
+		{
+			Name:          "SOCKS5 without negotiated feature",
+			URL:           "/api/v1/clients/client-1/tunnels?local=0.0.0.0%3A3390&remote=socks5%3A%2F%2F",
+			ExpectedError: "doesn't support SOCKS5 tunnels",
+		},
+		{
+			Name:           "SOCKS5 with tunnel proxy",
+			URL:            "/api/v1/clients/client-1/tunnels?local=0.0.0.0%3A3390&remote=socks5%3A%2F%2F&http_proxy=1",
+			ClientFeatures: []string{models.FeatureSOCKS5Tunnels},
+			ExpectedError:  "tunnel proxy not allowed with SOCKS5 tunnels",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -540,6 +669,7 @@ func TestHandlePutTunnelWithName(t *testing.T) {
 			c1 := clients.New(t).ID("client-1").ClientAuthID(cl1.ID).Logger(testLog).Build()
 			c1.SetConnection(connMock)
 			c1.Logger = testLog
+			c1.Features = tc.ClientFeatures
 
 			mockClientService := &SimpleMockClientService{
 				ExpectedIDs: []string{"10"},
@@ -563,6 +693,9 @@ func TestHandlePutTunnelWithName(t *testing.T) {
 						},
 					},
 					clientGroupProvider: mockClientGroupProvider{},
+					tunnelSchemes: tunnelschemes.New([]tunnelschemes.Scheme{
+						{Name: "vnc", Port: "5901", ACL: "10.0.0.1", IdleTimeoutMinutes: &vncIdleTimeout},
+					}),
 				},
 				userService: mockUsersService,
 				Logger:      testLog,
@@ -630,7 +763,8 @@ func TestHandlePutTunnelUsingCaddyProxies(t *testing.T) {
 				"auth_user":"",
 				"auth_password":"",
 				"created_at": "0001-01-01T00:00:00Z",
-				"tunnel_url": ""
+				"tunnel_url": "",
+				"record": false
 			}
 		}`,
 		},
@@ -651,6 +785,7 @@ func TestHandlePutTunnelUsingCaddyProxies(t *testing.T) {
 				"lport_random": false,
 				"scheme": "http",
 				"tunnel_url": "https://12345678.tunnels.rport.test:443",
+				"record": false,
 				"acl": "127.0.0.1",
 				"idle_timeout_minutes": 5,
 				"auto_close": 0,
@@ -679,6 +814,7 @@ func TestHandlePutTunnelUsingCaddyProxies(t *testing.T) {
 				"lport_random": false,
 				"scheme": "http",
 				"tunnel_url": "https://12345678.tunnels.rport.test:8443",
+				"record": false,
 				"acl": "127.0.0.1",
 				"idle_timeout_minutes": 5,
 				"auto_close": 0,
@@ -707,6 +843,7 @@ func TestHandlePutTunnelUsingCaddyProxies(t *testing.T) {
 				"lport_random": false,
 				"scheme": null,
 				"tunnel_url": "https://12345678.tunnels.rport.test:443",
+				"record": false,
 				"acl": "127.0.0.1",
 				"idle_timeout_minutes": 5,
 				"auto_close": 0,
@@ -735,6 +872,7 @@ func TestHandlePutTunnelUsingCaddyProxies(t *testing.T) {
 					"lport_random": false,
 					"scheme": "http",
 					"tunnel_url": "",
+					"record": false,
 					"acl": "127.0.0.1",
 					"idle_timeout_minutes": 5,
 					"auto_close": 0,
@@ -838,6 +976,8 @@ func TestHandlePutClientTunnelACL(t *testing.T) {
 	mockTunnelProtocol := &MockTunnelProtocol{}
 	c1 := clients.New(t).ID("client-1").Build()
 	c1.Tunnels[0].TunnelProtocol = mockTunnelProtocol
+	c1.Tunnels[1].TunnelProtocol = &MockTunnelProtocol{}
+	c1.Tunnels[1].VaultCredentialsID = 12
 	al := APIListener{
 		insecureForTests: true,
 		Server: &Server{
@@ -879,6 +1019,11 @@ func TestHandlePutClientTunnelACL(t *testing.T) {
 			URL:            "/api/v1/clients/client-1/tunnels/1/acl",
 			Body:           `{"acl": "invalid"}`,
 			ExpectedStatus: http.StatusBadRequest,
+		}, {
+			Name:           "remove acl of tunnel with vault credentials",
+			URL:            "/api/v1/clients/client-1/tunnels/2/acl",
+			Body:           `{"acl": null}`,
+			ExpectedStatus: http.StatusBadRequest,
 		}, {
 			Name:           "unknown tunnel",
 			URL:            "/api/v1/clients/client-1/tunnels/unknown/acl",
@@ -907,6 +1052,41 @@ func TestHandlePutClientTunnelACL(t *testing.T) {
 	}
 }
 
+func TestSetVaultCredentialsForRemoteRequiresRestrictedAccess(t *testing.T) {
+	al := APIListener{}
+	c1 := clients.New(t).ID("client-1").Build()
+	rdp := "rdp"
+
+	testCases := []struct {
+		name   string
+		remote models.Remote
+	}{
+		{
+			name:   "no acl",
+			remote: models.Remote{HTTPProxy: true, Scheme: &rdp},
+		},
+		{
+			name:   "empty acl",
+			remote: models.Remote{HTTPProxy: true, Scheme: &rdp, ACL: new(string)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/clients/client-1/tunnels?vault_credentials_id=12", nil)
+
+			err := al.setVaultCredentialsForRemote(req, c1, &tc.remote, &users.User{Username: "admin"})
+
+			assert.EqualError(t, err, "vault_credentials_id requires an acl or auth_user and auth_password")
+			assert.Zero(t, tc.remote.VaultCredentialsID)
+		})
+	}
+
+	acl := "10.0.0.0/8"
+	assert.True(t, hasRestrictedAccess(&models.Remote{ACL: &acl}))
+	assert.True(t, hasRestrictedAccess(&models.Remote{AuthUser: "user"}))
+}
+
 type MockTunnelProtocol struct {
 	clienttunnel.TunnelProtocol
 	ACL *clienttunnel.TunnelACL
@@ -0,0 +1,28 @@
+package chserver
+
+import (
+	"net/http"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/clients"
+)
+
+// handleGetClientsV2 handles GET /api/v2/clients. Unlike v1, the meta has the count of the page, the total count and
+// the pagination, and links to the next and previous pages are returned.
+func (al *APIListener) handleGetClientsV2(w http.ResponseWriter, req *http.Request) {
+	options, page, totalCount, err := al.listClients(req)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	payload := api.NewListPayload(
+		clients.ConvertToClientsPayload(page, options.Fields),
+		req.URL,
+		totalCount,
+		len(page),
+		options.Pagination.ValidatedLimit,
+		options.Pagination.ValidatedOffset,
+	)
+	al.writeJSONResponse(w, http.StatusOK, payload)
+}
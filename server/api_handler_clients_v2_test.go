@@ -0,0 +1,110 @@
+package chserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/middleware"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+)
+
+func TestHandleGetClientsV2(t *testing.T) {
+	curUser := &users.User{
+		Username: "admin",
+		Groups:   []string{users.Administrators},
+	}
+
+	c1 := clients.New(t).ID("client-1").ClientAuthID(cl1.ID).Logger(testLog).Build()
+	c2 := clients.New(t).ID("client-2").ClientAuthID(cl1.ID).DisconnectedDuration(5 * time.Minute).Logger(testLog).Build()
+	c3 := clients.New(t).ID("client-3").ClientAuthID(cl1.ID).Logger(testLog).Build()
+
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			clientService: clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1, c2, c3}, &hour, testLog), testLog, nil),
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+			clientGroupProvider: mockClientGroupProvider{},
+		},
+		userService: users.NewAPIService(users.NewStaticProvider([]*users.User{curUser}), false, 0, -1),
+	}
+	al.initRouter()
+
+	testCases := []struct {
+		Name         string
+		URL          string
+		ExpectedJSON string
+	}{
+		{
+			Name: "first page",
+			URL:  "/api/v2/clients?fields[clients]=id&page[limit]=2",
+			ExpectedJSON: `{
+   "data":[{"id":"client-1"}, {"id":"client-2"}],
+   "meta": {"total": 3, "count": 2, "limit": 2, "offset": 0},
+   "links": {
+      "self": "/api/v2/clients?fields%5Bclients%5D=id&page%5Blimit%5D=2&page%5Boffset%5D=0",
+      "next": "/api/v2/clients?fields%5Bclients%5D=id&page%5Blimit%5D=2&page%5Boffset%5D=2"
+   }
+}`,
+		},
+		{
+			Name: "last page",
+			URL:  "/api/v2/clients?fields[clients]=id&page[limit]=2&page[offset]=2",
+			ExpectedJSON: `{
+   "data":[{"id":"client-3"}],
+   "meta": {"total": 3, "count": 1, "limit": 2, "offset": 2},
+   "links": {
+      "self": "/api/v2/clients?fields%5Bclients%5D=id&page%5Blimit%5D=2&page%5Boffset%5D=2",
+      "prev": "/api/v2/clients?fields%5Bclients%5D=id&page%5Blimit%5D=2&page%5Boffset%5D=0"
+   }
+}`,
+		},
+		{
+			Name: "invalid limit",
+			URL:  "/api/v2/clients?page[limit]=0",
+			ExpectedJSON: `{
+   "errors":[{"code":"ERR_CODE_INVALID_REQUEST", "title":"pagination limit must be positive", "detail":""}],
+   "request_id": "request-1"
+}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tc.URL, nil)
+			req.Header.Set(middleware.RequestIDHeader, "request-1")
+			req = req.WithContext(api.WithUser(context.Background(), curUser.Username))
+			al.router.ServeHTTP(w, req)
+
+			assert.JSONEq(t, tc.ExpectedJSON, w.Body.String())
+			assert.Empty(t, w.Header().Get(middleware.DeprecationHeader))
+		})
+	}
+
+	t.Run("v1 deprecated", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/clients", nil)
+		req = req.WithContext(api.WithUser(context.Background(), curUser.Username))
+		al.router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, w.Header().Get(middleware.DeprecationHeader))
+		assert.Equal(t, `</api/v2/clients>; rel="successor-version"`, w.Header().Get(middleware.LinkHeader))
+	})
+}
@@ -0,0 +1,133 @@
+package chserver
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/realvnc-labs/rport/server/bearer"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+const clientsWSPingInterval = 30 * time.Second
+
+// clientsWSSessionCheckInterval is how often the session of a client list subscription is checked, the feed is closed
+// once the session expired or was revoked, e.g. by logout or by deleting the user.
+var clientsWSSessionCheckInterval = time.Minute
+
+// handleClientsWS handles GET /ws/clients. It accepts the same filters and fields as GET /clients and pushes the
+// changes of the matching clients the user has access to. Clients matching on subscribe are not sent, the UI lists
+// them via GET /clients first. Client groups are evaluated on subscribe. The connection is closed once the session it
+// was opened with isn't valid anymore.
+func (al *APIListener) handleClientsWS(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	options := query.NewOptions(req, nil, nil, clients.OptionsListDefaultFields)
+	errs := query.ValidateListOptions(options, nil, clients.OptionsSupportedFilters, clients.OptionsSupportedFields, nil)
+	if errs != nil {
+		al.jsonError(w, errs)
+		return
+	}
+
+	curUser, err := al.getUserModelForAuth(ctx)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	groups, err := al.clientGroupProvider.GetAll(ctx)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, "Failed to get client groups.", err)
+		return
+	}
+
+	repo := al.clientService.GetRepo()
+	match := func(client *clientdata.Client) (bool, bool) {
+		calculatedClient, matches, err := repo.UserClientMatches(curUser, client, options.Filters, groups)
+		if err != nil || !matches {
+			return false, false
+		}
+		return true, calculatedClient.GetConnectionState() == clientdata.Connected
+	}
+	convert := func(client *clientdata.Client) interface{} {
+		return clients.ConvertToClientPayload(client.ToCalculated(groups), options.Fields)
+	}
+
+	uiConn, err := apiUpgrader.Upgrade(w, req, nil)
+	if err != nil {
+		al.Errorf("Failed to establish WS connection: %v", err)
+		return
+	}
+	defer uiConn.Close()
+
+	sub := al.Server.clientFeed.Subscribe(match, convert, repo.GetAllClients())
+	defer al.Server.clientFeed.Unsubscribe(sub)
+
+	// the subscriber doesn't send anything, reading is required to notice when it closes the connection
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := uiConn.ReadMessage(); err != nil {
+				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+					al.Infof("closed ws connection: %v", err)
+				}
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(clientsWSPingInterval)
+	defer ticker.Stop()
+	sessionTicker := time.NewTicker(clientsWSSessionCheckInterval)
+	defer sessionTicker.Stop()
+	for {
+		select {
+		case e, ok := <-sub.Events():
+			if !ok {
+				if sub.Overflowed() {
+					msg := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "too many client changes, reload the clients and subscribe again")
+					_ = uiConn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+				}
+				return
+			}
+			if err := uiConn.WriteJSON(e); err != nil {
+				al.Debugf("failed to write client change to ws connection: %v", err)
+				return
+			}
+		case <-ticker.C:
+			if err := uiConn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+				return
+			}
+		case <-sessionTicker.C:
+			if !al.wsSessionValid(req) {
+				msg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "session expired")
+				_ = uiConn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+// wsSessionValid checks the credentials a ws connection was opened with again, like wsAuth but without extending the
+// session lifetime.
+func (al *APIListener) wsSessionValid(req *http.Request) bool {
+	ctx := req.Context()
+	tokenStr := req.URL.Query().Get(WebSocketAccessTokenQueryParam)
+	if tokenStr == "" {
+		basicUser, basicPwd, _ := req.BasicAuth()
+		authorized, _, err := al.handleBasicAuth(ctx, req.Method, req.URL.Path, basicUser, basicPwd)
+		return authorized && err == nil
+	}
+
+	tokenCtx, err := bearer.ParseToken(tokenStr, al.config.API.JWTSecret)
+	if err != nil {
+		return false
+	}
+	authorized, _, err := bearer.ValidateBearerToken(ctx, tokenCtx, req.URL.Path, req.Method, al.apiSessions, al.Logger)
+	return authorized && err == nil
+}
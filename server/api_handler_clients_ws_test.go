@@ -0,0 +1,111 @@
+package chserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/bearer"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clientfeed"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+)
+
+func TestHandleClientsWS(t *testing.T) {
+	curUser := &users.User{
+		Username: "admin",
+		Groups:   []string{users.Administrators},
+	}
+	c1 := clients.New(t).ID("client-1").Logger(testLog).Build()
+	c2 := clients.New(t).ID("client-2").DisconnectedDuration(5 * time.Minute).Logger(testLog).Build()
+	repo := clients.NewClientRepository([]*clientdata.Client{c1, c2}, &hour, testLog)
+	feed := clientfeed.New(testLog)
+	repo.AddChangeHandlerFn(feed.ClientChanged)
+
+	al := APIListener{
+		Server: &Server{
+			clientService: clients.NewClientService(nil, nil, repo, testLog, nil),
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+			clientGroupProvider: mockClientGroupProvider{},
+			clientFeed:          feed,
+		},
+		userService: users.NewAPIService(users.NewStaticProvider([]*users.User{curUser}), false, 0, -1),
+		Logger:      testLog,
+	}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		al.handleClientsWS(w, req.WithContext(api.WithUser(req.Context(), curUser.Username)))
+	}))
+	defer s.Close()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/ws/clients?filter[unknown]=1", nil)
+	al.handleClientsWS(w, req.WithContext(api.WithUser(req.Context(), curUser.Username)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	ws, _, err := websocket.DefaultDialer.Dial(httpToWS(t, s.URL)+"?filter[connection_state]=connected&fields[clients]=id,name", nil)
+	require.NoError(t, err)
+	defer ws.Close()
+	require.Eventually(t, func() bool { return feed.Count() == 1 }, time.Second, 10*time.Millisecond)
+
+	// not matching the filter
+	c2.Name = "disconnected"
+	require.NoError(t, repo.Save(c2))
+
+	c1.Name = "renamed"
+	require.NoError(t, repo.Save(c1))
+	var e clientfeed.Event
+	require.NoError(t, ws.ReadJSON(&e))
+	assert.Equal(t, clientfeed.EventUpdated, e.Type)
+	assert.Equal(t, "client-1", e.ClientID)
+	assert.Equal(t, map[string]interface{}{"id": "client-1", "name": "renamed"}, e.Client)
+
+	require.NoError(t, repo.Delete(c1))
+	e = clientfeed.Event{}
+	require.NoError(t, ws.ReadJSON(&e))
+	assert.Equal(t, clientfeed.Event{Type: clientfeed.EventRemoved, ClientID: "client-1"}, e)
+
+	require.NoError(t, ws.Close())
+	assert.Eventually(t, func() bool { return feed.Count() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestHandleClientsWSClosedOnRevokedSession(t *testing.T) {
+	defaultInterval := clientsWSSessionCheckInterval
+	clientsWSSessionCheckInterval = 10 * time.Millisecond
+	defer func() { clientsWSSessionCheckInterval = defaultInterval }()
+
+	al, adminUser := setupTestAPIListenerUserAPISessions(t, nil)
+	feed := clientfeed.New(testLog)
+	al.Server.clientFeed = feed
+	al.Server.clientGroupProvider = mockClientGroupProvider{}
+	s := httptest.NewServer(al.router)
+	defer s.Close()
+
+	ctx := context.Background()
+	token, err := bearer.CreateAuthToken(ctx, al.apiSessions, al.config.API.JWTSecret, time.Hour, adminUser.Username, []bearer.Scope{}, "", "")
+	require.NoError(t, err)
+
+	ws, _, err := websocket.DefaultDialer.Dial(httpToWS(t, s.URL)+"/api/v1/ws/clients?access_token="+token, nil)
+	require.NoError(t, err)
+	defer ws.Close()
+	require.Eventually(t, func() bool { return feed.Count() == 1 }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, al.apiSessions.DeleteAllByUser(ctx, adminUser.Username))
+
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(time.Second)))
+	_, _, err = ws.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), err)
+	assert.Eventually(t, func() bool { return feed.Count() == 0 }, time.Second, 10*time.Millisecond)
+}
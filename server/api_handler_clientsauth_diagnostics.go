@@ -0,0 +1,179 @@
+package chserver
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/clientsauth"
+	"github.com/realvnc-labs/rport/server/routes"
+)
+
+const (
+	DiagnosticInvalidPassword   = "invalid_password"
+	DiagnosticTooManyAttempts   = "too_many_attempts"
+	DiagnosticWrongFingerprint  = "wrong_fingerprint"
+	DiagnosticProtocolMismatch  = "protocol_mismatch"
+	DiagnosticVersionRejected   = "version_rejected"
+	DiagnosticClientIDConflict  = "client_id_conflict"
+	DiagnosticClientAuthInUse   = "client_auth_in_use"
+	DiagnosticClockSkew         = "clock_skew"
+	DiagnosticNoConnectAttempts = "no_connection_attempts"
+)
+
+type ClientAuthDiagnostics struct {
+	ClientAuthID   string                         `json:"client_auth_id"`
+	Fingerprint    string                         `json:"fingerprint"`
+	ConnectCommand string                         `json:"connect_command"`
+	ClientConfig   string                         `json:"client_config"`
+	Clients        []*ClientAuthDiagnosticsClient `json:"clients"`
+	RecentFailures []clientsauth.HandshakeFailure `json:"recent_failures"`
+	Issues         []*DiagnosticIssue             `json:"issues"`
+}
+
+type ClientAuthDiagnosticsClient struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Address   string   `json:"address"`
+	Connected bool     `json:"connected"`
+	ClockSkew *float64 `json:"clock_skew"`
+}
+
+type DiagnosticIssue struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// handleGetClientAuthDiagnostics handles GET /clients-auth/{client_auth_id}/diagnostics
+// It returns the command to connect a client with the client auth and troubleshoots failed connection attempts.
+func (al *APIListener) handleGetClientAuthDiagnostics(w http.ResponseWriter, req *http.Request) {
+	clientAuthID := mux.Vars(req)[routes.ParamClientAuthID]
+	clientAuth, err := al.clientAuthProvider.Get(clientAuthID)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if clientAuth == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("Client Auth with ID %q not found", clientAuthID))
+		return
+	}
+
+	clockSkewThreshold := al.config.Server.AlertingClockSkewThreshold
+	if clockSkewThreshold == 0 {
+		clockSkewThreshold = chconfig.DefaultClockSkewThreshold
+	}
+
+	diagnostics := newClientAuthDiagnostics(clientAuth, al.config.Server.URL, al.fingerprint)
+	diagnostics.diagnose(al.clientService.GetAllByClientID(clientAuthID), al.handshakeFailures, clockSkewThreshold)
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(diagnostics))
+}
+
+func newClientAuthDiagnostics(clientAuth *clientsauth.ClientAuth, serverURLs []string, fingerprint string) *ClientAuthDiagnostics {
+	auth := clientAuth.ID + ":" + clientAuth.Password
+
+	var serverURL string
+	var fallbackServers []string
+	if len(serverURLs) > 0 {
+		serverURL = serverURLs[0]
+		fallbackServers = serverURLs[1:]
+	}
+
+	connectCommand := fmt.Sprintf("rport --fingerprint %s --auth %s", fingerprint, shellQuote(auth))
+	for _, fallback := range fallbackServers {
+		connectCommand += " --fallback-server " + shellQuote(fallback)
+	}
+	connectCommand += " " + shellQuote(serverURL)
+
+	var config strings.Builder
+	config.WriteString("[client]\n")
+	fmt.Fprintf(&config, "  server = %s\n", strconv.Quote(serverURL))
+	if len(fallbackServers) > 0 {
+		quoted := make([]string, 0, len(fallbackServers))
+		for _, fallback := range fallbackServers {
+			quoted = append(quoted, strconv.Quote(fallback))
+		}
+		fmt.Fprintf(&config, "  fallback_servers = [%s]\n", strings.Join(quoted, ", "))
+	}
+	fmt.Fprintf(&config, "  fingerprint = %s\n", strconv.Quote(fingerprint))
+	fmt.Fprintf(&config, "  auth = %s\n", strconv.Quote(auth))
+
+	return &ClientAuthDiagnostics{
+		ClientAuthID:   clientAuth.ID,
+		Fingerprint:    fingerprint,
+		ConnectCommand: connectCommand,
+		ClientConfig:   config.String(),
+		Clients:        []*ClientAuthDiagnosticsClient{},
+		Issues:         []*DiagnosticIssue{},
+	}
+}
+
+// diagnose checks the clients using the client auth and troubleshoots the recent failed handshakes.
+func (d *ClientAuthDiagnostics) diagnose(clients []*clientdata.Client, failures *clientsauth.HandshakeFailures, clockSkewThreshold time.Duration) {
+	var ips []string
+	var connected bool
+	for _, c := range clients {
+		dc := &ClientAuthDiagnosticsClient{
+			ID:        c.GetID(),
+			Name:      c.GetName(),
+			Address:   c.GetAddress(),
+			Connected: c.IsConnected(),
+			ClockSkew: c.GetClockSkew(),
+		}
+		d.Clients = append(d.Clients, dc)
+		ips = append(ips, dc.Address)
+		connected = connected || dc.Connected
+
+		if dc.ClockSkew != nil && math.Abs(*dc.ClockSkew) > clockSkewThreshold.Seconds() {
+			d.addIssue(DiagnosticClockSkew, fmt.Sprintf(
+				"The clock of client %s is off by %.0f seconds. Sync the time of the client, e.g. via NTP, time-based one-time passwords fail if the clock is off by more than %s.",
+				dc.ID, *dc.ClockSkew, clockSkewThreshold,
+			))
+		}
+	}
+
+	d.RecentFailures = failures.Find(d.ClientAuthID, ips)
+	if len(d.RecentFailures) == 0 {
+		if !connected {
+			d.addIssue(DiagnosticNoConnectAttempts, "No connection attempts in the last 24 hours. Make sure the client is running and the server address and port are not blocked by a firewall or proxy on the way to the server.")
+		}
+		return
+	}
+
+	// only the latest failure is relevant, earlier failures might be fixed already
+	switch latest := d.RecentFailures[0]; latest.Reason {
+	case clientsauth.HandshakeInvalidPassword:
+		d.addIssue(DiagnosticInvalidPassword, "The client sends a wrong password. Update the auth of the client config, see client_config.")
+	case clientsauth.HandshakeTooManyAttempts:
+		d.addIssue(DiagnosticTooManyAttempts, "The client auth is temporarily blocked after too many failed attempts. Fix the password of the client config and wait for the client to reconnect.")
+	case clientsauth.HandshakeClosedByClient:
+		d.addIssue(DiagnosticWrongFingerprint, fmt.Sprintf("The client closed the connection during the handshake, most likely the fingerprint of the client config doesn't match the server fingerprint %s.", d.Fingerprint))
+	case clientsauth.HandshakeProtocolMismatch:
+		d.addIssue(DiagnosticProtocolMismatch, "The client uses an incompatible protocol version. Update the client to a version matching the server.")
+	case clientsauth.HandshakeVersionRejected:
+		d.addIssue(DiagnosticVersionRejected, "The client version is lower than the min client version required by the server. Update the client.")
+	case clientsauth.HandshakeClientIDConflict:
+		d.addIssue(DiagnosticClientIDConflict, fmt.Sprintf("Another client with the id %s is connected. Cloned clients share the id, set a unique id in the client config or use a client id policy of the server.", latest.ClientID))
+	case clientsauth.HandshakeClientAuthInUse:
+		d.addIssue(DiagnosticClientAuthInUse, "The client auth is used by another client. Create a client auth per client or enable auth_multiuse_creds on the server.")
+	}
+}
+
+func (d *ClientAuthDiagnostics) addIssue(code, message string) {
+	d.Issues = append(d.Issues, &DiagnosticIssue{
+		Code:    code,
+		Message: message,
+	})
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
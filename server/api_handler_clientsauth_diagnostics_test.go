@@ -0,0 +1,121 @@
+package chserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/clientsauth"
+)
+
+func TestHandleGetClientAuthDiagnostics(t *testing.T) {
+	connected := clients.New(t).ID("client-1").ClientAuthID(cl1.ID).Connection(&mockConnection{}).Logger(testLog).Build()
+	connected.Address = "192.0.2.1"
+	connected.SetClockSkew(-2 * time.Minute)
+	disconnected := clients.New(t).ID("client-2").ClientAuthID(cl2.ID).DisconnectedDuration(time.Hour).Logger(testLog).Build()
+	disconnected.Address = "192.0.2.2"
+
+	failures := clientsauth.NewHandshakeFailures()
+	failures.Add(clientsauth.HandshakeFailure{ClientAuthID: cl2.ID, IP: "192.0.2.2", Reason: clientsauth.HandshakeInvalidPassword})
+	failures.Add(clientsauth.HandshakeFailure{IP: "192.0.2.2", Reason: clientsauth.HandshakeClosedByClient, Error: "unexpected EOF"})
+
+	hour := time.Hour
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			config: &chconfig.Config{
+				Server: chconfig.ServerConfig{
+					URL: []string{"https://rport.example.com", "https://fallback.example.com"},
+				},
+			},
+			clientAuthProvider: clientsauth.NewMockFileProvider([]*clientsauth.ClientAuth{cl1, cl2, cl3}, t),
+			clientService:      clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{connected, disconnected}, &hour, testLog), testLog, nil),
+			handshakeFailures:  failures,
+		},
+		fingerprint: "36:98:56:12:f3:dc:e5:8d:ac:96:48:23:b6:f0:42:15",
+		Logger:      testLog,
+	}
+	al.initRouter()
+
+	testCases := []struct {
+		clientAuthID     string
+		wantStatus       int
+		wantClients      []string
+		wantFailures     int
+		wantIssues       []string
+		wantConnectCmd   string
+		wantClientConfig string
+	}{
+		{
+			clientAuthID: cl1.ID,
+			wantStatus:   http.StatusOK,
+			wantClients:  []string{"client-1"},
+			wantIssues:   []string{DiagnosticClockSkew},
+			wantConnectCmd: "rport --fingerprint 36:98:56:12:f3:dc:e5:8d:ac:96:48:23:b6:f0:42:15 --auth 'user1:pswd1' " +
+				"--fallback-server 'https://fallback.example.com' 'https://rport.example.com'",
+			wantClientConfig: "[client]\n" +
+				"  server = \"https://rport.example.com\"\n" +
+				"  fallback_servers = [\"https://fallback.example.com\"]\n" +
+				"  fingerprint = \"36:98:56:12:f3:dc:e5:8d:ac:96:48:23:b6:f0:42:15\"\n" +
+				"  auth = \"user1:pswd1\"\n",
+		},
+		{
+			clientAuthID: cl2.ID,
+			wantStatus:   http.StatusOK,
+			wantClients:  []string{"client-2"},
+			wantFailures: 2,
+			wantIssues:   []string{DiagnosticWrongFingerprint},
+		},
+		{
+			clientAuthID: cl3.ID,
+			wantStatus:   http.StatusOK,
+			wantClients:  []string{},
+			wantIssues:   []string{DiagnosticNoConnectAttempts},
+		},
+		{
+			clientAuthID: "unknown",
+			wantStatus:   http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.clientAuthID, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/clients-auth/"+tc.clientAuthID+"/diagnostics", nil)
+			w := httptest.NewRecorder()
+			al.router.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantStatus, w.Code, w.Body.String())
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+
+			var gotResp struct {
+				Data ClientAuthDiagnostics `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &gotResp))
+			gotClients := []string{}
+			for _, c := range gotResp.Data.Clients {
+				gotClients = append(gotClients, c.ID)
+			}
+			assert.Equal(t, tc.wantClients, gotClients)
+			assert.Len(t, gotResp.Data.RecentFailures, tc.wantFailures)
+			gotIssues := []string{}
+			for _, issue := range gotResp.Data.Issues {
+				gotIssues = append(gotIssues, issue.Code)
+			}
+			assert.Equal(t, tc.wantIssues, gotIssues)
+			if tc.wantConnectCmd != "" {
+				assert.Equal(t, tc.wantConnectCmd, gotResp.Data.ConnectCommand)
+				assert.Equal(t, tc.wantClientConfig, gotResp.Data.ClientConfig)
+			}
+		})
+	}
+}
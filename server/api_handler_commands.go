@@ -10,8 +10,11 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/realvnc-labs/rport/server/api"
+	errors2 "github.com/realvnc-labs/rport/server/api/errors"
 	"github.com/realvnc-labs/rport/server/api/jobs"
 	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/clients/clientvars"
 	"github.com/realvnc-labs/rport/server/routes"
 	"github.com/realvnc-labs/rport/server/validation"
 	"github.com/realvnc-labs/rport/share/comm"
@@ -39,6 +42,8 @@ type jobPayload struct {
 	Result      **jobResult `json:"result,omitempty"`
 	IsSudo      *bool       `json:"is_sudo,omitempty"`
 	IsScript    *bool       `json:"is_script,omitempty"`
+	IsCapture   *bool       `json:"is_capture,omitempty"`
+	Labels      *[]string   `json:"labels,omitempty"`
 }
 
 type jobResult struct {
@@ -47,7 +52,13 @@ type jobResult struct {
 	Summary *string `json:"summary,omitempty"`
 }
 
-func convertToJobsPayload(jobs []*models.Job, fields []query.FieldsOption) []jobPayload {
+// jobPayloadConverter converts jobs to payloads containing the requested fields only.
+type jobPayloadConverter struct {
+	requestedFields       map[string]bool
+	requestedResultFields map[string]bool
+}
+
+func newJobPayloadConverter(fields []query.FieldsOption) jobPayloadConverter {
 	requestedFields := query.RequestedFields(fields, "jobs")
 	if len(requestedFields) == 0 {
 		requestedFields = query.RequestedFields(fields, "commands")
@@ -55,83 +66,94 @@ func convertToJobsPayload(jobs []*models.Job, fields []query.FieldsOption) []job
 			requestedFields = query.RequestedFields(fields, "scripts")
 		}
 	}
-	requestedResultFields := query.RequestedFields(fields, "result")
+	return jobPayloadConverter{
+		requestedFields:       requestedFields,
+		requestedResultFields: query.RequestedFields(fields, "result"),
+	}
+}
 
-	result := make([]jobPayload, len(jobs))
-	for i, job := range jobs {
-		if requestedFields["jid"] {
-			result[i].JID = &job.JID
-		}
-		if requestedFields["status"] {
-			result[i].Status = &job.Status
-		}
-		if requestedFields["finished_at"] {
-			result[i].FinishedAt = &job.FinishedAt
-		}
-		if requestedFields["client_id"] {
-			result[i].ClientID = &job.ClientID
-		}
-		if requestedFields["client_name"] {
-			result[i].ClientName = &job.ClientName
-		}
-		if requestedFields["command"] {
-			result[i].Command = &job.Command
-		}
-		if requestedFields["cwd"] {
-			result[i].Cwd = &job.Cwd
-		}
-		if requestedFields["interpreter"] {
-			result[i].Interpreter = &job.Interpreter
-		}
-		if requestedFields["pid"] {
-			result[i].PID = &job.PID
-		}
-		if requestedFields["started_at"] {
-			result[i].StartedAt = &job.StartedAt
-		}
-		if requestedFields["created_by"] {
-			result[i].CreatedBy = &job.CreatedBy
-		}
-		if requestedFields["timeout_sec"] {
-			result[i].TimeoutSec = &job.TimeoutSec
-		}
-		if requestedFields["multi_job_id"] {
-			result[i].MultiJobID = &job.MultiJobID
-		}
-		if requestedFields["schedule_id"] {
-			result[i].ScheduleID = &job.ScheduleID
-		}
-		if requestedFields["error"] {
-			result[i].Error = &job.Error
-		}
-		if requestedFields["is_sudo"] {
-			result[i].IsSudo = &job.IsSudo
-		}
-		if requestedFields["is_script"] {
-			result[i].IsScript = &job.IsScript
-		}
-		if len(requestedResultFields) > 0 {
-			result[i].Result = new(*jobResult)
-			if job.Result != nil {
-				(*result[i].Result) = &jobResult{}
-				if requestedResultFields["stdout"] {
-					(*result[i].Result).StdOut = &job.Result.StdOut
-				}
-				if requestedResultFields["stderr"] {
-					(*result[i].Result).StdErr = &job.Result.StdErr
-				}
-				if requestedResultFields["summary"] {
-					(*result[i].Result).Summary = &job.Result.Summary
-				}
+func (c jobPayloadConverter) convert(job *models.Job) jobPayload {
+	var p jobPayload
+	if c.requestedFields["jid"] {
+		p.JID = &job.JID
+	}
+	if c.requestedFields["status"] {
+		p.Status = &job.Status
+	}
+	if c.requestedFields["finished_at"] {
+		p.FinishedAt = &job.FinishedAt
+	}
+	if c.requestedFields["client_id"] {
+		p.ClientID = &job.ClientID
+	}
+	if c.requestedFields["client_name"] {
+		p.ClientName = &job.ClientName
+	}
+	if c.requestedFields["command"] {
+		p.Command = &job.Command
+	}
+	if c.requestedFields["cwd"] {
+		p.Cwd = &job.Cwd
+	}
+	if c.requestedFields["interpreter"] {
+		p.Interpreter = &job.Interpreter
+	}
+	if c.requestedFields["pid"] {
+		p.PID = &job.PID
+	}
+	if c.requestedFields["started_at"] {
+		p.StartedAt = &job.StartedAt
+	}
+	if c.requestedFields["created_by"] {
+		p.CreatedBy = &job.CreatedBy
+	}
+	if c.requestedFields["timeout_sec"] {
+		p.TimeoutSec = &job.TimeoutSec
+	}
+	if c.requestedFields["multi_job_id"] {
+		p.MultiJobID = &job.MultiJobID
+	}
+	if c.requestedFields["schedule_id"] {
+		p.ScheduleID = &job.ScheduleID
+	}
+	if c.requestedFields["error"] {
+		p.Error = &job.Error
+	}
+	if c.requestedFields["is_sudo"] {
+		p.IsSudo = &job.IsSudo
+	}
+	if c.requestedFields["is_script"] {
+		p.IsScript = &job.IsScript
+	}
+	if c.requestedFields["is_capture"] {
+		p.IsCapture = &job.IsCapture
+	}
+	if c.requestedFields["labels"] {
+		p.Labels = &job.Labels
+	}
+	if len(c.requestedResultFields) > 0 {
+		p.Result = new(*jobResult)
+		if job.Result != nil {
+			(*p.Result) = &jobResult{}
+			if c.requestedResultFields["stdout"] {
+				(*p.Result).StdOut = &job.Result.StdOut
+			}
+			if c.requestedResultFields["stderr"] {
+				(*p.Result).StdErr = &job.Result.StdErr
+			}
+			if c.requestedResultFields["summary"] {
+				(*p.Result).Summary = &job.Result.Summary
 			}
 		}
 	}
 
-	return result
+	return p
 }
 
 type newJobResponse struct {
 	JID string `json:"jid"`
+	// Unreachable are the clients that failed the pre-flight check of a multi-client job
+	Unreachable []jobs.UnreachableClient `json:"unreachable,omitempty"`
 }
 
 // handlePostCommand handles POST /clients/{client_id}/commands
@@ -159,6 +181,7 @@ func (al *APIListener) handlePostCommand(w http.ResponseWriter, req *http.Reques
 			WithHTTPRequest(req).
 			WithClientID(cid).
 			WithRequest(execCmdInput).
+			WithLabels(execCmdInput.Labels).
 			WithResponse(resp).
 			WithID(resp.JID).
 			Save()
@@ -198,11 +221,10 @@ func (al *APIListener) handleGetCommands(w http.ResponseWriter, req *http.Reques
 		return
 	}
 
-	payload := &api.SuccessPayload{
-		Data: convertToJobsPayload(result, options.Fields),
-		Meta: api.NewMeta(totalCount),
-	}
-	al.writeJSONResponse(w, http.StatusOK, payload)
+	converter := newJobPayloadConverter(options.Fields)
+	al.writeJSONListResponse(w, http.StatusOK, len(result), func(i int) interface{} {
+		return converter.convert(result[i])
+	}, api.NewMeta(totalCount))
 }
 
 // handleGetMultiClientCommandJobs handles GET /commands/{job_id}/jobs
@@ -238,11 +260,10 @@ func (al *APIListener) handleGetMultiClientCommandJobs(w http.ResponseWriter, re
 		return
 	}
 
-	payload := &api.SuccessPayload{
-		Data: convertToJobsPayload(result, options.Fields),
-		Meta: api.NewMeta(totalCount),
-	}
-	al.writeJSONResponse(w, http.StatusOK, payload)
+	converter := newJobPayloadConverter(options.Fields)
+	al.writeJSONListResponse(w, http.StatusOK, len(result), func(i int) interface{} {
+		return converter.convert(result[i])
+	}, api.NewMeta(totalCount))
 }
 
 // handleGetCommand handles GET /clients/{client_id}/commands/{job_id}
@@ -291,12 +312,24 @@ func (al *APIListener) handlePostMultiClientCommand(w http.ResponseWriter, req *
 		al.jsonErrorResponseWithError(w, http.StatusBadRequest, "Invalid interpreter.", err)
 		return
 	}
+	if err := validation.ValidateLabels(reqBody.Labels); err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusBadRequest, "Invalid labels.", err)
+		return
+	}
+	if err := reqBody.ValidatePreflight(); err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusBadRequest, "Invalid pre-flight check.", err)
+		return
+	}
 
 	orderedClients, _, responseErr := al.getOrderedClientsWithValidation(ctx, &reqBody)
 	if responseErr != nil {
 		al.jsonError(w, responseErr)
 		return
 	}
+	if err := checkClientsInterpreter(reqBody.Interpreter, orderedClients); err != nil {
+		al.jsonError(w, err)
+		return
+	}
 
 	reqBody.OrderedClients = orderedClients
 
@@ -319,6 +352,12 @@ func (al *APIListener) handlePostMultiClientCommand(w http.ResponseWriter, req *
 
 	reqBody.Username = curUser.Username
 
+	unreachable, err := al.runPreflightCheck(ctx, &reqBody)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
 	multiJob, err := al.StartMultiClientJob(ctx, &reqBody)
 	if err != nil {
 		al.jsonError(w, err)
@@ -326,15 +365,17 @@ func (al *APIListener) handlePostMultiClientCommand(w http.ResponseWriter, req *
 	}
 
 	resp := newJobResponse{
-		JID: multiJob.JID,
+		JID:         multiJob.JID,
+		Unreachable: unreachable,
 	}
 
 	al.auditLog.Entry(auditlog.ApplicationClientCommand, auditlog.ActionExecuteStart).
 		WithHTTPRequest(req).
 		WithRequest(reqBody).
+		WithLabels(reqBody.Labels).
 		WithResponse(resp).
 		WithID(multiJob.JID).
-		SaveForMultipleClients(reqBody.OrderedClients)
+		SaveForMultipleClients(reqBody.TargetClients())
 
 	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(resp))
 
@@ -350,6 +391,10 @@ func (al *APIListener) handleExecuteCommand(ctx context.Context, w http.Response
 		al.jsonErrorResponseWithError(w, http.StatusBadRequest, "Invalid interpreter.", err)
 		return nil
 	}
+	if err := validation.ValidateLabels(executeInput.Labels); err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusBadRequest, "Invalid labels.", err)
+		return nil
+	}
 
 	if executeInput.TimeoutSec <= 0 {
 		executeInput.TimeoutSec = al.config.Server.RunRemoteCmdTimeoutSec
@@ -361,7 +406,7 @@ func (al *APIListener) handleExecuteCommand(ctx context.Context, w http.Response
 		return nil
 	}
 	if client == nil {
-		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("Active client with id=%q not found.", executeInput.ClientID))
+		al.jsonErrorResponseWithErrCode(w, http.StatusNotFound, errors2.ErrCodeClientNotActive, fmt.Sprintf("Active client with id=%q not found.", executeInput.ClientID))
 		return nil
 	}
 
@@ -370,6 +415,27 @@ func (al *APIListener) handleExecuteCommand(ctx context.Context, w http.Response
 		return nil
 	}
 
+	if client.IsCheckInOnly() {
+		al.jsonErrorResponseWithErrCode(w, http.StatusConflict, errors2.ErrCodeClientCheckInOnly, fmt.Sprintf("failed to execute command/script for client with id %s: %v", client.GetID(), ErrClientCheckInOnly))
+		return nil
+	}
+
+	if err := client.CheckQuarantine(); err != nil {
+		al.jsonErrorResponseWithErrCode(w, http.StatusConflict, errors2.ErrCodeClientQuarantined, fmt.Sprintf("failed to execute command/script for client with id %s: %v", client.GetID(), err))
+		return nil
+	}
+
+	if err := checkClientsInterpreter(executeInput.Interpreter, []*clientdata.Client{client}); err != nil {
+		al.jsonError(w, err)
+		return nil
+	}
+
+	command, err := clientvars.Resolve(executeInput.Command, client)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusBadRequest, "Invalid client variables.", err)
+		return nil
+	}
+
 	// send the command to the client
 	// Send a job with all possible info in order to get the full-populated job back (in client-listener) when it's done.
 	// Needed when server restarts to get all job data from client. Because on server restart job running info is lost.
@@ -383,7 +449,7 @@ func (al *APIListener) handleExecuteCommand(ctx context.Context, w http.Response
 		FinishedAt:  nil,
 		ClientID:    executeInput.ClientID,
 		ClientName:  client.GetName(),
-		Command:     executeInput.Command,
+		Command:     command,
 		Interpreter: executeInput.Interpreter,
 		CreatedBy:   api.GetUser(ctx, al.Logger),
 		TimeoutSec:  executeInput.TimeoutSec,
@@ -391,6 +457,9 @@ func (al *APIListener) handleExecuteCommand(ctx context.Context, w http.Response
 		Cwd:         executeInput.Cwd,
 		IsSudo:      executeInput.IsSudo,
 		IsScript:    executeInput.IsScript,
+		Labels:      executeInput.Labels,
+
+		CorrelationID: api.GetRequestID(ctx),
 	}
 	sshResp := &comm.RunCmdResponse{}
 	err = comm.SendRequestAndGetResponse(client.GetConnection(), comm.RequestTypeRunCmd, curJob, sshResp, al.Log())
@@ -459,33 +528,44 @@ func (al *APIListener) handleCommandsWS(w http.ResponseWriter, req *http.Request
 
 // handleGetMultiClientCommand handles GET /commands/{job_id}
 func (al *APIListener) handleGetMultiClientCommand(w http.ResponseWriter, req *http.Request) {
+	job := al.getMultiJobOfCurrentUser(w, req)
+	if job == nil {
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(job))
+}
+
+// getMultiJobOfCurrentUser returns the multi-client job of the request if the current user created it or can read all
+// items, otherwise it writes the error response and returns nil.
+func (al *APIListener) getMultiJobOfCurrentUser(w http.ResponseWriter, req *http.Request) *models.MultiJob {
 	vars := mux.Vars(req)
 	jid := vars[routes.ParamJobID]
 	if jid == "" {
 		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Missing %q route param.", routes.ParamJobID))
-		return
+		return nil
 	}
 
 	job, err := al.jobProvider.GetMultiJob(req.Context(), jid)
 	if err != nil {
 		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to find a multi-client job[id=%q].", jid), err)
-		return
+		return nil
 	}
 	if job == nil {
 		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("Multi-client Job[id=%q] not found.", jid))
-		return
+		return nil
 	}
 
 	curUser, err := al.getUserModelForAuth(req.Context())
 	if err != nil {
 		al.jsonError(w, err)
-		return
+		return nil
 	}
-	if curUser.IsAdmin() || job.CreatedBy == curUser.Username {
-		al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(job))
-		return
+	if !curUser.CanReadAll() && job.CreatedBy != curUser.Username {
+		al.jsonErrorResponseWithError(w, http.StatusForbidden, "forbidden", fmt.Errorf("you are not allowed to access items created by another user"))
+		return nil
 	}
-	al.jsonErrorResponseWithError(w, http.StatusForbidden, "forbidden", fmt.Errorf("you are not allowed to access items created by another user"))
+	return job
 }
 
 // handleGetMultiClientCommands handles GET /commands
@@ -0,0 +1,100 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/jobs"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+const (
+	multiJobStatusWaitQueryParam = "wait"
+	maxMultiJobStatusWait        = time.Minute
+)
+
+var multiJobStatusPollInterval = time.Second
+
+type multiJobStatusPayload struct {
+	*jobs.MultiJobStatus
+	Jobs []interface{} `json:"jobs"`
+}
+
+// handleGetMultiClientCommandStatus handles GET /commands/{job_id}/status
+// It returns the aggregated status of a multi-client job and the status of the jobs of the clients, optionally filtered
+// e.g. by filter[status]=failed. With the wait query param the response is delayed until the job is finished or the
+// wait duration is over.
+func (al *APIListener) handleGetMultiClientCommandStatus(w http.ResponseWriter, req *http.Request) {
+	options := query.NewOptions(req, nil, nil, jobs.MultiJobStatusDefaultFields)
+	err := query.ValidateListOptions(options, jobs.JobSupportedSorts, jobs.MultiJobStatusSupportedFilters, jobs.JobSupportedFields, &query.PaginationConfig{
+		MaxLimit:     jobs.MaxLimit,
+		DefaultLimit: jobs.MaxLimit,
+	})
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	wait, ok := al.parseDurationQueryParam(w, req, multiJobStatusWaitQueryParam, 0)
+	if !ok {
+		return
+	}
+	if wait > maxMultiJobStatusWait {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s: max %s is allowed.", multiJobStatusWaitQueryParam, maxMultiJobStatusWait))
+		return
+	}
+
+	multiJob := al.getMultiJobOfCurrentUser(w, req)
+	if multiJob == nil {
+		return
+	}
+
+	ctx := req.Context()
+	deadline := time.Now().Add(wait)
+	var status *jobs.MultiJobStatus
+	for {
+		counts, err := al.jobProvider.CountMultiJobStatuses(ctx, multiJob.JID)
+		if err != nil {
+			al.jsonErrorResponseWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get status of multi-client job[id=%q].", multiJob.JID), err)
+			return
+		}
+		status = jobs.NewMultiJobStatus(multiJob, counts)
+		if status.Finished || !time.Now().Add(multiJobStatusPollInterval).Before(deadline) {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(multiJobStatusPollInterval):
+		}
+	}
+
+	options.Filters = append(options.Filters, query.FilterOption{Column: []string{"multi_job_id"}, Values: []string{multiJob.JID}})
+	result, err := al.jobProvider.List(ctx, options)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get jobs: multi_job_id=%q.", multiJob.JID), err)
+		return
+	}
+
+	totalCount, err := al.jobProvider.Count(ctx, options)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get jobs: multi_job_id=%q.", multiJob.JID), err)
+		return
+	}
+
+	converter := newJobPayloadConverter(options.Fields)
+	payload := multiJobStatusPayload{
+		MultiJobStatus: status,
+		Jobs:           make([]interface{}, 0, len(result)),
+	}
+	for _, job := range result {
+		payload.Jobs = append(payload.Jobs, converter.convert(job))
+	}
+	al.writeJSONResponse(w, http.StatusOK, &api.SuccessPayload{
+		Data: payload,
+		Meta: api.NewMeta(totalCount),
+	})
+}
@@ -0,0 +1,118 @@
+package chserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	jobsmigration "github.com/realvnc-labs/rport/db/migration/jobs"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/jobs"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/test/jb"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func TestHandleGetMultiClientCommandStatus(t *testing.T) {
+	jobsDB, err := sqlite.New(":memory:", jobsmigration.AssetNames(), jobsmigration.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	jp := jobs.NewSqliteProvider(jobsDB, testLog)
+	defer jp.Close()
+
+	multiJob := jb.NewMulti(t).JID("multi-1").Concurrent(true).Build()
+	multiJob.CreatedBy = "alice"
+	multiJob.ClientCount = 2
+	require.NoError(t, jp.SaveMultiJob(multiJob))
+	failedJob := jb.New(t).ClientID("client-1").MultiJobID("multi-1").Status(models.JobStatusFailed).FinishedAt(time.Now()).Build()
+	runningJob := jb.New(t).ClientID("client-2").MultiJobID("multi-1").Status(models.JobStatusRunning).Build()
+	require.NoError(t, jp.SaveJob(failedJob))
+	require.NoError(t, jp.SaveJob(runningJob))
+
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+			jobProvider: jp,
+		},
+		userService: users.NewAPIService(users.NewStaticProvider([]*users.User{
+			{Username: "alice"},
+			{Username: "bob"},
+		}), false, 0, -1),
+		Logger: testLog,
+	}
+	al.initRouter()
+	multiJobStatusPollInterval = 10 * time.Millisecond
+
+	type statusResponse struct {
+		Data struct {
+			jobs.MultiJobStatus
+			Jobs []struct {
+				ClientID string `json:"client_id"`
+				Status   string `json:"status"`
+			} `json:"jobs"`
+		} `json:"data"`
+		Meta struct {
+			Count int `json:"count"`
+		} `json:"meta"`
+	}
+	get := func(username, query string) (*httptest.ResponseRecorder, statusResponse) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/commands/multi-1/status?"+query, nil)
+		req = req.WithContext(api.WithUser(context.Background(), username))
+		w := httptest.NewRecorder()
+		al.router.ServeHTTP(w, req)
+		var resp statusResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w, resp
+	}
+
+	w, resp := get("alice", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, 2, resp.Data.Total)
+	assert.Equal(t, 1, resp.Data.Failed)
+	assert.Equal(t, 1, resp.Data.Running)
+	assert.False(t, resp.Data.Finished)
+	assert.Len(t, resp.Data.Jobs, 2)
+	assert.Equal(t, 2, resp.Meta.Count)
+
+	w, resp = get("alice", "filter[status]=failed")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Len(t, resp.Data.Jobs, 1)
+	assert.Equal(t, "client-1", resp.Data.Jobs[0].ClientID)
+	assert.Equal(t, 1, resp.Meta.Count)
+
+	w, _ = get("bob", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w, _ = get("alice", "wait=2m")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, _ = get("alice", "filter[command]=date")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		runningJob.Status = models.JobStatusSuccessful
+		finishedAt := time.Now()
+		runningJob.FinishedAt = &finishedAt
+		assert.NoError(t, jp.SaveJob(runningJob))
+	}()
+	w, resp = get("alice", "wait=10s")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.True(t, resp.Data.Finished)
+	assert.Equal(t, 1, resp.Data.Successful)
+	assert.Equal(t, 0, resp.Data.Running)
+}
@@ -0,0 +1,47 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/jobs"
+)
+
+// handleGetMultiClientCommandSummary handles GET /commands/{job_id}/summary
+// It returns the aggregated results of a multi-client job: the counts by status, the stats of the durations and the
+// errors clustered by similarity. The summary is stored once the job is finished.
+func (al *APIListener) handleGetMultiClientCommandSummary(w http.ResponseWriter, req *http.Request) {
+	multiJob := al.getMultiJobOfCurrentUser(w, req)
+	if multiJob == nil {
+		return
+	}
+
+	ctx := req.Context()
+	summary, err := al.jobProvider.GetResultSummary(ctx, multiJob.JID)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get summary of multi-client job[id=%q].", multiJob.JID), err)
+		return
+	}
+	if summary != nil {
+		al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(summary))
+		return
+	}
+
+	results, err := al.jobProvider.ListMultiJobResults(ctx, multiJob.JID)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get jobs: multi_job_id=%q.", multiJob.JID), err)
+		return
+	}
+
+	summary = jobs.NewResultSummary(multiJob, results, time.Now().UTC())
+	if summary.Finished {
+		err = al.jobProvider.SaveResultSummary(ctx, summary)
+		if err != nil {
+			al.Errorf("Failed to save summary of multi-client job %s: %v", multiJob.JID, err)
+		}
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(summary))
+}
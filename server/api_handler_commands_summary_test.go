@@ -0,0 +1,104 @@
+package chserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	jobsmigration "github.com/realvnc-labs/rport/db/migration/jobs"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/jobs"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/test/jb"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func TestHandleGetMultiClientCommandSummary(t *testing.T) {
+	ctx := context.Background()
+	jobsDB, err := sqlite.New(":memory:", jobsmigration.AssetNames(), jobsmigration.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	jp := jobs.NewSqliteProvider(jobsDB, testLog)
+	defer jp.Close()
+
+	multiJob := jb.NewMulti(t).JID("multi-1").Concurrent(true).Build()
+	multiJob.CreatedBy = "alice"
+	multiJob.ClientCount = 2
+	require.NoError(t, jp.SaveMultiJob(multiJob))
+	startedAt := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	failedJob := jb.New(t).ClientID("client-1").MultiJobID("multi-1").Status(models.JobStatusFailed).StartedAt(startedAt).FinishedAt(startedAt.Add(2 * time.Second)).Build()
+	failedJob.Error = "exit status 1"
+	runningJob := jb.New(t).ClientID("client-2").MultiJobID("multi-1").Status(models.JobStatusRunning).StartedAt(startedAt).Build()
+	require.NoError(t, jp.SaveJob(failedJob))
+	require.NoError(t, jp.SaveJob(runningJob))
+
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+			jobProvider: jp,
+		},
+		userService: users.NewAPIService(users.NewStaticProvider([]*users.User{
+			{Username: "alice"},
+			{Username: "bob"},
+		}), false, 0, -1),
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	get := func(username string) (*httptest.ResponseRecorder, *jobs.ResultSummary) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/commands/multi-1/summary", nil)
+		req = req.WithContext(api.WithUser(ctx, username))
+		w := httptest.NewRecorder()
+		al.router.ServeHTTP(w, req)
+		var resp struct {
+			Data *jobs.ResultSummary `json:"data"`
+		}
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w, resp.Data
+	}
+
+	w, summary := get("alice")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, summary.Failed)
+	assert.Equal(t, 1, summary.Running)
+	assert.False(t, summary.Finished)
+	assert.Equal(t, &jobs.DurationStats{Min: 2, Max: 2, Avg: 2, P50: 2, P90: 2, P95: 2, P99: 2}, summary.DurationSec)
+	require.Len(t, summary.ErrorClusters, 1)
+	assert.Equal(t, "exit status <n>", summary.ErrorClusters[0].Signature)
+
+	stored, err := jp.GetResultSummary(ctx, "multi-1")
+	require.NoError(t, err)
+	assert.Nil(t, stored, "summary of a running job must not be stored")
+
+	runningJob.Status = models.JobStatusSuccessful
+	finishedAt := startedAt.Add(4 * time.Second)
+	runningJob.FinishedAt = &finishedAt
+	require.NoError(t, jp.SaveJob(runningJob))
+
+	w, summary = get("alice")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, summary.Finished)
+	assert.Equal(t, 1, summary.Successful)
+
+	stored, err = jp.GetResultSummary(ctx, "multi-1")
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, summary.ComputedAt, stored.ComputedAt)
+
+	w, _ = get("bob")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
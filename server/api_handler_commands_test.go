@@ -23,13 +23,16 @@ import (
 	"github.com/realvnc-labs/rport/db/sqlite"
 	"github.com/realvnc-labs/rport/server/api"
 	"github.com/realvnc-labs/rport/server/api/authorization"
+	errors2 "github.com/realvnc-labs/rport/server/api/errors"
 	"github.com/realvnc-labs/rport/server/api/jobs"
 	"github.com/realvnc-labs/rport/server/api/jobs/schedule"
+	"github.com/realvnc-labs/rport/server/api/middleware"
 	"github.com/realvnc-labs/rport/server/api/users"
 	"github.com/realvnc-labs/rport/server/cgroups"
 	"github.com/realvnc-labs/rport/server/chconfig"
 	"github.com/realvnc-labs/rport/server/clients"
 	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/jobqueue"
 	"github.com/realvnc-labs/rport/server/test/jb"
 	"github.com/realvnc-labs/rport/share/comm"
 	"github.com/realvnc-labs/rport/share/logger"
@@ -129,6 +132,7 @@ func TestHandlePostCommand(t *testing.T) {
 		wantErrTitle    string
 		wantErrDetail   string
 		wantInterpreter string
+		wantLabels      []string
 	}{
 		{
 			name:           "valid cmd",
@@ -147,6 +151,24 @@ func TestHandlePostCommand(t *testing.T) {
 			wantTimeout:     defaultTimeout,
 			wantInterpreter: "powershell",
 		},
+		{
+			name:           "valid cmd with labels",
+			requestBody:    `{"command": "` + gotCmd + `","labels": ["INC-1234", "project-x"]}`,
+			cid:            c1.GetID(),
+			clients:        []*clientdata.Client{c1},
+			wantStatusCode: http.StatusOK,
+			wantTimeout:    defaultTimeout,
+			wantLabels:     []string{"INC-1234", "project-x"},
+		},
+		{
+			name:           "invalid labels",
+			requestBody:    `{"command": "` + gotCmd + `","labels": ["INC-1234,INC-5678"]}`,
+			cid:            c1.GetID(),
+			clients:        []*clientdata.Client{c1},
+			wantStatusCode: http.StatusBadRequest,
+			wantErrTitle:   "Invalid labels.",
+			wantErrDetail:  `label "INC-1234,INC-5678" cannot contain a comma`,
+		},
 		{
 			name:           "invalid interpreter",
 			requestBody:    `{"command": "` + gotCmd + `","interpreter": "unsupported"}`,
@@ -221,6 +243,7 @@ func TestHandlePostCommand(t *testing.T) {
 			clients:        []*clientdata.Client{},
 			wantStatusCode: http.StatusNotFound,
 			wantErrTitle:   fmt.Sprintf("Active client with id=%q not found.", c1.GetID()),
+			wantErrCode:    errors2.ErrCodeClientNotActive,
 		},
 		{
 			name:           "disconnected client",
@@ -229,6 +252,7 @@ func TestHandlePostCommand(t *testing.T) {
 			clients:        []*clientdata.Client{c1, c2},
 			wantStatusCode: http.StatusNotFound,
 			wantErrTitle:   fmt.Sprintf("Active client with id=%q not found.", c2.GetID()),
+			wantErrCode:    errors2.ErrCodeClientNotActive,
 		},
 		{
 			name:            "error on save job",
@@ -327,10 +351,14 @@ func TestHandlePostCommand(t *testing.T) {
 				assert.Equal(t, sshSuccessResp.StartedAt, gotRunningJob.StartedAt)
 				assert.Equal(t, testUser, gotRunningJob.CreatedBy)
 				assert.Equal(t, tc.wantTimeout, gotRunningJob.TimeoutSec)
+				assert.Equal(t, tc.wantLabels, gotRunningJob.Labels)
+				assert.NotEmpty(t, gotRunningJob.CorrelationID)
+				assert.Equal(t, w.Header().Get(middleware.RequestIDHeader), gotRunningJob.CorrelationID)
 				assert.Nil(t, gotRunningJob.Result)
 			} else {
 				// failure case
-				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail)
+				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail).WithDefaultCode(tc.wantStatusCode)
+				wantResp.RequestID = w.Header().Get(middleware.RequestIDHeader)
 				wantRespBytes, err := json.Marshal(wantResp)
 				require.NoError(t, err)
 				require.Equal(t, string(wantRespBytes), w.Body.String())
@@ -339,6 +367,71 @@ func TestHandlePostCommand(t *testing.T) {
 	}
 }
 
+func TestHandlePostCommandClientVariables(t *testing.T) {
+	connMock := test.NewConnMock()
+	connMock.ReturnOk = true
+	connMock.ReturnResponsePayload = []byte(`{"pid":123}`)
+	c1 := clients.New(t).Connection(connMock).Logger(testLog).Build()
+	c1.Name = "web-01"
+	c1.IPv4 = []string{"192.168.1.10"}
+
+	testCases := []struct {
+		name           string
+		command        string
+		wantStatusCode int
+		wantCommand    string
+	}{
+		{
+			name:           "resolved",
+			command:        "/usr/bin/echo {{.client.Name}} {{.client.IPv4 0}}",
+			wantStatusCode: http.StatusOK,
+			wantCommand:    "/usr/bin/echo web-01 192.168.1.10",
+		},
+		{
+			name:           "not resolvable",
+			command:        "/usr/bin/echo {{.client.IPv6 99}}",
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			al := APIListener{
+				insecureForTests: true,
+				Server: &Server{
+					clientService: clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil),
+					config: &chconfig.Config{
+						API: chconfig.APIConfig{
+							MaxRequestBytes: 1024 * 1024,
+						},
+					},
+				},
+				Logger: testLog,
+			}
+			al.initRouter()
+			jp := NewJobProviderMock()
+			al.jobProvider = jp
+
+			body, err := json.Marshal(map[string]string{"command": tc.command})
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/clients/%s/commands", c1.GetID()), strings.NewReader(string(body)))
+
+			w := httptest.NewRecorder()
+			al.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+			if tc.wantStatusCode == http.StatusOK {
+				require.NotNil(t, jp.InputCreateJob)
+				assert.Equal(t, tc.wantCommand, jp.InputCreateJob.Command)
+				_, _, payload := connMock.InputSendRequest()
+				assert.Contains(t, string(payload), tc.wantCommand)
+			} else {
+				assert.Contains(t, w.Body.String(), "client has no IPv6 address with index 99")
+			}
+		})
+	}
+}
+
 func TestHandleGetCommand(t *testing.T) {
 	wantJob := jb.New(t).ClientID("cid-1234").JID("jid-1234").Build()
 	wantJobResp := api.NewSuccessPayload(wantJob)
@@ -413,7 +506,8 @@ func TestHandleGetCommand(t *testing.T) {
 				assert.Equal(t, wantJob.JID, jp.InputJID)
 			} else {
 				// failure case
-				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail)
+				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail).WithDefaultCode(tc.wantStatusCode)
+				wantResp.RequestID = w.Header().Get(middleware.RequestIDHeader)
 				wantRespBytes, err := json.Marshal(wantResp)
 				require.NoError(t, err)
 				require.Equal(t, string(wantRespBytes), w.Body.String())
@@ -521,7 +615,8 @@ func TestHandleGetCommands(t *testing.T) {
 				assert.Equal(t, testCID, jp.InputCID)
 			} else {
 				// failure case
-				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail)
+				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail).WithDefaultCode(tc.wantStatusCode)
+				wantResp.RequestID = w.Header().Get(middleware.RequestIDHeader)
 				wantRespBytes, err := json.Marshal(wantResp)
 				require.NoError(t, err)
 				require.Equal(t, string(wantRespBytes), w.Body.String())
@@ -624,6 +719,7 @@ func TestHandlePostMultiClientCommand(t *testing.T) {
 		}`,
 			wantStatusCode: http.StatusNotFound,
 			wantErrTitle:   fmt.Sprintf("Client with id=%q not found.", "client-4"),
+			wantErrCode:    errors2.ErrCodeClientNotFound,
 		},
 		{
 			name:           "error on send request",
@@ -731,7 +827,8 @@ func TestHandlePostMultiClientCommand(t *testing.T) {
 				}
 			} else {
 				// failure case
-				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail)
+				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail).WithDefaultCode(tc.wantStatusCode)
+				wantResp.RequestID = w.Header().Get(middleware.RequestIDHeader)
 				wantRespBytes, err := json.Marshal(wantResp)
 				require.NoError(t, err)
 				require.Equal(t, string(wantRespBytes), w.Body.String())
@@ -740,6 +837,165 @@ func TestHandlePostMultiClientCommand(t *testing.T) {
 	}
 }
 
+func TestHandlePostMultiClientCommandPreflight(t *testing.T) {
+	testUser := "test-user"
+	curUser := &users.User{
+		Username: testUser,
+		Groups:   []string{users.Administrators},
+	}
+
+	connMock1 := test.NewConnMock()
+	connMock1.ReturnOk = true
+	sshRespBytes, err := json.Marshal(comm.RunCmdResponse{Pid: 1, StartedAt: time.Date(2020, 10, 10, 10, 10, 1, 0, time.UTC)})
+	require.NoError(t, err)
+	connMock1.ReturnResponsePayload = sshRespBytes
+	connMock2 := test.NewConnMock()
+	connMock2.ReturnOk = false
+
+	c1 := clients.New(t).ID("client-1").Connection(connMock1).Logger(testLog).Build()
+	c2 := clients.New(t).ID("client-2").Connection(connMock2).Logger(testLog).Build()
+	c3 := clients.New(t).ID("client-3").DisconnectedDuration(5 * time.Minute).Logger(testLog).Build()
+
+	testCases := []struct {
+		name string
+
+		requestBody string
+
+		wantStatusCode  int
+		wantErrTitle    string
+		wantErrDetail   string
+		wantUnreachable []jobs.UnreachableClient
+		wantClientCount int
+		wantQueued      map[string]int
+	}{
+		{
+			name: "skip unreachable",
+			requestBody: `{
+				"command": "/bin/date",
+				"client_ids": ["client-1", "client-2", "client-3"],
+				"preflight_check": true
+			}`,
+			wantStatusCode: http.StatusOK,
+			wantUnreachable: []jobs.UnreachableClient{
+				{ClientID: "client-2", ClientName: "Random Rport Client", Error: "client rejected the ping", Action: jobs.OnUnreachableSkip},
+				{ClientID: "client-3", ClientName: "Random Rport Client", Error: ErrClientNotConnected.Error(), Action: jobs.OnUnreachableSkip},
+			},
+			wantClientCount: 1,
+			wantQueued:      map[string]int{"client-2": 0, "client-3": 0},
+		},
+		{
+			name: "queue unreachable",
+			requestBody: `{
+				"command": "/bin/date",
+				"client_ids": ["client-1", "client-3"],
+				"execute_concurrently": true,
+				"preflight_check": true,
+				"on_unreachable": "queue",
+				"queue_ttl_sec": 600
+			}`,
+			wantStatusCode: http.StatusOK,
+			wantUnreachable: []jobs.UnreachableClient{
+				{ClientID: "client-3", ClientName: "Random Rport Client", Error: ErrClientNotConnected.Error(), Action: jobs.OnUnreachableQueue},
+			},
+			wantClientCount: 2,
+			wantQueued:      map[string]int{"client-3": 1},
+		},
+		{
+			name: "none reachable",
+			requestBody: `{
+				"command": "/bin/date",
+				"client_ids": ["client-2", "client-3"],
+				"preflight_check": true
+			}`,
+			wantStatusCode: http.StatusConflict,
+			wantErrTitle:   "None of the targeted clients is reachable: client-2, client-3.",
+		},
+		{
+			name: "invalid on_unreachable",
+			requestBody: `{
+				"command": "/bin/date",
+				"client_ids": ["client-1"],
+				"preflight_check": true,
+				"on_unreachable": "wait"
+			}`,
+			wantStatusCode: http.StatusBadRequest,
+			wantErrTitle:   "Invalid pre-flight check.",
+			wantErrDetail:  `on_unreachable must be either "skip" or "queue"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			al := APIListener{
+				insecureForTests: true,
+				Server: &Server{
+					clientService: clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1, c2, c3}, &hour, testLog), testLog, nil),
+					config: &chconfig.Config{
+						Server: chconfig.ServerConfig{
+							RunRemoteCmdTimeoutSec: 60,
+						},
+						API: chconfig.APIConfig{
+							MaxRequestBytes: 1024 * 1024,
+						},
+					},
+					jobsDoneChannel: jobResultChanMap{
+						m: make(map[string]chan *models.Job),
+					},
+					jobQueue:            jobqueue.New(testLog),
+					clientGroupProvider: mockClientGroupProvider{},
+				},
+				userService: users.NewAPIService(users.NewStaticProvider([]*users.User{curUser}), false, 0, -1),
+				Logger:      testLog,
+			}
+			if tc.wantStatusCode == http.StatusOK {
+				al.testDone = make(chan bool)
+			}
+			al.initRouter()
+
+			jobsDB, err := sqlite.New(":memory:", jobsmigration.AssetNames(), jobsmigration.Asset, DataSourceOptions)
+			require.NoError(t, err)
+			jp := jobs.NewSqliteProvider(jobsDB, testLog)
+			defer jp.Close()
+			al.jobProvider = jp
+
+			ctx := api.WithUser(context.Background(), testUser)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/commands", strings.NewReader(tc.requestBody))
+			req = req.WithContext(ctx)
+
+			// when
+			w := httptest.NewRecorder()
+			al.router.ServeHTTP(w, req)
+
+			// then
+			require.Equal(t, tc.wantStatusCode, w.Code, w.Body.String())
+			if tc.wantStatusCode != http.StatusOK {
+				wantResp := api.NewErrAPIPayloadFromMessage("", tc.wantErrTitle, tc.wantErrDetail).WithDefaultCode(tc.wantStatusCode)
+				wantResp.RequestID = w.Header().Get(middleware.RequestIDHeader)
+				wantRespBytes, err := json.Marshal(wantResp)
+				require.NoError(t, err)
+				assert.Equal(t, string(wantRespBytes), w.Body.String())
+				return
+			}
+
+			<-al.testDone
+			var gotResp struct {
+				Data newJobResponse `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &gotResp))
+			assert.Equal(t, tc.wantUnreachable, gotResp.Data.Unreachable)
+
+			gotMultiJob, err := jp.GetMultiJob(ctx, gotResp.Data.JID)
+			require.NoError(t, err)
+			require.NotNil(t, gotMultiJob)
+			assert.Equal(t, tc.wantClientCount, gotMultiJob.ClientCount)
+			for clientID, count := range tc.wantQueued {
+				assert.Equal(t, count, al.jobQueue.Count(clientID), clientID)
+			}
+		})
+	}
+}
+
 func TestHandlePostMultiClientCommandWithPausedClient(t *testing.T) {
 	testUser := "test-user"
 	curUser := &users.User{
@@ -1054,7 +1310,8 @@ func TestHandlePostMultiClientCommandWithGroupIDs(t *testing.T) {
 				require.Len(t, gotMultiJob.Jobs, tc.wantJobCount)
 			} else {
 				// failure case
-				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail)
+				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail).WithDefaultCode(tc.wantStatusCode)
+				wantResp.RequestID = w.Header().Get(middleware.RequestIDHeader)
 				wantRespBytes, err := json.Marshal(wantResp)
 				require.NoError(t, err)
 				require.Equal(t, string(wantRespBytes), w.Body.String())
@@ -1284,7 +1541,8 @@ func TestHandlePostMultiClientCommandWithTags(t *testing.T) {
 				require.Len(t, gotMultiJob.Jobs, tc.wantJobCount)
 			} else {
 				// failure case
-				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail)
+				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail).WithDefaultCode(tc.wantStatusCode)
+				wantResp.RequestID = w.Header().Get(middleware.RequestIDHeader)
 				wantRespBytes, err := json.Marshal(wantResp)
 				require.NoError(t, err)
 				require.Equal(t, string(wantRespBytes), w.Body.String())
@@ -1756,7 +2014,8 @@ func TestHandlePostMultiClientScriptWithTags(t *testing.T) {
 				require.Len(t, gotMultiJob.Jobs, tc.wantJobCount)
 			} else {
 				// failure case
-				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail)
+				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail).WithDefaultCode(tc.wantStatusCode)
+				wantResp.RequestID = w.Header().Get(middleware.RequestIDHeader)
 				wantRespBytes, err := json.Marshal(wantResp)
 				require.NoError(t, err)
 				require.Equal(t, string(wantRespBytes), w.Body.String())
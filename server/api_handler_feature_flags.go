@@ -0,0 +1,104 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/featureflags"
+	"github.com/realvnc-labs/rport/server/routes"
+)
+
+// handleGetFeatureFlags handles GET /feature-flags
+func (al *APIListener) handleGetFeatureFlags(w http.ResponseWriter, req *http.Request) {
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(al.featureFlags.List()))
+}
+
+// handleGetFeatureFlag handles GET /feature-flags/{flag_name}
+func (al *APIListener) handleGetFeatureFlag(w http.ResponseWriter, req *http.Request) {
+	flag, err := al.featureFlags.Get(mux.Vars(req)[routes.ParamFeatureFlag])
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(flag))
+}
+
+// handlePutFeatureFlag handles PUT /feature-flags/{flag_name}
+func (al *APIListener) handlePutFeatureFlag(w http.ResponseWriter, req *http.Request) {
+	var rollout featureflags.Rollout
+	err := parseRequestBody(req.Body, &rollout)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	name := mux.Vars(req)[routes.ParamFeatureFlag]
+	flag, err := al.featureFlags.Update(req.Context(), name, rollout, api.GetUser(req.Context(), al.Logger))
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationFeatureFlag, auditlog.ActionUpdate).
+		WithHTTPRequest(req).
+		WithID(name).
+		WithRequest(rollout).
+		Save()
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(flag))
+}
+
+// handleDeleteFeatureFlag handles DELETE /feature-flags/{flag_name}, it restores the configured rollout.
+func (al *APIListener) handleDeleteFeatureFlag(w http.ResponseWriter, req *http.Request) {
+	name := mux.Vars(req)[routes.ParamFeatureFlag]
+	_, err := al.featureFlags.Reset(req.Context(), name)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationFeatureFlag, auditlog.ActionDelete).
+		WithHTTPRequest(req).
+		WithID(name).
+		Save()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetMeFeatureFlags handles GET /me/feature-flags
+func (al *APIListener) handleGetMeFeatureFlags(w http.ResponseWriter, req *http.Request) {
+	user, err := al.getUserModelForAuth(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(al.featureFlags.UserFlags(user.Groups)))
+}
+
+// handleGetClientFeatureFlags handles GET /clients/{client_id}/feature-flags
+func (al *APIListener) handleGetClientFeatureFlags(w http.ResponseWriter, req *http.Request) {
+	clientID := mux.Vars(req)[routes.ParamClientID]
+	client, err := al.clientService.GetByID(clientID)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if client == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("client with id %q not found", clientID))
+		return
+	}
+
+	clientGroups, err := al.clientGroupIDs(req.Context(), client)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(al.featureFlags.ClientFlags(clientGroups)))
+}
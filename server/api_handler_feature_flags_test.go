@@ -0,0 +1,107 @@
+package chserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	featureflagsmigration "github.com/realvnc-labs/rport/db/migration/feature_flags"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/cgroups"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/featureflags"
+)
+
+func TestHandleFeatureFlags(t *testing.T) {
+	db, err := sqlite.New(":memory:", featureflagsmigration.AssetNames(), featureflagsmigration.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	flags, err := featureflags.NewService(context.Background(), db, []featureflags.Flag{
+		{Name: "new-data-path", Description: "New tunnel data path", Rollout: featureflags.Rollout{ClientGroups: []string{"canary"}}},
+		{Name: "new-auth", Rollout: featureflags.Rollout{UserGroups: []string{"Beta"}}},
+	})
+	require.NoError(t, err)
+	defer flags.Close()
+
+	c1 := clients.New(t).ID("client-1").Logger(testLog).Build()
+	c2 := clients.New(t).ID("client-2").Logger(testLog).Build()
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+			clientService: clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1, c2}, &hour, testLog), testLog, nil),
+			clientGroupProvider: monitoringProfilesGroupProvider{groups: []*cgroups.ClientGroup{
+				{ID: "canary", Params: &cgroups.ClientParams{ClientID: &cgroups.ParamValues{"client-1"}}},
+			}},
+			featureFlags: flags,
+		},
+		userService: users.NewAPIService(users.NewStaticProvider([]*users.User{
+			{Username: "admin", Groups: []string{users.Administrators}},
+			{Username: "alice", Groups: []string{"Beta"}},
+		}), false, 0, -1),
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	do := func(method, url, username, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req = req.WithContext(api.WithUser(req.Context(), username))
+		al.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodGet, "/api/v1/feature-flags", "admin", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":[
+		{"name":"new-auth","description":"","rollout":{"enabled":false,"user_groups":["Beta"],"client_groups":null},"default":{"enabled":false,"user_groups":["Beta"],"client_groups":null}},
+		{"name":"new-data-path","description":"New tunnel data path","rollout":{"enabled":false,"user_groups":null,"client_groups":["canary"]},"default":{"enabled":false,"user_groups":null,"client_groups":["canary"]}}
+	]}`, w.Body.String())
+
+	w = do(http.MethodGet, "/api/v1/feature-flags/unknown", "admin", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = do(http.MethodGet, "/api/v1/me/feature-flags", "alice", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":["new-auth"]}`, w.Body.String())
+
+	w = do(http.MethodGet, "/api/v1/me/feature-flags", "admin", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":[]}`, w.Body.String())
+
+	w = do(http.MethodGet, "/api/v1/clients/client-1/feature-flags", "admin", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":["new-data-path"]}`, w.Body.String())
+
+	w = do(http.MethodGet, "/api/v1/clients/client-2/feature-flags", "admin", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":[]}`, w.Body.String())
+
+	w = do(http.MethodPut, "/api/v1/feature-flags/new-data-path", "admin", `{"enabled":false,"client_groups":[""]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = do(http.MethodPut, "/api/v1/feature-flags/new-data-path", "admin", `{"enabled":true}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"rollout":{"enabled":true,"user_groups":null,"client_groups":null}`)
+	assert.Contains(t, w.Body.String(), `"updated_by":"admin"`)
+
+	w = do(http.MethodGet, "/api/v1/clients/client-2/feature-flags", "admin", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":["new-data-path"]}`, w.Body.String())
+
+	w = do(http.MethodDelete, "/api/v1/feature-flags/new-data-path", "admin", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.False(t, flags.EnabledForClient("new-data-path", nil))
+}
@@ -0,0 +1,235 @@
+package chserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/clients/gatewaytargets"
+	"github.com/realvnc-labs/rport/server/routes"
+	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+type gatewayTargetCtxKeyType int
+
+const gatewayTargetCtxKey gatewayTargetCtxKeyType = iota
+
+type gatewayTargetCommandInput struct {
+	Command    string `json:"command"`
+	TimeoutSec int    `json:"timeout_sec"`
+}
+
+// handleGetGatewayTargets handles GET /gateway-targets
+func (al *APIListener) handleGetGatewayTargets(w http.ResponseWriter, req *http.Request) {
+	result, err := al.gatewayTargets.List(req.Context(), query.GetListOptions(req))
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, result)
+}
+
+// handleGetGatewayTarget handles GET /gateway-targets/{gateway_target_id}
+func (al *APIListener) handleGetGatewayTarget(w http.ResponseWriter, req *http.Request) {
+	target, err := al.gatewayTargets.Get(req.Context(), mux.Vars(req)[routes.ParamGatewayTargetID])
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(target.Redacted()))
+}
+
+// handlePostGatewayTargets handles POST /gateway-targets
+func (al *APIListener) handlePostGatewayTargets(w http.ResponseWriter, req *http.Request) {
+	target := &gatewaytargets.GatewayTarget{}
+	err := parseRequestBody(req.Body, target)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	if !al.gatewayClientExists(w, target.GatewayClientID) {
+		return
+	}
+
+	target, err = al.gatewayTargets.Create(req.Context(), target)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationGatewayTarget, auditlog.ActionCreate).
+		WithHTTPRequest(req).
+		WithClientID(target.GatewayClientID).
+		WithRequest(target.Redacted()).
+		WithID(target.ID).
+		Save()
+
+	al.writeJSONResponse(w, http.StatusCreated, api.NewSuccessPayload(target.Redacted()))
+}
+
+// handlePutGatewayTarget handles PUT /gateway-targets/{gateway_target_id}
+func (al *APIListener) handlePutGatewayTarget(w http.ResponseWriter, req *http.Request) {
+	target := &gatewaytargets.GatewayTarget{}
+	err := parseRequestBody(req.Body, target)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	if !al.gatewayClientExists(w, target.GatewayClientID) {
+		return
+	}
+
+	target, err = al.gatewayTargets.Update(req.Context(), mux.Vars(req)[routes.ParamGatewayTargetID], target)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationGatewayTarget, auditlog.ActionUpdate).
+		WithHTTPRequest(req).
+		WithClientID(target.GatewayClientID).
+		WithRequest(target.Redacted()).
+		WithID(target.ID).
+		Save()
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(target.Redacted()))
+}
+
+// handleDeleteGatewayTarget handles DELETE /gateway-targets/{gateway_target_id}
+func (al *APIListener) handleDeleteGatewayTarget(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)[routes.ParamGatewayTargetID]
+	err := al.gatewayTargets.Delete(req.Context(), id)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationGatewayTarget, auditlog.ActionDelete).
+		WithHTTPRequest(req).
+		WithID(id).
+		Save()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (al *APIListener) gatewayClientExists(w http.ResponseWriter, clientID string) bool {
+	if clientID == "" {
+		// reported by the validation
+		return true
+	}
+	client, err := al.clientService.GetByID(clientID)
+	if err != nil {
+		al.jsonError(w, err)
+		return false
+	}
+	if client == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("gateway client with id %q not found", clientID))
+		return false
+	}
+	return true
+}
+
+// withGatewayTarget puts the gateway target into the request context and sets the client id route param to the
+// gateway client, so the client middlewares and handlers apply to the gateway client.
+func (al *APIListener) withGatewayTarget(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		target, err := al.gatewayTargets.Get(req.Context(), vars[routes.ParamGatewayTargetID])
+		if err != nil {
+			al.jsonError(w, err)
+			return
+		}
+
+		newVars := map[string]string{routes.ParamClientID: target.GatewayClientID}
+		for k, v := range vars {
+			newVars[k] = v
+		}
+		req = mux.SetURLVars(req, newVars)
+
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), gatewayTargetCtxKey, target)))
+	})
+}
+
+func getGatewayTargetFromContext(ctx context.Context) *gatewaytargets.GatewayTarget {
+	target, _ := ctx.Value(gatewayTargetCtxKey).(*gatewaytargets.GatewayTarget)
+	return target
+}
+
+// handlePutGatewayTargetTunnel handles PUT /gateway-targets/{gateway_target_id}/tunnels
+// It creates a tunnel to the ssh port of the target on the gateway client, the same options as for client tunnels apply.
+func (al *APIListener) handlePutGatewayTargetTunnel(w http.ResponseWriter, req *http.Request) {
+	target := getGatewayTargetFromContext(req.Context())
+
+	q := req.URL.Query()
+	if protocol := q.Get("protocol"); protocol != "" && protocol != models.ProtocolTCP {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, "Gateway targets support tcp tunnels only.")
+		return
+	}
+	q.Set("remote", target.Address())
+	if q.Get("name") == "" {
+		q.Set("name", target.Name)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	al.handlePutClientTunnel(w, req)
+}
+
+// handlePostGatewayTargetCommand handles POST /gateway-targets/{gateway_target_id}/commands
+// Commands are executed synchronously via ssh, they are not stored as jobs.
+func (al *APIListener) handlePostGatewayTargetCommand(w http.ResponseWriter, req *http.Request) {
+	target := getGatewayTargetFromContext(req.Context())
+
+	input := &gatewayTargetCommandInput{}
+	err := parseRequestBody(req.Body, input)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if input.Command == "" {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, "Command cannot be empty.")
+		return
+	}
+	if input.TimeoutSec <= 0 {
+		input.TimeoutSec = al.config.Server.RunRemoteCmdTimeoutSec
+	}
+	if !target.HasCredentials() {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, "Gateway target has no ssh credentials to execute commands.")
+		return
+	}
+
+	client, err := al.clientService.GetActiveByID(target.GatewayClientID)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if client == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("Active gateway client with id=%q not found.", target.GatewayClientID))
+		return
+	}
+
+	result, err := gatewaytargets.RunCommand(req.Context(), client.GetConnection(), target, input.Command, time.Duration(input.TimeoutSec)*time.Second)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusConflict, "Failed to execute command on gateway target.", err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationGatewayTarget, auditlog.ActionExecuteDone).
+		WithHTTPRequest(req).
+		WithClient(client).
+		WithRequest(input).
+		WithResponse(result).
+		WithID(target.ID).
+		Save()
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(result))
+}
@@ -0,0 +1,49 @@
+package chserver
+
+import (
+	"net/http"
+)
+
+const (
+	healthzRoute = "/healthz"
+	readyzRoute  = "/readyz"
+)
+
+type ProbeStatus struct {
+	Status string             `json:"status"`
+	Checks []*ComponentHealth `json:"checks,omitempty"`
+}
+
+// handleGetHealthz handles GET /healthz. The liveness probe doesn't check any dependencies, it only confirms the
+// server is able to handle requests. With details enabled, the usage of the port pool is reported.
+func (al *APIListener) handleGetHealthz(w http.ResponseWriter, req *http.Request) {
+	status := &ProbeStatus{Status: ComponentStatusOK}
+	if al.config.API.HealthProbesDetails {
+		status.Checks = al.checkComponents(req.Context(), al.livenessDetailChecks())
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	al.writeJSONResponse(w, http.StatusOK, status)
+}
+
+// handleGetReadyz handles GET /readyz. The readiness probe checks all dependencies required to serve clients and
+// users. 503 is returned if any of them is unhealthy.
+func (al *APIListener) handleGetReadyz(w http.ResponseWriter, req *http.Request) {
+	checks := al.checkComponents(req.Context(), al.readinessChecks())
+
+	status := &ProbeStatus{
+		Status: ComponentStatusOK,
+	}
+	// the probes don't require authentication, so the checks are only shown if enabled
+	if al.config.API.HealthProbesDetails {
+		status.Checks = checks
+	}
+	httpStatus := http.StatusOK
+	if !componentsHealthy(checks) {
+		status.Status = ComponentStatusError
+		httpStatus = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	al.writeJSONResponse(w, httpStatus, status)
+}
@@ -0,0 +1,267 @@
+package chserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mapset "github.com/deckarep/golang-set"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/ports"
+)
+
+func TestHandleGetHealthz(t *testing.T) {
+	// keep a port busy to exhaust the pool
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	busyPort := l.Addr().(*net.TCPAddr).Port
+
+	testCases := []struct {
+		name     string
+		details  bool
+		wantBody string
+	}{
+		{
+			name:     "no details",
+			wantBody: `{"status":"ok"}`,
+		},
+		{
+			name:     "exhausted port pool in details",
+			details:  true,
+			wantBody: `{"status":"ok","checks":[{"name":"port_pool","status":"error","details":"0 of 1 ports free"}]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			al := APIListener{
+				Server: &Server{
+					config: &chconfig.Config{
+						API: chconfig.APIConfig{
+							HealthProbesEnabled: true,
+							HealthProbesDetails: tc.details,
+						},
+					},
+					portDistributor: ports.NewPortDistributor(mapset.NewSetFromSlice([]interface{}{busyPort})),
+				},
+				Logger: testLog,
+			}
+			al.initRouter()
+
+			req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+			w := httptest.NewRecorder()
+			al.router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, tc.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandleGetReadyz(t *testing.T) {
+	// keep a port busy to exhaust the pool
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	busyPort := l.Addr().(*net.TCPAddr).Port
+
+	testCases := []struct {
+		name           string
+		disabled       bool
+		noDetails      bool
+		dataDir        string
+		allowedPorts   mapset.Set
+		certFile       string
+		wantStatusCode int
+		wantStatus     string
+		wantChecks     map[string]string
+	}{
+		{
+			name:           "disabled",
+			disabled:       true,
+			dataDir:        t.TempDir(),
+			wantStatusCode: http.StatusNotFound,
+		},
+		{
+			name:           "ready",
+			dataDir:        t.TempDir(),
+			allowedPorts:   mapset.NewSetFromSlice([]interface{}{busyPort, busyPort + 1}),
+			certFile:       writeTestCert(t, time.Now().Add(365*24*time.Hour)),
+			wantStatusCode: http.StatusOK,
+			wantStatus:     ComponentStatusOK,
+			wantChecks: map[string]string{
+				ComponentAuth:        ComponentStatusOK,
+				ComponentCertificate: ComponentStatusOK,
+				ComponentClientsAuth: ComponentStatusOK,
+				ComponentDatabase:    ComponentStatusOK,
+				ComponentStorage:     ComponentStatusOK,
+			},
+		},
+		{
+			name:           "no free ports is ready",
+			dataDir:        t.TempDir(),
+			allowedPorts:   mapset.NewSetFromSlice([]interface{}{busyPort}),
+			wantStatusCode: http.StatusOK,
+			wantStatus:     ComponentStatusOK,
+			wantChecks: map[string]string{
+				ComponentAuth:        ComponentStatusOK,
+				ComponentClientsAuth: ComponentStatusOK,
+				ComponentDatabase:    ComponentStatusOK,
+				ComponentStorage:     ComponentStatusOK,
+			},
+		},
+		{
+			name:           "storage missing",
+			dataDir:        filepath.Join(t.TempDir(), "missing"),
+			wantStatusCode: http.StatusServiceUnavailable,
+			wantStatus:     ComponentStatusError,
+			wantChecks: map[string]string{
+				ComponentAuth:        ComponentStatusOK,
+				ComponentClientsAuth: ComponentStatusOK,
+				ComponentDatabase:    ComponentStatusOK,
+				ComponentStorage:     ComponentStatusError,
+			},
+		},
+		{
+			name:           "storage missing without details",
+			noDetails:      true,
+			dataDir:        filepath.Join(t.TempDir(), "missing"),
+			wantStatusCode: http.StatusServiceUnavailable,
+			wantStatus:     ComponentStatusError,
+			wantChecks:     map[string]string{},
+		},
+		{
+			name:           "expired certificate",
+			dataDir:        t.TempDir(),
+			certFile:       writeTestCert(t, time.Now().Add(-time.Hour)),
+			wantStatusCode: http.StatusServiceUnavailable,
+			wantStatus:     ComponentStatusError,
+			wantChecks: map[string]string{
+				ComponentAuth:        ComponentStatusOK,
+				ComponentCertificate: ComponentStatusError,
+				ComponentClientsAuth: ComponentStatusOK,
+				ComponentDatabase:    ComponentStatusOK,
+				ComponentStorage:     ComponentStatusOK,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var portDistributor *ports.PortDistributor
+			if tc.allowedPorts != nil {
+				portDistributor = ports.NewPortDistributor(tc.allowedPorts)
+			}
+			al := APIListener{
+				Server: &Server{
+					config: &chconfig.Config{
+						Server: chconfig.ServerConfig{DataDir: tc.dataDir},
+						API: chconfig.APIConfig{
+							HealthProbesEnabled: !tc.disabled,
+							HealthProbesDetails: !tc.noDetails,
+							CertFile:            tc.certFile,
+						},
+					},
+					portDistributor: portDistributor,
+				},
+				Logger:      testLog,
+				userService: MockUserService("admin", users.Administrators),
+			}
+			al.initRouter()
+
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			w := httptest.NewRecorder()
+			al.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+			if tc.wantStatus == "" {
+				return
+			}
+
+			var resp ProbeStatus
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tc.wantStatus, resp.Status)
+
+			statuses := map[string]string{}
+			for _, c := range resp.Checks {
+				statuses[c.Name] = c.Status
+			}
+			assert.Equal(t, tc.wantChecks, statuses)
+		})
+	}
+}
+
+func TestCheckPortPoolDetails(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	busyPort := l.Addr().(*net.TCPAddr).Port
+
+	al := APIListener{
+		Server: &Server{
+			portDistributor: ports.NewPortDistributor(mapset.NewSetFromSlice([]interface{}{busyPort})),
+		},
+	}
+
+	details, err := al.checkPortPool(context.Background())
+
+	assert.EqualError(t, err, "no free ports left")
+	assert.Equal(t, "0 of 1 ports free", details)
+}
+
+func TestCheckStorageDoesNotWrite(t *testing.T) {
+	dataDir := t.TempDir()
+	al := APIListener{
+		Server: &Server{
+			config: &chconfig.Config{
+				Server: chconfig.ServerConfig{DataDir: dataDir},
+			},
+		},
+	}
+
+	_, err := al.checkStorage(context.Background())
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(dataDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	al.config.Server.DataDir = writeTestCert(t, time.Now())
+	_, err = al.checkStorage(context.Background())
+	assert.EqualError(t, err, al.config.Server.DataDir+" is not a directory")
+}
+
+func writeTestCert(t *testing.T, notAfter time.Time) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "rport"},
+		NotBefore:    notAfter.Add(-2 * 365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(t.TempDir(), "cert.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	return certFile
+}
@@ -0,0 +1,89 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/jobs"
+	"github.com/realvnc-labs/rport/server/routes"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+// handleGetClientJobStats handles GET /clients/{client_id}/job-stats
+func (al *APIListener) handleGetClientJobStats(w http.ResponseWriter, req *http.Request) {
+	cid := mux.Vars(req)[routes.ParamClientID]
+
+	options := query.GetListOptions(req)
+	err := query.ValidateListOptions(options, nil, jobs.JobStatsClientSupportedFilters, nil, nil)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	stats, err := al.jobProvider.GetJobStats(req.Context(), cid, options.Filters)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get client job stats: client_id=%q.", cid), err)
+		return
+	}
+	if stats == nil {
+		stats = &jobs.JobStats{ClientID: cid}
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(stats))
+}
+
+// handleGetJobStats handles GET /job-stats
+// Non-admin users get the stats of the clients they have access to only.
+func (al *APIListener) handleGetJobStats(w http.ResponseWriter, req *http.Request) {
+	options := query.GetListOptions(req)
+	err := query.ValidateListOptions(options, jobs.JobStatsSupportedSorts, jobs.JobStatsSupportedFilters, nil, &query.PaginationConfig{
+		MaxLimit:     jobs.MaxLimit,
+		DefaultLimit: jobs.DefaultLimit,
+	})
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	curUser, err := al.getUserModelForAuth(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if !curUser.CanReadAll() {
+		clientGroups, err := al.clientGroupProvider.GetAll(req.Context())
+		if err != nil {
+			al.jsonError(w, err)
+			return
+		}
+		var clientIDs []string
+		for _, c := range al.clientService.GetUserClients(clientGroups, curUser) {
+			clientIDs = append(clientIDs, c.GetID())
+		}
+		if len(clientIDs) == 0 {
+			al.writeJSONResponse(w, http.StatusOK, &api.SuccessPayload{Data: []*jobs.JobStats{}, Meta: api.NewMeta(0)})
+			return
+		}
+		options.Filters = append(options.Filters, query.FilterOption{Column: []string{"client_id"}, Values: clientIDs})
+	}
+
+	result, err := al.jobProvider.ListJobStats(req.Context(), options)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, "Failed to get job stats.", err)
+		return
+	}
+
+	totalCount, err := al.jobProvider.CountJobStats(req.Context(), options)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, "Failed to count job stats.", err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, &api.SuccessPayload{
+		Data: result,
+		Meta: api.NewMeta(totalCount),
+	})
+}
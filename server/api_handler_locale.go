@@ -0,0 +1,72 @@
+package chserver
+
+import (
+	"net/http"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/i18n"
+)
+
+type MeLocalePayload struct {
+	// Locale selected by the user, empty if the default locale or the Accept-Language header is used.
+	Locale        string   `json:"locale"`
+	DefaultLocale string   `json:"default_locale"`
+	Supported     []string `json:"supported"`
+}
+
+// handleGetMeLocale handles GET /me/locale
+func (al *APIListener) handleGetMeLocale(w http.ResponseWriter, req *http.Request) {
+	username := api.GetUser(req.Context(), al.Logger)
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(MeLocalePayload{
+		Locale:        al.locales.GetUserLocale(username),
+		DefaultLocale: al.locales.DefaultLocale(),
+		Supported:     i18n.Supported(),
+	}))
+}
+
+type changeMeLocaleRequest struct {
+	Locale string `json:"locale"`
+}
+
+// handlePutMeLocale handles PUT /me/locale
+func (al *APIListener) handlePutMeLocale(w http.ResponseWriter, req *http.Request) {
+	var r changeMeLocaleRequest
+	err := parseRequestBody(req.Body, &r)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	username := api.GetUser(req.Context(), al.Logger)
+	err = al.locales.SetUserLocale(req.Context(), username, r.Locale)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationAuthUserMe, auditlog.ActionUpdate).
+		WithHTTPRequest(req).
+		WithID(username).
+		WithRequest(r).
+		Save()
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(MeLocalePayload{
+		Locale:        r.Locale,
+		DefaultLocale: al.locales.DefaultLocale(),
+		Supported:     i18n.Supported(),
+	}))
+}
+
+// wrapUserLocaleMiddleware uses the locale selected by the authenticated user for the responses.
+func (al *APIListener) wrapUserLocaleMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if al.locales != nil {
+			if locale := al.locales.GetUserLocale(api.GetUser(r.Context(), al.Logger)); locale != "" {
+				i18n.SetRequestLocale(r.Context(), locale)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
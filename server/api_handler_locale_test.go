@@ -0,0 +1,75 @@
+package chserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	userlocalesmigration "github.com/realvnc-labs/rport/db/migration/user_locales"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/i18n"
+)
+
+func TestHandleMeLocale(t *testing.T) {
+	db, err := sqlite.New(":memory:", userlocalesmigration.AssetNames(), userlocalesmigration.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	locales, err := i18n.NewService(context.Background(), db, i18n.DefaultLocale)
+	require.NoError(t, err)
+	defer locales.Close()
+
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+			locales: locales,
+		},
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	ctx := api.WithUser(context.Background(), "admin")
+	do := func(method, url, body, acceptLanguage string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body)).WithContext(ctx)
+		req.Header.Set("Accept-Language", acceptLanguage)
+		w := httptest.NewRecorder()
+		al.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodGet, "/api/v1/me/locale", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":{"locale":"","default_locale":"en","supported":["en","de","es","fr"]}}`, w.Body.String())
+
+	w = do(http.MethodPut, "/api/v1/me/locale", `{"locale":"pt"}`, "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"title":"Unsupported locale \"pt\"."`)
+
+	w = do(http.MethodPut, "/api/v1/me/locale", `{"locale":"pt"}`, "fr")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"title":"Langue non prise en charge \"pt\"."`)
+
+	w = do(http.MethodPut, "/api/v1/me/locale", `{"locale":"de"}`, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":{"locale":"de","default_locale":"en","supported":["en","de","es","fr"]}}`, w.Body.String())
+	assert.Equal(t, "de", locales.GetUserLocale("admin"))
+
+	// the selected locale takes precedence over the Accept-Language header
+	w = do(http.MethodPut, "/api/v1/me/locale", `{"locale":"pt"}`, "fr")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"title":"Nicht unterstützte Sprache \"pt\"."`)
+
+	w = do(http.MethodPut, "/api/v1/me/locale", `{"locale":""}`, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "", locales.GetUserLocale("admin"))
+}
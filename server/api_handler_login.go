@@ -10,6 +10,7 @@ import (
 	rportplus "github.com/realvnc-labs/rport/plus"
 	"github.com/realvnc-labs/rport/server/api"
 	errors2 "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/api/message"
 	"github.com/realvnc-labs/rport/server/api/users"
 	"github.com/realvnc-labs/rport/server/bearer"
 	chshare "github.com/realvnc-labs/rport/share"
@@ -130,7 +131,7 @@ func (al *APIListener) handleLogin(username, pwd string, newpwd string, skipPass
 			Token: &tokenStr,
 			TwoFA: &twoFAResponse{
 				SendTo:         sendTo,
-				DeliveryMethod: al.twoFASrv.MsgSrv.DeliveryMethod(),
+				DeliveryMethod: message.DeliveryMethodFor(al.twoFASrv.MsgSrv, sendTo),
 			},
 		}))
 		return
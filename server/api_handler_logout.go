@@ -1,6 +1,7 @@
 package chserver
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -58,6 +59,15 @@ func (al *APIListener) handleDeleteLogout(w http.ResponseWriter, req *http.Reque
 		al.jsonErrorResponse(w, http.StatusInternalServerError, err)
 		return
 	}
+	al.closeEphemeralTunnels(req.Context())
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// closeEphemeralTunnels closes the ephemeral tunnels of api sessions that ended. Expired sessions are handled by
+// a scheduled task, this closes the tunnels right away on logout and revocation of sessions.
+func (al *APIListener) closeEphemeralTunnels(ctx context.Context) {
+	if err := al.clientService.TerminateEphemeralTunnels(ctx, al.apiSessions); err != nil {
+		al.Errorf("Failed to close ephemeral tunnels: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package chserver
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/maintenance"
+)
+
+// handleGetMaintenance handles GET /maintenance
+func (al *APIListener) handleGetMaintenance(w http.ResponseWriter, req *http.Request) {
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(al.maintenance.Status()))
+}
+
+// handlePostMaintenanceRun handles POST /maintenance/run
+// The maintenance runs synchronously, the response contains the results of all steps.
+func (al *APIListener) handlePostMaintenanceRun(w http.ResponseWriter, req *http.Request) {
+	report, err := al.maintenance.RunNow(req.Context(), maintenance.TriggerManual)
+	if err != nil {
+		if errors.Is(err, maintenance.ErrAlreadyRunning) {
+			al.jsonErrorResponseWithTitle(w, http.StatusConflict, "Maintenance is already running.")
+			return
+		}
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationMaintenance, auditlog.ActionExecuteDone).
+		WithHTTPRequest(req).
+		WithResponse(report).
+		Save()
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(report))
+}
+
+// handleGetMaintenanceFreeze handles GET /maintenance/freeze
+func (al *APIListener) handleGetMaintenanceFreeze(w http.ResponseWriter, req *http.Request) {
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(al.maintenance.FreezeStatus()))
+}
+
+// handlePutMaintenanceFreeze handles PUT /maintenance/freeze
+// It puts the API into read-only mode, client connections and tunnels are not affected.
+func (al *APIListener) handlePutMaintenanceFreeze(w http.ResponseWriter, req *http.Request) {
+	var input maintenance.FreezeInput
+	err := parseRequestBody(req.Body, &input)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	freeze, err := al.maintenance.Freeze(input, api.GetUser(req.Context(), al.Logger))
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationMaintenance, auditlog.ActionUpdate).
+		WithHTTPRequest(req).
+		WithRequest(input).
+		Save()
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(freeze))
+}
+
+// handleDeleteMaintenanceFreeze handles DELETE /maintenance/freeze
+func (al *APIListener) handleDeleteMaintenanceFreeze(w http.ResponseWriter, req *http.Request) {
+	al.maintenance.Unfreeze()
+
+	al.auditLog.Entry(auditlog.ApplicationMaintenance, auditlog.ActionDelete).
+		WithHTTPRequest(req).
+		Save()
+
+	w.WriteHeader(http.StatusNoContent)
+}
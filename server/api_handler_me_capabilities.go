@@ -0,0 +1,129 @@
+package chserver
+
+import (
+	"net/http"
+
+	rportplus "github.com/realvnc-labs/rport/plus"
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/share/enums"
+)
+
+const (
+	featureGroupPermissions = "group_permissions"
+	featureUserManagement   = "user_management"
+	featureTwoFA            = "two_fa"
+	featureTotP             = "totp"
+	featureMonitoring       = "monitoring"
+	featureAuditLog         = "auditlog"
+	featureSessionRecording = "session_recording"
+	featureCaptures         = "captures"
+	featureTunnelProxy      = "tunnel_proxy"
+	featurePlus             = "plus"
+	featureAlerting         = "alerting"
+)
+
+// capabilityAction is an action of the API, it's allowed if the user has the permission, is an admin if required
+// and the feature is enabled. Auditors are allowed read-only actions and actions on their own account only.
+type capabilityAction struct {
+	Name       string
+	Permission string
+	AdminOnly  bool
+	Feature    string
+	ReadOnly   bool
+	Self       bool
+}
+
+// capabilityActions must be kept in sync with the middlewares of the routes in api_router.go.
+var capabilityActions = []capabilityAction{
+	{Name: "clients.delete", AdminOnly: true},
+	{Name: "clients.acl", AdminOnly: true},
+	{Name: "clients.mode", AdminOnly: true},
+	{Name: "tunnels.manage", Permission: users.PermissionTunnels},
+	{Name: "commands.execute", Permission: users.PermissionCommands},
+	{Name: "scripts.execute", Permission: users.PermissionScripts},
+	{Name: "uploads.create", Permission: users.PermissionUploads},
+	{Name: "schedules.manage", Permission: users.PermissionScheduler},
+	{Name: "vault.access", Permission: users.PermissionVault},
+	{Name: "vault.admin", Permission: users.PermissionVault, AdminOnly: true},
+	{Name: "monitoring.read", Permission: users.PermissionMonitoring, Feature: featureMonitoring, ReadOnly: true},
+	{Name: "updates_status.refresh", Permission: users.PermissionMonitoring},
+	{Name: "captures.manage", Permission: users.PermissionCaptures, Feature: featureCaptures},
+	{Name: "auditlog.read", Permission: users.PermissionsAuditLog, Feature: featureAuditLog, ReadOnly: true},
+	{Name: "session_recordings.read", Permission: users.PermissionsAuditLog, Feature: featureSessionRecording, ReadOnly: true},
+	{Name: "users.manage", AdminOnly: true, Feature: featureUserManagement},
+	{Name: "client_groups.manage", AdminOnly: true},
+	{Name: "clients_auth.manage", AdminOnly: true},
+	{Name: "gateway_targets.manage", AdminOnly: true},
+	{Name: "capacity.read", AdminOnly: true, ReadOnly: true},
+	{Name: "maintenance.manage", AdminOnly: true},
+	{Name: "notifications.read", AdminOnly: true, ReadOnly: true},
+	{Name: "security_events.read", AdminOnly: true, ReadOnly: true},
+	{Name: "client_connection_failures.read", AdminOnly: true, ReadOnly: true},
+	{Name: "alerting.manage", AdminOnly: true, Feature: featureAlerting},
+	{Name: "me.totp", Feature: featureTotP, Self: true},
+}
+
+type MeCapabilitiesPayload struct {
+	Username    string          `json:"username"`
+	Admin       bool            `json:"admin"`
+	Auditor     bool            `json:"auditor"`
+	Permissions map[string]bool `json:"permissions"`
+	Features    map[string]bool `json:"features"`
+	Actions     map[string]bool `json:"actions"`
+}
+
+// handleGetMeCapabilities handles GET /me/capabilities
+// It returns the effective permissions of the current user, the enabled features and the actions the user is
+// allowed to perform, so clients of the API can hide actions instead of running into 403 errors.
+func (al *APIListener) handleGetMeCapabilities(w http.ResponseWriter, req *http.Request) {
+	user, err := al.getUserModelForAuth(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	permissions, err := al.userService.GetEffectiveUserPermissions(user)
+	if err != nil {
+		al.jsonErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	features := al.getFeatures()
+	actions := make(map[string]bool, len(capabilityActions))
+	for _, a := range capabilityActions {
+		if user.IsAuditor() {
+			actions[a.Name] = (a.ReadOnly || a.Self) && (a.Feature == "" || features[a.Feature])
+			continue
+		}
+		actions[a.Name] = (a.Permission == "" || permissions[a.Permission]) &&
+			(!a.AdminOnly || user.IsAdmin()) &&
+			(a.Feature == "" || features[a.Feature])
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(MeCapabilitiesPayload{
+		Username:    user.Username,
+		Admin:       user.IsAdmin(),
+		Auditor:     user.IsAuditor(),
+		Permissions: permissions,
+		Features:    features,
+		Actions:     actions,
+	}))
+}
+
+func (al *APIListener) getFeatures() map[string]bool {
+	plusEnabled := rportplus.IsPlusEnabled(al.config.PlusConfig)
+	return map[string]bool{
+		featureGroupPermissions: al.userService.SupportsGroupPermissions(),
+		featureUserManagement:   al.userService.GetProviderType() != enums.ProviderSourceStatic,
+		featureTwoFA:            al.config.API.IsTwoFAOn() || al.config.API.TotPEnabled,
+		featureTotP:             al.config.API.TotPEnabled,
+		featureMonitoring:       al.config.Monitoring.Enabled,
+		featureAuditLog:         al.auditLog != nil && al.auditLog.Status().Enabled,
+		featureSessionRecording: al.sessionRecordings != nil,
+		featureCaptures:         al.captures != nil,
+		featureTunnelProxy:      al.config.Server.InternalTunnelProxyConfig.Enabled,
+		featurePlus:             plusEnabled,
+		featureAlerting:         plusEnabled && al.alertingService != nil,
+	}
+}
@@ -0,0 +1,143 @@
+package chserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/chconfig"
+)
+
+func TestHandleGetMeCapabilities(t *testing.T) {
+	db, err := sqlx.Connect("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlExecs := []string{
+		`CREATE TABLE "users" ("username" TEXT PRIMARY KEY, "password" TEXT, "password_expired" BOOLEAN NOT NULL CHECK (password_expired IN (0, 1)) DEFAULT 0)`,
+		`INSERT INTO "users" VALUES("test-user","1", false)`,
+		`INSERT INTO "users" VALUES("admin","1", false)`,
+		`INSERT INTO "users" VALUES("auditor","1", false)`,
+		`CREATE TABLE "groups" ("username" TEXT, "group" TEXT)`,
+		`INSERT INTO "groups" VALUES("test-user","group1")`,
+		`INSERT INTO "groups" VALUES("admin","Administrators")`,
+		`INSERT INTO "groups" VALUES("auditor","Auditors")`,
+		`CREATE TABLE "group_details" ("name" TEXT, "permissions" TEXT)`,
+		`CREATE UNIQUE INDEX "main"."username_group_name" ON "group_details" ("name" ASC)`,
+		`INSERT INTO "group_details" VALUES('group1','{"vault":true, "monitoring": true, "captures": true}')`,
+		`INSERT INTO "group_details" VALUES('Administrators','{"vault":true, "tunnels": true}')`,
+	}
+	for _, sqlExec := range sqlExecs {
+		_, err = db.Exec(sqlExec)
+		require.NoError(t, err)
+	}
+
+	userProvider, err := users.NewUserDatabase(db, "users", "groups", "group_details", false, false, false, testLog)
+	require.NoError(t, err)
+
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			config: &chconfig.Config{
+				Monitoring: chconfig.MonitoringConfig{
+					Enabled: true,
+				},
+			},
+		},
+		userService: users.NewAPIService(userProvider, false, 0, -1),
+		Logger:      testLog,
+	}
+	al.initRouter()
+
+	testCases := []struct {
+		username        string
+		wantAdmin       bool
+		wantAuditor     bool
+		wantPermissions map[string]bool
+		wantActions     map[string]bool
+	}{
+		{
+			username: "test-user",
+			wantPermissions: map[string]bool{
+				users.PermissionVault:      true,
+				users.PermissionMonitoring: true,
+				users.PermissionCaptures:   true,
+				users.PermissionTunnels:    false,
+			},
+			wantActions: map[string]bool{
+				"vault.access":         true,
+				"vault.admin":          false,
+				"monitoring.read":      true,
+				"captures.manage":      false, // captures are disabled
+				"tunnels.manage":       false,
+				"users.manage":         false,
+				"security_events.read": false,
+				"me.totp":              false,
+			},
+		},
+		{
+			username:  "admin",
+			wantAdmin: true,
+			wantPermissions: map[string]bool{
+				users.PermissionVault:      true,
+				users.PermissionMonitoring: false,
+				users.PermissionTunnels:    true,
+			},
+			wantActions: map[string]bool{
+				"vault.access":         true,
+				"vault.admin":          true,
+				"monitoring.read":      false,
+				"tunnels.manage":       true,
+				"users.manage":         true,
+				"security_events.read": true,
+			},
+		},
+		{
+			username:    "auditor",
+			wantAuditor: true,
+			wantActions: map[string]bool{
+				"vault.access":         false,
+				"tunnels.manage":       false,
+				"users.manage":         false,
+				"monitoring.read":      true,
+				"security_events.read": true,
+				"me.totp":              false, // totp is disabled
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.username, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/me/capabilities", nil)
+			req = req.WithContext(api.WithUser(req.Context(), tc.username))
+			w := httptest.NewRecorder()
+			al.router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			var gotResp struct {
+				Data MeCapabilitiesPayload `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &gotResp))
+			assert.Equal(t, tc.username, gotResp.Data.Username)
+			assert.Equal(t, tc.wantAdmin, gotResp.Data.Admin)
+			assert.Equal(t, tc.wantAuditor, gotResp.Data.Auditor)
+			assert.True(t, gotResp.Data.Features[featureMonitoring])
+			assert.True(t, gotResp.Data.Features[featureGroupPermissions])
+			assert.False(t, gotResp.Data.Features[featureCaptures])
+			assert.Len(t, gotResp.Data.Actions, len(capabilityActions))
+			for permission, want := range tc.wantPermissions {
+				assert.Equal(t, want, gotResp.Data.Permissions[permission], permission)
+			}
+			for action, want := range tc.wantActions {
+				assert.Equal(t, want, gotResp.Data.Actions[action], action)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package chserver
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/realvnc-labs/rport/db/pool"
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/middleware"
+	"github.com/realvnc-labs/rport/server/routes"
+)
+
+// deprecatedByPrometheusMetrics announces the deprecation of the JSON metrics, the same values are exported by the
+// Prometheus metrics endpoint.
+var deprecatedByPrometheusMetrics = middleware.Deprecated(middleware.Deprecation{
+	Since:           time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC),
+	Prefix:          routes.AllRoutesPrefix + "/metrics",
+	SuccessorPrefix: prometheusMetricsRoute,
+})
+
+// ServerMetrics holds metrics of the server itself, to diagnose e.g. API latency caused by the databases.
+type ServerMetrics struct {
+	// Databases holds the connection pool stats of the SQL stores by name, e.g. clients.db
+	Databases map[string]pool.Stats `json:"databases"`
+	// SlowQueryThresholdMillis is the duration of queries counted as slow, 0 if slow queries are not logged
+	SlowQueryThresholdMillis int64 `json:"slow_query_threshold_ms"`
+}
+
+// handleGetMetrics handles GET /metrics, it's deprecated in favor of the Prometheus metrics, see
+// handleGetPrometheusMetrics.
+func (al *APIListener) handleGetMetrics(w http.ResponseWriter, req *http.Request) {
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(ServerMetrics{
+		Databases:                pool.AllStats(),
+		SlowQueryThresholdMillis: al.config.Server.SlowQueryThreshold.Milliseconds(),
+	}))
+}
@@ -0,0 +1,55 @@
+package chserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/db/migration/dummy"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/api/middleware"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/chconfig"
+)
+
+func TestHandleGetMetrics(t *testing.T) {
+	db, err := sqlite.New(t.TempDir()+"/metrics-test.db", dummy.AssetNames(), dummy.Asset, sqlite.DataSourceOptions{MaxOpenConnections: 2})
+	require.NoError(t, err)
+	defer db.Close()
+
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+				Server: chconfig.ServerConfig{
+					SlowQueryThreshold: 500 * time.Millisecond,
+				},
+			},
+		},
+		userService: users.NewAPIService(users.NewStaticProvider(nil), false, 0, -1),
+		Logger:      testLog,
+	}
+	al.initRouter()
+
+	w := httptest.NewRecorder()
+	al.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Header().Get(middleware.DeprecationHeader))
+	assert.Equal(t, `</metrics>; rel="successor-version"`, w.Header().Get(middleware.LinkHeader))
+
+	var resp struct {
+		Data ServerMetrics `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(500), resp.Data.SlowQueryThresholdMillis)
+	require.Contains(t, resp.Data.Databases, "metrics-test.db")
+	assert.Equal(t, 2, resp.Data.Databases["metrics-test.db"].MaxOpenConnections)
+}
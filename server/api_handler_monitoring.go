@@ -7,6 +7,7 @@ import (
 
 	"github.com/gorilla/mux"
 
+	errors2 "github.com/realvnc-labs/rport/server/api/errors"
 	"github.com/realvnc-labs/rport/server/monitoring"
 	"github.com/realvnc-labs/rport/server/routes"
 	"github.com/realvnc-labs/rport/share/comm"
@@ -31,6 +32,10 @@ func (al *APIListener) handleRefreshUpdatesStatus(w http.ResponseWriter, req *ht
 		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("client with id %s not found", clientID))
 		return
 	}
+	if client.IsCheckInOnly() {
+		al.jsonErrorResponseWithErrCode(w, http.StatusConflict, errors2.ErrCodeClientCheckInOnly, fmt.Sprintf("failed to refresh updates status of client with id %s: %v", clientID, ErrClientCheckInOnly))
+		return
+	}
 
 	err = comm.SendRequestAndGetResponse(client.GetConnection(), comm.RequestTypeRefreshUpdatesStatus, nil, nil, al.Log())
 	if err != nil {
@@ -164,6 +169,70 @@ func (al *APIListener) handleGetClientMountpoints(w http.ResponseWriter, req *ht
 	al.writeJSONResponse(w, http.StatusOK, payload)
 }
 
+type postCustomMetricsRequest struct {
+	Metrics map[string]float64 `json:"metrics"`
+}
+
+// handlePostClientCustomMetrics handles POST /clients/{client_id}/custom-metrics
+func (al *APIListener) handlePostClientCustomMetrics(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	clientID := vars[routes.ParamClientID]
+
+	client, err := al.clientService.GetByID(clientID)
+	if err != nil {
+		al.jsonErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	if client == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("client with id %s not found", clientID))
+		return
+	}
+
+	var r postCustomMetricsRequest
+	if err := parseRequestBody(req.Body, &r); err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	if err := al.monitoringService.SaveCustomMetrics(req.Context(), clientID, r.Metrics); err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetClientCustomMetrics handles GET /clients/{client_id}/custom-metrics
+func (al *APIListener) handleGetClientCustomMetrics(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	clientID := vars[routes.ParamClientID]
+
+	queryOptions := query.NewOptions(req, monitoring.ClientCustomMetricsSortDefault, monitoring.ClientCustomMetricsFilterDefault, monitoring.ClientCustomMetricsFieldsDefault)
+
+	payload, err := al.monitoringService.ListClientCustomMetrics(req.Context(), clientID, queryOptions)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	al.writeJSONResponse(w, http.StatusOK, payload)
+}
+
+// handleGetClientCustomMetricsGraph handles GET /clients/{client_id}/graph-metrics/custom/{metric_name}
+func (al *APIListener) handleGetClientCustomMetricsGraph(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	clientID := vars[routes.ParamClientID]
+	name := vars[routes.ParamMetricName]
+
+	queryOptions := query.NewOptions(req, monitoring.ClientGraphMetricsSortDefault, monitoring.ClientGraphMetricsFilterDefault, monitoring.ClientGraphMetricsFieldsDefault)
+
+	payload, err := al.monitoringService.ListClientCustomGraph(req.Context(), clientID, name, queryOptions)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	al.writeJSONResponse(w, http.StatusOK, payload)
+}
+
 // handleMonitoringDisabled returns Not Found (404) when monitoring is disabled
 func (al *APIListener) handleMonitoringDisabled(w http.ResponseWriter, req *http.Request) {
 	al.jsonErrorResponseWithTitle(w, http.StatusNotFound, "monitoring disabled. re-enable to view monitoring statistics.")
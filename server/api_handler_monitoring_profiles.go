@@ -0,0 +1,106 @@
+package chserver
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+)
+
+type monitoringProfilePayload struct {
+	Name                string   `json:"name"`
+	Version             string   `json:"version"`
+	ClientGroups        []string `json:"client_groups"`
+	Enabled             bool     `json:"enabled"`
+	IntervalSec         int      `json:"interval_sec"`
+	Metrics             []string `json:"metrics"`
+	WatchProcesses      []string `json:"watch_processes"`
+	WatchServices       []string `json:"watch_services"`
+	Clients             int      `json:"clients"`
+	AcknowledgedClients int      `json:"acknowledged_clients"`
+}
+
+type monitoringProfilesPushResponse struct {
+	Clients int `json:"clients"`
+}
+
+// handleGetMonitoringProfiles handles GET /monitoring-profiles
+// It returns the configured profiles with the number of clients the profile was pushed to and how many of them
+// acknowledged the current version.
+func (al *APIListener) handleGetMonitoringProfiles(w http.ResponseWriter, req *http.Request) {
+	payload := make([]monitoringProfilePayload, 0, len(al.monitoringProfiles))
+	for _, profile := range al.monitoringProfiles {
+		p := monitoringProfilePayload{
+			Name:           profile.Name,
+			Version:        profile.Version(),
+			ClientGroups:   profile.ClientGroups,
+			Enabled:        profile.IsEnabled(),
+			IntervalSec:    int(profile.Interval.Seconds()),
+			Metrics:        profile.Metrics,
+			WatchProcesses: profile.WatchProcesses,
+			WatchServices:  profile.WatchServices,
+		}
+		for _, client := range al.clientService.GetAll() {
+			state := client.GetMonitoringProfile()
+			if state == nil || state.Name != profile.Name {
+				continue
+			}
+			p.Clients++
+			if state.Version == profile.Version() && state.IsAcknowledged() {
+				p.AcknowledgedClients++
+			}
+		}
+		payload = append(payload, p)
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(payload))
+}
+
+// handlePostMonitoringProfilesPush handles POST /monitoring-profiles/push
+// Profiles are pushed to clients on connect. Pushing them to all connected clients applies changes of the client
+// groups. It runs in the background, the result is stored in the monitoring_profile field of the clients.
+func (al *APIListener) handlePostMonitoringProfilesPush(w http.ResponseWriter, req *http.Request) {
+	if !al.config.Monitoring.Enabled {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, "Monitoring is disabled.")
+		return
+	}
+
+	var connected []*clientdata.Client
+	for _, client := range al.clientService.GetAll() {
+		if client.IsConnected() {
+			connected = append(connected, client)
+		}
+	}
+
+	go al.pushMonitoringProfiles(connected)
+
+	resp := monitoringProfilesPushResponse{Clients: len(connected)}
+
+	al.auditLog.Entry(auditlog.ApplicationMonitoringProfile, auditlog.ActionRequest).
+		WithHTTPRequest(req).
+		WithResponse(resp).
+		Save()
+
+	al.writeJSONResponse(w, http.StatusAccepted, api.NewSuccessPayload(resp))
+}
+
+func (al *APIListener) pushMonitoringProfiles(connected []*clientdata.Client) {
+	wg := sync.WaitGroup{}
+	for _, client := range connected {
+		wg.Add(1)
+		go func(client *clientdata.Client) {
+			defer wg.Done()
+			if err := al.pushMonitoringProfile(context.Background(), client); err != nil {
+				al.Errorf("clientID=%q, Failed to push monitoring profile: %v", client.GetID(), err)
+			}
+		}(client)
+	}
+	wg.Wait()
+
+	if al.testDone != nil {
+		al.testDone <- true
+	}
+}
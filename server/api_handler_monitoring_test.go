@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/realvnc-labs/rport/server/api/middleware"
 	"github.com/realvnc-labs/rport/server/chconfig"
 	"github.com/realvnc-labs/rport/server/clients"
 	"github.com/realvnc-labs/rport/server/clients/clientdata"
@@ -111,7 +113,7 @@ func TestListClientMetrics(t *testing.T) {
 		ProcessesListPayload:   lcpp,
 		MountpointsListPayload: nil,
 	}
-	monitoringService := monitoring.NewService(dbProvider)
+	monitoringService := monitoring.NewService(dbProvider, nil)
 	al := APIListener{
 		insecureForTests: true,
 		Server: &Server{
@@ -141,7 +143,7 @@ func TestListClientMetrics(t *testing.T) {
 			Name:           "metrics with fields, no filter, unknown field",
 			URL:            "metrics?fields[metrics]=timestamp,cpu_usage_percent,unknown_field",
 			ExpectedStatus: http.StatusBadRequest,
-			ExpectedJSON:   `{"errors":[{"code":"","title":"unsupported field \"unknown_field\" for resource \"metrics\"","detail":""}]}`,
+			ExpectedJSON:   `{"errors":[{"code":"ERR_CODE_INVALID_REQUEST","title":"unsupported field \"unknown_field\" for resource \"metrics\"","detail":""}],"request_id":"request-1"}`,
 		},
 		{
 			Name:           "metrics with timestamp filter, filter ok",
@@ -167,6 +169,7 @@ func TestListClientMetrics(t *testing.T) {
 		t.Run(tc.Name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			req := httptest.NewRequest("GET", "/api/v1/clients/test_client/"+tc.URL, nil)
+			req.Header.Set(middleware.RequestIDHeader, "request-1")
 			al.router.ServeHTTP(w, req)
 
 			assert.Equal(t, tc.ExpectedStatus, w.Code)
@@ -204,7 +207,7 @@ func TestMonitoringDisabled(t *testing.T) {
 		ProcessesListPayload:   lcpp,
 		MountpointsListPayload: nil,
 	}
-	monitoringService := monitoring.NewService(dbProvider)
+	monitoringService := monitoring.NewService(dbProvider, nil)
 
 	testCases := []struct {
 		Name           string
@@ -255,6 +258,7 @@ func TestMonitoringDisabled(t *testing.T) {
 
 			w := httptest.NewRecorder()
 			req := httptest.NewRequest("GET", "/api/v1/clients/test_client/"+tc.URL, nil)
+			req.Header.Set(middleware.RequestIDHeader, "request-1")
 			al.router.ServeHTTP(w, req)
 
 			assert.Equal(t, tc.ExpectedStatus, w.Code)
@@ -265,3 +269,90 @@ func TestMonitoringDisabled(t *testing.T) {
 		})
 	}
 }
+
+func TestHandlePostClientCustomMetrics(t *testing.T) {
+	c1 := clients.New(t).Logger(testLog).Build()
+
+	testCases := []struct {
+		Name           string
+		ClientID       string
+		Body           string
+		ExpectedStatus int
+		ExpectedJSON   string
+		ExpectedSaved  int
+	}{
+		{
+			Name:           "valid metrics",
+			ClientID:       c1.GetID(),
+			Body:           `{"metrics": {"queue_length": 12, "backup.age_hours": 3.5}}`,
+			ExpectedStatus: http.StatusNoContent,
+			ExpectedSaved:  2,
+		},
+		{
+			Name:           "non-existing client",
+			ClientID:       "non-existing-client",
+			Body:           `{"metrics": {"queue_length": 12}}`,
+			ExpectedStatus: http.StatusNotFound,
+			ExpectedJSON:   `{"errors":[{"code":"ERR_CODE_NOT_FOUND","title":"client with id non-existing-client not found","detail":""}],"request_id":"request-1"}`,
+		},
+		{
+			Name:           "empty metrics",
+			ClientID:       c1.GetID(),
+			Body:           `{"metrics": {}}`,
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedJSON:   `{"errors":[{"code":"ERR_CODE_INVALID_REQUEST","title":"metrics cannot be empty","detail":""}],"request_id":"request-1"}`,
+		},
+		{
+			Name:           "invalid name",
+			ClientID:       c1.GetID(),
+			Body:           `{"metrics": {"queue length": 12}}`,
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedJSON:   `{"errors":[{"code":"ERR_CODE_INVALID_REQUEST","title":"invalid metric name \"queue length\": only letters, digits, '_', '.' and '-' are allowed, max 100 characters","detail":""}],"request_id":"request-1"}`,
+		},
+		{
+			Name:           "invalid value",
+			ClientID:       c1.GetID(),
+			Body:           `{"metrics": {"queue_length": "12"}}`,
+			ExpectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			dbProvider := &monitoring.DBProviderMock{}
+			clientService := clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil)
+			al := APIListener{
+				insecureForTests: true,
+				Server: &Server{
+					clientService: clientService,
+					config: &chconfig.Config{
+						API: chconfig.APIConfig{
+							MaxRequestBytes: 1024 * 1024,
+						},
+						Monitoring: chconfig.MonitoringConfig{
+							Enabled: true,
+						},
+					},
+					monitoringService: monitoring.NewService(dbProvider, nil),
+				},
+				Logger: testLog,
+			}
+			al.initRouter()
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/clients/%s/custom-metrics", tc.ClientID), strings.NewReader(tc.Body))
+			req.Header.Set(middleware.RequestIDHeader, "request-1")
+
+			w := httptest.NewRecorder()
+			al.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.ExpectedStatus, w.Code)
+			if tc.ExpectedJSON != "" {
+				assert.JSONEq(t, tc.ExpectedJSON, w.Body.String())
+			}
+			assert.Len(t, dbProvider.CustomMeasurements, tc.ExpectedSaved)
+			for _, m := range dbProvider.CustomMeasurements {
+				assert.Equal(t, c1.GetID(), m.ClientID)
+			}
+		})
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/realvnc-labs/rport/plus/capabilities/alerting/entities/rules"
 	"github.com/realvnc-labs/rport/plus/capabilities/alerting/entities/templates"
 	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/auditlog"
 	"github.com/realvnc-labs/rport/server/routes"
 	"github.com/realvnc-labs/rport/share/query"
 )
@@ -61,7 +62,7 @@ func (al *APIListener) handleGetRuleSet(w http.ResponseWriter, _ *http.Request)
 	al.writeJSONResponse(w, http.StatusOK, response)
 }
 
-func (al *APIListener) handleDeleteRuleSet(w http.ResponseWriter, _ *http.Request) {
+func (al *APIListener) handleDeleteRuleSet(w http.ResponseWriter, r *http.Request) {
 	as, status, err := al.getAlertingService()
 	if err != nil {
 		al.jsonErrorResponse(w, status, err)
@@ -77,6 +78,11 @@ func (al *APIListener) handleDeleteRuleSet(w http.ResponseWriter, _ *http.Reques
 		return
 	}
 
+	al.auditLog.Entry(auditlog.ApplicationAlertingRuleSet, auditlog.ActionDelete).
+		WithHTTPRequest(r).
+		WithID(rules.DefaultRuleSetID).
+		Save()
+
 	al.Debugf("deleted ruleset = %s", rules.DefaultRuleSetID)
 }
 
@@ -103,11 +109,15 @@ func (al *APIListener) handleSaveRuleSet(w http.ResponseWriter, r *http.Request)
 
 	rs.RuleSetID = rules.DefaultRuleSetID
 
+	if errs := al.validateRunbookActions(r.Context(), rs); len(errs) > 0 {
+		al.writeErrorResponse(w, http.StatusBadRequest, makeValidationErrorPayload(errs))
+		return
+	}
+
 	errs, err := as.SaveRuleSet(rs)
 	if err != nil {
 		if errs != nil {
-			errPayload := makeValidationErrorPayload(errs)
-			al.writeJSONResponse(w, http.StatusBadRequest, errPayload)
+			al.writeErrorResponse(w, http.StatusBadRequest, makeValidationErrorPayload(errs))
 			return
 		}
 		al.jsonErrorResponse(w, http.StatusInternalServerError, err)
@@ -120,23 +130,27 @@ func (al *APIListener) handleSaveRuleSet(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	al.auditLog.Entry(auditlog.ApplicationAlertingRuleSet, auditlog.ActionUpdate).
+		WithHTTPRequest(r).
+		WithID(rs.RuleSetID).
+		WithRequest(rs).
+		Save()
+
 	al.Debugf("saved ruleset = %v", rs)
 }
 
-func makeValidationErrorPayload(errs validations.ErrorList) *api.ErrorPayload {
+func makeValidationErrorPayload(errs validations.ErrorList) api.ErrorPayload {
 	validationErrs := []api.ErrorPayloadItem{}
 	for _, validationErr := range errs {
 		vErr := api.ErrorPayloadItem{
-			Code:   "",
 			Title:  "error during rule set validation",
 			Detail: fmt.Sprintf("%s: %s", validationErr.Prefix, validationErr.Err.Error()),
 		}
 		validationErrs = append(validationErrs, vErr)
 	}
-	errPayload := &api.ErrorPayload{
+	return api.ErrorPayload{
 		Errors: validationErrs,
 	}
-	return errPayload
 }
 
 func (al *APIListener) handleSaveTemplate(w http.ResponseWriter, r *http.Request) {
@@ -178,17 +192,31 @@ func (al *APIListener) handleSaveTemplate(w http.ResponseWriter, r *http.Request
 		template.ID = templates.TemplateID(tid)
 	}
 
+	if errs := template.ValidateFormats(); len(errs) > 0 {
+		al.writeErrorResponse(w, http.StatusBadRequest, makeValidationErrorPayload(errs))
+		return
+	}
+
 	errs, err := as.SaveTemplate(template)
 	if err != nil {
 		if errs != nil {
-			errPayload := makeValidationErrorPayload(errs)
-			al.writeJSONResponse(w, http.StatusBadRequest, errPayload)
+			al.writeErrorResponse(w, http.StatusBadRequest, makeValidationErrorPayload(errs))
 			return
 		}
 		al.jsonErrorResponse(w, http.StatusInternalServerError, err)
 		return
 	}
 
+	action := auditlog.ActionUpdate
+	if r.Method == http.MethodPost {
+		action = auditlog.ActionCreate
+	}
+	al.auditLog.Entry(auditlog.ApplicationAlertingTemplate, action).
+		WithHTTPRequest(r).
+		WithID(template.ID).
+		WithRequest(template).
+		Save()
+
 	al.Debugf("saved template = %v", template)
 }
 
@@ -215,6 +243,11 @@ func (al *APIListener) handleDeleteTemplate(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	al.auditLog.Entry(auditlog.ApplicationAlertingTemplate, auditlog.ActionDelete).
+		WithHTTPRequest(r).
+		WithID(tid).
+		Save()
+
 	al.Debugf("deleted template = %s", tid)
 }
 
@@ -264,6 +297,147 @@ func (al *APIListener) handleGetAllTemplates(w http.ResponseWriter, _ *http.Requ
 	al.writeJSONResponse(w, http.StatusOK, response)
 }
 
+type renderTemplateRequest struct {
+	Template  *templates.Template `json:"template"`
+	ProblemID string              `json:"problem_id"`
+	ClientID  string              `json:"client_id"`
+}
+
+type renderTemplateResponse struct {
+	*templates.Rendered
+	Variables *templates.Data `json:"variables"`
+}
+
+// handleRenderTemplate handles POST /notification-templates/render and POST /notification-templates/{template_id}/render.
+// The template is rendered with the variables of the given problem and/or client or with sample data.
+func (al *APIListener) handleRenderTemplate(w http.ResponseWriter, r *http.Request) {
+	as, status, err := al.getAlertingService()
+	if err != nil {
+		al.jsonErrorResponse(w, status, err)
+		return
+	}
+
+	renderReq := &renderTemplateRequest{}
+	err = parseRequestBody(r.Body, renderReq)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	template := renderReq.Template
+	if tid := mux.Vars(r)[routes.ParamTemplateID]; tid != "" {
+		if template != nil {
+			al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, "when rendering a saved template, the template in request body must be omitted")
+			return
+		}
+		template, err = as.GetTemplate(templates.TemplateID(tid))
+		if err != nil && !errors.Is(err, alertingcap.ErrEntityNotFound) {
+			al.jsonErrorResponse(w, http.StatusInternalServerError, err)
+			return
+		}
+		if template == nil {
+			al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("template with id %q not found", tid))
+			return
+		}
+	}
+	if template == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, "missing template")
+		return
+	}
+
+	if errs := template.ValidateFormats(); len(errs) > 0 {
+		al.writeErrorResponse(w, http.StatusBadRequest, makeValidationErrorPayload(errs))
+		return
+	}
+
+	data, status, err := al.getTemplateData(as, renderReq.ProblemID, renderReq.ClientID)
+	if err != nil {
+		al.jsonErrorResponseWithTitle(w, status, err.Error())
+		return
+	}
+
+	rendered, err := template.Render(data)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusBadRequest, "failed to render template", err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(renderTemplateResponse{
+		Rendered:  rendered,
+		Variables: data,
+	}))
+}
+
+func (al *APIListener) getTemplateData(as alertingcap.Service, problemID, clientID string) (data *templates.Data, status int, err error) {
+	if problemID == "" && clientID == "" {
+		return templates.SampleData(), 0, nil
+	}
+
+	data = &templates.Data{
+		Outcome: templates.OutcomeProblem,
+	}
+
+	if problemID != "" {
+		problem, err := as.GetProblem(rules.ProblemID(problemID))
+		if err != nil && !errors.Is(err, alertingcap.ErrEntityNotFound) {
+			return nil, http.StatusInternalServerError, err
+		}
+		if problem == nil {
+			return nil, http.StatusNotFound, fmt.Errorf("problem with id %s not found", problemID)
+		}
+
+		data.Problem = templates.ProblemData{
+			ID:        string(problem.ID),
+			Active:    problem.Active,
+			CreatedAt: problem.CreatedAt,
+		}
+		if !problem.Active {
+			data.Outcome = templates.OutcomeResolved
+			if !problem.ResolvedAt.IsZero() {
+				resolvedAt := problem.ResolvedAt.Time
+				data.Problem.ResolvedAt = &resolvedAt
+			}
+		}
+		data.Rule.ID = string(problem.RuleID)
+		rs, err := as.LoadRuleSet(rules.DefaultRuleSetID)
+		if err == nil && rs != nil {
+			for _, rule := range rs.Rules {
+				if rule.ID == problem.RuleID {
+					data.Rule.Severity = string(rule.Severity)
+				}
+			}
+		}
+
+		if clientID == "" {
+			clientID = problem.ClientID
+		}
+	}
+
+	if clientID != "" {
+		data.Client.ID = clientID
+		client, err := al.clientService.GetByID(clientID)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		if client == nil && problemID == "" {
+			return nil, http.StatusNotFound, fmt.Errorf("client with id %s not found", clientID)
+		}
+		if client != nil {
+			data.Client = templates.ClientData{
+				ID:       client.GetID(),
+				Name:     client.GetName(),
+				Hostname: client.GetHostname(),
+				Address:  client.GetAddress(),
+				OS:       client.GetOS(),
+				Tags:     client.GetTags(),
+				Labels:   client.GetLabels(),
+			}
+		}
+	}
+
+	return data, 0, nil
+}
+
 func (al *APIListener) handleGetProblem(w http.ResponseWriter, r *http.Request) {
 	as, status, err := al.getAlertingService()
 	if err != nil {
@@ -322,6 +496,12 @@ func (al *APIListener) handleUpdateProblem(w http.ResponseWriter, r *http.Reques
 			return
 		}
 	}
+	al.auditLog.Entry(auditlog.ApplicationAlertingProblem, auditlog.ActionUpdate).
+		WithHTTPRequest(r).
+		WithID(pid).
+		WithRequest(problemUpdateRequest).
+		Save()
+
 	al.Debugf("updated problem = %v", problemUpdateRequest)
 }
 
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -473,6 +474,133 @@ func TestShouldGetAllTemplates(t *testing.T) {
 	assert.Equal(t, templates.TemplateID("t2"), templatesInfo.Data[1].ID)
 }
 
+func TestShouldRenderTemplate(t *testing.T) {
+	plusManager, plusConfig, plusLog := setupPlusAlerting()
+
+	_, err := plusManager.RegisterCapability(plusMockAlertingCapability, &alertingmock.Capability{
+		Logger: plusLog,
+	})
+	require.NoError(t, err)
+
+	al := setupTestAPIListenerForAlerting(t,
+		plusManager,
+		plusConfig,
+		plusLog)
+
+	body := `{"template": {
+		"subject": "{{.Outcome}} on {{.Client.Name}}",
+		"body": "<b>{{.Client.Hostname}}</b>",
+		"html": true,
+		"formats": {
+			"text": "{{.Rule.ID}} ({{.Rule.Severity}})",
+			"slack": "{\"blocks\": [{\"type\": \"section\", \"text\": {\"type\": \"mrkdwn\", \"text\": {{json .Client.Name}}}}]}"
+		}
+	}}`
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", routes.AllRoutesPrefix+routes.AlertingServiceRoutesPrefix+routes.ASTemplatesRoute+routes.ASRenderRoute, strings.NewReader(body))
+
+	al.router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var rendered struct {
+		Data templates.Rendered
+	}
+	err = json.NewDecoder(w.Body).Decode(&rendered)
+	require.NoError(t, err)
+
+	assert.Equal(t, templates.Rendered{
+		Subject: "problem on sample-client",
+		Body:    "<b>sample.example.com</b>",
+		Formats: map[string]string{
+			"text":  "sample-rule (critical)",
+			"slack": `{"blocks": [{"type": "section", "text": {"type": "mrkdwn", "text": "sample-client"}}]}`,
+		},
+	}, rendered.Data)
+}
+
+func TestShouldRenderSavedTemplateWithProblem(t *testing.T) {
+	plusManager, plusConfig, plusLog := setupPlusAlerting()
+
+	_, err := plusManager.RegisterCapability(plusMockAlertingCapability, &alertingmock.Capability{
+		Logger: plusLog,
+	})
+	require.NoError(t, err)
+
+	al := setupTestAPIListenerForAlerting(t,
+		plusManager,
+		plusConfig,
+		plusLog)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", routes.AllRoutesPrefix+routes.AlertingServiceRoutesPrefix+routes.ASTemplatesRoute+"/t1"+routes.ASRenderRoute, strings.NewReader(`{"problem_id": "p1"}`))
+
+	al.router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var rendered struct {
+		Data struct {
+			templates.Rendered
+			Variables templates.Data
+		}
+	}
+	err = json.NewDecoder(w.Body).Decode(&rendered)
+	require.NoError(t, err)
+
+	assert.Equal(t, "p1", rendered.Data.Variables.Problem.ID)
+	assert.Equal(t, templates.OutcomeResolved, rendered.Data.Variables.Outcome)
+	assert.Equal(t, "The client with ID:  has triggered rule ID:  BODY1", rendered.Data.Body)
+}
+
+func TestShouldNotRenderInvalidTemplate(t *testing.T) {
+	plusManager, plusConfig, plusLog := setupPlusAlerting()
+
+	_, err := plusManager.RegisterCapability(plusMockAlertingCapability, &alertingmock.Capability{
+		Logger: plusLog,
+	})
+	require.NoError(t, err)
+
+	al := setupTestAPIListenerForAlerting(t,
+		plusManager,
+		plusConfig,
+		plusLog)
+
+	testCases := []struct {
+		name string
+		body string
+	}{
+		{
+			name: "unknown format",
+			body: `{"template": {"formats": {"teams": "{{.Client.Name}}"}}}`,
+		},
+		{
+			name: "parse error",
+			body: `{"template": {"formats": {"text": "{{.Client.Name"}}}`,
+		},
+		{
+			name: "slack is not json",
+			body: `{"template": {"formats": {"slack": "{{.Client.Name}}"}}}`,
+		},
+		{
+			name: "missing template",
+			body: `{"client_id": "client-1"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", routes.AllRoutesPrefix+routes.AlertingServiceRoutesPrefix+routes.ASTemplatesRoute+routes.ASRenderRoute, strings.NewReader(tc.body))
+
+			al.router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
 func TestShouldOnGetErrorWhenProblemNotFound(t *testing.T) {
 	plusManager, plusConfig, plusLog := setupPlusAlerting()
 
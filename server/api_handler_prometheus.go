@@ -0,0 +1,117 @@
+package chserver
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/realvnc-labs/rport/db/pool"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/metrics"
+)
+
+const prometheusMetricsRoute = "/metrics"
+
+// registerClientGauges registers the gauges calculated from the clients when the metrics are scraped.
+func registerClientGauges(clientService clients.ClientService) {
+	metrics.NewGaugeFunc("rport_clients", "Number of clients known to the server, connected and disconnected.", func() float64 {
+		return float64(clientService.Count())
+	})
+	metrics.NewGaugeFunc("rport_clients_connected", "Number of connected clients.", func() float64 {
+		return float64(clientService.CountActive())
+	})
+	metrics.NewGaugeFunc("rport_tunnels_active", "Number of active tunnels.", func() float64 {
+		tunnels := 0
+		for _, c := range clientService.GetAll() {
+			tunnels += len(c.GetTunnels())
+		}
+		return float64(tunnels)
+	})
+}
+
+// registerDatabaseGauges registers the gauges of the connection pools of the SQL stores, labeled with the name of the
+// database, e.g. clients.db.
+func registerDatabaseGauges(slowQueryThreshold time.Duration) {
+	poolGauges := []struct {
+		name  string
+		help  string
+		value func(pool.Stats) float64
+	}{
+		{
+			name:  "rport_db_max_open_connections",
+			help:  "Maximum number of open connections to the database.",
+			value: func(s pool.Stats) float64 { return float64(s.MaxOpenConnections) },
+		},
+		{
+			name:  "rport_db_open_connections",
+			help:  "Number of established connections to the database, in use and idle.",
+			value: func(s pool.Stats) float64 { return float64(s.OpenConnections) },
+		},
+		{
+			name:  "rport_db_in_use_connections",
+			help:  "Number of connections to the database currently in use.",
+			value: func(s pool.Stats) float64 { return float64(s.InUse) },
+		},
+		{
+			name:  "rport_db_idle_connections",
+			help:  "Number of idle connections to the database.",
+			value: func(s pool.Stats) float64 { return float64(s.Idle) },
+		},
+		{
+			name:  "rport_db_wait_count",
+			help:  "Number of connections waited for.",
+			value: func(s pool.Stats) float64 { return float64(s.WaitCount) },
+		},
+		{
+			name:  "rport_db_wait_duration_seconds",
+			help:  "Time blocked waiting for new connections.",
+			value: func(s pool.Stats) float64 { return float64(s.WaitDurationMillis) / 1000 },
+		},
+		{
+			name:  "rport_db_max_idle_closed",
+			help:  "Number of connections closed due to the maximum of idle connections.",
+			value: func(s pool.Stats) float64 { return float64(s.MaxIdleClosed) },
+		},
+		{
+			name:  "rport_db_max_idle_time_closed",
+			help:  "Number of connections closed due to the maximum idle time.",
+			value: func(s pool.Stats) float64 { return float64(s.MaxIdleTimeClosed) },
+		},
+		{
+			name:  "rport_db_max_lifetime_closed",
+			help:  "Number of connections closed due to the maximum lifetime.",
+			value: func(s pool.Stats) float64 { return float64(s.MaxLifetimeClosed) },
+		},
+		{
+			name:  "rport_db_slow_queries",
+			help:  "Number of queries slower than the slow query threshold.",
+			value: func(s pool.Stats) float64 { return float64(s.SlowQueries) },
+		},
+	}
+	for _, g := range poolGauges {
+		value := g.value
+		metrics.NewGaugeVecFunc(g.name, g.help, []string{"database"}, func() []metrics.GaugeValue {
+			all := pool.AllStats()
+			values := make([]metrics.GaugeValue, 0, len(all))
+			for name, stats := range all {
+				values = append(values, metrics.GaugeValue{LabelValues: []string{name}, Value: value(stats)})
+			}
+			return values
+		})
+	}
+	metrics.NewGaugeFunc(
+		"rport_db_slow_query_threshold_seconds",
+		"Duration of queries counted as slow, 0 if slow queries are not logged.",
+		func() float64 {
+			return slowQueryThreshold.Seconds()
+		},
+	)
+}
+
+// handleGetPrometheusMetrics handles GET /metrics
+func (al *APIListener) handleGetPrometheusMetrics(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", metrics.ContentType)
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := metrics.Default.WriteTo(w); err != nil {
+		al.Errorf("Failed to write metrics: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package chserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/db/migration/dummy"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/metrics"
+)
+
+func TestHandleGetPrometheusMetrics(t *testing.T) {
+	c1 := clients.New(t).Logger(testLog).Build()
+	c2 := clients.New(t).DisconnectedDuration(5 * time.Minute).Logger(testLog).Build()
+	clientService := clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1, c2}, &hour, testLog), testLog, nil)
+	registerClientGauges(clientService)
+	db, err := sqlite.New(t.TempDir()+"/prometheus-test.db", dummy.AssetNames(), dummy.Asset, sqlite.DataSourceOptions{MaxOpenConnections: 2})
+	require.NoError(t, err)
+	defer db.Close()
+	registerDatabaseGauges(500 * time.Millisecond)
+
+	testCases := []struct {
+		name           string
+		disabled       bool
+		wantStatusCode int
+	}{
+		{
+			name:           "enabled",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "disabled",
+			disabled:       true,
+			wantStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			al := APIListener{
+				insecureForTests: true,
+				Server: &Server{
+					clientService: clientService,
+					config: &chconfig.Config{
+						API: chconfig.APIConfig{
+							MaxRequestBytes:          1024 * 1024,
+							PrometheusMetricsEnabled: !tc.disabled,
+						},
+					},
+				},
+				userService: users.NewAPIService(users.NewStaticProvider(nil), false, 0, -1),
+				Logger:      testLog,
+			}
+			al.initRouter()
+
+			w := httptest.NewRecorder()
+			al.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			require.Equal(t, tc.wantStatusCode, w.Code)
+			if tc.disabled {
+				return
+			}
+
+			assert.Equal(t, metrics.ContentType, w.Header().Get("Content-Type"))
+			body := w.Body.String()
+			assert.Contains(t, body, "# TYPE rport_clients gauge\nrport_clients 2\n")
+			assert.Contains(t, body, "# TYPE rport_clients_connected gauge\nrport_clients_connected 1\n")
+			assert.Contains(t, body, "# TYPE rport_tunnels_active gauge\nrport_tunnels_active 4\n")
+			assert.Contains(t, body, "# TYPE rport_client_connections_total counter\nrport_client_connections_total 0\n")
+			assert.Contains(t, body, "# TYPE rport_tunnels_opened_total counter\n")
+			assert.Contains(t, body, "# TYPE rport_jobs_created_total counter\n")
+			assert.Contains(t, body, "# TYPE rport_api_request_duration_seconds histogram\n")
+			assert.Contains(t, body, "# TYPE rport_db_max_open_connections gauge\n")
+			assert.Contains(t, body, `rport_db_max_open_connections{database="prometheus-test.db"} 2`+"\n")
+			assert.Contains(t, body, "# TYPE rport_db_slow_query_threshold_seconds gauge\nrport_db_slow_query_threshold_seconds 0.5\n")
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/reports"
+	"github.com/realvnc-labs/rport/server/routes"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+// handleGetReports handles GET /reports
+func (al *APIListener) handleGetReports(w http.ResponseWriter, req *http.Request) {
+	result, err := al.reports.List(req.Context(), query.GetListOptions(req))
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, result)
+}
+
+// handleGetReport handles GET /reports/{report_id}
+func (al *APIListener) handleGetReport(w http.ResponseWriter, req *http.Request) {
+	report, err := al.reports.Get(req.Context(), mux.Vars(req)[routes.ParamReportID])
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(report))
+}
+
+// handlePostReports handles POST /reports
+func (al *APIListener) handlePostReports(w http.ResponseWriter, req *http.Request) {
+	report := &reports.Report{}
+	err := parseRequestBody(req.Body, report)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	curUser, err := al.getUserModelForAuth(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	report.CreatedBy = curUser.Username
+
+	report, err = al.reports.Create(req.Context(), report)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationReport, auditlog.ActionCreate).
+		WithHTTPRequest(req).
+		WithRequest(report).
+		WithID(report.ID).
+		Save()
+
+	al.writeJSONResponse(w, http.StatusCreated, api.NewSuccessPayload(report))
+}
+
+// handlePutReport handles PUT /reports/{report_id}
+func (al *APIListener) handlePutReport(w http.ResponseWriter, req *http.Request) {
+	report := &reports.Report{}
+	err := parseRequestBody(req.Body, report)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	report, err = al.reports.Update(req.Context(), mux.Vars(req)[routes.ParamReportID], report)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationReport, auditlog.ActionUpdate).
+		WithHTTPRequest(req).
+		WithRequest(report).
+		WithID(report.ID).
+		Save()
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(report))
+}
+
+// handleDeleteReport handles DELETE /reports/{report_id}
+func (al *APIListener) handleDeleteReport(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)[routes.ParamReportID]
+	err := al.reports.Delete(req.Context(), id)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationReport, auditlog.ActionDelete).
+		WithHTTPRequest(req).
+		WithID(id).
+		Save()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePostReportRun handles POST /reports/{report_id}/run
+func (al *APIListener) handlePostReportRun(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)[routes.ParamReportID]
+	run, err := al.reports.Run(req.Context(), id, reports.TriggerManual)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationReport, auditlog.ActionExecuteStart).
+		WithHTTPRequest(req).
+		WithID(id).
+		WithResponse(run).
+		Save()
+
+	al.writeJSONResponse(w, http.StatusCreated, api.NewSuccessPayload(run))
+}
+
+// handleGetReportRuns handles GET /reports/{report_id}/runs
+func (al *APIListener) handleGetReportRuns(w http.ResponseWriter, req *http.Request) {
+	runs, err := al.reports.ListRuns(req.Context(), mux.Vars(req)[routes.ParamReportID])
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(runs))
+}
+
+// handleGetReportRunDownload handles GET /reports/{report_id}/runs/{run_id}/download
+func (al *APIListener) handleGetReportRunDownload(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	report, run, err := al.reports.GetRun(req.Context(), vars[routes.ParamReportID], vars[routes.ParamReportRunID])
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if len(run.Content) == 0 {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("Run %q failed without content: %s", run.ID, run.Error))
+		return
+	}
+
+	w.Header().Set("Content-Type", run.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", run.Filename(report)))
+	w.Header().Set("Content-Length", strconv.Itoa(len(run.Content)))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(run.Content); err != nil {
+		al.Errorf("Failed to write report %s: %v", run.ID, err)
+	}
+}
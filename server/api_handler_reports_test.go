@@ -0,0 +1,108 @@
+package chserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	reportsmigration "github.com/realvnc-labs/rport/db/migration/reports"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/jobs"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/reports"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+type reportsSourceMock struct{}
+
+func (reportsSourceMock) Clients(ctx context.Context, filters []query.FilterOption) ([]*clientdata.CalculatedClient, error) {
+	return []*clientdata.CalculatedClient{{
+		Client:          &clientdata.Client{ID: "client-1", Name: "web-1", Hostname: "web-1.example.com"},
+		ConnectionState: clientdata.Connected,
+	}}, nil
+}
+
+func (reportsSourceMock) JobStats(ctx context.Context, since time.Time) ([]*jobs.JobStats, error) {
+	return nil, nil
+}
+
+func TestHandleReports(t *testing.T) {
+	testUser := "admin"
+	db, err := sqlite.New(":memory:", reportsmigration.AssetNames(), reportsmigration.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	manager, err := reports.New(context.Background(), reports.NewSQLiteProvider(db), reportsSourceMock{}, testLog)
+	require.NoError(t, err)
+	defer manager.Close()
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+			reports: manager,
+		},
+		userService: users.NewAPIService(users.NewStaticProvider([]*users.User{{
+			Username: testUser,
+			Groups:   []string{users.Administrators},
+		}}), false, 0, -1),
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	ctx := api.WithUser(context.Background(), testUser)
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body)).WithContext(ctx)
+		w := httptest.NewRecorder()
+		al.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, "/api/v1/reports", `{"name": "Inventory", "type": "inventory", "format": "doc"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `invalid format \"doc\", expected one of: csv, pdf`)
+
+	w = do(http.MethodPost, "/api/v1/reports", `{"name": "Inventory", "type": "inventory"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created struct {
+		Data reports.Report `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, testUser, created.Data.CreatedBy)
+	assert.Equal(t, reports.FormatCSV, created.Data.Format)
+
+	w = do(http.MethodPost, "/api/v1/reports/"+created.Data.ID+"/run", "")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var run struct {
+		Data reports.Run `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &run))
+	assert.Equal(t, reports.RunStatusSuccessful, run.Data.Status)
+	assert.Equal(t, 1, run.Data.Rows)
+
+	w = do(http.MethodGet, "/api/v1/reports/"+created.Data.ID+"/runs/"+run.Data.ID+"/download", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="`+run.Data.Filename(&created.Data)+`"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "id,name,hostname,os,os_kernel,os_arch,ipv4,version,tags,connection_state\nclient-1,web-1,web-1.example.com,,,,,,,connected\n", w.Body.String())
+
+	w = do(http.MethodGet, "/api/v1/reports/"+created.Data.ID+"/runs/unknown/download", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = do(http.MethodDelete, "/api/v1/reports/"+created.Data.ID, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = do(http.MethodGet, "/api/v1/reports/"+created.Data.ID, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
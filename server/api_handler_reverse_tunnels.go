@@ -0,0 +1,232 @@
+package chserver
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	errors2 "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/reversetunnel"
+	"github.com/realvnc-labs/rport/server/routes"
+	"github.com/realvnc-labs/rport/share/comm"
+	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/random"
+)
+
+type ReverseTunnelRequest struct {
+	// Local is the address the client listens on, either host:port or a port to listen on 127.0.0.1
+	Local string `json:"local"`
+	// Target is dialed by the server, it must be allowed by the reverse_tunnel_targets config
+	Target string `json:"target"`
+	// TargetClientID and TargetTunnelID select the tunnel of another client as target instead
+	TargetClientID string `json:"target_client_id"`
+	TargetTunnelID string `json:"target_tunnel_id"`
+}
+
+// handleGetReverseTunnels handles GET /clients/{client_id}/reverse-tunnels
+func (al *APIListener) handleGetReverseTunnels(w http.ResponseWriter, req *http.Request) {
+	clientID := mux.Vars(req)[routes.ParamClientID]
+	client, err := al.clientService.GetByID(clientID)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if client == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("client with id %q not found", clientID))
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(client.GetReverseTunnels()))
+}
+
+// handlePostReverseTunnel handles POST /clients/{client_id}/reverse-tunnels
+func (al *APIListener) handlePostReverseTunnel(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	clientID := mux.Vars(req)[routes.ParamClientID]
+
+	var reqBody ReverseTunnelRequest
+	err := parseRequestBody(req.Body, &reqBody)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	t, err := newReverseTunnel(reqBody)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusBadRequest, "Invalid reverse tunnel.", err)
+		return
+	}
+
+	client, err := al.clientService.GetActiveByID(clientID)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to find an active client with id=%q.", clientID), err)
+		return
+	}
+	if client == nil {
+		al.jsonErrorResponseWithErrCode(w, http.StatusNotFound, errors2.ErrCodeClientNotActive, fmt.Sprintf("Active client with id=%q not found.", clientID))
+		return
+	}
+	if client.IsCheckInOnly() {
+		al.jsonErrorResponseWithErrCode(w, http.StatusConflict, errors2.ErrCodeClientCheckInOnly, fmt.Sprintf("failed to start reverse tunnel for client with id %s: %v", clientID, ErrClientCheckInOnly))
+		return
+	}
+	if err := client.CheckQuarantine(); err != nil {
+		al.jsonErrorResponseWithErrCode(w, http.StatusConflict, errors2.ErrCodeClientQuarantined, fmt.Sprintf("failed to start reverse tunnel for client with id %s: %v", clientID, err))
+		return
+	}
+	if existing := client.FindReverseTunnelByLocal(t.Local()); existing != nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusConflict, fmt.Sprintf("Reverse tunnel %s already listens on %s.", existing.ID, t.Local()))
+		return
+	}
+
+	if t.TargetClientID != "" {
+		if err := al.checkReverseTunnelTargetClient(req, t); err != nil {
+			al.jsonError(w, err)
+			return
+		}
+	} else if !reversetunnel.TargetAllowed(al.config.Server.ReverseTunnelTargets, t.Target) {
+		al.jsonErrorResponseWithTitle(w, http.StatusForbidden, fmt.Sprintf("Target %q is not allowed by the reverse_tunnel_targets config.", t.Target))
+		return
+	}
+
+	t.ID, err = random.UUID4()
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	t.Owner = api.GetUser(ctx, al.Logger)
+	t.CreatedAt = time.Now().UTC()
+
+	err = sendStartReverseTunnel(client, t, al.Log())
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusConflict, "Failed to start the reverse tunnel on the client.", err)
+		return
+	}
+
+	client.AddReverseTunnel(t)
+	err = al.clientService.GetRepo().Save(client)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, "Failed to save the reverse tunnel.", err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationClientReverseTunnel, auditlog.ActionCreate).
+		WithHTTPRequest(req).
+		WithClient(client).
+		WithID(t.ID).
+		WithRequest(reqBody).
+		Save()
+
+	al.writeJSONResponse(w, http.StatusCreated, api.NewSuccessPayload(t))
+}
+
+// handleDeleteReverseTunnel handles DELETE /clients/{client_id}/reverse-tunnels/{reverse_tunnel_id}. The reverse
+// tunnel is removed even if the client is disconnected, the client stops listening when it reconnects or restarts.
+func (al *APIListener) handleDeleteReverseTunnel(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	clientID := vars[routes.ParamClientID]
+	id := vars[routes.ParamReverseTunnelID]
+
+	client, err := al.clientService.GetByID(clientID)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if client == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("client with id %q not found", clientID))
+		return
+	}
+	if client.FindReverseTunnel(id) == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("reverse tunnel with id %q not found", id))
+		return
+	}
+
+	if client.IsConnected() && client.GetConnection() != nil {
+		stopReq := &comm.StopReverseTunnelRequest{ID: id}
+		if err := comm.SendRequestAndGetResponse(client.GetConnection(), comm.RequestTypeStopReverseTunnel, stopReq, nil, al.Log()); err != nil {
+			al.Infof("failed to stop reverse tunnel %s on client %s: %v", id, clientID, err)
+		}
+	}
+
+	client.RemoveReverseTunnel(id)
+	err = al.clientService.GetRepo().Save(client)
+	if err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusInternalServerError, "Failed to delete the reverse tunnel.", err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationClientReverseTunnel, auditlog.ActionDelete).
+		WithHTTPRequest(req).
+		WithClient(client).
+		WithID(id).
+		Save()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newReverseTunnel(reqBody ReverseTunnelRequest) (*models.ReverseTunnel, error) {
+	t := &models.ReverseTunnel{
+		Target:         reqBody.Target,
+		TargetClientID: reqBody.TargetClientID,
+		TargetTunnelID: reqBody.TargetTunnelID,
+	}
+
+	host, port, err := net.SplitHostPort(reqBody.Local)
+	if err != nil {
+		host, port = models.LocalHost, reqBody.Local
+	}
+	t.LocalHost, t.LocalPort = host, port
+	if err := t.ValidateLocal(); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case t.TargetClientID != "":
+		if t.Target != "" {
+			return nil, errors.New("either target or target_client_id can be given")
+		}
+		if t.TargetTunnelID == "" {
+			return nil, errors.New("target_tunnel_id is required with target_client_id")
+		}
+	case t.TargetTunnelID != "":
+		return nil, errors.New("target_tunnel_id requires target_client_id")
+	case t.Target == "":
+		return nil, errors.New("either target or target_client_id is required")
+	default:
+		if err := reversetunnel.ValidateTarget(t.Target); err != nil {
+			return nil, fmt.Errorf("invalid target %q: %v", t.Target, err)
+		}
+	}
+	return t, nil
+}
+
+// checkReverseTunnelTargetClient checks the user has access to the target client and its tunnel exists.
+func (al *APIListener) checkReverseTunnelTargetClient(req *http.Request, t *models.ReverseTunnel) error {
+	ctx := req.Context()
+	curUser, err := al.getUserModelForAuth(ctx)
+	if err != nil {
+		return err
+	}
+	groups, err := al.clientGroupProvider.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	if err := al.clientService.CheckClientAccess(t.TargetClientID, curUser, groups); err != nil {
+		return err
+	}
+
+	if _, err := al.resolveReverseTunnelTarget(t); err != nil {
+		return errors2.APIError{
+			HTTPStatus: http.StatusBadRequest,
+			Message:    "Invalid target tunnel.",
+			Err:        err,
+		}
+	}
+	return nil
+}
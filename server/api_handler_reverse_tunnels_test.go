@@ -0,0 +1,152 @@
+package chserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/share/comm"
+	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/test"
+)
+
+func TestHandlePostReverseTunnel(t *testing.T) {
+	testCases := []struct {
+		Name           string
+		Body           string
+		SSHError       bool
+		ExpectedStatus int
+		ExpectedLocal  string
+	}{
+		{
+			Name:           "port only",
+			Body:           `{"local": "3128", "target": "10.0.0.5:3128"}`,
+			ExpectedStatus: http.StatusCreated,
+			ExpectedLocal:  "127.0.0.1:3128",
+		},
+		{
+			Name:           "host and port",
+			Body:           `{"local": "0.0.0.0:8080", "target": "proxy.example.com:8080"}`,
+			ExpectedStatus: http.StatusCreated,
+			ExpectedLocal:  "0.0.0.0:8080",
+		},
+		{
+			Name:           "target not allowed",
+			Body:           `{"local": "3128", "target": "10.0.0.6:22"}`,
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:           "invalid local",
+			Body:           `{"local": "70000", "target": "10.0.0.5:3128"}`,
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:           "missing target",
+			Body:           `{"local": "3128"}`,
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:           "target and target client",
+			Body:           `{"local": "3128", "target": "10.0.0.5:3128", "target_client_id": "c2", "target_tunnel_id": "1"}`,
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:           "rejected by client",
+			Body:           `{"local": "3128", "target": "10.0.0.5:3128"}`,
+			SSHError:       true,
+			ExpectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			c1 := clients.New(t).Logger(testLog).Build()
+			connMock := test.NewConnMock()
+			connMock.ReturnOk = !tc.SSHError
+			c1.SetConnection(connMock)
+			al := newReverseTunnelsTestAPIListener(c1)
+
+			w := httptest.NewRecorder()
+			al.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/clients/%s/reverse-tunnels", c1.GetID()), strings.NewReader(tc.Body)))
+			require.Equal(t, tc.ExpectedStatus, w.Code, w.Body.String())
+			if tc.ExpectedStatus != http.StatusCreated {
+				assert.Empty(t, c1.GetReverseTunnels())
+				return
+			}
+
+			var res struct {
+				Data models.ReverseTunnel `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			assert.NotEmpty(t, res.Data.ID)
+			assert.Equal(t, tc.ExpectedLocal, res.Data.Local())
+			require.Len(t, c1.GetReverseTunnels(), 1)
+
+			name, _, payload := connMock.InputSendRequest()
+			assert.Equal(t, comm.RequestTypeStartReverseTunnel, name)
+			var startReq comm.StartReverseTunnelRequest
+			require.NoError(t, json.Unmarshal(payload, &startReq))
+			assert.Equal(t, res.Data.ID, startReq.ID)
+
+			// the same local address can't be used twice
+			w = httptest.NewRecorder()
+			al.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/clients/%s/reverse-tunnels", c1.GetID()), strings.NewReader(tc.Body)))
+			assert.Equal(t, http.StatusConflict, w.Code)
+		})
+	}
+}
+
+func TestHandleDeleteReverseTunnel(t *testing.T) {
+	c1 := clients.New(t).Logger(testLog).Build()
+	connMock := test.NewConnMock()
+	connMock.ReturnOk = true
+	c1.SetConnection(connMock)
+	c1.AddReverseTunnel(&models.ReverseTunnel{ID: "rt1", LocalHost: "127.0.0.1", LocalPort: "3128", Target: "10.0.0.5:3128"})
+	al := newReverseTunnelsTestAPIListener(c1)
+
+	w := httptest.NewRecorder()
+	al.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/clients/%s/reverse-tunnels", c1.GetID()), nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"rt1"`)
+
+	w = httptest.NewRecorder()
+	al.router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/clients/%s/reverse-tunnels/rt1", c1.GetID()), nil))
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, c1.GetReverseTunnels())
+	name, _, _ := connMock.InputSendRequest()
+	assert.Equal(t, comm.RequestTypeStopReverseTunnel, name)
+
+	w = httptest.NewRecorder()
+	al.router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/clients/%s/reverse-tunnels/rt1", c1.GetID()), nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func newReverseTunnelsTestAPIListener(c1 *clientdata.Client) *APIListener {
+	clientService := clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil)
+	al := &APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			clientService: clientService,
+			config: &chconfig.Config{
+				Server: chconfig.ServerConfig{
+					ReverseTunnelTargets: []string{"10.0.0.5:3128", "proxy.example.com:*"},
+				},
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+		},
+		Logger: testLog,
+	}
+	al.initRouter()
+	return al
+}
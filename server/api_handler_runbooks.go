@@ -0,0 +1,210 @@
+package chserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	errors2 "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/api/jobs/runbook"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/routes"
+)
+
+// handleMaterializeScript handles POST /library/scripts/{script_id}/materialize
+func (al *APIListener) handleMaterializeScript(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	vars := mux.Vars(req)
+	idStr := vars[routes.ParamScriptValueID]
+	if idStr == "" {
+		al.jsonError(w, errors2.APIError{
+			Err:        errors.New("empty script id provided"),
+			HTTPStatus: http.StatusBadRequest,
+		})
+		return
+	}
+
+	var input runbook.MaterializeRequest
+	err := parseRequestBody(req.Body, &input)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	foundScript, found, err := al.scriptManager.GetOne(ctx, req, idStr)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if !found {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("Cannot find a script by the provided id: %s", idStr))
+		return
+	}
+
+	orderedClients, err := al.checkRunbookTargets(req, &input)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	curUser, err := al.getUserModelForAuth(ctx)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	rb, err := al.runbookManager.Materialize(ctx, foundScript, &input, curUser.GetUsername())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationRunbook, auditlog.ActionCreate).
+		WithHTTPRequest(req).
+		WithRequest(input).
+		WithResponse(rb).
+		WithID(rb.ID).
+		SaveForMultipleClients(orderedClients)
+
+	al.writeJSONResponse(w, http.StatusCreated, api.NewSuccessPayload(rb))
+}
+
+// checkRunbookTargets validates the targets and returns the targeted clients if the current user has access to all of them.
+func (al *APIListener) checkRunbookTargets(req *http.Request, params TargetingParams) ([]*clientdata.Client, error) {
+	ctx := req.Context()
+	orderedClients, _, err := al.getOrderedClientsWithValidation(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	curUser, err := al.getUserModelForAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	clientGroups, err := al.clientGroupProvider.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = al.clientService.CheckClientsAccess(orderedClients, curUser, clientGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	return orderedClients, nil
+}
+
+// handleListRunbooks handles GET /runbooks
+func (al *APIListener) handleListRunbooks(w http.ResponseWriter, req *http.Request) {
+	items, err := al.runbookManager.List(req.Context(), req)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, items)
+}
+
+// handleGetRunbook handles GET /runbooks/{runbook_id}
+func (al *APIListener) handleGetRunbook(w http.ResponseWriter, req *http.Request) {
+	rb, ok := al.getRunbookFromRequest(w, req)
+	if !ok {
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(rb))
+}
+
+// handleDeleteRunbook handles DELETE /runbooks/{runbook_id}
+func (al *APIListener) handleDeleteRunbook(w http.ResponseWriter, req *http.Request) {
+	rb, ok := al.getRunbookFromRequest(w, req)
+	if !ok {
+		return
+	}
+
+	err := al.runbookManager.Delete(req.Context(), rb.ID)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationRunbook, auditlog.ActionDelete).
+		WithHTTPRequest(req).
+		WithID(rb.ID).
+		Save()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleExecuteRunbook handles POST /runbooks/{runbook_id}/execute
+func (al *APIListener) handleExecuteRunbook(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	rb, ok := al.getRunbookFromRequest(w, req)
+	if !ok {
+		return
+	}
+
+	orderedClients, err := al.checkRunbookTargets(req, rb)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	if err := checkClientsInterpreter(rb.Details.Interpreter, orderedClients); err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	curUser, err := al.getUserModelForAuth(ctx)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	multiJob, err := al.runbookManager.Run(ctx, rb, curUser.GetUsername(), orderedClients)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	resp := newJobResponse{
+		JID: multiJob.JID,
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationRunbook, auditlog.ActionExecuteStart).
+		WithHTTPRequest(req).
+		WithResponse(resp).
+		WithID(rb.ID).
+		SaveForMultipleClients(orderedClients)
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(resp))
+}
+
+// handleListRunbookRemediations handles GET /runbook-remediations
+func (al *APIListener) handleListRunbookRemediations(w http.ResponseWriter, req *http.Request) {
+	items, err := al.runbookManager.ListRemediations(req.Context(), req)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, items)
+}
+
+func (al *APIListener) getRunbookFromRequest(w http.ResponseWriter, req *http.Request) (*runbook.Runbook, bool) {
+	idStr := mux.Vars(req)["runbook_id"]
+	rb, err := al.runbookManager.Get(req.Context(), idStr)
+	if err != nil {
+		al.jsonError(w, err)
+		return nil, false
+	}
+	if rb == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("Cannot find a runbook by the provided id: %s", idStr))
+		return nil, false
+	}
+
+	return rb, true
+}
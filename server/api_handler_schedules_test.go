@@ -15,6 +15,7 @@ import (
 
 	"github.com/realvnc-labs/rport/server/api"
 	"github.com/realvnc-labs/rport/server/api/jobs/schedule"
+	"github.com/realvnc-labs/rport/server/api/middleware"
 	"github.com/realvnc-labs/rport/server/cgroups"
 	"github.com/realvnc-labs/rport/server/clients"
 	"github.com/realvnc-labs/rport/server/clients/clientdata"
@@ -240,7 +241,8 @@ func TestHandlePostScheduleMultiClientJobWithTags(t *testing.T) {
 				require.NotEmpty(t, gotID)
 			} else {
 				// failure case
-				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail)
+				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail).WithDefaultCode(tc.wantStatusCode)
+				wantResp.RequestID = w.Header().Get(middleware.RequestIDHeader)
 				wantRespBytes, err := json.Marshal(wantResp)
 				require.NoError(t, err)
 				require.Equal(t, string(wantRespBytes), w.Body.String())
@@ -484,7 +486,8 @@ func TestHandlePostUpdateScheduleMultiClientJobWithTags(t *testing.T) {
 				require.NotEmpty(t, gotID)
 			} else {
 				// failure case
-				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail)
+				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail).WithDefaultCode(tc.wantStatusCode)
+				wantResp.RequestID = w.Header().Get(middleware.RequestIDHeader)
 				wantRespBytes, err := json.Marshal(wantResp)
 				require.NoError(t, err)
 				require.Equal(t, string(wantRespBytes), w.Body.String())
@@ -13,6 +13,7 @@ import (
 	"github.com/realvnc-labs/rport/server/api/jobs"
 	"github.com/realvnc-labs/rport/server/auditlog"
 	"github.com/realvnc-labs/rport/server/routes"
+	"github.com/realvnc-labs/rport/server/validation"
 	"github.com/realvnc-labs/rport/share/ws"
 )
 
@@ -53,6 +54,7 @@ func (al *APIListener) handleExecuteScript(w http.ResponseWriter, req *http.Requ
 			WithHTTPRequest(req).
 			WithClientID(cid).
 			WithRequest(execCmdInput).
+			WithLabels(execCmdInput.Labels).
 			WithResponse(resp).
 			WithID(resp.JID).
 			Save()
@@ -83,6 +85,20 @@ func (al *APIListener) handlePostMultiClientScript(w http.ResponseWriter, req *h
 		return
 	}
 
+	if err := checkClientsInterpreter(inboundMsg.Interpreter, inboundMsg.OrderedClients); err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	if err := validation.ValidateLabels(inboundMsg.Labels); err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusBadRequest, "Invalid labels.", err)
+		return
+	}
+	if err := inboundMsg.ValidatePreflight(); err != nil {
+		al.jsonErrorResponseWithError(w, http.StatusBadRequest, "Invalid pre-flight check.", err)
+		return
+	}
+
 	curUser, err := al.getUserModelForAuth(req.Context())
 	if err != nil {
 		al.jsonError(w, err)
@@ -100,6 +116,12 @@ func (al *APIListener) handlePostMultiClientScript(w http.ResponseWriter, req *h
 
 	inboundMsg.Username = curUser.Username
 
+	unreachable, err := al.runPreflightCheck(ctx, inboundMsg)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
 	multiJob, err := al.StartMultiClientJob(ctx, inboundMsg)
 	if err != nil {
 		al.jsonError(w, err)
@@ -107,15 +129,17 @@ func (al *APIListener) handlePostMultiClientScript(w http.ResponseWriter, req *h
 	}
 
 	resp := newJobResponse{
-		JID: multiJob.JID,
+		JID:         multiJob.JID,
+		Unreachable: unreachable,
 	}
 
 	al.auditLog.Entry(auditlog.ApplicationClientScript, auditlog.ActionExecuteStart).
 		WithHTTPRequest(req).
 		WithRequest(inboundMsg).
+		WithLabels(inboundMsg.Labels).
 		WithResponse(resp).
 		WithID(multiJob.JID).
-		SaveForMultipleClients(inboundMsg.OrderedClients)
+		SaveForMultipleClients(inboundMsg.TargetClients())
 
 	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(resp))
 
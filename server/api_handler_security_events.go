@@ -0,0 +1,81 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/securityevents"
+)
+
+const (
+	securityEventsSinceQueryParam  = "since"
+	securityEventsBucketQueryParam = "bucket"
+	securityEventsTopQueryParam    = "top"
+	securityEventsFormatQueryParam = "format"
+
+	securityEventsMaxBuckets = 1000
+)
+
+// handleGetSecurityEventsSummary handles GET /security-events/summary
+// It returns the counts of auth failures, lockouts, 2FA failures and denied tunnel connections grouped by time
+// and the top offending IPs. With format=csv the time buckets are exported as CSV.
+func (al *APIListener) handleGetSecurityEventsSummary(w http.ResponseWriter, req *http.Request) {
+	since, ok := al.parseDurationQueryParam(w, req, securityEventsSinceQueryParam, 24*time.Hour)
+	if !ok {
+		return
+	}
+	if since > securityevents.DefaultRetention {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s: events are kept for %s only.", securityEventsSinceQueryParam, securityevents.DefaultRetention))
+		return
+	}
+	bucket, ok := al.parseDurationQueryParam(w, req, securityEventsBucketQueryParam, time.Hour)
+	if !ok {
+		return
+	}
+	if since/bucket > securityEventsMaxBuckets {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s: at most %d buckets are allowed.", securityEventsBucketQueryParam, securityEventsMaxBuckets))
+		return
+	}
+
+	top := 10
+	if topStr := req.URL.Query().Get(securityEventsTopQueryParam); topStr != "" {
+		var err error
+		top, err = strconv.Atoi(topStr)
+		if err != nil || top < 0 {
+			al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s: %q.", securityEventsTopQueryParam, topStr))
+			return
+		}
+	}
+
+	summary := al.securityEvents.Summary(time.Now().Add(-since), bucket, top)
+
+	switch format := req.URL.Query().Get(securityEventsFormatQueryParam); format {
+	case "", "json":
+		al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(summary))
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="security-events.csv"`)
+		w.WriteHeader(http.StatusOK)
+		if err := summary.WriteCSV(w); err != nil {
+			al.Errorf("Failed to write security events CSV: %v", err)
+		}
+	default:
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s: %q.", securityEventsFormatQueryParam, format))
+	}
+}
+
+func (al *APIListener) parseDurationQueryParam(w http.ResponseWriter, req *http.Request, param string, defaultValue time.Duration) (time.Duration, bool) {
+	str := req.URL.Query().Get(param)
+	if str == "" {
+		return defaultValue, true
+	}
+	d, err := time.ParseDuration(str)
+	if err != nil || d <= 0 {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s: %q.", param, str))
+		return 0, false
+	}
+	return d, true
+}
@@ -0,0 +1,104 @@
+package chserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/securityevents"
+	"github.com/realvnc-labs/rport/share/security"
+)
+
+func TestHandleGetSecurityEventsSummary(t *testing.T) {
+	testUser := "admin"
+	store := securityevents.NewStore(securityevents.DefaultRetention, securityevents.DefaultMaxEvents)
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+			securityEvents: store,
+		},
+		bannedIPs: security.NewMaxBadAttemptsBanList(2, time.Minute, testLog),
+		userService: users.NewAPIService(users.NewStaticProvider([]*users.User{{
+			Username: testUser,
+			Groups:   []string{users.Administrators},
+		}}), false, 0, -1),
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	failedLogin := httptest.NewRequest(http.MethodPost, "/api/v1/login", nil)
+	failedLogin.RemoteAddr = "203.0.113.5:1234"
+	al.handleBannedIPs(failedLogin, false)
+	al.handleBannedIPs(failedLogin, false)
+	failed2FA := httptest.NewRequest(http.MethodPost, "/api/v1/verify-2fa", nil)
+	failed2FA.RemoteAddr = "192.0.2.2:1234"
+	al.handleBannedIPsWithEventKind(failed2FA, false, securityevents.Kind2FAFailure)
+	store.Add(securityevents.KindTunnelACLDenied, "198.51.100.7", "client 1 tunnel 1")
+
+	ctx := api.WithUser(context.Background(), testUser)
+
+	t.Run("json", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/security-events/summary?since=2h&bucket=30m&top=1", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		al.router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var gotResp struct {
+			Data securityevents.Summary `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &gotResp))
+		assert.Equal(t, map[securityevents.Kind]int{
+			securityevents.KindAuthFailure:     2,
+			securityevents.KindLockout:         1,
+			securityevents.Kind2FAFailure:      1,
+			securityevents.KindTunnelACLDenied: 1,
+		}, gotResp.Data.Totals)
+		assert.Len(t, gotResp.Data.Buckets, 5)
+		require.Len(t, gotResp.Data.TopIPs, 1)
+		assert.Equal(t, "203.0.113.5", gotResp.Data.TopIPs[0].IP)
+		assert.Equal(t, 3, gotResp.Data.TopIPs[0].Total)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/security-events/summary?since=1h&format=csv", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		al.router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), "start,total,auth_failure,lockout,2fa_failure,tunnel_acl_denied\n")
+		assert.Contains(t, w.Body.String(), ",5,2,1,1,1\n")
+	})
+
+	invalid := []string{
+		"since=-1h",
+		"since=30d",
+		"since=720h",
+		"bucket=1s",
+		"top=x",
+		"format=xml",
+	}
+	for _, params := range invalid {
+		t.Run(params, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/security-events/summary?"+params, nil).WithContext(ctx)
+			w := httptest.NewRecorder()
+			al.router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
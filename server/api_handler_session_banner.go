@@ -0,0 +1,62 @@
+package chserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/routes"
+)
+
+type sessionBannerPayload struct {
+	Banner string `json:"banner"`
+}
+
+// sessionBanner returns the banner shown at the start of interactive sessions on the client, the global banner
+// followed by the banners of the client groups the client belongs to. It's empty if none is configured.
+func (al *APIListener) sessionBanner(ctx context.Context, client *clientdata.Client) (string, error) {
+	var banners []string
+	if banner := strings.TrimSpace(al.config.Server.SessionBanner); banner != "" {
+		banners = append(banners, banner)
+	}
+
+	groups, err := al.clientGroupProvider.GetAll(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, group := range groups {
+		banner := strings.TrimSpace(group.Banner)
+		if banner != "" && client.BelongsTo(group) {
+			banners = append(banners, banner)
+		}
+	}
+
+	return strings.Join(banners, "\n\n"), nil
+}
+
+// handleGetClientSessionBanner handles GET /clients/{client_id}/session-banner
+func (al *APIListener) handleGetClientSessionBanner(w http.ResponseWriter, req *http.Request) {
+	clientID := mux.Vars(req)[routes.ParamClientID]
+	client, err := al.clientService.GetByID(clientID)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if client == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("client with id %q not found", clientID))
+		return
+	}
+
+	banner, err := al.sessionBanner(req.Context(), client)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(sessionBannerPayload{Banner: banner}))
+}
@@ -0,0 +1,90 @@
+package chserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/cgroups"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+)
+
+func TestHandleGetClientSessionBanner(t *testing.T) {
+	c1 := clients.New(t).ID("client-1").Logger(testLog).Build()
+	c2 := clients.New(t).ID("client-2").Logger(testLog).Build()
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+				Server: chconfig.ServerConfig{
+					SessionBanner: "Authorized use only.\n",
+				},
+			},
+			clientService: clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1, c2}, &hour, testLog), testLog, nil),
+			clientGroupProvider: monitoringProfilesGroupProvider{groups: []*cgroups.ClientGroup{
+				{ID: "finance", Params: &cgroups.ClientParams{ClientID: &cgroups.ParamValues{"client-1"}}, Banner: "Contains customer data."},
+				{ID: "all", Params: &cgroups.ClientParams{ClientID: &cgroups.ParamValues{"client-*"}}},
+			}},
+		},
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	testCases := []struct {
+		name           string
+		clientID       string
+		globalBanner   string
+		wantStatusCode int
+		wantJSON       string
+	}{
+		{
+			name:           "global and group banner",
+			clientID:       "client-1",
+			globalBanner:   "Authorized use only.\n",
+			wantStatusCode: http.StatusOK,
+			wantJSON:       `{"data":{"banner":"Authorized use only.\n\nContains customer data."}}`,
+		},
+		{
+			name:           "global banner only",
+			clientID:       "client-2",
+			globalBanner:   "Authorized use only.\n",
+			wantStatusCode: http.StatusOK,
+			wantJSON:       `{"data":{"banner":"Authorized use only."}}`,
+		},
+		{
+			name:           "no banner",
+			clientID:       "client-2",
+			wantStatusCode: http.StatusOK,
+			wantJSON:       `{"data":{"banner":""}}`,
+		},
+		{
+			name:           "unknown client",
+			clientID:       "client-3",
+			wantStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			al.config.Server.SessionBanner = tc.globalBanner
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/clients/"+tc.clientID+"/session-banner", nil)
+			req = req.WithContext(api.WithUser(req.Context(), "admin"))
+			al.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+			if tc.wantJSON != "" {
+				assert.JSONEq(t, tc.wantJSON, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package chserver
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/routes"
+	"github.com/realvnc-labs/rport/server/sessionrecording"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+const asciicastContentType = "application/x-asciicast"
+
+var sessionRecordingsSupportedFilters = map[string]bool{
+	"client_id": true,
+	"tunnel_id": true,
+	"user":      true,
+	"remote":    true,
+}
+
+// handleListSessionRecordings handles GET /session-recordings
+func (al *APIListener) handleListSessionRecordings(w http.ResponseWriter, req *http.Request) {
+	if al.sessionRecordings == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, "Session recording is disabled.")
+		return
+	}
+
+	options := query.GetListOptions(req)
+	err := query.ValidateListOptions(options, nil /* sorts */, sessionRecordingsSupportedFilters, nil /* fields */, &query.PaginationConfig{
+		MaxLimit:     500,
+		DefaultLimit: 50,
+	})
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	recordings, err := al.sessionRecordings.List()
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	filtered := make([]*sessionrecording.Recording, 0, len(recordings))
+	for _, rec := range recordings {
+		matches, err := query.MatchesFilters(rec, options.Filters)
+		if err != nil {
+			al.jsonError(w, err)
+			return
+		}
+		if matches {
+			filtered = append(filtered, rec)
+		}
+	}
+
+	totalCount := len(filtered)
+	start, end := options.Pagination.GetStartEnd(totalCount)
+
+	al.writeJSONResponse(w, http.StatusOK, &api.SuccessPayload{
+		Data: filtered[start:end],
+		Meta: api.NewMeta(totalCount),
+	})
+}
+
+// handleGetSessionRecording handles GET /session-recordings/{recording_id}
+func (al *APIListener) handleGetSessionRecording(w http.ResponseWriter, req *http.Request) {
+	if al.sessionRecordings == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, "Session recording is disabled.")
+		return
+	}
+
+	id := mux.Vars(req)[routes.ParamRecordingID]
+	rec, err := al.sessionRecordings.Get(id)
+	if err != nil {
+		if errors.Is(err, sessionrecording.ErrNotFound) {
+			al.jsonErrorResponseWithTitle(w, http.StatusNotFound, err.Error())
+			return
+		}
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(rec))
+}
+
+// handleGetSessionRecordingPlayback handles GET /session-recordings/{recording_id}/playback
+func (al *APIListener) handleGetSessionRecordingPlayback(w http.ResponseWriter, req *http.Request) {
+	if al.sessionRecordings == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, "Session recording is disabled.")
+		return
+	}
+
+	id := mux.Vars(req)[routes.ParamRecordingID]
+	f, err := al.sessionRecordings.Open(id)
+	if err != nil {
+		if errors.Is(err, sessionrecording.ErrNotFound) {
+			al.jsonErrorResponseWithTitle(w, http.StatusNotFound, err.Error())
+			return
+		}
+		al.jsonError(w, err)
+		return
+	}
+	defer f.Close()
+
+	al.auditLog.Entry(auditlog.ApplicationSessionRecording, auditlog.ActionPlayback).
+		WithHTTPRequest(req).
+		WithID(id).
+		Save()
+
+	w.Header().Set("Content-Type", asciicastContentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, f); err != nil {
+		al.Errorf("Failed to send session recording %q: %v", id, err)
+	}
+}
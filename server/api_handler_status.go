@@ -4,6 +4,8 @@ import (
 	"net/http"
 
 	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/message"
+	"github.com/realvnc-labs/rport/server/clientpayload"
 	chshare "github.com/realvnc-labs/rport/share"
 )
 
@@ -17,12 +19,25 @@ func (al *APIListener) handleGetStatus(w http.ResponseWriter, req *http.Request)
 	}
 
 	var twoFADelivery string
+	var twoFADeliveryMethods []string
 	if al.twoFASrv.MsgSrv != nil {
 		twoFADelivery = al.twoFASrv.MsgSrv.DeliveryMethod()
+		twoFADeliveryMethods = message.DeliveryMethods(al.twoFASrv.MsgSrv)
 	} else if al.config.API.TotPEnabled {
 		twoFADelivery = "totp_authenticator_app"
 	}
 
+	var keepalive *KeepaliveStats
+	if al.clientsStatusCheck != nil {
+		stats := al.clientsStatusCheck.Stats()
+		keepalive = &stats
+	}
+	var clientPayload *clientpayload.Stats
+	if al.clientPayload != nil {
+		stats := al.clientPayload.Stats()
+		clientPayload = &stats
+	}
+
 	response := api.NewSuccessPayload(map[string]interface{}{
 		"version":                   chshare.BuildVersion,
 		"clients_connected":         countActive,
@@ -36,6 +51,7 @@ func (al *APIListener) handleGetStatus(w http.ResponseWriter, req *http.Request)
 		"group_permissions_enabled": al.userService.SupportsGroupPermissions(),
 		"two_fa_enabled":            al.config.API.IsTwoFAOn() || al.config.API.TotPEnabled,
 		"two_fa_delivery_method":    twoFADelivery,
+		"two_fa_delivery_methods":   twoFADeliveryMethods,
 		"auditlog":                  al.auditLog.Status(),
 		"auth_header":               al.config.API.AuthHeader != "",
 		"tunnel_host":               al.config.Server.InternalTunnelProxyConfig.Host,
@@ -44,6 +60,8 @@ func (al *APIListener) handleGetStatus(w http.ResponseWriter, req *http.Request)
 		"excluded_ports":            al.config.Server.ExcludedPortsRaw,
 		"used_ports":                al.config.Server.UsedPortsRaw,
 		"monitoring_enabled":        al.config.Monitoring.Enabled,
+		"keepalive":                 keepalive,
+		"client_payload":            clientPayload,
 	})
 
 	al.writeJSONResponse(w, http.StatusOK, response)
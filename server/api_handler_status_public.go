@@ -0,0 +1,89 @@
+package chserver
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/api"
+	chshare "github.com/realvnc-labs/rport/share"
+)
+
+const (
+	PublicStatusOK       = "ok"
+	PublicStatusDegraded = "degraded"
+)
+
+type PublicStatus struct {
+	Status           string             `json:"status"`
+	Version          string             `json:"version"`
+	StartedAt        time.Time          `json:"started_at"`
+	UptimeSeconds    int64              `json:"uptime_seconds"`
+	ClientsConnected int                `json:"clients_connected"`
+	Components       []*ComponentHealth `json:"components"`
+}
+
+var publicStatusTemplate = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Rport server status: {{.Status}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+td { padding: 0.2em 1em 0.2em 0; }
+.ok { color: #2e7d32; }
+.degraded, .error { color: #c62828; }
+</style>
+</head>
+<body>
+<h1>Rport server status: <span class="{{.Status}}">{{.Status}}</span></h1>
+<table>
+<tr><td>Version</td><td>{{.Version}}</td></tr>
+<tr><td>Uptime</td><td>{{.Uptime}}</td></tr>
+<tr><td>Connected clients</td><td>{{.ClientsConnected}}</td></tr>
+{{range .Components}}<tr><td>{{.Name}}</td><td class="{{.Status}}">{{.Status}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// handleGetPublicStatus handles GET /status/public. It doesn't require authentication, so it only exposes information
+// useful for external uptime monitors. 503 is returned if any component is unhealthy.
+func (al *APIListener) handleGetPublicStatus(w http.ResponseWriter, req *http.Request) {
+	components := al.checkComponents(req.Context(), al.statusChecks())
+
+	status := &PublicStatus{
+		Status:           PublicStatusOK,
+		Version:          chshare.BuildVersion,
+		StartedAt:        al.startedAt,
+		UptimeSeconds:    int64(time.Since(al.startedAt).Seconds()),
+		ClientsConnected: al.clientService.CountActive(),
+		Components:       components,
+	}
+	httpStatus := http.StatusOK
+	if !componentsHealthy(components) {
+		status.Status = PublicStatusDegraded
+		httpStatus = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+
+	if strings.Contains(req.Header.Get("Accept"), "text/html") {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(httpStatus)
+		err := publicStatusTemplate.Execute(w, struct {
+			*PublicStatus
+			Uptime time.Duration
+		}{
+			PublicStatus: status,
+			Uptime:       time.Duration(status.UptimeSeconds) * time.Second,
+		})
+		if err != nil {
+			al.Errorf("Failed to render status page: %v", err)
+		}
+		return
+	}
+
+	al.writeJSONResponse(w, httpStatus, api.NewSuccessPayload(status))
+}
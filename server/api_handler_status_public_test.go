@@ -0,0 +1,116 @@
+package chserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+)
+
+func TestHandleGetPublicStatus(t *testing.T) {
+	testCases := []struct {
+		name           string
+		enabled        bool
+		dataDir        string
+		accept         string
+		wantStatusCode int
+		wantStatus     string
+		wantStorage    string
+	}{
+		{
+			name:           "disabled",
+			dataDir:        t.TempDir(),
+			wantStatusCode: http.StatusNotFound,
+		},
+		{
+			name:           "healthy",
+			enabled:        true,
+			dataDir:        t.TempDir(),
+			wantStatusCode: http.StatusOK,
+			wantStatus:     PublicStatusOK,
+			wantStorage:    ComponentStatusOK,
+		},
+		{
+			name:           "storage not writable",
+			enabled:        true,
+			dataDir:        filepath.Join(t.TempDir(), "missing"),
+			wantStatusCode: http.StatusServiceUnavailable,
+			wantStatus:     PublicStatusDegraded,
+			wantStorage:    ComponentStatusError,
+		},
+		{
+			name:           "html",
+			enabled:        true,
+			dataDir:        t.TempDir(),
+			accept:         "text/html,application/xhtml+xml",
+			wantStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c1 := clients.New(t).Connection(nil).Logger(testLog).Build()
+			al := APIListener{
+				// authentication must not be required
+				insecureForTests: false,
+				Server: &Server{
+					config: &chconfig.Config{
+						Server: chconfig.ServerConfig{DataDir: tc.dataDir},
+						API:    chconfig.APIConfig{PublicStatusEnabled: tc.enabled},
+					},
+					clientService: clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil),
+					startedAt:     time.Now().Add(-time.Hour),
+				},
+				Logger:      testLog,
+				userService: MockUserService("admin", users.Administrators),
+			}
+			al.initRouter()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/status/public", nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			w := httptest.NewRecorder()
+			al.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+			if tc.accept != "" {
+				assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+				assert.Contains(t, w.Body.String(), "Rport server status: <span class=\"ok\">ok</span>")
+				return
+			}
+			if tc.wantStatus == "" {
+				return
+			}
+
+			var resp struct {
+				Data *PublicStatus `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tc.wantStatus, resp.Data.Status)
+			assert.Equal(t, 1, resp.Data.ClientsConnected)
+			assert.InDelta(t, 3600, resp.Data.UptimeSeconds, 5)
+
+			statuses := map[string]string{}
+			for _, c := range resp.Data.Components {
+				statuses[c.Name] = c.Status
+			}
+			assert.Equal(t, map[string]string{
+				ComponentAuth:        ComponentStatusOK,
+				ComponentClientsAuth: ComponentStatusOK,
+				ComponentDatabase:    ComponentStatusOK,
+				ComponentStorage:     tc.wantStorage,
+			}, statuses)
+		})
+	}
+}
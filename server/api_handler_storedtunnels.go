@@ -7,6 +7,7 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/auditlog"
 	"github.com/realvnc-labs/rport/server/clients/storedtunnels"
 	"github.com/realvnc-labs/rport/server/routes"
 	"github.com/realvnc-labs/rport/share/query"
@@ -65,6 +66,13 @@ func (al *APIListener) handlePostStoredTunnels(w http.ResponseWriter, req *http.
 		return
 	}
 
+	al.auditLog.Entry(auditlog.ApplicationClientStoredTunnel, auditlog.ActionCreate).
+		WithHTTPRequest(req).
+		WithClient(client).
+		WithID(result.ID).
+		WithRequest(storedTunnel).
+		Save()
+
 	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(result))
 }
 
@@ -91,6 +99,12 @@ func (al *APIListener) handleDeleteStoredTunnel(w http.ResponseWriter, req *http
 		return
 	}
 
+	al.auditLog.Entry(auditlog.ApplicationClientStoredTunnel, auditlog.ActionDelete).
+		WithHTTPRequest(req).
+		WithClient(client).
+		WithID(tunnelID).
+		Save()
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -124,5 +138,12 @@ func (al *APIListener) handlePutStoredTunnel(w http.ResponseWriter, req *http.Re
 		return
 	}
 
+	al.auditLog.Entry(auditlog.ApplicationClientStoredTunnel, auditlog.ActionUpdate).
+		WithHTTPRequest(req).
+		WithClient(client).
+		WithID(tunnelID).
+		WithRequest(storedTunnel).
+		Save()
+
 	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(result))
 }
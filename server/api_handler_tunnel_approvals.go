@@ -0,0 +1,214 @@
+package chserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/mail"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/routes"
+	"github.com/realvnc-labs/rport/server/tunnelapproval"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+// tunnelApproverGroups returns the user groups required to approve the tunnel, nil if no approval is required.
+func (al *APIListener) tunnelApproverGroups(req *http.Request, client *clientdata.Client, remote *models.Remote) ([]string, error) {
+	if al.tunnelApprovals == nil {
+		return nil, nil
+	}
+
+	clientGroups, err := al.clientGroupIDs(req.Context(), client)
+	if err != nil {
+		return nil, err
+	}
+
+	return al.tunnelApprovals.ApproverGroups(remote, clientGroups), nil
+}
+
+func (al *APIListener) requestTunnelApproval(w http.ResponseWriter, req *http.Request, client *clientdata.Client, remote *models.Remote, approverGroups []string) {
+	pt, err := al.tunnelApprovals.Request(req.Context(), client.GetID(), client.GetName(), remote, remote.Owner, approverGroups)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationClientTunnel, auditlog.ActionRequest).
+		WithHTTPRequest(req).
+		WithClient(client).
+		WithRequest(remote).
+		WithLabels(remote.Labels).
+		WithID(pt.ID).
+		Save()
+
+	al.writeJSONResponse(w, http.StatusAccepted, api.NewSuccessPayload(pt))
+}
+
+// handleGetPendingTunnels handles GET /pending-tunnels
+func (al *APIListener) handleGetPendingTunnels(w http.ResponseWriter, req *http.Request) {
+	if al.tunnelApprovals == nil {
+		al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload([]interface{}{}))
+		return
+	}
+
+	curUser, err := al.getUserModelForAuth(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	clientGroups, err := al.clientGroupProvider.GetAll(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	// users see their own requests and the ones they can approve
+	res := []*tunnelapproval.PendingTunnel{}
+	for _, pt := range al.tunnelApprovals.List() {
+		if pt.RequestedBy == curUser.Username ||
+			pt.IsApprover(curUser.Groups) && al.clientService.CheckClientAccess(pt.ClientID, curUser, clientGroups) == nil {
+			res = append(res, pt)
+		}
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(res))
+}
+
+// handlePostPendingTunnelApproval handles POST /pending-tunnels/{pending_tunnel_id}/approve
+func (al *APIListener) handlePostPendingTunnelApproval(w http.ResponseWriter, req *http.Request) {
+	if al.tunnelApprovals == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, "Tunnel approval is not enabled.")
+		return
+	}
+
+	curUser, err := al.getUserModelForAuth(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	pt, err := al.tunnelApprovals.Get(mux.Vars(req)[routes.ParamPendingTunnel])
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	clientGroups, err := al.clientGroupProvider.GetAll(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	err = al.clientService.CheckClientAccess(pt.ClientID, curUser, clientGroups)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	pt, err = al.tunnelApprovals.Approve(pt.ID, curUser.Username, curUser.Groups)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	client, err := al.clientService.GetActiveByID(pt.ClientID)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if client == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("client with id %s not found", pt.ClientID))
+		return
+	}
+
+	tunnels, err := al.clientService.StartClientTunnels(client, []*models.Remote{pt.Remote})
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationClientTunnel, auditlog.ActionApprove).
+		WithHTTPRequest(req).
+		WithClient(client).
+		WithRequest(pt).
+		WithLabels(pt.Remote.Labels).
+		WithResponse(tunnels[0]).
+		WithID(tunnels[0].ID).
+		Save()
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(tunnels[0]))
+}
+
+// handleDeletePendingTunnel handles DELETE /pending-tunnels/{pending_tunnel_id}
+func (al *APIListener) handleDeletePendingTunnel(w http.ResponseWriter, req *http.Request) {
+	if al.tunnelApprovals == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, "Tunnel approval is not enabled.")
+		return
+	}
+
+	curUser, err := al.getUserModelForAuth(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	pt, err := al.tunnelApprovals.Reject(mux.Vars(req)[routes.ParamPendingTunnel], curUser.Username, curUser.Groups)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationClientTunnel, auditlog.ActionReject).
+		WithHTTPRequest(req).
+		WithClientID(pt.ClientID).
+		WithRequest(pt).
+		WithID(pt.ID).
+		Save()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// tunnelApprovers returns the members of the user groups with access to the client, who have an email address set to
+// receive the two factor auth tokens.
+func (al *APIListener) tunnelApprovers(ctx context.Context, clientID string, userGroups []string) ([]tunnelapproval.Approver, error) {
+	allUsers, err := al.userService.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	clientGroups, err := al.clientGroupProvider.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []tunnelapproval.Approver
+	for _, user := range allUsers {
+		if !isMemberOfAny(user.Groups, userGroups) {
+			continue
+		}
+		addr, err := mail.ParseAddress(user.TwoFASendTo)
+		if err != nil {
+			continue
+		}
+		if al.clientService.CheckClientAccess(clientID, user, clientGroups) != nil {
+			continue
+		}
+		res = append(res, tunnelapproval.Approver{Username: user.Username, Email: addr.Address})
+	}
+	return res, nil
+}
+
+func isMemberOfAny(userGroups, groups []string) bool {
+	for _, userGroup := range userGroups {
+		for _, group := range groups {
+			if userGroup == group {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package chserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/tunnelapproval"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func TestPendingTunnelsAccess(t *testing.T) {
+	c1 := clients.New(t).ID("client-1").AllowedUserGroups([]string{"Users", "Security"}).Logger(testLog).Build()
+	c2 := clients.New(t).ID("client-2").AllowedUserGroups([]string{"Users"}).Logger(testLog).Build()
+	approvals := tunnelapproval.NewService(tunnelapproval.Config{
+		Rules:   []tunnelapproval.Rule{{Ports: []string{"22"}, ApproverGroups: []string{"Security"}}},
+		Timeout: tunnelapproval.DefaultTimeout,
+	}, testLog)
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			clientService:       clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1, c2}, &hour, testLog), testLog, nil),
+			tunnelApprovals:     approvals,
+			clientGroupProvider: mockClientGroupProvider{},
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+		},
+		userService: users.NewAPIService(users.NewStaticProvider([]*users.User{
+			{Username: "alice", Groups: []string{"Users"}},
+			{Username: "bob", Groups: []string{"Security"}, TwoFASendTo: "bob@example.com"},
+			{Username: "carol", Groups: []string{"Users"}},
+			{Username: "dave", Groups: []string{"Security"}, TwoFASendTo: "+491234567"},
+		}), false, 0, -1),
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	ctx := context.Background()
+	pt1, err := approvals.Request(ctx, "client-1", "", &models.Remote{RemotePort: "22"}, "alice", []string{"Security"})
+	require.NoError(t, err)
+	pt2, err := approvals.Request(ctx, "client-2", "", &models.Remote{RemotePort: "22"}, "alice", []string{"Security"})
+	require.NoError(t, err)
+	pt3, err := approvals.Request(ctx, "client-2", "", &models.Remote{RemotePort: "22"}, "carol", []string{"Security"})
+	require.NoError(t, err)
+
+	do := func(method, path, username string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req = req.WithContext(api.WithUser(req.Context(), username))
+		w := httptest.NewRecorder()
+		al.router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("list", func(t *testing.T) {
+		testCases := []struct {
+			username string
+			wantIDs  []string
+		}{
+			{username: "alice", wantIDs: []string{pt1.ID, pt2.ID}},
+			{username: "carol", wantIDs: []string{pt3.ID}},
+			// bob has no access to client-2
+			{username: "bob", wantIDs: []string{pt1.ID}},
+		}
+		for _, tc := range testCases {
+			w := do(http.MethodGet, "/api/v1/pending-tunnels", tc.username)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var res struct {
+				Data []*tunnelapproval.PendingTunnel `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			var gotIDs []string
+			for _, pt := range res.Data {
+				gotIDs = append(gotIDs, pt.ID)
+			}
+			assert.ElementsMatch(t, tc.wantIDs, gotIDs, tc.username)
+		}
+	})
+
+	t.Run("approve without client access", func(t *testing.T) {
+		w := do(http.MethodPost, "/api/v1/pending-tunnels/"+pt3.ID+"/approve", "bob")
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "Access denied to client(s) with ID(s): client-2")
+		_, err := approvals.Get(pt3.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("approvers", func(t *testing.T) {
+		approvers, err := al.tunnelApprovers(ctx, "client-1", []string{"Security"})
+		require.NoError(t, err)
+		assert.Equal(t, []tunnelapproval.Approver{{Username: "bob", Email: "bob@example.com"}}, approvers)
+
+		approvers, err = al.tunnelApprovers(ctx, "client-2", []string{"Security"})
+		require.NoError(t, err)
+		assert.Empty(t, approvers)
+	})
+}
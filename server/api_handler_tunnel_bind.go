@@ -0,0 +1,93 @@
+package chserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	apierrors "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/cgroups"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/tunnelbind"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+const bindQueryParam = "bind"
+
+// setBindAddressForRemote sets the server address the tunnel listens on. It's given per tunnel with the bind param,
+// an IP address or a network interface name, or defaults to the bind address of the client groups of the client.
+// Bind addresses must be allowed by the tunnel_bind_addresses config.
+func (al *APIListener) setBindAddressForRemote(req *http.Request, client *clientdata.Client, localAddr string, remote *models.Remote) error {
+	allowed := al.config.Server.TunnelBindAddresses
+	bind := req.URL.Query().Get(bindQueryParam)
+
+	// local is given as host:port
+	if strings.Contains(localAddr, ":") {
+		if bind != "" {
+			return apierrors.NewAPIError(http.StatusBadRequest, "", fmt.Sprintf("%s param cannot be combined with a host in local.", bindQueryParam), nil)
+		}
+		if len(allowed) == 0 || remote.LocalHost == models.ZeroHost {
+			return nil
+		}
+		_, err := tunnelbind.Resolve(allowed, remote.LocalHost)
+		return bindAddressAPIError(err)
+	}
+
+	if bind == "" {
+		groupBind, err := al.groupTunnelBindAddress(req.Context(), client)
+		if err != nil {
+			return err
+		}
+		if groupBind == "" {
+			return nil
+		}
+		bind = groupBind
+	}
+
+	addr, err := tunnelbind.Resolve(allowed, bind)
+	if err != nil {
+		return bindAddressAPIError(err)
+	}
+	remote.BindAddress = addr
+	if remote.IsLocalSpecified() {
+		remote.LocalHost = addr
+	}
+
+	return nil
+}
+
+// groupTunnelBindAddress returns the bind address of the first client group of the client, ordered by id, which has one.
+func (al *APIListener) groupTunnelBindAddress(ctx context.Context, client *clientdata.Client) (string, error) {
+	groups, err := al.clientGroupProvider.GetAll(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, group := range groups {
+		if group.TunnelBindAddress != "" && client.BelongsTo(group) {
+			return group.TunnelBindAddress, nil
+		}
+	}
+	return "", nil
+}
+
+func (al *APIListener) validateClientGroupTunnelBind(group cgroups.ClientGroup) error {
+	if group.TunnelBindAddress == "" {
+		return nil
+	}
+	if _, err := tunnelbind.Resolve(al.config.Server.TunnelBindAddresses, group.TunnelBindAddress); err != nil {
+		return fmt.Errorf("invalid tunnel_bind_address: %v", err)
+	}
+	return nil
+}
+
+func bindAddressAPIError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, tunnelbind.ErrNotAllowed) {
+		return apierrors.NewAPIError(http.StatusForbidden, "", fmt.Sprintf("%s, see tunnel_bind_addresses config.", err), nil)
+	}
+	return apierrors.NewAPIError(http.StatusBadRequest, "", err.Error(), nil)
+}
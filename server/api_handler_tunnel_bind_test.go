@@ -0,0 +1,151 @@
+package chserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apierrors "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/cgroups"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func TestSetBindAddressForRemote(t *testing.T) {
+	c1 := clients.New(t).ID("client-1").Logger(testLog).Build()
+	c2 := clients.New(t).ID("client-2").Logger(testLog).Build()
+	al := APIListener{
+		Server: &Server{
+			config: &chconfig.Config{
+				Server: chconfig.ServerConfig{
+					TunnelBindAddresses: []string{"10.0.0.5", "192.168.10.1"},
+				},
+			},
+			clientGroupProvider: monitoringProfilesGroupProvider{groups: []*cgroups.ClientGroup{
+				{ID: "internal", Params: &cgroups.ClientParams{ClientID: &cgroups.ParamValues{"client-1"}}, TunnelBindAddress: "192.168.10.1"},
+				{ID: "all", Params: &cgroups.ClientParams{ClientID: &cgroups.ParamValues{"client-*"}}},
+			}},
+		},
+		Logger: testLog,
+	}
+
+	testCases := []struct {
+		name           string
+		clientID       string
+		local          string
+		bind           string
+		wantLocalHost  string
+		wantBind       string
+		wantStatusCode int
+	}{
+		{
+			name:          "bind with local port",
+			clientID:      "client-2",
+			local:         "3000",
+			bind:          "10.0.0.5",
+			wantLocalHost: "10.0.0.5",
+			wantBind:      "10.0.0.5",
+		},
+		{
+			name:     "bind with random port",
+			clientID: "client-2",
+			bind:     "10.0.0.5",
+			wantBind: "10.0.0.5",
+		},
+		{
+			name:          "group default",
+			clientID:      "client-1",
+			local:         "3000",
+			wantLocalHost: "192.168.10.1",
+			wantBind:      "192.168.10.1",
+		},
+		{
+			name:          "bind overrides group default",
+			clientID:      "client-1",
+			local:         "3000",
+			bind:          "10.0.0.5",
+			wantLocalHost: "10.0.0.5",
+			wantBind:      "10.0.0.5",
+		},
+		{
+			name:          "no bind",
+			clientID:      "client-2",
+			local:         "3000",
+			wantLocalHost: "0.0.0.0",
+		},
+		{
+			name:          "allowed host in local",
+			clientID:      "client-1",
+			local:         "10.0.0.5:3000",
+			wantLocalHost: "10.0.0.5",
+		},
+		{
+			name:           "not allowed bind",
+			clientID:       "client-2",
+			bind:           "10.0.0.6",
+			wantStatusCode: http.StatusForbidden,
+		},
+		{
+			name:           "not allowed host in local",
+			clientID:       "client-2",
+			local:          "10.0.0.6:3000",
+			wantStatusCode: http.StatusForbidden,
+		},
+		{
+			name:           "bind and host in local",
+			clientID:       "client-2",
+			local:          "10.0.0.5:3000",
+			bind:           "10.0.0.5",
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := c1
+			if tc.clientID == c2.GetID() {
+				client = c2
+			}
+			remoteStr := "127.0.0.1:22"
+			if tc.local != "" {
+				remoteStr = tc.local + ":" + remoteStr
+			}
+			remote, err := models.NewRemote(remoteStr)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/clients/"+tc.clientID+"/tunnels?bind="+tc.bind, nil)
+			err = al.setBindAddressForRemote(req, client, tc.local, remote)
+
+			if tc.wantStatusCode != 0 {
+				require.Error(t, err)
+				apiErr, ok := err.(apierrors.APIError)
+				require.True(t, ok)
+				assert.Equal(t, tc.wantStatusCode, apiErr.HTTPStatus)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantLocalHost, remote.LocalHost)
+			assert.Equal(t, tc.wantBind, remote.BindAddress)
+		})
+	}
+}
+
+func TestValidateClientGroupTunnelBind(t *testing.T) {
+	al := APIListener{
+		Server: &Server{
+			config: &chconfig.Config{
+				Server: chconfig.ServerConfig{
+					TunnelBindAddresses: []string{"10.0.0.5"},
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, al.validateClientGroupTunnelBind(cgroups.ClientGroup{ID: "g1"}))
+	assert.NoError(t, al.validateClientGroupTunnelBind(cgroups.ClientGroup{ID: "g1", TunnelBindAddress: "10.0.0.5"}))
+	assert.EqualError(t, al.validateClientGroupTunnelBind(cgroups.ClientGroup{ID: "g1", TunnelBindAddress: "10.0.0.6"}), "invalid tunnel_bind_address: bind address is not allowed: 10.0.0.6")
+}
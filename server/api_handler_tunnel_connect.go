@@ -0,0 +1,139 @@
+package chserver
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/clients/clienttunnel"
+	"github.com/realvnc-labs/rport/server/routes"
+	chshare "github.com/realvnc-labs/rport/share"
+)
+
+const proxyAuthorizationHeader = "Proxy-Authorization"
+
+// handleConnectClientTunnel handles CONNECT /clients/{client_id}/tunnels/{tunnel_id}/connect
+// It pipes the connection of the authenticated API user into the tunnel, so each tunnel connection is attributed to
+// a user instead of a source IP only. The tunnel owner, administrators and the users the tunnel is shared with are
+// allowed to connect, the IP based ACL of the tunnel is not applied.
+func (al *APIListener) handleConnectClientTunnel(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	clientID := vars[routes.ParamClientID]
+
+	client, err := al.clientService.GetActiveByID(clientID)
+	if err != nil {
+		al.jsonErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	if client == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("client with id %s not found", clientID))
+		return
+	}
+
+	tunnel := al.clientService.FindTunnel(client, vars["tunnel_id"])
+	if tunnel == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, "tunnel not found")
+		return
+	}
+
+	curUser, err := al.getUserModelForAuth(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if !canConnectTunnel(curUser, tunnel) {
+		al.jsonErrorResponseWithTitle(w, http.StatusForbidden, "Only the tunnel owner, an administrator or users the tunnel is shared with can connect to the tunnel.")
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		al.jsonErrorResponse(w, http.StatusInternalServerError, errors.New("connection doesn't support hijacking"))
+		return
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		al.jsonErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+	if err != nil {
+		al.Debugf("failed to reply to tunnel connect request: %v", err)
+		return
+	}
+
+	ip := chshare.RemoteIP(req)
+	al.auditLog.Entry(auditlog.ApplicationClientTunnel, auditlog.ActionConnect).
+		WithHTTPRequest(req).
+		WithClient(client).
+		WithID(tunnel.ID).
+		WithLabels(tunnel.Labels).
+		Save()
+
+	// data sent by the user right after the request might already be buffered
+	src := &bufferedConn{Conn: conn, r: rw.Reader}
+	err = tunnel.ServeConn(src, fmt.Sprintf("%s@%s", curUser.Username, ip))
+	if err != nil {
+		al.Errorf("Failed to connect user %q to tunnel %s of client %s: %v", curUser.Username, tunnel.ID, client.GetID(), err)
+	}
+}
+
+func canConnectTunnel(user *users.User, tunnel *clienttunnel.Tunnel) bool {
+	if user.IsAdmin() || tunnel.Owner == user.Username {
+		return true
+	}
+	for _, share := range tunnel.Shares() {
+		if share.Includes(user.Username, user.Groups) {
+			return true
+		}
+	}
+	return false
+}
+
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// rewriteTunnelProxyConnect allows to use the API as a regular HTTP proxy. CONNECT requests with the target
+// "<client_id>:<tunnel_id>" are rewritten to the tunnel connect route, the credentials of the Proxy-Authorization
+// header are used if the Authorization header is missing.
+func rewriteTunnelProxyConnect(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect || r.URL.Path != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		clientID, tunnelID, err := net.SplitHostPort(r.URL.Host)
+		if err != nil || clientID == "" || tunnelID == "" {
+			http.Error(w, fmt.Sprintf("invalid tunnel target %q, expected <client_id>:<tunnel_id>", r.URL.Host), http.StatusBadRequest)
+			return
+		}
+
+		r2 := r.Clone(r.Context())
+		r2.URL = &url.URL{
+			Path: fmt.Sprintf("%s/clients/%s/tunnels/%s/connect", routes.AllRoutesPrefix, clientID, tunnelID),
+		}
+		r2.RequestURI = r2.URL.RequestURI()
+		if proxyAuth := r2.Header.Get(proxyAuthorizationHeader); proxyAuth != "" && r2.Header.Get("Authorization") == "" {
+			r2.Header.Set("Authorization", proxyAuth)
+		}
+		r2.Header.Del(proxyAuthorizationHeader)
+
+		next.ServeHTTP(w, r2)
+	})
+}
@@ -0,0 +1,188 @@
+package chserver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/clients/clienttunnel"
+)
+
+type echoTunnelProtocol struct {
+	MockTunnelProtocol
+	sources chan string
+}
+
+func (p *echoTunnelProtocol) ServeConn(src io.ReadWriteCloser, source string) error {
+	p.sources <- source
+	_, err := io.Copy(src, src)
+	return err
+}
+
+func TestHandleConnectClientTunnel(t *testing.T) {
+	tunnelProtocol := &echoTunnelProtocol{sources: make(chan string, 1)}
+	c1 := clients.New(t).ID("client-1").Logger(testLog).Build()
+	c1.Tunnels[0].TunnelProtocol = tunnelProtocol
+	c1.Tunnels[0].Owner = "owner"
+	c1.Tunnels[0].AddShare(&clienttunnel.TunnelShare{ID: "share-1", Groups: []string{"devs"}})
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			clientService: clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil),
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+		},
+		userService: users.NewAPIService(users.NewStaticProvider([]*users.User{
+			{Username: "owner", Groups: []string{"ops"}},
+			{Username: "admin", Groups: []string{users.Administrators}},
+			{Username: "alice", Groups: []string{"devs"}},
+			{Username: "bob", Groups: []string{"support"}},
+		}), false, 0, -1),
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	srv := httptest.NewServer(rewriteTunnelProxyConnect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		al.router.ServeHTTP(w, r.WithContext(api.WithUser(r.Context(), r.Header.Get("X-Test-User"))))
+	})))
+	defer srv.Close()
+
+	connect := func(target, username string) (net.Conn, *bufio.Reader, *http.Response) {
+		conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+		require.NoError(t, err)
+		_, err = fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\nX-Test-User: %s\r\n\r\n", target, srv.Listener.Addr(), username)
+		require.NoError(t, err)
+		r := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(r, &http.Request{Method: http.MethodConnect})
+		require.NoError(t, err)
+		return conn, r, resp
+	}
+
+	testCases := []struct {
+		name       string
+		target     string
+		username   string
+		wantStatus int
+	}{
+		{
+			name:       "owner",
+			target:     "/api/v1/clients/client-1/tunnels/1/connect",
+			username:   "owner",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "admin",
+			target:     "/api/v1/clients/client-1/tunnels/1/connect",
+			username:   "admin",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "shared with group",
+			target:     "client-1:1",
+			username:   "alice",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "not shared",
+			target:     "client-1:1",
+			username:   "bob",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "unknown tunnel",
+			target:     "client-1:9",
+			username:   "owner",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "unknown client",
+			target:     "client-2:1",
+			username:   "owner",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "invalid target",
+			target:     "client-1",
+			username:   "owner",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conn, r, resp := connect(tc.target, tc.username)
+			defer conn.Close()
+
+			require.Equal(t, tc.wantStatus, resp.StatusCode)
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, tc.username+"@127.0.0.1", <-tunnelProtocol.sources)
+			_, err := conn.Write([]byte("ping"))
+			require.NoError(t, err)
+			buf := make([]byte, 4)
+			_, err = io.ReadFull(r, buf)
+			require.NoError(t, err)
+			assert.Equal(t, "ping", string(buf))
+		})
+	}
+}
+
+func TestRewriteTunnelProxyConnect(t *testing.T) {
+	var got *http.Request
+	h := rewriteTunnelProxyConnect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+	}))
+
+	req := httptest.NewRequest(http.MethodConnect, "client-1:2", nil)
+	req.Header.Set("Proxy-Authorization", "Basic YWxpY2U6cHdk")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.NotNil(t, got)
+	assert.Equal(t, "/api/v1/clients/client-1/tunnels/2/connect", got.URL.Path)
+	assert.Equal(t, "Basic YWxpY2U6cHdk", got.Header.Get("Authorization"))
+	assert.Empty(t, got.Header.Get("Proxy-Authorization"))
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/status", strings.NewReader(""))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "/api/v1/status", got.URL.Path)
+}
+
+func TestHandleConnectClientTunnelRequiresClientAccess(t *testing.T) {
+	c1 := clients.New(t).ID("client-1").Logger(testLog).Build()
+	c1.Tunnels[0].TunnelProtocol = &MockTunnelProtocol{}
+	c1.Tunnels[0].AddShare(&clienttunnel.TunnelShare{ID: "share-1", Users: []string{"alice"}})
+	al, adminUser := setupTestAPIListenerUserAPISessions(t, nil)
+	al.clientService = clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil)
+	al.clientGroupProvider = mockClientGroupProvider{}
+	al.userService = users.NewAPIService(users.NewStaticProvider([]*users.User{
+		adminUser,
+		{Username: "alice", Password: "pa55word", Groups: []string{"devs"}},
+	}), false, 0, -1)
+
+	req := httptest.NewRequest(http.MethodConnect, "/api/v1/clients/client-1/tunnels/1/connect", nil)
+	req.SetBasicAuth("alice", "pa55word")
+	w := httptest.NewRecorder()
+
+	al.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "Access denied to client(s) with ID(s): client-1")
+}
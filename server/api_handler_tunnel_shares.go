@@ -0,0 +1,290 @@
+package chserver
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	errors2 "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/clients/clienttunnel"
+	"github.com/realvnc-labs/rport/server/routes"
+	chshare "github.com/realvnc-labs/rport/share"
+	"github.com/realvnc-labs/rport/share/random"
+)
+
+type tunnelShareRequest struct {
+	Users  []string `json:"users"`
+	Groups []string `json:"groups"`
+}
+
+type SharedTunnelPayload struct {
+	TunnelPayload
+	ClientName string `json:"client_name"`
+	ShareID    string `json:"share_id"`
+	SharedBy   string `json:"shared_by"`
+}
+
+// handleGetTunnelShares handles GET /clients/{client_id}/tunnels/{tunnel_id}/shares
+func (al *APIListener) handleGetTunnelShares(w http.ResponseWriter, req *http.Request) {
+	_, tunnel := al.getOwnedTunnel(w, req)
+	if tunnel == nil {
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(tunnel.Shares()))
+}
+
+// handlePostTunnelShare handles POST /clients/{client_id}/tunnels/{tunnel_id}/shares
+func (al *APIListener) handlePostTunnelShare(w http.ResponseWriter, req *http.Request) {
+	client, tunnel := al.getOwnedTunnel(w, req)
+	if tunnel == nil {
+		return
+	}
+
+	var reqBody tunnelShareRequest
+	err := parseRequestBody(req.Body, &reqBody)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	if len(reqBody.Users) == 0 && len(reqBody.Groups) == 0 {
+		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, "At least one user or group is required.")
+		return
+	}
+	err = al.validateTunnelShare(reqBody)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	curUser, err := al.getUserModelForAuth(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	id, err := random.UUID4()
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	share := &clienttunnel.TunnelShare{
+		ID:         id,
+		Users:      reqBody.Users,
+		Groups:     reqBody.Groups,
+		CreatedBy:  curUser.Username,
+		CreatedAt:  time.Now(),
+		AllowedIPs: []string{},
+	}
+	if share.Users == nil {
+		share.Users = []string{}
+	}
+	if share.Groups == nil {
+		share.Groups = []string{}
+	}
+	tunnel.AddShare(share)
+
+	al.auditLog.Entry(auditlog.ApplicationClientTunnelShare, auditlog.ActionCreate).
+		WithHTTPRequest(req).
+		WithClient(client).
+		WithID(tunnel.ID).
+		WithLabels(tunnel.Labels).
+		WithRequest(reqBody).
+		WithResponse(share).
+		Save()
+
+	al.writeJSONResponse(w, http.StatusCreated, api.NewSuccessPayload(share))
+}
+
+// handleDeleteTunnelShare handles DELETE /clients/{client_id}/tunnels/{tunnel_id}/shares/{share_id}
+func (al *APIListener) handleDeleteTunnelShare(w http.ResponseWriter, req *http.Request) {
+	client, tunnel := al.getOwnedTunnel(w, req)
+	if tunnel == nil {
+		return
+	}
+
+	shareID := mux.Vars(req)[routes.ParamTunnelShareID]
+	err := al.clientService.RevokeTunnelShare(client, tunnel, shareID)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationClientTunnelShare, auditlog.ActionDelete).
+		WithHTTPRequest(req).
+		WithClient(client).
+		WithID(tunnel.ID).
+		WithLabels(tunnel.Labels).
+		WithRequest(map[string]string{"share_id": shareID}).
+		Save()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetSharedTunnels handles GET /shared-tunnels
+func (al *APIListener) handleGetSharedTunnels(w http.ResponseWriter, req *http.Request) {
+	curUser, err := al.getUserModelForAuth(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	tunnels := make([]SharedTunnelPayload, 0)
+	al.forEachSharedTunnel(curUser, func(c *clientdata.Client, t *clienttunnel.Tunnel, share *clienttunnel.TunnelShare) bool {
+		tunnels = append(tunnels, SharedTunnelPayload{
+			TunnelPayload: convertToTunnelPayload(t, c.GetID()),
+			ClientName:    c.GetName(),
+			ShareID:       share.ID,
+			SharedBy:      share.CreatedBy,
+		})
+		return true
+	})
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(tunnels))
+}
+
+// handlePostSharedTunnelAccess handles POST /shared-tunnels/{share_id}/access
+// It adds the IP of the requesting user to the ACL of the shared tunnel.
+func (al *APIListener) handlePostSharedTunnelAccess(w http.ResponseWriter, req *http.Request) {
+	curUser, err := al.getUserModelForAuth(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	shareID := mux.Vars(req)[routes.ParamTunnelShareID]
+	var client *clientdata.Client
+	var tunnel *clienttunnel.Tunnel
+	al.forEachSharedTunnel(curUser, func(c *clientdata.Client, t *clienttunnel.Tunnel, share *clienttunnel.TunnelShare) bool {
+		if share.ID != shareID {
+			return true
+		}
+		client, tunnel = c, t
+		return false
+	})
+	if tunnel == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("Shared tunnel with share id %q not found.", shareID))
+		return
+	}
+
+	// the IP is added to the ACL, so it must not be taken from headers set by the user
+	ip := chshare.ConnIP(req)
+	err = al.clientService.AllowTunnelShareIP(client, tunnel, shareID, ip)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationClientTunnelShare, auditlog.ActionUpdate).
+		WithHTTPRequest(req).
+		WithClient(client).
+		WithID(tunnel.ID).
+		WithLabels(tunnel.Labels).
+		WithRequest(map[string]string{"share_id": shareID, "ip": ip}).
+		Save()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateTunnelShare checks that the users of the share exist and the groups have at least one member.
+func (al *APIListener) validateTunnelShare(reqBody tunnelShareRequest) error {
+	allUsers, err := al.userService.GetAll()
+	if err != nil {
+		return err
+	}
+
+	usernames := make(map[string]bool)
+	userGroups := make(map[string]bool)
+	for _, user := range allUsers {
+		usernames[user.Username] = true
+		for _, group := range user.Groups {
+			userGroups[group] = true
+		}
+	}
+
+	var unknownUsers, unknownGroups []string
+	for _, username := range reqBody.Users {
+		if !usernames[username] {
+			unknownUsers = append(unknownUsers, username)
+		}
+	}
+	for _, group := range reqBody.Groups {
+		if !userGroups[group] {
+			unknownGroups = append(unknownGroups, group)
+		}
+	}
+
+	if len(unknownUsers) > 0 {
+		return errors2.APIError{
+			Message:    fmt.Sprintf("Users not found: %s.", strings.Join(unknownUsers, ", ")),
+			HTTPStatus: http.StatusBadRequest,
+		}
+	}
+	if len(unknownGroups) > 0 {
+		return errors2.APIError{
+			Message:    fmt.Sprintf("User groups without members: %s.", strings.Join(unknownGroups, ", ")),
+			HTTPStatus: http.StatusBadRequest,
+		}
+	}
+	return nil
+}
+
+// getOwnedTunnel returns the tunnel of the request if the current user is the tunnel owner or an admin,
+// otherwise it writes the error response and returns nil.
+func (al *APIListener) getOwnedTunnel(w http.ResponseWriter, req *http.Request) (*clientdata.Client, *clienttunnel.Tunnel) {
+	vars := mux.Vars(req)
+	clientID := vars[routes.ParamClientID]
+
+	client, err := al.clientService.GetActiveByID(clientID)
+	if err != nil {
+		al.jsonErrorResponse(w, http.StatusInternalServerError, err)
+		return nil, nil
+	}
+	if client == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, fmt.Sprintf("client with id %s not found", clientID))
+		return nil, nil
+	}
+
+	tunnel := al.clientService.FindTunnel(client, vars["tunnel_id"])
+	if tunnel == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, "tunnel not found")
+		return nil, nil
+	}
+
+	curUser, err := al.getUserModelForAuth(req.Context())
+	if err != nil {
+		al.jsonError(w, err)
+		return nil, nil
+	}
+	if !curUser.IsAdmin() && tunnel.Owner != curUser.Username {
+		al.jsonErrorResponseWithTitle(w, http.StatusForbidden, "Only the tunnel owner or an administrator can share the tunnel.")
+		return nil, nil
+	}
+
+	return client, tunnel
+}
+
+// forEachSharedTunnel calls fn for all tunnels of active clients shared with the given user until fn returns false.
+func (al *APIListener) forEachSharedTunnel(user *users.User, fn func(*clientdata.Client, *clienttunnel.Tunnel, *clienttunnel.TunnelShare) bool) {
+	for _, c := range al.clientService.GetAll() {
+		if !c.IsConnected() {
+			continue
+		}
+		for _, t := range c.GetTunnels() {
+			for _, share := range t.Shares() {
+				if !share.Includes(user.Username, user.Groups) {
+					continue
+				}
+				if !fn(c, t, share) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package chserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/clients/clienttunnel"
+)
+
+func TestHandleTunnelShares(t *testing.T) {
+	mockTunnelProtocol := &MockTunnelProtocol{}
+	c1 := clients.New(t).ID("client-1").Logger(testLog).Build()
+	tunnelACL := "127.0.0.0/24"
+	c1.Tunnels[0].TunnelProtocol = mockTunnelProtocol
+	c1.Tunnels[0].Owner = "owner"
+	c1.Tunnels[0].ACL = &tunnelACL
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			clientService: clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil),
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+		},
+		userService: users.NewAPIService(users.NewStaticProvider([]*users.User{
+			{Username: "owner", Groups: []string{"ops"}},
+			{Username: "alice", Groups: []string{"devs"}},
+			{Username: "bob", Groups: []string{"support"}},
+		}), false, 0, -1),
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	doWithHeader := func(method, url, username, header, value string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, nil)
+		req.RemoteAddr = "192.0.2.10:1234"
+		req.Header.Set(header, value)
+		req = req.WithContext(api.WithUser(req.Context(), username))
+		al.router.ServeHTTP(w, req)
+		return w
+	}
+	do := func(method, url, username, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.RemoteAddr = "192.0.2.10:1234"
+		req = req.WithContext(api.WithUser(req.Context(), username))
+		al.router.ServeHTTP(w, req)
+		return w
+	}
+	sharedTunnels := func(username string) []SharedTunnelPayload {
+		w := do(http.MethodGet, "/api/v1/shared-tunnels", username, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data []SharedTunnelPayload `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Data
+	}
+
+	w := do(http.MethodPost, "/api/v1/clients/client-1/tunnels/1/shares", "alice", `{"users": ["alice"]}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = do(http.MethodPost, "/api/v1/clients/client-1/tunnels/1/shares", "owner", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = do(http.MethodPost, "/api/v1/clients/client-1/tunnels/1/shares", "owner", `{"users": ["alice", "mallory"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Users not found: mallory.")
+
+	w = do(http.MethodPost, "/api/v1/clients/client-1/tunnels/1/shares", "owner", `{"groups": ["unknown"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "User groups without members: unknown.")
+
+	w = do(http.MethodPost, "/api/v1/clients/client-1/tunnels/1/shares", "owner", `{"users": ["alice"]}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		Data clienttunnel.TunnelShare `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	shareID := created.Data.ID
+	assert.Equal(t, "owner", created.Data.CreatedBy)
+
+	w = do(http.MethodGet, "/api/v1/clients/client-1/tunnels/1/shares", "owner", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), shareID)
+
+	shared := sharedTunnels("alice")
+	require.Len(t, shared, 1)
+	assert.Equal(t, "1", shared[0].ID)
+	assert.Equal(t, "client-1", shared[0].ClientID)
+	assert.Equal(t, shareID, shared[0].ShareID)
+	assert.Empty(t, sharedTunnels("bob"))
+
+	w = do(http.MethodPost, "/api/v1/shared-tunnels/"+shareID+"/access", "bob", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// the forwarded IP set by the user is ignored
+	w = doWithHeader(http.MethodPost, "/api/v1/shared-tunnels/"+shareID+"/access", "alice", "X-Forwarded-For", "8.8.8.8")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	wantACL, err := clienttunnel.ParseTunnelACL("127.0.0.0/24,192.0.2.10")
+	require.NoError(t, err)
+	assert.Equal(t, wantACL, mockTunnelProtocol.ACL)
+
+	w = do(http.MethodDelete, "/api/v1/clients/client-1/tunnels/1/shares/unknown", "owner", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = do(http.MethodDelete, "/api/v1/clients/client-1/tunnels/1/shares/"+shareID, "owner", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	wantACL, err = clienttunnel.ParseTunnelACL("127.0.0.0/24")
+	require.NoError(t, err)
+	assert.Equal(t, wantACL, mockTunnelProtocol.ACL)
+	assert.Empty(t, sharedTunnels("alice"))
+}
@@ -0,0 +1,127 @@
+package chserver
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/updatesrefresh"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+type updatesStatusRefreshRequest struct {
+	ClientIDs  []string              `json:"client_ids"`
+	GroupIDs   []string              `json:"group_ids"`
+	ClientTags *models.JobClientTags `json:"tags"`
+}
+
+func (r *updatesStatusRefreshRequest) GetClientIDs() []string {
+	return r.ClientIDs
+}
+
+func (r *updatesStatusRefreshRequest) GetGroupIDs() []string {
+	return r.GroupIDs
+}
+
+func (r *updatesStatusRefreshRequest) GetClientTags() *models.JobClientTags {
+	return r.ClientTags
+}
+
+// handlePostUpdatesStatusRefresh handles POST /client-updates-status/refresh
+// It requests an updates status refresh from multiple clients, the results are stored in the jobs of a multi-client job.
+func (al *APIListener) handlePostUpdatesStatusRefresh(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	var reqBody updatesStatusRefreshRequest
+	err := parseRequestBody(req.Body, &reqBody)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	orderedClients, _, err := al.getOrderedClientsWithValidation(ctx, &reqBody)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	curUser, err := al.getUserModelForAuth(ctx)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	clientGroups, err := al.clientGroupProvider.GetAll(ctx)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	err = al.clientService.CheckClientsAccess(orderedClients, curUser, clientGroups)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	jid, err := generateNewJobID()
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	multiJob := &models.MultiJob{
+		MultiJobSummary: models.MultiJobSummary{
+			JID:       jid,
+			StartedAt: time.Now(),
+			CreatedBy: curUser.Username,
+		},
+		ClientIDs:   reqBody.ClientIDs,
+		GroupIDs:    reqBody.GroupIDs,
+		ClientTags:  reqBody.ClientTags,
+		Command:     updatesrefresh.Command,
+		TimeoutSec:  int(al.config.Server.UpdatesStatusRefreshTimeout.Seconds()),
+		Concurrent:  true,
+		ClientCount: len(orderedClients),
+
+		CorrelationID: api.GetRequestID(ctx),
+	}
+	if err := al.jobProvider.SaveMultiJob(multiJob); err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	go al.refreshUpdatesStatus(multiJob, orderedClients)
+
+	resp := newJobResponse{
+		JID: multiJob.JID,
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationClientUpdatesStatus, auditlog.ActionRequest).
+		WithHTTPRequest(req).
+		WithRequest(reqBody).
+		WithResponse(resp).
+		WithID(multiJob.JID).
+		SaveForMultipleClients(orderedClients)
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(resp))
+
+	al.Debugf("Multi-client Job[id=%q] created to refresh updates status on clients %s, groups %s, tags %s.", multiJob.JID, reqBody.ClientIDs, reqBody.GroupIDs, reqBody.ClientTags)
+}
+
+func (al *APIListener) refreshUpdatesStatus(multiJob *models.MultiJob, orderedClients []*clientdata.Client) {
+	wg := sync.WaitGroup{}
+	for _, client := range orderedClients {
+		wg.Add(1)
+		go func(client *clientdata.Client) {
+			defer wg.Done()
+			_, err := al.updatesRefresher.Request(client, &multiJob.JID, multiJob.CreatedBy, multiJob.CorrelationID)
+			if err != nil {
+				al.Errorf("multiJobID=%q, clientID=%q, Failed to persist updates status refresh job: %v", multiJob.JID, client.GetID(), err)
+			}
+		}(client)
+	}
+	wg.Wait()
+
+	if al.testDone != nil {
+		al.testDone <- true
+	}
+}
@@ -0,0 +1,132 @@
+package chserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	jobsmigration "github.com/realvnc-labs/rport/db/migration/jobs"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/jobs"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/updatesrefresh"
+	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/test"
+)
+
+func TestHandlePostUpdatesStatusRefresh(t *testing.T) {
+	testUser := "test-user"
+	curUser := &users.User{
+		Username: testUser,
+		Groups:   []string{users.Administrators},
+	}
+
+	connMock1 := test.NewConnMock()
+	connMock1.ReturnOk = true
+	connMock2 := test.NewConnMock()
+	connMock2.ReturnErr = errors.New("connection lost")
+
+	c1 := clients.New(t).ID("client-1").Connection(connMock1).Logger(testLog).Build()
+	c2 := clients.New(t).ID("client-2").Connection(connMock2).Logger(testLog).Build()
+
+	testCases := []struct {
+		name           string
+		requestBody    string
+		wantStatusCode int
+		wantJobStatus  map[string]string
+	}{
+		{
+			name:           "refresh requested",
+			requestBody:    `{"client_ids": ["client-1", "client-2"]}`,
+			wantStatusCode: http.StatusOK,
+			wantJobStatus: map[string]string{
+				"client-1": models.JobStatusRunning,
+				"client-2": models.JobStatusFailed,
+			},
+		},
+		{
+			name:           "no targets",
+			requestBody:    `{}`,
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown client",
+			requestBody:    `{"client_ids": ["client-1", "client-3"]}`,
+			wantStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			jobsDB, err := sqlite.New(
+				":memory:",
+				jobsmigration.AssetNames(),
+				jobsmigration.Asset,
+				DataSourceOptions,
+			)
+			require.NoError(t, err)
+			jp := jobs.NewSqliteProvider(jobsDB, testLog)
+			defer jp.Close()
+
+			al := APIListener{
+				insecureForTests: true,
+				Server: &Server{
+					clientService: clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1, c2}, &hour, testLog), testLog, nil),
+					config: &chconfig.Config{
+						API: chconfig.APIConfig{
+							MaxRequestBytes: 1024 * 1024,
+						},
+					},
+					jobProvider:         jp,
+					updatesRefresher:    updatesrefresh.New(jp, time.Minute, time.Hour, testLog),
+					clientGroupProvider: mockClientGroupProvider{},
+				},
+				userService: users.NewAPIService(users.NewStaticProvider([]*users.User{curUser}), false, 0, -1),
+				Logger:      testLog,
+			}
+			done := make(chan bool)
+			al.testDone = done
+			al.initRouter()
+
+			ctx := api.WithUser(context.Background(), testUser)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/client-updates-status/refresh", strings.NewReader(tc.requestBody))
+			req = req.WithContext(ctx)
+
+			w := httptest.NewRecorder()
+			al.router.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantStatusCode, w.Code, w.Body.String())
+			if tc.wantStatusCode != http.StatusOK {
+				return
+			}
+
+			<-al.testDone
+			var gotResp struct {
+				Data newJobResponse `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &gotResp))
+			require.NotEmpty(t, gotResp.Data.JID)
+
+			gotMultiJob, err := jp.GetMultiJob(ctx, gotResp.Data.JID)
+			require.NoError(t, err)
+			require.NotNil(t, gotMultiJob)
+			assert.Equal(t, updatesrefresh.Command, gotMultiJob.Command)
+			require.Len(t, gotMultiJob.Jobs, len(tc.wantJobStatus))
+			for _, job := range gotMultiJob.Jobs {
+				assert.Equal(t, tc.wantJobStatus[job.ClientID], job.Status, job.ClientID)
+			}
+		})
+	}
+}
@@ -96,6 +96,7 @@ func (al *APIListener) handleChangeUser(w http.ResponseWriter, req *http.Request
 			al.jsonErrorResponseWithDetail(w, http.StatusInternalServerError, "Unable to delete all User's sessions", titleMsg, err.Error())
 			return
 		}
+		al.closeEphemeralTunnels(ctx)
 	}
 
 	if userIDExists {
@@ -238,6 +239,7 @@ func (al *APIListener) handleDeleteUserAPISession(w http.ResponseWriter, req *ht
 		al.jsonErrorResponseWithDetail(w, http.StatusInternalServerError, "", titleMsg, err.Error())
 		return
 	}
+	al.closeEphemeralTunnels(ctx)
 
 	al.auditLog.Entry(auditlog.ApplicationAuthAPISession, auditlog.ActionDelete).
 		WithHTTPRequest(req).
@@ -263,6 +265,7 @@ func (al *APIListener) handleDeleteAllUserAPISessions(w http.ResponseWriter, req
 		al.jsonErrorResponseWithDetail(w, http.StatusInternalServerError, "", titleMsg, err.Error())
 		return
 	}
+	al.closeEphemeralTunnels(ctx)
 
 	al.auditLog.Entry(auditlog.ApplicationAuthAPISessions, auditlog.ActionDelete).
 		WithHTTPRequest(req).
@@ -19,6 +19,7 @@ import (
 	"github.com/realvnc-labs/rport/server/api/users"
 	"github.com/realvnc-labs/rport/server/bearer"
 	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
 	"github.com/realvnc-labs/rport/share/logger"
 	"github.com/realvnc-labs/rport/share/security"
 )
@@ -372,7 +373,8 @@ func setupTestAPIListenerUserAPISessions(t *testing.T, sessionCache *session.Cac
 	al = &APIListener{
 		Logger: testlog,
 		Server: &Server{
-			config: serverCfg,
+			config:        serverCfg,
+			clientService: clients.NewClientService(nil, nil, clients.NewClientRepository(nil, &hour, testlog), testlog, nil),
 		},
 		bannedUsers: security.NewBanList(0),
 		apiSessions: sessionCache,
@@ -5,13 +5,14 @@ import (
 
 	errors2 "github.com/realvnc-labs/rport/server/api/errors"
 	"github.com/realvnc-labs/rport/server/bearer"
+	"github.com/realvnc-labs/rport/server/securityevents"
 )
 
 func (al *APIListener) handlePostVerify2FAToken() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		username, err := al.parseAndValidate2FATokenRequest(req)
 		if err != nil {
-			if !al.handleBannedIPs(req, false) {
+			if !al.handleBannedIPsWithEventKind(req, false, securityevents.Kind2FAFailure) {
 				return
 			}
 			al.Errorf(err.Error())
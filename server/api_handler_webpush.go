@@ -0,0 +1,113 @@
+package chserver
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/routes"
+	"github.com/realvnc-labs/rport/server/webpush"
+)
+
+type webPushPublicKeyResponse struct {
+	PublicKey string `json:"public_key"`
+}
+
+func (al *APIListener) checkWebPushEnabled(w http.ResponseWriter) bool {
+	if al.webPush == nil {
+		al.jsonErrorResponseWithTitle(w, http.StatusNotFound, "Web Push is not enabled.")
+		return false
+	}
+	return true
+}
+
+// handleGetWebPushPublicKey handles GET /me/push-subscriptions/public-key
+// It returns the application server key the browsers subscribe with.
+func (al *APIListener) handleGetWebPushPublicKey(w http.ResponseWriter, req *http.Request) {
+	if !al.checkWebPushEnabled(w) {
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(webPushPublicKeyResponse{PublicKey: al.webPush.PublicKey()}))
+}
+
+// handleGetPushSubscriptions handles GET /me/push-subscriptions
+func (al *APIListener) handleGetPushSubscriptions(w http.ResponseWriter, req *http.Request) {
+	if !al.checkWebPushEnabled(w) {
+		return
+	}
+
+	subs, err := al.webPush.List(req.Context(), api.GetUser(req.Context(), al.Logger))
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.writeJSONResponse(w, http.StatusOK, api.NewSuccessPayload(subs))
+}
+
+// handlePostPushSubscription handles POST /me/push-subscriptions
+// It subscribes the browser of the current user to the notifications sent to the user.
+func (al *APIListener) handlePostPushSubscription(w http.ResponseWriter, req *http.Request) {
+	if !al.checkWebPushEnabled(w) {
+		return
+	}
+
+	var reqBody webpush.SubscribeRequest
+	err := parseRequestBody(req.Body, &reqBody)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	sub, err := al.webPush.Subscribe(req.Context(), api.GetUser(req.Context(), al.Logger), req.UserAgent(), &reqBody)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationPushSubscription, auditlog.ActionCreate).
+		WithHTTPRequest(req).
+		WithID(sub.ID).
+		Save()
+
+	al.writeJSONResponse(w, http.StatusCreated, api.NewSuccessPayload(sub))
+}
+
+// handleDeletePushSubscription handles DELETE /me/push-subscriptions/{subscription_id}
+func (al *APIListener) handleDeletePushSubscription(w http.ResponseWriter, req *http.Request) {
+	if !al.checkWebPushEnabled(w) {
+		return
+	}
+
+	id := mux.Vars(req)[routes.ParamPushSubscriptionID]
+	err := al.webPush.Delete(req.Context(), id, api.GetUser(req.Context(), al.Logger))
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+
+	al.auditLog.Entry(auditlog.ApplicationPushSubscription, auditlog.ActionDelete).
+		WithHTTPRequest(req).
+		WithID(id).
+		Save()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HasClientAccess limits the client disconnect push notifications to users with access to the client.
+func (al *APIListener) HasClientAccess(ctx context.Context, username, clientID string) bool {
+	user, err := al.userService.GetByUsername(username)
+	if err != nil || user == nil {
+		return false
+	}
+	groups, err := al.clientGroupProvider.GetAll(ctx)
+	if err != nil {
+		al.Errorf("Failed to get client groups: %v", err)
+		return false
+	}
+	return al.clientService.CheckClientAccess(clientID, user, groups) == nil
+}
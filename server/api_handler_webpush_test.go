@@ -0,0 +1,84 @@
+package chserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	webpushmigration "github.com/realvnc-labs/rport/db/migration/webpush"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/webpush"
+)
+
+func TestHandlePushSubscriptions(t *testing.T) {
+	al := APIListener{
+		insecureForTests: true,
+		Server: &Server{
+			config: &chconfig.Config{
+				API: chconfig.APIConfig{
+					MaxRequestBytes: 1024 * 1024,
+				},
+			},
+		},
+		Logger: testLog,
+	}
+	al.initRouter()
+
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req = req.WithContext(api.WithUser(req.Context(), "alice"))
+		al.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodGet, "/api/v1/me/push-subscriptions/public-key", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	db, err := sqlite.New(":memory:", webpushmigration.AssetNames(), webpushmigration.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	al.webPush, err = webpush.NewService(context.Background(), db, "mailto:admin@example.com", testLog)
+	require.NoError(t, err)
+	defer al.webPush.Close()
+
+	w = do(http.MethodGet, "/api/v1/me/push-subscriptions/public-key", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":{"public_key":"`+al.webPush.PublicKey()+`"}}`, w.Body.String())
+
+	w = do(http.MethodPost, "/api/v1/me/push-subscriptions", `{"endpoint":"https://push.example.com/1","keys":{"p256dh":"abc","auth":"abc"}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = do(http.MethodPost, "/api/v1/me/push-subscriptions", `{
+		"endpoint":"https://push.example.com/1",
+		"keys":{
+			"p256dh":"BNcRdreALRFXTkOOUHK1EtK2wtaz5Ry4YfYCA_0QTpQtUbVlUls0VJXg7A8u-Ts1XbjhazAkj7I99e8QcYP7DkM",
+			"auth":"tBHItJI5svbpez7KI4CCXg"
+		},
+		"client_disconnects":true
+	}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var resp struct {
+		Data webpush.Subscription `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "alice", resp.Data.Username)
+	assert.True(t, resp.Data.ClientDisconnects)
+	assert.NotContains(t, w.Body.String(), "tBHItJI5svbpez7KI4CCXg")
+
+	w = do(http.MethodGet, "/api/v1/me/push-subscriptions", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"`+resp.Data.ID+`"`)
+
+	w = do(http.MethodDelete, "/api/v1/me/push-subscriptions/"+resp.Data.ID, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = do(http.MethodDelete, "/api/v1/me/push-subscriptions/"+resp.Data.ID, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -3,17 +3,26 @@ package chserver
 import (
 	"net/http"
 
+	"github.com/realvnc-labs/rport/server/securityevents"
 	chshare "github.com/realvnc-labs/rport/share"
 )
 
 func (al *APIListener) handleBannedIPs(r *http.Request, authorized bool) (ok bool) {
-	if al.bannedIPs != nil {
-		ip := chshare.RemoteIP(r)
+	return al.handleBannedIPsWithEventKind(r, authorized, securityevents.KindAuthFailure)
+}
 
+// handleBannedIPsWithEventKind registers the attempt and records a security event of the given kind if not authorized.
+func (al *APIListener) handleBannedIPsWithEventKind(r *http.Request, authorized bool, kind securityevents.Kind) (ok bool) {
+	ip := chshare.RemoteIP(r)
+	if !authorized {
+		al.securityEvents.Add(kind, ip, r.URL.Path)
+	}
+
+	if al.bannedIPs != nil {
 		if authorized {
 			al.bannedIPs.AddSuccessAttempt(ip)
-		} else {
-			al.bannedIPs.AddBadAttempt(ip)
+		} else if al.bannedIPs.AddBadAttempt(ip) {
+			al.securityEvents.Add(securityevents.KindLockout, ip, r.URL.Path)
 		}
 	}
 
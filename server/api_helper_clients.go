@@ -139,6 +139,7 @@ func (al *APIListener) makeClientsList(clientIDs []string) (orderedClients []*cl
 				Message:    fmt.Sprintf("Client with id=%q not found.", cid),
 				Err:        err,
 				HTTPStatus: http.StatusNotFound,
+				ErrCode:    errors2.ErrCodeClientNotFound,
 			}
 			return orderedClients, usedClientIDs, err
 		}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/gorilla/websocket"
 
+	"github.com/realvnc-labs/rport/server/api"
 	"github.com/realvnc-labs/rport/server/api/jobs"
 	"github.com/realvnc-labs/rport/server/auditlog"
 	"github.com/realvnc-labs/rport/server/validation"
@@ -28,6 +29,14 @@ func (al *APIListener) handleCommandsExecutionWS(
 		uiConnTS.WriteError("Invalid interpreter", err)
 		return
 	}
+	if err := validation.ValidateLabels(inboundMsg.Labels); err != nil {
+		uiConnTS.WriteError("Invalid labels", err)
+		return
+	}
+	if err := checkClientsInterpreter(inboundMsg.Interpreter, inboundMsg.OrderedClients); err != nil {
+		uiConnTS.WriteError("Invalid interpreter", err)
+		return
+	}
 
 	if inboundMsg.TimeoutSec <= 0 {
 		inboundMsg.TimeoutSec = al.config.Server.RunRemoteCmdTimeoutSec
@@ -66,6 +75,7 @@ func (al *APIListener) handleCommandsExecutionWS(
 
 	auditLogEntry.
 		WithRequest(inboundMsg).
+		WithLabels(inboundMsg.Labels).
 		WithID(jid).
 		SaveForMultipleClients(inboundMsg.OrderedClients)
 
@@ -94,6 +104,10 @@ func (al *APIListener) handleCommandsExecutionWS(
 			AbortOnErr:  abortOnErr,
 			IsSudo:      inboundMsg.IsSudo,
 			IsScript:    inboundMsg.IsScript,
+			Labels:      inboundMsg.Labels,
+			ClientCount: len(inboundMsg.OrderedClients),
+
+			CorrelationID: api.GetRequestID(ctx),
 		}
 		if err := al.jobProvider.SaveMultiJob(multiJob); err != nil {
 			uiConnTS.WriteError("Failed to persist a new multi-client job.", err)
@@ -131,9 +145,11 @@ func (al *APIListener) handleCommandsExecutionWS(
 					multiJob.Interpreter,
 					createdBy,
 					multiJob.Cwd,
+					multiJob.CorrelationID,
 					multiJob.TimeoutSec,
 					multiJob.IsSudo,
 					multiJob.IsScript,
+					multiJob.Labels,
 					client,
 				)
 			} else {
@@ -145,9 +161,11 @@ func (al *APIListener) handleCommandsExecutionWS(
 					multiJob.Interpreter,
 					createdBy,
 					multiJob.Cwd,
+					multiJob.CorrelationID,
 					multiJob.TimeoutSec,
 					multiJob.IsSudo,
 					multiJob.IsScript,
+					multiJob.Labels,
 					client,
 				)
 
@@ -183,9 +201,11 @@ func (al *APIListener) handleCommandsExecutionWS(
 			inboundMsg.Interpreter,
 			createdBy,
 			inboundMsg.Cwd,
+			api.GetRequestID(ctx),
 			inboundMsg.TimeoutSec,
 			inboundMsg.IsSudo,
 			inboundMsg.IsScript,
+			inboundMsg.Labels,
 			client,
 		)
 	}
@@ -5,10 +5,16 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/realvnc-labs/rport/server/api"
+	errors2 "github.com/realvnc-labs/rport/server/api/errors"
 	"github.com/realvnc-labs/rport/server/api/jobs"
 	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/clients/clientvars"
 	"github.com/realvnc-labs/rport/share/comm"
 	"github.com/realvnc-labs/rport/share/models"
 	"github.com/realvnc-labs/rport/share/query"
@@ -17,6 +23,7 @@ import (
 )
 
 var ErrClientNotConnected = errors.New("client is not connected")
+var ErrClientCheckInOnly = errors.New("client is in check-in only mode, switch it to full mode first")
 
 var generateNewJobID = func() (string, error) {
 	return random.UUID4()
@@ -35,23 +42,42 @@ type JobProvider interface {
 	CountMultiJobs(ctx context.Context, options *query.ListOptions) (int, error)
 	SaveMultiJob(multiJob *models.MultiJob) error
 	CleanupJobsMultiJobs(context.Context, int) error
+	ListJobStats(ctx context.Context, options *query.ListOptions) ([]*jobs.JobStats, error)
+	CountJobStats(ctx context.Context, options *query.ListOptions) (int, error)
+	GetJobStats(ctx context.Context, clientID string, filters []query.FilterOption) (*jobs.JobStats, error)
+	CountMultiJobStatuses(ctx context.Context, multiJobID string) (map[string]int, error)
+	ListMultiJobResults(ctx context.Context, multiJobID string) ([]*models.Job, error)
+	GetResultSummary(ctx context.Context, multiJobID string) (*jobs.ResultSummary, error)
+	SaveResultSummary(ctx context.Context, s *jobs.ResultSummary) error
+	SaveDispatches(dispatches []*jobs.Dispatch) error
+	SetDispatchState(multiJobID, clientID, state string) error
+	DeleteDispatch(multiJobID, clientID string) error
+	DeletePendingDispatches(multiJobID string) error
+	ListDispatches(ctx context.Context) ([]*jobs.Dispatch, error)
+	ListRunningJobs(ctx context.Context) ([]*models.Job, error)
 	Close() error
 }
 
 func (al *APIListener) createAndRunJob(
 	uiConnTS *ws.ConcurrentWebSocket,
 	multiJobID *string,
-	jid, cmd, interpreter, createdBy, cwd string,
+	jid, cmd, interpreter, createdBy, cwd, correlationID string,
 	timeoutSec int,
 	isSudo, isScript bool,
+	labels []string,
 	client *clientdata.Client,
 ) error {
+	// the client variables are resolved per client, the failed job keeps the unresolved command
+	command, resolveErr := clientvars.Resolve(cmd, client)
+	if resolveErr != nil {
+		command = cmd
+	}
 	curJob := models.Job{
 		JID:          jid,
 		StartedAt:    time.Now(),
 		ClientID:     client.GetID(),
 		ClientName:   client.GetName(),
-		Command:      cmd,
+		Command:      command,
 		Cwd:          cwd,
 		IsSudo:       isSudo,
 		IsScript:     isScript,
@@ -60,14 +86,30 @@ func (al *APIListener) createAndRunJob(
 		TimeoutSec:   timeoutSec,
 		MultiJobID:   multiJobID,
 		StreamResult: uiConnTS != nil,
+		Labels:       labels,
+
+		CorrelationID: correlationID,
 	}
 	logPrefix := curJob.LogPrefix()
 
+	if multiJobID != nil {
+		// once dispatching it's unknown whether the client received the job if the server stops
+		if dbErr := al.jobProvider.SetDispatchState(*multiJobID, curJob.ClientID, jobs.DispatchStateDispatching); dbErr != nil {
+			al.Errorf("%s, Failed to persist dispatch state: %v", logPrefix, dbErr)
+		}
+	}
+
 	// send the command to the client
 	sshResp := &comm.RunCmdResponse{}
 
 	var err error
-	if !client.IsPaused() {
+	if resolveErr != nil {
+		err = resolveErr
+	} else if client.IsCheckInOnly() {
+		err = ErrClientCheckInOnly
+	} else if quarantineErr := client.CheckQuarantine(); quarantineErr != nil {
+		err = quarantineErr
+	} else if !client.IsPaused() {
 		if client.Connection != nil {
 			err = comm.SendRequestAndGetResponse(client.GetConnection(), comm.RequestTypeRunCmd, curJob, sshResp, al.Log())
 		} else {
@@ -107,6 +149,11 @@ func (al *APIListener) createAndRunJob(
 		// just log it, cmd is running, when it's finished it can be saved on result return
 		al.Errorf("%s, Failed to persist job: %v", logPrefix, dbErr)
 	}
+	if multiJobID != nil {
+		if dbErr := al.jobProvider.DeleteDispatch(*multiJobID, curJob.ClientID); dbErr != nil {
+			al.Errorf("%s, Failed to delete dispatch state: %v", logPrefix, dbErr)
+		}
+	}
 
 	return err
 }
@@ -141,7 +188,7 @@ func (al *APIListener) StartMultiClientJob(ctx context.Context, multiJobRequest
 		}
 	}
 
-	if len(multiJobRequest.OrderedClients) == 0 {
+	if len(multiJobRequest.OrderedClients) == 0 && len(multiJobRequest.QueuedClients) == 0 {
 		return nil, fmt.Errorf("no clients for execution")
 	}
 
@@ -172,20 +219,68 @@ func (al *APIListener) StartMultiClientJob(ctx context.Context, multiJobRequest
 		TimeoutSec:  multiJobRequest.TimeoutSec,
 		Concurrent:  multiJobRequest.ExecuteConcurrently,
 		AbortOnErr:  abortOnErr,
+		Labels:      multiJobRequest.Labels,
+		ClientCount: len(multiJobRequest.OrderedClients) + len(multiJobRequest.QueuedClients),
+		Idempotent:  multiJobRequest.Idempotent,
+
+		CorrelationID: api.GetRequestID(ctx),
 	}
 	if err := al.jobProvider.SaveMultiJob(multiJob); err != nil {
 		return nil, err
 	}
 
-	go al.executeMultiClientJob(multiJob, multiJobRequest.OrderedClients)
+	queueTTL := time.Duration(multiJobRequest.QueueTTLSec) * time.Second
+	if err := al.saveDispatches(multiJob, multiJobRequest.OrderedClients, multiJobRequest.QueuedClients, queueTTL); err != nil {
+		return nil, err
+	}
+	go al.executeMultiClientJob(multiJob, multiJobRequest.OrderedClients, multiJobRequest.QueuedClients, queueTTL)
 
 	return multiJob, nil
 }
 
+// saveDispatches persists the clients the job is not sent to yet to resume the dispatch after a restart of the server.
+func (al *APIListener) saveDispatches(job *models.MultiJob, orderedClients, queuedClients []*clientdata.Client, queueTTL time.Duration) error {
+	dispatches := make([]*jobs.Dispatch, 0, len(orderedClients)+len(queuedClients))
+	for _, client := range orderedClients {
+		dispatches = append(dispatches, &jobs.Dispatch{
+			MultiJobID: job.JID,
+			ClientID:   client.GetID(),
+			State:      jobs.DispatchStatePending,
+		})
+	}
+	expiresAt := time.Now().Add(queueTTL)
+	for _, client := range queuedClients {
+		dispatches = append(dispatches, &jobs.Dispatch{
+			MultiJobID: job.JID,
+			ClientID:   client.GetID(),
+			State:      jobs.DispatchStateQueued,
+			ExpiresAt:  &expiresAt,
+		})
+	}
+	return al.jobProvider.SaveDispatches(dispatches)
+}
+
+// abortMultiClientJob deletes the pending clients of a sequential job the job is not sent to anymore.
+func (al *APIListener) abortMultiClientJob(job *models.MultiJob) {
+	if err := al.jobProvider.DeletePendingDispatches(job.JID); err != nil {
+		al.Errorf("multi job %s: failed to delete pending dispatch states: %v", job.JID, err)
+	}
+}
+
 func (al *APIListener) executeMultiClientJob(
 	job *models.MultiJob,
 	orderedClients []*clientdata.Client,
+	queuedClients []*clientdata.Client,
+	queueTTL time.Duration,
 ) {
+	// queued jobs are not part of the sequence, they are queued once it's done to not interfere with waiting for the
+	// job results
+	if job.Concurrent {
+		al.queueMultiClientJob(job, queuedClients, queueTTL)
+	} else {
+		defer al.queueMultiClientJob(job, queuedClients, queueTTL)
+	}
+
 	// for sequential execution - create a channel to get the job result
 	var curJobDoneChannel chan *models.Job
 	if !job.Concurrent {
@@ -199,6 +294,7 @@ func (al *APIListener) executeMultiClientJob(
 	for _, client := range orderedClients {
 		curJID, err := generateNewJobID()
 		if err != nil {
+			al.abortMultiClientJob(job)
 			return
 		}
 		if job.Concurrent {
@@ -210,9 +306,11 @@ func (al *APIListener) executeMultiClientJob(
 				job.Interpreter,
 				job.CreatedBy,
 				job.Cwd,
+				job.CorrelationID,
 				job.TimeoutSec,
 				job.IsSudo,
 				job.IsScript,
+				job.Labels,
 				client,
 			)
 		} else {
@@ -224,13 +322,16 @@ func (al *APIListener) executeMultiClientJob(
 				job.Interpreter,
 				job.CreatedBy,
 				job.Cwd,
+				job.CorrelationID,
 				job.TimeoutSec,
 				job.IsSudo,
 				job.IsScript,
+				job.Labels,
 				client,
 			)
 			if err != nil {
 				if job.AbortOnErr && !errors.Is(err, ErrClientNotConnected) {
+					al.abortMultiClientJob(job)
 					break
 				}
 				continue
@@ -245,6 +346,7 @@ func (al *APIListener) executeMultiClientJob(
 			// wait until command is finished
 			jobResult := <-curJobDoneChannel
 			if job.AbortOnErr && jobResult.Status == models.JobStatusFailed {
+				al.abortMultiClientJob(job)
 				break
 			}
 		}
@@ -253,3 +355,145 @@ func (al *APIListener) executeMultiClientJob(
 		al.testDone <- true
 	}
 }
+
+// queueMultiClientJob runs the job on the given clients once they reconnect. A failed job is saved for clients not
+// reconnecting within the ttl.
+func (al *APIListener) queueMultiClientJob(job *models.MultiJob, clients []*clientdata.Client, ttl time.Duration) {
+	for _, client := range clients {
+		al.queueMultiClientJobFor(job, client.GetID(), client.GetName(), ttl)
+	}
+}
+
+// queueMultiClientJobFor runs the job on a single client once it reconnects.
+func (al *APIListener) queueMultiClientJobFor(job *models.MultiJob, clientID, clientName string, ttl time.Duration) {
+	al.jobQueue.Add(clientID, ttl, func(client *clientdata.Client) {
+		curJID, err := generateNewJobID()
+		if err != nil {
+			al.Errorf("multi job %s: failed to generate job id for queued client %s: %v", job.JID, clientID, err)
+			return
+		}
+		_ = al.createAndRunJob(
+			nil,
+			&job.JID,
+			curJID,
+			job.Command,
+			job.Interpreter,
+			job.CreatedBy,
+			job.Cwd,
+			job.CorrelationID,
+			job.TimeoutSec,
+			job.IsSudo,
+			job.IsScript,
+			job.Labels,
+			client,
+		)
+	}, func() {
+		al.saveExpiredQueuedJob(job, clientID, clientName, ttl)
+	})
+
+	// the client might have reconnected before the job was queued
+	if cur, err := al.clientService.GetRepo().GetActiveByID(clientID); err == nil && cur != nil {
+		al.jobQueue.ClientChanged(cur, false)
+	}
+}
+
+func (al *APIListener) saveExpiredQueuedJob(job *models.MultiJob, clientID, clientName string, ttl time.Duration) {
+	curJID, err := generateNewJobID()
+	if err != nil {
+		al.Errorf("multi job %s: failed to generate job id for expired client %s: %v", job.JID, clientID, err)
+		return
+	}
+	now := time.Now()
+	expiredJob := &models.Job{
+		JID:         curJID,
+		StartedAt:   now,
+		FinishedAt:  &now,
+		ClientID:    clientID,
+		ClientName:  clientName,
+		Command:     job.Command,
+		Cwd:         job.Cwd,
+		IsSudo:      job.IsSudo,
+		IsScript:    job.IsScript,
+		Interpreter: job.Interpreter,
+		CreatedBy:   job.CreatedBy,
+		TimeoutSec:  job.TimeoutSec,
+		MultiJobID:  &job.JID,
+		Labels:      job.Labels,
+		Status:      models.JobStatusFailed,
+		Error:       fmt.Sprintf("client didn't reconnect within %s", ttl),
+
+		CorrelationID: job.CorrelationID,
+	}
+	if err := al.jobProvider.CreateJob(expiredJob); err != nil {
+		al.Errorf("%s, Failed to persist expired job: %v", expiredJob.LogPrefix(), err)
+	}
+	if err := al.jobProvider.DeleteDispatch(job.JID, clientID); err != nil {
+		al.Errorf("%s, Failed to delete dispatch state: %v", expiredJob.LogPrefix(), err)
+	}
+}
+
+// runPreflightCheck pings the clients of the request concurrently if requested. Unreachable clients are removed from
+// the ordered clients and either skipped or queued, they are returned to report them to the user.
+func (al *APIListener) runPreflightCheck(ctx context.Context, req *jobs.MultiJobRequest) ([]jobs.UnreachableClient, error) {
+	if !req.PreflightCheck {
+		return nil, nil
+	}
+
+	timeout := time.Duration(req.PreflightTimeoutSec) * time.Second
+	errs := make([]error, len(req.OrderedClients))
+	wg := sync.WaitGroup{}
+	for i, client := range req.OrderedClients {
+		wg.Add(1)
+		go func(i int, client *clientdata.Client) {
+			defer wg.Done()
+			errs[i] = al.pingClient(ctx, client, timeout)
+		}(i, client)
+	}
+	wg.Wait()
+
+	reachable := make([]*clientdata.Client, 0, len(req.OrderedClients))
+	unreachable := []jobs.UnreachableClient{}
+	for i, client := range req.OrderedClients {
+		if errs[i] == nil {
+			reachable = append(reachable, client)
+			continue
+		}
+		unreachable = append(unreachable, jobs.UnreachableClient{
+			ClientID:   client.GetID(),
+			ClientName: client.GetName(),
+			Error:      errs[i].Error(),
+			Action:     req.OnUnreachable,
+		})
+		if req.OnUnreachable == jobs.OnUnreachableQueue {
+			req.QueuedClients = append(req.QueuedClients, client)
+		}
+	}
+	req.OrderedClients = reachable
+
+	if len(req.OrderedClients) == 0 && len(req.QueuedClients) == 0 {
+		ids := make([]string, 0, len(unreachable))
+		for _, u := range unreachable {
+			ids = append(ids, u.ClientID)
+		}
+		return unreachable, errors2.APIError{
+			HTTPStatus: http.StatusConflict,
+			Message:    fmt.Sprintf("None of the targeted clients is reachable: %s.", strings.Join(ids, ", ")),
+		}
+	}
+	return unreachable, nil
+}
+
+func (al *APIListener) pingClient(ctx context.Context, client *clientdata.Client, timeout time.Duration) error {
+	conn := client.GetConnection()
+	if conn == nil || !client.IsConnected() {
+		return ErrClientNotConnected
+	}
+	ok, _, _, err := comm.PingConnectionWithTimeout(ctx, conn, timeout, al.Log())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("client rejected the ping")
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package chserver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/api/jobs"
+	"github.com/realvnc-labs/rport/server/jobqueue"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+// orphanedJobGracePeriod is the time to wait for the result of a job after its timeout before the job is considered lost.
+const orphanedJobGracePeriod = time.Minute
+
+// recoverMultiClientJobs resumes the dispatch of multi-client jobs interrupted by a stop of the server. Pending and
+// queued clients are queued to run the job once they reconnect. For clients the job was sent to while the server stopped
+// it's unknown whether they received it, the job is sent again if it's idempotent, otherwise a job with status unknown
+// is saved.
+func (al *APIListener) recoverMultiClientJobs(ctx context.Context) error {
+	dispatches, err := al.jobProvider.ListDispatches(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list dispatch states: %w", err)
+	}
+
+	multiJobs := make(map[string]*models.MultiJob)
+	for _, d := range dispatches {
+		job, ok := multiJobs[d.MultiJobID]
+		if !ok {
+			job, err = al.jobProvider.GetMultiJob(ctx, d.MultiJobID)
+			if err != nil {
+				return fmt.Errorf("failed to get multi job %s: %w", d.MultiJobID, err)
+			}
+			multiJobs[d.MultiJobID] = job
+		}
+		if job == nil {
+			// the multi job was deleted in the meantime
+			if err := al.jobProvider.DeleteDispatch(d.MultiJobID, d.ClientID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		clientName := al.getClientName(d.ClientID)
+		switch d.State {
+		case jobs.DispatchStatePending:
+			al.Infof("multi job %s: queueing pending client %s", job.JID, d.ClientID)
+			al.requeueMultiClientJob(job, d.ClientID, clientName)
+		case jobs.DispatchStateQueued:
+			if d.ExpiresAt == nil {
+				al.requeueMultiClientJob(job, d.ClientID, clientName)
+				continue
+			}
+			ttl := time.Until(*d.ExpiresAt)
+			if ttl <= 0 {
+				al.saveExpiredQueuedJob(job, d.ClientID, clientName, d.ExpiresAt.Sub(job.StartedAt).Round(time.Second))
+				continue
+			}
+			al.Infof("multi job %s: queueing client %s for %s", job.JID, d.ClientID, ttl.Round(time.Second))
+			al.queueMultiClientJobFor(job, d.ClientID, clientName, ttl)
+		case jobs.DispatchStateDispatching:
+			if job.Idempotent {
+				al.Infof("multi job %s: queueing idempotent job again for client %s", job.JID, d.ClientID)
+				al.requeueMultiClientJob(job, d.ClientID, clientName)
+				continue
+			}
+			al.Infof("multi job %s: client %s might not have received the job", job.JID, d.ClientID)
+			al.saveInterruptedJob(job, d.ClientID, clientName)
+		}
+	}
+	return nil
+}
+
+// requeueMultiClientJob persists the client as queued with the default ttl and queues the job.
+func (al *APIListener) requeueMultiClientJob(job *models.MultiJob, clientID, clientName string) {
+	expiresAt := time.Now().Add(jobqueue.DefaultTTL)
+	err := al.jobProvider.SaveDispatches([]*jobs.Dispatch{{
+		MultiJobID: job.JID,
+		ClientID:   clientID,
+		State:      jobs.DispatchStateQueued,
+		ExpiresAt:  &expiresAt,
+	}})
+	if err != nil {
+		al.Errorf("multi job %s: failed to persist dispatch state of client %s: %v", job.JID, clientID, err)
+	}
+	al.queueMultiClientJobFor(job, clientID, clientName, jobqueue.DefaultTTL)
+}
+
+// saveInterruptedJob saves a job with status unknown for a client the job was sent to while the server stopped.
+func (al *APIListener) saveInterruptedJob(job *models.MultiJob, clientID, clientName string) {
+	curJID, err := generateNewJobID()
+	if err != nil {
+		al.Errorf("multi job %s: failed to generate job id for interrupted client %s: %v", job.JID, clientID, err)
+		return
+	}
+	now := time.Now()
+	interruptedJob := &models.Job{
+		JID:         curJID,
+		StartedAt:   now,
+		FinishedAt:  &now,
+		ClientID:    clientID,
+		ClientName:  clientName,
+		Command:     job.Command,
+		Cwd:         job.Cwd,
+		IsSudo:      job.IsSudo,
+		IsScript:    job.IsScript,
+		Interpreter: job.Interpreter,
+		CreatedBy:   job.CreatedBy,
+		TimeoutSec:  job.TimeoutSec,
+		MultiJobID:  &job.JID,
+		Labels:      job.Labels,
+		Status:      models.JobStatusUnknown,
+		Error:       "server stopped while sending the job to the client",
+
+		CorrelationID: job.CorrelationID,
+	}
+	if err := al.jobProvider.CreateJob(interruptedJob); err != nil {
+		al.Errorf("%s, Failed to persist interrupted job: %v", interruptedJob.LogPrefix(), err)
+	}
+	if err := al.jobProvider.DeleteDispatch(job.JID, clientID); err != nil {
+		al.Errorf("%s, Failed to delete dispatch state: %v", interruptedJob.LogPrefix(), err)
+	}
+}
+
+func (al *APIListener) getClientName(clientID string) string {
+	client, err := al.clientService.GetByID(clientID)
+	if err != nil || client == nil {
+		return ""
+	}
+	return client.GetName()
+}
+
+// orphanedJobsTask marks jobs as unknown that were running when the server stopped and whose result wasn't received
+// within their timeout. Jobs of idempotent multi-client jobs are queued to run again.
+type orphanedJobsTask struct {
+	al        *APIListener
+	startedAt time.Time
+}
+
+func newOrphanedJobsTask(al *APIListener, startedAt time.Time) *orphanedJobsTask {
+	return &orphanedJobsTask{
+		al:        al,
+		startedAt: startedAt,
+	}
+}
+
+func (t *orphanedJobsTask) Run(ctx context.Context) error {
+	running, err := t.al.jobProvider.ListRunningJobs(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, job := range running {
+		// jobs started by this server are handled when the result is received
+		if !job.StartedAt.Before(t.startedAt) {
+			continue
+		}
+		timeoutSec := job.TimeoutSec
+		if timeoutSec <= 0 {
+			timeoutSec = t.al.config.Server.RunRemoteCmdTimeoutSec
+		}
+		if now.Before(job.StartedAt.Add(time.Duration(timeoutSec)*time.Second + orphanedJobGracePeriod)) {
+			continue
+		}
+
+		job.Status = models.JobStatusUnknown
+		job.FinishedAt = &now
+		job.Error = "result was not received, the server stopped while the job was running"
+		if err := t.al.jobProvider.SaveJob(job); err != nil {
+			return fmt.Errorf("failed to save orphaned job %s: %w", job.JID, err)
+		}
+		t.al.Infof("%s, Job marked as orphaned", job.LogPrefix())
+
+		if job.MultiJobID == nil {
+			continue
+		}
+		multiJob, err := t.al.jobProvider.GetMultiJob(ctx, *job.MultiJobID)
+		if err != nil {
+			return fmt.Errorf("failed to get multi job %s: %w", *job.MultiJobID, err)
+		}
+		if multiJob != nil && multiJob.Idempotent {
+			t.al.Infof("multi job %s: queueing idempotent job again for client %s", multiJob.JID, job.ClientID)
+			t.al.requeueMultiClientJob(multiJob, job.ClientID, job.ClientName)
+		}
+	}
+	return nil
+}
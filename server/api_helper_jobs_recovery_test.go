@@ -0,0 +1,132 @@
+package chserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	jobsmigration "github.com/realvnc-labs/rport/db/migration/jobs"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/api/jobs"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/jobqueue"
+	"github.com/realvnc-labs/rport/server/test/jb"
+	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+func newJobsRecoveryTestListener(t *testing.T) (*APIListener, *jobs.SqliteProvider) {
+	jobsDB, err := sqlite.New(":memory:", jobsmigration.AssetNames(), jobsmigration.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	jp := jobs.NewSqliteProvider(jobsDB, testLog)
+	t.Cleanup(func() { jp.Close() })
+
+	al := &APIListener{
+		Server: &Server{
+			clientService: clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{}, &hour, testLog), testLog, nil),
+			config: &chconfig.Config{
+				Server: chconfig.ServerConfig{
+					RunRemoteCmdTimeoutSec: 60,
+				},
+			},
+			jobQueue:    jobqueue.New(testLog),
+			jobProvider: jp,
+		},
+		Logger: testLog,
+	}
+	return al, jp
+}
+
+func TestRecoverMultiClientJobs(t *testing.T) {
+	ctx := context.Background()
+	al, jp := newJobsRecoveryTestListener(t)
+
+	job := jb.NewMulti(t).JID("multi-1").StartedAt(time.Now().Add(-2 * time.Hour)).Build()
+	require.NoError(t, jp.SaveMultiJob(job))
+	idempotentJob := jb.NewMulti(t).JID("multi-2").Build()
+	idempotentJob.Idempotent = true
+	require.NoError(t, jp.SaveMultiJob(idempotentJob))
+
+	expired := time.Now().Add(-time.Hour)
+	notExpired := time.Now().Add(time.Hour)
+	require.NoError(t, jp.SaveDispatches([]*jobs.Dispatch{
+		{MultiJobID: "multi-1", ClientID: "client-1", State: jobs.DispatchStatePending},
+		{MultiJobID: "multi-1", ClientID: "client-2", State: jobs.DispatchStateQueued, ExpiresAt: &expired},
+		{MultiJobID: "multi-1", ClientID: "client-3", State: jobs.DispatchStateQueued, ExpiresAt: &notExpired},
+		{MultiJobID: "multi-1", ClientID: "client-4", State: jobs.DispatchStateDispatching},
+		{MultiJobID: "multi-2", ClientID: "client-5", State: jobs.DispatchStateDispatching},
+	}))
+
+	require.NoError(t, al.recoverMultiClientJobs(ctx))
+
+	assert.Equal(t, 1, al.jobQueue.Count("client-1"))
+	assert.Equal(t, 0, al.jobQueue.Count("client-2"))
+	assert.Equal(t, 1, al.jobQueue.Count("client-3"))
+	assert.Equal(t, 0, al.jobQueue.Count("client-4"))
+	assert.Equal(t, 1, al.jobQueue.Count("client-5"))
+
+	dispatches, err := jp.ListDispatches(ctx)
+	require.NoError(t, err)
+	gotStates := make(map[string]string)
+	for _, d := range dispatches {
+		gotStates[d.ClientID] = d.State
+	}
+	assert.Equal(t, map[string]string{
+		"client-1": jobs.DispatchStateQueued,
+		"client-3": jobs.DispatchStateQueued,
+		"client-5": jobs.DispatchStateQueued,
+	}, gotStates)
+
+	gotJobs, err := jp.List(ctx, &query.ListOptions{})
+	require.NoError(t, err)
+	gotStatuses := make(map[string]string)
+	for _, j := range gotJobs {
+		gotStatuses[j.ClientID] = j.Status
+	}
+	assert.Equal(t, map[string]string{
+		"client-2": models.JobStatusFailed,
+		"client-4": models.JobStatusUnknown,
+	}, gotStatuses)
+}
+
+func TestOrphanedJobsTask(t *testing.T) {
+	ctx := context.Background()
+	al, jp := newJobsRecoveryTestListener(t)
+
+	idempotentJob := jb.NewMulti(t).JID("multi-1").Build()
+	idempotentJob.Idempotent = true
+	require.NoError(t, jp.SaveMultiJob(idempotentJob))
+
+	startedAt := time.Now()
+	lost := jb.New(t).JID("1").ClientID("client-1").Status(models.JobStatusRunning).StartedAt(startedAt.Add(-time.Hour)).Build()
+	lost.TimeoutSec = 60
+	lostIdempotent := jb.New(t).JID("2").ClientID("client-2").MultiJobID("multi-1").Status(models.JobStatusRunning).StartedAt(startedAt.Add(-time.Hour)).Build()
+	lostIdempotent.TimeoutSec = 60
+	withinTimeout := jb.New(t).JID("3").ClientID("client-3").Status(models.JobStatusRunning).StartedAt(startedAt.Add(-time.Minute)).Build()
+	withinTimeout.TimeoutSec = 3600
+	startedAfter := jb.New(t).JID("4").ClientID("client-4").Status(models.JobStatusRunning).StartedAt(startedAt.Add(time.Second)).Build()
+	startedAfter.TimeoutSec = 1
+	for _, j := range []*models.Job{lost, lostIdempotent, withinTimeout, startedAfter} {
+		require.NoError(t, jp.SaveJob(j))
+	}
+
+	require.NoError(t, newOrphanedJobsTask(al, startedAt).Run(ctx))
+
+	for jid, wantStatus := range map[string]string{
+		"1": models.JobStatusUnknown,
+		"2": models.JobStatusUnknown,
+		"3": models.JobStatusRunning,
+		"4": models.JobStatusRunning,
+	} {
+		got, err := jp.GetByJID("", jid)
+		require.NoError(t, err)
+		assert.Equal(t, wantStatus, got.Status, jid)
+	}
+	assert.Equal(t, 0, al.jobQueue.Count("client-1"))
+	assert.Equal(t, 1, al.jobQueue.Count("client-2"))
+}
@@ -0,0 +1,180 @@
+package chserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	alertingcap "github.com/realvnc-labs/rport/plus/capabilities/alerting"
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/entities/rules"
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/entities/validations"
+	"github.com/realvnc-labs/rport/server/api/jobs/runbook"
+)
+
+var errClientNotTargeted = errors.New("client of the problem is not a target of the runbook")
+
+type problemsProvider interface {
+	GetLatestProblems(limit int) (problems []*rules.Problem, err error)
+}
+
+// runbookRemediationTask runs the runbooks referenced by the actions of active alerting problems on the client
+// of the problem. Each runbook is run once per problem, the results of started runbooks are checked on each run.
+type runbookRemediationTask struct {
+	al       *APIListener
+	problems problemsProvider
+}
+
+func newRunbookRemediationTask(al *APIListener, problems problemsProvider) *runbookRemediationTask {
+	return &runbookRemediationTask{
+		al:       al,
+		problems: problems,
+	}
+}
+
+func (t *runbookRemediationTask) Run(ctx context.Context) error {
+	err := t.al.runbookManager.CheckRemediations(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check remediations: %v", err)
+	}
+
+	problems, err := t.problems.GetLatestProblems(alertingcap.NoLimit)
+	if err != nil {
+		return fmt.Errorf("failed to get problems: %v", err)
+	}
+
+	for _, problem := range problems {
+		if problem == nil || !problem.Active {
+			continue
+		}
+		for _, action := range problem.Actions {
+			if action.RunbookID == "" {
+				continue
+			}
+			err := t.remediate(ctx, problem, action)
+			if err != nil {
+				t.al.Errorf("Failed to remediate problem %s with runbook %s: %v", problem.ID, action.RunbookID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t *runbookRemediationTask) remediate(ctx context.Context, problem *rules.Problem, action rules.Action) error {
+	problemID := string(problem.ID)
+	runbookID := string(action.RunbookID)
+	done, err := t.al.runbookManager.IsRemediated(ctx, problemID, runbookID)
+	if err != nil {
+		return err
+	}
+	if done {
+		return nil
+	}
+
+	rb, err := t.al.runbookManager.Get(ctx, runbookID)
+	if err != nil {
+		return err
+	}
+	if rb == nil {
+		// the runbook might be created later, the problem is checked again
+		t.al.Debugf("Runbook %s referenced by problem %s not found.", runbookID, problemID)
+		return nil
+	}
+
+	req := &runbook.RemediationRequest{
+		ProblemID: problemID,
+		RuleID:    string(problem.RuleID),
+		ClientID:  problem.ClientID,
+	}
+	if action.RemediationSettings != nil {
+		req.MaxPerHour = action.MaxPerHour
+		req.SuccessOutput = action.SuccessOutput
+	}
+
+	targeted, err := t.al.runbookTargetsClient(ctx, rb, problem.ClientID)
+	if err != nil {
+		return err
+	}
+	if !targeted {
+		_, err = t.al.runbookManager.SkipRemediation(ctx, rb, req, errClientNotTargeted)
+		return err
+	}
+
+	_, err = t.al.runbookManager.Remediate(ctx, rb, req)
+	return err
+}
+
+// runbookTargetsClient returns true if the client is one of the targets of the runbook, a runbook is never run
+// on clients it's not materialized for.
+func (al *APIListener) runbookTargetsClient(ctx context.Context, rb *runbook.Runbook, clientID string) (bool, error) {
+	for _, id := range rb.Details.ClientIDs {
+		if id == clientID {
+			return true, nil
+		}
+	}
+
+	if hasClientTags(rb) {
+		tagged, err := al.getOrderedClientsByTag(rb.Details.ClientTags)
+		if err != nil {
+			return false, err
+		}
+		for _, client := range tagged {
+			if client.GetID() == clientID {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	if len(rb.Details.GroupIDs) == 0 {
+		return false, nil
+	}
+	groupClients, err := al.makeGroupClientsList(ctx, rb.Details.GroupIDs)
+	if err != nil {
+		return false, err
+	}
+	for _, client := range groupClients {
+		if client.GetID() == clientID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// validateRunbookActions checks that the runbooks referenced by the rules exist and their guardrails are valid,
+// the rest of the rule set is validated by the alerting service.
+func (al *APIListener) validateRunbookActions(ctx context.Context, rs *rules.RuleSet) (errs validations.ErrorList) {
+	for ri, rule := range rs.Rules {
+		for ai, action := range rule.Actions {
+			prefix := fmt.Sprintf("rules[%d].actions[%d]", ri, ai)
+			if action.RunbookID == "" {
+				if action.RemediationSettings != nil {
+					errs = append(errs, validations.ValidationError{Prefix: prefix, Err: errors.New("remediation requires a runbook")})
+				}
+				continue
+			}
+
+			rb, err := al.runbookManager.Get(ctx, string(action.RunbookID))
+			if err != nil {
+				errs = append(errs, validations.ValidationError{Prefix: prefix, Err: err})
+				continue
+			}
+			if rb == nil {
+				errs = append(errs, validations.ValidationError{Prefix: prefix, Err: fmt.Errorf("runbook %s not found", action.RunbookID)})
+			}
+
+			if action.RemediationSettings == nil {
+				continue
+			}
+			if action.MaxPerHour < 0 {
+				errs = append(errs, validations.ValidationError{Prefix: prefix, Err: errors.New("max_per_hour cannot be negative")})
+			}
+			if _, err := regexp.Compile(action.SuccessOutput); err != nil {
+				errs = append(errs, validations.ValidationError{Prefix: prefix, Err: fmt.Errorf("invalid success_output: %v", err)})
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,140 @@
+package chserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	jobsmigration "github.com/realvnc-labs/rport/db/migration/jobs"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/entities/rules"
+	"github.com/realvnc-labs/rport/server/api/jobs"
+	"github.com/realvnc-labs/rport/server/api/jobs/runbook"
+	"github.com/realvnc-labs/rport/server/script"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+type fakeProblemsProvider struct {
+	problems []*rules.Problem
+}
+
+func (f *fakeProblemsProvider) GetLatestProblems(limit int) ([]*rules.Problem, error) {
+	return f.problems, nil
+}
+
+type fakeRunbookJobRunner struct {
+	requests []*jobs.MultiJobRequest
+}
+
+func (f *fakeRunbookJobRunner) StartMultiClientJob(ctx context.Context, req *jobs.MultiJobRequest) (*models.MultiJob, error) {
+	f.requests = append(f.requests, req)
+	return &models.MultiJob{MultiJobSummary: models.MultiJobSummary{JID: "multi-job-1"}}, nil
+}
+
+func TestRunbookRemediationTask(t *testing.T) {
+	ctx := context.Background()
+	jobsDB, err := sqlite.New(":memory:", jobsmigration.AssetNames(), jobsmigration.Asset, DataSourceOptions)
+	require.NoError(t, err)
+
+	jp := jobs.NewSqliteProvider(jobsDB, testLog)
+	runner := &fakeRunbookJobRunner{}
+	runbookManager := runbook.NewManager(runner, jp, jobsDB, testLog, 60)
+	rb, err := runbookManager.Materialize(ctx, &script.Script{ID: "script-1", Script: "systemctl restart {{.params.service}}"}, &runbook.MaterializeRequest{
+		Name:      "restart nginx",
+		Params:    map[string]string{"service": "nginx"},
+		ClientIDs: []string{"client-1"},
+	}, "admin")
+	require.NoError(t, err)
+
+	al := &APIListener{
+		Server: &Server{
+			runbookManager: runbookManager,
+		},
+		Logger: testLog,
+	}
+	runbookAction := rules.ActionList{{RunbookID: rules.RunbookID(rb.ID), RemediationSettings: &rules.RemediationSettings{SuccessOutput: "ok"}}}
+	problems := &fakeProblemsProvider{
+		problems: []*rules.Problem{
+			{ID: "problem-1", RuleID: "rule-1", ClientID: "client-1", Active: true, Actions: runbookAction},
+			{ID: "problem-2", RuleID: "rule-1", ClientID: "client-2", Active: true, Actions: runbookAction},
+			{ID: "problem-3", RuleID: "rule-1", ClientID: "client-1", Active: false, Actions: runbookAction},
+			{ID: "problem-4", RuleID: "rule-2", ClientID: "client-1", Active: true, Actions: rules.ActionList{{RunbookID: "unknown"}}},
+			{ID: "problem-5", RuleID: "rule-3", ClientID: "client-1", Active: true, Actions: rules.ActionList{{LogMessage: "log"}}},
+		},
+	}
+	task := newRunbookRemediationTask(al, problems)
+
+	require.NoError(t, task.Run(ctx))
+	// problems are remediated only once
+	require.NoError(t, task.Run(ctx))
+
+	require.Len(t, runner.requests, 1)
+	assert.Equal(t, []string{"client-1"}, runner.requests[0].ClientIDs)
+	assert.Equal(t, "admin", runner.requests[0].Username)
+
+	req := httptest.NewRequest(http.MethodGet, "/runbook-remediations", nil)
+	payload, err := runbookManager.ListRemediations(ctx, req)
+	require.NoError(t, err)
+	got := map[string]string{}
+	for _, r := range payload.Data.([]*runbook.Remediation) {
+		assert.Equal(t, "rule-1", r.RuleID)
+		got[r.ProblemID] = r.Status + ": " + r.Error
+	}
+	assert.Equal(t, map[string]string{
+		"problem-1": "running: ",
+		"problem-2": "skipped: " + errClientNotTargeted.Error(),
+	}, got)
+	assert.Equal(t, "ok", payload.Data.([]*runbook.Remediation)[0].SuccessOutput)
+}
+
+func TestValidateRunbookActions(t *testing.T) {
+	ctx := context.Background()
+	jobsDB, err := sqlite.New(":memory:", jobsmigration.AssetNames(), jobsmigration.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	jp := jobs.NewSqliteProvider(jobsDB, testLog)
+	defer jp.Close()
+
+	runbookManager := runbook.NewManager(&fakeRunbookJobRunner{}, jp, jobsDB, testLog, 60)
+	rb, err := runbookManager.Materialize(ctx, &script.Script{ID: "script-1", Script: "systemctl restart nginx"}, &runbook.MaterializeRequest{
+		Name:      "restart nginx",
+		ClientIDs: []string{"client-1"},
+	}, "admin")
+	require.NoError(t, err)
+
+	al := &APIListener{
+		Server: &Server{
+			runbookManager: runbookManager,
+		},
+	}
+	rs := &rules.RuleSet{
+		Rules: []rules.Rule{
+			{
+				ID: "rule-1",
+				Actions: rules.ActionList{
+					{LogMessage: "log"},
+					{RunbookID: rules.RunbookID(rb.ID), RemediationSettings: &rules.RemediationSettings{MaxPerHour: 1, SuccessOutput: "^ok"}},
+					{RunbookID: "unknown"},
+					{RunbookID: rules.RunbookID(rb.ID), RemediationSettings: &rules.RemediationSettings{MaxPerHour: -1, SuccessOutput: "("}},
+					{RemediationSettings: &rules.RemediationSettings{MaxPerHour: 1}},
+				},
+			},
+		},
+	}
+
+	errs := al.validateRunbookActions(ctx, rs)
+
+	gotErrs := []string{}
+	for _, e := range errs {
+		gotErrs = append(gotErrs, e.Prefix+": "+e.Err.Error())
+	}
+	assert.Equal(t, []string{
+		"rules[0].actions[2]: runbook unknown not found",
+		"rules[0].actions[3]: max_per_hour cannot be negative",
+		"rules[0].actions[3]: invalid success_output: error parsing regexp: missing closing ): `(`",
+		"rules[0].actions[4]: remediation requires a runbook",
+	}, gotErrs)
+}
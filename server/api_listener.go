@@ -33,15 +33,20 @@ import (
 
 	"github.com/realvnc-labs/rport/server/api/authorization"
 	"github.com/realvnc-labs/rport/server/api/session"
+	"github.com/realvnc-labs/rport/server/clients/gatewaytargets"
 	"github.com/realvnc-labs/rport/server/clients/storedtunnels"
 	"github.com/realvnc-labs/rport/server/script"
 
 	"github.com/realvnc-labs/rport/server/api"
 	"github.com/realvnc-labs/rport/server/api/command"
 	"github.com/realvnc-labs/rport/server/api/message"
+	"github.com/realvnc-labs/rport/server/api/policy"
 	"github.com/realvnc-labs/rport/server/api/users"
 	"github.com/realvnc-labs/rport/server/bearer"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/routes"
 	"github.com/realvnc-labs/rport/server/vault"
+	"github.com/realvnc-labs/rport/server/webpush"
 
 	extperm "github.com/realvnc-labs/rport/plus/capabilities/extendedpermission"
 	chshare "github.com/realvnc-labs/rport/share"
@@ -83,6 +88,8 @@ type APIListener struct {
 	tokenManager   *authorization.Manager
 	commandManager *command.Manager
 	storedTunnels  *storedtunnels.Manager
+	policyClient   *policy.OPAClient
+	gatewayTargets *gatewaytargets.Manager
 
 	notificationsStorage   notificationsSQLite.Repository
 	notificationsProcessor notifications.Processor
@@ -166,6 +173,13 @@ func NewAPIListener(
 		notificationConsumers = append(notificationConsumers, logConsumer)
 	}
 
+	if server.webPush != nil {
+		notificationConsumers = append(notificationConsumers, webpush.NewConsumer(server.webPush, notificationsLogger.Fork("webpush")))
+	} else {
+		logConsumer := toLog.NewLogConsumer(notificationsLogger.Fork("webpush disabled"), notifications.TargetWebPush) // consume push notifications even if web push is not enabled
+		notificationConsumers = append(notificationConsumers, logConsumer)
+	}
+
 	notificationProcessor := notifications.NewProcessor(notificationsLogger, store, notificationConsumers...)
 	notificationsCleaner := notificationsSQLite.StartCleaner(logger.NewLogger("cleaner", config.Logging.LogOutput, logger.LogLevelInfo), store, MaxNotificationLife, CleanupNotificationsEvery)
 
@@ -234,7 +248,18 @@ func NewAPIListener(
 		}
 	}
 
+	HTTPServerOptions = append(HTTPServerOptions, chshare.WithHTTP2(config.API.EnableHTTP2), chshare.WithProxyProtocol(&config.API.ProxyProtocol))
+
 	allog := logger.NewLogger("api-listener", config.Logging.LogOutput, config.Logging.LogLevel)
+	activated, err := chshare.ActivatedListener(chshare.ActivatedAPISocket)
+	if err != nil {
+		return nil, err
+	}
+	if activated != nil {
+		allog.Infof("Using socket %s passed by systemd instead of %s", activated.Addr(), config.API.Address)
+		HTTPServerOptions = append(HTTPServerOptions, chshare.WithListener(activated))
+	}
+
 	a := &APIListener{
 		Server:                 server,
 		Logger:                 allog,
@@ -248,6 +273,7 @@ func NewAPIListener(
 		commandManager:         commandManager,
 		tokenManager:           tokenManager,
 		storedTunnels:          storedtunnels.New(server.clientDB),
+		gatewayTargets:         gatewaytargets.New(server.clientDB),
 		notificationsStorage:   store,
 		notificationsProcessor: notificationProcessor,
 		notificationsDB:        db,
@@ -256,31 +282,27 @@ func NewAPIListener(
 
 	a.errResponseLogger = allog.Fork("error-response")
 
+	if config.API.Policy.Enabled() {
+		a.policyClient = policy.NewOPAClient(config.API.Policy)
+	}
+
 	if config.API.IsTwoFAOn() {
-		var msgSrv message.Service
-		switch config.API.TwoFATokenDelivery {
-		case "pushover":
-			msgSrv = message.NewPushoverService(config.Pushover.APIToken)
-		case "smtp":
-			msgSrv, err = message.NewSMTPService(
-				config.SMTP.Server,
-				config.SMTP.AuthUsername,
-				config.SMTP.AuthPassword,
-				config.SMTP.SenderEmail,
-				config.SMTP.Secure,
-			)
-			if err != nil {
-				return nil, fmt.Errorf("failed to init smtp service: %v", err)
+		msgSrv, err := newTwoFAMessageService(config, config.API.TwoFATokenDelivery)
+		if err != nil {
+			return nil, err
+		}
+		if len(config.API.TwoFATokenDeliveryOptions) > 0 {
+			var options []message.Service
+			for _, delivery := range config.API.TwoFATokenDeliveryOptions {
+				option, err := newTwoFAMessageService(config, delivery)
+				if err != nil {
+					return nil, err
+				}
+				options = append(options, option)
 			}
-		default:
-			if _, err := exec.LookPath(config.API.TwoFATokenDelivery); err == nil {
-				msgSrv = message.NewScriptService(
-					config.API.TwoFATokenDelivery,
-					config.API.TwoFASendToType,
-					config.API.TwoFASendToRegexCompiled,
-				)
-			} else {
-				msgSrv = message.NewURLService(config.API.TwoFATokenDelivery, config.API.BaseURL)
+			msgSrv, err = message.NewMultiService(msgSrv, options...)
+			if err != nil {
+				return nil, err
 			}
 		}
 
@@ -335,10 +357,46 @@ func NewAPIListener(
 	return a, nil
 }
 
+// newTwoFAMessageService returns the service sending 2FA tokens via the given delivery, validated by the config.
+func newTwoFAMessageService(config *chconfig.Config, delivery string) (message.Service, error) {
+	switch delivery {
+	case "pushover":
+		return message.NewPushoverService(config.Pushover.APIToken), nil
+	case "smtp":
+		msgSrv, err := message.NewSMTPService(
+			config.SMTP.Server,
+			config.SMTP.AuthUsername,
+			config.SMTP.AuthPassword,
+			config.SMTP.SenderEmail,
+			config.SMTP.Secure,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init smtp service: %v", err)
+		}
+		return msgSrv, nil
+	case "twilio":
+		return message.NewTwilioService(
+			config.Twilio.APIURL,
+			config.Twilio.AccountSID,
+			config.Twilio.AuthToken,
+			config.Twilio.From,
+		), nil
+	default:
+		if _, err := exec.LookPath(delivery); err == nil {
+			return message.NewScriptService(
+				delivery,
+				config.API.TwoFASendToType,
+				config.API.TwoFASendToRegexCompiled,
+			), nil
+		}
+		return message.NewURLService(delivery, config.API.BaseURL, config.API.TwoFASendToRegexCompiled), nil
+	}
+}
+
 func (al *APIListener) Start(ctx context.Context, addr string) error {
 	al.Infof("API Listening on %s...", addr)
 
-	err := al.httpServer.GoListenAndServe(ctx, addr, al.router)
+	err := al.httpServer.GoListenAndServe(ctx, addr, rewriteTunnelProxyConnect(al.router))
 	if err != nil {
 		return err
 	}
@@ -476,6 +534,10 @@ func (al *APIListener) handleBasicAuth(ctx context.Context, httpverb, urlpath, u
 				if strings.Contains(urlpath, "clients-auth") {
 					return true, username, nil
 				}
+			case authorization.APITokenMetricsPush:
+				if httpverb == http.MethodPost && strings.HasSuffix(urlpath, routes.CustomMetricsRoute) {
+					return true, username, nil
+				}
 			}
 			return false, username, ErrInvalidScopeOfThatToken
 		}
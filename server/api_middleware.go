@@ -5,13 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
 
 	rportplus "github.com/realvnc-labs/rport/plus"
 	"github.com/realvnc-labs/rport/server/api"
 	errors2 "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/api/policy"
 	"github.com/realvnc-labs/rport/server/api/users"
 	"github.com/realvnc-labs/rport/server/bearer"
 	"github.com/realvnc-labs/rport/server/clients/clienttunnel"
@@ -46,7 +49,8 @@ func (al *APIListener) wrapAdminAccessMiddleware(next http.Handler) http.Handler
 			return
 		}
 
-		if user.IsAdmin() {
+		// auditors reach admin resources with read requests only, see wrapAuditorReadOnlyMiddleware
+		if user.CanReadAll() {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -61,6 +65,108 @@ func (al *APIListener) wrapAdminAccessMiddleware(next http.Handler) http.Handler
 	})
 }
 
+// wrapAuditorReadOnlyMiddleware rejects all requests of auditors that could change something, that is all requests but
+// GET, HEAD and OPTIONS and web socket upgrades. Only requests to the own account are allowed, e.g. to change the
+// password. It's applied to all authenticated routes, so the other middlewares can grant auditors read access.
+func (al *APIListener) wrapAuditorReadOnlyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if al.insecureForTests || isReadOnlyRequest(r) || isOwnAccountRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, err := al.getUserModelForAuth(r.Context())
+		if err != nil {
+			al.jsonError(w, err)
+			return
+		}
+
+		if user.IsAuditor() {
+			al.jsonError(w, errors2.APIError{
+				Message:    fmt.Sprintf("members of group %s have read-only access", users.Auditors),
+				HTTPStatus: http.StatusForbidden,
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// wrapNoAuditorAccessMiddleware rejects auditors on read routes that reveal secrets, e.g. client credentials and vault
+// values. Auditors are allowed to see what is configured, but not to use it.
+func (al *APIListener) wrapNoAuditorAccessMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if al.insecureForTests {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, err := al.getUserModelForAuth(r.Context())
+		if err != nil {
+			al.jsonError(w, err)
+			return
+		}
+
+		if user.IsAuditor() {
+			al.jsonError(w, errors2.APIError{
+				Message:    fmt.Sprintf("members of group %s have no access to secrets", users.Auditors),
+				HTTPStatus: http.StatusForbidden,
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// wrapAPIFreezeMiddleware rejects all requests that could change something with 503 while the API is frozen for
+// maintenance, except the requests to lift the freeze and connections to existing tunnels.
+func (al *APIListener) wrapAPIFreezeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if al.maintenance == nil || isReadOnlyRequest(r) || isTunnelConnectRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		route := routes.TrimVersionPrefix(r.URL.Path)
+		if route == "/maintenance/freeze" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if frozen, message := al.maintenance.IsFrozen(route); frozen {
+			al.jsonErrorResponseWithTitle(w, http.StatusServiceUnavailable, message)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isReadOnlyRequest(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return !websocket.IsWebSocketUpgrade(r)
+	}
+	return false
+}
+
+// isTunnelConnectRequest returns true for CONNECT /clients/{client_id}/tunnels/{tunnel_id}/connect, it only uses an
+// existing tunnel.
+func isTunnelConnectRequest(r *http.Request) bool {
+	if r.Method != http.MethodConnect {
+		return false
+	}
+	parts := strings.Split(strings.Trim(routes.TrimVersionPrefix(r.URL.Path), "/"), "/")
+	return len(parts) == 5 && parts[0] == "clients" && parts[2] == "tunnels" && parts[4] == "connect"
+}
+
+func isOwnAccountRequest(r *http.Request) bool {
+	path := routes.TrimVersionPrefix(r.URL.Path)
+	return path == "/me" || strings.HasPrefix(path, "/me/")
+}
+
 func (al *APIListener) wrapTotPEnabledMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if !al.config.API.TotPEnabled {
@@ -190,6 +296,12 @@ func (al *APIListener) permissionsMiddleware(permission string) mux.MiddlewareFu
 				return
 			}
 
+			// auditors have read access regardless of permissions, see wrapAuditorReadOnlyMiddleware
+			if currUser.IsAuditor() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			if al.userService.SupportsGroupPermissions() {
 				// Check group permissions only if supported otherwise let pass.
 				err = al.userService.CheckPermission(currUser, permission)
@@ -260,3 +372,94 @@ func (al *APIListener) updateTokenAccess(ctx context.Context, token string, acce
 
 	return nil
 }
+
+// wrapWithPolicyMiddleware denies requests not allowed by the external authorization policy.
+// It has to be used after the auth middleware as it needs the current user.
+func (al *APIListener) wrapWithPolicyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := al.getUserModelForAuth(r.Context())
+		if err != nil {
+			al.jsonError(w, err)
+			return
+		}
+
+		decision, err := al.policyClient.Decide(r.Context(), al.policyInput(r, user))
+		if err != nil {
+			if al.policyClient.FailOpen() {
+				al.Errorf("Authorization policy failed, allowing %s %s: %v", r.Method, r.URL.Path, err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			al.jsonError(w, errors2.APIError{
+				Message:    "Authorization policy is not available.",
+				Err:        err,
+				HTTPStatus: http.StatusForbidden,
+			})
+			return
+		}
+
+		if !decision.Allow {
+			msg := "Access denied by authorization policy."
+			if decision.Reason != "" {
+				msg = fmt.Sprintf("Access denied by authorization policy: %s", decision.Reason)
+			}
+			al.jsonError(w, errors2.APIError{
+				Message:    msg,
+				HTTPStatus: http.StatusForbidden,
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// wrapWithPolicyIfEnabled applies the authorization policy to routes which have their own auth instead of the secure router.
+func (al *APIListener) wrapWithPolicyIfEnabled(next http.Handler) http.Handler {
+	if al.policyClient == nil {
+		return next
+	}
+	return al.wrapWithPolicyMiddleware(next)
+}
+
+func (al *APIListener) policyInput(r *http.Request, user *users.User) *policy.Input {
+	input := &policy.Input{
+		User: policy.User{
+			Username: user.Username,
+			Groups:   user.Groups,
+		},
+		Request: policy.Request{
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			Vars:       mux.Vars(r),
+			Query:      r.URL.Query(),
+			RemoteAddr: r.RemoteAddr,
+		},
+	}
+	if route := mux.CurrentRoute(r); route != nil {
+		input.Request.Route, _ = route.GetPathTemplate()
+	}
+
+	clientID := mux.Vars(r)[routes.ParamClientID]
+	if clientID == "" {
+		return input
+	}
+	client, err := al.clientService.GetByID(clientID)
+	if err != nil || client == nil {
+		return input
+	}
+	input.Client = &policy.Client{
+		ID:                client.GetID(),
+		Name:              client.GetName(),
+		Tags:              client.GetTags(),
+		AutoTags:          client.GetAutoTags(),
+		Labels:            client.GetLabels(),
+		OSFamily:          client.GetOSFamily(),
+		OSKernel:          client.GetOSKernel(),
+		Hostname:          client.GetHostname(),
+		ClientAuthID:      client.GetClientAuthID(),
+		AllowedUserGroups: client.GetAllowedUserGroups(),
+		Connected:         client.IsConnected(),
+	}
+	return input
+}
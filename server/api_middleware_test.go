@@ -0,0 +1,253 @@
+package chserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/policy"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/clientsauth"
+	"github.com/realvnc-labs/rport/server/maintenance"
+)
+
+func TestWrapAuditorReadOnlyMiddleware(t *testing.T) {
+	al := APIListener{
+		userService: users.NewAPIService(users.NewStaticProvider([]*users.User{
+			{Username: "auditor", Groups: []string{users.Auditors}},
+			{Username: "admin-auditor", Groups: []string{users.Administrators, users.Auditors}},
+			{Username: "admin", Groups: []string{users.Administrators}},
+		}), false, 0, -1),
+		Logger: testLog,
+	}
+	handler := al.wrapAuditorReadOnlyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	testCases := []struct {
+		name       string
+		username   string
+		method     string
+		path       string
+		webSocket  bool
+		wantStatus int
+	}{
+		{
+			name:       "auditor reads",
+			username:   "auditor",
+			method:     http.MethodGet,
+			path:       "/api/v1/auditlog",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "auditor deletes",
+			username:   "auditor",
+			method:     http.MethodDelete,
+			path:       "/api/v1/clients/client-1",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "auditor executes command",
+			username:   "auditor",
+			method:     http.MethodPost,
+			path:       "/api/v1/clients/client-1/commands",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "auditor opens web socket",
+			username:   "auditor",
+			method:     http.MethodGet,
+			path:       "/api/v1/ws/commands",
+			webSocket:  true,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "auditor changes own account",
+			username:   "auditor",
+			method:     http.MethodPut,
+			path:       "/api/v1/me",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "auditor who is admin as well",
+			username:   "admin-auditor",
+			method:     http.MethodPost,
+			path:       "/api/v1/users",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "admin",
+			username:   "admin",
+			method:     http.MethodPost,
+			path:       "/api/v1/users",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.webSocket {
+				req.Header.Set("Connection", "Upgrade")
+				req.Header.Set("Upgrade", "websocket")
+			}
+			req = req.WithContext(api.WithUser(req.Context(), tc.username))
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestAuditorHasNoAccessToSecrets(t *testing.T) {
+	al, adminUser := setupTestAPIListenerUserAPISessions(t, nil)
+	al.userService = users.NewAPIService(users.NewStaticProvider([]*users.User{
+		adminUser,
+		{Username: "auditor", Password: "pa55word", Groups: []string{users.Auditors}},
+	}), false, 0, -1)
+	al.clientAuthProvider = clientsauth.NewSingleProvider("client-1", "secret")
+
+	testCases := []struct {
+		name       string
+		username   string
+		password   string
+		path       string
+		wantStatus int
+	}{
+		{
+			name:       "auditor lists client credentials",
+			username:   "auditor",
+			password:   "pa55word",
+			path:       "/api/v1/clients-auth",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "auditor reads client credentials",
+			username:   "auditor",
+			password:   "pa55word",
+			path:       "/api/v1/clients-auth/client-1",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "auditor reads vault value",
+			username:   "auditor",
+			password:   "pa55word",
+			path:       "/api/v1/vault/1",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "admin lists client credentials",
+			username:   adminUser.Username,
+			password:   adminUser.Password,
+			path:       "/api/v1/clients-auth",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "admin reads client credentials",
+			username:   adminUser.Username,
+			password:   adminUser.Password,
+			path:       "/api/v1/clients-auth/client-1",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.SetBasicAuth(tc.username, tc.password)
+			w := httptest.NewRecorder()
+
+			al.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestWrapAPIFreezeMiddleware(t *testing.T) {
+	al := APIListener{
+		Server: &Server{
+			maintenance: maintenance.NewService(testLog, maintenance.Config{}),
+		},
+		Logger: testLog,
+	}
+	_, err := al.maintenance.Freeze(maintenance.FreezeInput{Message: "data migration"}, "admin")
+	require.NoError(t, err)
+	handler := al.wrapAPIFreezeMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	testCases := []struct {
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{
+			method:     http.MethodGet,
+			path:       "/api/v1/clients",
+			wantStatus: http.StatusOK,
+		},
+		{
+			method:     http.MethodPut,
+			path:       "/api/v1/clients/client-1/tags",
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			method:     http.MethodDelete,
+			path:       "/api/v1/maintenance/freeze",
+			wantStatus: http.StatusOK,
+		},
+		{
+			method:     http.MethodConnect,
+			path:       "/api/v1/clients/client-1/tunnels/1/connect",
+			wantStatus: http.StatusOK,
+		},
+		{
+			method:     http.MethodConnect,
+			path:       "/api/v1/clients/client-1/tunnels",
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			if tc.wantStatus == http.StatusServiceUnavailable {
+				assert.Contains(t, w.Body.String(), "data migration")
+			}
+		})
+	}
+}
+
+func TestPolicyAppliesToWebSockets(t *testing.T) {
+	opa := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result": {"allow": false, "reason": "no commands"}}`))
+	}))
+	defer opa.Close()
+
+	al, adminUser := setupTestAPIListenerUserAPISessions(t, nil)
+	al.policyClient = policy.NewOPAClient(policy.Config{OPAURL: opa.URL, OPATimeout: policy.DefaultTimeout})
+	al.initRouter()
+
+	for _, path := range []string{"/api/v1/ws/commands", "/api/v1/ws/scripts", "/api/v1/ws/uploads", "/api/v1/ws/clients"} {
+		t.Run(path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.SetBasicAuth(adminUser.Username, adminUser.Password)
+			w := httptest.NewRecorder()
+
+			al.router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusForbidden, w.Code)
+			assert.Contains(t, w.Body.String(), "Access denied by authorization policy: no commands")
+		})
+	}
+}
@@ -1,12 +1,15 @@
 package chserver
 
 import (
+	"bufio"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 
 	"github.com/realvnc-labs/rport/server/api"
 	errors2 "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/api/middleware"
 	"github.com/realvnc-labs/rport/share/logger"
 )
 
@@ -16,6 +19,15 @@ func (al *APIListener) writeErrorResponseLog(errPayload api.ErrorPayload) {
 	}
 }
 
+// writeErrorResponse writes the error payload, errors without a specific code get the generic code of the status.
+// The id of the request is added to the payload.
+func (al *APIListener) writeErrorResponse(w http.ResponseWriter, statusCode int, errPayload api.ErrorPayload) {
+	errPayload = errPayload.WithDefaultCode(statusCode)
+	errPayload.RequestID = w.Header().Get(middleware.RequestIDHeader)
+	al.writeErrorResponseLog(errPayload)
+	al.writeJSONResponse(w, statusCode, errPayload)
+}
+
 func (al *APIListener) writeJSONResponse(w http.ResponseWriter, statusCode int, response interface{}) {
 	b, err := json.Marshal(response)
 	if err != nil {
@@ -30,10 +42,61 @@ func (al *APIListener) writeJSONResponse(w http.ResponseWriter, statusCode int,
 	}
 }
 
+// writeJSONListResponse writes a success payload with a list of n elements as data. Elements are created and encoded
+// one by one as the response is written, so large lists are not held in memory as a whole.
+func (al *APIListener) writeJSONListResponse(w http.ResponseWriter, statusCode int, n int, element func(i int) interface{}, meta *api.Meta) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(statusCode)
+
+	err := writeJSONList(w, n, element, meta)
+	if err != nil {
+		al.Errorf("error writing response: %s", err)
+	}
+}
+
+func writeJSONList(w io.Writer, n int, element func(i int) interface{}, meta *api.Meta) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(`{"data":[`); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			if err := bw.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		b, err := json.Marshal(element(i))
+		if err != nil {
+			return err
+		}
+		if _, err := bw.Write(b); err != nil {
+			return err
+		}
+	}
+	if _, err := bw.WriteString("]"); err != nil {
+		return err
+	}
+	if meta != nil {
+		b, err := json.Marshal(meta)
+		if err != nil {
+			return err
+		}
+		if _, err := bw.WriteString(`,"meta":`); err != nil {
+			return err
+		}
+		if _, err := bw.Write(b); err != nil {
+			return err
+		}
+	}
+	if _, err := bw.WriteString("}"); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
 func (al *APIListener) jsonErrorResponse(w http.ResponseWriter, statusCode int, err error) {
 	errPayload := api.NewErrAPIPayloadFromError(err, "", "")
-	al.writeErrorResponseLog(errPayload)
-	al.writeJSONResponse(w, statusCode, errPayload)
+	al.writeErrorResponse(w, statusCode, errPayload)
 }
 
 func (al *APIListener) jsonError(w http.ResponseWriter, err error) {
@@ -56,26 +119,22 @@ func (al *APIListener) jsonError(w http.ResponseWriter, err error) {
 	}
 
 	errPayload := api.NewErrAPIPayloadFromError(err, errCode, message)
-	al.writeErrorResponseLog(errPayload)
-	al.writeJSONResponse(w, statusCode, errPayload)
+	al.writeErrorResponse(w, statusCode, errPayload)
 }
 
 func (al *APIListener) jsonErrorResponseWithErrCode(w http.ResponseWriter, statusCode int, errCode, title string) {
 	errPayload := api.NewErrAPIPayloadFromMessage(errCode, title, "")
-	al.writeErrorResponseLog(errPayload)
-	al.writeJSONResponse(w, statusCode, errPayload)
+	al.writeErrorResponse(w, statusCode, errPayload)
 }
 
 func (al *APIListener) jsonErrorResponseWithTitle(w http.ResponseWriter, statusCode int, title string) {
 	errPayload := api.NewErrAPIPayloadFromMessage("", title, "")
-	al.writeErrorResponseLog(errPayload)
-	al.writeJSONResponse(w, statusCode, errPayload)
+	al.writeErrorResponse(w, statusCode, errPayload)
 }
 
 func (al *APIListener) jsonErrorResponseWithDetail(w http.ResponseWriter, statusCode int, errCode, title, detail string) {
 	errPayload := api.NewErrAPIPayloadFromMessage(errCode, title, detail)
-	al.writeErrorResponseLog(errPayload)
-	al.writeJSONResponse(w, statusCode, errPayload)
+	al.writeErrorResponse(w, statusCode, errPayload)
 }
 
 func (al *APIListener) jsonErrorResponseWithError(w http.ResponseWriter, statusCode int, title string, err error) {
@@ -84,6 +143,5 @@ func (al *APIListener) jsonErrorResponseWithError(w http.ResponseWriter, statusC
 		detail = err.Error()
 	}
 	errPayload := api.NewErrAPIPayloadFromMessage("", title, detail)
-	al.writeErrorResponseLog(errPayload)
-	al.writeJSONResponse(w, statusCode, errPayload)
+	al.writeErrorResponse(w, statusCode, errPayload)
 }
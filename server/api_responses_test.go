@@ -0,0 +1,60 @@
+package chserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/api"
+)
+
+func TestWriteJSONListResponse(t *testing.T) {
+	type item struct {
+		ID   string `json:"id"`
+		Name string `json:"name,omitempty"`
+	}
+	testCases := []struct {
+		Name  string
+		Items []item
+		Meta  *api.Meta
+	}{
+		{
+			Name:  "empty",
+			Items: []item{},
+			Meta:  api.NewMeta(0),
+		},
+		{
+			Name:  "items with meta",
+			Items: []item{{ID: "1", Name: "one"}, {ID: "2"}, {ID: "3", Name: "three"}},
+			Meta:  api.NewMeta(10),
+		},
+		{
+			Name:  "without meta",
+			Items: []item{{ID: "1"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			al := APIListener{Server: &Server{}}
+			w := httptest.NewRecorder()
+
+			al.writeJSONListResponse(w, http.StatusOK, len(tc.Items), func(i int) interface{} {
+				return tc.Items[i]
+			}, tc.Meta)
+
+			expected, err := json.Marshal(&api.SuccessPayload{
+				Data: tc.Items,
+				Meta: tc.Meta,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "application/json; charset=UTF-8", w.Header().Get("Content-Type"))
+			assert.Equal(t, string(expected), w.Body.String())
+		})
+	}
+}
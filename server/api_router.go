@@ -5,7 +5,6 @@ import (
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
-	"github.com/jpillora/requestlog"
 	"github.com/rs/cors"
 
 	rportplus "github.com/realvnc-labs/rport/plus"
@@ -32,15 +31,24 @@ var vueHistoryPaths = []string{
 func (al *APIListener) initRouter() {
 	r := mux.NewRouter()
 	api := r.PathPrefix(routes.AllRoutesPrefix).Subrouter()
+	apiV2 := r.PathPrefix(routes.V2RoutesPrefix).Subrouter()
+	al.initV2Routes(al.newSecureRouter(apiV2))
 
-	secureAPI := api.NewRoute().Subrouter()
-	if !al.insecureForTests {
-		secureAPI.Use(al.wrapWithAuthMiddleware(false))
-	}
+	secureAPI := al.newSecureRouter(api)
 	secureAPI.HandleFunc("/status", al.handleGetStatus).Methods(http.MethodGet)
+	secureAPI.HandleFunc("/capabilities", al.handleGetCapabilities).Methods(http.MethodGet)
+	secureAPI.Handle("/metrics", deprecatedByPrometheusMetrics(al.wrapAdminAccessMiddleware(http.HandlerFunc(al.handleGetMetrics)))).Methods(http.MethodGet)
 	secureAPI.HandleFunc("/me", al.handleGetMe).Methods(http.MethodGet)
 	secureAPI.HandleFunc("/me", al.handleChangeMe).Methods(http.MethodPut)
 	secureAPI.HandleFunc("/me/ip", al.handleGetIP).Methods(http.MethodGet)
+	secureAPI.HandleFunc("/me/capabilities", al.handleGetMeCapabilities).Methods(http.MethodGet)
+	secureAPI.HandleFunc("/me/locale", al.handleGetMeLocale).Methods(http.MethodGet)
+	secureAPI.HandleFunc("/me/locale", al.handlePutMeLocale).Methods(http.MethodPut)
+	secureAPI.HandleFunc("/me/feature-flags", al.handleGetMeFeatureFlags).Methods(http.MethodGet)
+	secureAPI.HandleFunc("/me/push-subscriptions", al.handleGetPushSubscriptions).Methods(http.MethodGet)
+	secureAPI.HandleFunc("/me/push-subscriptions", al.handlePostPushSubscription).Methods(http.MethodPost)
+	secureAPI.HandleFunc("/me/push-subscriptions/public-key", al.handleGetWebPushPublicKey).Methods(http.MethodGet)
+	secureAPI.HandleFunc("/me/push-subscriptions/{"+routes.ParamPushSubscriptionID+"}", al.handleDeletePushSubscription).Methods(http.MethodDelete)
 
 	secureAPI.HandleFunc("/me/token", al.handleTokenGone).Methods(http.MethodGet)
 	secureAPI.HandleFunc("/me/token", al.handleTokenGone).Methods(http.MethodPost)
@@ -52,13 +60,26 @@ func (al *APIListener) initRouter() {
 	secureAPI.HandleFunc("/me/tokens/{prefix}", al.handlePutToken).Methods(http.MethodPut)
 	secureAPI.HandleFunc("/me/tokens/{prefix}", al.handleDeleteToken).Methods(http.MethodDelete)
 
-	secureAPI.HandleFunc("/clients", al.handleGetClients).Methods(http.MethodGet)
+	secureAPI.Handle("/clients", deprecatedByV2(http.HandlerFunc(al.handleGetClients))).Methods(http.MethodGet)
+	secureAPI.Handle("/clients", al.wrapAdminAccessMiddleware(http.HandlerFunc(al.handleDeleteClients))).Methods(http.MethodDelete)
 	clientDetails := secureAPI.PathPrefix("/clients/{client_id}").Subrouter()
 	clientDetails.Use(al.wrapClientAccessMiddleware)
 	clientDetails.HandleFunc("", al.handleGetClient).Methods(http.MethodGet)
 	clientDetails.HandleFunc("", al.handleDeleteClient).Methods(http.MethodDelete)
 	clientDetails.Handle("/acl", al.wrapAdminAccessMiddleware(http.HandlerFunc(al.handlePostClientACL))).Methods(http.MethodPost)
+	clientDetails.Handle("/mode", al.wrapAdminAccessMiddleware(al.withActiveClient(http.HandlerFunc(al.handlePutClientMode)))).Methods(http.MethodPut)
+	clientDetails.Handle("/quarantine", al.wrapAdminAccessMiddleware(http.HandlerFunc(al.handlePutClientQuarantine))).Methods(http.MethodPut)
+	clientDetails.Handle("/quarantine", al.wrapAdminAccessMiddleware(http.HandlerFunc(al.handleDeleteClientQuarantine))).Methods(http.MethodDelete)
 	clientDetails.Handle("/scripts", al.permissionsMiddleware(users.PermissionScripts)(http.HandlerFunc(al.handleExecuteScript))).Methods(http.MethodPost)
+	clientDetails.HandleFunc("/interpreters", al.handleGetClientInterpreters).Methods(http.MethodGet)
+	clientDetails.HandleFunc("/address-changes", al.handleGetClientAddressChanges).Methods(http.MethodGet)
+	clientDetails.HandleFunc("/tunnel-closures", al.handleGetClientTunnelClosures).Methods(http.MethodGet)
+	clientDetails.HandleFunc("/security-snapshot", al.handleGetClientSecuritySnapshot).Methods(http.MethodGet)
+	clientDetails.HandleFunc("/watches", al.handlePostClientWatch).Methods(http.MethodPost)
+	clientDetails.HandleFunc("/feature-flags", al.handleGetClientFeatureFlags).Methods(http.MethodGet)
+	clientDetails.HandleFunc("/session-banner", al.handleGetClientSessionBanner).Methods(http.MethodGet)
+	clientDetails.Handle("/activity", al.wrapAdminAccessMiddleware(http.HandlerFunc(al.handleGetClientActivity))).Methods(http.MethodGet)
+	clientDetails.Handle("/interpreters", al.withActiveClient(http.HandlerFunc(al.handleRefreshClientInterpreters))).Methods(http.MethodPost)
 
 	clientAttributes := clientDetails.PathPrefix("/attributes").Subrouter()
 	clientAttributes.Use(al.withActiveClient)
@@ -70,38 +91,80 @@ func (al *APIListener) initRouter() {
 	clientCommands.HandleFunc("", al.handlePostCommand).Methods(http.MethodPost)
 	clientCommands.HandleFunc("", al.handleGetCommands).Methods(http.MethodGet)
 	clientCommands.HandleFunc("/{job_id}", al.handleGetCommand).Methods(http.MethodGet)
+	clientCaptures := clientDetails.PathPrefix("/captures").Subrouter()
+	clientCaptures.Use(al.permissionsMiddleware(users.PermissionCaptures))
+	clientCaptures.HandleFunc("", al.handlePostCapture).Methods(http.MethodPost)
+	clientCaptures.HandleFunc("/{job_id}", al.handleGetCapture).Methods(http.MethodGet)
+	clientCaptures.HandleFunc("/{job_id}", al.handleDeleteCapture).Methods(http.MethodDelete)
+
+	clientDetails.Handle("/job-stats", al.permissionsMiddleware(users.PermissionCommands)(http.HandlerFunc(al.handleGetClientJobStats))).Methods(http.MethodGet)
 
 	clientTunnels := clientDetails.NewRoute().Subrouter()
 	clientTunnels.Use(al.permissionsMiddleware(users.PermissionTunnels))
 	clientTunnels.HandleFunc("/tunnels", al.handlePutClientTunnel).Methods(http.MethodPut)
 	clientTunnels.HandleFunc("/tunnels/{tunnel_id}", al.handleDeleteClientTunnel).Methods(http.MethodDelete)
 	clientTunnels.HandleFunc("/tunnels/{tunnel_id}/acl", al.handlePutClientTunnelACL).Methods(http.MethodPut)
+	clientTunnels.HandleFunc("/tunnels/{tunnel_id}/connect", al.handleConnectClientTunnel).Methods(http.MethodConnect)
+	clientTunnels.HandleFunc("/tunnels/{tunnel_id}/shares", al.handleGetTunnelShares).Methods(http.MethodGet)
+	clientTunnels.HandleFunc("/tunnels/{tunnel_id}/shares", al.handlePostTunnelShare).Methods(http.MethodPost)
+	clientTunnels.HandleFunc("/tunnels/{tunnel_id}/shares/{"+routes.ParamTunnelShareID+"}", al.handleDeleteTunnelShare).Methods(http.MethodDelete)
 	clientTunnels.HandleFunc("/stored-tunnels", al.handleGetStoredTunnels).Methods(http.MethodGet)
 	clientTunnels.HandleFunc("/stored-tunnels", al.handlePostStoredTunnels).Methods(http.MethodPost)
 	clientTunnels.HandleFunc("/stored-tunnels/{tunnel_id}", al.handleDeleteStoredTunnel).Methods(http.MethodDelete)
 	clientTunnels.HandleFunc("/stored-tunnels/{tunnel_id}", al.handlePutStoredTunnel).Methods(http.MethodPut)
+	clientTunnels.HandleFunc("/reverse-tunnels", al.handleGetReverseTunnels).Methods(http.MethodGet)
+	clientTunnels.HandleFunc("/reverse-tunnels", al.handlePostReverseTunnel).Methods(http.MethodPost)
+	clientTunnels.HandleFunc("/reverse-tunnels/{"+routes.ParamReverseTunnelID+"}", al.handleDeleteReverseTunnel).Methods(http.MethodDelete)
 
 	clientMonitoring := clientDetails.NewRoute().Subrouter()
 	clientMonitoring.Use(al.permissionsMiddleware(users.PermissionMonitoring))
 	clientMonitoring.HandleFunc("/updates-status", al.handleRefreshUpdatesStatus).Methods(http.MethodPost)
+	clientMonitoring.HandleFunc("/snapshots", al.handleGetClientSnapshots).Methods(http.MethodGet)
+	clientMonitoring.HandleFunc("/snapshots", al.handlePostClientSnapshot).Methods(http.MethodPost)
+	clientMonitoring.HandleFunc("/snapshots/diff", al.handleGetClientSnapshotsDiff).Methods(http.MethodGet)
+	clientMonitoring.HandleFunc("/snapshots/{"+routes.ParamSnapshotID+"}", al.handleGetClientSnapshot).Methods(http.MethodGet)
 	if al.Server.config.Monitoring.Enabled {
 		clientMonitoring.HandleFunc("/graph-metrics", al.handleGetClientGraphMetrics).Methods(http.MethodGet)
 		clientMonitoring.HandleFunc("/graph-metrics/{"+routes.ParamGraphName+"}", al.handleGetClientGraphMetricsGraph).Methods(http.MethodGet)
 		clientMonitoring.HandleFunc("/metrics", al.handleGetClientMetrics).Methods(http.MethodGet)
 		clientMonitoring.HandleFunc("/processes", al.handleGetClientProcesses).Methods(http.MethodGet)
 		clientMonitoring.HandleFunc("/mountpoints", al.handleGetClientMountpoints).Methods(http.MethodGet)
+		clientMonitoring.HandleFunc(routes.CustomMetricsRoute, al.handlePostClientCustomMetrics).Methods(http.MethodPost)
+		clientMonitoring.HandleFunc(routes.CustomMetricsRoute, al.handleGetClientCustomMetrics).Methods(http.MethodGet)
+		clientMonitoring.HandleFunc("/graph-metrics/custom/{"+routes.ParamMetricName+"}", al.handleGetClientCustomMetricsGraph).Methods(http.MethodGet)
 	} else {
 		clientMonitoring.HandleFunc("/graph-metrics", al.handleMonitoringDisabled).Methods(http.MethodGet)
 		clientMonitoring.HandleFunc("/graph-metrics/{"+routes.ParamGraphName+"}", al.handleMonitoringDisabled).Methods(http.MethodGet)
 		clientMonitoring.HandleFunc("/metrics", al.handleMonitoringDisabled).Methods(http.MethodGet)
 		clientMonitoring.HandleFunc("/processes", al.handleMonitoringDisabled).Methods(http.MethodGet)
 		clientMonitoring.HandleFunc("/mountpoints", al.handleMonitoringDisabled).Methods(http.MethodGet)
+		clientMonitoring.HandleFunc(routes.CustomMetricsRoute, al.handleMonitoringDisabled).Methods(http.MethodPost, http.MethodGet)
+		clientMonitoring.HandleFunc("/graph-metrics/custom/{"+routes.ParamMetricName+"}", al.handleMonitoringDisabled).Methods(http.MethodGet)
 	}
 
 	secureAPI.HandleFunc("/client-tags", al.handleGetClientTags).Methods(http.MethodGet)
+	secureAPI.HandleFunc("/client-duplicates", al.handleGetClientDuplicates).Methods(http.MethodGet)
+	secureAPI.HandleFunc("/client-watches", al.handleGetClientWatches).Methods(http.MethodGet)
+	secureAPI.HandleFunc("/client-watches/{"+routes.ParamClientWatchID+"}", al.handleDeleteClientWatch).Methods(http.MethodDelete)
+	secureAPI.Handle("/client-updates-status/refresh", al.permissionsMiddleware(users.PermissionMonitoring)(http.HandlerFunc(al.handlePostUpdatesStatusRefresh))).Methods(http.MethodPost)
+
+	gatewayTarget := secureAPI.PathPrefix("/gateway-targets/{" + routes.ParamGatewayTargetID + "}").Subrouter()
+	gatewayTarget.Use(al.withGatewayTarget, al.wrapClientAccessMiddleware)
+	gatewayTarget.Handle("/tunnels", al.permissionsMiddleware(users.PermissionTunnels)(http.HandlerFunc(al.handlePutGatewayTargetTunnel))).Methods(http.MethodPut)
+	gatewayTarget.Handle("/commands", al.permissionsMiddleware(users.PermissionCommands)(http.HandlerFunc(al.handlePostGatewayTargetCommand))).Methods(http.MethodPost)
 
 	secureAPI.Handle("/tunnels", al.permissionsMiddleware(users.PermissionTunnels)(http.HandlerFunc(al.handleGetTunnels))).Methods(http.MethodGet)
+	secureAPI.HandleFunc("/shared-tunnels", al.handleGetSharedTunnels).Methods(http.MethodGet)
+	secureAPI.HandleFunc("/shared-tunnels/{"+routes.ParamTunnelShareID+"}/access", al.handlePostSharedTunnelAccess).Methods(http.MethodPost)
+	pendingTunnels := secureAPI.PathPrefix("/pending-tunnels").Subrouter()
+	pendingTunnels.Use(al.permissionsMiddleware(users.PermissionTunnels))
+	pendingTunnels.HandleFunc("", al.handleGetPendingTunnels).Methods(http.MethodGet)
+	pendingTunnels.HandleFunc("/{"+routes.ParamPendingTunnel+"}/approve", al.handlePostPendingTunnelApproval).Methods(http.MethodPost)
+	pendingTunnels.HandleFunc("/{"+routes.ParamPendingTunnel+"}", al.handleDeletePendingTunnel).Methods(http.MethodDelete)
 	secureAPI.Handle("/auditlog", al.permissionsMiddleware(users.PermissionsAuditLog)(http.HandlerFunc(al.handleListAuditLog))).Methods(http.MethodGet)
+	secureAPI.Handle("/session-recordings", al.permissionsMiddleware(users.PermissionsAuditLog)(http.HandlerFunc(al.handleListSessionRecordings))).Methods(http.MethodGet)
+	secureAPI.Handle("/session-recordings/{"+routes.ParamRecordingID+"}", al.permissionsMiddleware(users.PermissionsAuditLog)(http.HandlerFunc(al.handleGetSessionRecording))).Methods(http.MethodGet)
+	secureAPI.Handle("/session-recordings/{"+routes.ParamRecordingID+"}/playback", al.permissionsMiddleware(users.PermissionsAuditLog)(http.HandlerFunc(al.handleGetSessionRecordingPlayback))).Methods(http.MethodGet)
 	secureAPI.Handle("/files", al.permissionsMiddleware(users.PermissionUploads)(http.HandlerFunc(al.handleFileUploads))).Methods(http.MethodPost).Name(routes.FilesUploadRouteName)
 
 	secureAPI.HandleFunc("/client-groups", al.handleGetClientGroups).Methods(http.MethodGet)
@@ -109,9 +172,44 @@ func (al *APIListener) initRouter() {
 
 	adminOnly := secureAPI.NewRoute().Subrouter()
 	adminOnly.Use(al.wrapAdminAccessMiddleware)
+	adminOnly.HandleFunc("/client-changes", al.handleGetClientChanges).Methods(http.MethodGet)
+	adminOnly.HandleFunc("/capacity", al.handleGetCapacity).Methods(http.MethodGet)
+	adminOnly.HandleFunc("/capacity/history", al.handleGetCapacityHistory).Methods(http.MethodGet)
+	adminOnly.HandleFunc("/security-events/summary", al.handleGetSecurityEventsSummary).Methods(http.MethodGet)
+	adminOnly.HandleFunc("/client-connection-failures", al.handleListClientConnectionFailures).Methods(http.MethodGet)
+	adminOnly.HandleFunc("/bandwidth", al.handleGetBandwidthReport).Methods(http.MethodGet)
+	adminOnly.HandleFunc("/bandwidth/daily", al.handleGetBandwidthDaily).Methods(http.MethodGet)
+	adminOnly.HandleFunc("/maintenance", al.handleGetMaintenance).Methods(http.MethodGet)
+	adminOnly.HandleFunc("/maintenance/run", al.handlePostMaintenanceRun).Methods(http.MethodPost)
+	adminOnly.HandleFunc("/maintenance/freeze", al.handleGetMaintenanceFreeze).Methods(http.MethodGet)
+	adminOnly.HandleFunc("/maintenance/freeze", al.handlePutMaintenanceFreeze).Methods(http.MethodPut)
+	adminOnly.HandleFunc("/maintenance/freeze", al.handleDeleteMaintenanceFreeze).Methods(http.MethodDelete)
+	adminOnly.HandleFunc("/bootstrap", al.handlePostBootstrap).Methods(http.MethodPost)
+	adminOnly.HandleFunc("/feature-flags", al.handleGetFeatureFlags).Methods(http.MethodGet)
+	adminOnly.HandleFunc("/feature-flags/{"+routes.ParamFeatureFlag+"}", al.handleGetFeatureFlag).Methods(http.MethodGet)
+	adminOnly.HandleFunc("/feature-flags/{"+routes.ParamFeatureFlag+"}", al.handlePutFeatureFlag).Methods(http.MethodPut)
+	adminOnly.HandleFunc("/feature-flags/{"+routes.ParamFeatureFlag+"}", al.handleDeleteFeatureFlag).Methods(http.MethodDelete)
+	adminOnly.HandleFunc("/monitoring-profiles", al.handleGetMonitoringProfiles).Methods(http.MethodGet)
+	adminOnly.HandleFunc("/monitoring-profiles/push", al.handlePostMonitoringProfilesPush).Methods(http.MethodPost)
+	adminOnly.HandleFunc("/gateway-targets", al.handleGetGatewayTargets).Methods(http.MethodGet)
+	adminOnly.HandleFunc("/gateway-targets", al.handlePostGatewayTargets).Methods(http.MethodPost)
+	adminOnly.HandleFunc("/gateway-targets/{"+routes.ParamGatewayTargetID+"}", al.handleGetGatewayTarget).Methods(http.MethodGet)
+	adminOnly.HandleFunc("/gateway-targets/{"+routes.ParamGatewayTargetID+"}", al.handlePutGatewayTarget).Methods(http.MethodPut)
+	adminOnly.HandleFunc("/gateway-targets/{"+routes.ParamGatewayTargetID+"}", al.handleDeleteGatewayTarget).Methods(http.MethodDelete)
+	adminOnly.HandleFunc("/reports", al.handleGetReports).Methods(http.MethodGet)
+	adminOnly.HandleFunc("/reports", al.handlePostReports).Methods(http.MethodPost)
+	adminOnly.HandleFunc("/reports/{"+routes.ParamReportID+"}", al.handleGetReport).Methods(http.MethodGet)
+	adminOnly.HandleFunc("/reports/{"+routes.ParamReportID+"}", al.handlePutReport).Methods(http.MethodPut)
+	adminOnly.HandleFunc("/reports/{"+routes.ParamReportID+"}", al.handleDeleteReport).Methods(http.MethodDelete)
+	adminOnly.HandleFunc("/reports/{"+routes.ParamReportID+"}/run", al.handlePostReportRun).Methods(http.MethodPost)
+	adminOnly.HandleFunc("/reports/{"+routes.ParamReportID+"}/runs", al.handleGetReportRuns).Methods(http.MethodGet)
+	adminOnly.HandleFunc("/reports/{"+routes.ParamReportID+"}/runs/{"+routes.ParamReportRunID+"}/download", al.handleGetReportRunDownload).Methods(http.MethodGet)
 	adminOnly.HandleFunc("/client-groups", al.handlePostClientGroups).Methods(http.MethodPost)
 	adminOnly.HandleFunc("/client-groups/{group_id}", al.handlePutClientGroup).Methods(http.MethodPut)
 	adminOnly.HandleFunc("/client-groups/{group_id}", al.handleDeleteClientGroup).Methods(http.MethodDelete)
+	adminOnly.HandleFunc("/client-groups/{group_id}/history", al.handleGetClientGroupHistory).Methods(http.MethodGet)
+	adminOnly.HandleFunc("/client-groups/{group_id}/history/{"+routes.ParamGroupHistoryID+"}", al.handleGetClientGroupHistoryEntry).Methods(http.MethodGet)
+	adminOnly.HandleFunc("/client-groups/{group_id}/history/{"+routes.ParamGroupHistoryID+"}/revert", al.handlePostClientGroupRevert).Methods(http.MethodPost)
 	adminOnly.HandleFunc("/users", al.wrapStaticPassModeMiddleware(al.handleGetUsers)).Methods(http.MethodGet)
 	adminOnly.HandleFunc("/users", al.wrapStaticPassModeMiddleware(al.handleChangeUser)).Methods(http.MethodPost)
 	adminOnly.HandleFunc("/users/{user_id}", al.wrapStaticPassModeMiddleware(al.handleChangeUser)).Methods(http.MethodPut)
@@ -129,8 +227,9 @@ func (al *APIListener) initRouter() {
 	adminOnly.HandleFunc("/user-groups/{group_name}", al.wrapStaticPassModeMiddleware(al.handleUpdateUserGroup)).Methods(http.MethodPut)
 	adminOnly.HandleFunc("/user-groups/{group_name}", al.wrapStaticPassModeMiddleware(al.handleDeleteUserGroup)).Methods(http.MethodDelete)
 
-	adminOnly.HandleFunc("/clients-auth", al.handleGetClientsAuth).Methods(http.MethodGet)
-	adminOnly.HandleFunc("/clients-auth/{client_auth_id}", al.handleGetClientAuth).Methods(http.MethodGet)
+	adminOnly.Handle("/clients-auth", al.wrapNoAuditorAccessMiddleware(http.HandlerFunc(al.handleGetClientsAuth))).Methods(http.MethodGet)
+	adminOnly.Handle("/clients-auth/{client_auth_id}", al.wrapNoAuditorAccessMiddleware(http.HandlerFunc(al.handleGetClientAuth))).Methods(http.MethodGet)
+	adminOnly.HandleFunc("/clients-auth/{client_auth_id}/diagnostics", al.handleGetClientAuthDiagnostics).Methods(http.MethodGet)
 	adminOnly.HandleFunc("/clients-auth", al.handlePostClientsAuth).Methods(http.MethodPost)
 	adminOnly.HandleFunc("/clients-auth/{client_auth_id}", al.handleDeleteClientAuth).Methods(http.MethodDelete)
 
@@ -143,6 +242,9 @@ func (al *APIListener) initRouter() {
 	commands.HandleFunc("/commands", al.handleGetMultiClientCommands).Methods(http.MethodGet)
 	commands.HandleFunc("/commands/{job_id}", al.handleGetMultiClientCommand).Methods(http.MethodGet)
 	commands.HandleFunc("/commands/{job_id}/jobs", al.handleGetMultiClientCommandJobs).Methods(http.MethodGet)
+	commands.HandleFunc("/commands/{job_id}/status", al.handleGetMultiClientCommandStatus).Methods(http.MethodGet)
+	commands.HandleFunc("/commands/{job_id}/summary", al.handleGetMultiClientCommandSummary).Methods(http.MethodGet)
+	commands.HandleFunc("/job-stats", al.handleGetJobStats).Methods(http.MethodGet)
 	commands.HandleFunc("/library/commands", al.handleListCommands).Methods(http.MethodGet)
 	commands.HandleFunc("/library/commands", al.handleCommandCreate).Methods(http.MethodPost)
 	commands.HandleFunc("/library/commands/{"+routes.ParamCommandValueID+"}", al.handleCommandUpdate).Methods(http.MethodPut)
@@ -156,7 +258,16 @@ func (al *APIListener) initRouter() {
 	scripts.HandleFunc("/library/scripts/{"+routes.ParamScriptValueID+"}", al.handleScriptUpdate).Methods(http.MethodPut)
 	scripts.HandleFunc("/library/scripts/{"+routes.ParamScriptValueID+"}", al.handleReadScript).Methods(http.MethodGet)
 	scripts.HandleFunc("/library/scripts/{"+routes.ParamScriptValueID+"}", al.handleDeleteScript).Methods(http.MethodDelete)
+	scripts.HandleFunc("/library/scripts/{"+routes.ParamScriptValueID+"}/materialize", al.handleMaterializeScript).Methods(http.MethodPost)
 	scripts.HandleFunc("/scripts", al.handlePostMultiClientScript).Methods(http.MethodPost)
+	scripts.HandleFunc("/runbook-remediations", al.handleListRunbookRemediations).Methods(http.MethodGet)
+
+	runbooks := secureAPI.PathPrefix("/runbooks").Subrouter()
+	runbooks.Use(al.permissionsMiddleware(users.PermissionScripts))
+	runbooks.HandleFunc("", al.handleListRunbooks).Methods(http.MethodGet)
+	runbooks.HandleFunc("/{runbook_id}", al.handleGetRunbook).Methods(http.MethodGet)
+	runbooks.HandleFunc("/{runbook_id}", al.handleDeleteRunbook).Methods(http.MethodDelete)
+	runbooks.HandleFunc("/{runbook_id}/execute", al.handleExecuteRunbook).Methods(http.MethodPost)
 
 	vault := secureAPI.NewRoute().Subrouter()
 	vault.Use(al.permissionsMiddleware(users.PermissionVault))
@@ -166,7 +277,7 @@ func (al *APIListener) initRouter() {
 	vault.Handle("/vault-admin/sesame", al.wrapAdminAccessMiddleware(http.HandlerFunc(al.handleVaultLock))).Methods(http.MethodDelete)
 	vault.HandleFunc("/vault", al.handleListVaultValues).Methods(http.MethodGet)
 	vault.HandleFunc("/vault", al.handleVaultStoreValue).Methods(http.MethodPost)
-	vault.HandleFunc("/vault/{"+routes.ParamVaultValueID+"}", al.handleReadVaultValue).Methods(http.MethodGet)
+	vault.Handle("/vault/{"+routes.ParamVaultValueID+"}", al.wrapNoAuditorAccessMiddleware(http.HandlerFunc(al.handleReadVaultValue))).Methods(http.MethodGet)
 	vault.HandleFunc("/vault/{"+routes.ParamVaultValueID+"}", al.handleVaultStoreValue).Methods(http.MethodPut)
 	vault.HandleFunc("/vault/{"+routes.ParamVaultValueID+"}", al.handleVaultDeleteValue).Methods(http.MethodDelete)
 
@@ -190,9 +301,14 @@ func (al *APIListener) initRouter() {
 
 	// web sockets
 	// common auth middleware is not used due to JS issue https://stackoverflow.com/questions/22383089/is-it-possible-to-use-bearer-authentication-for-websocket-upgrade-requests
-	api.HandleFunc("/ws/commands", al.wsAuth(al.permissionsMiddleware(users.PermissionCommands)(http.HandlerFunc(al.handleCommandsWS)))).Methods(http.MethodGet)
-	api.HandleFunc("/ws/scripts", al.wsAuth(al.permissionsMiddleware(users.PermissionScripts)(http.HandlerFunc(al.handleScriptsWS)))).Methods(http.MethodGet)
-	api.HandleFunc("/ws/uploads", al.wsAuth(al.permissionsMiddleware(users.PermissionUploads)(http.HandlerFunc(al.handleUploadsWS)))).Methods(http.MethodGet)
+	api.HandleFunc("/ws/commands", al.wsAuth(al.wrapWithPolicyIfEnabled(al.wrapAPIFreezeMiddleware(al.wrapAuditorReadOnlyMiddleware(al.permissionsMiddleware(users.PermissionCommands)(http.HandlerFunc(al.handleCommandsWS))))))).Methods(http.MethodGet)
+	api.HandleFunc("/ws/scripts", al.wsAuth(al.wrapWithPolicyIfEnabled(al.wrapAPIFreezeMiddleware(al.wrapAuditorReadOnlyMiddleware(al.permissionsMiddleware(users.PermissionScripts)(http.HandlerFunc(al.handleScriptsWS))))))).Methods(http.MethodGet)
+	api.HandleFunc("/ws/clients", al.wsAuth(al.wrapWithPolicyIfEnabled(http.HandlerFunc(al.handleClientsWS)))).Methods(http.MethodGet)
+	api.HandleFunc("/ws/uploads", al.wsAuth(al.wrapWithPolicyIfEnabled(al.wrapAPIFreezeMiddleware(al.wrapAuditorReadOnlyMiddleware(al.permissionsMiddleware(users.PermissionUploads)(http.HandlerFunc(al.handleUploadsWS))))))).Methods(http.MethodGet)
+
+	if al.config.API.PublicStatusEnabled {
+		api.HandleFunc("/status/public", al.handleGetPublicStatus).Methods(http.MethodGet)
+	}
 
 	if al.config.API.EnableWsTestEndpoints {
 		api.HandleFunc("/test/commands/ui", al.wsCommands)
@@ -205,14 +321,20 @@ func (al *APIListener) initRouter() {
 	}
 
 	// add max bytes middleware
-	_ = api.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
-		if route.GetName() == routes.FilesUploadRouteName {
-			route.HandlerFunc(middleware.MaxBytes(route.GetHandler(), al.config.API.MaxFilePushSize))
-		} else {
-			route.HandlerFunc(middleware.MaxBytes(route.GetHandler(), al.config.API.MaxRequestBytes))
-		}
-		return nil
-	})
+	for _, versionRouter := range []*mux.Router{api, apiV2} {
+		_ = versionRouter.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+			if route.GetName() == routes.FilesUploadRouteName {
+				route.HandlerFunc(middleware.MaxBytes(route.GetHandler(), al.config.API.MaxFilePushSize))
+				return nil
+			}
+			maxBytes := al.config.API.MaxRequestBytes
+			if pathTemplate, err := route.GetPathTemplate(); err == nil {
+				maxBytes = al.config.API.MaxRequestBytesForRoute(routes.TrimVersionPrefix(pathTemplate))
+			}
+			route.HandlerFunc(middleware.MaxBytes(route.GetHandler(), maxBytes))
+			return nil
+		})
+	}
 
 	plusRouter := api.PathPrefix("/plus").Subrouter()
 	plusRouter.HandleFunc("/status", al.handlePlusStatus).Methods(http.MethodGet)
@@ -243,6 +365,9 @@ func (al *APIListener) initRouter() {
 
 		secureASRouter.Handle(routes.ASTemplatesRoute, al.wrapAdminAccessMiddleware(http.HandlerFunc(al.handleSaveTemplate))).Methods(http.MethodPost)
 		secureASRouter.Handle(routes.ASTemplatesRoute+"/{"+routes.ParamTemplateID+"}", al.wrapAdminAccessMiddleware(http.HandlerFunc(al.handleSaveTemplate))).Methods(http.MethodPut)
+		secureASRouter.Handle(routes.ASTemplatesRoute+routes.ASRenderRoute, al.wrapAdminAccessMiddleware(http.HandlerFunc(al.handleRenderTemplate))).Methods(http.MethodPost)
+		secureASRouter.Handle(routes.ASTemplatesRoute+"/{"+routes.ParamTemplateID+"}"+routes.ASRenderRoute,
+			al.wrapAdminAccessMiddleware(http.HandlerFunc(al.handleRenderTemplate))).Methods(http.MethodPost)
 	}
 
 	if rportplus.IsPlusOAuthEnabled(al.config.PlusConfig) {
@@ -250,14 +375,28 @@ func (al *APIListener) initRouter() {
 		api.HandleFunc(oauth.DefaultDeviceLoginURI, al.handleGetDeviceAuth).Methods(http.MethodGet)
 	}
 
+	if al.config.API.HealthProbesEnabled {
+		// probes are served outside of the api prefix at the paths expected by orchestrators and load balancers
+		r.HandleFunc(healthzRoute, al.handleGetHealthz).Methods(http.MethodGet, http.MethodHead)
+		r.HandleFunc(readyzRoute, al.handleGetReadyz).Methods(http.MethodGet, http.MethodHead)
+	}
+
+	if al.config.API.PrometheusMetricsEnabled {
+		// served outside of the api prefix at the path expected by Prometheus, authenticated like the api
+		prometheus := al.newSecureRouter(r.PathPrefix(prometheusMetricsRoute).Subrouter())
+		prometheus.Handle("", al.wrapAdminAccessMiddleware(http.HandlerFunc(al.handleGetPrometheusMetrics))).Methods(http.MethodGet)
+	}
+
 	docRoot := al.config.API.DocRoot
 	if docRoot != "" {
 		// Start a http file server with proper Vue.js HTML5 history mode (aka rewrite to /) for the following paths
 		r.PathPrefix("/").Handler(middleware.Rewrite404ForVueJs(http.FileServer(http.Dir(docRoot)), vueHistoryPaths))
 	}
 
+	r.Use(middleware.RequestID)
+	r.Use(middleware.RequestMetrics)
 	if al.requestLogOptions != nil {
-		r.Use(func(next http.Handler) http.Handler { return requestlog.WrapWith(next, *al.requestLogOptions) })
+		r.Use(middleware.RequestLog(*al.requestLogOptions))
 	}
 	if al.accessLogFile != nil {
 		r.Use(func(next http.Handler) http.Handler { return handlers.CombinedLoggingHandler(al.accessLogFile, next) })
@@ -274,11 +413,18 @@ func (al *APIListener) initRouter() {
 				http.MethodPut,
 				http.MethodDelete,
 			},
-			AllowedHeaders: []string{"Authorization", "Content-Type"},
+			AllowedHeaders: []string{"Authorization", "Content-Type", middleware.RequestIDHeader},
+			ExposedHeaders: []string{
+				middleware.RequestIDHeader,
+				middleware.DeprecationHeader,
+				middleware.SunsetHeader,
+				middleware.LinkHeader,
+			},
 		}).Handler)
 	}
 
-	r.Use(handlers.CompressHandler)
+	r.Use(middleware.Compress(al.config.API.Compression, al.config.API.CompressionMinSize))
+	r.Use(middleware.Localize(al.config.Server.DefaultLocale))
 	r.Use(handlers.RecoveryHandler(
 		handlers.PrintRecoveryStack(true),
 		handlers.RecoveryLogger(middleware.NewRecoveryLogger(al.Logger)),
@@ -286,3 +432,17 @@ func (al *APIListener) initRouter() {
 
 	al.router = r
 }
+
+// newSecureRouter returns a subrouter of the API version router with the authentication and the other middlewares
+// shared by all API versions.
+func (al *APIListener) newSecureRouter(api *mux.Router) *mux.Router {
+	secureAPI := api.NewRoute().Subrouter()
+	if !al.insecureForTests {
+		secureAPI.Use(al.wrapWithAuthMiddleware(false))
+	}
+	secureAPI.Use(al.wrapWithPolicyIfEnabled)
+	secureAPI.Use(al.wrapUserLocaleMiddleware)
+	secureAPI.Use(al.wrapAuditorReadOnlyMiddleware)
+	secureAPI.Use(al.wrapAPIFreezeMiddleware)
+	return secureAPI
+}
@@ -0,0 +1,27 @@
+package chserver
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/realvnc-labs/rport/server/api/middleware"
+	"github.com/realvnc-labs/rport/server/routes"
+)
+
+// v2Since is the date the v2 API was introduced, v1 routes replaced by v2 routes are deprecated since then.
+var v2Since = time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+
+// deprecatedByV2 announces the deprecation of a v1 route with a v2 counterpart, the v1 route stays available.
+var deprecatedByV2 = middleware.Deprecated(middleware.Deprecation{
+	Since:           v2Since,
+	Prefix:          routes.AllRoutesPrefix,
+	SuccessorPrefix: routes.V2RoutesPrefix,
+})
+
+// initV2Routes registers the routes of the v2 API. The v2 handlers share the services with their v1 counterparts,
+// they differ in the format of the responses only. Routes without breaking changes are not duplicated in v2.
+func (al *APIListener) initV2Routes(secureAPI *mux.Router) {
+	secureAPI.HandleFunc("/clients", al.handleGetClientsV2).Methods(http.MethodGet)
+}
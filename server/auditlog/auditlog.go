@@ -14,6 +14,7 @@ import (
 	"github.com/realvnc-labs/rport/db/sqlite"
 
 	"github.com/realvnc-labs/rport/server/api"
+	errors2 "github.com/realvnc-labs/rport/server/api/errors"
 	"github.com/realvnc-labs/rport/server/clients/clientdata"
 	"github.com/realvnc-labs/rport/share/logger"
 	"github.com/realvnc-labs/rport/share/query"
@@ -32,6 +33,8 @@ var (
 		"affected_id":      true,
 		"client_id":        true,
 		"client_hostname":  true,
+		"labels":           true,
+		"request_id":       true,
 	}
 	supportedSorts = map[string]bool{
 		"timestamp":       true,
@@ -42,9 +45,14 @@ var (
 		"affected_id":     true,
 		"client_id":       true,
 		"client_hostname": true,
+		"labels":          true,
+		"request_id":      true,
 	}
 )
 
+// timestampFilterLayout matches the format of the stored timestamps for string comparison.
+const timestampFilterLayout = "2006-01-02 15:04:05"
+
 type ClientGetter interface {
 	GetByID(id string) (*clientdata.Client, error)
 }
@@ -71,7 +79,7 @@ func (e *NotAllowedError) Error() string {
 	return e.Msg
 }
 
-func New(l *logger.Logger, cg ClientGetter, dataDir string, cfg config.Config, dataSourceOptions sqlite.DataSourceOptions) (*AuditLog, error) {
+func New(l *logger.Logger, cg ClientGetter, dataDir string, cfg config.Config, dataSourceOptions sqlite.DataSourceOptions, readReplicaDir string) (*AuditLog, error) {
 	a := &AuditLog{
 		logger:       l,
 		clientGetter: cg,
@@ -84,6 +92,7 @@ func New(l *logger.Logger, cg ClientGetter, dataDir string, cfg config.Config, d
 			cfg.RotationPeriod(),
 			dataDir,
 			dataSourceOptions,
+			readReplicaDir,
 		)
 		if err != nil {
 			return nil, err
@@ -135,10 +144,106 @@ func (a *AuditLog) savePreparedEntry(e *Entry) error {
 	return a.provider.Save(e)
 }
 
+// Enabled returns true if entries are stored.
+func (a *AuditLog) Enabled() bool {
+	return a != nil && a.provider != nil
+}
+
+// ListClientEntries returns up to limit entries of the client since the given time, the latest first.
+func (a *AuditLog) ListClientEntries(ctx context.Context, clientID string, since time.Time, limit int) ([]*Entry, error) {
+	if !a.Enabled() {
+		return nil, nil
+	}
+
+	options := &query.ListOptions{
+		Filters: []query.FilterOption{
+			{
+				Column: []string{"client_id"},
+				Values: []string{clientID},
+			},
+			{
+				Column:   []string{"timestamp"},
+				Operator: query.FilterOperatorTypeSince,
+				Values:   []string{since.Format(timestampFilterLayout)},
+			},
+		},
+		Sorts:      []query.SortOption{{Column: "timestamp", IsASC: false}},
+		Pagination: query.NewPagination(limit, 0),
+	}
+	return a.provider.List(ctx, options)
+}
+
 func (a *AuditLog) List(r *http.Request, user *users.User) (*api.SuccessPayload, error) {
+	options, err := a.listOptions(r, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if cursor, ok := r.URL.Query()[CursorQueryParam]; ok {
+		return a.listByCursor(r.Context(), options, cursor[0])
+	}
+
+	entries, err := a.provider.List(r.Context(), options)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := a.provider.Count(r.Context(), options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.SuccessPayload{
+		Data: entries,
+		Meta: api.NewMeta(count),
+	}, nil
+}
+
+// listByCursor returns a page of entries after the cursor. The total count is skipped as it requires a scan of all
+// matching entries, meta count is the number of entries of the page.
+func (a *AuditLog) listByCursor(ctx context.Context, options *query.ListOptions, cursor string) (*api.SuccessPayload, error) {
+	if options.Pagination.ValidatedOffset != 0 {
+		return nil, errors2.APIError{
+			Message:    "pagination offset cannot be combined with a cursor",
+			HTTPStatus: http.StatusBadRequest,
+		}
+	}
+	asc, err := keysetOrder(options)
+	if err != nil {
+		return nil, err
+	}
+	after, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, errors2.APIError{
+			Message:    "invalid cursor",
+			Err:        err,
+			HTTPStatus: http.StatusBadRequest,
+		}
+	}
+
+	limit := options.Pagination.ValidatedLimit
+	entries, err := a.provider.List(ctx, keysetOptions(options, after, asc, limit))
+	if err != nil {
+		return nil, err
+	}
+
+	meta := api.NewMeta(len(entries))
+	if len(entries) == limit {
+		meta.NextCursor = encodeCursor(entries[len(entries)-1].RowID)
+	}
+
+	return &api.SuccessPayload{
+		Data: entries,
+		Meta: meta,
+	}, nil
+}
+
+// listOptions returns the validated list options of the request, users without read access to all entries see
+// their own entries only.
+func (a *AuditLog) listOptions(r *http.Request, user *users.User) (*query.ListOptions, error) {
 	options := query.GetListOptions(r)
-	if !user.IsAdmin() {
-		// Deny none-admins looking for foreign audit logs
+	if !user.CanReadAll() {
+		// Deny users without read access to all logs looking for foreign audit logs
 		for _, v := range options.Filters {
 			for _, col := range v.Column {
 				if col == "username" {
@@ -160,18 +265,5 @@ func (a *AuditLog) List(r *http.Request, user *users.User) (*api.SuccessPayload,
 		return nil, err
 	}
 
-	entries, err := a.provider.List(r.Context(), options)
-	if err != nil {
-		return nil, err
-	}
-
-	count, err := a.provider.Count(r.Context(), options)
-	if err != nil {
-		return nil, err
-	}
-
-	return &api.SuccessPayload{
-		Data: entries,
-		Meta: api.NewMeta(count),
-	}, nil
+	return options, nil
 }
@@ -1,8 +1,13 @@
 package auditlog
 
 import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/realvnc-labs/rport/server/api/users"
 	"github.com/realvnc-labs/rport/server/auditlog/config"
@@ -21,7 +26,7 @@ func TestNotEnabled(t *testing.T) {
 	req := httptest.NewRequest("GET", "/", nil)
 
 	mockProvider := &mockProvider{}
-	auditLog, err := New(nil, nil, "", config.Config{Enable: false}, DataSourceOptions)
+	auditLog, err := New(nil, nil, "", config.Config{Enable: false}, DataSourceOptions, "")
 	require.NoError(t, err)
 	auditLog.provider = mockProvider
 
@@ -132,7 +137,8 @@ func TestList(t *testing.T) {
 	db, err := sqlite.New(":memory:", auditlog.AssetNames(), auditlog.Asset, DataSourceOptions)
 	require.NoError(t, err)
 	dbProv := &SQLiteProvider{
-		db: db,
+		db:     db,
+		reader: sqlite.NewReadReplica(db, nil, nil),
 	}
 	auditLog := &AuditLog{
 		config: config.Config{
@@ -166,3 +172,171 @@ func TestList(t *testing.T) {
 	}
 
 }
+
+func TestListClientEntries(t *testing.T) {
+	db, err := sqlite.New(":memory:", auditlog.AssetNames(), auditlog.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	auditLog := &AuditLog{
+		config: config.Config{
+			Enable: true,
+		},
+		provider: &SQLiteProvider{
+			db:     db,
+			reader: sqlite.NewReadReplica(db, nil, nil),
+		},
+	}
+	defer auditLog.Close()
+
+	old := auditLog.Entry(ApplicationClientTunnel, ActionCreate)
+	old.ClientID = "client-1"
+	old.Timestamp = time.Now().Add(-48 * time.Hour)
+	old.Save()
+	for _, cid := range []string{"client-1", "client-1", "client-2"} {
+		e := auditLog.Entry(ApplicationClientTunnel, ActionCreate)
+		e.ClientID = cid
+		e.Save()
+	}
+
+	entries, err := auditLog.ListClientEntries(context.Background(), "client-1", time.Now().Add(-time.Hour), 10)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	entries, err = auditLog.ListClientEntries(context.Background(), "client-1", time.Now().Add(-72*time.Hour), 1)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	var disabled *AuditLog
+	assert.False(t, disabled.Enabled())
+	entries, err = disabled.ListClientEntries(context.Background(), "client-1", time.Now(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestListByCursor(t *testing.T) {
+	db, err := sqlite.New(":memory:", auditlog.AssetNames(), auditlog.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	auditLog := &AuditLog{
+		config: config.Config{
+			Enable: true,
+		},
+		provider: &SQLiteProvider{
+			db:     db,
+			reader: sqlite.NewReadReplica(db, nil, nil),
+		},
+	}
+	defer auditLog.Close()
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 12; i++ {
+		e := auditLog.Entry(ApplicationClientTunnel, ActionCreate)
+		e.Timestamp = start.Add(time.Duration(i) * time.Minute)
+		e.ID = fmt.Sprint(i)
+		if i%3 == 0 {
+			e.Action = ActionDelete
+		}
+		e.Save()
+	}
+	admin := &users.User{Username: "admin", Groups: []string{users.Administrators}}
+
+	listIDs := func(params string) ([]string, string) {
+		r := httptest.NewRequest("GET", "/auditlog?"+params, nil)
+		result, err := auditLog.List(r, admin)
+		require.NoError(t, err)
+		var ids []string
+		for _, e := range result.Data.([]*Entry) {
+			ids = append(ids, e.ID)
+		}
+		assert.Equal(t, len(ids), result.Meta.Count)
+		return ids, result.Meta.NextCursor
+	}
+
+	// latest first by default
+	ids, cursor := listIDs("page[limit]=5&page[cursor]=")
+	assert.Equal(t, []string{"11", "10", "9", "8", "7"}, ids)
+	require.NotEmpty(t, cursor)
+
+	ids, cursor = listIDs("page[limit]=5&page[cursor]=" + cursor)
+	assert.Equal(t, []string{"6", "5", "4", "3", "2"}, ids)
+
+	ids, cursor = listIDs("page[limit]=5&page[cursor]=" + cursor)
+	assert.Equal(t, []string{"1", "0"}, ids)
+	assert.Empty(t, cursor)
+
+	// with filters and ascending
+	ids, cursor = listIDs("filter[action]=delete&sort=timestamp&page[limit]=2&page[cursor]=")
+	assert.Equal(t, []string{"0", "3"}, ids)
+	ids, cursor = listIDs("filter[action]=delete&sort=timestamp&page[limit]=2&page[cursor]=" + cursor)
+	assert.Equal(t, []string{"6", "9"}, ids)
+	ids, _ = listIDs("filter[action]=delete&sort=timestamp&page[limit]=2&page[cursor]=" + cursor)
+	assert.Empty(t, ids)
+
+	// with time range
+	ids, _ = listIDs("filter[timestamp][since]=2022-01-01%2000:03:00&filter[timestamp][until]=2022-01-01%2000:05:30&page[cursor]=")
+	assert.Equal(t, []string{"5", "4", "3"}, ids)
+
+	for _, params := range []string{
+		"page[cursor]=invalid",
+		"sort=username&page[cursor]=",
+		"page[offset]=5&page[cursor]=",
+	} {
+		r := httptest.NewRequest("GET", "/auditlog?"+params, nil)
+		_, err := auditLog.List(r, admin)
+		assert.Error(t, err, params)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	db, err := sqlite.New(":memory:", auditlog.AssetNames(), auditlog.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	auditLog := &AuditLog{
+		config: config.Config{
+			Enable: true,
+		},
+		provider: &SQLiteProvider{
+			db:     db,
+			reader: sqlite.NewReadReplica(db, nil, nil),
+		},
+	}
+	defer auditLog.Close()
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	total := exportBatchSize + 5
+	for i := 0; i < total; i++ {
+		e := auditLog.Entry(ApplicationClientCommand, ActionCreate)
+		e.Timestamp = start.Add(time.Duration(i) * time.Second)
+		e.Username = "admin"
+		if i == 0 {
+			e.Username = "user"
+			e.Request = `{"command":"echo \"a,b\""}`
+		}
+		e.Save()
+	}
+
+	r := httptest.NewRequest("GET", "/auditlog?format=csv&page[limit]=1", nil)
+	export, err := auditLog.NewExport(r, &users.User{Username: "admin", Groups: []string{users.Administrators}})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteCSV(context.Background(), &buf))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, total+1)
+	assert.Equal(t, csvHeader, records[0])
+	assert.Equal(t, "2022-01-01T00:16:44Z", records[1][0])
+	last := records[total]
+	assert.Equal(t, []string{"2022-01-01T00:00:00Z", "user", "", ApplicationClientCommand, ActionCreate, "", "", "", `{"command":"echo \"a,b\""}`, "", "", ""}, last)
+
+	// users see their own entries only
+	r = httptest.NewRequest("GET", "/auditlog?format=csv&sort=timestamp", nil)
+	export, err = auditLog.NewExport(r, &users.User{Username: "user", Groups: []string{"Users"}})
+	require.NoError(t, err)
+	buf.Reset()
+	require.NoError(t, export.WriteCSV(context.Background(), &buf))
+	records, err = csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	r = httptest.NewRequest("GET", "/auditlog?format=csv&sort=action", nil)
+	_, err = auditLog.NewExport(r, &users.User{Username: "admin", Groups: []string{users.Administrators}})
+	assert.EqualError(t, err, "only sorting by timestamp is supported with cursor pagination and exports")
+}
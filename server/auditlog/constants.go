@@ -8,26 +8,54 @@ const (
 	ActionExecuteDone  = "execute.done"
 	ActionSuccess      = "success"
 	ActionFailed       = "failed"
+	ActionPlayback     = "playback"
+	ActionRequest      = "request"
+	ActionApprove      = "approve"
+	ActionReject       = "reject"
+	ActionRevert       = "revert"
+	ActionConnect      = "connect"
+	ActionApply        = "apply"
 )
 
 const (
-	ApplicationAuthUser        = "auth.user"
-	ApplicationAuthUserMe      = "auth.user.me"
-	ApplicationAuthUserMeToken = "auth.user.me.token" //nolint:gosec
-	ApplicationAuthUserTotP    = "auth.user.totp"
-	ApplicationAuthUserGroup   = "auth.user.group"
-	ApplicationAuthAPISession  = "auth.api.session"
-	ApplicationAuthAPISessions = "auth.api.sessions"
-	ApplicationClient          = "client"
-	ApplicationClientACL       = "client.acl"
-	ApplicationClientAuth      = "client.auth"
-	ApplicationClientGroup     = "client.group"
-	ApplicationClientTunnel    = "client.tunnel"
-	ApplicationClientCommand   = "client.command"
-	ApplicationClientScript    = "client.script"
-	ApplicationLibraryCommand  = "library.command"
-	ApplicationLibraryScript   = "library.script"
-	ApplicationVault           = "vault"
-	ApplicationSchedule        = "schedule"
-	ApplicationUploads         = "uploads"
+	ApplicationAuthUser            = "auth.user"
+	ApplicationAuthUserMe          = "auth.user.me"
+	ApplicationAuthUserMeToken     = "auth.user.me.token" //nolint:gosec
+	ApplicationAuthUserTotP        = "auth.user.totp"
+	ApplicationAuthUserGroup       = "auth.user.group"
+	ApplicationAuthAPISession      = "auth.api.session"
+	ApplicationAuthAPISessions     = "auth.api.sessions"
+	ApplicationClient              = "client"
+	ApplicationClientACL           = "client.acl"
+	ApplicationClientAuth          = "client.auth"
+	ApplicationClientGroup         = "client.group"
+	ApplicationClientTunnel        = "client.tunnel"
+	ApplicationClientTunnelShare   = "client.tunnel.share"
+	ApplicationClientReverseTunnel = "client.reverse-tunnel"
+	ApplicationClientCommand       = "client.command"
+	ApplicationClientScript        = "client.script"
+	ApplicationClientCapture       = "client.capture"
+	ApplicationClientQuarantine    = "client.quarantine"
+	ApplicationClientUpdatesStatus = "client.updates-status"
+	ApplicationClientAttributes    = "client.attributes"
+	ApplicationClientStoredTunnel  = "client.stored-tunnel"
+	ApplicationClientWatch         = "client.watch"
+	ApplicationLibraryCommand      = "library.command"
+	ApplicationLibraryScript       = "library.script"
+	ApplicationVault               = "vault"
+	ApplicationSchedule            = "schedule"
+	ApplicationRunbook             = "runbook"
+	ApplicationUploads             = "uploads"
+	ApplicationSessionRecording    = "session.recording"
+	ApplicationGatewayTarget       = "gateway.target"
+	ApplicationMaintenance         = "maintenance"
+	ApplicationMonitoringProfile   = "monitoring.profile"
+	ApplicationReport              = "report"
+	ApplicationClientSnapshot      = "client.snapshot"
+	ApplicationFeatureFlag         = "feature.flag"
+	ApplicationPushSubscription    = "push.subscription"
+	ApplicationBootstrap           = "bootstrap"
+	ApplicationAlertingRuleSet     = "alerting.ruleset"
+	ApplicationAlertingTemplate    = "alerting.template"
+	ApplicationAlertingProblem     = "alerting.problem"
 )
@@ -0,0 +1,76 @@
+package auditlog
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	errors2 "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+// CursorQueryParam enables cursor pagination, an empty value requests the first page. Unlike offsets, cursors stay
+// fast on large tables as the database seeks directly to the rowid of the last entry of the previous page.
+const CursorQueryParam = "page[cursor]"
+
+func encodeCursor(rowID int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(rowID, 10)))
+}
+
+func decodeCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	rowID, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if rowID <= 0 {
+		return 0, fmt.Errorf("rowid must be positive, got %d", rowID)
+	}
+	return rowID, nil
+}
+
+// keysetOrder returns the order of entries paginated by cursor, the latest first by default. Entries are stored in
+// the order they happen so only sorting by timestamp is supported.
+func keysetOrder(options *query.ListOptions) (asc bool, err error) {
+	switch {
+	case len(options.Sorts) == 0:
+		return false, nil
+	case len(options.Sorts) == 1 && options.Sorts[0].Column == "timestamp":
+		return options.Sorts[0].IsASC, nil
+	default:
+		return false, errors2.APIError{
+			Message:    "only sorting by timestamp is supported with cursor pagination and exports",
+			HTTPStatus: http.StatusBadRequest,
+		}
+	}
+}
+
+// keysetOptions returns the options to list up to limit entries after the given rowid, 0 to start from the beginning.
+func keysetOptions(options *query.ListOptions, afterRowID int64, asc bool, limit int) *query.ListOptions {
+	filters := make([]query.FilterOption, len(options.Filters), len(options.Filters)+1)
+	copy(filters, options.Filters)
+	if afterRowID > 0 {
+		operator := query.FilterOperatorTypeLT
+		if asc {
+			operator = query.FilterOperatorTypeGT
+		}
+		filters = append(filters, query.FilterOption{
+			Column:   []string{"rowid"},
+			Operator: operator,
+			Values:   []string{strconv.FormatInt(afterRowID, 10)},
+		})
+	}
+
+	return &query.ListOptions{
+		Filters:    filters,
+		Sorts:      []query.SortOption{{Column: "rowid", IsASC: asc}},
+		Pagination: query.NewPagination(limit, 0),
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/realvnc-labs/rport/server/api"
@@ -22,6 +23,10 @@ type Entry struct {
 	ClientHostName string    `db:"client_hostname" json:"client_hostname"`
 	Request        string    `db:"request" json:"request"`
 	Response       string    `db:"response" json:"response"`
+	Labels         string    `db:"labels" json:"labels"`
+	RequestID      string    `db:"request_id" json:"request_id"`
+	// RowID is the sqlite rowid, used as cursor to paginate through large result sets.
+	RowID int64 `db:"rowid" json:"-"`
 
 	al *AuditLog
 }
@@ -42,6 +47,7 @@ func (e *Entry) WithHTTPRequest(req *http.Request) *Entry {
 
 	e.Username = api.GetUser(req.Context(), e.al.logger)
 	e.RemoteIP = chshare.RemoteIP(req)
+	e.RequestID = api.GetRequestID(req.Context())
 
 	return e
 }
@@ -76,6 +82,26 @@ func (e *Entry) WithResponse(response interface{}) *Entry {
 	return e
 }
 
+// WithLabels stores the labels attached to a job or tunnel as a comma separated list.
+func (e *Entry) WithLabels(labels []string) *Entry {
+	if e == nil {
+		return e
+	}
+
+	e.Labels = strings.Join(labels, ",")
+	return e
+}
+
+// WithRequestID stores the id of the API request that caused the entry, e.g. the correlation id of a finished job.
+func (e *Entry) WithRequestID(requestID string) *Entry {
+	if e == nil {
+		return e
+	}
+
+	e.RequestID = requestID
+	return e
+}
+
 func (e *Entry) WithClient(c *clientdata.Client) *Entry {
 	if e == nil {
 		return e
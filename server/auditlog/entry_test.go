@@ -57,6 +57,7 @@ func TestWithID(t *testing.T) {
 
 func TestWithHTTPRequest(t *testing.T) {
 	ctx := api.WithUser(context.Background(), "test-user")
+	ctx = api.WithRequestID(ctx, "request-1")
 	req := httptest.NewRequest("GET", "/", nil)
 	req = req.WithContext(ctx)
 
@@ -64,6 +65,7 @@ func TestWithHTTPRequest(t *testing.T) {
 
 	assert.Equal(t, "test-user", e.Username)
 	assert.Equal(t, "192.0.2.1", e.RemoteIP)
+	assert.Equal(t, "request-1", e.RequestID)
 }
 
 func TestWithRequest(t *testing.T) {
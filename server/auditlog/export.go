@@ -0,0 +1,101 @@
+package auditlog
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+// exportBatchSize is the number of entries read at once while exporting.
+const exportBatchSize = 1000
+
+var csvHeader = []string{
+	"timestamp",
+	"username",
+	"remote_ip",
+	"application",
+	"action",
+	"affected_id",
+	"client_id",
+	"client_hostname",
+	"request",
+	"response",
+	"labels",
+	"request_id",
+}
+
+// Export writes all entries matching the filters of a request, pagination params are ignored.
+type Export struct {
+	provider Provider
+	options  *query.ListOptions
+	asc      bool
+}
+
+// NewExport validates the request before anything is written, so errors can still be returned as JSON.
+func (a *AuditLog) NewExport(r *http.Request, user *users.User) (*Export, error) {
+	options, err := a.listOptions(r, user)
+	if err != nil {
+		return nil, err
+	}
+	asc, err := keysetOrder(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Export{
+		provider: a.provider,
+		options:  options,
+		asc:      asc,
+	}, nil
+}
+
+// WriteCSV writes the entries in batches, so exports of millions of entries don't have to fit into memory.
+func (e *Export) WriteCSV(ctx context.Context, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	var after int64
+	for {
+		entries, err := e.provider.List(ctx, keysetOptions(e.options, after, e.asc, exportBatchSize))
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := cw.Write(csvRow(entry)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+		if len(entries) < exportBatchSize {
+			return nil
+		}
+		after = entries[len(entries)-1].RowID
+	}
+}
+
+func csvRow(e *Entry) []string {
+	return []string{
+		e.Timestamp.Format(time.RFC3339),
+		e.Username,
+		e.RemoteIP,
+		e.Application,
+		e.Action,
+		e.ID,
+		e.ClientID,
+		e.ClientHostName,
+		e.Request,
+		e.Response,
+		e.Labels,
+		e.RequestID,
+	}
+}
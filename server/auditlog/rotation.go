@@ -25,23 +25,26 @@ type RotationProvider struct {
 	ticker            *time.Ticker
 	dataDir           string
 	dataSourceOptions sqlite.DataSourceOptions
+	readReplicaDir    string
 
 	mtx    sync.RWMutex
 	sqlite *SQLiteProvider
 }
 
-func newRotationProvider(l *logger.Logger, period time.Duration, dataDir string, dataSourceOptions sqlite.DataSourceOptions) (*RotationProvider, error) {
-	sqlite, err := newSQLiteProvider(dataDir, dataSourceOptions)
+func newRotationProvider(l *logger.Logger, period time.Duration, dataDir string, dataSourceOptions sqlite.DataSourceOptions, readReplicaDir string) (*RotationProvider, error) {
+	sqlite, err := newSQLiteProvider(l, dataDir, dataSourceOptions, readReplicaDir)
 	if err != nil {
 		return nil, err
 	}
 
 	r := &RotationProvider{
-		logger:  l,
-		period:  period,
-		dataDir: dataDir,
-		sqlite:  sqlite,
-		ticker:  time.NewTicker(period),
+		logger:            l,
+		period:            period,
+		dataDir:           dataDir,
+		dataSourceOptions: dataSourceOptions,
+		readReplicaDir:    readReplicaDir,
+		sqlite:            sqlite,
+		ticker:            time.NewTicker(period),
 	}
 	err = r.rotateIfNeeded()
 	if err != nil {
@@ -78,7 +81,7 @@ func (r *RotationProvider) rotate() error {
 		return err
 	}
 
-	r.sqlite, err = newSQLiteProvider(r.dataDir, r.dataSourceOptions)
+	r.sqlite, err = newSQLiteProvider(r.logger, r.dataDir, r.dataSourceOptions, r.readReplicaDir)
 	if err != nil {
 		return err
 	}
@@ -123,3 +126,33 @@ func (r *RotationProvider) Close() error {
 	r.ticker.Stop()
 	return r.sqlite.Close()
 }
+
+// DeleteRotatedBefore deletes the rotated auditlog files of the given data dir rotated before the given time.
+func DeleteRotatedBefore(dataDir string, before time.Time) (int, error) {
+	files, err := os.ReadDir(dataDir)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		rotatedAt, err := time.ParseInLocation(rotatedFilename, f.Name(), time.Local)
+		if err != nil {
+			// not a rotated auditlog
+			continue
+		}
+		if !rotatedAt.Before(before) {
+			continue
+		}
+		err = os.Remove(path.Join(dataDir, f.Name()))
+		if err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
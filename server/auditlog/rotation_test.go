@@ -2,6 +2,7 @@ package auditlog
 
 import (
 	"context"
+	"os"
 	"path"
 	"testing"
 	"time"
@@ -22,7 +23,7 @@ func TestRotation(t *testing.T) {
 	period := 300 * time.Millisecond
 
 	// Prepare sqlite with 1 entry
-	sqlite, err := newSQLiteProvider(dir, dso)
+	sqlite, err := newSQLiteProvider(nil, dir, dso, "")
 	require.NoError(t, err)
 	err = sqlite.Save(&Entry{Timestamp: time.Now(), Username: "test1"})
 	require.NoError(t, err)
@@ -30,7 +31,7 @@ func TestRotation(t *testing.T) {
 	require.NoError(t, err)
 
 	// No rotation on init if entry is not older than period
-	rotation, err := newRotationProvider(nil, period, dir, dso)
+	rotation, err := newRotationProvider(nil, period, dir, dso, "")
 	require.NoError(t, err)
 	entries, err := rotation.List(ctx, &query.ListOptions{})
 	require.NoError(t, err)
@@ -42,7 +43,7 @@ func TestRotation(t *testing.T) {
 	time.Sleep(period)
 
 	// Should rotate on init
-	rotation, err = newRotationProvider(nil, period, dir, dso)
+	rotation, err = newRotationProvider(nil, period, dir, dso, "")
 	require.NoError(t, err)
 	entries, err = rotation.List(ctx, &query.ListOptions{})
 	require.NoError(t, err)
@@ -74,7 +75,8 @@ func assertRotatedSqlite(t *testing.T, dir, expectedUsername string) {
 	db, err := sqlite.New(path.Join(dir, time.Now().Format(rotatedFilename)), auditlog.AssetNames(), auditlog.Asset, dso)
 	require.NoError(t, err)
 	sqlite := &SQLiteProvider{
-		db: db,
+		db:     db,
+		reader: sqlite.NewReadReplica(db, nil, nil),
 	}
 	defer sqlite.Close()
 
@@ -83,3 +85,24 @@ func assertRotatedSqlite(t *testing.T, dir, expectedUsername string) {
 	assert.Equal(t, 1, len(entries))
 	assert.Equal(t, expectedUsername, entries[0].Username)
 }
+
+func TestDeleteRotatedBefore(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	old := path.Join(dir, now.AddDate(0, 0, -10).Format(rotatedFilename))
+	recent := path.Join(dir, now.AddDate(0, 0, -1).Format(rotatedFilename))
+	current := path.Join(dir, sqliteFilename)
+	other := path.Join(dir, "jobs.db")
+	for _, fn := range []string{old, recent, current, other} {
+		require.NoError(t, os.WriteFile(fn, nil, 0600))
+	}
+
+	deleted, err := DeleteRotatedBefore(dir, now.AddDate(0, 0, -5))
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	assert.NoFileExists(t, old)
+	assert.FileExists(t, recent)
+	assert.FileExists(t, current)
+	assert.FileExists(t, other)
+}
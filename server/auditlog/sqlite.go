@@ -9,15 +9,19 @@ import (
 
 	"github.com/realvnc-labs/rport/db/migration/auditlog"
 	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/share/logger"
 	"github.com/realvnc-labs/rport/share/query"
 )
 
 type SQLiteProvider struct {
-	db        *sqlx.DB
+	db *sqlx.DB
+	// reader is used for list queries
+	reader    *sqlite.ReadReplica
 	converter *query.SQLConverter
 }
 
-func newSQLiteProvider(dataDir string, dataSourceOptions sqlite.DataSourceOptions) (*SQLiteProvider, error) {
+// newSQLiteProvider opens the auditlog db, list queries use the replica in readReplicaDir if set.
+func newSQLiteProvider(l *logger.Logger, dataDir string, dataSourceOptions sqlite.DataSourceOptions, readReplicaDir string) (*SQLiteProvider, error) {
 	db, err := sqlite.New(
 		path.Join(dataDir, sqliteFilename),
 		auditlog.AssetNames(),
@@ -27,8 +31,18 @@ func newSQLiteProvider(dataDir string, dataSourceOptions sqlite.DataSourceOption
 	if err != nil {
 		return nil, err
 	}
+
+	var replica *sqlx.DB
+	if readReplicaDir != "" {
+		replica, err = sqlite.OpenReadReplica(readReplicaDir, sqliteFilename, l)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &SQLiteProvider{
 		db:        db,
+		reader:    sqlite.NewReadReplica(db, replica, l),
 		converter: query.NewSQLConverter(db.DriverName()),
 	}, nil
 }
@@ -45,7 +59,9 @@ func (p *SQLiteProvider) Save(e *Entry) error {
 			client_id,
 			client_hostname,
 			request,
-			response
+			response,
+			labels,
+			request_id
 		) VALUES (
 			:timestamp,
 			:username,
@@ -56,7 +72,9 @@ func (p *SQLiteProvider) Save(e *Entry) error {
 			:client_id,
 			:client_hostname,
 			:request,
-			:response
+			:response,
+			:labels,
+			:request_id
 		)`,
 		e,
 	)
@@ -67,11 +85,11 @@ func (p *SQLiteProvider) Save(e *Entry) error {
 func (p *SQLiteProvider) List(ctx context.Context, options *query.ListOptions) ([]*Entry, error) {
 	values := []*Entry{}
 
-	q := "SELECT * FROM `auditlog`"
+	q := "SELECT rowid, * FROM `auditlog`"
 
 	q, params := p.converter.ConvertListOptionsToQuery(options, q)
 
-	err := p.db.SelectContext(ctx, &values, q, params...)
+	err := p.reader.SelectContext(ctx, &values, q, params...)
 	if err != nil {
 		return values, err
 	}
@@ -87,7 +105,7 @@ func (p *SQLiteProvider) Count(ctx context.Context, options *query.ListOptions)
 	countOptions.Pagination = nil
 	q, params := p.converter.ConvertListOptionsToQuery(&countOptions, q)
 
-	err := p.db.GetContext(ctx, &result, q, params...)
+	err := p.reader.GetContext(ctx, &result, q, params...)
 	if err != nil {
 		return 0, err
 	}
@@ -106,5 +124,8 @@ func (p *SQLiteProvider) OldestTimestamp(ctx context.Context) (time.Time, error)
 }
 
 func (p *SQLiteProvider) Close() error {
+	if err := p.reader.Close(); err != nil {
+		return err
+	}
 	return p.db.Close()
 }
@@ -15,7 +15,8 @@ func TestSqliteSave(t *testing.T) {
 	db, err := sqlite.New(":memory:", auditlog.AssetNames(), auditlog.Asset, DataSourceOptions)
 	require.NoError(t, err)
 	dbProv := SQLiteProvider{
-		db: db,
+		db:     db,
+		reader: sqlite.NewReadReplica(db, nil, nil),
 	}
 	defer dbProv.Close()
 
@@ -30,6 +31,8 @@ func TestSqliteSave(t *testing.T) {
 		ClientHostName: "127.0.0.1",
 		Request:        `{"k1": "v1"}`,
 		Response:       `{"k1": "v1"}`,
+		Labels:         "INC-1234,project-x",
+		RequestID:      "request-1",
 	}
 	err = dbProv.Save(e)
 	require.NoError(t, err)
@@ -46,6 +49,8 @@ func TestSqliteSave(t *testing.T) {
 			"client_hostname": e.ClientHostName,
 			"request":         e.Request,
 			"response":        e.Response,
+			"labels":          e.Labels,
+			"request_id":      e.RequestID,
 		},
 	}
 	q := "SELECT * FROM auditlog"
@@ -0,0 +1,66 @@
+package autotags
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	TagUnstable = "unstable"
+	TagStale    = "stale"
+
+	DefaultUnstableDisconnects = 5
+	DefaultUnstablePeriod      = 24 * time.Hour
+	DefaultStaleAfter          = 30 * 24 * time.Hour
+)
+
+// Config defines when the server tags clients automatically. Setting a threshold to zero disables the tag.
+type Config struct {
+	UnstableDisconnects int           `mapstructure:"auto_tag_unstable_disconnects"`
+	UnstablePeriod      time.Duration `mapstructure:"auto_tag_unstable_period"`
+	StaleAfter          time.Duration `mapstructure:"auto_tag_stale_after"`
+}
+
+func (c *Config) Validate() error {
+	if c.UnstableDisconnects < 0 {
+		return errors.New("auto_tag_unstable_disconnects cannot be negative")
+	}
+	if c.UnstableDisconnects > 0 && c.UnstablePeriod <= 0 {
+		return errors.New("auto_tag_unstable_period must be greater than 0")
+	}
+	if c.StaleAfter < 0 {
+		return errors.New("auto_tag_stale_after cannot be negative")
+	}
+	return nil
+}
+
+func (c *Config) Enabled() bool {
+	return c.UnstableDisconnects > 0 || c.StaleAfter > 0
+}
+
+// RecentDisconnects returns the given disconnect times within the unstable period, the only ones worth keeping.
+func (c *Config) RecentDisconnects(disconnects []time.Time, now time.Time) []time.Time {
+	if c.UnstableDisconnects == 0 {
+		return nil
+	}
+	since := now.Add(-c.UnstablePeriod)
+	var res []time.Time
+	for _, t := range disconnects {
+		if t.After(since) {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// Tags returns the tags a client gets based on its recent disconnects and the time it's disconnected, nil if connected.
+func (c *Config) Tags(disconnects []time.Time, disconnectedAt *time.Time, now time.Time) []string {
+	var tags []string
+	if c.UnstableDisconnects > 0 && len(c.RecentDisconnects(disconnects, now)) > c.UnstableDisconnects {
+		tags = append(tags, TagUnstable)
+	}
+	if c.StaleAfter > 0 && disconnectedAt != nil && now.Sub(*disconnectedAt) > c.StaleAfter {
+		tags = append(tags, TagStale)
+	}
+	return tags
+}
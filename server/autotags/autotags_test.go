@@ -0,0 +1,80 @@
+package autotags
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTags(t *testing.T) {
+	now := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	cfg := &Config{
+		UnstableDisconnects: 2,
+		UnstablePeriod:      time.Hour,
+		StaleAfter:          24 * time.Hour,
+	}
+	recently := now.Add(-time.Minute)
+	longAgo := now.Add(-25 * time.Hour)
+
+	testCases := []struct {
+		name           string
+		cfg            *Config
+		disconnects    []time.Time
+		disconnectedAt *time.Time
+		expected       []string
+	}{
+		{
+			name:     "connected",
+			cfg:      cfg,
+			expected: nil,
+		},
+		{
+			name:           "disconnected recently",
+			cfg:            cfg,
+			disconnects:    []time.Time{recently, recently},
+			disconnectedAt: &recently,
+			expected:       nil,
+		},
+		{
+			name:        "unstable",
+			cfg:         cfg,
+			disconnects: []time.Time{longAgo, recently, recently, recently},
+			expected:    []string{TagUnstable},
+		},
+		{
+			name:           "stale",
+			cfg:            cfg,
+			disconnectedAt: &longAgo,
+			expected:       []string{TagStale},
+		},
+		{
+			name:           "unstable and stale",
+			cfg:            cfg,
+			disconnects:    []time.Time{recently, recently, recently},
+			disconnectedAt: &longAgo,
+			expected:       []string{TagUnstable, TagStale},
+		},
+		{
+			name:           "disabled",
+			cfg:            &Config{},
+			disconnects:    []time.Time{recently, recently, recently},
+			disconnectedAt: &longAgo,
+			expected:       nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.cfg.Tags(tc.disconnects, tc.disconnectedAt, now))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, (&Config{}).Validate())
+	assert.NoError(t, (&Config{UnstableDisconnects: 1, UnstablePeriod: time.Hour, StaleAfter: time.Hour}).Validate())
+	assert.EqualError(t, (&Config{UnstableDisconnects: -1}).Validate(), "auto_tag_unstable_disconnects cannot be negative")
+	assert.EqualError(t, (&Config{UnstableDisconnects: 1}).Validate(), "auto_tag_unstable_period must be greater than 0")
+	assert.EqualError(t, (&Config{StaleAfter: -time.Hour}).Validate(), "auto_tag_stale_after cannot be negative")
+}
@@ -0,0 +1,274 @@
+package bandwidth
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	apiErrors "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+const (
+	// DayLayout is the format of the days of the counters, days are in UTC.
+	DayLayout = "2006-01-02"
+	// KeepDays is the number of days to keep the daily counters.
+	KeepDays = 400
+
+	groupByClient = "client_id"
+	groupByUser   = "username"
+)
+
+// Config defines the monthly tunnel traffic quotas in bytes, 0 disables the quota.
+type Config struct {
+	ClientQuota int64 `mapstructure:"tunnel_traffic_quota_per_client"`
+	UserQuota   int64 `mapstructure:"tunnel_traffic_quota_per_user"`
+}
+
+func (c *Config) Validate() error {
+	if c.ClientQuota < 0 {
+		return errors.New("tunnel_traffic_quota_per_client cannot be negative")
+	}
+	if c.UserQuota < 0 {
+		return errors.New("tunnel_traffic_quota_per_user cannot be negative")
+	}
+	return nil
+}
+
+// Usage is the tunnel traffic of a client initiated by a user on a day. Username is empty for tunnels not created
+// via the API, e.g. tunnels of the client configuration.
+type Usage struct {
+	Day      string `json:"day" db:"day"`
+	ClientID string `json:"client_id" db:"client_id"`
+	Username string `json:"username" db:"username"`
+	BytesIn  int64  `json:"bytes_in" db:"bytes_in"`
+	BytesOut int64  `json:"bytes_out" db:"bytes_out"`
+}
+
+// Consumer is the traffic of a client or user within the period of a report.
+type Consumer struct {
+	ID       string `json:"id,omitempty" db:"id"`
+	BytesIn  int64  `json:"bytes_in" db:"bytes_in"`
+	BytesOut int64  `json:"bytes_out" db:"bytes_out"`
+	Total    int64  `json:"total"`
+}
+
+type Report struct {
+	From        string      `json:"from"`
+	To          string      `json:"to"`
+	Total       *Consumer   `json:"total"`
+	TopClients  []*Consumer `json:"top_clients"`
+	TopUsers    []*Consumer `json:"top_users"`
+	ClientQuota int64       `json:"client_quota"`
+	UserQuota   int64       `json:"user_quota"`
+}
+
+type key struct {
+	day      string
+	clientID string
+	username string
+}
+
+// Service accounts the traffic of tunnels per client and per user who created the tunnel. The traffic is counted in
+// memory and flushed to the daily counters in the database periodically.
+type Service struct {
+	provider *SQLiteProvider
+	config   Config
+	logger   *logger.Logger
+	now      func() time.Time
+
+	mu      sync.Mutex
+	pending map[key]*Usage
+}
+
+func NewService(provider *SQLiteProvider, config Config, logger *logger.Logger) *Service {
+	return &Service{
+		provider: provider,
+		config:   config,
+		logger:   logger,
+		now:      time.Now,
+		pending:  make(map[key]*Usage),
+	}
+}
+
+// Add counts the traffic of a tunnel of the given client created by the given user.
+func (s *Service) Add(clientID, username string, in, out int64) {
+	s.add(&Usage{
+		Day:      s.Today(),
+		ClientID: clientID,
+		Username: username,
+		BytesIn:  in,
+		BytesOut: out,
+	})
+}
+
+func (s *Service) add(usage *Usage) {
+	k := key{
+		day:      usage.Day,
+		clientID: usage.ClientID,
+		username: usage.Username,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.pending[k]
+	if u == nil {
+		s.pending[k] = usage
+		return
+	}
+	u.BytesIn += usage.BytesIn
+	u.BytesOut += usage.BytesOut
+}
+
+// Flush stores the traffic counted since the last flush.
+func (s *Service) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[key]*Usage)
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	usages := make([]*Usage, 0, len(pending))
+	for _, u := range pending {
+		usages = append(usages, u)
+	}
+	if err := s.provider.Add(ctx, usages); err != nil {
+		// keep the traffic for the next flush
+		for _, u := range usages {
+			s.add(u)
+		}
+		return fmt.Errorf("failed to save tunnel traffic: %w", err)
+	}
+	return nil
+}
+
+// Cleanup flushes the counted traffic and deletes the daily counters older than KeepDays.
+func (s *Service) Cleanup(ctx context.Context) error {
+	if err := s.Flush(ctx); err != nil {
+		return err
+	}
+
+	before := s.now().UTC().AddDate(0, 0, -KeepDays).Format(DayLayout)
+	if _, err := s.provider.DeleteOlderThan(ctx, before); err != nil {
+		return fmt.Errorf("failed to delete old tunnel traffic: %w", err)
+	}
+	return nil
+}
+
+// Report returns the total traffic and the top clients and users of the given days.
+func (s *Service) Report(ctx context.Context, from, to string, top int) (*Report, error) {
+	if err := s.Flush(ctx); err != nil {
+		return nil, err
+	}
+
+	total, err := s.provider.Total(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	topClients, err := s.provider.Top(ctx, groupByClient, from, to, top)
+	if err != nil {
+		return nil, err
+	}
+	topUsers, err := s.provider.Top(ctx, groupByUser, from, to, top)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range append(append([]*Consumer{total}, topClients...), topUsers...) {
+		c.Total = c.BytesIn + c.BytesOut
+	}
+
+	return &Report{
+		From:        from,
+		To:          to,
+		Total:       total,
+		TopClients:  topClients,
+		TopUsers:    topUsers,
+		ClientQuota: s.config.ClientQuota,
+		UserQuota:   s.config.UserQuota,
+	}, nil
+}
+
+// List returns the daily counters of the given days.
+func (s *Service) List(ctx context.Context, from, to string) ([]*Usage, error) {
+	if err := s.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return s.provider.List(ctx, from, to)
+}
+
+// CheckQuota returns an APIError with 403 if the client or the user used up their monthly traffic quota.
+func (s *Service) CheckQuota(ctx context.Context, clientID, username string) error {
+	if s == nil || s.config.ClientQuota == 0 && s.config.UserQuota == 0 {
+		return nil
+	}
+	if err := s.Flush(ctx); err != nil {
+		return err
+	}
+
+	monthStart := s.MonthStart()
+	if s.config.ClientQuota > 0 {
+		used, err := s.provider.Used(ctx, groupByClient, clientID, monthStart)
+		if err != nil {
+			return err
+		}
+		if used >= s.config.ClientQuota {
+			return apiErrors.NewAPIError(http.StatusForbidden, "", fmt.Sprintf("Client %s used up its monthly tunnel traffic quota.", clientID), nil)
+		}
+	}
+	if s.config.UserQuota > 0 && username != "" {
+		used, err := s.provider.Used(ctx, groupByUser, username, monthStart)
+		if err != nil {
+			return err
+		}
+		if used >= s.config.UserQuota {
+			return apiErrors.NewAPIError(http.StatusForbidden, "", fmt.Sprintf("User %s used up the monthly tunnel traffic quota.", username), nil)
+		}
+	}
+	return nil
+}
+
+// Today returns the current day in the format of the counters.
+func (s *Service) Today() string {
+	return s.now().UTC().Format(DayLayout)
+}
+
+// MonthStart returns the first day of the current month in the format of the counters.
+func (s *Service) MonthStart() string {
+	now := s.now().UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).Format(DayLayout)
+}
+
+func (s *Service) Close() error {
+	if err := s.Flush(context.Background()); err != nil {
+		s.logger.Errorf("Failed to save tunnel traffic on close: %v", err)
+	}
+	return s.provider.Close()
+}
+
+// WriteCSV writes the daily counters as CSV, one row per day, client and user.
+func WriteCSV(w io.Writer, usages []*Usage) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{"day", "client_id", "username", "bytes_in", "bytes_out"}); err != nil {
+		return err
+	}
+	for _, u := range usages {
+		row := []string{u.Day, u.ClientID, u.Username, strconv.FormatInt(u.BytesIn, 10), strconv.FormatInt(u.BytesOut, 10)}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,109 @@
+package bandwidth
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/db/migration/bandwidth"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+var testLog = logger.NewLogger("bandwidth-test", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+
+func newTestService(t *testing.T, config Config, now *time.Time) *Service {
+	db, err := sqlite.New(":memory:", bandwidth.AssetNames(), bandwidth.Asset, sqlite.DataSourceOptions{})
+	require.NoError(t, err)
+	s := NewService(NewSQLiteProvider(db), config, testLog)
+	s.now = func() time.Time {
+		return *now
+	}
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+	return s
+}
+
+func TestReport(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2023, 4, 30, 23, 0, 0, 0, time.UTC)
+	s := newTestService(t, Config{UserQuota: 1000}, &now)
+
+	s.Add("client-1", "alice", 100, 1000)
+	now = now.Add(2 * time.Hour)
+	s.Add("client-1", "alice", 10, 100)
+	s.Add("client-1", "alice", 10, 100)
+	s.Add("client-1", "", 5, 5)
+	s.Add("client-2", "bob", 1, 2)
+	require.NoError(t, s.Flush(ctx))
+	s.Add("client-2", "bob", 1, 2)
+
+	report, err := s.Report(ctx, s.MonthStart(), s.Today(), 10)
+	require.NoError(t, err)
+
+	assert.Equal(t, "2023-05-01", report.From)
+	assert.Equal(t, &Consumer{BytesIn: 27, BytesOut: 209, Total: 236}, report.Total)
+	assert.Equal(t, []*Consumer{
+		{ID: "client-1", BytesIn: 25, BytesOut: 205, Total: 230},
+		{ID: "client-2", BytesIn: 2, BytesOut: 4, Total: 6},
+	}, report.TopClients)
+	assert.Equal(t, []*Consumer{
+		{ID: "alice", BytesIn: 20, BytesOut: 200, Total: 220},
+		{ID: "bob", BytesIn: 2, BytesOut: 4, Total: 6},
+	}, report.TopUsers)
+	assert.Equal(t, int64(1000), report.UserQuota)
+
+	usages, err := s.List(ctx, "2023-04-30", "2023-05-01")
+	require.NoError(t, err)
+	require.Len(t, usages, 4)
+	assert.Equal(t, &Usage{Day: "2023-04-30", ClientID: "client-1", Username: "alice", BytesIn: 100, BytesOut: 1000}, usages[0])
+
+	var b strings.Builder
+	require.NoError(t, WriteCSV(&b, usages))
+	expected := `day,client_id,username,bytes_in,bytes_out
+2023-04-30,client-1,alice,100,1000
+2023-05-01,client-1,,5,5
+2023-05-01,client-1,alice,20,200
+2023-05-01,client-2,bob,2,4
+`
+	assert.Equal(t, expected, b.String())
+}
+
+func TestCheckQuota(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2023, 5, 10, 12, 0, 0, 0, time.UTC)
+	s := newTestService(t, Config{ClientQuota: 1000, UserQuota: 500}, &now)
+
+	s.Add("client-1", "alice", 100, 300)
+	assert.NoError(t, s.CheckQuota(ctx, "client-1", "alice"))
+
+	s.Add("client-1", "alice", 0, 100)
+	err := s.CheckQuota(ctx, "client-1", "alice")
+	var apiErr errors.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusForbidden, apiErr.HTTPStatus)
+	assert.NoError(t, s.CheckQuota(ctx, "client-1", "bob"))
+
+	s.Add("client-1", "bob", 500, 0)
+	err = s.CheckQuota(ctx, "client-1", "")
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "Client client-1 used up its monthly tunnel traffic quota.", apiErr.Message)
+
+	// quotas are monthly
+	now = time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, s.CheckQuota(ctx, "client-1", "alice"))
+}
+
+func TestCheckQuotaNilService(t *testing.T) {
+	var s *Service
+
+	assert.NoError(t, s.CheckQuota(context.Background(), "client-1", "alice"))
+}
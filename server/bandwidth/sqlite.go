@@ -0,0 +1,123 @@
+package bandwidth
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SQLiteProvider struct {
+	db *sqlx.DB
+}
+
+func NewSQLiteProvider(db *sqlx.DB) *SQLiteProvider {
+	return &SQLiteProvider{
+		db: db,
+	}
+}
+
+// Add adds the given traffic to the daily counters.
+func (p *SQLiteProvider) Add(ctx context.Context, usages []*Usage) error {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, u := range usages {
+		_, err := tx.NamedExecContext(
+			ctx,
+			`INSERT INTO traffic (
+				day,
+				client_id,
+				username,
+				bytes_in,
+				bytes_out
+			) VALUES (
+				:day,
+				:client_id,
+				:username,
+				:bytes_in,
+				:bytes_out
+			) ON CONFLICT (day, client_id, username) DO UPDATE SET
+				bytes_in = bytes_in + excluded.bytes_in,
+				bytes_out = bytes_out + excluded.bytes_out`,
+			u,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// List returns the daily counters of the given days sorted by day.
+func (p *SQLiteProvider) List(ctx context.Context, from, to string) ([]*Usage, error) {
+	values := []*Usage{}
+	err := p.db.SelectContext(
+		ctx,
+		&values,
+		"SELECT * FROM traffic WHERE day >= ? AND day <= ? ORDER BY day ASC, client_id ASC, username ASC",
+		from,
+		to,
+	)
+	return values, err
+}
+
+// Total returns the sum of the daily counters of the given days.
+func (p *SQLiteProvider) Total(ctx context.Context, from, to string) (*Consumer, error) {
+	total := &Consumer{}
+	err := p.db.GetContext(
+		ctx,
+		total,
+		"SELECT COALESCE(SUM(bytes_in), 0) AS bytes_in, COALESCE(SUM(bytes_out), 0) AS bytes_out FROM traffic WHERE day >= ? AND day <= ?",
+		from,
+		to,
+	)
+	return total, err
+}
+
+// Top returns the given number of clients or users with the most traffic on the given days.
+func (p *SQLiteProvider) Top(ctx context.Context, groupBy string, from, to string, limit int) ([]*Consumer, error) {
+	values := []*Consumer{}
+	// groupBy is either client_id or username, never user input
+	err := p.db.SelectContext(
+		ctx,
+		&values,
+		"SELECT "+groupBy+" AS id, SUM(bytes_in) AS bytes_in, SUM(bytes_out) AS bytes_out FROM traffic "+
+			"WHERE day >= ? AND day <= ? AND "+groupBy+" != '' GROUP BY "+groupBy+" "+
+			"ORDER BY SUM(bytes_in) + SUM(bytes_out) DESC, "+groupBy+" ASC LIMIT ?",
+		from,
+		to,
+		limit,
+	)
+	return values, err
+}
+
+// Used returns the traffic of a client or user since the given day.
+func (p *SQLiteProvider) Used(ctx context.Context, groupBy string, id string, since string) (int64, error) {
+	var result int64
+	err := p.db.GetContext(
+		ctx,
+		&result,
+		"SELECT COALESCE(SUM(bytes_in + bytes_out), 0) FROM traffic WHERE "+groupBy+" = ? AND day >= ?",
+		id,
+		since,
+	)
+	return result, err
+}
+
+func (p *SQLiteProvider) DeleteOlderThan(ctx context.Context, day string) (int64, error) {
+	res, err := p.db.ExecContext(ctx, "DELETE FROM traffic WHERE day < ?", day)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (p *SQLiteProvider) Close() error {
+	return p.db.Close()
+}
@@ -0,0 +1,20 @@
+package bandwidth
+
+import (
+	"context"
+)
+
+type FlushTask struct {
+	service *Service
+}
+
+// NewFlushTask returns a task to periodically store the counted tunnel traffic and purge old counters.
+func NewFlushTask(service *Service) *FlushTask {
+	return &FlushTask{
+		service: service,
+	}
+}
+
+func (t *FlushTask) Run(ctx context.Context) error {
+	return t.service.Cleanup(ctx)
+}
@@ -0,0 +1,338 @@
+package bootstrap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	alertingcap "github.com/realvnc-labs/rport/plus/capabilities/alerting"
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/entities/rules"
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/entities/validations"
+	"github.com/realvnc-labs/rport/server/api/jobs/schedule"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/clients/storedtunnels"
+	"github.com/realvnc-labs/rport/server/clientsauth"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+const (
+	KindUserGroup      = "user_group"
+	KindUser           = "user"
+	KindClientAuth     = "client_auth"
+	KindTunnelTemplate = "tunnel_template"
+	KindSchedule       = "schedule"
+	KindAlertRules     = "alert_rules"
+
+	ActionCreated   = "created"
+	ActionUpdated   = "updated"
+	ActionUnchanged = "unchanged"
+
+	// DefaultUser is the creator of schedules applied on startup.
+	DefaultUser = "bootstrap"
+)
+
+// ErrUnsupported is returned if the bundle contains entries the server can't apply with its configuration.
+var ErrUnsupported = errors.New("not supported")
+
+type UserService interface {
+	GetByUsername(username string) (*users.User, error)
+	Change(usr *users.User, username string) error
+	ListGroups() ([]users.Group, error)
+	UpdateGroup(name string, g users.Group) (users.Group, error)
+}
+
+type TunnelTemplates interface {
+	GetByName(ctx context.Context, clientID, name string) (*storedtunnels.StoredTunnel, error)
+	Create(ctx context.Context, clientID string, t *storedtunnels.StoredTunnel) (*storedtunnels.StoredTunnel, error)
+	Update(ctx context.Context, clientID string, t *storedtunnels.StoredTunnel) (*storedtunnels.StoredTunnel, error)
+}
+
+type Schedules interface {
+	GetByName(ctx context.Context, name string) (*schedule.Schedule, error)
+	Create(ctx context.Context, s *schedule.Schedule, user string) (*schedule.Schedule, error)
+	Update(ctx context.Context, id string, s *schedule.Schedule) (*schedule.Schedule, error)
+}
+
+type AlertRules interface {
+	LoadRuleSet(ruleSetID rules.RuleSetID) (*rules.RuleSet, error)
+	SaveRuleSet(rs *rules.RuleSet) (validations.ErrorList, error)
+	LoadDefaultRuleSet() error
+}
+
+// Change is the result of applying a single entry of a bundle.
+type Change struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id"`
+	Action string `json:"action"`
+}
+
+// Applier applies bundles. Kinds without a target, e.g. the alert rules without rport-plus, are rejected if they are
+// part of the bundle.
+type Applier struct {
+	Users           UserService
+	ClientAuths     clientsauth.Provider
+	TunnelTemplates TunnelTemplates
+	Schedules       Schedules
+	AlertRules      AlertRules
+	Logger          *logger.Logger
+}
+
+// Apply creates the entries of the bundle that don't exist yet and updates the ones that differ. Applying the same
+// bundle again doesn't change anything. Passwords of existing users are never changed, so users can change their
+// initial password. It stops on the first error and returns the changes applied so far.
+func (a *Applier) Apply(ctx context.Context, b *Bundle, user string) ([]Change, error) {
+	var changes []Change
+	add := func(kind, id, action string) {
+		changes = append(changes, Change{Kind: kind, ID: id, Action: action})
+		if action != ActionUnchanged {
+			a.Logger.Infof("bootstrap: %s %q %s", kind, id, action)
+		}
+	}
+
+	if len(b.UserGroups) > 0 {
+		if a.Users == nil {
+			return changes, fmt.Errorf("user_groups: %w by the configured user provider", ErrUnsupported)
+		}
+		existing, err := a.Users.ListGroups()
+		if err != nil {
+			return changes, fmt.Errorf("user_groups: %w", err)
+		}
+		for _, g := range b.UserGroups {
+			action, err := a.applyUserGroup(g, existing)
+			if err != nil {
+				return changes, fmt.Errorf("user_groups: %q: %w", g.Name, err)
+			}
+			add(KindUserGroup, g.Name, action)
+		}
+	}
+
+	for _, u := range b.Users {
+		if a.Users == nil {
+			return changes, fmt.Errorf("users: %w by the configured user provider", ErrUnsupported)
+		}
+		action, err := a.applyUser(u)
+		if err != nil {
+			return changes, fmt.Errorf("users: %q: %w", u.Username, err)
+		}
+		add(KindUser, u.Username, action)
+	}
+
+	for _, ca := range b.ClientAuths {
+		if a.ClientAuths == nil {
+			return changes, fmt.Errorf("client_auths: %w, client auths are read-only", ErrUnsupported)
+		}
+		action, err := a.applyClientAuth(ca)
+		if err != nil {
+			return changes, fmt.Errorf("client_auths: %q: %w", ca.ID, err)
+		}
+		add(KindClientAuth, ca.ID, action)
+	}
+
+	for _, t := range b.TunnelTemplates {
+		action, err := a.applyTunnelTemplate(ctx, t)
+		if err != nil {
+			return changes, fmt.Errorf("tunnel_templates: %q of client %q: %w", t.Name, t.ClientID, err)
+		}
+		add(KindTunnelTemplate, t.ClientID+"/"+t.Name, action)
+	}
+
+	for _, s := range b.Schedules {
+		action, err := a.applySchedule(ctx, s, user)
+		if err != nil {
+			return changes, fmt.Errorf("schedules: %q: %w", s.Name, err)
+		}
+		add(KindSchedule, s.Name, action)
+	}
+
+	if b.AlertRules != nil {
+		if a.AlertRules == nil {
+			return changes, fmt.Errorf("alert_rules: %w, alerting requires rport-plus", ErrUnsupported)
+		}
+		action, err := a.applyAlertRules(b.AlertRules)
+		if err != nil {
+			return changes, fmt.Errorf("alert_rules: %w", err)
+		}
+		add(KindAlertRules, string(rules.DefaultRuleSetID), action)
+	}
+
+	return changes, nil
+}
+
+func (a *Applier) applyUserGroup(g users.Group, existing []users.Group) (string, error) {
+	action := ActionCreated
+	for _, e := range existing {
+		if e.Name != g.Name {
+			continue
+		}
+		if reflect.DeepEqual(e.Permissions.All(), g.Permissions.All()) &&
+			reflect.DeepEqual(e.TunnelsRestricted, g.TunnelsRestricted) &&
+			reflect.DeepEqual(e.CommandsRestricted, g.CommandsRestricted) {
+			return ActionUnchanged, nil
+		}
+		action = ActionUpdated
+	}
+
+	if _, err := a.Users.UpdateGroup(g.Name, g); err != nil {
+		return "", err
+	}
+	return action, nil
+}
+
+func (a *Applier) applyUser(u users.User) (string, error) {
+	existing, err := a.Users.GetByUsername(u.Username)
+	if err != nil {
+		return "", err
+	}
+	if existing == nil {
+		if err := a.Users.Change(&u, ""); err != nil {
+			return "", err
+		}
+		return ActionCreated, nil
+	}
+
+	change := &users.User{}
+	if u.Groups != nil && !sameStrings(existing.Groups, u.Groups) {
+		change.Groups = u.Groups
+	}
+	if u.TwoFASendTo != "" && existing.TwoFASendTo != u.TwoFASendTo {
+		change.TwoFASendTo = u.TwoFASendTo
+	}
+	if change.Groups == nil && change.TwoFASendTo == "" {
+		return ActionUnchanged, nil
+	}
+	if err := a.Users.Change(change, u.Username); err != nil {
+		return "", err
+	}
+	return ActionUpdated, nil
+}
+
+func (a *Applier) applyClientAuth(ca clientsauth.ClientAuth) (string, error) {
+	existing, err := a.ClientAuths.Get(ca.ID)
+	if err != nil {
+		return "", err
+	}
+	action := ActionCreated
+	if existing != nil {
+		if existing.Password == ca.Password {
+			return ActionUnchanged, nil
+		}
+		if err := a.ClientAuths.Delete(ca.ID); err != nil {
+			return "", err
+		}
+		action = ActionUpdated
+	}
+
+	if _, err := a.ClientAuths.Add(&ca); err != nil {
+		return "", err
+	}
+	return action, nil
+}
+
+func (a *Applier) applyTunnelTemplate(ctx context.Context, t TunnelTemplate) (string, error) {
+	existing, err := a.TunnelTemplates.GetByName(ctx, t.ClientID, t.Name)
+	if err != nil {
+		return "", err
+	}
+	tunnel := t.StoredTunnel
+	if existing == nil {
+		if _, err := a.TunnelTemplates.Create(ctx, t.ClientID, &tunnel); err != nil {
+			return "", err
+		}
+		return ActionCreated, nil
+	}
+
+	tunnel.ID = existing.ID
+	tunnel.ClientID = existing.ClientID
+	tunnel.CreatedAt = existing.CreatedAt
+	if reflect.DeepEqual(existing, &tunnel) {
+		return ActionUnchanged, nil
+	}
+	if _, err := a.TunnelTemplates.Update(ctx, t.ClientID, &tunnel); err != nil {
+		return "", err
+	}
+	return ActionUpdated, nil
+}
+
+func (a *Applier) applySchedule(ctx context.Context, s *schedule.Schedule, user string) (string, error) {
+	existing, err := a.Schedules.GetByName(ctx, s.Name)
+	if err != nil {
+		return "", err
+	}
+	sched := &schedule.Schedule{Base: s.Base, Details: s.Details}
+	if existing == nil {
+		if _, err := a.Schedules.Create(ctx, sched, user); err != nil {
+			return "", err
+		}
+		return ActionCreated, nil
+	}
+
+	if existing.Schedule == s.Schedule && existing.Type == s.Type && reflect.DeepEqual(existing.Details, s.Details) {
+		return ActionUnchanged, nil
+	}
+	sched.CreatedAt = existing.CreatedAt
+	sched.CreatedBy = existing.CreatedBy
+	if _, err := a.Schedules.Update(ctx, existing.ID, sched); err != nil {
+		return "", err
+	}
+	return ActionUpdated, nil
+}
+
+func (a *Applier) applyAlertRules(rs *rules.RuleSet) (string, error) {
+	ruleSet := *rs
+	ruleSet.RuleSetID = rules.DefaultRuleSetID
+
+	existing, err := a.AlertRules.LoadRuleSet(rules.DefaultRuleSetID)
+	if err != nil && !errors.Is(err, alertingcap.ErrEntityNotFound) {
+		return "", err
+	}
+	action := ActionCreated
+	if existing != nil {
+		if sameJSON(existing, &ruleSet) {
+			return ActionUnchanged, nil
+		}
+		action = ActionUpdated
+	}
+
+	errs, err := a.AlertRules.SaveRuleSet(&ruleSet)
+	if len(errs) > 0 {
+		msgs := make([]string, 0, len(errs))
+		for _, e := range errs {
+			msgs = append(msgs, fmt.Sprintf("%s: %v", e.Prefix, e.Err))
+		}
+		return "", fmt.Errorf("invalid rule set: %s", strings.Join(msgs, ", "))
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := a.AlertRules.LoadDefaultRuleSet(); err != nil {
+		return "", err
+	}
+	return action, nil
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string{}, a...)
+	b = append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return reflect.DeepEqual(a, b)
+}
+
+func sameJSON(a, b interface{}) bool {
+	aJSON, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bJSON, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(aJSON) == string(bJSON)
+}
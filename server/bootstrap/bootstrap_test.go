@@ -0,0 +1,302 @@
+package bootstrap
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/db/migration/clients"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	alertingcap "github.com/realvnc-labs/rport/plus/capabilities/alerting"
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/entities/rules"
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/entities/validations"
+	"github.com/realvnc-labs/rport/server/api/jobs/schedule"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/clients/storedtunnels"
+	"github.com/realvnc-labs/rport/server/clientsauth"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+var testLog = logger.NewLogger("bootstrap-test", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+
+const testBundle = `
+user_groups:
+  - name: Operators
+    permissions:
+      commands: true
+      tunnels: true
+users:
+  - username: alice
+    password: s3cr3t-pass
+    groups: [Administrators]
+  - username: bob
+    password: s3cr3t-pass
+    groups: [Operators]
+client_auths:
+  - id: site-a
+    password: client-pass
+tunnel_templates:
+  - client_id: web1
+    name: ssh
+    scheme: ssh
+    remote_ip: 127.0.0.1
+    remote_port: 22
+schedules:
+  - name: cleanup
+    schedule: "0 3 * * *"
+    type: command
+    command: /usr/local/bin/cleanup
+    tags:
+      tags: [env:prod]
+      operator: AND
+alert_rules:
+  rules:
+    - id: disconnected
+      severity: high
+`
+
+func TestParse(t *testing.T) {
+	bundle, err := Parse([]byte(testBundle))
+	require.NoError(t, err)
+
+	require.Len(t, bundle.UserGroups, 1)
+	assert.True(t, bundle.UserGroups[0].Permissions.Has(users.PermissionCommands))
+	assert.False(t, bundle.UserGroups[0].Permissions.Has(users.PermissionUploads))
+	assert.Equal(t, []string{"Operators"}, bundle.Users[1].Groups)
+	assert.Equal(t, "site-a", bundle.ClientAuths[0].ID)
+	assert.Equal(t, "web1", bundle.TunnelTemplates[0].ClientID)
+	assert.Equal(t, 22, *bundle.TunnelTemplates[0].RemotePort)
+	assert.Equal(t, []string{"env:prod"}, bundle.Schedules[0].ClientTags.Tags)
+	require.NotNil(t, bundle.AlertRules)
+	assert.Len(t, bundle.AlertRules.Rules, 1)
+
+	testCases := []struct {
+		Name          string
+		Bundle        string
+		ExpectedError string
+	}{
+		{
+			Name:          "empty",
+			ExpectedError: "invalid bootstrap bundle: empty",
+		},
+		{
+			Name:          "unknown field",
+			Bundle:        "users:\n  - username: alice\n    pasword: s3cr3t-pass\n",
+			ExpectedError: `invalid bootstrap bundle: json: unknown field "pasword"`,
+		},
+		{
+			Name:          "duplicate user",
+			Bundle:        "users:\n  - username: alice\n  - username: alice\n",
+			ExpectedError: `invalid bootstrap bundle: users: duplicate username "alice"`,
+		},
+		{
+			Name:          "tunnel template without client",
+			Bundle:        "tunnel_templates:\n  - name: ssh\n",
+			ExpectedError: "invalid bootstrap bundle: tunnel_templates: entry 1: client_id and name are required",
+		},
+		{
+			Name:          "invalid permission",
+			Bundle:        "user_groups:\n  - name: Operators\n    permissions:\n      everything: true\n",
+			ExpectedError: "invalid bootstrap bundle: invalid permission: everything",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.Bundle))
+			assert.EqualError(t, err, tc.ExpectedError)
+		})
+	}
+}
+
+func TestParseFileExpandsEnv(t *testing.T) {
+	t.Setenv("TEST_BOOTSTRAP_PASSWORD", "from-env")
+	file := t.TempDir() + "/bootstrap.yaml"
+	require.NoError(t, os.WriteFile(file, []byte("client_auths:\n  - id: site-a\n    password: ${TEST_BOOTSTRAP_PASSWORD}\n"), 0600))
+
+	bundle, err := ParseFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", bundle.ClientAuths[0].Password)
+}
+
+func TestApply(t *testing.T) {
+	ctx := context.Background()
+	bundle, err := Parse([]byte(testBundle))
+	require.NoError(t, err)
+
+	db, err := sqlite.New(":memory:", clients.AssetNames(), clients.Asset, sqlite.DataSourceOptions{})
+	require.NoError(t, err)
+	userService := &fakeUserService{users: map[string]*users.User{}}
+	schedules := &fakeSchedules{}
+	alertRules := &fakeAlertRules{}
+	applier := &Applier{
+		Users:           userService,
+		ClientAuths:     clientsauth.NewDatabaseMockProvider(nil, t),
+		TunnelTemplates: storedtunnels.New(db),
+		Schedules:       schedules,
+		AlertRules:      alertRules,
+		Logger:          testLog,
+	}
+
+	changes, err := applier.Apply(ctx, bundle, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Kind: KindUserGroup, ID: "Operators", Action: ActionCreated},
+		{Kind: KindUser, ID: "alice", Action: ActionCreated},
+		{Kind: KindUser, ID: "bob", Action: ActionCreated},
+		{Kind: KindClientAuth, ID: "site-a", Action: ActionCreated},
+		{Kind: KindTunnelTemplate, ID: "web1/ssh", Action: ActionCreated},
+		{Kind: KindSchedule, ID: "cleanup", Action: ActionCreated},
+		{Kind: KindAlertRules, ID: string(rules.DefaultRuleSetID), Action: ActionCreated},
+	}, changes)
+	assert.Equal(t, "admin", schedules.items[0].CreatedBy)
+	assert.Equal(t, 1, alertRules.loaded)
+
+	// applying the same bundle again doesn't change anything
+	changes, err = applier.Apply(ctx, bundle, "admin")
+	require.NoError(t, err)
+	for _, c := range changes {
+		assert.Equal(t, ActionUnchanged, c.Action, c.ID)
+	}
+
+	// changed entries are updated, the password of existing users is kept
+	changedBundle, err := Parse([]byte(`
+users:
+  - username: bob
+    password: other-pass
+    groups: [Operators, Administrators]
+client_auths:
+  - id: site-a
+    password: new-client-pass
+tunnel_templates:
+  - client_id: web1
+    name: ssh
+    scheme: ssh
+    remote_ip: 127.0.0.1
+    remote_port: 2222
+schedules:
+  - name: cleanup
+    schedule: "0 4 * * *"
+    type: command
+    command: /usr/local/bin/cleanup
+`))
+	require.NoError(t, err)
+	changes, err = applier.Apply(ctx, changedBundle, "admin")
+	require.NoError(t, err)
+	for _, c := range changes {
+		assert.Equal(t, ActionUpdated, c.Action, c.ID)
+	}
+	assert.Equal(t, "s3cr3t-pass", userService.users["bob"].Password)
+	assert.Equal(t, []string{"Operators", "Administrators"}, userService.users["bob"].Groups)
+	clientAuth, err := applier.ClientAuths.Get("site-a")
+	require.NoError(t, err)
+	assert.Equal(t, "new-client-pass", clientAuth.Password)
+	tunnel, err := applier.TunnelTemplates.GetByName(ctx, "web1", "ssh")
+	require.NoError(t, err)
+	assert.Equal(t, 2222, *tunnel.RemotePort)
+	assert.Equal(t, "0 4 * * *", schedules.items[0].Schedule)
+	assert.Len(t, schedules.items, 1)
+}
+
+func TestApplyUnsupported(t *testing.T) {
+	bundle, err := Parse([]byte("client_auths:\n  - id: site-a\n    password: client-pass\n"))
+	require.NoError(t, err)
+	applier := &Applier{Logger: testLog}
+
+	_, err = applier.Apply(context.Background(), bundle, "admin")
+	assert.ErrorIs(t, err, ErrUnsupported)
+	assert.EqualError(t, err, "client_auths: not supported, client auths are read-only")
+}
+
+type fakeUserService struct {
+	users  map[string]*users.User
+	groups []users.Group
+}
+
+func (s *fakeUserService) GetByUsername(username string) (*users.User, error) {
+	return s.users[username], nil
+}
+
+func (s *fakeUserService) Change(usr *users.User, username string) error {
+	if username == "" {
+		s.users[usr.Username] = usr
+		return nil
+	}
+	if usr.Groups != nil {
+		s.users[username].Groups = usr.Groups
+	}
+	if usr.Password != "" {
+		s.users[username].Password = usr.Password
+	}
+	return nil
+}
+
+func (s *fakeUserService) ListGroups() ([]users.Group, error) {
+	return s.groups, nil
+}
+
+func (s *fakeUserService) UpdateGroup(name string, g users.Group) (users.Group, error) {
+	for i := range s.groups {
+		if s.groups[i].Name == name {
+			s.groups[i] = g
+			return g, nil
+		}
+	}
+	s.groups = append(s.groups, g)
+	return g, nil
+}
+
+type fakeSchedules struct {
+	items []*schedule.Schedule
+}
+
+func (s *fakeSchedules) GetByName(_ context.Context, name string) (*schedule.Schedule, error) {
+	for _, item := range s.items {
+		if item.Name == name {
+			cp := *item
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *fakeSchedules) Create(_ context.Context, sched *schedule.Schedule, user string) (*schedule.Schedule, error) {
+	sched.ID = sched.Name
+	sched.CreatedBy = user
+	s.items = append(s.items, sched)
+	return sched, nil
+}
+
+func (s *fakeSchedules) Update(_ context.Context, id string, sched *schedule.Schedule) (*schedule.Schedule, error) {
+	for i, item := range s.items {
+		if item.ID == id {
+			sched.ID = id
+			s.items[i] = sched
+		}
+	}
+	return sched, nil
+}
+
+type fakeAlertRules struct {
+	ruleSet *rules.RuleSet
+	loaded  int
+}
+
+func (r *fakeAlertRules) LoadRuleSet(rules.RuleSetID) (*rules.RuleSet, error) {
+	if r.ruleSet == nil {
+		return nil, alertingcap.ErrEntityNotFound
+	}
+	return r.ruleSet, nil
+}
+
+func (r *fakeAlertRules) SaveRuleSet(rs *rules.RuleSet) (validations.ErrorList, error) {
+	r.ruleSet = rs
+	return nil, nil
+}
+
+func (r *fakeAlertRules) LoadDefaultRuleSet() error {
+	r.loaded++
+	return nil
+}
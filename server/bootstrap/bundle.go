@@ -0,0 +1,134 @@
+// Package bootstrap applies a declarative bundle of users, groups, client auths, tunnel templates, schedules and alert
+// rules to the server, e.g. to set up a fresh server reproducibly.
+package bootstrap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/entities/rules"
+	"github.com/realvnc-labs/rport/server/api/jobs/schedule"
+	"github.com/realvnc-labs/rport/server/api/users"
+	"github.com/realvnc-labs/rport/server/clients/storedtunnels"
+	"github.com/realvnc-labs/rport/server/clientsauth"
+)
+
+// Bundle is the declarative state of the server. The entries use the same fields as the corresponding API endpoints.
+// Entries are matched by name, existing entries not part of the bundle are left untouched.
+type Bundle struct {
+	UserGroups      []users.Group            `json:"user_groups"`
+	Users           []users.User             `json:"users"`
+	ClientAuths     []clientsauth.ClientAuth `json:"client_auths"`
+	TunnelTemplates []TunnelTemplate         `json:"tunnel_templates"`
+	Schedules       []*schedule.Schedule     `json:"schedules"`
+	// AlertRules replaces the rule set of the rport-plus alerting if set.
+	AlertRules *rules.RuleSet `json:"alert_rules"`
+}
+
+// TunnelTemplate is a stored tunnel of a client, the client doesn't need to be connected yet.
+type TunnelTemplate struct {
+	storedtunnels.StoredTunnel
+	ClientID string `json:"client_id"`
+}
+
+// ParseFile reads a bundle from a yaml or json file. Environment variables like ${ADMIN_PASSWORD} are expanded to
+// keep secrets out of the file.
+func ParseFile(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bootstrap file: %w", err)
+	}
+	return Parse([]byte(os.ExpandEnv(string(data))))
+}
+
+// Parse decodes a bundle from yaml or json. The yaml is converted to json first to reuse the json decoding of the
+// API models, unknown fields are rejected to catch typos.
+func Parse(data []byte) (*Bundle, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid bootstrap bundle: %w", err)
+	}
+	if raw == nil {
+		return nil, errors.New("invalid bootstrap bundle: empty")
+	}
+	jsonData, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bootstrap bundle: %w", err)
+	}
+
+	bundle := &Bundle{}
+	dec := json.NewDecoder(bytes.NewReader(jsonData))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(bundle); err != nil {
+		return nil, fmt.Errorf("invalid bootstrap bundle: %w", err)
+	}
+	if err := bundle.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid bootstrap bundle: %w", err)
+	}
+	return bundle, nil
+}
+
+// Validate checks the identifying fields of the entries, everything else is validated when applied.
+func (b *Bundle) Validate() error {
+	groups := make(map[string]bool, len(b.UserGroups))
+	for i, g := range b.UserGroups {
+		if g.Name == "" {
+			return fmt.Errorf("user_groups: entry %d: name is required", i+1)
+		}
+		if groups[g.Name] {
+			return fmt.Errorf("user_groups: duplicate name %q", g.Name)
+		}
+		groups[g.Name] = true
+	}
+
+	usernames := make(map[string]bool, len(b.Users))
+	for i, u := range b.Users {
+		if u.Username == "" {
+			return fmt.Errorf("users: entry %d: username is required", i+1)
+		}
+		if usernames[u.Username] {
+			return fmt.Errorf("users: duplicate username %q", u.Username)
+		}
+		usernames[u.Username] = true
+	}
+
+	clientAuths := make(map[string]bool, len(b.ClientAuths))
+	for i, ca := range b.ClientAuths {
+		if ca.ID == "" || ca.Password == "" {
+			return fmt.Errorf("client_auths: entry %d: id and password are required", i+1)
+		}
+		if clientAuths[ca.ID] {
+			return fmt.Errorf("client_auths: duplicate id %q", ca.ID)
+		}
+		clientAuths[ca.ID] = true
+	}
+
+	templates := make(map[string]bool, len(b.TunnelTemplates))
+	for i, t := range b.TunnelTemplates {
+		if t.ClientID == "" || t.Name == "" {
+			return fmt.Errorf("tunnel_templates: entry %d: client_id and name are required", i+1)
+		}
+		key := t.ClientID + "/" + t.Name
+		if templates[key] {
+			return fmt.Errorf("tunnel_templates: duplicate name %q of client %q", t.Name, t.ClientID)
+		}
+		templates[key] = true
+	}
+
+	schedules := make(map[string]bool, len(b.Schedules))
+	for i, s := range b.Schedules {
+		if s == nil || s.Name == "" {
+			return fmt.Errorf("schedules: entry %d: name is required", i+1)
+		}
+		if schedules[s.Name] {
+			return fmt.Errorf("schedules: duplicate name %q", s.Name)
+		}
+		schedules[s.Name] = true
+	}
+	return nil
+}
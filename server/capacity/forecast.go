@@ -0,0 +1,111 @@
+package capacity
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	ResourcePorts   = "ports"
+	ResourceClients = "clients"
+)
+
+// Sample is a snapshot of the server usage at a given time.
+type Sample struct {
+	Timestamp        time.Time `json:"timestamp" db:"timestamp"`
+	Clients          int       `json:"clients" db:"clients"`
+	ConnectedClients int       `json:"connected_clients" db:"connected_clients"`
+	MaxClients       int       `json:"max_clients" db:"max_clients"` // 0 means unlimited
+	Tunnels          int       `json:"tunnels" db:"tunnels"`
+	PortsTotal       int       `json:"ports_total" db:"ports_total"`
+	PortsUsed        int       `json:"ports_used" db:"ports_used"`
+}
+
+// Forecast is a linear projection of a limited resource based on its history.
+type Forecast struct {
+	Resource     string     `json:"resource"`
+	Current      int        `json:"current"`
+	Limit        int        `json:"limit"`
+	GrowthPerDay float64    `json:"growth_per_day"`
+	DaysLeft     *float64   `json:"days_left"`   // nil if the resource is not projected to exhaust
+	ExhaustsAt   *time.Time `json:"exhausts_at"` // nil if the resource is not projected to exhaust
+	Warning      bool       `json:"warning"`     // true if the resource exhausts within the warning period
+}
+
+// NewForecasts calculates forecasts for all limited resources. Samples are expected to be sorted by timestamp asc.
+// warnDays is the number of days in which a projected exhaustion is considered a warning, 0 disables warnings.
+func NewForecasts(samples []*Sample, warnDays int) []*Forecast {
+	if len(samples) == 0 {
+		return []*Forecast{}
+	}
+
+	result := make([]*Forecast, 0, 2)
+	last := samples[len(samples)-1]
+
+	if last.PortsTotal > 0 {
+		result = append(result, newForecast(ResourcePorts, samples, last.PortsTotal, warnDays, func(s *Sample) int {
+			return s.PortsUsed
+		}))
+	}
+	if last.MaxClients > 0 {
+		result = append(result, newForecast(ResourceClients, samples, last.MaxClients, warnDays, func(s *Sample) int {
+			return s.ConnectedClients
+		}))
+	}
+
+	return result
+}
+
+func newForecast(resource string, samples []*Sample, limit, warnDays int, valueFn func(*Sample) int) *Forecast {
+	last := samples[len(samples)-1]
+	f := &Forecast{
+		Resource:     resource,
+		Current:      valueFn(last),
+		Limit:        limit,
+		GrowthPerDay: growthPerDay(samples, valueFn),
+	}
+
+	remaining := float64(limit - f.Current)
+	var daysLeft float64
+	switch {
+	case remaining <= 0:
+		daysLeft = 0
+	case f.GrowthPerDay > 0:
+		daysLeft = remaining / f.GrowthPerDay
+	default:
+		return f
+	}
+
+	exhaustsAt := last.Timestamp.Add(time.Duration(daysLeft * float64(24*time.Hour)))
+	f.DaysLeft = &daysLeft
+	f.ExhaustsAt = &exhaustsAt
+	f.Warning = warnDays > 0 && daysLeft <= float64(warnDays)
+	return f
+}
+
+// growthPerDay returns the slope of the least squares regression line of the sampled values.
+func growthPerDay(samples []*Sample, valueFn func(*Sample) int) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+
+	start := samples[0].Timestamp
+	n := float64(len(samples))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, s := range samples {
+		x := s.Timestamp.Sub(start).Hours() / 24
+		y := float64(valueFn(s))
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+
+	slope := (n*sumXY - sumX*sumY) / denominator
+	return math.Round(slope*1000) / 1000
+}
@@ -0,0 +1,127 @@
+package capacity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewForecasts(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	newSamples := func(portsUsed ...int) []*Sample {
+		var samples []*Sample
+		for i, used := range portsUsed {
+			samples = append(samples, &Sample{
+				Timestamp:        start.Add(time.Duration(i) * 24 * time.Hour),
+				ConnectedClients: 5,
+				PortsTotal:       100,
+				PortsUsed:        used,
+			})
+		}
+		return samples
+	}
+
+	testCases := []struct {
+		name            string
+		samples         []*Sample
+		warnDays        int
+		wantGrowth      float64
+		wantDaysLeft    *float64
+		wantWarning     bool
+		wantNoForecasts bool
+	}{
+		{
+			name:            "no samples",
+			wantNoForecasts: true,
+		},
+		{
+			name:       "stable usage",
+			samples:    newSamples(10, 10, 10),
+			warnDays:   14,
+			wantGrowth: 0,
+		},
+		{
+			name:       "decreasing usage",
+			samples:    newSamples(30, 20, 10),
+			warnDays:   14,
+			wantGrowth: -10,
+		},
+		{
+			name:         "growing usage outside of warning period",
+			samples:      newSamples(10, 12, 14),
+			warnDays:     14,
+			wantGrowth:   2,
+			wantDaysLeft: floatPtr(43),
+		},
+		{
+			name:         "growing usage within warning period",
+			samples:      newSamples(60, 70, 80),
+			warnDays:     14,
+			wantGrowth:   10,
+			wantDaysLeft: floatPtr(2),
+			wantWarning:  true,
+		},
+		{
+			name:         "warnings disabled",
+			samples:      newSamples(60, 70, 80),
+			warnDays:     0,
+			wantGrowth:   10,
+			wantDaysLeft: floatPtr(2),
+		},
+		{
+			name:         "exhausted",
+			samples:      newSamples(100, 100),
+			warnDays:     14,
+			wantDaysLeft: floatPtr(0),
+			wantWarning:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			forecasts := NewForecasts(tc.samples, tc.warnDays)
+
+			if tc.wantNoForecasts {
+				assert.Empty(t, forecasts)
+				return
+			}
+			// clients are unlimited, so only ports are forecasted
+			require.Len(t, forecasts, 1)
+			f := forecasts[0]
+			assert.Equal(t, ResourcePorts, f.Resource)
+			assert.Equal(t, 100, f.Limit)
+			assert.Equal(t, tc.wantGrowth, f.GrowthPerDay)
+			assert.Equal(t, tc.wantDaysLeft, f.DaysLeft)
+			assert.Equal(t, tc.wantWarning, f.Warning)
+			if tc.wantDaysLeft != nil {
+				last := tc.samples[len(tc.samples)-1].Timestamp
+				assert.Equal(t, last.Add(time.Duration(*tc.wantDaysLeft*24)*time.Hour), *f.ExhaustsAt)
+			} else {
+				assert.Nil(t, f.ExhaustsAt)
+			}
+		})
+	}
+}
+
+func TestNewForecastsWithMaxClients(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := []*Sample{
+		{Timestamp: start, ConnectedClients: 40, MaxClients: 50},
+		{Timestamp: start.Add(24 * time.Hour), ConnectedClients: 45, MaxClients: 50},
+	}
+
+	forecasts := NewForecasts(samples, 7)
+
+	require.Len(t, forecasts, 1)
+	assert.Equal(t, ResourceClients, forecasts[0].Resource)
+	assert.Equal(t, 45, forecasts[0].Current)
+	assert.Equal(t, 5.0, forecasts[0].GrowthPerDay)
+	assert.Equal(t, floatPtr(1), forecasts[0].DaysLeft)
+	assert.True(t, forecasts[0].Warning)
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
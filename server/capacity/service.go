@@ -0,0 +1,205 @@
+package capacity
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/entities/measures"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/query"
+	"github.com/realvnc-labs/rport/share/random"
+)
+
+const (
+	// ForecastPeriod is the period of history used to project the growth of resources.
+	ForecastPeriod = 30 * 24 * time.Hour
+	// KeepSamples is the period to keep the history of samples.
+	KeepSamples = 365 * 24 * time.Hour
+	// notifyInterval is the minimal interval between repeated warnings about the same resource.
+	notifyInterval = 24 * time.Hour
+
+	// AlertingClientID is the client id of the measurements sent to the alerting service, it doesn't belong to a
+	// real client.
+	AlertingClientID = "rportd-capacity"
+)
+
+var (
+	SupportedFilters = map[string]bool{
+		"timestamp[gt]":    true,
+		"timestamp[lt]":    true,
+		"timestamp[since]": true,
+		"timestamp[until]": true,
+	}
+	SupportedSorts = map[string]bool{
+		"timestamp": true,
+	}
+	ListDefaultSort = map[string][]string{
+		"sort": {"-timestamp"},
+	}
+)
+
+// Source provides the current usage of the server.
+type Source interface {
+	CurrentSample() (*Sample, error)
+}
+
+// MeasurementSink receives the forecasts as measurements, it's implemented by the alerting service.
+type MeasurementSink interface {
+	PutMeasurement(m *measures.Measure) (err error)
+}
+
+type Config struct {
+	// WarnDays is the number of days in which a projected exhaustion triggers a warning, 0 disables warnings.
+	WarnDays int
+}
+
+type Service struct {
+	provider *SQLiteProvider
+	source   Source
+	config   Config
+	logger   *logger.Logger
+	alerting MeasurementSink
+	now      func() time.Time
+
+	lastNotified map[string]time.Time
+	mu           sync.Mutex
+}
+
+func NewService(provider *SQLiteProvider, source Source, config Config, logger *logger.Logger) *Service {
+	return &Service{
+		provider:     provider,
+		source:       source,
+		config:       config,
+		logger:       logger,
+		now:          time.Now,
+		lastNotified: make(map[string]time.Time),
+	}
+}
+
+// SetAlertingService enables sending the forecasts to the alerting service, so rules can raise problems for them.
+func (s *Service) SetAlertingService(alerting MeasurementSink) {
+	s.alerting = alerting
+}
+
+// TakeSample stores the current usage, warns about resources projected to exhaust and purges old samples.
+func (s *Service) TakeSample(ctx context.Context) error {
+	sample, err := s.Current()
+	if err != nil {
+		return err
+	}
+
+	if err := s.provider.Save(ctx, sample); err != nil {
+		return fmt.Errorf("failed to save capacity sample: %w", err)
+	}
+
+	forecasts, err := s.Forecasts(ctx)
+	if err != nil {
+		return err
+	}
+	s.warn(forecasts)
+	s.sendToAlerting(sample.Timestamp, forecasts)
+
+	if _, err := s.provider.DeleteOlderThan(ctx, s.now().Add(-KeepSamples)); err != nil {
+		return fmt.Errorf("failed to delete old capacity samples: %w", err)
+	}
+
+	return nil
+}
+
+// Current returns the current usage of the server without storing it.
+func (s *Service) Current() (*Sample, error) {
+	sample, err := s.source.CurrentSample()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current usage: %w", err)
+	}
+	sample.Timestamp = s.now().UTC()
+	return sample, nil
+}
+
+func (s *Service) Forecasts(ctx context.Context) ([]*Forecast, error) {
+	samples, err := s.provider.ListSince(ctx, s.now().Add(-ForecastPeriod))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list capacity samples: %w", err)
+	}
+	return NewForecasts(samples, s.config.WarnDays), nil
+}
+
+func (s *Service) History(ctx context.Context, options *query.ListOptions) ([]*Sample, int, error) {
+	samples, err := s.provider.List(ctx, options)
+	if err != nil {
+		return nil, 0, err
+	}
+	count, err := s.provider.Count(ctx, options)
+	if err != nil {
+		return nil, 0, err
+	}
+	return samples, count, nil
+}
+
+func (s *Service) warn(forecasts []*Forecast) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, f := range forecasts {
+		if !f.Warning {
+			continue
+		}
+		if last, ok := s.lastNotified[f.Resource]; ok && s.now().Sub(last) < notifyInterval {
+			continue
+		}
+		s.lastNotified[f.Resource] = s.now()
+
+		s.logger.Errorf(
+			"Capacity warning: %s: %d of %d used, projected to exhaust in %.1f day(s) at %s",
+			f.Resource, f.Current, f.Limit, *f.DaysLeft, f.ExhaustsAt.Format(time.RFC3339),
+		)
+	}
+}
+
+// sendToAlerting sends the forecasts as custom metrics of the client AlertingClientID with each sample, so problems
+// raised by rules are resolved once a resource isn't projected to exhaust anymore.
+func (s *Service) sendToAlerting(timestamp time.Time, forecasts []*Forecast) {
+	if s.alerting == nil || len(forecasts) == 0 {
+		return
+	}
+
+	m := &measures.Measure{
+		ClientID:      AlertingClientID,
+		Timestamp:     timestamp,
+		CustomMetrics: ForecastMetrics(forecasts),
+	}
+	var err error
+	m.UID, err = random.UUID4()
+	if err != nil {
+		s.logger.Errorf("Failed to generate capacity measurement id: %v", err)
+		return
+	}
+	if err := s.alerting.PutMeasurement(m); err != nil {
+		s.logger.Errorf("Failed to send capacity forecast to the alerting service: %v", err)
+	}
+}
+
+// ForecastMetrics returns the forecasts as custom metrics named capacity.<resource>.<value>. days_left is only set if
+// the resource is projected to exhaust, warning is 1 if it exhausts within the warning period, otherwise 0.
+func ForecastMetrics(forecasts []*Forecast) map[string]float64 {
+	metrics := make(map[string]float64, 4*len(forecasts))
+	for _, f := range forecasts {
+		prefix := "capacity." + f.Resource + "."
+		metrics[prefix+"used"] = float64(f.Current)
+		metrics[prefix+"limit"] = float64(f.Limit)
+		if f.DaysLeft != nil {
+			metrics[prefix+"days_left"] = *f.DaysLeft
+		}
+		metrics[prefix+"warning"] = 0
+		if f.Warning {
+			metrics[prefix+"warning"] = 1
+		}
+	}
+	return metrics
+}
+
+func (s *Service) Close() error {
+	return s.provider.Close()
+}
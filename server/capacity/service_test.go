@@ -0,0 +1,57 @@
+package capacity
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/entities/measures"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+var testLog = logger.NewLogger("capacity", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+
+type measurementRecorder struct {
+	measures []*measures.Measure
+}
+
+func (r *measurementRecorder) PutMeasurement(m *measures.Measure) error {
+	r.measures = append(r.measures, m)
+	return nil
+}
+
+func TestSendToAlerting(t *testing.T) {
+	s := NewService(nil, nil, Config{WarnDays: 14}, testLog)
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	daysLeft := 3.5
+	exhaustsAt := now.Add(84 * time.Hour)
+	forecasts := []*Forecast{
+		{Resource: ResourcePorts, Current: 90, Limit: 100, DaysLeft: &daysLeft, ExhaustsAt: &exhaustsAt, Warning: true},
+		{Resource: ResourceClients, Current: 5, Limit: 50},
+	}
+
+	// nothing is sent without the alerting service
+	s.sendToAlerting(now, forecasts)
+
+	recorder := &measurementRecorder{}
+	s.SetAlertingService(recorder)
+	s.sendToAlerting(now, forecasts)
+
+	require.Len(t, recorder.measures, 1)
+	m := recorder.measures[0]
+	assert.Equal(t, AlertingClientID, m.ClientID)
+	assert.Equal(t, now, m.Timestamp)
+	assert.NotEmpty(t, m.UID)
+	assert.Equal(t, map[string]float64{
+		"capacity.ports.used":      90,
+		"capacity.ports.limit":     100,
+		"capacity.ports.days_left": 3.5,
+		"capacity.ports.warning":   1,
+		"capacity.clients.used":    5,
+		"capacity.clients.limit":   50,
+		"capacity.clients.warning": 0,
+	}, m.CustomMetrics)
+}
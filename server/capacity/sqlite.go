@@ -0,0 +1,91 @@
+package capacity
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+type SQLiteProvider struct {
+	db        *sqlx.DB
+	converter *query.SQLConverter
+}
+
+func NewSQLiteProvider(db *sqlx.DB) *SQLiteProvider {
+	return &SQLiteProvider{
+		db:        db,
+		converter: query.NewSQLConverter(db.DriverName()),
+	}
+}
+
+func (p *SQLiteProvider) Save(ctx context.Context, s *Sample) error {
+	_, err := p.db.NamedExecContext(
+		ctx,
+		`INSERT INTO samples (
+			timestamp,
+			clients,
+			connected_clients,
+			max_clients,
+			tunnels,
+			ports_total,
+			ports_used
+		) VALUES (
+			:timestamp,
+			:clients,
+			:connected_clients,
+			:max_clients,
+			:tunnels,
+			:ports_total,
+			:ports_used
+		)`,
+		s,
+	)
+	return err
+}
+
+func (p *SQLiteProvider) List(ctx context.Context, options *query.ListOptions) ([]*Sample, error) {
+	values := []*Sample{}
+	q := "SELECT * FROM `samples`"
+	q, params := p.converter.ConvertListOptionsToQuery(options, q)
+	err := p.db.SelectContext(ctx, &values, q, params...)
+	if err != nil {
+		return values, err
+	}
+	return values, nil
+}
+
+func (p *SQLiteProvider) Count(ctx context.Context, options *query.ListOptions) (int, error) {
+	var result int
+	q := "SELECT COUNT(*) FROM `samples`"
+	countOptions := *options
+	countOptions.Pagination = nil
+	countOptions.Sorts = nil
+	q, params := p.converter.ConvertListOptionsToQuery(&countOptions, q)
+	err := p.db.GetContext(ctx, &result, q, params...)
+	if err != nil {
+		return 0, err
+	}
+	return result, nil
+}
+
+// ListSince returns samples taken since a given time sorted by timestamp asc.
+func (p *SQLiteProvider) ListSince(ctx context.Context, since time.Time) ([]*Sample, error) {
+	values := []*Sample{}
+	err := p.db.SelectContext(ctx, &values, "SELECT * FROM samples WHERE timestamp >= ? ORDER BY timestamp ASC", since)
+	return values, err
+}
+
+func (p *SQLiteProvider) DeleteOlderThan(ctx context.Context, ts time.Time) (int64, error) {
+	res, err := p.db.ExecContext(ctx, "DELETE FROM samples WHERE timestamp < ?", ts)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (p *SQLiteProvider) Close() error {
+	return p.db.Close()
+}
@@ -0,0 +1,20 @@
+package capacity
+
+import (
+	"context"
+)
+
+type SampleTask struct {
+	service *Service
+}
+
+// NewSampleTask returns a task to periodically record the server usage.
+func NewSampleTask(service *Service) *SampleTask {
+	return &SampleTask{
+		service: service,
+	}
+}
+
+func (t *SampleTask) Run(ctx context.Context) error {
+	return t.service.TakeSample(ctx)
+}
@@ -0,0 +1,41 @@
+package chserver
+
+import (
+	"github.com/realvnc-labs/rport/server/capacity"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/ports"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+type capacitySource struct {
+	clientService   clients.ClientService
+	portDistributor *ports.PortDistributor
+}
+
+func newCapacitySource(clientService clients.ClientService, portDistributor *ports.PortDistributor) *capacitySource {
+	return &capacitySource{
+		clientService:   clientService,
+		portDistributor: portDistributor,
+	}
+}
+
+func (s *capacitySource) CurrentSample() (*capacity.Sample, error) {
+	portsTotal, portsUsed, err := s.portDistributor.Usage(models.ProtocolTCP)
+	if err != nil {
+		return nil, err
+	}
+
+	tunnels := 0
+	for _, c := range s.clientService.GetAll() {
+		tunnels += len(c.GetTunnels())
+	}
+
+	return &capacity.Sample{
+		Clients:          s.clientService.Count(),
+		ConnectedClients: s.clientService.CountActive(),
+		MaxClients:       s.clientService.GetMaxClients(),
+		Tunnels:          tunnels,
+		PortsTotal:       portsTotal,
+		PortsUsed:        portsUsed,
+	}, nil
+}
@@ -0,0 +1,33 @@
+package capture
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+type CleanupTask struct {
+	log       *logger.Logger
+	store     *Store
+	retention time.Duration
+}
+
+// NewCleanupTask returns a task to delete packet captures older than the retention period
+func NewCleanupTask(log *logger.Logger, store *Store, retention time.Duration) *CleanupTask {
+	return &CleanupTask{
+		log:       log,
+		store:     store,
+		retention: retention,
+	}
+}
+
+func (t *CleanupTask) Run(ctx context.Context) error {
+	deleted, err := t.store.DeleteOlderThan(t.retention)
+	if err != nil {
+		return fmt.Errorf("failed to cleanup packet captures: %v", err)
+	}
+	t.log.Debugf("capture.CleanupTask: %d packet captures deleted", deleted)
+	return nil
+}
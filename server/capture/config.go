@@ -0,0 +1,43 @@
+package capture
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	DefaultMaxDuration = 5 * time.Minute
+	DefaultMaxBytes    = 50 * 1024 * 1024
+	DefaultQuota       = 1024 * 1024 * 1024
+	DefaultRetention   = 7 * 24 * time.Hour
+	capturesDir        = "captures"
+)
+
+type Config struct {
+	Enabled     bool          `mapstructure:"capture_enabled"`
+	MaxDuration time.Duration `mapstructure:"capture_max_duration"`
+	MaxBytes    int64         `mapstructure:"capture_max_bytes"`
+	Quota       int64         `mapstructure:"capture_quota"`
+	Retention   time.Duration `mapstructure:"capture_retention"`
+}
+
+func (c *Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.MaxDuration <= 0 {
+		return errors.New("server.capture_max_duration must be greater than 0")
+	}
+	if c.MaxBytes <= 0 {
+		return errors.New("server.capture_max_bytes must be greater than 0")
+	}
+	if c.Quota < c.MaxBytes {
+		return errors.New("server.capture_quota cannot be less than server.capture_max_bytes")
+	}
+	if c.Retention < 0 {
+		return errors.New("server.capture_retention cannot be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,287 @@
+package capture
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+const (
+	fileExt = ".pcap"
+	// ContentType is the media type of the stored captures
+	ContentType = "application/vnd.tcpdump.pcap"
+)
+
+var (
+	ErrNotFound      = errors.New("packet capture not found")
+	ErrQuotaExceeded = errors.New("packet capture quota exceeded")
+	ErrLimitReached  = errors.New("packet capture size limit reached")
+
+	validIDRegex = regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+)
+
+// Store keeps the pcap files of packet capture jobs in a directory. The total size of all files is limited by the
+// quota, the size of the files being written counts as soon as the data is written.
+type Store struct {
+	dir    string
+	quota  int64
+	logger *logger.Logger
+	now    func() time.Time
+
+	used int64
+	// pending are the writers of captures created but not yet attached to the data stream of the client
+	pending map[string]*captureWriter
+	mu      sync.Mutex
+}
+
+func NewStore(dataDir string, quota int64, logger *logger.Logger) (*Store, error) {
+	dir := filepath.Join(dataDir, capturesDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create packet captures dir %q: %w", dir, err)
+	}
+
+	s := &Store{
+		dir:     dir,
+		quota:   quota,
+		logger:  logger,
+		now:     time.Now,
+		pending: make(map[string]*captureWriter),
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read packet captures dir: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != fileExt {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		s.used += info.Size()
+	}
+
+	return s, nil
+}
+
+// Used returns the total size of all stored captures.
+func (s *Store) Used() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.used
+}
+
+// CheckQuota returns ErrQuotaExceeded if there is no space left for new captures.
+func (s *Store) CheckQuota() error {
+	if s.Used() >= s.quota {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+// Create creates the capture of the given job before the capture is requested from the client, so the limits
+// are set by the server. The client data is written to it after Attach.
+func (s *Store) Create(jid, clientID string, maxBytes int64) error {
+	if !validIDRegex.MatchString(jid) {
+		return fmt.Errorf("invalid job id %q", jid)
+	}
+	if err := s.CheckQuota(); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.path(jid), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create packet capture: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending[jid] = &captureWriter{
+		store:    s,
+		file:     f,
+		clientID: clientID,
+		maxBytes: maxBytes,
+	}
+	return nil
+}
+
+// Attach returns the writer of a created capture of the given client. Writes fail with ErrLimitReached after
+// maxBytes and with ErrQuotaExceeded if the quota is used up, the data up to the limit is kept.
+// A capture can be attached only once.
+func (s *Store) Attach(jid, clientID string) (io.WriteCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w := s.pending[jid]
+	if w == nil || w.clientID != clientID {
+		return nil, ErrNotFound
+	}
+	delete(s.pending, jid)
+	return w, nil
+}
+
+// Abort deletes a created capture that wasn't attached, e.g. because the client failed to start it.
+func (s *Store) Abort(jid string) {
+	s.mu.Lock()
+	w := s.pending[jid]
+	delete(s.pending, jid)
+	s.mu.Unlock()
+	if w == nil {
+		return
+	}
+
+	if err := w.Close(); err != nil {
+		s.logger.Errorf("Failed to close packet capture %q: %v", jid, err)
+	}
+	if err := s.Delete(jid); err != nil {
+		s.logger.Errorf("Failed to delete packet capture %q: %v", jid, err)
+	}
+}
+
+// Open returns the pcap file of the given job, the caller is responsible for closing it.
+func (s *Store) Open(jid string) (*os.File, error) {
+	if !validIDRegex.MatchString(jid) {
+		return nil, ErrNotFound
+	}
+
+	f, err := os.Open(s.path(jid))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (s *Store) Delete(jid string) error {
+	if !validIDRegex.MatchString(jid) {
+		return ErrNotFound
+	}
+
+	info, err := os.Stat(s.path(jid))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if err := os.Remove(s.path(jid)); err != nil {
+		return err
+	}
+	s.release(info.Size())
+	return nil
+}
+
+// DeleteOlderThan removes captures that were not written to since the given period.
+func (s *Store) DeleteOlderThan(period time.Duration) (int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read packet captures dir: %w", err)
+	}
+
+	deadline := s.now().Add(-period)
+	deleted := 0
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != fileExt {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return deleted, err
+		}
+		if info.ModTime().After(deadline) {
+			continue
+		}
+		s.closePending(strings.TrimSuffix(entry.Name(), fileExt))
+		if err := os.Remove(filepath.Join(s.dir, entry.Name())); err != nil {
+			return deleted, err
+		}
+		s.release(info.Size())
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// closePending closes the writer of a capture that was never attached, e.g. because the client disconnected.
+func (s *Store) closePending(jid string) {
+	s.mu.Lock()
+	w := s.pending[jid]
+	delete(s.pending, jid)
+	s.mu.Unlock()
+	if w != nil {
+		_ = w.Close()
+	}
+}
+
+// reserve adds up to n bytes to the used space and returns the number of bytes that fit into the quota.
+func (s *Store) reserve(n int64) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if free := s.quota - s.used; n > free {
+		n = free
+	}
+	if n < 0 {
+		n = 0
+	}
+	s.used += n
+	return n
+}
+
+func (s *Store) release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.used -= n
+	if s.used < 0 {
+		s.used = 0
+	}
+}
+
+func (s *Store) path(jid string) string {
+	return filepath.Join(s.dir, jid+fileExt)
+}
+
+type captureWriter struct {
+	store    *Store
+	file     *os.File
+	clientID string
+	maxBytes int64
+	written  int64
+}
+
+func (w *captureWriter) Write(p []byte) (int, error) {
+	var limitErr error
+	if left := w.maxBytes - w.written; int64(len(p)) > left {
+		p = p[:left]
+		limitErr = ErrLimitReached
+	}
+	if reserved := w.store.reserve(int64(len(p))); reserved < int64(len(p)) {
+		p = p[:reserved]
+		limitErr = ErrQuotaExceeded
+	}
+
+	n, err := w.file.Write(p)
+	w.written += int64(n)
+	if unused := int64(len(p) - n); unused > 0 {
+		w.store.release(unused)
+	}
+	if err != nil {
+		return n, err
+	}
+	return n, limitErr
+}
+
+func (w *captureWriter) Close() error {
+	return w.file.Close()
+}
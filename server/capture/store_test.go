@@ -0,0 +1,109 @@
+package capture
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+var testLog = logger.NewLogger("capture", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+
+func TestStoreLimits(t *testing.T) {
+	dataDir := t.TempDir()
+	store, err := NewStore(dataDir, 10, testLog)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Create("job-1", "client-1", 6))
+	_, err = store.Attach("job-1", "client-2")
+	assert.ErrorIs(t, err, ErrNotFound)
+	w, err := store.Attach("job-1", "client-1")
+	require.NoError(t, err)
+	_, err = store.Attach("job-1", "client-1")
+	assert.ErrorIs(t, err, ErrNotFound)
+	n, err := w.Write([]byte("1234"))
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+	n, err = w.Write([]byte("5678"))
+	assert.ErrorIs(t, err, ErrLimitReached)
+	assert.Equal(t, 2, n)
+	require.NoError(t, w.Close())
+
+	require.NoError(t, store.Create("job-2", "client-1", 6))
+	w, err = store.Attach("job-2", "client-1")
+	require.NoError(t, err)
+	n, err = w.Write([]byte("abcdef"))
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	assert.Equal(t, 4, n)
+	require.NoError(t, w.Close())
+	assert.Equal(t, int64(10), store.Used())
+
+	err = store.Create("job-3", "client-1", 6)
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+
+	f, err := store.Open("job-1")
+	require.NoError(t, err)
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	assert.Equal(t, "123456", string(data))
+
+	require.NoError(t, store.Delete("job-1"))
+	assert.Equal(t, int64(4), store.Used())
+	assert.ErrorIs(t, store.Delete("job-1"), ErrNotFound)
+
+	// the used space is restored on restart
+	store, err = NewStore(dataDir, 10, testLog)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), store.Used())
+}
+
+func TestStoreInvalidID(t *testing.T) {
+	store, err := NewStore(t.TempDir(), 10, testLog)
+	require.NoError(t, err)
+
+	err = store.Create("../job", "client-1", 6)
+	assert.Error(t, err)
+	_, err = store.Open("../job")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestStoreAbort(t *testing.T) {
+	store, err := NewStore(t.TempDir(), 10, testLog)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Create("job-1", "client-1", 6))
+	store.Abort("job-1")
+
+	_, err = store.Attach("job-1", "client-1")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = store.Open("job-1")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestStoreDeleteOlderThan(t *testing.T) {
+	store, err := NewStore(t.TempDir(), 100, testLog)
+	require.NoError(t, err)
+	for _, jid := range []string{"old", "new"} {
+		require.NoError(t, store.Create(jid, "client-1", 10))
+		w, err := store.Attach(jid, "client-1")
+		require.NoError(t, err)
+		_, err = w.Write([]byte("data"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(store.path("old"), old, old))
+
+	deleted, err := store.DeleteOlderThan(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.Equal(t, int64(4), store.Used())
+	_, err = store.Open("old")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
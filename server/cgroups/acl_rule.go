@@ -0,0 +1,78 @@
+package cgroups
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ACLRule grants user groups access to clients that match a tag and/or a client auth id pattern.
+// Rules are applied when a client connects. Patterns support wildcards like client group params.
+type ACLRule struct {
+	Tag          Param    `mapstructure:"tag"`
+	ClientAuthID Param    `mapstructure:"client_auth_id"`
+	UserGroups   []string `mapstructure:"user_groups"`
+}
+
+func (r *ACLRule) Validate() error {
+	if r.Tag == "" && r.ClientAuthID == "" {
+		return errors.New("either tag or client_auth_id is required")
+	}
+	if len(r.UserGroups) == 0 {
+		return errors.New("user_groups cannot be empty")
+	}
+	for _, userGroup := range r.UserGroups {
+		if userGroup == "" {
+			return errors.New("user_groups cannot contain an empty group")
+		}
+	}
+	return nil
+}
+
+// Matches returns true if all patterns set in the rule match the client.
+func (r *ACLRule) Matches(tags []string, clientAuthID string) bool {
+	if r.ClientAuthID != "" && !r.ClientAuthID.matches(clientAuthID) {
+		return false
+	}
+	if r.Tag == "" {
+		return true
+	}
+	for _, tag := range tags {
+		if r.Tag.matches(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func ValidateACLRules(rules []ACLRule) error {
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return fmt.Errorf("invalid acl rule %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// ApplyACLRules returns the given user groups extended by the user groups of all matching rules, sorted and without duplicates.
+func ApplyACLRules(rules []ACLRule, userGroups []string, tags []string, clientAuthID string) []string {
+	set := make(map[string]bool, len(userGroups))
+	for _, userGroup := range userGroups {
+		set[userGroup] = true
+	}
+	for i := range rules {
+		if !rules[i].Matches(tags, clientAuthID) {
+			continue
+		}
+		for _, userGroup := range rules[i].UserGroups {
+			set[userGroup] = true
+		}
+	}
+
+	res := make([]string, 0, len(set))
+	for userGroup := range set {
+		res = append(res, userGroup)
+	}
+	sort.Strings(res)
+	return res
+}
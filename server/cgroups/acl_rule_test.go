@@ -0,0 +1,98 @@
+package cgroups
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestACLRuleValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		rule    ACLRule
+		wantErr string
+	}{
+		{
+			name: "tag",
+			rule: ACLRule{Tag: "customerA", UserGroups: []string{"customerA"}},
+		},
+		{
+			name: "client auth id",
+			rule: ACLRule{ClientAuthID: "customer-a-*", UserGroups: []string{"customerA"}},
+		},
+		{
+			name:    "no patterns",
+			rule:    ACLRule{UserGroups: []string{"customerA"}},
+			wantErr: "either tag or client_auth_id is required",
+		},
+		{
+			name:    "no user groups",
+			rule:    ACLRule{Tag: "customerA"},
+			wantErr: "user_groups cannot be empty",
+		},
+		{
+			name:    "empty user group",
+			rule:    ACLRule{Tag: "customerA", UserGroups: []string{""}},
+			wantErr: "user_groups cannot contain an empty group",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.rule.Validate()
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestApplyACLRules(t *testing.T) {
+	rules := []ACLRule{
+		{Tag: "customerA", UserGroups: []string{"customerA"}},
+		{ClientAuthID: "customer-b-*", UserGroups: []string{"customerB", "support"}},
+		{Tag: "linux", ClientAuthID: "customer-b-*", UserGroups: []string{"linux-admins"}},
+	}
+
+	testCases := []struct {
+		name         string
+		userGroups   []string
+		tags         []string
+		clientAuthID string
+		want         []string
+	}{
+		{
+			name:         "no match",
+			userGroups:   []string{"existing"},
+			tags:         []string{"customerC"},
+			clientAuthID: "customer-c-1",
+			want:         []string{"existing"},
+		},
+		{
+			name:         "tag match, case insensitive",
+			tags:         []string{"CustomerA"},
+			clientAuthID: "customer-a-1",
+			want:         []string{"customerA"},
+		},
+		{
+			name:         "client auth id match keeps existing groups",
+			userGroups:   []string{"support", "existing"},
+			clientAuthID: "customer-b-1",
+			want:         []string{"customerB", "existing", "support"},
+		},
+		{
+			name:         "tag and client auth id must both match",
+			tags:         []string{"linux"},
+			clientAuthID: "customer-b-1",
+			want:         []string{"customerB", "linux-admins", "support"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ApplyACLRules(rules, tc.userGroups, tc.tags, tc.clientAuthID))
+		})
+	}
+}
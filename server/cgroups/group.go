@@ -11,7 +11,11 @@ import (
 	"github.com/realvnc-labs/rport/share/types"
 )
 
-const OptionsResource = "client_groups"
+const (
+	OptionsResource = "client_groups"
+	// MaxBannerLength is the max length of session banners, of the global one and those of client groups.
+	MaxBannerLength = 4096
+)
 
 var OptionsSupportedFiltersAndSorts = map[string]bool{
 	"id":          true,
@@ -24,6 +28,8 @@ var OptionsSupportedFields = map[string]map[string]bool{
 		"description":           true,
 		"params":                true,
 		"allowed_user_groups":   true,
+		"banner":                true,
+		"tunnel_bind_address":   true,
 		"client_ids":            true,
 		"num_clients":           true,
 		"num_clients_connected": true,
@@ -46,6 +52,10 @@ type ClientGroup struct {
 	Description       string            `json:"description" db:"description"`
 	Params            *ClientParams     `json:"params" db:"params"`
 	AllowedUserGroups types.StringSlice `json:"allowed_user_groups" db:"allowed_user_groups"`
+	// Banner is shown to users at the start of interactive sessions on clients of the group, e.g. a legal notice.
+	Banner string `json:"banner" db:"banner"`
+	// TunnelBindAddress is the default server address tunnels to clients of the group listen on, if not given per tunnel.
+	TunnelBindAddress string `json:"tunnel_bind_address" db:"tunnel_bind_address"`
 	// ClientIDs shows what clients belong to a given group. Note: it's populated separately.
 	ClientIDs []string `json:"client_ids" db:"-"`
 }
@@ -65,6 +75,15 @@ type ClientParams struct {
 	Address         *ParamValues     `json:"address"`
 	ClientAuthID    *ParamValues     `json:"client_auth_id"`
 	ConnectionState *ParamValues     `json:"connection_state"`
+
+	// The following params match the live monitoring and alerting state, e.g. to run remediation jobs on
+	// unhealthy clients only. Clients without updates status don't match the updates params.
+	UpdatesAvailable         *NumberCondition `json:"updates_available,omitempty"`
+	SecurityUpdatesAvailable *NumberCondition `json:"security_updates_available,omitempty"`
+	RebootPending            *bool            `json:"reboot_pending,omitempty"`
+	// OpenProblems and OpenProblemMinSeverity match the active problems of the alerting service.
+	OpenProblems           *NumberCondition `json:"open_problems,omitempty"`
+	OpenProblemMinSeverity *string          `json:"open_problem_min_severity,omitempty"`
 }
 
 type Param string
@@ -0,0 +1,89 @@
+package cgroups
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/entities/severity"
+)
+
+var numberConditionRegexp = regexp.MustCompile(`^(>=|<=|!=|>|<|=)?\s*(\d+)$`)
+
+// severityRanks orders the severities of alerting rules, unknown severities rank 0.
+var severityRanks = map[string]int{
+	strings.ToLower(string(severity.Information)): 1,
+	strings.ToLower(string(severity.Warning)):     2,
+	strings.ToLower(string(severity.Average)):     3,
+	strings.ToLower(string(severity.High)):        4,
+	strings.ToLower(string(severity.Disaster)):    5,
+}
+
+// NumberCondition compares a number, e.g. ">10", ">=1", "<5", "!=0" or "0" for equality.
+type NumberCondition string
+
+func (c *NumberCondition) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if !numberConditionRegexp.MatchString(strings.TrimSpace(string(*c))) {
+		return fmt.Errorf("invalid condition %q, expected a number optionally prefixed by one of >, >=, <, <=, =, !=", *c)
+	}
+	return nil
+}
+
+// Matches returns true if the condition is not set or the number fulfills it.
+func (c *NumberCondition) Matches(n int) bool {
+	if c == nil {
+		return true
+	}
+	m := numberConditionRegexp.FindStringSubmatch(strings.TrimSpace(string(*c)))
+	if m == nil {
+		return false
+	}
+	v, err := strconv.Atoi(m[2])
+	if err != nil {
+		return false
+	}
+	switch m[1] {
+	case ">":
+		return n > v
+	case ">=":
+		return n >= v
+	case "<":
+		return n < v
+	case "<=":
+		return n <= v
+	case "!=":
+		return n != v
+	default:
+		return n == v
+	}
+}
+
+// SeverityRank returns the rank of the severity of an alerting rule, the higher the more severe, 0 if unknown.
+func SeverityRank(s string) int {
+	return severityRanks[strings.ToLower(s)]
+}
+
+// ValidateHealthParams checks the params matching the monitoring and alerting state of clients.
+func (p *ClientParams) ValidateHealthParams() error {
+	if p == nil {
+		return nil
+	}
+	if err := p.UpdatesAvailable.Validate(); err != nil {
+		return fmt.Errorf("updates_available: %v", err)
+	}
+	if err := p.SecurityUpdatesAvailable.Validate(); err != nil {
+		return fmt.Errorf("security_updates_available: %v", err)
+	}
+	if err := p.OpenProblems.Validate(); err != nil {
+		return fmt.Errorf("open_problems: %v", err)
+	}
+	if p.OpenProblemMinSeverity != nil && SeverityRank(*p.OpenProblemMinSeverity) == 0 {
+		return fmt.Errorf("open_problem_min_severity: unknown severity %q, expected one of %s, %s, %s, %s, %s",
+			*p.OpenProblemMinSeverity, severity.Information, severity.Warning, severity.Average, severity.High, severity.Disaster)
+	}
+	return nil
+}
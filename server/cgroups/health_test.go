@@ -0,0 +1,91 @@
+package cgroups
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumberConditionMatches(t *testing.T) {
+	testCases := []struct {
+		condition string
+		n         int
+		wantRes   bool
+	}{
+		{condition: ">10", n: 11, wantRes: true},
+		{condition: ">10", n: 10, wantRes: false},
+		{condition: ">= 1", n: 1, wantRes: true},
+		{condition: "<5", n: 5, wantRes: false},
+		{condition: "<=5", n: 5, wantRes: true},
+		{condition: "=0", n: 0, wantRes: true},
+		{condition: "0", n: 1, wantRes: false},
+		{condition: "!=0", n: 3, wantRes: true},
+		{condition: "!=0", n: 0, wantRes: false},
+		{condition: "invalid", n: 0, wantRes: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.condition, func(t *testing.T) {
+			c := NumberCondition(tc.condition)
+			assert.Equal(t, tc.wantRes, c.Matches(tc.n))
+		})
+	}
+
+	var unset *NumberCondition
+	assert.True(t, unset.Matches(100))
+}
+
+func TestValidateHealthParams(t *testing.T) {
+	cond := func(s string) *NumberCondition {
+		c := NumberCondition(s)
+		return &c
+	}
+	str := func(s string) *string {
+		return &s
+	}
+
+	testCases := []struct {
+		name    string
+		params  *ClientParams
+		wantErr string
+	}{
+		{
+			name:   "no params",
+			params: nil,
+		},
+		{
+			name: "valid",
+			params: &ClientParams{
+				UpdatesAvailable:       cond(">10"),
+				OpenProblems:           cond(">=1"),
+				OpenProblemMinSeverity: str("high"),
+			},
+		},
+		{
+			name:    "invalid updates condition",
+			params:  &ClientParams{UpdatesAvailable: cond("> ten")},
+			wantErr: `updates_available: invalid condition "> ten", expected a number optionally prefixed by one of >, >=, <, <=, =, !=`,
+		},
+		{
+			name:    "negative number",
+			params:  &ClientParams{OpenProblems: cond("-1")},
+			wantErr: `open_problems: invalid condition "-1", expected a number optionally prefixed by one of >, >=, <, <=, =, !=`,
+		},
+		{
+			name:    "unknown severity",
+			params:  &ClientParams{OpenProblemMinSeverity: str("critical")},
+			wantErr: `open_problem_min_severity: unknown severity "critical", expected one of Information, Warning, Average, High, Disaster`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.params.ValidateHealthParams()
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
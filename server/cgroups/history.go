@@ -0,0 +1,108 @@
+package cgroups
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/realvnc-labs/rport/share/types"
+)
+
+const (
+	HistoryActionCreate = "create"
+	HistoryActionUpdate = "update"
+	HistoryActionDelete = "delete"
+	HistoryActionRevert = "revert"
+)
+
+var (
+	HistorySupportedFilters = map[string]bool{
+		"action":           true,
+		"username":         true,
+		"timestamp[gt]":    true,
+		"timestamp[lt]":    true,
+		"timestamp[since]": true,
+		"timestamp[until]": true,
+	}
+	HistorySupportedSorts = map[string]bool{
+		"id":        true,
+		"timestamp": true,
+	}
+	HistoryListDefaultSort = map[string][]string{
+		"sort": {"-id"},
+	}
+)
+
+// Definition is what defines a client group, without the clients populated at runtime.
+type Definition struct {
+	Description       string            `json:"description"`
+	Params            *ClientParams     `json:"params"`
+	AllowedUserGroups types.StringSlice `json:"allowed_user_groups"`
+	Banner            string            `json:"banner"`
+	TunnelBindAddress string            `json:"tunnel_bind_address,omitempty"`
+}
+
+// Definition returns the current definition of the group.
+func (g *ClientGroup) Definition() *Definition {
+	return &Definition{
+		Description:       g.Description,
+		Params:            g.Params,
+		AllowedUserGroups: g.AllowedUserGroups,
+		Banner:            g.Banner,
+		TunnelBindAddress: g.TunnelBindAddress,
+	}
+}
+
+// ClientGroup returns a group with the given id and definition.
+func (d *Definition) ClientGroup(id string) *ClientGroup {
+	return &ClientGroup{
+		ID:                id,
+		Description:       d.Description,
+		Params:            d.Params,
+		AllowedUserGroups: d.AllowedUserGroups,
+		Banner:            d.Banner,
+		TunnelBindAddress: d.TunnelBindAddress,
+	}
+}
+
+func (d *Definition) Scan(value interface{}) error {
+	if d == nil {
+		return errors.New("'definition' cannot be nil")
+	}
+	valueStr, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("expected to have string, got %T", value)
+	}
+	err := json.Unmarshal([]byte(valueStr), d)
+	if err != nil {
+		return fmt.Errorf("failed to decode 'definition' field: %v", err)
+	}
+	return nil
+}
+
+func (d *Definition) Value() (driver.Value, error) {
+	if d == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode 'definition' field: %v", err)
+	}
+	return string(b), nil
+}
+
+// HistoryEntry is a change of a client group. Before is nil if the group was created, After is nil if the group was
+// deleted.
+type HistoryEntry struct {
+	ID        int64       `json:"id" db:"id"`
+	GroupID   string      `json:"group_id" db:"group_id"`
+	Timestamp time.Time   `json:"timestamp" db:"timestamp"`
+	Username  string      `json:"username" db:"username"`
+	Action    string      `json:"action" db:"action"`
+	Before    *Definition `json:"before" db:"old_definition"`
+	After     *Definition `json:"after" db:"new_definition"`
+	// RevertedFrom is the id of the entry whose definition was restored by a revert.
+	RevertedFrom *int64 `json:"reverted_from,omitempty" db:"reverted_from"`
+}
@@ -16,6 +16,10 @@ type ClientGroupProvider interface {
 	Create(ctx context.Context, group *ClientGroup) error
 	Update(ctx context.Context, group *ClientGroup) error
 	Delete(ctx context.Context, id string) error
+	InsertHistory(ctx context.Context, entry *HistoryEntry) error
+	ListHistory(ctx context.Context, options *query.ListOptions) ([]*HistoryEntry, error)
+	CountHistory(ctx context.Context, options *query.ListOptions) (int, error)
+	GetHistory(ctx context.Context, groupID string, id int64) (*HistoryEntry, error)
 	Close() error
 }
 
@@ -76,7 +80,7 @@ func (p *SqliteProvider) Get(ctx context.Context, id string) (*ClientGroup, erro
 func (p *SqliteProvider) Create(ctx context.Context, group *ClientGroup) error {
 	_, err := p.db.NamedExecContext(
 		ctx,
-		"INSERT INTO client_groups (id, description, params, allowed_user_groups) VALUES (:id, :description, :params, :allowed_user_groups)",
+		"INSERT INTO client_groups (id, description, params, allowed_user_groups, banner, tunnel_bind_address) VALUES (:id, :description, :params, :allowed_user_groups, :banner, :tunnel_bind_address)",
 		group,
 	)
 	return err
@@ -85,7 +89,7 @@ func (p *SqliteProvider) Create(ctx context.Context, group *ClientGroup) error {
 func (p *SqliteProvider) Update(ctx context.Context, group *ClientGroup) error {
 	_, err := p.db.NamedExecContext(
 		ctx,
-		"INSERT OR REPLACE INTO client_groups (id, description, params, allowed_user_groups) VALUES (:id, :description, :params, :allowed_user_groups)",
+		"INSERT OR REPLACE INTO client_groups (id, description, params, allowed_user_groups, banner, tunnel_bind_address) VALUES (:id, :description, :params, :allowed_user_groups, :banner, :tunnel_bind_address)",
 		group,
 	)
 	return err
@@ -96,6 +100,54 @@ func (p *SqliteProvider) Delete(ctx context.Context, id string) error {
 	return err
 }
 
+func (p *SqliteProvider) InsertHistory(ctx context.Context, entry *HistoryEntry) error {
+	res, err := p.db.NamedExecContext(
+		ctx,
+		"INSERT INTO client_group_history (group_id, timestamp, username, action, old_definition, new_definition, reverted_from) VALUES (:group_id, :timestamp, :username, :action, :old_definition, :new_definition, :reverted_from)",
+		entry,
+	)
+	if err != nil {
+		return err
+	}
+	entry.ID, err = res.LastInsertId()
+	return err
+}
+
+func (p *SqliteProvider) ListHistory(ctx context.Context, options *query.ListOptions) ([]*HistoryEntry, error) {
+	res := []*HistoryEntry{}
+	q, params := p.converter.ConvertListOptionsToQuery(options, "SELECT * FROM client_group_history")
+	err := p.db.SelectContext(ctx, &res, q, params...)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *SqliteProvider) CountHistory(ctx context.Context, options *query.ListOptions) (int, error) {
+	var result int
+	countOptions := *options
+	countOptions.Pagination = nil
+	countOptions.Sorts = nil
+	q, params := p.converter.ConvertListOptionsToQuery(&countOptions, "SELECT COUNT(*) FROM client_group_history")
+	err := p.db.GetContext(ctx, &result, q, params...)
+	if err != nil {
+		return 0, err
+	}
+	return result, nil
+}
+
+func (p *SqliteProvider) GetHistory(ctx context.Context, groupID string, id int64) (*HistoryEntry, error) {
+	res := &HistoryEntry{}
+	err := p.db.GetContext(ctx, res, "SELECT * FROM client_group_history WHERE group_id = ? AND id = ?", groupID, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
 func (p *SqliteProvider) Close() error {
 	return p.db.Close()
 }
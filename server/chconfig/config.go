@@ -20,6 +20,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/realvnc-labs/rport/db/pool"
 	"github.com/realvnc-labs/rport/db/sqlite"
 	rportplus "github.com/realvnc-labs/rport/plus"
 	"github.com/realvnc-labs/rport/server/caddy"
@@ -31,56 +32,111 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/realvnc-labs/rport/server/api/message"
+	"github.com/realvnc-labs/rport/server/api/middleware"
+	"github.com/realvnc-labs/rport/server/api/policy"
 	auditlog "github.com/realvnc-labs/rport/server/auditlog/config"
+	"github.com/realvnc-labs/rport/server/autotags"
+	"github.com/realvnc-labs/rport/server/bandwidth"
 	"github.com/realvnc-labs/rport/server/bearer"
+	"github.com/realvnc-labs/rport/server/capture"
+	"github.com/realvnc-labs/rport/server/cgroups"
+	"github.com/realvnc-labs/rport/server/clientpayload"
 	"github.com/realvnc-labs/rport/server/clients/clienttunnel"
+	"github.com/realvnc-labs/rport/server/clientsnapshot"
+	"github.com/realvnc-labs/rport/server/clienttags"
+	"github.com/realvnc-labs/rport/server/clientversion"
+	"github.com/realvnc-labs/rport/server/featureflags"
+	"github.com/realvnc-labs/rport/server/firewall"
+	"github.com/realvnc-labs/rport/server/hooks"
+	"github.com/realvnc-labs/rport/server/i18n"
+	"github.com/realvnc-labs/rport/server/maintenance"
+	"github.com/realvnc-labs/rport/server/monitoringprofiles"
 	"github.com/realvnc-labs/rport/server/ports"
+	"github.com/realvnc-labs/rport/server/preconnect"
+	"github.com/realvnc-labs/rport/server/redaction"
+	"github.com/realvnc-labs/rport/server/reversetunnel"
+	"github.com/realvnc-labs/rport/server/sessionrecording"
+	"github.com/realvnc-labs/rport/server/tunnelapproval"
+	"github.com/realvnc-labs/rport/server/tunnelbind"
+	"github.com/realvnc-labs/rport/server/tunnelschemes"
+	"github.com/realvnc-labs/rport/server/webpush"
 	chshare "github.com/realvnc-labs/rport/share"
 	"github.com/realvnc-labs/rport/share/email"
 	"github.com/realvnc-labs/rport/share/logger"
 )
 
 type APIConfig struct {
-	Address                string   `mapstructure:"address"`
-	BaseURL                string   `mapstructure:"base_url"`
-	EnableAcme             bool     `mapstructure:"enable_acme"`
-	Auth                   string   `mapstructure:"auth"`
-	AuthFile               string   `mapstructure:"auth_file"`
-	AuthUserTable          string   `mapstructure:"auth_user_table"`
-	AuthGroupTable         string   `mapstructure:"auth_group_table"`
-	AuthGroupDetailsTable  string   `mapstructure:"auth_group_details_table"`
-	AuthHeader             string   `mapstructure:"auth_header"`
-	UserHeader             string   `mapstructure:"user_header"`
-	CreateMissingUsers     bool     `mapstructure:"create_missing_users"`
-	DefaultUserGroup       string   `mapstructure:"default_user_group"`
-	JWTSecret              string   `mapstructure:"jwt_secret"`
-	DocRoot                string   `mapstructure:"doc_root"`
-	CertFile               string   `mapstructure:"cert_file"`
-	KeyFile                string   `mapstructure:"key_file"`
-	AccessLogFile          string   `mapstructure:"access_log_file"`
-	UserLoginWait          float32  `mapstructure:"user_login_wait"`
-	MaxFailedLogin         int      `mapstructure:"max_failed_login"`
-	BanTime                int      `mapstructure:"ban_time"`
-	MaxTokenLifeTimeHours  int      `mapstructure:"max_token_lifetime"`
-	PasswordMinLength      int      `mapstructure:"password_min_length"`
-	PasswordZxcvbnMinscore int      `mapstructure:"password_zxcvbn_minscore"`
-	TLSMin                 string   `mapstructure:"tls_min"`
-	EnableWsTestEndpoints  bool     `mapstructure:"enable_ws_test_endpoints"`
-	MaxRequestBytes        int64    `mapstructure:"max_request_bytes"`
-	MaxFilePushSize        int64    `mapstructure:"max_filepush_size"`
-	CORS                   []string `mapstructure:"cors"`
-
-	TwoFATokenDelivery       string                 `mapstructure:"two_fa_token_delivery"`
-	TwoFATokenTTLSeconds     int                    `mapstructure:"two_fa_token_ttl_seconds"`
-	TwoFASendTimeout         time.Duration          `mapstructure:"two_fa_send_timeout"`
-	TwoFASendToType          message.ValidationType `mapstructure:"two_fa_send_to_type"`
-	TwoFASendToRegex         string                 `mapstructure:"two_fa_send_to_regex"`
-	TwoFASendToRegexCompiled *regexp.Regexp
+	Address                string           `mapstructure:"address"`
+	BaseURL                string           `mapstructure:"base_url"`
+	EnableAcme             bool             `mapstructure:"enable_acme"`
+	Auth                   string           `mapstructure:"auth"`
+	AuthFile               string           `mapstructure:"auth_file"`
+	AuthUserTable          string           `mapstructure:"auth_user_table"`
+	AuthGroupTable         string           `mapstructure:"auth_group_table"`
+	AuthGroupDetailsTable  string           `mapstructure:"auth_group_details_table"`
+	AuthHeader             string           `mapstructure:"auth_header"`
+	UserHeader             string           `mapstructure:"user_header"`
+	CreateMissingUsers     bool             `mapstructure:"create_missing_users"`
+	DefaultUserGroup       string           `mapstructure:"default_user_group"`
+	JWTSecret              string           `mapstructure:"jwt_secret"`
+	DocRoot                string           `mapstructure:"doc_root"`
+	CertFile               string           `mapstructure:"cert_file"`
+	KeyFile                string           `mapstructure:"key_file"`
+	AccessLogFile          string           `mapstructure:"access_log_file"`
+	UserLoginWait          float32          `mapstructure:"user_login_wait"`
+	MaxFailedLogin         int              `mapstructure:"max_failed_login"`
+	BanTime                int              `mapstructure:"ban_time"`
+	MaxTokenLifeTimeHours  int              `mapstructure:"max_token_lifetime"`
+	PasswordMinLength      int              `mapstructure:"password_min_length"`
+	PasswordZxcvbnMinscore int              `mapstructure:"password_zxcvbn_minscore"`
+	TLSMin                 string           `mapstructure:"tls_min"`
+	EnableWsTestEndpoints  bool             `mapstructure:"enable_ws_test_endpoints"`
+	MaxRequestBytes        int64            `mapstructure:"max_request_bytes"`
+	MaxFilePushSize        int64            `mapstructure:"max_filepush_size"`
+	MaxRequestBytesByRoute map[string]int64 `mapstructure:"max_request_bytes_by_route"`
+	CORS                   []string         `mapstructure:"cors"`
+	Compression            []string         `mapstructure:"compression"`
+	CompressionMinSize     int              `mapstructure:"compression_min_size"`
+	EnableHTTP2            bool             `mapstructure:"enable_http2"`
+
+	TwoFATokenDelivery        string                 `mapstructure:"two_fa_token_delivery"`
+	TwoFATokenDeliveryOptions []string               `mapstructure:"two_fa_token_delivery_options"`
+	TwoFATokenTTLSeconds      int                    `mapstructure:"two_fa_token_ttl_seconds"`
+	TwoFASendTimeout          time.Duration          `mapstructure:"two_fa_send_timeout"`
+	TwoFASendToType           message.ValidationType `mapstructure:"two_fa_send_to_type"`
+	TwoFASendToRegex          string                 `mapstructure:"two_fa_send_to_regex"`
+	TwoFASendToRegexCompiled  *regexp.Regexp
 
 	AuditLog                auditlog.Config `mapstructure:",squash"`
+	Policy                  policy.Config   `mapstructure:",squash"`
 	TotPEnabled             bool            `mapstructure:"totp_enabled"`
 	TotPLoginSessionTimeout time.Duration   `mapstructure:"totp_login_session_ttl"`
 	TotPAccountName         string          `mapstructure:"totp_account_name"`
+
+	ProxyProtocol chshare.ProxyProtocolConfig `mapstructure:",squash"`
+
+	PublicStatusEnabled      bool `mapstructure:"public_status_enabled"`
+	HealthProbesEnabled      bool `mapstructure:"health_probes_enabled"`
+	HealthProbesDetails      bool `mapstructure:"health_probes_details"`
+	PrometheusMetricsEnabled bool `mapstructure:"prometheus_metrics_enabled"`
+}
+
+// MaxRequestBytesForRoute returns the request body limit for an API route given by its path relative to the API prefix.
+// The limit of the longest matching prefix in max_request_bytes_by_route wins, otherwise max_request_bytes is used.
+func (c *APIConfig) MaxRequestBytesForRoute(routePath string) int64 {
+	maxBytes := c.MaxRequestBytes
+	longest := 0
+	for prefix, limit := range c.MaxRequestBytesByRoute {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if len(prefix) <= longest {
+			continue
+		}
+		if routePath == prefix || strings.HasPrefix(routePath, prefix+"/") {
+			maxBytes = limit
+			longest = len(prefix)
+		}
+	}
+	return maxBytes
 }
 
 func (c *APIConfig) IsTwoFAOn() bool {
@@ -105,10 +161,23 @@ func (c *APIConfig) parseAndValidate2FASendToType() error {
 	return nil
 }
 
+const (
+	// ClientIDPolicyClient uses the id sent by the client.
+	ClientIDPolicyClient = "client"
+	// ClientIDPolicyMachineIDHash uses a hash of the client auth id and the id sent by the client, e.g. the machine id.
+	ClientIDPolicyMachineIDHash = "machine_id_hash"
+	// ClientIDPolicyHostname uses the hostname reported by the client.
+	ClientIDPolicyHostname = "hostname"
+	// ClientIDPolicyUUID ignores the id sent by the client and generates a random one.
+	ClientIDPolicyUUID = "uuid"
+)
+
 const (
 	MinKeepDisconnectedClients = time.Second
 	MaxKeepDisconnectedClients = 7 * 24 * time.Hour
 	DefaultVaultDBName         = "vault.sqlite.db"
+	// DefaultClockSkewThreshold is the TOTP time step, a larger skew makes TOTP codes generated on the client fail.
+	DefaultClockSkewThreshold = 30 * time.Second
 
 	socketPrefix = "socket:"
 )
@@ -131,6 +200,17 @@ type ServerConfig struct {
 	ExcludedPortsRaw                     []string                               `mapstructure:"excluded_ports"`
 	DataDir                              string                                 `mapstructure:"data_dir"`
 	SqliteWAL                            bool                                   `mapstructure:"sqlite_wal"`
+	SqliteJournalMode                    string                                 `mapstructure:"sqlite_journal_mode"`
+	SqliteBusyTimeout                    time.Duration                          `mapstructure:"sqlite_busy_timeout"`
+	SqliteCacheSize                      int                                    `mapstructure:"sqlite_cache_size"`
+	SqliteSynchronous                    string                                 `mapstructure:"sqlite_synchronous"`
+	SqliteReadReplicaDir                 string                                 `mapstructure:"sqlite_read_replica_dir"`
+	SqliteMaxOpenConnections             int                                    `mapstructure:"sqlite_max_open_connections"`
+	SqliteClientsMaxOpenConnections      int                                    `mapstructure:"sqlite_clients_max_open_connections"`
+	SqliteMaxIdleConnections             int                                    `mapstructure:"sqlite_max_idle_connections"`
+	SqliteConnMaxLifetime                time.Duration                          `mapstructure:"sqlite_conn_max_lifetime"`
+	SqliteConnMaxIdleTime                time.Duration                          `mapstructure:"sqlite_conn_max_idle_time"`
+	SlowQueryThreshold                   time.Duration                          `mapstructure:"slow_query_threshold"`
 	MaxConcurrentSSHConnectionHandshakes int                                    `mapstructure:"max_concurrent_ssh_handshakes"`
 	PurgeDisconnectedClients             bool                                   `mapstructure:"purge_disconnected_clients"`
 	CleanupLostClients                   bool                                   `mapstructure:"cleanup_lost_clients" replaced_by:"PurgeDisconnectedClients"`
@@ -146,6 +226,8 @@ type ServerConfig struct {
 	AuthWrite                            bool                                   `mapstructure:"auth_write"`
 	AuthMultiuseCreds                    bool                                   `mapstructure:"auth_multiuse_creds"`
 	EquateClientauthidClientid           bool                                   `mapstructure:"equate_clientauthid_clientid"`
+	ClientIDPolicy                       string                                 `mapstructure:"client_id_policy"`
+	ClientIDPrefixes                     map[string]string                      `mapstructure:"client_id_prefixes"`
 	AllowRoot                            bool                                   `mapstructure:"allow_root"`
 	ClientLoginWait                      float32                                `mapstructure:"client_login_wait"`
 	MaxFailedLogin                       int                                    `mapstructure:"max_failed_login"`
@@ -153,6 +235,42 @@ type ServerConfig struct {
 	InternalTunnelProxyConfig            clienttunnel.InternalTunnelProxyConfig `mapstructure:",squash"`
 	JobsMaxResults                       int                                    `mapstructure:"jobs_max_results"`
 	AcmeHTTPPort                         int                                    `mapstructure:"acme_http_port"`
+	SessionRecording                     sessionrecording.Config                `mapstructure:",squash"`
+	Capture                              capture.Config                         `mapstructure:",squash"`
+	ProxyProtocol                        chshare.ProxyProtocolConfig            `mapstructure:",squash"`
+	ClientACLRules                       []cgroups.ACLRule                      `mapstructure:"client_acl_rules"`
+	ExecHooksTimeout                     time.Duration                          `mapstructure:"exec_hooks_timeout"`
+	ExecHooks                            []hooks.Hook                           `mapstructure:"exec_hooks"`
+	PreConnect                           preconnect.Config                      `mapstructure:",squash"`
+	AlertingFlappingWindow               time.Duration                          `mapstructure:"alerting_flapping_window"`
+	AlertingFlappingThreshold            int                                    `mapstructure:"alerting_flapping_threshold"`
+	AutoTags                             autotags.Config                        `mapstructure:",squash"`
+	ClientPayload                        clientpayload.Config                   `mapstructure:",squash"`
+	ClientTags                           clienttags.Config                      `mapstructure:",squash"`
+	MinClientVersion                     clientversion.Config                   `mapstructure:",squash"`
+	DuplicateClientsSerialLabel          string                                 `mapstructure:"duplicate_clients_serial_label"`
+	AlertingDuplicateClients             bool                                   `mapstructure:"alerting_duplicate_clients"`
+	AlertingClockSkewThreshold           time.Duration                          `mapstructure:"alerting_clock_skew_threshold"`
+	AlertingAgentRSSThresholdMB          int                                    `mapstructure:"alerting_agent_rss_threshold_mb"`
+	AlertingAgentGoroutinesThreshold     int                                    `mapstructure:"alerting_agent_goroutines_threshold"`
+	AlertingAgentOpenFDsThreshold        int                                    `mapstructure:"alerting_agent_open_fds_threshold"`
+	OSEOLFile                            string                                 `mapstructure:"os_eol_file"`
+	BootstrapFile                        string                                 `mapstructure:"bootstrap_file"`
+	Bandwidth                            bandwidth.Config                       `mapstructure:",squash"`
+	UpdatesStatusRefreshMinInterval      time.Duration                          `mapstructure:"updates_status_refresh_min_interval"`
+	UpdatesStatusRefreshTimeout          time.Duration                          `mapstructure:"updates_status_refresh_timeout"`
+	TunnelApprovalRules                  []tunnelapproval.Rule                  `mapstructure:"tunnel_approval_rules"`
+	TunnelApprovalTimeout                time.Duration                          `mapstructure:"tunnel_approval_timeout"`
+	TunnelSchemes                        []tunnelschemes.Scheme                 `mapstructure:"tunnel_schemes"`
+	ReverseTunnelTargets                 []string                               `mapstructure:"reverse_tunnel_targets"`
+	TunnelBindAddresses                  []string                               `mapstructure:"tunnel_bind_addresses"`
+	Maintenance                          maintenance.Config                     `mapstructure:",squash"`
+	ClientSnapshots                      clientsnapshot.Config                  `mapstructure:",squash"`
+	DefaultLocale                        string                                 `mapstructure:"default_locale"`
+	WebPushSubject                       string                                 `mapstructure:"webpush_subject"`
+	FeatureFlags                         []featureflags.Flag                    `mapstructure:"feature_flags"`
+	SessionBanner                        string                                 `mapstructure:"session_banner"`
+	RedactionRules                       []redaction.Rule                       `mapstructure:"redaction_rules"`
 
 	// DEPRECATED, only here for backwards compatibility
 	MaxRequestBytes       int64 `mapstructure:"max_request_bytes"`
@@ -164,6 +282,23 @@ type ServerConfig struct {
 	AuthPassword string
 }
 
+func (c *ServerConfig) parseAndValidateClientIDPolicy() error {
+	switch c.ClientIDPolicy {
+	case "":
+		c.ClientIDPolicy = ClientIDPolicyClient
+	case ClientIDPolicyClient, ClientIDPolicyMachineIDHash, ClientIDPolicyHostname, ClientIDPolicyUUID:
+	default:
+		return fmt.Errorf("invalid server.client_id_policy %q, expected one of: %s, %s, %s, %s", c.ClientIDPolicy,
+			ClientIDPolicyClient, ClientIDPolicyMachineIDHash, ClientIDPolicyHostname, ClientIDPolicyUUID)
+	}
+
+	if c.ClientIDPolicy == ClientIDPolicyUUID && !c.AuthMultiuseCreds {
+		return fmt.Errorf("server.client_id_policy %q requires server.auth_multiuse_creds to be enabled", ClientIDPolicyUUID)
+	}
+
+	return nil
+}
+
 type DatabaseConfig struct {
 	Type     string `mapstructure:"db_type"`
 	Host     string `mapstructure:"db_host"`
@@ -171,6 +306,11 @@ type DatabaseConfig struct {
 	Password string `mapstructure:"db_password"`
 	Name     string `mapstructure:"db_name"`
 
+	MaxOpenConnections int           `mapstructure:"db_max_open_connections"`
+	MaxIdleConnections int           `mapstructure:"db_max_idle_connections"`
+	ConnMaxLifetime    time.Duration `mapstructure:"db_conn_max_lifetime"`
+	ConnMaxIdleTime    time.Duration `mapstructure:"db_conn_max_idle_time"`
+
 	Driver string
 	Dsn    string
 }
@@ -195,6 +335,29 @@ func (c *PushoverConfig) Validate() error {
 	return nil
 }
 
+type TwilioConfig struct {
+	APIURL     string `mapstructure:"api_url"`
+	AccountSID string `mapstructure:"account_sid"`
+	AuthToken  string `mapstructure:"auth_token"`
+	From       string `mapstructure:"from"`
+}
+
+func (c *TwilioConfig) Validate() error {
+	if c.AccountSID == "" {
+		return errors.New("twilio.account_sid is required")
+	}
+	if c.AuthToken == "" {
+		return errors.New("twilio.auth_token is required")
+	}
+	if c.From == "" {
+		return errors.New("twilio.from is required")
+	}
+	if err := validateHTTPorHTTPSURL(c.APIURL); err != nil {
+		return fmt.Errorf("invalid twilio.api_url: %v", err)
+	}
+	return nil
+}
+
 type SMTPConfig struct {
 	Server       string `mapstructure:"server"`
 	AuthUsername string `mapstructure:"auth_username"`
@@ -266,14 +429,32 @@ type MonitoringConfig struct {
 	DataStorageDays     int64  `mapstructure:"data_storage_days"`
 	Enabled             bool   `mapstructure:"enabled"`
 
+	Rollup5mStorageDuration string `mapstructure:"rollup_5m_storage_duration"`
+	Rollup1hStorageDuration string `mapstructure:"rollup_1h_storage_duration"`
+
+	CapacityWarningDays int `mapstructure:"capacity_warning_days"`
+
+	Profiles []monitoringprofiles.Profile `mapstructure:"profiles"`
+
 	// cached version of DataStorageDuration as real time.Duration
 	duration time.Duration `mapstructure:"-"`
+	// cached versions of the rollup storage durations
+	rollup5mDuration time.Duration `mapstructure:"-"`
+	rollup1hDuration time.Duration `mapstructure:"-"`
 }
 
 func (mc *MonitoringConfig) GetDataStorageDuration() (duration time.Duration) {
 	return mc.duration
 }
 
+func (mc *MonitoringConfig) GetRollup5mStorageDuration() time.Duration {
+	return mc.rollup5mDuration
+}
+
+func (mc *MonitoringConfig) GetRollup1hStorageDuration() time.Duration {
+	return mc.rollup1hDuration
+}
+
 type Config struct {
 	Server     ServerConfig     `mapstructure:"server"`
 	Caddy      caddy.Config     `mapstructure:"caddy-integration"`
@@ -282,7 +463,9 @@ type Config struct {
 	Database   DatabaseConfig   `mapstructure:"database"`
 	Pushover   PushoverConfig   `mapstructure:"pushover"`
 	SMTP       SMTPConfig       `mapstructure:"smtp"`
+	Twilio     TwilioConfig     `mapstructure:"twilio"`
 	Monitoring MonitoringConfig `mapstructure:"monitoring"`
+	Firewall   firewall.Config  `mapstructure:"firewall"`
 
 	PlusConfig rportplus.PlusConfig `mapstructure:",squash"`
 }
@@ -300,7 +483,18 @@ func (c *Config) GetUploadDir() string {
 }
 
 func (s *ServerConfig) GetSQLiteDataSourceOptions() sqlite.DataSourceOptions {
-	return sqlite.DataSourceOptions{WALEnabled: s.SqliteWAL}
+	return sqlite.DataSourceOptions{
+		WALEnabled:  s.SqliteWAL,
+		JournalMode: s.SqliteJournalMode,
+		BusyTimeout: s.SqliteBusyTimeout,
+		CacheSize:   s.SqliteCacheSize,
+		Synchronous: s.SqliteSynchronous,
+
+		MaxOpenConnections: s.SqliteMaxOpenConnections,
+		MaxIdleConnections: s.SqliteMaxIdleConnections,
+		ConnMaxLifetime:    s.SqliteConnMaxLifetime,
+		ConnMaxIdleTime:    s.SqliteConnMaxIdleTime,
+	}
 }
 
 func (c *Config) InitRequestLogOptions() *requestlog.Options {
@@ -317,6 +511,10 @@ func (c *Config) AllowedPorts() mapset.Set {
 }
 
 func (c *Config) ParseAndValidate(mLog *logger.MemLogger) error {
+	if err := c.resolveSecrets(); err != nil {
+		return err
+	}
+
 	rpl, err := ConfigReplaceDeprecated(&c.Server)
 	for old, new := range rpl {
 		mLog.Infof("server setting '%s' is deprecated and will be removed soon. Use '%s' instead.", old, new)
@@ -351,6 +549,146 @@ func (c *Config) ParseAndValidate(mLog *logger.MemLogger) error {
 	}
 	c.Server.InternalTunnelProxyConfig.CORS = parseAndValidateCORS(mLog, c.Server.InternalTunnelProxyConfig.CORS)
 
+	if err := c.Server.SessionRecording.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Server.Capture.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Server.ProxyProtocol.ParseAndValidate(); err != nil {
+		return fmt.Errorf("server: %v", err)
+	}
+
+	if err := c.Server.parseAndValidateClientIDPolicy(); err != nil {
+		return err
+	}
+
+	if err := c.Server.GetSQLiteDataSourceOptions().Validate(); err != nil {
+		return fmt.Errorf("server.sqlite: %v", err)
+	}
+	if c.Server.SqliteClientsMaxOpenConnections < 0 {
+		return errors.New("server.sqlite_clients_max_open_connections must not be negative")
+	}
+	if c.Server.SlowQueryThreshold < 0 {
+		return errors.New("server.slow_query_threshold must not be negative")
+	}
+	if c.Server.SqliteReadReplicaDir != "" && filepath.Clean(c.Server.SqliteReadReplicaDir) == filepath.Clean(c.Server.DataDir) {
+		return errors.New("server.sqlite_read_replica_dir must not be the data_dir")
+	}
+
+	if err := cgroups.ValidateACLRules(c.Server.ClientACLRules); err != nil {
+		return fmt.Errorf("server.client_acl_rules: %v", err)
+	}
+
+	if err := hooks.ValidateHooks(c.Server.ExecHooks); err != nil {
+		return fmt.Errorf("server.exec_hooks: %v", err)
+	}
+
+	if err := c.Server.PreConnect.Validate(); err != nil {
+		return fmt.Errorf("server.%v", err)
+	}
+
+	if c.Server.AlertingFlappingThreshold < 0 {
+		return errors.New("server.alerting_flapping_threshold cannot be negative")
+	}
+	if c.Server.AlertingFlappingThreshold > 0 && c.Server.AlertingFlappingWindow <= 0 {
+		return errors.New("server.alerting_flapping_window must be greater than 0")
+	}
+
+	if c.Server.AlertingClockSkewThreshold < 0 {
+		return errors.New("server.alerting_clock_skew_threshold cannot be negative")
+	}
+	if c.Server.AlertingAgentRSSThresholdMB < 0 {
+		return errors.New("server.alerting_agent_rss_threshold_mb cannot be negative")
+	}
+	if c.Server.AlertingAgentGoroutinesThreshold < 0 {
+		return errors.New("server.alerting_agent_goroutines_threshold cannot be negative")
+	}
+	if c.Server.AlertingAgentOpenFDsThreshold < 0 {
+		return errors.New("server.alerting_agent_open_fds_threshold cannot be negative")
+	}
+
+	if c.Server.UpdatesStatusRefreshMinInterval < 0 {
+		return errors.New("server.updates_status_refresh_min_interval cannot be negative")
+	}
+	if c.Server.UpdatesStatusRefreshTimeout < 0 {
+		return errors.New("server.updates_status_refresh_timeout cannot be negative")
+	}
+
+	if err := c.Server.AutoTags.Validate(); err != nil {
+		return fmt.Errorf("server.%v", err)
+	}
+
+	if err := c.Server.Bandwidth.Validate(); err != nil {
+		return fmt.Errorf("server.%v", err)
+	}
+
+	if err := c.Server.ClientPayload.Validate(); err != nil {
+		return fmt.Errorf("server.%v", err)
+	}
+
+	if err := c.Server.ClientTags.Validate(); err != nil {
+		return fmt.Errorf("server.%v", err)
+	}
+
+	if err := c.Server.MinClientVersion.Validate(); err != nil {
+		return fmt.Errorf("server.%v", err)
+	}
+
+	if err := tunnelapproval.ValidateRules(c.Server.TunnelApprovalRules); err != nil {
+		return fmt.Errorf("server.tunnel_approval_rules: %v", err)
+	}
+	if len(c.Server.TunnelApprovalRules) > 0 && c.Server.TunnelApprovalTimeout <= 0 {
+		return errors.New("server.tunnel_approval_timeout must be greater than 0")
+	}
+
+	if err := tunnelschemes.ValidateSchemes(c.Server.TunnelSchemes); err != nil {
+		return fmt.Errorf("server.tunnel_schemes: %v", err)
+	}
+
+	if err := reversetunnel.ValidateTargets(c.Server.ReverseTunnelTargets); err != nil {
+		return fmt.Errorf("server.reverse_tunnel_targets: %v", err)
+	}
+
+	if err := tunnelbind.ValidateAddresses(c.Server.TunnelBindAddresses); err != nil {
+		return fmt.Errorf("server.tunnel_bind_addresses: %v", err)
+	}
+
+	if err := c.Server.Maintenance.Validate(); err != nil {
+		return fmt.Errorf("server.%v", err)
+	}
+
+	if err := c.Server.ClientSnapshots.Validate(); err != nil {
+		return fmt.Errorf("server.%v", err)
+	}
+
+	if c.Server.DefaultLocale == "" {
+		c.Server.DefaultLocale = i18n.DefaultLocale
+	}
+	if !i18n.IsSupported(c.Server.DefaultLocale) {
+		return fmt.Errorf("server.default_locale: unsupported locale %q, supported: %s", c.Server.DefaultLocale, strings.Join(i18n.Supported(), ", "))
+	}
+
+	if err := featureflags.ValidateFlags(c.Server.FeatureFlags); err != nil {
+		return fmt.Errorf("server.feature_flags: %v", err)
+	}
+
+	if c.Server.WebPushSubject != "" {
+		if err := webpush.ValidateSubject(c.Server.WebPushSubject); err != nil {
+			return fmt.Errorf("server.webpush_subject: %v", err)
+		}
+	}
+
+	if len(c.Server.SessionBanner) > cgroups.MaxBannerLength {
+		return fmt.Errorf("server.session_banner: max length %d, got %d", cgroups.MaxBannerLength, len(c.Server.SessionBanner))
+	}
+
+	if err := redaction.ValidateRules(c.Server.RedactionRules); err != nil {
+		return fmt.Errorf("server.redaction_rules: %v", err)
+	}
+
 	filesAPI := files.NewFileSystem()
 	serverLogLevel := c.Logging.LogLevel.String()
 
@@ -359,6 +697,10 @@ func (c *Config) ParseAndValidate(mLog *logger.MemLogger) error {
 		mLog.Errorf("caddy integration not enabled due to error: %v", err)
 	}
 
+	if err := c.Firewall.ParseAndValidate(); err != nil {
+		return fmt.Errorf("firewall: %v", err)
+	}
+
 	if c.Server.DataDir == "" {
 		return errors.New("'data directory path' cannot be empty")
 	}
@@ -397,6 +739,14 @@ func (c *Config) ParseAndValidate(mLog *logger.MemLogger) error {
 		return err
 	}
 
+	if c.Monitoring.CapacityWarningDays < 0 {
+		return errors.New("monitoring.capacity_warning_days cannot be negative")
+	}
+
+	if err := monitoringprofiles.ValidateProfiles(c.Monitoring.Profiles); err != nil {
+		return fmt.Errorf("monitoring.profiles: %v", err)
+	}
+
 	return nil
 }
 
@@ -469,6 +819,30 @@ func (c *Config) parseAndValidateAPI(mLog *logger.MemLogger) error {
 		}
 
 		c.API.CORS = parseAndValidateCORS(mLog, c.API.CORS)
+
+		if err := middleware.ValidateEncodings(c.API.Compression); err != nil {
+			return fmt.Errorf("invalid api.compression: %v", err)
+		}
+		if c.API.CompressionMinSize < 0 {
+			return errors.New("api.compression_min_size cannot be negative")
+		}
+
+		if err := c.API.ProxyProtocol.ParseAndValidate(); err != nil {
+			return fmt.Errorf("API: %v", err)
+		}
+
+		for prefix, limit := range c.API.MaxRequestBytesByRoute {
+			if !strings.HasPrefix(prefix, "/") {
+				return fmt.Errorf("invalid api.max_request_bytes_by_route: route %q must start with a slash", prefix)
+			}
+			if limit <= 0 {
+				return fmt.Errorf("invalid api.max_request_bytes_by_route: limit for route %q must be positive", prefix)
+			}
+		}
+
+		if err := c.API.Policy.Validate(); err != nil {
+			return fmt.Errorf("api.%v", err)
+		}
 	} else {
 		// API disabled
 		if c.API.DocRoot != "" {
@@ -593,6 +967,21 @@ func (mc *MonitoringConfig) parseAndValidateMonitoring(mLog *logger.MemLogger) (
 	if mc.Enabled && mc.GetDataStorageDuration() < time.Hour {
 		return errors.New("monitoring results must be stored for at least 1 hour")
 	}
+
+	mc.rollup5mDuration, err = convertHourOrDayStringToDuration("rollup_5m_storage_duration", mc.Rollup5mStorageDuration)
+	if err != nil {
+		return err
+	}
+	mc.rollup1hDuration, err = convertHourOrDayStringToDuration("rollup_1h_storage_duration", mc.Rollup1hStorageDuration)
+	if err != nil {
+		return err
+	}
+	if mc.rollup5mDuration < mc.duration {
+		return errors.New("'rollup_5m_storage_duration' must not be shorter than 'data_storage_duration'")
+	}
+	if mc.rollup1hDuration < mc.rollup5mDuration {
+		return errors.New("'rollup_1h_storage_duration' must not be shorter than 'rollup_5m_storage_duration'")
+	}
 	return nil
 }
 
@@ -639,6 +1028,9 @@ func (c *Config) parseAndValidateTotPSecret() error {
 
 func (c *Config) parseAndValidate2FA() error {
 	if c.API.TwoFATokenDelivery == "" {
+		if len(c.API.TwoFATokenDeliveryOptions) > 0 {
+			return errors.New("two_fa_token_delivery_options require two_fa_token_delivery to be set")
+		}
 		return nil
 	}
 
@@ -646,27 +1038,42 @@ func (c *Config) parseAndValidate2FA() error {
 		return errors.New("2FA is not available if you use a single static user-password pair")
 	}
 
+	for _, delivery := range append([]string{c.API.TwoFATokenDelivery}, c.API.TwoFATokenDeliveryOptions...) {
+		if err := c.validate2FADelivery(delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validate2FADelivery(delivery string) error {
 	// TODO: to do better handling, maybe with using enums
-	switch c.API.TwoFATokenDelivery {
+	switch delivery {
 	case "pushover":
 		return c.Pushover.Validate()
 	case "smtp":
 		return c.SMTP.Validate()
+	case "twilio":
+		return c.Twilio.Validate()
 	default:
 		// if the setting is a valid executable we set script delivery
-		if _, err := exec.LookPath(c.API.TwoFATokenDelivery); err == nil {
+		if _, err := exec.LookPath(delivery); err == nil {
 			return c.API.parseAndValidate2FASendToType()
 		}
 		// if the setting is a valid url, we set url delivery
-		if err := validateHTTPorHTTPSURL(c.API.TwoFATokenDelivery); err == nil {
+		if err := validateHTTPorHTTPSURL(delivery); err == nil {
 			if c.API.BaseURL == "" {
 				return errors.New("base_url is required for url two_fa_token_delivery")
 			}
+			if c.API.TwoFASendToType == message.ValidationRegex {
+				return c.API.parseAndValidate2FASendToType()
+			}
 			return nil
 		}
 	}
 
-	return fmt.Errorf("unknown 2fa token delivery method: %s", c.API.TwoFATokenDelivery)
+	return fmt.Errorf("unknown 2fa token delivery method: %s", delivery)
 }
 
 func (c *Config) parseAndValidateAPIAuth() error {
@@ -784,6 +1191,10 @@ func validateHTTPorHTTPSURL(testURL string) error {
 }
 
 func (d *DatabaseConfig) ParseAndValidate() error {
+	if err := d.PoolOptions().Validate(); err != nil {
+		return fmt.Errorf("database: %v", err)
+	}
+
 	switch d.Type {
 	case "":
 		return nil
@@ -817,6 +1228,15 @@ func (d *DatabaseConfig) ParseAndValidate() error {
 	return nil
 }
 
+func (d *DatabaseConfig) PoolOptions() pool.Options {
+	return pool.Options{
+		MaxOpenConnections: d.MaxOpenConnections,
+		MaxIdleConnections: d.MaxIdleConnections,
+		ConnMaxLifetime:    d.ConnMaxLifetime,
+		ConnMaxIdleTime:    d.ConnMaxIdleTime,
+	}
+}
+
 func (d *DatabaseConfig) DsnForLogs() string {
 	if d.Password != "" {
 		// hide the password
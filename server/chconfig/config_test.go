@@ -7,6 +7,7 @@ import (
 	"github.com/realvnc-labs/rport/server/api/message"
 	"github.com/realvnc-labs/rport/server/caddy"
 	"github.com/realvnc-labs/rport/server/clients/clienttunnel"
+	chshare "github.com/realvnc-labs/rport/share"
 	"github.com/realvnc-labs/rport/share/logger"
 
 	mapset "github.com/deckarep/golang-set"
@@ -80,6 +81,84 @@ func TestParseAndValidateServerConfig(t *testing.T) {
 			},
 			ExpectedError: "invalid tunnel_host 'bad tunnel host': use IP address or FQDN",
 		},
+		{
+			Name: "Bad client id policy",
+			Config: Config{
+				Server: ServerConfig{
+					URL:            []string{"http://localhost/"},
+					DataDir:        "./",
+					Auth:           "abc:def",
+					UsedPortsRaw:   []string{"10-20"},
+					ClientIDPolicy: "serial",
+				},
+			},
+			ExpectedError: `invalid server.client_id_policy "serial", expected one of: client, machine_id_hash, hostname, uuid`,
+		},
+		{
+			Name: "Random client ids with single-use credentials",
+			Config: Config{
+				Server: ServerConfig{
+					URL:            []string{"http://localhost/"},
+					DataDir:        "./",
+					Auth:           "abc:def",
+					UsedPortsRaw:   []string{"10-20"},
+					ClientIDPolicy: ClientIDPolicyUUID,
+				},
+			},
+			ExpectedError: `server.client_id_policy "uuid" requires server.auth_multiuse_creds to be enabled`,
+		},
+		{
+			Name: "Invalid sqlite journal mode",
+			Config: Config{
+				Server: ServerConfig{
+					URL:               []string{"http://localhost/"},
+					DataDir:           "./",
+					Auth:              "abc:def",
+					UsedPortsRaw:      []string{"10-20"},
+					SqliteJournalMode: "fast",
+				},
+			},
+			ExpectedError: `server.sqlite: invalid journal mode "fast", expected one of DELETE, TRUNCATE, PERSIST, MEMORY, WAL, OFF`,
+		},
+		{
+			Name: "Negative sqlite connection lifetime",
+			Config: Config{
+				Server: ServerConfig{
+					URL:                   []string{"http://localhost/"},
+					DataDir:               "./",
+					Auth:                  "abc:def",
+					UsedPortsRaw:          []string{"10-20"},
+					SqliteConnMaxLifetime: -time.Minute,
+				},
+			},
+			ExpectedError: `server.sqlite: connection max lifetime must not be negative`,
+		},
+		{
+			Name: "Negative slow query threshold",
+			Config: Config{
+				Server: ServerConfig{
+					URL:                []string{"http://localhost/"},
+					DataDir:            "./",
+					Auth:               "abc:def",
+					UsedPortsRaw:       []string{"10-20"},
+					SlowQueryThreshold: -time.Second,
+				},
+			},
+			ExpectedError: `server.slow_query_threshold must not be negative`,
+		},
+		{
+			Name: "PROXY protocol without trusted load balancers",
+			Config: Config{
+				Server: ServerConfig{
+					URL:           []string{"http://localhost/"},
+					DataDir:       "./",
+					Auth:          "abc:def",
+					UsedPortsRaw:  []string{"10-20"},
+					ProxyProtocol: chshare.ProxyProtocolConfig{Mode: chshare.ProxyProtocolRequired},
+				},
+			},
+			ExpectedError: `server: proxy_protocol_trusted is required if proxy_protocol is enabled`,
+		},
 		{
 			Name: "Correct tunnel host",
 			Config: Config{
@@ -543,6 +622,65 @@ func TestParseAndValidateAPI(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name: "api enabled, twilio 2fa method, no account_sid",
+			Config: Config{
+				API: APIConfig{
+					Address:            "0.0.0.0:3000",
+					AuthFile:           "test.json",
+					TwoFATokenDelivery: "twilio",
+				},
+				Twilio: TwilioConfig{
+					APIURL:    "https://api.twilio.com/2010-04-01",
+					AuthToken: "secret",
+					From:      "+15005550006",
+				},
+			},
+			ExpectedError: "API: twilio.account_sid is required",
+		},
+		{
+			Name: "api enabled, 2fa delivery options without default",
+			Config: Config{
+				API: APIConfig{
+					Address:                   "0.0.0.0:3000",
+					AuthFile:                  "test.json",
+					TwoFATokenDeliveryOptions: []string{"twilio"},
+				},
+			},
+			ExpectedError: "API: two_fa_token_delivery_options require two_fa_token_delivery to be set",
+		},
+		{
+			Name: "api enabled, 2fa delivery options, invalid option",
+			Config: Config{
+				API: APIConfig{
+					BaseURL:                   "https://rport.example.com",
+					Address:                   "0.0.0.0:3000",
+					AuthFile:                  "test.json",
+					TwoFATokenDelivery:        "https://2fa.example.com",
+					TwoFATokenDeliveryOptions: []string{"unknown"},
+				},
+			},
+			ExpectedError: "API: unknown 2fa token delivery method: unknown",
+		},
+		{
+			Name: "api enabled, 2fa delivery options, ok",
+			Config: Config{
+				API: APIConfig{
+					BaseURL:                   "https://rport.example.com",
+					Address:                   "0.0.0.0:3000",
+					AuthFile:                  "test.json",
+					TwoFATokenDelivery:        "https://2fa.example.com",
+					TwoFATokenDeliveryOptions: []string{"twilio", "/bin/sh"},
+					TwoFASendToType:           message.ValidationNone,
+				},
+				Twilio: TwilioConfig{
+					APIURL:     "https://api.twilio.com/2010-04-01",
+					AccountSID: "AC123",
+					AuthToken:  "secret",
+					From:       "+15005550006",
+				},
+			},
+		},
 		{
 			Name: "api enabled, auth_header no user_header",
 			Config: Config{
@@ -633,6 +771,50 @@ func TestParseAndValidateAPI(t *testing.T) {
 			},
 			ExpectedError: "API: TLS must be either 1.2 or 1.3",
 		},
+		{
+			Name: "api enabled, unsupported compression",
+			Config: Config{
+				API: APIConfig{
+					Address:     "0.0.0.0:3000",
+					Auth:        "abc:def",
+					Compression: []string{"gzip", "br"},
+				},
+			},
+			ExpectedError: `API: invalid api.compression: unsupported compression "br", supported: "zstd", "gzip", "deflate"`,
+		},
+		{
+			Name: "api enabled, invalid route of max request bytes",
+			Config: Config{
+				API: APIConfig{
+					Address:                "0.0.0.0:3000",
+					Auth:                   "abc:def",
+					MaxRequestBytesByRoute: map[string]int64{"scripts": 1024},
+				},
+			},
+			ExpectedError: `API: invalid api.max_request_bytes_by_route: route "scripts" must start with a slash`,
+		},
+		{
+			Name: "api enabled, invalid max request bytes of route",
+			Config: Config{
+				API: APIConfig{
+					Address:                "0.0.0.0:3000",
+					Auth:                   "abc:def",
+					MaxRequestBytesByRoute: map[string]int64{"/scripts": 0},
+				},
+			},
+			ExpectedError: `API: invalid api.max_request_bytes_by_route: limit for route "/scripts" must be positive`,
+		},
+		{
+			Name: "api enabled, valid compression",
+			Config: Config{
+				API: APIConfig{
+					Address:     "0.0.0.0:3000",
+					Auth:        "abc:def",
+					Compression: []string{"zstd", "gzip"},
+				},
+			},
+			ExpectedJwtSecret: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -857,3 +1039,67 @@ func TestParseAndValidateCORS(t *testing.T) {
 	}
 	assert.Equal(t, expected, result)
 }
+
+func TestMaxRequestBytesForRoute(t *testing.T) {
+	c := APIConfig{
+		MaxRequestBytes: 10240,
+		MaxRequestBytesByRoute: map[string]int64{
+			"/scripts":          1048576,
+			"/clients/":         20480,
+			"/clients/{id}/acl": 1024,
+		},
+	}
+
+	testCases := []struct {
+		routePath string
+		want      int64
+	}{
+		{routePath: "/scripts", want: 1048576},
+		{routePath: "/scripts/{script_id}", want: 1048576},
+		{routePath: "/scriptsfoo", want: 10240},
+		{routePath: "/clients", want: 20480},
+		{routePath: "/clients/{id}/tunnels", want: 20480},
+		{routePath: "/clients/{id}/acl", want: 1024},
+		{routePath: "/users", want: 10240},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.routePath, func(t *testing.T) {
+			assert.Equal(t, tc.want, c.MaxRequestBytesForRoute(tc.routePath))
+		})
+	}
+}
+
+func TestParseAndValidateMonitoringRollups(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rollup5m string
+		rollup1h string
+		wantErr  string
+	}{
+		{name: "defaults", rollup5m: "90d", rollup1h: "730d"},
+		{name: "5m rollups shorter than raw data", rollup5m: "3d", rollup1h: "730d", wantErr: "'rollup_5m_storage_duration' must not be shorter than 'data_storage_duration'"},
+		{name: "hourly rollups shorter than 5m rollups", rollup5m: "90d", rollup1h: "30d", wantErr: "'rollup_1h_storage_duration' must not be shorter than 'rollup_5m_storage_duration'"},
+		{name: "missing units", rollup5m: "90", rollup1h: "730d", wantErr: "'rollup_5m_storage_duration' must include units of either d (=days) or h (=hours)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mc := MonitoringConfig{
+				Enabled:                 true,
+				DataStorageDuration:     "7d",
+				Rollup5mStorageDuration: tc.rollup5m,
+				Rollup1hStorageDuration: tc.rollup1h,
+			}
+
+			err := mc.parseAndValidateMonitoring(&Mlog)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 90*24*time.Hour, mc.GetRollup5mStorageDuration())
+			assert.Equal(t, 730*24*time.Hour, mc.GetRollup1hStorageDuration())
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package chconfig
+
+import (
+	"fmt"
+
+	"github.com/realvnc-labs/rport/server/secretref"
+)
+
+type secretOption struct {
+	name  string
+	value *string
+}
+
+// secretOptions returns the options that may reference a secret like env://NAME, file:///path or vault://path#key.
+func (c *Config) secretOptions() []secretOption {
+	options := []secretOption{
+		{"server.auth", &c.Server.Auth},
+		{"server.key_seed", &c.Server.KeySeed},
+		{"api.auth", &c.API.Auth},
+		{"api.jwt_secret", &c.API.JWTSecret},
+		{"database.db_user", &c.Database.User},
+		{"database.db_password", &c.Database.Password},
+		{"smtp.auth_username", &c.SMTP.AuthUsername},
+		{"smtp.auth_password", &c.SMTP.AuthPassword},
+		{"pushover.api_token", &c.Pushover.APIToken},
+		{"pushover.user_key", &c.Pushover.UserKey},
+		{"twilio.auth_token", &c.Twilio.AuthToken},
+	}
+	if c.PlusConfig.OAuthConfig != nil {
+		options = append(options,
+			secretOption{"plus-oauth.client_secret", &c.PlusConfig.OAuthConfig.ClientSecret},
+			secretOption{"plus-oauth.device_client_secret", &c.PlusConfig.OAuthConfig.DeviceClientSecret},
+		)
+	}
+	if c.PlusConfig.LicenseConfig != nil {
+		options = append(options, secretOption{"plus-license.key", &c.PlusConfig.LicenseConfig.Key})
+	}
+	return options
+}
+
+// resolveSecrets replaces references to secrets by the secrets, so plaintext secrets don't have to be kept in the
+// config file.
+func (c *Config) resolveSecrets() error {
+	for _, o := range c.secretOptions() {
+		if !secretref.IsReference(*o.value) {
+			continue
+		}
+		secret, err := secretref.Resolve(*o.value)
+		if err != nil {
+			return fmt.Errorf("failed to resolve secret of %s: %v", o.name, err)
+		}
+		*o.value = secret
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package chconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/plus/license"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	t.Setenv("RPORTD_TEST_CLIENT_AUTH", "client:password")
+	secretFile := filepath.Join(t.TempDir(), "smtp_password")
+	require.NoError(t, os.WriteFile(secretFile, []byte("smtp-password\n"), 0600))
+
+	c := &Config{
+		Server:   ServerConfig{Auth: "env://RPORTD_TEST_CLIENT_AUTH", KeySeed: "plain-seed"},
+		SMTP:     SMTPConfig{AuthUsername: "user", AuthPassword: "file://" + secretFile},
+		Database: DatabaseConfig{Password: ""},
+	}
+	c.PlusConfig.LicenseConfig = &license.Config{Key: "env://RPORTD_TEST_CLIENT_AUTH"}
+
+	require.NoError(t, c.resolveSecrets())
+	assert.Equal(t, "client:password", c.Server.Auth)
+	assert.Equal(t, "plain-seed", c.Server.KeySeed)
+	assert.Equal(t, "user", c.SMTP.AuthUsername)
+	assert.Equal(t, "smtp-password", c.SMTP.AuthPassword)
+	assert.Equal(t, "", c.Database.Password)
+	assert.Equal(t, "client:password", c.PlusConfig.LicenseConfig.Key)
+
+	c.Database.Password = "env://RPORTD_TEST_UNKNOWN"
+	assert.EqualError(t, c.resolveSecrets(), "failed to resolve secret of database.db_password: env var RPORTD_TEST_UNKNOWN is not set")
+}
+
+func TestParseAndValidateResolvesSecrets(t *testing.T) {
+	t.Setenv("RPORTD_TEST_CLIENT_AUTH", "abc:def")
+	c := &Config{Server: defaultValidMinServerConfig}
+	c.Server.Auth = "env://RPORTD_TEST_CLIENT_AUTH"
+
+	require.NoError(t, c.ParseAndValidate(&Mlog))
+	assert.Equal(t, "abc", c.Server.AuthID)
+	assert.Equal(t, "def", c.Server.AuthPassword)
+}
@@ -2,7 +2,9 @@ package chserver
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +13,7 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"regexp"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -25,11 +28,15 @@ import (
 	rportplus "github.com/realvnc-labs/rport/plus"
 	alertingcap "github.com/realvnc-labs/rport/plus/capabilities/alerting"
 	"github.com/realvnc-labs/rport/plus/capabilities/alerting/transformers"
+	"github.com/realvnc-labs/rport/server/api/jobs"
 	"github.com/realvnc-labs/rport/server/api/middleware"
 	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/capture"
 	"github.com/realvnc-labs/rport/server/chconfig"
 	"github.com/realvnc-labs/rport/server/clients"
 	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/clientsauth"
+	"github.com/realvnc-labs/rport/server/clientversion"
 	chshare "github.com/realvnc-labs/rport/share"
 	"github.com/realvnc-labs/rport/share/comm"
 	"github.com/realvnc-labs/rport/share/logger"
@@ -91,9 +98,18 @@ func NewClientListener(server *Server, privateKey ssh.Signer) (*ClientListener,
 	inprogressSSHHandshakes := make(chan struct{}, config.Server.MaxConcurrentSSHConnectionHandshakes)
 
 	clog := logger.NewLogger("client-listener", config.Logging.LogOutput, config.Logging.LogLevel)
+	httpServerOptions := []chshare.ServerOption{chshare.WithProxyProtocol(&config.Server.ProxyProtocol)}
+	activated, err := chshare.ActivatedListener(chshare.ActivatedServerSocket)
+	if err != nil {
+		return nil, err
+	}
+	if activated != nil {
+		clog.Infof("Using socket %s passed by systemd instead of %s", activated.Addr(), config.Server.ListenAddress)
+		httpServerOptions = append(httpServerOptions, chshare.WithListener(activated))
+	}
 	cl := &ClientListener{
 		server:                  server,
-		httpServer:              chshare.NewHTTPServer(int(config.Server.MaxRequestBytesClient), clog),
+		httpServer:              chshare.NewHTTPServer(int(config.Server.MaxRequestBytesClient), clog, httpServerOptions...),
 		requestLogOptions:       config.InitRequestLogOptions(),
 		bannedClientAuths:       security.NewBanList(time.Duration(config.Server.ClientLoginWait) * time.Second),
 		inprogressSSHHandshakes: inprogressSSHHandshakes,
@@ -142,6 +158,12 @@ func (cl *ClientListener) authUser(c ssh.ConnMetadata, password []byte) (*ssh.Pe
 	clientAuthID := c.User()
 
 	if cl.bannedClientAuths.IsBanned(clientAuthID) {
+		cl.server.handshakeFailures.Add(clientsauth.HandshakeFailure{
+			ClientAuthID: clientAuthID,
+			IP:           cl.getIP(c.RemoteAddr()),
+			Reason:       clientsauth.HandshakeTooManyAttempts,
+			Error:        ErrTooManyRequests.Error(),
+		})
 		cl.log().Infof("Failed login attempt for client auth id %q, forcing to wait for %vs (%s)",
 			clientAuthID,
 			cl.server.config.Server.ClientLoginWait,
@@ -159,6 +181,15 @@ func (cl *ClientListener) authUser(c ssh.ConnMetadata, password []byte) (*ssh.Pe
 	// constant time compare is used for security reasons
 	if clientAuth == nil || subtle.ConstantTimeCompare([]byte(clientAuth.Password), password) != 1 {
 		cl.log().Debugf("Login failed for client auth id: %s", clientAuthID)
+		reason := clientsauth.HandshakeInvalidPassword
+		if clientAuth == nil {
+			reason = clientsauth.HandshakeUnknownClientAuth
+		}
+		cl.server.handshakeFailures.Add(clientsauth.HandshakeFailure{
+			ClientAuthID: clientAuthID,
+			IP:           ip,
+			Reason:       reason,
+		})
 		cl.bannedClientAuths.Add(clientAuthID)
 		if cl.bannedIPs != nil {
 			cl.bannedIPs.AddBadAttempt(ip)
@@ -226,6 +257,12 @@ func (cl *ClientListener) handleClient(w http.ResponseWriter, r *http.Request) {
 		// print into server logs and silently fall-through
 		cl.log().Infof("ignored client connection using protocol '%s', expected '%s'",
 			protocol, chshare.ProtocolVersion)
+		ip, _, _ := net.SplitHostPort(r.RemoteAddr)
+		cl.server.handshakeFailures.Add(clientsauth.HandshakeFailure{
+			IP:     ip,
+			Reason: clientsauth.HandshakeProtocolMismatch,
+			Error:  fmt.Sprintf("protocol %s, expected %s", protocol, chshare.ProtocolVersion),
+		})
 	}
 	// proxy target was provided
 	if cl.reverseProxy != nil {
@@ -263,8 +300,22 @@ func (cl *ClientListener) acceptSSHConnection(w http.ResponseWriter, req *http.R
 	if err != nil {
 		if strings.Contains(err.Error(), "unexpected EOF") {
 			clog.Debugf("Failed to handshake (client closed connection? - %s) from %s", err, conn.RemoteAddr().String())
+			cl.server.handshakeFailures.Add(clientsauth.HandshakeFailure{
+				IP:     cl.getIP(conn.RemoteAddr()),
+				Reason: clientsauth.HandshakeClosedByClient,
+				Error:  err.Error(),
+			})
 		} else {
 			clog.Debugf("Failed to handshake (%s) from %s", err, conn.RemoteAddr().String())
+			// authentication failures are recorded by authUser
+			var authErr *ssh.ServerAuthError
+			if !errors.As(err, &authErr) {
+				cl.server.handshakeFailures.Add(clientsauth.HandshakeFailure{
+					IP:     cl.getIP(conn.RemoteAddr()),
+					Reason: clientsauth.HandshakeFailed,
+					Error:  err.Error(),
+				})
+			}
 		}
 		<-cl.inprogressSSHHandshakes
 		return nil, nil, nil, nil, err
@@ -316,6 +367,19 @@ func (cl *ClientListener) receiveClientConnectionRequest(sshConn *ssh.ServerConn
 		return nil, nil, fmt.Errorf("invalid connection request: %s", err)
 	}
 
+	if cl.server.clientPayload != nil {
+		if err := cl.server.clientPayload.Validate(connRequest); err != nil {
+			clog.Infof("rejected connection request: %s", err)
+			return nil, r, fmt.Errorf("invalid connection request: %s", err)
+		}
+	}
+
+	connRequest.Tags, err = cl.server.clientTags.Validate(connRequest.Tags, sshConn.User())
+	if err != nil {
+		clog.Infof("rejected connection request: %s", err)
+		return nil, r, fmt.Errorf("invalid connection request: %s", err)
+	}
+
 	return connRequest, r, nil
 }
 
@@ -335,19 +399,34 @@ func (cl *ClientListener) handleWebsocket(w http.ResponseWriter, req *http.Reque
 	// first request to be received must be a connection request
 	connRequest, r, err := cl.receiveClientConnectionRequest(sshConn, reqs, clientLog)
 	if err != nil {
+		cl.server.handshakeFailures.Add(clientsauth.HandshakeFailure{
+			ClientAuthID: sshConn.User(),
+			IP:           cl.getIP(sshConn.RemoteAddr()),
+			Reason:       clientsauth.HandshakeRejected,
+			Error:        err.Error(),
+		})
 		cl.replyConnectionError(r, err)
 		return
 	}
 
 	clientLog.Debugf("client version: %s", connRequest.Version)
+	if connRequest.Capabilities == nil {
+		clientLog.Debugf("client doesn't support capability negotiation, legacy features assumed")
+	}
 
 	checkVersions(clientLog, connRequest.Version)
 
 	// get the current client auth id
 	clientAuthID := sshConn.User()
 
-	clientID, err := cl.getClientID(connRequest.ID, cl.server.config, clientAuthID)
+	clientID, err := cl.getClientID(connRequest, cl.server.config, clientAuthID)
 	if err != nil {
+		cl.server.handshakeFailures.Add(clientsauth.HandshakeFailure{
+			ClientAuthID: clientAuthID,
+			IP:           cl.getIP(sshConn.RemoteAddr()),
+			Reason:       clientsauth.HandshakeRejected,
+			Error:        err.Error(),
+		})
 		cl.replyConnectionError(r, fmt.Errorf("could not get clientID: %s", err))
 		return
 	}
@@ -357,6 +436,13 @@ func (cl *ClientListener) handleWebsocket(w http.ResponseWriter, req *http.Reque
 
 	client, err := cl.getClientService().StartClient(ctx, clientAuthID, clientID, sshConn, cl.server.config.Server.AuthMultiuseCreds, connRequest, clientLog)
 	if err != nil {
+		cl.server.handshakeFailures.Add(clientsauth.HandshakeFailure{
+			ClientAuthID: clientAuthID,
+			ClientID:     clientID,
+			IP:           cl.getIP(sshConn.RemoteAddr()),
+			Reason:       startClientFailureReason(err),
+			Error:        err.Error(),
+		})
 		cl.replyConnectionError(r, err)
 		return
 	}
@@ -365,7 +451,7 @@ func (cl *ClientListener) handleWebsocket(w http.ResponseWriter, req *http.Reque
 	ts2 := time.Now()
 
 	cl.replyConnectionSuccess(r, connRequest.Remotes)
-	cl.sendCapabilities(sshConn)
+	cl.sendCapabilities(sshConn, client)
 	// Now the client is fully connected and ready to create tunnels and execute command and scripts
 
 	clientBanner := client.Banner()
@@ -373,7 +459,14 @@ func (cl *ClientListener) handleWebsocket(w http.ResponseWriter, req *http.Reque
 
 	// now run handler for other client requests and connections
 	go cl.handleSSHRequests(clientLog, clientID, reqs)
-	go cl.handleSSHChannels(clientLog, chans)
+	go cl.handleSSHChannels(clientLog, clientID, chans)
+	go cl.queryInterpreters(clientLog, clientID, sshConn)
+	if cl.server.config.Monitoring.Enabled {
+		go cl.pushMonitoringProfile(clientLog, client)
+	}
+	if len(client.GetReverseTunnels()) > 0 && !client.IsCheckInOnly() && !client.IsQuarantined() {
+		go cl.server.startReverseTunnels(clientLog, client)
+	}
 
 	// wait until we're disconnected from the client
 	if err = sshConn.Wait(); err != nil {
@@ -387,6 +480,19 @@ func (cl *ClientListener) handleWebsocket(w http.ResponseWriter, req *http.Reque
 	}
 }
 
+func startClientFailureReason(err error) clientsauth.HandshakeFailureReason {
+	var outdatedErr *clientversion.OutdatedError
+	switch {
+	case errors.Is(err, clients.ErrClientAlreadyConnected):
+		return clientsauth.HandshakeClientIDConflict
+	case errors.Is(err, clients.ErrClientAuthIDInUse):
+		return clientsauth.HandshakeClientAuthInUse
+	case errors.As(err, &outdatedErr):
+		return clientsauth.HandshakeVersionRejected
+	}
+	return clientsauth.HandshakeRejected
+}
+
 // checkVersions print if client and server versions dont match.
 func checkVersions(log *logger.Logger, clientVersion string) {
 	if clientVersion == chshare.BuildVersion {
@@ -401,9 +507,42 @@ func checkVersions(log *logger.Logger, clientVersion string) {
 	log.Infof("Client version (%s) differs from server version (%s)", v, chshare.BuildVersion)
 }
 
-func (cl *ClientListener) getClientID(reqID string, config *chconfig.Config, clientAuthID string) (string, error) {
-	if reqID != "" {
-		return reqID, nil
+func (cl *ClientListener) getClientID(connRequest *chshare.ConnectionRequest, config *chconfig.Config, clientAuthID string) (string, error) {
+	clientID, err := deriveClientID(connRequest, config, clientAuthID)
+	if err != nil {
+		return "", err
+	}
+
+	// viper lower-cases map keys, so client auth ids are matched case-insensitively
+	prefix := config.Server.ClientIDPrefixes[strings.ToLower(clientAuthID)]
+	if prefix != "" && !strings.HasPrefix(clientID, prefix) {
+		clientID = prefix + clientID
+	}
+
+	return clientID, nil
+}
+
+// deriveClientID returns the client id according to the configured client id policy.
+func deriveClientID(connRequest *chshare.ConnectionRequest, config *chconfig.Config, clientAuthID string) (string, error) {
+	switch config.Server.ClientIDPolicy {
+	case chconfig.ClientIDPolicyMachineIDHash:
+		if connRequest.ID == "" {
+			return "", fmt.Errorf("client id policy %q requires the client to send an id, enable client.use_system_id", config.Server.ClientIDPolicy)
+		}
+		hash := sha256.Sum256([]byte(clientAuthID + ":" + connRequest.ID))
+		return hex.EncodeToString(hash[:16]), nil
+	case chconfig.ClientIDPolicyHostname:
+		hostname := sanitizeClientID(connRequest.Hostname)
+		if hostname == "" {
+			return "", fmt.Errorf("client id policy %q requires the client to report a hostname", config.Server.ClientIDPolicy)
+		}
+		return hostname, nil
+	case chconfig.ClientIDPolicyUUID:
+		return clientdata.NewClientID()
+	}
+
+	if connRequest.ID != "" {
+		return connRequest.ID, nil
 	}
 
 	// use client auth id as client id if proper configs are set
@@ -414,6 +553,14 @@ func (cl *ClientListener) getClientID(reqID string, config *chconfig.Config, cli
 	return clientdata.NewClientID()
 }
 
+var invalidClientIDChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// sanitizeClientID returns a lower-cased id with any char except letters, digits, dots, underscores and dashes replaced.
+func sanitizeClientID(id string) string {
+	id = strings.ToLower(strings.TrimSpace(id))
+	return strings.Trim(invalidClientIDChars.ReplaceAllString(id, "-"), "-")
+}
+
 func (cl *ClientListener) replyConnectionSuccess(r *ssh.Request, remotes []*models.Remote) {
 	replyPayload, err := json.Marshal(remotes)
 	if err != nil {
@@ -478,11 +625,36 @@ func (cl *ClientListener) handleSSHRequests(clientLog *logger.Logger, clientID s
 			// clientLog.Debugf("ping received from: %s", clientID)
 			// ts := time.Now()
 			_ = r.Reply(true, nil)
-			err := clientService.SetLastHeartbeat(clientID, time.Now())
+			now := time.Now()
+			err := clientService.SetLastHeartbeat(clientID, now)
 			if err != nil {
 				clientLog.Errorf("Failed to save heartbeat: %s", err)
 				continue
 			}
+			ping, err := comm.DecodePingRequest(r.Payload)
+			if err != nil {
+				clientLog.Debugf("Failed to decode ping: %s", err)
+				continue
+			}
+			// older clients send pings without timestamp
+			if ping != nil && !ping.Timestamp.IsZero() {
+				err = clientService.SetClockSkew(clientID, ping.Timestamp.Sub(now))
+				if err != nil {
+					clientLog.Errorf("Failed to save clock skew: %s", err)
+				}
+			}
+			if ping != nil && ping.AgentStats != nil {
+				err = clientService.SetAgentStats(clientID, ping.AgentStats)
+				if err != nil {
+					clientLog.Errorf("Failed to save agent stats: %s", err)
+				}
+			}
+			if ping != nil && ping.Addresses != nil {
+				err = clientService.SetAddresses(clientID, ping.Addresses.IPv4, ping.Addresses.IPv6)
+				if err != nil {
+					clientLog.Errorf("Failed to save addresses: %s", err)
+				}
+			}
 			// clientLog.Debugf("ping for: %s done in %s", clientID, time.Since(ts))
 
 		case comm.RequestTypeCmdResult:
@@ -494,10 +666,13 @@ func (cl *ClientListener) handleSSHRequests(clientLog *logger.Logger, clientID s
 				continue
 			}
 			clientLog.Debugf("%s, Command result saved successfully.", job.LogPrefix())
+			jobs.CountFinishedJob(job)
 
 			var auditLogEntry *auditlog.Entry
 			if job.IsScript {
 				auditLogEntry = cl.server.auditLog.Entry(auditlog.ApplicationClientScript, auditlog.ActionExecuteDone)
+			} else if job.IsCapture {
+				auditLogEntry = cl.server.auditLog.Entry(auditlog.ApplicationClientCapture, auditlog.ActionExecuteDone)
 			} else {
 				auditLogEntry = cl.server.auditLog.Entry(auditlog.ApplicationClientCommand, auditlog.ActionExecuteDone)
 			}
@@ -508,6 +683,8 @@ func (cl *ClientListener) handleSSHRequests(clientLog *logger.Logger, clientID s
 			}
 			auditLogEntry.
 				WithResponse(job).
+				WithLabels(job.Labels).
+				WithRequestID(job.CorrelationID).
 				WithClientID(clientID).
 				Save()
 
@@ -534,6 +711,23 @@ func (cl *ClientListener) handleSSHRequests(clientLog *logger.Logger, clientID s
 				clientLog.Errorf("Failed to save updates status: %s", err)
 				continue
 			}
+			if cl.server.updatesRefresher != nil {
+				cl.server.updatesRefresher.Done(clientID, updatesStatus)
+			}
+
+		case comm.RequestTypeSecuritySnapshot:
+			clientLog.Debugf("setting security snapshot from: %s", clientID)
+			snapshot := &models.SecuritySnapshot{}
+			err := json.Unmarshal(r.Payload, snapshot)
+			if err != nil {
+				clientLog.Errorf("Failed to unmarshal security snapshot: %s", err)
+				continue
+			}
+			err = clientService.SetSecuritySnapshot(clientID, snapshot)
+			if err != nil {
+				clientLog.Errorf("Failed to save security snapshot: %s", err)
+				continue
+			}
 
 		case comm.RequestTypeSaveMeasurement:
 			// if server monitoring is disabled then do not save measurements even if received
@@ -554,6 +748,12 @@ func (cl *ClientListener) handleSSHRequests(clientLog *logger.Logger, clientID s
 				clientLog.Errorf("Failed to save measurement for client %s: %s", clientID, err)
 				continue
 			}
+			if len(measurement.CustomMetrics) > 0 {
+				err = cl.server.monitoringService.SaveCustomMetrics(context.Background(), clientID, measurement.CustomMetrics)
+				if err != nil {
+					clientLog.Errorf("Failed to save custom metrics for client %s: %s", clientID, err)
+				}
+			}
 
 			if rportplus.IsPlusEnabled(cl.server.config.PlusConfig) {
 				alertingCap := cl.server.plusManager.GetAlertingCapabilityEx()
@@ -577,6 +777,11 @@ func (cl *ClientListener) sendMeasurementToAlertingService(
 		return
 	}
 
+	m.CustomMetrics, err = cl.server.monitoringService.GetLatestCustomMetrics(context.Background(), measurement.ClientID, customMetricsMaxAge)
+	if err != nil {
+		clientLog.Debugf("Failed to get custom metrics: %v", err)
+	}
+
 	as := alertingCap.GetService()
 
 	err = as.PutMeasurement(m)
@@ -593,6 +798,15 @@ func (cl *ClientListener) saveCmdResult(respBytes []byte) (*models.Job, error) {
 		return nil, fmt.Errorf("failed to decode cmd result request: %s", err)
 	}
 
+	cl.server.redactor.RedactJob(&resp)
+	if resp.RedactionCount > 0 {
+		cl.log().Debugf("%s, %d matches of redaction rules replaced in result.", resp.LogPrefix(), resp.RedactionCount)
+		respBytes, err = json.Marshal(resp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode redacted cmd result: %s", err)
+		}
+	}
+
 	var wsJID string
 	if resp.MultiJobID != nil {
 		wsJID = *resp.MultiJobID
@@ -618,7 +832,7 @@ func (cl *ClientListener) saveCmdResult(respBytes []byte) (*models.Job, error) {
 	return &resp, nil
 }
 
-func (cl *ClientListener) handleSSHChannels(clientLog *logger.Logger, chans <-chan ssh.NewChannel) {
+func (cl *ClientListener) handleSSHChannels(clientLog *logger.Logger, clientID string, chans <-chan ssh.NewChannel) {
 	for ch := range chans {
 		ch := ch
 		extraData := string(ch.ExtraData())
@@ -644,6 +858,15 @@ func (cl *ClientListener) handleSSHChannels(clientLog *logger.Logger, chans <-ch
 					clientLog.Errorf("Error handling output channel %s: %v", ch.ChannelType(), err)
 				}
 			}()
+		case models.ChannelCapture:
+			go func() {
+				err := cl.handleCaptureChannel(ch.ExtraData(), clientLog, stream)
+				if err != nil {
+					clientLog.Errorf("Error handling capture channel: %v", err)
+				}
+			}()
+		case models.ChannelReverseTunnel:
+			go cl.server.handleReverseTunnelChannel(cl.getCtx(), clientLog, clientID, extraData, stream)
 		default:
 			// handle stream type
 			connID := cl.connStats.New()
@@ -681,40 +904,83 @@ func (cl *ClientListener) handleOutputChannel(typ string, jobData []byte, client
 		ClientName: job.ClientName,
 	}
 
+	write := func(output string) {
+		if output == "" {
+			return
+		}
+		if ws == nil {
+			clientLog.Debugf("WS conn not found handling output channel. No active listeners connected")
+			return
+		}
+		switch typ {
+		case models.ChannelStdout:
+			ocd.Result = &models.JobResult{
+				StdOut: output,
+			}
+		case models.ChannelStderr:
+			ocd.Result = &models.JobResult{
+				StdErr: output,
+			}
+		}
+		err := ws.WriteNonFinalJSON(ocd)
+		if err != nil {
+			clientLog.Errorf("Failed to write message to UI Web Socket: %v", err)
+			// proceed further
+		}
+	}
+
+	// output is redacted before it's streamed, the same as the final result
+	redactStream := cl.server.redactor.NewStream()
 	data := make([]byte, 4096)
 	for {
 		n, err := stream.Read(data)
 		if err != nil {
 			if err == io.EOF {
 				clientLog.Debugf("Output channel %s for %s stop: %v", typ, wsJID, err)
+				write(redactStream.Flush())
 				break
 			}
 			return err
 		}
 
-		if ws != nil {
-			switch typ {
-			case models.ChannelStdout:
-				ocd.Result = &models.JobResult{
-					StdOut: string(data[:n]),
-				}
-			case models.ChannelStderr:
-				ocd.Result = &models.JobResult{
-					StdErr: string(data[:n]),
-				}
-			}
-			err := ws.WriteNonFinalJSON(ocd)
-			if err != nil {
-				clientLog.Errorf("Failed to write message to UI Web Socket: %v", err)
-				// proceed further
-			}
-		} else {
-			clientLog.Debugf("WS conn not found handling output channel. No active listeners connected")
-		}
+		write(redactStream.Write(string(data[:n])))
 	}
 	return nil
 }
 
+// handleCaptureChannel writes the pcap data sent by the client to the capture created by the server for the job.
+// The channel is closed when the size limit or quota is reached, so the client stops the capture.
+func (cl *ClientListener) handleCaptureChannel(jobData []byte, clientLog *logger.Logger, stream ssh.Channel) error {
+	defer stream.Close()
+
+	job := models.Job{}
+	err := json.Unmarshal(jobData, &job)
+	if err != nil {
+		return err
+	}
+	if cl.server.captures == nil {
+		return errors.New("packet capture is disabled")
+	}
+
+	// the client id of the job is sent by the client, attaching checks it against the client the capture was created for
+	w, err := cl.server.captures.Attach(job.JID, job.ClientID)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.JID, err)
+	}
+	defer w.Close()
+
+	n, err := io.Copy(w, stream)
+	if errors.Is(err, capture.ErrLimitReached) || errors.Is(err, capture.ErrQuotaExceeded) {
+		clientLog.Infof("Packet capture of job %s stopped after %d bytes: %v", job.JID, n, err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.JID, err)
+	}
+	clientLog.Debugf("Packet capture of job %s finished with %d bytes", job.JID, n)
+	return nil
+}
+
 func (cl *ClientListener) handleReq(req *ssh.Request, clientLog *logger.Logger) {
 	ok := false
 	switch req.Type {
@@ -753,8 +1019,36 @@ func (cl *ClientListener) handleSessionChannel(stream ssh.Channel, clientLog *lo
 	}
 }
 
-func (cl *ClientListener) sendCapabilities(conn *ssh.ServerConn) {
-	payload, err := json.Marshal(cl.server.capabilities)
+// queryInterpreters stores the interpreters found on the client. Clients not supporting it keep unknown interpreters,
+// so jobs are not validated against them.
+func (cl *ClientListener) queryInterpreters(clientLog *logger.Logger, clientID string, conn ssh.Conn) {
+	var interpreters []models.Interpreter
+	err := comm.SendRequestAndGetResponse(conn, comm.RequestTypeGetInterpreters, nil, &interpreters, clientLog)
+	if err != nil {
+		clientLog.Debugf("can't get interpreters: %v", err)
+		return
+	}
+
+	err = cl.getClientService().SetInterpreters(clientID, interpreters)
+	if err != nil {
+		clientLog.Errorf("can't save interpreters: %v", err)
+	}
+}
+
+func (cl *ClientListener) pushMonitoringProfile(clientLog *logger.Logger, client *clientdata.Client) {
+	if err := cl.server.pushMonitoringProfile(cl.getCtx(), client); err != nil {
+		clientLog.Errorf("can't push monitoring profile: %v", err)
+	}
+}
+
+// sendCapabilities sends the server capabilities with the protocol version and the features negotiated with the client.
+func (cl *ClientListener) sendCapabilities(conn *ssh.ServerConn, client *clientdata.Client) {
+	caps := *cl.server.capabilities
+	client.GetLock().RLock()
+	caps.ProtocolVersion, caps.Features = client.ProtocolVersion, client.Features
+	client.GetLock().RUnlock()
+
+	payload, err := json.Marshal(caps)
 	if err != nil {
 		cl.log().Errorf("can't encode capabilities payload")
 		return
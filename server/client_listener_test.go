@@ -10,6 +10,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/realvnc-labs/rport/server/chconfig"
+	chshare "github.com/realvnc-labs/rport/share"
 	"github.com/realvnc-labs/rport/share/logger"
 	"github.com/realvnc-labs/rport/share/models"
 	"github.com/realvnc-labs/rport/share/ptr"
@@ -119,3 +121,95 @@ func (c *connMock) WriteJSON(data interface{}) error {
 	c.LastWrite = data
 	return nil
 }
+
+func TestGetClientID(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Server        chconfig.ServerConfig
+		Request       chshare.ConnectionRequest
+		ExpectedID    string
+		ExpectedError string
+	}{
+		{
+			Name:       "client policy",
+			Server:     chconfig.ServerConfig{ClientIDPolicy: chconfig.ClientIDPolicyClient},
+			Request:    chshare.ConnectionRequest{ID: "machine-1"},
+			ExpectedID: "machine-1",
+		},
+		{
+			Name:       "client policy, equate client auth id",
+			Server:     chconfig.ServerConfig{ClientIDPolicy: chconfig.ClientIDPolicyClient, EquateClientauthidClientid: true},
+			ExpectedID: "Site-A",
+		},
+		{
+			Name:       "machine id hash",
+			Server:     chconfig.ServerConfig{ClientIDPolicy: chconfig.ClientIDPolicyMachineIDHash},
+			Request:    chshare.ConnectionRequest{ID: "machine-1"},
+			ExpectedID: "d9a3e87b6c8e5b89cf3ad3e8c853afcd",
+		},
+		{
+			Name:          "machine id hash without id",
+			Server:        chconfig.ServerConfig{ClientIDPolicy: chconfig.ClientIDPolicyMachineIDHash},
+			ExpectedError: `client id policy "machine_id_hash" requires the client to send an id, enable client.use_system_id`,
+		},
+		{
+			Name:       "hostname",
+			Server:     chconfig.ServerConfig{ClientIDPolicy: chconfig.ClientIDPolicyHostname},
+			Request:    chshare.ConnectionRequest{ID: "machine-1", Hostname: " Web Server#1.example.com "},
+			ExpectedID: "web-server-1.example.com",
+		},
+		{
+			Name:          "hostname missing",
+			Server:        chconfig.ServerConfig{ClientIDPolicy: chconfig.ClientIDPolicyHostname},
+			Request:       chshare.ConnectionRequest{ID: "machine-1"},
+			ExpectedError: `client id policy "hostname" requires the client to report a hostname`,
+		},
+		{
+			Name: "hostname with prefix",
+			Server: chconfig.ServerConfig{
+				ClientIDPolicy:   chconfig.ClientIDPolicyHostname,
+				ClientIDPrefixes: map[string]string{"site-a": "site-a-", "site-b": "site-b-"},
+			},
+			Request:    chshare.ConnectionRequest{Hostname: "web1"},
+			ExpectedID: "site-a-web1",
+		},
+		{
+			Name: "prefix is not repeated",
+			Server: chconfig.ServerConfig{
+				ClientIDPolicy:   chconfig.ClientIDPolicyClient,
+				ClientIDPrefixes: map[string]string{"site-a": "site-a-"},
+			},
+			Request:    chshare.ConnectionRequest{ID: "site-a-web1"},
+			ExpectedID: "site-a-web1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			cl := &ClientListener{}
+			config := &chconfig.Config{Server: tc.Server}
+
+			id, err := cl.getClientID(&tc.Request, config, "Site-A")
+
+			if tc.ExpectedError != "" {
+				assert.EqualError(t, err, tc.ExpectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedID, id)
+		})
+	}
+
+	t.Run("uuid", func(t *testing.T) {
+		cl := &ClientListener{}
+		config := &chconfig.Config{Server: chconfig.ServerConfig{ClientIDPolicy: chconfig.ClientIDPolicyUUID}}
+
+		id1, err := cl.getClientID(&chshare.ConnectionRequest{ID: "machine-1"}, config, "site-a")
+		require.NoError(t, err)
+		id2, err := cl.getClientID(&chshare.ConnectionRequest{ID: "machine-1"}, config, "site-a")
+		require.NoError(t, err)
+
+		assert.NotEqual(t, "machine-1", id1)
+		assert.NotEqual(t, id1, id2)
+	})
+}
@@ -2,6 +2,8 @@ package chserver
 
 import (
 	"context"
+	"hash/fnv"
+	"sync"
 	"time"
 
 	"github.com/realvnc-labs/rport/server/clients"
@@ -12,35 +14,115 @@ import (
 
 const DefaultMaxWorkers = 100
 
+// KeepaliveWheelSlots is the number of slots the clients are spread over, so each run of the status check
+// pings only the clients of one slot instead of all clients at once.
+const KeepaliveWheelSlots = 60
+
 type ClientsStatusCheckTask struct {
 	log         *logger.Logger
 	clientsRepo *clients.ClientRepository
 	threshold   time.Duration // Threshold after which a client to server ping is considered outdated.
 	pingTimeout time.Duration // Don't wait longer than pingTimeout for a response
+	slots       int           // Number of wheel slots, the task must run slots times per threshold to check all clients.
+
+	nextSlot int
+	stats    KeepaliveStats
+	// pinging are the ids of the clients with a ping in flight, they are skipped until the ping returns
+	pinging map[string]bool
+	mu      sync.Mutex
+	pings   sync.WaitGroup
 }
 
-// NewClientsStatusCheckTask pings all active clients and marks them disconnected on ping failure
-func NewClientsStatusCheckTask(log *logger.Logger, cr *clients.ClientRepository, th time.Duration, pingTimeout time.Duration) *ClientsStatusCheckTask {
+// KeepaliveStats are the aggregated results of the server to client pings since the server started.
+type KeepaliveStats struct {
+	WheelSlots int        `json:"wheel_slots"`
+	Pinged     int64      `json:"pinged"`
+	Missed     int64      `json:"missed"`
+	LastMissAt *time.Time `json:"last_miss_at"`
+	LastRunAt  *time.Time `json:"last_run_at"`
+}
+
+// NewClientsStatusCheckTask pings active clients and marks them disconnected on ping failure.
+// Clients are spread over the given number of wheel slots by their id and each run checks the clients of the next slot.
+func NewClientsStatusCheckTask(log *logger.Logger, cr *clients.ClientRepository, th time.Duration, pingTimeout time.Duration, slots int) *ClientsStatusCheckTask {
+	if slots < 1 {
+		slots = 1
+	}
 	return &ClientsStatusCheckTask{
 		log:         log.Fork("clients-status-check"),
 		clientsRepo: cr,
 		threshold:   th,
 		pingTimeout: pingTimeout,
+		slots:       slots,
+		stats:       KeepaliveStats{WheelSlots: slots},
+		pinging:     make(map[string]bool),
 	}
 }
 
+// Stats returns the aggregated keepalive stats.
+func (t *ClientsStatusCheckTask) Stats() KeepaliveStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
+
+// Run pings the due clients of the next slot. The pings run in the background, so clients not responding until the
+// ping timeout don't delay the next slots.
 func (t *ClientsStatusCheckTask) Run(ctx context.Context) error {
-	t.log.Debugf("status check running")
+	t.mu.Lock()
+	slot := t.nextSlot
+	t.nextSlot = (slot + 1) % t.slots
+	t.mu.Unlock()
+
+	t.log.Debugf("status check running for slot %d/%d", slot+1, t.slots)
 	timerStart := time.Now()
-	var confirmedClients = 0
 
-	dueClients, totalClientsCount := t.getDueClients()
+	dueClients, totalClientsCount := t.getDueClients(slot)
+	dueClients = t.startPinging(dueClients)
 	if len(dueClients) == 0 {
 		// Nothing to do
 		t.log.Debugf("ended after %s, no clients to ping", time.Since(timerStart))
+		t.updateStats(timerStart, 0, 0)
 		return nil
 	}
 
+	t.pings.Add(1)
+	go func() {
+		defer t.pings.Done()
+		t.pingDueClients(ctx, timerStart, dueClients, totalClientsCount)
+	}()
+	return nil
+}
+
+// startPinging marks the clients as being pinged and returns the ones that are not pinged already.
+func (t *ClientsStatusCheckTask) startPinging(dueClients []*clientdata.Client) []*clientdata.Client {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	res := make([]*clientdata.Client, 0, len(dueClients))
+	for _, c := range dueClients {
+		if t.pinging[c.GetID()] {
+			t.log.Debugf("skipping client %s, the previous ping did not return yet", c.GetID())
+			continue
+		}
+		t.pinging[c.GetID()] = true
+		res = append(res, c)
+	}
+	return res
+}
+
+func (t *ClientsStatusCheckTask) donePinging(dueClients []*clientdata.Client) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, c := range dueClients {
+		delete(t.pinging, c.GetID())
+	}
+}
+
+func (t *ClientsStatusCheckTask) pingDueClients(ctx context.Context, timerStart time.Time, dueClients []*clientdata.Client, totalClientsCount int) {
+	defer t.donePinging(dueClients)
+
 	// make sure no more workers than clients and limit to max workers
 	maxWorkers := DefaultMaxWorkers
 	if maxWorkers > len(dueClients) {
@@ -78,15 +160,43 @@ func (t *ClientsStatusCheckTask) Run(ctx context.Context) error {
 		}
 	}
 
-	t.log.Debugf("ended after %s, skipped: %d, pinged: %d, alive: %d, dead: %d, total: %d", time.Since(timerStart), confirmedClients, len(dueClients), alive, dead, totalClientsCount)
-	return nil
+	t.updateStats(timerStart, len(dueClients), dead)
+
+	t.log.Debugf("ended after %s, pinged: %d, alive: %d, dead: %d, total: %d", time.Since(timerStart), len(dueClients), alive, dead, totalClientsCount)
 }
 
-func (t *ClientsStatusCheckTask) getDueClients() (dueClients []*clientdata.Client, totalCount int) {
+// waitForPings blocks until the pings of all runs returned.
+func (t *ClientsStatusCheckTask) waitForPings() {
+	t.pings.Wait()
+}
+
+func (t *ClientsStatusCheckTask) updateStats(runAt time.Time, pinged, missed int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats.Pinged += int64(pinged)
+	t.stats.Missed += int64(missed)
+	t.stats.LastRunAt = &runAt
+	if missed > 0 {
+		now := time.Now()
+		t.stats.LastMissAt = &now
+	}
+}
+
+// wheelSlot returns the slot of the client, it's stable for a client id.
+func wheelSlot(clientID string, slots int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(clientID))
+	return int(h.Sum32() % uint32(slots))
+}
+
+func (t *ClientsStatusCheckTask) getDueClients(slot int) (dueClients []*clientdata.Client, totalCount int) {
 	var confirmedClients = 0
 	var now = time.Now()
 	activeClients := t.clientsRepo.GetAllActiveClients()
 	for _, c := range activeClients {
+		if wheelSlot(c.GetID(), t.slots) != slot {
+			continue
+		}
 		// Shorten the threshold aka make heartbeat older than it is because the ping response is stored after this check.
 		// Clients would get checked only every second time otherwise.
 		if c.HasLastHeartbeatAt() {
@@ -21,6 +21,7 @@ type mockSSHConn struct {
 	ssh.Conn
 	shallFail    bool
 	shallTimeout bool
+	stall        chan struct{}
 }
 
 func (m mockSSHConn) SendRequest(name string, wantReply bool, payload []byte) (bool, []byte, error) {
@@ -33,6 +34,9 @@ func (m mockSSHConn) SendRequest(name string, wantReply bool, payload []byte) (b
 	if m.shallTimeout {
 		time.Sleep(2 * time.Millisecond)
 	}
+	if m.stall != nil {
+		<-m.stall
+	}
 	return true, []byte(""), nil
 }
 
@@ -93,11 +97,12 @@ func TestClientsStatusDeterminationTask(t *testing.T) {
 	c4.Logger = myTestLog
 
 	cr := clients.NewClientRepository([]*clientdata.Client{&c1, &c2, &c3, &c4}, nil, myTestLog)
-	task := NewClientsStatusCheckTask(myTestLog, cr, 120*time.Second, timeout)
+	task := NewClientsStatusCheckTask(myTestLog, cr, 120*time.Second, timeout, 1)
 
 	// Check the last heartbeat of c1 has changed due to the ping sent
 	err = task.Run(context.Background())
 	assert.NoError(t, err)
+	task.waitForPings()
 	tcl1, err := cr.GetByID("1")
 	assert.NoError(t, err)
 	assert.IsType(t, &time.Time{}, tcl1.GetLastHeartbeatAt())
@@ -126,3 +131,72 @@ func TestClientsStatusDeterminationTask(t *testing.T) {
 	assert.NoError(t, err, "error reading log file")
 	assert.Contains(t, string(log), fmt.Sprintf("ping to  [4] failed: conn.SendRequest(ping), timeout %s exceeded", timeout))
 }
+
+func TestClientsStatusCheckTaskWheelSlots(t *testing.T) {
+	const slots = 4
+	var clientsList []*clientdata.Client
+	for i := 0; i < 20; i++ {
+		c := &clientdata.Client{}
+		c.SetID(fmt.Sprintf("client-%d", i))
+		c.SetClientAuthID(c.GetID())
+		c.SetConnection(mockSSHConn{shallFail: i%5 == 0})
+		c.Logger = testLog
+		clientsList = append(clientsList, c)
+	}
+	cr := clients.NewClientRepository(clientsList, nil, testLog)
+	task := NewClientsStatusCheckTask(testLog, cr, 120*time.Second, time.Second, slots)
+
+	for slot := 0; slot < slots; slot++ {
+		before := task.Stats().Pinged
+		expected := 0
+		for _, c := range cr.GetAllActiveClients() {
+			if wheelSlot(c.GetID(), slots) == slot {
+				expected++
+			}
+		}
+
+		assert.NoError(t, task.Run(context.Background()))
+		task.waitForPings()
+
+		assert.Equal(t, int64(expected), task.Stats().Pinged-before, "slot %d", slot)
+	}
+
+	stats := task.Stats()
+	assert.Equal(t, slots, stats.WheelSlots)
+	assert.Equal(t, int64(20), stats.Pinged)
+	assert.Equal(t, int64(4), stats.Missed)
+	assert.NotNil(t, stats.LastMissAt)
+	assert.NotNil(t, stats.LastRunAt)
+	assert.Len(t, cr.GetAllActiveClients(), 16)
+}
+
+func TestClientsStatusCheckTaskStalledClient(t *testing.T) {
+	stall := make(chan struct{})
+	stalled := &clientdata.Client{}
+	stalled.SetID("stalled")
+	stalled.SetClientAuthID("stalled")
+	stalled.SetConnection(mockSSHConn{stall: stall})
+	stalled.Logger = testLog
+	responsive := &clientdata.Client{}
+	responsive.SetID("responsive")
+	responsive.SetClientAuthID("responsive")
+	responsive.SetConnection(mockSSHConn{})
+	responsive.Logger = testLog
+	cr := clients.NewClientRepository([]*clientdata.Client{stalled, responsive}, nil, testLog)
+	task := NewClientsStatusCheckTask(testLog, cr, 120*time.Second, time.Minute, 1)
+
+	// the run must not wait for the ping timeout of the stalled client
+	start := time.Now()
+	assert.NoError(t, task.Run(context.Background()))
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Eventually(t, responsive.HasLastHeartbeatAt, time.Second, 10*time.Millisecond)
+
+	// the stalled client is not pinged again while its ping is in flight
+	assert.NoError(t, task.Run(context.Background()))
+	assert.Equal(t, int64(0), task.Stats().Pinged)
+
+	close(stall)
+	task.waitForPings()
+	assert.Equal(t, int64(2), task.Stats().Pinged)
+	assert.True(t, stalled.HasLastHeartbeatAt())
+}
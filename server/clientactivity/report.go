@@ -0,0 +1,159 @@
+// Package clientactivity summarizes who accessed a client and how, based on the audit log, the jobs and the tunnel
+// traffic.
+package clientactivity
+
+import (
+	"sort"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/bandwidth"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+// MaxEntries limits the audit log entries and jobs read for a report.
+const MaxEntries = 10000
+
+// UserActivity is what a user did on a client within the period of a report.
+type UserActivity struct {
+	Username string `json:"username"`
+	// LastAccess is the time of the latest audit log entry or job of the user, nil if the user only has tunnel
+	// traffic or active tunnels.
+	LastAccess       *time.Time `json:"last_access"`
+	TunnelsOpened    int        `json:"tunnels_opened"`
+	ActiveTunnels    int        `json:"active_tunnels"`
+	TunnelBytesIn    int64      `json:"tunnel_bytes_in"`
+	TunnelBytesOut   int64      `json:"tunnel_bytes_out"`
+	CommandsRun      int        `json:"commands_run"`
+	ScriptsRun       int        `json:"scripts_run"`
+	JobsFailed       int        `json:"jobs_failed"`
+	FilesTransferred int        `json:"files_transferred"`
+}
+
+type Report struct {
+	ClientID string    `json:"client_id"`
+	Since    time.Time `json:"since"`
+	Until    time.Time `json:"until"`
+	// AuditLogEnabled is false if opened tunnels and file transfers are not counted because the audit log is disabled.
+	AuditLogEnabled bool `json:"audit_log_enabled"`
+	// Truncated is true if more than MaxEntries audit log entries or jobs were found, only the latest are counted.
+	Truncated bool            `json:"truncated"`
+	Users     []*UserActivity `json:"users"`
+
+	users map[string]*UserActivity
+}
+
+func NewReport(clientID string, since, until time.Time) *Report {
+	return &Report{
+		ClientID: clientID,
+		Since:    since,
+		Until:    until,
+		Users:    []*UserActivity{},
+		users:    make(map[string]*UserActivity),
+	}
+}
+
+// AddAuditEntries counts opened tunnels and file transfers.
+func (r *Report) AddAuditEntries(entries []*auditlog.Entry) {
+	r.AuditLogEnabled = true
+	if len(entries) >= MaxEntries {
+		r.Truncated = true
+	}
+	for _, e := range entries {
+		if !r.inPeriod(e.Timestamp) || e.Username == "" {
+			continue
+		}
+		u := r.user(e.Username)
+		u.access(e.Timestamp)
+		switch {
+		case e.Application == auditlog.ApplicationClientTunnel && e.Action == auditlog.ActionCreate:
+			u.TunnelsOpened++
+		case e.Application == auditlog.ApplicationUploads && e.Action == auditlog.ActionCreate:
+			u.FilesTransferred++
+		}
+	}
+}
+
+// AddJobs counts commands and scripts.
+func (r *Report) AddJobs(jobs []*models.Job) {
+	if len(jobs) >= MaxEntries {
+		r.Truncated = true
+	}
+	for _, job := range jobs {
+		if !r.inPeriod(job.StartedAt) || job.CreatedBy == "" {
+			continue
+		}
+		u := r.user(job.CreatedBy)
+		u.access(job.StartedAt)
+		if job.IsScript {
+			u.ScriptsRun++
+		} else {
+			u.CommandsRun++
+		}
+		if job.Status == models.JobStatusFailed || job.Status == models.JobStatusUnknown {
+			u.JobsFailed++
+		}
+	}
+}
+
+// AddTunnelTraffic sums the daily tunnel traffic, days are counted as a whole.
+func (r *Report) AddTunnelTraffic(usages []*bandwidth.Usage) {
+	for _, usage := range usages {
+		if usage.ClientID != r.ClientID || usage.Username == "" {
+			continue
+		}
+		u := r.user(usage.Username)
+		u.TunnelBytesIn += usage.BytesIn
+		u.TunnelBytesOut += usage.BytesOut
+	}
+}
+
+// AddActiveTunnels counts the tunnels currently open by their owners.
+func (r *Report) AddActiveTunnels(owners []string) {
+	for _, owner := range owners {
+		if owner == "" {
+			continue
+		}
+		r.user(owner).ActiveTunnels++
+	}
+}
+
+// Finish sorts the users, the latest access first.
+func (r *Report) Finish() *Report {
+	users := make([]*UserActivity, 0, len(r.users))
+	for _, u := range r.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		a, b := users[i].LastAccess, users[j].LastAccess
+		if a != nil && b != nil && !a.Equal(*b) {
+			return a.After(*b)
+		}
+		if (a == nil) != (b == nil) {
+			return a != nil
+		}
+		return users[i].Username < users[j].Username
+	})
+	r.Users = users
+	return r
+}
+
+func (r *Report) inPeriod(t time.Time) bool {
+	return !t.Before(r.Since) && !t.After(r.Until)
+}
+
+func (r *Report) user(username string) *UserActivity {
+	u, ok := r.users[username]
+	if !ok {
+		u = &UserActivity{Username: username}
+		r.users[username] = u
+	}
+	return u
+}
+
+func (u *UserActivity) access(t time.Time) {
+	if u.LastAccess == nil || t.After(*u.LastAccess) {
+		t := t
+		u.LastAccess = &t
+	}
+}
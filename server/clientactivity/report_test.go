@@ -0,0 +1,62 @@
+package clientactivity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/bandwidth"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func TestReport(t *testing.T) {
+	now := time.Date(2023, 5, 10, 12, 0, 0, 0, time.UTC)
+	r := NewReport("client-1", now.Add(-24*time.Hour), now)
+
+	r.AddAuditEntries([]*auditlog.Entry{
+		{Timestamp: now.Add(-time.Hour), Username: "alice", Application: auditlog.ApplicationClientTunnel, Action: auditlog.ActionCreate},
+		{Timestamp: now.Add(-2 * time.Hour), Username: "alice", Application: auditlog.ApplicationUploads, Action: auditlog.ActionCreate},
+		{Timestamp: now.Add(-3 * time.Hour), Username: "bob", Application: auditlog.ApplicationClientTunnel, Action: auditlog.ActionDelete},
+		{Timestamp: now.Add(-48 * time.Hour), Username: "bob", Application: auditlog.ApplicationClientTunnel, Action: auditlog.ActionCreate},
+	})
+	r.AddJobs([]*models.Job{
+		{Status: models.JobStatusSuccessful, CreatedBy: "bob", StartedAt: now.Add(-30 * time.Minute)},
+		{Status: models.JobStatusFailed, CreatedBy: "bob", StartedAt: now.Add(-40 * time.Minute), IsScript: true},
+	})
+	r.AddTunnelTraffic([]*bandwidth.Usage{
+		{Day: "2023-05-10", ClientID: "client-1", Username: "alice", BytesIn: 10, BytesOut: 20},
+		{Day: "2023-05-09", ClientID: "client-1", Username: "alice", BytesIn: 1, BytesOut: 2},
+		{Day: "2023-05-10", ClientID: "client-2", Username: "alice", BytesIn: 100, BytesOut: 200},
+	})
+	r.AddActiveTunnels([]string{"carol", "alice", ""})
+	r.Finish()
+
+	assert.True(t, r.AuditLogEnabled)
+	assert.False(t, r.Truncated)
+	require.Len(t, r.Users, 3)
+
+	bob := r.Users[0]
+	assert.Equal(t, "bob", bob.Username)
+	assert.Equal(t, now.Add(-30*time.Minute), *bob.LastAccess)
+	assert.Equal(t, 0, bob.TunnelsOpened)
+	assert.Equal(t, 1, bob.CommandsRun)
+	assert.Equal(t, 1, bob.ScriptsRun)
+	assert.Equal(t, 1, bob.JobsFailed)
+
+	alice := r.Users[1]
+	assert.Equal(t, "alice", alice.Username)
+	assert.Equal(t, now.Add(-time.Hour), *alice.LastAccess)
+	assert.Equal(t, 1, alice.TunnelsOpened)
+	assert.Equal(t, 1, alice.FilesTransferred)
+	assert.Equal(t, 1, alice.ActiveTunnels)
+	assert.Equal(t, int64(11), alice.TunnelBytesIn)
+	assert.Equal(t, int64(22), alice.TunnelBytesOut)
+
+	carol := r.Users[2]
+	assert.Equal(t, "carol", carol.Username)
+	assert.Nil(t, carol.LastAccess)
+	assert.Equal(t, 1, carol.ActiveTunnels)
+}
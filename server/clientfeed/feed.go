@@ -0,0 +1,218 @@
+// Package clientfeed pushes changes of the client list to subscribers, e.g. to update the clients table of the UI
+// without polling.
+package clientfeed
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"sync"
+
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+const (
+	// EventConnected is sent if a client connected or started matching the subscription while connected.
+	EventConnected = "connected"
+	// EventDisconnected is sent if a client disconnected or started matching the subscription while disconnected.
+	EventDisconnected = "disconnected"
+	// EventUpdated is sent if subscribed fields of a client changed.
+	EventUpdated = "updated"
+	// EventRemoved is sent if a client was deleted or doesn't match the subscription anymore.
+	EventRemoved = "removed"
+
+	// BufferSize is the number of changes and of events queued per subscription, slower subscriptions are closed.
+	BufferSize = 256
+)
+
+// Event is a single change of the client list. Client holds the subscribed fields, it's nil for removed clients.
+type Event struct {
+	Type     string      `json:"type"`
+	ClientID string      `json:"client_id"`
+	Client   interface{} `json:"client,omitempty"`
+}
+
+// MatchFn returns whether the client matches the subscription and if so, whether it's connected.
+type MatchFn func(client *clientdata.Client) (match bool, connected bool)
+
+// ConvertFn returns the subscribed fields of the client.
+type ConvertFn func(client *clientdata.Client) interface{}
+
+type sentClient struct {
+	connected bool
+	hash      uint64
+}
+
+type change struct {
+	client  *clientdata.Client
+	removed bool
+}
+
+// Subscription receives the events of the clients matching it. The events channel is closed on Unsubscribe or if the
+// subscriber doesn't keep up, it has to reload the client list then.
+type Subscription struct {
+	match   MatchFn
+	convert ConvertFn
+	// changes are queued by ClientChanged and turned into events by the goroutine of the subscription, so matching and
+	// marshaling don't block saving clients
+	changes chan change
+	events  chan *Event
+	done    chan struct{}
+	// sent holds the state of the matching clients last sent, to send only actual changes. It's only used by the
+	// goroutine of the subscription.
+	sent       map[string]sentClient
+	overflowed bool
+	closed     bool
+}
+
+// Events returns the channel of the events.
+func (s *Subscription) Events() <-chan *Event {
+	return s.events
+}
+
+// Overflowed returns true if the subscription was closed because the subscriber didn't keep up.
+func (s *Subscription) Overflowed() bool {
+	return s.overflowed
+}
+
+type Feed struct {
+	logger *logger.Logger
+
+	subscriptions map[*Subscription]bool
+	mu            sync.Mutex
+}
+
+func New(logger *logger.Logger) *Feed {
+	return &Feed{
+		logger:        logger,
+		subscriptions: make(map[*Subscription]bool),
+	}
+}
+
+// Subscribe adds a subscription. initial are the clients the subscriber already knows, e.g. from listing the clients
+// with the same filters, so only later changes are sent.
+func (f *Feed) Subscribe(match MatchFn, convert ConvertFn, initial []*clientdata.Client) *Subscription {
+	s := &Subscription{
+		match:   match,
+		convert: convert,
+		changes: make(chan change, BufferSize),
+		events:  make(chan *Event, BufferSize),
+		done:    make(chan struct{}),
+		sent:    make(map[string]sentClient, len(initial)),
+	}
+	for _, client := range initial {
+		if match, connected := match(client); match {
+			s.sent[client.GetID()] = sentClient{connected: connected, hash: hash(convert(client))}
+		}
+	}
+	go s.run()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.subscriptions[s] = true
+	return s
+}
+
+// Unsubscribe removes the subscription, its events channel is closed shortly after.
+func (f *Feed) Unsubscribe(s *Subscription) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// the subscriber doesn't read the events anymore, also after an overflow
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+	f.close(s)
+}
+
+// Count returns the number of subscriptions.
+func (f *Feed) Count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.subscriptions)
+}
+
+// ClientChanged queues the change of the client for all subscriptions, each of them sends it if the client matches
+// it now or did before. It's called after a client was saved or removed.
+func (f *Feed) ClientChanged(client *clientdata.Client, removed bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for s := range f.subscriptions {
+		select {
+		case s.changes <- change{client: client, removed: removed}:
+		default:
+			f.logger.Infof("client list subscription doesn't keep up, closing it")
+			s.overflowed = true
+			f.close(s)
+		}
+	}
+}
+
+// run turns the queued changes into events until the subscription is closed. Changes queued before an overflow are
+// still sent, the events channel is closed afterwards.
+func (s *Subscription) run() {
+	defer close(s.events)
+	for c := range s.changes {
+		e := s.change(c.client.GetID(), c.client, c.removed)
+		if e == nil {
+			continue
+		}
+		select {
+		case s.events <- e:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// change returns the event to send to the subscription or nil if nothing changed for it.
+func (s *Subscription) change(clientID string, client *clientdata.Client, removed bool) *Event {
+	prev, wasSent := s.sent[clientID]
+	match, connected := false, false
+	if !removed {
+		match, connected = s.match(client)
+	}
+	if !match {
+		if !wasSent {
+			return nil
+		}
+		delete(s.sent, clientID)
+		return &Event{Type: EventRemoved, ClientID: clientID}
+	}
+
+	payload := s.convert(client)
+	cur := sentClient{connected: connected, hash: hash(payload)}
+	if wasSent && prev == cur {
+		return nil
+	}
+	s.sent[clientID] = cur
+
+	e := &Event{Type: EventUpdated, ClientID: clientID, Client: payload}
+	if !wasSent || prev.connected != connected {
+		e.Type = EventDisconnected
+		if connected {
+			e.Type = EventConnected
+		}
+	}
+	return e
+}
+
+func (f *Feed) close(s *Subscription) {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	delete(f.subscriptions, s)
+	close(s.changes)
+}
+
+func hash(payload interface{}) uint64 {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = h.Write(b)
+	return h.Sum64()
+}
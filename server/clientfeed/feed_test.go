@@ -0,0 +1,128 @@
+package clientfeed
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+var testLog = logger.NewLogger("client-feed", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+
+// matchLinux matches clients with os kernel linux, clients with a name are connected.
+func matchLinux(c *clientdata.Client) (bool, bool) {
+	return c.OSKernel == "linux", c.Name != ""
+}
+
+func convertName(c *clientdata.Client) interface{} {
+	return map[string]string{"name": c.Name, "hostname": c.Hostname}
+}
+
+// receive returns the next event or nil if none is sent within a short time, changes are processed asynchronously.
+func receive(t *testing.T, sub *Subscription) *Event {
+	t.Helper()
+	select {
+	case e := <-sub.Events():
+		return e
+	case <-time.After(50 * time.Millisecond):
+		return nil
+	}
+}
+
+func TestClientChanged(t *testing.T) {
+	f := New(testLog)
+	known := &clientdata.Client{ID: "known", OSKernel: "linux", Name: "known"}
+	sub := f.Subscribe(matchLinux, convertName, []*clientdata.Client{known, {ID: "windows", OSKernel: "windows"}})
+	assert.Equal(t, 1, f.Count())
+
+	// unchanged subscribed fields
+	f.ClientChanged(known, false)
+	assert.Nil(t, receive(t, sub))
+
+	// not matching
+	f.ClientChanged(&clientdata.Client{ID: "windows", OSKernel: "windows", Name: "win"}, false)
+	assert.Nil(t, receive(t, sub))
+
+	f.ClientChanged(&clientdata.Client{ID: "new", OSKernel: "linux", Name: "new"}, false)
+	assert.Equal(t, &Event{Type: EventConnected, ClientID: "new", Client: map[string]string{"name": "new", "hostname": ""}}, receive(t, sub))
+
+	f.ClientChanged(&clientdata.Client{ID: "known", OSKernel: "linux", Name: "known", Hostname: "host"}, false)
+	assert.Equal(t, &Event{Type: EventUpdated, ClientID: "known", Client: map[string]string{"name": "known", "hostname": "host"}}, receive(t, sub))
+
+	f.ClientChanged(&clientdata.Client{ID: "known", OSKernel: "linux", Hostname: "host"}, false)
+	assert.Equal(t, EventDisconnected, receive(t, sub).Type)
+
+	// doesn't match anymore
+	f.ClientChanged(&clientdata.Client{ID: "known", OSKernel: "windows"}, false)
+	assert.Equal(t, &Event{Type: EventRemoved, ClientID: "known"}, receive(t, sub))
+
+	f.ClientChanged(&clientdata.Client{ID: "new", OSKernel: "linux", Name: "new"}, true)
+	assert.Equal(t, &Event{Type: EventRemoved, ClientID: "new"}, receive(t, sub))
+
+	// removed clients not sent before are ignored
+	f.ClientChanged(&clientdata.Client{ID: "windows"}, true)
+	assert.Nil(t, receive(t, sub))
+
+	f.Unsubscribe(sub)
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	assert.False(t, sub.Overflowed())
+	assert.Equal(t, 0, f.Count())
+	// unsubscribing twice is fine
+	f.Unsubscribe(sub)
+}
+
+func TestClientChangedOverflow(t *testing.T) {
+	f := New(testLog)
+	sub := f.Subscribe(matchLinux, convertName, nil)
+
+	// each call sends an event, alternately connected and removed. Without reading, the events and the changes queue
+	// fill up, one more change is held by the goroutine of the subscription.
+	client := &clientdata.Client{ID: "client-1", OSKernel: "linux", Name: "client"}
+	for i := 0; i < 2*BufferSize+2; i++ {
+		f.ClientChanged(client, i%2 == 1)
+	}
+	assert.Equal(t, 0, f.Count())
+
+	count := 0
+	for range sub.Events() {
+		count++
+	}
+	assert.GreaterOrEqual(t, count, BufferSize)
+	assert.LessOrEqual(t, count, 2*BufferSize+1)
+	assert.True(t, sub.Overflowed())
+	// unsubscribing after an overflow is fine
+	f.Unsubscribe(sub)
+}
+
+func TestClientChangedDoesNotWaitForSubscribers(t *testing.T) {
+	f := New(testLog)
+	block := make(chan struct{})
+	slowMatch := func(c *clientdata.Client) (bool, bool) {
+		<-block
+		return true, true
+	}
+	sub := f.Subscribe(slowMatch, convertName, nil)
+	fast := f.Subscribe(matchLinux, convertName, nil)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f.ClientChanged(&clientdata.Client{ID: "client-1", OSKernel: "linux", Name: "client"}, false)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("ClientChanged waits for a slow subscriber")
+	}
+	assert.Equal(t, EventConnected, receive(t, fast).Type)
+
+	close(block)
+	assert.Equal(t, EventConnected, receive(t, sub).Type)
+	f.Unsubscribe(sub)
+	f.Unsubscribe(fast)
+}
@@ -0,0 +1,64 @@
+package clientpayload
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	OnOversizeReject   = "reject"
+	OnOversizeTruncate = "truncate"
+
+	DefaultMaxIdentifierLength = 255
+	DefaultMaxTextLength       = 1024
+	DefaultMaxTags             = 100
+	DefaultMaxTagLength        = 255
+	DefaultMaxLabels           = 100
+	DefaultMaxLabelLength      = 1024
+	DefaultMaxAddresses        = 256
+)
+
+// Config defines the limits of the connection requests sent by clients. Setting a limit to zero disables it.
+type Config struct {
+	// MaxIdentifierLength limits id, name, hostname and session id.
+	MaxIdentifierLength int `mapstructure:"client_payload_max_identifier_length"`
+	// MaxTextLength limits all other string fields, e.g. os, cpu and version details.
+	MaxTextLength  int `mapstructure:"client_payload_max_text_length"`
+	MaxTags        int `mapstructure:"client_payload_max_tags"`
+	MaxTagLength   int `mapstructure:"client_payload_max_tag_length"`
+	MaxLabels      int `mapstructure:"client_payload_max_labels"`
+	MaxLabelLength int `mapstructure:"client_payload_max_label_length"`
+	// MaxAddresses limits the number of IPv4 and IPv6 addresses each.
+	MaxAddresses int `mapstructure:"client_payload_max_addresses"`
+	// OnOversize is either "reject" to deny the connection or "truncate" to cut values and lists exceeding the limits.
+	OnOversize string `mapstructure:"client_payload_on_oversize"`
+}
+
+func (c *Config) Validate() error {
+	limits := []struct {
+		name  string
+		value int
+	}{
+		{"client_payload_max_identifier_length", c.MaxIdentifierLength},
+		{"client_payload_max_text_length", c.MaxTextLength},
+		{"client_payload_max_tags", c.MaxTags},
+		{"client_payload_max_tag_length", c.MaxTagLength},
+		{"client_payload_max_labels", c.MaxLabels},
+		{"client_payload_max_label_length", c.MaxLabelLength},
+		{"client_payload_max_addresses", c.MaxAddresses},
+	}
+	for _, limit := range limits {
+		if limit.value < 0 {
+			return fmt.Errorf("%s cannot be negative", limit.name)
+		}
+	}
+
+	switch c.OnOversize {
+	case "":
+		c.OnOversize = OnOversizeReject
+	case OnOversizeReject, OnOversizeTruncate:
+	default:
+		return errors.New("client_payload_on_oversize must be either 'reject' or 'truncate'")
+	}
+	return nil
+}
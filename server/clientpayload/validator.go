@@ -0,0 +1,216 @@
+package clientpayload
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	chshare "github.com/realvnc-labs/rport/share"
+)
+
+// Field classes the limits apply to, also used as keys of the stats.
+const (
+	ClassIdentifier = "identifier"
+	ClassText       = "text"
+	ClassTags       = "tags"
+	ClassLabels     = "labels"
+	ClassAddresses  = "addresses"
+)
+
+// Stats are the numbers of connection requests rejected or truncated per field class since the server started.
+type Stats struct {
+	Rejected  map[string]int64 `json:"rejected"`
+	Truncated map[string]int64 `json:"truncated"`
+}
+
+// Validator sanitizes connection requests and enforces the configured limits.
+type Validator struct {
+	config Config
+
+	rejected  map[string]int64
+	truncated map[string]int64
+	mu        sync.Mutex
+}
+
+func NewValidator(config Config) *Validator {
+	return &Validator{
+		config:    config,
+		rejected:  make(map[string]int64),
+		truncated: make(map[string]int64),
+	}
+}
+
+// Validate removes control characters and surrounding whitespace from all strings of the request and drops empty tags.
+// Values exceeding the limits cause an error or are truncated depending on the OnOversize config.
+func (v *Validator) Validate(req *chshare.ConnectionRequest) error {
+	check := &check{config: v.config}
+
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{
+		{"id", &req.ID},
+		{"name", &req.Name},
+		{"hostname", &req.Hostname},
+		{"session_id", &req.SessionID},
+	} {
+		*field.value = check.string(ClassIdentifier, field.name, *field.value, v.config.MaxIdentifierLength)
+	}
+
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{
+		{"os", &req.OS},
+		{"os_full_name", &req.OSFullName},
+		{"os_version", &req.OSVersion},
+		{"os_virtualization_system", &req.OSVirtualizationSystem},
+		{"os_virtualization_role", &req.OSVirtualizationRole},
+		{"os_arch", &req.OSArch},
+		{"os_family", &req.OSFamily},
+		{"os_kernel", &req.OSKernel},
+		{"version", &req.Version},
+		{"cpu_family", &req.CPUFamily},
+		{"cpu_model", &req.CPUModel},
+		{"cpu_model_name", &req.CPUModelName},
+		{"cpu_vendor", &req.CPUVendor},
+		{"timezone", &req.Timezone},
+		{"mode", &req.Mode},
+	} {
+		*field.value = check.string(ClassText, field.name, *field.value, v.config.MaxTextLength)
+	}
+
+	req.Tags = check.list(ClassTags, "tags", req.Tags, v.config.MaxTags, v.config.MaxTagLength, true)
+	req.IPv4 = check.list(ClassAddresses, "ipv4", req.IPv4, v.config.MaxAddresses, v.config.MaxTextLength, false)
+	req.IPv6 = check.list(ClassAddresses, "ipv6", req.IPv6, v.config.MaxAddresses, v.config.MaxTextLength, false)
+	req.Labels = check.labels(req.Labels)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for class := range check.truncated {
+		v.truncated[class]++
+	}
+	if check.err != nil {
+		v.rejected[check.errClass]++
+		return check.err
+	}
+	return nil
+}
+
+func (v *Validator) Stats() Stats {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	stats := Stats{
+		Rejected:  make(map[string]int64, len(v.rejected)),
+		Truncated: make(map[string]int64, len(v.truncated)),
+	}
+	for class, n := range v.rejected {
+		stats.Rejected[class] = n
+	}
+	for class, n := range v.truncated {
+		stats.Truncated[class] = n
+	}
+	return stats
+}
+
+// check keeps the first error and the truncated classes of a single request.
+type check struct {
+	config    Config
+	err       error
+	errClass  string
+	truncated map[string]bool
+}
+
+func (c *check) oversize(class string, err error) {
+	if c.config.OnOversize == OnOversizeTruncate {
+		if c.truncated == nil {
+			c.truncated = make(map[string]bool)
+		}
+		c.truncated[class] = true
+		return
+	}
+	if c.err == nil {
+		c.err = err
+		c.errClass = class
+	}
+}
+
+func (c *check) string(class, name, value string, maxLength int) string {
+	value = sanitize(value)
+	if maxLength > 0 && utf8.RuneCountInString(value) > maxLength {
+		c.oversize(class, fmt.Errorf("%s exceeds the limit of %d characters", name, maxLength))
+		value = truncate(value, maxLength)
+	}
+	return value
+}
+
+func (c *check) list(class, name string, values []string, maxItems, maxLength int, dropEmpty bool) []string {
+	if values == nil {
+		return nil
+	}
+	res := make([]string, 0, len(values))
+	for _, value := range values {
+		value = c.string(class, name, value, maxLength)
+		if dropEmpty && value == "" {
+			continue
+		}
+		res = append(res, value)
+	}
+	if maxItems > 0 && len(res) > maxItems {
+		c.oversize(class, fmt.Errorf("%s exceeds the limit of %d items", name, maxItems))
+		res = res[:maxItems]
+	}
+	return res
+}
+
+func (c *check) labels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	// sorted to keep the same labels on truncation
+	sort.Strings(keys)
+	if maxItems := c.config.MaxLabels; maxItems > 0 && len(keys) > maxItems {
+		c.oversize(ClassLabels, fmt.Errorf("labels exceed the limit of %d items", maxItems))
+		keys = keys[:maxItems]
+	}
+
+	res := make(map[string]string, len(keys))
+	for _, key := range keys {
+		sanitizedKey := c.string(ClassLabels, "label key", key, c.config.MaxLabelLength)
+		if sanitizedKey == "" {
+			continue
+		}
+		res[sanitizedKey] = c.string(ClassLabels, "label value", labels[key], c.config.MaxLabelLength)
+	}
+	return res
+}
+
+// sanitize removes invalid UTF-8, non-printable characters and surrounding whitespace.
+func sanitize(value string) string {
+	value = strings.ToValidUTF8(value, "")
+	value = strings.Map(func(r rune) rune {
+		if !unicode.IsPrint(r) && r != ' ' {
+			return -1
+		}
+		return r
+	}, value)
+	return strings.TrimSpace(value)
+}
+
+func truncate(value string, maxLength int) string {
+	i := 0
+	for j := range value {
+		if i == maxLength {
+			return value[:j]
+		}
+		i++
+	}
+	return value
+}
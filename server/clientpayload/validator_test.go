@@ -0,0 +1,156 @@
+package clientpayload
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chshare "github.com/realvnc-labs/rport/share"
+)
+
+var testConfig = Config{
+	MaxIdentifierLength: 10,
+	MaxTextLength:       20,
+	MaxTags:             2,
+	MaxTagLength:        5,
+	MaxLabels:           2,
+	MaxLabelLength:      5,
+	MaxAddresses:        2,
+	OnOversize:          OnOversizeReject,
+}
+
+func TestValidateSanitizes(t *testing.T) {
+	v := NewValidator(testConfig)
+	req := &chshare.ConnectionRequest{
+		ID:       " client-1\n",
+		Name:     "na\x00me",
+		OS:       "Linux\t\x1b[31m",
+		Hostname: "host\xff",
+		Tags:     []string{" a ", "", "\n", "b"},
+		Labels:   map[string]string{" k ": " v\r", "\t": "dropped"},
+		IPv4:     []string{" 192.0.2.1 "},
+	}
+
+	err := v.Validate(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, "client-1", req.ID)
+	assert.Equal(t, "name", req.Name)
+	assert.Equal(t, "Linux[31m", req.OS)
+	assert.Equal(t, "host", req.Hostname)
+	assert.Equal(t, []string{"a", "b"}, req.Tags)
+	assert.Equal(t, map[string]string{"k": "v"}, req.Labels)
+	assert.Equal(t, []string{"192.0.2.1"}, req.IPv4)
+	assert.Nil(t, req.IPv6)
+	assert.Equal(t, Stats{Rejected: map[string]int64{}, Truncated: map[string]int64{}}, v.Stats())
+}
+
+func TestValidateLimits(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Request       chshare.ConnectionRequest
+		ExpectedError string
+		ExpectedClass string
+	}{
+		{
+			Name:          "identifier",
+			Request:       chshare.ConnectionRequest{Name: strings.Repeat("n", 11)},
+			ExpectedError: "name exceeds the limit of 10 characters",
+			ExpectedClass: ClassIdentifier,
+		},
+		{
+			Name:    "identifier multibyte within limit",
+			Request: chshare.ConnectionRequest{Name: strings.Repeat("ü", 10)},
+		},
+		{
+			Name:          "text",
+			Request:       chshare.ConnectionRequest{OSFullName: strings.Repeat("o", 21)},
+			ExpectedError: "os_full_name exceeds the limit of 20 characters",
+			ExpectedClass: ClassText,
+		},
+		{
+			Name:          "tag length",
+			Request:       chshare.ConnectionRequest{Tags: []string{"longtag"}},
+			ExpectedError: "tags exceeds the limit of 5 characters",
+			ExpectedClass: ClassTags,
+		},
+		{
+			Name:          "tags count",
+			Request:       chshare.ConnectionRequest{Tags: []string{"a", "b", "c"}},
+			ExpectedError: "tags exceeds the limit of 2 items",
+			ExpectedClass: ClassTags,
+		},
+		{
+			Name:          "labels count",
+			Request:       chshare.ConnectionRequest{Labels: map[string]string{"a": "1", "b": "2", "c": "3"}},
+			ExpectedError: "labels exceed the limit of 2 items",
+			ExpectedClass: ClassLabels,
+		},
+		{
+			Name:          "label value",
+			Request:       chshare.ConnectionRequest{Labels: map[string]string{"a": "123456"}},
+			ExpectedError: "label value exceeds the limit of 5 characters",
+			ExpectedClass: ClassLabels,
+		},
+		{
+			Name:          "addresses",
+			Request:       chshare.ConnectionRequest{IPv6: []string{"::1", "::2", "::3"}},
+			ExpectedError: "ipv6 exceeds the limit of 2 items",
+			ExpectedClass: ClassAddresses,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			v := NewValidator(testConfig)
+
+			err := v.Validate(&tc.Request)
+
+			if tc.ExpectedError == "" {
+				assert.NoError(t, err)
+				assert.Empty(t, v.Stats().Rejected)
+				return
+			}
+			assert.EqualError(t, err, tc.ExpectedError)
+			assert.Equal(t, map[string]int64{tc.ExpectedClass: 1}, v.Stats().Rejected)
+		})
+	}
+}
+
+func TestValidateTruncates(t *testing.T) {
+	config := testConfig
+	config.OnOversize = OnOversizeTruncate
+	v := NewValidator(config)
+	req := &chshare.ConnectionRequest{
+		Name:   "ääääääääääää",
+		Tags:   []string{"a", "longtag", "c"},
+		Labels: map[string]string{"c": "3", "a": "123456", "b": "2"},
+		IPv4:   []string{"192.0.2.1"},
+	}
+
+	err := v.Validate(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, "ääääääääää", req.Name)
+	assert.Equal(t, []string{"a", "longt"}, req.Tags)
+	assert.Equal(t, map[string]string{"a": "12345", "b": "2"}, req.Labels)
+	assert.Equal(t, []string{"192.0.2.1"}, req.IPv4)
+	assert.Equal(t, Stats{
+		Rejected:  map[string]int64{},
+		Truncated: map[string]int64{ClassIdentifier: 1, ClassTags: 1, ClassLabels: 1},
+	}, v.Stats())
+}
+
+func TestConfigValidate(t *testing.T) {
+	c := Config{}
+	require.NoError(t, c.Validate())
+	assert.Equal(t, OnOversizeReject, c.OnOversize)
+
+	c = Config{MaxTags: -1}
+	assert.EqualError(t, c.Validate(), "client_payload_max_tags cannot be negative")
+
+	c = Config{OnOversize: "drop"}
+	assert.EqualError(t, c.Validate(), "client_payload_on_oversize must be either 'reject' or 'truncate'")
+}
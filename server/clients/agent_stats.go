@@ -0,0 +1,30 @@
+package clients
+
+import (
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+// AgentStatsThresholds define the resource usage of the client process reported to the alerting service as exceeded.
+// A threshold of 0 is disabled.
+type AgentStatsThresholds struct {
+	RSSBytes   uint64
+	Goroutines int
+	OpenFDs    int
+}
+
+// Exceeded returns true if any of the enabled thresholds is exceeded by the given stats.
+func (t AgentStatsThresholds) Exceeded(stats *models.AgentStats) bool {
+	if stats == nil {
+		return false
+	}
+	if t.RSSBytes > 0 && stats.RSSBytes > t.RSSBytes {
+		return true
+	}
+	if t.Goroutines > 0 && stats.Goroutines > t.Goroutines {
+		return true
+	}
+	if t.OpenFDs > 0 && stats.OpenFDs != nil && *stats.OpenFDs > t.OpenFDs {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/autotags"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+type AutoTagsTask struct {
+	log *logger.Logger
+	cr  *ClientRepository
+	cfg *autotags.Config
+}
+
+// NewAutoTagsTask returns a task to recalculate the auto tags of all clients, e.g. to tag clients offline for too long.
+func NewAutoTagsTask(log *logger.Logger, cr *ClientRepository, cfg *autotags.Config) *AutoTagsTask {
+	return &AutoTagsTask{
+		log: log,
+		cr:  cr,
+		cfg: cfg,
+	}
+}
+
+func (t *AutoTagsTask) Run(ctx context.Context) error {
+	now := clientdata.Now()
+	updated := 0
+	for _, client := range t.cr.GetAllClients() {
+		if !updateAutoTags(t.cfg, client, now) {
+			continue
+		}
+		if err := t.cr.Save(client); err != nil {
+			return fmt.Errorf("failed to save auto tags of client %s: %v", client.GetID(), err)
+		}
+		updated++
+	}
+
+	if updated > 0 {
+		t.log.Debugf("Updated auto tags of %d client(s).", updated)
+	}
+
+	return nil
+}
+
+// updateAutoTags recalculates the auto tags of the client and returns true if they changed.
+func updateAutoTags(cfg *autotags.Config, client *clientdata.Client, now time.Time) bool {
+	disconnects := client.GetDisconnects()
+	recent := cfg.RecentDisconnects(disconnects, now)
+	if len(recent) != len(disconnects) {
+		client.SetDisconnects(recent)
+	}
+
+	tags := cfg.Tags(recent, client.GetDisconnectedAt(), now)
+	oldTags := client.GetAutoTags()
+	changed := len(tags) != len(oldTags)
+	for i := 0; !changed && i < len(tags); i++ {
+		changed = tags[i] != oldTags[i]
+	}
+	if changed {
+		client.SetAutoTags(tags)
+	}
+	return changed
+}
@@ -0,0 +1,70 @@
+package clients
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/autotags"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+)
+
+var testAutoTagsConfig = &autotags.Config{
+	UnstableDisconnects: 2,
+	UnstablePeriod:      time.Hour,
+	StaleAfter:          24 * time.Hour,
+}
+
+func TestAutoTagsTask(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c1 := New(t).ID("client-1").Logger(testLog).Build()                                       // active
+	c2 := New(t).ID("client-2").DisconnectedDuration(5 * time.Minute).Logger(testLog).Build() // disconnected
+	c3 := New(t).ID("client-3").DisconnectedDuration(25 * time.Hour).Logger(testLog).Build()  // stale
+	c4 := New(t).ID("client-4").DisconnectedDuration(time.Minute).Logger(testLog).Build()     // no longer stale
+	c4.SetAutoTags([]string{autotags.TagStale})
+	clients := []*clientdata.Client{c1, c2, c3, c4}
+	p := NewFakeClientProvider(t, nil, c1, c2, c3, c4)
+	defer p.Close()
+	clientsRepo := NewClientRepositoryWithDB(clients, nil, p, testLog)
+
+	task := NewAutoTagsTask(testLog, clientsRepo, testAutoTagsConfig)
+
+	// when
+	err := task.Run(ctx)
+
+	// then
+	require.NoError(t, err)
+	assert.Empty(t, c1.GetAutoTags())
+	assert.Empty(t, c2.GetAutoTags())
+	assert.Equal(t, []string{autotags.TagStale}, c3.GetAutoTags())
+	assert.Empty(t, c4.GetAutoTags())
+
+	gotStale, err := p.get(ctx, c3.GetID(), testLog)
+	require.NoError(t, err)
+	assert.Equal(t, []string{autotags.TagStale}, gotStale.GetAutoTags())
+}
+
+func TestUpdateAutoTagsUnstable(t *testing.T) {
+	now := time.Now()
+	c1 := New(t).ID("client-1").Logger(testLog).Build()
+	c1.SetDisconnects([]time.Time{now.Add(-2 * time.Hour), now.Add(-30 * time.Minute), now.Add(-20 * time.Minute)})
+
+	assert.False(t, updateAutoTags(testAutoTagsConfig, c1, now))
+	assert.Empty(t, c1.GetAutoTags())
+	// outdated disconnects are dropped
+	assert.Len(t, c1.GetDisconnects(), 2)
+
+	c1.SetDisconnects(append(c1.GetDisconnects(), now.Add(-time.Minute)))
+	assert.True(t, updateAutoTags(testAutoTagsConfig, c1, now))
+	assert.Equal(t, []string{autotags.TagUnstable}, c1.GetAutoTags())
+
+	assert.False(t, updateAutoTags(testAutoTagsConfig, c1, now))
+
+	assert.True(t, updateAutoTags(testAutoTagsConfig, c1, now.Add(time.Hour)))
+	assert.Empty(t, c1.GetAutoTags())
+	assert.Empty(t, c1.GetDisconnects())
+}
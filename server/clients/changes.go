@@ -0,0 +1,227 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+const (
+	ChangeTypeCreated = "created"
+	ChangeTypeUpdated = "updated"
+	ChangeTypeDeleted = "deleted"
+)
+
+var (
+	ChangesSupportedFilters = map[string]bool{
+		"revision[gt]":     true,
+		"revision[lt]":     true,
+		"timestamp[gt]":    true,
+		"timestamp[lt]":    true,
+		"timestamp[since]": true,
+		"timestamp[until]": true,
+		"client_id":        true,
+		"type":             true,
+	}
+	ChangesSupportedSorts = map[string]bool{
+		"revision": true,
+	}
+	ChangesListDefaultSort = map[string][]string{
+		"sort": {"revision"},
+	}
+)
+
+// ClientChange describes a single change of a persisted client record. Revisions are strictly increasing, so they
+// can be used by external systems to fetch only the changes they haven't seen yet.
+type ClientChange struct {
+	Revision  int64         `json:"revision" db:"revision"`
+	ClientID  string        `json:"client_id" db:"client_id"`
+	Type      string        `json:"type" db:"type"`
+	Changes   ChangedFields `json:"changes" db:"changes"`
+	Timestamp time.Time     `json:"timestamp" db:"timestamp"`
+}
+
+// ChangedFields holds the new values of changed fields mapped by the field name.
+type ChangedFields map[string]json.RawMessage
+
+func (f *ChangedFields) Scan(value interface{}) error {
+	if f == nil {
+		return errors.New("'changes' cannot be nil")
+	}
+	valueStr, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("expected to have string, got %T", value)
+	}
+	err := json.Unmarshal([]byte(valueStr), f)
+	if err != nil {
+		return fmt.Errorf("failed to decode 'changes' field: %v", err)
+	}
+	return nil
+}
+
+func (f ChangedFields) Value() (driver.Value, error) {
+	if f == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode 'changes' field: %v", err)
+	}
+	return string(b), nil
+}
+
+// fields returns the persisted fields of a client as json values mapped by the field name.
+func (s *clientSqlite) fields() (ChangedFields, error) {
+	b, err := json.Marshal(s.Details)
+	if err != nil {
+		return nil, err
+	}
+	fields := ChangedFields{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+
+	if fields["client_auth_id"], err = json.Marshal(s.ClientAuthID); err != nil {
+		return nil, err
+	}
+	var disconnectedAt *time.Time
+	if s.DisconnectedAt.Valid {
+		disconnectedAt = &s.DisconnectedAt.Time
+	}
+	if fields["disconnected_at"], err = json.Marshal(disconnectedAt); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// diffClientFields returns the fields of cur that differ from prev. If prev is nil all fields are returned.
+func diffClientFields(prev, cur *clientSqlite) (ChangedFields, error) {
+	curFields, err := cur.fields()
+	if err != nil {
+		return nil, err
+	}
+	if prev == nil {
+		return curFields, nil
+	}
+
+	prevFields, err := prev.fields()
+	if err != nil {
+		return nil, err
+	}
+
+	changed := ChangedFields{}
+	for name, value := range curFields {
+		if !bytes.Equal(prevFields[name], value) {
+			changed[name] = value
+		}
+	}
+	return changed, nil
+}
+
+func insertClientChange(ctx context.Context, tx *sqlx.Tx, change *ClientChange) error {
+	_, err := tx.NamedExecContext(
+		ctx,
+		"INSERT INTO client_changes (client_id, type, changes, timestamp) VALUES (:client_id, :type, :changes, :timestamp)",
+		change,
+	)
+	return err
+}
+
+// saveWithChange saves the client and records the changed fields compared to the currently persisted client.
+func (p *SqliteProvider) saveWithChange(ctx context.Context, tx *sqlx.Tx, cur *clientSqlite) error {
+	var prev *clientSqlite
+	existing := &clientSqlite{}
+	err := tx.GetContext(ctx, existing, "SELECT * FROM clients WHERE id = ?", cur.ID)
+	switch {
+	case err == nil:
+		prev = existing
+	case err != sql.ErrNoRows:
+		return err
+	}
+
+	changed, err := diffClientFields(prev, cur)
+	if err != nil {
+		return fmt.Errorf("failed to compare client fields: %w", err)
+	}
+
+	if len(changed) > 0 {
+		changeType := ChangeTypeUpdated
+		if prev == nil {
+			changeType = ChangeTypeCreated
+		}
+		err = insertClientChange(ctx, tx, &ClientChange{
+			ClientID:  cur.ID,
+			Type:      changeType,
+			Changes:   changed,
+			Timestamp: time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.NamedExecContext(
+		ctx,
+		"INSERT OR REPLACE INTO clients (id, client_auth_id, disconnected_at, details) VALUES (:id, :client_auth_id, :disconnected_at, :details)",
+		cur,
+	)
+	return err
+}
+
+func recordClientsDeleted(ctx context.Context, tx *sqlx.Tx, ids []string) error {
+	now := time.Now().UTC()
+	for _, id := range ids {
+		err := insertClientChange(ctx, tx, &ClientChange{
+			ClientID:  id,
+			Type:      ChangeTypeDeleted,
+			Timestamp: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *SqliteProvider) ListChanges(ctx context.Context, options *query.ListOptions) ([]*ClientChange, error) {
+	values := []*ClientChange{}
+	q := "SELECT * FROM `client_changes`"
+	q, params := p.converter.ConvertListOptionsToQuery(options, q)
+	err := p.reader.SelectContext(ctx, &values, q, params...)
+	if err != nil {
+		return values, err
+	}
+	return values, nil
+}
+
+func (p *SqliteProvider) CountChanges(ctx context.Context, options *query.ListOptions) (int, error) {
+	var result int
+	q := "SELECT COUNT(*) FROM `client_changes`"
+	countOptions := *options
+	countOptions.Pagination = nil
+	countOptions.Sorts = nil
+	q, params := p.converter.ConvertListOptionsToQuery(&countOptions, q)
+	err := p.reader.GetContext(ctx, &result, q, params...)
+	if err != nil {
+		return 0, err
+	}
+	return result, nil
+}
+
+func (p *SqliteProvider) DeleteChangesOlderThan(ctx context.Context, period time.Duration) (int64, error) {
+	res, err := p.db.ExecContext(ctx, "DELETE FROM client_changes WHERE timestamp < ?", time.Now().UTC().Add(-period))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -4,6 +4,7 @@ package clients
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/realvnc-labs/rport/share/logger"
 )
@@ -33,3 +34,31 @@ func (t *CleanupTask) Run(ctx context.Context) error {
 
 	return nil
 }
+
+type ChangesCleanupTask struct {
+	log    *logger.Logger
+	cr     *ClientRepository
+	period time.Duration
+}
+
+// NewChangesCleanupTask returns a task to delete client changes older than a given period.
+func NewChangesCleanupTask(log *logger.Logger, cr *ClientRepository, period time.Duration) *ChangesCleanupTask {
+	return &ChangesCleanupTask{
+		log:    log,
+		cr:     cr,
+		period: period,
+	}
+}
+
+func (t *ChangesCleanupTask) Run(ctx context.Context) error {
+	deleted, err := t.cr.DeleteChangesOlderThan(ctx, t.period)
+	if err != nil {
+		return fmt.Errorf("failed to delete client changes: %v", err)
+	}
+
+	if deleted > 0 {
+		t.log.Debugf("Deleted %d client change(s).", deleted)
+	}
+
+	return nil
+}
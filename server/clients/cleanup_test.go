@@ -21,7 +21,7 @@ func TestCleanup(t *testing.T) {
 	p := NewFakeClientProvider(t, &hour, c1, c2, c3)
 	defer p.Close()
 	clientsRepo := NewClientRepositoryWithDB(clients, &hour, p, testLog)
-	require.Len(t, clientsRepo.clientState, 3)
+	require.Equal(t, 3, clientsRepo.clientState.len())
 	gotObsolete, err := p.get(ctx, c3.GetID(), testLog)
 	require.NoError(t, err)
 
@@ -33,7 +33,7 @@ func TestCleanup(t *testing.T) {
 
 	// then
 	assert.NoError(t, err)
-	assert.ElementsMatch(t, getValues(clientsRepo.clientState), []*clientdata.Client{c1, c2})
+	assert.ElementsMatch(t, clientsRepo.clientState.all(), []*clientdata.Client{c1, c2})
 	gotClients, err := p.GetAll(ctx, testLog)
 	assert.NoError(t, err)
 
@@ -53,7 +53,7 @@ func TestCleanupDisabled(t *testing.T) {
 	p := NewFakeClientProvider(t, nil, c1, c2, c3)
 	defer p.Close()
 	clientsRepo := NewClientRepositoryWithDB(clients, nil, p, testLog)
-	require.Len(t, clientsRepo.clientState, 3)
+	require.Equal(t, 3, clientsRepo.clientState.len())
 
 	task := NewCleanupTask(testLog, clientsRepo)
 
@@ -62,13 +62,5 @@ func TestCleanupDisabled(t *testing.T) {
 
 	// then
 	assert.NoError(t, err)
-	assert.ElementsMatch(t, getValues(clientsRepo.clientState), []*clientdata.Client{c1, c2, c3})
-}
-
-func getValues(clients map[string]*clientdata.Client) []*clientdata.Client {
-	var r []*clientdata.Client
-	for _, v := range clients {
-		r = append(r, v)
-	}
-	return r
+	assert.ElementsMatch(t, clientsRepo.clientState.all(), []*clientdata.Client{c1, c2, c3})
 }
@@ -2,7 +2,9 @@ package clients
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"net"
 	"net/http"
 	"sort"
@@ -17,16 +19,29 @@ import (
 	"github.com/jmoiron/sqlx"
 
 	alertingcap "github.com/realvnc-labs/rport/plus/capabilities/alerting"
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/correlation"
 	"github.com/realvnc-labs/rport/plus/capabilities/alerting/transformers"
 	licensecap "github.com/realvnc-labs/rport/plus/capabilities/license"
 
 	"github.com/realvnc-labs/rport/server/acme"
 	apiErrors "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/autotags"
+	"github.com/realvnc-labs/rport/server/bandwidth"
 	"github.com/realvnc-labs/rport/server/caddy"
 	"github.com/realvnc-labs/rport/server/cgroups"
 	"github.com/realvnc-labs/rport/server/clients/clientdata"
 	"github.com/realvnc-labs/rport/server/clients/clienttunnel"
+	"github.com/realvnc-labs/rport/server/clientversion"
+	"github.com/realvnc-labs/rport/server/clientwatch"
+	"github.com/realvnc-labs/rport/server/firewall"
+	"github.com/realvnc-labs/rport/server/hooks"
+	"github.com/realvnc-labs/rport/server/oseol"
 	"github.com/realvnc-labs/rport/server/ports"
+	"github.com/realvnc-labs/rport/server/preconnect"
+	"github.com/realvnc-labs/rport/server/securityevents"
+	"github.com/realvnc-labs/rport/server/sessionrecording"
+	"github.com/realvnc-labs/rport/server/tunnelschemes"
+	"github.com/realvnc-labs/rport/server/webpush"
 	chshare "github.com/realvnc-labs/rport/share"
 	"github.com/realvnc-labs/rport/share/logger"
 	"github.com/realvnc-labs/rport/share/models"
@@ -39,6 +54,7 @@ type ClientService interface {
 
 	Count() int
 	CountActive() int
+	GetMaxClients() int
 	CountDisconnected() (int, error)
 	GetByID(id string) (*clientdata.Client, error)
 	GetActiveByID(id string) (*clientdata.Client, error)
@@ -48,6 +64,7 @@ type ClientService interface {
 	GetAll() []*clientdata.Client
 	GetUserClients(groups []*cgroups.ClientGroup, user User) []*clientdata.Client
 	GetFilteredUserClients(user User, filterOptions []query.FilterOption, groups []*cgroups.ClientGroup) ([]*clientdata.CalculatedClient, error)
+	GetUserClientsPage(user User, options *query.ListOptions, groups []*cgroups.ClientGroup) ([]*clientdata.CalculatedClient, int, error)
 
 	PopulateGroupsWithUserClients(groups []*cgroups.ClientGroup, user User)
 	UpdateClientStatus()
@@ -59,23 +76,55 @@ type ClientService interface {
 	Terminate(client *clientdata.Client) error
 	ForceDelete(client *clientdata.Client) error
 	DeleteOffline(clientID string) error
+	DeleteOfflineByFilter(
+		user User, filterOptions []query.FilterOption, groups []*cgroups.ClientGroup, disconnectedBefore time.Time, dryRun bool,
+	) ([]*clientdata.CalculatedClient, error)
 
 	SetACL(clientID string, allowedUserGroups []string) error
 	CheckClientAccess(clientID string, user User, groups []*cgroups.ClientGroup) error
 	CheckClientsAccess(clients []*clientdata.Client, user User, groups []*cgroups.ClientGroup) error
 
 	SetUpdatesStatus(clientID string, updatesStatus *models.UpdatesStatus) error
+	SetSecuritySnapshot(clientID string, snapshot *models.SecuritySnapshot) error
+	SetInterpreters(clientID string, interpreters []models.Interpreter) error
+	SetMonitoringProfile(clientID string, state *clientdata.MonitoringProfileState) error
 	SetLastHeartbeat(clientID string, heartbeat time.Time) error
+	SetClockSkew(clientID string, skew time.Duration) error
+	SetAgentStats(clientID string, stats *models.AgentStats) error
+	SetAddresses(clientID string, ipv4, ipv6 []string) error
 
 	GetRepo() *ClientRepository
 
 	SetCaddyAPI(capi caddy.API)
+	SetSessionRecordingStore(store *sessionrecording.Store)
+	SetSecurityEvents(store *securityevents.Store)
+	SetACLRules(rules []cgroups.ACLRule)
+	SetHooks(runner *hooks.Runner)
+	SetClientWatches(watches *clientwatch.Service)
+	SetWebPush(webPush *webpush.Service)
+	SetFlapDetector(detector *correlation.FlapDetector)
+	SetDuplicateDetection(serialLabel string, alerting bool)
+	SetClockSkewThreshold(threshold time.Duration)
+	SetAgentStatsThresholds(thresholds AgentStatsThresholds)
+	SetAutoTagsConfig(cfg *autotags.Config)
+	SetOSEOLDataset(dataset *oseol.Dataset)
+	SetBandwidth(bandwidth *bandwidth.Service)
+	SetFirewall(firewall *firewall.Manager)
+	SetTunnelSchemes(schemes tunnelschemes.Schemes)
+	SetPreConnectScript(script *preconnect.Script)
+	SetMinClientVersion(config *clientversion.Config)
+	SetTunnelCredentialsProvider(provider clienttunnel.CredentialsProvider)
 	StartClientTunnels(client *clientdata.Client, remotes []*models.Remote) ([]*clienttunnel.Tunnel, error)
 	StartTunnel(c *clientdata.Client, r *models.Remote, acl *clienttunnel.TunnelACL) (*clienttunnel.Tunnel, error)
 	FindTunnel(c *clientdata.Client, id string) *clienttunnel.Tunnel
 	FindTunnelByRemote(c *clientdata.Client, r *models.Remote) *clienttunnel.Tunnel
 	TerminateTunnel(c *clientdata.Client, t *clienttunnel.Tunnel, force bool) error
+	TerminateEphemeralTunnels(ctx context.Context, sessions APISessionGetter) error
 	SetTunnelACL(c *clientdata.Client, t *clienttunnel.Tunnel, aclStr *string) error
+	AllowTunnelShareIP(c *clientdata.Client, t *clienttunnel.Tunnel, shareID string, ip string) error
+	RevokeTunnelShare(c *clientdata.Client, t *clienttunnel.Tunnel, shareID string) error
+	Quarantine(clientID, reason, by string) (*clientdata.Client, error)
+	Release(clientID string) (*clientdata.Client, error)
 }
 
 type ClientServiceProvider struct {
@@ -86,12 +135,49 @@ type ClientServiceProvider struct {
 	logger            *logger.Logger
 	acme              *acme.Acme
 	alertingService   alertingcap.Service
+	recordingStore    *sessionrecording.Store
+	securityEvents    *securityevents.Store
+	aclRules          []cgroups.ACLRule
+	hooks             *hooks.Runner
+	clientWatches     *clientwatch.Service
+	webPush           *webpush.Service
+	flapDetector      *correlation.FlapDetector
+	autoTags          *autotags.Config
+	osEOL             *oseol.Dataset
+	bandwidth         *bandwidth.Service
+	firewall          *firewall.Manager
+	tunnelSchemes     tunnelschemes.Schemes
+	preConnect        *preconnect.Script
+	minClientVersion  *clientversion.Config
+	tunnelCredentials clienttunnel.CredentialsProvider
+	// duplicatesSerialLabel is the client label holding the machine serial to detect duplicated clients
+	duplicatesSerialLabel string
+	alertDuplicates       bool
+	// clockSkewThreshold is the clock skew of clients reported to the alerting service as exceeded, 0 disables it
+	clockSkewThreshold time.Duration
+	// agentStatsThresholds is the resource usage of client processes reported to the alerting service as exceeded
+	agentStatsThresholds AgentStatsThresholds
 
 	licensecap licensecap.CapabilityEx
 
+	// clientLocks serialize connects and tunnel changes per client
+	clientLocks clientLocks
+
 	mu sync.RWMutex
 }
 
+var (
+	// ErrClientAlreadyConnected is returned on connecting a client with the id of a connected client.
+	ErrClientAlreadyConnected = errors.New("client is already connected")
+	// ErrClientAuthIDInUse is returned on connecting a client with a client auth used by another client.
+	ErrClientAuthIDInUse = errors.New("client auth ID is already in use")
+)
+
+var errTunnelShareNotFound = apiErrors.APIError{
+	Message:    "Tunnel share not found",
+	HTTPStatus: http.StatusNotFound,
+}
+
 var OptionsSupportedFilters = map[string]bool{
 	"id":                       true,
 	"name":                     true,
@@ -108,11 +194,23 @@ var OptionsSupportedFilters = map[string]bool{
 	"cpu_model_name":           true,
 	"cpu_vendor":               true,
 	"num_cpus":                 true,
+	"num_cpus[gt]":             true,
+	"num_cpus[lt]":             true,
+	"num_cpus[gte]":            true,
+	"num_cpus[lte]":            true,
+	"num_cpus[ne]":             true,
+	"mem_total":                true,
+	"mem_total[gt]":            true,
+	"mem_total[lt]":            true,
+	"mem_total[gte]":           true,
+	"mem_total[lte]":           true,
+	"mem_total[ne]":            true,
 	"timezone":                 true,
 	"hostname":                 true,
 	"ipv4":                     true,
 	"ipv6":                     true,
 	"tags":                     true,
+	"auto_tags":                true,
 	"labels":                   true,
 	"version":                  true,
 	"address":                  true,
@@ -120,6 +218,16 @@ var OptionsSupportedFilters = map[string]bool{
 	"allowed_user_groups":      true,
 	"groups":                   true,
 	"connection_state":         true,
+	"is_eol":                   true,
+	"outdated":                 true,
+	"mode":                     true,
+	"protocol_version":         true,
+	"protocol_version[gt]":     true,
+	"protocol_version[lt]":     true,
+	"protocol_version[gte]":    true,
+	"protocol_version[lte]":    true,
+	"protocol_version[ne]":     true,
+	"features":                 true,
 }
 
 var OptionsSupportedSorts = map[string]bool{
@@ -142,12 +250,14 @@ var OptionsSupportedFields = map[string]map[string]bool{
 		"ipv4":                     true,
 		"ipv6":                     true,
 		"tags":                     true,
+		"auto_tags":                true,
 		"labels":                   true,
 		"version":                  true,
 		"address":                  true,
 		"tunnels":                  true,
 		"disconnected_at":          true,
 		"last_heartbeat_at":        true,
+		"agent_stats":              true,
 		"connection_state":         true,
 		"client_auth_id":           true,
 		"os_full_name":             true,
@@ -163,8 +273,17 @@ var OptionsSupportedFields = map[string]map[string]bool{
 		"mem_total":                true,
 		"allowed_user_groups":      true,
 		"updates_status":           true,
+		"interpreters":             true,
 		"client_configuration":     true,
 		"groups":                   true,
+		"eol_date":                 true,
+		"is_eol":                   true,
+		"outdated":                 true,
+		"quarantine":               true,
+		"monitoring_profile":       true,
+		"mode":                     true,
+		"protocol_version":         true,
+		"features":                 true,
 	},
 }
 
@@ -231,6 +350,16 @@ func (s *ClientServiceProvider) SendClientUpdateToAlerting(cl *clientdata.Client
 		s.log().Debugf("unable to transform client update for alerting service")
 		return
 	}
+	if s.alertDuplicates {
+		clientupdate.DuplicateClientIDs = FindDuplicatesOf(cl, s.repo.GetAllClients(), s.duplicatesSerialLabel)
+	}
+	clientupdate.ClockSkewExceeded = s.clockSkewExceeded(clientupdate.ClockSkew)
+	clientupdate.AgentStatsExceeded = s.agentStatsThresholds.Exceeded(cl.GetAgentStats())
+	clientupdate.IsEOL = cl.EOLReached(clientdata.Now())
+	if s.flapDetector != nil && !s.flapDetector.Correlate(clientupdate) {
+		s.log().Debugf("client %s is flapping, connection state change not sent to the alerting service", clientupdate.ID)
+		return
+	}
 	err = s.alertingService.PutClientUpdate(clientupdate)
 	if err != nil {
 		s.log().Debugf("Failed to send client update to the alerting service")
@@ -256,6 +385,10 @@ func (s *ClientServiceProvider) UpdateClientStatus() {
 	clientList := s.repo.GetAllActiveClients()
 
 	for i, client := range clientList {
+		// outdated clients stay paused until updated
+		if client.IsOutdated() {
+			continue
+		}
 		if i < s.GetMaxClients() {
 			client.SetPaused(false, "")
 		} else {
@@ -289,15 +422,7 @@ func (s *ClientServiceProvider) GetByGroups(groups []*cgroups.ClientGroup) ([]*c
 		return nil, nil
 	}
 
-	allClients := s.repo.GetAllClients()
-
-	var res []*clientdata.Client
-	for _, cur := range allClients {
-		if cur.BelongsToOneOf(groups) {
-			res = append(res, cur)
-		}
-	}
-	return res, nil
+	return s.repo.GetByGroups(groups), nil
 }
 
 func (s *ClientServiceProvider) GetClientsByTag(tags []string, operator string, allowDisconnected bool) (clients []*clientdata.Client, err error) {
@@ -335,6 +460,12 @@ func (s *ClientServiceProvider) GetFilteredUserClients(user User, filterOptions
 	return s.repo.GetFilteredUserClients(user, filterOptions, groups)
 }
 
+// GetUserClientsPage returns the page of the clients of the user given by the list options and the total count of
+// matching clients.
+func (s *ClientServiceProvider) GetUserClientsPage(user User, options *query.ListOptions, groups []*cgroups.ClientGroup) ([]*clientdata.CalculatedClient, int, error) {
+	return s.repo.GetUserClientsPage(user, options, groups)
+}
+
 func (s *ClientServiceProvider) StartClient(
 	ctx context.Context, clientAuthID, clientID string, sshConn ssh.Conn, authMultiuseCreds bool,
 	req *chshare.ConnectionRequest, clog *logger.Logger,
@@ -342,6 +473,9 @@ func (s *ClientServiceProvider) StartClient(
 	clog.Debugf("Starting client session: %s", clientID)
 	repo := s.GetRepo()
 
+	unlock := s.clientLocks.lock(clientID)
+	defer unlock()
+
 	clientAddr := sshConn.RemoteAddr().String()
 	clientHost, _, err := net.SplitHostPort(clientAddr)
 	if err != nil {
@@ -366,7 +500,7 @@ func (s *ClientServiceProvider) StartClient(
 
 		if client.IsConnected() && !sessionReUsed {
 			clog.Debugf("client is already connected:  %s", clientID)
-			return nil, fmt.Errorf("client is already connected: %s [%s]", client.GetName(), clientID)
+			return nil, fmt.Errorf("%w: %s [%s]", ErrClientAlreadyConnected, client.GetName(), clientID)
 		}
 
 		oldTunnels := getTunnelsToReestablish(getRemotes(client.GetTunnels()), req.Remotes)
@@ -395,16 +529,54 @@ func (s *ClientServiceProvider) StartClient(
 	// check if client auth ID is already used by another client
 	if !authMultiuseCreds && s.isClientAuthIDInUse(clientAuthID, clientID) {
 		clog.Debugf("client auth ID is already in use: %s: %q: ", clientID, clientAuthID)
-		return nil, fmt.Errorf("client auth ID is already in use: %q", clientAuthID)
+		return nil, fmt.Errorf("%w: %q", ErrClientAuthIDInUse, clientAuthID)
+	}
+
+	outdatedErr := s.minClientVersion.Check(clientAuthID, req.Version)
+	if outdatedErr != nil && !s.minClientVersion.Quarantine() {
+		clog.Infof("rejected client %s: %v", clientID, outdatedErr)
+		return nil, outdatedErr
+	}
+
+	decision, err := s.preConnect.Evaluate(ctx, preconnect.NewClient(clientID, clientAuthID, clientHost, req))
+	if err != nil {
+		return nil, err
+	}
+	if decision.Tags != nil {
+		clog.Infof("pre-connect script set tags of client %s to %v", clientID, decision.Tags)
+		req.Tags = decision.Tags
+	}
+
+	reconnected := client != nil
+	var prevIPv4, prevIPv6 []string
+	if reconnected {
+		prevIPv4, prevIPv6 = client.GetIPv4(), client.GetIPv6()
 	}
 
 	client = clientdata.NewClientFromConnRequest(ctx, client, clientAuthID, clientID, req, clientHost, sshConn, clog)
 
+	var addressChange *clientdata.AddressChange
+	if reconnected {
+		addressChange = clientdata.NewAddressChange(clientdata.AddressChangeSourceConnect, prevIPv4, prevIPv6, req.IPv4, req.IPv6)
+		if addressChange != nil {
+			client.AddAddressChange(*addressChange)
+			clog.Infof("addresses of client %s changed since the last connect: added %v, removed %v", clientID, addressChange.Added, addressChange.Removed)
+		}
+	}
+
 	client.SetConnected()
 
+	s.applyACLRules(client, clog)
+	s.addAllowedUserGroups(client, decision.UserGroups, clog)
+	s.updateOutdated(client, outdatedErr, clog)
+	s.updateAutoTags(client)
+	if s.osEOL != nil {
+		updateEOLDate(s.osEOL, client)
+	}
+
 	s.UpdateClientStatus()
 
-	if !client.IsPaused() {
+	if !client.IsPaused() && !client.IsCheckInOnly() && !client.IsQuarantined() {
 		_, err = s.startClientTunnels(client, req.Remotes, clog)
 
 		if err != nil {
@@ -417,6 +589,13 @@ func (s *ClientServiceProvider) StartClient(
 		return nil, err
 	}
 
+	clientConnectionsTotal.Inc()
+	s.fireHook(hooks.EventClientConnected, client, nil)
+	if addressChange != nil {
+		s.fireAddressesChangedHook(client, addressChange)
+	}
+	s.clientWatches.ClientConnected(ctx, client.GetID(), client.GetName())
+
 	// TODO: (rs): should we keep this?
 	totalClients := repo.GetAllActiveClients()
 	s.log().Debugf("total clients = %d (last: %s)", len(totalClients), client.GetName())
@@ -492,6 +671,13 @@ loop2:
 func (s *ClientServiceProvider) StartClientTunnels(client *clientdata.Client, remotes []*models.Remote) ([]*clienttunnel.Tunnel, error) {
 	s.logger.Debugf("starting client tunnels: %s", client.GetID())
 
+	unlock := s.clientLocks.lock(client.GetID())
+	defer unlock()
+
+	if err := client.CheckQuarantine(); err != nil {
+		return nil, apiErrors.NewAPIError(http.StatusConflict, apiErrors.ErrCodeClientQuarantined, err.Error(), nil)
+	}
+
 	newTunnels, err := s.startClientTunnels(client, remotes, s.log())
 	if err != nil {
 		return nil, err
@@ -513,6 +699,10 @@ func (s *ClientServiceProvider) startClientTunnels(client *clientdata.Client, re
 
 	tunnels := make([]*clienttunnel.Tunnel, 0, len(remotes))
 	for _, remote := range remotes {
+		if err := s.tunnelSchemes.ApplyDefaults(remote); err != nil {
+			return nil, err
+		}
+
 		if !remote.IsLocalSpecified() {
 			clog.Debugf("no local specified")
 			port, err := s.portDistributor.GetRandomPort(remote.Protocol)
@@ -521,6 +711,9 @@ func (s *ClientServiceProvider) startClientTunnels(client *clientdata.Client, re
 			}
 			remote.LocalPort = strconv.Itoa(port)
 			remote.LocalHost = models.ZeroHost
+			if remote.BindAddress != "" {
+				remote.LocalHost = remote.BindAddress
+			}
 			remote.LocalPortRandom = true
 			clog.Debugf("using random port %s", remote.LocalPort)
 		} else {
@@ -567,7 +760,7 @@ func (s *ClientServiceProvider) checkLocalPort(protocol, port string) error {
 	}
 
 	if s.portDistributor.IsPortBusy(protocol, localPort) {
-		return apiErrors.NewAPIError(http.StatusConflict, "", fmt.Sprintf("Local port %d already in use.", localPort), nil)
+		return apiErrors.NewAPIError(http.StatusConflict, apiErrors.ErrCodeLocalPortInUse, fmt.Sprintf("Local port %d already in use.", localPort), nil)
 	}
 
 	return nil
@@ -575,12 +768,24 @@ func (s *ClientServiceProvider) checkLocalPort(protocol, port string) error {
 
 func (s *ClientServiceProvider) Terminate(client *clientdata.Client) error {
 	s.log().Infof("terminating client: %s: %s", client.GetID(), client.GetName())
+
+	for _, t := range client.GetTunnels() {
+		s.firewall.Close(t.Protocol, t.LocalHost, t.LocalPort)
+		s.releaseTunnelPorts(t)
+		tunnelsClosedTotal.Inc(t.Protocol, tunnelCloseReasonClientDisconnected)
+		s.fireHook(hooks.EventTunnelClosed, client, t)
+	}
+	clientDisconnectionsTotal.Inc()
+	s.fireHook(hooks.EventClientDisconnected, client, nil)
+	s.webPush.ClientDisconnected(context.Background(), client.GetID(), client.GetName())
+
 	keepDisconnectedClientsDuration := s.repo.GetKeepDisconnectedClients()
 	if keepDisconnectedClientsDuration != nil && *keepDisconnectedClientsDuration == 0 {
 		return s.repo.Delete(client)
 	}
 
 	client.SetDisconnectedNow()
+	s.updateAutoTags(client)
 
 	// Do not save if client doesn't exist in repo - it was force deleted
 	existing, err := s.repo.GetByID(client.GetID())
@@ -612,6 +817,9 @@ func (s *ClientServiceProvider) ForceDelete(client *clientdata.Client) error {
 func (s *ClientServiceProvider) DeleteOffline(clientID string) error {
 	s.logger.Debugf("deleting offline client: %s", clientID)
 
+	unlock := s.clientLocks.lock(clientID)
+	defer unlock()
+
 	existing, err := s.getExistingClientByID(clientID)
 	if err != nil {
 		return err
@@ -627,6 +835,65 @@ func (s *ClientServiceProvider) DeleteOffline(clientID string) error {
 	return s.repo.Delete(existing)
 }
 
+// DeleteOfflineByFilter deletes all clients the user has access to that match the filters and were disconnected
+// at or before disconnectedBefore. With dryRun the matching clients are returned without deleting them.
+func (s *ClientServiceProvider) DeleteOfflineByFilter(
+	user User, filterOptions []query.FilterOption, groups []*cgroups.ClientGroup, disconnectedBefore time.Time, dryRun bool,
+) ([]*clientdata.CalculatedClient, error) {
+	filteredClients, err := s.repo.GetFilteredUserClients(user, filterOptions, groups)
+	if err != nil {
+		return nil, err
+	}
+
+	matchingClients := make([]*clientdata.CalculatedClient, 0, len(filteredClients))
+	for _, client := range filteredClients {
+		if !isDisconnectedBefore(client.Client, disconnectedBefore) {
+			continue
+		}
+		matchingClients = append(matchingClients, client)
+	}
+
+	if dryRun {
+		return matchingClients, nil
+	}
+
+	deletedClients := make([]*clientdata.CalculatedClient, 0, len(matchingClients))
+	for _, client := range matchingClients {
+		deleted, err := s.deleteIfDisconnectedBefore(client.GetID(), disconnectedBefore)
+		if err != nil {
+			return deletedClients, err
+		}
+		if deleted {
+			deletedClients = append(deletedClients, client)
+		}
+	}
+
+	return deletedClients, nil
+}
+
+// deleteIfDisconnectedBefore deletes the client if it is still disconnected since disconnectedBefore, the client might
+// have reconnected since it was filtered.
+func (s *ClientServiceProvider) deleteIfDisconnectedBefore(clientID string, disconnectedBefore time.Time) (bool, error) {
+	unlock := s.clientLocks.lock(clientID)
+	defer unlock()
+
+	existing, err := s.repo.GetByID(clientID)
+	if err != nil {
+		return false, err
+	}
+	if existing == nil || !isDisconnectedBefore(existing, disconnectedBefore) {
+		return false, nil
+	}
+
+	s.logger.Debugf("deleting offline client: %s", clientID)
+	return true, s.repo.Delete(existing)
+}
+
+func isDisconnectedBefore(client *clientdata.Client, disconnectedBefore time.Time) bool {
+	disconnectedAt := client.GetDisconnectedAt()
+	return disconnectedAt != nil && !disconnectedAt.After(disconnectedBefore)
+}
+
 // isClientAuthIDInUse returns true when the client with different id exists for the client auth
 func (s *ClientServiceProvider) isClientAuthIDInUse(clientAuthID, clientID string) bool {
 	for _, client := range s.repo.GetAllByClientAuthID(clientAuthID) {
@@ -659,6 +926,39 @@ func (s *ClientServiceProvider) SetUpdatesStatus(clientID string, updatesStatus
 	return s.repo.Save(client)
 }
 
+func (s *ClientServiceProvider) SetSecuritySnapshot(clientID string, snapshot *models.SecuritySnapshot) error {
+	client, err := s.getExistingClientByID(clientID)
+	if err != nil {
+		return err
+	}
+
+	client.SetSecuritySnapshot(snapshot)
+
+	return s.repo.Save(client)
+}
+
+func (s *ClientServiceProvider) SetInterpreters(clientID string, interpreters []models.Interpreter) error {
+	client, err := s.getExistingClientByID(clientID)
+	if err != nil {
+		return err
+	}
+
+	client.SetInterpreters(interpreters)
+
+	return s.repo.Save(client)
+}
+
+func (s *ClientServiceProvider) SetMonitoringProfile(clientID string, state *clientdata.MonitoringProfileState) error {
+	client, err := s.getExistingClientByID(clientID)
+	if err != nil {
+		return err
+	}
+
+	client.SetMonitoringProfile(state)
+
+	return s.repo.Save(client)
+}
+
 func (s *ClientServiceProvider) SetLastHeartbeat(clientID string, heartbeat time.Time) error {
 	existing, err := s.getExistingClientByID(clientID)
 	if err != nil {
@@ -668,6 +968,85 @@ func (s *ClientServiceProvider) SetLastHeartbeat(clientID string, heartbeat time
 	return nil
 }
 
+// SetClockSkew stores the clock skew measured on a client to server heartbeat. The client update is sent to the
+// alerting service if the skew exceeds the threshold now but didn't before or vice versa.
+func (s *ClientServiceProvider) SetClockSkew(clientID string, skew time.Duration) error {
+	existing, err := s.getExistingClientByID(clientID)
+	if err != nil {
+		return err
+	}
+	wasExceeded := s.clockSkewExceeded(existing.GetClockSkew())
+	existing.SetClockSkew(skew)
+	exceeded := s.clockSkewExceeded(existing.GetClockSkew())
+	if exceeded == wasExceeded {
+		return nil
+	}
+
+	if exceeded {
+		existing.Log().Infof("clock skew of client %s is %s, exceeds %s", clientID, skew, s.clockSkewThreshold)
+	} else {
+		existing.Log().Infof("clock skew of client %s is %s, back within %s", clientID, skew, s.clockSkewThreshold)
+	}
+	if s.alertingService != nil {
+		s.SendClientUpdateToAlerting(existing)
+	}
+	return nil
+}
+
+// SetAgentStats stores the resource usage of the client process reported on a client to server heartbeat. The client
+// update is sent to the alerting service if the usage exceeds a threshold now but didn't before or vice versa.
+func (s *ClientServiceProvider) SetAgentStats(clientID string, stats *models.AgentStats) error {
+	existing, err := s.getExistingClientByID(clientID)
+	if err != nil {
+		return err
+	}
+	wasExceeded := s.agentStatsThresholds.Exceeded(existing.GetAgentStats())
+	existing.SetAgentStats(stats)
+	exceeded := s.agentStatsThresholds.Exceeded(stats)
+	if exceeded == wasExceeded {
+		return nil
+	}
+
+	if exceeded {
+		existing.Log().Infof("resource usage of client %s exceeds the thresholds: rss %d bytes, %d goroutines", clientID, stats.RSSBytes, stats.Goroutines)
+	} else {
+		existing.Log().Infof("resource usage of client %s is back within the thresholds", clientID)
+	}
+	if s.alertingService != nil {
+		s.SendClientUpdateToAlerting(existing)
+	}
+	return nil
+}
+
+// SetAddresses updates the addresses reported on a client to server heartbeat. If they changed, the change is
+// recorded, the client is saved, which also updates the alerting, and the client_addresses_changed hooks are run.
+func (s *ClientServiceProvider) SetAddresses(clientID string, ipv4, ipv6 []string) error {
+	existing, err := s.getExistingClientByID(clientID)
+	if err != nil {
+		return err
+	}
+	// unlike on connect the addresses aren't checked by the client payload validator
+	ipv4, ipv6 = clientdata.ValidAddresses(ipv4), clientdata.ValidAddresses(ipv6)
+	change := existing.SetAddresses(clientdata.AddressChangeSourceHeartbeat, ipv4, ipv6)
+	if change == nil {
+		return nil
+	}
+
+	existing.Log().Infof("addresses of client %s changed: added %v, removed %v", clientID, change.Added, change.Removed)
+	if err := s.GetRepo().Save(existing); err != nil {
+		return err
+	}
+	s.fireAddressesChangedHook(existing, change)
+	return nil
+}
+
+func (s *ClientServiceProvider) clockSkewExceeded(skew *float64) bool {
+	if skew == nil || s.clockSkewThreshold <= 0 {
+		return false
+	}
+	return math.Abs(*skew) > s.clockSkewThreshold.Seconds()
+}
+
 // CheckClientAccess returns nil if a given user has an access to a given client.
 // Otherwise, APIError with 403 is returned.
 func (s *ClientServiceProvider) CheckClientAccess(clientID string, user User, groups []*cgroups.ClientGroup) error {
@@ -724,6 +1103,7 @@ func (s *ClientServiceProvider) getExistingClientByID(clientID string) (*clientd
 		return nil, apiErrors.APIError{
 			Message:    fmt.Sprintf("Client with id=%q not found.", clientID),
 			HTTPStatus: http.StatusNotFound,
+			ErrCode:    apiErrors.ErrCodeClientNotFound,
 		}
 	}
 
@@ -774,6 +1154,248 @@ func (s *ClientServiceProvider) SetCaddyAPI(capi caddy.API) {
 	s.caddyAPI = capi
 }
 
+func (s *ClientServiceProvider) SetSessionRecordingStore(store *sessionrecording.Store) {
+	// unguarded as set during initialization
+	s.recordingStore = store
+}
+
+func (s *ClientServiceProvider) SetSecurityEvents(store *securityevents.Store) {
+	// unguarded as set during initialization
+	s.securityEvents = store
+}
+
+// tunnelACLRejectHandler records connections rejected by the ACL of a tunnel as security events.
+func (s *ClientServiceProvider) tunnelACLRejectHandler(client *clientdata.Client, tunnelID string) clienttunnel.ACLRejectHandler {
+	if s.securityEvents == nil {
+		return nil
+	}
+	detail := fmt.Sprintf("client %s tunnel %s", client.GetID(), tunnelID)
+	return func(ip net.IP) {
+		s.securityEvents.Add(securityevents.KindTunnelACLDenied, ip.String(), detail)
+	}
+}
+
+// tunnelTrafficHandler accounts the traffic of a tunnel to the client and the user who created the tunnel.
+func (s *ClientServiceProvider) tunnelTrafficHandler(client *clientdata.Client, remote *models.Remote) clienttunnel.TrafficHandler {
+	clientID := client.GetID()
+	owner := remote.Owner
+	return func(in, out int64) {
+		if in > 0 {
+			tunnelTrafficBytesTotal.Add(float64(in), "in")
+		}
+		if out > 0 {
+			tunnelTrafficBytesTotal.Add(float64(out), "out")
+		}
+		if s.bandwidth != nil {
+			s.bandwidth.Add(clientID, owner, in, out)
+		}
+	}
+}
+
+func (s *ClientServiceProvider) SetACLRules(rules []cgroups.ACLRule) {
+	// unguarded as set during initialization
+	s.aclRules = rules
+}
+
+func (s *ClientServiceProvider) SetHooks(runner *hooks.Runner) {
+	// unguarded as set during initialization
+	s.hooks = runner
+}
+
+func (s *ClientServiceProvider) SetClientWatches(watches *clientwatch.Service) {
+	// unguarded as set during initialization
+	s.clientWatches = watches
+}
+
+func (s *ClientServiceProvider) SetWebPush(webPush *webpush.Service) {
+	// unguarded as set during initialization
+	s.webPush = webPush
+}
+
+func (s *ClientServiceProvider) SetFlapDetector(detector *correlation.FlapDetector) {
+	// unguarded as set during initialization
+	s.flapDetector = detector
+}
+
+// SetDuplicateDetection sets the label holding the machine serial of clients and whether the ids of clients sharing
+// the hostname or serial are sent to the alerting service.
+func (s *ClientServiceProvider) SetDuplicateDetection(serialLabel string, alerting bool) {
+	// unguarded as set during initialization
+	s.duplicatesSerialLabel = serialLabel
+	s.alertDuplicates = alerting
+}
+
+func (s *ClientServiceProvider) SetClockSkewThreshold(threshold time.Duration) {
+	// unguarded as set during initialization
+	s.clockSkewThreshold = threshold
+}
+
+func (s *ClientServiceProvider) SetAgentStatsThresholds(thresholds AgentStatsThresholds) {
+	// unguarded as set during initialization
+	s.agentStatsThresholds = thresholds
+}
+
+func (s *ClientServiceProvider) SetAutoTagsConfig(cfg *autotags.Config) {
+	// unguarded as set during initialization
+	s.autoTags = cfg
+}
+
+func (s *ClientServiceProvider) SetOSEOLDataset(dataset *oseol.Dataset) {
+	// unguarded as set during initialization
+	s.osEOL = dataset
+}
+
+func (s *ClientServiceProvider) SetBandwidth(bandwidth *bandwidth.Service) {
+	// unguarded as set during initialization
+	s.bandwidth = bandwidth
+}
+
+func (s *ClientServiceProvider) SetFirewall(firewall *firewall.Manager) {
+	// unguarded as set during initialization
+	s.firewall = firewall
+}
+
+func (s *ClientServiceProvider) SetTunnelSchemes(schemes tunnelschemes.Schemes) {
+	// unguarded as set during initialization
+	s.tunnelSchemes = schemes
+}
+
+func (s *ClientServiceProvider) SetPreConnectScript(script *preconnect.Script) {
+	// unguarded as set during initialization
+	s.preConnect = script
+}
+
+func (s *ClientServiceProvider) SetMinClientVersion(config *clientversion.Config) {
+	// unguarded as set during initialization
+	s.minClientVersion = config
+}
+
+func (s *ClientServiceProvider) SetTunnelCredentialsProvider(provider clienttunnel.CredentialsProvider) {
+	// unguarded as set during initialization
+	s.tunnelCredentials = provider
+}
+
+func (s *ClientServiceProvider) updateAutoTags(client *clientdata.Client) {
+	if s.autoTags == nil {
+		// disconnects are only tracked for the auto tags
+		client.SetDisconnects(nil)
+		return
+	}
+	updateAutoTags(s.autoTags, client, clientdata.Now())
+}
+
+// fireHook runs the exec hooks registered for the event, tunnel is nil for client events.
+func (s *ClientServiceProvider) fireHook(event string, client *clientdata.Client, tunnel *clienttunnel.Tunnel) {
+	if s.hooks == nil {
+		return
+	}
+
+	e := &hooks.Event{
+		Name:      event,
+		Timestamp: time.Now(),
+		Client:    hookClient(client),
+	}
+	if tunnel != nil {
+		e.Tunnel = &hooks.Tunnel{
+			ID:         tunnel.ID,
+			Protocol:   tunnel.Protocol,
+			LocalHost:  tunnel.LocalHost,
+			LocalPort:  tunnel.LocalPort,
+			RemoteHost: tunnel.RemoteHost,
+			RemotePort: tunnel.RemotePort,
+			Owner:      tunnel.Owner,
+			ACL:        tunnel.ACL,
+		}
+	}
+	s.hooks.Fire(e)
+}
+
+// fireAddressesChangedHook runs the exec hooks registered for changes of the client addresses.
+func (s *ClientServiceProvider) fireAddressesChangedHook(client *clientdata.Client, change *clientdata.AddressChange) {
+	if s.hooks == nil {
+		return
+	}
+
+	s.hooks.Fire(&hooks.Event{
+		Name:      hooks.EventClientAddressesChanged,
+		Timestamp: change.Timestamp,
+		Client:    hookClient(client),
+		Addresses: &hooks.Addresses{
+			IPv4:    change.IPv4,
+			IPv6:    change.IPv6,
+			Added:   change.Added,
+			Removed: change.Removed,
+		},
+	})
+}
+
+func hookClient(client *clientdata.Client) hooks.Client {
+	return hooks.Client{
+		ID:           client.GetID(),
+		Name:         client.GetName(),
+		Hostname:     client.GetHostname(),
+		Address:      client.GetAddress(),
+		ClientAuthID: client.GetClientAuthID(),
+	}
+}
+
+// applyACLRules adds the user groups of the configured acl rules matching the client to its allowed user groups.
+func (s *ClientServiceProvider) applyACLRules(client *clientdata.Client, clog *logger.Logger) {
+	if len(s.aclRules) == 0 {
+		return
+	}
+
+	current := client.GetAllowedUserGroups()
+	userGroups := cgroups.ApplyACLRules(s.aclRules, current, client.GetTags(), client.GetClientAuthID())
+	if len(userGroups) == len(current) {
+		return
+	}
+
+	clog.Infof("acl rules set allowed user groups of client %s to %v", client.GetID(), userGroups)
+	client.SetAllowedUserGroups(userGroups)
+}
+
+// updateOutdated flags and pauses clients connecting with an outdated version, so they have no tunnel and job
+// capability until updated.
+func (s *ClientServiceProvider) updateOutdated(client *clientdata.Client, outdatedErr error, clog *logger.Logger) {
+	client.SetOutdated(outdatedErr != nil)
+	if outdatedErr != nil {
+		clog.Infof("quarantined client %s: %v", client.GetID(), outdatedErr)
+		client.SetPaused(true, clientdata.PausedDueToOutdatedVersion)
+		return
+	}
+	if client.GetPausedReason() == clientdata.PausedDueToOutdatedVersion {
+		client.SetPaused(false, "")
+	}
+}
+
+// addAllowedUserGroups adds the user groups assigned by the pre-connect script to the allowed user groups of the client.
+func (s *ClientServiceProvider) addAllowedUserGroups(client *clientdata.Client, userGroups []string, clog *logger.Logger) {
+	if len(userGroups) == 0 {
+		return
+	}
+
+	current := client.GetAllowedUserGroups()
+	existing := len(current)
+	set := make(map[string]bool, existing)
+	for _, userGroup := range current {
+		set[userGroup] = true
+	}
+	for _, userGroup := range userGroups {
+		if userGroup != "" && !set[userGroup] {
+			set[userGroup] = true
+			current = append(current, userGroup)
+		}
+	}
+	if len(current) == existing {
+		return
+	}
+
+	sort.Strings(current)
+	clog.Infof("pre-connect script set allowed user groups of client %s to %v", client.GetID(), current)
+	client.SetAllowedUserGroups(current)
+}
+
 func (s *ClientServiceProvider) StartTunnel(
 	client *clientdata.Client,
 	remote *models.Remote,
@@ -829,6 +1451,10 @@ func (s *ClientServiceProvider) StartTunnel(
 	existingTunnels = append(existingTunnels, tunnel)
 	client.SetTunnels(existingTunnels)
 
+	s.firewall.Open(tunnel.Protocol, tunnel.LocalHost, tunnel.LocalPort)
+	tunnelsOpenedTotal.Inc(tunnel.Protocol)
+	s.fireHook(hooks.EventTunnelOpened, client, tunnel)
+
 	return tunnel, nil
 }
 
@@ -875,7 +1501,12 @@ func (s *ClientServiceProvider) startCaddyDownstreamProxy(
 func (s *ClientServiceProvider) startRegularTunnel(ctx context.Context, client *clientdata.Client, remote *models.Remote, acl *clienttunnel.TunnelACL) (*clienttunnel.Tunnel, error) {
 	tunnelID := client.NewTunnelID()
 
-	tunnel, err := clienttunnel.NewTunnel(client.Log(), client.GetConnection(), tunnelID, *remote, acl)
+	recorder, err := s.newTunnelRecorder(client, tunnelID, remote)
+	if err != nil {
+		return nil, err
+	}
+
+	tunnel, err := clienttunnel.NewTunnel(client.Log(), client.GetConnection(), tunnelID, *remote, acl, recorder, s.tunnelACLRejectHandler(client, tunnelID), s.tunnelTrafficHandler(client, remote))
 	if err != nil {
 		return nil, err
 	}
@@ -922,7 +1553,13 @@ func (s *ClientServiceProvider) startTunnelWithProxy(
 	tunnelID := client.NewTunnelID()
 
 	// original tunnel will use the reconfigured original remote
-	t, err := clienttunnel.NewTunnel(clientLogger, client.GetConnection(), tunnelID, *remote, acl)
+	recorder, err := s.newTunnelRecorder(client, tunnelID, remote)
+	if err != nil {
+		return nil, err
+	}
+
+	// the tunnel accepts connections from the proxy only, rejections are recorded by the proxy
+	t, err := clienttunnel.NewTunnel(clientLogger, client.GetConnection(), tunnelID, *remote, acl, recorder, nil, s.tunnelTrafficHandler(client, remote))
 	if err != nil {
 		return nil, err
 	}
@@ -934,7 +1571,7 @@ func (s *ClientServiceProvider) startTunnelWithProxy(
 	}
 
 	// create new proxy tunnel listening at the original tunnel local host addr
-	tProxy := clienttunnel.NewInternalTunnelProxy(t, clientLogger, s.tunnelProxyConfig, proxyHost, proxyPort, proxyACL, s.acme)
+	tProxy := clienttunnel.NewInternalTunnelProxy(t, clientLogger, s.tunnelProxyConfig, proxyHost, proxyPort, proxyACL, s.acme, s.tunnelCredentials, s.tunnelACLRejectHandler(client, tunnelID))
 	clientLogger.Debugf("client %s starting tunnel proxy", clientID)
 	if err := tProxy.Start(ctx); err != nil {
 		clientLogger.Debugf("tunnel proxy could not be started, tunnel must be terminated: %v", err)
@@ -956,11 +1593,25 @@ func (s *ClientServiceProvider) startTunnelWithProxy(
 	return t, nil
 }
 
+// newTunnelRecorder returns nil if recording is not requested for the given remote.
+func (s *ClientServiceProvider) newTunnelRecorder(client *clientdata.Client, tunnelID string, remote *models.Remote) (clienttunnel.ConnRecorder, error) {
+	if !remote.Record {
+		return nil, nil
+	}
+	if s.recordingStore == nil {
+		return nil, apiErrors.APIError{
+			Message:    "Session recording is disabled.",
+			HTTPStatus: http.StatusBadRequest,
+		}
+	}
+	return s.recordingStore.NewTunnelRecorder(client.GetID(), tunnelID, remote.Owner, remote.Remote()), nil
+}
+
 func (s *ClientServiceProvider) cleanupOnAutoCloseDeadlineExceeded(ctx context.Context, t *clienttunnel.Tunnel, c *clientdata.Client) {
 	<-ctx.Done()
 	// DeadlineExceeded err is expected when tunnel AutoClose period is reached, otherwise skip cleanup
 	if ctx.Err() == context.DeadlineExceeded {
-		s.cleanupAfterAutoClose(c, t)
+		s.cleanupAfterAutoClose(c, t, clientdata.TunnelCloseReasonAutoClose)
 	}
 }
 
@@ -977,7 +1628,7 @@ func (s *ClientServiceProvider) terminateTunnelOnIdleTimeout(ctx context.Context
 			if sinceLastActive > idleTimeout {
 				c.Log().Infof("Terminating... inactivity period is reached: %d minute(s)", t.IdleTimeoutMinutes)
 				_ = t.Terminate(true)
-				s.cleanupAfterAutoClose(c, t)
+				s.cleanupAfterAutoClose(c, t, clientdata.TunnelCloseReasonIdleTimeout)
 				return
 			}
 			timer.Reset(idleTimeout - sinceLastActive)
@@ -985,10 +1636,10 @@ func (s *ClientServiceProvider) terminateTunnelOnIdleTimeout(ctx context.Context
 	}
 }
 
-func (s *ClientServiceProvider) cleanupAfterAutoClose(c *clientdata.Client, t *clienttunnel.Tunnel) {
+func (s *ClientServiceProvider) cleanupAfterAutoClose(c *clientdata.Client, t *clienttunnel.Tunnel, reason string) {
 	clientLogger := c.Log()
 
-	clientLogger.Infof("Auto closing tunnel %s ...", t.ID)
+	clientLogger.Infof("Auto closing tunnel %s (reason: %s) ...", t.ID, reason)
 
 	// stop tunnel proxy
 	if t.InternalTunnelProxy != nil {
@@ -1001,6 +1652,19 @@ func (s *ClientServiceProvider) cleanupAfterAutoClose(c *clientdata.Client, t *c
 	}
 
 	c.RemoveTunnelByID(t.ID)
+	s.firewall.Close(t.Protocol, t.LocalHost, t.LocalPort)
+	s.releaseTunnelPorts(t)
+	c.AddTunnelClosure(clientdata.TunnelClosure{
+		Timestamp: time.Now().UTC(),
+		TunnelID:  t.ID,
+		Name:      t.Name,
+		Protocol:  t.Protocol,
+		LocalPort: t.LocalPort,
+		Remote:    t.Remote.Remote(),
+		Reason:    reason,
+	})
+	tunnelsClosedTotal.Inc(t.Protocol, reason)
+	s.fireHook(hooks.EventTunnelClosed, c, t)
 
 	err := s.repo.Save(c)
 	if err != nil {
@@ -1010,6 +1674,22 @@ func (s *ClientServiceProvider) cleanupAfterAutoClose(c *clientdata.Client, t *c
 	clientLogger.Debugf("auto closed tunnel with id=%s removed", t.ID)
 }
 
+// releaseTunnelPorts returns the ports of a closed tunnel and of its tunnel proxy to the port distributor.
+func (s *ClientServiceProvider) releaseTunnelPorts(t *clienttunnel.Tunnel) {
+	if s.portDistributor == nil {
+		return
+	}
+	ports := []string{t.LocalPort}
+	if t.InternalTunnelProxy != nil {
+		ports = append(ports, t.InternalTunnelProxy.Port)
+	}
+	for _, p := range ports {
+		if port, err := strconv.Atoi(p); err == nil {
+			s.portDistributor.Release(t.Protocol, port)
+		}
+	}
+}
+
 func (s *ClientServiceProvider) TerminateTunnel(c *clientdata.Client, t *clienttunnel.Tunnel, force bool) error {
 	clientLogger := c.Log()
 
@@ -1033,6 +1713,10 @@ func (s *ClientServiceProvider) TerminateTunnel(c *clientdata.Client, t *clientt
 	}
 
 	c.RemoveTunnelByID(t.ID)
+	s.firewall.Close(t.Protocol, t.LocalHost, t.LocalPort)
+	s.releaseTunnelPorts(t)
+	tunnelsClosedTotal.Inc(t.Protocol, tunnelCloseReasonTerminated)
+	s.fireHook(hooks.EventTunnelClosed, c, t)
 
 	err = s.repo.Save(c)
 	if err != nil {
@@ -1044,20 +1728,24 @@ func (s *ClientServiceProvider) TerminateTunnel(c *clientdata.Client, t *clientt
 }
 
 func (s *ClientServiceProvider) SetTunnelACL(c *clientdata.Client, t *clienttunnel.Tunnel, aclStr *string) error {
-	var err error
-	var acl *clienttunnel.TunnelACL
-
-	t.Remote.ACL = aclStr
-
 	if aclStr != nil {
-		acl, err = clienttunnel.ParseTunnelACL(*aclStr)
+		_, err := clienttunnel.ParseTunnelACL(*aclStr)
 		if err != nil {
 			return err
 		}
 	}
-	t.TunnelProtocol.SetACL(acl)
-	if t.InternalTunnelProxy != nil {
-		t.InternalTunnelProxy.SetACL(acl)
+	// injected vault credentials would give everyone an authenticated session
+	if t.VaultCredentialsID != 0 && t.AuthUser == "" && (aclStr == nil || *aclStr == "") {
+		return apiErrors.APIError{
+			Message:    "The ACL of a tunnel with vault credentials can only be removed if auth_user is set.",
+			HTTPStatus: http.StatusBadRequest,
+		}
+	}
+	t.Remote.ACL = aclStr
+
+	err := s.applyTunnelACL(t)
+	if err != nil {
+		return err
 	}
 
 	err = s.repo.Save(c)
@@ -1068,6 +1756,57 @@ func (s *ClientServiceProvider) SetTunnelACL(c *clientdata.Client, t *clienttunn
 	return nil
 }
 
+// AllowTunnelShareIP adds the ip to the tunnel ACL on behalf of a user of the given tunnel share.
+// The ip is removed from the ACL when the share is revoked.
+func (s *ClientServiceProvider) AllowTunnelShareIP(c *clientdata.Client, t *clienttunnel.Tunnel, shareID string, ip string) error {
+	if net.ParseIP(ip) == nil {
+		return apiErrors.APIError{
+			Message:    fmt.Sprintf("Invalid IP addr: %s", ip),
+			HTTPStatus: http.StatusBadRequest,
+		}
+	}
+	if !t.AllowShareIP(shareID, ip) {
+		return errTunnelShareNotFound
+	}
+
+	c.Log().Infof("tunnel %s: allowed %s for share %s", t.ID, ip, shareID)
+	return s.applyTunnelACL(t)
+}
+
+// RevokeTunnelShare removes the share from the tunnel together with the IPs the share added to the tunnel ACL.
+func (s *ClientServiceProvider) RevokeTunnelShare(c *clientdata.Client, t *clienttunnel.Tunnel, shareID string) error {
+	if !t.RemoveShare(shareID) {
+		return errTunnelShareNotFound
+	}
+
+	c.Log().Infof("tunnel %s: revoked share %s", t.ID, shareID)
+	return s.applyTunnelACL(t)
+}
+
+// applyTunnelACL sets the ACL of the tunnel owner extended by the IPs allowed by tunnel shares.
+// Without an ACL of the owner, the tunnel is open to all IPs, so the shares don't change it.
+func (s *ClientServiceProvider) applyTunnelACL(t *clienttunnel.Tunnel) error {
+	var acl *clienttunnel.TunnelACL
+	if t.Remote.ACL != nil {
+		var err error
+		acl, err = clienttunnel.ParseTunnelACL(*t.Remote.ACL)
+		if err != nil {
+			return err
+		}
+	}
+	if acl != nil {
+		for _, ip := range t.SharedIPs() {
+			acl.AddACL(ip)
+		}
+	}
+
+	t.TunnelProtocol.SetACL(acl)
+	if t.InternalTunnelProxy != nil {
+		t.InternalTunnelProxy.SetACL(acl)
+	}
+	return nil
+}
+
 func (s *ClientServiceProvider) removeCaddyDownstreamProxy(c *clientdata.Client, t *clienttunnel.Tunnel) (err error) {
 	clientLogger := c.Log()
 
@@ -22,6 +22,8 @@ import (
 
 	clientsmigration "github.com/realvnc-labs/rport/db/migration/clients"
 	"github.com/realvnc-labs/rport/db/sqlite"
+	alertingcap "github.com/realvnc-labs/rport/plus/capabilities/alerting"
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/entities/clientupdates"
 	apiErrors "github.com/realvnc-labs/rport/server/api/errors"
 	"github.com/realvnc-labs/rport/server/api/users"
 	"github.com/realvnc-labs/rport/server/caddy"
@@ -29,6 +31,7 @@ import (
 	"github.com/realvnc-labs/rport/server/clients/clientdata"
 	"github.com/realvnc-labs/rport/server/clients/clienttunnel"
 	"github.com/realvnc-labs/rport/server/clientsauth"
+	"github.com/realvnc-labs/rport/server/clientversion"
 	"github.com/realvnc-labs/rport/server/ports"
 	chshare "github.com/realvnc-labs/rport/share"
 	"github.com/realvnc-labs/rport/share/models"
@@ -106,11 +109,64 @@ func TestStartClient(t *testing.T) {
 			_, err := cs.StartClient(
 				context.Background(), tc.ClientAuthID, tc.ClientID, connMock, tc.AuthMultiuseCreds,
 				&chshare.ConnectionRequest{}, testLog)
-			assert.Equal(t, tc.ExpectedError, err)
+			if tc.ExpectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.ExpectedError.Error())
+			}
 		})
 	}
 }
 
+func TestStartClientMinVersion(t *testing.T) {
+	connMock := test.NewConnMock()
+	connMock.ReturnRemoteAddr = &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 2345}
+
+	newService := func(onOutdated string) *ClientServiceProvider {
+		config := &clientversion.Config{MinVersion: "0.9.0", OnOutdated: onOutdated}
+		require.NoError(t, config.Validate())
+		cs := &ClientServiceProvider{
+			repo:            NewClientRepository([]*clientdata.Client{}, nil, testLog),
+			portDistributor: ports.NewPortDistributor(mapset.NewSet()),
+			logger:          testLog,
+		}
+		cs.SetMinClientVersion(config)
+		return cs
+	}
+
+	t.Run("reject", func(t *testing.T) {
+		cs := newService(clientversion.OnOutdatedReject)
+
+		_, err := cs.StartClient(
+			context.Background(), "client-auth-1", "client-1", connMock, false,
+			&chshare.ConnectionRequest{Version: "0.8.0"}, testLog)
+
+		assert.EqualError(t, err, `client version "0.8.0" is outdated, minimum required version is 0.9.0, please update the client`)
+		assert.Equal(t, 0, cs.Count())
+	})
+
+	t.Run("quarantine", func(t *testing.T) {
+		cs := newService(clientversion.OnOutdatedQuarantine)
+
+		client, err := cs.StartClient(
+			context.Background(), "client-auth-1", "client-1", connMock, false,
+			&chshare.ConnectionRequest{Version: "0.8.0"}, testLog)
+		require.NoError(t, err)
+		assert.True(t, client.IsOutdated())
+		assert.True(t, client.IsPaused())
+		assert.Equal(t, clientdata.PausedDueToOutdatedVersion, client.GetPausedReason())
+
+		// updated client
+		client.SetDisconnectedNow()
+		client, err = cs.StartClient(
+			context.Background(), "client-auth-1", "client-1", connMock, false,
+			&chshare.ConnectionRequest{Version: "0.9.0"}, testLog)
+		require.NoError(t, err)
+		assert.False(t, client.IsOutdated())
+		assert.False(t, client.IsPaused())
+	})
+}
+
 // this is a fairly crude concurrency test for start client. currently excluded from the regular test runs as
 // it consumes a moderate amount of memory and takes some time to run. If run, remember to uncomment the t.Skip().
 // go test -count=1 -race -v github.com/realvnc-labs/rport/server/clients -run TestStartClientConcurrency
@@ -223,6 +279,34 @@ func TestStartClientDisconnected(t *testing.T) {
 	assert.Equal(t, 13, client.UpdatesStatus.UpdatesAvailable)
 }
 
+func TestStartClientWithACLRules(t *testing.T) {
+	connMock := test.NewConnMock()
+	connMock.ReturnRemoteAddr = &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 2345}
+	now := time.Now()
+	cs := &ClientServiceProvider{
+		repo: NewClientRepository([]*clientdata.Client{{
+			ID:                "disconnected-client",
+			ClientAuthID:      "customer-b-1",
+			DisconnectedAt:    &now,
+			AllowedUserGroups: []string{"test-group"},
+		}}, nil, testLog),
+		portDistributor: ports.NewPortDistributor(mapset.NewSet()),
+		logger:          testLog,
+	}
+	cs.SetACLRules([]cgroups.ACLRule{
+		{Tag: "customerA", UserGroups: []string{"customerA"}},
+		{ClientAuthID: "customer-b-*", UserGroups: []string{"customerB", "support"}},
+		{Tag: "customerC", ClientAuthID: "customer-b-*", UserGroups: []string{"customerC"}},
+	})
+
+	client, err := cs.StartClient(
+		context.Background(), "customer-b-1", "disconnected-client", connMock, false,
+		&chshare.ConnectionRequest{Name: "new-connection", Version: "0.7.0", Tags: []string{"customerA"}}, testLog)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"customerA", "customerB", "support", "test-group"}, client.GetAllowedUserGroups())
+}
+
 func TestDeleteOfflineClient(t *testing.T) {
 	c1Active := New(t).Logger(testLog).Build()
 	c2Active := New(t).Logger(testLog).Build()
@@ -253,6 +337,7 @@ func TestDeleteOfflineClient(t *testing.T) {
 			wantError: apiErrors.APIError{
 				Message:    fmt.Sprintf("Client with id=%q not found.", "unknown-id"),
 				HTTPStatus: http.StatusNotFound,
+				ErrCode:    apiErrors.ErrCodeClientNotFound,
 			},
 		},
 		{
@@ -289,6 +374,132 @@ func TestDeleteOfflineClient(t *testing.T) {
 	}
 }
 
+func TestDeleteOfflineByFilter(t *testing.T) {
+	c1Active := New(t).Logger(testLog).Build()
+	c2Offline := New(t).DisconnectedDuration(5 * time.Minute).Logger(testLog).Build()
+	c3Offline := New(t).DisconnectedDuration(time.Hour).Logger(testLog).Build()
+	clientService := NewClientService(nil, nil, NewClientRepository([]*clientdata.Client{c1Active, c2Offline, c3Offline}, &hour, testLog), testLog, nil)
+
+	deleted, err := clientService.DeleteOfflineByFilter(admin, nil, nil, clientdata.Now().Add(-10*time.Minute), true)
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	assert.Equal(t, c3Offline.GetID(), deleted[0].GetID())
+	assert.Equal(t, 3, clientService.Count())
+
+	// the client reconnects while the deletion waits for the lock of the client
+	unlock := clientService.clientLocks.lock(c3Offline.GetID())
+	done := make(chan []*clientdata.CalculatedClient)
+	go func() {
+		deleted, err := clientService.DeleteOfflineByFilter(admin, nil, nil, clientdata.Now().Add(-10*time.Minute), false)
+		assert.NoError(t, err)
+		done <- deleted
+	}()
+	time.Sleep(100 * time.Millisecond)
+	c3Offline.SetConnected()
+	unlock()
+
+	assert.Empty(t, <-done)
+	assert.Equal(t, 3, clientService.Count())
+}
+
+type clientUpdatesRecorder struct {
+	alertingcap.Service
+	updates []*clientupdates.Client
+}
+
+func (r *clientUpdatesRecorder) PutClientUpdate(cl *clientupdates.Client) error {
+	r.updates = append(r.updates, cl)
+	return nil
+}
+
+func TestSetClockSkew(t *testing.T) {
+	c1 := New(t).Logger(testLog).Build()
+	clientService := NewClientService(nil, nil, NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil)
+	clientService.SetClockSkewThreshold(30 * time.Second)
+	recorder := &clientUpdatesRecorder{}
+	clientService.SetPlusAlertingServiceCap(recorder)
+
+	require.NoError(t, clientService.SetClockSkew(c1.GetID(), 1500*time.Microsecond))
+	require.NotNil(t, c1.GetClockSkew())
+	assert.Equal(t, 0.002, *c1.GetClockSkew())
+	assert.Empty(t, recorder.updates)
+
+	require.NoError(t, clientService.SetClockSkew(c1.GetID(), -45*time.Second))
+	assert.Equal(t, -45.0, *c1.GetClockSkew())
+	require.Len(t, recorder.updates, 1)
+	assert.True(t, recorder.updates[0].ClockSkewExceeded)
+	assert.Equal(t, -45.0, *recorder.updates[0].ClockSkew)
+
+	// no update while it stays exceeded
+	require.NoError(t, clientService.SetClockSkew(c1.GetID(), 40*time.Second))
+	require.Len(t, recorder.updates, 1)
+
+	require.NoError(t, clientService.SetClockSkew(c1.GetID(), 10*time.Second))
+	require.Len(t, recorder.updates, 2)
+	assert.False(t, recorder.updates[1].ClockSkewExceeded)
+
+	err := clientService.SetClockSkew("unknown-id", time.Second)
+	assert.Error(t, err)
+}
+
+func TestSetAgentStats(t *testing.T) {
+	c1 := New(t).Logger(testLog).Build()
+	clientService := NewClientService(nil, nil, NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil)
+	clientService.SetAgentStatsThresholds(AgentStatsThresholds{RSSBytes: 100 * 1024 * 1024, OpenFDs: 500})
+	recorder := &clientUpdatesRecorder{}
+	clientService.SetPlusAlertingServiceCap(recorder)
+
+	openFDs := 20
+	require.NoError(t, clientService.SetAgentStats(c1.GetID(), &models.AgentStats{RSSBytes: 30 * 1024 * 1024, Goroutines: 5000, OpenFDs: &openFDs}))
+	require.NotNil(t, c1.GetAgentStats())
+	assert.Equal(t, 5000, c1.GetAgentStats().Goroutines)
+	assert.Empty(t, recorder.updates, "goroutines threshold is disabled")
+
+	leakedFDs := 800
+	require.NoError(t, clientService.SetAgentStats(c1.GetID(), &models.AgentStats{RSSBytes: 30 * 1024 * 1024, Goroutines: 10, CPUPercent: 1.5, OpenFDs: &leakedFDs}))
+	require.Len(t, recorder.updates, 1)
+	assert.True(t, recorder.updates[0].AgentStatsExceeded)
+	assert.Equal(t, 800, *recorder.updates[0].AgentOpenFDs)
+	assert.Equal(t, 1.5, *recorder.updates[0].AgentCPUPercent)
+
+	// no update while it stays exceeded
+	require.NoError(t, clientService.SetAgentStats(c1.GetID(), &models.AgentStats{RSSBytes: 200 * 1024 * 1024, Goroutines: 10}))
+	require.Len(t, recorder.updates, 1)
+
+	require.NoError(t, clientService.SetAgentStats(c1.GetID(), &models.AgentStats{RSSBytes: 40 * 1024 * 1024, Goroutines: 10}))
+	require.Len(t, recorder.updates, 2)
+	assert.False(t, recorder.updates[1].AgentStatsExceeded)
+	assert.Nil(t, recorder.updates[1].AgentOpenFDs)
+
+	err := clientService.SetAgentStats("unknown-id", &models.AgentStats{})
+	assert.Error(t, err)
+}
+
+func TestSetAddresses(t *testing.T) {
+	c1 := New(t).Logger(testLog).Build()
+	clientService := NewClientService(nil, nil, NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil)
+	recorder := &clientUpdatesRecorder{}
+	clientService.SetPlusAlertingServiceCap(recorder)
+
+	// same addresses in a different order
+	require.NoError(t, clientService.SetAddresses(c1.GetID(), []string{"192.168.122.111"}, []string{"fe80::b84f:aff:fe59:a0b1"}))
+	assert.Empty(t, c1.GetAddressChanges())
+	assert.Empty(t, recorder.updates)
+
+	require.NoError(t, clientService.SetAddresses(c1.GetID(), []string{"192.168.122.112", "not-an-ip"}, []string{"fe80::b84f:aff:fe59:a0b1"}))
+	assert.Equal(t, []string{"192.168.122.112"}, c1.GetIPv4())
+	changes := c1.GetAddressChanges()
+	require.Len(t, changes, 1)
+	assert.Equal(t, clientdata.AddressChangeSourceHeartbeat, changes[0].Source)
+	assert.Equal(t, []string{"192.168.122.112"}, changes[0].Added)
+	assert.Equal(t, []string{"192.168.122.111"}, changes[0].Removed)
+	require.Len(t, recorder.updates, 1)
+	assert.Equal(t, []string{"192.168.122.112"}, recorder.updates[0].IPv4)
+
+	err := clientService.SetAddresses("unknown-id", nil, nil)
+	assert.Error(t, err)
+}
+
 func TestCheckLocalPort(t *testing.T) {
 	srv := ClientServiceProvider{
 		portDistributor: ports.NewPortDistributorForTests(
@@ -335,6 +546,7 @@ func TestCheckLocalPort(t *testing.T) {
 			wantError: apiErrors.APIError{
 				Message:    "Local port 5 already in use.",
 				HTTPStatus: http.StatusConflict,
+				ErrCode:    apiErrors.ErrCodeLocalPortInUse,
 			},
 		},
 		{
@@ -350,6 +562,7 @@ func TestCheckLocalPort(t *testing.T) {
 			wantError: apiErrors.APIError{
 				Message:    "Local port 5 already in use.",
 				HTTPStatus: http.StatusConflict,
+				ErrCode:    apiErrors.ErrCodeLocalPortInUse,
 			},
 		},
 		{
@@ -987,3 +1200,90 @@ func TestShouldStartTunnelsWithSubdomains(t *testing.T) {
 		})
 	}
 }
+
+func TestCleanupAfterAutoClose(t *testing.T) {
+	c1 := New(t).ID("client-1").ClientAuthID(cl1.ID).Logger(testLog).Build()
+	c1.Context = context.Background()
+	tunnel := &clienttunnel.Tunnel{
+		ID: "1",
+		Remote: models.Remote{
+			Name:               "ssh",
+			Protocol:           models.ProtocolTCP,
+			LocalHost:          models.ZeroHost,
+			LocalPort:          "4000",
+			RemoteHost:         "127.0.0.1",
+			RemotePort:         "22",
+			IdleTimeoutMinutes: 5,
+		},
+	}
+	c1.SetTunnels([]*clienttunnel.Tunnel{tunnel})
+
+	pd := ports.NewPortDistributorForTests(
+		mapset.NewSetFromSlice([]interface{}{4000, 4001}),
+		mapset.NewSetFromSlice([]interface{}{4001}),
+		mapset.NewSetFromSlice([]interface{}{4000, 4001}),
+	)
+	clientService := NewClientService(nil, pd, NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil)
+
+	clientService.cleanupAfterAutoClose(c1, tunnel, clientdata.TunnelCloseReasonIdleTimeout)
+
+	assert.Empty(t, c1.GetTunnels())
+	assert.False(t, pd.IsPortBusy(models.ProtocolTCP, 4000))
+	closures := c1.GetTunnelClosures()
+	require.Len(t, closures, 1)
+	assert.Equal(t, "1", closures[0].TunnelID)
+	assert.Equal(t, "ssh", closures[0].Name)
+	assert.Equal(t, "4000", closures[0].LocalPort)
+	assert.Equal(t, "127.0.0.1:22", closures[0].Remote)
+	assert.Equal(t, clientdata.TunnelCloseReasonIdleTimeout, closures[0].Reason)
+}
+
+func TestClosedTunnelsReleasePorts(t *testing.T) {
+	testCases := []struct {
+		name  string
+		close func(s *ClientServiceProvider, c *clientdata.Client, tunnel *clienttunnel.Tunnel) error
+	}{
+		{
+			name: "terminated",
+			close: func(s *ClientServiceProvider, c *clientdata.Client, tunnel *clienttunnel.Tunnel) error {
+				return s.TerminateTunnel(c, tunnel, true)
+			},
+		},
+		{
+			name: "client disconnected",
+			close: func(s *ClientServiceProvider, c *clientdata.Client, tunnel *clienttunnel.Tunnel) error {
+				return s.Terminate(c)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c1 := New(t).ID("client-1").ClientAuthID(cl1.ID).Logger(testLog).Build()
+			c1.Context = context.Background()
+			tunnel := &clienttunnel.Tunnel{
+				ID: "1",
+				Remote: models.Remote{
+					Protocol:   models.ProtocolTCP,
+					LocalHost:  models.ZeroHost,
+					LocalPort:  "4000",
+					RemoteHost: "127.0.0.1",
+					RemotePort: "22",
+				},
+				TunnelProtocol: &clienttunnel.MultiProtocolTunnel{},
+			}
+			c1.SetTunnels([]*clienttunnel.Tunnel{tunnel})
+
+			pd := ports.NewPortDistributorForTests(
+				mapset.NewSetFromSlice([]interface{}{4000, 4001}),
+				mapset.NewSetFromSlice([]interface{}{4001}),
+				mapset.NewSetFromSlice([]interface{}{4000, 4001}),
+			)
+			clientService := NewClientService(nil, pd, NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil)
+
+			require.NoError(t, tc.close(clientService, c1, tunnel))
+
+			assert.False(t, pd.IsPortBusy(models.ProtocolTCP, 4000))
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package clients
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+)
+
+// clientShardsCount is the number of shards the in-memory client state is split into,
+// so concurrent connects and updates of different clients rarely wait for the same lock.
+const clientShardsCount = 32
+
+type clientShard struct {
+	clients map[string]*clientdata.Client
+	mu      sync.RWMutex
+}
+
+// clientShards is a thread-safe map of clients by client id.
+type clientShards [clientShardsCount]*clientShard
+
+func newClientShards() *clientShards {
+	var shards clientShards
+	for i := range shards {
+		shards[i] = &clientShard{clients: make(map[string]*clientdata.Client)}
+	}
+	return &shards
+}
+
+func (s *clientShards) shard(clientID string) *clientShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(clientID))
+	return s[h.Sum32()%clientShardsCount]
+}
+
+func (s *clientShards) get(clientID string) *clientdata.Client {
+	shard := s.shard(clientID)
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+	return shard.clients[clientID]
+}
+
+func (s *clientShards) set(clientID string, client *clientdata.Client) {
+	s.setAnd(clientID, client, nil)
+}
+
+// setAnd sets the client and calls fn, if not nil, while the shard is still locked. State derived from the client
+// is updated by fn in the same order as the client.
+func (s *clientShards) setAnd(clientID string, client *clientdata.Client, fn func()) {
+	shard := s.shard(clientID)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	shard.clients[clientID] = client
+	if fn != nil {
+		fn()
+	}
+}
+
+func (s *clientShards) delete(clientID string) {
+	s.deleteAnd(clientID, nil)
+}
+
+// deleteAnd deletes the client and calls fn, if not nil, while the shard is still locked, see setAnd.
+func (s *clientShards) deleteAnd(clientID string, fn func()) {
+	shard := s.shard(clientID)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	delete(shard.clients, clientID)
+	if fn != nil {
+		fn()
+	}
+}
+
+// all returns a new array of all clients, each shard is locked only while it's copied.
+func (s *clientShards) all() []*clientdata.Client {
+	res := make([]*clientdata.Client, 0, DefaultInitialClientsArraySize)
+	for _, shard := range s {
+		shard.mu.RLock()
+		for _, c := range shard.clients {
+			res = append(res, c)
+		}
+		shard.mu.RUnlock()
+	}
+	return res
+}
+
+func (s *clientShards) len() int {
+	n := 0
+	for _, shard := range s {
+		shard.mu.RLock()
+		n += len(shard.clients)
+		shard.mu.RUnlock()
+	}
+	return n
+}
+
+// clientLocks are per client id locks to serialize operations on the same client,
+// e.g. concurrent connects with the same client id, without blocking other clients. The zero value is ready to use.
+type clientLocks struct {
+	locks map[string]*clientLock
+	mu    sync.Mutex
+}
+
+type clientLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock locks the given client id and returns the func to unlock it.
+func (l *clientLocks) lock(clientID string) (unlock func()) {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*clientLock)
+	}
+	cl := l.locks[clientID]
+	if cl == nil {
+		cl = &clientLock{}
+		l.locks[clientID] = cl
+	}
+	cl.refs++
+	l.mu.Unlock()
+
+	cl.mu.Lock()
+
+	return func() {
+		cl.mu.Unlock()
+
+		l.mu.Lock()
+		cl.refs--
+		if cl.refs == 0 {
+			delete(l.locks, clientID)
+		}
+		l.mu.Unlock()
+	}
+}
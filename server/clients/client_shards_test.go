@@ -0,0 +1,82 @@
+package clients
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+)
+
+func TestClientShards(t *testing.T) {
+	shards := newClientShards()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("client-%d", i)
+			shards.set(id, &clientdata.Client{ID: id})
+			assert.NotNil(t, shards.get(id))
+			_ = shards.all()
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 100, shards.len())
+	assert.Len(t, shards.all(), 100)
+
+	shards.delete("client-1")
+	assert.Nil(t, shards.get("client-1"))
+	assert.Equal(t, 99, shards.len())
+}
+
+func TestClientLocks(t *testing.T) {
+	var locks clientLocks
+
+	unlock1 := locks.lock("client-1")
+
+	// other clients are not blocked
+	done := make(chan struct{})
+	go func() {
+		unlock2 := locks.lock("client-2")
+		unlock2()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("lock of another client blocked")
+	}
+
+	// the same client is blocked until unlocked
+	locked := make(chan struct{})
+	go func() {
+		unlock := locks.lock("client-1")
+		close(locked)
+		unlock()
+	}()
+	select {
+	case <-locked:
+		t.Fatal("lock of the same client not blocked")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock1()
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Fatal("lock not released")
+	}
+
+	require.Eventually(t, func() bool {
+		locks.mu.Lock()
+		defer locks.mu.Unlock()
+		return len(locks.locks) == 0
+	}, time.Second, 10*time.Millisecond)
+}
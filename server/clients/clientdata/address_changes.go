@@ -0,0 +1,121 @@
+package clientdata
+
+import (
+	"net"
+	"sort"
+	"time"
+)
+
+const (
+	AddressChangeSourceConnect   = "connect"
+	AddressChangeSourceHeartbeat = "heartbeat"
+
+	// MaxAddressChanges is the number of address changes kept per client, older ones are dropped.
+	MaxAddressChanges = 20
+	// MaxReportedAddresses limits the number of ipv4 and ipv6 addresses each reported on heartbeat.
+	MaxReportedAddresses = 256
+)
+
+// AddressChange is a change of the ipv4 and ipv6 addresses reported by a client, e.g. after a dhcp renumbering.
+type AddressChange struct {
+	Timestamp time.Time `json:"timestamp"`
+	// Source is either connect or heartbeat, depending on when the change was detected.
+	Source  string   `json:"source"`
+	IPv4    []string `json:"ipv4"`
+	IPv6    []string `json:"ipv6"`
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// NewAddressChange compares the previous and the current addresses regardless of their order, it returns nil if
+// they are equal.
+func NewAddressChange(source string, prevIPv4, prevIPv6, ipv4, ipv6 []string) *AddressChange {
+	prev := append(append([]string{}, prevIPv4...), prevIPv6...)
+	cur := append(append([]string{}, ipv4...), ipv6...)
+	added := diffAddresses(cur, prev)
+	removed := diffAddresses(prev, cur)
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	return &AddressChange{
+		Timestamp: time.Now().UTC(),
+		Source:    source,
+		IPv4:      ipv4,
+		IPv6:      ipv6,
+		Added:     added,
+		Removed:   removed,
+	}
+}
+
+// ValidAddresses returns the given addresses without the ones not being an ip address, limited to
+// MaxReportedAddresses.
+func ValidAddresses(addrs []string) []string {
+	res := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		if len(res) == MaxReportedAddresses {
+			break
+		}
+		if net.ParseIP(addr) != nil {
+			res = append(res, addr)
+		}
+	}
+	return res
+}
+
+// diffAddresses returns the sorted addresses of a missing in b.
+func diffAddresses(a, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, addr := range b {
+		inB[addr] = true
+	}
+	res := []string{}
+	for _, addr := range a {
+		if !inB[addr] {
+			res = append(res, addr)
+			inB[addr] = true
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// GetAddressChanges returns the recorded address changes, the latest first.
+func (c *Client) GetAddressChanges() []AddressChange {
+	c.flock.RLock()
+	defer c.flock.RUnlock()
+	changes := make([]AddressChange, len(c.AddressChanges))
+	for i := range c.AddressChanges {
+		changes[len(changes)-1-i] = c.AddressChanges[i]
+	}
+	return changes
+}
+
+// SetAddresses sets the current addresses of the client and records the change, it returns nil if the addresses
+// didn't change.
+func (c *Client) SetAddresses(source string, ipv4, ipv6 []string) *AddressChange {
+	c.flock.Lock()
+	defer c.flock.Unlock()
+	change := NewAddressChange(source, c.IPv4, c.IPv6, ipv4, ipv6)
+	if change == nil {
+		return nil
+	}
+	c.IPv4 = ipv4
+	c.IPv6 = ipv6
+	c.recordAddressChange(*change)
+	return change
+}
+
+// AddAddressChange records a change of the addresses already set on the client, e.g. on connect.
+func (c *Client) AddAddressChange(change AddressChange) {
+	c.flock.Lock()
+	defer c.flock.Unlock()
+	c.recordAddressChange(change)
+}
+
+func (c *Client) recordAddressChange(change AddressChange) {
+	c.AddressChanges = append(c.AddressChanges, change)
+	if len(c.AddressChanges) > MaxAddressChanges {
+		c.AddressChanges = c.AddressChanges[len(c.AddressChanges)-MaxAddressChanges:]
+	}
+}
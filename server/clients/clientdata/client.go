@@ -2,6 +2,7 @@ package clientdata
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -74,10 +75,36 @@ type Client struct {
 	// DisconnectedAt is a time when a client was disconnected. If nil - it's connected.
 	DisconnectedAt      *time.Time            `json:"disconnected_at"`
 	LastHeartbeatAt     *time.Time            `json:"last_heartbeat_at"`
+	ClockSkew           *float64              `json:"clock_skew"` // seconds the client clock is ahead, nil if not reported
+	EOLDate             *time.Time            `json:"eol_date"`   // end-of-life date of the os, nil if unknown
+	Outdated            bool                  `json:"outdated"`   // client version is lower than the required minimum
+	AgentStats          *models.AgentStats    `json:"agent_stats"`
 	ClientAuthID        string                `json:"client_auth_id"`
 	AllowedUserGroups   []string              `json:"allowed_user_groups"`
 	UpdatesStatus       *models.UpdatesStatus `json:"updates_status"`
+	Interpreters        []models.Interpreter  `json:"interpreters"` // nil if not reported by the client
 	ClientConfiguration *clientconfig.Config  `json:"client_configuration"`
+	Mode                string                `json:"mode"`
+	// ProtocolVersion and Features are negotiated in the connection handshake, see models.Negotiate.
+	ProtocolVersion int      `json:"protocol_version"`
+	Features        []string `json:"features"`
+	// AutoTags are maintained by the server, e.g. for clients disconnecting often.
+	AutoTags    []string    `json:"auto_tags,omitempty"`
+	Disconnects []time.Time `json:"-"`
+	// AddressChanges are the latest changes of IPv4 and IPv6, available via a separate endpoint.
+	AddressChanges []AddressChange `json:"-"`
+	// TunnelClosures are the latest tunnels closed by the server, available via a separate endpoint.
+	TunnelClosures []TunnelClosure `json:"-"`
+	// ReverseTunnels listen on the client host and forward to targets dialed by the server.
+	ReverseTunnels []*models.ReverseTunnel `json:"-"`
+	// SecuritySnapshot is the latest security snapshot reported by the client, available via a separate endpoint.
+	SecuritySnapshot *models.SecuritySnapshot `json:"-"`
+	// Quarantine is set by an admin to deny all tunnels, jobs and file transfers of a suspicious client.
+	Quarantine *Quarantine `json:"quarantine"`
+	// MonitoringProfile is the monitoring profile pushed by the server, nil if none is assigned.
+	MonitoringProfile *MonitoringProfileState `json:"monitoring_profile"`
+	// OpenProblems summarizes the active problems of the alerting service, refreshed periodically.
+	OpenProblems ProblemsSummary `json:"-"`
 
 	Connection   ssh.Conn        `json:"-"`
 	Context      context.Context `json:"-"`
@@ -89,11 +116,38 @@ type Client struct {
 	flock sync.RWMutex
 }
 
+type Quarantine struct {
+	Reason string    `json:"reason"`
+	By     string    `json:"by"`
+	At     time.Time `json:"at"`
+}
+
+// ProblemsSummary counts the active problems of a client and keeps the highest severity of their rules.
+type ProblemsSummary struct {
+	Count       int
+	MaxSeverity string
+}
+
+// MonitoringProfileState tracks whether the client applied the version of the monitoring profile pushed to it.
+type MonitoringProfileState struct {
+	Name                string     `json:"name"`
+	Version             string     `json:"version"`
+	AcknowledgedVersion string     `json:"acknowledged_version"`
+	AcknowledgedAt      *time.Time `json:"acknowledged_at"`
+	Error               string     `json:"error,omitempty"`
+}
+
+// IsAcknowledged returns true if the client applied the current version of the profile.
+func (s *MonitoringProfileState) IsAcknowledged() bool {
+	return s.AcknowledgedVersion == s.Version
+}
+
 // CalculatedClient contains additional fields and is calculated on each request
 type CalculatedClient struct {
 	*Client
 	Groups          []string        `json:"groups"`
 	ConnectionState ConnectionState `json:"connection_state"`
+	IsEOL           bool            `json:"is_eol"`
 }
 
 func NewCalculatedClient(c *Client, groups []string, connectionState ConnectionState) (cc *CalculatedClient) {
@@ -108,6 +162,49 @@ func (cc *CalculatedClient) GetConnectionState() (cs ConnectionState) {
 	return cc.ConnectionState
 }
 
+func (c *Client) GetAutoTags() (tags []string) {
+	c.flock.RLock()
+	defer c.flock.RUnlock()
+	if c.AutoTags == nil {
+		return nil
+	}
+	tags = make([]string, len(c.AutoTags))
+	copy(tags, c.AutoTags)
+	return tags
+}
+
+func (c *Client) SetAutoTags(tags []string) {
+	c.flock.Lock()
+	c.AutoTags = tags
+	c.flock.Unlock()
+}
+
+func (c *Client) GetOpenProblems() ProblemsSummary {
+	c.flock.RLock()
+	defer c.flock.RUnlock()
+	return c.OpenProblems
+}
+
+func (c *Client) SetOpenProblems(summary ProblemsSummary) {
+	c.flock.Lock()
+	c.OpenProblems = summary
+	c.flock.Unlock()
+}
+
+func (c *Client) GetDisconnects() (disconnects []time.Time) {
+	c.flock.RLock()
+	defer c.flock.RUnlock()
+	disconnects = make([]time.Time, len(c.Disconnects))
+	copy(disconnects, c.Disconnects)
+	return disconnects
+}
+
+func (c *Client) SetDisconnects(disconnects []time.Time) {
+	c.flock.Lock()
+	c.Disconnects = disconnects
+	c.flock.Unlock()
+}
+
 func (c *Client) GetLock() (mu *sync.RWMutex) {
 	return &c.flock
 }
@@ -257,7 +354,7 @@ func (c *Client) GetLabels() (labels map[string]string) {
 func (c *Client) GetIPv4() (ipv4 []string) {
 	c.flock.RLock()
 	defer c.flock.RUnlock()
-	ipv4 = make([]string, 0, len(c.IPv4))
+	ipv4 = make([]string, len(c.IPv4))
 	copy(ipv4, c.IPv4)
 	return ipv4
 }
@@ -265,7 +362,7 @@ func (c *Client) GetIPv4() (ipv4 []string) {
 func (c *Client) GetIPv6() (ipv6 []string) {
 	c.flock.RLock()
 	defer c.flock.RUnlock()
-	ipv6 = make([]string, 0, len(c.IPv6))
+	ipv6 = make([]string, len(c.IPv6))
 	copy(ipv6, c.IPv6)
 	return ipv6
 }
@@ -273,10 +370,44 @@ func (c *Client) GetIPv6() (ipv6 []string) {
 func (c *Client) GetUpdatesStatus() (status models.UpdatesStatus) {
 	c.flock.RLock()
 	defer c.flock.RUnlock()
-	status = *c.UpdatesStatus
+	if c.UpdatesStatus != nil {
+		status = *c.UpdatesStatus
+	}
 	return status
 }
 
+// GetSecuritySnapshot returns the latest security snapshot, nil if the client didn't report one.
+func (c *Client) GetSecuritySnapshot() *models.SecuritySnapshot {
+	c.flock.RLock()
+	defer c.flock.RUnlock()
+	return c.SecuritySnapshot
+}
+
+func (c *Client) GetInterpreters() (interpreters []models.Interpreter) {
+	c.flock.RLock()
+	defer c.flock.RUnlock()
+	if c.Interpreters == nil {
+		return nil
+	}
+	interpreters = make([]models.Interpreter, len(c.Interpreters))
+	copy(interpreters, c.Interpreters)
+	return interpreters
+}
+
+// HasInterpreter returns true if the interpreter of a job is found on the client. The default interpreter
+// and all interpreters of clients not reporting interpreters are considered as available.
+func (c *Client) HasInterpreter(interpreter string) bool {
+	if interpreter == "" {
+		return true
+	}
+	c.flock.RLock()
+	defer c.flock.RUnlock()
+	if c.Interpreters == nil {
+		return true
+	}
+	return models.FindInterpreter(c.Interpreters, interpreter) != nil
+}
+
 func (c *Client) GetDisconnectedAt() (at *time.Time) {
 	c.flock.RLock()
 	defer c.flock.RUnlock()
@@ -313,6 +444,36 @@ func (c *Client) GetLastHeartbeatAtValue() (at time.Time) {
 	return at
 }
 
+func (c *Client) GetClockSkew() (skew *float64) {
+	c.flock.RLock()
+	defer c.flock.RUnlock()
+	return c.ClockSkew
+}
+
+func (c *Client) GetAgentStats() (stats *models.AgentStats) {
+	c.flock.RLock()
+	defer c.flock.RUnlock()
+	return c.AgentStats
+}
+
+func (c *Client) GetEOLDate() (eol *time.Time) {
+	c.flock.RLock()
+	defer c.flock.RUnlock()
+	return c.EOLDate
+}
+
+func (c *Client) IsOutdated() (outdated bool) {
+	c.flock.RLock()
+	defer c.flock.RUnlock()
+	return c.Outdated
+}
+
+// EOLReached returns true if the os of the client reached its end-of-life date at the given time.
+func (c *Client) EOLReached(now time.Time) bool {
+	eol := c.GetEOLDate()
+	return eol != nil && !now.Before(*eol)
+}
+
 func (c *Client) GetConnection() (conn ssh.Conn) {
 	c.flock.RLock()
 	defer c.flock.RUnlock()
@@ -355,6 +516,62 @@ func (c *Client) IsPaused() (paused bool) {
 	return c.Paused
 }
 
+func (c *Client) GetMode() (mode string) {
+	c.flock.RLock()
+	defer c.flock.RUnlock()
+	return c.Mode
+}
+
+func (c *Client) SetMode(mode string) {
+	c.flock.Lock()
+	defer c.flock.Unlock()
+	c.Mode = mode
+}
+
+// HasFeature returns true if the feature was negotiated with the client, so the server can use it.
+func (c *Client) HasFeature(feature string) bool {
+	c.flock.RLock()
+	defer c.flock.RUnlock()
+	for _, f := range c.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCheckInOnly returns true if the client rejects tunnels, commands, scripts and file uploads.
+func (c *Client) IsCheckInOnly() bool {
+	return c.GetMode() == clientconfig.ModeCheckIn
+}
+
+func (c *Client) GetQuarantine() (q *Quarantine) {
+	c.flock.RLock()
+	defer c.flock.RUnlock()
+	return c.Quarantine
+}
+
+// IsQuarantined returns true if the client is quarantined, the control channel is kept but tunnels, commands,
+// scripts and file uploads are denied.
+func (c *Client) IsQuarantined() bool {
+	return c.GetQuarantine() != nil
+}
+
+// CheckQuarantine returns an error with the reason if the client is quarantined.
+func (c *Client) CheckQuarantine() error {
+	q := c.GetQuarantine()
+	if q == nil {
+		return nil
+	}
+	return fmt.Errorf("client is quarantined (reason = %s), release it first", q.Reason)
+}
+
+func (c *Client) GetMonitoringProfile() *MonitoringProfileState {
+	c.flock.RLock()
+	defer c.flock.RUnlock()
+	return c.MonitoringProfile
+}
+
 func (c *Client) GetMonitoringConfig() (monitoringConfig *clientconfig.MonitoringConfig) {
 	c.flock.RLock()
 	defer c.flock.RUnlock()
@@ -447,6 +664,18 @@ func (c *Client) SetUpdatesStatus(status *models.UpdatesStatus) {
 	c.flock.Unlock()
 }
 
+func (c *Client) SetSecuritySnapshot(snapshot *models.SecuritySnapshot) {
+	c.flock.Lock()
+	c.SecuritySnapshot = snapshot
+	c.flock.Unlock()
+}
+
+func (c *Client) SetInterpreters(interpreters []models.Interpreter) {
+	c.flock.Lock()
+	c.Interpreters = interpreters
+	c.flock.Unlock()
+}
+
 func (c *Client) SetDisconnectedAt(at *time.Time) {
 	// TODO: (rs): do we want this log? very noisy when starting a server with many clients.
 	// if at != nil {
@@ -464,7 +693,50 @@ func (c *Client) SetLastHeartbeatAt(at *time.Time) {
 	c.flock.Unlock()
 }
 
-const PausedDueToMaxClientsExceeded = "unlicensed"
+// SetClockSkew sets the difference between the client and the server clock measured on a client to server heartbeat,
+// positive if the client clock is ahead. It's stored in seconds rounded to milliseconds.
+func (c *Client) SetClockSkew(skew time.Duration) {
+	seconds := skew.Round(time.Millisecond).Seconds()
+	c.flock.Lock()
+	c.ClockSkew = &seconds
+	c.flock.Unlock()
+}
+
+// SetAgentStats sets the resource usage of the client process reported on a client to server heartbeat.
+func (c *Client) SetAgentStats(stats *models.AgentStats) {
+	c.flock.Lock()
+	c.AgentStats = stats
+	c.flock.Unlock()
+}
+
+func (c *Client) SetEOLDate(eol *time.Time) {
+	c.flock.Lock()
+	c.EOLDate = eol
+	c.flock.Unlock()
+}
+
+func (c *Client) SetQuarantine(q *Quarantine) {
+	c.flock.Lock()
+	c.Quarantine = q
+	c.flock.Unlock()
+}
+
+func (c *Client) SetMonitoringProfile(state *MonitoringProfileState) {
+	c.flock.Lock()
+	c.MonitoringProfile = state
+	c.flock.Unlock()
+}
+
+func (c *Client) SetOutdated(outdated bool) {
+	c.flock.Lock()
+	c.Outdated = outdated
+	c.flock.Unlock()
+}
+
+const (
+	PausedDueToMaxClientsExceeded = "unlicensed"
+	PausedDueToOutdatedVersion    = "outdated version"
+)
 
 func (c *Client) SetPaused(paused bool, reason string) {
 	c.flock.Lock()
@@ -487,7 +759,12 @@ func (c *Client) SetConnected() {
 
 func (c *Client) SetDisconnectedNow() {
 	now := time.Now()
-	c.SetDisconnectedAt(&now)
+	c.flock.Lock()
+	if c.DisconnectedAt == nil {
+		c.Disconnects = append(c.Disconnects, now)
+	}
+	c.DisconnectedAt = &now
+	c.flock.Unlock()
 }
 
 func (c *Client) SetHeartbeatNow() {
@@ -504,7 +781,9 @@ func (c *Client) ToCalculated(allGroups []*cgroups.ClientGroup) *CalculatedClien
 		}
 	}
 
-	return NewCalculatedClient(c, clientGroups, c.CalculateConnectionState())
+	cc := NewCalculatedClient(c, clientGroups, c.CalculateConnectionState())
+	cc.IsEOL = c.EOLReached(Now())
+	return cc
 }
 
 // Obsolete returns true if a given client was disconnected longer than a given duration.
@@ -603,7 +882,10 @@ func (c *Client) BelongsTo(group *cgroups.ClientGroup) bool {
 		return false
 	}
 
-	if !cgroups.MatchesRawTags(p.Tag, c.Tags) {
+	tags := make([]string, 0, len(c.Tags)+len(c.AutoTags))
+	tags = append(tags, c.Tags...)
+	tags = append(tags, c.AutoTags...)
+	if !cgroups.MatchesRawTags(p.Tag, tags) {
 		return false
 	}
 
@@ -623,6 +905,33 @@ func (c *Client) BelongsTo(group *cgroups.ClientGroup) bool {
 		return false
 	}
 
+	return c.matchesHealthParams(p)
+}
+
+// matchesHealthParams must be called with the read lock held.
+func (c *Client) matchesHealthParams(p *cgroups.ClientParams) bool {
+	if p.UpdatesAvailable != nil || p.SecurityUpdatesAvailable != nil || p.RebootPending != nil {
+		if c.UpdatesStatus == nil {
+			return false
+		}
+		if !p.UpdatesAvailable.Matches(c.UpdatesStatus.UpdatesAvailable) {
+			return false
+		}
+		if !p.SecurityUpdatesAvailable.Matches(c.UpdatesStatus.SecurityUpdatesAvailable) {
+			return false
+		}
+		if p.RebootPending != nil && *p.RebootPending != c.UpdatesStatus.RebootPending {
+			return false
+		}
+	}
+
+	if !p.OpenProblems.Matches(c.OpenProblems.Count) {
+		return false
+	}
+	if p.OpenProblemMinSeverity != nil && cgroups.SeverityRank(c.OpenProblems.MaxSeverity) < cgroups.SeverityRank(*p.OpenProblemMinSeverity) {
+		return false
+	}
+
 	return true
 }
 
@@ -636,7 +945,7 @@ func (c *Client) CalculateConnectionState() ConnectionState {
 // HasAccessViaUserGroups returns true if at least one of given user groups has access to a current client.
 func (c *Client) HasAccessViaUserGroups(userGroups []string) bool {
 	for _, curUserGroup := range userGroups {
-		if curUserGroup == users.Administrators {
+		if curUserGroup == users.Administrators || curUserGroup == users.Auditors {
 			return true
 		}
 		for _, allowedGroup := range c.GetAllowedUserGroups() {
@@ -713,6 +1022,12 @@ func NewClientFromConnRequest(ctx context.Context, existingClient *Client, clien
 	client.Labels = req.Labels
 	client.Version = req.Version
 	client.ClientConfiguration = req.ClientConfiguration
+	client.Mode = req.Mode
+	if client.Mode == "" {
+		// clients without check-in only mode support always run in full mode
+		client.Mode = clientconfig.ModeFull
+	}
+	client.ProtocolVersion, client.Features = models.Negotiate(models.SupportedFeatures, req.Capabilities)
 	client.Address = clientHost
 	client.Tunnels = make([]*clienttunnel.Tunnel, 0)
 	client.DisconnectedAt = nil
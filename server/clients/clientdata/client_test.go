@@ -2,6 +2,8 @@ package clientdata
 
 import (
 	"encoding/json"
+	"fmt"
+	"strconv"
 	"testing"
 	"time"
 
@@ -10,6 +12,7 @@ import (
 
 	"github.com/realvnc-labs/rport/server/api/users"
 	"github.com/realvnc-labs/rport/server/cgroups"
+	"github.com/realvnc-labs/rport/share/models"
 )
 
 func NewTestClient(id string, address string, hostname string, clientAuthID string, connection ssh.Conn) (c *Client) {
@@ -436,3 +439,114 @@ func TestToCalculatedWhenDisconnected(t *testing.T) {
 	assert.Equal(t, client, calculated.Client)
 	assert.Equal(t, "disconnected", string(calculated.ConnectionState))
 }
+
+func TestSetAddresses(t *testing.T) {
+	client := &Client{IPv4: []string{"10.0.0.2", "192.168.1.2"}, IPv6: []string{"fe80::1"}}
+
+	change := client.SetAddresses(AddressChangeSourceHeartbeat, []string{"192.168.1.2", "10.0.0.2"}, []string{"fe80::1"})
+	assert.Nil(t, change)
+
+	change = client.SetAddresses(AddressChangeSourceHeartbeat, []string{"10.0.0.7", "192.168.1.2"}, []string{})
+	assert.Equal(t, []string{"10.0.0.7"}, change.Added)
+	assert.Equal(t, []string{"10.0.0.2", "fe80::1"}, change.Removed)
+	assert.Equal(t, []string{"10.0.0.7", "192.168.1.2"}, client.IPv4)
+
+	for i := 0; i < MaxAddressChanges; i++ {
+		client.SetAddresses(AddressChangeSourceHeartbeat, []string{fmt.Sprintf("10.0.1.%d", i)}, nil)
+	}
+	changes := client.GetAddressChanges()
+	assert.Len(t, changes, MaxAddressChanges)
+	assert.Equal(t, []string{"10.0.1.19"}, changes[0].IPv4)
+	assert.Equal(t, []string{"10.0.1.0"}, changes[MaxAddressChanges-1].IPv4)
+}
+
+func TestTunnelClosures(t *testing.T) {
+	client := &Client{}
+	assert.Empty(t, client.GetTunnelClosures())
+
+	for i := 0; i < MaxTunnelClosures+5; i++ {
+		client.AddTunnelClosure(TunnelClosure{TunnelID: strconv.Itoa(i), Reason: TunnelCloseReasonIdleTimeout})
+	}
+	closures := client.GetTunnelClosures()
+	assert.Len(t, closures, MaxTunnelClosures)
+	assert.Equal(t, "24", closures[0].TunnelID)
+	assert.Equal(t, "5", closures[MaxTunnelClosures-1].TunnelID)
+}
+
+func TestClientBelongsToGroupHealthParams(t *testing.T) {
+	cond := func(s string) *cgroups.NumberCondition {
+		c := cgroups.NumberCondition(s)
+		return &c
+	}
+	severity := func(s string) *string {
+		return &s
+	}
+	reboot := true
+
+	healthy := &Client{
+		ID:            "healthy",
+		UpdatesStatus: &models.UpdatesStatus{UpdatesAvailable: 2},
+	}
+	unhealthy := &Client{
+		ID:            "unhealthy",
+		UpdatesStatus: &models.UpdatesStatus{UpdatesAvailable: 25, SecurityUpdatesAvailable: 3, RebootPending: true},
+		OpenProblems:  ProblemsSummary{Count: 2, MaxSeverity: "Disaster"},
+	}
+	unknown := &Client{
+		ID:           "unknown",
+		OpenProblems: ProblemsSummary{Count: 1, MaxSeverity: "Warning"},
+	}
+
+	testCases := []struct {
+		name        string
+		params      *cgroups.ClientParams
+		wantMatches []string
+	}{
+		{
+			name:        "updates pending",
+			params:      &cgroups.ClientParams{UpdatesAvailable: cond(">10")},
+			wantMatches: []string{"unhealthy"},
+		},
+		{
+			name:        "no updates pending",
+			params:      &cgroups.ClientParams{SecurityUpdatesAvailable: cond("0")},
+			wantMatches: []string{"healthy"},
+		},
+		{
+			name:        "reboot pending",
+			params:      &cgroups.ClientParams{RebootPending: &reboot},
+			wantMatches: []string{"unhealthy"},
+		},
+		{
+			name:        "open problems",
+			params:      &cgroups.ClientParams{OpenProblems: cond(">0")},
+			wantMatches: []string{"unhealthy", "unknown"},
+		},
+		{
+			name:        "open critical problem",
+			params:      &cgroups.ClientParams{OpenProblemMinSeverity: severity("high")},
+			wantMatches: []string{"unhealthy"},
+		},
+		{
+			name: "combined with other params",
+			params: &cgroups.ClientParams{
+				ClientID:     &cgroups.ParamValues{"unknown"},
+				OpenProblems: cond(">=1"),
+			},
+			wantMatches: []string{"unknown"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			group := &cgroups.ClientGroup{ID: "group", Params: tc.params}
+			var gotMatches []string
+			for _, c := range []*Client{healthy, unhealthy, unknown} {
+				if c.BelongsTo(group) {
+					gotMatches = append(gotMatches, c.ID)
+				}
+			}
+			assert.Equal(t, tc.wantMatches, gotMatches)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package clientdata
+
+import (
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+// GetReverseTunnels returns the reverse tunnels of the client.
+func (c *Client) GetReverseTunnels() []*models.ReverseTunnel {
+	c.flock.RLock()
+	defer c.flock.RUnlock()
+	return append([]*models.ReverseTunnel{}, c.ReverseTunnels...)
+}
+
+// FindReverseTunnel returns the reverse tunnel with the given id or nil if not found.
+func (c *Client) FindReverseTunnel(id string) *models.ReverseTunnel {
+	c.flock.RLock()
+	defer c.flock.RUnlock()
+	for _, t := range c.ReverseTunnels {
+		if t.ID == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// FindReverseTunnelByLocal returns the reverse tunnel listening on the given address or nil if not found.
+func (c *Client) FindReverseTunnelByLocal(local string) *models.ReverseTunnel {
+	c.flock.RLock()
+	defer c.flock.RUnlock()
+	for _, t := range c.ReverseTunnels {
+		if t.Local() == local {
+			return t
+		}
+	}
+	return nil
+}
+
+func (c *Client) AddReverseTunnel(t *models.ReverseTunnel) {
+	c.flock.Lock()
+	defer c.flock.Unlock()
+	c.ReverseTunnels = append(c.ReverseTunnels, t)
+}
+
+// RemoveReverseTunnel removes the reverse tunnel with the given id, it returns false if not found.
+func (c *Client) RemoveReverseTunnel(id string) bool {
+	c.flock.Lock()
+	defer c.flock.Unlock()
+	for i, t := range c.ReverseTunnels {
+		if t.ID == id {
+			c.ReverseTunnels = append(c.ReverseTunnels[:i:i], c.ReverseTunnels[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package clientdata
+
+import (
+	"time"
+)
+
+const (
+	// TunnelCloseReasonIdleTimeout is recorded for tunnels closed after no traffic passed for IdleTimeoutMinutes.
+	TunnelCloseReasonIdleTimeout = "idle_timeout"
+	// TunnelCloseReasonAutoClose is recorded for tunnels closed after their AutoClose period.
+	TunnelCloseReasonAutoClose = "auto_close"
+
+	// MaxTunnelClosures is the number of tunnel closures kept per client, older ones are dropped.
+	MaxTunnelClosures = 20
+)
+
+// TunnelClosure records a tunnel closed by the server, e.g. due to the idle timeout.
+type TunnelClosure struct {
+	Timestamp time.Time `json:"timestamp"`
+	TunnelID  string    `json:"tunnel_id"`
+	Name      string    `json:"name"`
+	Protocol  string    `json:"protocol"`
+	LocalPort string    `json:"lport"`
+	Remote    string    `json:"remote"`
+	Reason    string    `json:"reason"`
+}
+
+// GetTunnelClosures returns the recorded tunnel closures, the latest first.
+func (c *Client) GetTunnelClosures() []TunnelClosure {
+	c.flock.RLock()
+	defer c.flock.RUnlock()
+	closures := make([]TunnelClosure, len(c.TunnelClosures))
+	for i := range c.TunnelClosures {
+		closures[len(closures)-1-i] = c.TunnelClosures[i]
+	}
+	return closures
+}
+
+// AddTunnelClosure records a tunnel closed by the server.
+func (c *Client) AddTunnelClosure(closure TunnelClosure) {
+	c.flock.Lock()
+	defer c.flock.Unlock()
+	c.TunnelClosures = append(c.TunnelClosures, closure)
+	if len(c.TunnelClosures) > MaxTunnelClosures {
+		c.TunnelClosures = c.TunnelClosures[len(c.TunnelClosures)-MaxTunnelClosures:]
+	}
+}
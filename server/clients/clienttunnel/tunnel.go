@@ -2,6 +2,8 @@ package clienttunnel
 
 import (
 	"context"
+	"io"
+	"sync"
 	"time"
 
 	"golang.org/x/crypto/ssh"
@@ -13,6 +15,11 @@ import (
 	"github.com/realvnc-labs/rport/share/models"
 )
 
+var (
+	ErrTunnelNotRunning      = errors.New("tunnel is not running")
+	ErrTunnelConnUnsupported = errors.New("tunnel doesn't accept TCP connections")
+)
+
 type TunnelProtocol interface {
 	Start(ctx context.Context) error
 	Terminate(force bool) error
@@ -20,6 +27,44 @@ type TunnelProtocol interface {
 	SetACL(*TunnelACL)
 }
 
+// connServer is implemented by tunnel protocols accepting connections that don't come from the tunnel listener.
+type connServer interface {
+	ServeConn(src io.ReadWriteCloser, source string) error
+}
+
+// ConnRecorder records the traffic of tunnel connections.
+type ConnRecorder interface {
+	RecordConn(conn io.ReadWriteCloser, connID int) (io.ReadWriteCloser, error)
+}
+
+// TrafficHandler is notified about the bytes transferred through a tunnel. in is the traffic from the tunnel user to
+// the client, out the traffic from the client back to the user.
+type TrafficHandler func(in, out int64)
+
+func (h TrafficHandler) add(in, out int64) {
+	if h != nil && (in > 0 || out > 0) {
+		h(in, out)
+	}
+}
+
+// trafficConn notifies the traffic handler about the bytes read from and written to the tunnel user.
+type trafficConn struct {
+	io.ReadWriteCloser
+	onTraffic TrafficHandler
+}
+
+func (c *trafficConn) Read(p []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Read(p)
+	c.onTraffic.add(int64(n), 0)
+	return n, err
+}
+
+func (c *trafficConn) Write(p []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Write(p)
+	c.onTraffic.add(0, int64(n))
+	return n, err
+}
+
 type MultiProtocolTunnel struct {
 	Protocols []TunnelProtocol
 }
@@ -62,6 +107,15 @@ func (mt *MultiProtocolTunnel) SetACL(acl *TunnelACL) {
 	}
 }
 
+func (mt *MultiProtocolTunnel) ServeConn(src io.ReadWriteCloser, source string) error {
+	for _, tp := range mt.Protocols {
+		if s, ok := tp.(connServer); ok {
+			return s.ServeConn(src, source)
+		}
+	}
+	return ErrTunnelConnUnsupported
+}
+
 // TODO(m-terel): Refactor to use separate models for representation and business logic.
 // Tunnel represents active remote proxy connection
 type Tunnel struct {
@@ -72,23 +126,26 @@ type Tunnel struct {
 	TunnelProtocol      `json:"-"`
 	InternalTunnelProxy *InternalTunnelProxy `json:"-"`
 	CreatedAt           time.Time            `json:"created_at"`
+
+	shares   []*TunnelShare
+	sharesMu sync.RWMutex
 }
 
-func NewTunnel(logger *logger.Logger, ssh ssh.Conn, id string, remote models.Remote, acl *TunnelACL) (*Tunnel, error) {
+func NewTunnel(logger *logger.Logger, ssh ssh.Conn, id string, remote models.Remote, acl *TunnelACL, recorder ConnRecorder, onReject ACLRejectHandler, onTraffic TrafficHandler) (*Tunnel, error) {
 	logger = logger.Fork("tunnel#%s:%s", id, remote)
 	logger.Debugf("new tunnel with remote = %#v", remote)
 
 	var tunnelProtocol TunnelProtocol
 	switch remote.Protocol {
 	case models.ProtocolUDP:
-		tunnelProtocol = newTunnelUDP(logger, ssh, remote, acl)
+		tunnelProtocol = newTunnelUDP(logger, ssh, remote, acl, onReject, onTraffic)
 	case models.ProtocolTCP:
-		tunnelProtocol = newTunnelTCP(logger, ssh, remote, acl)
+		tunnelProtocol = newTunnelTCP(logger, ssh, remote, acl, recorder, onReject, onTraffic)
 	case models.ProtocolTCPUDP:
 		tunnelProtocol = &MultiProtocolTunnel{
 			Protocols: []TunnelProtocol{
-				newTunnelTCP(logger, ssh, remote, acl),
-				newTunnelUDP(logger, ssh, remote, acl),
+				newTunnelTCP(logger, ssh, remote, acl, recorder, onReject, onTraffic),
+				newTunnelUDP(logger, ssh, remote, acl, onReject, onTraffic),
 			},
 		}
 	default:
@@ -102,3 +159,13 @@ func NewTunnel(logger *logger.Logger, ssh ssh.Conn, id string, remote models.Rem
 		CreatedAt:      time.Now(),
 	}, nil
 }
+
+// ServeConn pipes the given connection to the tunnel remote like a connection accepted by the tunnel listener.
+// source describes the origin of the connection in the logs. It blocks until the connection is closed.
+func (t *Tunnel) ServeConn(src io.ReadWriteCloser, source string) error {
+	s, ok := t.TunnelProtocol.(connServer)
+	if !ok {
+		return ErrTunnelConnUnsupported
+	}
+	return s.ServeConn(src, source)
+}
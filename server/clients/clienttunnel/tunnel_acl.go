@@ -15,6 +15,15 @@ func (a *TunnelACL) AddACL(aclStr string) {
 	a.AllowedIPs = append(a.AllowedIPs, *lh)
 }
 
+// ACLRejectHandler is notified about connections rejected by a tunnel ACL.
+type ACLRejectHandler func(ip net.IP)
+
+func (h ACLRejectHandler) notify(ip net.IP) {
+	if h != nil {
+		h(ip)
+	}
+}
+
 // CheckAccess returns true if connection from specified address is allowed
 func (a TunnelACL) CheckAccess(ip net.IP) bool {
 	if len(a.AllowedIPs) == 0 {
@@ -103,6 +103,11 @@ func (c *InternalTunnelProxyConfig) validateHost() error {
 	return nil
 }
 
+// CredentialsProvider resolves the vault value injected by the rdp and vnc tunnel proxies at connect time.
+type CredentialsProvider interface {
+	GetTunnelPassword(ctx context.Context, vaultID int, owner string) (string, error)
+}
+
 type InternalTunnelProxy struct {
 	Tunnel               *Tunnel
 	Logger               *logger.Logger
@@ -115,17 +120,21 @@ type InternalTunnelProxy struct {
 	proxyServer          *http.Server
 	tunnelProxyConnector TunnelProxyConnector
 	acme                 *acme.Acme
+	credentials          CredentialsProvider
+	onReject             ACLRejectHandler
 }
 
-func NewInternalTunnelProxy(tunnel *Tunnel, logger *logger.Logger, config *InternalTunnelProxyConfig, host string, port string, acl *TunnelACL, acme *acme.Acme) *InternalTunnelProxy {
+func NewInternalTunnelProxy(tunnel *Tunnel, logger *logger.Logger, config *InternalTunnelProxyConfig, host string, port string, acl *TunnelACL, acme *acme.Acme, credentials CredentialsProvider, onReject ACLRejectHandler) *InternalTunnelProxy {
 	tp := &InternalTunnelProxy{
-		Tunnel:     tunnel,
-		Config:     config,
-		Host:       host,
-		Port:       port,
-		TunnelHost: tunnel.Remote.LocalHost,
-		TunnelPort: tunnel.Remote.LocalPort,
-		acme:       acme,
+		Tunnel:      tunnel,
+		Config:      config,
+		Host:        host,
+		Port:        port,
+		TunnelHost:  tunnel.Remote.LocalHost,
+		TunnelPort:  tunnel.Remote.LocalPort,
+		acme:        acme,
+		credentials: credentials,
+		onReject:    onReject,
 	}
 	tp.SetACL(acl)
 	tp.Logger = logger.Fork("tunnel-proxy:%s", tp.Addr())
@@ -199,6 +208,18 @@ func (tp *InternalTunnelProxy) Stop(ctx context.Context) error {
 	return nil
 }
 
+// injectedPassword returns the password referenced by the tunnel, an empty string if the tunnel has no vault credentials.
+func (tp *InternalTunnelProxy) injectedPassword(ctx context.Context) (string, error) {
+	vaultID := tp.Tunnel.Remote.VaultCredentialsID
+	if vaultID == 0 {
+		return "", nil
+	}
+	if tp.credentials == nil {
+		return "", errors.New("vault credentials are not available")
+	}
+	return tp.credentials.GetTunnelPassword(ctx, vaultID, tp.Tunnel.Remote.Owner)
+}
+
 func (tp *InternalTunnelProxy) Addr() string {
 	return net.JoinHostPort(tp.Host, tp.Port)
 }
@@ -228,6 +249,7 @@ func (tp *InternalTunnelProxy) handleACL(next http.Handler) http.Handler {
 			}
 
 			tp.Logger.Infof("Proxy Access rejected. Remote addr: %s", clientIP)
+			tp.onReject.notify(ipv4)
 		}
 		tp.sendHTML(w, http.StatusForbidden, "Access rejected by ACL")
 	})
@@ -87,6 +87,8 @@ func (tc *TunnelProxyConnectorRDP) serveIndex(w http.ResponseWriter, r *http.Req
 		"isError":          guacError != "",
 		"securityOptions":  CreateOptions(keysSecurity, keysSecurity, selSecurity),
 		"keyboardOptions":  CreateOptions(keysKeyboard, valuesKeyboard, selKeyboard),
+		"passwordInjected": tc.tunnelProxy.Tunnel.Remote.VaultCredentialsID != 0,
+		"banner":           tc.tunnelProxy.Tunnel.Remote.Banner,
 	}
 
 	tc.tunnelProxy.serveTemplate(w, r, guacIndexHTML, templateData)
@@ -143,6 +145,15 @@ func (tc *TunnelProxyConnectorRDP) connectToGuacamole(r *http.Request) (guac.Tun
 	}
 	tc.guacTokenStore.Delete(token)
 
+	injectedPassword, err := tc.tunnelProxy.injectedPassword(r.Context())
+	if err != nil {
+		tc.tunnelProxy.Logger.Errorf("Cannot get vault credentials: %v", err)
+		return nil, err
+	}
+	if injectedPassword != "" {
+		guacToken.password = injectedPassword
+	}
+
 	config.Parameters[queryParSecurity] = guacToken.security
 	config.Parameters[queryParUsername] = guacToken.username
 	config.Parameters[queryParPassword] = guacToken.password
@@ -55,6 +55,7 @@ func (tc *TunnelProxyConnectorVNC) serveIndex(w http.ResponseWriter, r *http.Req
 		"noURLPassword":   true,
 		"defaultViewOnly": false,
 		"params":          novncParamsMap,
+		"banner":          tc.tunnelProxy.Tunnel.Remote.Banner,
 	}
 
 	tc.tunnelProxy.serveTemplate(w, r, indexHTML, templateData)
@@ -87,6 +88,12 @@ func (tc *TunnelProxyConnectorVNC) serveVNC(w http.ResponseWriter, r *http.Reque
 			}
 
 			if err == nil {
+				if err = tc.injectCredentials(r, p); err != nil {
+					tc.tunnelProxy.Logger.Errorf("failed to inject vault credentials: %v", err)
+					_ = wsConn.WriteMessage(websocket.CloseMessage, []byte("could not inject vault credentials"))
+					p.Teardown()
+					return
+				}
 				go p.Start()
 				return
 			}
@@ -94,3 +101,14 @@ func (tc *TunnelProxyConnectorVNC) serveVNC(w http.ResponseWriter, r *http.Reque
 	}
 	_ = wsConn.WriteMessage(websocket.CloseMessage, []byte("could not start websocket tcp proxy"))
 }
+
+func (tc *TunnelProxyConnectorVNC) injectCredentials(r *http.Request, p *WebsocketTCPProxy) error {
+	if tc.tunnelProxy.Tunnel.Remote.VaultCredentialsID == 0 {
+		return nil
+	}
+	password, err := tc.tunnelProxy.injectedPassword(r.Context())
+	if err != nil {
+		return err
+	}
+	return p.InjectVNCAuth(password)
+}
@@ -0,0 +1,114 @@
+package clienttunnel
+
+import (
+	"time"
+)
+
+// TunnelShare grants users and user groups other than the owner access to a tunnel for the lifetime of the tunnel.
+type TunnelShare struct {
+	ID        string    `json:"id"`
+	Users     []string  `json:"users"`
+	Groups    []string  `json:"groups"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	// AllowedIPs are added to the tunnel ACL by the users of the share to connect to the tunnel
+	AllowedIPs []string `json:"allowed_ips"`
+}
+
+// Includes returns true if the share grants access to the given user.
+func (s *TunnelShare) Includes(username string, groups []string) bool {
+	for _, u := range s.Users {
+		if u == username {
+			return true
+		}
+	}
+	for _, g := range s.Groups {
+		for _, userGroup := range groups {
+			if g == userGroup {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *TunnelShare) clone() *TunnelShare {
+	c := *s
+	c.Users = append([]string(nil), s.Users...)
+	c.Groups = append([]string(nil), s.Groups...)
+	c.AllowedIPs = append([]string(nil), s.AllowedIPs...)
+	return &c
+}
+
+// AddShare shares the tunnel, the shares are dropped when the tunnel is closed.
+func (t *Tunnel) AddShare(share *TunnelShare) {
+	t.sharesMu.Lock()
+	defer t.sharesMu.Unlock()
+	t.shares = append(t.shares, share.clone())
+}
+
+// Shares returns copies of all shares of the tunnel.
+func (t *Tunnel) Shares() []*TunnelShare {
+	t.sharesMu.RLock()
+	defer t.sharesMu.RUnlock()
+	shares := make([]*TunnelShare, 0, len(t.shares))
+	for _, s := range t.shares {
+		shares = append(shares, s.clone())
+	}
+	return shares
+}
+
+// GetShare returns a copy of the share with the given id or nil if not found.
+func (t *Tunnel) GetShare(id string) *TunnelShare {
+	t.sharesMu.RLock()
+	defer t.sharesMu.RUnlock()
+	for _, s := range t.shares {
+		if s.ID == id {
+			return s.clone()
+		}
+	}
+	return nil
+}
+
+// RemoveShare removes the share with the given id and returns false if not found.
+func (t *Tunnel) RemoveShare(id string) bool {
+	t.sharesMu.Lock()
+	defer t.sharesMu.Unlock()
+	for i, s := range t.shares {
+		if s.ID == id {
+			t.shares = append(t.shares[:i], t.shares[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// AllowShareIP adds the ip to the allowed IPs of the share and returns false if the share is not found.
+func (t *Tunnel) AllowShareIP(id string, ip string) bool {
+	t.sharesMu.Lock()
+	defer t.sharesMu.Unlock()
+	for _, s := range t.shares {
+		if s.ID != id {
+			continue
+		}
+		for _, allowed := range s.AllowedIPs {
+			if allowed == ip {
+				return true
+			}
+		}
+		s.AllowedIPs = append(s.AllowedIPs, ip)
+		return true
+	}
+	return false
+}
+
+// SharedIPs returns the IPs allowed by all shares of the tunnel.
+func (t *Tunnel) SharedIPs() []string {
+	t.sharesMu.RLock()
+	defer t.sharesMu.RUnlock()
+	var ips []string
+	for _, s := range t.shares {
+		ips = append(ips, s.AllowedIPs...)
+	}
+	return ips
+}
@@ -0,0 +1,37 @@
+package clienttunnel_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/realvnc-labs/rport/server/clients/clienttunnel"
+)
+
+func TestTunnelShares(t *testing.T) {
+	tunnel := &clienttunnel.Tunnel{}
+	tunnel.AddShare(&clienttunnel.TunnelShare{ID: "share-1", Users: []string{"alice"}})
+	tunnel.AddShare(&clienttunnel.TunnelShare{ID: "share-2", Groups: []string{"devs"}})
+
+	shares := tunnel.Shares()
+	assert.Len(t, shares, 2)
+	assert.True(t, shares[0].Includes("alice", nil))
+	assert.False(t, shares[0].Includes("bob", []string{"devs"}))
+	assert.True(t, shares[1].Includes("bob", []string{"ops", "devs"}))
+	assert.False(t, shares[1].Includes("alice", []string{"ops"}))
+
+	assert.True(t, tunnel.AllowShareIP("share-1", "192.0.2.10"))
+	assert.True(t, tunnel.AllowShareIP("share-1", "192.0.2.10"))
+	assert.True(t, tunnel.AllowShareIP("share-2", "192.0.2.20"))
+	assert.False(t, tunnel.AllowShareIP("unknown", "192.0.2.30"))
+	assert.Equal(t, []string{"192.0.2.10", "192.0.2.20"}, tunnel.SharedIPs())
+
+	// copies are returned
+	shares[0].AllowedIPs = append(shares[0].AllowedIPs, "192.0.2.40")
+	assert.Equal(t, []string{"192.0.2.10"}, tunnel.GetShare("share-1").AllowedIPs)
+
+	assert.True(t, tunnel.RemoveShare("share-1"))
+	assert.False(t, tunnel.RemoveShare("share-1"))
+	assert.Nil(t, tunnel.GetShare("share-1"))
+	assert.Equal(t, []string{"192.0.2.20"}, tunnel.SharedIPs())
+}
@@ -22,20 +22,28 @@ type tunnelTCP struct {
 	lastConnClose int64 // time stored as int64 so it can be used with atomic
 	*logger.Logger
 	models.Remote
-	sshConn ssh.Conn
-	acl     atomic.Pointer[TunnelACL] // parsed Remote.ACL field
-
+	sshConn   ssh.Conn
+	acl       atomic.Pointer[TunnelACL] // parsed Remote.ACL field
+	recorder  ConnRecorder
+	onReject  ACLRejectHandler
+	onTraffic TrafficHandler
+
+	mu                        sync.Mutex // guards ctx and stopFn, they are set by Start and read by ServeConn
+	ctx                       context.Context
 	stopFn                    func()
-	connectionIDAutoIncrement int
+	connectionIDAutoIncrement int32
 	connCount                 int32
 	wg                        sync.WaitGroup // TODO: verify whether wait group is needed here
 }
 
-func newTunnelTCP(logger *logger.Logger, ssh ssh.Conn, remote models.Remote, acl *TunnelACL) *tunnelTCP {
+func newTunnelTCP(logger *logger.Logger, ssh ssh.Conn, remote models.Remote, acl *TunnelACL, recorder ConnRecorder, onReject ACLRejectHandler, onTraffic TrafficHandler) *tunnelTCP {
 	t := &tunnelTCP{
-		Logger:  logger,
-		Remote:  remote,
-		sshConn: ssh,
+		Logger:    logger,
+		Remote:    remote,
+		sshConn:   ssh,
+		recorder:  recorder,
+		onReject:  onReject,
+		onTraffic: onTraffic,
 	}
 	t.SetACL(acl)
 	return t
@@ -51,7 +59,11 @@ func (t *tunnelTCP) Start(ctx context.Context) error {
 		return fmt.Errorf("%s: %s", t.Logger.Prefix(), err)
 	}
 
-	ctx, t.stopFn = context.WithCancel(ctx)
+	ctx, stopFn := context.WithCancel(ctx)
+	t.mu.Lock()
+	t.ctx = ctx
+	t.stopFn = stopFn
+	t.mu.Unlock()
 	t.wg.Add(1)
 	go t.listen(ctx, l)
 	return nil
@@ -62,14 +74,19 @@ func (t *tunnelTCP) Terminate(force bool) error {
 	if !force && n > 0 {
 		return fmt.Errorf("tunnel has %d active connection(s)", n)
 	}
-	if t.stopFn == nil {
+	t.mu.Lock()
+	stopFn := t.stopFn
+	t.stopFn = nil
+	if stopFn != nil {
+		stopFn()
+	}
+	t.mu.Unlock()
+	if stopFn == nil {
 		return nil
 	}
 
-	t.stopFn()
 	t.wg.Wait()
 	t.Infof("stopped")
-	t.stopFn = nil
 	return nil
 }
 
@@ -115,6 +132,7 @@ func (t *tunnelTCP) listen(ctx context.Context, l net.Listener) {
 
 			if !acl.CheckAccess(tcpAddr.IP) {
 				t.Debugf("Access rejected. Remote addr: %s", tcpAddr)
+				t.onReject.notify(tcpAddr.IP)
 				conn.Close()
 				continue
 			}
@@ -122,7 +140,7 @@ func (t *tunnelTCP) listen(ctx context.Context, l net.Listener) {
 
 		t.wg.Add(1)
 		go func() {
-			t.accept(ctx, conn)
+			t.accept(ctx, conn, conn.RemoteAddr().String())
 			t.wg.Done()
 			atomic.StoreInt64(&t.lastConnClose, time.Now().Unix())
 		}()
@@ -136,16 +154,37 @@ func (t *tunnelTCP) LastActive() time.Time {
 	return time.Unix(atomic.LoadInt64(&t.lastConnClose), 0)
 }
 
-func (t *tunnelTCP) accept(ctx context.Context, src io.ReadWriteCloser) {
+// ServeConn pipes a connection that was accepted outside of the tunnel listener, e.g. proxied by the API, to the
+// tunnel remote. The tunnel ACL is not checked, the caller is responsible for authorizing the connection.
+func (t *tunnelTCP) ServeConn(src io.ReadWriteCloser, source string) error {
+	// the connection is added to the wait group while holding the lock, so Terminate can't cancel the context and wait
+	// in between
+	t.mu.Lock()
+	ctx := t.ctx
+	if ctx == nil || ctx.Err() != nil {
+		t.mu.Unlock()
+		return ErrTunnelNotRunning
+	}
+	t.wg.Add(1)
+	t.mu.Unlock()
+
+	defer func() {
+		t.wg.Done()
+		atomic.StoreInt64(&t.lastConnClose, time.Now().Unix())
+	}()
+	t.accept(ctx, src, source)
+	return nil
+}
+
+func (t *tunnelTCP) accept(ctx context.Context, src io.ReadWriteCloser, source string) {
 	defer src.Close()
-	t.connectionIDAutoIncrement++
 	atomic.AddInt32(&t.connCount, 1)
 	defer atomic.AddInt32(&t.connCount, -1)
 
-	cid := t.connectionIDAutoIncrement
+	cid := int(atomic.AddInt32(&t.connectionIDAutoIncrement, 1))
 	l := t.Fork("conn#%d", cid)
 
-	l.Debugf("Accept")
+	l.Debugf("Accept from %s", source)
 
 	done := make(chan bool)
 	// link ctx to conn
@@ -175,8 +214,22 @@ func (t *tunnelTCP) accept(ctx context.Context, src io.ReadWriteCloser) {
 	l.Debugf("from %+v", t.sshConn.RemoteAddr())
 
 	go ssh.DiscardRequests(reqs)
+
+	var conn io.ReadWriteCloser = src
+	if t.recorder != nil {
+		conn, err = t.recorder.RecordConn(src, cid)
+		if err != nil {
+			l.Errorf("Could not start session recording: %v", err)
+			dst.Close()
+			return
+		}
+	}
+	if t.onTraffic != nil {
+		conn = &trafficConn{ReadWriteCloser: conn, onTraffic: t.onTraffic}
+	}
+
 	//then pipe
-	s, r := chshare.Pipe(src, dst)
+	s, r := chshare.Pipe(conn, dst)
 	l.Debugf("Close (sent %s received %s)", sizestr.ToString(s), sizestr.ToString(r))
 	close(done)
 }
@@ -0,0 +1,65 @@
+package clienttunnel
+
+import (
+	"context"
+	"net"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func TestTunnelServeConn(t *testing.T) {
+	testLog := logger.NewLogger("tunnel-test", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+
+	udpTunnel, err := NewTunnel(testLog, nil, "1", models.Remote{Protocol: models.ProtocolUDP}, nil, nil, nil, nil)
+	require.NoError(t, err)
+	src, _ := net.Pipe()
+	assert.ErrorIs(t, udpTunnel.ServeConn(src, "test"), ErrTunnelConnUnsupported)
+
+	tcpTunnel, err := NewTunnel(testLog, nil, "2", models.Remote{Protocol: models.ProtocolTCP, LocalHost: "127.0.0.1", LocalPort: "0"}, nil, nil, nil, nil)
+	require.NoError(t, err)
+	assert.ErrorIs(t, tcpTunnel.ServeConn(src, "test"), ErrTunnelNotRunning)
+
+	require.NoError(t, tcpTunnel.Start(context.Background()))
+	// without ssh connection the connection is closed right away
+	assert.NoError(t, tcpTunnel.ServeConn(src, "test"))
+	_, err = src.Write([]byte("abc"))
+	assert.Error(t, err)
+
+	require.NoError(t, tcpTunnel.Terminate(false))
+	assert.ErrorIs(t, tcpTunnel.ServeConn(src, "test"), ErrTunnelNotRunning)
+}
+
+func TestTunnelServeConnConcurrentStartAndTerminate(t *testing.T) {
+	testLog := logger.NewLogger("tunnel-test", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+	tcpTunnel, err := NewTunnel(testLog, nil, "1", models.Remote{Protocol: models.ProtocolTCP, LocalHost: "127.0.0.1", LocalPort: "0"}, nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	// run with -race, connections are served by the API while the tunnel is started and terminated
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				src, _ := net.Pipe()
+				err := tcpTunnel.ServeConn(src, "test")
+				if err != nil {
+					assert.ErrorIs(t, err, ErrTunnelNotRunning)
+					src.Close()
+				}
+			}
+		}()
+	}
+	require.NoError(t, tcpTunnel.Start(context.Background()))
+	require.NoError(t, tcpTunnel.Terminate(true))
+	wg.Wait()
+
+	assert.ErrorIs(t, tcpTunnel.ServeConn(nil, "test"), ErrTunnelNotRunning)
+}
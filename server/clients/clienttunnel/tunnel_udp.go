@@ -23,6 +23,8 @@ type tunnelUDP struct {
 	sshConn     ssh.Conn
 	acl         atomic.Pointer[TunnelACL] // parsed Remote.ACL field
 	idleTimeout time.Duration
+	onReject    ACLRejectHandler
+	onTraffic   TrafficHandler
 
 	conn    *net.UDPConn
 	channel *comm.UDPChannel
@@ -33,7 +35,7 @@ type tunnelUDP struct {
 	lastActive time.Time
 }
 
-func newTunnelUDP(logger *logger.Logger, ssh ssh.Conn, remote models.Remote, acl *TunnelACL) *tunnelUDP {
+func newTunnelUDP(logger *logger.Logger, ssh ssh.Conn, remote models.Remote, acl *TunnelACL, onReject ACLRejectHandler, onTraffic TrafficHandler) *tunnelUDP {
 	t := &tunnelUDP{
 		Logger:      logger,
 		Remote:      remote,
@@ -41,6 +43,8 @@ func newTunnelUDP(logger *logger.Logger, ssh ssh.Conn, remote models.Remote, acl
 		done:        make(chan struct{}),
 		lastActive:  time.Now(),
 		idleTimeout: time.Duration(remote.IdleTimeoutMinutes) * time.Minute,
+		onReject:    onReject,
+		onTraffic:   onTraffic,
 	}
 	t.SetACL(acl)
 	return t
@@ -124,6 +128,7 @@ func (t *tunnelUDP) runInbound(ctx context.Context) error {
 		if acl != nil {
 			if !acl.CheckAccess(sourceAddr.IP) {
 				t.Debugf("Access rejected. Remote addr: %s", sourceAddr)
+				t.onReject.notify(sourceAddr.IP)
 				continue
 			}
 		}
@@ -132,6 +137,7 @@ func (t *tunnelUDP) runInbound(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
+		t.onTraffic.add(int64(n), 0)
 	}
 }
 
@@ -153,10 +159,11 @@ func (t *tunnelUDP) runOutbound(ctx context.Context) error {
 
 		t.setLastActive()
 
-		_, err = t.conn.WriteToUDP(data, addr)
+		n, err := t.conn.WriteToUDP(data, addr)
 		if err != nil {
 			return err
 		}
+		t.onTraffic.add(0, int64(n))
 	}
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net"
 	"os"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -20,7 +21,11 @@ func TestTunnelUDP(t *testing.T) {
 	udpReadTimeout = time.Millisecond
 	remote := models.Remote{}
 	logger := logger.NewLogger("udp-handler-test", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
-	tunnel := newTunnelUDP(logger, nil, remote, nil)
+	var in, out atomic.Int64
+	tunnel := newTunnelUDP(logger, nil, remote, nil, nil, func(i, o int64) {
+		in.Add(i)
+		out.Add(o)
+	})
 	serverChannel, clientChannel := test.NewMockChannel()
 	channel := comm.NewUDPChannel(clientChannel)
 	err := tunnel.start(context.Background(), serverChannel)
@@ -50,6 +55,10 @@ func TestTunnelUDP(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.WithinDuration(t, time.Now(), tunnel.LastActive(), 10*time.Millisecond)
+	assert.Equal(t, int64(3), in.Load())
+	assert.Eventually(t, func() bool {
+		return out.Load() == 3
+	}, time.Second, time.Millisecond)
 }
 
 func TestTunnelUDPWithACL(t *testing.T) {
@@ -58,7 +67,10 @@ func TestTunnelUDPWithACL(t *testing.T) {
 	logger := logger.NewLogger("udp-handler-test", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
 	acl, err := ParseTunnelACL("127.0.0.2")
 	require.NoError(t, err)
-	tunnel := newTunnelUDP(logger, nil, remote, acl)
+	rejected := make(chan net.IP, 2)
+	tunnel := newTunnelUDP(logger, nil, remote, acl, func(ip net.IP) {
+		rejected <- ip
+	}, nil)
 	serverChannel, clientChannel := test.NewMockChannel()
 	channel := comm.NewUDPChannel(clientChannel)
 	local1, err := net.ResolveUDPAddr("udp", "127.0.0.1:0")
@@ -85,6 +97,7 @@ func TestTunnelUDPWithACL(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, []byte("def"), data)
 	assert.Equal(t, conn.LocalAddr(), addr)
+	assert.Equal(t, "127.0.0.1", (<-rejected).String())
 
 	// update ACL
 	acl2, err := ParseTunnelACL("127.0.0.1")
@@ -107,4 +120,5 @@ func TestTunnelUDPWithACL(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, []byte("def"), data)
 	assert.Equal(t, conn.LocalAddr(), addr)
+	assert.Equal(t, "127.0.0.2", (<-rejected).String())
 }
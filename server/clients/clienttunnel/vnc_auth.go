@@ -0,0 +1,235 @@
+package clienttunnel
+
+import (
+	"bytes"
+	"crypto/des" //nolint:gosec // DES is mandated by vnc authentication
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// RFB security types, see RFC 6143 section 7.1.2
+const (
+	rfbSecurityInvalid = 0
+	rfbSecurityNone    = 1
+	rfbSecurityVNCAuth = 2
+)
+
+const vncAuthTimeout = 10 * time.Second
+
+// InjectVNCAuth authenticates at the vnc server with the given password and offers the browser the security type None
+// instead, so the password never reaches the browser. It must be called after Dial and before Start.
+func (p *WebsocketTCPProxy) InjectVNCAuth(password string) error {
+	deadline := time.Now().Add(vncAuthTimeout)
+	_ = p.tcpConn.SetDeadline(deadline)
+	_ = p.wsConn.SetReadDeadline(deadline)
+	defer func() {
+		_ = p.tcpConn.SetDeadline(time.Time{})
+		_ = p.wsConn.SetReadDeadline(time.Time{})
+	}()
+
+	browser := &websocketReadWriter{conn: p.wsConn}
+	err := injectVNCAuth(p.tcpConn, browser, password)
+	if err != nil {
+		return err
+	}
+
+	// forward what the browser already sent after the handshake
+	if len(browser.buf) > 0 {
+		_, err = p.tcpConn.Write(browser.buf)
+	}
+	return err
+}
+
+func injectVNCAuth(server io.ReadWriter, browser io.ReadWriter, password string) error {
+	minor, err := readRFBVersion(server)
+	if err != nil {
+		return fmt.Errorf("vnc server: %w", err)
+	}
+	if minor < 7 {
+		return fmt.Errorf("vnc server protocol version 3.%d does not support credential injection", minor)
+	}
+	if minor > 8 {
+		minor = 8
+	}
+	version := []byte(fmt.Sprintf("RFB 003.%03d\n", minor))
+
+	if _, err = browser.Write(version); err != nil {
+		return err
+	}
+	browserMinor, err := readRFBVersion(browser)
+	if err != nil {
+		return fmt.Errorf("vnc browser client: %w", err)
+	}
+	if browserMinor != minor {
+		return fmt.Errorf("vnc browser client requested unsupported protocol version 3.%d", browserMinor)
+	}
+	if _, err = server.Write(version); err != nil {
+		return err
+	}
+
+	securityType, err := readSecurityType(server)
+	if err != nil {
+		return err
+	}
+
+	if _, err = browser.Write([]byte{1, rfbSecurityNone}); err != nil {
+		return err
+	}
+	selected := make([]byte, 1)
+	if _, err = io.ReadFull(browser, selected); err != nil {
+		return err
+	}
+	if selected[0] != rfbSecurityNone {
+		return fmt.Errorf("vnc browser client selected unsupported security type %d", selected[0])
+	}
+
+	if _, err = server.Write([]byte{securityType}); err != nil {
+		return err
+	}
+	if securityType == rfbSecurityVNCAuth {
+		challenge := make([]byte, 16)
+		if _, err = io.ReadFull(server, challenge); err != nil {
+			return err
+		}
+		response, err := vncAuthResponse(challenge, password)
+		if err != nil {
+			return err
+		}
+		if _, err = server.Write(response); err != nil {
+			return err
+		}
+	}
+
+	// version 3.7 sends no security result for the security type None
+	if minor == 7 && securityType == rfbSecurityNone {
+		return nil
+	}
+
+	result := make([]byte, 4)
+	if _, err = io.ReadFull(server, result); err != nil {
+		return err
+	}
+	if binary.BigEndian.Uint32(result) != 0 {
+		reason := "vnc authentication failed"
+		if minor == 8 {
+			if r, err := readReason(server); err == nil {
+				reason = r
+			}
+			_, _ = browser.Write(append(result, encodeReason(reason)...))
+		}
+		return errors.New(reason)
+	}
+	if minor == 8 {
+		_, err = browser.Write(result)
+	}
+	return err
+}
+
+func readRFBVersion(r io.Reader) (int, error) {
+	buf := make([]byte, 12)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return 0, err
+	}
+	var major, minor int
+	if _, err := fmt.Sscanf(string(buf), "RFB %03d.%03d\n", &major, &minor); err != nil || major != 3 {
+		return 0, fmt.Errorf("invalid protocol version %q", buf)
+	}
+	return minor, nil
+}
+
+// readSecurityType returns VNC authentication if offered by the server, None otherwise.
+func readSecurityType(server io.Reader) (byte, error) {
+	count := make([]byte, 1)
+	if _, err := io.ReadFull(server, count); err != nil {
+		return 0, err
+	}
+	if count[0] == rfbSecurityInvalid {
+		reason, err := readReason(server)
+		if err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("vnc server refused connection: %s", reason)
+	}
+
+	types := make([]byte, count[0])
+	if _, err := io.ReadFull(server, types); err != nil {
+		return 0, err
+	}
+	switch {
+	case bytes.IndexByte(types, rfbSecurityVNCAuth) >= 0:
+		return rfbSecurityVNCAuth, nil
+	case bytes.IndexByte(types, rfbSecurityNone) >= 0:
+		return rfbSecurityNone, nil
+	}
+	return 0, fmt.Errorf("vnc server does not support password authentication, offered security types: %v", types)
+}
+
+func readReason(r io.Reader) (string, error) {
+	size := make([]byte, 4)
+	if _, err := io.ReadFull(r, size); err != nil {
+		return "", err
+	}
+	reason := make([]byte, binary.BigEndian.Uint32(size))
+	if _, err := io.ReadFull(r, reason); err != nil {
+		return "", err
+	}
+	return string(reason), nil
+}
+
+func encodeReason(reason string) []byte {
+	buf := make([]byte, 4, 4+len(reason))
+	binary.BigEndian.PutUint32(buf, uint32(len(reason)))
+	return append(buf, reason...)
+}
+
+// vncAuthResponse encrypts the challenge with DES using the first 8 bytes of the password as key, with the bits of each
+// key byte reversed as required by vnc authentication.
+func vncAuthResponse(challenge []byte, password string) ([]byte, error) {
+	key := make([]byte, 8)
+	copy(key, password)
+	for i, b := range key {
+		key[i] = bits.Reverse8(b)
+	}
+
+	cipher, err := des.NewCipher(key) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	response := make([]byte, len(challenge))
+	for i := 0; i < len(challenge); i += des.BlockSize {
+		cipher.Encrypt(response[i:i+des.BlockSize], challenge[i:i+des.BlockSize])
+	}
+	return response, nil
+}
+
+// websocketReadWriter reads the websocket messages as a stream, unread bytes of a message are kept in buf.
+type websocketReadWriter struct {
+	conn *websocket.Conn
+	buf  []byte
+}
+
+func (rw *websocketReadWriter) Read(p []byte) (int, error) {
+	for len(rw.buf) == 0 {
+		_, data, err := rw.conn.ReadMessage()
+		if err != nil {
+			return 0, err
+		}
+		rw.buf = data
+	}
+	n := copy(p, rw.buf)
+	rw.buf = rw.buf[n:]
+	return n, nil
+}
+
+func (rw *websocketReadWriter) Write(p []byte) (int, error) {
+	if err := rw.conn.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,125 @@
+package clienttunnel
+
+import (
+	"encoding/hex"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVNCAuthResponse(t *testing.T) {
+	challenge, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+
+	response, err := vncAuthResponse(challenge, "password")
+	require.NoError(t, err)
+	assert.Equal(t, "b866924125c8eebb9debc1db61c538e2", hex.EncodeToString(response))
+
+	// only the first 8 bytes of the password are used
+	longResponse, err := vncAuthResponse(challenge, "password-too-long")
+	require.NoError(t, err)
+	assert.Equal(t, response, longResponse)
+}
+
+func TestInjectVNCAuth(t *testing.T) {
+	testCases := []struct {
+		name           string
+		serverVersion  string
+		serverPassword string
+		wantErr        string
+		wantBrowser    []byte
+	}{
+		{
+			name:           "success",
+			serverVersion:  "RFB 003.008\n",
+			serverPassword: "secret",
+			wantBrowser:    []byte{0, 0, 0, 0},
+		},
+		{
+			name:           "wrong password",
+			serverVersion:  "RFB 003.008\n",
+			serverPassword: "other",
+			wantErr:        "authentication failed",
+			wantBrowser:    append([]byte{0, 0, 0, 1}, encodeReason("authentication failed")...),
+		},
+		{
+			name:          "unsupported version",
+			serverVersion: "RFB 003.003\n",
+			wantErr:       "vnc server protocol version 3.3 does not support credential injection",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server, serverSide := net.Pipe()
+			browser, browserSide := net.Pipe()
+			defer server.Close()
+
+			go fakeVNCServer(t, serverSide, tc.serverVersion, tc.serverPassword)
+			browserResult := make(chan []byte, 1)
+			go fakeVNCBrowser(browserSide, browserResult)
+
+			err := injectVNCAuth(server, browser, "secret")
+			browser.Close()
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tc.wantBrowser != nil {
+				assert.Equal(t, tc.wantBrowser, <-browserResult)
+			}
+		})
+	}
+}
+
+func fakeVNCServer(t *testing.T, conn net.Conn, version, password string) {
+	defer conn.Close()
+	if _, err := conn.Write([]byte(version)); err != nil {
+		return
+	}
+	buf := make([]byte, 12)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return
+	}
+	assert.Equal(t, version, string(buf))
+
+	_, _ = conn.Write([]byte{2, rfbSecurityNone, rfbSecurityVNCAuth})
+	selected := make([]byte, 1)
+	_, _ = io.ReadFull(conn, selected)
+	assert.Equal(t, byte(rfbSecurityVNCAuth), selected[0])
+
+	challenge := []byte("0123456789abcdef")
+	_, _ = conn.Write(challenge)
+	response := make([]byte, 16)
+	_, _ = io.ReadFull(conn, response)
+
+	expected, err := vncAuthResponse(challenge, password)
+	require.NoError(t, err)
+	if string(expected) == string(response) {
+		_, _ = conn.Write([]byte{0, 0, 0, 0})
+		return
+	}
+	_, _ = conn.Write(append([]byte{0, 0, 0, 1}, encodeReason("authentication failed")...))
+}
+
+func fakeVNCBrowser(conn net.Conn, result chan<- []byte) {
+	defer conn.Close()
+	version := make([]byte, 12)
+	if _, err := io.ReadFull(conn, version); err != nil {
+		return
+	}
+	_, _ = conn.Write(version)
+
+	securityTypes := make([]byte, 2)
+	_, _ = io.ReadFull(conn, securityTypes)
+	if securityTypes[1] != rfbSecurityNone {
+		return
+	}
+	_, _ = conn.Write([]byte{rfbSecurityNone})
+
+	data, _ := io.ReadAll(conn)
+	result <- data
+}
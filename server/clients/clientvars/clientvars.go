@@ -0,0 +1,119 @@
+package clientvars
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+)
+
+// actionRegex matches template actions referencing the client, other actions like {{.Names}} of a docker
+// format string are left untouched.
+var actionRegex = regexp.MustCompile(`\{\{-?\s*\.client\.[^{}]*\}\}`)
+
+// Resolve replaces the client variables in a command or script body, e.g. {{.client.Name}}, {{.client.IPv4 0}}
+// or {{.client.Tags}}, with the attributes of the given client.
+func Resolve(body string, client *clientdata.Client) (string, error) {
+	if !strings.Contains(body, "{{") {
+		return body, nil
+	}
+
+	data := map[string]interface{}{
+		"client": newClientVars(client),
+	}
+	var resolveErr error
+	resolved := actionRegex.ReplaceAllStringFunc(body, func(action string) string {
+		if resolveErr != nil {
+			return action
+		}
+		tmpl, err := template.New("").Option("missingkey=error").Parse(action)
+		if err != nil {
+			resolveErr = fmt.Errorf("invalid client variable %s: %v", action, err)
+			return action
+		}
+		var b strings.Builder
+		err = tmpl.Execute(&b, data)
+		if err != nil {
+			resolveErr = fmt.Errorf("failed to resolve client variable %s: %v", action, err)
+			return action
+		}
+		return b.String()
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return resolved, nil
+}
+
+// List is printed comma separated and can be iterated with range.
+type List []string
+
+func (l List) String() string {
+	return strings.Join(l, ",")
+}
+
+// vars are the client attributes available in templates, it's a snapshot of the client taken at dispatch.
+type vars struct {
+	ID         string
+	Name       string
+	Hostname   string
+	OS         string
+	OSArch     string
+	OSFamily   string
+	OSKernel   string
+	OSFullName string
+	OSVersion  string
+	Version    string
+	Address    string
+	Timezone   string
+	Tags       List
+	Labels     map[string]string
+
+	ipv4 []string
+	ipv6 []string
+}
+
+func newClientVars(c *clientdata.Client) *vars {
+	return &vars{
+		ID:         c.GetID(),
+		Name:       c.GetName(),
+		Hostname:   c.GetHostname(),
+		OS:         c.GetOS(),
+		OSArch:     c.GetOSArch(),
+		OSFamily:   c.GetOSFamily(),
+		OSKernel:   c.GetOSKernel(),
+		OSFullName: c.GetOSFullName(),
+		OSVersion:  c.GetOSVersion(),
+		Version:    c.GetVersion(),
+		Address:    c.GetAddress(),
+		Timezone:   c.GetTimezone(),
+		Tags:       c.GetTags(),
+		Labels:     c.GetLabels(),
+		ipv4:       c.GetIPv4(),
+		ipv6:       c.GetIPv6(),
+	}
+}
+
+// IPv4 returns the IPv4 address with the given index.
+func (v *vars) IPv4(i int) (string, error) {
+	return item(v.ipv4, i, "IPv4")
+}
+
+// IPv6 returns the IPv6 address with the given index.
+func (v *vars) IPv6(i int) (string, error) {
+	return item(v.ipv6, i, "IPv6")
+}
+
+// Label returns the value of the label with the given name, empty if not set.
+func (v *vars) Label(name string) string {
+	return v.Labels[name]
+}
+
+func item(list []string, i int, name string) (string, error) {
+	if i < 0 || i >= len(list) {
+		return "", fmt.Errorf("client has no %s address with index %d", name, i)
+	}
+	return list[i], nil
+}
@@ -0,0 +1,88 @@
+package clientvars
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+)
+
+func TestResolve(t *testing.T) {
+	client := &clientdata.Client{
+		ID:       "client-1",
+		Name:     "web-01",
+		Hostname: "web-01.example.com",
+		OS:       "Linux web-01 5.15.0",
+		IPv4:     []string{"192.168.1.10", "10.0.0.10"},
+		Tags:     []string{"Linux", "Datacenter 1"},
+		Labels:   map[string]string{"city": "Cologne"},
+	}
+
+	testCases := []struct {
+		name          string
+		body          string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "no variables",
+			body:     "/usr/bin/uptime",
+			expected: "/usr/bin/uptime",
+		},
+		{
+			name:     "name",
+			body:     "echo {{.client.Name}} {{ .client.ID }}",
+			expected: "echo web-01 client-1",
+		},
+		{
+			name:     "ipv4 by index",
+			body:     "ping -c 1 {{.client.IPv4 1}}",
+			expected: "ping -c 1 10.0.0.10",
+		},
+		{
+			name:     "tags",
+			body:     "echo {{.client.Tags}}",
+			expected: "echo Linux,Datacenter 1",
+		},
+		{
+			name:     "label and pipeline",
+			body:     `echo {{.client.Label "city" | printf "%q"}}`,
+			expected: `echo "Cologne"`,
+		},
+		{
+			name:     "other template actions are kept",
+			body:     "docker ps --format '{{.Names}}' # {{.client.Hostname}}",
+			expected: "docker ps --format '{{.Names}}' # web-01.example.com",
+		},
+		{
+			name:          "ipv4 index out of range",
+			body:          "ping {{.client.IPv4 2}}",
+			expectedError: "client has no IPv4 address with index 2",
+		},
+		{
+			name:          "unknown attribute",
+			body:          "echo {{.client.Unknown}}",
+			expectedError: "failed to resolve client variable {{.client.Unknown}}",
+		},
+		{
+			name:          "invalid syntax",
+			body:          "echo {{.client.IPv4 (}}",
+			expectedError: "invalid client variable {{.client.IPv4 (}}",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resolved, err := Resolve(tc.body, client)
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, resolved)
+		})
+	}
+}
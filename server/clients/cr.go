@@ -9,6 +9,7 @@ import (
 
 	"github.com/jmoiron/sqlx"
 
+	"github.com/realvnc-labs/rport/db/sqlite"
 	"github.com/realvnc-labs/rport/server/cgroups"
 	"github.com/realvnc-labs/rport/server/clients/clientdata"
 	"github.com/realvnc-labs/rport/share/logger"
@@ -16,14 +17,20 @@ import (
 )
 
 type ClientRepository struct {
-	// in-memory state
-	clientState map[string]*clientdata.Client
+	// in-memory state, sharded by client id to avoid a single lock for all clients
+	clientState *clientShards
+	tagIndex    *tagIndex
+	// tagsMu guards the tag index only, the client state is guarded by the shards. Writes of the index are done while
+	// holding the lock of the client's shard, so the index is written in the same order as the client state.
+	tagsMu sync.RWMutex
 	// db based store
 	clientStore ClientStore
 
 	keepDisconnectedClients *time.Duration
 
 	postSaveHandlerFn func(cl *clientdata.Client)
+	// changeHandlerFns are called after a client was saved or removed
+	changeHandlerFns []func(cl *clientdata.Client, removed bool)
 
 	logger *logger.Logger
 
@@ -44,14 +51,17 @@ func NewClientRepository(initClients []*clientdata.Client, keepDisconnectedClien
 
 // NewClientRepositoryWithDB @todo: used for test setup in two separate packages. need to review use as part of the test code refactoring.
 func NewClientRepositoryWithDB(initialClients []*clientdata.Client, keepDisconnectedClients *time.Duration, store ClientStore, logger *logger.Logger) *ClientRepository {
-	clients := make(map[string]*clientdata.Client)
+	clients := newClientShards()
+	index := newTagIndex()
 	for i := range initialClients {
 		newClientID := initialClients[i].GetID()
-		clients[newClientID] = initialClients[i]
+		clients.set(newClientID, initialClients[i])
+		index.update(newClientID, indexedTags(initialClients[i]))
 	}
 
 	return &ClientRepository{
 		clientState:             clients,
+		tagIndex:                index,
 		clientStore:             store,
 		logger:                  logger,
 		keepDisconnectedClients: keepDisconnectedClients,
@@ -84,6 +94,22 @@ func (r *ClientRepository) GetPostSaveHandlerFn() (handlerFn func(cl *clientdata
 	return handlerFn
 }
 
+// AddChangeHandlerFn adds a fn called after a client was saved or removed, e.g. to push the change to subscribers.
+func (r *ClientRepository) AddChangeHandlerFn(handlerFn func(cl *clientdata.Client, removed bool)) {
+	r.mu.Lock()
+	r.changeHandlerFns = append(r.changeHandlerFns, handlerFn)
+	r.mu.Unlock()
+}
+
+func (r *ClientRepository) notifyChange(cl *clientdata.Client, removed bool) {
+	r.mu.RLock()
+	handlerFns := r.changeHandlerFns
+	r.mu.RUnlock()
+	for _, handlerFn := range handlerFns {
+		handlerFn(cl, removed)
+	}
+}
+
 func (r *ClientRepository) Save(cl *clientdata.Client) error {
 	ts := time.Now()
 
@@ -102,6 +128,7 @@ func (r *ClientRepository) Save(cl *clientdata.Client) error {
 	if handlerFn != nil {
 		handlerFn(cl)
 	}
+	r.notifyChange(cl, false)
 
 	r.log().Debugf(
 		"saved client: %s status=%s, within %s",
@@ -128,15 +155,55 @@ func (r *ClientRepository) Delete(client *clientdata.Client) error {
 	}
 
 	r.removeClient(clientID)
+	r.notifyChange(client, true)
 	return nil
 }
 
+// SetReadReplica sets a read-only replica of the clients db used for list queries, ignored without a db based store.
+// unguarded as set during initialization
+func (r *ClientRepository) SetReadReplica(replica *sqlx.DB) {
+	if p, ok := r.clientStore.(*SqliteProvider); ok {
+		p.reader = sqlite.NewReadReplica(p.db, replica, r.logger)
+	}
+}
+
+// ListChanges returns the changes of persisted clients matching the given options and the total count of matching changes.
+func (r *ClientRepository) ListChanges(ctx context.Context, options *query.ListOptions) ([]*ClientChange, int, error) {
+	store := r.getStore()
+	if store == nil {
+		return []*ClientChange{}, 0, nil
+	}
+
+	changes, err := store.ListChanges(ctx, options)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list client changes: %w", err)
+	}
+
+	count, err := store.CountChanges(ctx, options)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count client changes: %w", err)
+	}
+
+	return changes, count, nil
+}
+
+// DeleteChangesOlderThan deletes changes of persisted clients older than a given period.
+func (r *ClientRepository) DeleteChangesOlderThan(ctx context.Context, period time.Duration) (int64, error) {
+	store := r.getStore()
+	if store == nil {
+		return 0, nil
+	}
+	return store.DeleteChangesOlderThan(ctx, period)
+}
+
 func (r *ClientRepository) GetClientsByTag(tags []string, operator string, allowDisconnected bool) (matchingClients []*clientdata.Client, err error) {
-	var availableClients []*clientdata.Client
-	if allowDisconnected {
-		availableClients = r.GetAllClients()
-	} else {
-		availableClients = r.GetAllActiveClients()
+	keep := r.GetKeepDisconnectedClients()
+	candidates := r.getClientsByTagIndex(tags, strings.EqualFold(operator, "AND"))
+	availableClients := make([]*clientdata.Client, 0, len(candidates))
+	for _, c := range candidates {
+		if allowDisconnected && !c.Obsolete(keep) || c.IsConnected() {
+			availableClients = append(availableClients, c)
+		}
 	}
 	if strings.EqualFold(operator, "AND") {
 		matchingClients = findMatchingANDClients(availableClients, tags)
@@ -147,6 +214,51 @@ func (r *ClientRepository) GetClientsByTag(tags []string, operator string, allow
 	return matchingClients, nil
 }
 
+// GetByGroups returns all non-obsolete active and disconnected clients belonging to one of the given groups.
+// Groups with a tag param without wildcards are resolved using the tag index, all other groups require a scan of all clients.
+func (r *ClientRepository) GetByGroups(groups []*cgroups.ClientGroup) []*clientdata.Client {
+	var candidates []*clientdata.Client
+	seen := make(map[string]bool)
+	for _, group := range groups {
+		tags, and, ok := groupTags(group)
+		if !ok {
+			candidates = r.GetAllClients()
+			break
+		}
+		for _, c := range r.getClientsByTagIndex(tags, and) {
+			if !seen[c.GetID()] {
+				seen[c.GetID()] = true
+				candidates = append(candidates, c)
+			}
+		}
+	}
+
+	keep := r.GetKeepDisconnectedClients()
+	var res []*clientdata.Client
+	for _, c := range candidates {
+		if !c.Obsolete(keep) && c.BelongsToOneOf(groups) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// getClientsByTagIndex returns the clients having all or one of the given tags compared case-insensitive,
+// including obsolete clients.
+func (r *ClientRepository) getClientsByTagIndex(tags []string, and bool) []*clientdata.Client {
+	r.tagsMu.RLock()
+	ids := r.tagIndex.lookup(tags, and)
+	r.tagsMu.RUnlock()
+
+	res := make([]*clientdata.Client, 0, len(ids))
+	for _, id := range ids {
+		if c := r.clientState.get(id); c != nil {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
 // this fn doesn't lock the availableClients. please make sure not to use the main clients state array.
 // the various GetXXXClient fns will return new client arrays. please use those fns to get a
 // clients array copy for this fn to operate on.
@@ -217,6 +329,7 @@ func (r *ClientRepository) DeleteObsolete() ([]*clientdata.Client, error) {
 		clientID := client.GetID()
 		r.log().Debugf("deleting obsolete client: %s status=%s", clientID, FormatConnectionState(client))
 		r.removeClient(clientID)
+		r.notifyChange(client, true)
 	}
 
 	return clientsToDelete, nil
@@ -301,14 +414,7 @@ func (r *ClientRepository) GetFilteredUserClients(user User, filterOptions []que
 
 	// uses copy of clients array returned by getNonObsoleteClientsByUser
 	for _, client := range clients {
-		calculatedClient := client.ToCalculated(groups)
-
-		// we need to lock because MatchesFilters receives an interface and not a client,
-		// therefore we lose our ability to lock.
-		calculatedClient.GetLock().RLock()
-		matches, err := query.MatchesFilters(calculatedClient, filterOptions)
-		calculatedClient.GetLock().RUnlock()
-
+		calculatedClient, matches, err := matchesFilters(client, filterOptions, groups)
 		if err != nil {
 			return matchingClients, err
 		}
@@ -322,6 +428,53 @@ func (r *ClientRepository) GetFilteredUserClients(user User, filterOptions []que
 	return matchingClients, nil
 }
 
+// GetUserClientsPage returns the page of the non-obsolete clients the user has access to, filtered and sorted by the
+// list options, and the total count of matching clients.
+func (r *ClientRepository) GetUserClientsPage(user User, options *query.ListOptions, groups []*cgroups.ClientGroup) ([]*clientdata.CalculatedClient, int, error) {
+	sortFunc, desc, err := GetSortFunc(options.Sorts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	matchingClients, err := r.GetFilteredUserClients(user, options.Filters, groups)
+	if err != nil {
+		return nil, 0, err
+	}
+	sortFunc(matchingClients, desc)
+
+	total := len(matchingClients)
+	if options.Pagination == nil {
+		return matchingClients, total, nil
+	}
+	start, end := options.Pagination.GetStartEnd(total)
+	return matchingClients[start:end], total, nil
+}
+
+// UserClientMatches returns whether the client is a non-obsolete client the user has access to matching the filters
+// like GetFilteredUserClients does for all clients.
+func (r *ClientRepository) UserClientMatches(user User, client *clientdata.Client, filterOptions []query.FilterOption, groups []*cgroups.ClientGroup) (*clientdata.CalculatedClient, bool, error) {
+	if client.Obsolete(r.GetKeepDisconnectedClients()) {
+		return nil, false, nil
+	}
+	userGroups := user.GetGroups()
+	if !user.IsAdmin() && !client.HasAccessViaUserGroups(userGroups) && !client.UserGroupHasAccessViaClientGroup(userGroups, groups) {
+		return nil, false, nil
+	}
+	return matchesFilters(client, filterOptions, groups)
+}
+
+func matchesFilters(client *clientdata.Client, filterOptions []query.FilterOption, groups []*cgroups.ClientGroup) (*clientdata.CalculatedClient, bool, error) {
+	calculatedClient := client.ToCalculated(groups)
+
+	// we need to lock because MatchesFilters receives an interface and not a client,
+	// therefore we lose our ability to lock.
+	calculatedClient.GetLock().RLock()
+	matches, err := query.MatchesFilters(calculatedClient, filterOptions)
+	calculatedClient.GetLock().RUnlock()
+
+	return calculatedClient, matches, err
+}
+
 func (r *ClientRepository) getStore() (store ClientStore) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -377,51 +530,41 @@ func (r *ClientRepository) log() (l *logger.Logger) {
 
 type ClientQueryFn func(client *clientdata.Client) (match bool)
 
-// some notes on thread safe looping over a map in the post below
-// https://stackoverflow.com/questions/40442846/concurrent-access-to-maps-with-range-in-go
-
+// queryClients runs the query fn on a copy of all clients, so no repository lock is held while the fn locks a client.
 func (r *ClientRepository) queryClients(queryFn ClientQueryFn) (matchingClients []*clientdata.Client) {
 	matchingClients = make([]*clientdata.Client, 0, DefaultInitialClientsArraySize)
 
-	clients := r.getClients()
-
-	r.mu.RLock()
-	for _, c := range clients {
-		r.mu.RUnlock()
-		match := queryFn(c)
-		if match {
+	for _, c := range r.clientState.all() {
+		if queryFn(c) {
 			matchingClients = append(matchingClients, c)
 		}
-		r.mu.RLock()
 	}
-	r.mu.RUnlock()
 
 	return matchingClients
 }
 
 func (r *ClientRepository) getClient(clientID string) (client *clientdata.Client) {
-	r.mu.RLock()
-	client = r.clientState[clientID]
-	r.mu.RUnlock()
-	return client
-}
-
-func (r *ClientRepository) getClients() (clients map[string]*clientdata.Client) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	return r.clientState
+	return r.clientState.get(clientID)
 }
 
+// updateClient updates the client and its tags in the index under the lock of the client's shard, so concurrent writes
+// of the same client can't leave the index with the tags of another version of the client.
 func (r *ClientRepository) updateClient(client *clientdata.Client) {
 	clientID := client.GetID()
 
-	r.mu.Lock()
-	r.clientState[clientID] = client
-	r.mu.Unlock()
+	r.clientState.setAnd(clientID, client, func() {
+		tags := indexedTags(client)
+
+		r.tagsMu.Lock()
+		r.tagIndex.update(clientID, tags)
+		r.tagsMu.Unlock()
+	})
 }
 
 func (r *ClientRepository) removeClient(clientID string) {
-	r.mu.Lock()
-	delete(r.clientState, clientID)
-	r.mu.Unlock()
+	r.clientState.deleteAnd(clientID, func() {
+		r.tagsMu.Lock()
+		r.tagIndex.remove(clientID)
+		r.tagsMu.Unlock()
+	})
 }
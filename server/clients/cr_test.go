@@ -1,6 +1,11 @@
 package clients
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
@@ -606,6 +611,151 @@ func TestGetUserClients(t *testing.T) {
 	}
 }
 
+func TestGetUserClientsPage(t *testing.T) {
+	c1 := New(t).ID("client-1").Logger(testLog).Build()
+	c2 := New(t).ID("client-2").Logger(testLog).Build()
+	c3 := New(t).ID("client-3").AllowedUserGroups([]string{"group1"}).Logger(testLog).Build()
+	repo := NewClientRepository([]*clientdata.Client{c1, c2, c3}, nil, testLog)
+
+	testCases := []struct {
+		name          string
+		user          User
+		options       *query.ListOptions
+		wantClientIDs []string
+		wantTotal     int
+	}{
+		{
+			name:          "all",
+			user:          admin,
+			options:       &query.ListOptions{},
+			wantClientIDs: []string{"client-1", "client-2", "client-3"},
+			wantTotal:     3,
+		},
+		{
+			name: "sorted page",
+			user: admin,
+			options: &query.ListOptions{
+				Sorts:      []query.SortOption{{Column: "id", IsASC: false}},
+				Pagination: query.NewPagination(2, 1),
+			},
+			wantClientIDs: []string{"client-2", "client-1"},
+			wantTotal:     3,
+		},
+		{
+			name: "offset beyond total",
+			user: admin,
+			options: &query.ListOptions{
+				Pagination: query.NewPagination(2, 5),
+			},
+			wantClientIDs: []string{},
+			wantTotal:     3,
+		},
+		{
+			name: "filtered by user access",
+			user: &users.User{Groups: []string{"group1"}},
+			options: &query.ListOptions{
+				Pagination: query.NewPagination(10, 0),
+			},
+			wantClientIDs: []string{"client-3"},
+			wantTotal:     1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotClients, gotTotal, err := repo.GetUserClientsPage(tc.user, tc.options, nil)
+			require.NoError(t, err)
+
+			gotClientIDs := make([]string, 0, len(gotClients))
+			for _, c := range gotClients {
+				gotClientIDs = append(gotClientIDs, c.GetID())
+			}
+			assert.Equal(t, tc.wantClientIDs, gotClientIDs)
+			assert.Equal(t, tc.wantTotal, gotTotal)
+		})
+	}
+
+	_, _, err := repo.GetUserClientsPage(admin, &query.ListOptions{Sorts: []query.SortOption{{Column: "id"}, {Column: "name"}}}, nil)
+	assert.EqualError(t, err, "Only one sort field is supported for clients.")
+}
+
+func TestRangeFilters(t *testing.T) {
+	fields := []struct {
+		name     string
+		setValue func(c *clientdata.Client, v int)
+	}{
+		{
+			name:     "num_cpus",
+			setValue: func(c *clientdata.Client, v int) { c.NumCPUs = v },
+		},
+		{
+			name:     "mem_total",
+			setValue: func(c *clientdata.Client, v int) { c.MemoryTotal = uint64(v) },
+		},
+		{
+			name:     "protocol_version",
+			setValue: func(c *clientdata.Client, v int) { c.ProtocolVersion = v },
+		},
+	}
+	operators := []struct {
+		filter        string
+		wantClientIDs []string
+	}{
+		{
+			filter:        "",
+			wantClientIDs: []string{"client-2"},
+		},
+		{
+			filter:        "[gt]",
+			wantClientIDs: []string{"client-3"},
+		},
+		{
+			filter:        "[lt]",
+			wantClientIDs: []string{"client-1"},
+		},
+		{
+			filter:        "[gte]",
+			wantClientIDs: []string{"client-2", "client-3"},
+		},
+		{
+			filter:        "[lte]",
+			wantClientIDs: []string{"client-1", "client-2"},
+		},
+		{
+			filter:        "[ne]",
+			wantClientIDs: []string{"client-1", "client-3"},
+		},
+	}
+
+	for _, field := range fields {
+		c1 := New(t).ID("client-1").Logger(testLog).Build()
+		c2 := New(t).ID("client-2").Logger(testLog).Build()
+		c3 := New(t).ID("client-3").Logger(testLog).Build()
+		field.setValue(c1, 2)
+		field.setValue(c2, 4)
+		field.setValue(c3, 16)
+		repo := NewClientRepository([]*clientdata.Client{c1, c2, c3}, nil, testLog)
+
+		for _, op := range operators {
+			filter := fmt.Sprintf("filter[%s]%s", field.name, op.filter)
+			t.Run(filter, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, "/clients?"+filter+"=4", nil)
+				options := query.NewOptions(req, nil, nil, nil)
+				require.NoError(t, query.ValidateListOptions(options, OptionsSupportedSorts, OptionsSupportedFilters, nil, nil))
+
+				gotClients, _, err := repo.GetUserClientsPage(admin, options, nil)
+				require.NoError(t, err)
+
+				gotClientIDs := make([]string, 0, len(gotClients))
+				for _, c := range gotClients {
+					gotClientIDs = append(gotClientIDs, c.GetID())
+				}
+				assert.ElementsMatch(t, op.wantClientIDs, gotClientIDs)
+			})
+		}
+	}
+}
+
 func TestGetClientByTag(t *testing.T) {
 	// clients from data_test.go
 	availableClients := []*clientdata.Client{c1, c2, c3, c4, c5}
@@ -676,3 +826,133 @@ func TestGetClientByTag(t *testing.T) {
 		})
 	}
 }
+
+func TestGetClientsByTagUsesUpdatedIndex(t *testing.T) {
+	cl1 := New(t).Build()
+	cl1.Tags = []string{"Linux", "Datacenter 1"}
+	cl2 := New(t).DisconnectedDuration(time.Minute).Build()
+	cl2.Tags = []string{"Linux", "Datacenter 2"}
+	repo := NewClientRepository([]*clientdata.Client{cl1}, nil, testLog)
+	require.NoError(t, repo.Save(cl2))
+
+	got, err := repo.GetClientsByTag([]string{"Linux"}, "OR", true)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*clientdata.Client{cl1, cl2}, got)
+
+	got, err = repo.GetClientsByTag([]string{"Linux"}, "OR", false)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*clientdata.Client{cl1}, got)
+
+	got, err = repo.GetClientsByTag([]string{"Linux", "Datacenter 2"}, "AND", true)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*clientdata.Client{cl2}, got)
+
+	// the tag lookup is case-sensitive
+	got, err = repo.GetClientsByTag([]string{"linux"}, "OR", true)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	cl1.Tags = []string{"Windows"}
+	require.NoError(t, repo.Save(cl1))
+	got, err = repo.GetClientsByTag([]string{"Linux"}, "OR", true)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*clientdata.Client{cl2}, got)
+
+	require.NoError(t, repo.Delete(cl2))
+	got, err = repo.GetClientsByTag([]string{"Linux"}, "OR", true)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestTagIndexFollowsConcurrentWrites(t *testing.T) {
+	repo := NewClientRepository(nil, nil, testLog)
+
+	for i := 0; i < 1000; i++ {
+		v1 := &clientdata.Client{ID: "client-1", Tags: []string{"v1"}}
+		v2 := &clientdata.Client{ID: "client-1", Tags: []string{"v2"}}
+
+		var wg sync.WaitGroup
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			repo.updateClient(v1)
+		}()
+		go func() {
+			defer wg.Done()
+			repo.updateClient(v2)
+		}()
+		go func() {
+			defer wg.Done()
+			repo.removeClient("client-1")
+		}()
+		wg.Wait()
+
+		var wantV1, wantV2 []string
+		switch repo.getClient("client-1") {
+		case v1:
+			wantV1 = []string{"client-1"}
+		case v2:
+			wantV2 = []string{"client-1"}
+		}
+		require.Equal(t, wantV1, repo.tagIndex.lookup([]string{"v1"}, false), "iteration %d", i)
+		require.Equal(t, wantV2, repo.tagIndex.lookup([]string{"v2"}, false), "iteration %d", i)
+	}
+}
+
+func TestGetByGroups(t *testing.T) {
+	cl1 := New(t).Build()
+	cl1.Tags = []string{"Linux", "Datacenter 1"}
+	cl2 := New(t).Build()
+	cl2.Tags = []string{"Linux", "Datacenter 2"}
+	cl2.AutoTags = []string{"unstable"}
+	cl3 := New(t).Build()
+	cl3.Tags = []string{"Windows"}
+	cl3.Hostname = "win-01"
+	repo := NewClientRepository([]*clientdata.Client{cl1, cl2, cl3}, nil, testLog)
+
+	tagGroup := func(tag string) *cgroups.ClientGroup {
+		raw := json.RawMessage(tag)
+		return &cgroups.ClientGroup{ID: tag, Params: &cgroups.ClientParams{Tag: &raw}}
+	}
+
+	testCases := []struct {
+		Name     string
+		Groups   []*cgroups.ClientGroup
+		Expected []*clientdata.Client
+	}{
+		{
+			Name:     "tags or",
+			Groups:   []*cgroups.ClientGroup{tagGroup(`["datacenter 1", "Windows"]`)},
+			Expected: []*clientdata.Client{cl1, cl3},
+		},
+		{
+			Name:     "tags and",
+			Groups:   []*cgroups.ClientGroup{tagGroup(`{"and": ["Linux", "Datacenter 2"]}`)},
+			Expected: []*clientdata.Client{cl2},
+		},
+		{
+			Name:     "auto tags",
+			Groups:   []*cgroups.ClientGroup{tagGroup(`["unstable"]`)},
+			Expected: []*clientdata.Client{cl2},
+		},
+		{
+			Name:     "wildcard",
+			Groups:   []*cgroups.ClientGroup{tagGroup(`["Datacenter*"]`)},
+			Expected: []*clientdata.Client{cl1, cl2},
+		},
+		{
+			Name: "multiple groups",
+			Groups: []*cgroups.ClientGroup{
+				tagGroup(`["Linux"]`),
+				{ID: "hostname", Params: &cgroups.ClientParams{Hostname: &cgroups.ParamValues{"win-*"}}},
+			},
+			Expected: []*clientdata.Client{cl1, cl2, cl3},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.ElementsMatch(t, tc.Expected, repo.GetByGroups(tc.Groups))
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package clients
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+)
+
+const (
+	DuplicateKindHostname = "hostname"
+	DuplicateKindSerial   = "serial"
+)
+
+// Duplicate is a group of clients with different ids sharing the same hostname or machine serial,
+// usually caused by cloned images or agents installed twice.
+type Duplicate struct {
+	Kind      string   `json:"kind"`
+	Value     string   `json:"value"`
+	ClientIDs []string `json:"client_ids"`
+}
+
+// FindDuplicates returns the clients sharing the same hostname, compared case-insensitive, and if serialLabel is set
+// the clients having the same value of this label. The result is sorted by kind and value.
+func FindDuplicates(clients []*clientdata.Client, serialLabel string) []Duplicate {
+	byHostname := make(map[string][]string)
+	bySerial := make(map[string][]string)
+	for _, c := range clients {
+		if hostname := strings.ToLower(c.GetHostname()); hostname != "" {
+			byHostname[hostname] = append(byHostname[hostname], c.GetID())
+		}
+		if serial := clientSerial(c, serialLabel); serial != "" {
+			bySerial[serial] = append(bySerial[serial], c.GetID())
+		}
+	}
+
+	var res []Duplicate
+	res = appendDuplicates(res, DuplicateKindHostname, byHostname)
+	res = appendDuplicates(res, DuplicateKindSerial, bySerial)
+	return res
+}
+
+// FindDuplicatesOf returns the ids of other clients sharing the hostname or serial of the given client.
+func FindDuplicatesOf(client *clientdata.Client, clients []*clientdata.Client, serialLabel string) []string {
+	hostname := strings.ToLower(client.GetHostname())
+	serial := clientSerial(client, serialLabel)
+	if hostname == "" && serial == "" {
+		return nil
+	}
+
+	var res []string
+	for _, c := range clients {
+		if c.GetID() == client.GetID() {
+			continue
+		}
+		if hostname != "" && strings.ToLower(c.GetHostname()) == hostname || serial != "" && clientSerial(c, serialLabel) == serial {
+			res = append(res, c.GetID())
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+func clientSerial(c *clientdata.Client, serialLabel string) string {
+	if serialLabel == "" {
+		return ""
+	}
+	return strings.TrimSpace(c.GetLabels()[serialLabel])
+}
+
+func appendDuplicates(res []Duplicate, kind string, clientIDsByValue map[string][]string) []Duplicate {
+	values := make([]string, 0, len(clientIDsByValue))
+	for value, clientIDs := range clientIDsByValue {
+		if len(clientIDs) > 1 {
+			values = append(values, value)
+		}
+	}
+	sort.Strings(values)
+
+	for _, value := range values {
+		clientIDs := clientIDsByValue[value]
+		sort.Strings(clientIDs)
+		res = append(res, Duplicate{
+			Kind:      kind,
+			Value:     value,
+			ClientIDs: clientIDs,
+		})
+	}
+	return res
+}
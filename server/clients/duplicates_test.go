@@ -0,0 +1,48 @@
+package clients
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	newClient := func(id, hostname, serial string) *clientdata.Client {
+		c := New(t).ID(id).Build()
+		c.Hostname = hostname
+		c.Labels = map[string]string{}
+		if serial != "" {
+			c.Labels["serial"] = serial
+		}
+		return c
+	}
+	c1 := newClient("c1", "web-01", "S1")
+	c2 := newClient("c2", "WEB-01", "S2")
+	c3 := newClient("c3", "db-01", "S1")
+	c4 := newClient("c4", "db-02", "")
+	c5 := newClient("c5", "", "")
+	c6 := newClient("c6", "", "")
+	all := []*clientdata.Client{c1, c2, c3, c4, c5, c6}
+
+	t.Run("hostname only", func(t *testing.T) {
+		assert.Equal(t, []Duplicate{
+			{Kind: DuplicateKindHostname, Value: "web-01", ClientIDs: []string{"c1", "c2"}},
+		}, FindDuplicates(all, ""))
+	})
+
+	t.Run("hostname and serial", func(t *testing.T) {
+		assert.Equal(t, []Duplicate{
+			{Kind: DuplicateKindHostname, Value: "web-01", ClientIDs: []string{"c1", "c2"}},
+			{Kind: DuplicateKindSerial, Value: "S1", ClientIDs: []string{"c1", "c3"}},
+		}, FindDuplicates(all, "serial"))
+	})
+
+	t.Run("duplicates of client", func(t *testing.T) {
+		assert.Equal(t, []string{"c2", "c3"}, FindDuplicatesOf(c1, all, "serial"))
+		assert.Equal(t, []string{"c2"}, FindDuplicatesOf(c1, all, ""))
+		assert.Nil(t, FindDuplicatesOf(c4, all, "serial"))
+		assert.Nil(t, FindDuplicatesOf(c5, all, "serial"))
+	})
+}
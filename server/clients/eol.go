@@ -0,0 +1,71 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/oseol"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+type EOLTask struct {
+	log     *logger.Logger
+	cr      *ClientRepository
+	dataset *oseol.Dataset
+	lastRun time.Time
+}
+
+// NewEOLTask returns a task to reload the os end-of-life dates and update them on all clients. Clients are saved,
+// and so passed to the alerting service, if their end-of-life date changed or was reached since the last run.
+func NewEOLTask(log *logger.Logger, cr *ClientRepository, dataset *oseol.Dataset) *EOLTask {
+	return &EOLTask{
+		log:     log,
+		cr:      cr,
+		dataset: dataset,
+		lastRun: clientdata.Now(),
+	}
+}
+
+func (t *EOLTask) Run(ctx context.Context) error {
+	reloaded, err := t.dataset.Reload()
+	if err != nil {
+		return err
+	}
+	if reloaded {
+		t.log.Infof("Reloaded os end-of-life dates.")
+	}
+
+	now := clientdata.Now()
+	updated := 0
+	for _, client := range t.cr.GetAllClients() {
+		wasEOL := client.EOLReached(t.lastRun)
+		changed := updateEOLDate(t.dataset, client)
+		if !changed && wasEOL == client.EOLReached(now) {
+			continue
+		}
+		if err := t.cr.Save(client); err != nil {
+			return fmt.Errorf("failed to save os end-of-life date of client %s: %v", client.GetID(), err)
+		}
+		updated++
+	}
+	t.lastRun = now
+
+	if updated > 0 {
+		t.log.Debugf("Updated os end-of-life state of %d client(s).", updated)
+	}
+
+	return nil
+}
+
+// updateEOLDate looks up the end-of-life date of the client os and returns true if it changed.
+func updateEOLDate(dataset *oseol.Dataset, client *clientdata.Client) bool {
+	eol := dataset.Lookup(client.GetOSFullName(), client.GetOSVersion())
+	old := client.GetEOLDate()
+	if eol == nil && old == nil || eol != nil && old != nil && eol.Equal(*old) {
+		return false
+	}
+	client.SetEOLDate(eol)
+	return true
+}
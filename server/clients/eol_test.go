@@ -0,0 +1,58 @@
+package clients
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/oseol"
+)
+
+func TestEOLTask(t *testing.T) {
+	now := time.Date(2025, 5, 30, 12, 0, 0, 0, time.UTC)
+	clientdata.Now = func() time.Time {
+		return now
+	}
+	defer func() {
+		clientdata.Now = time.Now
+	}()
+
+	c1 := New(t).ID("client-1").Logger(testLog).Build()
+	c1.OSFullName = "Ubuntu 20.04"
+	c1.OSVersion = "20.04"
+	c2 := New(t).ID("client-2").Logger(testLog).Build()
+	c2.OSFullName = "Alpine 3.14.0"
+	c2.OSVersion = "3.14.0"
+	clientService := NewClientService(nil, nil, NewClientRepository([]*clientdata.Client{c1, c2}, &hour, testLog), testLog, nil)
+	recorder := &clientUpdatesRecorder{}
+	clientService.SetPlusAlertingServiceCap(recorder)
+	dataset, err := oseol.NewDataset("")
+	require.NoError(t, err)
+
+	task := NewEOLTask(testLog, clientService.GetRepo(), dataset)
+	require.NoError(t, task.Run(context.Background()))
+
+	require.NotNil(t, c1.GetEOLDate())
+	assert.Equal(t, "2025-05-31", c1.GetEOLDate().Format("2006-01-02"))
+	assert.Nil(t, c2.GetEOLDate())
+	require.Len(t, recorder.updates, 1)
+	assert.Equal(t, "client-1", recorder.updates[0].ID)
+	assert.False(t, recorder.updates[0].IsEOL)
+	assert.False(t, c1.ToCalculated(nil).IsEOL)
+
+	// nothing changed
+	require.NoError(t, task.Run(context.Background()))
+	assert.Len(t, recorder.updates, 1)
+
+	// end-of-life date reached
+	now = now.Add(48 * time.Hour)
+	require.NoError(t, task.Run(context.Background()))
+	require.Len(t, recorder.updates, 2)
+	assert.True(t, recorder.updates[1].IsEOL)
+	assert.Equal(t, c1.GetEOLDate(), recorder.updates[1].EOLDate)
+	assert.True(t, c1.ToCalculated(nil).IsEOL)
+}
@@ -0,0 +1,57 @@
+package clients
+
+import (
+	"context"
+
+	"github.com/realvnc-labs/rport/server/api/session"
+	"github.com/realvnc-labs/rport/server/clients/clienttunnel"
+)
+
+type APISessionGetter interface {
+	Get(ctx context.Context, sessionID int64) (found bool, sessionInfo session.APISession, err error)
+}
+
+// TerminateEphemeralTunnels terminates the ephemeral tunnels of all active clients whose api session ended,
+// e.g. on logout, revocation or expiry of the session.
+func (s *ClientServiceProvider) TerminateEphemeralTunnels(ctx context.Context, sessions APISessionGetter) error {
+	for _, c := range s.repo.GetAllActiveClients() {
+		var ended []*clienttunnel.Tunnel
+		for _, t := range c.GetTunnels() {
+			if !t.Ephemeral {
+				continue
+			}
+			found, _, err := sessions.Get(ctx, t.SessionID)
+			if err != nil {
+				return err
+			}
+			if !found {
+				ended = append(ended, t)
+			}
+		}
+
+		for _, t := range ended {
+			c.Log().Infof("Closing ephemeral tunnel %s, the api session of %s ended.", t.ID, t.Owner)
+			if err := s.TerminateTunnel(c, t, true); err != nil {
+				c.Log().Errorf("Failed to close ephemeral tunnel %s: %v", t.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+type EphemeralTunnelsTask struct {
+	cs       ClientService
+	sessions APISessionGetter
+}
+
+// NewEphemeralTunnelsTask returns a task to close the ephemeral tunnels of expired api sessions.
+func NewEphemeralTunnelsTask(cs ClientService, sessions APISessionGetter) *EphemeralTunnelsTask {
+	return &EphemeralTunnelsTask{
+		cs:       cs,
+		sessions: sessions,
+	}
+}
+
+func (t *EphemeralTunnelsTask) Run(ctx context.Context) error {
+	return t.cs.TerminateEphemeralTunnels(ctx, t.sessions)
+}
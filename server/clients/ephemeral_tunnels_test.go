@@ -0,0 +1,64 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/api/session"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/clients/clienttunnel"
+)
+
+type sessionsMock map[int64]bool
+
+func (m sessionsMock) Get(ctx context.Context, sessionID int64) (bool, session.APISession, error) {
+	return m[sessionID], session.APISession{SessionID: sessionID}, nil
+}
+
+func TestTerminateEphemeralTunnels(t *testing.T) {
+	c1 := New(t).ID("client-1").Logger(testLog).Build()
+	require.Len(t, c1.Tunnels, 2)
+	for _, tunnel := range c1.Tunnels {
+		tunnel.TunnelProtocol = &clienttunnel.MultiProtocolTunnel{}
+	}
+	c1.Tunnels[0].Ephemeral = true
+	c1.Tunnels[0].SessionID = 1
+	c1.Tunnels[1].Ephemeral = true
+	c1.Tunnels[1].SessionID = 2
+	c2 := New(t).ID("client-2").Logger(testLog).Build()
+	clientService := NewClientService(nil, nil, NewClientRepository([]*clientdata.Client{c1, c2}, &hour, testLog), testLog, nil)
+
+	err := NewEphemeralTunnelsTask(clientService, sessionsMock{1: true}).Run(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, c1.Tunnels, 1)
+	assert.Equal(t, int64(1), c1.Tunnels[0].SessionID)
+	// tunnels which are not ephemeral are kept
+	assert.Len(t, c2.Tunnels, 2)
+}
+
+func TestEphemeralTunnelSessionIsPersisted(t *testing.T) {
+	ctx := context.Background()
+	p := NewFakeClientProvider(t, &hour)
+	defer p.Close()
+
+	c1 := New(t).Logger(testLog).Build()
+	c1.Tunnels[0].Ephemeral = true
+	c1.Tunnels[0].SessionID = 7
+
+	// the session is neither returned by the api nor sent to the client
+	b, err := json.Marshal(c1.Tunnels[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "session")
+
+	require.NoError(t, p.Save(ctx, c1))
+	got, err := p.get(ctx, c1.GetID(), testLog)
+	require.NoError(t, err)
+	require.Len(t, got.Tunnels, 2)
+	assert.Equal(t, int64(7), got.Tunnels[0].SessionID)
+	assert.Equal(t, int64(0), got.Tunnels[1].SessionID)
+}
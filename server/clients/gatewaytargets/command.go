@@ -0,0 +1,166 @@
+package gatewaytargets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// maxOutputBytes limits the captured stdout and stderr of a command each.
+const maxOutputBytes = 1024 * 1024
+
+type CommandResult struct {
+	Stdout     string    `json:"stdout"`
+	Stderr     string    `json:"stderr"`
+	ExitCode   int       `json:"exit_code"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// RunCommand executes the command on the target via ssh. The ssh connection is tunneled through a channel of the
+// connection to the gateway client, the same way tunnels reach their remote.
+func RunCommand(ctx context.Context, gatewayConn ssh.Conn, t *GatewayTarget, command string, timeout time.Duration) (*CommandResult, error) {
+	if !t.HasCredentials() {
+		return nil, errors.New("gateway target has no ssh credentials")
+	}
+	config, err := t.clientConfig(timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	addr := t.Address()
+	ch, reqs, err := gatewayConn.OpenChannel("rport", []byte(addr))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s via gateway client: %w", addr, err)
+	}
+	go ssh.DiscardRequests(reqs)
+
+	conn, chans, sshReqs, err := ssh.NewClientConn(&channelConn{Channel: ch}, addr, config)
+	if err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("ssh connection to %s failed: %w", addr, err)
+	}
+	client := ssh.NewClient(conn, chans, sshReqs)
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ssh session: %w", err)
+	}
+	defer session.Close()
+
+	stdout := &limitedBuffer{limit: maxOutputBytes}
+	stderr := &limitedBuffer{limit: maxOutputBytes}
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	res := &CommandResult{StartedAt: time.Now()}
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Run(command)
+	}()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		client.Close()
+		return nil, fmt.Errorf("command did not finish within %s", timeout)
+	}
+	res.FinishedAt = time.Now()
+	res.Stdout = stdout.String()
+	res.Stderr = stderr.String()
+
+	var exitErr *ssh.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		res.ExitCode = exitErr.ExitStatus()
+	case err != nil:
+		return nil, fmt.Errorf("failed to run command: %w", err)
+	}
+
+	return res, nil
+}
+
+// Address returns the host and port the gateway client connects to.
+func (t *GatewayTarget) Address() string {
+	return net.JoinHostPort(t.Host, strconv.Itoa(t.Port))
+}
+
+func (t *GatewayTarget) clientConfig(timeout time.Duration) (*ssh.ClientConfig, error) {
+	var auth []ssh.AuthMethod
+	if t.PrivateKey != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(t.PrivateKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if t.Password != "" {
+		auth = append(auth, ssh.Password(t.Password))
+	}
+
+	hostKeyCallback := ssh.InsecureIgnoreHostKey() //nolint:gosec // verifying the host key is optional
+	if t.HostKey != "" {
+		hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(t.HostKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid host key: %w", err)
+		}
+		hostKeyCallback = ssh.FixedHostKey(hostKey)
+	}
+
+	return &ssh.ClientConfig{
+		User:            t.Username,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         timeout,
+	}, nil
+}
+
+// channelConn makes an ssh channel usable as net.Conn for an ssh client connection.
+type channelConn struct {
+	ssh.Channel
+}
+
+func (c *channelConn) LocalAddr() net.Addr {
+	return &net.TCPAddr{}
+}
+
+func (c *channelConn) RemoteAddr() net.Addr {
+	return &net.TCPAddr{}
+}
+
+func (c *channelConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+func (c *channelConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+func (c *channelConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.Len(); remaining < len(p) {
+		if remaining > 0 {
+			b.Buffer.Write(p[:remaining])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
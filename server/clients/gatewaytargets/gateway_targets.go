@@ -0,0 +1,149 @@
+package gatewaytargets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/share/query"
+	"github.com/realvnc-labs/rport/share/random"
+)
+
+var (
+	supportedFilters = map[string]bool{
+		"name":              true,
+		"host":              true,
+		"port":              true,
+		"gateway_client_id": true,
+	}
+	supportedSorts = map[string]bool{
+		"created_at":        true,
+		"name":              true,
+		"host":              true,
+		"gateway_client_id": true,
+	}
+)
+
+type Provider interface {
+	Delete(context.Context, string) error
+	Insert(context.Context, *GatewayTarget) error
+	Update(context.Context, *GatewayTarget) error
+	Get(context.Context, string) (*GatewayTarget, error)
+	List(context.Context, *query.ListOptions) ([]*GatewayTarget, error)
+	Count(context.Context, *query.ListOptions) (int, error)
+}
+
+type Manager struct {
+	provider Provider
+}
+
+func New(db *sqlx.DB) *Manager {
+	return &Manager{
+		provider: newSQLiteProvider(db),
+	}
+}
+
+func (m *Manager) List(ctx context.Context, options *query.ListOptions) (*api.SuccessPayload, error) {
+	err := query.ValidateListOptions(options, supportedSorts, supportedFilters, nil, &query.PaginationConfig{
+		DefaultLimit: 10,
+		MaxLimit:     100,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := m.provider.List(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := m.provider.Count(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	redacted := make([]*GatewayTarget, 0, len(entries))
+	for _, t := range entries {
+		redacted = append(redacted, t.Redacted())
+	}
+
+	return &api.SuccessPayload{
+		Data: redacted,
+		Meta: api.NewMeta(count),
+	}, nil
+}
+
+// Get returns the target including the secrets, an APIError with 404 if not found.
+func (m *Manager) Get(ctx context.Context, id string) (*GatewayTarget, error) {
+	t, err := m.provider.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, errors.NewAPIError(http.StatusNotFound, "", fmt.Sprintf("Gateway target with id %q not found.", id), nil)
+	}
+	return t, nil
+}
+
+func (m *Manager) Create(ctx context.Context, t *GatewayTarget) (*GatewayTarget, error) {
+	if t.Port == 0 {
+		t.Port = DefaultPort
+	}
+	if err := t.Validate(); err != nil {
+		return nil, errors.NewAPIError(http.StatusBadRequest, "", err.Error(), nil)
+	}
+
+	id, err := random.UUID4()
+	if err != nil {
+		return nil, err
+	}
+	t.ID = id
+	t.CreatedAt = time.Now().UTC()
+
+	err = m.provider.Insert(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Update replaces the target, secrets not given are kept.
+func (m *Manager) Update(ctx context.Context, id string, t *GatewayTarget) (*GatewayTarget, error) {
+	existing, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	t.ID = id
+	t.CreatedAt = existing.CreatedAt
+	if t.Port == 0 {
+		t.Port = DefaultPort
+	}
+	if t.Password == "" && t.PrivateKey == "" && t.Username == existing.Username {
+		t.Password = existing.Password
+		t.PrivateKey = existing.PrivateKey
+	}
+	if err := t.Validate(); err != nil {
+		return nil, errors.NewAPIError(http.StatusBadRequest, "", err.Error(), nil)
+	}
+
+	err = m.provider.Update(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (m *Manager) Delete(ctx context.Context, id string) error {
+	if _, err := m.Get(ctx, id); err != nil {
+		return err
+	}
+	return m.provider.Delete(ctx, id)
+}
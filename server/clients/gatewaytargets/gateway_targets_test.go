@@ -0,0 +1,180 @@
+package gatewaytargets
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/realvnc-labs/rport/db/migration/clients"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+var DataSourceOptions = sqlite.DataSourceOptions{WALEnabled: false}
+
+func TestGatewayTargets(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlite.New(":memory:", clients.AssetNames(), clients.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	manager := New(db)
+	options := &query.ListOptions{}
+
+	// invalid target
+	_, err = manager.Create(ctx, &GatewayTarget{Name: "db-1", GatewayClientID: "client-1"})
+	assert.EqualError(t, err, "host is required")
+
+	target, err := manager.Create(ctx, &GatewayTarget{
+		Name:            "db-1",
+		GatewayClientID: "client-1",
+		Host:            "192.168.1.10",
+		Username:        "admin",
+		Password:        "secret",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultPort, target.Port)
+
+	results, err := manager.List(ctx, options)
+	require.NoError(t, err)
+	assert.Equal(t, 1, results.Meta.Count)
+	assert.Equal(t, []*GatewayTarget{target.Redacted()}, results.Data)
+	assert.Empty(t, target.Redacted().Password)
+
+	// secrets are kept if not given
+	updated, err := manager.Update(ctx, target.ID, &GatewayTarget{
+		Name:            "db-1",
+		GatewayClientID: "client-1",
+		Host:            "192.168.1.11",
+		Port:            2222,
+		Username:        "admin",
+	})
+	require.NoError(t, err)
+	got, err := manager.Get(ctx, target.ID)
+	require.NoError(t, err)
+	assert.Equal(t, updated, got)
+	assert.Equal(t, "secret", got.Password)
+	assert.Equal(t, "192.168.1.11:2222", got.Address())
+
+	err = manager.Delete(ctx, target.ID)
+	require.NoError(t, err)
+
+	_, err = manager.Get(ctx, target.ID)
+	apiErr, ok := err.(errors.APIError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, apiErr.HTTPStatus)
+}
+
+func TestRunCommand(t *testing.T) {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	require.NoError(t, err)
+
+	gateway := &gatewayConnMock{t: t, hostSigner: hostSigner}
+	target := &GatewayTarget{
+		Host:     "192.168.1.10",
+		Port:     22,
+		Username: "admin",
+		Password: "secret",
+		HostKey:  string(ssh.MarshalAuthorizedKey(hostSigner.PublicKey())),
+	}
+
+	res, err := RunCommand(context.Background(), gateway, target, "uptime", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.1.10:22", gateway.remote)
+	assert.Equal(t, "out: uptime", res.Stdout)
+	assert.Equal(t, "err: uptime", res.Stderr)
+	assert.Equal(t, 3, res.ExitCode)
+
+	target.Password = "wrong"
+	_, err = RunCommand(context.Background(), gateway, target, "uptime", time.Second)
+	assert.ErrorContains(t, err, "ssh connection to 192.168.1.10:22 failed")
+}
+
+// gatewayConnMock connects channels opened to the gateway client to a local ssh server that echoes exec requests.
+type gatewayConnMock struct {
+	ssh.Conn
+	t          *testing.T
+	hostSigner ssh.Signer
+	remote     string
+}
+
+func (c *gatewayConnMock) OpenChannel(name string, data []byte) (ssh.Channel, <-chan *ssh.Request, error) {
+	c.remote = string(data)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(c.t, err)
+	defer l.Close()
+	go func() {
+		serverSide, err := l.Accept()
+		if err == nil {
+			c.serve(serverSide)
+		}
+	}()
+	clientSide, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(c.t, err)
+	return &connChannel{Conn: clientSide}, make(chan *ssh.Request), nil
+}
+
+func (c *gatewayConnMock) serve(conn net.Conn) {
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(meta ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if meta.User() == "admin" && string(password) == "secret" {
+				return nil, nil
+			}
+			return nil, io.EOF
+		},
+	}
+	config.AddHostKey(c.hostSigner)
+
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newCh := range chans {
+		ch, chReqs, err := newCh.Accept()
+		require.NoError(c.t, err)
+		for req := range chReqs {
+			if req.Type != "exec" {
+				_ = req.Reply(false, nil)
+				continue
+			}
+			_ = req.Reply(true, nil)
+			command := string(req.Payload[4:])
+			_, _ = ch.Write([]byte("out: " + command))
+			_, _ = ch.Stderr().Write([]byte("err: " + command))
+			status := make([]byte, 4)
+			binary.BigEndian.PutUint32(status, 3)
+			_, _ = ch.SendRequest("exit-status", false, status)
+			ch.Close()
+		}
+	}
+}
+
+type connChannel struct {
+	net.Conn
+	stderr bytes.Buffer
+}
+
+func (c *connChannel) CloseWrite() error {
+	return nil
+}
+
+func (c *connChannel) SendRequest(string, bool, []byte) (bool, error) {
+	return false, nil
+}
+
+func (c *connChannel) Stderr() io.ReadWriter {
+	return &c.stderr
+}
@@ -0,0 +1,69 @@
+package gatewaytargets
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const DefaultPort = 22
+
+// GatewayTarget is a host with a plain sshd but no rport client, reached via the network of the gateway client.
+type GatewayTarget struct {
+	ID              string    `json:"id" db:"id"`
+	GatewayClientID string    `json:"gateway_client_id" db:"gateway_client_id"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	Name            string    `json:"name" db:"name"`
+	Host            string    `json:"host" db:"host"`
+	Port            int       `json:"port" db:"port"`
+	// Username, Password and PrivateKey are the ssh credentials used to execute commands, not needed for tunnels.
+	Username   string `json:"username" db:"username"`
+	Password   string `json:"password,omitempty" db:"password"`
+	PrivateKey string `json:"private_key,omitempty" db:"private_key"`
+	// HostKey is the public key of the target in authorized_keys format. If empty, the host key isn't verified.
+	HostKey string `json:"host_key" db:"host_key"`
+}
+
+func (t *GatewayTarget) Validate() error {
+	if t.Name == "" {
+		return errors.New("name is required")
+	}
+	if t.GatewayClientID == "" {
+		return errors.New("gateway_client_id is required")
+	}
+	if t.Host == "" {
+		return errors.New("host is required")
+	}
+	if t.Port < 1 || t.Port > 65535 {
+		return fmt.Errorf("invalid port %d", t.Port)
+	}
+	if (t.Password != "" || t.PrivateKey != "") && t.Username == "" {
+		return errors.New("username is required with password or private_key")
+	}
+	if t.PrivateKey != "" {
+		if _, err := ssh.ParsePrivateKey([]byte(t.PrivateKey)); err != nil {
+			return fmt.Errorf("invalid private_key: %v", err)
+		}
+	}
+	if t.HostKey != "" {
+		if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(t.HostKey)); err != nil {
+			return fmt.Errorf("invalid host_key: %v", err)
+		}
+	}
+	return nil
+}
+
+// HasCredentials returns true if commands can be executed on the target.
+func (t *GatewayTarget) HasCredentials() bool {
+	return t.Username != "" && (t.Password != "" || t.PrivateKey != "")
+}
+
+// Redacted returns a copy of the target without the secrets to be returned by the API.
+func (t *GatewayTarget) Redacted() *GatewayTarget {
+	redacted := *t
+	redacted.Password = ""
+	redacted.PrivateKey = ""
+	return &redacted
+}
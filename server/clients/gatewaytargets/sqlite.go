@@ -0,0 +1,119 @@
+package gatewaytargets
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+type SQLiteProvider struct {
+	db        *sqlx.DB
+	converter *query.SQLConverter
+}
+
+func newSQLiteProvider(db *sqlx.DB) *SQLiteProvider {
+	return &SQLiteProvider{
+		db:        db,
+		converter: query.NewSQLConverter(db.DriverName()),
+	}
+}
+
+func (p *SQLiteProvider) Insert(ctx context.Context, t *GatewayTarget) error {
+	_, err := p.db.NamedExecContext(ctx,
+		`INSERT INTO gateway_targets (
+			id,
+			gateway_client_id,
+			created_at,
+			name,
+			host,
+			port,
+			username,
+			password,
+			private_key,
+			host_key
+		) VALUES (
+			:id,
+			:gateway_client_id,
+			:created_at,
+			:name,
+			:host,
+			:port,
+			:username,
+			:password,
+			:private_key,
+			:host_key
+		)`,
+		t,
+	)
+
+	return err
+}
+
+func (p *SQLiteProvider) Update(ctx context.Context, t *GatewayTarget) error {
+	_, err := p.db.NamedExecContext(ctx,
+		`UPDATE gateway_targets SET
+			gateway_client_id = :gateway_client_id,
+			name = :name,
+			host = :host,
+			port = :port,
+			username = :username,
+			password = :password,
+			private_key = :private_key,
+			host_key = :host_key
+		WHERE id = :id`,
+		t,
+	)
+
+	return err
+}
+
+func (p *SQLiteProvider) Get(ctx context.Context, id string) (*GatewayTarget, error) {
+	res := &GatewayTarget{}
+	err := p.db.GetContext(ctx, res, "SELECT * FROM gateway_targets WHERE id = ?", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (p *SQLiteProvider) List(ctx context.Context, options *query.ListOptions) ([]*GatewayTarget, error) {
+	values := []*GatewayTarget{}
+
+	q := "SELECT * FROM gateway_targets"
+	q, params := p.converter.ConvertListOptionsToQuery(options, q)
+
+	err := p.db.SelectContext(ctx, &values, q, params...)
+	if err != nil {
+		return values, err
+	}
+
+	return values, nil
+}
+
+func (p *SQLiteProvider) Count(ctx context.Context, options *query.ListOptions) (int, error) {
+	var result int
+
+	countOptions := *options
+	countOptions.Pagination = nil
+	q, params := p.converter.ConvertListOptionsToQuery(&countOptions, "SELECT COUNT(*) FROM gateway_targets")
+
+	err := p.db.GetContext(ctx, &result, q, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result, nil
+}
+
+func (p *SQLiteProvider) Delete(ctx context.Context, id string) error {
+	_, err := p.db.ExecContext(ctx, "DELETE FROM gateway_targets WHERE id = ?", id)
+	return err
+}
@@ -0,0 +1,23 @@
+package clients
+
+import (
+	"github.com/realvnc-labs/rport/server/metrics"
+)
+
+const (
+	tunnelCloseReasonTerminated         = "terminated"
+	tunnelCloseReasonClientDisconnected = "client_disconnected"
+)
+
+var (
+	clientConnectionsTotal = metrics.NewCounter("rport_client_connections_total",
+		"Number of client connections accepted by the server.")
+	clientDisconnectionsTotal = metrics.NewCounter("rport_client_disconnections_total",
+		"Number of client connections closed.")
+	tunnelsOpenedTotal = metrics.NewCounter("rport_tunnels_opened_total",
+		"Number of tunnels started by protocol.", "protocol")
+	tunnelsClosedTotal = metrics.NewCounter("rport_tunnels_closed_total",
+		"Number of tunnels closed by protocol and reason.", "protocol", "reason")
+	tunnelTrafficBytesTotal = metrics.NewCounter("rport_tunnel_traffic_bytes_total",
+		"Bytes transferred through tunnels, in from the tunnel users to the clients, out from the clients to the users.", "direction")
+)
@@ -11,39 +11,48 @@ import (
 )
 
 type ClientPayload struct {
-	ID                     *string                 `json:"id,omitempty"`
-	Name                   *string                 `json:"name,omitempty"`
-	Address                *string                 `json:"address,omitempty"`
-	Hostname               *string                 `json:"hostname,omitempty"`
-	OS                     *string                 `json:"os,omitempty"`
-	OSFullName             *string                 `json:"os_full_name,omitempty"`
-	OSVersion              *string                 `json:"os_version,omitempty"`
-	OSArch                 *string                 `json:"os_arch,omitempty"`
-	OSFamily               *string                 `json:"os_family,omitempty"`
-	OSKernel               *string                 `json:"os_kernel,omitempty"`
-	OSVirtualizationSystem *string                 `json:"os_virtualization_system,omitempty"`
-	OSVirtualizationRole   *string                 `json:"os_virtualization_role,omitempty"`
-	NumCPUs                *int                    `json:"num_cpus,omitempty"`
-	CPUFamily              *string                 `json:"cpu_family,omitempty"`
-	CPUModel               *string                 `json:"cpu_model,omitempty"`
-	CPUModelName           *string                 `json:"cpu_model_name,omitempty"`
-	CPUVendor              *string                 `json:"cpu_vendor,omitempty"`
-	MemoryTotal            *uint64                 `json:"mem_total,omitempty"`
-	Timezone               *string                 `json:"timezone,omitempty"`
-	ClientAuthID           *string                 `json:"client_auth_id,omitempty"`
-	Version                *string                 `json:"version,omitempty"`
-	DisconnectedAt         **time.Time             `json:"disconnected_at,omitempty"`
-	LastHeartbeatAt        **time.Time             `json:"last_heartbeat_at,omitempty"`
-	ConnectionState        *string                 `json:"connection_state,omitempty"`
-	IPv4                   *[]string               `json:"ipv4,omitempty"`
-	IPv6                   *[]string               `json:"ipv6,omitempty"`
-	Tags                   *[]string               `json:"tags,omitempty"`
-	AllowedUserGroups      *[]string               `json:"allowed_user_groups,omitempty"`
-	Tunnels                *[]*clienttunnel.Tunnel `json:"tunnels,omitempty"`
-	UpdatesStatus          **models.UpdatesStatus  `json:"updates_status,omitempty"`
-	ClientConfiguration    **clientconfig.Config   `json:"client_configuration,omitempty"`
-	Groups                 *[]string               `json:"groups,omitempty"`
-	Labels                 *map[string]string      `json:"labels,omitempty"`
+	ID                     *string                             `json:"id,omitempty"`
+	Name                   *string                             `json:"name,omitempty"`
+	Address                *string                             `json:"address,omitempty"`
+	Hostname               *string                             `json:"hostname,omitempty"`
+	OS                     *string                             `json:"os,omitempty"`
+	OSFullName             *string                             `json:"os_full_name,omitempty"`
+	OSVersion              *string                             `json:"os_version,omitempty"`
+	OSArch                 *string                             `json:"os_arch,omitempty"`
+	OSFamily               *string                             `json:"os_family,omitempty"`
+	OSKernel               *string                             `json:"os_kernel,omitempty"`
+	OSVirtualizationSystem *string                             `json:"os_virtualization_system,omitempty"`
+	OSVirtualizationRole   *string                             `json:"os_virtualization_role,omitempty"`
+	NumCPUs                *int                                `json:"num_cpus,omitempty"`
+	CPUFamily              *string                             `json:"cpu_family,omitempty"`
+	CPUModel               *string                             `json:"cpu_model,omitempty"`
+	CPUModelName           *string                             `json:"cpu_model_name,omitempty"`
+	CPUVendor              *string                             `json:"cpu_vendor,omitempty"`
+	MemoryTotal            *uint64                             `json:"mem_total,omitempty"`
+	Timezone               *string                             `json:"timezone,omitempty"`
+	ClientAuthID           *string                             `json:"client_auth_id,omitempty"`
+	Version                *string                             `json:"version,omitempty"`
+	DisconnectedAt         **time.Time                         `json:"disconnected_at,omitempty"`
+	LastHeartbeatAt        **time.Time                         `json:"last_heartbeat_at,omitempty"`
+	AgentStats             **models.AgentStats                 `json:"agent_stats,omitempty"`
+	ConnectionState        *string                             `json:"connection_state,omitempty"`
+	IPv4                   *[]string                           `json:"ipv4,omitempty"`
+	IPv6                   *[]string                           `json:"ipv6,omitempty"`
+	Tags                   *[]string                           `json:"tags,omitempty"`
+	AllowedUserGroups      *[]string                           `json:"allowed_user_groups,omitempty"`
+	Tunnels                *[]*clienttunnel.Tunnel             `json:"tunnels,omitempty"`
+	UpdatesStatus          **models.UpdatesStatus              `json:"updates_status,omitempty"`
+	Interpreters           *[]models.Interpreter               `json:"interpreters,omitempty"`
+	ClientConfiguration    **clientconfig.Config               `json:"client_configuration,omitempty"`
+	Groups                 *[]string                           `json:"groups,omitempty"`
+	Labels                 *map[string]string                  `json:"labels,omitempty"`
+	EOLDate                **time.Time                         `json:"eol_date,omitempty"`
+	IsEOL                  *bool                               `json:"is_eol,omitempty"`
+	Outdated               *bool                               `json:"outdated,omitempty"`
+	Quarantine             **clientdata.Quarantine             `json:"quarantine,omitempty"`
+	MonitoringProfile      **clientdata.MonitoringProfileState `json:"monitoring_profile,omitempty"`
+	ProtocolVersion        *int                                `json:"protocol_version,omitempty"`
+	Features               *[]string                           `json:"features,omitempty"`
 }
 
 func ConvertToClientsPayload(clientsList []*clientdata.CalculatedClient, fields []query.FieldsOption) []ClientPayload {
@@ -102,6 +111,9 @@ func ConvertToClientPayload(client *clientdata.CalculatedClient, fields []query.
 		case "last_heartbeat_at":
 			lastHeartbeatAt := client.LastHeartbeatAt
 			p.LastHeartbeatAt = &lastHeartbeatAt
+		case "agent_stats":
+			agentStats := client.AgentStats
+			p.AgentStats = &agentStats
 		case "client_auth_id":
 			p.ClientAuthID = &client.ClientAuthID
 		case "os_full_name":
@@ -130,10 +142,28 @@ func ConvertToClientPayload(client *clientdata.CalculatedClient, fields []query.
 			p.AllowedUserGroups = &client.AllowedUserGroups
 		case "updates_status":
 			p.UpdatesStatus = &client.UpdatesStatus
+		case "interpreters":
+			p.Interpreters = &client.Interpreters
 		case "client_configuration":
 			p.ClientConfiguration = &client.ClientConfiguration
 		case "groups":
 			p.Groups = &client.Groups
+		case "eol_date":
+			eolDate := client.EOLDate
+			p.EOLDate = &eolDate
+		case "is_eol":
+			isEOL := client.IsEOL
+			p.IsEOL = &isEOL
+		case "outdated":
+			p.Outdated = &client.Outdated
+		case "quarantine":
+			p.Quarantine = &client.Quarantine
+		case "monitoring_profile":
+			p.MonitoringProfile = &client.MonitoringProfile
+		case "protocol_version":
+			p.ProtocolVersion = &client.ProtocolVersion
+		case "features":
+			p.Features = &client.Features
 		case "connection_state":
 			connectionState := string(client.GetConnectionState())
 			p.ConnectionState = &connectionState
@@ -0,0 +1,59 @@
+//go:build !windows
+// +build !windows
+
+package clients
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mapset "github.com/deckarep/golang-set"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/ports"
+	"github.com/realvnc-labs/rport/server/preconnect"
+	chshare "github.com/realvnc-labs/rport/share"
+	"github.com/realvnc-labs/rport/share/test"
+)
+
+func TestStartClientPreConnect(t *testing.T) {
+	connMock := test.NewConnMock()
+	connMock.ReturnRemoteAddr = &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 2345}
+
+	script := filepath.Join(t.TempDir(), "pre-connect.sh")
+	err := os.WriteFile(script, []byte(`#!/bin/sh
+if [ "$RPORT_CLIENT_VERSION" = "0.8.0" ]; then
+  echo '{"reject": true, "reason": "unsupported version"}'
+else
+  echo '{"tags": ["managed"], "user_groups": ["support"]}'
+fi
+`), 0700)
+	require.NoError(t, err)
+	config := preconnect.Config{Script: script}
+	require.NoError(t, config.Validate())
+
+	cs := &ClientServiceProvider{
+		repo:            NewClientRepository([]*clientdata.Client{}, nil, testLog),
+		portDistributor: ports.NewPortDistributor(mapset.NewSet()),
+		logger:          testLog,
+	}
+	cs.SetPreConnectScript(preconnect.New(config, testLog))
+
+	_, err = cs.StartClient(
+		context.Background(), "client-auth-1", "client-1", connMock, false,
+		&chshare.ConnectionRequest{Version: "0.8.0", Tags: []string{"linux"}}, testLog)
+	assert.EqualError(t, err, "connection rejected: unsupported version")
+	assert.Equal(t, 0, cs.Count())
+
+	client, err := cs.StartClient(
+		context.Background(), "client-auth-1", "client-1", connMock, false,
+		&chshare.ConnectionRequest{Version: "0.9.0", Tags: []string{"linux"}}, testLog)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"managed"}, client.GetTags())
+	assert.Equal(t, []string{"support"}, client.GetAllowedUserGroups())
+}
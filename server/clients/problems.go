@@ -0,0 +1,84 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+
+	alertingcap "github.com/realvnc-labs/rport/plus/capabilities/alerting"
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/entities/rules"
+	"github.com/realvnc-labs/rport/server/cgroups"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+// ProblemsSource provides the problems and rules of the alerting service.
+type ProblemsSource interface {
+	GetLatestProblems(limit int) (problems []*rules.Problem, err error)
+	LoadRuleSet(ruleSetID rules.RuleSetID) (rs *rules.RuleSet, err error)
+}
+
+type ProblemsTask struct {
+	log    *logger.Logger
+	cr     *ClientRepository
+	source ProblemsSource
+}
+
+// NewProblemsTask returns a task to refresh the open problems of all clients, used to match client groups by alerting state.
+func NewProblemsTask(log *logger.Logger, cr *ClientRepository, source ProblemsSource) *ProblemsTask {
+	return &ProblemsTask{
+		log:    log,
+		cr:     cr,
+		source: source,
+	}
+}
+
+func (t *ProblemsTask) Run(ctx context.Context) error {
+	problems, err := t.source.GetLatestProblems(alertingcap.NoLimit)
+	if err != nil {
+		return fmt.Errorf("failed to get problems: %v", err)
+	}
+
+	severities := map[rules.RuleID]string{}
+	rs, err := t.source.LoadRuleSet(rules.DefaultRuleSetID)
+	if err != nil {
+		// problems are still counted, without severity
+		t.log.Infof("Failed to load rule set to get the severity of problems: %v", err)
+	} else if rs != nil {
+		for _, rule := range rs.Rules {
+			severities[rule.ID] = string(rule.Severity)
+		}
+	}
+
+	summaries := summarizeProblems(problems, severities)
+	updated := 0
+	for _, client := range t.cr.GetAllClients() {
+		summary := summaries[client.GetID()]
+		if client.GetOpenProblems() == summary {
+			continue
+		}
+		client.SetOpenProblems(summary)
+		updated++
+	}
+
+	if updated > 0 {
+		t.log.Debugf("Updated open problems of %d client(s).", updated)
+	}
+
+	return nil
+}
+
+func summarizeProblems(problems []*rules.Problem, severities map[rules.RuleID]string) map[string]clientdata.ProblemsSummary {
+	summaries := make(map[string]clientdata.ProblemsSummary)
+	for _, problem := range problems {
+		if problem == nil || !problem.Active {
+			continue
+		}
+		summary := summaries[problem.ClientID]
+		summary.Count++
+		if sev := severities[problem.RuleID]; cgroups.SeverityRank(sev) > cgroups.SeverityRank(summary.MaxSeverity) {
+			summary.MaxSeverity = sev
+		}
+		summaries[problem.ClientID] = summary
+	}
+	return summaries
+}
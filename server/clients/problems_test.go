@@ -0,0 +1,71 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/entities/rules"
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/entities/severity"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+)
+
+type fakeProblemsSource struct {
+	problems   []*rules.Problem
+	ruleSet    *rules.RuleSet
+	ruleSetErr error
+}
+
+func (f *fakeProblemsSource) GetLatestProblems(limit int) ([]*rules.Problem, error) {
+	return f.problems, nil
+}
+
+func (f *fakeProblemsSource) LoadRuleSet(ruleSetID rules.RuleSetID) (*rules.RuleSet, error) {
+	return f.ruleSet, f.ruleSetErr
+}
+
+func TestProblemsTask(t *testing.T) {
+	// given
+	c1 := New(t).ID("client-1").Logger(testLog).Build()
+	c2 := New(t).ID("client-2").Logger(testLog).Build()
+	c3 := New(t).ID("client-3").Logger(testLog).Build()
+	c3.SetOpenProblems(clientdata.ProblemsSummary{Count: 1, MaxSeverity: string(severity.High)}) // resolved since
+	hour := time.Hour
+	repo := NewClientRepository([]*clientdata.Client{c1, c2, c3}, &hour, testLog)
+	source := &fakeProblemsSource{
+		problems: []*rules.Problem{
+			{RuleID: "cpu", ClientID: "client-1", Active: true},
+			{RuleID: "disk", ClientID: "client-1", Active: true},
+			{RuleID: "mem", ClientID: "client-2", Active: true},
+			{RuleID: "disk", ClientID: "client-3", Active: false},
+		},
+		ruleSet: &rules.RuleSet{
+			Rules: []rules.Rule{
+				{ID: "cpu", Severity: severity.Warning},
+				{ID: "disk", Severity: severity.Disaster},
+			},
+		},
+	}
+	task := NewProblemsTask(testLog, repo, source)
+
+	// when
+	err := task.Run(context.Background())
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, clientdata.ProblemsSummary{Count: 2, MaxSeverity: string(severity.Disaster)}, c1.GetOpenProblems())
+	assert.Equal(t, clientdata.ProblemsSummary{Count: 1}, c2.GetOpenProblems())
+	assert.Equal(t, clientdata.ProblemsSummary{}, c3.GetOpenProblems())
+
+	// when the rule set fails to load
+	source.ruleSetErr = errors.New("rule set error")
+	err = task.Run(context.Background())
+
+	// then problems are counted without severity
+	require.NoError(t, err)
+	assert.Equal(t, clientdata.ProblemsSummary{Count: 2}, c1.GetOpenProblems())
+}
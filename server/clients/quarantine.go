@@ -0,0 +1,54 @@
+package clients
+
+import (
+	"net/http"
+
+	apiErrors "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+)
+
+// Quarantine denies all tunnels, jobs and file transfers of the client until released, open tunnels are closed.
+// The control channel of the client is kept.
+func (s *ClientServiceProvider) Quarantine(clientID, reason, by string) (*clientdata.Client, error) {
+	unlock := s.clientLocks.lock(clientID)
+	defer unlock()
+
+	client, err := s.getExistingClientByID(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	client.SetQuarantine(&clientdata.Quarantine{
+		Reason: reason,
+		By:     by,
+		At:     clientdata.Now(),
+	})
+	s.log().Infof("client %s quarantined by %s: %s", clientID, by, reason)
+
+	for _, t := range client.GetTunnels() {
+		if err := s.TerminateTunnel(client, t, true); err != nil {
+			s.log().Errorf("Failed to close tunnel %s of quarantined client %s: %v", t.ID, clientID, err)
+		}
+	}
+
+	return client, s.repo.Save(client)
+}
+
+// Release lifts the quarantine of the client.
+func (s *ClientServiceProvider) Release(clientID string) (*clientdata.Client, error) {
+	unlock := s.clientLocks.lock(clientID)
+	defer unlock()
+
+	client, err := s.getExistingClientByID(clientID)
+	if err != nil {
+		return nil, err
+	}
+	if !client.IsQuarantined() {
+		return nil, apiErrors.NewAPIError(http.StatusConflict, "", "Client is not quarantined.", nil)
+	}
+
+	client.SetQuarantine(nil)
+	s.log().Infof("client %s released from quarantine", clientID)
+
+	return client, s.repo.Save(client)
+}
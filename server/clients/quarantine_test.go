@@ -0,0 +1,52 @@
+package clients
+
+import (
+	"net/http"
+	"testing"
+
+	mapset "github.com/deckarep/golang-set"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiErrors "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/ports"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func TestQuarantine(t *testing.T) {
+	c1 := New(t).Logger(testLog).Build()
+	c1.Tunnels = nil
+	cs := &ClientServiceProvider{
+		repo:            NewClientRepository([]*clientdata.Client{c1}, nil, testLog),
+		portDistributor: ports.NewPortDistributor(mapset.NewSet()),
+		logger:          testLog,
+	}
+
+	client, err := cs.Quarantine(c1.GetID(), "suspicious traffic", "admin")
+	require.NoError(t, err)
+	require.True(t, client.IsQuarantined())
+	assert.Equal(t, "suspicious traffic", client.GetQuarantine().Reason)
+	assert.Equal(t, "admin", client.GetQuarantine().By)
+
+	_, err = cs.StartClientTunnels(client, []*models.Remote{{LocalHost: "0.0.0.0", RemoteHost: "0.0.0.0", RemotePort: "22"}})
+	assert.Equal(t, apiErrors.APIError{
+		Message:    "client is quarantined (reason = suspicious traffic), release it first",
+		HTTPStatus: http.StatusConflict,
+		ErrCode:    apiErrors.ErrCodeClientQuarantined,
+	}, err)
+
+	client, err = cs.Release(c1.GetID())
+	require.NoError(t, err)
+	assert.False(t, client.IsQuarantined())
+	assert.NoError(t, client.CheckQuarantine())
+
+	_, err = cs.Release(c1.GetID())
+	assert.Equal(t, apiErrors.APIError{
+		Message:    "Client is not quarantined.",
+		HTTPStatus: http.StatusConflict,
+	}, err)
+
+	_, err = cs.Quarantine("unknown", "suspicious traffic", "admin")
+	assert.Error(t, err)
+}
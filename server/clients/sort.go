@@ -1,12 +1,43 @@
 package clients
 
 import (
+	"net/http"
 	"sort"
 	"strings"
 
+	apiErrors "github.com/realvnc-labs/rport/server/api/errors"
 	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/share/query"
 )
 
+// GetSortFunc returns the function sorting the clients by the sort option and whether the order is descending.
+func GetSortFunc(sorts []query.SortOption) (sortFunc func(a []*clientdata.CalculatedClient, desc bool), desc bool, err error) {
+	if len(sorts) < 1 {
+		return SortByID, false, nil
+	}
+	if len(sorts) > 1 {
+		return nil, false, apiErrors.APIError{
+			Message:    "Only one sort field is supported for clients.",
+			HTTPStatus: http.StatusBadRequest,
+		}
+	}
+
+	switch sorts[0].Column {
+	case "id":
+		sortFunc = SortByID
+	case "name":
+		sortFunc = SortByName
+	case "os":
+		sortFunc = SortByOS
+	case "hostname":
+		sortFunc = SortByHostname
+	case "version":
+		sortFunc = SortByVersion
+	}
+
+	return sortFunc, !sorts[0].IsASC, nil
+}
+
 func SortByID(a []*clientdata.CalculatedClient, desc bool) {
 	sort.Slice(a, func(i, j int) bool {
 		less := strings.ToLower(a[i].GetID()) < strings.ToLower(a[j].GetID())
@@ -1,11 +1,16 @@
 package clients
 
 import (
+	"fmt"
+	"reflect"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/share/query"
 )
 
 func TestSortByIDAsc(t *testing.T) {
@@ -125,3 +130,89 @@ func TestSortByHostnameDesc(t *testing.T) {
 	// then
 	assert.ElementsMatch(t, a, []*clientdata.CalculatedClient{c7H, c6H, c5H, c4H, c3H, c2H, c1H})
 }
+
+func TestGetSortFuncPositive(t *testing.T) {
+	testCases := []struct {
+		sortStr string
+
+		wantFunc func(a []*clientdata.CalculatedClient, desc bool)
+		wantDesc bool
+	}{
+		{
+			sortStr:  "",
+			wantFunc: SortByID,
+			wantDesc: false,
+		},
+		{
+			sortStr:  "id",
+			wantFunc: SortByID,
+			wantDesc: false,
+		},
+		{
+			sortStr:  "-id",
+			wantFunc: SortByID,
+			wantDesc: true,
+		},
+		{
+			sortStr:  "name",
+			wantFunc: SortByName,
+			wantDesc: false,
+		},
+		{
+			sortStr:  "-name",
+			wantFunc: SortByName,
+			wantDesc: true,
+		},
+		{
+			sortStr:  "hostname",
+			wantFunc: SortByHostname,
+			wantDesc: false,
+		},
+		{
+			sortStr:  "-hostname",
+			wantFunc: SortByHostname,
+			wantDesc: true,
+		},
+		{
+			sortStr:  "os",
+			wantFunc: SortByOS,
+			wantDesc: false,
+		},
+		{
+			sortStr:  "-os",
+			wantFunc: SortByOS,
+			wantDesc: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.sortStr, func(t *testing.T) {
+			t.Parallel()
+
+			// when
+			sortOptions := query.ParseSortOptions(map[string][]string{"sort": {tc.sortStr}})
+			gotFunc, gotDesc, gotErr := GetSortFunc(sortOptions)
+
+			// then
+			// workaround to compare func vars, see https://github.com/stretchr/testify/issues/182
+			wantFuncName := runtime.FuncForPC(reflect.ValueOf(tc.wantFunc).Pointer()).Name()
+			gotFuncName := runtime.FuncForPC(reflect.ValueOf(gotFunc).Pointer()).Name()
+			msg := fmt.Sprintf("GetSortFunc(%q) = (%s, %v, %v), expected: (%s, %v, %v)", tc.sortStr, gotFuncName, gotDesc, gotErr, wantFuncName, tc.wantDesc, nil)
+
+			assert.NoErrorf(t, gotErr, msg)
+			assert.Equalf(t, wantFuncName, gotFuncName, msg)
+			assert.Equalf(t, tc.wantDesc, gotDesc, msg)
+		})
+	}
+}
+
+func TestGetSortFuncError(t *testing.T) {
+	// when
+	sortOptions := query.ParseSortOptions(map[string][]string{"sort": {"id", "-name"}})
+	_, _, gotErr := GetSortFunc(sortOptions)
+
+	// then
+	require.Error(t, gotErr)
+	assert.Equal(t, gotErr.Error(), "Only one sort field is supported for clients.")
+}
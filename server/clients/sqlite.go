@@ -17,6 +17,7 @@ import (
 	chshare "github.com/realvnc-labs/rport/share/clientconfig"
 	"github.com/realvnc-labs/rport/share/logger"
 	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/query"
 )
 
 type ClientStore interface {
@@ -24,16 +25,27 @@ type ClientStore interface {
 	Save(ctx context.Context, client *clientdata.Client) error
 	DeleteObsolete(ctx context.Context, l *logger.Logger) error
 	Delete(ctx context.Context, id string, l *logger.Logger) error
+	ListChanges(ctx context.Context, options *query.ListOptions) ([]*ClientChange, error)
+	CountChanges(ctx context.Context, options *query.ListOptions) (int, error)
+	DeleteChangesOlderThan(ctx context.Context, period time.Duration) (int64, error)
 	Close() error
 }
 
 type SqliteProvider struct {
-	db                      *sqlx.DB
+	db *sqlx.DB
+	// reader is used for list queries
+	reader                  *sqlite.ReadReplica
+	converter               *query.SQLConverter
 	keepDisconnectedClients *time.Duration
 }
 
 func newSqliteProvider(db *sqlx.DB, keepDisconnectedClients *time.Duration) *SqliteProvider {
-	return &SqliteProvider{db: db, keepDisconnectedClients: keepDisconnectedClients}
+	return &SqliteProvider{
+		db:                      db,
+		reader:                  sqlite.NewReadReplica(db, nil, nil),
+		converter:               query.NewSQLConverter(db.DriverName()),
+		keepDisconnectedClients: keepDisconnectedClients,
+	}
 }
 
 func (p *SqliteProvider) GetAll(ctx context.Context, l *logger.Logger) ([]*clientdata.Client, error) {
@@ -70,11 +82,9 @@ func (p *SqliteProvider) Save(ctx context.Context, client *clientdata.Client) er
 
 	_, err := sqlite.WithRetryWhenBusy(func() (result sql.Result, err error) {
 
-		_, err = p.db.NamedExecContext(
-			ctx,
-			"INSERT OR REPLACE INTO clients (id, client_auth_id, disconnected_at, details) VALUES (:id, :client_auth_id, :disconnected_at, :details)",
-			clientForSQL,
-		)
+		err = p.inTx(ctx, func(tx *sqlx.Tx) error {
+			return p.saveWithChange(ctx, tx, clientForSQL)
+		})
 
 		return nil, err
 	}, "save", client.Log())
@@ -85,12 +95,31 @@ func (p *SqliteProvider) Save(ctx context.Context, client *clientdata.Client) er
 func (p *SqliteProvider) DeleteObsolete(ctx context.Context, l *logger.Logger) error {
 	_, err := sqlite.WithRetryWhenBusy(func() (result sql.Result, err error) {
 
-		_, err = p.db.ExecContext(
-			ctx,
-			"DELETE FROM clients WHERE disconnected_at IS NOT NULL AND DATETIME(disconnected_at) < DATETIME(?) AND ?",
-			p.keepDisconnectedClientsStart(),
-			p.keepDisconnectedClients != nil,
-		)
+		err = p.inTx(ctx, func(tx *sqlx.Tx) error {
+			var ids []string
+			err := tx.SelectContext(
+				ctx,
+				&ids,
+				"SELECT id FROM clients WHERE disconnected_at IS NOT NULL AND DATETIME(disconnected_at) < DATETIME(?) AND ?",
+				p.keepDisconnectedClientsStart(),
+				p.keepDisconnectedClients != nil,
+			)
+			if err != nil {
+				return err
+			}
+
+			if err := recordClientsDeleted(ctx, tx, ids); err != nil {
+				return err
+			}
+
+			_, err = tx.ExecContext(
+				ctx,
+				"DELETE FROM clients WHERE disconnected_at IS NOT NULL AND DATETIME(disconnected_at) < DATETIME(?) AND ?",
+				p.keepDisconnectedClientsStart(),
+				p.keepDisconnectedClients != nil,
+			)
+			return err
+		})
 
 		return nil, err
 	}, "delete obsolete", l)
@@ -101,7 +130,16 @@ func (p *SqliteProvider) DeleteObsolete(ctx context.Context, l *logger.Logger) e
 func (p *SqliteProvider) Delete(ctx context.Context, id string, l *logger.Logger) error {
 	_, err := sqlite.WithRetryWhenBusy(func() (result sql.Result, err error) {
 
-		_, err = p.db.ExecContext(ctx, "DELETE FROM clients WHERE id = ?", id)
+		err = p.inTx(ctx, func(tx *sqlx.Tx) error {
+			res, err := tx.ExecContext(ctx, "DELETE FROM clients WHERE id = ?", id)
+			if err != nil {
+				return err
+			}
+			if n, err := res.RowsAffected(); err != nil || n == 0 {
+				return err
+			}
+			return recordClientsDeleted(ctx, tx, []string{id})
+		})
 
 		return nil, err
 	}, "delete", l)
@@ -109,7 +147,24 @@ func (p *SqliteProvider) Delete(ctx context.Context, id string, l *logger.Logger
 	return err
 }
 
+func (p *SqliteProvider) inTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (p *SqliteProvider) Close() error {
+	if err := p.reader.Close(); err != nil {
+		return err
+	}
 	return p.db.Close()
 }
 
@@ -155,9 +210,19 @@ func convertToSqlite(c *clientdata.Client) (res *clientSqlite) {
 			Tags:                   c.Tags,
 			Labels:                 c.Labels,
 			Tunnels:                c.Tunnels,
+			TunnelSessions:         tunnelSessions(c.Tunnels),
 			AllowedUserGroups:      c.AllowedUserGroups,
 			UpdatesStatus:          c.UpdatesStatus,
+			Interpreters:           c.Interpreters,
 			ClientConfig:           c.ClientConfiguration,
+			AutoTags:               c.AutoTags,
+			Disconnects:            c.Disconnects,
+			AddressChanges:         c.AddressChanges,
+			TunnelClosures:         c.TunnelClosures,
+			ReverseTunnels:         c.ReverseTunnels,
+			SecuritySnapshot:       c.SecuritySnapshot,
+			ProtocolVersion:        c.ProtocolVersion,
+			Features:               c.Features,
 		},
 	}
 	c.GetLock().RUnlock()
@@ -202,7 +267,22 @@ type clientDetails struct {
 	Tunnels                []*clienttunnel.Tunnel `json:"tunnels"`
 	AllowedUserGroups      []string               `json:"allowed_user_groups"`
 	UpdatesStatus          *models.UpdatesStatus  `json:"updates_status"`
+	Interpreters           []models.Interpreter   `json:"interpreters,omitempty"`
 	ClientConfig           *chshare.Config        `json:"client_configuration"`
+	AutoTags               []string               `json:"auto_tags,omitempty"`
+	Disconnects            []time.Time            `json:"disconnects,omitempty"`
+
+	AddressChanges []clientdata.AddressChange `json:"address_changes,omitempty"`
+	TunnelClosures []clientdata.TunnelClosure `json:"tunnel_closures,omitempty"`
+	ReverseTunnels []*models.ReverseTunnel    `json:"reverse_tunnels,omitempty"`
+
+	// TunnelSessions holds the api sessions of ephemeral tunnels by tunnel id, they aren't part of the tunnel json
+	TunnelSessions map[string]int64 `json:"tunnel_sessions,omitempty"`
+
+	SecuritySnapshot *models.SecuritySnapshot `json:"security_snapshot,omitempty"`
+
+	ProtocolVersion int      `json:"protocol_version,omitempty"`
+	Features        []string `json:"features,omitempty"`
 }
 
 func (d *clientDetails) Scan(value interface{}) error {
@@ -262,15 +342,41 @@ func (s *clientSqlite) convert(l *logger.Logger) (res *clientdata.Client) {
 		Timezone:               d.Timezone,
 		AllowedUserGroups:      d.AllowedUserGroups,
 		UpdatesStatus:          d.UpdatesStatus,
+		Interpreters:           d.Interpreters,
 		ClientConfiguration:    d.ClientConfig,
+		AutoTags:               d.AutoTags,
+		Disconnects:            d.Disconnects,
+		AddressChanges:         d.AddressChanges,
+		TunnelClosures:         d.TunnelClosures,
+		ReverseTunnels:         d.ReverseTunnels,
+		SecuritySnapshot:       d.SecuritySnapshot,
+		ProtocolVersion:        d.ProtocolVersion,
+		Features:               d.Features,
 		Logger:                 l,
 	}
+	for _, t := range res.Tunnels {
+		t.SessionID = d.TunnelSessions[t.ID]
+	}
 	if s.DisconnectedAt.Valid {
 		res.SetDisconnectedAt(&s.DisconnectedAt.Time)
 	}
 	return res
 }
 
+func tunnelSessions(tunnels []*clienttunnel.Tunnel) map[string]int64 {
+	var res map[string]int64
+	for _, t := range tunnels {
+		if t.SessionID == 0 {
+			continue
+		}
+		if res == nil {
+			res = make(map[string]int64)
+		}
+		res[t.ID] = t.SessionID
+	}
+	return res
+}
+
 func convertClientList(list []*clientSqlite, l *logger.Logger) []*clientdata.Client {
 	res := make([]*clientdata.Client, 0, len(list))
 	for _, cur := range list {
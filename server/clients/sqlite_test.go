@@ -2,6 +2,7 @@ package clients
 
 import (
 	"context"
+	"strconv"
 	"testing"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/share/query"
 )
 
 func TestClientsSqliteProvider(t *testing.T) {
@@ -70,3 +72,58 @@ func TestClientsSqliteProvider(t *testing.T) {
 	require.NoError(t, err)
 	assert.ElementsMatch(t, []*clientdata.Client{c1, c2, c3, c4}, gotAll)
 }
+
+func TestClientsSqliteProviderChanges(t *testing.T) {
+	ctx := context.Background()
+	p := NewFakeClientProvider(t, nil)
+	defer p.Close()
+
+	c1 := New(t).ID("client-1").Logger(testLog).Build()
+	require.NoError(t, p.Save(ctx, c1))
+
+	// saving without changes doesn't record a change
+	require.NoError(t, p.Save(ctx, c1))
+
+	c1.SetHostname("new-hostname")
+	require.NoError(t, p.Save(ctx, c1))
+	require.NoError(t, p.Delete(ctx, c1.GetID(), testLog))
+
+	// deleting an unknown client doesn't record a change
+	require.NoError(t, p.Delete(ctx, "unknown-id", testLog))
+
+	options := &query.ListOptions{Sorts: []query.SortOption{{Column: "revision", IsASC: true}}}
+	changes, err := p.ListChanges(ctx, options)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	assert.Equal(t, ChangeTypeCreated, changes[0].Type)
+	assert.Equal(t, "client-1", changes[0].ClientID)
+	assert.Contains(t, changes[0].Changes, "name")
+	assert.Contains(t, changes[0].Changes, "hostname")
+
+	assert.Equal(t, ChangeTypeUpdated, changes[1].Type)
+	assert.Equal(t, ChangedFields{"hostname": []byte(`"new-hostname"`)}, changes[1].Changes)
+
+	assert.Equal(t, ChangeTypeDeleted, changes[2].Type)
+	assert.Empty(t, changes[2].Changes)
+
+	assert.Less(t, changes[0].Revision, changes[1].Revision)
+	assert.Less(t, changes[1].Revision, changes[2].Revision)
+
+	options.Filters = []query.FilterOption{{
+		Column:   []string{"revision"},
+		Operator: query.FilterOperatorTypeGT,
+		Values:   []string{strconv.FormatInt(changes[0].Revision, 10)},
+	}}
+	since, err := p.ListChanges(ctx, options)
+	require.NoError(t, err)
+	assert.Equal(t, changes[1:], since)
+
+	count, err := p.CountChanges(ctx, options)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	deleted, err := p.DeleteChangesOlderThan(ctx, -time.Minute)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, deleted)
+}
@@ -102,3 +102,17 @@ func (m *Manager) Update(ctx context.Context, clientID string, t *StoredTunnel)
 func (m *Manager) Delete(ctx context.Context, clientID, id string) error {
 	return m.provider.Delete(ctx, clientID, id)
 }
+
+// GetByName returns the stored tunnel of the client with the given name or nil if not found.
+func (m *Manager) GetByName(ctx context.Context, clientID, name string) (*StoredTunnel, error) {
+	entries, err := m.provider.List(ctx, clientID, &query.ListOptions{
+		Filters: []query.FilterOption{{Column: []string{"name"}, Values: []string{name}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return entries[0], nil
+}
@@ -54,3 +54,24 @@ func TestStoredTunnels(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 0, results.Meta.Count)
 }
+
+func TestStoredTunnelsGetByName(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlite.New(":memory:", clients.AssetNames(), clients.Asset, DataSourceOptions)
+	require.NoError(t, err)
+	manager := New(db)
+
+	ssh, err := manager.Create(ctx, "client-1", &StoredTunnel{Name: "ssh"})
+	require.NoError(t, err)
+	_, err = manager.Create(ctx, "client-1", &StoredTunnel{Name: "rdp"})
+	require.NoError(t, err)
+
+	result, err := manager.GetByName(ctx, "client-1", "ssh")
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, ssh.ID, result.ID)
+
+	result, err = manager.GetByName(ctx, "client-2", "ssh")
+	require.NoError(t, err)
+	assert.Nil(t, result)
+}
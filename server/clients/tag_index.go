@@ -0,0 +1,132 @@
+package clients
+
+import (
+	"strings"
+
+	"github.com/realvnc-labs/rport/server/cgroups"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+)
+
+// tagIndex maps the lower cased tags and auto tags of clients to the client ids to avoid scanning all clients
+// on tag and client group lookups. It's not thread-safe, the ClientRepository lock guards it.
+type tagIndex struct {
+	clientIDs map[string]map[string]bool
+	// tags are the indexed tags per client id, used to remove outdated entries on updates
+	tags map[string][]string
+}
+
+func newTagIndex() *tagIndex {
+	return &tagIndex{
+		clientIDs: make(map[string]map[string]bool),
+		tags:      make(map[string][]string),
+	}
+}
+
+// indexedTags returns the lower cased tags and auto tags of the client.
+// It's called before locking the repository as it locks the client.
+func indexedTags(c *clientdata.Client) []string {
+	tags := c.GetTags()
+	tags = append(tags, c.GetAutoTags()...)
+	res := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(tag)
+		if !seen[tag] {
+			seen[tag] = true
+			res = append(res, tag)
+		}
+	}
+	return res
+}
+
+func (i *tagIndex) update(clientID string, tags []string) {
+	i.remove(clientID)
+	for _, tag := range tags {
+		ids := i.clientIDs[tag]
+		if ids == nil {
+			ids = make(map[string]bool)
+			i.clientIDs[tag] = ids
+		}
+		ids[clientID] = true
+	}
+	i.tags[clientID] = tags
+}
+
+func (i *tagIndex) remove(clientID string) {
+	for _, tag := range i.tags[clientID] {
+		ids := i.clientIDs[tag]
+		delete(ids, clientID)
+		if len(ids) == 0 {
+			delete(i.clientIDs, tag)
+		}
+	}
+	delete(i.tags, clientID)
+}
+
+// lookup returns the ids of the clients having all of the given tags if and is true, one of them otherwise.
+// Tags are compared case-insensitive, so callers must check the exact match.
+func (i *tagIndex) lookup(tags []string, and bool) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	if !and {
+		seen := make(map[string]bool)
+		var res []string
+		for _, tag := range tags {
+			for id := range i.clientIDs[strings.ToLower(tag)] {
+				if !seen[id] {
+					seen[id] = true
+					res = append(res, id)
+				}
+			}
+		}
+		return res
+	}
+
+	// start with the smallest set to intersect
+	sets := make([]map[string]bool, 0, len(tags))
+	for _, tag := range tags {
+		ids := i.clientIDs[strings.ToLower(tag)]
+		if len(ids) == 0 {
+			return nil
+		}
+		sets = append(sets, ids)
+	}
+	smallest := 0
+	for j := range sets {
+		if len(sets[j]) < len(sets[smallest]) {
+			smallest = j
+		}
+	}
+
+	var res []string
+nextID:
+	for id := range sets[smallest] {
+		for _, ids := range sets {
+			if !ids[id] {
+				continue nextID
+			}
+		}
+		res = append(res, id)
+	}
+	return res
+}
+
+// groupTags returns the tags and operator of the tag param of the client group if the group can be resolved
+// using the index, ok is false if the group has no tag param or the tags contain wildcards.
+func groupTags(group *cgroups.ClientGroup) (tags []string, and bool, ok bool) {
+	if group.Params == nil || group.Params.Tag == nil || len(*group.Params.Tag) == 0 {
+		return nil, false, false
+	}
+	operator, operands, err := cgroups.ParseTag(group.Params.Tag)
+	if err != nil || len(operands) == 0 {
+		return nil, false, false
+	}
+	for _, operand := range operands {
+		if strings.Contains(operand, "*") {
+			return nil, false, false
+		}
+	}
+	return operands, strings.EqualFold(operator, "and"), true
+}
@@ -0,0 +1,134 @@
+package clientsauth
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	HandshakeFailuresRetention = 24 * time.Hour
+	HandshakeFailuresMax       = 1000
+)
+
+type HandshakeFailureReason string
+
+const (
+	// HandshakeUnknownClientAuth means the client sent a client auth id that doesn't exist.
+	HandshakeUnknownClientAuth HandshakeFailureReason = "unknown_client_auth"
+	// HandshakeInvalidPassword means the client sent a wrong password for an existing client auth id.
+	HandshakeInvalidPassword HandshakeFailureReason = "invalid_password"
+	// HandshakeTooManyAttempts means the client auth id is temporarily banned after failed attempts.
+	HandshakeTooManyAttempts HandshakeFailureReason = "too_many_attempts"
+	// HandshakeClosedByClient means the client closed the connection during the key exchange, usually because the
+	// server fingerprint doesn't match the fingerprint configured on the client.
+	HandshakeClosedByClient HandshakeFailureReason = "closed_by_client"
+	// HandshakeProtocolMismatch means the client uses an incompatible protocol version.
+	HandshakeProtocolMismatch HandshakeFailureReason = "protocol_mismatch"
+	// HandshakeVersionRejected means the client version is lower than the required min version.
+	HandshakeVersionRejected HandshakeFailureReason = "version_rejected"
+	// HandshakeClientIDConflict means another client with the same client id is connected.
+	HandshakeClientIDConflict HandshakeFailureReason = "client_id_conflict"
+	// HandshakeClientAuthInUse means the client auth is used by another client and multi-use is disabled.
+	HandshakeClientAuthInUse HandshakeFailureReason = "client_auth_in_use"
+	// HandshakeRejected is any other reason the server rejected the authenticated client, e.g. by the pre-connect script.
+	HandshakeRejected HandshakeFailureReason = "rejected"
+	// HandshakeFailed is any other error during the handshake.
+	HandshakeFailed HandshakeFailureReason = "failed"
+)
+
+// HandshakeFailure is a failed attempt of a client to connect. The client auth id is empty if the handshake failed
+// before the client authenticated, the client id is empty if the client failed before it sent the connection request.
+type HandshakeFailure struct {
+	Time         time.Time              `json:"time"`
+	ClientAuthID string                 `json:"client_auth_id"`
+	ClientID     string                 `json:"client_id"`
+	IP           string                 `json:"ip"`
+	Reason       HandshakeFailureReason `json:"reason"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+// HandshakeFailures keeps the recent failed handshakes in memory to troubleshoot client connections.
+type HandshakeFailures struct {
+	mu       sync.Mutex
+	failures []HandshakeFailure
+
+	now func() time.Time
+}
+
+func NewHandshakeFailures() *HandshakeFailures {
+	return &HandshakeFailures{
+		now: time.Now,
+	}
+}
+
+// Add records a failed handshake at the current time, it's a noop on nil.
+func (h *HandshakeFailures) Add(failure HandshakeFailure) {
+	if h == nil {
+		return
+	}
+
+	failure.Time = h.now()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failures = append(h.failures, failure)
+	h.prune(failure.Time)
+}
+
+// prune drops expired failures and the oldest failures over the limit, failures are sorted by time.
+func (h *HandshakeFailures) prune(now time.Time) {
+	first := 0
+	for first < len(h.failures) && h.failures[first].Time.Before(now.Add(-HandshakeFailuresRetention)) {
+		first++
+	}
+	if over := len(h.failures) - first - HandshakeFailuresMax; over > 0 {
+		first += over
+	}
+	if first > 0 {
+		h.failures = append([]HandshakeFailure(nil), h.failures[first:]...)
+	}
+}
+
+// List returns the failures since the given time, the latest first.
+func (h *HandshakeFailures) List(since time.Time) []HandshakeFailure {
+	found := []HandshakeFailure{}
+	if h == nil {
+		return found
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := len(h.failures) - 1; i >= 0 && !h.failures[i].Time.Before(since); i-- {
+		found = append(found, h.failures[i])
+	}
+	return found
+}
+
+// Find returns the failures of the given client auth id and the failures before authentication from the given IPs
+// or from IPs the client auth id failed from, the latest first.
+func (h *HandshakeFailures) Find(clientAuthID string, ips []string) []HandshakeFailure {
+	found := []HandshakeFailure{}
+	if h == nil {
+		return found
+	}
+
+	knownIPs := make(map[string]bool, len(ips))
+	for _, ip := range ips {
+		knownIPs[ip] = true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, f := range h.failures {
+		if f.ClientAuthID == clientAuthID {
+			knownIPs[f.IP] = true
+		}
+	}
+	for i := len(h.failures) - 1; i >= 0; i-- {
+		f := h.failures[i]
+		if f.ClientAuthID == clientAuthID || (f.ClientAuthID == "" && knownIPs[f.IP]) {
+			found = append(found, f)
+		}
+	}
+	return found
+}
@@ -0,0 +1,43 @@
+package clientsauth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandshakeFailures(t *testing.T) {
+	h := NewHandshakeFailures()
+	now := time.Date(2022, 10, 10, 12, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return now }
+
+	h.Add(HandshakeFailure{ClientAuthID: "client-1", IP: "192.0.2.1", Reason: HandshakeInvalidPassword})
+	h.Add(HandshakeFailure{IP: "192.0.2.1", Reason: HandshakeClosedByClient, Error: "unexpected EOF"})
+	h.Add(HandshakeFailure{IP: "192.0.2.2", Reason: HandshakeClosedByClient, Error: "unexpected EOF"})
+	h.Add(HandshakeFailure{IP: "198.51.100.1", Reason: HandshakeFailed, Error: "ssh: no common algorithm"})
+	now = now.Add(time.Minute)
+	h.Add(HandshakeFailure{ClientAuthID: "client-2", ClientID: "c2", IP: "198.51.100.2", Reason: HandshakeClientIDConflict})
+
+	assert.Equal(t, []HandshakeFailure{
+		{Time: now.Add(-time.Minute), IP: "198.51.100.1", Reason: HandshakeFailed, Error: "ssh: no common algorithm"},
+		{Time: now.Add(-time.Minute), IP: "192.0.2.1", Reason: HandshakeClosedByClient, Error: "unexpected EOF"},
+		{Time: now.Add(-time.Minute), ClientAuthID: "client-1", IP: "192.0.2.1", Reason: HandshakeInvalidPassword},
+	}, h.Find("client-1", []string{"198.51.100.1"}))
+	assert.Empty(t, h.Find("client-3", nil))
+
+	assert.Len(t, h.List(now.Add(-time.Hour)), 5)
+	assert.Equal(t, []HandshakeFailure{
+		{Time: now, ClientAuthID: "client-2", ClientID: "c2", IP: "198.51.100.2", Reason: HandshakeClientIDConflict},
+	}, h.List(now))
+
+	now = now.Add(HandshakeFailuresRetention + time.Second)
+	h.Add(HandshakeFailure{ClientAuthID: "client-3", IP: "192.0.2.3", Reason: HandshakeTooManyAttempts})
+	assert.Empty(t, h.Find("client-1", nil))
+	assert.Len(t, h.List(time.Time{}), 1)
+
+	var nilFailures *HandshakeFailures
+	nilFailures.Add(HandshakeFailure{ClientAuthID: "client-1", IP: "192.0.2.1", Reason: HandshakeInvalidPassword})
+	assert.Empty(t, nilFailures.Find("client-1", nil))
+	assert.Empty(t, nilFailures.List(time.Time{}))
+}
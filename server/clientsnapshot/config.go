@@ -0,0 +1,28 @@
+package clientsnapshot
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	DefaultInterval  = 24 * time.Hour
+	DefaultRetention = 30 * 24 * time.Hour
+)
+
+type Config struct {
+	// Interval between snapshots of all connected clients, 0 disables periodic snapshots.
+	Interval time.Duration `mapstructure:"client_snapshot_interval"`
+	// Retention of snapshots, 0 keeps snapshots forever.
+	Retention time.Duration `mapstructure:"client_snapshot_retention"`
+}
+
+func (c *Config) Validate() error {
+	if c.Interval < 0 {
+		return errors.New("client_snapshot_interval: must not be negative")
+	}
+	if c.Retention < 0 {
+		return errors.New("client_snapshot_retention: must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package clientsnapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+// Diff lists what changed between two snapshots of a client.
+type Diff struct {
+	ClientID  string        `json:"client_id"`
+	From      *Snapshot     `json:"from"`
+	To        *Snapshot     `json:"to"`
+	Inventory []FieldChange `json:"inventory"`
+	Updates   UpdatesDiff   `json:"updates"`
+	// Services is nil if the services are missing in one of the snapshots.
+	Services *ServicesDiff `json:"services"`
+}
+
+// FieldChange is a changed field, From or To is null if the field didn't exist.
+type FieldChange struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from"`
+	To    json.RawMessage `json:"to"`
+}
+
+type UpdatesDiff struct {
+	Changes []FieldChange `json:"changes"`
+	// Added and Removed are the titles of pending updates.
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+type ServicesDiff struct {
+	Added   []models.Service `json:"added"`
+	Removed []models.Service `json:"removed"`
+	Changed []ServiceChange  `json:"changed"`
+}
+
+type ServiceChange struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// NewDiff compares the state of two snapshots.
+func NewDiff(from, to *Snapshot) *Diff {
+	diff := &Diff{
+		ClientID:  to.ClientID,
+		From:      withoutState(from),
+		To:        withoutState(to),
+		Inventory: diffFields(from.State.Inventory, to.State.Inventory),
+		Updates:   diffUpdates(from.State.Updates, to.State.Updates),
+	}
+	if from.State.Services != nil && to.State.Services != nil {
+		diff.Services = diffServices(from.State.Services, to.State.Services)
+	}
+	return diff
+}
+
+func withoutState(s *Snapshot) *Snapshot {
+	res := *s
+	res.State = nil
+	return &res
+}
+
+func diffFields(from, to map[string]json.RawMessage) []FieldChange {
+	names := make(map[string]bool, len(to))
+	for name := range from {
+		names[name] = true
+	}
+	for name := range to {
+		names[name] = true
+	}
+
+	changes := make([]FieldChange, 0)
+	for name := range names {
+		if !bytes.Equal(from[name], to[name]) {
+			changes = append(changes, FieldChange{Field: name, From: from[name], To: to[name]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
+
+func diffUpdates(from, to *models.UpdatesStatus) UpdatesDiff {
+	fromTitles := updateTitles(from)
+	toTitles := updateTitles(to)
+	return UpdatesDiff{
+		Changes: diffFields(updatesFields(from), updatesFields(to)),
+		Added:   missing(toTitles, fromTitles),
+		Removed: missing(fromTitles, toTitles),
+	}
+}
+
+// updatesFields returns the summary of the updates status as json values, the pending updates are compared by title.
+func updatesFields(status *models.UpdatesStatus) map[string]json.RawMessage {
+	fields := make(map[string]json.RawMessage)
+	if status == nil {
+		return fields
+	}
+	values := map[string]interface{}{
+		"updates_available":          status.UpdatesAvailable,
+		"security_updates_available": status.SecurityUpdatesAvailable,
+		"reboot_pending":             status.RebootPending,
+		"error":                      status.Error,
+	}
+	for name, value := range values {
+		// marshalling ints, bools and strings can't fail
+		fields[name], _ = json.Marshal(value)
+	}
+	return fields
+}
+
+func updateTitles(status *models.UpdatesStatus) []string {
+	if status == nil {
+		return nil
+	}
+	titles := make([]string, 0, len(status.UpdateSummaries))
+	for _, u := range status.UpdateSummaries {
+		titles = append(titles, u.Title)
+	}
+	return titles
+}
+
+// missing returns the values of a missing in b.
+func missing(a, b []string) []string {
+	exists := make(map[string]bool, len(b))
+	for _, v := range b {
+		exists[v] = true
+	}
+	res := make([]string, 0)
+	for _, v := range a {
+		if !exists[v] {
+			res = append(res, v)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+func diffServices(from, to []models.Service) *ServicesDiff {
+	fromStates := make(map[string]string, len(from))
+	for _, s := range from {
+		fromStates[s.Name] = s.State
+	}
+	toStates := make(map[string]string, len(to))
+	for _, s := range to {
+		toStates[s.Name] = s.State
+	}
+
+	diff := &ServicesDiff{
+		Added:   make([]models.Service, 0),
+		Removed: make([]models.Service, 0),
+		Changed: make([]ServiceChange, 0),
+	}
+	for _, s := range to {
+		state, ok := fromStates[s.Name]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, s)
+		case state != s.State:
+			diff.Changed = append(diff.Changed, ServiceChange{Name: s.Name, From: state, To: s.State})
+		}
+	}
+	for _, s := range from {
+		if _, ok := toStates[s.Name]; !ok {
+			diff.Removed = append(diff.Removed, s)
+		}
+	}
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].Name < diff.Added[j].Name })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].Name < diff.Removed[j].Name })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Name < diff.Changed[j].Name })
+	return diff
+}
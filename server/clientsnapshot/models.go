@@ -0,0 +1,58 @@
+package clientsnapshot
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// Snapshot is the state of a client at a point in time. State is nil in lists.
+type Snapshot struct {
+	ID        int64     `json:"id" db:"id"`
+	ClientID  string    `json:"client_id" db:"client_id"`
+	Timestamp time.Time `json:"timestamp" db:"timestamp"`
+	Trigger   string    `json:"trigger" db:"trigger"`
+	State     *State    `json:"state,omitempty" db:"state"`
+}
+
+// State is the full state of a client.
+type State struct {
+	// Inventory holds the attributes of the client as json values mapped by the field name.
+	Inventory map[string]json.RawMessage `json:"inventory"`
+	Updates   *models.UpdatesStatus      `json:"updates"`
+	// Services is nil if the services couldn't be collected, see ServicesError.
+	Services      []models.Service `json:"services"`
+	ServicesError string           `json:"services_error,omitempty"`
+}
+
+func (s *State) Scan(value interface{}) error {
+	if s == nil {
+		return errors.New("'state' cannot be nil")
+	}
+	valueStr, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("expected to have string, got %T", value)
+	}
+	err := json.Unmarshal([]byte(valueStr), s)
+	if err != nil {
+		return fmt.Errorf("failed to decode 'state' field: %v", err)
+	}
+	return nil
+}
+
+func (s State) Value() (driver.Value, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode 'state' field: %v", err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,177 @@
+package clientsnapshot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/api"
+	apiErrors "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+var (
+	SupportedFilters = map[string]bool{
+		"timestamp[gt]":    true,
+		"timestamp[lt]":    true,
+		"timestamp[since]": true,
+		"timestamp[until]": true,
+		"trigger":          true,
+	}
+	SupportedSorts = map[string]bool{
+		"timestamp": true,
+	}
+	ListDefaultSort = map[string][]string{
+		"sort": {"-timestamp"},
+	}
+)
+
+// Source provides the state of the clients.
+type Source interface {
+	// ConnectedClientIDs returns the ids of all connected clients.
+	ConnectedClientIDs() []string
+	// State returns the current state of the client, an APIError with 404 if the client doesn't exist.
+	State(ctx context.Context, clientID string) (*State, error)
+}
+
+type Service struct {
+	provider *SQLiteProvider
+	source   Source
+	config   Config
+	logger   *logger.Logger
+	now      func() time.Time
+}
+
+func NewService(provider *SQLiteProvider, source Source, config Config, logger *logger.Logger) *Service {
+	return &Service{
+		provider: provider,
+		source:   source,
+		config:   config,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Take stores the current state of the client.
+func (s *Service) Take(ctx context.Context, clientID, trigger string) (*Snapshot, error) {
+	state, err := s.source.State(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{
+		ClientID:  clientID,
+		Timestamp: s.now().UTC(),
+		Trigger:   trigger,
+		State:     state,
+	}
+	if err := s.provider.Insert(ctx, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to save snapshot of client %s: %w", clientID, err)
+	}
+	return snapshot, nil
+}
+
+// TakeAll stores the state of all connected clients.
+func (s *Service) TakeAll(ctx context.Context) error {
+	taken := 0
+	for _, clientID := range s.source.ConnectedClientIDs() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if _, err := s.Take(ctx, clientID, TriggerSchedule); err != nil {
+			s.logger.Errorf("Failed to take snapshot of client %s: %v", clientID, err)
+			continue
+		}
+		taken++
+	}
+	s.logger.Debugf("Took snapshots of %d client(s).", taken)
+	return nil
+}
+
+// List returns the snapshots of the client without the state.
+func (s *Service) List(ctx context.Context, clientID string, options *query.ListOptions) (*api.SuccessPayload, error) {
+	err := query.ValidateListOptions(options, SupportedSorts, SupportedFilters, nil, &query.PaginationConfig{
+		DefaultLimit: 20,
+		MaxLimit:     100,
+	})
+	if err != nil {
+		return nil, err
+	}
+	options.Filters = append(options.Filters, query.FilterOption{Column: []string{"client_id"}, Values: []string{clientID}})
+
+	snapshots, err := s.provider.List(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	count, err := s.provider.Count(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.SuccessPayload{
+		Data: snapshots,
+		Meta: api.NewMeta(count),
+	}, nil
+}
+
+// Get returns the snapshot including the state, an APIError with 404 if not found.
+func (s *Service) Get(ctx context.Context, clientID string, id int64) (*Snapshot, error) {
+	snapshot, err := s.provider.Get(ctx, clientID, id)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot == nil {
+		return nil, apiErrors.NewAPIError(http.StatusNotFound, "", fmt.Sprintf("Snapshot %d of client %q not found.", id, clientID), nil)
+	}
+	return snapshot, nil
+}
+
+// Diff returns the changes from one snapshot of the client to another.
+func (s *Service) Diff(ctx context.Context, clientID string, fromID, toID int64) (*Diff, error) {
+	from, err := s.Get(ctx, clientID, fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.Get(ctx, clientID, toID)
+	if err != nil {
+		return nil, err
+	}
+	return NewDiff(from, to), nil
+}
+
+// DeleteExpired deletes snapshots older than the retention.
+func (s *Service) DeleteExpired(ctx context.Context) (int64, error) {
+	if s.config.Retention == 0 {
+		return 0, nil
+	}
+	return s.provider.DeleteOlderThan(ctx, s.now().Add(-s.config.Retention))
+}
+
+func (s *Service) Close() error {
+	return s.provider.Close()
+}
+
+type Task struct {
+	service *Service
+}
+
+// NewTask returns a task to take snapshots of all connected clients and to delete expired snapshots.
+func NewTask(service *Service) *Task {
+	return &Task{
+		service: service,
+	}
+}
+
+func (t *Task) Run(ctx context.Context) error {
+	deleted, err := t.service.DeleteExpired(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired client snapshots: %w", err)
+	}
+	if deleted > 0 {
+		t.service.logger.Debugf("Deleted %d expired client snapshot(s).", deleted)
+	}
+
+	return t.service.TakeAll(ctx)
+}
@@ -0,0 +1,166 @@
+package clientsnapshot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	clientsnapshotsmigration "github.com/realvnc-labs/rport/db/migration/client_snapshots"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	apiErrors "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+var testLog = logger.NewLogger("client-snapshots", logger.LogOutput{File: nil}, logger.LogLevelDebug)
+
+type sourceMock struct {
+	connected []string
+	states    map[string]*State
+}
+
+func (s *sourceMock) ConnectedClientIDs() []string {
+	return s.connected
+}
+
+func (s *sourceMock) State(ctx context.Context, clientID string) (*State, error) {
+	state, ok := s.states[clientID]
+	if !ok {
+		return nil, apiErrors.NewAPIError(http.StatusNotFound, "", "not found", nil)
+	}
+	return state, nil
+}
+
+func newTestService(t *testing.T, source Source, config Config) *Service {
+	db, err := sqlite.New(":memory:", clientsnapshotsmigration.AssetNames(), clientsnapshotsmigration.Asset, sqlite.DataSourceOptions{})
+	require.NoError(t, err)
+	s := NewService(NewSQLiteProvider(db), source, config, testLog)
+	t.Cleanup(func() {
+		s.Close()
+	})
+	return s
+}
+
+func testState(hostname string, services ...models.Service) *State {
+	return &State{
+		Inventory: map[string]json.RawMessage{
+			"hostname": json.RawMessage(`"` + hostname + `"`),
+			"num_cpus": json.RawMessage(`2`),
+		},
+		Services: services,
+	}
+}
+
+func TestServiceTakeAndDiff(t *testing.T) {
+	ctx := context.Background()
+	source := &sourceMock{
+		connected: []string{"client-1"},
+		states: map[string]*State{
+			"client-1": testState("web-1", models.Service{Name: "cron", State: "running"}, models.Service{Name: "nginx", State: "running"}),
+		},
+	}
+	s := newTestService(t, source, Config{})
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	err := s.TakeAll(ctx)
+	require.NoError(t, err)
+
+	now = now.Add(time.Hour)
+	source.states["client-1"] = testState("web-2", models.Service{Name: "nginx", State: "failed"}, models.Service{Name: "sshd", State: "running"})
+	second, err := s.Take(ctx, "client-1", TriggerManual)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), second.ID)
+
+	_, err = s.Take(ctx, "unknown", TriggerManual)
+	assert.Equal(t, http.StatusNotFound, err.(apiErrors.APIError).HTTPStatus)
+
+	result, err := s.List(ctx, "client-1", &query.ListOptions{Sorts: []query.SortOption{{Column: "timestamp", IsASC: false}}})
+	require.NoError(t, err)
+	list := result.Data.([]*Snapshot)
+	require.Len(t, list, 2)
+	assert.Equal(t, int64(2), list[0].ID)
+	assert.Equal(t, TriggerManual, list[0].Trigger)
+	assert.Nil(t, list[0].State)
+	assert.Equal(t, TriggerSchedule, list[1].Trigger)
+
+	diff, err := s.Diff(ctx, "client-1", 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []FieldChange{{Field: "hostname", From: json.RawMessage(`"web-1"`), To: json.RawMessage(`"web-2"`)}}, diff.Inventory)
+	assert.Equal(t, &ServicesDiff{
+		Added:   []models.Service{{Name: "sshd", State: "running"}},
+		Removed: []models.Service{{Name: "cron", State: "running"}},
+		Changed: []ServiceChange{{Name: "nginx", From: "running", To: "failed"}},
+	}, diff.Services)
+	assert.Nil(t, diff.From.State)
+
+	_, err = s.Diff(ctx, "client-2", 1, 2)
+	assert.Equal(t, http.StatusNotFound, err.(apiErrors.APIError).HTTPStatus)
+}
+
+func TestServiceDeleteExpired(t *testing.T) {
+	ctx := context.Background()
+	source := &sourceMock{
+		states: map[string]*State{"client-1": testState("web-1")},
+	}
+	s := newTestService(t, source, Config{Retention: 24 * time.Hour})
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	_, err := s.Take(ctx, "client-1", TriggerManual)
+	require.NoError(t, err)
+	now = now.Add(23 * time.Hour)
+	_, err = s.Take(ctx, "client-1", TriggerManual)
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Hour)
+	deleted, err := s.DeleteExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	_, err = s.Get(ctx, "client-1", 1)
+	assert.Error(t, err)
+	_, err = s.Get(ctx, "client-1", 2)
+	assert.NoError(t, err)
+}
+
+func TestNewDiffWithoutServices(t *testing.T) {
+	from := &Snapshot{ID: 1, State: &State{
+		Inventory: map[string]json.RawMessage{"tags": json.RawMessage(`["a"]`)},
+		Updates: &models.UpdatesStatus{
+			UpdatesAvailable: 1,
+			UpdateSummaries:  []models.UpdateSummary{{Title: "openssl"}},
+		},
+	}}
+	to := &Snapshot{ID: 2, State: &State{
+		Inventory: map[string]json.RawMessage{"tags": json.RawMessage(`["a"]`), "labels": json.RawMessage(`{}`)},
+		Updates: &models.UpdatesStatus{
+			UpdatesAvailable: 1,
+			UpdateSummaries:  []models.UpdateSummary{{Title: "curl"}},
+		},
+		ServicesError: "client is disconnected",
+	}}
+
+	diff := NewDiff(from, to)
+
+	assert.Equal(t, []FieldChange{{Field: "labels", To: json.RawMessage(`{}`)}}, diff.Inventory)
+	assert.Equal(t, UpdatesDiff{
+		Changes: []FieldChange{},
+		Added:   []string{"curl"},
+		Removed: []string{"openssl"},
+	}, diff.Updates)
+	assert.Nil(t, diff.Services)
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, (&Config{Interval: DefaultInterval, Retention: DefaultRetention}).Validate())
+	assert.NoError(t, (&Config{}).Validate())
+	assert.EqualError(t, (&Config{Interval: -time.Hour}).Validate(), "client_snapshot_interval: must not be negative")
+	assert.EqualError(t, (&Config{Retention: -time.Hour}).Validate(), "client_snapshot_retention: must not be negative")
+}
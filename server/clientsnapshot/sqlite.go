@@ -0,0 +1,85 @@
+package clientsnapshot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+type SQLiteProvider struct {
+	db        *sqlx.DB
+	converter *query.SQLConverter
+}
+
+func NewSQLiteProvider(db *sqlx.DB) *SQLiteProvider {
+	return &SQLiteProvider{
+		db:        db,
+		converter: query.NewSQLConverter(db.DriverName()),
+	}
+}
+
+func (p *SQLiteProvider) Insert(ctx context.Context, s *Snapshot) error {
+	res, err := p.db.NamedExecContext(ctx,
+		"INSERT INTO snapshots (client_id, timestamp, trigger, state) VALUES (:client_id, :timestamp, :trigger, :state)",
+		s,
+	)
+	if err != nil {
+		return err
+	}
+	s.ID, err = res.LastInsertId()
+	return err
+}
+
+// List returns the snapshots without the state.
+func (p *SQLiteProvider) List(ctx context.Context, options *query.ListOptions) ([]*Snapshot, error) {
+	values := []*Snapshot{}
+	q, params := p.converter.ConvertListOptionsToQuery(options, "SELECT id, client_id, timestamp, trigger FROM snapshots")
+	err := p.db.SelectContext(ctx, &values, q, params...)
+	if err != nil {
+		return values, err
+	}
+	return values, nil
+}
+
+func (p *SQLiteProvider) Count(ctx context.Context, options *query.ListOptions) (int, error) {
+	var result int
+	countOptions := *options
+	countOptions.Pagination = nil
+	countOptions.Sorts = nil
+	q, params := p.converter.ConvertListOptionsToQuery(&countOptions, "SELECT COUNT(*) FROM snapshots")
+	err := p.db.GetContext(ctx, &result, q, params...)
+	if err != nil {
+		return 0, err
+	}
+	return result, nil
+}
+
+// Get returns the snapshot including the state, nil if not found.
+func (p *SQLiteProvider) Get(ctx context.Context, clientID string, id int64) (*Snapshot, error) {
+	res := &Snapshot{}
+	err := p.db.GetContext(ctx, res, "SELECT * FROM snapshots WHERE client_id = ? AND id = ?", clientID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *SQLiteProvider) DeleteOlderThan(ctx context.Context, t time.Time) (int64, error) {
+	res, err := p.db.ExecContext(ctx, "DELETE FROM snapshots WHERE timestamp < ?", t.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (p *SQLiteProvider) Close() error {
+	return p.db.Close()
+}
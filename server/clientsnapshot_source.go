@@ -0,0 +1,123 @@
+package chserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	apiErrors "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/clientsnapshot"
+	"github.com/realvnc-labs/rport/share/comm"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+type clientSnapshotSource struct {
+	clientService clients.ClientService
+	logger        *logger.Logger
+	// getServices queries the services from the client, replaced in tests
+	getServices func(client *clientdata.Client) ([]models.Service, error)
+}
+
+func newClientSnapshotSource(clientService clients.ClientService, logger *logger.Logger) *clientSnapshotSource {
+	s := &clientSnapshotSource{
+		clientService: clientService,
+		logger:        logger,
+	}
+	s.getServices = s.queryServices
+	return s
+}
+
+func (s *clientSnapshotSource) ConnectedClientIDs() []string {
+	var ids []string
+	for _, client := range s.clientService.GetAll() {
+		if client.IsConnected() {
+			ids = append(ids, client.GetID())
+		}
+	}
+	return ids
+}
+
+// State returns the current state of the client. The services are only collected from connected clients.
+func (s *clientSnapshotSource) State(ctx context.Context, clientID string) (*clientsnapshot.State, error) {
+	client, err := s.clientService.GetByID(clientID)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, apiErrors.NewAPIError(http.StatusNotFound, apiErrors.ErrCodeClientNotFound, fmt.Sprintf("Client with id=%q not found.", clientID), nil)
+	}
+
+	values := map[string]interface{}{
+		"name":                     client.GetName(),
+		"hostname":                 client.GetHostname(),
+		"address":                  client.GetAddress(),
+		"os":                       client.GetOS(),
+		"os_full_name":             client.GetOSFullName(),
+		"os_version":               client.GetOSVersion(),
+		"os_family":                client.GetOSFamily(),
+		"os_kernel":                client.GetOSKernel(),
+		"os_arch":                  client.GetOSArch(),
+		"os_virtualization_system": client.GetOSVirtualizationSystem(),
+		"os_virtualization_role":   client.GetOSVirtualizationRole(),
+		"num_cpus":                 client.GetNumCPUs(),
+		"mem_total":                client.GetMemoryTotal(),
+		"timezone":                 client.GetTimezone(),
+		"ipv4":                     client.GetIPv4(),
+		"ipv6":                     client.GetIPv6(),
+		"tags":                     client.GetTags(),
+		"labels":                   client.GetLabels(),
+		"version":                  client.GetVersion(),
+		"client_auth_id":           client.GetClientAuthID(),
+		"allowed_user_groups":      client.GetAllowedUserGroups(),
+		"mode":                     client.GetMode(),
+		"quarantined":              client.IsQuarantined(),
+	}
+	state := &clientsnapshot.State{
+		Inventory: make(map[string]json.RawMessage, len(values)),
+	}
+	for name, value := range values {
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s of client %s: %w", name, clientID, err)
+		}
+		state.Inventory[name] = b
+	}
+
+	updates := client.GetUpdatesStatus()
+	if !updates.Refreshed.IsZero() {
+		state.Updates = &updates
+	}
+
+	if !client.IsConnected() {
+		state.ServicesError = "client is disconnected"
+		return state, nil
+	}
+	if client.IsCheckInOnly() {
+		state.ServicesError = ErrClientCheckInOnly.Error()
+		return state, nil
+	}
+	services, err := s.getServices(client)
+	if err != nil {
+		state.ServicesError = err.Error()
+		return state, nil
+	}
+	if services == nil {
+		services = []models.Service{}
+	}
+	state.Services = services
+
+	return state, nil
+}
+
+func (s *clientSnapshotSource) queryServices(client *clientdata.Client) ([]models.Service, error) {
+	var services []models.Service
+	err := comm.SendRequestAndGetResponse(client.GetConnection(), comm.RequestTypeGetServices, nil, &services, s.logger)
+	if err != nil {
+		return nil, err
+	}
+	return services, nil
+}
@@ -0,0 +1,82 @@
+// Package clienttags validates namespaced tags of clients like "env:prod" or "team:dba".
+package clienttags
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+const (
+	Separator = ":"
+
+	OnInvalidReject    = "reject"
+	OnInvalidNormalize = "normalize"
+)
+
+var namespaceNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_.\-]*$`)
+
+// Namespace defines the allowed values of the tags "<name>:<value>".
+type Namespace struct {
+	Name string `mapstructure:"name"`
+	// Pattern is a regular expression the value must match, any non-empty value is allowed if empty.
+	Pattern string `mapstructure:"pattern"`
+	// Required makes clients connecting with one of the ClientAuthIDs or with any client auth if empty have a tag of the namespace.
+	Required      bool     `mapstructure:"required"`
+	ClientAuthIDs []string `mapstructure:"client_auth_ids"`
+	// Default is the value of the tag added to clients missing a required tag if invalid tags are normalized.
+	Default string `mapstructure:"default"`
+}
+
+// Config defines the namespaces of client tags. Tags without a namespace are always allowed.
+// If namespaces are defined, tags of other namespaces are invalid.
+type Config struct {
+	Namespaces []Namespace `mapstructure:"tag_namespaces"`
+	// OnInvalid is either "reject" to deny the connection or the change of the tags, or "normalize" to drop
+	// invalid tags and add defaults of missing required tags.
+	OnInvalid string `mapstructure:"on_invalid_tags"`
+}
+
+func (c *Config) Validate() error {
+	switch c.OnInvalid {
+	case "":
+		c.OnInvalid = OnInvalidReject
+	case OnInvalidReject, OnInvalidNormalize:
+	default:
+		return errors.New("on_invalid_tags must be either 'reject' or 'normalize'")
+	}
+
+	names := make(map[string]bool, len(c.Namespaces))
+	for i, ns := range c.Namespaces {
+		if err := ns.validate(); err != nil {
+			return fmt.Errorf("tag_namespaces: invalid namespace %d: %v", i+1, err)
+		}
+		if names[ns.Name] {
+			return fmt.Errorf("tag_namespaces: invalid namespace %d: duplicate name %q", i+1, ns.Name)
+		}
+		names[ns.Name] = true
+	}
+	return nil
+}
+
+func (ns *Namespace) validate() error {
+	if !namespaceNameRegex.MatchString(ns.Name) {
+		return fmt.Errorf("name %q must consist of lower case letters, digits, '_', '.' and '-'", ns.Name)
+	}
+	if ns.Pattern != "" {
+		re, err := regexp.Compile(ns.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %v", err)
+		}
+		if ns.Default != "" && !re.MatchString(ns.Default) {
+			return fmt.Errorf("default %q doesn't match the pattern", ns.Default)
+		}
+	}
+	if ns.Default != "" && !ns.Required {
+		return errors.New("default requires required = true")
+	}
+	if len(ns.ClientAuthIDs) > 0 && !ns.Required {
+		return errors.New("client_auth_ids requires required = true")
+	}
+	return nil
+}
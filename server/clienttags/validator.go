@@ -0,0 +1,153 @@
+package clienttags
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type compiledNamespace struct {
+	Namespace
+	re *regexp.Regexp
+}
+
+// Validator checks the tags of clients against the configured namespaces. A nil Validator accepts all tags.
+type Validator struct {
+	onInvalid  string
+	namespaces []*compiledNamespace
+	byName     map[string]*compiledNamespace
+}
+
+// NewValidator returns nil if no namespaces are defined.
+func NewValidator(config Config) (*Validator, error) {
+	if len(config.Namespaces) == 0 {
+		return nil, nil
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	v := &Validator{
+		onInvalid: config.OnInvalid,
+		byName:    make(map[string]*compiledNamespace, len(config.Namespaces)),
+	}
+	for _, ns := range config.Namespaces {
+		cns := &compiledNamespace{Namespace: ns}
+		if ns.Pattern != "" {
+			cns.re = regexp.MustCompile(ns.Pattern)
+		}
+		v.namespaces = append(v.namespaces, cns)
+		v.byName[ns.Name] = cns
+	}
+	return v, nil
+}
+
+// Names returns the names of the defined namespaces.
+func (v *Validator) Names() []string {
+	if v == nil {
+		return nil
+	}
+	names := make([]string, 0, len(v.namespaces))
+	for _, ns := range v.namespaces {
+		names = append(names, ns.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks the tags of a client using the given client auth and returns the normalized tags.
+// Invalid tags cause an error or are dropped depending on the OnInvalid config.
+func (v *Validator) Validate(tags []string, clientAuthID string) ([]string, error) {
+	if v == nil {
+		return tags, nil
+	}
+
+	normalize := v.onInvalid == OnInvalidNormalize
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	present := make(map[string]bool)
+	for _, tag := range tags {
+		name, value, ok := split(tag)
+		if !ok {
+			if !seen[tag] {
+				result = append(result, tag)
+				seen[tag] = true
+			}
+			continue
+		}
+
+		normalized := name + Separator + value
+		if err := v.check(name, value); err != nil {
+			if normalize {
+				continue
+			}
+			return nil, fmt.Errorf("invalid tag %q: %v", tag, err)
+		}
+		if normalized != tag && !normalize {
+			return nil, fmt.Errorf("invalid tag %q: use %q instead", tag, normalized)
+		}
+
+		present[name] = true
+		if !seen[normalized] {
+			result = append(result, normalized)
+			seen[normalized] = true
+		}
+	}
+
+	for _, ns := range v.namespaces {
+		if present[ns.Name] || !ns.requiredFor(clientAuthID) {
+			continue
+		}
+		if normalize && ns.Default != "" {
+			result = append(result, ns.Name+Separator+ns.Default)
+			continue
+		}
+		return nil, fmt.Errorf("missing required tag %s%s<value>", ns.Name, Separator)
+	}
+
+	return result, nil
+}
+
+func (v *Validator) check(name, value string) error {
+	ns, ok := v.byName[name]
+	if !ok {
+		return fmt.Errorf("unknown namespace %q, allowed: %s", name, strings.Join(v.Names(), ", "))
+	}
+	if value == "" {
+		return fmt.Errorf("value of namespace %q cannot be empty", name)
+	}
+	if ns.re != nil && !ns.re.MatchString(value) {
+		return fmt.Errorf("value %q doesn't match the pattern %s of namespace %q", value, ns.Pattern, name)
+	}
+	return nil
+}
+
+func (ns *compiledNamespace) requiredFor(clientAuthID string) bool {
+	if !ns.Required {
+		return false
+	}
+	if len(ns.ClientAuthIDs) == 0 {
+		return true
+	}
+	for _, id := range ns.ClientAuthIDs {
+		if id == clientAuthID {
+			return true
+		}
+	}
+	return false
+}
+
+// split returns the lower case namespace and the value of a tag without surrounding whitespace.
+// Tags are namespaced only if the part before the separator is a valid namespace name, e.g. "Datacenter 1: Rack 2" is not.
+func split(tag string) (string, string, bool) {
+	name, value, ok := strings.Cut(tag, Separator)
+	if !ok {
+		return "", "", false
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !namespaceNameRegex.MatchString(name) {
+		return "", "", false
+	}
+	return name, strings.TrimSpace(value), true
+}
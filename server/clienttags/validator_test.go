@@ -0,0 +1,171 @@
+package clienttags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNamespaces = []Namespace{
+	{Name: "env", Pattern: `^(prod|staging|dev)$`, Required: true, Default: "dev"},
+	{Name: "team"},
+	{Name: "site", Pattern: `^[a-z]{3}[0-9]$`, Required: true, ClientAuthIDs: []string{"site-a"}},
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		OnInvalid     string
+		Tags          []string
+		ClientAuthID  string
+		ExpectedTags  []string
+		ExpectedError string
+	}{
+		{
+			Name:         "valid",
+			OnInvalid:    OnInvalidReject,
+			Tags:         []string{"env:prod", "team:dba", "Linux", "Datacenter 1: Rack 2"},
+			ClientAuthID: "client-1",
+			ExpectedTags: []string{"env:prod", "team:dba", "Linux", "Datacenter 1: Rack 2"},
+		},
+		{
+			Name:          "unknown namespace",
+			OnInvalid:     OnInvalidReject,
+			Tags:          []string{"env:prod", "owner:alice"},
+			ExpectedError: `invalid tag "owner:alice": unknown namespace "owner", allowed: env, site, team`,
+		},
+		{
+			Name:          "value not matching",
+			OnInvalid:     OnInvalidReject,
+			Tags:          []string{"env:production"},
+			ExpectedError: `invalid tag "env:production": value "production" doesn't match the pattern ^(prod|staging|dev)$ of namespace "env"`,
+		},
+		{
+			Name:          "empty value",
+			OnInvalid:     OnInvalidReject,
+			Tags:          []string{"env:prod", "team:"},
+			ExpectedError: `invalid tag "team:": value of namespace "team" cannot be empty`,
+		},
+		{
+			Name:          "not normalized",
+			OnInvalid:     OnInvalidReject,
+			Tags:          []string{"Env: prod"},
+			ExpectedError: `invalid tag "Env: prod": use "env:prod" instead`,
+		},
+		{
+			Name:          "missing required",
+			OnInvalid:     OnInvalidReject,
+			Tags:          []string{"team:dba"},
+			ExpectedError: "missing required tag env:<value>",
+		},
+		{
+			Name:          "missing required of client auth",
+			OnInvalid:     OnInvalidReject,
+			Tags:          []string{"env:prod"},
+			ClientAuthID:  "site-a",
+			ExpectedError: "missing required tag site:<value>",
+		},
+		{
+			Name:         "normalize",
+			OnInvalid:    OnInvalidNormalize,
+			Tags:         []string{"Env: prod", "env:prod", "owner:alice", "team:", "Linux"},
+			ExpectedTags: []string{"env:prod", "Linux"},
+		},
+		{
+			Name:         "normalize adds default",
+			OnInvalid:    OnInvalidNormalize,
+			Tags:         []string{"env:production", "team:dba"},
+			ExpectedTags: []string{"team:dba", "env:dev"},
+		},
+		{
+			Name:          "normalize without default",
+			OnInvalid:     OnInvalidNormalize,
+			Tags:          []string{"env:prod", "site:x"},
+			ClientAuthID:  "site-a",
+			ExpectedError: "missing required tag site:<value>",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			v, err := NewValidator(Config{Namespaces: testNamespaces, OnInvalid: tc.OnInvalid})
+			require.NoError(t, err)
+
+			tags, err := v.Validate(tc.Tags, tc.ClientAuthID)
+
+			if tc.ExpectedError != "" {
+				assert.EqualError(t, err, tc.ExpectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedTags, tags)
+		})
+	}
+}
+
+func TestValidateWithoutNamespaces(t *testing.T) {
+	v, err := NewValidator(Config{})
+	require.NoError(t, err)
+	assert.Nil(t, v)
+
+	tags, err := v.Validate([]string{"owner:alice"}, "client-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"owner:alice"}, tags)
+}
+
+func TestConfigValidate(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Config        Config
+		ExpectedError string
+	}{
+		{
+			Name:   "valid",
+			Config: Config{Namespaces: testNamespaces},
+		},
+		{
+			Name:          "invalid on_invalid_tags",
+			Config:        Config{OnInvalid: "drop"},
+			ExpectedError: "on_invalid_tags must be either 'reject' or 'normalize'",
+		},
+		{
+			Name:          "invalid name",
+			Config:        Config{Namespaces: []Namespace{{Name: "Env"}}},
+			ExpectedError: `tag_namespaces: invalid namespace 1: name "Env" must consist of lower case letters, digits, '_', '.' and '-'`,
+		},
+		{
+			Name:          "duplicate name",
+			Config:        Config{Namespaces: []Namespace{{Name: "env"}, {Name: "env"}}},
+			ExpectedError: `tag_namespaces: invalid namespace 2: duplicate name "env"`,
+		},
+		{
+			Name:          "invalid pattern",
+			Config:        Config{Namespaces: []Namespace{{Name: "env", Pattern: "("}}},
+			ExpectedError: "tag_namespaces: invalid namespace 1: invalid pattern: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			Name:          "default not matching",
+			Config:        Config{Namespaces: []Namespace{{Name: "env", Pattern: "^prod$", Required: true, Default: "dev"}}},
+			ExpectedError: `tag_namespaces: invalid namespace 1: default "dev" doesn't match the pattern`,
+		},
+		{
+			Name:          "default of optional namespace",
+			Config:        Config{Namespaces: []Namespace{{Name: "env", Default: "dev"}}},
+			ExpectedError: "tag_namespaces: invalid namespace 1: default requires required = true",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := tc.Config.Validate()
+
+			if tc.ExpectedError != "" {
+				assert.EqualError(t, err, tc.ExpectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, OnInvalidReject, tc.Config.OnInvalid)
+		})
+	}
+}
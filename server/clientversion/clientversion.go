@@ -0,0 +1,90 @@
+package clientversion
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+const (
+	OnOutdatedReject     = "reject"
+	OnOutdatedQuarantine = "quarantine"
+)
+
+// Config defines the minimum version of clients allowed to connect, globally and per client auth id.
+type Config struct {
+	MinVersion string `mapstructure:"min_client_version"`
+	// MinVersionPerClientAuth overrides MinVersion for the given client auth ids. Keys are case-insensitive.
+	MinVersionPerClientAuth map[string]string `mapstructure:"min_client_version_per_client_auth"`
+	// OnOutdated is either "reject" to deny the connection or "quarantine" to connect outdated clients paused.
+	OnOutdated string `mapstructure:"min_client_version_on_outdated"`
+}
+
+func (c *Config) Validate() error {
+	if c.MinVersion != "" {
+		if _, err := version.NewVersion(c.MinVersion); err != nil {
+			return fmt.Errorf("invalid min_client_version %q: %v", c.MinVersion, err)
+		}
+	}
+	for clientAuthID, v := range c.MinVersionPerClientAuth {
+		if _, err := version.NewVersion(v); err != nil {
+			return fmt.Errorf("invalid min_client_version_per_client_auth %q of %q: %v", v, clientAuthID, err)
+		}
+	}
+
+	switch c.OnOutdated {
+	case "":
+		c.OnOutdated = OnOutdatedReject
+	case OnOutdatedReject, OnOutdatedQuarantine:
+	default:
+		return fmt.Errorf("invalid min_client_version_on_outdated %q, expected one of: %s, %s", c.OnOutdated, OnOutdatedReject, OnOutdatedQuarantine)
+	}
+	return nil
+}
+
+// Enabled returns true if a minimum version is configured.
+func (c *Config) Enabled() bool {
+	return c.MinVersion != "" || len(c.MinVersionPerClientAuth) > 0
+}
+
+// Quarantine returns true if outdated clients are connected paused instead of rejected.
+func (c *Config) Quarantine() bool {
+	return c.OnOutdated == OnOutdatedQuarantine
+}
+
+// OutdatedError is returned by Check if the client version is lower than the required min version.
+type OutdatedError struct {
+	Version    string
+	MinVersion string
+}
+
+func (e *OutdatedError) Error() string {
+	return fmt.Sprintf("client version %q is outdated, minimum required version is %s, please update the client", e.Version, e.MinVersion)
+}
+
+// Check returns an error if the client version is lower than the minimum version required for the client auth id.
+// Versions that can't be parsed are treated as outdated.
+func (c *Config) Check(clientAuthID, clientVersion string) error {
+	if c == nil || !c.Enabled() {
+		return nil
+	}
+
+	minVersion := c.MinVersion
+	if v, ok := c.MinVersionPerClientAuth[strings.ToLower(clientAuthID)]; ok {
+		minVersion = v
+	}
+	if minVersion == "" {
+		return nil
+	}
+	required, err := version.NewVersion(minVersion)
+	if err != nil {
+		return err
+	}
+
+	current, err := version.NewVersion(clientVersion)
+	if err != nil || current.LessThan(required) {
+		return &OutdatedError{Version: clientVersion, MinVersion: minVersion}
+	}
+	return nil
+}
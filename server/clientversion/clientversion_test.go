@@ -0,0 +1,92 @@
+package clientversion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	config := &Config{
+		MinVersion: "0.9.0",
+		MinVersionPerClientAuth: map[string]string{
+			"legacy": "0.6.0",
+		},
+	}
+	require.NoError(t, config.Validate())
+
+	testCases := []struct {
+		name          string
+		clientAuthID  string
+		version       string
+		expectedError string
+	}{
+		{
+			name:         "same version",
+			clientAuthID: "client-1",
+			version:      "0.9.0",
+		},
+		{
+			name:         "newer version",
+			clientAuthID: "client-1",
+			version:      "0.9.12",
+		},
+		{
+			name:          "older version",
+			clientAuthID:  "client-1",
+			version:       "0.8.4",
+			expectedError: `client version "0.8.4" is outdated, minimum required version is 0.9.0, please update the client`,
+		},
+		{
+			name:          "pre-release of min version",
+			clientAuthID:  "client-1",
+			version:       "0.9.0-rc1",
+			expectedError: `client version "0.9.0-rc1" is outdated, minimum required version is 0.9.0, please update the client`,
+		},
+		{
+			name:          "invalid version",
+			clientAuthID:  "client-1",
+			version:       "unknown",
+			expectedError: `client version "unknown" is outdated, minimum required version is 0.9.0, please update the client`,
+		},
+		{
+			name:         "per client auth",
+			clientAuthID: "Legacy",
+			version:      "0.6.4",
+		},
+		{
+			name:          "per client auth older version",
+			clientAuthID:  "legacy",
+			version:       "0.5.0",
+			expectedError: `client version "0.5.0" is outdated, minimum required version is 0.6.0, please update the client`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := config.Check(tc.clientAuthID, tc.version)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckDisabled(t *testing.T) {
+	var config *Config
+	assert.NoError(t, config.Check("client-1", "0.1.0"))
+	assert.NoError(t, (&Config{}).Check("client-1", "0.1.0"))
+}
+
+func TestValidate(t *testing.T) {
+	config := &Config{MinVersion: "0.9.0"}
+	require.NoError(t, config.Validate())
+	assert.Equal(t, OnOutdatedReject, config.OnOutdated)
+	assert.False(t, config.Quarantine())
+
+	assert.EqualError(t, (&Config{MinVersion: "latest"}).Validate(), `invalid min_client_version "latest": Malformed version: latest`)
+	assert.EqualError(t, (&Config{OnOutdated: "ignore"}).Validate(), `invalid min_client_version_on_outdated "ignore", expected one of: reject, quarantine`)
+}
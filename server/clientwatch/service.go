@@ -0,0 +1,220 @@
+package clientwatch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/i18n"
+	"github.com/realvnc-labs/rport/server/notifications"
+	"github.com/realvnc-labs/rport/share/email"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/random"
+	"github.com/realvnc-labs/rport/share/refs"
+)
+
+const (
+	DefaultTTL        = 24 * time.Hour
+	MaxTTL            = 7 * 24 * time.Hour
+	MaxWatchesPerUser = 100
+
+	NotificationType refs.IdentifiableType = "client_watch"
+)
+
+// Watch notifies the recipients once the client reconnects, it's deleted afterwards or when it expires.
+type Watch struct {
+	ID         string    `json:"id"`
+	ClientID   string    `json:"client_id"`
+	ClientName string    `json:"client_name"`
+	Username   string    `json:"username"`
+	Recipients []string  `json:"recipients"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Service keeps the watches in memory, they are lost on restart of the server.
+type Service struct {
+	logger     *logger.Logger
+	dispatcher notifications.Dispatcher
+	localizer  i18n.Localizer
+	now        func() time.Time
+
+	watches map[string]*Watch
+	mu      sync.Mutex
+}
+
+func NewService(logger *logger.Logger) *Service {
+	return &Service{
+		logger:  logger,
+		now:     time.Now,
+		watches: make(map[string]*Watch),
+	}
+}
+
+// SetDispatcher enables notifying the recipients of watches, without it reconnects are only logged.
+func (s *Service) SetDispatcher(dispatcher notifications.Dispatcher) {
+	s.dispatcher = dispatcher
+}
+
+// SetLocalizer enables notifying in the locale of the user that watches the client, without it english is used.
+func (s *Service) SetLocalizer(localizer i18n.Localizer) {
+	s.localizer = localizer
+}
+
+// Add adds a watch of the client for the user, an existing watch of the user for the same client is replaced.
+// A zero ttl means DefaultTTL.
+func (s *Service) Add(clientID, clientName, username string, recipients []string, ttl time.Duration) (*Watch, error) {
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	if ttl < 0 || ttl > MaxTTL {
+		return nil, errors.NewAPIError(http.StatusBadRequest, "", fmt.Sprintf("ttl must be between 1s and %s.", MaxTTL), nil)
+	}
+	if len(recipients) == 0 {
+		return nil, errors.NewAPIError(http.StatusBadRequest, "", "At least one recipient is required.", nil)
+	}
+	for _, recipient := range recipients {
+		if err := email.Validate(recipient); err != nil {
+			return nil, errors.NewAPIError(http.StatusBadRequest, "", fmt.Sprintf("Invalid recipient %q.", recipient), err)
+		}
+	}
+
+	id, err := random.UUID4()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteExpired()
+	count := 0
+	for existingID, w := range s.watches {
+		if w.Username != username {
+			continue
+		}
+		if w.ClientID == clientID {
+			delete(s.watches, existingID)
+			continue
+		}
+		count++
+	}
+	if count >= MaxWatchesPerUser {
+		return nil, errors.NewAPIError(http.StatusConflict, "", fmt.Sprintf("A user cannot have more than %d watches.", MaxWatchesPerUser), nil)
+	}
+
+	now := s.now()
+	w := &Watch{
+		ID:         id,
+		ClientID:   clientID,
+		ClientName: clientName,
+		Username:   username,
+		Recipients: recipients,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(ttl),
+	}
+	s.watches[id] = w
+
+	return w, nil
+}
+
+// List returns the watches that are not expired, oldest first. If username is not empty only the watches of the
+// user are returned.
+func (s *Service) List(username string) []*Watch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteExpired()
+	res := make([]*Watch, 0, len(s.watches))
+	for _, w := range s.watches {
+		if username == "" || w.Username == username {
+			res = append(res, w)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.Before(res[j].CreatedAt)
+	})
+	return res
+}
+
+// Delete removes the watch, if it belongs to the user or the user is an admin.
+func (s *Service) Delete(id, username string, isAdmin bool) (*Watch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteExpired()
+	w, ok := s.watches[id]
+	if !ok {
+		return nil, errors.NewAPIError(http.StatusNotFound, "", fmt.Sprintf("Client watch with id %q not found.", id), nil)
+	}
+	if w.Username != username && !isAdmin {
+		return nil, errors.NewAPIError(http.StatusForbidden, "", "You are not allowed to delete this client watch.", nil)
+	}
+
+	delete(s.watches, id)
+	return w, nil
+}
+
+// ClientConnected notifies the recipients of all watches of the client and deletes them, it's a noop on a nil service.
+func (s *Service) ClientConnected(ctx context.Context, clientID, clientName string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.deleteExpired()
+	var fired []*Watch
+	for id, w := range s.watches {
+		if w.ClientID == clientID {
+			fired = append(fired, w)
+			delete(s.watches, id)
+		}
+	}
+	s.mu.Unlock()
+
+	now := s.now()
+	for _, w := range fired {
+		s.logger.Infof("Client %s watched by %s reconnected.", clientID, w.Username)
+		s.notify(ctx, w, clientName, now)
+	}
+}
+
+func (s *Service) deleteExpired() {
+	now := s.now()
+	for id, w := range s.watches {
+		if now.After(w.ExpiresAt) {
+			s.logger.Debugf("Watch %s of client %s by %s expired.", id, w.ClientID, w.Username)
+			delete(s.watches, id)
+		}
+	}
+}
+
+func (s *Service) notify(ctx context.Context, w *Watch, clientName string, connectedAt time.Time) {
+	if s.dispatcher == nil {
+		return
+	}
+
+	locale := i18n.DefaultLocale
+	if s.localizer != nil {
+		locale = s.localizer.UserLocale(ctx, w.Username)
+	}
+
+	_, err := s.dispatcher.Dispatch(ctx, refs.GenerateIdentifiable(NotificationType), notifications.NotificationData{
+		Target:     string(notifications.TargetMail),
+		Recipients: w.Recipients,
+		Subject:    i18n.Sprintf(locale, "Rport client %s is back online", clientName),
+		Content: i18n.Sprintf(
+			locale,
+			"Client %s (%s) reconnected at %s.\nYou receive this message because %s watched the client since %s.",
+			clientName, w.ClientID, connectedAt.Format(time.RFC3339), w.Username, w.CreatedAt.Format(time.RFC3339),
+		),
+		ContentType: notifications.ContentTypeTextPlain,
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to dispatch client watch notification: %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package clientwatch
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/notifications"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/refs"
+)
+
+var testLog = logger.NewLogger("client-watch-test", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+
+type dispatcherMock struct {
+	notifications []notifications.NotificationData
+}
+
+func (d *dispatcherMock) Dispatch(ctx context.Context, refID refs.Identifiable, notification notifications.NotificationData) (refs.Identifiable, error) {
+	d.notifications = append(d.notifications, notification)
+	return refID, nil
+}
+
+func newTestService(now *time.Time) (*Service, *dispatcherMock) {
+	s := NewService(testLog)
+	s.now = func() time.Time {
+		return *now
+	}
+	d := &dispatcherMock{}
+	s.SetDispatcher(d)
+	return s, d
+}
+
+func assertAPIError(t *testing.T, status int, err error) {
+	t.Helper()
+	var apiErr errors.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, status, apiErr.HTTPStatus)
+}
+
+func TestAdd(t *testing.T) {
+	now := time.Date(2023, 5, 10, 12, 0, 0, 0, time.UTC)
+	s, _ := newTestService(&now)
+
+	_, err := s.Add("client-1", "Client 1", "alice", nil, 0)
+	assertAPIError(t, http.StatusBadRequest, err)
+
+	_, err = s.Add("client-1", "Client 1", "alice", []string{"not-an-email"}, 0)
+	assertAPIError(t, http.StatusBadRequest, err)
+
+	_, err = s.Add("client-1", "Client 1", "alice", []string{"alice@example.com"}, MaxTTL+time.Second)
+	assertAPIError(t, http.StatusBadRequest, err)
+
+	w1, err := s.Add("client-1", "Client 1", "alice", []string{"alice@example.com"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(DefaultTTL), w1.ExpiresAt)
+
+	now = now.Add(time.Minute)
+	w2, err := s.Add("client-1", "Client 1", "alice", []string{"ops@example.com"}, time.Hour)
+	require.NoError(t, err)
+	_, err = s.Add("client-1", "Client 1", "bob", []string{"bob@example.com"}, time.Hour)
+	require.NoError(t, err)
+
+	watches := s.List("alice")
+	require.Len(t, watches, 1)
+	assert.Equal(t, w2.ID, watches[0].ID)
+	assert.Equal(t, []string{"ops@example.com"}, watches[0].Recipients)
+	assert.Len(t, s.List(""), 2)
+
+	now = now.Add(time.Hour + time.Second)
+	assert.Empty(t, s.List(""))
+}
+
+func TestDelete(t *testing.T) {
+	now := time.Now()
+	s, _ := newTestService(&now)
+
+	w, err := s.Add("client-1", "Client 1", "alice", []string{"alice@example.com"}, 0)
+	require.NoError(t, err)
+
+	_, err = s.Delete("unknown", "alice", false)
+	assertAPIError(t, http.StatusNotFound, err)
+
+	_, err = s.Delete(w.ID, "bob", false)
+	assertAPIError(t, http.StatusForbidden, err)
+
+	_, err = s.Delete(w.ID, "admin", true)
+	require.NoError(t, err)
+	assert.Empty(t, s.List(""))
+}
+
+func TestClientConnected(t *testing.T) {
+	now := time.Date(2023, 5, 10, 12, 0, 0, 0, time.UTC)
+	s, d := newTestService(&now)
+
+	_, err := s.Add("client-1", "Client 1", "alice", []string{"alice@example.com"}, time.Hour)
+	require.NoError(t, err)
+	_, err = s.Add("client-1", "Client 1", "bob", []string{"bob@example.com"}, time.Minute)
+	require.NoError(t, err)
+	w3, err := s.Add("client-2", "Client 2", "alice", []string{"alice@example.com"}, time.Hour)
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	s.ClientConnected(context.Background(), "client-1", "Client 1")
+
+	require.Len(t, d.notifications, 1)
+	assert.Equal(t, []string{"alice@example.com"}, d.notifications[0].Recipients)
+	assert.Equal(t, string(notifications.TargetMail), d.notifications[0].Target)
+	assert.Equal(t, "Rport client Client 1 is back online", d.notifications[0].Subject)
+
+	watches := s.List("")
+	require.Len(t, watches, 1)
+	assert.Equal(t, w3.ID, watches[0].ID)
+
+	s.ClientConnected(context.Background(), "client-1", "Client 1")
+	assert.Len(t, d.notifications, 1)
+}
+
+func TestClientConnectedNilService(t *testing.T) {
+	var s *Service
+
+	assert.NotPanics(t, func() {
+		s.ClientConnected(context.Background(), "client-1", "Client 1")
+	})
+}
@@ -0,0 +1,229 @@
+package chserver
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+const (
+	ComponentStatusOK    = "ok"
+	ComponentStatusError = "error"
+
+	ComponentDatabase    = "database"
+	ComponentStorage     = "storage"
+	ComponentAuth        = "auth"
+	ComponentClientsAuth = "clients_auth"
+	ComponentPortPool    = "port_pool"
+	ComponentCertificate = "certificate"
+
+	componentCheckTimeout = 3 * time.Second
+	// certificateExpiryWarning is the period before the expiry of the API certificate in which it's reported in details
+	certificateExpiryWarning = 14 * 24 * time.Hour
+)
+
+type ComponentHealth struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Details string `json:"details,omitempty"`
+	// err is logged only, it's not exposed since the health might be shown to unauthenticated users
+	err error
+}
+
+// componentCheck returns an error if the component is unhealthy. Details must not contain sensitive information.
+type componentCheck func(ctx context.Context) (details string, err error)
+
+// statusChecks returns the checks of the components shown on the status page.
+func (al *APIListener) statusChecks() map[string]componentCheck {
+	return map[string]componentCheck{
+		ComponentDatabase:    al.checkDatabase,
+		ComponentStorage:     al.checkStorage,
+		ComponentAuth:        al.checkAuth,
+		ComponentClientsAuth: al.checkClientsAuth,
+	}
+}
+
+// readinessChecks returns the checks that must pass before the server is ready to handle requests.
+func (al *APIListener) readinessChecks() map[string]componentCheck {
+	checks := al.statusChecks()
+	if al.config.API.CertFile != "" {
+		checks[ComponentCertificate] = al.checkCertificate
+	}
+	return checks
+}
+
+// livenessDetailChecks returns the checks reported by the liveness probe. They don't affect the status, an exhausted
+// port pool only fails new tunnels, so the server must neither be restarted nor taken out of the load balancer.
+func (al *APIListener) livenessDetailChecks() map[string]componentCheck {
+	checks := map[string]componentCheck{}
+	if al.portDistributor != nil {
+		checks[ComponentPortPool] = al.checkPortPool
+	}
+	return checks
+}
+
+// checkComponents runs the given health checks concurrently.
+func (al *APIListener) checkComponents(ctx context.Context, checks map[string]componentCheck) []*ComponentHealth {
+	ctx, cancel := context.WithTimeout(ctx, componentCheckTimeout)
+	defer cancel()
+
+	results := make(chan *ComponentHealth, len(checks))
+	for name, check := range checks {
+		go func(name string, check componentCheck) {
+			details, err := check(ctx)
+			c := newComponentHealth(name, err)
+			c.Details = details
+			results <- c
+		}(name, check)
+	}
+
+	done := make(map[string]*ComponentHealth, len(checks))
+	for len(done) < len(checks) {
+		select {
+		case c := <-results:
+			done[c.Name] = c
+		case <-ctx.Done():
+			// checks that don't support a context might not return in time
+			for name := range checks {
+				if done[name] == nil {
+					done[name] = newComponentHealth(name, ctx.Err())
+				}
+			}
+		}
+	}
+
+	components := make([]*ComponentHealth, 0, len(checks))
+	for _, c := range done {
+		if c.err != nil {
+			al.Errorf("Health check of %s failed: %v", c.Name, c.err)
+		}
+		components = append(components, c)
+	}
+	sort.Slice(components, func(i, j int) bool {
+		return components[i].Name < components[j].Name
+	})
+	return components
+}
+
+func newComponentHealth(name string, err error) *ComponentHealth {
+	status := ComponentStatusOK
+	if err != nil {
+		status = ComponentStatusError
+	}
+	return &ComponentHealth{
+		Name:   name,
+		Status: status,
+		err:    err,
+	}
+}
+
+func componentsHealthy(components []*ComponentHealth) bool {
+	for _, c := range components {
+		if c.Status != ComponentStatusOK {
+			return false
+		}
+	}
+	return true
+}
+
+func (al *APIListener) checkDatabase(ctx context.Context) (string, error) {
+	if al.clientDB != nil {
+		if err := al.clientDB.PingContext(ctx); err != nil {
+			return "", err
+		}
+	}
+	if al.authDB != nil {
+		if err := al.authDB.PingContext(ctx); err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// checkStorage makes sure the data dir is readable. It doesn't write to not wear the disk on every probe.
+func (al *APIListener) checkStorage(ctx context.Context) (string, error) {
+	f, err := os.Open(al.config.Server.DataDir)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", al.config.Server.DataDir)
+	}
+	_, err = f.Readdirnames(1)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return "", nil
+}
+
+// checkAuth looks up a non-existing user to make sure the user auth provider is reachable.
+func (al *APIListener) checkAuth(ctx context.Context) (string, error) {
+	if al.userService == nil {
+		return "", nil
+	}
+	_, err := al.userService.GetByUsername("")
+	return "", err
+}
+
+// checkClientsAuth looks up a non-existing client auth to make sure the client auth provider is reachable.
+func (al *APIListener) checkClientsAuth(ctx context.Context) (string, error) {
+	if al.clientAuthProvider == nil {
+		return "", nil
+	}
+	_, err := al.clientAuthProvider.Get("")
+	return "", err
+}
+
+// checkPortPool makes sure there are free ports left for new tunnels.
+func (al *APIListener) checkPortPool(ctx context.Context) (string, error) {
+	total, used, err := al.portDistributor.Usage(models.ProtocolTCP)
+	if err != nil {
+		return "", err
+	}
+	details := fmt.Sprintf("%d of %d ports free", total-used, total)
+	if used >= total {
+		return details, errors.New("no free ports left")
+	}
+	return details, nil
+}
+
+// checkCertificate makes sure the certificate of the API is readable and not expired.
+func (al *APIListener) checkCertificate(ctx context.Context) (string, error) {
+	certPEM, err := os.ReadFile(al.config.API.CertFile)
+	if err != nil {
+		return "", err
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return "", errors.New("no PEM certificate found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	switch {
+	case now.After(cert.NotAfter):
+		return "expired", fmt.Errorf("certificate expired at %s", cert.NotAfter)
+	case now.Before(cert.NotBefore):
+		return "not yet valid", fmt.Errorf("certificate is not valid before %s", cert.NotBefore)
+	case cert.NotAfter.Sub(now) < certificateExpiryWarning:
+		return fmt.Sprintf("expires in %d day(s)", int(cert.NotAfter.Sub(now).Hours()/24)), nil
+	}
+	return "", nil
+}
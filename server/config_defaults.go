@@ -0,0 +1,139 @@
+package chserver
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/correlation"
+	"github.com/realvnc-labs/rport/server/api/message"
+	"github.com/realvnc-labs/rport/server/api/policy"
+	auditlog "github.com/realvnc-labs/rport/server/auditlog/config"
+	"github.com/realvnc-labs/rport/server/autotags"
+	"github.com/realvnc-labs/rport/server/capture"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clientpayload"
+	"github.com/realvnc-labs/rport/server/clientsnapshot"
+	"github.com/realvnc-labs/rport/server/hooks"
+	"github.com/realvnc-labs/rport/server/sessionrecording"
+	"github.com/realvnc-labs/rport/server/tunnelapproval"
+	"github.com/realvnc-labs/rport/server/updatesrefresh"
+)
+
+const (
+	DefaultKeepDisconnectedClients           = time.Hour
+	DefaultPurgeDisconnectedClientsInterval  = 1 * time.Minute
+	DefaultCheckClientsConnectionInterval    = 5 * time.Minute
+	DefaultCheckClientsConnectionTimeout     = 30 * time.Second
+	DefaultMaxRequestBytes                   = 10 * 1024       // 10 KB
+	DefaultMaxRequestBytesClient             = 512 * 1024      // 512KB
+	DefaultMaxFilePushBytes                  = int64(10 << 20) // 10M
+	DefaultCheckPortTimeout                  = 2 * time.Second
+	DefaultUsedPorts                         = "20000-30000"
+	DefaultExcludedPorts                     = "1-1024"
+	DefaultServerAddress                     = "0.0.0.0:8080"
+	DefaultLogLevel                          = "info"
+	DefaultRunRemoteCmdTimeoutSec            = 60
+	DefaultMonitoringDataStorageDuration     = "7d"
+	DefaultMonitoringRollup5mStorageDuration = "90d"
+	DefaultMonitoringRollup1hStorageDuration = "730d"
+	DefaultPairingURL                        = "https://pairing.rport.io"
+)
+
+var (
+	DefaultMaxConcurrentSSHConnectionHandshakes = calcMaxConcurrentSSHConnectionHandshakes()
+)
+
+func calcMaxConcurrentSSHConnectionHandshakes() (max int) {
+	maxProcs := runtime.GOMAXPROCS(0)
+	if maxProcs == 1 {
+		return maxProcs
+	}
+	return maxProcs / 2
+}
+
+// SetConfigDefaults sets the default values of the server config, used by rportd and servers embedded in other programs.
+func SetConfigDefaults(v *viper.Viper) {
+	v.SetDefault("logging.log_level", DefaultLogLevel)
+	v.SetDefault("server.address", DefaultServerAddress)
+	v.SetDefault("server.used_ports", []string{DefaultUsedPorts})
+	v.SetDefault("server.excluded_ports", []string{DefaultExcludedPorts})
+	v.SetDefault("server.data_dir", DefaultDataDirectory)
+	v.SetDefault("server.sqlite_wal", true)
+	v.SetDefault("server.sqlite_busy_timeout", sqlite.DefaultBusyTimeout)
+	v.SetDefault("server.keep_disconnected_clients", DefaultKeepDisconnectedClients)
+	v.SetDefault("server.max_concurrent_ssh_handshakes", DefaultMaxConcurrentSSHConnectionHandshakes)
+	v.SetDefault("server.purge_disconnected_clients_interval", DefaultPurgeDisconnectedClientsInterval)
+	v.SetDefault("server.check_clients_connection_interval", DefaultCheckClientsConnectionInterval)
+	v.SetDefault("server.check_clients_connection_timeout", DefaultCheckClientsConnectionTimeout)
+	v.SetDefault("server.max_request_bytes_client", DefaultMaxRequestBytesClient)
+	v.SetDefault("server.check_port_timeout", DefaultCheckPortTimeout)
+	v.SetDefault("server.auth_write", true)
+	v.SetDefault("server.auth_multiuse_creds", true)
+	v.SetDefault("server.run_remote_cmd_timeout_sec", DefaultRunRemoteCmdTimeoutSec)
+	v.SetDefault("server.client_login_wait", 2)
+	v.SetDefault("server.max_failed_login", 5)
+	v.SetDefault("server.pairing_url", DefaultPairingURL)
+	v.SetDefault("server.ban_time", 3600)
+	v.SetDefault("server.jobs_max_results", 10000)
+	v.SetDefault("server.tls_min", "1.3")
+	v.SetDefault("server.session_recording_retention", sessionrecording.DefaultRetention)
+	v.SetDefault("server.capture_max_duration", capture.DefaultMaxDuration)
+	v.SetDefault("server.capture_max_bytes", capture.DefaultMaxBytes)
+	v.SetDefault("server.capture_quota", capture.DefaultQuota)
+	v.SetDefault("server.capture_retention", capture.DefaultRetention)
+	v.SetDefault("server.exec_hooks_timeout", hooks.DefaultTimeout)
+	v.SetDefault("server.alerting_flapping_window", correlation.DefaultFlappingWindow)
+	v.SetDefault("server.alerting_flapping_threshold", correlation.DefaultFlappingThreshold)
+	v.SetDefault("server.alerting_clock_skew_threshold", chconfig.DefaultClockSkewThreshold)
+	v.SetDefault("server.updates_status_refresh_min_interval", updatesrefresh.DefaultMinInterval)
+	v.SetDefault("server.updates_status_refresh_timeout", updatesrefresh.DefaultTimeout)
+	v.SetDefault("server.auto_tag_unstable_disconnects", autotags.DefaultUnstableDisconnects)
+	v.SetDefault("server.auto_tag_unstable_period", autotags.DefaultUnstablePeriod)
+	v.SetDefault("server.auto_tag_stale_after", autotags.DefaultStaleAfter)
+	v.SetDefault("server.client_payload_max_identifier_length", clientpayload.DefaultMaxIdentifierLength)
+	v.SetDefault("server.client_payload_max_text_length", clientpayload.DefaultMaxTextLength)
+	v.SetDefault("server.client_payload_max_tags", clientpayload.DefaultMaxTags)
+	v.SetDefault("server.client_payload_max_tag_length", clientpayload.DefaultMaxTagLength)
+	v.SetDefault("server.client_payload_max_labels", clientpayload.DefaultMaxLabels)
+	v.SetDefault("server.client_payload_max_label_length", clientpayload.DefaultMaxLabelLength)
+	v.SetDefault("server.client_payload_max_addresses", clientpayload.DefaultMaxAddresses)
+	v.SetDefault("server.client_payload_on_oversize", clientpayload.OnOversizeReject)
+	v.SetDefault("server.tunnel_approval_timeout", tunnelapproval.DefaultTimeout)
+	v.SetDefault("server.maintenance_vacuum", true)
+	v.SetDefault("server.client_snapshot_interval", clientsnapshot.DefaultInterval)
+	v.SetDefault("server.client_snapshot_retention", clientsnapshot.DefaultRetention)
+	v.SetDefault("api.user_header", "Authentication-User")
+	v.SetDefault("api.default_user_group", "Administrators")
+	v.SetDefault("api.user_login_wait", 2)
+	v.SetDefault("api.max_failed_login", 10)
+	v.SetDefault("api.ban_time", 600)
+	v.SetDefault("api.opa_timeout", policy.DefaultTimeout)
+	v.SetDefault("api.two_fa_token_ttl_seconds", 600)
+	v.SetDefault("api.two_fa_send_timeout", 10*time.Second)
+	v.SetDefault("api.two_fa_send_to_type", message.ValidationNone)
+	v.SetDefault("twilio.api_url", message.TwilioDefaultAPIURL)
+	v.SetDefault("api.enable_audit_log", true)
+	v.SetDefault("api.totp_enabled", false)
+	v.SetDefault("api.audit_log_rotation", auditlog.RotationMonthly)
+	v.SetDefault("monitoring.data_storage_duration", DefaultMonitoringDataStorageDuration)
+	v.SetDefault("monitoring.rollup_5m_storage_duration", DefaultMonitoringRollup5mStorageDuration)
+	v.SetDefault("monitoring.rollup_1h_storage_duration", DefaultMonitoringRollup1hStorageDuration)
+	v.SetDefault("monitoring.enabled", true)
+	v.SetDefault("monitoring.capacity_warning_days", 14)
+	v.SetDefault("api.max_request_bytes", DefaultMaxRequestBytes)
+	v.SetDefault("api.max_filepush_size", DefaultMaxFilePushBytes)
+	v.SetDefault("api.enable_ws_test_endpoints", false)
+	v.SetDefault("api.totp_login_session_ttl", time.Minute*10)
+	v.SetDefault("api.totp_account_name", "RPort")
+	v.SetDefault("api.password_min_length", 14)
+	v.SetDefault("api.password_zxcvbn_minscore", 0)
+	v.SetDefault("api.tls_min", "1.3")
+	v.SetDefault("api.compression", []string{"gzip", "deflate"})
+	v.SetDefault("api.compression_min_size", 1024)
+	v.SetDefault("api.enable_http2", true)
+	v.SetDefault("api.health_probes_enabled", true)
+	v.SetDefault("api.prometheus_metrics_enabled", false)
+}
@@ -0,0 +1,227 @@
+package embedded
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/crypto/ssh"
+
+	chshare "github.com/realvnc-labs/rport/share"
+	"github.com/realvnc-labs/rport/share/clientconfig"
+	"github.com/realvnc-labs/rport/share/comm"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/random"
+)
+
+const connectTimeout = 10 * time.Second
+
+// JobHandler sets the status and result of a command or script sent to a test client. Timestamps and the pid are
+// filled by the client.
+type JobHandler func(job *models.Job)
+
+type ClientOptions struct {
+	// ID of the client, required.
+	ID string
+	// Name of the client, the id if empty.
+	Name   string
+	Tags   []string
+	Labels map[string]string
+	// Tunnels are the remotes requested on connect, e.g. "22" or "2222:22".
+	Tunnels []string
+	// RunJob handles commands and scripts, they succeed without output if nil.
+	RunJob JobHandler
+}
+
+// Client is a test client speaking the real client protocol without touching the local system. Commands and scripts
+// are answered by the job handler and tunnel streams echo the received data.
+type Client struct {
+	ID     string
+	conn   ssh.Conn
+	runJob JobHandler
+	logger *logger.Logger
+}
+
+// ConnectClient connects a test client and returns once the server accepted it.
+func (s *Server) ConnectClient(ctx context.Context, opts ClientOptions) (*Client, error) {
+	if opts.ID == "" {
+		return nil, errors.New("client id is required")
+	}
+	if opts.Name == "" {
+		opts.Name = opts.ID
+	}
+	if opts.RunJob == nil {
+		opts.RunJob = func(job *models.Job) {
+			job.Status = models.JobStatusSuccessful
+		}
+	}
+
+	var remotes []*models.Remote
+	for _, t := range opts.Tunnels {
+		r, err := models.NewRemote(t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tunnel %q: %v", t, err)
+		}
+		remotes = append(remotes, r)
+	}
+
+	d := &websocket.Dialer{
+		HandshakeTimeout: connectTimeout,
+		Subprotocols:     []string{chshare.ProtocolVersion},
+	}
+	wsConn, _, err := d.DialContext(ctx, "ws://"+s.ClientListenerAddr().String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint := s.Fingerprint()
+	conn, chans, reqs, err := ssh.NewClientConn(chshare.NewWebSocketConn(wsConn), "", &ssh.ClientConfig{
+		User:          s.clientUser,
+		Auth:          []ssh.AuthMethod{ssh.Password(s.clientPass)},
+		ClientVersion: "SSH-" + chshare.ProtocolVersion + "-client",
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			if got := chshare.FingerprintKey(key); got != fingerprint {
+				return fmt.Errorf("invalid fingerprint (%s)", got)
+			}
+			return nil
+		},
+		Timeout: connectTimeout,
+	})
+	if err != nil {
+		wsConn.Close()
+		return nil, err
+	}
+
+	c := &Client{
+		ID:     opts.ID,
+		conn:   conn,
+		runJob: opts.RunJob,
+		logger: s.Logger.Fork("test client %s", opts.ID),
+	}
+	go c.handleRequests(reqs)
+	go c.handleStreams(chans)
+
+	sessionID, err := random.UUID4()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	req, err := chshare.EncodeConnectionRequest(&chshare.ConnectionRequest{
+		ID:        opts.ID,
+		Name:      opts.Name,
+		SessionID: sessionID,
+		OS:        "Linux test",
+		OSKernel:  "linux",
+		OSFamily:  "test",
+		OSArch:    "amd64",
+		Version:   chshare.BuildVersion,
+		Hostname:  opts.Name,
+		Tags:      opts.Tags,
+		Labels:    opts.Labels,
+		Remotes:   remotes,
+		ClientConfiguration: &clientconfig.Config{
+			RemoteCommands: clientconfig.CommandsConfig{Enabled: true},
+			RemoteScripts:  clientconfig.ScriptsConfig{Enabled: true},
+		},
+		Mode:         clientconfig.ModeFull,
+		Capabilities: models.NewClientCapabilities(),
+	})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	ok, resp, err := comm.SendRequestWithTimeout(ctx, conn, "new_connection", true, req, connectTimeout, c.logger)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("connection request failed: %v", err)
+	}
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("connection rejected: %s", resp)
+	}
+	return c, nil
+}
+
+// Close disconnects the client.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) handleRequests(reqs <-chan *ssh.Request) {
+	for r := range reqs {
+		var resp interface{}
+		switch r.Type {
+		case comm.RequestTypePing:
+			_ = r.Reply(true, nil)
+			continue
+		case comm.RequestTypeRunCmd:
+			job := &models.Job{}
+			if err := json.Unmarshal(r.Payload, job); err != nil {
+				comm.ReplyError(c.logger, r, err)
+				continue
+			}
+			startedAt := time.Now()
+			resp = &comm.RunCmdResponse{Pid: 1, StartedAt: startedAt}
+			go c.finishJob(job, startedAt)
+		case comm.RequestTypeCheckPort:
+			resp = &comm.CheckPortResponse{Open: true}
+		case comm.RequestTypeCheckTunnelAllowed:
+			resp = &comm.CheckTunnelAllowedResponse{IsAllowed: true}
+		case comm.RequestTypeGetInterpreters:
+			resp = []models.Interpreter{}
+		case comm.RequestTypeUpdateClientAttributes,
+			comm.RequestTypePutCapabilities,
+			comm.RequestTypeRefreshUpdatesStatus,
+			comm.RequestTypeSetMode,
+			comm.RequestTypeSetMonitoringProfile:
+			// accepted without doing anything
+		default:
+			comm.ReplyError(c.logger, r, fmt.Errorf("%q is not supported by test clients", r.Type))
+			continue
+		}
+		comm.ReplySuccessJSON(c.logger, r, resp)
+	}
+}
+
+func (c *Client) finishJob(job *models.Job, startedAt time.Time) {
+	c.runJob(job)
+
+	pid := 1
+	finishedAt := time.Now()
+	job.PID = &pid
+	job.StartedAt = startedAt
+	job.FinishedAt = &finishedAt
+	if job.Result == nil {
+		job.Result = &models.JobResult{}
+	}
+
+	jobBytes, err := json.Marshal(job)
+	if err != nil {
+		c.logger.Errorf("Failed to encode job result [jid=%q]: %v", job.JID, err)
+		return
+	}
+	if _, _, err := c.conn.SendRequest(comm.RequestTypeCmdResult, false, jobBytes); err != nil {
+		c.logger.Errorf("Failed to send job result [jid=%q]: %v", job.JID, err)
+	}
+}
+
+func (c *Client) handleStreams(chans <-chan ssh.NewChannel) {
+	for ch := range chans {
+		stream, reqs, err := ch.Accept()
+		if err != nil {
+			continue
+		}
+		go ssh.DiscardRequests(reqs)
+		go func() {
+			defer stream.Close()
+			_, _ = io.Copy(stream, stream)
+		}()
+	}
+}
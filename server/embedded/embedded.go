@@ -0,0 +1,202 @@
+// Package embedded runs a rport server inside another Go program, e.g. for integration tests of projects that embed
+// or extend rport without shelling out to the binaries.
+package embedded
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	chserver "github.com/realvnc-labs/rport/server"
+	"github.com/realvnc-labs/rport/server/chconfig"
+	chshare "github.com/realvnc-labs/rport/share"
+	"github.com/realvnc-labs/rport/share/files"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+const (
+	DefaultClientAuth = "client1:client-password"
+	DefaultAPIAuth    = "admin:admin-password"
+	DefaultLogLevel   = "error"
+)
+
+type Options struct {
+	// Config holds additional server settings in the TOML format of rportd.conf.
+	Config string
+	// ClientAuth holds the client credentials "<user>:<password>", DefaultClientAuth if empty.
+	ClientAuth string
+	// APIAuth holds the credentials "<user>:<password>" of the API user, DefaultAPIAuth if empty.
+	APIAuth string
+	// DataDir is the data directory of the server. If empty a temporary directory is used and removed on Stop.
+	DataDir string
+}
+
+// Server is a rport server listening on random local ports. Client and API users are kept in memory, the other
+// stores use the data directory.
+type Server struct {
+	*chserver.Server
+	config     *chconfig.Config
+	clientUser string
+	clientPass string
+	apiUser    string
+	apiPass    string
+	tempDir    string
+	cancel     context.CancelFunc
+	done       chan error
+}
+
+// Start creates a server and returns once it accepts client and API connections.
+func Start(ctx context.Context, opts Options) (*Server, error) {
+	s := &Server{}
+
+	var err error
+	s.clientUser, s.clientPass, err = parseAuth(opts.ClientAuth, DefaultClientAuth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client auth: %v", err)
+	}
+	s.apiUser, s.apiPass, err = parseAuth(opts.APIAuth, DefaultAPIAuth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid api auth: %v", err)
+	}
+
+	dataDir := opts.DataDir
+	if dataDir == "" {
+		s.tempDir, err = os.MkdirTemp("", "rportd-embedded-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create data dir: %v", err)
+		}
+		dataDir = s.tempDir
+	}
+
+	s.config, err = newConfig(opts, dataDir, s.clientUser+":"+s.clientPass, s.apiUser+":"+s.apiPass)
+	if err != nil {
+		s.removeTempDir()
+		return nil, err
+	}
+	if err := s.config.Logging.LogOutput.Start(); err != nil {
+		s.removeTempDir()
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.Server, err = chserver.NewServer(runCtx, s.config, &chserver.ServerOpts{
+		FilesAPI: files.NewFileSystem(),
+	})
+	if err != nil {
+		s.shutdown()
+		return nil, err
+	}
+
+	s.done = make(chan error, 1)
+	go func() {
+		s.done <- s.Server.Run(runCtx)
+	}()
+
+	select {
+	case <-s.Server.Started():
+		return s, nil
+	case err := <-s.done:
+		s.shutdown()
+		if err == nil {
+			err = errors.New("server stopped while starting")
+		}
+		return nil, err
+	}
+}
+
+func newConfig(opts Options, dataDir, clientAuth, apiAuth string) (*chconfig.Config, error) {
+	v := viper.New()
+	v.SetConfigType("toml")
+	chserver.SetConfigDefaults(v)
+	v.SetDefault("logging.log_level", DefaultLogLevel)
+	v.SetDefault("server.address", "127.0.0.1:0")
+	v.SetDefault("api.address", "127.0.0.1:0")
+	v.SetDefault("server.data_dir", dataDir)
+	v.SetDefault("server.auth", clientAuth)
+	v.SetDefault("api.auth", apiAuth)
+
+	cfg := &chconfig.Config{}
+	if err := chshare.DecodeViperConfig(v, cfg, strings.NewReader(opts.Config)); err != nil {
+		return nil, err
+	}
+
+	mLog := logger.NewMemLogger()
+	if err := cfg.ParseAndValidate(&mLog); err != nil {
+		return nil, fmt.Errorf("invalid config: %v", err)
+	}
+	return cfg, nil
+}
+
+func parseAuth(auth, defaultAuth string) (user, pass string, err error) {
+	if auth == "" {
+		auth = defaultAuth
+	}
+	user, pass, ok := strings.Cut(auth, ":")
+	if !ok || user == "" || pass == "" {
+		return "", "", errors.New(`must be set as "<user>:<password>"`)
+	}
+	return user, pass, nil
+}
+
+// Stop stops the server and waits until all listeners and stores are closed.
+func (s *Server) Stop() error {
+	s.cancel()
+	err := <-s.done
+	s.config.Logging.LogOutput.Shutdown()
+	s.removeTempDir()
+
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+func (s *Server) shutdown() {
+	s.cancel()
+	if s.Server != nil {
+		_ = s.Server.Close()
+	}
+	s.config.Logging.LogOutput.Shutdown()
+	s.removeTempDir()
+}
+
+func (s *Server) removeTempDir() {
+	if s.tempDir != "" {
+		_ = os.RemoveAll(s.tempDir)
+	}
+}
+
+// Config returns the config the server runs with.
+func (s *Server) Config() *chconfig.Config {
+	return s.config
+}
+
+// URL returns the url clients connect to.
+func (s *Server) URL() string {
+	return "http://" + s.ClientListenerAddr().String()
+}
+
+// APIURL returns the base url of the API, e.g. http://127.0.0.1:43215/api/v1.
+func (s *Server) APIURL() string {
+	return "http://" + s.APIListenerAddr().String() + "/api/v1"
+}
+
+// APIRequest sends a request authenticated as the API user, path is relative to APIURL, e.g. "/clients".
+func (s *Server) APIRequest(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.APIURL()+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(s.apiUser, s.apiPass)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return http.DefaultClient.Do(req)
+}
@@ -0,0 +1,88 @@
+package embedded
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := Start(ctx, Options{})
+	require.NoError(t, err)
+	dataDir := s.Config().Server.DataDir
+	assert.DirExists(t, dataDir)
+
+	c, err := s.ConnectClient(ctx, ClientOptions{
+		ID:   "client-1",
+		Tags: []string{"test"},
+		RunJob: func(job *models.Job) {
+			job.Status = models.JobStatusSuccessful
+			job.Result = &models.JobResult{StdOut: "ran " + job.Command}
+		},
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	var clients struct {
+		Data []struct {
+			ID   string   `json:"id"`
+			Tags []string `json:"tags"`
+		} `json:"data"`
+	}
+	apiJSON(t, s, http.MethodGet, "/clients?fields[clients]=id,tags", "", http.StatusOK, &clients)
+	require.Len(t, clients.Data, 1)
+	assert.Equal(t, "client-1", clients.Data[0].ID)
+	assert.Equal(t, []string{"test"}, clients.Data[0].Tags)
+
+	var newJob struct {
+		Data struct {
+			JID string `json:"jid"`
+		} `json:"data"`
+	}
+	apiJSON(t, s, http.MethodPost, "/clients/client-1/commands", `{"command":"whoami"}`, http.StatusOK, &newJob)
+	require.NotEmpty(t, newJob.Data.JID)
+
+	var job struct {
+		Data models.Job `json:"data"`
+	}
+	assert.Eventually(t, func() bool {
+		apiJSON(t, s, http.MethodGet, "/clients/client-1/commands/"+newJob.Data.JID, "", http.StatusOK, &job)
+		return job.Data.Status == models.JobStatusSuccessful
+	}, 5*time.Second, 50*time.Millisecond)
+	require.NotNil(t, job.Data.Result)
+	assert.Equal(t, "ran whoami", job.Data.Result.StdOut)
+
+	require.NoError(t, s.Stop())
+	_, err = os.Stat(dataDir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestStartInvalidConfig(t *testing.T) {
+	_, err := Start(context.Background(), Options{Config: "[server]\n  client_id_policy = \"invalid\"\n"})
+	assert.Error(t, err)
+
+	_, err = Start(context.Background(), Options{APIAuth: "admin"})
+	assert.EqualError(t, err, `invalid api auth: must be set as "<user>:<password>"`)
+}
+
+func apiJSON(t *testing.T, s *Server, method, path, body string, wantStatus int, dest interface{}) {
+	t.Helper()
+
+	resp, err := s.APIRequest(context.Background(), method, path, strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, wantStatus, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(dest))
+}
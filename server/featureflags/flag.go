@@ -0,0 +1,80 @@
+package featureflags
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+
+// Rollout defines who a flag is enabled for. A flag is enabled for everyone if Enabled is set, otherwise only for
+// users of the user groups and clients of the client groups.
+type Rollout struct {
+	Enabled      bool     `mapstructure:"enabled" json:"enabled"`
+	UserGroups   []string `mapstructure:"user_groups" json:"user_groups"`
+	ClientGroups []string `mapstructure:"client_groups" json:"client_groups"`
+}
+
+func (r *Rollout) Validate() error {
+	for _, group := range r.UserGroups {
+		if group == "" {
+			return errors.New("user_groups cannot contain an empty group")
+		}
+	}
+	for _, group := range r.ClientGroups {
+		if group == "" {
+			return errors.New("client_groups cannot contain an empty group")
+		}
+	}
+	return nil
+}
+
+// EnabledForUser returns true if the rollout includes a user of the given user groups.
+func (r *Rollout) EnabledForUser(userGroups []string) bool {
+	return r.Enabled || containsAny(r.UserGroups, userGroups)
+}
+
+// EnabledForClient returns true if the rollout includes a client of the given client groups.
+func (r *Rollout) EnabledForClient(clientGroups []string) bool {
+	return r.Enabled || containsAny(r.ClientGroups, clientGroups)
+}
+
+// Flag gates a new behavior. The configured rollout is the default, it can be changed via the API.
+type Flag struct {
+	Name        string  `mapstructure:"name"`
+	Description string  `mapstructure:"description"`
+	Rollout     Rollout `mapstructure:",squash"`
+}
+
+func (f *Flag) Validate() error {
+	if !validName.MatchString(f.Name) {
+		return fmt.Errorf("invalid name %q: only lower case letters, digits, '_', '.' and '-' are allowed, up to 64 characters", f.Name)
+	}
+	return f.Rollout.Validate()
+}
+
+func ValidateFlags(flags []Flag) error {
+	names := make(map[string]bool, len(flags))
+	for i := range flags {
+		if err := flags[i].Validate(); err != nil {
+			return fmt.Errorf("invalid feature flag %d: %v", i+1, err)
+		}
+		if names[flags[i].Name] {
+			return fmt.Errorf("duplicate feature flag %q", flags[i].Name)
+		}
+		names[flags[i].Name] = true
+	}
+	return nil
+}
+
+func containsAny(values, wanted []string) bool {
+	for _, v := range values {
+		for _, w := range wanted {
+			if v == w {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,213 @@
+package featureflags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	apiErrors "github.com/realvnc-labs/rport/server/api/errors"
+)
+
+// State is a flag with the rollout in effect.
+type State struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Rollout     Rollout `json:"rollout"`
+	// Default is the configured rollout, used again when the flag is reset.
+	Default   Rollout    `json:"default"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+type override struct {
+	Name      string    `db:"name"`
+	Rollout   []byte    `db:"rollout"`
+	UpdatedBy string    `db:"updated_by"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Service keeps the configured flags and the rollouts changed via the API. Changed rollouts are stored in the
+// database, rollouts of flags removed from the config are ignored.
+type Service struct {
+	db  *sqlx.DB
+	now func() time.Time
+
+	flags map[string]*State
+	mu    sync.RWMutex
+}
+
+func NewService(ctx context.Context, db *sqlx.DB, flags []Flag) (*Service, error) {
+	s := &Service{
+		db:    db,
+		now:   time.Now,
+		flags: make(map[string]*State, len(flags)),
+	}
+	for _, f := range flags {
+		s.flags[f.Name] = &State{
+			Name:        f.Name,
+			Description: f.Description,
+			Rollout:     f.Rollout,
+			Default:     f.Rollout,
+		}
+	}
+
+	var overrides []override
+	err := db.SelectContext(ctx, &overrides, "SELECT name, rollout, updated_by, updated_at FROM feature_flags")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load feature flags: %w", err)
+	}
+	for _, o := range overrides {
+		state, ok := s.flags[o.Name]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(o.Rollout, &state.Rollout); err != nil {
+			return nil, fmt.Errorf("failed to decode rollout of feature flag %q: %w", o.Name, err)
+		}
+		updatedAt := o.UpdatedAt
+		state.UpdatedBy = o.UpdatedBy
+		state.UpdatedAt = &updatedAt
+	}
+	return s, nil
+}
+
+// List returns all configured flags sorted by name.
+func (s *Service) List() []State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]State, 0, len(s.flags))
+	for _, state := range s.flags {
+		result = append(result, *state)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func (s *Service) Get(name string) (State, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, ok := s.flags[name]
+	if !ok {
+		return State{}, notFound(name)
+	}
+	return *state, nil
+}
+
+// Update changes the rollout of a flag until it's reset.
+func (s *Service) Update(ctx context.Context, name string, rollout Rollout, updatedBy string) (State, error) {
+	if err := rollout.Validate(); err != nil {
+		return State{}, apiErrors.NewAPIError(http.StatusBadRequest, "", err.Error(), nil)
+	}
+	rolloutBytes, err := json.Marshal(rollout)
+	if err != nil {
+		return State{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.flags[name]
+	if !ok {
+		return State{}, notFound(name)
+	}
+
+	updatedAt := s.now().UTC()
+	_, err = s.db.ExecContext(ctx,
+		"INSERT OR REPLACE INTO feature_flags (name, rollout, updated_by, updated_at) VALUES (?, ?, ?, ?)",
+		name, rolloutBytes, updatedBy, updatedAt,
+	)
+	if err != nil {
+		return State{}, err
+	}
+
+	state.Rollout = rollout
+	state.UpdatedBy = updatedBy
+	state.UpdatedAt = &updatedAt
+	return *state, nil
+}
+
+// Reset restores the configured rollout of a flag.
+func (s *Service) Reset(ctx context.Context, name string) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.flags[name]
+	if !ok {
+		return State{}, notFound(name)
+	}
+
+	_, err := s.db.ExecContext(ctx, "DELETE FROM feature_flags WHERE name = ?", name)
+	if err != nil {
+		return State{}, err
+	}
+
+	state.Rollout = state.Default
+	state.UpdatedBy = ""
+	state.UpdatedAt = nil
+	return *state, nil
+}
+
+// EnabledForUser returns true if the flag is enabled for a user of the given groups, false for unknown flags.
+func (s *Service) EnabledForUser(name string, userGroups []string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, ok := s.flags[name]
+	return ok && state.Rollout.EnabledForUser(userGroups)
+}
+
+// EnabledForClient returns true if the flag is enabled for a client of the given client groups, false for unknown
+// flags.
+func (s *Service) EnabledForClient(name string, clientGroups []string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, ok := s.flags[name]
+	return ok && state.Rollout.EnabledForClient(clientGroups)
+}
+
+// UserFlags returns the names of all flags enabled for a user of the given groups.
+func (s *Service) UserFlags(userGroups []string) []string {
+	return s.enabledFlags(func(r *Rollout) bool {
+		return r.EnabledForUser(userGroups)
+	})
+}
+
+// ClientFlags returns the names of all flags enabled for a client of the given client groups.
+func (s *Service) ClientFlags(clientGroups []string) []string {
+	return s.enabledFlags(func(r *Rollout) bool {
+		return r.EnabledForClient(clientGroups)
+	})
+}
+
+func (s *Service) enabledFlags(enabled func(r *Rollout) bool) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := []string{}
+	for name, state := range s.flags {
+		if enabled(&state.Rollout) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Service) Close() error {
+	return s.db.Close()
+}
+
+func notFound(name string) error {
+	return apiErrors.NewAPIError(http.StatusNotFound, "", fmt.Sprintf("Feature flag %q not found.", name), nil)
+}
@@ -0,0 +1,126 @@
+package featureflags
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	featureflagsmigration "github.com/realvnc-labs/rport/db/migration/feature_flags"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/api/errors"
+)
+
+var testFlags = []Flag{
+	{Name: "new-data-path", Description: "New tunnel data path", Rollout: Rollout{ClientGroups: []string{"canary"}}},
+	{Name: "new-auth", Rollout: Rollout{UserGroups: []string{"Beta"}}},
+	{Name: "everyone", Rollout: Rollout{Enabled: true}},
+}
+
+func TestValidateFlags(t *testing.T) {
+	assert.NoError(t, ValidateFlags(testFlags))
+
+	err := ValidateFlags([]Flag{{Name: "New Auth"}})
+	assert.EqualError(t, err, `invalid feature flag 1: invalid name "New Auth": only lower case letters, digits, '_', '.' and '-' are allowed, up to 64 characters`)
+
+	err = ValidateFlags([]Flag{{Name: "a"}, {Name: "b", Rollout: Rollout{ClientGroups: []string{""}}}})
+	assert.EqualError(t, err, "invalid feature flag 2: client_groups cannot contain an empty group")
+
+	err = ValidateFlags([]Flag{{Name: "a"}, {Name: "a"}})
+	assert.EqualError(t, err, `duplicate feature flag "a"`)
+}
+
+func TestEnabled(t *testing.T) {
+	s := newTestService(t)
+
+	assert.True(t, s.EnabledForClient("new-data-path", []string{"default", "canary"}))
+	assert.False(t, s.EnabledForClient("new-data-path", []string{"default"}))
+	assert.False(t, s.EnabledForUser("new-data-path", []string{"canary"}))
+	assert.True(t, s.EnabledForUser("new-auth", []string{"Beta"}))
+	assert.True(t, s.EnabledForUser("everyone", nil))
+	assert.True(t, s.EnabledForClient("everyone", nil))
+	assert.False(t, s.EnabledForUser("unknown", []string{"Beta"}))
+
+	assert.Equal(t, []string{"everyone", "new-auth"}, s.UserFlags([]string{"Beta"}))
+	assert.Equal(t, []string{"everyone"}, s.ClientFlags(nil))
+}
+
+func TestUpdateAndReset(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlite.New(":memory:", featureflagsmigration.AssetNames(), featureflagsmigration.Asset, sqlite.DataSourceOptions{})
+	require.NoError(t, err)
+	defer db.Close()
+
+	s, err := NewService(ctx, db, testFlags)
+	require.NoError(t, err)
+	now := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	s.now = func() time.Time {
+		return now
+	}
+
+	_, err = s.Update(ctx, "unknown", Rollout{}, "admin")
+	assertAPIError(t, http.StatusNotFound, `Feature flag "unknown" not found.`, err)
+	_, err = s.Update(ctx, "new-auth", Rollout{UserGroups: []string{""}}, "admin")
+	assertAPIError(t, http.StatusBadRequest, "user_groups cannot contain an empty group", err)
+
+	rollout := Rollout{UserGroups: []string{"Beta", "Administrators"}}
+	flag, err := s.Update(ctx, "new-auth", rollout, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, State{
+		Name:      "new-auth",
+		Rollout:   rollout,
+		Default:   Rollout{UserGroups: []string{"Beta"}},
+		UpdatedBy: "admin",
+		UpdatedAt: &now,
+	}, flag)
+	assert.True(t, s.EnabledForUser("new-auth", []string{"Administrators"}))
+
+	// changes are loaded again, changes of flags no longer configured are ignored
+	s, err = NewService(ctx, db, testFlags[1:2])
+	require.NoError(t, err)
+	flag, err = s.Get("new-auth")
+	require.NoError(t, err)
+	assert.Equal(t, rollout, flag.Rollout)
+	assert.Equal(t, "admin", flag.UpdatedBy)
+	require.NotNil(t, flag.UpdatedAt)
+	assert.True(t, now.Equal(*flag.UpdatedAt))
+	assert.Len(t, s.List(), 1)
+
+	flag, err = s.Reset(ctx, "new-auth")
+	require.NoError(t, err)
+	assert.Equal(t, State{
+		Name:    "new-auth",
+		Rollout: Rollout{UserGroups: []string{"Beta"}},
+		Default: Rollout{UserGroups: []string{"Beta"}},
+	}, flag)
+	assert.False(t, s.EnabledForUser("new-auth", []string{"Administrators"}))
+
+	s, err = NewService(ctx, db, testFlags)
+	require.NoError(t, err)
+	flag, err = s.Get("new-auth")
+	require.NoError(t, err)
+	assert.Nil(t, flag.UpdatedAt)
+}
+
+func newTestService(t *testing.T) *Service {
+	db, err := sqlite.New(":memory:", featureflagsmigration.AssetNames(), featureflagsmigration.Asset, sqlite.DataSourceOptions{})
+	require.NoError(t, err)
+	s, err := NewService(context.Background(), db, testFlags)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		s.Close()
+	})
+	return s
+}
+
+func assertAPIError(t *testing.T, wantStatus int, wantMessage string, err error) {
+	t.Helper()
+
+	apiErr, ok := err.(errors.APIError)
+	require.True(t, ok, "unexpected error: %v", err)
+	assert.Equal(t, wantStatus, apiErr.HTTPStatus)
+	assert.Equal(t, wantMessage, apiErr.Message)
+}
@@ -0,0 +1,82 @@
+package firewall
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+const (
+	BackendFirewalld = "firewalld"
+	BackendNftables  = "nftables"
+
+	DefaultNftFamily = "inet"
+	DefaultNftTable  = "filter"
+	DefaultNftSetTCP = "rport_tcp_ports"
+	DefaultNftSetUDP = "rport_udp_ports"
+)
+
+var (
+	nftNameRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+	zoneRegexp    = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+)
+
+// Config of the firewall integration. It's disabled if no backend is set.
+type Config struct {
+	Backend string `mapstructure:"backend"`
+	// DryRun logs the commands instead of running them.
+	DryRun bool `mapstructure:"dry_run"`
+
+	// FirewalldZone is the zone the ports are opened in, the default zone if empty.
+	FirewalldZone string `mapstructure:"firewalld_zone"`
+
+	NftFamily string `mapstructure:"nft_family"`
+	NftTable  string `mapstructure:"nft_table"`
+	NftSetTCP string `mapstructure:"nft_set_tcp"`
+	NftSetUDP string `mapstructure:"nft_set_udp"`
+}
+
+func (c *Config) Enabled() bool {
+	return c.Backend != ""
+}
+
+func (c *Config) ParseAndValidate() error {
+	switch c.Backend {
+	case "":
+		return nil
+	case BackendFirewalld:
+		if c.FirewalldZone != "" && !zoneRegexp.MatchString(c.FirewalldZone) {
+			return fmt.Errorf("invalid firewalld_zone %q", c.FirewalldZone)
+		}
+		return nil
+	case BackendNftables:
+		if c.NftFamily == "" {
+			c.NftFamily = DefaultNftFamily
+		}
+		if c.NftTable == "" {
+			c.NftTable = DefaultNftTable
+		}
+		if c.NftSetTCP == "" {
+			c.NftSetTCP = DefaultNftSetTCP
+		}
+		if c.NftSetUDP == "" {
+			c.NftSetUDP = DefaultNftSetUDP
+		}
+		switch c.NftFamily {
+		case "inet", "ip", "ip6":
+		default:
+			return fmt.Errorf("invalid nft_family %q, expected one of: inet, ip, ip6", c.NftFamily)
+		}
+		for _, name := range []string{c.NftTable, c.NftSetTCP, c.NftSetUDP} {
+			if !nftNameRegexp.MatchString(name) {
+				return fmt.Errorf("invalid nftables name %q", name)
+			}
+		}
+		if c.NftSetTCP == c.NftSetUDP {
+			return errors.New("nft_set_tcp and nft_set_udp must differ")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown backend %q, expected one of: %s, %s", c.Backend, BackendFirewalld, BackendNftables)
+	}
+}
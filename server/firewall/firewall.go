@@ -0,0 +1,238 @@
+// Package firewall opens the ports of tunnel listeners in the firewall of the server host while the tunnels exist, so
+// the whole range of tunnel ports doesn't need to be opened.
+package firewall
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+const commandTimeout = 10 * time.Second
+
+// RunFn runs a firewall command.
+type RunFn func(ctx context.Context, name string, args ...string) error
+
+type port struct {
+	protocol string
+	port     string
+}
+
+func (p port) String() string {
+	return p.port + "/" + p.protocol
+}
+
+// Manager keeps track of the opened ports. A nil Manager does nothing.
+type Manager struct {
+	config *Config
+	logger *logger.Logger
+	run    RunFn
+
+	// open holds the opened ports with the number of tunnel listeners using them per listen host, a port is closed
+	// when the last of them is closed
+	open map[port]map[string]int
+	mu   sync.Mutex
+}
+
+func NewManager(config *Config, logger *logger.Logger) *Manager {
+	return &Manager{
+		config: config,
+		logger: logger,
+		run:    runCommand,
+		open:   make(map[port]map[string]int),
+	}
+}
+
+// Start prepares the firewall, e.g. it creates the nftables sets and removes ports left from a previous run.
+func (m *Manager) Start() error {
+	if m == nil || m.config.Backend != BackendNftables {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, set := range []string{m.config.NftSetTCP, m.config.NftSetUDP} {
+		err := m.exec("nft", "add", "set", m.config.NftFamily, m.config.NftTable, set, "{ type inet_service; }")
+		if err != nil {
+			return err
+		}
+		err = m.exec("nft", "flush", "set", m.config.NftFamily, m.config.NftTable, set)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Open opens the port of a tunnel listener. Tunnels listening on a loopback address are skipped.
+func (m *Manager) Open(protocol, host, localPort string) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range ports(protocol, host, localPort) {
+		hosts := m.open[p]
+		if hosts == nil {
+			if err := m.exec(m.openCommand(p)...); err != nil {
+				m.logger.Errorf("failed to open port %s: %v", p, err)
+				continue
+			}
+			hosts = make(map[string]int)
+			m.open[p] = hosts
+			m.logger.Infof("opened port %s", p)
+		}
+		hosts[host]++
+	}
+}
+
+// Close releases the port of a tunnel listener opened before. The port is closed once no other tunnel listener uses it.
+func (m *Manager) Close(protocol, host, localPort string) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range ports(protocol, host, localPort) {
+		hosts := m.open[p]
+		if hosts[host] == 0 {
+			continue
+		}
+		hosts[host]--
+		if hosts[host] == 0 {
+			delete(hosts, host)
+		}
+		if len(hosts) == 0 {
+			m.close(p)
+		}
+	}
+}
+
+// CloseAll closes all ports opened before, it's called on shutdown.
+func (m *Manager) CloseAll() {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for p := range m.open {
+		m.close(p)
+	}
+}
+
+// OpenPorts returns the opened ports as port/protocol.
+func (m *Manager) OpenPorts() []string {
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := make([]string, 0, len(m.open))
+	for p := range m.open {
+		res = append(res, p.String())
+	}
+	sort.Strings(res)
+	return res
+}
+
+func (m *Manager) close(p port) {
+	if _, ok := m.open[p]; !ok {
+		return
+	}
+	delete(m.open, p)
+	if err := m.exec(m.closeCommand(p)...); err != nil {
+		m.logger.Errorf("failed to close port %s: %v", p, err)
+		return
+	}
+	m.logger.Infof("closed port %s", p)
+}
+
+func (m *Manager) openCommand(p port) []string {
+	if m.config.Backend == BackendFirewalld {
+		return m.firewalldCommand("--add-port=" + p.String())
+	}
+	return m.nftElementCommand("add", p)
+}
+
+func (m *Manager) closeCommand(p port) []string {
+	if m.config.Backend == BackendFirewalld {
+		return m.firewalldCommand("--remove-port=" + p.String())
+	}
+	return m.nftElementCommand("delete", p)
+}
+
+// firewalldCommand changes the runtime configuration only, so ports are never kept permanently open.
+func (m *Manager) firewalldCommand(arg string) []string {
+	cmd := []string{"firewall-cmd"}
+	if m.config.FirewalldZone != "" {
+		cmd = append(cmd, "--zone="+m.config.FirewalldZone)
+	}
+	return append(cmd, arg)
+}
+
+func (m *Manager) nftElementCommand(action string, p port) []string {
+	set := m.config.NftSetTCP
+	if p.protocol == models.ProtocolUDP {
+		set = m.config.NftSetUDP
+	}
+	return []string{"nft", action, "element", m.config.NftFamily, m.config.NftTable, set, "{ " + p.port + " }"}
+}
+
+func (m *Manager) exec(cmd ...string) error {
+	if m.config.DryRun {
+		m.logger.Infof("dry run: %s", strings.Join(cmd, " "))
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	return m.run(ctx, cmd[0], cmd[1:]...)
+}
+
+func runCommand(ctx context.Context, name string, args ...string) error {
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...) // #nosec G204
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timeout of %s exceeded", commandTimeout)
+	}
+	if err != nil {
+		if output.Len() > 0 {
+			return fmt.Errorf("%v: %s", err, bytes.TrimSpace(output.Bytes()))
+		}
+		return err
+	}
+	return nil
+}
+
+// ports returns the ports to open for the tunnel listener, none if it listens on a loopback address.
+func ports(protocol, host, localPort string) []port {
+	if ip := net.ParseIP(host); (ip != nil && ip.IsLoopback()) || host == "localhost" {
+		return nil
+	}
+
+	switch protocol {
+	case models.ProtocolTCPUDP:
+		return []port{{protocol: models.ProtocolTCP, port: localPort}, {protocol: models.ProtocolUDP, port: localPort}}
+	case models.ProtocolUDP:
+		return []port{{protocol: models.ProtocolUDP, port: localPort}}
+	default:
+		return []port{{protocol: models.ProtocolTCP, port: localPort}}
+	}
+}
@@ -0,0 +1,183 @@
+package firewall
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+var testLog = logger.NewLogger("firewall", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+
+type commandRecorder struct {
+	commands []string
+	err      error
+}
+
+func (r *commandRecorder) run(_ context.Context, name string, args ...string) error {
+	r.commands = append(r.commands, name+" "+strings.Join(args, " "))
+	return r.err
+}
+
+func newTestManager(t *testing.T, config *Config) (*Manager, *commandRecorder) {
+	require.NoError(t, config.ParseAndValidate())
+	recorder := &commandRecorder{}
+	m := NewManager(config, testLog)
+	m.run = recorder.run
+	return m, recorder
+}
+
+func TestManagerFirewalld(t *testing.T) {
+	m, recorder := newTestManager(t, &Config{Backend: BackendFirewalld, FirewalldZone: "public"})
+	require.NoError(t, m.Start())
+
+	m.Open("tcp+udp", "0.0.0.0", "20001")
+	m.Open("tcp", "127.0.0.1", "20002")
+	m.Open("tcp", "::1", "20003")
+	// opening twice doesn't run the command again
+	m.Open("tcp", "", "20001")
+	assert.Equal(t, []string{"20001/tcp", "20001/udp"}, m.OpenPorts())
+
+	m.Close("udp", "0.0.0.0", "20001")
+	m.CloseAll()
+	assert.Empty(t, m.OpenPorts())
+
+	assert.Equal(t, []string{
+		"firewall-cmd --zone=public --add-port=20001/tcp",
+		"firewall-cmd --zone=public --add-port=20001/udp",
+		"firewall-cmd --zone=public --remove-port=20001/udp",
+		"firewall-cmd --zone=public --remove-port=20001/tcp",
+	}, recorder.commands)
+}
+
+func TestManagerNftables(t *testing.T) {
+	m, recorder := newTestManager(t, &Config{Backend: BackendNftables})
+	require.NoError(t, m.Start())
+
+	m.Open("udp", "10.0.0.1", "20001")
+	m.Close("udp", "10.0.0.1", "20001")
+
+	assert.Equal(t, []string{
+		"nft add set inet filter rport_tcp_ports { type inet_service; }",
+		"nft flush set inet filter rport_tcp_ports",
+		"nft add set inet filter rport_udp_ports { type inet_service; }",
+		"nft flush set inet filter rport_udp_ports",
+		"nft add element inet filter rport_udp_ports { 20001 }",
+		"nft delete element inet filter rport_udp_ports { 20001 }",
+	}, recorder.commands)
+}
+
+func TestManagerSharedPort(t *testing.T) {
+	m, recorder := newTestManager(t, &Config{Backend: BackendFirewalld})
+
+	// tunnels listening on the same port of different addresses or replacing each other share the rule
+	m.Open("tcp", "10.0.0.1", "20001")
+	m.Open("tcp", "10.0.0.2", "20001")
+	m.Open("tcp", "10.0.0.2", "20001")
+	// not opened before
+	m.Close("tcp", "10.0.0.3", "20001")
+
+	m.Close("tcp", "10.0.0.1", "20001")
+	m.Close("tcp", "10.0.0.2", "20001")
+	assert.Equal(t, []string{"20001/tcp"}, m.OpenPorts())
+
+	m.Close("tcp", "10.0.0.2", "20001")
+	assert.Empty(t, m.OpenPorts())
+	// closing twice doesn't run the command again
+	m.Close("tcp", "10.0.0.2", "20001")
+
+	assert.Equal(t, []string{
+		"firewall-cmd --add-port=20001/tcp",
+		"firewall-cmd --remove-port=20001/tcp",
+	}, recorder.commands)
+}
+
+func TestManagerDryRun(t *testing.T) {
+	m, recorder := newTestManager(t, &Config{Backend: BackendNftables, DryRun: true})
+	require.NoError(t, m.Start())
+
+	m.Open("tcp", "0.0.0.0", "20001")
+	assert.Equal(t, []string{"20001/tcp"}, m.OpenPorts())
+	assert.Empty(t, recorder.commands)
+}
+
+func TestManagerCommandFails(t *testing.T) {
+	m, recorder := newTestManager(t, &Config{Backend: BackendFirewalld})
+	recorder.err = errors.New("not running")
+
+	m.Open("tcp", "0.0.0.0", "20001")
+	assert.Empty(t, m.OpenPorts())
+	assert.Equal(t, []string{"firewall-cmd --add-port=20001/tcp"}, recorder.commands)
+}
+
+func TestNilManager(t *testing.T) {
+	var m *Manager
+	require.NoError(t, m.Start())
+	m.Open("tcp", "0.0.0.0", "20001")
+	m.Close("tcp", "0.0.0.0", "20001")
+	m.CloseAll()
+	assert.Empty(t, m.OpenPorts())
+}
+
+func TestConfigParseAndValidate(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Config        Config
+		ExpectedError string
+	}{
+		{
+			Name:   "disabled",
+			Config: Config{},
+		},
+		{
+			Name:   "firewalld",
+			Config: Config{Backend: BackendFirewalld, FirewalldZone: "rport-tunnels"},
+		},
+		{
+			Name:          "invalid zone",
+			Config:        Config{Backend: BackendFirewalld, FirewalldZone: "public --panic-on"},
+			ExpectedError: `invalid firewalld_zone "public --panic-on"`,
+		},
+		{
+			Name:   "nftables",
+			Config: Config{Backend: BackendNftables, NftFamily: "ip6"},
+		},
+		{
+			Name:          "invalid nft family",
+			Config:        Config{Backend: BackendNftables, NftFamily: "arp"},
+			ExpectedError: `invalid nft_family "arp", expected one of: inet, ip, ip6`,
+		},
+		{
+			Name:          "invalid nft set",
+			Config:        Config{Backend: BackendNftables, NftSetTCP: "ports; flush ruleset"},
+			ExpectedError: `invalid nftables name "ports; flush ruleset"`,
+		},
+		{
+			Name:          "same nft sets",
+			Config:        Config{Backend: BackendNftables, NftSetTCP: "ports", NftSetUDP: "ports"},
+			ExpectedError: "nft_set_tcp and nft_set_udp must differ",
+		},
+		{
+			Name:          "unknown backend",
+			Config:        Config{Backend: "iptables"},
+			ExpectedError: `unknown backend "iptables", expected one of: firewalld, nftables`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := tc.Config.ParseAndValidate()
+			if tc.ExpectedError != "" {
+				assert.EqualError(t, err, tc.ExpectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
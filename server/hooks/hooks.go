@@ -0,0 +1,213 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+const (
+	EventClientConnected    = "client_connected"
+	EventClientDisconnected = "client_disconnected"
+	EventTunnelOpened       = "tunnel_opened"
+	EventTunnelClosed       = "tunnel_closed"
+	// EventClientAddressesChanged occurs when the ipv4 or ipv6 addresses reported by a client change.
+	EventClientAddressesChanged = "client_addresses_changed"
+
+	DefaultTimeout = 20 * time.Second
+)
+
+var validEvents = map[string]bool{
+	EventClientConnected:    true,
+	EventClientDisconnected: true,
+	EventTunnelOpened:       true,
+	EventTunnelClosed:       true,
+
+	EventClientAddressesChanged: true,
+}
+
+// Hook is a local executable run by the server when one of the given events occurs.
+type Hook struct {
+	Exec   string   `mapstructure:"exec"`
+	Events []string `mapstructure:"events"`
+}
+
+func (h *Hook) Validate() error {
+	if h.Exec == "" {
+		return errors.New("exec is required")
+	}
+	if len(h.Events) == 0 {
+		return errors.New("events cannot be empty")
+	}
+	for _, event := range h.Events {
+		if !validEvents[event] {
+			return fmt.Errorf("unknown event %q, expected one of: %s, %s, %s, %s, %s", event, EventClientConnected, EventClientDisconnected, EventTunnelOpened, EventTunnelClosed, EventClientAddressesChanged)
+		}
+	}
+	return nil
+}
+
+func (h *Hook) handles(event string) bool {
+	for _, e := range h.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func ValidateHooks(hooks []Hook) error {
+	for i := range hooks {
+		if err := hooks[i].Validate(); err != nil {
+			return fmt.Errorf("invalid hook %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+type Client struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Hostname     string `json:"hostname"`
+	Address      string `json:"address"`
+	ClientAuthID string `json:"client_auth_id"`
+}
+
+type Tunnel struct {
+	ID         string  `json:"id"`
+	Protocol   string  `json:"protocol"`
+	LocalHost  string  `json:"lhost"`
+	LocalPort  string  `json:"lport"`
+	RemoteHost string  `json:"rhost"`
+	RemotePort string  `json:"rport"`
+	Owner      string  `json:"owner"`
+	ACL        *string `json:"acl"`
+}
+
+// Addresses are the current and the changed addresses of a client.
+type Addresses struct {
+	IPv4    []string `json:"ipv4"`
+	IPv6    []string `json:"ipv6"`
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// Event is passed as json on stdin to the hook. The main fields are also set as RPORT_* environment variables.
+type Event struct {
+	Name      string     `json:"event"`
+	Timestamp time.Time  `json:"timestamp"`
+	Client    Client     `json:"client"`
+	Tunnel    *Tunnel    `json:"tunnel,omitempty"`
+	Addresses *Addresses `json:"addresses,omitempty"`
+}
+
+func (e *Event) env() []string {
+	env := []string{
+		"RPORT_EVENT=" + e.Name,
+		"RPORT_CLIENT_ID=" + e.Client.ID,
+		"RPORT_CLIENT_NAME=" + e.Client.Name,
+		"RPORT_CLIENT_HOSTNAME=" + e.Client.Hostname,
+		"RPORT_CLIENT_ADDRESS=" + e.Client.Address,
+		"RPORT_CLIENT_AUTH_ID=" + e.Client.ClientAuthID,
+	}
+	if e.Tunnel != nil {
+		acl := ""
+		if e.Tunnel.ACL != nil {
+			acl = *e.Tunnel.ACL
+		}
+		env = append(env,
+			"RPORT_TUNNEL_ID="+e.Tunnel.ID,
+			"RPORT_TUNNEL_PROTOCOL="+e.Tunnel.Protocol,
+			"RPORT_TUNNEL_LHOST="+e.Tunnel.LocalHost,
+			"RPORT_TUNNEL_LPORT="+e.Tunnel.LocalPort,
+			"RPORT_TUNNEL_RHOST="+e.Tunnel.RemoteHost,
+			"RPORT_TUNNEL_RPORT="+e.Tunnel.RemotePort,
+			"RPORT_TUNNEL_OWNER="+e.Tunnel.Owner,
+			"RPORT_TUNNEL_ACL="+acl,
+		)
+	}
+	if e.Addresses != nil {
+		env = append(env,
+			"RPORT_CLIENT_IPV4="+strings.Join(e.Addresses.IPv4, " "),
+			"RPORT_CLIENT_IPV6="+strings.Join(e.Addresses.IPv6, " "),
+			"RPORT_ADDRESSES_ADDED="+strings.Join(e.Addresses.Added, " "),
+			"RPORT_ADDRESSES_REMOVED="+strings.Join(e.Addresses.Removed, " "),
+		)
+	}
+	return env
+}
+
+type Runner struct {
+	hooks   []Hook
+	timeout time.Duration
+	logger  *logger.Logger
+}
+
+func NewRunner(hooks []Hook, timeout time.Duration, logger *logger.Logger) *Runner {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Runner{
+		hooks:   hooks,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+// Fire runs all hooks registered for the event in the background.
+func (r *Runner) Fire(e *Event) {
+	if r == nil {
+		return
+	}
+	for i := range r.hooks {
+		if r.hooks[i].handles(e.Name) {
+			go r.run(r.hooks[i].Exec, e)
+		}
+	}
+}
+
+func (r *Runner) run(execPath string, e *Event) {
+	err := r.Run(context.Background(), execPath, e)
+	if err != nil {
+		r.logger.Errorf("hook %s failed on %s of client %s: %v", execPath, e.Name, e.Client.ID, err)
+		return
+	}
+	r.logger.Debugf("hook %s executed on %s of client %s", execPath, e.Name, e.Client.ID)
+}
+
+// Run executes a single hook and waits until it is finished or the timeout is reached.
+func (r *Runner) Run(ctx context.Context, execPath string, e *Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, execPath)
+	cmd.Env = append(os.Environ(), e.env()...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timeout of %s exceeded", r.timeout)
+	}
+	if err != nil {
+		if stderr.Len() > 0 {
+			return fmt.Errorf("%v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+		}
+		return err
+	}
+	return nil
+}
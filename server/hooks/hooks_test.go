@@ -0,0 +1,110 @@
+//go:build !windows
+// +build !windows
+
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+var testLog = logger.NewLogger("hooks", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+
+func TestValidateHooks(t *testing.T) {
+	testCases := []struct {
+		name    string
+		hooks   []Hook
+		wantErr string
+	}{
+		{
+			name: "valid",
+			hooks: []Hook{
+				{Exec: "/usr/local/bin/hook.sh", Events: []string{EventClientConnected, EventTunnelClosed}},
+			},
+		},
+		{
+			name:    "missing exec",
+			hooks:   []Hook{{Events: []string{EventClientConnected}}},
+			wantErr: "invalid hook 1: exec is required",
+		},
+		{
+			name:    "missing events",
+			hooks:   []Hook{{Exec: "/usr/local/bin/hook.sh"}},
+			wantErr: "invalid hook 1: events cannot be empty",
+		},
+		{
+			name:    "unknown event",
+			hooks:   []Hook{{Exec: "/usr/local/bin/hook.sh", Events: []string{"client_updated"}}},
+			wantErr: `invalid hook 1: unknown event "client_updated", expected one of: client_connected, client_disconnected, tunnel_opened, tunnel_closed, client_addresses_changed`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateHooks(tc.hooks)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	script := filepath.Join(dir, "hook.sh")
+	err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$RPORT_EVENT $RPORT_CLIENT_ID $RPORT_TUNNEL_LPORT\" > "+out+"\ncat >> "+out+"\n"), 0700)
+	require.NoError(t, err)
+
+	e := &Event{
+		Name:      EventTunnelOpened,
+		Timestamp: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		Client:    Client{ID: "client-1", Name: "Client 1"},
+		Tunnel:    &Tunnel{ID: "1", LocalPort: "2222", RemotePort: "22"},
+	}
+	r := NewRunner(nil, time.Second, testLog)
+
+	err = r.Run(context.Background(), script, e)
+	require.NoError(t, err)
+
+	got, err := os.ReadFile(out)
+	require.NoError(t, err)
+	payload, err := json.Marshal(e)
+	require.NoError(t, err)
+	assert.Equal(t, "tunnel_opened client-1 2222\n"+string(payload), string(got))
+}
+
+func TestRunFailure(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "hook.sh")
+	err := os.WriteFile(script, []byte("#!/bin/sh\necho failed >&2\nexit 1\n"), 0700)
+	require.NoError(t, err)
+
+	r := NewRunner(nil, time.Second, testLog)
+
+	err = r.Run(context.Background(), script, &Event{Name: EventClientConnected})
+	assert.EqualError(t, err, "exit status 1: failed")
+}
+
+func TestRunTimeout(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "hook.sh")
+	err := os.WriteFile(script, []byte("#!/bin/sh\nexec sleep 5\n"), 0700)
+	require.NoError(t, err)
+
+	r := NewRunner(nil, 100*time.Millisecond, testLog)
+
+	err = r.Run(context.Background(), script, &Event{Name: EventClientConnected})
+	assert.EqualError(t, err, "timeout of 100ms exceeded")
+}
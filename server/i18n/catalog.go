@@ -0,0 +1,120 @@
+package i18n
+
+// catalog maps the locales to the translations of english messages and formats. Formats are translated using
+// the same verbs, use explicit argument indexes like %[2]s to change the order of the arguments.
+var catalog = map[string]map[string]string{
+	"de": {
+		// API errors
+		"unauthorized":                        "Nicht autorisiert",
+		"user not found":                      "Benutzer nicht gefunden",
+		"too many requests, please try later": "Zu viele Anfragen, bitte später erneut versuchen",
+		"current user should belong to %s group to access this resource": "Der aktuelle Benutzer muss zur Gruppe %s gehören, um auf diese Ressource zuzugreifen",
+		"user does not have %q permission":                               "Der Benutzer hat keine %q-Berechtigung",
+		"Missing body with json data.":                                   "Fehlender Body mit JSON-Daten.",
+		"Invalid JSON data.":                                             "Ungültige JSON-Daten.",
+		"Missing %q route param.":                                        "Fehlender Routenparameter %q.",
+		"Missing query parameter %q.":                                    "Fehlender Abfrageparameter %q.",
+		"client id is missing":                                           "Client-ID fehlt",
+		"Client with id=%q not found.":                                   "Client mit id=%q nicht gefunden.",
+		"Active client with id=%q not found.":                            "Aktiver Client mit id=%q nicht gefunden.",
+		"Client is not quarantined.":                                     "Der Client ist nicht in Quarantäne.",
+		"tunnel not found":                                               "Tunnel nicht gefunden",
+		"Command cannot be empty.":                                       "Der Befehl darf nicht leer sein.",
+		"Session recording is disabled.":                                 "Die Sitzungsaufzeichnung ist deaktiviert.",
+		"Packet capture is disabled.":                                    "Die Paketaufzeichnung ist deaktiviert.",
+		"Tunnel approval is not enabled.":                                "Die Tunnelfreigabe ist nicht aktiviert.",
+		"Another user with this username already exists":                 "Ein anderer Benutzer mit diesem Benutzernamen existiert bereits",
+		"username is required":                                           "Benutzername ist erforderlich",
+		"Missing old password.":                                          "Altes Passwort fehlt.",
+		"Incorrect old password.":                                        "Falsches altes Passwort.",
+		"Report with id %q not found.":                                   "Bericht mit ID %q nicht gefunden.",
+		"Snapshot %d of client %q not found.":                            "Snapshot %d von Client %q nicht gefunden.",
+		"Unsupported locale %q.":                                         "Nicht unterstützte Sprache %q.",
+
+		// notifications
+		"Rport client %s is back online": "Rport-Client %s ist wieder online",
+		"Client %s (%s) reconnected at %s.\nYou receive this message because %s watched the client since %s.": "Client %s (%s) hat sich um %s wieder verbunden.\nSie erhalten diese Nachricht, weil %s den Client seit %s beobachtet.",
+		"Rport tunnel approval required": "Rport-Tunnelfreigabe erforderlich",
+		"%s requested a tunnel to %s on client %s (%s).\nIt can be approved by members of %v until %s, pending tunnel id: %s": "%s hat einen Tunnel zu %s auf Client %s (%s) angefordert.\nEr kann bis %[6]s von Mitgliedern von %[5]v freigegeben werden, ID des wartenden Tunnels: %[7]s",
+		"RPort report: %s": "RPort-Bericht: %s",
+		"The report %q generated at %s is attached, it contains %d row(s).": "Der um %[2]s erstellte Bericht %[1]q ist angehängt, er enthält %[3]d Zeile(n).",
+		"Rport client %s disconnected":                                      "Rport-Client %s hat die Verbindung getrennt",
+		"Client %s (%s) disconnected at %s.":                                "Client %s (%s) hat die Verbindung um %s getrennt.",
+	},
+	"es": {
+		// API errors
+		"unauthorized":                        "No autorizado",
+		"user not found":                      "Usuario no encontrado",
+		"too many requests, please try later": "Demasiadas solicitudes, inténtelo más tarde",
+		"current user should belong to %s group to access this resource": "El usuario actual debe pertenecer al grupo %s para acceder a este recurso",
+		"user does not have %q permission":                               "El usuario no tiene el permiso %q",
+		"Missing body with json data.":                                   "Falta el cuerpo con datos JSON.",
+		"Invalid JSON data.":                                             "Datos JSON no válidos.",
+		"Missing %q route param.":                                        "Falta el parámetro de ruta %q.",
+		"Missing query parameter %q.":                                    "Falta el parámetro de consulta %q.",
+		"client id is missing":                                           "Falta el id del cliente",
+		"Client with id=%q not found.":                                   "No se encontró el cliente con id=%q.",
+		"Active client with id=%q not found.":                            "No se encontró el cliente activo con id=%q.",
+		"Client is not quarantined.":                                     "El cliente no está en cuarentena.",
+		"tunnel not found":                                               "Túnel no encontrado",
+		"Command cannot be empty.":                                       "El comando no puede estar vacío.",
+		"Session recording is disabled.":                                 "La grabación de sesiones está desactivada.",
+		"Packet capture is disabled.":                                    "La captura de paquetes está desactivada.",
+		"Tunnel approval is not enabled.":                                "La aprobación de túneles no está activada.",
+		"Another user with this username already exists":                 "Ya existe otro usuario con este nombre de usuario",
+		"username is required":                                           "El nombre de usuario es obligatorio",
+		"Missing old password.":                                          "Falta la contraseña anterior.",
+		"Incorrect old password.":                                        "La contraseña anterior es incorrecta.",
+		"Report with id %q not found.":                                   "No se encontró el informe con id %q.",
+		"Snapshot %d of client %q not found.":                            "No se encontró la instantánea %d del cliente %q.",
+		"Unsupported locale %q.":                                         "Idioma no soportado %q.",
+
+		// notifications
+		"Rport client %s is back online": "El cliente Rport %s vuelve a estar en línea",
+		"Client %s (%s) reconnected at %s.\nYou receive this message because %s watched the client since %s.": "El cliente %s (%s) se reconectó el %s.\nRecibe este mensaje porque %s vigila el cliente desde %s.",
+		"Rport tunnel approval required": "Se requiere aprobación de túnel en Rport",
+		"%s requested a tunnel to %s on client %s (%s).\nIt can be approved by members of %v until %s, pending tunnel id: %s": "%s solicitó un túnel a %s en el cliente %s (%s).\nPuede ser aprobado por miembros de %v hasta %s, id del túnel pendiente: %s",
+		"RPort report: %s": "Informe de RPort: %s",
+		"The report %q generated at %s is attached, it contains %d row(s).": "Se adjunta el informe %q generado el %s, contiene %d fila(s).",
+		"Rport client %s disconnected":                                      "El cliente Rport %s se desconectó",
+		"Client %s (%s) disconnected at %s.":                                "El cliente %s (%s) se desconectó el %s.",
+	},
+	"fr": {
+		// API errors
+		"unauthorized":                        "Non autorisé",
+		"user not found":                      "Utilisateur introuvable",
+		"too many requests, please try later": "Trop de requêtes, veuillez réessayer plus tard",
+		"current user should belong to %s group to access this resource": "L'utilisateur actuel doit appartenir au groupe %s pour accéder à cette ressource",
+		"user does not have %q permission":                               "L'utilisateur n'a pas la permission %q",
+		"Missing body with json data.":                                   "Corps avec données JSON manquant.",
+		"Invalid JSON data.":                                             "Données JSON invalides.",
+		"Missing %q route param.":                                        "Paramètre de route %q manquant.",
+		"Missing query parameter %q.":                                    "Paramètre de requête %q manquant.",
+		"client id is missing":                                           "L'id du client est manquant",
+		"Client with id=%q not found.":                                   "Client avec id=%q introuvable.",
+		"Active client with id=%q not found.":                            "Client actif avec id=%q introuvable.",
+		"Client is not quarantined.":                                     "Le client n'est pas en quarantaine.",
+		"tunnel not found":                                               "Tunnel introuvable",
+		"Command cannot be empty.":                                       "La commande ne peut pas être vide.",
+		"Session recording is disabled.":                                 "L'enregistrement des sessions est désactivé.",
+		"Packet capture is disabled.":                                    "La capture de paquets est désactivée.",
+		"Tunnel approval is not enabled.":                                "L'approbation des tunnels n'est pas activée.",
+		"Another user with this username already exists":                 "Un autre utilisateur avec ce nom d'utilisateur existe déjà",
+		"username is required":                                           "Le nom d'utilisateur est obligatoire",
+		"Missing old password.":                                          "Ancien mot de passe manquant.",
+		"Incorrect old password.":                                        "Ancien mot de passe incorrect.",
+		"Report with id %q not found.":                                   "Rapport avec id %q introuvable.",
+		"Snapshot %d of client %q not found.":                            "Instantané %d du client %q introuvable.",
+		"Unsupported locale %q.":                                         "Langue non prise en charge %q.",
+
+		// notifications
+		"Rport client %s is back online": "Le client Rport %s est de nouveau en ligne",
+		"Client %s (%s) reconnected at %s.\nYou receive this message because %s watched the client since %s.": "Le client %s (%s) s'est reconnecté le %s.\nVous recevez ce message car %s surveille le client depuis %s.",
+		"Rport tunnel approval required": "Approbation de tunnel Rport requise",
+		"%s requested a tunnel to %s on client %s (%s).\nIt can be approved by members of %v until %s, pending tunnel id: %s": "%s a demandé un tunnel vers %s sur le client %s (%s).\nIl peut être approuvé par les membres de %v jusqu'au %s, id du tunnel en attente : %s",
+		"RPort report: %s": "Rapport RPort : %s",
+		"The report %q generated at %s is attached, it contains %d row(s).": "Le rapport %q généré le %s est joint, il contient %d ligne(s).",
+		"Rport client %s disconnected":                                      "Le client Rport %s s'est déconnecté",
+		"Client %s (%s) disconnected at %s.":                                "Le client %s (%s) s'est déconnecté le %s.",
+	},
+}
@@ -0,0 +1,209 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale is the locale of the messages in the source code, used if no translation exists.
+const DefaultLocale = "en"
+
+var (
+	// verbRegexp matches fmt verbs including flags, width, precision and explicit argument indexes.
+	verbRegexp = regexp.MustCompile(`%(\[\d+\])?[-+# 0]*\d*(\.\d+)?[a-zA-Z%]`)
+
+	patterns     map[string][]pattern
+	patternsOnce sync.Once
+)
+
+// pattern matches a formatted english message to translate it afterwards.
+type pattern struct {
+	regexp      *regexp.Regexp
+	translation string
+}
+
+// Supported returns the supported locales.
+func Supported() []string {
+	locales := []string{DefaultLocale}
+	for locale := range catalog {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales[1:])
+	return locales
+}
+
+func IsSupported(locale string) bool {
+	if locale == DefaultLocale {
+		return true
+	}
+	_, ok := catalog[locale]
+	return ok
+}
+
+// Sprintf formats according to the translation of the format to the locale. The format itself is used if there is
+// no translation.
+func Sprintf(locale, format string, args ...interface{}) string {
+	if translation, ok := catalog[locale][format]; ok {
+		format = translation
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Translate translates an already formatted english message to the locale. Messages without translation are
+// returned unchanged.
+func Translate(locale, message string) string {
+	messages, ok := catalog[locale]
+	if !ok || message == "" {
+		return message
+	}
+	if translation, ok := messages[message]; ok && !verbRegexp.MatchString(message) {
+		return translation
+	}
+
+	patternsOnce.Do(compilePatterns)
+	for _, p := range patterns[locale] {
+		match := p.regexp.FindStringSubmatch(message)
+		if match == nil {
+			continue
+		}
+		args := make([]interface{}, 0, len(match)-1)
+		for _, arg := range match[1:] {
+			args = append(args, arg)
+		}
+		return fmt.Sprintf(p.translation, args...)
+	}
+	return message
+}
+
+func compilePatterns() {
+	patterns = make(map[string][]pattern, len(catalog))
+	for locale, messages := range catalog {
+		for format, translation := range messages {
+			if !verbRegexp.MatchString(strings.ReplaceAll(format, "%%", "")) {
+				continue
+			}
+			patterns[locale] = append(patterns[locale], pattern{
+				regexp:      formatToRegexp(format),
+				translation: verbsToStrings(translation),
+			})
+		}
+		// longer formats are more specific, try them first
+		sort.Slice(patterns[locale], func(i, j int) bool {
+			return len(patterns[locale][i].regexp.String()) > len(patterns[locale][j].regexp.String())
+		})
+	}
+}
+
+// formatToRegexp returns a regexp matching messages formatted with the format, each argument is captured.
+func formatToRegexp(format string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	last := 0
+	for _, loc := range verbRegexp.FindAllStringIndex(format, -1) {
+		b.WriteString(regexp.QuoteMeta(format[last:loc[0]]))
+		if format[loc[0]:loc[1]] == "%%" {
+			b.WriteString("%")
+		} else {
+			b.WriteString("(.*?)")
+		}
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(format[last:]))
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// verbsToStrings replaces all verbs by %s keeping explicit argument indexes, since captured arguments are strings.
+func verbsToStrings(format string) string {
+	return verbRegexp.ReplaceAllStringFunc(format, func(verb string) string {
+		if verb == "%%" {
+			return verb
+		}
+		if strings.HasPrefix(verb, "%[") {
+			return verb[:strings.Index(verb, "]")+1] + "s"
+		}
+		return "%s"
+	})
+}
+
+// Negotiate returns the supported locale preferred by the Accept-Language header value, the fallback if none is
+// supported.
+func Negotiate(acceptLanguage, fallback string) string {
+	type preference struct {
+		locale string
+		q      float64
+	}
+	var preferences []preference
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		primary, _, _ := strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-")
+		preferences = append(preferences, preference{locale: primary, q: parseQValue(params)})
+	}
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].q > preferences[j].q
+	})
+
+	for _, p := range preferences {
+		if p.q <= 0 {
+			continue
+		}
+		if p.locale == "*" {
+			return fallback
+		}
+		if IsSupported(p.locale) {
+			return p.locale
+		}
+	}
+	return fallback
+}
+
+func parseQValue(params string) float64 {
+	for _, param := range strings.Split(params, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || strings.TrimSpace(key) != "q" {
+			continue
+		}
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return 0
+		}
+		return q
+	}
+	return 1
+}
+
+type requestLocaleKey struct{}
+
+// requestLocale is shared between the middlewares handling a request, the locale is refined once the user is known.
+type requestLocale struct {
+	locale string
+}
+
+// WithRequestLocale returns a context holding the locale of the request.
+func WithRequestLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, requestLocaleKey{}, &requestLocale{locale: locale})
+}
+
+// SetRequestLocale changes the locale of the request, it has no effect on contexts without a request locale.
+func SetRequestLocale(ctx context.Context, locale string) {
+	if rl, ok := ctx.Value(requestLocaleKey{}).(*requestLocale); ok {
+		rl.locale = locale
+	}
+}
+
+// RequestLocale returns the locale of the request, DefaultLocale if not set.
+func RequestLocale(ctx context.Context) string {
+	if rl, ok := ctx.Value(requestLocaleKey{}).(*requestLocale); ok {
+		return rl.locale
+	}
+	return DefaultLocale
+}
@@ -0,0 +1,159 @@
+package i18n
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	userlocalesmigration "github.com/realvnc-labs/rport/db/migration/user_locales"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	apiErrors "github.com/realvnc-labs/rport/server/api/errors"
+)
+
+func TestSupported(t *testing.T) {
+	assert.Equal(t, []string{"en", "de", "es", "fr"}, Supported())
+	assert.True(t, IsSupported("en"))
+	assert.True(t, IsSupported("de"))
+	assert.False(t, IsSupported("pt"))
+}
+
+func TestCatalogFormats(t *testing.T) {
+	// translations must use the same verbs as the english format, otherwise Sprintf renders errors
+	for locale, messages := range catalog {
+		for format, translation := range messages {
+			assert.Equal(t, len(verbRegexp.FindAllString(format, -1)), len(verbRegexp.FindAllString(translation, -1)), "%s: %q", locale, format)
+		}
+	}
+}
+
+func TestSprintf(t *testing.T) {
+	assert.Equal(t, "Rport client web-1 is back online", Sprintf("en", "Rport client %s is back online", "web-1"))
+	assert.Equal(t, "Rport-Client web-1 ist wieder online", Sprintf("de", "Rport client %s is back online", "web-1"))
+	assert.Equal(t, "Rport client web-1 is back online", Sprintf("pt", "Rport client %s is back online", "web-1"))
+	assert.Equal(t, "no translation 1", Sprintf("de", "no translation %d", 1))
+	assert.Equal(
+		t,
+		`Der um Mon, 06 Mar 2023 10:00:00 UTC erstellte Bericht "Weekly" ist angehängt, er enthält 3 Zeile(n).`,
+		Sprintf("de", "The report %q generated at %s is attached, it contains %d row(s).", "Weekly", "Mon, 06 Mar 2023 10:00:00 UTC", 3),
+	)
+}
+
+func TestTranslate(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Locale   string
+		Message  string
+		Expected string
+	}{
+		{
+			Name:     "exact",
+			Locale:   "fr",
+			Message:  "unauthorized",
+			Expected: "Non autorisé",
+		}, {
+			Name:     "default locale",
+			Locale:   "en",
+			Message:  "unauthorized",
+			Expected: "unauthorized",
+		}, {
+			Name:     "unsupported locale",
+			Locale:   "pt",
+			Message:  "unauthorized",
+			Expected: "unauthorized",
+		}, {
+			Name:     "formatted",
+			Locale:   "de",
+			Message:  `Client with id="client-1" not found.`,
+			Expected: `Client mit id="client-1" nicht gefunden.`,
+		}, {
+			Name:     "longer format first",
+			Locale:   "es",
+			Message:  `Active client with id="client-1" not found.`,
+			Expected: `No se encontró el cliente activo con id="client-1".`,
+		}, {
+			Name:     "multiple args",
+			Locale:   "fr",
+			Message:  `Snapshot 12 of client "client-1" not found.`,
+			Expected: `Instantané 12 du client "client-1" introuvable.`,
+		}, {
+			Name:     "no translation",
+			Locale:   "de",
+			Message:  "something else went wrong",
+			Expected: "something else went wrong",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, Translate(tc.Locale, tc.Message))
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	testCases := []struct {
+		AcceptLanguage string
+		Expected       string
+	}{
+		{"", "en"},
+		{"de", "de"},
+		{"de-DE,de;q=0.9,en;q=0.8", "de"},
+		{"pt-BR,fr;q=0.5,es;q=0.7", "es"},
+		{"FR_ca", "fr"},
+		{"pt,*;q=0.1", "en"},
+		{"de;q=0", "en"},
+		{"pt", "en"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.Expected, Negotiate(tc.AcceptLanguage, "en"), tc.AcceptLanguage)
+	}
+}
+
+func TestRequestLocale(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, DefaultLocale, RequestLocale(ctx))
+	SetRequestLocale(ctx, "de")
+	assert.Equal(t, DefaultLocale, RequestLocale(ctx))
+
+	ctx = WithRequestLocale(ctx, "fr")
+	assert.Equal(t, "fr", RequestLocale(ctx))
+	SetRequestLocale(ctx, "de")
+	assert.Equal(t, "de", RequestLocale(ctx))
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlite.New(":memory:", userlocalesmigration.AssetNames(), userlocalesmigration.Asset, sqlite.DataSourceOptions{})
+	require.NoError(t, err)
+	s, err := NewService(ctx, db, "fr")
+	require.NoError(t, err)
+	defer s.Close()
+
+	assert.Equal(t, "", s.GetUserLocale("admin"))
+	assert.Equal(t, "fr", s.UserLocale(ctx, "admin"))
+	assert.Equal(t, "fr", s.UserLocale(ctx, ""))
+
+	require.NoError(t, s.SetUserLocale(ctx, "admin", "de"))
+	assert.Equal(t, "de", s.UserLocale(ctx, "admin"))
+	require.NoError(t, s.SetUserLocale(ctx, "admin", "es"))
+	assert.Equal(t, "es", s.GetUserLocale("admin"))
+
+	err = s.SetUserLocale(ctx, "admin", "pt")
+	assert.Equal(t, http.StatusBadRequest, err.(apiErrors.APIError).HTTPStatus)
+
+	// reloaded from the db
+	s2, err := NewService(ctx, db, "en")
+	require.NoError(t, err)
+	assert.Equal(t, "es", s2.GetUserLocale("admin"))
+
+	require.NoError(t, s.SetUserLocale(ctx, "admin", ""))
+	assert.Equal(t, "fr", s.UserLocale(ctx, "admin"))
+	s3, err := NewService(ctx, db, "en")
+	require.NoError(t, err)
+	assert.Equal(t, "", s3.GetUserLocale("admin"))
+}
@@ -0,0 +1,99 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+
+	apiErrors "github.com/realvnc-labs/rport/server/api/errors"
+)
+
+// Localizer returns the locale to use for messages to a user.
+type Localizer interface {
+	// UserLocale returns the locale selected by the user, the default locale for users without a selection or
+	// an empty username.
+	UserLocale(ctx context.Context, username string) string
+}
+
+// Service keeps the locales selected by the users. All selections are cached, there is one row per user at most.
+type Service struct {
+	db            *sqlx.DB
+	defaultLocale string
+
+	locales map[string]string
+	mu      sync.RWMutex
+}
+
+func NewService(ctx context.Context, db *sqlx.DB, defaultLocale string) (*Service, error) {
+	var rows []struct {
+		Username string `db:"username"`
+		Locale   string `db:"locale"`
+	}
+	err := db.SelectContext(ctx, &rows, "SELECT username, locale FROM user_locales")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load user locales: %w", err)
+	}
+
+	s := &Service{
+		db:            db,
+		defaultLocale: defaultLocale,
+		locales:       make(map[string]string, len(rows)),
+	}
+	for _, row := range rows {
+		s.locales[row.Username] = row.Locale
+	}
+	return s, nil
+}
+
+func (s *Service) DefaultLocale() string {
+	return s.defaultLocale
+}
+
+func (s *Service) UserLocale(ctx context.Context, username string) string {
+	if locale := s.GetUserLocale(username); locale != "" {
+		return locale
+	}
+	return s.defaultLocale
+}
+
+// GetUserLocale returns the locale selected by the user, empty if the user didn't select one.
+func (s *Service) GetUserLocale(username string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.locales[username]
+}
+
+// SetUserLocale stores the locale selected by the user, an empty locale resets the selection.
+func (s *Service) SetUserLocale(ctx context.Context, username, locale string) error {
+	if locale != "" && !IsSupported(locale) {
+		return apiErrors.NewAPIError(http.StatusBadRequest, "", fmt.Sprintf("Unsupported locale %q.", locale), nil)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	if locale == "" {
+		_, err = s.db.ExecContext(ctx, "DELETE FROM user_locales WHERE username = ?", username)
+	} else {
+		_, err = s.db.ExecContext(ctx, "INSERT OR REPLACE INTO user_locales (username, locale) VALUES (?, ?)", username, locale)
+	}
+	if err != nil {
+		return err
+	}
+
+	if locale == "" {
+		delete(s.locales, username)
+	} else {
+		s.locales[username] = locale
+	}
+	return nil
+}
+
+func (s *Service) Close() error {
+	return s.db.Close()
+}
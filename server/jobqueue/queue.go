@@ -0,0 +1,104 @@
+// Package jobqueue keeps jobs of unreachable clients until the clients reconnect.
+package jobqueue
+
+import (
+	"sync"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+const (
+	DefaultTTL = time.Hour
+	MaxTTL     = 24 * time.Hour
+)
+
+// RunFn runs the queued job on the reconnected client.
+type RunFn func(client *clientdata.Client)
+
+// ExpireFn is called if the client didn't reconnect within the ttl.
+type ExpireFn func()
+
+type entry struct {
+	run    RunFn
+	expire ExpireFn
+	timer  *time.Timer
+}
+
+// Queue keeps the jobs in memory, the multi-client jobs persist their dispatch state to queue them again after a
+// restart of the server.
+type Queue struct {
+	logger *logger.Logger
+
+	entries map[string][]*entry
+	mu      sync.Mutex
+}
+
+func New(logger *logger.Logger) *Queue {
+	return &Queue{
+		logger:  logger,
+		entries: make(map[string][]*entry),
+	}
+}
+
+// Add queues a job for the client. run is called once the client is connected again, expire if it doesn't reconnect
+// within the ttl.
+func (q *Queue) Add(clientID string, ttl time.Duration, run RunFn, expire ExpireFn) {
+	e := &entry{run: run, expire: expire}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	e.timer = time.AfterFunc(ttl, func() {
+		if q.remove(clientID, e) {
+			q.logger.Infof("client %s didn't reconnect within %s, queued job expired", clientID, ttl)
+			e.expire()
+		}
+	})
+	q.entries[clientID] = append(q.entries[clientID], e)
+}
+
+// Count returns the number of jobs queued for the client.
+func (q *Queue) Count(clientID string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.entries[clientID])
+}
+
+// ClientChanged runs the jobs queued for the client if it's connected. It's called after a client was saved or removed.
+func (q *Queue) ClientChanged(client *clientdata.Client, removed bool) {
+	if removed || !client.IsConnected() || client.GetConnection() == nil {
+		return
+	}
+	clientID := client.GetID()
+
+	q.mu.Lock()
+	entries := q.entries[clientID]
+	delete(q.entries, clientID)
+	q.mu.Unlock()
+
+	if len(entries) == 0 {
+		return
+	}
+	q.logger.Infof("client %s reconnected, running %d queued job(s)", clientID, len(entries))
+	for _, e := range entries {
+		e.timer.Stop()
+		go e.run(client)
+	}
+}
+
+func (q *Queue) remove(clientID string, e *entry) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entries := q.entries[clientID]
+	for i := range entries {
+		if entries[i] == e {
+			q.entries[clientID] = append(entries[:i:i], entries[i+1:]...)
+			if len(q.entries[clientID]) == 0 {
+				delete(q.entries, clientID)
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package jobqueue
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/test"
+)
+
+var testLog = logger.NewLogger("job-queue", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+
+func TestClientChanged(t *testing.T) {
+	q := New(testLog)
+	ran := make(chan *clientdata.Client, 2)
+	expired := make(chan bool, 2)
+	run := func(c *clientdata.Client) { ran <- c }
+	expire := func() { expired <- true }
+
+	q.Add("client-1", time.Hour, run, expire)
+	q.Add("client-1", time.Hour, run, expire)
+	q.Add("client-2", time.Hour, run, expire)
+	assert.Equal(t, 2, q.Count("client-1"))
+
+	disconnected := &clientdata.Client{ID: "client-1"}
+	disconnected.SetDisconnectedNow()
+	q.ClientChanged(disconnected, false)
+	assert.Equal(t, 2, q.Count("client-1"))
+
+	connected := &clientdata.Client{ID: "client-1"}
+	connected.SetConnection(test.NewConnMock())
+	q.ClientChanged(connected, true)
+	assert.Equal(t, 2, q.Count("client-1"), "removed clients don't run the jobs")
+
+	q.ClientChanged(connected, false)
+	assert.Equal(t, 0, q.Count("client-1"))
+	assert.Equal(t, 1, q.Count("client-2"))
+	for i := 0; i < 2; i++ {
+		select {
+		case c := <-ran:
+			assert.Equal(t, connected, c)
+		case <-time.After(time.Second):
+			require.Fail(t, "queued job didn't run")
+		}
+	}
+	assert.Empty(t, expired)
+}
+
+func TestExpire(t *testing.T) {
+	q := New(testLog)
+	expired := make(chan bool, 1)
+	q.Add("client-1", 10*time.Millisecond, func(c *clientdata.Client) {
+		assert.Fail(t, "expired job must not run")
+	}, func() {
+		expired <- true
+	})
+
+	select {
+	case <-expired:
+	case <-time.After(time.Second):
+		require.Fail(t, "queued job didn't expire")
+	}
+	assert.Equal(t, 0, q.Count("client-1"))
+
+	connected := &clientdata.Client{ID: "client-1"}
+	connected.SetConnection(test.NewConnMock())
+	q.ClientChanged(connected, false)
+}
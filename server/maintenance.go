@@ -0,0 +1,72 @@
+package chserver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/realvnc-labs/rport/server/api/jobs"
+	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/maintenance"
+)
+
+type maintenanceDBs struct {
+	jobs         *sqlx.DB
+	clientGroups *sqlx.DB
+	capacity     *sqlx.DB
+	monitoring   *sqlx.DB
+}
+
+func (s *Server) newMaintenanceService(jobsProvider *jobs.SqliteProvider, dbs maintenanceDBs) *maintenance.Service {
+	cfg := s.config.Server.Maintenance
+	m := maintenance.NewService(s.Logger.Fork("maintenance"), cfg)
+
+	if cfg.JobsMaxAge > 0 {
+		m.AddStep(maintenance.Step{
+			Name: "prune jobs",
+			Run: func(ctx context.Context) (string, error) {
+				deleted, err := jobsProvider.DeleteJobsBefore(ctx, time.Now().Add(-cfg.JobsMaxAge))
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("deleted %d jobs older than %s", deleted, cfg.JobsMaxAge), nil
+			},
+		})
+	}
+
+	if cfg.AuditLogMaxAge > 0 && s.config.API.AuditLog.Enable {
+		m.AddStep(maintenance.Step{
+			Name: "prune auditlog",
+			Run: func(ctx context.Context) (string, error) {
+				deleted, err := auditlog.DeleteRotatedBefore(s.config.Server.DataDir, time.Now().Add(-cfg.AuditLogMaxAge))
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("deleted %d rotated auditlog files older than %s", deleted, cfg.AuditLogMaxAge), nil
+			},
+		})
+	}
+
+	if s.config.Monitoring.Enabled {
+		m.AddStep(maintenance.Step{
+			Name: "prune measurements",
+			Run: func(ctx context.Context) (string, error) {
+				deleted, err := s.monitoringService.DeleteExpiredMeasurements(ctx)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("deleted %d measurements and rollups older than their retention", deleted), nil
+			},
+		})
+	}
+
+	m.AddDatabase("jobs", dbs.jobs)
+	m.AddDatabase("clients", s.clientDB)
+	m.AddDatabase("client_groups", dbs.clientGroups)
+	m.AddDatabase("capacity", dbs.capacity)
+	m.AddDatabase("monitoring", dbs.monitoring)
+
+	return m
+}
@@ -0,0 +1,88 @@
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	// QuietHours in the format "HH:MM-HH:MM" in server local time, empty disables scheduled runs
+	QuietHours     string        `mapstructure:"maintenance_quiet_hours"`
+	Vacuum         bool          `mapstructure:"maintenance_vacuum"`
+	JobsMaxAge     time.Duration `mapstructure:"maintenance_jobs_max_age"`
+	AuditLogMaxAge time.Duration `mapstructure:"maintenance_audit_log_max_age"`
+	// FreezeMessage is returned to changing requests while the API is frozen, if no message is given on freezing
+	FreezeMessage string `mapstructure:"maintenance_freeze_message"`
+}
+
+func (c *Config) Validate() error {
+	if c.QuietHours != "" {
+		if _, err := ParseQuietHours(c.QuietHours); err != nil {
+			return fmt.Errorf("maintenance_quiet_hours: %v", err)
+		}
+	}
+	if c.JobsMaxAge < 0 {
+		return errors.New("maintenance_jobs_max_age: must not be negative")
+	}
+	if c.AuditLogMaxAge < 0 {
+		return errors.New("maintenance_audit_log_max_age: must not be negative")
+	}
+	return nil
+}
+
+// QuietHours is a daily time window, the end is before the start if the window spans midnight.
+type QuietHours struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+func ParseQuietHours(s string) (*QuietHours, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid quiet hours %q, expected format HH:MM-HH:MM", s)
+	}
+	start, err := parseTimeOfDay(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseTimeOfDay(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	if start == end {
+		return nil, fmt.Errorf("invalid quiet hours %q, start and end must differ", s)
+	}
+	return &QuietHours{Start: start, End: end}, nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected format HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// WindowStart returns the start of the quiet hours window containing t, false if t is outside of the quiet hours.
+func (q *QuietHours) WindowStart(t time.Time) (time.Time, bool) {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	sinceMidnight := t.Sub(midnight)
+
+	if q.Start < q.End {
+		if sinceMidnight >= q.Start && sinceMidnight < q.End {
+			return midnight.Add(q.Start), true
+		}
+		return time.Time{}, false
+	}
+
+	// the window spans midnight
+	if sinceMidnight >= q.Start {
+		return midnight.Add(q.Start), true
+	}
+	if sinceMidnight < q.End {
+		return midnight.AddDate(0, 0, -1).Add(q.Start), true
+	}
+	return time.Time{}, false
+}
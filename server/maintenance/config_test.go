@@ -0,0 +1,111 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuietHours(t *testing.T) {
+	testCases := []struct {
+		Input       string
+		Expected    *QuietHours
+		ExpectedErr string
+	}{
+		{
+			Input:    "01:00-05:30",
+			Expected: &QuietHours{Start: time.Hour, End: 5*time.Hour + 30*time.Minute},
+		},
+		{
+			Input:    "22:00 - 04:00",
+			Expected: &QuietHours{Start: 22 * time.Hour, End: 4 * time.Hour},
+		},
+		{
+			Input:       "01:00",
+			ExpectedErr: `invalid quiet hours "01:00", expected format HH:MM-HH:MM`,
+		},
+		{
+			Input:       "01:00-25:00",
+			ExpectedErr: `invalid time "25:00", expected format HH:MM`,
+		},
+		{
+			Input:       "01:00-01:00",
+			ExpectedErr: `invalid quiet hours "01:00-01:00", start and end must differ`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Input, func(t *testing.T) {
+			q, err := ParseQuietHours(tc.Input)
+			if tc.ExpectedErr != "" {
+				assert.EqualError(t, err, tc.ExpectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.Expected, q)
+		})
+	}
+}
+
+func TestQuietHoursWindowStart(t *testing.T) {
+	day := func(d, h, m int) time.Time {
+		return time.Date(2022, 10, d, h, m, 0, 0, time.UTC)
+	}
+	testCases := []struct {
+		Name          string
+		QuietHours    string
+		Now           time.Time
+		ExpectedStart time.Time
+		ExpectedOK    bool
+	}{
+		{
+			Name:       "before window",
+			QuietHours: "01:00-05:00",
+			Now:        day(10, 0, 59),
+		},
+		{
+			Name:          "in window",
+			QuietHours:    "01:00-05:00",
+			Now:           day(10, 4, 59),
+			ExpectedStart: day(10, 1, 0),
+			ExpectedOK:    true,
+		},
+		{
+			Name:       "after window",
+			QuietHours: "01:00-05:00",
+			Now:        day(10, 5, 0),
+		},
+		{
+			Name:          "over midnight, before midnight",
+			QuietHours:    "23:00-02:00",
+			Now:           day(10, 23, 30),
+			ExpectedStart: day(10, 23, 0),
+			ExpectedOK:    true,
+		},
+		{
+			Name:          "over midnight, after midnight",
+			QuietHours:    "23:00-02:00",
+			Now:           day(11, 1, 30),
+			ExpectedStart: day(10, 23, 0),
+			ExpectedOK:    true,
+		},
+		{
+			Name:       "over midnight, outside",
+			QuietHours: "23:00-02:00",
+			Now:        day(11, 12, 0),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			q, err := ParseQuietHours(tc.QuietHours)
+			require.NoError(t, err)
+
+			start, ok := q.WindowStart(tc.Now)
+			assert.Equal(t, tc.ExpectedOK, ok)
+			assert.Equal(t, tc.ExpectedStart, start)
+		})
+	}
+}
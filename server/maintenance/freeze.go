@@ -0,0 +1,109 @@
+package maintenance
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	errors2 "github.com/realvnc-labs/rport/server/api/errors"
+)
+
+const DefaultFreezeMessage = "The API is in maintenance mode, changes are not possible at the moment. Please try again later."
+
+// FreezeInput puts the API into read-only mode. Routes limits the freeze to the given route prefixes, e.g. "/clients",
+// all routes are frozen if empty.
+type FreezeInput struct {
+	Message string   `json:"message"`
+	Routes  []string `json:"routes"`
+}
+
+type Freeze struct {
+	Enabled  bool       `json:"enabled"`
+	Message  string     `json:"message,omitempty"`
+	Routes   []string   `json:"routes"`
+	FrozenBy string     `json:"frozen_by,omitempty"`
+	FrozenAt *time.Time `json:"frozen_at,omitempty"`
+}
+
+func (i *FreezeInput) Validate() error {
+	for _, route := range i.Routes {
+		if !strings.HasPrefix(route, "/") {
+			return errors2.APIError{
+				Message:    fmt.Sprintf("invalid route %q, must start with /", route),
+				HTTPStatus: http.StatusBadRequest,
+			}
+		}
+	}
+	return nil
+}
+
+// Freeze puts the API into read-only mode until Unfreeze is called. Freezing again replaces the previous freeze.
+// The freeze is kept in memory only, the API is writable again after a restart.
+func (s *Service) Freeze(input FreezeInput, username string) (*Freeze, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	message := input.Message
+	if message == "" {
+		message = s.config.FreezeMessage
+	}
+	if message == "" {
+		message = DefaultFreezeMessage
+	}
+	routes := make([]string, 0, len(input.Routes))
+	for _, route := range input.Routes {
+		routes = append(routes, strings.TrimSuffix(route, "/"))
+	}
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.freeze = &Freeze{
+		Enabled:  true,
+		Message:  message,
+		Routes:   routes,
+		FrozenBy: username,
+		FrozenAt: &now,
+	}
+	return s.freeze, nil
+}
+
+func (s *Service) Unfreeze() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.freeze = nil
+}
+
+func (s *Service) FreezeStatus() *Freeze {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.freezeStatus()
+}
+
+func (s *Service) freezeStatus() *Freeze {
+	if s.freeze == nil {
+		return &Freeze{Routes: []string{}}
+	}
+	return s.freeze
+}
+
+// IsFrozen returns true and the message to show if changes to the given route are not allowed.
+// The route is the path of the request without the api prefix, e.g. "/clients/client-1/tags".
+func (s *Service) IsFrozen(route string) (bool, string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.freeze == nil {
+		return false, ""
+	}
+	if len(s.freeze.Routes) == 0 {
+		return true, s.freeze.Message
+	}
+	for _, prefix := range s.freeze.Routes {
+		if route == prefix || strings.HasPrefix(route, prefix+"/") {
+			return true, s.freeze.Message
+		}
+	}
+	return false, ""
+}
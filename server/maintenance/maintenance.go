@@ -0,0 +1,210 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+var ErrAlreadyRunning = errors.New("maintenance is already running")
+
+// Step is a single maintenance operation, it returns a short summary of what was done.
+type Step struct {
+	Name string
+	Run  func(ctx context.Context) (string, error)
+}
+
+type StepResult struct {
+	Name       string  `json:"name"`
+	Result     string  `json:"result,omitempty"`
+	Error      string  `json:"error,omitempty"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+type Report struct {
+	Trigger    string        `json:"trigger"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt time.Time     `json:"finished_at"`
+	Steps      []*StepResult `json:"steps"`
+}
+
+type Status struct {
+	QuietHours string  `json:"quiet_hours"`
+	Vacuum     bool    `json:"vacuum"`
+	Running    bool    `json:"running"`
+	LastRun    *Report `json:"last_run"`
+	Freeze     *Freeze `json:"freeze"`
+}
+
+// Service runs the prune steps followed by VACUUM and ANALYZE of the sqlite databases.
+type Service struct {
+	logger     *logger.Logger
+	config     Config
+	quietHours *QuietHours
+	steps      []Step
+	databases  map[string]*sqlx.DB
+	dbNames    []string
+	now        func() time.Time
+
+	runMu   sync.Mutex
+	mu      sync.RWMutex
+	running bool
+	lastRun *Report
+	freeze  *Freeze
+	// lastScheduledRun is the start of the last scheduled run, manual runs do not skip scheduled runs
+	lastScheduledRun time.Time
+}
+
+func NewService(logger *logger.Logger, config Config) *Service {
+	s := &Service{
+		logger:    logger,
+		config:    config,
+		databases: make(map[string]*sqlx.DB),
+		now:       time.Now,
+	}
+	// validated on config parsing
+	s.quietHours, _ = ParseQuietHours(config.QuietHours)
+	return s
+}
+
+// AddStep adds a prune step, steps run in the order they are added.
+func (s *Service) AddStep(step Step) {
+	s.steps = append(s.steps, step)
+}
+
+// AddDatabase adds a database to be vacuumed and analyzed after the prune steps.
+func (s *Service) AddDatabase(name string, db *sqlx.DB) {
+	if db == nil || db.DriverName() != "sqlite3" {
+		return
+	}
+	s.databases[name] = db
+	s.dbNames = append(s.dbNames, name)
+}
+
+func (s *Service) Status() *Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &Status{
+		QuietHours: s.config.QuietHours,
+		Vacuum:     s.config.Vacuum,
+		Running:    s.running,
+		LastRun:    s.lastRun,
+		Freeze:     s.freezeStatus(),
+	}
+}
+
+// Run implements the scheduler task, maintenance runs once per quiet hours window.
+func (s *Service) Run(ctx context.Context) error {
+	if s.quietHours == nil {
+		return nil
+	}
+	windowStart, ok := s.quietHours.WindowStart(s.now())
+	if !ok {
+		return nil
+	}
+	s.mu.RLock()
+	lastScheduledRun := s.lastScheduledRun
+	s.mu.RUnlock()
+	if !lastScheduledRun.Before(windowStart) {
+		return nil
+	}
+
+	report, err := s.RunNow(ctx, TriggerSchedule)
+	if err != nil {
+		return err
+	}
+	for _, step := range report.Steps {
+		if step.Error != "" {
+			s.logger.Errorf("maintenance step %q failed: %s", step.Name, step.Error)
+		}
+	}
+	return nil
+}
+
+// RunNow runs all maintenance steps, it returns ErrAlreadyRunning if a run is in progress.
+// A failing step does not stop the following steps.
+func (s *Service) RunNow(ctx context.Context, trigger string) (*Report, error) {
+	if !s.runMu.TryLock() {
+		return nil, ErrAlreadyRunning
+	}
+	defer s.runMu.Unlock()
+
+	s.setRunning(true)
+	defer s.setRunning(false)
+
+	s.logger.Infof("maintenance started, trigger: %s", trigger)
+	report := &Report{
+		Trigger:   trigger,
+		StartedAt: s.now(),
+		Steps:     []*StepResult{},
+	}
+
+	for _, step := range s.steps {
+		report.Steps = append(report.Steps, s.runStep(ctx, step))
+	}
+	if s.config.Vacuum {
+		for _, name := range s.dbNames {
+			report.Steps = append(report.Steps, s.runStep(ctx, vacuumStep(name, s.databases[name])))
+		}
+	}
+
+	report.FinishedAt = s.now()
+	s.logger.Infof("maintenance finished in %s", report.FinishedAt.Sub(report.StartedAt))
+
+	s.mu.Lock()
+	s.lastRun = report
+	if trigger == TriggerSchedule {
+		s.lastScheduledRun = report.StartedAt
+	}
+	s.mu.Unlock()
+
+	return report, nil
+}
+
+func (s *Service) runStep(ctx context.Context, step Step) *StepResult {
+	start := time.Now()
+	res := &StepResult{Name: step.Name}
+
+	result, err := step.Run(ctx)
+	if err != nil {
+		res.Error = err.Error()
+	} else {
+		res.Result = result
+	}
+	res.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+	s.logger.Debugf("maintenance step %q done: %s", step.Name, result)
+
+	return res
+}
+
+func (s *Service) setRunning(running bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = running
+}
+
+func vacuumStep(name string, db *sqlx.DB) Step {
+	return Step{
+		Name: fmt.Sprintf("vacuum %s", name),
+		Run: func(ctx context.Context) (string, error) {
+			if _, err := db.ExecContext(ctx, "VACUUM"); err != nil {
+				return "", err
+			}
+			if _, err := db.ExecContext(ctx, "ANALYZE"); err != nil {
+				return "", err
+			}
+			return "vacuumed and analyzed", nil
+		},
+	}
+}
@@ -0,0 +1,156 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+var testLog = logger.NewLogger("maintenance", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+
+func TestRunNow(t *testing.T) {
+	s := NewService(testLog, Config{})
+	var calls []string
+	s.AddStep(Step{
+		Name: "failing",
+		Run: func(ctx context.Context) (string, error) {
+			calls = append(calls, "failing")
+			return "", errors.New("test error")
+		},
+	})
+	s.AddStep(Step{
+		Name: "prune",
+		Run: func(ctx context.Context) (string, error) {
+			calls = append(calls, "prune")
+			return "deleted 2", nil
+		},
+	})
+
+	report, err := s.RunNow(context.Background(), TriggerManual)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"failing", "prune"}, calls)
+	assert.Equal(t, TriggerManual, report.Trigger)
+	require.Len(t, report.Steps, 2)
+	assert.Equal(t, "test error", report.Steps[0].Error)
+	assert.Equal(t, "deleted 2", report.Steps[1].Result)
+
+	status := s.Status()
+	assert.False(t, status.Running)
+	assert.Equal(t, report, status.LastRun)
+}
+
+func TestRunNowAlreadyRunning(t *testing.T) {
+	s := NewService(testLog, Config{})
+	started := make(chan struct{})
+	release := make(chan struct{})
+	s.AddStep(Step{
+		Name: "slow",
+		Run: func(ctx context.Context) (string, error) {
+			close(started)
+			<-release
+			return "", nil
+		},
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := s.RunNow(context.Background(), TriggerManual)
+		assert.NoError(t, err)
+	}()
+
+	<-started
+	assert.True(t, s.Status().Running)
+	_, err := s.RunNow(context.Background(), TriggerManual)
+	assert.Equal(t, ErrAlreadyRunning, err)
+
+	close(release)
+	<-done
+}
+
+func TestRunOncePerQuietHoursWindow(t *testing.T) {
+	s := NewService(testLog, Config{QuietHours: "23:00-02:00"})
+	runs := 0
+	s.AddStep(Step{
+		Name: "count",
+		Run: func(ctx context.Context) (string, error) {
+			runs++
+			return "", nil
+		},
+	})
+	ctx := context.Background()
+
+	now := time.Date(2022, 10, 10, 22, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	require.NoError(t, s.Run(ctx))
+	assert.Equal(t, 0, runs)
+
+	now = now.Add(90 * time.Minute)
+	require.NoError(t, s.Run(ctx))
+	assert.Equal(t, 1, runs)
+
+	// same window
+	now = now.Add(time.Hour)
+	require.NoError(t, s.Run(ctx))
+	assert.Equal(t, 1, runs)
+
+	// manual runs do not affect scheduled runs
+	_, err := s.RunNow(ctx, TriggerManual)
+	require.NoError(t, err)
+	assert.Equal(t, 2, runs)
+
+	// next window
+	now = now.Add(23 * time.Hour)
+	require.NoError(t, s.Run(ctx))
+	assert.Equal(t, 3, runs)
+}
+
+func TestFreeze(t *testing.T) {
+	s := NewService(testLog, Config{FreezeMessage: "migration"})
+	now := time.Date(2022, 10, 10, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	frozen, _ := s.IsFrozen("/clients")
+	assert.False(t, frozen)
+	assert.Equal(t, &Freeze{Routes: []string{}}, s.FreezeStatus())
+
+	freeze, err := s.Freeze(FreezeInput{}, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, &Freeze{Enabled: true, Message: "migration", Routes: []string{}, FrozenBy: "admin", FrozenAt: &now}, freeze)
+	frozen, message := s.IsFrozen("/clients")
+	assert.True(t, frozen)
+	assert.Equal(t, "migration", message)
+
+	_, err = s.Freeze(FreezeInput{Message: "moving clients", Routes: []string{"/clients/"}}, "admin")
+	require.NoError(t, err)
+	for route, want := range map[string]bool{
+		"/clients":          true,
+		"/clients/c1/tags":  true,
+		"/clients-auth":     false,
+		"/users/admin":      false,
+		"/client-groups/g1": false,
+	} {
+		frozen, message = s.IsFrozen(route)
+		assert.Equal(t, want, frozen, route)
+		if want {
+			assert.Equal(t, "moving clients", message)
+		}
+	}
+	assert.Equal(t, s.FreezeStatus(), s.Status().Freeze)
+
+	s.Unfreeze()
+	frozen, _ = s.IsFrozen("/clients")
+	assert.False(t, frozen)
+
+	_, err = s.Freeze(FreezeInput{Routes: []string{"clients"}}, "admin")
+	assert.EqualError(t, err, `invalid route "clients", must start with /`)
+}
@@ -0,0 +1,366 @@
+// Package metrics collects counters, gauges and histograms of the server and writes them in the Prometheus text
+// exposition format, so the server can be scraped without pulling in the Prometheus client library.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultDurationBuckets are the upper bounds in seconds used for durations, e.g. API request latencies.
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Default is the registry metrics are registered to by the package level constructors.
+var Default = NewRegistry()
+
+type metric interface {
+	name() string
+	write(w *bytes.Buffer)
+}
+
+// Registry holds metrics by name.
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]metric
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		metrics: make(map[string]metric),
+	}
+}
+
+// register adds the metric, a metric registered before with the same name is replaced. This way a component that is
+// created again, e.g. in tests, doesn't leave a stale gauge behind.
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics[m.name()] = m
+}
+
+// WriteTo writes all metrics sorted by name in the text exposition format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	metrics := make([]metric, 0, len(r.metrics))
+	for _, m := range r.metrics {
+		metrics = append(metrics, m)
+	}
+	r.mu.Unlock()
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].name() < metrics[j].name()
+	})
+
+	var buf bytes.Buffer
+	for _, m := range metrics {
+		m.write(&buf)
+	}
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+type desc struct {
+	metricName string
+	help       string
+	typ        string
+	labelNames []string
+}
+
+func (d *desc) name() string {
+	return d.metricName
+}
+
+func (d *desc) writeHeader(w *bytes.Buffer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.metricName, helpReplacer.Replace(d.help), d.metricName, d.typ)
+}
+
+func (d *desc) checkLabelValues(labelValues []string) {
+	if len(labelValues) != len(d.labelNames) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", d.metricName, len(d.labelNames), len(labelValues)))
+	}
+}
+
+// Counter is a monotonically increasing value, partitioned by the values of its labels.
+type Counter struct {
+	desc
+	mu     sync.Mutex
+	series map[string]*counterSeries
+}
+
+type counterSeries struct {
+	labelValues []string
+	value       float64
+}
+
+// NewCounter creates a counter registered to the Default registry.
+func NewCounter(name, help string, labelNames ...string) *Counter {
+	return Default.NewCounter(name, help, labelNames...)
+}
+
+// NewCounter creates a counter registered to the registry.
+func (r *Registry) NewCounter(name, help string, labelNames ...string) *Counter {
+	c := &Counter{
+		desc:   desc{metricName: name, help: help, typ: "counter", labelNames: labelNames},
+		series: make(map[string]*counterSeries),
+	}
+	r.register(c)
+	return c
+}
+
+// Inc increments the counter of the given label values by one.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increments the counter of the given label values by v, negative values are ignored.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	c.checkLabelValues(labelValues)
+	if v < 0 {
+		return
+	}
+	key := seriesKey(labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.series[key]
+	if s == nil {
+		s = &counterSeries{labelValues: append([]string(nil), labelValues...)}
+		c.series[key] = s
+	}
+	s.value += v
+}
+
+// Value returns the current value of the counter of the given label values.
+func (c *Counter) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s := c.series[seriesKey(labelValues)]; s != nil {
+		return s.value
+	}
+	return 0
+}
+
+func (c *Counter) write(w *bytes.Buffer) {
+	c.writeHeader(w)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.labelNames) == 0 && len(c.series) == 0 {
+		// a counter without labels is known to be 0 before the first increment
+		writeSample(w, c.metricName, "", nil, nil, "", "", 0)
+		return
+	}
+	for _, key := range sortedKeys(c.series) {
+		s := c.series[key]
+		writeSample(w, c.metricName, "", c.labelNames, s.labelValues, "", "", s.value)
+	}
+}
+
+// GaugeFunc is a gauge whose value is calculated when the metrics are written, e.g. the number of connected clients.
+type GaugeFunc struct {
+	desc
+	fn func() float64
+}
+
+// NewGaugeFunc creates a gauge registered to the Default registry.
+func NewGaugeFunc(name, help string, fn func() float64) *GaugeFunc {
+	return Default.NewGaugeFunc(name, help, fn)
+}
+
+// NewGaugeFunc creates a gauge registered to the registry.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) *GaugeFunc {
+	g := &GaugeFunc{
+		desc: desc{metricName: name, help: help, typ: "gauge"},
+		fn:   fn,
+	}
+	r.register(g)
+	return g
+}
+
+func (g *GaugeFunc) write(w *bytes.Buffer) {
+	g.writeHeader(w)
+	writeSample(w, g.metricName, "", nil, nil, "", "", g.fn())
+}
+
+// GaugeValue is the value of a gauge for the given label values.
+type GaugeValue struct {
+	LabelValues []string
+	Value       float64
+}
+
+// GaugeVecFunc is a gauge partitioned by the values of its labels, whose values are calculated when the metrics are
+// written, e.g. the connections of each database.
+type GaugeVecFunc struct {
+	desc
+	fn func() []GaugeValue
+}
+
+// NewGaugeVecFunc creates a gauge with labels registered to the Default registry.
+func NewGaugeVecFunc(name, help string, labelNames []string, fn func() []GaugeValue) *GaugeVecFunc {
+	return Default.NewGaugeVecFunc(name, help, labelNames, fn)
+}
+
+// NewGaugeVecFunc creates a gauge with labels registered to the registry.
+func (r *Registry) NewGaugeVecFunc(name, help string, labelNames []string, fn func() []GaugeValue) *GaugeVecFunc {
+	g := &GaugeVecFunc{
+		desc: desc{metricName: name, help: help, typ: "gauge", labelNames: labelNames},
+		fn:   fn,
+	}
+	r.register(g)
+	return g
+}
+
+func (g *GaugeVecFunc) write(w *bytes.Buffer) {
+	g.writeHeader(w)
+	values := g.fn()
+	sort.Slice(values, func(i, j int) bool {
+		return seriesKey(values[i].LabelValues) < seriesKey(values[j].LabelValues)
+	})
+	for _, v := range values {
+		g.checkLabelValues(v.LabelValues)
+		writeSample(w, g.metricName, "", g.labelNames, v.LabelValues, "", "", v.Value)
+	}
+}
+
+// Histogram counts observations in buckets, partitioned by the values of its labels.
+type Histogram struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64
+	count       uint64
+	sum         float64
+}
+
+// NewHistogram creates a histogram with the given upper bounds of the buckets registered to the Default registry.
+func NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	return Default.NewHistogram(name, help, buckets, labelNames...)
+}
+
+// NewHistogram creates a histogram with the given upper bounds of the buckets registered to the registry.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	h := &Histogram{
+		desc:    desc{metricName: name, help: help, typ: "histogram", labelNames: labelNames},
+		buckets: sorted,
+		series:  make(map[string]*histogramSeries),
+	}
+	r.register(h)
+	return h
+}
+
+// Observe adds the value to the histogram of the given label values.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.checkLabelValues(labelValues)
+	key := seriesKey(labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.series[key]
+	if s == nil {
+		s = &histogramSeries{
+			labelValues: append([]string(nil), labelValues...),
+			counts:      make([]uint64, len(h.buckets)),
+		}
+		h.series[key] = s
+	}
+	for i, upper := range h.buckets {
+		if v <= upper {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += v
+}
+
+// Count returns the number of observations of the given label values.
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s := h.series[seriesKey(labelValues)]; s != nil {
+		return s.count
+	}
+	return 0
+}
+
+func (h *Histogram) write(w *bytes.Buffer) {
+	h.writeHeader(w)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		for i, upper := range h.buckets {
+			writeSample(w, h.metricName, "_bucket", h.labelNames, s.labelValues, "le", formatFloat(upper), float64(s.counts[i]))
+		}
+		writeSample(w, h.metricName, "_bucket", h.labelNames, s.labelValues, "le", "+Inf", float64(s.count))
+		writeSample(w, h.metricName, "_sum", h.labelNames, s.labelValues, "", "", s.sum)
+		writeSample(w, h.metricName, "_count", h.labelNames, s.labelValues, "", "", float64(s.count))
+	}
+}
+
+func writeSample(w *bytes.Buffer, name, suffix string, labelNames, labelValues []string, extraName, extraValue string, value float64) {
+	w.WriteString(name)
+	w.WriteString(suffix)
+	if len(labelNames) > 0 || extraName != "" {
+		w.WriteByte('{')
+		for i, labelName := range labelNames {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, labelName, labelValueReplacer.Replace(labelValues[i]))
+		}
+		if extraName != "" {
+			if len(labelNames) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, extraName, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpReplacer       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func seriesKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryWriteTo(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounter("test_requests_total", "Requests.\nBy method.", "method")
+	latency := r.NewHistogram("test_duration_seconds", "Durations.", []float64{1, 0.1}, "route")
+	r.NewGaugeFunc("test_connected", "Connected.", func() float64 { return 3 })
+	r.NewCounter("test_errors_total", "Errors.")
+	r.NewGaugeVecFunc("test_open", "Open.", []string{"db"}, func() []GaugeValue {
+		return []GaugeValue{{LabelValues: []string{"b"}, Value: 2}, {LabelValues: []string{"a"}, Value: 1}}
+	})
+
+	requests.Inc("GET")
+	requests.Add(2, `P"O\ST`)
+	requests.Add(-1, "GET")
+	latency.Observe(0.05, "/a")
+	latency.Observe(0.5, "/a")
+	latency.Observe(5, "/a")
+
+	var buf bytes.Buffer
+	n, err := r.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+	assert.Equal(t, `# HELP test_connected Connected.
+# TYPE test_connected gauge
+test_connected 3
+# HELP test_duration_seconds Durations.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{route="/a",le="0.1"} 1
+test_duration_seconds_bucket{route="/a",le="1"} 2
+test_duration_seconds_bucket{route="/a",le="+Inf"} 3
+test_duration_seconds_sum{route="/a"} 5.55
+test_duration_seconds_count{route="/a"} 3
+# HELP test_errors_total Errors.
+# TYPE test_errors_total counter
+test_errors_total 0
+# HELP test_open Open.
+# TYPE test_open gauge
+test_open{db="a"} 1
+test_open{db="b"} 2
+# HELP test_requests_total Requests.\nBy method.
+# TYPE test_requests_total counter
+test_requests_total{method="GET"} 1
+test_requests_total{method="P\"O\\ST"} 2
+`, buf.String())
+
+	assert.Equal(t, float64(1), requests.Value("GET"))
+	assert.Equal(t, uint64(3), latency.Count("/a"))
+	assert.Equal(t, uint64(0), latency.Count("/b"))
+}
+
+func TestRegistryReplacesMetricWithSameName(t *testing.T) {
+	r := NewRegistry()
+	r.NewGaugeFunc("test_gauge", "Old.", func() float64 { return 1 })
+	r.NewGaugeFunc("test_gauge", "New.", func() float64 { return 2 })
+
+	var buf bytes.Buffer
+	_, err := r.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, "# HELP test_gauge New.\n# TYPE test_gauge gauge\ntest_gauge 2\n", buf.String())
+}
+
+func TestCounterPanicsOnWrongLabelValues(t *testing.T) {
+	c := NewRegistry().NewCounter("test_total", "Test.", "a", "b")
+
+	assert.Panics(t, func() { c.Inc("x") })
+}
@@ -3,28 +3,25 @@ package monitoring
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/realvnc-labs/rport/share/logger"
 )
 
 type CleanupTask struct {
-	log      *logger.Logger
-	service  Service
-	duration time.Duration
+	log     *logger.Logger
+	service Service
 }
 
-// NewCleanupTask returns a task to cleanup monitoring data after configured period
-func NewCleanupTask(log *logger.Logger, service Service, duration time.Duration) *CleanupTask {
+// NewCleanupTask returns a task to cleanup monitoring data after the retention period of its tier
+func NewCleanupTask(log *logger.Logger, service Service) *CleanupTask {
 	return &CleanupTask{
-		log:      log,
-		service:  service,
-		duration: duration,
+		log:     log,
+		service: service,
 	}
 }
 
 func (t *CleanupTask) Run(ctx context.Context) error {
-	deletedRecords, err := t.service.DeleteMeasurementsOlderThan(ctx, t.duration)
+	deletedRecords, err := t.service.DeleteExpiredMeasurements(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to cleanup measurements: %v", err)
 	}
@@ -16,6 +16,10 @@ type DBProviderMock struct {
 	MetricsListPayload           []*ClientMetricsPayload
 	ProcessesListPayload         []*ClientProcessesPayload
 	MountpointsListPayload       []*ClientMountpointsPayload
+	CustomMetricsListPayload     []*ClientCustomMetricsPayload
+	CustomGraphListPayload       []*ClientCustomGraphPayload
+	CustomMeasurements           []*models.CustomMeasurement
+	GraphResolution              time.Duration
 }
 
 func (p *DBProviderMock) CountByClientID(ctx context.Context, clientID string, fo *query.ListOptions) (int, error) {
@@ -30,7 +34,8 @@ func (p *DBProviderMock) ListMountpointsByClientID(ctx context.Context, clientID
 	return p.MountpointsListPayload, nil
 }
 
-func (p *DBProviderMock) ListGraphByClientID(context.Context, string, float64, *query.ListOptions, string) ([]*ClientGraphMetricsGraphPayload, error) {
+func (p *DBProviderMock) ListGraphByClientID(ctx context.Context, clientID string, resolution time.Duration, hours float64, o *query.ListOptions, graph string) ([]*ClientGraphMetricsGraphPayload, error) {
+	p.GraphResolution = resolution
 	return p.GraphMetricsGraphListPayload, nil
 }
 
@@ -38,7 +43,8 @@ func (p *DBProviderMock) ListMetricsByClientID(ctx context.Context, clientID str
 	return p.MetricsListPayload, nil
 }
 
-func (p *DBProviderMock) ListGraphMetricsByClientID(ctx context.Context, clientID string, hours float64, o *query.ListOptions) ([]*ClientGraphMetricsPayload, error) {
+func (p *DBProviderMock) ListGraphMetricsByClientID(ctx context.Context, clientID string, resolution time.Duration, hours float64, o *query.ListOptions) ([]*ClientGraphMetricsPayload, error) {
+	p.GraphResolution = resolution
 	return p.GraphMetricsListPayload, nil
 }
 
@@ -50,6 +56,36 @@ func (p *DBProviderMock) DeleteMeasurementsBefore(ctx context.Context, compare t
 	return 0, nil
 }
 
+func (p *DBProviderMock) CreateCustomMeasurements(ctx context.Context, measurements []*models.CustomMeasurement) error {
+	p.CustomMeasurements = append(p.CustomMeasurements, measurements...)
+	return nil
+}
+
+func (p *DBProviderMock) ListCustomMetricsByClientID(ctx context.Context, clientID string, o *query.ListOptions) ([]*ClientCustomMetricsPayload, error) {
+	return p.CustomMetricsListPayload, nil
+}
+
+func (p *DBProviderMock) CountCustomMetricsByClientID(ctx context.Context, clientID string, o *query.ListOptions) (int, error) {
+	return len(p.CustomMetricsListPayload), nil
+}
+
+func (p *DBProviderMock) ListCustomGraphByClientID(ctx context.Context, clientID string, name string, resolution time.Duration, hours float64, o *query.ListOptions) ([]*ClientCustomGraphPayload, error) {
+	p.GraphResolution = resolution
+	return p.CustomGraphListPayload, nil
+}
+
+func (p *DBProviderMock) ListLatestCustomMetricsByClientID(ctx context.Context, clientID string, since time.Time) ([]*models.CustomMeasurement, error) {
+	return p.CustomMeasurements, nil
+}
+
+func (p *DBProviderMock) RollupMeasurements(ctx context.Context, resolution time.Duration, until time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (p *DBProviderMock) DeleteRollupsBefore(ctx context.Context, resolution time.Duration, compare time.Time) (int64, error) {
+	return 0, nil
+}
+
 func (p *DBProviderMock) Close() error {
 	return nil
 }
@@ -126,6 +126,19 @@ type ClientMountpointsPayload struct {
 	Mountpoints types.JSONString `json:"mountpoints" db:"mountpoints"`
 }
 
+type ClientCustomMetricsPayload struct {
+	Timestamp time.Time `json:"timestamp,omitempty" db:"timestamp"`
+	Name      string    `json:"name,omitempty" db:"name"`
+	Value     *float64  `json:"value,omitempty" db:"value"`
+}
+
+type ClientCustomGraphPayload struct {
+	Timestamp time.Time `json:"timestamp,omitempty" db:"timestamp"`
+	Avg       float64   `json:"avg" db:"value_avg"`
+	Min       float64   `json:"min" db:"value_min"`
+	Max       float64   `json:"max" db:"value_max"`
+}
+
 type GraphMetricsLinksPayload struct {
 	CPUUsagePercent    *string `json:"cpu_usage_percent,omitempty"`
 	MemUsagePercent    *string `json:"mem_usage_percent,omitempty"`
@@ -158,6 +171,31 @@ var ClientMountpointsSortFields = map[string]bool{
 }
 
 var ClientMetricsFilterFields = map[string]bool{
+	"timestamp[gt]":             true,
+	"timestamp[lt]":             true,
+	"timestamp[since]":          true,
+	"timestamp[until]":          true,
+	"cpu_usage_percent[gt]":     true,
+	"cpu_usage_percent[lt]":     true,
+	"cpu_usage_percent[gte]":    true,
+	"cpu_usage_percent[lte]":    true,
+	"memory_usage_percent[gt]":  true,
+	"memory_usage_percent[lt]":  true,
+	"memory_usage_percent[gte]": true,
+	"memory_usage_percent[lte]": true,
+	"io_usage_percent[gt]":      true,
+	"io_usage_percent[lt]":      true,
+	"io_usage_percent[gte]":     true,
+	"io_usage_percent[lte]":     true,
+}
+
+var ClientCustomMetricsSortFields = map[string]bool{
+	"timestamp": true,
+	"name":      true,
+}
+
+var ClientCustomMetricsFilterFields = map[string]bool{
+	"name":             true,
 	"timestamp[gt]":    true,
 	"timestamp[lt]":    true,
 	"timestamp[since]": true,
@@ -202,6 +240,14 @@ var ClientMetricsFields = map[string]map[string]bool{
 	},
 }
 
+var ClientCustomMetricsFields = map[string]map[string]bool{
+	"custom_metrics": {
+		"timestamp": true,
+		"name":      true,
+		"value":     true,
+	},
+}
+
 var ClientProcessesFields = map[string]map[string]bool{
 	"processes": {
 		"timestamp": true,
@@ -224,6 +270,10 @@ var ClientMetricsSortDefault = map[string][]string{"sort": {"-timestamp"}}
 var ClientMetricsFilterDefault = map[string][]string{}
 var ClientMetricsFieldsDefault = map[string][]string{"fields[metrics]": {"timestamp", "cpu_usage_percent", "memory_usage_percent", "io_usage_percent"}}
 
+var ClientCustomMetricsSortDefault = map[string][]string{"sort": {"-timestamp"}}
+var ClientCustomMetricsFilterDefault = map[string][]string{}
+var ClientCustomMetricsFieldsDefault = map[string][]string{"fields[custom_metrics]": {"timestamp", "name", "value"}}
+
 var ClientProcessesSortDefault = map[string][]string{"sort": {"-timestamp"}}
 var ClientProcessesFilterDefault = map[string][]string{}
 var ClientProcessesFieldsDefault = map[string][]string{"fields[processes]": {"timestamp", "processes"}}
@@ -0,0 +1,30 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+type RollupTask struct {
+	log     *logger.Logger
+	service Service
+}
+
+// NewRollupTask returns a task to aggregate raw measurements into the rollup tiers
+func NewRollupTask(log *logger.Logger, service Service) *RollupTask {
+	return &RollupTask{
+		log:     log,
+		service: service,
+	}
+}
+
+func (t *RollupTask) Run(ctx context.Context) error {
+	rolledUp, err := t.service.RollupMeasurements(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to rollup measurements: %v", err)
+	}
+	t.log.Debugf("monitoring.RollupTask: %d rollup records written", rolledUp)
+	return nil
+}
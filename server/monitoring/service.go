@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -16,12 +17,17 @@ import (
 
 type Service interface {
 	SaveMeasurement(ctx context.Context, measurement *models.Measurement) error
-	DeleteMeasurementsOlderThan(ctx context.Context, period time.Duration) (int64, error)
+	DeleteExpiredMeasurements(ctx context.Context) (int64, error)
+	RollupMeasurements(ctx context.Context) (int64, error)
 	ListClientMetrics(context.Context, string, *query.ListOptions) (*api.SuccessPayload, error)
 	ListClientGraph(context.Context, string, *query.ListOptions, string, *models.NetworkCard, *models.NetworkCard) (*api.SuccessPayload, error)
 	ListClientGraphMetrics(context.Context, string, *query.ListOptions, *query.RequestInfo, bool, bool) (*api.SuccessPayload, error)
 	ListClientMountpoints(context.Context, string, *query.ListOptions) (*api.SuccessPayload, error)
 	ListClientProcesses(context.Context, string, *query.ListOptions) (*api.SuccessPayload, error)
+	SaveCustomMetrics(ctx context.Context, clientID string, metrics map[string]float64) error
+	ListClientCustomMetrics(context.Context, string, *query.ListOptions) (*api.SuccessPayload, error)
+	ListClientCustomGraph(context.Context, string, string, *query.ListOptions) (*api.SuccessPayload, error)
+	GetLatestCustomMetrics(ctx context.Context, clientID string, maxAge time.Duration) (map[string]float64, error)
 }
 
 const layoutAPI = time.RFC3339
@@ -37,13 +43,26 @@ const minDownsamplingDuration = time.Duration(minDownsamplingHours) * time.Hour
 const maxDownsamplingHours = 48
 const maxDownsamplingDuration = time.Duration(maxDownsamplingHours) * time.Hour
 const oneMBitBytes = 125000.0 // for converting MBits to Bytes
+const defaultLimitCustomMetrics = 100
+const maxLimitCustomMetrics = 1000
+const rollupDelay = time.Minute // wait for measurements still being saved before rolling up a bucket
+const MaxCustomMetricsPerPush = 50
+const maxCustomMetricNameLength = 100
+
+var customMetricNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_.\-]+$`)
 
 type monitoringService struct {
 	DBProvider DBProvider
+	tiers      Tiers
 }
 
-func NewService(dbProvider DBProvider) Service {
-	return &monitoringService{DBProvider: dbProvider}
+// NewService returns a monitoring service keeping measurements in the given tiers.
+// Without tiers measurements are not rolled up and graphs are read from raw measurements.
+func NewService(dbProvider DBProvider, tiers Tiers) Service {
+	return &monitoringService{
+		DBProvider: dbProvider,
+		tiers:      tiers,
+	}
 }
 
 func (s *monitoringService) SaveMeasurement(ctx context.Context, measurement *models.Measurement) error {
@@ -51,18 +70,102 @@ func (s *monitoringService) SaveMeasurement(ctx context.Context, measurement *mo
 	return s.DBProvider.CreateMeasurement(ctx, measurement)
 }
 
-func (s *monitoringService) DeleteMeasurementsOlderThan(ctx context.Context, period time.Duration) (int64, error) {
-	compare := time.Now().Add(-period)
-	return s.DBProvider.DeleteMeasurementsBefore(ctx, compare)
+func (s *monitoringService) SaveCustomMetrics(ctx context.Context, clientID string, metrics map[string]float64) error {
+	if err := ValidateCustomMetrics(metrics); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	measurements := make([]*models.CustomMeasurement, 0, len(metrics))
+	for name, value := range metrics {
+		measurements = append(measurements, &models.CustomMeasurement{
+			ClientID:  clientID,
+			Timestamp: now,
+			Name:      name,
+			Value:     value,
+		})
+	}
+	return s.DBProvider.CreateCustomMeasurements(ctx, measurements)
+}
+
+// ValidateCustomMetrics checks names and number of pushed custom metrics.
+func ValidateCustomMetrics(metrics map[string]float64) error {
+	if len(metrics) == 0 {
+		return errors.APIError{Message: "metrics cannot be empty", HTTPStatus: http.StatusBadRequest}
+	}
+	if len(metrics) > MaxCustomMetricsPerPush {
+		return errors.APIError{
+			Message:    fmt.Sprintf("too many metrics, max %d metrics can be pushed at once", MaxCustomMetricsPerPush),
+			HTTPStatus: http.StatusBadRequest,
+		}
+	}
+	for name := range metrics {
+		if len(name) > maxCustomMetricNameLength || !customMetricNameRegex.MatchString(name) {
+			return errors.APIError{
+				Message: fmt.Sprintf("invalid metric name %q: only letters, digits, '_', '.' and '-' are allowed, max %d characters",
+					name, maxCustomMetricNameLength),
+				HTTPStatus: http.StatusBadRequest,
+			}
+		}
+	}
+	return nil
+}
+
+// GetLatestCustomMetrics returns the latest value of each custom metric of the client not older than maxAge.
+func (s *monitoringService) GetLatestCustomMetrics(ctx context.Context, clientID string, maxAge time.Duration) (map[string]float64, error) {
+	measurements, err := s.DBProvider.ListLatestCustomMetricsByClientID(ctx, clientID, time.Now().UTC().Add(-maxAge))
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]float64, len(measurements))
+	for _, m := range measurements {
+		res[m.Name] = m.Value
+	}
+	return res, nil
+}
+
+// DeleteExpiredMeasurements deletes the measurements and rollups older than the retention of their tier.
+func (s *monitoringService) DeleteExpiredMeasurements(ctx context.Context) (int64, error) {
+	now := time.Now()
+	var deleted int64
+	for _, tier := range s.tiers {
+		var n int64
+		var err error
+		if tier.IsRaw() {
+			n, err = s.DBProvider.DeleteMeasurementsBefore(ctx, now.Add(-tier.Retention))
+		} else {
+			n, err = s.DBProvider.DeleteRollupsBefore(ctx, tier.Resolution, now.Add(-tier.Retention))
+		}
+		if err != nil {
+			return deleted, fmt.Errorf("tier %s: %w", tier.Name, err)
+		}
+		deleted += n
+	}
+	return deleted, nil
+}
+
+// RollupMeasurements aggregates the raw measurements of all completed buckets into the rollup tiers.
+func (s *monitoringService) RollupMeasurements(ctx context.Context) (int64, error) {
+	until := time.Now().UTC().Add(-rollupDelay)
+	var rolledUp int64
+	for _, tier := range s.tiers.Rollups() {
+		n, err := s.DBProvider.RollupMeasurements(ctx, tier.Resolution, until.Truncate(tier.Resolution))
+		if err != nil {
+			return rolledUp, fmt.Errorf("tier %s: %w", tier.Name, err)
+		}
+		rolledUp += n
+	}
+	return rolledUp, nil
 }
 
 func (s *monitoringService) ListClientGraphMetrics(ctx context.Context, clientID string, lo *query.ListOptions, ri *query.RequestInfo, netLan bool, netWan bool) (*api.SuccessPayload, error) {
-	span, err := s.validateAndParseGraphOptions(lo)
+	span, tier, err := s.validateAndParseGraphOptions(lo)
 	if err != nil {
 		return nil, err
 	}
 
-	entries, err := s.DBProvider.ListGraphMetricsByClientID(ctx, clientID, span.Hours(), lo)
+	entries, err := s.DBProvider.ListGraphMetricsByClientID(ctx, clientID, tier.Resolution, span.Hours(), lo)
 	if err != nil {
 		return nil, err
 	}
@@ -96,12 +199,12 @@ func (s *monitoringService) ListClientGraph(ctx context.Context, clientID string
 		}
 	}
 
-	span, err := s.validateAndParseGraphOptions(lo)
+	span, tier, err := s.validateAndParseGraphOptions(lo)
 	if err != nil {
 		return nil, err
 	}
 
-	entries, err := s.DBProvider.ListGraphByClientID(ctx, clientID, span.Hours(), lo, graph)
+	entries, err := s.DBProvider.ListGraphByClientID(ctx, clientID, tier.Resolution, span.Hours(), lo, graph)
 	if err != nil {
 		return nil, err
 	}
@@ -202,17 +305,18 @@ func calculateBytesPercent(bytes float64, bytesMax float64) float64 {
 	return bytes / bytesMax * 100
 }
 
-func (s *monitoringService) validateAndParseGraphOptions(lo *query.ListOptions) (*time.Duration, error) {
+// validateAndParseGraphOptions returns the span of the requested graph and the tier to read it from.
+func (s *monitoringService) validateAndParseGraphOptions(lo *query.ListOptions) (time.Duration, Tier, error) {
 	err := query.ValidateListOptions(lo, ClientGraphMetricsSortFields, ClientGraphMetricsFilterFields, ClientGraphMetricsFields, nil)
 	if err != nil {
-		return nil, err
+		return 0, Tier{}, err
 	}
 	if err := parseAndConvertFilterValues(lo.Filters); err != nil {
-		return nil, err
+		return 0, Tier{}, err
 	}
 
 	if len(lo.Filters) != 2 {
-		return nil, errors.APIError{
+		return 0, Tier{}, errors.APIError{
 			Message:    "Illegal number of filter options",
 			HTTPStatus: http.StatusBadRequest,
 		}
@@ -223,21 +327,25 @@ func (s *monitoringService) validateAndParseGraphOptions(lo *query.ListOptions)
 		(lo.Filters[0].Operator == query.FilterOperatorTypeSince && lo.Filters[1].Operator == query.FilterOperatorTypeUntil) {
 		//these are the allowed filter combinations
 	} else {
-		return nil, errors.APIError{Message: fmt.Sprintf("Illegal filter pair %s %s", lo.Filters[0], lo.Filters[1]), HTTPStatus: http.StatusBadRequest}
+		return 0, Tier{}, errors.APIError{Message: fmt.Sprintf("Illegal filter pair %s %s", lo.Filters[0], lo.Filters[1]), HTTPStatus: http.StatusBadRequest}
 	}
 
 	lower, _ := time.Parse(layoutDb, lo.Filters[0].Values[0])
 	upper, _ := time.Parse(layoutDb, lo.Filters[1].Values[0])
 
 	if upper.Before(lower) {
-		return nil, errors.APIError{Message: "Illegal time value (upper before lower)", HTTPStatus: http.StatusBadRequest}
+		return 0, Tier{}, errors.APIError{Message: "Illegal time value (upper before lower)", HTTPStatus: http.StatusBadRequest}
 	}
 	span := upper.Sub(lower)
-	if span < minDownsamplingDuration || span > maxDownsamplingDuration {
-		return nil, errors.APIError{Message: fmt.Sprintf("Illegal period (min,max allowed: %d,%d hours)", minDownsamplingHours, maxDownsamplingHours), HTTPStatus: http.StatusBadRequest}
+	maxSpan := maxDownsamplingDuration
+	if s.tiers.MaxRetention() > maxSpan {
+		maxSpan = s.tiers.MaxRetention()
+	}
+	if span < minDownsamplingDuration || span > maxSpan {
+		return 0, Tier{}, errors.APIError{Message: fmt.Sprintf("Illegal period (min,max allowed: %d,%d hours)", minDownsamplingHours, int64(maxSpan.Hours())), HTTPStatus: http.StatusBadRequest}
 	}
 
-	return &span, nil
+	return span, s.tiers.ForGraph(lower, span, time.Now()), nil
 }
 
 func (s *monitoringService) ListClientMetrics(ctx context.Context, clientID string, options *query.ListOptions) (*api.SuccessPayload, error) {
@@ -321,8 +429,63 @@ func (s *monitoringService) ListClientProcesses(ctx context.Context, clientID st
 	}, nil
 }
 
+func (s *monitoringService) ListClientCustomMetrics(ctx context.Context, clientID string, options *query.ListOptions) (*api.SuccessPayload, error) {
+	err := query.ValidateListOptions(options, ClientCustomMetricsSortFields, ClientCustomMetricsFilterFields, ClientCustomMetricsFields, &query.PaginationConfig{
+		DefaultLimit: defaultLimitCustomMetrics,
+		MaxLimit:     maxLimitCustomMetrics,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := parseAndConvertFilterValues(options.Filters); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.DBProvider.ListCustomMetricsByClientID(ctx, clientID, options)
+	if err != nil {
+		return nil, err
+	}
+	count, err := s.DBProvider.CountCustomMetricsByClientID(ctx, clientID, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.SuccessPayload{
+		Data: entries,
+		Meta: api.NewMeta(count),
+	}, nil
+}
+
+func (s *monitoringService) ListClientCustomGraph(ctx context.Context, clientID string, name string, lo *query.ListOptions) (*api.SuccessPayload, error) {
+	span, tier, err := s.validateAndParseGraphOptions(lo)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.DBProvider.ListCustomGraphByClientID(ctx, clientID, name, tier.Resolution, span.Hours(), lo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.SuccessPayload{
+		Data: entries,
+	}, nil
+}
+
 func parseAndConvertFilterValues(filters []query.FilterOption) error {
 	for _, fo := range filters {
+		if fo.Operator == "" {
+			continue
+		}
+		if !isTimestampFilter(fo) {
+			for _, v := range fo.Values {
+				if _, err := strconv.ParseFloat(v, 64); err != nil {
+					return errors.APIError{Message: fmt.Sprintf("Illegal numeric value %s", v), HTTPStatus: http.StatusBadRequest}
+				}
+			}
+			continue
+		}
+
 		if (fo.Operator == query.FilterOperatorTypeGT) || (fo.Operator == query.FilterOperatorTypeLT) {
 			ti, err := strconv.ParseInt(fo.Values[0], 10, 64)
 			if err != nil {
@@ -345,3 +508,7 @@ func parseAndConvertFilterValues(filters []query.FilterOption) error {
 	}
 	return nil
 }
+
+func isTimestampFilter(fo query.FilterOption) bool {
+	return len(fo.Column) == 1 && fo.Column[0] == "timestamp"
+}
@@ -20,7 +20,7 @@ func TestMonitoringService_SaveMeasurement(t *testing.T) {
 	require.NoError(t, err)
 	defer dbProvider.Close()
 
-	service := NewService(dbProvider)
+	service := NewService(dbProvider, nil)
 	minGap := time.Second
 	mClient := time.Now().UTC().Add(-minGap)
 	m := &models.Measurement{
@@ -38,7 +38,7 @@ func TestMonitoringService_ListClientMetrics(t *testing.T) {
 	require.NoError(t, err)
 	defer dbProvider.Close()
 
-	service := NewService(dbProvider)
+	service := NewService(dbProvider, nil)
 
 	ctx := context.Background()
 
@@ -50,6 +50,10 @@ func TestMonitoringService_ListClientMetrics(t *testing.T) {
 	options2.Sorts[0].IsASC = true
 	options3 := createMetricsDefaultOptions()
 	options3.Pagination.Limit = "2"
+	options4 := createMetricsDefaultOptions()
+	options4.Filters = append(options4.Filters, query.FilterOption{Column: []string{"cpu_usage_percent"}, Operator: query.FilterOperatorTypeGTE, Values: []string{"15"}})
+	options5 := createMetricsDefaultOptions()
+	options5.Filters = append(options5.Filters, query.FilterOption{Column: []string{"cpu_usage_percent"}, Operator: query.FilterOperatorTypeLT, Values: []string{"15"}})
 
 	testCases := []struct {
 		Name                string
@@ -79,6 +83,20 @@ func TestMonitoringService_ListClientMetrics(t *testing.T) {
 			ExpectedDataListLen: 2,
 			ExpectedTimestamp:   measurement3,
 		},
+		{
+			Name:                "filter[cpu_usage_percent][gte]=15",
+			Options:             options4,
+			ExpectedMetaCount:   2,
+			ExpectedDataListLen: 1,
+			ExpectedTimestamp:   measurement3,
+		},
+		{
+			Name:                "filter[cpu_usage_percent][lt]=15",
+			Options:             options5,
+			ExpectedMetaCount:   1,
+			ExpectedDataListLen: 1,
+			ExpectedTimestamp:   measurement1,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -96,13 +114,21 @@ func TestMonitoringService_ListClientMetrics(t *testing.T) {
 			require.Equal(t, tc.ExpectedTimestamp, metricsList[0].Timestamp)
 		})
 	}
+
+	t.Run("illegal numeric value", func(t *testing.T) {
+		options := createMetricsDefaultOptions()
+		options.Filters = append(options.Filters, query.FilterOption{Column: []string{"cpu_usage_percent"}, Operator: query.FilterOperatorTypeGT, Values: []string{"high"}})
+
+		_, err := service.ListClientMetrics(ctx, "test_client_1", options)
+		require.EqualError(t, err, "Illegal numeric value high")
+	})
 }
 func TestMonitoringService_ListClientGraphMetrics(t *testing.T) {
 	dbProvider, err := NewSqliteProvider(":memory:", DataSourceOptions, testLog)
 	require.NoError(t, err)
 	defer dbProvider.Close()
 
-	service := NewService(dbProvider)
+	service := NewService(dbProvider, nil)
 
 	ctx := context.Background()
 
@@ -193,3 +219,63 @@ func TestMonitoringService_ListClientGraphMetrics(t *testing.T) {
 	}
 
 }
+
+func TestMonitoringService_GraphTiers(t *testing.T) {
+	day := 24 * time.Hour
+	dbProvider := &DBProviderMock{}
+	service := NewService(dbProvider, NewTiers(7*day, 90*day, 730*day))
+	now := time.Now().UTC()
+
+	testCases := []struct {
+		Name               string
+		Since              time.Time
+		Span               time.Duration
+		ExpectedResolution time.Duration
+		ExpectError        bool
+	}{
+		{
+			Name:               "recent data from raw measurements",
+			Since:              now.Add(-2 * time.Hour),
+			Span:               2 * time.Hour,
+			ExpectedResolution: 0,
+		},
+		{
+			Name:               "long span from 5 minutes rollups",
+			Since:              now.Add(-5 * day),
+			Span:               5 * day,
+			ExpectedResolution: 5 * time.Minute,
+		},
+		{
+			Name:               "older than raw retention from 5 minutes rollups",
+			Since:              now.Add(-30 * day),
+			Span:               day,
+			ExpectedResolution: 5 * time.Minute,
+		},
+		{
+			Name:               "older than 5 minutes rollups retention from hourly rollups",
+			Since:              now.Add(-365 * day),
+			Span:               30 * day,
+			ExpectedResolution: time.Hour,
+		},
+		{
+			Name:        "span longer than max retention",
+			Since:       now.Add(-800 * day),
+			Span:        800 * day,
+			ExpectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			options := createGraphMetricsDefaultOptions(tc.Since, tc.Span.Hours(), layoutAPI)
+
+			_, err := service.ListClientCustomGraph(context.Background(), "test_client", "queue_length", options)
+			if tc.ExpectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.ExpectedResolution, dbProvider.GraphResolution)
+		})
+	}
+}
@@ -20,12 +20,19 @@ import (
 type DBProvider interface {
 	CreateMeasurement(ctx context.Context, measurement *models.Measurement) error
 	DeleteMeasurementsBefore(ctx context.Context, compare time.Time) (int64, error)
-	ListGraphByClientID(context.Context, string, float64, *query.ListOptions, string) ([]*ClientGraphMetricsGraphPayload, error)
-	ListGraphMetricsByClientID(context.Context, string, float64, *query.ListOptions) ([]*ClientGraphMetricsPayload, error)
+	ListGraphByClientID(context.Context, string, time.Duration, float64, *query.ListOptions, string) ([]*ClientGraphMetricsGraphPayload, error)
+	ListGraphMetricsByClientID(context.Context, string, time.Duration, float64, *query.ListOptions) ([]*ClientGraphMetricsPayload, error)
 	ListMetricsByClientID(context.Context, string, *query.ListOptions) ([]*ClientMetricsPayload, error)
 	ListMountpointsByClientID(context.Context, string, *query.ListOptions) ([]*ClientMountpointsPayload, error)
 	ListProcessesByClientID(context.Context, string, *query.ListOptions) ([]*ClientProcessesPayload, error)
 	CountByClientID(context.Context, string, *query.ListOptions) (int, error)
+	CreateCustomMeasurements(ctx context.Context, measurements []*models.CustomMeasurement) error
+	ListCustomMetricsByClientID(context.Context, string, *query.ListOptions) ([]*ClientCustomMetricsPayload, error)
+	CountCustomMetricsByClientID(context.Context, string, *query.ListOptions) (int, error)
+	ListCustomGraphByClientID(context.Context, string, string, time.Duration, float64, *query.ListOptions) ([]*ClientCustomGraphPayload, error)
+	ListLatestCustomMetricsByClientID(ctx context.Context, clientID string, since time.Time) ([]*models.CustomMeasurement, error)
+	RollupMeasurements(ctx context.Context, resolution time.Duration, until time.Time) (int64, error)
+	DeleteRollupsBefore(ctx context.Context, resolution time.Duration, compare time.Time) (int64, error)
 	Close() error
 }
 
@@ -102,22 +109,17 @@ func (p *SqliteProvider) CountByClientID(ctx context.Context, clientID string, o
 	return result, nil
 }
 
-func (p *SqliteProvider) ListGraphMetricsByClientID(ctx context.Context, clientID string, hours float64, lo *query.ListOptions) ([]*ClientGraphMetricsPayload, error) {
+func (p *SqliteProvider) ListGraphMetricsByClientID(ctx context.Context, clientID string, resolution time.Duration, hours float64, lo *query.ListOptions) ([]*ClientGraphMetricsPayload, error) {
 	params := []interface{}{}
+	table, params := graphTable("measurements", resolution, params)
 	params = append(params, clientID)
 
 	q := `SELECT
-		timestamp,
-		round(avg(cpu_usage_percent),2) as cpu_usage_percent_avg,
-		min(cpu_usage_percent) as cpu_usage_percent_min,
-		max(cpu_usage_percent) as cpu_usage_percent_max,
-		round(avg(memory_usage_percent),2) as memory_usage_percent_avg,
-		min(memory_usage_percent) as memory_usage_percent_min,
-		max(memory_usage_percent) as memory_usage_percent_max,
-		round(avg(io_usage_percent),2) as io_usage_percent_avg,
-		min(io_usage_percent) as io_usage_percent_min,
-		max(io_usage_percent) as io_usage_percent_max
-	FROM measurements WHERE client_id = ?`
+		timestamp, ` +
+		graphColumns("cpu_usage_percent", "cpu_usage_percent", resolution) + `, ` +
+		graphColumns("memory_usage_percent", "memory_usage_percent", resolution) + `, ` +
+		graphColumns("io_usage_percent", "io_usage_percent", resolution) + `
+	FROM ` + table + ` WHERE client_id = ?`
 
 	q, params = p.converter.AddWhere(lo.Filters, q, params)
 
@@ -135,31 +137,26 @@ func (p *SqliteProvider) ListGraphMetricsByClientID(ctx context.Context, clientI
 	return val, err
 }
 
-func (p *SqliteProvider) ListGraphByClientID(ctx context.Context, clientID string, hours float64, lo *query.ListOptions, graph string) ([]*ClientGraphMetricsGraphPayload, error) {
+func (p *SqliteProvider) ListGraphByClientID(ctx context.Context, clientID string, resolution time.Duration, hours float64, lo *query.ListOptions, graph string) ([]*ClientGraphMetricsGraphPayload, error) {
 	params := []interface{}{}
-	params = append(params, clientID)
 	field, okField := ClientGraphNameToField[graph]
 	alias, okAlias := ClientGraphNameToAlias[graph]
 	if !okField || !okAlias {
 		return nil, fmt.Errorf("unknown graph: %s", graph)
 	}
+	table, params := graphTable("measurements", resolution, params)
+	params = append(params, clientID)
 
 	q := `SELECT timestamp, `
-	q = q + ` 
-		round(avg(` + field + `),2) as ` + alias + `_avg,
-		min(` + field + `) as ` + alias + `_min,
-		max(` + field + `) as ` + alias + `_max`
+	q = q + graphColumns(field, alias, resolution)
 
 	if strings.HasPrefix(graph, "net_") {
 		field = strings.ReplaceAll(field, "_in", "_out")
 		alias = strings.ReplaceAll(alias, "_in", "_out")
-		q = q + `, 
-		round(avg(` + field + `),2) as ` + alias + `_avg,
-		min(` + field + `) as ` + alias + `_min,
-		max(` + field + `) as ` + alias + `_max`
+		q = q + `, ` + graphColumns(field, alias, resolution)
 	}
 	q = q + ` 
-	FROM measurements WHERE client_id = ?`
+	FROM ` + table + ` WHERE client_id = ?`
 
 	q, params = p.converter.AddWhere(lo.Filters, q, params)
 
@@ -174,6 +171,29 @@ func (p *SqliteProvider) ListGraphByClientID(ctx context.Context, clientID strin
 	return val, err
 }
 
+// graphTable returns the table to read graphs of the given resolution from, rollups are filtered by resolution.
+func graphTable(table string, resolution time.Duration, params []interface{}) (string, []interface{}) {
+	if resolution == 0 {
+		return table, params
+	}
+	params = append(params, int64(resolution.Seconds()))
+	return `(SELECT * FROM ` + table + `_rollups WHERE resolution = ?)`, params
+}
+
+// graphColumns returns the avg, min and max of a field. Averages of rollups are weighted by the number of samples.
+func graphColumns(field, alias string, resolution time.Duration) string {
+	if resolution == 0 {
+		return `
+		round(avg(` + field + `),2) as ` + alias + `_avg,
+		min(` + field + `) as ` + alias + `_min,
+		max(` + field + `) as ` + alias + `_max`
+	}
+	return `
+		round(sum(` + field + `_avg*samples)/sum(CASE WHEN ` + field + `_avg IS NULL THEN NULL ELSE samples END),2) as ` + alias + `_avg,
+		min(` + field + `_min) as ` + alias + `_min,
+		max(` + field + `_max) as ` + alias + `_max`
+}
+
 func (p *SqliteProvider) CreateMeasurement(ctx context.Context, measurement *models.Measurement) error {
 	q := `INSERT INTO measurements (client_id, timestamp, cpu_usage_percent, memory_usage_percent, io_usage_percent, processes, mountpoints, net_lan_in, net_lan_out, net_wan_in, net_wan_out) 
 		VALUES (:client_id, :timestamp, :cpu_usage_percent, :memory_usage_percent, :io_usage_percent, :processes, :mountpoints, `
@@ -197,14 +217,199 @@ func (p *SqliteProvider) CreateMeasurement(ctx context.Context, measurement *mod
 	return err
 }
 
+func (p *SqliteProvider) CreateCustomMeasurements(ctx context.Context, measurements []*models.CustomMeasurement) error {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range measurements {
+		_, err := tx.NamedExecContext(ctx, `INSERT OR REPLACE INTO custom_measurements (client_id, timestamp, name, value)
+			VALUES (:client_id, :timestamp, :name, :value)`, m)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (p *SqliteProvider) ListCustomMetricsByClientID(ctx context.Context, clientID string, o *query.ListOptions) ([]*ClientCustomMetricsPayload, error) {
+	q := "SELECT * FROM `custom_measurements` as `custom_metrics` WHERE `client_id` = ? "
+	params := []interface{}{}
+	params = append(params, clientID)
+	q, params = p.converter.AppendOptionsToQuery(o, q, params)
+
+	val := []*ClientCustomMetricsPayload{}
+	err := p.db.SelectContext(ctx, &val, q, params...)
+	return val, err
+}
+
+func (p *SqliteProvider) CountCustomMetricsByClientID(ctx context.Context, clientID string, options *query.ListOptions) (int, error) {
+	var result int
+
+	q := "SELECT COUNT(*) FROM `custom_measurements` WHERE `client_id` = ? "
+	countOptions := *options
+	countOptions.Pagination = nil
+
+	params := []interface{}{}
+	params = append(params, clientID)
+	q, params = p.converter.AppendOptionsToQuery(&countOptions, q, params)
+
+	err := p.db.GetContext(ctx, &result, q, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result, nil
+}
+
+func (p *SqliteProvider) ListCustomGraphByClientID(ctx context.Context, clientID string, name string, resolution time.Duration, hours float64, lo *query.ListOptions) ([]*ClientCustomGraphPayload, error) {
+	params := []interface{}{}
+	table, params := graphTable("custom_measurements", resolution, params)
+	params = append(params, clientID, name)
+
+	q := `SELECT
+		timestamp, ` +
+		graphColumns("value", "value", resolution) + `
+	FROM ` + table + ` WHERE client_id = ? AND name = ?`
+
+	q, params = p.converter.AddWhere(lo.Filters, q, params)
+
+	// downsampling as for the system metrics graphs
+	q = q + ` GROUP BY round((strftime('%s',timestamp)/(?)),0)`
+	divisor := (math.Round(hours*100) / 100) * 29
+	params = append(params, divisor)
+
+	q = p.converter.AddOrderBy(lo.Sorts, q)
+
+	val := []*ClientCustomGraphPayload{}
+	err := p.db.SelectContext(ctx, &val, q, params...)
+	return val, err
+}
+
+// ListLatestCustomMetricsByClientID returns the latest value of each custom metric of the client pushed after since.
+func (p *SqliteProvider) ListLatestCustomMetricsByClientID(ctx context.Context, clientID string, since time.Time) ([]*models.CustomMeasurement, error) {
+	q := `SELECT client_id, timestamp, name, value
+	FROM custom_measurements AS c WHERE client_id = ? AND timestamp > ? AND timestamp = (
+		SELECT max(timestamp) FROM custom_measurements WHERE client_id = c.client_id AND name = c.name
+	) ORDER BY name`
+
+	val := []*models.CustomMeasurement{}
+	err := p.db.SelectContext(ctx, &val, q, clientID, since)
+	return val, err
+}
+
 func (p *SqliteProvider) DeleteMeasurementsBefore(ctx context.Context, compare time.Time) (int64, error) {
 	result, err := p.db.ExecContext(ctx, "DELETE FROM measurements WHERE  timestamp < ?", compare)
 	if err != nil {
 		return 0, err
 	}
-	return result.RowsAffected()
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err = p.db.ExecContext(ctx, "DELETE FROM custom_measurements WHERE timestamp < ?", compare)
+	if err != nil {
+		return 0, err
+	}
+	deletedCustom, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted + deletedCustom, nil
+}
+
+// RollupMeasurements aggregates the measurements not rolled up yet before until into buckets of the given resolution.
+// until must be the start of a bucket. It returns the number of written rollups.
+func (p *SqliteProvider) RollupMeasurements(ctx context.Context, resolution time.Duration, until time.Time) (int64, error) {
+	seconds := int64(resolution.Seconds())
+	to := until.UTC().Format(layoutDb)
+
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var from string
+	err = tx.GetContext(ctx, &from, "SELECT strftime('%Y-%m-%d %H:%M:%S', rolled_up_until) FROM rollups_progress WHERE resolution = ?", seconds)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+	if from >= to {
+		return 0, nil
+	}
+
+	bucket := "datetime(CAST(strftime('%s', timestamp) AS INTEGER) / ? * ?, 'unixepoch')"
+	result, err := tx.ExecContext(ctx, `INSERT OR REPLACE INTO measurements_rollups
+		SELECT ?, client_id, `+bucket+` AS bucket, count(*),
+			avg(cpu_usage_percent), min(cpu_usage_percent), max(cpu_usage_percent),
+			avg(memory_usage_percent), min(memory_usage_percent), max(memory_usage_percent),
+			avg(io_usage_percent), min(io_usage_percent), max(io_usage_percent),
+			avg(net_lan_in), min(net_lan_in), max(net_lan_in),
+			avg(net_lan_out), min(net_lan_out), max(net_lan_out),
+			avg(net_wan_in), min(net_wan_in), max(net_wan_in),
+			avg(net_wan_out), min(net_wan_out), max(net_wan_out)
+		FROM measurements WHERE timestamp >= ? AND timestamp < ?
+		GROUP BY client_id, bucket`, seconds, seconds, seconds, from, to)
+	if err != nil {
+		return 0, err
+	}
+	rolledUp, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err = tx.ExecContext(ctx, `INSERT OR REPLACE INTO custom_measurements_rollups
+		SELECT ?, client_id, `+bucket+` AS bucket, name, count(*), avg(value), min(value), max(value)
+		FROM custom_measurements WHERE timestamp >= ? AND timestamp < ?
+		GROUP BY client_id, name, bucket`, seconds, seconds, seconds, from, to)
+	if err != nil {
+		return 0, err
+	}
+	rolledUpCustom, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT OR REPLACE INTO rollups_progress (resolution, rolled_up_until) VALUES (?, ?)", seconds, to)
+	if err != nil {
+		return 0, err
+	}
+
+	return rolledUp + rolledUpCustom, tx.Commit()
+}
+
+func (p *SqliteProvider) DeleteRollupsBefore(ctx context.Context, resolution time.Duration, compare time.Time) (int64, error) {
+	seconds := int64(resolution.Seconds())
+	before := compare.UTC().Format(layoutDb)
+
+	var deleted int64
+	for _, table := range []string{"measurements_rollups", "custom_measurements_rollups"} {
+		result, err := p.db.ExecContext(ctx, "DELETE FROM "+table+" WHERE resolution = ? AND timestamp < ?", seconds, before)
+		if err != nil {
+			return 0, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		deleted += n
+	}
+
+	return deleted, nil
 }
 
 func (p *SqliteProvider) Close() error {
 	return p.db.Close()
 }
+
+func (p *SqliteProvider) DB() *sqlx.DB {
+	return p.db
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	chshare "github.com/realvnc-labs/rport/share/logger"
@@ -180,14 +181,14 @@ func TestSqliteProvider_ListGraphMetricsByClientID(t *testing.T) {
 	hours := 48.0
 	options := createGraphMetricsDefaultOptions(measurement1, hours, layoutDb)
 
-	mList, err := dbProvider.ListGraphMetricsByClientID(ctx, "test_client", hours, options)
+	mList, err := dbProvider.ListGraphMetricsByClientID(ctx, "test_client", 0, hours, options)
 	require.NoError(t, err)
 	require.NotNil(t, mList)
 	require.Equal(t, 126, len(mList))
 
 	options.Filters = createGTLTFilter(measurement1, hours)
 
-	mList, err = dbProvider.ListGraphMetricsByClientID(ctx, "test_client", hours, options)
+	mList, err = dbProvider.ListGraphMetricsByClientID(ctx, "test_client", 0, hours, options)
 	require.NoError(t, err)
 	require.NotNil(t, mList)
 	require.Equal(t, 126, len(mList))
@@ -229,7 +230,7 @@ func TestSqliteProvider_ListGraphMetricsGraphByClientID(t *testing.T) {
 			hours := 48.0
 			options := createGraphMetricsDefaultOptions(measurement1, hours, layoutDb)
 
-			mList, err := dbProvider.ListGraphByClientID(ctx, "test_client", hours, options, tc.GraphName)
+			mList, err := dbProvider.ListGraphByClientID(ctx, "test_client", 0, hours, options, tc.GraphName)
 			if tc.ExpectError {
 				require.Error(t, err)
 			} else {
@@ -467,3 +468,139 @@ func createMountpointsDefaultOptions() *query.ListOptions {
 
 	return qOptions
 }
+
+func TestSqliteProvider_CustomMeasurements(t *testing.T) {
+	dbProvider, err := NewSqliteProvider(":memory:", DataSourceOptions, testLog)
+	require.NoError(t, err)
+	defer dbProvider.Close()
+
+	ctx := context.Background()
+
+	var measurements []*models.CustomMeasurement
+	for i, ts := range []time.Time{measurement1, measurement2, measurement3} {
+		measurements = append(measurements,
+			&models.CustomMeasurement{ClientID: "test_client_1", Timestamp: ts, Name: "queue_length", Value: float64(10 + i)},
+			&models.CustomMeasurement{ClientID: "test_client_2", Timestamp: ts, Name: "queue_length", Value: float64(20 + i)},
+		)
+	}
+	measurements = append(measurements, &models.CustomMeasurement{ClientID: "test_client_1", Timestamp: measurement1, Name: "backups", Value: 1})
+	require.NoError(t, dbProvider.CreateCustomMeasurements(ctx, measurements))
+
+	options := &query.ListOptions{
+		Sorts:   query.ParseSortOptions(ClientCustomMetricsSortDefault),
+		Filters: []query.FilterOption{{Column: []string{"name"}, Operator: query.FilterOperatorTypeEQ, Values: []string{"queue_length"}}},
+		Fields:  query.ParseFieldsOptions(ClientCustomMetricsFieldsDefault),
+	}
+	list, err := dbProvider.ListCustomMetricsByClientID(ctx, "test_client_1", options)
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.Equal(t, "queue_length", list[0].Name)
+	assert.Equal(t, 12.0, *list[0].Value)
+
+	count, err := dbProvider.CountCustomMetricsByClientID(ctx, "test_client_1", &query.ListOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	latest, err := dbProvider.ListLatestCustomMetricsByClientID(ctx, "test_client_1", measurement1.Add(-time.Second))
+	require.NoError(t, err)
+	require.Len(t, latest, 2)
+	assert.Equal(t, "backups", latest[0].Name)
+	assert.Equal(t, 1.0, latest[0].Value)
+	assert.Equal(t, "queue_length", latest[1].Name)
+	assert.Equal(t, 12.0, latest[1].Value)
+
+	latest, err = dbProvider.ListLatestCustomMetricsByClientID(ctx, "test_client_1", measurement3)
+	require.NoError(t, err)
+	assert.Len(t, latest, 0)
+
+	deleted, err := dbProvider.DeleteMeasurementsBefore(ctx, measurement3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), deleted)
+}
+
+func TestSqliteProvider_ListCustomGraphByClientID(t *testing.T) {
+	dbProvider, err := NewSqliteProvider(":memory:", DataSourceOptions, testLog)
+	require.NoError(t, err)
+	defer dbProvider.Close()
+
+	ctx := context.Background()
+
+	var measurements []*models.CustomMeasurement
+	count := 60 * 24
+	for i := 0; i < count; i++ {
+		measurements = append(measurements, &models.CustomMeasurement{
+			ClientID:  "test_client",
+			Timestamp: measurement1.Add(time.Duration(i) * measurementInterval),
+			Name:      "queue_length",
+			Value:     float64(10 + (i%2)*10),
+		})
+	}
+	require.NoError(t, dbProvider.CreateCustomMeasurements(ctx, measurements))
+
+	options := createGraphMetricsDefaultOptions(measurement1, 24, layoutDb)
+	graph, err := dbProvider.ListCustomGraphByClientID(ctx, "test_client", "queue_length", 0, 24, options)
+	require.NoError(t, err)
+	require.NotEmpty(t, graph)
+	assert.Less(t, len(graph), count/10)
+	assert.InDelta(t, 15.0, graph[1].Avg, 1)
+	assert.Equal(t, 10.0, graph[1].Min)
+	assert.Equal(t, 20.0, graph[1].Max)
+}
+
+func TestSqliteProvider_RollupMeasurements(t *testing.T) {
+	dbProvider, err := NewSqliteProvider(":memory:", DataSourceOptions, testLog)
+	require.NoError(t, err)
+	defer dbProvider.Close()
+
+	ctx := context.Background()
+
+	require.NoError(t, createDownsamplingData(ctx, dbProvider))
+	require.NoError(t, dbProvider.CreateCustomMeasurements(ctx, []*models.CustomMeasurement{
+		{ClientID: "test_client", Timestamp: measurement1, Name: "queue_length", Value: 10},
+		{ClientID: "test_client", Timestamp: measurement2, Name: "queue_length", Value: 20},
+	}))
+
+	until := measurement1.Add(24 * time.Hour)
+	rolledUp, err := dbProvider.RollupMeasurements(ctx, 5*time.Minute, until)
+	require.NoError(t, err)
+	assert.Equal(t, int64(24*12+1), rolledUp)
+
+	// measurements before until are rolled up already
+	rolledUp, err = dbProvider.RollupMeasurements(ctx, 5*time.Minute, until)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), rolledUp)
+
+	rolledUp, err = dbProvider.RollupMeasurements(ctx, time.Hour, until)
+	require.NoError(t, err)
+	assert.Equal(t, int64(24+1), rolledUp)
+
+	options := createGraphMetricsDefaultOptions(measurement1, 24, layoutDb)
+	graph, err := dbProvider.ListGraphMetricsByClientID(ctx, "test_client", 5*time.Minute, 24, options)
+	require.NoError(t, err)
+	require.NotEmpty(t, graph)
+	assert.Less(t, len(graph), 24*12)
+	// sorted by timestamp desc
+	oldest := graph[len(graph)-1]
+	assert.Equal(t, measurement1, oldest.Timestamp)
+	assert.InDelta(t, 15.0, oldest.CPUUsagePercent.Avg, 1)
+	assert.Equal(t, 10.0, oldest.CPUUsagePercent.Min)
+	assert.Equal(t, 20.0, oldest.CPUUsagePercent.Max)
+
+	netGraph, err := dbProvider.ListGraphByClientID(ctx, "test_client", time.Hour, 24, options, "net_usage_bps_lan")
+	require.NoError(t, err)
+	require.Len(t, netGraph, 24)
+	require.NotNil(t, netGraph[23].NetUsageBPSLan)
+	assert.Equal(t, 10000.0, *netGraph[23].NetUsageBPSLan.InMin)
+	assert.Equal(t, 10590.0, *netGraph[23].NetUsageBPSLan.InMax)
+
+	customGraph, err := dbProvider.ListCustomGraphByClientID(ctx, "test_client", "queue_length", 5*time.Minute, 24, options)
+	require.NoError(t, err)
+	require.Len(t, customGraph, 1)
+	assert.Equal(t, 15.0, customGraph[0].Avg)
+	assert.Equal(t, 10.0, customGraph[0].Min)
+	assert.Equal(t, 20.0, customGraph[0].Max)
+
+	deleted, err := dbProvider.DeleteRollupsBefore(ctx, 5*time.Minute, measurement1.Add(12*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(12*12+1), deleted)
+}
@@ -0,0 +1,76 @@
+package monitoring
+
+import (
+	"time"
+)
+
+const (
+	TierRaw = "raw"
+	Tier5m  = "5m"
+	Tier1h  = "1h"
+)
+
+// Tier is a resolution measurements are stored in. Raw measurements are aggregated into rollups of a lower resolution
+// by the RollupTask, each tier keeps its data for its own retention period.
+type Tier struct {
+	Name string
+	// Resolution is the size of the rollup buckets, zero for raw measurements.
+	Resolution time.Duration
+	Retention  time.Duration
+}
+
+func (t Tier) IsRaw() bool {
+	return t.Resolution == 0
+}
+
+// Tiers are sorted by resolution, raw measurements first.
+type Tiers []Tier
+
+// NewTiers returns the raw tier followed by the 5 minutes and the hourly rollups.
+func NewTiers(rawRetention, rollup5mRetention, rollup1hRetention time.Duration) Tiers {
+	return Tiers{
+		{Name: TierRaw, Retention: rawRetention},
+		{Name: Tier5m, Resolution: 5 * time.Minute, Retention: rollup5mRetention},
+		{Name: Tier1h, Resolution: time.Hour, Retention: rollup1hRetention},
+	}
+}
+
+// Rollups returns the tiers aggregated from raw measurements.
+func (t Tiers) Rollups() Tiers {
+	var rollups Tiers
+	for _, tier := range t {
+		if !tier.IsRaw() {
+			rollups = append(rollups, tier)
+		}
+	}
+	return rollups
+}
+
+// MaxRetention returns the longest retention of all tiers.
+func (t Tiers) MaxRetention() time.Duration {
+	var max time.Duration
+	for _, tier := range t {
+		if tier.Retention > max {
+			max = tier.Retention
+		}
+	}
+	return max
+}
+
+// ForGraph returns the tier with the highest resolution still keeping the data since the given time.
+// Raw measurements are used for spans up to maxDownsamplingDuration only, longer spans are read from rollups.
+// Without tiers graphs are read from raw measurements.
+func (t Tiers) ForGraph(since time.Time, span time.Duration, now time.Time) Tier {
+	if len(t) == 0 {
+		return Tier{Name: TierRaw}
+	}
+	for _, tier := range t {
+		if tier.IsRaw() && span > maxDownsamplingDuration {
+			continue
+		}
+		if !since.Before(now.Add(-tier.Retention)) {
+			return tier
+		}
+	}
+	return t[len(t)-1]
+}
@@ -0,0 +1,54 @@
+package chserver
+
+import (
+	"context"
+
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/share/comm"
+)
+
+// pushMonitoringProfile pushes the monitoring profile assigned to the client groups of the client and stores whether
+// the client acknowledged it. Clients not assigned to a profile anymore are reset to their local monitoring config.
+func (s *Server) pushMonitoringProfile(ctx context.Context, client *clientdata.Client) error {
+	groups, err := s.clientGroupProvider.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	var groupIDs []string
+	for _, group := range groups {
+		if client.BelongsTo(group) {
+			groupIDs = append(groupIDs, group.ID)
+		}
+	}
+
+	profile := s.monitoringProfiles.Select(groupIDs)
+	if profile == nil {
+		if client.GetMonitoringProfile() == nil {
+			return nil
+		}
+		err = comm.SendRequestAndGetResponse(client.GetConnection(), comm.RequestTypeSetMonitoringProfile, &comm.SetMonitoringProfileRequest{}, nil, s.Logger)
+		if err != nil {
+			return err
+		}
+		return s.clientService.SetMonitoringProfile(client.GetID(), nil)
+	}
+
+	state := &clientdata.MonitoringProfileState{
+		Name:    profile.Name,
+		Version: profile.Version(),
+	}
+	var resp comm.SetMonitoringProfileResponse
+	pushErr := comm.SendRequestAndGetResponse(client.GetConnection(), comm.RequestTypeSetMonitoringProfile, profile.Request(), &resp, s.Logger)
+	if pushErr != nil {
+		state.Error = pushErr.Error()
+	} else {
+		now := clientdata.Now()
+		state.AcknowledgedVersion = resp.Version
+		state.AcknowledgedAt = &now
+	}
+
+	if err := s.clientService.SetMonitoringProfile(client.GetID(), state); err != nil {
+		return err
+	}
+	return pushErr
+}
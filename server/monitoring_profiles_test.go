@@ -0,0 +1,110 @@
+package chserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/cgroups"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/monitoringprofiles"
+	"github.com/realvnc-labs/rport/share/comm"
+	"github.com/realvnc-labs/rport/share/test"
+)
+
+type monitoringProfilesGroupProvider struct {
+	cgroups.ClientGroupProvider
+	groups []*cgroups.ClientGroup
+}
+
+func (p monitoringProfilesGroupProvider) GetAll(ctx context.Context) ([]*cgroups.ClientGroup, error) {
+	return p.groups, nil
+}
+
+func TestPushMonitoringProfile(t *testing.T) {
+	configured := []monitoringprofiles.Profile{
+		{
+			Name:           "servers",
+			ClientGroups:   []string{"linux-servers"},
+			Interval:       2 * time.Minute,
+			Metrics:        []string{comm.MonitoringMetricCPU, comm.MonitoringMetricMemory},
+			WatchProcesses: []string{"nginx"},
+		},
+	}
+	require.NoError(t, monitoringprofiles.ValidateProfiles(configured))
+	profiles := monitoringprofiles.New(configured)
+
+	c1 := clients.New(t).ID("client-1").Logger(testLog).Build()
+	connMock := test.NewConnMock()
+	c1.SetConnection(connMock)
+
+	groups := []*cgroups.ClientGroup{
+		{ID: "linux-servers", Params: &cgroups.ClientParams{ClientID: &cgroups.ParamValues{"client-1"}}},
+	}
+	s := &Server{
+		Logger:              testLog,
+		clientService:       clients.NewClientService(nil, nil, clients.NewClientRepository([]*clientdata.Client{c1}, &hour, testLog), testLog, nil),
+		clientGroupProvider: monitoringProfilesGroupProvider{groups: groups},
+		monitoringProfiles:  profiles,
+	}
+
+	t.Run("acknowledged", func(t *testing.T) {
+		connMock.ReturnOk = true
+		connMock.ReturnResponsePayload = []byte(`{"Version":"` + profiles[0].Version() + `"}`)
+
+		require.NoError(t, s.pushMonitoringProfile(context.Background(), c1))
+
+		name, _, payload := connMock.InputSendRequest()
+		assert.Equal(t, comm.RequestTypeSetMonitoringProfile, name)
+		assert.JSONEq(t, `{
+			"Name": "servers",
+			"Version": "`+profiles[0].Version()+`",
+			"Enabled": true,
+			"Interval": 120000000000,
+			"Metrics": ["cpu", "memory"],
+			"WatchProcesses": ["nginx"],
+			"WatchServices": null
+		}`, string(payload))
+		state := c1.GetMonitoringProfile()
+		require.NotNil(t, state)
+		assert.Equal(t, "servers", state.Name)
+		assert.True(t, state.IsAcknowledged())
+		assert.NotNil(t, state.AcknowledgedAt)
+		assert.Empty(t, state.Error)
+	})
+
+	t.Run("not supported by client", func(t *testing.T) {
+		connMock.ReturnOk = false
+		connMock.ReturnResponsePayload = []byte("unknown request")
+
+		assert.Error(t, s.pushMonitoringProfile(context.Background(), c1))
+
+		state := c1.GetMonitoringProfile()
+		require.NotNil(t, state)
+		assert.False(t, state.IsAcknowledged())
+		assert.NotEmpty(t, state.Error)
+	})
+
+	t.Run("removed from group", func(t *testing.T) {
+		connMock.ReturnOk = true
+		connMock.ReturnResponsePayload = nil
+		s.clientGroupProvider = monitoringProfilesGroupProvider{}
+
+		require.NoError(t, s.pushMonitoringProfile(context.Background(), c1))
+
+		_, _, payload := connMock.InputSendRequest()
+		assert.Contains(t, string(payload), `"Name":""`)
+		assert.Nil(t, c1.GetMonitoringProfile())
+	})
+
+	t.Run("no profile", func(t *testing.T) {
+		connMock.ReturnErr = errors.New("not expected to be sent")
+
+		assert.NoError(t, s.pushMonitoringProfile(context.Background(), c1))
+	})
+}
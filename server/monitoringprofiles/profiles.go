@@ -0,0 +1,159 @@
+package monitoringprofiles
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/realvnc-labs/rport/share/comm"
+)
+
+const (
+	DefaultInterval = 60 * time.Second
+	// MaxWatched limits the watched processes and services of a profile, their states are pushed as custom metrics.
+	MaxWatched = 20
+)
+
+var watchNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_.@\-]+$`)
+
+// Profile defines the monitoring config pushed to clients belonging to one of the client groups. It overrides the
+// monitoring config of the clients.
+type Profile struct {
+	Name           string        `mapstructure:"name"`
+	ClientGroups   []string      `mapstructure:"client_groups"`
+	Enabled        *bool         `mapstructure:"enabled"`
+	Interval       time.Duration `mapstructure:"interval"`
+	Metrics        []string      `mapstructure:"metrics"`
+	WatchProcesses []string      `mapstructure:"watch_processes"`
+	WatchServices  []string      `mapstructure:"watch_services"`
+
+	version string
+}
+
+func (p *Profile) Validate() error {
+	if p.Name == "" {
+		return errors.New("name cannot be empty")
+	}
+	if len(p.ClientGroups) == 0 {
+		return errors.New("client_groups cannot be empty")
+	}
+	if p.Enabled == nil {
+		enabled := true
+		p.Enabled = &enabled
+	}
+	if p.Interval == 0 {
+		p.Interval = DefaultInterval
+	}
+	if p.Interval < DefaultInterval {
+		return fmt.Errorf("interval must be at least %s", DefaultInterval)
+	}
+	for _, metric := range p.Metrics {
+		if !isKnownMetric(metric) {
+			return fmt.Errorf("invalid metric %q, expected one of: %v", metric, comm.MonitoringMetrics)
+		}
+	}
+	if len(p.WatchProcesses)+len(p.WatchServices) > MaxWatched {
+		return fmt.Errorf("max %d processes and services can be watched", MaxWatched)
+	}
+	for _, name := range append(append([]string{}, p.WatchProcesses...), p.WatchServices...) {
+		if !watchNameRegex.MatchString(name) {
+			return fmt.Errorf("invalid process or service name %q", name)
+		}
+	}
+
+	version, err := p.calcVersion()
+	if err != nil {
+		return err
+	}
+	p.version = version
+	return nil
+}
+
+func isKnownMetric(metric string) bool {
+	for _, m := range comm.MonitoringMetrics {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
+
+// calcVersion returns a hash of the settings pushed to clients, so clients running an outdated profile are detected.
+func (p *Profile) calcVersion() (string, error) {
+	b, err := json.Marshal(p.request(""))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:6]), nil
+}
+
+// Version is the hash of the profile settings, it's set by Validate.
+func (p *Profile) Version() string {
+	return p.version
+}
+
+// IsEnabled returns false if the profile turns the monitoring of the clients off.
+func (p *Profile) IsEnabled() bool {
+	return p.Enabled == nil || *p.Enabled
+}
+
+// Request returns the request to push the profile to clients.
+func (p *Profile) Request() *comm.SetMonitoringProfileRequest {
+	return p.request(p.version)
+}
+
+func (p *Profile) request(version string) *comm.SetMonitoringProfileRequest {
+	return &comm.SetMonitoringProfileRequest{
+		Name:           p.Name,
+		Version:        version,
+		Enabled:        p.IsEnabled(),
+		Interval:       p.Interval,
+		Metrics:        p.Metrics,
+		WatchProcesses: p.WatchProcesses,
+		WatchServices:  p.WatchServices,
+	}
+}
+
+func ValidateProfiles(profiles []Profile) error {
+	names := make(map[string]bool, len(profiles))
+	for i := range profiles {
+		if err := profiles[i].Validate(); err != nil {
+			return fmt.Errorf("invalid monitoring profile %d: %v", i+1, err)
+		}
+		if names[profiles[i].Name] {
+			return fmt.Errorf("duplicate monitoring profile %q", profiles[i].Name)
+		}
+		names[profiles[i].Name] = true
+	}
+	return nil
+}
+
+// Profiles holds the configured profiles in the order of the configuration.
+type Profiles []*Profile
+
+func New(configured []Profile) Profiles {
+	profiles := make(Profiles, 0, len(configured))
+	for i := range configured {
+		profiles = append(profiles, &configured[i])
+	}
+	return profiles
+}
+
+// Select returns the first profile assigned to one of the given client group ids, nil if none is assigned.
+func (p Profiles) Select(clientGroupIDs []string) *Profile {
+	for _, profile := range p {
+		for _, assigned := range profile.ClientGroups {
+			for _, id := range clientGroupIDs {
+				if assigned == id {
+					return profile
+				}
+			}
+		}
+	}
+	return nil
+}
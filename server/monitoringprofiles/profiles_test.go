@@ -0,0 +1,92 @@
+package monitoringprofiles
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	p := &Profile{Name: "servers", ClientGroups: []string{"linux-servers"}}
+	require.NoError(t, p.Validate())
+	assert.True(t, p.IsEnabled())
+	assert.Equal(t, DefaultInterval, p.Interval)
+	assert.Len(t, p.Version(), 12)
+
+	testCases := []struct {
+		name          string
+		profile       Profile
+		expectedError string
+	}{
+		{
+			name:          "no name",
+			profile:       Profile{ClientGroups: []string{"linux-servers"}},
+			expectedError: "name cannot be empty",
+		},
+		{
+			name:          "no client groups",
+			profile:       Profile{Name: "servers"},
+			expectedError: "client_groups cannot be empty",
+		},
+		{
+			name:          "interval too short",
+			profile:       Profile{Name: "servers", ClientGroups: []string{"linux-servers"}, Interval: 10 * time.Second},
+			expectedError: "interval must be at least 1m0s",
+		},
+		{
+			name:          "unknown metric",
+			profile:       Profile{Name: "servers", ClientGroups: []string{"linux-servers"}, Metrics: []string{"gpu"}},
+			expectedError: `invalid metric "gpu", expected one of: [cpu memory io processes mountpoints net]`,
+		},
+		{
+			name:          "invalid process name",
+			profile:       Profile{Name: "servers", ClientGroups: []string{"linux-servers"}, WatchProcesses: []string{"nginx; rm"}},
+			expectedError: `invalid process or service name "nginx; rm"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.EqualError(t, tc.profile.Validate(), tc.expectedError)
+		})
+	}
+}
+
+func TestVersion(t *testing.T) {
+	p1 := &Profile{Name: "servers", ClientGroups: []string{"linux-servers"}, WatchServices: []string{"sshd"}}
+	p2 := &Profile{Name: "servers", ClientGroups: []string{"other-servers"}, WatchServices: []string{"sshd"}}
+	p3 := &Profile{Name: "servers", ClientGroups: []string{"linux-servers"}, WatchServices: []string{"nginx"}}
+	require.NoError(t, p1.Validate())
+	require.NoError(t, p2.Validate())
+	require.NoError(t, p3.Validate())
+
+	// the version only changes with the settings pushed to clients
+	assert.Equal(t, p1.Version(), p2.Version())
+	assert.NotEqual(t, p1.Version(), p3.Version())
+	assert.Equal(t, p1.Version(), p1.Request().Version)
+}
+
+func TestValidateProfiles(t *testing.T) {
+	profiles := []Profile{
+		{Name: "servers", ClientGroups: []string{"linux-servers"}},
+		{Name: "servers", ClientGroups: []string{"windows-servers"}},
+	}
+	assert.EqualError(t, ValidateProfiles(profiles), `duplicate monitoring profile "servers"`)
+
+	profiles[1].ClientGroups = nil
+	assert.EqualError(t, ValidateProfiles(profiles), "invalid monitoring profile 2: client_groups cannot be empty")
+}
+
+func TestSelect(t *testing.T) {
+	profiles := New([]Profile{
+		{Name: "databases", ClientGroups: []string{"db-servers"}},
+		{Name: "servers", ClientGroups: []string{"linux-servers", "windows-servers"}},
+	})
+
+	assert.Nil(t, profiles.Select(nil))
+	assert.Nil(t, profiles.Select([]string{"desktops"}))
+	assert.Equal(t, "servers", profiles.Select([]string{"windows-servers"}).Name)
+	// the first configured profile wins
+	assert.Equal(t, "databases", profiles.Select([]string{"linux-servers", "db-servers"}).Name)
+}
@@ -1,6 +1,7 @@
 package rmailer
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"net/url"
@@ -18,6 +19,18 @@ type Mailer interface {
 	Send(ctx context.Context, to []string, subject string, contentType ContentType, body string) error
 }
 
+// AttachmentMailer is a Mailer also able to send files attached to the message.
+type AttachmentMailer interface {
+	Mailer
+	SendWithAttachments(ctx context.Context, to []string, subject string, contentType ContentType, body string, attachments ...Attachment) error
+}
+
+// Attachment is a file attached to a mail.
+type Attachment struct {
+	Name    string
+	Content []byte
+}
+
 const MaxHangingMailSends = 20
 
 // ContentType represents a content type for the Msg
@@ -57,12 +70,16 @@ type rMailer struct {
 }
 
 func (rm rMailer) Send(ctx context.Context, to []string, subject string, contentType ContentType, body string) error {
+	return rm.SendWithAttachments(ctx, to, subject, contentType, body)
+}
+
+func (rm rMailer) SendWithAttachments(ctx context.Context, to []string, subject string, contentType ContentType, body string, attachments ...Attachment) error {
 
 	if err := contentType.Valid(); err != nil {
 		return fmt.Errorf("invalid content type: %v", err)
 	}
 
-	mailerOut := rm.enqueueSend(ctx, to, subject, contentType, body)
+	mailerOut := rm.enqueueSend(ctx, to, subject, contentType, body, attachments)
 
 	if len(rm.doomQueue) >= MaxHangingMailSends {
 		return fmt.Errorf("smtp server non-responsive")
@@ -83,7 +100,7 @@ func (rm rMailer) Send(ctx context.Context, to []string, subject string, content
 
 }
 
-func (rm rMailer) send(ctx context.Context, to []string, subject string, contentType ContentType, body string) error {
+func (rm rMailer) send(ctx context.Context, to []string, subject string, contentType ContentType, body string, attachments []Attachment) error {
 	m := mail.NewMsg()
 
 	if err := m.From(rm.config.From); err != nil {
@@ -95,6 +112,9 @@ func (rm rMailer) send(ctx context.Context, to []string, subject string, content
 
 	m.Subject(subject)
 	m.SetBodyString(mail.ContentType(contentType), body)
+	for _, a := range attachments {
+		m.AttachReader(a.Name, bytes.NewReader(a.Content))
+	}
 
 	client, err := rm.buildClient()
 	if err != nil {
@@ -138,11 +158,11 @@ func (rm rMailer) buildClient() (*mail.Client, error) {
 	return client, err
 }
 
-func (rm rMailer) enqueueSend(ctx context.Context, to []string, subject string, contentType ContentType, body string) chan error {
+func (rm rMailer) enqueueSend(ctx context.Context, to []string, subject string, contentType ContentType, body string, attachments []Attachment) chan error {
 	done := make(chan error, 1)
 	go func() {
 		rm.doomQueue <- struct{}{}
-		done <- rm.send(ctx, to, subject, contentType, body)
+		done <- rm.send(ctx, to, subject, contentType, body, attachments)
 		close(done)
 		<-rm.doomQueue
 	}()
@@ -155,7 +175,7 @@ type AuthUserPass struct {
 	Pass string
 }
 
-func NewRMailer(config Config, l *logger.Logger) Mailer {
+func NewRMailer(config Config, l *logger.Logger) AttachmentMailer {
 	return rMailer{
 		config:    config,
 		doomQueue: make(chan struct{}, MaxHangingMailSends),
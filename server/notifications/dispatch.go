@@ -46,6 +46,8 @@ func FigureOutTarget(target string) Target {
 	switch target {
 	case "smtp":
 		return TargetMail
+	case "webpush":
+		return TargetWebPush
 	default:
 		return TargetScript
 	}
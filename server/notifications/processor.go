@@ -23,8 +23,9 @@ type Target string
 
 const TargetMail Target = "smtp"
 const TargetScript Target = "script"
+const TargetWebPush Target = "webpush"
 
-var AllTargets = []Target{TargetMail, TargetScript}
+var AllTargets = []Target{TargetMail, TargetScript, TargetWebPush}
 
 func (t Target) Valid() bool {
 	for _, target := range AllTargets {
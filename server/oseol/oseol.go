@@ -0,0 +1,147 @@
+package oseol
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+//go:embed eol.json
+var defaultEntries []byte
+
+// Entry is an end-of-life date of an operating system. Clients match if their os_full_name matches the OSFullName
+// regex and their os_version matches the OSVersion regex, an empty OSVersion matches any version.
+type Entry struct {
+	OSFullName string `json:"os_full_name"`
+	OSVersion  string `json:"os_version"`
+	EOL        string `json:"eol"`
+}
+
+type entry struct {
+	osFullName *regexp.Regexp
+	osVersion  *regexp.Regexp
+	eol        time.Time
+}
+
+func (e entry) matches(osFullName, osVersion string) bool {
+	if !e.osFullName.MatchString(osFullName) {
+		return false
+	}
+	return e.osVersion == nil || e.osVersion.MatchString(osVersion)
+}
+
+// Dataset holds the end-of-life dates shipped with the server, optionally extended by a file.
+// Entries of the file take precedence over the shipped ones, so they can be used to correct or add dates.
+type Dataset struct {
+	file string
+
+	mu          sync.RWMutex
+	entries     []entry
+	fileModTime time.Time
+}
+
+// NewDataset returns the shipped dataset extended by the entries of the given file, if not empty.
+func NewDataset(file string) (*Dataset, error) {
+	d := &Dataset{
+		file: file,
+	}
+	if _, err := d.Reload(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Reload reads the file again if it was modified since it was read last. It returns true if the dataset changed.
+func (d *Dataset) Reload() (bool, error) {
+	defaults, err := parse(defaultEntries)
+	if err != nil {
+		return false, fmt.Errorf("invalid shipped os end-of-life dates: %v", err)
+	}
+	if d.file == "" {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		changed := d.entries == nil
+		d.entries = defaults
+		return changed, nil
+	}
+
+	info, err := os.Stat(d.file)
+	if err != nil {
+		return false, fmt.Errorf("failed to read os end-of-life dates: %v", err)
+	}
+	d.mu.RLock()
+	modified := !info.ModTime().Equal(d.fileModTime)
+	d.mu.RUnlock()
+	if !modified {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(d.file)
+	if err != nil {
+		return false, fmt.Errorf("failed to read os end-of-life dates: %v", err)
+	}
+	custom, err := parse(data)
+	if err != nil {
+		return false, fmt.Errorf("invalid os end-of-life dates in %s: %v", d.file, err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.entries = append(custom, defaults...)
+	d.fileModTime = info.ModTime()
+	return true, nil
+}
+
+// Lookup returns the end-of-life date of the first entry matching the given os, nil if there is none.
+func (d *Dataset) Lookup(osFullName, osVersion string) *time.Time {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, e := range d.entries {
+		if e.matches(osFullName, osVersion) {
+			eol := e.eol
+			return &eol
+		}
+	}
+	return nil
+}
+
+func parse(data []byte) ([]entry, error) {
+	var raw []Entry
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	entries := make([]entry, 0, len(raw))
+	for i, r := range raw {
+		if r.OSFullName == "" {
+			return nil, fmt.Errorf("entry %d: os_full_name is required", i)
+		}
+		osFullName, err := regexp.Compile(r.OSFullName)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: invalid os_full_name: %v", i, err)
+		}
+		var osVersion *regexp.Regexp
+		if r.OSVersion != "" {
+			osVersion, err = regexp.Compile(r.OSVersion)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: invalid os_version: %v", i, err)
+			}
+		}
+		eol, err := time.Parse(dateLayout, r.EOL)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: invalid eol date %q, expected YYYY-MM-DD", i, r.EOL)
+		}
+		entries = append(entries, entry{
+			osFullName: osFullName,
+			osVersion:  osVersion,
+			eol:        eol,
+		})
+	}
+	return entries, nil
+}
@@ -0,0 +1,75 @@
+package oseol
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) *time.Time {
+	d, _ := time.Parse(dateLayout, s)
+	return &d
+}
+
+func TestLookup(t *testing.T) {
+	d, err := NewDataset("")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		osFullName string
+		osVersion  string
+		expected   *time.Time
+	}{
+		{"Ubuntu 18.04", "18.04", date("2023-05-31")},
+		{"Ubuntu 22.04", "22.04", date("2027-06-01")},
+		{"Debian 10.13", "10.13", date("2022-09-10")},
+		{"Debian 1.0", "1.0", nil},
+		{"Centos 7.9.2009", "7.9.2009", date("2024-06-30")},
+		{"Microsoft Windows Server 2016 Standard", "10.0.14393 Build 14393", date("2027-01-12")},
+		{"Microsoft Windows 10 Pro", "10.0.19045 Build 19045", date("2025-10-14")},
+		{"Alpine 3.14.0", "3.14.0", nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.osFullName, func(t *testing.T) {
+			assert.Equal(t, tc.expected, d.Lookup(tc.osFullName, tc.osVersion))
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "eol.json")
+	require.NoError(t, os.WriteFile(file, []byte(`[{"os_full_name": "(?i)^ubuntu", "os_version": "^18\\.04", "eol": "2028-04-30"}]`), 0600))
+
+	d, err := NewDataset(file)
+	require.NoError(t, err)
+	// entries of the file take precedence
+	assert.Equal(t, date("2028-04-30"), d.Lookup("Ubuntu 18.04", "18.04"))
+	assert.Equal(t, date("2025-05-31"), d.Lookup("Ubuntu 20.04", "20.04"))
+
+	changed, err := d.Reload()
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	require.NoError(t, os.WriteFile(file, []byte(`[{"os_full_name": "^Alpine", "eol": "2024-05-01"}]`), 0600))
+	require.NoError(t, os.Chtimes(file, time.Now(), time.Now().Add(time.Minute)))
+	changed, err = d.Reload()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, date("2024-05-01"), d.Lookup("Alpine 3.14.0", "3.14.0"))
+	assert.Equal(t, date("2023-05-31"), d.Lookup("Ubuntu 18.04", "18.04"))
+}
+
+func TestNewDatasetInvalidFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "eol.json")
+	require.NoError(t, os.WriteFile(file, []byte(`[{"os_full_name": "Ubuntu", "eol": "April 2028"}]`), 0600))
+
+	_, err := NewDataset(file)
+	assert.EqualError(t, err, "invalid os end-of-life dates in "+file+`: entry 0: invalid eol date "April 2028", expected YYYY-MM-DD`)
+
+	_, err = NewDataset(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
@@ -50,20 +50,40 @@ func (d *PortDistributor) GetRandomPort(protocol string) (int, error) {
 		}
 	}
 
-	port := d.getPool(protocol).Pop()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	port := d.pool(protocol).Pop()
 	if port == nil {
 		return 0, fmt.Errorf("no ports available")
 	}
 
 	// Make sure port is removed from all pools for tcp+udp protocol
 	for _, p := range subProtocols {
-		pool := d.getPoolFromMap(p)
-		pool.Remove(port)
+		d.portsPools[p].Remove(port)
 	}
 
 	return port.(int), nil
 }
 
+// Release returns the port of a closed tunnel to the pools, so it can be distributed again without waiting for the
+// next refresh.
+func (d *PortDistributor) Release(protocol string, port int) {
+	if !d.IsPortAllowed(port) {
+		return
+	}
+	subProtocols := []string{protocol}
+	if protocol == models.ProtocolTCPUDP {
+		subProtocols = []string{models.ProtocolTCP, models.ProtocolUDP}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, p := range subProtocols {
+		if pool := d.portsPools[p]; pool != nil {
+			pool.Add(port)
+		}
+	}
+}
+
 func (d *PortDistributor) IsPortAllowed(port int) bool {
 	return d.allowedPorts.Contains(port)
 }
@@ -73,11 +93,17 @@ func (d *PortDistributor) IsPortBusy(protocol string, port int) bool {
 }
 
 func (d *PortDistributor) getPool(protocol string) mapset.Set {
-	pool := d.getPoolFromMap(protocol)
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.pool(protocol)
+}
+
+// pool returns the pool of the protocol, d.mu must be held.
+func (d *PortDistributor) pool(protocol string) mapset.Set {
 	if protocol == models.ProtocolTCPUDP {
-		pool = d.portsPools[models.ProtocolTCP].Intersect(d.portsPools[models.ProtocolUDP])
+		return d.portsPools[models.ProtocolTCP].Intersect(d.portsPools[models.ProtocolUDP])
 	}
-	return pool
+	return d.portsPools[protocol]
 }
 
 func (d *PortDistributor) Refresh() error {
@@ -133,3 +159,13 @@ func (d *PortDistributor) setPool(protocol string, pool mapset.Set) {
 	d.portsPools[protocol] = pool
 	d.mu.Unlock()
 }
+
+// Usage returns the number of allowed ports and how many of them are currently in use for a given protocol.
+func (d *PortDistributor) Usage(protocol string) (total, used int, err error) {
+	busyPorts, err := ListBusyPorts(protocol)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return d.allowedPorts.Cardinality(), d.allowedPorts.Intersect(busyPorts).Cardinality(), nil
+}
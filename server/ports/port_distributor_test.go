@@ -1,6 +1,7 @@
 package ports
 
 import (
+	"sync"
 	"testing"
 
 	mapset "github.com/deckarep/golang-set"
@@ -30,3 +31,64 @@ func TestPortDistributor(t *testing.T) {
 		})
 	}
 }
+
+func TestPortDistributorRelease(t *testing.T) {
+	for _, protocol := range []string{models.ProtocolTCP, models.ProtocolUDP, models.ProtocolTCPUDP} {
+		t.Run(protocol, func(t *testing.T) {
+			pd := NewPortDistributorForTests(
+				mapset.NewSetFromSlice([]interface{}{1, 2, 3}),
+				mapset.NewSetFromSlice([]interface{}{2}),
+				mapset.NewSetFromSlice([]interface{}{2}),
+			)
+
+			port, err := pd.GetRandomPort(protocol)
+			require.NoError(t, err)
+			_, err = pd.GetRandomPort(protocol)
+			require.EqualError(t, err, "no ports available")
+
+			pd.Release(protocol, port)
+			pd.Release(protocol, 10)
+
+			assert.Equal(t, false, pd.IsPortBusy(protocol, port))
+			assert.Equal(t, true, pd.IsPortBusy(protocol, 10))
+		})
+	}
+}
+
+func TestPortDistributorConcurrentRelease(t *testing.T) {
+	allowed := mapset.NewSet()
+	for port := 29000; port < 29100; port++ {
+		allowed.Add(port)
+	}
+	pd := NewPortDistributorForTests(allowed, allowed.Clone(), allowed.Clone())
+
+	// run with -race, Refresh replaces the pools while ports are distributed and released
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		protocol := []string{models.ProtocolTCP, models.ProtocolUDP, models.ProtocolTCPUDP}[i%3]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				port, err := pd.GetRandomPort(protocol)
+				if err != nil {
+					continue
+				}
+				pd.IsPortBusy(protocol, port)
+				pd.Release(protocol, port)
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 3; j++ {
+			assert.NoError(t, pd.Refresh())
+		}
+	}()
+	wg.Wait()
+
+	for _, port := range allowed.ToSlice() {
+		assert.False(t, pd.IsPortBusy(models.ProtocolTCPUDP, port.(int)), "port %d not released", port)
+	}
+}
@@ -0,0 +1,188 @@
+package preconnect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	chshare "github.com/realvnc-labs/rport/share"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+const (
+	OnErrorAllow  = "allow"
+	OnErrorReject = "reject"
+
+	DefaultTimeout = 5 * time.Second
+)
+
+// Config defines a local executable run by the server before a client is connected. The script decides if the
+// connection is accepted and can rewrite the tags and add allowed user groups of the client.
+type Config struct {
+	Script  string        `mapstructure:"pre_connect_script"`
+	Timeout time.Duration `mapstructure:"pre_connect_timeout"`
+	// OnError is either "allow" to accept or "reject" to deny the connection if the script fails.
+	OnError string `mapstructure:"pre_connect_on_error"`
+}
+
+func (c *Config) Validate() error {
+	if c.Timeout < 0 {
+		return errors.New("pre_connect_timeout cannot be negative")
+	}
+	if c.Timeout == 0 {
+		c.Timeout = DefaultTimeout
+	}
+
+	switch c.OnError {
+	case "":
+		c.OnError = OnErrorAllow
+	case OnErrorAllow, OnErrorReject:
+	default:
+		return fmt.Errorf("invalid pre_connect_on_error %q, expected one of: %s, %s", c.OnError, OnErrorAllow, OnErrorReject)
+	}
+	return nil
+}
+
+// Client is passed as json on stdin to the script. The main fields are also set as RPORT_* environment variables.
+type Client struct {
+	ID           string            `json:"id"`
+	ClientAuthID string            `json:"client_auth_id"`
+	Address      string            `json:"address"`
+	Name         string            `json:"name"`
+	Hostname     string            `json:"hostname"`
+	Version      string            `json:"version"`
+	OS           string            `json:"os"`
+	OSFullName   string            `json:"os_full_name"`
+	OSVersion    string            `json:"os_version"`
+	OSArch       string            `json:"os_arch"`
+	OSFamily     string            `json:"os_family"`
+	OSKernel     string            `json:"os_kernel"`
+	IPv4         []string          `json:"ipv4"`
+	IPv6         []string          `json:"ipv6"`
+	Tags         []string          `json:"tags"`
+	Labels       map[string]string `json:"labels"`
+	Mode         string            `json:"mode"`
+}
+
+func NewClient(clientID, clientAuthID, address string, req *chshare.ConnectionRequest) *Client {
+	return &Client{
+		ID:           clientID,
+		ClientAuthID: clientAuthID,
+		Address:      address,
+		Name:         req.Name,
+		Hostname:     req.Hostname,
+		Version:      req.Version,
+		OS:           req.OS,
+		OSFullName:   req.OSFullName,
+		OSVersion:    req.OSVersion,
+		OSArch:       req.OSArch,
+		OSFamily:     req.OSFamily,
+		OSKernel:     req.OSKernel,
+		IPv4:         req.IPv4,
+		IPv6:         req.IPv6,
+		Tags:         req.Tags,
+		Labels:       req.Labels,
+		Mode:         req.Mode,
+	}
+}
+
+func (c *Client) env() []string {
+	return []string{
+		"RPORT_CLIENT_ID=" + c.ID,
+		"RPORT_CLIENT_AUTH_ID=" + c.ClientAuthID,
+		"RPORT_CLIENT_ADDRESS=" + c.Address,
+		"RPORT_CLIENT_NAME=" + c.Name,
+		"RPORT_CLIENT_HOSTNAME=" + c.Hostname,
+		"RPORT_CLIENT_VERSION=" + c.Version,
+		"RPORT_CLIENT_OS_FAMILY=" + c.OSFamily,
+	}
+}
+
+// Decision is read as json from stdout of the script. An empty output accepts the client unchanged.
+type Decision struct {
+	Reject bool   `json:"reject"`
+	Reason string `json:"reason"`
+	// Tags replace the tags sent by the client if not null.
+	Tags []string `json:"tags"`
+	// UserGroups are added to the allowed user groups of the client.
+	UserGroups []string `json:"user_groups"`
+}
+
+type Script struct {
+	config Config
+	logger *logger.Logger
+}
+
+func New(config Config, logger *logger.Logger) *Script {
+	return &Script{
+		config: config,
+		logger: logger,
+	}
+}
+
+// Evaluate runs the script for the connecting client. Returns an error if the client is rejected, either by the
+// script or because the script failed and pre_connect_on_error is "reject".
+func (s *Script) Evaluate(ctx context.Context, client *Client) (*Decision, error) {
+	if s == nil {
+		return &Decision{}, nil
+	}
+
+	decision, err := s.run(ctx, client)
+	if err != nil {
+		s.logger.Errorf("pre-connect script %s failed for client %s: %v", s.config.Script, client.ID, err)
+		if s.config.OnError == OnErrorReject {
+			return nil, errors.New("connection rejected: pre-connect script failed")
+		}
+		return &Decision{}, nil
+	}
+
+	if decision.Reject {
+		s.logger.Infof("pre-connect script rejected client %s: %s", client.ID, decision.Reason)
+		if decision.Reason != "" {
+			return nil, fmt.Errorf("connection rejected: %s", decision.Reason)
+		}
+		return nil, errors.New("connection rejected")
+	}
+	return decision, nil
+}
+
+func (s *Script) run(ctx context.Context, client *Client) (*Decision, error) {
+	payload, err := json.Marshal(client)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.config.Script)
+	cmd.Env = append(os.Environ(), client.env()...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("timeout of %s exceeded", s.config.Timeout)
+	}
+	if err != nil {
+		if stderr.Len() > 0 {
+			return nil, fmt.Errorf("%v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+		}
+		return nil, err
+	}
+
+	decision := &Decision{}
+	if out := bytes.TrimSpace(stdout.Bytes()); len(out) > 0 {
+		if err := json.Unmarshal(out, decision); err != nil {
+			return nil, fmt.Errorf("invalid output: %v", err)
+		}
+	}
+	return decision, nil
+}
@@ -0,0 +1,115 @@
+//go:build !windows
+// +build !windows
+
+package preconnect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+var testLog = logger.NewLogger("pre-connect", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+
+func writeScript(t *testing.T, content string) string {
+	script := filepath.Join(t.TempDir(), "pre-connect.sh")
+	err := os.WriteFile(script, []byte("#!/bin/sh\n"+content), 0700)
+	require.NoError(t, err)
+	return script
+}
+
+func TestEvaluate(t *testing.T) {
+	client := &Client{ID: "client-1", Version: "0.9.0", Tags: []string{"linux"}}
+
+	testCases := []struct {
+		name             string
+		script           string
+		onError          string
+		expectedDecision *Decision
+		expectedError    string
+	}{
+		{
+			name:             "accept unchanged",
+			script:           "cat > /dev/null\n",
+			expectedDecision: &Decision{},
+		},
+		{
+			name:             "rewrite tags and add user groups",
+			script:           `echo '{"tags": ["linux", "version-'$RPORT_CLIENT_VERSION'"], "user_groups": ["support"]}'` + "\n",
+			expectedDecision: &Decision{Tags: []string{"linux", "version-0.9.0"}, UserGroups: []string{"support"}},
+		},
+		{
+			name:          "reject",
+			script:        `grep -q '"version":"0.9.0"' && echo '{"reject": true, "reason": "unsupported version"}'` + "\n",
+			expectedError: "connection rejected: unsupported version",
+		},
+		{
+			name:             "failure allowed",
+			script:           "exit 1\n",
+			onError:          OnErrorAllow,
+			expectedDecision: &Decision{},
+		},
+		{
+			name:          "failure rejected",
+			script:        "exit 1\n",
+			onError:       OnErrorReject,
+			expectedError: "connection rejected: pre-connect script failed",
+		},
+		{
+			name:          "invalid output rejected",
+			script:        "echo rejected\n",
+			onError:       OnErrorReject,
+			expectedError: "connection rejected: pre-connect script failed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := Config{Script: writeScript(t, tc.script), OnError: tc.onError}
+			require.NoError(t, config.Validate())
+
+			decision, err := New(config, testLog).Evaluate(context.Background(), client)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedDecision, decision)
+		})
+	}
+}
+
+func TestEvaluateTimeout(t *testing.T) {
+	config := Config{Script: writeScript(t, "exec sleep 5\n"), Timeout: 100 * time.Millisecond, OnError: OnErrorReject}
+	require.NoError(t, config.Validate())
+
+	_, err := New(config, testLog).Evaluate(context.Background(), &Client{ID: "client-1"})
+
+	assert.EqualError(t, err, "connection rejected: pre-connect script failed")
+}
+
+func TestEvaluateNilScript(t *testing.T) {
+	var s *Script
+
+	decision, err := s.Evaluate(context.Background(), &Client{ID: "client-1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, &Decision{}, decision)
+}
+
+func TestValidate(t *testing.T) {
+	config := Config{Script: "/usr/local/bin/pre-connect.sh"}
+	require.NoError(t, config.Validate())
+	assert.Equal(t, DefaultTimeout, config.Timeout)
+	assert.Equal(t, OnErrorAllow, config.OnError)
+
+	config.OnError = "ignore"
+	assert.EqualError(t, config.Validate(), `invalid pre_connect_on_error "ignore", expected one of: allow, reject`)
+}
@@ -0,0 +1,144 @@
+package redaction
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+// maxPendingBytes is the max size of an incomplete line kept by a stream until the rest of the line arrives.
+const maxPendingBytes = 64 * 1024
+
+type compiledRule struct {
+	re          *regexp.Regexp
+	replacement string
+	valid       func(match string) bool
+}
+
+// Redactor applies the redaction rules. A nil Redactor redacts nothing.
+type Redactor struct {
+	rules []compiledRule
+}
+
+// NewRedactor returns nil if no rules are given.
+func NewRedactor(rules []Rule) (*Redactor, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	if err := ValidateRules(rules); err != nil {
+		return nil, err
+	}
+
+	r := &Redactor{}
+	for _, rule := range rules {
+		if rule.Preset != "" {
+			p := presets[rule.Preset]
+			for _, pp := range p.patterns {
+				replacement := pp.replacement
+				if rule.Replacement != "" {
+					replacement = rule.Replacement
+				}
+				r.rules = append(r.rules, compiledRule{
+					re:          regexp.MustCompile(pp.pattern),
+					replacement: replacement,
+					valid:       p.valid,
+				})
+			}
+			continue
+		}
+
+		replacement := rule.Replacement
+		if replacement == "" {
+			replacement = DefaultReplacement
+		}
+		r.rules = append(r.rules, compiledRule{
+			re:          regexp.MustCompile(rule.Pattern),
+			replacement: replacement,
+		})
+	}
+	return r, nil
+}
+
+// Redact returns the text with all matches of the rules replaced and the number of replaced matches.
+func (r *Redactor) Redact(text string) (string, int) {
+	if r == nil || text == "" {
+		return text, 0
+	}
+
+	count := 0
+	for _, rule := range r.rules {
+		matches := rule.re.FindAllStringSubmatchIndex(text, -1)
+		if len(matches) == 0 {
+			continue
+		}
+
+		var b strings.Builder
+		last := 0
+		for _, m := range matches {
+			if rule.valid != nil && !rule.valid(text[m[0]:m[1]]) {
+				continue
+			}
+			b.WriteString(text[last:m[0]])
+			b.Write(rule.re.ExpandString(nil, rule.replacement, text, m))
+			last = m[1]
+			count++
+		}
+		b.WriteString(text[last:])
+		text = b.String()
+	}
+	return text, count
+}
+
+// RedactJob redacts the output of the job and records the number of redactions on the job.
+func (r *Redactor) RedactJob(job *models.Job) {
+	if r == nil || job.Result == nil {
+		return
+	}
+
+	var stdOutCount, stdErrCount, summaryCount int
+	job.Result.StdOut, stdOutCount = r.Redact(job.Result.StdOut)
+	job.Result.StdErr, stdErrCount = r.Redact(job.Result.StdErr)
+	job.Result.Summary, summaryCount = r.Redact(job.Result.Summary)
+	job.RedactionCount = stdOutCount + stdErrCount + summaryCount
+}
+
+// Stream redacts output received in chunks. A match can span chunks, so only complete lines are redacted and
+// returned, the rest is kept until the next chunk or Flush.
+type Stream struct {
+	redactor *Redactor
+	pending  string
+}
+
+func (r *Redactor) NewStream() *Stream {
+	return &Stream{redactor: r}
+}
+
+// Write returns the redacted complete lines of the output received so far, an empty string if there are none.
+func (s *Stream) Write(chunk string) string {
+	if s.redactor == nil {
+		return chunk
+	}
+
+	s.pending += chunk
+	i := strings.LastIndexByte(s.pending, '\n')
+	if i < 0 {
+		if len(s.pending) < maxPendingBytes {
+			return ""
+		}
+		// a line that long is flushed as is, matches spanning the cut are not redacted
+		return s.Flush()
+	}
+
+	lines := s.pending[:i+1]
+	s.pending = s.pending[i+1:]
+	redacted, _ := s.redactor.Redact(lines)
+	return redacted
+}
+
+// Flush returns the redacted rest of the output.
+func (s *Stream) Flush() string {
+	redacted, _ := s.redactor.Redact(s.pending)
+	s.pending = ""
+	return redacted
+}
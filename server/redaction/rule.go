@@ -0,0 +1,113 @@
+// Package redaction replaces sensitive data like credit card numbers, passwords and tokens in the output of commands
+// and scripts before it's stored or sent to API consumers.
+package redaction
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const DefaultReplacement = "[REDACTED]"
+
+// Rule redacts the matches of a regular expression or of a built-in preset.
+type Rule struct {
+	Preset string `mapstructure:"preset"`
+	// Pattern is a regular expression as supported by Go, submatches can be referenced in the replacement, e.g. ${1}.
+	Pattern     string `mapstructure:"pattern"`
+	Replacement string `mapstructure:"replacement"`
+}
+
+type presetPattern struct {
+	pattern     string
+	replacement string
+}
+
+type preset struct {
+	patterns []presetPattern
+	// valid filters out matches that are no sensitive data, e.g. numbers that are no credit card numbers.
+	valid func(match string) bool
+}
+
+var presets = map[string]preset{
+	"credit_card": {
+		patterns: []presetPattern{
+			{pattern: `\b(?:\d[ -]?){12,18}\d\b`, replacement: DefaultReplacement},
+		},
+		valid: luhnValid,
+	},
+	"password": {
+		patterns: []presetPattern{
+			{pattern: `(?i)\b((?:password|passwd|pwd)\s*[:=]\s*)\S+`, replacement: "${1}" + DefaultReplacement},
+		},
+	},
+	"token": {
+		patterns: []presetPattern{
+			{pattern: `(?i)\b(bearer\s+)[A-Za-z0-9\-._~+/]{8,}=*`, replacement: "${1}" + DefaultReplacement},
+			{pattern: `(?i)\b((?:api[_-]?key|access[_-]?key|secret[_-]?key|secret|token)\s*[:=]\s*)\S+`, replacement: "${1}" + DefaultReplacement},
+			{pattern: `\bAKIA[0-9A-Z]{16}\b`, replacement: DefaultReplacement},
+		},
+	},
+}
+
+// Presets returns the names of the built-in rules.
+func Presets() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *Rule) Validate() error {
+	if r.Preset == "" && r.Pattern == "" {
+		return errors.New("either preset or pattern is required")
+	}
+	if r.Preset != "" && r.Pattern != "" {
+		return errors.New("preset and pattern cannot be used together")
+	}
+	if r.Preset != "" {
+		if _, ok := presets[r.Preset]; !ok {
+			return fmt.Errorf("unknown preset %q, supported: %s", r.Preset, strings.Join(Presets(), ", "))
+		}
+		return nil
+	}
+	if _, err := regexp.Compile(r.Pattern); err != nil {
+		return fmt.Errorf("invalid pattern: %v", err)
+	}
+	return nil
+}
+
+func ValidateRules(rules []Rule) error {
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return fmt.Errorf("invalid redaction rule %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// luhnValid returns true if the digits of the match have a valid Luhn checksum as credit card numbers have.
+func luhnValid(match string) bool {
+	sum := 0
+	double := false
+	for i := len(match) - 1; i >= 0; i-- {
+		c := match[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
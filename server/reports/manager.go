@@ -0,0 +1,317 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	cron "github.com/robfig/cron/v3"
+
+	"github.com/realvnc-labs/rport/server/api"
+	apiErrors "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/i18n"
+	"github.com/realvnc-labs/rport/server/notifications/channels/rmailer"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/query"
+	"github.com/realvnc-labs/rport/share/random"
+)
+
+// KeepRuns is the number of generated reports kept per report for the download.
+const KeepRuns = 10
+
+var (
+	supportedFilters = map[string]bool{
+		"name":       true,
+		"type":       true,
+		"format":     true,
+		"created_by": true,
+	}
+	supportedSorts = map[string]bool{
+		"created_at": true,
+		"name":       true,
+		"type":       true,
+	}
+)
+
+// Mailer sends the generated reports by email.
+type Mailer interface {
+	SendWithAttachments(ctx context.Context, to []string, subject string, contentType rmailer.ContentType, body string, attachments ...rmailer.Attachment) error
+}
+
+type Manager struct {
+	provider  *SQLiteProvider
+	source    Source
+	mailer    Mailer
+	localizer i18n.Localizer
+	logger    *logger.Logger
+	now       func() time.Time
+
+	cron    *cron.Cron
+	entries map[string]cron.EntryID
+	mu      sync.Mutex
+}
+
+// New returns a manager with the existing reports scheduled.
+func New(ctx context.Context, provider *SQLiteProvider, source Source, logger *logger.Logger) (*Manager, error) {
+	m := &Manager{
+		provider: provider,
+		source:   source,
+		logger:   logger,
+		now:      time.Now,
+		cron:     cron.New(cron.WithParser(cronParser), cron.WithChain(cron.Recover(cron.DefaultLogger))),
+		entries:  make(map[string]cron.EntryID),
+	}
+
+	existing, err := provider.List(ctx, &query.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range existing {
+		if err := m.schedule(r); err != nil {
+			return nil, err
+		}
+	}
+	m.cron.Start()
+
+	return m, nil
+}
+
+// SetMailer enables sending the reports by email.
+func (m *Manager) SetMailer(mailer Mailer) {
+	m.mailer = mailer
+}
+
+// SetLocalizer enables sending the reports in the locale of the user that created the report, without it english is
+// used.
+func (m *Manager) SetLocalizer(localizer i18n.Localizer) {
+	m.localizer = localizer
+}
+
+func (m *Manager) List(ctx context.Context, options *query.ListOptions) (*api.SuccessPayload, error) {
+	err := query.ValidateListOptions(options, supportedSorts, supportedFilters, nil, &query.PaginationConfig{
+		DefaultLimit: 20,
+		MaxLimit:     100,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := m.provider.List(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := m.provider.Count(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.SuccessPayload{
+		Data: entries,
+		Meta: api.NewMeta(count),
+	}, nil
+}
+
+// Get returns the report, an APIError with 404 if not found.
+func (m *Manager) Get(ctx context.Context, id string) (*Report, error) {
+	r, err := m.provider.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, apiErrors.NewAPIError(http.StatusNotFound, "", fmt.Sprintf("Report with id %q not found.", id), nil)
+	}
+	return r, nil
+}
+
+func (m *Manager) Create(ctx context.Context, r *Report) (*Report, error) {
+	if err := m.validate(r); err != nil {
+		return nil, err
+	}
+
+	id, err := random.UUID4()
+	if err != nil {
+		return nil, err
+	}
+	r.ID = id
+	r.CreatedAt = m.now().UTC()
+
+	err = m.provider.Insert(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, m.schedule(r)
+}
+
+func (m *Manager) Update(ctx context.Context, id string, r *Report) (*Report, error) {
+	existing, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.ID = id
+	r.CreatedAt = existing.CreatedAt
+	r.CreatedBy = existing.CreatedBy
+	if err := m.validate(r); err != nil {
+		return nil, err
+	}
+
+	err = m.provider.Update(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	m.unschedule(id)
+	return r, m.schedule(r)
+}
+
+func (m *Manager) Delete(ctx context.Context, id string) error {
+	if _, err := m.Get(ctx, id); err != nil {
+		return err
+	}
+	m.unschedule(id)
+	return m.provider.Delete(ctx, id)
+}
+
+// Run generates the report, stores it for the download and sends it to the recipients.
+// A run failing to send the email is stored as failed including the content.
+func (m *Manager) Run(ctx context.Context, id, trigger string) (*Run, error) {
+	r, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	runID, err := random.UUID4()
+	if err != nil {
+		return nil, err
+	}
+	run := &Run{
+		ID:        runID,
+		ReportID:  r.ID,
+		StartedAt: m.now().UTC(),
+		Trigger:   trigger,
+		Status:    RunStatusSuccessful,
+		Format:    r.Format,
+	}
+
+	if err := m.generate(ctx, r, run); err != nil {
+		run.Status = RunStatusFailed
+		run.Error = err.Error()
+		m.logger.Errorf("Report %q (%s): %v", r.Name, r.ID, err)
+	}
+	finishedAt := m.now().UTC()
+	run.FinishedAt = &finishedAt
+
+	if err := m.provider.InsertRun(ctx, run, KeepRuns); err != nil {
+		return nil, fmt.Errorf("failed to save report run: %w", err)
+	}
+
+	return run, nil
+}
+
+func (m *Manager) generate(ctx context.Context, r *Report, run *Run) error {
+	table, err := buildTable(ctx, m.source, r, run.StartedAt)
+	if err != nil {
+		return err
+	}
+	run.Rows = len(table.Rows)
+
+	run.Content, err = render(table, r.Format)
+	if err != nil {
+		return err
+	}
+
+	if len(r.Details.Recipients) == 0 {
+		return nil
+	}
+	if m.mailer == nil {
+		return errors.New("failed to send report: SMTP is not configured")
+	}
+	locale := i18n.DefaultLocale
+	if m.localizer != nil {
+		locale = m.localizer.UserLocale(ctx, r.CreatedBy)
+	}
+	body := i18n.Sprintf(locale, "The report %q generated at %s is attached, it contains %d row(s).", r.Name, run.StartedAt.Format(time.RFC1123), run.Rows)
+	err = m.mailer.SendWithAttachments(ctx, r.Details.Recipients, i18n.Sprintf(locale, "RPort report: %s", r.Name), rmailer.ContentTypeTextPlain, body, rmailer.Attachment{
+		Name:    run.Filename(r),
+		Content: run.Content,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send report: %w", err)
+	}
+	return nil
+}
+
+// ListRuns returns the stored runs of the report, the latest first.
+func (m *Manager) ListRuns(ctx context.Context, id string) ([]*Run, error) {
+	if _, err := m.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	return m.provider.ListRuns(ctx, id)
+}
+
+// GetRun returns the run including the content, an APIError with 404 if not found.
+func (m *Manager) GetRun(ctx context.Context, id, runID string) (*Report, *Run, error) {
+	r, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	run, err := m.provider.GetRun(ctx, id, runID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if run == nil {
+		return nil, nil, apiErrors.NewAPIError(http.StatusNotFound, "", fmt.Sprintf("Run with id %q of report %q not found.", runID, id), nil)
+	}
+	return r, run, nil
+}
+
+func (m *Manager) Close() error {
+	<-m.cron.Stop().Done()
+	return m.provider.Close()
+}
+
+func (m *Manager) validate(r *Report) error {
+	if err := r.Validate(); err != nil {
+		return apiErrors.NewAPIError(http.StatusBadRequest, "", err.Error(), nil)
+	}
+	if len(r.Details.Recipients) > 0 && m.mailer == nil {
+		return apiErrors.NewAPIError(http.StatusBadRequest, "", "Reports can't be sent by email, SMTP is not configured.", nil)
+	}
+	return nil
+}
+
+func (m *Manager) schedule(r *Report) error {
+	if r.Schedule == "" {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := r.ID
+	entryID, err := m.cron.AddFunc(r.Schedule, func() {
+		if _, err := m.Run(context.Background(), id, TriggerSchedule); err != nil {
+			m.logger.Errorf("Failed to run scheduled report %s: %v", id, err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule report %s: %w", id, err)
+	}
+	m.entries[id] = entryID
+	return nil
+}
+
+func (m *Manager) unschedule(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entryID, ok := m.entries[id]; ok {
+		m.cron.Remove(entryID)
+		delete(m.entries, id)
+	}
+}
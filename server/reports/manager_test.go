@@ -0,0 +1,287 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/db/migration/reports"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/api/jobs"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/notifications/channels/rmailer"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+var testLog = logger.NewLogger("reports-test", logger.LogOutput{File: nil}, logger.LogLevelDebug)
+
+type fakeSource struct {
+	clients  []*clientdata.CalculatedClient
+	jobStats []*jobs.JobStats
+	filters  []query.FilterOption
+}
+
+func (s *fakeSource) Clients(ctx context.Context, filters []query.FilterOption) ([]*clientdata.CalculatedClient, error) {
+	s.filters = filters
+	return s.clients, nil
+}
+
+func (s *fakeSource) JobStats(ctx context.Context, since time.Time) ([]*jobs.JobStats, error) {
+	return s.jobStats, nil
+}
+
+type fakeMailer struct {
+	to          []string
+	subject     string
+	attachments []rmailer.Attachment
+	err         error
+}
+
+func (m *fakeMailer) SendWithAttachments(ctx context.Context, to []string, subject string, contentType rmailer.ContentType, body string, attachments ...rmailer.Attachment) error {
+	m.to = to
+	m.subject = subject
+	m.attachments = attachments
+	return m.err
+}
+
+func newTestManager(t *testing.T, source Source) *Manager {
+	db, err := sqlite.New(":memory:", reports.AssetNames(), reports.Asset, sqlite.DataSourceOptions{})
+	require.NoError(t, err)
+	m, err := New(context.Background(), NewSQLiteProvider(db), source, testLog)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = m.Close()
+	})
+	return m
+}
+
+func testClients() []*clientdata.CalculatedClient {
+	refreshed := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	return []*clientdata.CalculatedClient{
+		{
+			Client: &clientdata.Client{
+				ID:         "client-2",
+				Name:       "web-2",
+				OSFullName: "Ubuntu 22.04",
+				UpdatesStatus: &models.UpdatesStatus{
+					Refreshed:                refreshed,
+					UpdatesAvailable:         3,
+					SecurityUpdatesAvailable: 1,
+				},
+			},
+			ConnectionState: clientdata.Disconnected,
+		},
+		{
+			Client: &clientdata.Client{
+				ID:         "client-1",
+				Name:       "web-1",
+				OSFullName: "Ubuntu 22.04",
+				IPv4:       []string{"192.168.1.1", "10.0.0.1"},
+				Tags:       []string{"prod", "web"},
+				UpdatesStatus: &models.UpdatesStatus{
+					Refreshed: refreshed,
+				},
+			},
+			ConnectionState: clientdata.Connected,
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	r := &Report{Name: " Inventory ", Type: TypeInventory}
+	require.NoError(t, r.Validate())
+	assert.Equal(t, "Inventory", r.Name)
+	assert.Equal(t, FormatCSV, r.Format)
+	assert.Equal(t, DefaultPeriodDays, r.Details.PeriodDays)
+
+	testCases := []struct {
+		report        Report
+		expectedError string
+	}{
+		{
+			report:        Report{Type: TypeInventory},
+			expectedError: "name is required",
+		},
+		{
+			report:        Report{Name: "r", Type: "traffic"},
+			expectedError: `invalid type "traffic", expected one of: inventory, availability, updates, jobs`,
+		},
+		{
+			report:        Report{Name: "r", Type: TypeJobs, Format: "xlsx"},
+			expectedError: `invalid format "xlsx", expected one of: csv, pdf`,
+		},
+		{
+			report:        Report{Name: "r", Type: TypeJobs, Schedule: "every day"},
+			expectedError: `invalid schedule "every day": expected exactly 5 fields, found 2: [every day]`,
+		},
+		{
+			report:        Report{Name: "r", Type: TypeJobs, Details: Details{Filters: map[string][]string{"password": {"x"}}}},
+			expectedError: `unsupported filter "password"`,
+		},
+		{
+			report:        Report{Name: "r", Type: TypeJobs, Details: Details{PeriodDays: 400}},
+			expectedError: "invalid period_days 400, expected 1 to 365",
+		},
+		{
+			report:        Report{Name: "r", Type: TypeJobs, Details: Details{Recipients: []string{"admin"}}},
+			expectedError: `invalid recipient "admin": invalid email format`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expectedError, func(t *testing.T) {
+			assert.EqualError(t, tc.report.Validate(), tc.expectedError)
+		})
+	}
+}
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	source := &fakeSource{clients: testClients()}
+	m := newTestManager(t, source)
+
+	_, err := m.Create(ctx, &Report{Name: "Updates", Type: TypeUpdates, Details: Details{Recipients: []string{"admin@example.com"}}})
+	assert.EqualError(t, err, "Reports can't be sent by email, SMTP is not configured.")
+
+	mailer := &fakeMailer{}
+	m.SetMailer(mailer)
+	report, err := m.Create(ctx, &Report{
+		Name:      "Updates",
+		Type:      TypeUpdates,
+		Schedule:  "0 6 * * 1",
+		CreatedBy: "admin",
+		Details: Details{
+			Filters:    map[string][]string{"tags": {"prod"}},
+			Recipients: []string{"admin@example.com"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Len(t, m.entries, 1)
+
+	list, err := m.List(ctx, &query.ListOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, list.Meta.Count)
+
+	run, err := m.Run(ctx, report.ID, TriggerManual)
+	require.NoError(t, err)
+	assert.Equal(t, RunStatusSuccessful, run.Status)
+	assert.Equal(t, 2, run.Rows)
+	assert.Equal(t, []query.FilterOption{{Column: []string{"tags"}, Values: []string{"prod"}}}, source.filters)
+	assert.Equal(t, []string{"admin@example.com"}, mailer.to)
+	assert.Equal(t, "RPort report: Updates", mailer.subject)
+	require.Len(t, mailer.attachments, 1)
+	assert.Equal(t, run.Filename(report), mailer.attachments[0].Name)
+	assert.Equal(t, `id,name,os,updates_available,security_updates_available,reboot_pending,refreshed,compliant
+client-1,web-1,Ubuntu 22.04,0,0,false,2026-10-01T12:00:00Z,yes
+client-2,web-2,Ubuntu 22.04,3,1,false,2026-10-01T12:00:00Z,no
+`, string(mailer.attachments[0].Content))
+
+	mailer.err = errors.New("connection refused")
+	failed, err := m.Run(ctx, report.ID, TriggerSchedule)
+	require.NoError(t, err)
+	assert.Equal(t, RunStatusFailed, failed.Status)
+	assert.Equal(t, "failed to send report: connection refused", failed.Error)
+
+	runs, err := m.ListRuns(ctx, report.ID)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Nil(t, runs[0].Content)
+
+	_, stored, err := m.GetRun(ctx, report.ID, run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, run.Content, stored.Content)
+
+	report.Schedule = ""
+	_, err = m.Update(ctx, report.ID, report)
+	require.NoError(t, err)
+	assert.Len(t, m.entries, 0)
+
+	require.NoError(t, m.Delete(ctx, report.ID))
+	_, err = m.Get(ctx, report.ID)
+	assert.EqualError(t, err, `Report with id "`+report.ID+`" not found.`)
+	runs, err = m.provider.ListRuns(ctx, report.ID)
+	require.NoError(t, err)
+	assert.Len(t, runs, 0)
+}
+
+func TestKeepRuns(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t, &fakeSource{})
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time {
+		start = start.Add(time.Minute)
+		return start
+	}
+
+	report, err := m.Create(ctx, &Report{Name: "Inventory", Type: TypeInventory})
+	require.NoError(t, err)
+	var last *Run
+	for i := 0; i < KeepRuns+2; i++ {
+		last, err = m.Run(ctx, report.ID, TriggerManual)
+		require.NoError(t, err)
+	}
+
+	runs, err := m.ListRuns(ctx, report.ID)
+	require.NoError(t, err)
+	assert.Len(t, runs, KeepRuns)
+	assert.Equal(t, last.ID, runs[0].ID)
+}
+
+func TestBuildTable(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	successRate := 0.5
+	source := &fakeSource{
+		clients: testClients(),
+		jobStats: []*jobs.JobStats{
+			{ClientID: "client-1", Total: 2, Successful: 1, Failed: 1, SuccessRate: &successRate},
+		},
+	}
+	source.clients[0].Disconnects = []time.Time{now.AddDate(0, 0, -10), now.AddDate(0, 0, -1), now.Add(-time.Hour)}
+
+	table, err := buildTable(context.Background(), source, &Report{Name: "Inventory", Type: TypeInventory}, now)
+	require.NoError(t, err)
+	assert.Equal(t, "Inventory - Thu, 15 Oct 2026 12:00:00 UTC", table.Title)
+	assert.Equal(t, []string{"client-1", "web-1", "", "Ubuntu 22.04", "", "", "192.168.1.1 10.0.0.1", "", "prod web", "connected"}, table.Rows[0])
+
+	table, err = buildTable(context.Background(), source, &Report{Name: "Availability", Type: TypeAvailability, Details: Details{PeriodDays: 7}}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"client-2", "web-2", "disconnected", "", "", "2"}, table.Rows[1])
+
+	table, err = buildTable(context.Background(), source, &Report{Name: "Jobs", Type: TypeJobs, Details: Details{PeriodDays: 7}}, now)
+	require.NoError(t, err)
+	assert.Equal(t, "Jobs - Thu, 15 Oct 2026 12:00:00 UTC, last 7 day(s)", table.Title)
+	assert.Equal(t, []string{"client-1", "web-1", "2", "1", "1", "0", "0.5", "", ""}, table.Rows[0])
+	assert.Equal(t, []string{"client-2", "web-2", "0", "0", "0", "0", "", "", ""}, table.Rows[1])
+}
+
+func TestRenderPDF(t *testing.T) {
+	table := &Table{
+		Title:   "Inventory (prod)",
+		Columns: []string{"id", "name"},
+	}
+	for i := 0; i < 120; i++ {
+		table.Rows = append(table.Rows, []string{"client", "web \\ (1)"})
+	}
+
+	content, err := render(table, FormatPDF)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(content, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(content, []byte("%%EOF\n")))
+	assert.Contains(t, string(content), "/Count 3")
+	assert.Contains(t, string(content), `(Inventory \(prod\) \(page 1 of 3\)) Tj`)
+	assert.Contains(t, string(content), `(client  web \\ \(1\)) Tj T*`)
+
+	// the xref table points to the objects
+	for _, obj := range []string{"1 0 obj", "3 0 obj", "9 0 obj"} {
+		offset := bytes.Index(content, []byte(obj))
+		require.NotEqual(t, -1, offset)
+		assert.Contains(t, string(content), fmt.Sprintf("%010d 00000 n", offset))
+	}
+}
@@ -0,0 +1,167 @@
+package reports
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	cron "github.com/robfig/cron/v3"
+
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/share/email"
+)
+
+const (
+	TypeInventory    = "inventory"
+	TypeAvailability = "availability"
+	TypeUpdates      = "updates"
+	TypeJobs         = "jobs"
+
+	FormatCSV = "csv"
+	FormatPDF = "pdf"
+
+	TriggerManual   = "manual"
+	TriggerSchedule = "schedule"
+
+	RunStatusSuccessful = "successful"
+	RunStatusFailed     = "failed"
+
+	// DefaultPeriodDays is the period covered by availability and jobs reports if not given.
+	DefaultPeriodDays = 7
+	MaxPeriodDays     = 365
+)
+
+var (
+	Types   = []string{TypeInventory, TypeAvailability, TypeUpdates, TypeJobs}
+	Formats = []string{FormatCSV, FormatPDF}
+
+	cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+)
+
+// Report defines which data is reported in which format, when it's generated and who receives it.
+type Report struct {
+	ID        string    `json:"id" db:"id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	CreatedBy string    `json:"created_by" db:"created_by"`
+	Name      string    `json:"name" db:"name"`
+	Type      string    `json:"type" db:"type"`
+	Format    string    `json:"format" db:"format"`
+	// Schedule is a cron expression, if empty the report is only generated on request.
+	Schedule string  `json:"schedule" db:"schedule"`
+	Details  Details `json:"details" db:"details"`
+}
+
+type Details struct {
+	// Filters select the clients using the same filters as the client list, e.g. {"tags": ["prod*"]}.
+	Filters map[string][]string `json:"filters"`
+	// PeriodDays is the period covered by availability and jobs reports.
+	PeriodDays int `json:"period_days"`
+	// Recipients receive the generated report by email.
+	Recipients []string `json:"recipients"`
+}
+
+func (d *Details) Scan(value interface{}) error {
+	if d == nil {
+		return errors.New("'details' cannot be nil")
+	}
+	valueStr, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("expected to have string, got %T", value)
+	}
+	err := json.Unmarshal([]byte(valueStr), d)
+	if err != nil {
+		return fmt.Errorf("failed to decode 'details' field: %v", err)
+	}
+	return nil
+}
+
+func (d Details) Value() (driver.Value, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode 'details' field: %v", err)
+	}
+	return string(b), nil
+}
+
+func (r *Report) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	if !contains(Types, r.Type) {
+		return fmt.Errorf("invalid type %q, expected one of: %s", r.Type, strings.Join(Types, ", "))
+	}
+	if r.Format == "" {
+		r.Format = FormatCSV
+	}
+	if !contains(Formats, r.Format) {
+		return fmt.Errorf("invalid format %q, expected one of: %s", r.Format, strings.Join(Formats, ", "))
+	}
+	if r.Schedule != "" {
+		if _, err := cronParser.Parse(r.Schedule); err != nil {
+			return fmt.Errorf("invalid schedule %q: %v", r.Schedule, err)
+		}
+	}
+	for column := range r.Details.Filters {
+		if !clients.OptionsSupportedFilters[column] || strings.Contains(column, "[") {
+			return fmt.Errorf("unsupported filter %q", column)
+		}
+	}
+	if r.Details.PeriodDays == 0 {
+		r.Details.PeriodDays = DefaultPeriodDays
+	}
+	if r.Details.PeriodDays < 0 || r.Details.PeriodDays > MaxPeriodDays {
+		return fmt.Errorf("invalid period_days %d, expected 1 to %d", r.Details.PeriodDays, MaxPeriodDays)
+	}
+	for _, recipient := range r.Details.Recipients {
+		if err := email.Validate(recipient); err != nil {
+			return fmt.Errorf("invalid recipient %q: %v", recipient, err)
+		}
+	}
+	return nil
+}
+
+// Run is a generated report. The content is only returned by the download.
+type Run struct {
+	ID         string     `json:"id" db:"id"`
+	ReportID   string     `json:"report_id" db:"report_id"`
+	StartedAt  time.Time  `json:"started_at" db:"started_at"`
+	FinishedAt *time.Time `json:"finished_at" db:"finished_at"`
+	Trigger    string     `json:"trigger" db:"trigger"`
+	Status     string     `json:"status" db:"status"`
+	Error      string     `json:"error" db:"error"`
+	Format     string     `json:"format" db:"format"`
+	Rows       int        `json:"rows" db:"rows"`
+	Content    []byte     `json:"-" db:"content"`
+}
+
+// Filename returns the name of the downloaded or attached file.
+func (r *Run) Filename(report *Report) string {
+	name := strings.Map(func(c rune) rune {
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' {
+			return c
+		}
+		return '_'
+	}, report.Name)
+	return fmt.Sprintf("%s_%s.%s", name, r.StartedAt.Format("20060102-150405"), r.Format)
+}
+
+// ContentType returns the media type of the content.
+func (r *Run) ContentType() string {
+	if r.Format == FormatPDF {
+		return "application/pdf"
+	}
+	return "text/csv"
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package reports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+func render(table *Table, format string) ([]byte, error) {
+	switch format {
+	case FormatCSV:
+		return renderCSV(table)
+	case FormatPDF:
+		return renderPDF(table), nil
+	default:
+		return nil, fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+func renderCSV(table *Table) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	if err := w.Write(table.Columns); err != nil {
+		return nil, err
+	}
+	if err := w.WriteAll(table.Rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+const (
+	// A4 landscape in points
+	pdfPageWidth  = 842
+	pdfPageHeight = 595
+	pdfMargin     = 36
+	pdfFontSize   = 7
+	pdfLineHeight = 10
+	// pdfLineChars is the number of characters fitting a line, a Courier character is 0.6 of the font size wide.
+	// The fixed width font allows to lay out columns without font metrics.
+	pdfLineChars = (pdfPageWidth - 2*pdfMargin) * 10 / (6 * pdfFontSize)
+	pdfMaxCell   = 40
+)
+
+// renderPDF renders the table as a plain PDF with a monospaced font, one line per row.
+// Cells longer than pdfMaxCell characters are truncated and columns not fitting the page width are cut off.
+func renderPDF(table *Table) []byte {
+	widths := make([]int, len(table.Columns))
+	for i, c := range table.Columns {
+		widths[i] = utf8.RuneCountInString(c)
+	}
+	for _, row := range table.Rows {
+		for i, cell := range row {
+			if i < len(widths) && utf8.RuneCountInString(cell) > widths[i] {
+				widths[i] = utf8.RuneCountInString(cell)
+			}
+		}
+	}
+	for i := range widths {
+		if widths[i] > pdfMaxCell {
+			widths[i] = pdfMaxCell
+		}
+	}
+	formatRow := func(row []string) string {
+		b := &strings.Builder{}
+		for i, cell := range row {
+			if i >= len(widths) {
+				break
+			}
+			if i > 0 {
+				b.WriteString("  ")
+			}
+			cell = truncate(cell, widths[i])
+			b.WriteString(cell)
+			b.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)))
+		}
+		return truncate(strings.TrimRight(b.String(), " "), pdfLineChars)
+	}
+
+	header := formatRow(table.Columns)
+	// the title and the header take about four lines
+	linesPerPage := (pdfPageHeight-2*pdfMargin)/pdfLineHeight - 4
+	var pages [][]string
+	for start := 0; start < len(table.Rows) || len(pages) == 0; start += linesPerPage {
+		end := start + linesPerPage
+		if end > len(table.Rows) {
+			end = len(table.Rows)
+		}
+		lines := []string{header, strings.Repeat("-", utf8.RuneCountInString(header))}
+		for _, row := range table.Rows[start:end] {
+			lines = append(lines, formatRow(row))
+		}
+		pages = append(pages, lines)
+	}
+
+	w := &pdfWriter{}
+	w.object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, 0, len(pages))
+	for i := range pages {
+		// each page consists of the page and its content object, following the catalog, pages and font objects
+		kids = append(kids, fmt.Sprintf("%d 0 R", 4+2*i))
+	}
+	w.object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	w.object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	for i, lines := range pages {
+		content := &strings.Builder{}
+		content.WriteString("BT\n")
+		fmt.Fprintf(content, "/F1 %d Tf\n%d TL\n", pdfFontSize+3, pdfLineHeight+4)
+		fmt.Fprintf(content, "%d %d Td\n", pdfMargin, pdfPageHeight-pdfMargin-pdfFontSize)
+		fmt.Fprintf(content, "(%s) Tj\n", pdfEscape(truncate(fmt.Sprintf("%s (page %d of %d)", table.Title, i+1, len(pages)), pdfLineChars*pdfFontSize/(pdfFontSize+3))))
+		fmt.Fprintf(content, "T*\n/F1 %d Tf\n%d TL\n", pdfFontSize, pdfLineHeight)
+		for _, line := range lines {
+			fmt.Fprintf(content, "(%s) Tj T*\n", pdfEscape(line))
+		}
+		content.WriteString("ET")
+
+		w.object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", pdfPageWidth, pdfPageHeight, 5+2*i))
+		w.object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+	return w.bytes()
+}
+
+type pdfWriter struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func (w *pdfWriter) object(body string) {
+	if w.buf.Len() == 0 {
+		w.buf.WriteString("%PDF-1.4\n")
+	}
+	w.offsets = append(w.offsets, w.buf.Len())
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", len(w.offsets), body)
+}
+
+func (w *pdfWriter) bytes() []byte {
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, offset := range w.offsets {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets)+1, xref)
+	return w.buf.Bytes()
+}
+
+// pdfEscape escapes a PDF string literal, characters not available in the standard fonts are replaced.
+func pdfEscape(s string) string {
+	b := &strings.Builder{}
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 126:
+			b.WriteRune('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	if max <= 3 {
+		return string([]rune(s)[:max])
+	}
+	return string([]rune(s)[:max-3]) + "..."
+}
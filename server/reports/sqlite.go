@@ -0,0 +1,199 @@
+package reports
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+const runColumns = "id, report_id, started_at, finished_at, trigger, status, error, format, rows"
+
+type SQLiteProvider struct {
+	db        *sqlx.DB
+	converter *query.SQLConverter
+}
+
+func NewSQLiteProvider(db *sqlx.DB) *SQLiteProvider {
+	return &SQLiteProvider{
+		db:        db,
+		converter: query.NewSQLConverter(db.DriverName()),
+	}
+}
+
+func (p *SQLiteProvider) Insert(ctx context.Context, r *Report) error {
+	_, err := p.db.NamedExecContext(ctx,
+		`INSERT INTO reports (
+			id,
+			created_at,
+			created_by,
+			name,
+			type,
+			format,
+			schedule,
+			details
+		) VALUES (
+			:id,
+			:created_at,
+			:created_by,
+			:name,
+			:type,
+			:format,
+			:schedule,
+			:details
+		)`,
+		r,
+	)
+
+	return err
+}
+
+func (p *SQLiteProvider) Update(ctx context.Context, r *Report) error {
+	_, err := p.db.NamedExecContext(ctx,
+		`UPDATE reports SET
+			name = :name,
+			type = :type,
+			format = :format,
+			schedule = :schedule,
+			details = :details
+		WHERE id = :id`,
+		r,
+	)
+
+	return err
+}
+
+func (p *SQLiteProvider) Get(ctx context.Context, id string) (*Report, error) {
+	res := &Report{}
+	err := p.db.GetContext(ctx, res, "SELECT * FROM reports WHERE id = ?", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (p *SQLiteProvider) List(ctx context.Context, options *query.ListOptions) ([]*Report, error) {
+	values := []*Report{}
+
+	q := "SELECT * FROM reports"
+	q, params := p.converter.ConvertListOptionsToQuery(options, q)
+
+	err := p.db.SelectContext(ctx, &values, q, params...)
+	if err != nil {
+		return values, err
+	}
+
+	return values, nil
+}
+
+func (p *SQLiteProvider) Count(ctx context.Context, options *query.ListOptions) (int, error) {
+	var result int
+
+	countOptions := *options
+	countOptions.Pagination = nil
+	q, params := p.converter.ConvertListOptionsToQuery(&countOptions, "SELECT COUNT(*) FROM reports")
+
+	err := p.db.GetContext(ctx, &result, q, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result, nil
+}
+
+// Delete deletes the report and its runs.
+func (p *SQLiteProvider) Delete(ctx context.Context, id string) error {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM report_runs WHERE report_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM reports WHERE id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// InsertRun stores the run and deletes the oldest runs of the report exceeding keep.
+func (p *SQLiteProvider) InsertRun(ctx context.Context, run *Run, keep int) error {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.NamedExecContext(ctx,
+		`INSERT INTO report_runs (`+runColumns+`, content) VALUES (
+			:id,
+			:report_id,
+			:started_at,
+			:finished_at,
+			:trigger,
+			:status,
+			:error,
+			:format,
+			:rows,
+			:content
+		)`,
+		run,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`DELETE FROM report_runs WHERE report_id = ? AND id NOT IN (
+			SELECT id FROM report_runs WHERE report_id = ? ORDER BY started_at DESC LIMIT ?
+		)`,
+		run.ReportID, run.ReportID, keep,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListRuns returns the runs of the report without the content, the latest first.
+func (p *SQLiteProvider) ListRuns(ctx context.Context, reportID string) ([]*Run, error) {
+	values := []*Run{}
+	err := p.db.SelectContext(ctx, &values, "SELECT "+runColumns+" FROM report_runs WHERE report_id = ? ORDER BY started_at DESC", reportID)
+	if err != nil {
+		return values, err
+	}
+
+	return values, nil
+}
+
+// GetRun returns the run including the content.
+func (p *SQLiteProvider) GetRun(ctx context.Context, reportID, id string) (*Run, error) {
+	res := &Run{}
+	err := p.db.GetContext(ctx, res, "SELECT * FROM report_runs WHERE report_id = ? AND id = ?", reportID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (p *SQLiteProvider) Close() error {
+	return p.db.Close()
+}
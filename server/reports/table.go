@@ -0,0 +1,161 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/api/jobs"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+// Source provides the data the reports are built of.
+type Source interface {
+	// Clients returns all clients matching the filters.
+	Clients(ctx context.Context, filters []query.FilterOption) ([]*clientdata.CalculatedClient, error)
+	// JobStats returns the job stats of all clients with jobs started since the given time.
+	JobStats(ctx context.Context, since time.Time) ([]*jobs.JobStats, error)
+}
+
+// Table is the rendered data of a report.
+type Table struct {
+	Title   string
+	Columns []string
+	Rows    [][]string
+}
+
+func buildTable(ctx context.Context, source Source, report *Report, now time.Time) (*Table, error) {
+	filters := make([]query.FilterOption, 0, len(report.Details.Filters))
+	for column, values := range report.Details.Filters {
+		filters = append(filters, query.FilterOption{Column: []string{column}, Values: values})
+	}
+	sort.Slice(filters, func(i, j int) bool {
+		return filters[i].Column[0] < filters[j].Column[0]
+	})
+
+	list, err := source.Clients(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clients: %w", err)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].GetName() < list[j].GetName()
+	})
+
+	since := now.AddDate(0, 0, -report.Details.PeriodDays)
+	table := &Table{
+		Title: fmt.Sprintf("%s - %s", report.Name, now.Format(time.RFC1123)),
+	}
+	switch report.Type {
+	case TypeInventory:
+		table.Columns = []string{"id", "name", "hostname", "os", "os_kernel", "os_arch", "ipv4", "version", "tags", "connection_state"}
+		for _, c := range list {
+			table.Rows = append(table.Rows, []string{
+				c.GetID(),
+				c.GetName(),
+				c.GetHostname(),
+				c.GetOSFullName(),
+				c.GetOSKernel(),
+				c.GetOSArch(),
+				strings.Join(c.GetIPv4(), " "),
+				c.GetVersion(),
+				strings.Join(c.GetTags(), " "),
+				string(c.ConnectionState),
+			})
+		}
+	case TypeAvailability:
+		table.Title += fmt.Sprintf(", last %d day(s)", report.Details.PeriodDays)
+		table.Columns = []string{"id", "name", "connection_state", "last_heartbeat_at", "disconnected_at", "disconnects"}
+		for _, c := range list {
+			disconnects := 0
+			for _, t := range c.GetDisconnects() {
+				if t.After(since) {
+					disconnects++
+				}
+			}
+			table.Rows = append(table.Rows, []string{
+				c.GetID(),
+				c.GetName(),
+				string(c.ConnectionState),
+				formatTime(c.GetLastHeartbeatAt()),
+				formatTime(c.GetDisconnectedAt()),
+				strconv.Itoa(disconnects),
+			})
+		}
+	case TypeUpdates:
+		table.Columns = []string{"id", "name", "os", "updates_available", "security_updates_available", "reboot_pending", "refreshed", "compliant"}
+		for _, c := range list {
+			status := c.GetUpdatesStatus()
+			if status.Refreshed.IsZero() {
+				// the client hasn't reported the updates status yet
+				table.Rows = append(table.Rows, []string{c.GetID(), c.GetName(), c.GetOSFullName(), "", "", "", "", "unknown"})
+				continue
+			}
+			compliant := "yes"
+			if status.Error != "" {
+				compliant = "unknown"
+			} else if status.SecurityUpdatesAvailable > 0 || status.RebootPending {
+				compliant = "no"
+			}
+			table.Rows = append(table.Rows, []string{
+				c.GetID(),
+				c.GetName(),
+				c.GetOSFullName(),
+				strconv.Itoa(status.UpdatesAvailable),
+				strconv.Itoa(status.SecurityUpdatesAvailable),
+				strconv.FormatBool(status.RebootPending),
+				formatTime(&status.Refreshed),
+				compliant,
+			})
+		}
+	case TypeJobs:
+		table.Title += fmt.Sprintf(", last %d day(s)", report.Details.PeriodDays)
+		table.Columns = []string{"id", "name", "total", "successful", "failed", "running", "success_rate", "avg_duration_sec", "last_failure_at"}
+		jobStats, err := source.JobStats(ctx, since)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get job stats: %w", err)
+		}
+		stats := make(map[string]*jobs.JobStats, len(jobStats))
+		for _, s := range jobStats {
+			stats[s.ClientID] = s
+		}
+		for _, c := range list {
+			s, ok := stats[c.GetID()]
+			if !ok {
+				s = &jobs.JobStats{}
+			}
+			table.Rows = append(table.Rows, []string{
+				c.GetID(),
+				c.GetName(),
+				strconv.Itoa(s.Total),
+				strconv.Itoa(s.Successful),
+				strconv.Itoa(s.Failed),
+				strconv.Itoa(s.Running),
+				formatFloat(s.SuccessRate),
+				formatFloat(s.AvgDurationSec),
+				formatTime(s.LastFailureAt),
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unknown report type %q", report.Type)
+	}
+
+	return table, nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
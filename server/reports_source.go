@@ -0,0 +1,55 @@
+package chserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/api/jobs"
+	"github.com/realvnc-labs/rport/server/cgroups"
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/share/query"
+)
+
+// reportsUser is used to select the clients of reports, reports are managed by admins and cover all clients.
+type reportsUser struct{}
+
+func (reportsUser) IsAdmin() bool {
+	return true
+}
+
+func (reportsUser) GetGroups() []string {
+	return nil
+}
+
+type reportsSource struct {
+	clientService       clients.ClientService
+	clientGroupProvider cgroups.ClientGroupProvider
+	jobProvider         JobProvider
+}
+
+func newReportsSource(clientService clients.ClientService, clientGroupProvider cgroups.ClientGroupProvider, jobProvider JobProvider) *reportsSource {
+	return &reportsSource{
+		clientService:       clientService,
+		clientGroupProvider: clientGroupProvider,
+		jobProvider:         jobProvider,
+	}
+}
+
+func (s *reportsSource) Clients(ctx context.Context, filters []query.FilterOption) ([]*clientdata.CalculatedClient, error) {
+	groups, err := s.clientGroupProvider.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.clientService.GetFilteredUserClients(reportsUser{}, filters, groups)
+}
+
+func (s *reportsSource) JobStats(ctx context.Context, since time.Time) ([]*jobs.JobStats, error) {
+	return s.jobProvider.ListJobStats(ctx, &query.ListOptions{
+		Filters: []query.FilterOption{{
+			Column:   []string{"started_at"},
+			Operator: query.FilterOperatorTypeGT,
+			Values:   []string{since.UTC().Format("2006-01-02 15:04:05")},
+		}},
+	})
+}
@@ -0,0 +1,106 @@
+package chserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/server/reversetunnel"
+	chshare "github.com/realvnc-labs/rport/share"
+	"github.com/realvnc-labs/rport/share/comm"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+const reverseTunnelDialTimeout = 10 * time.Second
+
+// resolveReverseTunnelTarget returns the address the server dials for the reverse tunnel. The allowed targets are
+// checked again, they might have changed since the reverse tunnel was created.
+func (s *Server) resolveReverseTunnelTarget(t *models.ReverseTunnel) (string, error) {
+	if t.TargetClientID == "" {
+		if !reversetunnel.TargetAllowed(s.config.Server.ReverseTunnelTargets, t.Target) {
+			return "", fmt.Errorf("target %q is not allowed", t.Target)
+		}
+		return t.Target, nil
+	}
+
+	targetClient, err := s.clientService.GetActiveByID(t.TargetClientID)
+	if err != nil {
+		return "", err
+	}
+	if targetClient == nil {
+		return "", fmt.Errorf("target client %q is not active", t.TargetClientID)
+	}
+	tunnel := s.clientService.FindTunnel(targetClient, t.TargetTunnelID)
+	if tunnel == nil {
+		return "", fmt.Errorf("tunnel %q of target client %q not found", t.TargetTunnelID, t.TargetClientID)
+	}
+	if tunnel.Protocol == models.ProtocolUDP {
+		return "", fmt.Errorf("tunnel %q of target client %q is an udp tunnel", t.TargetTunnelID, t.TargetClientID)
+	}
+	host := tunnel.LocalHost
+	if host == models.ZeroHost || host == "" {
+		host = models.LocalHost
+	}
+	return net.JoinHostPort(host, tunnel.LocalPort), nil
+}
+
+// startReverseTunnels starts the listeners of all reverse tunnels of the client, e.g. after it reconnected.
+func (s *Server) startReverseTunnels(clientLog *logger.Logger, client *clientdata.Client) {
+	for _, t := range client.GetReverseTunnels() {
+		err := sendStartReverseTunnel(client, t, s.Logger)
+		if err != nil {
+			clientLog.Errorf("failed to start reverse tunnel %s on %s: %v", t.ID, t.Local(), err)
+			continue
+		}
+		clientLog.Debugf("started reverse tunnel %s on %s", t.ID, t.Local())
+	}
+}
+
+func sendStartReverseTunnel(client *clientdata.Client, t *models.ReverseTunnel, l *logger.Logger) error {
+	req := &comm.StartReverseTunnelRequest{
+		ID:        t.ID,
+		LocalHost: t.LocalHost,
+		LocalPort: t.LocalPort,
+	}
+	return comm.SendRequestAndGetResponse(client.GetConnection(), comm.RequestTypeStartReverseTunnel, req, nil, l)
+}
+
+// handleReverseTunnelChannel connects a connection accepted by a reverse tunnel of the client to its target.
+func (s *Server) handleReverseTunnelChannel(ctx context.Context, clientLog *logger.Logger, clientID, id string, stream ssh.Channel) {
+	defer stream.Close()
+
+	client, err := s.clientService.GetActiveByID(clientID)
+	if err != nil || client == nil {
+		clientLog.Errorf("reverse tunnel %s: client %s not found: %v", id, clientID, err)
+		return
+	}
+	if err := client.CheckQuarantine(); err != nil {
+		clientLog.Infof("reverse tunnel %s: %v", id, err)
+		return
+	}
+	t := client.FindReverseTunnel(id)
+	if t == nil {
+		clientLog.Infof("reverse tunnel %s not found", id)
+		return
+	}
+
+	target, err := s.resolveReverseTunnelTarget(t)
+	if err != nil {
+		clientLog.Errorf("reverse tunnel %s: %v", id, err)
+		return
+	}
+
+	dialer := net.Dialer{Timeout: reverseTunnelDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", target)
+	if err != nil {
+		clientLog.Errorf("reverse tunnel %s: failed to connect to %s: %v", id, target, err)
+		return
+	}
+	sent, received := chshare.Pipe(stream, conn)
+	clientLog.Debugf("reverse tunnel %s: closed connection to %s (sent %d, received %d)", id, target, sent, received)
+}
@@ -0,0 +1,70 @@
+// Package reversetunnel restricts the targets the server dials for reverse tunnels.
+package reversetunnel
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// AnyPort allows all ports of a host in the allowed targets, e.g. "proxy.example.com:*".
+const AnyPort = "*"
+
+// ValidateTargets checks the allowed targets are given as host:port or host:*.
+func ValidateTargets(targets []string) error {
+	for _, target := range targets {
+		host, port, err := net.SplitHostPort(target)
+		if err != nil {
+			return fmt.Errorf("invalid target %q: %v", target, err)
+		}
+		if host == "" {
+			return fmt.Errorf("invalid target %q: host is required", target)
+		}
+		if port == AnyPort {
+			continue
+		}
+		if err := validatePort(port); err != nil {
+			return fmt.Errorf("invalid target %q: %v", target, err)
+		}
+	}
+	return nil
+}
+
+// ValidateTarget checks the target of a reverse tunnel is a valid host:port.
+func ValidateTarget(target string) error {
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		return err
+	}
+	if host == "" {
+		return fmt.Errorf("host is required")
+	}
+	return validatePort(port)
+}
+
+// TargetAllowed returns true if the target matches one of the allowed targets. Hosts are compared as given, they are
+// not resolved.
+func TargetAllowed(allowed []string, target string) bool {
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		return false
+	}
+	for _, a := range allowed {
+		aHost, aPort, err := net.SplitHostPort(a)
+		if err != nil {
+			continue
+		}
+		if aHost == host && (aPort == AnyPort || aPort == port) {
+			return true
+		}
+	}
+	return false
+}
+
+func validatePort(port string) error {
+	p, err := strconv.Atoi(port)
+	if err != nil || p < 1 || p > 65535 {
+		return fmt.Errorf("invalid port %q", port)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package reversetunnel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTargets(t *testing.T) {
+	assert.NoError(t, ValidateTargets([]string{"proxy.example.com:3128", "10.0.0.1:*", "[fd00::1]:443"}))
+	assert.EqualError(t, ValidateTargets([]string{"proxy.example.com"}), `invalid target "proxy.example.com": address proxy.example.com: missing port in address`)
+	assert.EqualError(t, ValidateTargets([]string{":80"}), `invalid target ":80": host is required`)
+	assert.EqualError(t, ValidateTargets([]string{"10.0.0.1:99999"}), `invalid target "10.0.0.1:99999": invalid port "99999"`)
+}
+
+func TestTargetAllowed(t *testing.T) {
+	allowed := []string{"proxy.example.com:3128", "10.0.0.1:*"}
+
+	testCases := []struct {
+		target string
+		want   bool
+	}{
+		{target: "proxy.example.com:3128", want: true},
+		{target: "proxy.example.com:80", want: false},
+		{target: "10.0.0.1:22", want: true},
+		{target: "10.0.0.2:22", want: false},
+		{target: "invalid", want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.target, func(t *testing.T) {
+			assert.Equal(t, tc.want, TargetAllowed(allowed, tc.target))
+		})
+	}
+	assert.False(t, TargetAllowed(nil, "proxy.example.com:3128"))
+}
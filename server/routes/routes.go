@@ -1,22 +1,38 @@
 package routes
 
+import "strings"
+
 const (
-	ParamClientID       = "client_id"
-	ParamClientAuthID   = "client_auth_id"
-	ParamUserID         = "user_id"
-	ParamSessionID      = "session_id"
-	ParamJobID          = "job_id"
-	ParamGroupID        = "group_id"
-	ParamTokenPrefix    = "prefix"
-	ParamVaultValueID   = "vault_value_id"
-	ParamScriptValueID  = "script_value_id"
-	ParamCommandValueID = "command_value_id"
-	ParamGraphName      = "graph_name"
-	ParamTemplateID     = "template_id"
-	ParamProblemID      = "problem_id"
-	ParamNotificationID = "notification_id"
+	ParamClientID           = "client_id"
+	ParamClientAuthID       = "client_auth_id"
+	ParamUserID             = "user_id"
+	ParamSessionID          = "session_id"
+	ParamJobID              = "job_id"
+	ParamGroupID            = "group_id"
+	ParamTokenPrefix        = "prefix"
+	ParamVaultValueID       = "vault_value_id"
+	ParamScriptValueID      = "script_value_id"
+	ParamCommandValueID     = "command_value_id"
+	ParamGraphName          = "graph_name"
+	ParamTemplateID         = "template_id"
+	ParamProblemID          = "problem_id"
+	ParamNotificationID     = "notification_id"
+	ParamRecordingID        = "recording_id"
+	ParamMetricName         = "metric_name"
+	ParamPendingTunnel      = "pending_tunnel_id"
+	ParamGatewayTargetID    = "gateway_target_id"
+	ParamTunnelShareID      = "share_id"
+	ParamClientWatchID      = "watch_id"
+	ParamReportID           = "report_id"
+	ParamReportRunID        = "run_id"
+	ParamSnapshotID         = "snapshot_id"
+	ParamFeatureFlag        = "flag_name"
+	ParamGroupHistoryID     = "history_id"
+	ParamPushSubscriptionID = "subscription_id"
+	ParamReverseTunnelID    = "reverse_tunnel_id"
 
 	AllRoutesPrefix             = "/api/v1"
+	V2RoutesPrefix              = "/api/v2"
 	AuthRoutesPrefix            = "/auth"
 	AuthProviderRoute           = "/provider"
 	AuthSettingsRoute           = "/ext/settings"
@@ -24,8 +40,20 @@ const (
 	AlertingServiceRoutesPrefix = "/monitoring"
 	ASRuleSetRoute              = "/rules"
 	ASTemplatesRoute            = "/notification-templates"
+	ASRenderRoute               = "/render"
 	ASProblemsRoute             = "/problems"
 	TotPRoutes                  = "/me/totp-secret"
 	Verify2FaRoute              = "/verify-2fa"
 	FilesUploadRouteName        = "files"
+	CustomMetricsRoute          = "/custom-metrics"
 )
+
+// TrimVersionPrefix returns the path without the prefix of the API version.
+func TrimVersionPrefix(path string) string {
+	for _, prefix := range []string{AllRoutesPrefix, V2RoutesPrefix} {
+		if strings.HasPrefix(path, prefix) {
+			return strings.TrimPrefix(path, prefix)
+		}
+	}
+	return path
+}
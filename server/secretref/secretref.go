@@ -0,0 +1,143 @@
+// Package secretref resolves references to secrets kept outside the config file, e.g. db_password = "env://DB_PASSWORD".
+package secretref
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	chshare "github.com/realvnc-labs/rport/share"
+)
+
+const (
+	SchemeEnv   = "env://"
+	SchemeFile  = "file://"
+	SchemeVault = "vault://"
+
+	EnvVaultAddr      = "VAULT_ADDR"
+	EnvVaultToken     = "VAULT_TOKEN"
+	EnvVaultNamespace = "VAULT_NAMESPACE"
+
+	vaultTimeout = 10 * time.Second
+)
+
+var defaultResolver = &Resolver{
+	Getenv: os.Getenv,
+	Client: &http.Client{Timeout: vaultTimeout},
+}
+
+// IsReference returns true if the value references a secret.
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, SchemeEnv) || strings.HasPrefix(value, SchemeFile) || strings.HasPrefix(value, SchemeVault)
+}
+
+// Resolve returns the secret the value references, other values are returned as they are.
+func Resolve(value string) (string, error) {
+	return defaultResolver.Resolve(value)
+}
+
+type Resolver struct {
+	Getenv func(key string) string
+	Client *http.Client
+}
+
+// Resolve returns the secret the value references, other values are returned as they are.
+func (r *Resolver) Resolve(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, SchemeEnv):
+		return r.resolveEnv(strings.TrimPrefix(value, SchemeEnv))
+	case strings.HasPrefix(value, SchemeFile):
+		return r.resolveFile(strings.TrimPrefix(value, SchemeFile))
+	case strings.HasPrefix(value, SchemeVault):
+		return r.resolveVault(strings.TrimPrefix(value, SchemeVault))
+	default:
+		return value, nil
+	}
+}
+
+func (r *Resolver) resolveEnv(name string) (string, error) {
+	if name == "" {
+		return "", errors.New("missing env var name")
+	}
+	secret := r.Getenv(name)
+	if secret == "" {
+		return "", fmt.Errorf("env var %s is not set", name)
+	}
+	return secret, nil
+}
+
+// resolveFile reads the secret from a file like the env vars with the __FILE suffix.
+func (r *Resolver) resolveFile(name string) (string, error) {
+	if name == "" {
+		return "", errors.New("missing file name")
+	}
+	secret, err := chshare.ReadSecretFile(name)
+	if err != nil {
+		return "", err
+	}
+	if secret == "" {
+		return "", fmt.Errorf("file %s is empty", name)
+	}
+	return secret, nil
+}
+
+// resolveVault reads a key of a secret from HashiCorp Vault, the reference is the path of the secret and the key
+// separated by #, e.g. vault://secret/data/rportd#db_password. Both the kv v1 and v2 secrets engines are supported.
+func (r *Resolver) resolveVault(ref string) (string, error) {
+	secretPath, key, ok := strings.Cut(ref, "#")
+	if !ok || secretPath == "" || key == "" {
+		return "", fmt.Errorf("invalid vault reference %q, expected vault://<path>#<key>", SchemeVault+ref)
+	}
+	addr := r.Getenv(EnvVaultAddr)
+	if addr == "" {
+		return "", fmt.Errorf("%s is not set", EnvVaultAddr)
+	}
+	token := r.Getenv(EnvVaultToken)
+	if token == "" {
+		return "", fmt.Errorf("%s is not set", EnvVaultToken)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(addr, "/")+"/v1/"+strings.TrimPrefix(secretPath, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := r.Getenv(EnvVaultNamespace); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s from vault: %v", secretPath, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to read secret %s from vault: status %d", secretPath, resp.StatusCode)
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode secret %s from vault: %v", secretPath, err)
+	}
+	data := body.Data
+	// kv v2 nests the data next to the metadata of the version
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("secret %s in vault has no key %q", secretPath, key)
+	}
+	secret, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("key %q of secret %s in vault is not a string", key, secretPath)
+	}
+	return secret, nil
+}
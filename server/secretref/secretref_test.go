@@ -0,0 +1,82 @@
+package secretref
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "vault-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/rportd":
+			_, _ = w.Write([]byte(`{"data": {"data": {"db_password": "kv2-password"}, "metadata": {"version": 3}}}`))
+		case "/v1/kv/rportd":
+			_, _ = w.Write([]byte(`{"data": {"db_password": "kv1-password", "port": 3306}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer vault.Close()
+
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "secret")
+	require.NoError(t, os.WriteFile(secretFile, []byte("file-password\n"), 0600))
+	emptyFile := filepath.Join(dir, "empty")
+	require.NoError(t, os.WriteFile(emptyFile, []byte("\n"), 0600))
+
+	env := map[string]string{
+		"DB_PASSWORD": "env-password",
+		EnvVaultAddr:  vault.URL,
+		EnvVaultToken: "vault-token",
+	}
+	r := &Resolver{
+		Getenv: func(key string) string { return env[key] },
+		Client: vault.Client(),
+	}
+
+	testCases := []struct {
+		value   string
+		want    string
+		wantErr string
+	}{
+		{value: "plain", want: "plain"},
+		{value: "", want: ""},
+		{value: "env://DB_PASSWORD", want: "env-password"},
+		{value: "env://UNKNOWN", wantErr: "env var UNKNOWN is not set"},
+		{value: "env://", wantErr: "missing env var name"},
+		{value: "file://" + secretFile, want: "file-password"},
+		{value: "file://" + emptyFile, wantErr: "file " + emptyFile + " is empty"},
+		{value: "vault://secret/data/rportd#db_password", want: "kv2-password"},
+		{value: "vault://kv/rportd#db_password", want: "kv1-password"},
+		{value: "vault://kv/rportd#port", wantErr: `key "port" of secret kv/rportd in vault is not a string`},
+		{value: "vault://kv/rportd#unknown", wantErr: `secret kv/rportd in vault has no key "unknown"`},
+		{value: "vault://kv/unknown#key", wantErr: "failed to read secret kv/unknown from vault: status 404"},
+		{value: "vault://kv/rportd", wantErr: `invalid vault reference "vault://kv/rportd", expected vault://<path>#<key>`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := r.Resolve(tc.value)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	delete(env, EnvVaultToken)
+	_, err := r.Resolve("vault://kv/rportd#db_password")
+	assert.EqualError(t, err, "VAULT_TOKEN is not set")
+}
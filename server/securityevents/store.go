@@ -0,0 +1,209 @@
+package securityevents
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultRetention = 7 * 24 * time.Hour
+	DefaultMaxEvents = 100000
+)
+
+type Kind string
+
+const (
+	KindAuthFailure     Kind = "auth_failure"
+	KindLockout         Kind = "lockout"
+	KindTunnelACLDenied Kind = "tunnel_acl_denied"
+	Kind2FAFailure      Kind = "2fa_failure"
+)
+
+// Kinds lists all kinds of security events in the order they are reported.
+var Kinds = []Kind{KindAuthFailure, KindLockout, Kind2FAFailure, KindTunnelACLDenied}
+
+type Event struct {
+	Time   time.Time
+	Kind   Kind
+	IP     string
+	Detail string
+}
+
+// Store keeps the security events in memory for the retention period, the oldest events are dropped
+// when the max number of events is exceeded.
+type Store struct {
+	mu        sync.Mutex
+	events    []Event
+	retention time.Duration
+	maxEvents int
+
+	now func() time.Time
+}
+
+func NewStore(retention time.Duration, maxEvents int) *Store {
+	return &Store{
+		retention: retention,
+		maxEvents: maxEvents,
+		now:       time.Now,
+	}
+}
+
+// Add records a security event, it's a noop on a nil store.
+func (s *Store) Add(kind Kind, ip, detail string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.events = append(s.events, Event{
+		Time:   now,
+		Kind:   kind,
+		IP:     ip,
+		Detail: detail,
+	})
+	s.prune(now)
+}
+
+// prune drops expired events and the oldest events over the limit, events are sorted by time.
+func (s *Store) prune(now time.Time) {
+	first := sort.Search(len(s.events), func(i int) bool {
+		return s.events[i].Time.After(now.Add(-s.retention))
+	})
+	if over := len(s.events) - first - s.maxEvents; s.maxEvents > 0 && over > 0 {
+		first += over
+	}
+	if first > 0 {
+		s.events = append([]Event(nil), s.events[first:]...)
+	}
+}
+
+// Events returns the events recorded since the given time.
+func (s *Store) Events(since time.Time) []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	first := sort.Search(len(s.events), func(i int) bool {
+		return !s.events[i].Time.Before(since)
+	})
+	return append([]Event(nil), s.events[first:]...)
+}
+
+type Summary struct {
+	From       time.Time    `json:"from"`
+	To         time.Time    `json:"to"`
+	BucketSize string       `json:"bucket_size"`
+	Totals     map[Kind]int `json:"totals"`
+	Buckets    []*Bucket    `json:"buckets"`
+	TopIPs     []*IPCounts  `json:"top_ips"`
+}
+
+type Bucket struct {
+	Start  time.Time    `json:"start"`
+	Total  int          `json:"total"`
+	Counts map[Kind]int `json:"counts"`
+}
+
+type IPCounts struct {
+	IP       string       `json:"ip"`
+	Total    int          `json:"total"`
+	Counts   map[Kind]int `json:"counts"`
+	LastSeen time.Time    `json:"last_seen"`
+}
+
+// Summary returns the event counts since the given time grouped into buckets of the given size and the top IPs
+// by number of events.
+func (s *Store) Summary(since time.Time, bucketSize time.Duration, top int) *Summary {
+	now := s.now()
+	from := since.Truncate(bucketSize)
+	summary := &Summary{
+		From:       from,
+		To:         now,
+		BucketSize: bucketSize.String(),
+		Totals:     newCounts(),
+		Buckets:    []*Bucket{},
+		TopIPs:     []*IPCounts{},
+	}
+	for start := from; !start.After(now); start = start.Add(bucketSize) {
+		summary.Buckets = append(summary.Buckets, &Bucket{
+			Start:  start,
+			Counts: newCounts(),
+		})
+	}
+
+	ips := make(map[string]*IPCounts)
+	for _, e := range s.Events(from) {
+		summary.Totals[e.Kind]++
+
+		i := int(e.Time.Sub(from) / bucketSize)
+		if i < len(summary.Buckets) {
+			summary.Buckets[i].Total++
+			summary.Buckets[i].Counts[e.Kind]++
+		}
+
+		if e.IP == "" {
+			continue
+		}
+		ip, ok := ips[e.IP]
+		if !ok {
+			ip = &IPCounts{
+				IP:     e.IP,
+				Counts: newCounts(),
+			}
+			ips[e.IP] = ip
+			summary.TopIPs = append(summary.TopIPs, ip)
+		}
+		ip.Total++
+		ip.Counts[e.Kind]++
+		ip.LastSeen = e.Time
+	}
+
+	sort.SliceStable(summary.TopIPs, func(i, j int) bool {
+		return summary.TopIPs[i].Total > summary.TopIPs[j].Total
+	})
+	if len(summary.TopIPs) > top {
+		summary.TopIPs = summary.TopIPs[:top]
+	}
+
+	return summary
+}
+
+func newCounts() map[Kind]int {
+	counts := make(map[Kind]int, len(Kinds))
+	for _, k := range Kinds {
+		counts[k] = 0
+	}
+	return counts
+}
+
+// WriteCSV writes the buckets of the summary as CSV, one row per bucket with a column per kind of event.
+func (s *Summary) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"start", "total"}
+	for _, k := range Kinds {
+		header = append(header, string(k))
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, b := range s.Buckets {
+		row := []string{b.Start.Format(time.RFC3339), strconv.Itoa(b.Total)}
+		for _, k := range Kinds {
+			row = append(row, strconv.Itoa(b.Counts[k]))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
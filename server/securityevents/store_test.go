@@ -0,0 +1,101 @@
+package securityevents
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary(t *testing.T) {
+	now := time.Date(2023, 5, 10, 12, 30, 0, 0, time.UTC)
+	s := NewStore(24*time.Hour, 0)
+	add := func(at time.Time, kind Kind, ip string) {
+		s.now = func() time.Time {
+			return at
+		}
+		s.Add(kind, ip, "")
+	}
+
+	add(now.Add(-30*time.Hour), KindAuthFailure, "192.0.2.1") // expired
+	add(now.Add(-3*time.Hour), KindAuthFailure, "192.0.2.2")  // before since
+	add(now.Add(-90*time.Minute), KindAuthFailure, "192.0.2.1")
+	add(now.Add(-80*time.Minute), KindAuthFailure, "192.0.2.1")
+	add(now.Add(-80*time.Minute), KindLockout, "192.0.2.1")
+	add(now.Add(-10*time.Minute), Kind2FAFailure, "192.0.2.3")
+	add(now.Add(-5*time.Minute), KindTunnelACLDenied, "198.51.100.7")
+	add(now.Add(-time.Minute), KindTunnelACLDenied, "198.51.100.7")
+	s.now = func() time.Time {
+		return now
+	}
+
+	summary := s.Summary(now.Add(-2*time.Hour), time.Hour, 2)
+
+	assert.Equal(t, time.Date(2023, 5, 10, 10, 0, 0, 0, time.UTC), summary.From)
+	assert.Equal(t, now, summary.To)
+	assert.Equal(t, "1h0m0s", summary.BucketSize)
+	assert.Equal(t, map[Kind]int{
+		KindAuthFailure:     2,
+		KindLockout:         1,
+		Kind2FAFailure:      1,
+		KindTunnelACLDenied: 2,
+	}, summary.Totals)
+
+	require.Len(t, summary.Buckets, 3)
+	assert.Equal(t, 0, summary.Buckets[0].Total)
+	assert.Equal(t, 3, summary.Buckets[1].Total)
+	assert.Equal(t, 2, summary.Buckets[1].Counts[KindAuthFailure])
+	assert.Equal(t, 1, summary.Buckets[1].Counts[KindLockout])
+	assert.Equal(t, 3, summary.Buckets[2].Total)
+	assert.Equal(t, 2, summary.Buckets[2].Counts[KindTunnelACLDenied])
+
+	require.Len(t, summary.TopIPs, 2)
+	assert.Equal(t, "192.0.2.1", summary.TopIPs[0].IP)
+	assert.Equal(t, 3, summary.TopIPs[0].Total)
+	assert.Equal(t, now.Add(-80*time.Minute), summary.TopIPs[0].LastSeen)
+	assert.Equal(t, "198.51.100.7", summary.TopIPs[1].IP)
+	assert.Equal(t, 2, summary.TopIPs[1].Counts[KindTunnelACLDenied])
+}
+
+func TestWriteCSV(t *testing.T) {
+	now := time.Date(2023, 5, 10, 12, 30, 0, 0, time.UTC)
+	s := NewStore(24*time.Hour, 0)
+	s.now = func() time.Time {
+		return now
+	}
+	s.Add(KindLockout, "192.0.2.1", "")
+	s.Add(KindAuthFailure, "192.0.2.1", "")
+
+	var b strings.Builder
+	err := s.Summary(now.Add(-time.Hour), time.Hour, 10).WriteCSV(&b)
+	require.NoError(t, err)
+
+	expected := `start,total,auth_failure,lockout,2fa_failure,tunnel_acl_denied
+2023-05-10T11:00:00Z,0,0,0,0,0
+2023-05-10T12:00:00Z,2,1,1,0,0
+`
+	assert.Equal(t, expected, b.String())
+}
+
+func TestMaxEvents(t *testing.T) {
+	s := NewStore(time.Hour, 2)
+
+	s.Add(KindAuthFailure, "192.0.2.1", "")
+	s.Add(KindAuthFailure, "192.0.2.2", "")
+	s.Add(KindAuthFailure, "192.0.2.3", "")
+
+	events := s.Events(time.Time{})
+	require.Len(t, events, 2)
+	assert.Equal(t, "192.0.2.2", events[0].IP)
+	assert.Equal(t, "192.0.2.3", events[1].IP)
+}
+
+func TestAddNilStore(t *testing.T) {
+	var s *Store
+
+	assert.NotPanics(t, func() {
+		s.Add(KindAuthFailure, "192.0.2.1", "")
+	})
+}
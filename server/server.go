@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"path"
 	"runtime"
 	"sync"
@@ -20,26 +21,63 @@ import (
 
 	"github.com/patrickmn/go-cache"
 
+	bandwidthmigration "github.com/realvnc-labs/rport/db/migration/bandwidth"
+	capacitymigration "github.com/realvnc-labs/rport/db/migration/capacity"
 	"github.com/realvnc-labs/rport/db/migration/client_groups"
+	clientsnapshotsmigration "github.com/realvnc-labs/rport/db/migration/client_snapshots"
 	clientsmigration "github.com/realvnc-labs/rport/db/migration/clients"
+	featureflagsmigration "github.com/realvnc-labs/rport/db/migration/feature_flags"
 	jobsmigration "github.com/realvnc-labs/rport/db/migration/jobs"
+	reportsmigration "github.com/realvnc-labs/rport/db/migration/reports"
+	userlocalesmigration "github.com/realvnc-labs/rport/db/migration/user_locales"
+	webpushmigration "github.com/realvnc-labs/rport/db/migration/webpush"
+	"github.com/realvnc-labs/rport/db/pool"
 	"github.com/realvnc-labs/rport/db/sqlite"
 	rportplus "github.com/realvnc-labs/rport/plus"
 	alertingcap "github.com/realvnc-labs/rport/plus/capabilities/alerting"
+	"github.com/realvnc-labs/rport/plus/capabilities/alerting/correlation"
 	"github.com/realvnc-labs/rport/server/acme"
 	"github.com/realvnc-labs/rport/server/api/jobs"
+	"github.com/realvnc-labs/rport/server/api/jobs/runbook"
 	"github.com/realvnc-labs/rport/server/api/jobs/schedule"
 	"github.com/realvnc-labs/rport/server/api/session"
 	"github.com/realvnc-labs/rport/server/auditlog"
+	"github.com/realvnc-labs/rport/server/bandwidth"
+	"github.com/realvnc-labs/rport/server/bootstrap"
 	"github.com/realvnc-labs/rport/server/caddy"
+	"github.com/realvnc-labs/rport/server/capacity"
+	"github.com/realvnc-labs/rport/server/capture"
 	"github.com/realvnc-labs/rport/server/cgroups"
 	"github.com/realvnc-labs/rport/server/chconfig"
+	"github.com/realvnc-labs/rport/server/clientfeed"
+	"github.com/realvnc-labs/rport/server/clientpayload"
 	"github.com/realvnc-labs/rport/server/clients"
 	"github.com/realvnc-labs/rport/server/clientsauth"
+	"github.com/realvnc-labs/rport/server/clientsnapshot"
+	"github.com/realvnc-labs/rport/server/clienttags"
+	"github.com/realvnc-labs/rport/server/clientwatch"
+	"github.com/realvnc-labs/rport/server/featureflags"
+	"github.com/realvnc-labs/rport/server/firewall"
+	"github.com/realvnc-labs/rport/server/hooks"
+	"github.com/realvnc-labs/rport/server/i18n"
+	"github.com/realvnc-labs/rport/server/jobqueue"
+	"github.com/realvnc-labs/rport/server/maintenance"
 	"github.com/realvnc-labs/rport/server/monitoring"
+	"github.com/realvnc-labs/rport/server/monitoringprofiles"
 	"github.com/realvnc-labs/rport/server/notifications"
+	"github.com/realvnc-labs/rport/server/notifications/channels/rmailer"
+	"github.com/realvnc-labs/rport/server/oseol"
 	"github.com/realvnc-labs/rport/server/ports"
+	"github.com/realvnc-labs/rport/server/preconnect"
+	"github.com/realvnc-labs/rport/server/redaction"
+	"github.com/realvnc-labs/rport/server/reports"
 	"github.com/realvnc-labs/rport/server/scheduler"
+	"github.com/realvnc-labs/rport/server/securityevents"
+	"github.com/realvnc-labs/rport/server/sessionrecording"
+	"github.com/realvnc-labs/rport/server/tunnelapproval"
+	"github.com/realvnc-labs/rport/server/tunnelschemes"
+	"github.com/realvnc-labs/rport/server/updatesrefresh"
+	"github.com/realvnc-labs/rport/server/webpush"
 	chshare "github.com/realvnc-labs/rport/share"
 	"github.com/realvnc-labs/rport/share/capabilities"
 	"github.com/realvnc-labs/rport/share/files"
@@ -49,10 +87,26 @@ import (
 )
 
 const (
-	cleanupMeasurementsInterval = time.Minute * 2
-	cleanupAPISessionsInterval  = time.Hour
-	cleanupJobsInterval         = time.Hour
-	LogNumGoRoutinesInterval    = time.Minute * 2
+	cleanupMeasurementsInterval      = time.Minute * 2
+	rollupMeasurementsInterval       = time.Minute * 5
+	cleanupAPISessionsInterval       = time.Hour
+	closeEphemeralTunnelsInterval    = time.Minute
+	cleanupJobsInterval              = time.Hour
+	checkOrphanedJobsInterval        = time.Minute
+	cleanupSessionRecordingsInterval = time.Hour
+	cleanupCapturesInterval          = time.Hour
+	cleanupClientChangesInterval     = time.Hour
+	keepClientChanges                = 30 * 24 * time.Hour
+	capacitySampleInterval           = time.Hour
+	maintenanceCheckInterval         = 5 * time.Minute
+	checkClientsFlappingInterval     = time.Minute
+	updateAutoTagsInterval           = 10 * time.Minute
+	refreshClientProblemsInterval    = time.Minute
+	remediateProblemsInterval        = time.Minute
+	updateOSEOLInterval              = time.Hour
+	flushBandwidthInterval           = time.Minute
+	customMetricsMaxAge              = 10 * time.Minute
+	LogNumGoRoutinesInterval         = time.Minute * 2
 
 	DefaultMaxClientDBConnections = 50
 )
@@ -76,11 +130,41 @@ type Server struct {
 	auditLog            *auditlog.AuditLog
 	capabilities        *models.Capabilities
 	scheduleManager     *schedule.Manager
+	runbookManager      *runbook.Manager
 	filesAPI            files.FileAPI
 	plusManager         rportplus.Manager
 	caddyServer         *caddy.Server
 	acme                *acme.Acme
 	alertingService     alertingcap.Service
+	flapDetector        *correlation.FlapDetector
+	sessionRecordings   *sessionrecording.Store
+	captures            *capture.Store
+	updatesRefresher    *updatesrefresh.Refresher
+	securityEvents      *securityevents.Store
+	handshakeFailures   *clientsauth.HandshakeFailures
+	portDistributor     *ports.PortDistributor
+	capacityService     *capacity.Service
+	bandwidth           *bandwidth.Service
+	firewall            *firewall.Manager
+	reports             *reports.Manager
+	clientSnapshots     *clientsnapshot.Service
+	locales             *i18n.Service
+	featureFlags        *featureflags.Service
+	webPush             *webpush.Service
+	tunnelApprovals     *tunnelapproval.Service
+	redactor            *redaction.Redactor
+	tunnelSchemes       tunnelschemes.Schemes
+	monitoringProfiles  monitoringprofiles.Profiles
+	osEOL               *oseol.Dataset
+	clientWatches       *clientwatch.Service
+	clientFeed          *clientfeed.Feed
+	jobQueue            *jobqueue.Queue
+	maintenance         *maintenance.Service
+	clientsStatusCheck  *ClientsStatusCheckTask
+	clientPayload       *clientpayload.Validator
+	clientTags          *clienttags.Validator
+	startedAt           time.Time
+	started             chan struct{}
 }
 
 type ServerOpts struct {
@@ -95,13 +179,19 @@ func NewServer(ctx context.Context, config *chconfig.Config, opts *ServerOpts) (
 	s := &Server{
 		Logger:           logger.NewLogger("server", config.Logging.LogOutput, config.Logging.LogLevel),
 		config:           config,
+		startedAt:        time.Now(),
+		clientPayload:    clientpayload.NewValidator(config.Server.ClientPayload),
 		uiJobWebSockets:  ws.NewWebSocketCache(),
 		uploadWebSockets: sync.Map{},
 		jobsDoneChannel: jobResultChanMap{
 			m: make(map[string]chan *models.Job),
 		},
+		started: make(chan struct{}),
 	}
 
+	// set before any store is connected, stores connected afterwards log their slow queries
+	pool.SetSlowQueryLog(config.Server.SlowQueryThreshold, s.Logger.Fork("slow-query"))
+
 	s.acme = acme.New(s.Logger.Fork("acme"), config.Server.DataDir, config.Server.AcmeHTTPPort)
 	if config.Server.InternalTunnelProxyConfig.EnableAcme {
 		s.acme.AddHost(config.Server.InternalTunnelProxyConfig.Host)
@@ -164,7 +254,21 @@ func NewServer(ctx context.Context, config *chconfig.Config, opts *ServerOpts) (
 		return nil, fmt.Errorf("failed to create jobs DB instance: %v", err)
 	}
 
-	s.jobProvider = jobs.NewSqliteProvider(jobsDB, s.Logger)
+	jobsProvider := jobs.NewSqliteProvider(jobsDB, s.Logger)
+	if config.Server.SqliteReadReplicaDir != "" {
+		replica, err := sqlite.OpenReadReplica(config.Server.SqliteReadReplicaDir, "jobs.db", s.Logger)
+		if err != nil {
+			return nil, err
+		}
+		jobsProvider.SetReadReplica(replica)
+	}
+	s.jobProvider = jobsProvider
+	s.updatesRefresher = updatesrefresh.New(
+		jobsProvider,
+		config.Server.UpdatesStatusRefreshMinInterval,
+		config.Server.UpdatesStatusRefreshTimeout,
+		s.Logger.Fork("updates-refresh"),
+	)
 
 	groupsDB, err := sqlite.New(
 		path.Join(config.Server.DataDir, "client_groups.db"),
@@ -192,7 +296,7 @@ func NewServer(ctx context.Context, config *chconfig.Config, opts *ServerOpts) (
 	}
 
 	// even if monitoring disabled, always create the monitoring service to support queries of past data etc
-	s.monitoringService = monitoring.NewService(monitoringProvider)
+	s.monitoringService = monitoring.NewService(monitoringProvider, monitoringTiers(config.Monitoring))
 
 	sourceOptions := config.Server.GetSQLiteDataSourceOptions()
 
@@ -200,6 +304,9 @@ func NewServer(ctx context.Context, config *chconfig.Config, opts *ServerOpts) (
 	// and use the RetryWhenBusy fn to ensure writes succeed if we get a busy error due to
 	// concurrent thread access.
 	sourceOptions.MaxOpenConnections = DefaultMaxClientDBConnections
+	if config.Server.SqliteClientsMaxOpenConnections > 0 {
+		sourceOptions.MaxOpenConnections = config.Server.SqliteClientsMaxOpenConnections
+	}
 
 	s.clientDB, err = sqlite.New(
 		path.Join(config.Server.DataDir, "clients.db"),
@@ -217,10 +324,12 @@ func NewServer(ctx context.Context, config *chconfig.Config, opts *ServerOpts) (
 		keepDisconnectedClients = &config.Server.KeepDisconnectedClients
 	}
 
+	s.portDistributor = ports.NewPortDistributor(config.AllowedPorts())
+
 	s.clientService, err = clients.InitClientService(
 		ctx,
 		&s.config.Server.InternalTunnelProxyConfig,
-		ports.NewPortDistributor(config.AllowedPorts()),
+		s.portDistributor,
 		s.clientDB,
 		keepDisconnectedClients,
 		s.Logger,
@@ -230,25 +339,255 @@ func NewServer(ctx context.Context, config *chconfig.Config, opts *ServerOpts) (
 		return nil, err
 	}
 
+	if config.Server.SqliteReadReplicaDir != "" {
+		replica, err := sqlite.OpenReadReplica(config.Server.SqliteReadReplicaDir, "clients.db", s.Logger)
+		if err != nil {
+			return nil, err
+		}
+		s.clientService.GetRepo().SetReadReplica(replica)
+	}
+
 	if rportplus.IsPlusEnabled(config.PlusConfig) {
 		licCapEx := s.plusManager.GetLicenseCapabilityEx()
 		s.clientService.SetPlusLicenseInfoCap(licCapEx)
 		s.clientService.SetPlusAlertingServiceCap(s.alertingService)
+		if s.alertingService != nil && config.Server.AlertingFlappingThreshold > 0 {
+			s.flapDetector = correlation.NewFlapDetector(config.Server.AlertingFlappingWindow, config.Server.AlertingFlappingThreshold)
+			s.clientService.SetFlapDetector(s.flapDetector)
+		}
+	}
+
+	if config.Server.SessionRecording.Enabled {
+		s.sessionRecordings, err = sessionrecording.NewStore(config.Server.DataDir, s.Logger.Fork("session-recording"))
+		if err != nil {
+			return nil, err
+		}
+		s.clientService.SetSessionRecordingStore(s.sessionRecordings)
+	}
+
+	if config.Server.Capture.Enabled {
+		s.captures, err = capture.NewStore(config.Server.DataDir, config.Server.Capture.Quota, s.Logger.Fork("capture"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.securityEvents = securityevents.NewStore(securityevents.DefaultRetention, securityevents.DefaultMaxEvents)
+	s.handshakeFailures = clientsauth.NewHandshakeFailures()
+	s.clientService.SetSecurityEvents(s.securityEvents)
+
+	s.clientService.SetACLRules(config.Server.ClientACLRules)
+	if len(config.Server.ExecHooks) > 0 {
+		s.clientService.SetHooks(hooks.NewRunner(config.Server.ExecHooks, config.Server.ExecHooksTimeout, s.Logger.Fork("hooks")))
+	}
+	if config.Server.MinClientVersion.Enabled() {
+		s.clientService.SetMinClientVersion(&config.Server.MinClientVersion)
+	}
+	if config.Server.PreConnect.Script != "" {
+		s.clientService.SetPreConnectScript(preconnect.New(config.Server.PreConnect, s.Logger.Fork("pre-connect")))
+	}
+	if config.Server.AutoTags.Enabled() {
+		s.clientService.SetAutoTagsConfig(&config.Server.AutoTags)
+	}
+	s.clientService.SetDuplicateDetection(config.Server.DuplicateClientsSerialLabel, config.Server.AlertingDuplicateClients)
+	s.clientService.SetClockSkewThreshold(config.Server.AlertingClockSkewThreshold)
+	s.clientService.SetAgentStatsThresholds(clients.AgentStatsThresholds{
+		RSSBytes:   uint64(config.Server.AlertingAgentRSSThresholdMB) * 1024 * 1024,
+		Goroutines: config.Server.AlertingAgentGoroutinesThreshold,
+		OpenFDs:    config.Server.AlertingAgentOpenFDsThreshold,
+	})
+
+	s.osEOL, err = oseol.NewDataset(config.Server.OSEOLFile)
+	if err != nil {
+		return nil, err
+	}
+	s.clientService.SetOSEOLDataset(s.osEOL)
+
+	s.clientWatches = clientwatch.NewService(s.Logger.Fork("client-watch"))
+	s.clientService.SetClientWatches(s.clientWatches)
+	s.clientFeed = clientfeed.New(s.Logger.Fork("client-feed"))
+	s.clientService.GetRepo().AddChangeHandlerFn(s.clientFeed.ClientChanged)
+	s.jobQueue = jobqueue.New(s.Logger.Fork("job-queue"))
+	s.clientService.GetRepo().AddChangeHandlerFn(s.jobQueue.ClientChanged)
+
+	if len(config.Server.TunnelApprovalRules) > 0 {
+		s.tunnelApprovals = tunnelapproval.NewService(
+			tunnelapproval.Config{
+				Rules:   config.Server.TunnelApprovalRules,
+				Timeout: config.Server.TunnelApprovalTimeout,
+			},
+			s.Logger.Fork("tunnel-approval"),
+		)
+	}
+
+	s.redactor, err = redaction.NewRedactor(config.Server.RedactionRules)
+	if err != nil {
+		return nil, err
+	}
+
+	s.clientTags, err = clienttags.NewValidator(config.Server.ClientTags)
+	if err != nil {
+		return nil, err
 	}
 
+	s.tunnelSchemes = tunnelschemes.New(config.Server.TunnelSchemes)
+	s.clientService.SetTunnelSchemes(s.tunnelSchemes)
+
+	s.monitoringProfiles = monitoringprofiles.New(config.Monitoring.Profiles)
+
+	capacityDB, err := sqlite.New(
+		path.Join(config.Server.DataDir, "capacity.db"),
+		capacitymigration.AssetNames(),
+		capacitymigration.Asset,
+		config.Server.GetSQLiteDataSourceOptions(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create capacity DB instance: %v", err)
+	}
+	registerClientGauges(s.clientService)
+	registerDatabaseGauges(config.Server.SlowQueryThreshold)
+
+	s.capacityService = capacity.NewService(
+		capacity.NewSQLiteProvider(capacityDB),
+		newCapacitySource(s.clientService, s.portDistributor),
+		capacity.Config{
+			WarnDays: config.Monitoring.CapacityWarningDays,
+		},
+		s.Logger.Fork("capacity"),
+	)
+
+	userLocalesDB, err := sqlite.New(
+		path.Join(config.Server.DataDir, "user_locales.db"),
+		userlocalesmigration.AssetNames(),
+		userlocalesmigration.Asset,
+		config.Server.GetSQLiteDataSourceOptions(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user locales DB instance: %v", err)
+	}
+	s.locales, err = i18n.NewService(ctx, userLocalesDB, config.Server.DefaultLocale)
+	if err != nil {
+		return nil, err
+	}
+
+	featureFlagsDB, err := sqlite.New(
+		path.Join(config.Server.DataDir, "feature_flags.db"),
+		featureflagsmigration.AssetNames(),
+		featureflagsmigration.Asset,
+		config.Server.GetSQLiteDataSourceOptions(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create feature flags DB instance: %v", err)
+	}
+	s.featureFlags, err = featureflags.NewService(ctx, featureFlagsDB, config.Server.FeatureFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Server.WebPushSubject != "" {
+		webPushDB, err := sqlite.New(
+			path.Join(config.Server.DataDir, "webpush.db"),
+			webpushmigration.AssetNames(),
+			webpushmigration.Asset,
+			config.Server.GetSQLiteDataSourceOptions(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create web push DB instance: %v", err)
+		}
+		s.webPush, err = webpush.NewService(ctx, webPushDB, config.Server.WebPushSubject, s.Logger.Fork("webpush"))
+		if err != nil {
+			return nil, err
+		}
+		s.clientService.SetWebPush(s.webPush)
+	}
+
+	bandwidthDB, err := sqlite.New(
+		path.Join(config.Server.DataDir, "bandwidth.db"),
+		bandwidthmigration.AssetNames(),
+		bandwidthmigration.Asset,
+		config.Server.GetSQLiteDataSourceOptions(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bandwidth DB instance: %v", err)
+	}
+	s.bandwidth = bandwidth.NewService(bandwidth.NewSQLiteProvider(bandwidthDB), config.Server.Bandwidth, s.Logger.Fork("bandwidth"))
+	s.clientService.SetBandwidth(s.bandwidth)
+
+	if config.Firewall.Enabled() {
+		s.firewall = firewall.NewManager(&config.Firewall, s.Logger.Fork("firewall"))
+		if err := s.firewall.Start(); err != nil {
+			return nil, fmt.Errorf("failed to prepare the firewall: %w", err)
+		}
+		s.clientService.SetFirewall(s.firewall)
+	}
+
+	reportsDB, err := sqlite.New(
+		path.Join(config.Server.DataDir, "reports.db"),
+		reportsmigration.AssetNames(),
+		reportsmigration.Asset,
+		config.Server.GetSQLiteDataSourceOptions(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reports DB instance: %v", err)
+	}
+	s.reports, err = reports.New(
+		ctx,
+		reports.NewSQLiteProvider(reportsDB),
+		newReportsSource(s.clientService, s.clientGroupProvider, s.jobProvider),
+		s.Logger.Fork("reports"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init reports: %v", err)
+	}
+	s.reports.SetLocalizer(s.locales)
+	if config.SMTP.Server != "" {
+		smtpConfig, err := rmailer.ConfigFromSMTPConfig(config.SMTP)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init reports mailer: %v", err)
+		}
+		s.reports.SetMailer(rmailer.NewRMailer(smtpConfig, s.Logger.Fork("reports smtp")))
+	}
+
+	clientSnapshotsDB, err := sqlite.New(
+		path.Join(config.Server.DataDir, "client_snapshots.db"),
+		clientsnapshotsmigration.AssetNames(),
+		clientsnapshotsmigration.Asset,
+		config.Server.GetSQLiteDataSourceOptions(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client snapshots DB instance: %v", err)
+	}
+	s.clientSnapshots = clientsnapshot.NewService(
+		clientsnapshot.NewSQLiteProvider(clientSnapshotsDB),
+		newClientSnapshotSource(s.clientService, s.Logger.Fork("client snapshots")),
+		config.Server.ClientSnapshots,
+		s.Logger.Fork("client snapshots"),
+	)
+
 	s.auditLog, err = auditlog.New(
 		logger.NewLogger("auditlog", config.Logging.LogOutput, config.Logging.LogLevel),
 		s.clientService,
 		s.config.Server.DataDir,
 		s.config.API.AuditLog,
 		s.config.Server.GetSQLiteDataSourceOptions(),
+		s.config.Server.SqliteReadReplicaDir,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	dbs := maintenanceDBs{
+		jobs:         jobsDB,
+		clientGroups: groupsDB,
+		capacity:     capacityDB,
+	}
+	if p, ok := monitoringProvider.(*monitoring.SqliteProvider); ok {
+		dbs.monitoring = p.DB()
+	}
+	s.maintenance = s.newMaintenanceService(jobsProvider, dbs)
+
 	if config.Database.Driver != "" {
-		s.authDB, err = sqlx.Connect(config.Database.Driver, config.Database.Dsn)
+		s.authDB, err = pool.Connect("auth", config.Database.Driver, config.Database.Dsn, config.Database.PoolOptions())
 		if err != nil {
 			return nil, err
 		}
@@ -271,6 +610,10 @@ func NewServer(ctx context.Context, config *chconfig.Config, opts *ServerOpts) (
 	if err != nil {
 		return nil, err
 	}
+	s.clientService.SetTunnelCredentialsProvider(&vaultTunnelCredentials{
+		vaultManager: s.apiListener.vaultManager,
+		userService:  s.apiListener.userService,
+	})
 
 	s.capabilities = capabilities.NewServerCapabilities(&config.Monitoring)
 
@@ -278,6 +621,7 @@ func NewServer(ctx context.Context, config *chconfig.Config, opts *ServerOpts) (
 	if err != nil {
 		return nil, err
 	}
+	s.runbookManager = runbook.NewManager(s.apiListener, s.jobProvider, jobsDB, s.Logger, config.Server.RunRemoteCmdTimeoutSec)
 
 	if s.config.CaddyEnabled() {
 		cfg := s.config
@@ -294,10 +638,35 @@ func NewServer(ctx context.Context, config *chconfig.Config, opts *ServerOpts) (
 		s.clientService.SetCaddyAPI(s.caddyServer)
 	}
 
+	if s.alertingService != nil {
+		s.capacityService.SetAlertingService(s.alertingService)
+	}
+	s.clientWatches.SetDispatcher(notifications.NewDispatcher(s.apiListener.notificationsStorage))
+	s.clientWatches.SetLocalizer(s.locales)
+	if s.webPush != nil {
+		s.webPush.SetDispatcher(notifications.NewDispatcher(s.apiListener.notificationsStorage))
+		s.webPush.SetLocalizer(s.locales)
+		s.webPush.SetAccessChecker(s.apiListener)
+	}
+	if s.tunnelApprovals != nil {
+		s.tunnelApprovals.SetDispatcher(notifications.NewDispatcher(s.apiListener.notificationsStorage), s.apiListener.tunnelApprovers)
+		s.tunnelApprovals.SetLocalizer(s.locales)
+	}
+
 	if s.alertingService != nil {
 		dispatcher := notifications.NewDispatcher(s.apiListener.notificationsStorage)
 		s.alertingService.Run(ctx, dispatcher)
 	}
+
+	if config.Server.BootstrapFile != "" {
+		bundle, err := bootstrap.ParseFile(config.Server.BootstrapFile)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.apiListener.newBootstrapApplier().Apply(ctx, bundle, bootstrap.DefaultUser); err != nil {
+			return nil, fmt.Errorf("failed to apply bootstrap file %s: %w", config.Server.BootstrapFile, err)
+		}
+	}
 	return s, nil
 }
 
@@ -365,9 +734,11 @@ func initPrivateKey(seed string) (ssh.Signer, error) {
 
 // Run is responsible for starting the rport service
 func (s *Server) Run(ctx context.Context) error {
+	startedAt := time.Now()
 	if err := s.Start(ctx); err != nil {
 		return err
 	}
+	close(s.started)
 
 	s.acme.Start()
 
@@ -380,29 +751,38 @@ func (s *Server) Run(ctx context.Context) error {
 		s.Debugf("Task to purge disconnected clients disabled")
 	}
 
-	// Run a task to Check the client connections status by sending and receiving pings
-	clientsStatusCheckTask := NewClientsStatusCheckTask(
+	clientChangesCleanupTask := clients.NewChangesCleanupTask(s.Logger, s.clientListener.server.clientService.GetRepo(), keepClientChanges)
+	go scheduler.Run(ctx, s.Logger.Fork(fmt.Sprintf("task %T", clientChangesCleanupTask)), clientChangesCleanupTask, cleanupClientChangesInterval)
+	s.Infof("Task to cleanup client changes older than %v will run with interval %v", keepClientChanges, cleanupClientChangesInterval)
+
+	// Run a task to Check the client connections status by sending and receiving pings.
+	// Clients are spread over the wheel slots, so the task runs once per slot within the interval.
+	s.clientsStatusCheck = NewClientsStatusCheckTask(
 		s.Logger,
 		s.clientListener.server.clientService.GetRepo(),
 		s.config.Server.CheckClientsConnectionInterval,
 		s.config.Server.CheckClientsConnectionTimeout,
+		KeepaliveWheelSlots,
 	)
-	go scheduler.Run(ctx, s.Logger.Fork(fmt.Sprintf("task %T", clientsStatusCheckTask)), clientsStatusCheckTask, s.config.Server.CheckClientsConnectionInterval)
-	s.Infof("Task to check the clients connection status will run with interval %v", s.config.Server.CheckClientsConnectionInterval)
+	statusCheckSlotInterval := s.config.Server.CheckClientsConnectionInterval / KeepaliveWheelSlots
+	go scheduler.Run(ctx, s.Logger.Fork(fmt.Sprintf("task %T", s.clientsStatusCheck)), s.clientsStatusCheck, statusCheckSlotInterval)
+	s.Infof("Task to check the clients connection status will run with interval %v in %d slots of %v", s.config.Server.CheckClientsConnectionInterval, KeepaliveWheelSlots, statusCheckSlotInterval)
 
 	if s.config.Monitoring.Enabled {
-		var cleaningPeriod time.Duration
 		if s.config.Monitoring.DataStorageDays > 0 {
 			s.Infof("Period to keep measurements will be %d day(s)", s.config.Monitoring.DataStorageDays)
-			cleaningPeriod = time.Hour * 24 * time.Duration(s.config.Monitoring.DataStorageDays)
 		} else {
 			s.Infof("Period to keep measurements will be %s", s.config.Monitoring.DataStorageDuration)
-			cleaningPeriod = s.config.Monitoring.GetDataStorageDuration()
 		}
+		s.Infof("Period to keep 5 minutes rollups will be %s, hourly rollups %s", s.config.Monitoring.Rollup5mStorageDuration, s.config.Monitoring.Rollup1hStorageDuration)
 
-		monitoringCleanupTask := monitoring.NewCleanupTask(s.Logger, s.monitoringService, cleaningPeriod)
+		monitoringCleanupTask := monitoring.NewCleanupTask(s.Logger, s.monitoringService)
 		go scheduler.Run(ctx, s.Logger.Fork(fmt.Sprintf("task %T", monitoringCleanupTask)), monitoringCleanupTask, cleanupMeasurementsInterval)
 		s.Infof("Task to cleanup measurements will run with interval %v", cleanupMeasurementsInterval)
+
+		monitoringRollupTask := monitoring.NewRollupTask(s.Logger, s.monitoringService)
+		go scheduler.Run(ctx, s.Logger.Fork(fmt.Sprintf("task %T", monitoringRollupTask)), monitoringRollupTask, rollupMeasurementsInterval)
+		s.Infof("Task to rollup measurements will run with interval %v", rollupMeasurementsInterval)
 	} else {
 		s.Infof("Measurement disabled")
 	}
@@ -411,10 +791,78 @@ func (s *Server) Run(ctx context.Context) error {
 	go scheduler.Run(ctx, s.Logger.Fork(fmt.Sprintf("task %T", sessionsCleanupTask)), sessionsCleanupTask, cleanupAPISessionsInterval)
 	s.Infof("Task to cleanup expired api sessions will run with interval %v", cleanupAPISessionsInterval)
 
+	ephemeralTunnelsTask := clients.NewEphemeralTunnelsTask(s.clientService, s.apiListener.apiSessions)
+	go scheduler.Run(ctx, s.Logger.Fork(fmt.Sprintf("task %T", ephemeralTunnelsTask)), ephemeralTunnelsTask, closeEphemeralTunnelsInterval)
+	s.Infof("Task to close ephemeral tunnels of expired api sessions will run with interval %v", closeEphemeralTunnelsInterval)
+
 	jobsCleanupTask := jobs.NewCleanupTask(s.jobProvider, s.config.Server.JobsMaxResults)
 	go scheduler.Run(ctx, s.Logger.Fork(fmt.Sprintf("task %T", jobsCleanupTask)), jobsCleanupTask, cleanupJobsInterval)
 	s.Infof("Task to cleanup jobs will run with interval %v", cleanupJobsInterval)
 
+	if err := s.apiListener.recoverMultiClientJobs(ctx); err != nil {
+		s.Errorf("Failed to recover multi-client jobs: %v", err)
+	}
+	orphanedJobsTask := newOrphanedJobsTask(s.apiListener, startedAt)
+	go scheduler.Run(ctx, s.Logger.Fork(fmt.Sprintf("task %T", orphanedJobsTask)), orphanedJobsTask, checkOrphanedJobsInterval)
+	s.Infof("Task to check jobs orphaned by a stop of the server will run with interval %v", checkOrphanedJobsInterval)
+
+	if s.sessionRecordings != nil && s.config.Server.SessionRecording.Retention > 0 {
+		recordingsCleanupTask := sessionrecording.NewCleanupTask(s.Logger, s.sessionRecordings, s.config.Server.SessionRecording.Retention)
+		go scheduler.Run(ctx, s.Logger.Fork(fmt.Sprintf("task %T", recordingsCleanupTask)), recordingsCleanupTask, cleanupSessionRecordingsInterval)
+		s.Infof("Task to cleanup session recordings will run with interval %v", cleanupSessionRecordingsInterval)
+	}
+
+	if s.captures != nil && s.config.Server.Capture.Retention > 0 {
+		capturesCleanupTask := capture.NewCleanupTask(s.Logger, s.captures, s.config.Server.Capture.Retention)
+		go scheduler.Run(ctx, s.Logger.Fork(fmt.Sprintf("task %T", capturesCleanupTask)), capturesCleanupTask, cleanupCapturesInterval)
+		s.Infof("Task to cleanup packet captures will run with interval %v", cleanupCapturesInterval)
+	}
+
+	if s.flapDetector != nil {
+		flappingTask := correlation.NewFlappingTask(s.Logger, s.flapDetector, s.alertingService)
+		go scheduler.Run(ctx, s.Logger.Fork(fmt.Sprintf("task %T", flappingTask)), flappingTask, checkClientsFlappingInterval)
+		s.Infof("Task to check clients that stopped flapping will run with interval %v", checkClientsFlappingInterval)
+	}
+
+	if s.config.Server.AutoTags.Enabled() {
+		autoTagsTask := clients.NewAutoTagsTask(s.Logger, s.clientService.GetRepo(), &s.config.Server.AutoTags)
+		go scheduler.Run(ctx, s.Logger.Fork(fmt.Sprintf("task %T", autoTagsTask)), autoTagsTask, updateAutoTagsInterval)
+		s.Infof("Task to update the auto tags of clients will run with interval %v", updateAutoTagsInterval)
+	}
+
+	if s.alertingService != nil {
+		problemsTask := clients.NewProblemsTask(s.Logger, s.clientService.GetRepo(), s.alertingService)
+		go scheduler.Run(ctx, s.Logger.Fork(fmt.Sprintf("task %T", problemsTask)), problemsTask, refreshClientProblemsInterval)
+		s.Infof("Task to refresh the open problems of clients will run with interval %v", refreshClientProblemsInterval)
+
+		remediationTask := newRunbookRemediationTask(s.apiListener, s.alertingService)
+		go scheduler.Run(ctx, s.Logger.Fork(fmt.Sprintf("task %T", remediationTask)), remediationTask, remediateProblemsInterval)
+		s.Infof("Task to run the runbooks of alerting problems will run with interval %v", remediateProblemsInterval)
+	}
+
+	bandwidthTask := bandwidth.NewFlushTask(s.bandwidth)
+	go scheduler.Run(ctx, s.Logger.Fork(fmt.Sprintf("task %T", bandwidthTask)), bandwidthTask, flushBandwidthInterval)
+	s.Infof("Task to save the tunnel traffic will run with interval %v", flushBandwidthInterval)
+
+	if s.config.Server.ClientSnapshots.Interval > 0 {
+		snapshotsTask := clientsnapshot.NewTask(s.clientSnapshots)
+		go scheduler.Run(ctx, s.Logger.Fork(fmt.Sprintf("task %T", snapshotsTask)), snapshotsTask, s.config.Server.ClientSnapshots.Interval)
+		s.Infof("Task to take snapshots of the clients will run with interval %v", s.config.Server.ClientSnapshots.Interval)
+	}
+
+	eolTask := clients.NewEOLTask(s.Logger, s.clientService.GetRepo(), s.osEOL)
+	go scheduler.Run(ctx, s.Logger.Fork(fmt.Sprintf("task %T", eolTask)), eolTask, updateOSEOLInterval)
+	s.Infof("Task to update the os end-of-life dates of clients will run with interval %v", updateOSEOLInterval)
+
+	capacitySampleTask := capacity.NewSampleTask(s.capacityService)
+	go scheduler.Run(ctx, s.Logger.Fork(fmt.Sprintf("task %T", capacitySampleTask)), capacitySampleTask, capacitySampleInterval)
+	s.Infof("Task to sample the server capacity will run with interval %v", capacitySampleInterval)
+
+	if s.config.Server.Maintenance.QuietHours != "" {
+		go scheduler.Run(ctx, s.Logger.Fork(fmt.Sprintf("task %T", s.maintenance)), s.maintenance, maintenanceCheckInterval)
+		s.Infof("Task to run the maintenance during quiet hours %s will run with interval %v", s.config.Server.Maintenance.QuietHours, maintenanceCheckInterval)
+	}
+
 	// Only on debug mode, log the number of running go routines
 	if s.config.Logging.LogLevel == logger.LogLevelDebug {
 		go func() {
@@ -457,6 +905,29 @@ func (s *Server) Start(ctx context.Context) error {
 	return err
 }
 
+// Started returns a channel that is closed once Run started the listeners.
+func (s *Server) Started() <-chan struct{} {
+	return s.started
+}
+
+// ClientListenerAddr returns the address the server listens on for client connections, nil if it's not started.
+func (s *Server) ClientListenerAddr() net.Addr {
+	return s.clientListener.httpServer.Addr()
+}
+
+// APIListenerAddr returns the address the API listens on, nil if it's not started or the API is disabled.
+func (s *Server) APIListenerAddr() net.Addr {
+	if s.apiListener.httpServer == nil {
+		return nil
+	}
+	return s.apiListener.httpServer.Addr()
+}
+
+// Fingerprint returns the fingerprint of the server key, clients use it to verify the server.
+func (s *Server) Fingerprint() string {
+	return s.apiListener.fingerprint
+}
+
 func (s *Server) Wait() error {
 	wg := &errgroup.Group{}
 	wg.Go(s.clientListener.Wait)
@@ -489,6 +960,34 @@ func (s *Server) Close() error {
 	if s.auditLog != nil {
 		wg.Go(s.auditLog.Close)
 	}
+	if s.capacityService != nil {
+		wg.Go(s.capacityService.Close)
+	}
+
+	if s.bandwidth != nil {
+		wg.Go(s.bandwidth.Close)
+	}
+	if s.reports != nil {
+		wg.Go(s.reports.Close)
+	}
+	if s.clientSnapshots != nil {
+		wg.Go(s.clientSnapshots.Close)
+	}
+	if s.locales != nil {
+		wg.Go(s.locales.Close)
+	}
+	if s.featureFlags != nil {
+		wg.Go(s.featureFlags.Close)
+	}
+	if s.webPush != nil {
+		wg.Go(s.webPush.Close)
+	}
+	if s.firewall != nil {
+		wg.Go(func() error {
+			s.firewall.CloseAll()
+			return nil
+		})
+	}
 
 	s.uploadWebSockets.Range(func(key, value interface{}) bool {
 		if wsConn, ok := value.(*ws.ConcurrentWebSocket); ok {
@@ -539,3 +1038,16 @@ func (m *jobResultChanMap) GetAllKeys() (jobIDs []string) {
 	}
 	return jobIDs
 }
+
+// monitoringTiers returns the tiers to keep measurements in, measurements are not rolled up if monitoring is disabled.
+func monitoringTiers(config chconfig.MonitoringConfig) monitoring.Tiers {
+	if !config.Enabled {
+		return nil
+	}
+
+	rawRetention := config.GetDataStorageDuration()
+	if config.DataStorageDays > 0 {
+		rawRetention = time.Hour * 24 * time.Duration(config.DataStorageDays)
+	}
+	return monitoring.NewTiers(rawRetention, config.GetRollup5mStorageDuration(), config.GetRollup1hStorageDuration())
+}
@@ -0,0 +1,82 @@
+package sessionrecording
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// asciicast v2 format, see https://github.com/asciinema/asciinema/blob/develop/doc/asciicast-v2.md
+const (
+	asciicastVersion = 2
+	defaultWidth     = 80
+	defaultHeight    = 24
+
+	EventOutput = "o"
+	EventInput  = "i"
+
+	envClientID = "RPORT_CLIENT_ID"
+	envTunnelID = "RPORT_TUNNEL_ID"
+	envUser     = "RPORT_USER"
+	envRemote   = "RPORT_REMOTE"
+)
+
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// AsciicastWriter writes events in asciicast v2 format. It's safe for concurrent use.
+type AsciicastWriter struct {
+	w       io.Writer
+	started time.Time
+	now     func() time.Time
+	mu      sync.Mutex
+}
+
+func NewAsciicastWriter(w io.Writer, header Header, now func() time.Time) (*AsciicastWriter, error) {
+	started := now()
+	header.Version = asciicastVersion
+	if header.Width == 0 {
+		header.Width = defaultWidth
+	}
+	if header.Height == 0 {
+		header.Height = defaultHeight
+	}
+	header.Timestamp = started.Unix()
+
+	b, err := json.Marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode asciicast header: %w", err)
+	}
+	if _, err := w.Write(append(b, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write asciicast header: %w", err)
+	}
+
+	return &AsciicastWriter{
+		w:       w,
+		started: started,
+		now:     now,
+	}, nil
+}
+
+// WriteEvent writes a single event of the given type. Invalid UTF-8 sequences are replaced by json encoding.
+func (a *AsciicastWriter) WriteEvent(eventType string, data []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	elapsed := a.now().Sub(a.started).Seconds()
+	b, err := json.Marshal([]interface{}{elapsed, eventType, string(data)})
+	if err != nil {
+		return fmt.Errorf("failed to encode asciicast event: %w", err)
+	}
+
+	_, err = a.w.Write(append(b, '\n'))
+	return err
+}
@@ -0,0 +1,28 @@
+package sessionrecording
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	DefaultRetention = 30 * 24 * time.Hour
+	recordingsDir    = "session-recordings"
+)
+
+type Config struct {
+	Enabled   bool          `mapstructure:"session_recording_enabled"`
+	Retention time.Duration `mapstructure:"session_recording_retention"`
+}
+
+func (c *Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.Retention < 0 {
+		return errors.New("server.session_recording_retention cannot be negative")
+	}
+
+	return nil
+}
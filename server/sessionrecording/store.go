@@ -0,0 +1,262 @@
+package sessionrecording
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/random"
+)
+
+const fileExt = ".cast"
+
+var (
+	ErrNotFound = errors.New("session recording not found")
+
+	validIDRegex = regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+)
+
+// Recording holds the metadata of a stored session recording.
+type Recording struct {
+	ID        string    `json:"id"`
+	ClientID  string    `json:"client_id"`
+	TunnelID  string    `json:"tunnel_id"`
+	User      string    `json:"user"`
+	Remote    string    `json:"remote"`
+	StartedAt time.Time `json:"started_at"`
+	Size      int64     `json:"size"`
+}
+
+// Store keeps session recordings as asciicast files in a directory.
+type Store struct {
+	dir    string
+	logger *logger.Logger
+	now    func() time.Time
+}
+
+func NewStore(dataDir string, logger *logger.Logger) (*Store, error) {
+	dir := filepath.Join(dataDir, recordingsDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create session recordings dir %q: %w", dir, err)
+	}
+
+	return &Store{
+		dir:    dir,
+		logger: logger,
+		now:    time.Now,
+	}, nil
+}
+
+// NewTunnelRecorder returns a recorder for connections of the given tunnel.
+func (s *Store) NewTunnelRecorder(clientID, tunnelID, user, remote string) *TunnelRecorder {
+	return &TunnelRecorder{
+		store:    s,
+		clientID: clientID,
+		tunnelID: tunnelID,
+		user:     user,
+		remote:   remote,
+	}
+}
+
+func (s *Store) List() ([]*Recording, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session recordings dir: %w", err)
+	}
+
+	result := make([]*Recording, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != fileExt {
+			continue
+		}
+		rec, err := s.Get(strings.TrimSuffix(entry.Name(), fileExt))
+		if err != nil {
+			s.logger.Errorf("Skipping session recording %q: %v", entry.Name(), err)
+			continue
+		}
+		result = append(result, rec)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].StartedAt.After(result[j].StartedAt)
+	})
+
+	return result, nil
+}
+
+func (s *Store) Get(id string) (*Recording, error) {
+	f, err := s.Open(id)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	line, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	header := Header{}
+	if err := json.Unmarshal(line, &header); err != nil {
+		return nil, fmt.Errorf("invalid asciicast header: %w", err)
+	}
+
+	return &Recording{
+		ID:        id,
+		ClientID:  header.Env[envClientID],
+		TunnelID:  header.Env[envTunnelID],
+		User:      header.Env[envUser],
+		Remote:    header.Env[envRemote],
+		StartedAt: time.Unix(header.Timestamp, 0),
+		Size:      info.Size(),
+	}, nil
+}
+
+// Open returns the asciicast file of the given recording, the caller is responsible for closing it.
+func (s *Store) Open(id string) (*os.File, error) {
+	if !validIDRegex.MatchString(id) {
+		return nil, ErrNotFound
+	}
+
+	f, err := os.Open(s.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// DeleteOlderThan removes recordings that were not written to since the given period.
+func (s *Store) DeleteOlderThan(period time.Duration) (int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read session recordings dir: %w", err)
+	}
+
+	deadline := s.now().Add(-period)
+	deleted := 0
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != fileExt {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return deleted, err
+		}
+		if info.ModTime().After(deadline) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, entry.Name())); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+fileExt)
+}
+
+// TunnelRecorder records connections of a single tunnel, each connection is stored as a separate recording.
+type TunnelRecorder struct {
+	store    *Store
+	clientID string
+	tunnelID string
+	user     string
+	remote   string
+}
+
+// RecordConn returns a connection that writes all data going through the given connection to a new recording.
+// Data read from the connection is recorded as input, data written to it as output.
+func (r *TunnelRecorder) RecordConn(conn io.ReadWriteCloser, connID int) (io.ReadWriteCloser, error) {
+	id, err := random.UUID4()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(r.store.path(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session recording: %w", err)
+	}
+
+	cast, err := NewAsciicastWriter(f, Header{
+		Title: fmt.Sprintf("%s tunnel %s conn#%d", r.clientID, r.remote, connID),
+		Env: map[string]string{
+			envClientID: r.clientID,
+			envTunnelID: r.tunnelID,
+			envUser:     r.user,
+			envRemote:   r.remote,
+		},
+	}, r.store.now)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &recordedConn{
+		ReadWriteCloser: conn,
+		file:            f,
+		cast:            cast,
+		logger:          r.store.logger,
+	}, nil
+}
+
+type recordedConn struct {
+	io.ReadWriteCloser
+	file      *os.File
+	cast      *AsciicastWriter
+	logger    *logger.Logger
+	closeOnce sync.Once
+	errOnce   sync.Once
+}
+
+func (c *recordedConn) Read(p []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Read(p)
+	if n > 0 {
+		c.record(EventInput, p[:n])
+	}
+	return n, err
+}
+
+func (c *recordedConn) Write(p []byte) (int, error) {
+	c.record(EventOutput, p)
+	return c.ReadWriteCloser.Write(p)
+}
+
+func (c *recordedConn) Close() error {
+	err := c.ReadWriteCloser.Close()
+	c.closeOnce.Do(func() {
+		if fErr := c.file.Close(); fErr != nil {
+			c.logger.Errorf("Failed to close session recording %q: %v", c.file.Name(), fErr)
+		}
+	})
+	return err
+}
+
+// record never fails the connection, recording errors are only logged once.
+func (c *recordedConn) record(eventType string, data []byte) {
+	if err := c.cast.WriteEvent(eventType, data); err != nil {
+		c.errOnce.Do(func() {
+			c.logger.Errorf("Failed to write session recording %q: %v", c.file.Name(), err)
+		})
+	}
+}
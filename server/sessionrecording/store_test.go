@@ -0,0 +1,118 @@
+package sessionrecording
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+var testLog = logger.NewLogger("session-recording", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+
+func TestRecordConn(t *testing.T) {
+	store, err := NewStore(t.TempDir(), testLog)
+	require.NoError(t, err)
+	started := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	calls := 0
+	store.now = func() time.Time {
+		calls++
+		return started.Add(time.Duration(calls-1) * time.Second)
+	}
+
+	server, client := net.Pipe()
+	recorder := store.NewTunnelRecorder("client-1", "2", "admin", "127.0.0.1:23")
+	conn, err := recorder.RecordConn(server, 1)
+	require.NoError(t, err)
+
+	go func() {
+		_, _ = client.Write([]byte("ls\n"))
+		buf := make([]byte, 16)
+		_, _ = client.Read(buf)
+	}()
+
+	buf := make([]byte, 16)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ls\n", string(buf[:n]))
+	_, err = conn.Write([]byte("file.txt\n"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	recordings, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, recordings, 1)
+	rec := recordings[0]
+	assert.Equal(t, "client-1", rec.ClientID)
+	assert.Equal(t, "2", rec.TunnelID)
+	assert.Equal(t, "admin", rec.User)
+	assert.Equal(t, "127.0.0.1:23", rec.Remote)
+	assert.Equal(t, started.Unix(), rec.StartedAt.Unix())
+
+	f, err := store.Open(rec.ID)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.Len(t, lines, 3)
+
+	header := Header{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &header))
+	assert.Equal(t, 2, header.Version)
+	assert.Equal(t, 80, header.Width)
+	assert.Equal(t, 24, header.Height)
+	assert.Equal(t, `[1,"i","ls\n"]`, lines[1])
+	assert.Equal(t, `[2,"o","file.txt\n"]`, lines[2])
+}
+
+func TestOpenInvalidID(t *testing.T) {
+	store, err := NewStore(t.TempDir(), testLog)
+	require.NoError(t, err)
+
+	for _, id := range []string{"", "../clients", "unknown"} {
+		_, err := store.Open(id)
+		assert.ErrorIs(t, err, ErrNotFound, id)
+	}
+}
+
+func TestDeleteOlderThan(t *testing.T) {
+	store, err := NewStore(t.TempDir(), testLog)
+	require.NoError(t, err)
+
+	recorder := store.NewTunnelRecorder("client-1", "1", "admin", "127.0.0.1:23")
+	for i := 0; i < 2; i++ {
+		server, _ := net.Pipe()
+		conn, err := recorder.RecordConn(server, i)
+		require.NoError(t, err)
+		require.NoError(t, conn.Close())
+	}
+
+	recordings, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, recordings, 2)
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(store.dir, recordings[0].ID+fileExt), old, old))
+
+	deleted, err := store.DeleteOlderThan(24 * time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	remaining, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, recordings[1].ID, remaining[0].ID)
+
+	_, err = store.Open(recordings[0].ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,67 @@
+package tunnelapproval
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Rule requires tunnels to the given schemes and/or remote ports of clients in the given client groups to be approved
+// by a user of the approver groups before the tunnel is started. Empty lists match everything.
+type Rule struct {
+	Schemes        []string `mapstructure:"schemes"`
+	Ports          []string `mapstructure:"ports"`
+	ClientGroups   []string `mapstructure:"client_groups"`
+	ApproverGroups []string `mapstructure:"approver_groups"`
+}
+
+func (r *Rule) Validate() error {
+	if len(r.Schemes) == 0 && len(r.Ports) == 0 && len(r.ClientGroups) == 0 {
+		return errors.New("at least one of schemes, ports or client_groups is required")
+	}
+	if len(r.ApproverGroups) == 0 {
+		return errors.New("approver_groups cannot be empty")
+	}
+	for _, userGroup := range r.ApproverGroups {
+		if userGroup == "" {
+			return errors.New("approver_groups cannot contain an empty group")
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the tunnel scheme, the remote port and the groups of the client match the rule.
+func (r *Rule) Matches(scheme, port string, clientGroups []string) bool {
+	if len(r.Schemes) > 0 && !contains(r.Schemes, scheme) {
+		return false
+	}
+	if len(r.Ports) > 0 && !contains(r.Ports, port) {
+		return false
+	}
+	if len(r.ClientGroups) == 0 {
+		return true
+	}
+	for _, group := range clientGroups {
+		if contains(r.ClientGroups, group) {
+			return true
+		}
+	}
+	return false
+}
+
+func ValidateRules(rules []Rule) error {
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return fmt.Errorf("invalid approval rule %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
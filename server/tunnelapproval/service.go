@@ -0,0 +1,260 @@
+package tunnelapproval
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/i18n"
+	"github.com/realvnc-labs/rport/server/notifications"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/random"
+	"github.com/realvnc-labs/rport/share/refs"
+)
+
+const (
+	DefaultTimeout = time.Hour
+
+	NotificationType refs.IdentifiableType = "tunnel_approval"
+)
+
+type Config struct {
+	Rules []Rule
+	// Timeout after which pending tunnels expire if not approved.
+	Timeout time.Duration
+}
+
+// Approver is a member of an approver group who is notified about new pending tunnels.
+type Approver struct {
+	Username string
+	Email    string
+}
+
+// ApproversFunc returns the members of the given user groups who have access to the client and an email address.
+type ApproversFunc func(ctx context.Context, clientID string, userGroups []string) ([]Approver, error)
+
+// PendingTunnel is a tunnel requested by a user that is started once approved by another user.
+type PendingTunnel struct {
+	ID             string         `json:"id"`
+	ClientID       string         `json:"client_id"`
+	ClientName     string         `json:"client_name"`
+	Remote         *models.Remote `json:"remote"`
+	RequestedBy    string         `json:"requested_by"`
+	ApproverGroups []string       `json:"approver_groups"`
+	CreatedAt      time.Time      `json:"created_at"`
+	ExpiresAt      time.Time      `json:"expires_at"`
+}
+
+// Service keeps the pending tunnels in memory, they are lost on restart of the server.
+type Service struct {
+	config     Config
+	logger     *logger.Logger
+	dispatcher notifications.Dispatcher
+	approvers  ApproversFunc
+	localizer  i18n.Localizer
+	now        func() time.Time
+
+	pending map[string]*PendingTunnel
+	mu      sync.Mutex
+}
+
+func NewService(config Config, logger *logger.Logger) *Service {
+	return &Service{
+		config:  config,
+		logger:  logger,
+		now:     time.Now,
+		pending: make(map[string]*PendingTunnel),
+	}
+}
+
+// SetDispatcher enables notifying the members of the approver groups about new pending tunnels.
+func (s *Service) SetDispatcher(dispatcher notifications.Dispatcher, approvers ApproversFunc) {
+	s.dispatcher = dispatcher
+	s.approvers = approvers
+}
+
+// SetLocalizer enables notifying in the default locale of the server, without it english is used.
+func (s *Service) SetLocalizer(localizer i18n.Localizer) {
+	s.localizer = localizer
+}
+
+// ApproverGroups returns the approver groups of all rules matching the tunnel, nil if no approval is required.
+func (s *Service) ApproverGroups(remote *models.Remote, clientGroups []string) []string {
+	scheme := ""
+	if remote.Scheme != nil {
+		scheme = *remote.Scheme
+	}
+
+	set := make(map[string]bool)
+	for i := range s.config.Rules {
+		if !s.config.Rules[i].Matches(scheme, remote.RemotePort, clientGroups) {
+			continue
+		}
+		for _, userGroup := range s.config.Rules[i].ApproverGroups {
+			set[userGroup] = true
+		}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+
+	res := make([]string, 0, len(set))
+	for userGroup := range set {
+		res = append(res, userGroup)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Request adds a pending tunnel and notifies the approvers.
+func (s *Service) Request(ctx context.Context, clientID, clientName string, remote *models.Remote, requestedBy string, approverGroups []string) (*PendingTunnel, error) {
+	id, err := random.UUID4()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	pt := &PendingTunnel{
+		ID:             id,
+		ClientID:       clientID,
+		ClientName:     clientName,
+		Remote:         remote,
+		RequestedBy:    requestedBy,
+		ApproverGroups: approverGroups,
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(s.config.Timeout),
+	}
+
+	s.mu.Lock()
+	s.pending[id] = pt
+	s.mu.Unlock()
+
+	s.notify(ctx, pt)
+
+	return pt, nil
+}
+
+// List returns the pending tunnels that are not expired, oldest first.
+func (s *Service) List() []*PendingTunnel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteExpired()
+	res := make([]*PendingTunnel, 0, len(s.pending))
+	for _, pt := range s.pending {
+		res = append(res, pt)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.Before(res[j].CreatedAt)
+	})
+	return res
+}
+
+// Get returns the pending tunnel if it is not expired.
+func (s *Service) Get(id string) (*PendingTunnel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteExpired()
+	pt, ok := s.pending[id]
+	if !ok {
+		return nil, errors.NewAPIError(http.StatusNotFound, "", fmt.Sprintf("Pending tunnel with id %q not found.", id), nil)
+	}
+	return pt, nil
+}
+
+// Approve removes the pending tunnel and returns it to be started, if the user is allowed to approve it.
+func (s *Service) Approve(id, username string, userGroups []string) (*PendingTunnel, error) {
+	return s.take(id, username, userGroups, false)
+}
+
+// Reject removes the pending tunnel, if the user is allowed to approve it or requested it.
+func (s *Service) Reject(id, username string, userGroups []string) (*PendingTunnel, error) {
+	return s.take(id, username, userGroups, true)
+}
+
+func (s *Service) take(id, username string, userGroups []string, allowRequester bool) (*PendingTunnel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteExpired()
+	pt, ok := s.pending[id]
+	if !ok {
+		return nil, errors.NewAPIError(http.StatusNotFound, "", fmt.Sprintf("Pending tunnel with id %q not found.", id), nil)
+	}
+
+	switch {
+	case pt.RequestedBy == username && allowRequester:
+	case pt.RequestedBy == username:
+		return nil, errors.NewAPIError(http.StatusForbidden, "", "A tunnel cannot be approved by the user who requested it.", nil)
+	case !pt.IsApprover(userGroups):
+		return nil, errors.NewAPIError(http.StatusForbidden, "", "You are not allowed to approve this tunnel.", nil)
+	}
+
+	delete(s.pending, id)
+	return pt, nil
+}
+
+func (s *Service) deleteExpired() {
+	now := s.now()
+	for id, pt := range s.pending {
+		if now.After(pt.ExpiresAt) {
+			s.logger.Infof("Pending tunnel %s requested by %s expired.", id, pt.RequestedBy)
+			delete(s.pending, id)
+		}
+	}
+}
+
+func (s *Service) notify(ctx context.Context, pt *PendingTunnel) {
+	if s.dispatcher == nil || s.approvers == nil {
+		return
+	}
+
+	approvers, err := s.approvers(ctx, pt.ClientID, pt.ApproverGroups)
+	if err != nil {
+		s.logger.Errorf("Failed to get the approvers of pending tunnel %s: %v", pt.ID, err)
+		return
+	}
+
+	for _, approver := range approvers {
+		// the requester can't approve the tunnel anyway
+		if approver.Username == pt.RequestedBy {
+			continue
+		}
+
+		locale := i18n.DefaultLocale
+		if s.localizer != nil {
+			locale = s.localizer.UserLocale(ctx, approver.Username)
+		}
+
+		_, err := s.dispatcher.Dispatch(ctx, refs.GenerateIdentifiable(NotificationType), notifications.NotificationData{
+			Target:     string(notifications.TargetMail),
+			Recipients: []string{approver.Email},
+			Subject:    i18n.Sprintf(locale, "Rport tunnel approval required"),
+			Content: i18n.Sprintf(
+				locale,
+				"%s requested a tunnel to %s on client %s (%s).\nIt can be approved by members of %v until %s, pending tunnel id: %s",
+				pt.RequestedBy, pt.Remote.Remote(), pt.ClientName, pt.ClientID, pt.ApproverGroups, pt.ExpiresAt.Format(time.RFC3339), pt.ID,
+			),
+			ContentType: notifications.ContentTypeTextPlain,
+		})
+		if err != nil {
+			s.logger.Errorf("Failed to dispatch tunnel approval notification to %s: %v", approver.Username, err)
+		}
+	}
+}
+
+// IsApprover tells whether a user of the given groups is allowed to approve the tunnel.
+func (pt *PendingTunnel) IsApprover(userGroups []string) bool {
+	for _, userGroup := range userGroups {
+		if contains(pt.ApproverGroups, userGroup) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package tunnelapproval
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/notifications"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/refs"
+)
+
+var testLog = logger.NewLogger("tunnel-approval-test", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+
+func newTestService(now *time.Time) *Service {
+	s := NewService(Config{
+		Rules: []Rule{
+			{Schemes: []string{"rdp"}, ApproverGroups: []string{"Administrators"}},
+			{Ports: []string{"22"}, ClientGroups: []string{"production"}, ApproverGroups: []string{"Security", "Administrators"}},
+		},
+		Timeout: time.Hour,
+	}, testLog)
+	s.now = func() time.Time {
+		return *now
+	}
+	return s
+}
+
+func newRemote(port, scheme string) *models.Remote {
+	remote := &models.Remote{RemoteHost: "0.0.0.0", RemotePort: port}
+	if scheme != "" {
+		remote.Scheme = &scheme
+	}
+	return remote
+}
+
+func TestApproverGroups(t *testing.T) {
+	now := time.Now()
+	s := newTestService(&now)
+
+	testCases := []struct {
+		name         string
+		remote       *models.Remote
+		clientGroups []string
+		expected     []string
+	}{
+		{
+			name:     "rdp",
+			remote:   newRemote("3389", "rdp"),
+			expected: []string{"Administrators"},
+		},
+		{
+			name:         "ssh to production",
+			remote:       newRemote("22", "ssh"),
+			clientGroups: []string{"linux", "production"},
+			expected:     []string{"Administrators", "Security"},
+		},
+		{
+			name:         "ssh to other group",
+			remote:       newRemote("22", "ssh"),
+			clientGroups: []string{"linux"},
+			expected:     nil,
+		},
+		{
+			name:     "no scheme",
+			remote:   newRemote("80", ""),
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, s.ApproverGroups(tc.remote, tc.clientGroups))
+		})
+	}
+}
+
+func TestApprove(t *testing.T) {
+	now := time.Now()
+	s := newTestService(&now)
+	ctx := context.Background()
+
+	pt, err := s.Request(ctx, "client-1", "Client 1", newRemote("3389", "rdp"), "user1", []string{"Administrators"})
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), pt.ExpiresAt)
+	assert.Equal(t, []*PendingTunnel{pt}, s.List())
+
+	_, err = s.Approve(pt.ID, "user1", []string{"Administrators"})
+	assertAPIError(t, err, http.StatusForbidden)
+
+	_, err = s.Approve(pt.ID, "user2", []string{"Users"})
+	assertAPIError(t, err, http.StatusForbidden)
+
+	approved, err := s.Approve(pt.ID, "user2", []string{"Users", "Administrators"})
+	require.NoError(t, err)
+	assert.Equal(t, pt, approved)
+	assert.Empty(t, s.List())
+
+	_, err = s.Approve(pt.ID, "user2", []string{"Administrators"})
+	assertAPIError(t, err, http.StatusNotFound)
+}
+
+func TestReject(t *testing.T) {
+	now := time.Now()
+	s := newTestService(&now)
+	ctx := context.Background()
+
+	pt1, err := s.Request(ctx, "client-1", "Client 1", newRemote("3389", "rdp"), "user1", []string{"Administrators"})
+	require.NoError(t, err)
+	now = now.Add(time.Minute)
+	pt2, err := s.Request(ctx, "client-1", "Client 1", newRemote("3390", "rdp"), "user1", []string{"Administrators"})
+	require.NoError(t, err)
+	assert.Equal(t, []*PendingTunnel{pt1, pt2}, s.List())
+
+	_, err = s.Reject(pt1.ID, "user2", nil)
+	assertAPIError(t, err, http.StatusForbidden)
+
+	// the requester can withdraw the request
+	_, err = s.Reject(pt1.ID, "user1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []*PendingTunnel{pt2}, s.List())
+
+	// expired
+	now = now.Add(time.Hour + time.Second)
+	assert.Empty(t, s.List())
+	_, err = s.Reject(pt2.ID, "user1", nil)
+	assertAPIError(t, err, http.StatusNotFound)
+}
+
+type dispatcherMock struct {
+	notifications []notifications.NotificationData
+}
+
+func (d *dispatcherMock) Dispatch(ctx context.Context, refID refs.Identifiable, notification notifications.NotificationData) (refs.Identifiable, error) {
+	d.notifications = append(d.notifications, notification)
+	return refID, nil
+}
+
+func TestRequestNotifiesApprovers(t *testing.T) {
+	now := time.Now()
+	s := newTestService(&now)
+	d := &dispatcherMock{}
+	var gotGroups []string
+	s.SetDispatcher(d, func(ctx context.Context, clientID string, userGroups []string) ([]Approver, error) {
+		assert.Equal(t, "client-1", clientID)
+		gotGroups = userGroups
+		return []Approver{
+			{Username: "user1", Email: "user1@example.com"},
+			{Username: "user2", Email: "user2@example.com"},
+		}, nil
+	})
+
+	pt, err := s.Request(context.Background(), "client-1", "Client 1", newRemote("22", "ssh"), "user1", []string{"Security", "Administrators"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Security", "Administrators"}, gotGroups)
+	require.Len(t, d.notifications, 1)
+	assert.Equal(t, []string{"user2@example.com"}, d.notifications[0].Recipients)
+	assert.Contains(t, d.notifications[0].Content, pt.ID)
+}
+
+func TestGet(t *testing.T) {
+	now := time.Now()
+	s := newTestService(&now)
+
+	pt, err := s.Request(context.Background(), "client-1", "Client 1", newRemote("3389", "rdp"), "user1", []string{"Administrators"})
+	require.NoError(t, err)
+
+	got, err := s.Get(pt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, pt, got)
+
+	now = now.Add(time.Hour + time.Second)
+	_, err = s.Get(pt.ID)
+	assertAPIError(t, err, http.StatusNotFound)
+}
+
+func TestValidateRules(t *testing.T) {
+	assert.NoError(t, ValidateRules([]Rule{{Schemes: []string{"ssh"}, ApproverGroups: []string{"Administrators"}}}))
+	assert.EqualError(t, ValidateRules([]Rule{{ApproverGroups: []string{"Administrators"}}}), "invalid approval rule 1: at least one of schemes, ports or client_groups is required")
+	assert.EqualError(t, ValidateRules([]Rule{{Ports: []string{"22"}}}), "invalid approval rule 1: approver_groups cannot be empty")
+	assert.EqualError(t, ValidateRules([]Rule{{Ports: []string{"22"}, ApproverGroups: []string{""}}}), "invalid approval rule 1: approver_groups cannot contain an empty group")
+}
+
+func assertAPIError(t *testing.T, err error, code int) {
+	t.Helper()
+	apiErr, ok := err.(errors.APIError)
+	require.True(t, ok, "expected APIError, got %v", err)
+	assert.Equal(t, code, apiErr.HTTPStatus)
+}
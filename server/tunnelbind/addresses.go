@@ -0,0 +1,101 @@
+// Package tunnelbind restricts the server addresses the listeners of tunnels bind to.
+package tunnelbind
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ErrNotAllowed is returned for bind addresses not allowed by the tunnel_bind_addresses config.
+var ErrNotAllowed = errors.New("bind address is not allowed")
+
+// interfaceIPs returns the IP addresses of the network interface with the given name, replaced in tests.
+var interfaceIPs = func(name string) ([]net.IP, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	var ips []net.IP
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok {
+			ips = append(ips, ipNet.IP)
+		}
+	}
+	return ips, nil
+}
+
+// ValidateAddresses checks the allowed bind addresses are IP addresses or names of network interfaces. Interfaces are
+// not required to exist, they are resolved when a tunnel is created.
+func ValidateAddresses(addresses []string) error {
+	for _, addr := range addresses {
+		if net.ParseIP(addr) != nil {
+			continue
+		}
+		if addr == "" || strings.ContainsAny(addr, ": /") {
+			return fmt.Errorf("invalid bind address %q: expected an IP address or a network interface name", addr)
+		}
+	}
+	return nil
+}
+
+// Resolve returns the IP address to bind to for the requested address, given as IP address or network interface name.
+// An IP address must be one of the allowed addresses or an address of an allowed interface, an interface must be
+// allowed by its name. Interfaces are resolved to their first IPv4 address, if they have none to the first IPv6 one.
+func Resolve(allowed []string, addr string) (string, error) {
+	if ip := net.ParseIP(addr); ip != nil {
+		for _, a := range allowed {
+			if aIP := net.ParseIP(a); aIP != nil {
+				if aIP.Equal(ip) {
+					return ip.String(), nil
+				}
+				continue
+			}
+			ips, err := interfaceIPs(a)
+			if err != nil {
+				continue
+			}
+			for _, ifaceIP := range ips {
+				if ifaceIP.Equal(ip) {
+					return ip.String(), nil
+				}
+			}
+		}
+		return "", fmt.Errorf("%w: %s", ErrNotAllowed, addr)
+	}
+
+	if !contains(allowed, addr) {
+		return "", fmt.Errorf("%w: %s", ErrNotAllowed, addr)
+	}
+	ips, err := interfaceIPs(addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to get addresses of interface %q: %v", addr, err)
+	}
+	var ipv6 net.IP
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			return ip.String(), nil
+		}
+		if ipv6 == nil {
+			ipv6 = ip
+		}
+	}
+	if ipv6 != nil {
+		return ipv6.String(), nil
+	}
+	return "", fmt.Errorf("interface %q has no IP address", addr)
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
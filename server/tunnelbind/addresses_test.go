@@ -0,0 +1,64 @@
+package tunnelbind
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAddresses(t *testing.T) {
+	assert.NoError(t, ValidateAddresses([]string{"10.0.0.5", "0.0.0.0", "fd00::1", "eth1"}))
+	assert.EqualError(t, ValidateAddresses([]string{"10.0.0.5:3000"}), `invalid bind address "10.0.0.5:3000": expected an IP address or a network interface name`)
+	assert.EqualError(t, ValidateAddresses([]string{""}), `invalid bind address "": expected an IP address or a network interface name`)
+}
+
+func TestResolve(t *testing.T) {
+	orig := interfaceIPs
+	defer func() { interfaceIPs = orig }()
+	interfaceIPs = func(name string) ([]net.IP, error) {
+		switch name {
+		case "eth1":
+			return []net.IP{net.ParseIP("fd00::10"), net.ParseIP("192.168.10.1"), net.ParseIP("192.168.10.2")}, nil
+		case "eth2":
+			return []net.IP{net.ParseIP("fd00::20")}, nil
+		case "eth3":
+			return nil, nil
+		}
+		return nil, errors.New("no such network interface")
+	}
+
+	allowed := []string{"10.0.0.5", "eth1", "eth2", "eth3", "eth4"}
+
+	testCases := []struct {
+		addr    string
+		want    string
+		wantErr string
+	}{
+		{addr: "10.0.0.5", want: "10.0.0.5"},
+		{addr: "192.168.10.2", want: "192.168.10.2"},
+		{addr: "eth1", want: "192.168.10.1"},
+		{addr: "eth2", want: "fd00::20"},
+		{addr: "10.0.0.6", wantErr: "bind address is not allowed: 10.0.0.6"},
+		{addr: "0.0.0.0", wantErr: "bind address is not allowed: 0.0.0.0"},
+		{addr: "eth0", wantErr: "bind address is not allowed: eth0"},
+		{addr: "eth3", wantErr: `interface "eth3" has no IP address`},
+		{addr: "eth4", wantErr: `failed to get addresses of interface "eth4": no such network interface`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.addr, func(t *testing.T) {
+			got, err := Resolve(allowed, tc.addr)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	_, err := Resolve(nil, "10.0.0.5")
+	assert.True(t, errors.Is(err, ErrNotAllowed))
+}
@@ -0,0 +1,109 @@
+package tunnelschemes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	apiErrors "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/clients/clienttunnel"
+	"github.com/realvnc-labs/rport/server/validation"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+// Scheme defines the defaults of tunnels created with the scheme. The port is used if the tunnel is created without a
+// remote, ACL and idle timeout are used if not given with the tunnel.
+type Scheme struct {
+	Name               string `mapstructure:"scheme"`
+	Port               string `mapstructure:"port"`
+	ACL                string `mapstructure:"acl"`
+	IdleTimeoutMinutes *int   `mapstructure:"idle_timeout_minutes"`
+}
+
+// Defaults are the default ports of the well known schemes, they can be overridden by the server configuration.
+var Defaults = []Scheme{
+	{Name: "ssh", Port: "22"},
+	{Name: "rdp", Port: "3389"},
+	{Name: "vnc", Port: "5900"},
+	{Name: "http", Port: "80"},
+}
+
+func (s *Scheme) Validate() error {
+	if s.Name == "" {
+		return errors.New("scheme cannot be empty")
+	}
+	port, err := strconv.Atoi(s.Port)
+	if err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("invalid port %q", s.Port)
+	}
+	if _, err := clienttunnel.ParseTunnelACL(s.ACL); err != nil {
+		return fmt.Errorf("invalid acl: %v", err)
+	}
+	if s.IdleTimeoutMinutes != nil {
+		if _, err := validation.ResolveIdleTunnelTimeoutValue(strconv.Itoa(*s.IdleTimeoutMinutes), false); err != nil {
+			return fmt.Errorf("invalid idle_timeout_minutes: %v", err)
+		}
+	}
+	return nil
+}
+
+func ValidateSchemes(schemes []Scheme) error {
+	names := make(map[string]bool, len(schemes))
+	for i := range schemes {
+		if err := schemes[i].Validate(); err != nil {
+			return fmt.Errorf("invalid tunnel scheme %d: %v", i+1, err)
+		}
+		if names[schemes[i].Name] {
+			return fmt.Errorf("duplicate tunnel scheme %q", schemes[i].Name)
+		}
+		names[schemes[i].Name] = true
+	}
+	return nil
+}
+
+// Schemes holds the tunnel defaults by scheme name.
+type Schemes map[string]*Scheme
+
+// New returns the Defaults merged with the configured schemes, configured schemes take precedence.
+func New(configured []Scheme) Schemes {
+	schemes := make(Schemes, len(Defaults)+len(configured))
+	for _, list := range [][]Scheme{Defaults, configured} {
+		for i := range list {
+			s := list[i]
+			schemes[s.Name] = &s
+		}
+	}
+	return schemes
+}
+
+// Get returns the scheme with the given name or nil if not known.
+func (s Schemes) Get(name string) *Scheme {
+	return s[name]
+}
+
+// ApplyDefaults sets the remote port and the ACL of the remote from its scheme if not given. Returns an APIError if
+// the remote has no port and its scheme has no default port.
+func (s Schemes) ApplyDefaults(remote *models.Remote) error {
+	var scheme *Scheme
+	if remote.Scheme != nil {
+		scheme = s.Get(*remote.Scheme)
+	}
+
+	if remote.RemotePort == "" && !remote.SOCKS5 {
+		if scheme == nil {
+			return apiErrors.NewAPIError(http.StatusBadRequest, "", "Remote port is required for tunnels without a scheme with a default port.", nil)
+		}
+		remote.RemotePort = scheme.Port
+		if remote.RemoteHost == "" {
+			remote.RemoteHost = models.LocalHost
+		}
+	}
+
+	if scheme != nil && remote.ACL == nil && scheme.ACL != "" {
+		acl := scheme.ACL
+		remote.ACL = &acl
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package tunnelschemes
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiErrors "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	schemes := New([]Scheme{
+		{Name: "ssh", Port: "2222", ACL: "10.0.0.0/8"},
+		{Name: "mysql", Port: "3306"},
+	})
+	ssh := "ssh"
+	rdp := "rdp"
+	unknown := "unknown"
+	acl := "192.168.0.1"
+
+	testCases := []struct {
+		Name     string
+		Remote   *models.Remote
+		Expected *models.Remote
+	}{
+		{
+			Name:     "configured scheme",
+			Remote:   &models.Remote{Scheme: &ssh},
+			Expected: &models.Remote{Scheme: &ssh, RemoteHost: models.LocalHost, RemotePort: "2222", ACL: strPtr("10.0.0.0/8")},
+		},
+		{
+			Name:     "default scheme",
+			Remote:   &models.Remote{Scheme: &rdp},
+			Expected: &models.Remote{Scheme: &rdp, RemoteHost: models.LocalHost, RemotePort: "3389"},
+		},
+		{
+			Name:     "port and acl given",
+			Remote:   &models.Remote{Scheme: &ssh, RemoteHost: "10.0.0.1", RemotePort: "22", ACL: &acl},
+			Expected: &models.Remote{Scheme: &ssh, RemoteHost: "10.0.0.1", RemotePort: "22", ACL: &acl},
+		},
+		{
+			Name:     "unknown scheme with port",
+			Remote:   &models.Remote{Scheme: &unknown, RemoteHost: "10.0.0.1", RemotePort: "22"},
+			Expected: &models.Remote{Scheme: &unknown, RemoteHost: "10.0.0.1", RemotePort: "22"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			require.NoError(t, schemes.ApplyDefaults(tc.Remote))
+			assert.Equal(t, tc.Expected, tc.Remote)
+		})
+	}
+
+	t.Run("without port", func(t *testing.T) {
+		err := schemes.ApplyDefaults(&models.Remote{Scheme: &unknown})
+		var apiErr apiErrors.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusBadRequest, apiErr.HTTPStatus)
+	})
+}
+
+func TestValidateSchemes(t *testing.T) {
+	idle := 10
+	invalidIdle := -1
+
+	testCases := []struct {
+		Name          string
+		Schemes       []Scheme
+		ExpectedError string
+	}{
+		{
+			Name:    "valid",
+			Schemes: []Scheme{{Name: "ssh", Port: "22", ACL: "10.0.0.0/8", IdleTimeoutMinutes: &idle}},
+		},
+		{
+			Name:          "empty scheme",
+			Schemes:       []Scheme{{Port: "22"}},
+			ExpectedError: "invalid tunnel scheme 1: scheme cannot be empty",
+		},
+		{
+			Name:          "invalid port",
+			Schemes:       []Scheme{{Name: "ssh", Port: "65536"}},
+			ExpectedError: `invalid tunnel scheme 1: invalid port "65536"`,
+		},
+		{
+			Name:          "invalid acl",
+			Schemes:       []Scheme{{Name: "ssh", Port: "22", ACL: "invalid"}},
+			ExpectedError: "invalid tunnel scheme 1: invalid acl",
+		},
+		{
+			Name:          "invalid idle timeout",
+			Schemes:       []Scheme{{Name: "ssh", Port: "22", IdleTimeoutMinutes: &invalidIdle}},
+			ExpectedError: "invalid tunnel scheme 1: invalid idle_timeout_minutes",
+		},
+		{
+			Name:          "duplicate",
+			Schemes:       []Scheme{{Name: "ssh", Port: "22"}, {Name: "ssh", Port: "2222"}},
+			ExpectedError: `duplicate tunnel scheme "ssh"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := ValidateSchemes(tc.Schemes)
+			if tc.ExpectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.ExpectedError)
+			}
+		})
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,203 @@
+package updatesrefresh
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/realvnc-labs/rport/server/clients/clientdata"
+	"github.com/realvnc-labs/rport/share/comm"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/random"
+)
+
+const (
+	// Command is the command of the jobs refreshing the updates status, it's not executed on the client
+	Command = "refresh updates status"
+
+	DefaultMinInterval = time.Minute
+	DefaultTimeout     = 10 * time.Minute
+)
+
+var (
+	ErrRateLimited = errors.New("updates status was refreshed recently, try again later")
+	ErrInProgress  = errors.New("updates status refresh is already in progress")
+)
+
+type JobProvider interface {
+	CreateJob(job *models.Job) error
+	SaveJob(job *models.Job) error
+}
+
+// Refresher requests an immediate updates status refresh from clients. A job is created for each client, it's
+// finished with the updates status once the client sends it or failed after the timeout.
+type Refresher struct {
+	jobs        JobProvider
+	minInterval time.Duration
+	timeout     time.Duration
+	logger      *logger.Logger
+	now         func() time.Time
+
+	lastRequests map[string]time.Time
+	pending      map[string]*pendingRefresh
+	mu           sync.Mutex
+}
+
+type pendingRefresh struct {
+	// job is a copy of the created job, so it's not shared with the request
+	job   models.Job
+	timer *time.Timer
+}
+
+func New(jobs JobProvider, minInterval, timeout time.Duration, logger *logger.Logger) *Refresher {
+	if timeout == 0 {
+		// a refresh cannot wait forever, the client would be blocked for further refreshes
+		timeout = DefaultTimeout
+	}
+	return &Refresher{
+		jobs:         jobs,
+		minInterval:  minInterval,
+		timeout:      timeout,
+		logger:       logger,
+		now:          time.Now,
+		lastRequests: make(map[string]time.Time),
+		pending:      make(map[string]*pendingRefresh),
+	}
+}
+
+// Request sends the refresh request to the client and persists the job. Errors of the client are recorded as
+// failed job, an error is returned only if the job cannot be persisted.
+func (r *Refresher) Request(client *clientdata.Client, multiJobID *string, createdBy, correlationID string) (*models.Job, error) {
+	jid, err := random.UUID4()
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.Job{
+		JID:        jid,
+		Status:     models.JobStatusRunning,
+		StartedAt:  r.now(),
+		ClientID:   client.GetID(),
+		ClientName: client.GetName(),
+		Command:    Command,
+		CreatedBy:  createdBy,
+		TimeoutSec: int(r.timeout.Seconds()),
+		MultiJobID: multiJobID,
+
+		CorrelationID: correlationID,
+	}
+
+	err = r.send(client, job)
+	if err != nil {
+		r.logger.Infof("%s, failed to request updates status refresh: %v", job.LogPrefix(), err)
+		now := r.now()
+		job.Status = models.JobStatusFailed
+		job.FinishedAt = &now
+		job.Error = err.Error()
+	}
+
+	// a status received meanwhile already saved the job, then create does nothing
+	return job, r.jobs.CreateJob(job)
+}
+
+func (r *Refresher) send(client *clientdata.Client, job *models.Job) error {
+	if client.IsCheckInOnly() {
+		return errors.New("client is in check-in only mode")
+	}
+	if client.IsPaused() {
+		return fmt.Errorf("client is paused (reason = %s)", client.GetPausedReason())
+	}
+	conn := client.GetConnection()
+	if conn == nil {
+		return errors.New("client is not connected")
+	}
+
+	clientID := client.GetID()
+	r.mu.Lock()
+	if r.pending[clientID] != nil {
+		r.mu.Unlock()
+		return ErrInProgress
+	}
+	if last, ok := r.lastRequests[clientID]; ok && r.now().Sub(last) < r.minInterval {
+		r.mu.Unlock()
+		return ErrRateLimited
+	}
+	r.lastRequests[clientID] = r.now()
+	p := &pendingRefresh{job: *job}
+	r.pending[clientID] = p
+	p.timer = time.AfterFunc(r.timeout, func() {
+		r.expire(clientID, p)
+	})
+	r.mu.Unlock()
+
+	err := comm.SendRequestAndGetResponse(conn, comm.RequestTypeRefreshUpdatesStatus, nil, nil, r.logger)
+	if err != nil {
+		r.mu.Lock()
+		if r.pending[clientID] == p {
+			delete(r.pending, clientID)
+		}
+		r.mu.Unlock()
+		p.timer.Stop()
+		return err
+	}
+	return nil
+}
+
+// Done finishes the pending refresh job of the client with the given updates status.
+func (r *Refresher) Done(clientID string, status *models.UpdatesStatus) {
+	r.mu.Lock()
+	p := r.pending[clientID]
+	delete(r.pending, clientID)
+	r.mu.Unlock()
+	if p == nil {
+		return
+	}
+	p.timer.Stop()
+
+	job := &p.job
+	now := r.now()
+	job.FinishedAt = &now
+	job.Status = models.JobStatusSuccessful
+	if status.Error != "" {
+		job.Status = models.JobStatusFailed
+		job.Error = status.Error
+	}
+	stdOut, err := json.Marshal(status)
+	if err != nil {
+		r.logger.Errorf("%s, failed to encode updates status: %v", job.LogPrefix(), err)
+	}
+	job.Result = &models.JobResult{
+		StdOut:  string(stdOut),
+		Summary: fmt.Sprintf("%d updates available, %d security updates", status.UpdatesAvailable, status.SecurityUpdatesAvailable),
+	}
+
+	r.save(job)
+}
+
+func (r *Refresher) expire(clientID string, p *pendingRefresh) {
+	r.mu.Lock()
+	if r.pending[clientID] != p {
+		r.mu.Unlock()
+		return
+	}
+	delete(r.pending, clientID)
+	r.mu.Unlock()
+
+	job := &p.job
+	now := r.now()
+	job.FinishedAt = &now
+	job.Status = models.JobStatusFailed
+	job.Error = fmt.Sprintf("no updates status received within %s", r.timeout)
+
+	r.save(job)
+}
+
+func (r *Refresher) save(job *models.Job) {
+	err := r.jobs.SaveJob(job)
+	if err != nil {
+		r.logger.Errorf("%s, failed to save updates status refresh job: %v", job.LogPrefix(), err)
+	}
+}
@@ -0,0 +1,169 @@
+package updatesrefresh
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/server/clients"
+	"github.com/realvnc-labs/rport/share/comm"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/models"
+	"github.com/realvnc-labs/rport/share/test"
+)
+
+var testLog = logger.NewLogger("updates-refresh-test", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+
+type jobProviderMock struct {
+	mu      sync.Mutex
+	created []models.Job
+	saved   chan models.Job
+}
+
+func newJobProviderMock() *jobProviderMock {
+	return &jobProviderMock{
+		saved: make(chan models.Job, 1),
+	}
+}
+
+func (p *jobProviderMock) CreateJob(job *models.Job) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.created = append(p.created, *job)
+	return nil
+}
+
+func (p *jobProviderMock) SaveJob(job *models.Job) error {
+	p.saved <- *job
+	return nil
+}
+
+func TestRequest(t *testing.T) {
+	now := time.Now()
+	conn := test.NewConnMock()
+	conn.ReturnOk = true
+	client := clients.New(t).ID("client-1").Connection(conn).Logger(testLog).Build()
+	jobs := newJobProviderMock()
+	r := New(jobs, time.Minute, time.Hour, testLog)
+	r.now = func() time.Time {
+		return now
+	}
+	multiJobID := "multi-job-1"
+
+	job, err := r.Request(client, &multiJobID, "admin", "")
+	require.NoError(t, err)
+
+	name, _, _ := conn.InputSendRequest()
+	assert.Equal(t, comm.RequestTypeRefreshUpdatesStatus, name)
+	assert.Equal(t, models.JobStatusRunning, job.Status)
+	assert.Equal(t, "client-1", job.ClientID)
+	assert.Equal(t, Command, job.Command)
+	assert.Equal(t, "admin", job.CreatedBy)
+	assert.Equal(t, &multiJobID, job.MultiJobID)
+	assert.Len(t, jobs.created, 1)
+
+	// a second refresh is rejected while the first is in progress
+	job2, err := r.Request(client, nil, "admin", "")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusFailed, job2.Status)
+	assert.Equal(t, ErrInProgress.Error(), job2.Error)
+
+	r.Done("client-1", &models.UpdatesStatus{UpdatesAvailable: 3, SecurityUpdatesAvailable: 1})
+	saved := <-jobs.saved
+	assert.Equal(t, job.JID, saved.JID)
+	assert.Equal(t, models.JobStatusSuccessful, saved.Status)
+	require.NotNil(t, saved.Result)
+	assert.Equal(t, "3 updates available, 1 security updates", saved.Result.Summary)
+	assert.Contains(t, saved.Result.StdOut, `"updates_available":3`)
+
+	// the refresh is rate limited
+	now = now.Add(30 * time.Second)
+	job3, err := r.Request(client, nil, "admin", "")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusFailed, job3.Status)
+	assert.Equal(t, ErrRateLimited.Error(), job3.Error)
+
+	now = now.Add(time.Minute)
+	job4, err := r.Request(client, nil, "admin", "")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusRunning, job4.Status)
+}
+
+func TestRequestClientErrors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		connected     bool
+		paused        bool
+		sendErr       error
+		expectedError string
+	}{
+		{
+			name:          "not connected",
+			expectedError: "client is not connected",
+		},
+		{
+			name:          "paused",
+			connected:     true,
+			paused:        true,
+			expectedError: "client is paused (reason = test)",
+		},
+		{
+			name:          "send error",
+			connected:     true,
+			sendErr:       errors.New("send failed"),
+			expectedError: "send failed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			builder := clients.New(t).Logger(testLog)
+			if tc.connected {
+				conn := test.NewConnMock()
+				conn.ReturnOk = true
+				conn.ReturnErr = tc.sendErr
+				builder = builder.Connection(conn)
+			}
+			client := builder.Build()
+			client.SetPaused(tc.paused, "test")
+			jobs := newJobProviderMock()
+			r := New(jobs, time.Minute, time.Hour, testLog)
+
+			job, err := r.Request(client, nil, "admin", "")
+			require.NoError(t, err)
+
+			assert.Equal(t, models.JobStatusFailed, job.Status)
+			assert.Contains(t, job.Error, tc.expectedError)
+			assert.NotNil(t, job.FinishedAt)
+			assert.Len(t, jobs.created, 1)
+		})
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	conn := test.NewConnMock()
+	conn.ReturnOk = true
+	client := clients.New(t).ID("client-1").Connection(conn).Logger(testLog).Build()
+	jobs := newJobProviderMock()
+	r := New(jobs, 0, 10*time.Millisecond, testLog)
+
+	job, err := r.Request(client, nil, "admin", "")
+	require.NoError(t, err)
+
+	saved := <-jobs.saved
+	assert.Equal(t, job.JID, saved.JID)
+	assert.Equal(t, models.JobStatusFailed, saved.Status)
+	assert.Equal(t, "no updates status received within 10ms", saved.Error)
+
+	// a status received after the timeout is ignored
+	r.Done("client-1", &models.UpdatesStatus{})
+	assert.Empty(t, jobs.saved)
+}
@@ -1,8 +1,10 @@
 package chserver
 
 import (
+	"crypto/md5"
 	"encoding/json"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"path/filepath"
@@ -27,16 +29,22 @@ import (
 	"github.com/pkg/errors"
 )
 
-const uploadBufSize = 1000000 // 1Mb
+const (
+	uploadFormFileKey      = "upload"
+	maxUploadFormValueSize = 1000000 // 1Mb
+)
 
 type UploadRequest struct {
-	File                 multipart.File
-	FileHeader           *multipart.FileHeader
+	Filename             string
+	ContentType          string
 	ClientIDs            []string
 	GroupIDs             []string
 	ClientTags           *models.JobClientTags
 	clientsInGroupsCount int
 	Clients              []*clientdata.Client
+	// stagedFilePath is the temporary location of the file streamed from the request until the upload is accepted
+	stagedFilePath string
+	stagedBytes    int64
 	*models.UploadedFile
 }
 
@@ -53,18 +61,11 @@ func (ur UploadRequest) GetClientTags() (clientTags *models.JobClientTags) {
 }
 
 func (al *APIListener) handleFileUploads(w http.ResponseWriter, req *http.Request) {
-	uploadRequest, err := al.uploadRequestFromRequest(req)
-	if err != nil {
-		al.jsonError(w, err)
-		return
-	}
-
 	curUser, err := al.getUserModelForAuth(req.Context())
 	if err != nil {
 		al.jsonErrorResponseWithTitle(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	defer uploadRequest.File.Close()
 
 	wasCreated, err := al.filesAPI.CreateDirIfNotExists(al.config.GetUploadDir(), files.DefaultMode)
 	if err != nil {
@@ -75,6 +76,13 @@ func (al *APIListener) handleFileUploads(w http.ResponseWriter, req *http.Reques
 		al.Infof("created directory %s", al.config.GetUploadDir())
 	}
 
+	uploadRequest, err := al.uploadRequestFromRequest(req)
+	if err != nil {
+		al.jsonError(w, err)
+		return
+	}
+	defer al.removeStagedFile(uploadRequest)
+
 	uploadRequest.SourceFilePath = al.genFilePath(uploadRequest.ID)
 
 	err = uploadRequest.Validate()
@@ -98,33 +106,21 @@ func (al *APIListener) handleFileUploads(w http.ResponseWriter, req *http.Reques
 		return
 	}
 
-	copiedBytes, err := al.filesAPI.CreateFile(uploadRequest.SourceFilePath, uploadRequest.File)
+	err = al.filesAPI.Rename(uploadRequest.stagedFilePath, uploadRequest.SourceFilePath)
 	if err != nil {
 		al.jsonError(w, err)
 		return
 	}
-
-	file, err := al.filesAPI.Open(uploadRequest.SourceFilePath)
-	if err != nil {
-		al.jsonError(w, err)
-		return
-	}
-
-	md5Checksum, err := files.Md5HashFromReader(file)
-	if err != nil {
-		al.jsonError(w, err)
-		return
-	}
-
-	uploadRequest.Md5Checksum = md5Checksum
+	uploadRequest.stagedFilePath = ""
+	copiedBytes := uploadRequest.stagedBytes
 
 	al.Debugf(
 		"stored file %s on server, size %d, Content-Type %s, temp location: %s, md5 checksum: %x",
-		uploadRequest.FileHeader.Filename,
-		uploadRequest.FileHeader.Size,
-		uploadRequest.FileHeader.Header.Get("Content-Type"),
+		uploadRequest.Filename,
+		copiedBytes,
+		uploadRequest.ContentType,
 		uploadRequest.SourceFilePath,
-		md5Checksum,
+		uploadRequest.Md5Checksum,
 	)
 
 	uploadRep := &models.UploadResponseShort{
@@ -269,6 +265,24 @@ func (al *APIListener) consumeUploadResults(resChan chan *uploadResult, uploadRe
 func (al *APIListener) sendFileToClient(wg *sync.WaitGroup, file *models.UploadedFile, cl *clientdata.Client, resChan chan *uploadResult) {
 	defer wg.Done()
 
+	if cl.IsCheckInOnly() {
+		resChan <- &uploadResult{
+			err:    ErrClientCheckInOnly,
+			client: cl,
+			resp:   nil,
+		}
+		return
+	}
+
+	if err := cl.CheckQuarantine(); err != nil {
+		resChan <- &uploadResult{
+			err:    err,
+			client: cl,
+			resp:   nil,
+		}
+		return
+	}
+
 	fileReceptionConfig := cl.GetFileReceptionConfig()
 	if fileReceptionConfig != nil && !fileReceptionConfig.Enabled {
 		resChan <- &uploadResult{
@@ -301,11 +315,18 @@ func (al *APIListener) notifyUploadEventListeners(msg interface{}) {
 }
 
 func (al *APIListener) uploadRequestFromRequest(req *http.Request) (ur *UploadRequest, err error) {
-	ur = &UploadRequest{
+	staged := &UploadRequest{
 		UploadedFile: &models.UploadedFile{},
 	}
+	ur = staged
+	// the staged file must not be left behind if the request is rejected
+	defer func() {
+		if err != nil {
+			al.removeStagedFile(staged)
+		}
+	}()
 
-	err = req.ParseMultipartForm(uploadBufSize)
+	err = al.readMultipartForm(req, ur)
 	if err != nil {
 		return nil, &errors2.APIError{
 			Err:        err,
@@ -342,26 +363,107 @@ func (al *APIListener) uploadRequestFromRequest(req *http.Request) (ur *UploadRe
 		}
 	}
 
-	ur.File, ur.FileHeader, err = req.FormFile("upload")
-	if err != nil {
+	if ur.stagedFilePath == "" {
 		return nil, &errors2.APIError{
-			Err:        err,
+			Err:        http.ErrMissingFile,
 			HTTPStatus: http.StatusBadRequest,
 		}
 	}
 
 	if ur.UploadedFile.ID == "" {
-		id, e := random.UUID4()
-		if e != nil {
-			al.Errorf("failed to generate uuid, will fallback to timestamp uuid, error: %v", e)
-			id = fmt.Sprintf("%d", time.Now().UnixNano())
-		}
-		ur.UploadedFile.ID = id
+		ur.UploadedFile.ID = al.newUploadID()
 	}
 
 	return ur, nil
 }
 
+// readMultipartForm reads the multipart request part by part. Instead of buffering the uploaded file, it's streamed
+// to a staging file in the upload dir. All other form values are stored in req.MultipartForm.
+func (al *APIListener) readMultipartForm(req *http.Request, ur *UploadRequest) error {
+	reader, err := req.MultipartReader()
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string][]string)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name := part.FormName()
+		if name == "" {
+			continue
+		}
+
+		if part.FileName() == "" {
+			value, err := io.ReadAll(io.LimitReader(part, maxUploadFormValueSize+1))
+			if err != nil {
+				return err
+			}
+			if len(value) > maxUploadFormValueSize {
+				return fmt.Errorf("form value %q exceeds the limit of %d bytes", name, maxUploadFormValueSize)
+			}
+			values[name] = append(values[name], string(value))
+			continue
+		}
+
+		// only the first file is used, other files are skipped
+		if name != uploadFormFileKey || ur.stagedFilePath != "" {
+			continue
+		}
+
+		err = al.stageUploadedFile(part, ur)
+		if err != nil {
+			return err
+		}
+	}
+
+	req.MultipartForm = &multipart.Form{
+		Value: values,
+	}
+	return nil
+}
+
+func (al *APIListener) stageUploadedFile(part *multipart.Part, ur *UploadRequest) error {
+	ur.stagedFilePath = filepath.Join(al.config.GetUploadDir(), fmt.Sprintf("%s_rport_filepush.part", al.newUploadID()))
+	ur.Filename = part.FileName()
+	ur.ContentType = part.Header.Get("Content-Type")
+
+	md5Hash := md5.New()
+	copiedBytes, err := al.filesAPI.CreateFile(ur.stagedFilePath, io.TeeReader(part, md5Hash))
+	if err != nil {
+		return err
+	}
+
+	ur.stagedBytes = copiedBytes
+	ur.Md5Checksum = md5Hash.Sum(nil)
+	return nil
+}
+
+func (al *APIListener) removeStagedFile(ur *UploadRequest) {
+	if ur.stagedFilePath == "" {
+		return
+	}
+	if err := al.filesAPI.Remove(ur.stagedFilePath); err != nil {
+		al.Errorf("failed to remove staged upload %s: %v", ur.stagedFilePath, err)
+	}
+	ur.stagedFilePath = ""
+}
+
+func (al *APIListener) newUploadID() string {
+	id, err := random.UUID4()
+	if err != nil {
+		al.Errorf("failed to generate uuid, will fallback to timestamp uuid, error: %v", err)
+		id = fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return id
+}
+
 func getClientTagsFromReqForm(req *http.Request) (clientTags *models.JobClientTags, err error) {
 	jsonTags := req.MultipartForm.Value["tags"]
 
@@ -18,6 +18,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/realvnc-labs/rport/server/api"
+	"github.com/realvnc-labs/rport/server/api/middleware"
 	"github.com/realvnc-labs/rport/server/api/users"
 	"github.com/realvnc-labs/rport/server/chconfig"
 	"github.com/realvnc-labs/rport/server/clients"
@@ -45,13 +46,12 @@ func FsCallback(fs *test.FileAPIMock, t *testing.T) {
 
 		return string(actualFileContent) == "some content"
 	}
-	fs.On("CreateFile", "/data/filepush/id-123_rport_filepush", mock.MatchedBy(fileExpectation)).Return(int64(10), nil)
-
-	fileMock := &test.ReadWriteCloserMock{}
-	fileMock.Reader = strings.NewReader("some content")
-	fileMock.On("Close").Return(nil)
-
-	fs.On("Open", "/data/filepush/id-123_rport_filepush").Return(fileMock, nil)
+	stagedFile := mock.MatchedBy(func(path string) bool {
+		return strings.HasPrefix(path, "/data/filepush/") && strings.HasSuffix(path, "_rport_filepush.part")
+	})
+	fs.On("CreateFile", stagedFile, mock.MatchedBy(fileExpectation)).Return(int64(10), nil)
+	fs.On("Rename", stagedFile, "/data/filepush/id-123_rport_filepush").Return(nil)
+	fs.On("Remove", stagedFile).Return(nil)
 	fs.On("Remove", "/data/filepush/id-123_rport_filepush").Return(nil)
 }
 
@@ -396,10 +396,16 @@ func TestHandleFileUploads(t *testing.T) {
 			t.Logf("Got response %s", rec.Body)
 			assert.Equal(t, tc.wantStatus, rec.Code)
 			if tc.wantErrTitle != "" {
-				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail)
+				wantResp := api.NewErrAPIPayloadFromMessage(tc.wantErrCode, tc.wantErrTitle, tc.wantErrDetail).WithDefaultCode(tc.wantStatus)
+				wantResp.RequestID = rec.Header().Get(middleware.RequestIDHeader)
 				wantRespBytes, err := json.Marshal(wantResp)
 				require.NoError(t, err)
 				require.Equal(t, string(wantRespBytes), rec.Body.String())
+				// the file streamed from the rejected request must be removed
+				fileAPIMock.AssertCalled(t, "Remove", mock.MatchedBy(func(path string) bool {
+					return strings.HasSuffix(path, "_rport_filepush.part")
+				}))
+				fileAPIMock.AssertNotCalled(t, "Rename", mock.Anything, mock.Anything)
 				return
 			}
 
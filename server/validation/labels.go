@@ -0,0 +1,30 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const maxLabels = 20
+const maxLabelLength = 100
+
+// ValidateLabels checks labels attached to jobs and tunnels. Labels are stored comma separated in the audit log,
+// so they must not contain commas.
+func ValidateLabels(labels []string) error {
+	if len(labels) > maxLabels {
+		return fmt.Errorf("at most %d labels are allowed", maxLabels)
+	}
+	for _, label := range labels {
+		if strings.TrimSpace(label) == "" {
+			return errors.New("label cannot be empty")
+		}
+		if strings.Contains(label, ",") {
+			return fmt.Errorf("label %q cannot contain a comma", label)
+		}
+		if len(label) > maxLabelLength {
+			return fmt.Errorf("label %q exceeds %d characters", label, maxLabelLength)
+		}
+	}
+	return nil
+}
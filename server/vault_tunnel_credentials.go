@@ -0,0 +1,35 @@
+package chserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/realvnc-labs/rport/server/vault"
+)
+
+// vaultTunnelCredentials resolves the passwords injected by the rdp and vnc tunnel proxies. The vault access is checked
+// against the current groups of the tunnel owner.
+type vaultTunnelCredentials struct {
+	vaultManager *vault.Manager
+	userService  UserService
+}
+
+func (c *vaultTunnelCredentials) GetTunnelPassword(ctx context.Context, vaultID int, owner string) (string, error) {
+	user, err := c.userService.GetByUsername(owner)
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		return "", fmt.Errorf("tunnel owner %q not found", owner)
+	}
+
+	value, found, err := c.vaultManager.GetOne(ctx, vaultID, user)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", fmt.Errorf("vault value with id %d not found", vaultID)
+	}
+
+	return value.Value, nil
+}
@@ -0,0 +1,51 @@
+package webpush
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/realvnc-labs/rport/server/notifications"
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+const (
+	MessageTypeNotification       = "notification"
+	MessageTypeClientDisconnected = "client_disconnected"
+)
+
+// consumer sends the notifications with the webpush target, the recipients are usernames.
+type consumer struct {
+	service *Service
+	logger  *logger.Logger
+}
+
+func NewConsumer(service *Service, logger *logger.Logger) notifications.Consumer {
+	return consumer{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (c consumer) Process(ctx context.Context, details notifications.NotificationDetails) (string, error) {
+	msg := &Message{
+		Type:  MessageTypeNotification,
+		Title: details.Data.Subject,
+		Body:  details.Data.Content,
+	}
+	clientDisconnects := details.RefID != nil && details.RefID.Type() == ClientDisconnectedNotificationType
+	if clientDisconnects {
+		msg.Type = MessageTypeClientDisconnected
+		msg.ClientID = details.RefID.ID()
+	}
+
+	sent, err := c.service.Send(ctx, details.Data.Recipients, msg, clientDisconnects)
+	out := fmt.Sprintf("sent to %d subscription(s)", sent)
+	if err != nil {
+		c.logger.Errorf("notification %v: %v", details.ID, err)
+	}
+	return out, err
+}
+
+func (c consumer) Target() notifications.Target {
+	return notifications.TargetWebPush
+}
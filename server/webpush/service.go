@@ -0,0 +1,331 @@
+// Package webpush sends notifications to browsers subscribed via the Push API, so users get them even if the UI
+// is not open.
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	apiErrors "github.com/realvnc-labs/rport/server/api/errors"
+	"github.com/realvnc-labs/rport/server/i18n"
+	"github.com/realvnc-labs/rport/server/notifications"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/random"
+	"github.com/realvnc-labs/rport/share/refs"
+)
+
+const (
+	MaxSubscriptionsPerUser = 20
+	// TTL is how long push services keep a notification for a browser that is offline.
+	TTL = 24 * time.Hour
+
+	// ClientDisconnectedNotificationType is the type of the reference of client disconnect notifications, its id is
+	// the client id.
+	ClientDisconnectedNotificationType refs.IdentifiableType = "client_disconnected"
+
+	requestTimeout = 10 * time.Second
+)
+
+// Subscription is a browser subscribed to the notifications of a user.
+type Subscription struct {
+	ID        string `json:"id" db:"id"`
+	Username  string `json:"username" db:"username"`
+	Endpoint  string `json:"endpoint" db:"endpoint"`
+	P256dh    string `json:"-" db:"p256dh"`
+	Auth      string `json:"-" db:"auth"`
+	UserAgent string `json:"user_agent" db:"user_agent"`
+	// ClientDisconnects enables notifications on disconnects of the clients the user has access to.
+	ClientDisconnects bool      `json:"client_disconnects" db:"client_disconnects"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+}
+
+// SubscribeRequest is the PushSubscription of the browser as returned by its toJSON().
+type SubscribeRequest struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+	ClientDisconnects bool `json:"client_disconnects"`
+}
+
+// Message is the payload received by the service worker of the UI.
+type Message struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Body     string `json:"body"`
+	ClientID string `json:"client_id,omitempty"`
+}
+
+// AccessChecker decides if a user is notified about a client.
+type AccessChecker interface {
+	HasClientAccess(ctx context.Context, username, clientID string) bool
+}
+
+type Service struct {
+	db         *sqlx.DB
+	key        *ecdsa.PrivateKey
+	publicKey  string
+	subject    string
+	httpClient *http.Client
+	logger     *logger.Logger
+	now        func() time.Time
+
+	dispatcher notifications.Dispatcher
+	localizer  i18n.Localizer
+	access     AccessChecker
+}
+
+// NewService creates the VAPID key on first start. The subject is a mailto: or https: URL the push services can
+// contact in case of problems.
+func NewService(ctx context.Context, db *sqlx.DB, subject string, logger *logger.Logger) (*Service, error) {
+	key, err := loadOrCreateVAPIDKey(ctx, db, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &Service{
+		db:         db,
+		key:        key,
+		publicKey:  encodePublicKey(key),
+		subject:    subject,
+		httpClient: &http.Client{Timeout: requestTimeout},
+		logger:     logger,
+		now:        time.Now,
+	}, nil
+}
+
+// ValidateSubject returns an error if the subject is not a mailto: or https: URL.
+func ValidateSubject(subject string) error {
+	if !strings.HasPrefix(subject, "mailto:") && !strings.HasPrefix(subject, "https://") {
+		return fmt.Errorf("%q must be a mailto: or https:// URL", subject)
+	}
+	return nil
+}
+
+// SetDispatcher enables notifications on client disconnects.
+func (s *Service) SetDispatcher(dispatcher notifications.Dispatcher) {
+	s.dispatcher = dispatcher
+}
+
+// SetLocalizer enables notifying in the locale of the user, without it english is used.
+func (s *Service) SetLocalizer(localizer i18n.Localizer) {
+	s.localizer = localizer
+}
+
+// SetAccessChecker limits client disconnect notifications to users with access to the client, without it only
+// admins should subscribe to them.
+func (s *Service) SetAccessChecker(access AccessChecker) {
+	s.access = access
+}
+
+// PublicKey returns the VAPID public key the browsers subscribe with, url safe base64 encoded.
+func (s *Service) PublicKey() string {
+	return s.publicKey
+}
+
+// Subscribe adds the subscription of a browser for the user. A subscription with the same endpoint is replaced.
+func (s *Service) Subscribe(ctx context.Context, username, userAgent string, req *SubscribeRequest) (*Subscription, error) {
+	if err := validateSubscribeRequest(req); err != nil {
+		return nil, apiErrors.NewAPIError(http.StatusBadRequest, "", err.Error(), nil)
+	}
+
+	var count int
+	err := s.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM push_subscriptions WHERE username = ? AND endpoint != ?", username, req.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if count >= MaxSubscriptionsPerUser {
+		return nil, apiErrors.NewAPIError(http.StatusConflict, "", fmt.Sprintf("At most %d push subscriptions per user are allowed.", MaxSubscriptionsPerUser), nil)
+	}
+
+	id, err := random.UUID4()
+	if err != nil {
+		return nil, err
+	}
+	sub := &Subscription{
+		ID:                id,
+		Username:          username,
+		Endpoint:          req.Endpoint,
+		P256dh:            req.Keys.P256dh,
+		Auth:              req.Keys.Auth,
+		UserAgent:         userAgent,
+		ClientDisconnects: req.ClientDisconnects,
+		CreatedAt:         s.now().UTC(),
+	}
+	_, err = s.db.NamedExecContext(ctx, `INSERT OR REPLACE INTO push_subscriptions (id, username, endpoint, p256dh, auth, user_agent, client_disconnects, created_at)
+		VALUES (:id, :username, :endpoint, :p256dh, :auth, :user_agent, :client_disconnects, :created_at)`, sub)
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func validateSubscribeRequest(req *SubscribeRequest) error {
+	u, err := url.Parse(req.Endpoint)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("invalid endpoint %q, expected a https URL", req.Endpoint)
+	}
+	p256dh, err := decodeBase64(req.Keys.P256dh)
+	if err != nil || len(p256dh) != 65 {
+		return fmt.Errorf("invalid p256dh key, expected an uncompressed P-256 point")
+	}
+	auth, err := decodeBase64(req.Keys.Auth)
+	if err != nil || len(auth) != 16 {
+		return fmt.Errorf("invalid auth secret, expected 16 bytes")
+	}
+	return nil
+}
+
+// List returns the subscriptions of the user.
+func (s *Service) List(ctx context.Context, username string) ([]*Subscription, error) {
+	subs := []*Subscription{}
+	err := s.db.SelectContext(ctx, &subs, "SELECT * FROM push_subscriptions WHERE username = ? ORDER BY created_at", username)
+	return subs, err
+}
+
+// Delete deletes a subscription of the user.
+func (s *Service) Delete(ctx context.Context, id, username string) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM push_subscriptions WHERE id = ? AND username = ?", id, username)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return apiErrors.NewAPIError(http.StatusNotFound, "", fmt.Sprintf("Push subscription with id %q not found.", id), nil)
+	}
+	return nil
+}
+
+// ClientDisconnected notifies the users subscribed to client disconnects.
+func (s *Service) ClientDisconnected(ctx context.Context, clientID, clientName string) {
+	if s == nil || s.dispatcher == nil {
+		return
+	}
+
+	var usernames []string
+	err := s.db.SelectContext(ctx, &usernames, "SELECT DISTINCT username FROM push_subscriptions WHERE client_disconnects = 1")
+	if err != nil {
+		s.logger.Errorf("Failed to get push subscriptions: %v", err)
+		return
+	}
+
+	now := s.now()
+	for _, username := range usernames {
+		if s.access != nil && !s.access.HasClientAccess(ctx, username, clientID) {
+			continue
+		}
+		locale := i18n.DefaultLocale
+		if s.localizer != nil {
+			locale = s.localizer.UserLocale(ctx, username)
+		}
+		_, err := s.dispatcher.Dispatch(ctx, refs.NewIdentifiable(ClientDisconnectedNotificationType, clientID), notifications.NotificationData{
+			Target:      string(notifications.TargetWebPush),
+			Recipients:  []string{username},
+			Subject:     i18n.Sprintf(locale, "Rport client %s disconnected", clientName),
+			Content:     i18n.Sprintf(locale, "Client %s (%s) disconnected at %s.", clientName, clientID, now.Format(time.RFC3339)),
+			ContentType: notifications.ContentTypeTextPlain,
+		})
+		if err != nil {
+			s.logger.Errorf("Failed to dispatch client disconnect push notification: %v", err)
+		}
+	}
+}
+
+// Send sends the message to the subscriptions of the users, to those subscribed to client disconnects only if
+// clientDisconnects is set. Subscriptions expired at the push service are deleted.
+func (s *Service) Send(ctx context.Context, usernames []string, msg *Message, clientDisconnects bool) (int, error) {
+	if len(usernames) == 0 {
+		return 0, nil
+	}
+	q, args, err := sqlx.In("SELECT * FROM push_subscriptions WHERE username IN (?)", usernames)
+	if err != nil {
+		return 0, err
+	}
+	if clientDisconnects {
+		q += " AND client_disconnects = 1"
+	}
+	var subs []*Subscription
+	if err := s.db.SelectContext(ctx, &subs, q, args...); err != nil {
+		return 0, err
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var errs []string
+	for _, sub := range subs {
+		err := s.push(ctx, sub, payload)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("subscription %s of %s: %v", sub.ID, sub.Username, err))
+			continue
+		}
+		sent++
+	}
+	if len(errs) > 0 {
+		return sent, fmt.Errorf("failed to send push notifications: %s", strings.Join(errs, "; "))
+	}
+	return sent, nil
+}
+
+func (s *Service) push(ctx context.Context, sub *Subscription, payload []byte) error {
+	body, err := encrypt(sub, payload)
+	if err != nil {
+		return err
+	}
+	authorization, err := vapidAuthorization(s.key, s.publicKey, s.subject, sub.Endpoint, s.now())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", fmt.Sprintf("%d", int(TTL.Seconds())))
+	req.Header.Set("Urgency", "high")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		s.logger.Infof("Push subscription %s of %s expired, deleting it.", sub.ID, sub.Username)
+		if _, err := s.db.ExecContext(ctx, "DELETE FROM push_subscriptions WHERE id = ?", sub.ID); err != nil {
+			s.logger.Errorf("Failed to delete expired push subscription %s: %v", sub.ID, err)
+		}
+		return nil
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if msg = bytes.TrimSpace(msg); len(msg) > 0 {
+			return fmt.Errorf("push service responded with %d: %s", resp.StatusCode, msg)
+		}
+		return fmt.Errorf("push service responded with %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *Service) Close() error {
+	return s.db.Close()
+}
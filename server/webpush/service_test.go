@@ -0,0 +1,271 @@
+package webpush
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	webpushmigration "github.com/realvnc-labs/rport/db/migration/webpush"
+	"github.com/realvnc-labs/rport/db/sqlite"
+	"github.com/realvnc-labs/rport/server/notifications"
+	"github.com/realvnc-labs/rport/share/logger"
+	"github.com/realvnc-labs/rport/share/refs"
+)
+
+var testLog = logger.NewLogger("webpush-test", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug)
+
+type browser struct {
+	key  *ecdsa.PrivateKey
+	auth []byte
+}
+
+func newBrowser(t *testing.T) *browser {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	auth := make([]byte, 16)
+	_, err = rand.Read(auth)
+	require.NoError(t, err)
+	return &browser{key: key, auth: auth}
+}
+
+func (b *browser) subscribeRequest(endpoint string) *SubscribeRequest {
+	req := &SubscribeRequest{Endpoint: endpoint}
+	req.Keys.P256dh = base64.RawURLEncoding.EncodeToString(elliptic.Marshal(elliptic.P256(), b.key.X, b.key.Y))
+	req.Keys.Auth = base64.RawURLEncoding.EncodeToString(b.auth)
+	return req
+}
+
+// decrypt is the user agent side of RFC 8291.
+func (b *browser) decrypt(t *testing.T, body []byte) []byte {
+	salt := body[:16]
+	rs := binary.BigEndian.Uint32(body[16:20])
+	assert.Equal(t, uint32(recordSize), rs)
+	idLen := int(body[20])
+	asPublic := body[21 : 21+idLen]
+	ciphertext := body[21+idLen:]
+
+	curve := elliptic.P256()
+	asX, asY := elliptic.Unmarshal(curve, asPublic)
+	require.NotNil(t, asX)
+	sx, _ := curve.ScalarMult(asX, asY, b.key.D.Bytes())
+	ecdhSecret := make([]byte, 32)
+	sx.FillBytes(ecdhSecret)
+
+	uaPublic := elliptic.Marshal(curve, b.key.X, b.key.Y)
+	keyInfo := append(append([]byte("WebPush: info\x00"), uaPublic...), asPublic...)
+	ikm, err := hkdfBytes(ecdhSecret, b.auth, keyInfo, 32)
+	require.NoError(t, err)
+	cek, err := hkdfBytes(ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	require.NoError(t, err)
+	nonce, err := hkdfBytes(ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+	require.NoError(t, err)
+
+	block, err := aes.NewCipher(cek)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	require.NoError(t, err)
+	require.Equal(t, byte(0x02), plaintext[len(plaintext)-1])
+	return plaintext[:len(plaintext)-1]
+}
+
+type pushServer struct {
+	*httptest.Server
+	status   int
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newPushServer(t *testing.T) *pushServer {
+	ps := &pushServer{status: http.StatusCreated}
+	ps.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		ps.mu.Lock()
+		ps.requests = append(ps.requests, r)
+		ps.bodies = append(ps.bodies, body)
+		status := ps.status
+		ps.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(ps.Close)
+	return ps
+}
+
+func newTestService(t *testing.T) *Service {
+	db, err := sqlite.New(":memory:", webpushmigration.AssetNames(), webpushmigration.Asset, sqlite.DataSourceOptions{})
+	require.NoError(t, err)
+	s, err := NewService(context.Background(), db, "mailto:admin@example.com", testLog)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestSend(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	ps := newPushServer(t)
+	s.httpClient = ps.Client()
+
+	b := newBrowser(t)
+	sub, err := s.Subscribe(ctx, "alice", "Firefox", b.subscribeRequest(ps.URL+"/push/alice"))
+	require.NoError(t, err)
+	_, err = s.Subscribe(ctx, "bob", "Chrome", newBrowser(t).subscribeRequest(ps.URL+"/push/bob"))
+	require.NoError(t, err)
+
+	sent, err := s.Send(ctx, []string{"alice"}, &Message{Type: MessageTypeNotification, Title: "Problem", Body: "CPU high"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	require.Len(t, ps.requests, 1)
+
+	req := ps.requests[0]
+	assert.Equal(t, "/push/alice", req.URL.Path)
+	assert.Equal(t, "aes128gcm", req.Header.Get("Content-Encoding"))
+	assert.Equal(t, "86400", req.Header.Get("TTL"))
+
+	var msg Message
+	require.NoError(t, json.Unmarshal(b.decrypt(t, ps.bodies[0]), &msg))
+	assert.Equal(t, Message{Type: MessageTypeNotification, Title: "Problem", Body: "CPU high"}, msg)
+
+	authorization := req.Header.Get("Authorization")
+	require.True(t, strings.HasPrefix(authorization, "vapid t="))
+	parts := strings.SplitN(strings.TrimPrefix(authorization, "vapid t="), ", k=", 2)
+	require.Len(t, parts, 2)
+	assert.Equal(t, s.PublicKey(), parts[1])
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(parts[0], claims, func(*jwt.Token) (interface{}, error) {
+		return &s.key.PublicKey, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ps.URL, claims["aud"])
+	assert.Equal(t, "mailto:admin@example.com", claims["sub"])
+
+	// client disconnects are sent to subscribed browsers only
+	sent, err = s.Send(ctx, []string{"alice"}, &Message{Type: MessageTypeClientDisconnected}, true)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	// expired subscriptions are deleted
+	ps.status = http.StatusGone
+	_, err = s.Send(ctx, []string{"alice", "bob"}, &Message{Title: "Problem"}, false)
+	require.NoError(t, err)
+	subs, err := s.List(ctx, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, subs)
+	assert.Error(t, s.Delete(ctx, sub.ID, "alice"))
+
+	ps.status = http.StatusInternalServerError
+	_, err = s.Subscribe(ctx, "alice", "Firefox", b.subscribeRequest(ps.URL+"/push/alice"))
+	require.NoError(t, err)
+	_, err = s.Send(ctx, []string{"alice"}, &Message{Title: "Problem"}, false)
+	assert.EqualError(t, err, "failed to send push notifications: subscription "+mustListFirst(t, s, "alice").ID+" of alice: push service responded with 500")
+}
+
+func mustListFirst(t *testing.T, s *Service, username string) *Subscription {
+	subs, err := s.List(context.Background(), username)
+	require.NoError(t, err)
+	require.NotEmpty(t, subs)
+	return subs[0]
+}
+
+func TestSubscribe(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	b := newBrowser(t)
+
+	req := b.subscribeRequest("http://push.example.com/1")
+	_, err := s.Subscribe(ctx, "alice", "", req)
+	assert.EqualError(t, err, `invalid endpoint "http://push.example.com/1", expected a https URL`)
+
+	req = b.subscribeRequest("https://push.example.com/1")
+	req.Keys.Auth = "abc"
+	_, err = s.Subscribe(ctx, "alice", "", req)
+	assert.EqualError(t, err, "invalid auth secret, expected 16 bytes")
+
+	req = b.subscribeRequest("https://push.example.com/1")
+	first, err := s.Subscribe(ctx, "alice", "Firefox", req)
+	require.NoError(t, err)
+	req.ClientDisconnects = true
+	second, err := s.Subscribe(ctx, "alice", "Firefox", req)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, second.ID)
+
+	subs, err := s.List(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, second.ID, subs[0].ID)
+	assert.True(t, subs[0].ClientDisconnects)
+	assert.Equal(t, "Firefox", subs[0].UserAgent)
+
+	assert.EqualError(t, s.Delete(ctx, second.ID, "bob"), "Push subscription with id \""+second.ID+"\" not found.")
+	require.NoError(t, s.Delete(ctx, second.ID, "alice"))
+}
+
+type dispatcherMock struct {
+	refs          []refs.Identifiable
+	notifications []notifications.NotificationData
+}
+
+func (d *dispatcherMock) Dispatch(_ context.Context, refID refs.Identifiable, notification notifications.NotificationData) (refs.Identifiable, error) {
+	d.refs = append(d.refs, refID)
+	d.notifications = append(d.notifications, notification)
+	return refID, nil
+}
+
+type accessMock map[string]bool
+
+func (a accessMock) HasClientAccess(_ context.Context, username, _ string) bool {
+	return a[username]
+}
+
+func TestClientDisconnected(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	d := &dispatcherMock{}
+	s.SetDispatcher(d)
+	s.SetAccessChecker(accessMock{"alice": true, "carol": true})
+
+	for i, username := range []string{"alice", "bob", "carol"} {
+		req := newBrowser(t).subscribeRequest("https://push.example.com/" + username)
+		req.ClientDisconnects = i < 2
+		_, err := s.Subscribe(ctx, username, "", req)
+		require.NoError(t, err)
+	}
+
+	s.ClientDisconnected(ctx, "client-1", "web-1")
+
+	require.Len(t, d.notifications, 1)
+	assert.Equal(t, []string{"alice"}, d.notifications[0].Recipients)
+	assert.Equal(t, "webpush", d.notifications[0].Target)
+	assert.Equal(t, "Rport client web-1 disconnected", d.notifications[0].Subject)
+	assert.Equal(t, ClientDisconnectedNotificationType, d.refs[0].Type())
+	assert.Equal(t, "client-1", d.refs[0].ID())
+
+	var nilService *Service
+	nilService.ClientDisconnected(ctx, "client-1", "web-1")
+}
+
+func TestValidateSubject(t *testing.T) {
+	assert.NoError(t, ValidateSubject("mailto:admin@example.com"))
+	assert.NoError(t, ValidateSubject("https://rport.example.com"))
+	assert.Error(t, ValidateSubject("admin@example.com"))
+}
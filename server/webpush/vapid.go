@@ -0,0 +1,75 @@
+package webpush
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/jmoiron/sqlx"
+)
+
+// vapidTokenTTL is the validity of the tokens sent to the push services, at most 24h are allowed.
+const vapidTokenTTL = 12 * time.Hour
+
+// loadOrCreateVAPIDKey returns the key the server identifies itself with to the push services. It's created on
+// first use, browsers have to subscribe again if it changes.
+func loadOrCreateVAPIDKey(ctx context.Context, db *sqlx.DB, now time.Time) (*ecdsa.PrivateKey, error) {
+	var encoded string
+	err := db.GetContext(ctx, &encoded, "SELECT private_key FROM vapid_keys WHERE id = 1")
+	if err == nil {
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode VAPID key: %w", err)
+		}
+		return x509.ParseECPrivateKey(der)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to load VAPID key: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.ExecContext(ctx, "INSERT INTO vapid_keys (id, private_key, created_at) VALUES (1, ?, ?)", base64.StdEncoding.EncodeToString(der), now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save VAPID key: %w", err)
+	}
+	return key, nil
+}
+
+func encodePublicKey(key *ecdsa.PrivateKey) string {
+	return base64.RawURLEncoding.EncodeToString(elliptic.Marshal(elliptic.P256(), key.X, key.Y))
+}
+
+// vapidAuthorization returns the Authorization header for a request to the push service of the endpoint as defined
+// by RFC 8292.
+func vapidAuthorization(key *ecdsa.PrivateKey, publicKey, subject, endpoint string, now time.Time) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": now.Add(vapidTokenTTL).Unix(),
+		"sub": subject,
+	})
+	signed, err := token.SignedString(key)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("vapid t=%s, k=%s", signed, publicKey), nil
+}
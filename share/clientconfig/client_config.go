@@ -10,6 +10,14 @@ import (
 	"github.com/realvnc-labs/rport/share/models"
 )
 
+const (
+	// ModeFull enables all features of the client.
+	ModeFull = "full"
+	// ModeCheckIn keeps only the connection to the server to report the inventory and monitoring data, tunnels,
+	// commands, scripts and file uploads are rejected.
+	ModeCheckIn = "checkin"
+)
+
 type Config struct {
 	Client                   ClientConfig        `json:"client" mapstructure:"client"`
 	Connection               ConnectionConfig    `json:"connection" mapstructure:"connection"`
@@ -20,6 +28,8 @@ type Config struct {
 	Tunnels                  TunnelsConfig       `json:"-"`
 	InterpreterAliasesConfig map[string]any      `json:"-" mapstructure:"interpreter-aliases"`
 	FileReceptionConfig      FileReceptionConfig `json:"file_reception" mapstructure:"file-reception"`
+	PacketCapture            PacketCaptureConfig `json:"packet_capture" mapstructure:"packet-capture"`
+	ReverseTunnels           ReverseTunnelConfig `json:"reverse_tunnels" mapstructure:"reverse-tunnels"`
 
 	InterpreterAliases          map[string]string                   `json:"interpreter_aliases"`
 	InterpreterAliasesEncodings map[string]InterpreterAliasEncoding `json:"interpreter_aliases_encodings"`
@@ -43,8 +53,10 @@ type ClientConfig struct {
 	TunnelAllowed            []string          `json:"tunnel_allowed" mapstructure:"tunnel_allowed"`
 	AllowRoot                bool              `json:"allow_root" mapstructure:"allow_root"`
 	UpdatesInterval          time.Duration     `json:"updates_interval" mapstructure:"updates_interval"`
+	SecuritySnapshotInterval time.Duration     `json:"security_snapshot_interval" mapstructure:"security_snapshot_interval"`
 	DataDir                  string            `json:"data_dir" mapstructure:"data_dir"`
 	BindInterface            string            `json:"bind_interface" mapstructure:"bind_interface"`
+	Mode                     string            `json:"mode" mapstructure:"mode"`
 
 	ProxyURL *url.URL         `json:"proxy_url"`
 	Tunnels  []*models.Remote `json:"tunnels"`
@@ -90,6 +102,20 @@ type ScriptsConfig struct {
 	Enabled bool `json:"enabled" mapstructure:"enabled"`
 }
 
+// PacketCaptureConfig limits packet captures requested by the server, the lower limits of the server and the client apply.
+type PacketCaptureConfig struct {
+	Enabled     bool          `json:"enabled" mapstructure:"enabled"`
+	Tcpdump     string        `json:"tcpdump" mapstructure:"tcpdump"`
+	MaxDuration time.Duration `json:"max_duration" mapstructure:"max_duration"`
+	MaxBytes    int64         `json:"max_bytes" mapstructure:"max_bytes"`
+}
+
+// ReverseTunnelConfig controls the listeners of reverse tunnels started by the server.
+type ReverseTunnelConfig struct {
+	Enabled     bool     `json:"enabled" mapstructure:"enabled"`
+	ListenHosts []string `json:"listen_hosts" mapstructure:"listen_hosts"`
+}
+
 type MonitoringConfig struct {
 	Enabled                       bool          `json:"enabled" mapstructure:"enabled"`
 	Interval                      time.Duration `json:"interval" mapstructure:"interval"`
@@ -0,0 +1,49 @@
+package comm
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/realvnc-labs/rport/share/models"
+)
+
+const MaxCaptureFilterLength = 1024
+
+var captureInterfaceRegex = regexp.MustCompile(`^[a-zA-Z0-9._:@-]{1,64}$`)
+
+// CaptureRequest asks the client to run a packet capture for the job. The pcap data is streamed to the server on a
+// models.ChannelCapture channel, the capture stops after MaxDuration or MaxBytes, whichever comes first.
+type CaptureRequest struct {
+	Job         models.Job
+	Interface   string
+	Filter      string
+	MaxDuration time.Duration
+	MaxBytes    int64
+}
+
+// Validate checks the request is safe to pass to the capture tool, it's validated by the server and the client.
+func (r *CaptureRequest) Validate() error {
+	if !captureInterfaceRegex.MatchString(r.Interface) || strings.HasPrefix(r.Interface, "-") {
+		return fmt.Errorf("invalid interface %q", r.Interface)
+	}
+	if len(r.Filter) > MaxCaptureFilterLength {
+		return fmt.Errorf("filter exceeds %d characters", MaxCaptureFilterLength)
+	}
+	if strings.HasPrefix(strings.TrimSpace(r.Filter), "-") {
+		return errors.New("filter must not start with '-'")
+	}
+	if strings.IndexFunc(r.Filter, unicode.IsControl) >= 0 {
+		return errors.New("filter must not contain control characters")
+	}
+	if r.MaxDuration <= 0 {
+		return errors.New("max duration must be greater than 0")
+	}
+	if r.MaxBytes <= 0 {
+		return errors.New("max bytes must be greater than 0")
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package comm
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaptureRequestValidate(t *testing.T) {
+	valid := CaptureRequest{
+		Interface:   "eth0",
+		Filter:      "tcp port 443 and host 10.0.0.1",
+		MaxDuration: time.Minute,
+		MaxBytes:    1024,
+	}
+
+	testCases := []struct {
+		name    string
+		modify  func(r *CaptureRequest)
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			modify: func(r *CaptureRequest) {},
+		},
+		{
+			name:   "empty filter",
+			modify: func(r *CaptureRequest) { r.Filter = "" },
+		},
+		{
+			name:    "option as interface",
+			modify:  func(r *CaptureRequest) { r.Interface = "-w" },
+			wantErr: true,
+		},
+		{
+			name:    "interface with spaces",
+			modify:  func(r *CaptureRequest) { r.Interface = "eth0 -w /tmp/x" },
+			wantErr: true,
+		},
+		{
+			name:    "option as filter",
+			modify:  func(r *CaptureRequest) { r.Filter = "-z /bin/sh" },
+			wantErr: true,
+		},
+		{
+			name:    "filter with control chars",
+			modify:  func(r *CaptureRequest) { r.Filter = "port 53\n-w /tmp/x" },
+			wantErr: true,
+		},
+		{
+			name:    "filter too long",
+			modify:  func(r *CaptureRequest) { r.Filter = strings.Repeat("a", MaxCaptureFilterLength+1) },
+			wantErr: true,
+		},
+		{
+			name:    "no duration",
+			modify:  func(r *CaptureRequest) { r.MaxDuration = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "no max bytes",
+			modify:  func(r *CaptureRequest) { r.MaxBytes = 0 },
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := valid
+			tc.modify(&r)
+			err := r.Validate()
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
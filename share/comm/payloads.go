@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/realvnc-labs/rport/share/models"
 )
 
 const (
@@ -13,14 +15,22 @@ const (
 	RequestTypeRefreshUpdatesStatus = "refresh_updates_status"
 	RequestTypePutCapabilities      = "put_capabilities"
 	RequestTypeCheckTunnelAllowed   = "check_tunnel_allowed"
+	RequestTypeSetMode              = "set_mode"
+	RequestTypeCapture              = "capture"
+	RequestTypeGetInterpreters      = "get_interpreters"
+	RequestTypeSetMonitoringProfile = "set_monitoring_profile"
+	RequestTypeGetServices          = "get_services"
+	RequestTypeStartReverseTunnel   = "start_reverse_tunnel"
+	RequestTypeStopReverseTunnel    = "stop_reverse_tunnel"
 
 	RequestTypeUpdateClientAttributes = "update_client_metadata"
 
 	// request types sent by clients to server
-	RequestTypeCmdResult       = "cmd_result"
-	RequestTypeUpdatesStatus   = "updates_status"
-	RequestTypeSaveMeasurement = "save_measurement"
-	RequestTypeUpload          = "upload"
+	RequestTypeCmdResult        = "cmd_result"
+	RequestTypeUpdatesStatus    = "updates_status"
+	RequestTypeSaveMeasurement  = "save_measurement"
+	RequestTypeUpload           = "upload"
+	RequestTypeSecuritySnapshot = "security_snapshot"
 
 	// request types understood on both sides, client and server
 	RequestTypePing = "ping"
@@ -39,6 +49,33 @@ func DecodeCheckPortRequest(b []byte) (*CheckPortRequest, error) {
 	return res, nil
 }
 
+// PingRequest is the optional payload of client to server pings. Servers compare the timestamp with their clock
+// to detect clients with a skewed clock, store the resource usage of the client and track changes of its addresses,
+// older servers ignore it.
+type PingRequest struct {
+	Timestamp  time.Time
+	AgentStats *models.AgentStats `json:",omitempty"`
+	Addresses  *PingAddresses     `json:",omitempty"`
+}
+
+// PingAddresses are the current non-loopback addresses of the client, nil if they couldn't be determined.
+type PingAddresses struct {
+	IPv4 []string
+	IPv6 []string
+}
+
+// DecodePingRequest decodes the payload of a ping, it returns nil if the ping has no payload.
+func DecodePingRequest(b []byte) (*PingRequest, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	res := &PingRequest{}
+	if err := json.Unmarshal(b, res); err != nil {
+		return nil, fmt.Errorf("failed to decode %T: %v", res, err)
+	}
+	return res, nil
+}
+
 type CheckPortResponse struct {
 	Open   bool
 	ErrMsg string
@@ -56,3 +93,43 @@ type CheckTunnelAllowedRequest struct {
 type CheckTunnelAllowedResponse struct {
 	IsAllowed bool
 }
+
+type SetModeRequest struct {
+	Mode string
+}
+
+// metrics of the client monitoring that can be selected by monitoring profiles
+const (
+	MonitoringMetricCPU         = "cpu"
+	MonitoringMetricMemory      = "memory"
+	MonitoringMetricIO          = "io"
+	MonitoringMetricProcesses   = "processes"
+	MonitoringMetricMountpoints = "mountpoints"
+	MonitoringMetricNet         = "net"
+)
+
+var MonitoringMetrics = []string{
+	MonitoringMetricCPU,
+	MonitoringMetricMemory,
+	MonitoringMetricIO,
+	MonitoringMetricProcesses,
+	MonitoringMetricMountpoints,
+	MonitoringMetricNet,
+}
+
+// SetMonitoringProfileRequest overrides the monitoring config of the client with a profile defined on the server.
+// A request with an empty name resets the client to its own monitoring config.
+type SetMonitoringProfileRequest struct {
+	Name     string
+	Version  string
+	Enabled  bool
+	Interval time.Duration
+	// Metrics lists the measured metrics, all if empty.
+	Metrics        []string
+	WatchProcesses []string
+	WatchServices  []string
+}
+
+type SetMonitoringProfileResponse struct {
+	Version string
+}
@@ -2,6 +2,7 @@ package comm
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"golang.org/x/crypto/ssh"
@@ -14,3 +15,16 @@ func PingConnectionWithTimeout(ctx context.Context, conn ssh.Conn, timeout time.
 	ok, response, err = SendRequestWithTimeout(ctx, conn, RequestTypePing, true, nil, timeout, l)
 	return ok, response, time.Since(timerStart), err
 }
+
+// PingConnectionWithTimestamp pings like PingConnectionWithTimeout but sends the given request with the timestamp set
+// to the current time as payload.
+func PingConnectionWithTimestamp(ctx context.Context, conn ssh.Conn, timeout time.Duration, req PingRequest, l *logger.Logger) (ok bool, response []byte, rtt time.Duration, err error) {
+	timerStart := time.Now()
+	req.Timestamp = timerStart
+	payload, err := json.Marshal(&req)
+	if err != nil {
+		return false, nil, 0, err
+	}
+	ok, response, err = SendRequestWithTimeout(ctx, conn, RequestTypePing, true, payload, timeout, l)
+	return ok, response, time.Since(timerStart), err
+}
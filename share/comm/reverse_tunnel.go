@@ -0,0 +1,14 @@
+package comm
+
+// StartReverseTunnelRequest asks the client to listen on the local address of the reverse tunnel. Starting a reverse
+// tunnel that's already listening on the same address succeeds, so the server can restart all on reconnect.
+type StartReverseTunnelRequest struct {
+	ID        string
+	LocalHost string
+	LocalPort string
+}
+
+// StopReverseTunnelRequest asks the client to close the listener of the reverse tunnel.
+type StopReverseTunnelRequest struct {
+	ID string
+}
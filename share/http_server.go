@@ -8,6 +8,9 @@ import (
 	"net/http"
 	"time"
 
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
 	"github.com/realvnc-labs/rport/share/logger"
 )
 
@@ -23,6 +26,15 @@ func WithTLS(certFile string, keyFile string, tlsConfig *tls.Config) ServerOptio
 	}
 }
 
+// WithHTTP2 enables or disables HTTP/2. With TLS it's negotiated via ALPN, without TLS HTTP/2 is served in
+// cleartext (h2c) to clients that use it with prior knowledge, e.g. a reverse proxy. Servers created without this
+// option keep the defaults of net/http, so they never serve h2c.
+func WithHTTP2(enabled bool) ServerOption {
+	return func(s *HTTPServer) {
+		s.http2 = &enabled
+	}
+}
+
 // HTTPServer extends net/http Server and
 // adds graceful shutdowns
 type HTTPServer struct {
@@ -34,6 +46,11 @@ type HTTPServer struct {
 	certFile  string
 	keyFile   string
 	logger    *logger.Logger
+
+	http2         *bool
+	proxyProtocol *ProxyProtocolConfig
+
+	activatedListener net.Listener
 }
 
 // NewHTTPServer creates a new HTTPServer
@@ -60,13 +77,20 @@ func NewHTTPServer(maxHeaderBytes int, l *logger.Logger, options ...ServerOption
 }
 
 func (h *HTTPServer) GoListenAndServe(ctx context.Context, addr string, handler http.Handler) error {
-	l, err := net.Listen("tcp", addr)
-	if err != nil {
-		return err
+	l := h.activatedListener
+	if l == nil {
+		var err error
+		l, err = net.Listen("tcp", addr)
+		if err != nil {
+			return err
+		}
+	}
+	if h.proxyProtocol != nil {
+		l = h.proxyProtocol.Listen(l)
 	}
 	h.isRunning = true
 	h.ctx = ctx
-	h.Handler = handler
+	h.Handler = h.withHTTP2(handler)
 	h.listener = l
 	h.BaseContext = func(l net.Listener) context.Context {
 		return h.ctx
@@ -84,6 +108,36 @@ func (h *HTTPServer) GoListenAndServe(ctx context.Context, addr string, handler
 	return nil
 }
 
+func (h *HTTPServer) withHTTP2(handler http.Handler) http.Handler {
+	if h.http2 == nil {
+		return handler
+	}
+
+	if !*h.http2 {
+		// a non-nil empty map prevents net/http from configuring HTTP/2 for TLS connections
+		h.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+		if h.TLSConfig != nil {
+			h.TLSConfig.NextProtos = removeProto(h.TLSConfig.NextProtos, http2.NextProtoTLS)
+		}
+		return handler
+	}
+
+	if h.TLSConfig == nil {
+		return h2c.NewHandler(handler, &http2.Server{})
+	}
+	return handler
+}
+
+func removeProto(protos []string, proto string) []string {
+	result := make([]string, 0, len(protos))
+	for _, p := range protos {
+		if p != proto {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
 func (h *HTTPServer) closeWith(err error) {
 	if !h.isRunning {
 		return
@@ -100,6 +154,14 @@ func (h *HTTPServer) Close() error {
 	return h.listener.Close()
 }
 
+// Addr returns the address the server listens on, nil if it's not started. Useful when listening on port 0.
+func (h *HTTPServer) Addr() net.Addr {
+	if h.listener == nil {
+		return nil
+	}
+	return h.listener.Addr()
+}
+
 func (h *HTTPServer) Wait() error {
 	if !h.isRunning {
 		return errors.New("Already closed")
@@ -1,10 +1,18 @@
 package chshare
 
 import (
+	"context"
 	"crypto/tls"
+	"net"
+	"net/http"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+
+	"github.com/realvnc-labs/rport/share/logger"
 )
 
 func TestNewHttpServer(t *testing.T) {
@@ -28,3 +36,56 @@ func TestNewHttpServerWithTLS(t *testing.T) {
 	assert.Equal(t, "test.crt", s.certFile)
 	assert.Equal(t, "test.key", s.keyFile)
 }
+
+func TestHTTPServerHTTP2Cleartext(t *testing.T) {
+	testCases := []struct {
+		name      string
+		options   []ServerOption
+		wantProto string
+	}{
+		{
+			name:      "enabled",
+			options:   []ServerOption{WithHTTP2(true)},
+			wantProto: "HTTP/2.0",
+		},
+		{
+			name:    "disabled",
+			options: []ServerOption{WithHTTP2(false)},
+		},
+		{
+			name: "default",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			s := NewHTTPServer(1024, logger.NewLogger("test", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug), tc.options...)
+			err := s.GoListenAndServe(ctx, "127.0.0.1:0", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(r.Proto))
+			}))
+			require.NoError(t, err)
+			defer s.Close()
+
+			// h2c with prior knowledge
+			client := &http.Client{
+				Transport: &http2.Transport{
+					AllowHTTP: true,
+					DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+						return (&net.Dialer{}).DialContext(ctx, network, addr)
+					},
+				},
+			}
+			resp, err := client.Get("http://" + s.listener.Addr().String())
+			if tc.wantProto == "" {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tc.wantProto, resp.Proto)
+		})
+	}
+}
@@ -29,6 +29,16 @@ func RemoteIP(r *http.Request) string {
 	return ips[0]
 }
 
+// ConnIP returns the IP of the connection of the request. Unlike RemoteIP it ignores the X-Forwarded-For header that
+// can be set by the user.
+func ConnIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
 func firstValidIP(ips []string, allowPrivate bool) (string, bool) {
 	for _, ipStr := range ips {
 		ip := net.ParseIP(strings.TrimSpace(ipStr))
@@ -55,3 +55,11 @@ func TestRemoteIP(t *testing.T) {
 		})
 	}
 }
+
+func TestConnIP(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.168.0.13:1234"
+	req.Header.Set("X-Forwarded-For", "8.8.8.8")
+
+	assert.Equal(t, "192.168.0.13", ConnIP(req))
+}
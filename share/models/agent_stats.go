@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// AgentStats is the resource usage of the rport client process itself, reported with each client to server heartbeat.
+type AgentStats struct {
+	CollectedAt time.Time `json:"collected_at"`
+	// RSSBytes is the resident memory of the client process
+	RSSBytes uint64 `json:"rss_bytes"`
+	// CPUPercent is the cpu usage of the client process since the previous heartbeat
+	CPUPercent float64 `json:"cpu_percent"`
+	Goroutines int     `json:"goroutines"`
+	// OpenFDs is the number of open file descriptors, nil if not supported by the os
+	OpenFDs *int `json:"open_fds"`
+}
@@ -1,6 +1,84 @@
 package models
 
+const (
+	// LegacyProtocolVersion is assumed for clients connecting without capabilities in the connection request.
+	LegacyProtocolVersion = 1
+	// ProtocolVersion is the highest client-server protocol version supported by this build.
+	ProtocolVersion = 2
+)
+
+// Features negotiated in the connection handshake, a feature is used only if both sides support it.
+const (
+	FeatureUDPTunnels    = "udp_tunnels"
+	FeatureCompression   = "compression"
+	FeatureMonitoringV2  = "monitoring_v2"
+	FeatureSOCKS5Tunnels = "socks5_tunnels"
+)
+
+// SupportedFeatures are the features implemented by this build.
+var SupportedFeatures = []string{FeatureUDPTunnels, FeatureSOCKS5Tunnels}
+
+// LegacyFeatures are assumed for clients connecting without capabilities, they were supported before the negotiation
+// was added.
+var LegacyFeatures = []string{FeatureUDPTunnels}
+
+// Capabilities are sent by the server after a client has connected.
 type Capabilities struct {
 	ServerVersion     string
 	MonitoringVersion int
+	// ProtocolVersion and Features are the result of the negotiation with the client, ignored by older clients
+	ProtocolVersion int      `json:",omitempty"`
+	Features        []string `json:",omitempty"`
+}
+
+// HasFeature returns true if the feature was negotiated.
+func (c *Capabilities) HasFeature(feature string) bool {
+	return hasFeature(c.Features, feature)
+}
+
+// ClientCapabilities are sent by the client with the connection request.
+type ClientCapabilities struct {
+	ProtocolVersion int
+	Features        []string
+}
+
+// NewClientCapabilities returns the capabilities of this build.
+func NewClientCapabilities() *ClientCapabilities {
+	return &ClientCapabilities{
+		ProtocolVersion: ProtocolVersion,
+		Features:        SupportedFeatures,
+	}
+}
+
+// Negotiate returns the highest protocol version and the features supported by both sides.
+// Client capabilities are nil for clients not supporting the negotiation.
+func Negotiate(serverFeatures []string, client *ClientCapabilities) (int, []string) {
+	if client == nil {
+		client = &ClientCapabilities{
+			ProtocolVersion: LegacyProtocolVersion,
+			Features:        LegacyFeatures,
+		}
+	}
+
+	version := ProtocolVersion
+	if client.ProtocolVersion < version {
+		version = client.ProtocolVersion
+	}
+
+	features := []string{}
+	for _, f := range serverFeatures {
+		if hasFeature(client.Features, f) {
+			features = append(features, f)
+		}
+	}
+	return version, features
+}
+
+func hasFeature(features []string, feature string) bool {
+	for _, f := range features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	serverFeatures := []string{FeatureUDPTunnels, FeatureCompression}
+
+	testCases := []struct {
+		name         string
+		client       *ClientCapabilities
+		wantVersion  int
+		wantFeatures []string
+	}{
+		{
+			name:         "legacy client",
+			wantVersion:  LegacyProtocolVersion,
+			wantFeatures: []string{FeatureUDPTunnels},
+		},
+		{
+			name:         "common features",
+			client:       &ClientCapabilities{ProtocolVersion: ProtocolVersion, Features: []string{FeatureCompression, FeatureMonitoringV2}},
+			wantVersion:  ProtocolVersion,
+			wantFeatures: []string{FeatureCompression},
+		},
+		{
+			name:         "newer client",
+			client:       &ClientCapabilities{ProtocolVersion: ProtocolVersion + 1, Features: []string{FeatureUDPTunnels, "unknown"}},
+			wantVersion:  ProtocolVersion,
+			wantFeatures: []string{FeatureUDPTunnels},
+		},
+		{
+			name:         "no common features",
+			client:       &ClientCapabilities{ProtocolVersion: ProtocolVersion},
+			wantVersion:  ProtocolVersion,
+			wantFeatures: []string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotVersion, gotFeatures := Negotiate(serverFeatures, tc.client)
+
+			assert.Equal(t, tc.wantVersion, gotVersion)
+			assert.Equal(t, tc.wantFeatures, gotFeatures)
+		})
+	}
+}
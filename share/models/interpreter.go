@@ -0,0 +1,35 @@
+package models
+
+import "strings"
+
+// Interpreter is an interpreter found on a client to execute commands and scripts.
+type Interpreter struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	// Alias is true if the interpreter is an interpreter alias of the client configuration
+	Alias bool `json:"alias"`
+}
+
+// FindInterpreter returns the interpreter matching the interpreter of a job or nil if not found.
+// A job interpreter given as full path matches by the path or by the file name, because the same interpreter
+// can be found in different directories, e.g. /bin/bash and /usr/bin/bash.
+func FindInterpreter(interpreters []Interpreter, interpreter string) *Interpreter {
+	for i := range interpreters {
+		if strings.EqualFold(interpreters[i].Name, interpreter) || interpreters[i].Path == interpreter {
+			return &interpreters[i]
+		}
+	}
+
+	if !strings.ContainsAny(interpreter, `/\`) {
+		return nil
+	}
+	name := interpreter[strings.LastIndexAny(interpreter, `/\`)+1:]
+	name = strings.TrimSuffix(strings.ToLower(name), ".exe")
+	for i := range interpreters {
+		if !interpreters[i].Alias && strings.EqualFold(interpreters[i].Name, name) {
+			return &interpreters[i]
+		}
+	}
+	return nil
+}
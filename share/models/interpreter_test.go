@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindInterpreter(t *testing.T) {
+	interpreters := []Interpreter{
+		{Name: "bash", Path: "/usr/bin/bash", Version: "5.1.16"},
+		{Name: "python3", Path: "/usr/bin/python3", Version: "3.10.12"},
+		{Name: "powershell", Path: `C:\Windows\System32\WindowsPowerShell\v1.0\powershell.exe`, Version: "5.1.19041.1"},
+		{Name: "py", Path: "/opt/python/bin/python3.11", Alias: true},
+	}
+
+	testCases := []struct {
+		interpreter string
+		expected    string
+	}{
+		{interpreter: "bash", expected: "bash"},
+		{interpreter: "/usr/bin/bash", expected: "bash"},
+		{interpreter: "/bin/bash", expected: "bash"},
+		{interpreter: "PowerShell", expected: "powershell"},
+		{interpreter: `C:\Program Files\PowerShell.exe`, expected: "powershell"},
+		{interpreter: "py", expected: "py"},
+		{interpreter: "/opt/python/bin/python3.11", expected: "py"},
+		{interpreter: "/usr/local/bin/py", expected: ""},
+		{interpreter: "zsh", expected: ""},
+		{interpreter: "/bin/zsh", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.interpreter, func(t *testing.T) {
+			found := FindInterpreter(interpreters, tc.interpreter)
+			if tc.expected == "" {
+				assert.Nil(t, found)
+				return
+			}
+			if assert.NotNil(t, found) {
+				assert.Equal(t, tc.expected, found.Name)
+			}
+		})
+	}
+}
@@ -14,28 +14,35 @@ const (
 
 	ChannelStdout = "stdout"
 	ChannelStderr = "stderr"
+	// ChannelCapture streams the pcap data of a packet capture job from the client to the server
+	ChannelCapture = "capture"
 )
 
 type Job struct {
-	JID          string     `json:"jid"`
-	Status       string     `json:"status"`
-	FinishedAt   *time.Time `json:"finished_at"`
-	ClientID     string     `json:"client_id"`
-	ClientName   string     `json:"client_name"`
-	Command      string     `json:"command"`
-	Cwd          string     `json:"cwd"`
-	Interpreter  string     `json:"interpreter"`
-	PID          *int       `json:"pid"`
-	StartedAt    time.Time  `json:"started_at"`
-	CreatedBy    string     `json:"created_by"`
-	TimeoutSec   int        `json:"timeout_sec"`
-	MultiJobID   *string    `json:"multi_job_id"`
-	ScheduleID   *string    `json:"schedule_id"`
-	Error        string     `json:"error"`
-	Result       *JobResult `json:"result"`
-	IsSudo       bool       `json:"is_sudo"`
-	IsScript     bool       `json:"is_script"`
-	StreamResult bool       `json:"stream_result"`
+	JID           string     `json:"jid"`
+	Status        string     `json:"status"`
+	FinishedAt    *time.Time `json:"finished_at"`
+	ClientID      string     `json:"client_id"`
+	ClientName    string     `json:"client_name"`
+	Command       string     `json:"command"`
+	Cwd           string     `json:"cwd"`
+	Interpreter   string     `json:"interpreter"`
+	PID           *int       `json:"pid"`
+	StartedAt     time.Time  `json:"started_at"`
+	CreatedBy     string     `json:"created_by"`
+	TimeoutSec    int        `json:"timeout_sec"`
+	MultiJobID    *string    `json:"multi_job_id"`
+	ScheduleID    *string    `json:"schedule_id"`
+	Error         string     `json:"error"`
+	Result        *JobResult `json:"result"`
+	IsSudo        bool       `json:"is_sudo"`
+	IsScript      bool       `json:"is_script"`
+	IsCapture     bool       `json:"is_capture,omitempty"`
+	StreamResult  bool       `json:"stream_result"`
+	Labels        []string   `json:"labels,omitempty"`
+	CorrelationID string     `json:"correlation_id,omitempty"`
+	// RedactionCount is the number of matches of the server redaction rules replaced in the result
+	RedactionCount int `json:"redaction_count,omitempty"`
 }
 
 type JobResult struct {
@@ -52,18 +59,24 @@ type JobClientTags struct {
 // TODO: check that ClientTags is populated where required
 type MultiJob struct {
 	MultiJobSummary
-	ClientIDs   []string       `json:"client_ids"`
-	GroupIDs    []string       `json:"group_ids"`
-	ClientTags  *JobClientTags `json:"tags"`
-	Command     string         `json:"command"`
-	Cwd         string         `json:"cwd"`
-	Interpreter string         `json:"interpreter"`
-	TimeoutSec  int            `json:"timeout_sec"`
-	Concurrent  bool           `json:"concurrent"`
-	AbortOnErr  bool           `json:"abort_on_err"`
-	Jobs        []*Job         `json:"jobs"`
-	IsSudo      bool           `json:"is_sudo"`
-	IsScript    bool           `json:"is_script"`
+	ClientIDs     []string       `json:"client_ids"`
+	GroupIDs      []string       `json:"group_ids"`
+	ClientTags    *JobClientTags `json:"tags"`
+	Command       string         `json:"command"`
+	Cwd           string         `json:"cwd"`
+	Interpreter   string         `json:"interpreter"`
+	TimeoutSec    int            `json:"timeout_sec"`
+	Concurrent    bool           `json:"concurrent"`
+	AbortOnErr    bool           `json:"abort_on_err"`
+	Jobs          []*Job         `json:"jobs"`
+	IsSudo        bool           `json:"is_sudo"`
+	IsScript      bool           `json:"is_script"`
+	Labels        []string       `json:"labels,omitempty"`
+	CorrelationID string         `json:"correlation_id,omitempty"`
+	// ClientCount is the number of clients the job was started on, zero for jobs created by older versions
+	ClientCount int `json:"client_count,omitempty"`
+	// Idempotent jobs are sent again to clients when the server stopped before their result was received
+	Idempotent bool `json:"idempotent,omitempty"`
 }
 
 type MultiJobSummary struct {
@@ -85,6 +98,9 @@ func (j Job) LogPrefix() string {
 		r = fmt.Sprintf("multiJobID=%q, ", *j.MultiJobID)
 	}
 	r += fmt.Sprintf("jid=%q, clientID=%q", j.JID, j.ClientID)
+	if j.CorrelationID != "" {
+		r += fmt.Sprintf(", correlationID=%q", j.CorrelationID)
+	}
 	return r
 }
 
@@ -10,13 +10,22 @@ type NetBytes struct {
 }
 
 type Measurement struct {
-	ClientID           string    `json:"client_id" db:"client_id"`
-	Timestamp          time.Time `json:"timestamp" db:"timestamp"`
-	CPUUsagePercent    float64   `json:"cpu_usage_percent" db:"cpu_usage_percent"`
-	MemoryUsagePercent float64   `json:"memory_usage_percent" db:"memory_usage_percent"`
-	IoUsagePercent     float64   `json:"io_usage_percent" db:"io_usage_percent"`
-	Processes          string    `json:"processes" db:"processes"`
-	Mountpoints        string    `json:"mountpoints" db:"mountpoints"`
-	NetLan             *NetBytes `json:"net_lan" db:"net_lan"`
-	NetWan             *NetBytes `json:"net_wan" db:"net_wan"`
+	ClientID           string             `json:"client_id" db:"client_id"`
+	Timestamp          time.Time          `json:"timestamp" db:"timestamp"`
+	CPUUsagePercent    float64            `json:"cpu_usage_percent" db:"cpu_usage_percent"`
+	MemoryUsagePercent float64            `json:"memory_usage_percent" db:"memory_usage_percent"`
+	IoUsagePercent     float64            `json:"io_usage_percent" db:"io_usage_percent"`
+	Processes          string             `json:"processes" db:"processes"`
+	Mountpoints        string             `json:"mountpoints" db:"mountpoints"`
+	NetLan             *NetBytes          `json:"net_lan" db:"net_lan"`
+	NetWan             *NetBytes          `json:"net_wan" db:"net_wan"`
+	CustomMetrics      map[string]float64 `json:"custom_metrics,omitempty" db:"-"` // e.g. watched processes and services
+}
+
+// CustomMeasurement is a single value of a metric pushed via the API, e.g. by a script.
+type CustomMeasurement struct {
+	ClientID  string    `json:"client_id" db:"client_id"`
+	Timestamp time.Time `json:"timestamp" db:"timestamp"`
+	Name      string    `json:"name" db:"name"`
+	Value     float64   `json:"value" db:"value"`
 }
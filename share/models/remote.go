@@ -23,6 +23,9 @@ import (
 //     local  192.168.0.1:3000
 //     remote google.com:80
 //   .../udp ->  udp protocol
+//   3000:socks5:// ->
+//     local  0.0.0.0:3000
+//     remote SOCKS5 proxy running inside the client
 
 const (
 	ZeroHost       = "0.0.0.0"
@@ -30,6 +33,10 @@ const (
 	ProtocolTCP    = "tcp"
 	ProtocolUDP    = "udp"
 	ProtocolTCPUDP = "tcp+udp"
+
+	// RemoteSOCKS5 is the remote of tunnels to a SOCKS5 proxy inside the client, it's sent as the remote of the tunnel
+	// connections to the client.
+	RemoteSOCKS5 = "socks5://"
 )
 
 var protocolRe = regexp.MustCompile(`(.*)\/(tcp|udp|tcp\+udp)$`)
@@ -53,6 +60,20 @@ type Remote struct {
 	AuthUser           string        `json:"auth_user"`
 	AuthPassword       string        `json:"auth_password"`
 	TunnelURL          string        `json:"tunnel_url"`
+	Record             bool          `json:"record"`
+	Labels             []string      `json:"labels,omitempty"`
+	// VaultCredentialsID references the vault value holding the password injected by the rdp/vnc tunnel proxy
+	VaultCredentialsID int `json:"vault_credentials_id,omitempty"`
+	// Ephemeral tunnels are closed when the api session they were created with (SessionID) ends. SessionID is
+	// neither returned by the API nor sent to the client.
+	Ephemeral bool  `json:"ephemeral,omitempty"`
+	SessionID int64 `json:"-"`
+	// Banner is the session banner shown to the user before connecting, e.g. a legal notice
+	Banner string `json:"banner,omitempty"`
+	// BindAddress is the server address the tunnel listens on instead of 0.0.0.0, it's kept for random local ports
+	BindAddress string `json:"bind_address,omitempty"`
+	// SOCKS5 tunnels reach any host in the network of the client via a SOCKS5 proxy run by the client
+	SOCKS5 bool `json:"socks5,omitempty"`
 }
 
 func NewRemote(s string) (*Remote, error) {
@@ -63,6 +84,13 @@ func NewRemote(s string) (*Remote, error) {
 		protocol = matches[2]
 	}
 
+	if strings.HasSuffix(s, RemoteSOCKS5) {
+		if protocol != ProtocolTCP {
+			return nil, errors.New("SOCKS5 tunnels support the tcp protocol only")
+		}
+		return newSOCKS5Remote(strings.TrimSuffix(strings.TrimSuffix(s, RemoteSOCKS5), ":"))
+	}
+
 	parts := strings.Split(s, ":")
 	if len(parts) <= 0 || len(parts) >= 5 {
 		return nil, errors.New("Invalid remote")
@@ -102,6 +130,34 @@ func NewRemote(s string) (*Remote, error) {
 	return r, nil
 }
 
+// newSOCKS5Remote returns a SOCKS5 remote with the optional local "[host:]port".
+func newSOCKS5Remote(local string) (*Remote, error) {
+	r := &Remote{
+		Protocol: ProtocolTCP,
+		SOCKS5:   true,
+	}
+	if local == "" {
+		return r, nil
+	}
+
+	parts := strings.Split(local, ":")
+	switch len(parts) {
+	case 1:
+		r.LocalHost, r.LocalPort = ZeroHost, parts[0]
+	case 2:
+		r.LocalHost, r.LocalPort = parts[0], parts[1]
+	default:
+		return nil, errors.New("Invalid remote")
+	}
+	if !isPort(r.LocalPort) {
+		return nil, errors.New("Invalid local port")
+	}
+	if !isHost(r.LocalHost) {
+		return nil, errors.New("Invalid host")
+	}
+	return r, nil
+}
+
 var isPortRegExp = regexp.MustCompile(`^\d+$`)
 
 func isPort(s string) bool {
@@ -127,6 +183,9 @@ func (r Remote) String() string {
 }
 
 func (r *Remote) Remote() string {
+	if r.SOCKS5 {
+		return RemoteSOCKS5
+	}
 	return net.JoinHostPort(r.RemoteHost, r.RemotePort)
 }
 
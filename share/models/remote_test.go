@@ -119,6 +119,64 @@ func TestDecodeRemote(t *testing.T) {
 	}
 }
 
+func TestDecodeSOCKS5Remote(t *testing.T) {
+	testCases := []struct {
+		Input         string
+		WantLocalHost string
+		WantLocalPort string
+		WantErr       string
+	}{
+		{
+			Input: "socks5://",
+		},
+		{
+			Input:         "3000:socks5://",
+			WantLocalHost: ZeroHost,
+			WantLocalPort: "3000",
+		},
+		{
+			Input:         "127.0.0.1:3000:socks5://",
+			WantLocalHost: LocalHost,
+			WantLocalPort: "3000",
+		},
+		{
+			Input:   "socks5:///udp",
+			WantErr: "SOCKS5 tunnels support the tcp protocol only",
+		},
+		{
+			Input:   "abc:socks5://",
+			WantErr: "Invalid local port",
+		},
+		{
+			Input:   "1:2:3:socks5://",
+			WantErr: "Invalid remote",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.Input, func(t *testing.T) {
+			t.Parallel()
+
+			remote, err := NewRemote(tc.Input)
+			if tc.WantErr != "" {
+				require.EqualError(t, err, tc.WantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, remote.SOCKS5)
+			assert.Equal(t, ProtocolTCP, remote.Protocol)
+			assert.Equal(t, tc.WantLocalHost, remote.LocalHost)
+			assert.Equal(t, tc.WantLocalPort, remote.LocalPort)
+			assert.Equal(t, RemoteSOCKS5, remote.Remote())
+		})
+	}
+
+	remote, err := NewRemote("3000:socks5://")
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.0.0:3000:socks5://", remote.String())
+}
+
 func TestIsProtocol(t *testing.T) {
 	testCases := []struct {
 		Protocol      string
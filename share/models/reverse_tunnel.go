@@ -0,0 +1,46 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// ChannelReverseTunnel is opened by the client for each connection accepted by a reverse tunnel listener, the extra
+// data is the id of the reverse tunnel.
+const ChannelReverseTunnel = "reverse-tunnel"
+
+// ReverseTunnel listens on the client host and forwards the accepted connections through the rport connection to the
+// server, which dials the target. It makes services reachable by the server available to clients behind NAT.
+type ReverseTunnel struct {
+	ID        string `json:"id"`
+	LocalHost string `json:"lhost"`
+	LocalPort string `json:"lport"`
+	// Target is the address dialed by the server. It's empty if the target is the tunnel of another client.
+	Target         string    `json:"target,omitempty"`
+	TargetClientID string    `json:"target_client_id,omitempty"`
+	TargetTunnelID string    `json:"target_tunnel_id,omitempty"`
+	Owner          string    `json:"owner"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Local returns the address the client listens on.
+func (t *ReverseTunnel) Local() string {
+	return net.JoinHostPort(t.LocalHost, t.LocalPort)
+}
+
+// ValidateLocal checks the address the client listens on.
+func (t *ReverseTunnel) ValidateLocal() error {
+	if t.LocalHost == "" {
+		return errors.New("local host is required")
+	}
+	if !isHost(t.LocalHost) {
+		return fmt.Errorf("invalid local host %q", t.LocalHost)
+	}
+	if port, err := strconv.Atoi(t.LocalPort); err != nil || !isPort(t.LocalPort) || port < 1 || port > 65535 {
+		return fmt.Errorf("invalid local port %q", t.LocalPort)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// SecuritySnapshot gives incident responders the login context of a client without running commands on it. It's
+// collected by the client periodically.
+type SecuritySnapshot struct {
+	Refreshed     time.Time    `json:"refreshed"`
+	LoggedInUsers []UserLogin  `json:"logged_in_users"`
+	LastLogins    []UserLogin  `json:"last_logins"` // the latest first, nil if not supported by the os
+	SSHD          *SSHDSummary `json:"sshd"`        // nil if no sshd config was found
+	// Errors of the parts that could not be collected, the other parts are still reported
+	Errors []string `json:"errors,omitempty"`
+}
+
+// UserLogin is a session of a user, either active or from the login history.
+type UserLogin struct {
+	User     string    `json:"user"`
+	Terminal string    `json:"terminal"`
+	Host     string    `json:"host"` // remote host, empty for local logins
+	Started  time.Time `json:"started"`
+}
+
+// SSHDSummary are the security relevant settings of the sshd config. Settings not set in the config are empty, the
+// sshd defaults apply to them.
+type SSHDSummary struct {
+	ConfigFile             string   `json:"config_file"`
+	Ports                  []string `json:"ports"`
+	ListenAddresses        []string `json:"listen_addresses"`
+	PermitRootLogin        string   `json:"permit_root_login"`
+	PasswordAuthentication string   `json:"password_authentication"`
+	PubkeyAuthentication   string   `json:"pubkey_authentication"`
+	PermitEmptyPasswords   string   `json:"permit_empty_passwords"`
+	X11Forwarding          string   `json:"x11_forwarding"`
+	MaxAuthTries           string   `json:"max_auth_tries"`
+	AllowUsers             []string `json:"allow_users"`
+	AllowGroups            []string `json:"allow_groups"`
+}
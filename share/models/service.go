@@ -0,0 +1,8 @@
+package models
+
+// Service is a system service found on a client, e.g. a systemd unit, a launchd job or a windows service.
+type Service struct {
+	Name string `json:"name"`
+	// State is the state as reported by the service manager, e.g. "running", "exited", "failed" or "stopped".
+	State string `json:"state"`
+}
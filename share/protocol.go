@@ -36,6 +36,9 @@ type ConnectionRequest struct {
 	Labels                 map[string]string
 	Remotes                []*models.Remote
 	ClientConfiguration    *clientconfig.Config
+	Mode                   string
+	// Capabilities are nil for clients not supporting the capability negotiation
+	Capabilities *models.ClientCapabilities
 }
 
 func DecodeConnectionRequest(b []byte) (*ConnectionRequest, error) {
@@ -0,0 +1,116 @@
+package chshare
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pires/go-proxyproto"
+)
+
+const (
+	ProxyProtocolOff      = "off"
+	ProxyProtocolOptional = "optional"
+	ProxyProtocolRequired = "required"
+
+	proxyProtocolHeaderTimeout = 10 * time.Second
+)
+
+// ProxyProtocolConfig configures HAProxy PROXY protocol v1/v2 on a listener, so the real source address of connections
+// that pass a load balancer is used instead of the address of the load balancer.
+type ProxyProtocolConfig struct {
+	// Mode is one of "off", "optional" to accept connections with and without a PROXY header or "required".
+	Mode string `mapstructure:"proxy_protocol"`
+	// Trusted is a list of IP addresses or CIDRs allowed to send a PROXY header. It's required if the PROXY protocol is
+	// enabled, since any peer could spoof its address otherwise.
+	Trusted []string `mapstructure:"proxy_protocol_trusted"`
+
+	trustedNets []*net.IPNet
+}
+
+func (c *ProxyProtocolConfig) Enabled() bool {
+	return c.Mode != "" && c.Mode != ProxyProtocolOff
+}
+
+func (c *ProxyProtocolConfig) ParseAndValidate() error {
+	switch c.Mode {
+	case "", ProxyProtocolOff, ProxyProtocolOptional, ProxyProtocolRequired:
+	default:
+		return fmt.Errorf("invalid proxy_protocol %q, expected one of %q, %q, %q", c.Mode, ProxyProtocolOff, ProxyProtocolOptional, ProxyProtocolRequired)
+	}
+
+	if c.Enabled() && len(c.Trusted) == 0 {
+		return fmt.Errorf("proxy_protocol_trusted is required if proxy_protocol is enabled")
+	}
+
+	c.trustedNets = nil
+	for _, t := range c.Trusted {
+		t = strings.TrimSpace(t)
+		if !strings.Contains(t, "/") {
+			ip := net.ParseIP(t)
+			if ip == nil {
+				return fmt.Errorf("invalid proxy_protocol_trusted %q: not an IP address or CIDR", t)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			c.trustedNets = append(c.trustedNets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(t)
+		if err != nil {
+			return fmt.Errorf("invalid proxy_protocol_trusted %q: %v", t, err)
+		}
+		c.trustedNets = append(c.trustedNets, ipNet)
+	}
+
+	return nil
+}
+
+func (c *ProxyProtocolConfig) isTrusted(upstream net.Addr) bool {
+	tcpAddr, ok := upstream.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	for _, n := range c.trustedNets {
+		if n.Contains(tcpAddr.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// policy decides how to treat the PROXY header of a new connection. Connections of untrusted upstreams are handled as
+// direct connections and rejected if they send a PROXY header. The policy never returns an error, since it would
+// stop the server from accepting connections.
+func (c *ProxyProtocolConfig) policy(upstream net.Addr) (proxyproto.Policy, error) {
+	switch {
+	case !c.isTrusted(upstream):
+		return proxyproto.REJECT, nil
+	case c.Mode == ProxyProtocolRequired:
+		return proxyproto.REQUIRE, nil
+	default:
+		return proxyproto.USE, nil
+	}
+}
+
+// Listen wraps the listener to read the PROXY header of new connections if the PROXY protocol is enabled.
+func (c *ProxyProtocolConfig) Listen(l net.Listener) net.Listener {
+	if !c.Enabled() {
+		return l
+	}
+	return &proxyproto.Listener{
+		Listener:          l,
+		Policy:            c.policy,
+		ReadHeaderTimeout: proxyProtocolHeaderTimeout,
+	}
+}
+
+// WithProxyProtocol enables the PROXY protocol on the listener of the server.
+func WithProxyProtocol(c *ProxyProtocolConfig) ServerOption {
+	return func(s *HTTPServer) {
+		s.proxyProtocol = c
+	}
+}
@@ -0,0 +1,178 @@
+package chshare
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/pires/go-proxyproto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/realvnc-labs/rport/share/logger"
+)
+
+func TestProxyProtocolConfigParseAndValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		config  ProxyProtocolConfig
+		wantErr string
+	}{
+		{
+			name:   "disabled",
+			config: ProxyProtocolConfig{},
+		},
+		{
+			name: "valid",
+			config: ProxyProtocolConfig{
+				Mode:    ProxyProtocolRequired,
+				Trusted: []string{"10.0.0.0/8", "192.168.1.1", "::1"},
+			},
+		},
+		{
+			name:    "invalid mode",
+			config:  ProxyProtocolConfig{Mode: "yes"},
+			wantErr: `invalid proxy_protocol "yes", expected one of "off", "optional", "required"`,
+		},
+		{
+			name:    "enabled without trusted",
+			config:  ProxyProtocolConfig{Mode: ProxyProtocolOptional},
+			wantErr: "proxy_protocol_trusted is required if proxy_protocol is enabled",
+		},
+		{
+			name:   "disabled without trusted",
+			config: ProxyProtocolConfig{Mode: ProxyProtocolOff},
+		},
+		{
+			name: "invalid trusted ip",
+			config: ProxyProtocolConfig{
+				Mode:    ProxyProtocolOptional,
+				Trusted: []string{"10.0.0.256"},
+			},
+			wantErr: `invalid proxy_protocol_trusted "10.0.0.256": not an IP address or CIDR`,
+		},
+		{
+			name: "invalid trusted cidr",
+			config: ProxyProtocolConfig{
+				Mode:    ProxyProtocolOptional,
+				Trusted: []string{"10.0.0.0/33"},
+			},
+			wantErr: `invalid proxy_protocol_trusted "10.0.0.0/33": invalid CIDR address: 10.0.0.0/33`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.ParseAndValidate()
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHTTPServerProxyProtocol(t *testing.T) {
+	testCases := []struct {
+		name           string
+		config         ProxyProtocolConfig
+		sendHeader     bool
+		wantRemoteAddr string
+		wantRejected   bool
+	}{
+		{
+			name:           "disabled",
+			config:         ProxyProtocolConfig{Mode: ProxyProtocolOff},
+			wantRemoteAddr: "127.0.0.1",
+		},
+		{
+			name:           "optional with header",
+			config:         ProxyProtocolConfig{Mode: ProxyProtocolOptional, Trusted: []string{"127.0.0.1"}},
+			sendHeader:     true,
+			wantRemoteAddr: "203.0.113.7:4321",
+		},
+		{
+			name:           "optional without header",
+			config:         ProxyProtocolConfig{Mode: ProxyProtocolOptional, Trusted: []string{"127.0.0.1"}},
+			wantRemoteAddr: "127.0.0.1",
+		},
+		{
+			name:         "required without header",
+			config:       ProxyProtocolConfig{Mode: ProxyProtocolRequired, Trusted: []string{"127.0.0.1"}},
+			wantRejected: true,
+		},
+		{
+			name:           "required with header from trusted proxy",
+			config:         ProxyProtocolConfig{Mode: ProxyProtocolRequired, Trusted: []string{"127.0.0.0/8"}},
+			sendHeader:     true,
+			wantRemoteAddr: "203.0.113.7:4321",
+		},
+		{
+			name:         "header from untrusted proxy",
+			config:       ProxyProtocolConfig{Mode: ProxyProtocolOptional, Trusted: []string{"10.0.0.1"}},
+			sendHeader:   true,
+			wantRejected: true,
+		},
+		{
+			name:           "untrusted direct connection",
+			config:         ProxyProtocolConfig{Mode: ProxyProtocolRequired, Trusted: []string{"10.0.0.1"}},
+			wantRemoteAddr: "127.0.0.1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, tc.config.ParseAndValidate())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			s := NewHTTPServer(1024, logger.NewLogger("test", logger.LogOutput{File: os.Stdout}, logger.LogLevelDebug), WithProxyProtocol(&tc.config))
+			err := s.GoListenAndServe(ctx, "127.0.0.1:0", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(r.RemoteAddr))
+			}))
+			require.NoError(t, err)
+			defer s.Close()
+
+			conn, err := net.Dial("tcp", s.listener.Addr().String())
+			require.NoError(t, err)
+			defer conn.Close()
+
+			if tc.sendHeader {
+				_, err = fmt.Fprintf(conn, "PROXY TCP4 203.0.113.7 198.51.100.1 4321 443\r\n")
+				require.NoError(t, err)
+			}
+			_, err = fmt.Fprintf(conn, "GET / HTTP/1.1\r\nHost: localhost\r\nConnection: close\r\n\r\n")
+			require.NoError(t, err)
+
+			resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+			if tc.wantRejected {
+				if err == nil {
+					defer resp.Body.Close()
+					assert.NotEqual(t, http.StatusOK, resp.StatusCode)
+				}
+				return
+			}
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Contains(t, string(body), tc.wantRemoteAddr)
+		})
+	}
+}
+
+func TestProxyProtocolConfigTrustsNoUpstreamByDefault(t *testing.T) {
+	c := ProxyProtocolConfig{Mode: ProxyProtocolOptional}
+
+	policy, err := c.policy(&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 4321})
+	require.NoError(t, err)
+	assert.Equal(t, proxyproto.REJECT, policy)
+}
@@ -45,20 +45,27 @@ func (c *SQLConverter) AddWhere(filterOptions []FilterOption, q string, params [
 
 	whereParts := make([]string, 0, len(filterOptions))
 	for i := range filterOptions {
+		isNE := filterOptions[i].Operator == FilterOperatorTypeNE
 		orParts := make([]string, 0, len(filterOptions[i].Values))
 		for _, col := range filterOptions[i].Column {
 			for _, val := range filterOptions[i].Values {
 				part := fmt.Sprintf("%s %s ?", col, filterOptions[i].Operator.Code())
-				if val == "" {
+				if val == "" && isNE {
+					part = fmt.Sprintf("(%s AND %s IS NOT NULL)", part, col)
+				} else if val == "" {
 					part = fmt.Sprintf("(%s OR %s IS NULL)", part, col)
-				} else if strings.Contains(val, "*") && filterOptions[i].Operator.Code() == "=" {
+				} else if strings.Contains(val, "*") && (filterOptions[i].Operator.Code() == "=" || isNE) {
+					like := "LIKE"
+					if isNE {
+						like = "NOT LIKE"
+					}
 					// Implement a SQL LIKE search triggered by a wildcard
 					if c.dbDriverName == "mysql" {
 						//MySQL needs the backslash escaped, that means double-backslash;  WHERE LOWER(id) LIKE 'op\%' escape "\\";
-						part = fmt.Sprintf("LOWER(%s) LIKE ? ESCAPE '\\\\'", col)
+						part = fmt.Sprintf("LOWER(%s) %s ? ESCAPE '\\\\'", col, like)
 					} else {
 						//SQLite needs a single backslash
-						part = fmt.Sprintf("LOWER(%s) LIKE ? ESCAPE '\\'", col)
+						part = fmt.Sprintf("LOWER(%s) %s ? ESCAPE '\\'", col, like)
 					}
 					// Escape the % sign to treat it literally, on the API side % must not become a wildcard
 					val = strings.Replace(val, "%", "\\%", -1)
@@ -67,13 +74,22 @@ func (c *SQLConverter) AddWhere(filterOptions []FilterOption, q string, params [
 					// Replace wildcard * by sql wildcard %
 					val = strings.ReplaceAll(val, "*", "%")
 				}
+				if val != "" && isNE {
+					// NULL doesn't compare, but it's not equal to any given value
+					part = fmt.Sprintf("(%s OR %s IS NULL)", part, col)
+				}
 				orParts = append(orParts, part)
 				params = append(params, val)
 			}
 		}
 
 		if len(orParts) > 1 {
-			whereParts = append(whereParts, fmt.Sprintf("(%s)", strings.Join(orParts, " OR ")))
+			// a value must differ from all excluded values
+			join := " OR "
+			if isNE {
+				join = " AND "
+			}
+			whereParts = append(whereParts, fmt.Sprintf("(%s)", strings.Join(orParts, join)))
 		} else {
 			whereParts = append(whereParts, orParts[0])
 		}
@@ -122,6 +122,59 @@ func TestConvertListOptionsToQuery(t *testing.T) {
 			ExpectedQuery:  `SELECT * FROM res1 WHERE LOWER(field1) LIKE ? ESCAPE '\\' AND LOWER(field2) LIKE ? ESCAPE '\\' ORDER BY field1 ASC`,
 			ExpectedParams: []interface{}{"val%", "val%"},
 		},
+		{
+			Name: "comparison operators",
+			Options: &query.ListOptions{
+				Filters: []query.FilterOption{
+					{
+						Column:   []string{"field1"},
+						Operator: query.FilterOperatorTypeGT,
+						Values:   []string{"4"},
+					},
+					{
+						Column:   []string{"field2"},
+						Operator: query.FilterOperatorTypeLTE,
+						Values:   []string{"1024"},
+					},
+					{
+						Column:   []string{"field3"},
+						Operator: query.FilterOperatorTypeGTE,
+						Values:   []string{"1.5"},
+					},
+					{
+						Column:   []string{"field4"},
+						Operator: query.FilterOperatorTypeLT,
+						Values:   []string{"10"},
+					},
+				},
+			},
+			ExpectedQuery:  "SELECT * FROM res1 WHERE field1 > ? AND field2 <= ? AND field3 >= ? AND field4 < ?",
+			ExpectedParams: []interface{}{"4", "1024", "1.5", "10"},
+		},
+		{
+			Name: "not equal operator",
+			Options: &query.ListOptions{
+				Filters: []query.FilterOption{
+					{
+						Column:   []string{"field1"},
+						Operator: query.FilterOperatorTypeNE,
+						Values:   []string{"val1", "val2"},
+					},
+					{
+						Column:   []string{"field2"},
+						Operator: query.FilterOperatorTypeNE,
+						Values:   []string{""},
+					},
+					{
+						Column:   []string{"field3"},
+						Operator: query.FilterOperatorTypeNE,
+						Values:   []string{"val*"},
+					},
+				},
+			},
+			ExpectedQuery:  `SELECT * FROM res1 WHERE ((field1 != ? OR field1 IS NULL) AND (field1 != ? OR field1 IS NULL)) AND (field2 != ? AND field2 IS NOT NULL) AND (LOWER(field3) NOT LIKE ? ESCAPE '\' OR field3 IS NULL)`,
+			ExpectedParams: []interface{}{"val1", "val2", "", "val%"},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -4,14 +4,11 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"regexp"
 	"strings"
 
 	errors2 "github.com/realvnc-labs/rport/server/api/errors"
 )
 
-var fieldsRegex = regexp.MustCompile(`^fields\[(\w+)]`)
-
 type FieldsOption struct {
 	Resource string
 	Fields   []string
@@ -58,14 +55,8 @@ func ParseFieldsOptions(values url.Values) []FieldsOption {
 			continue
 		}
 
-		matches := fieldsRegex.FindStringSubmatch(fieldsKey)
-		if matches == nil || len(matches) < 2 {
-			continue
-		}
-
-		fieldsResource := matches[1]
-		fieldsResource = strings.TrimSpace(fieldsResource)
-		if fieldsResource == "" {
+		fieldsResource, _, ok := cutBracket(fieldsKey[len("fields"):], isWordChar)
+		if !ok {
 			continue
 		}
 
@@ -4,16 +4,12 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"regexp"
 	"sort"
 	"strings"
 
 	errors2 "github.com/realvnc-labs/rport/server/api/errors"
 )
 
-var filterRegex = regexp.MustCompile(`^filter\[([\w|*]+)](\[(\w+)])?`)
-var valuesLogicalOpsblock = regexp.MustCompile(`^(and|or){1}\((.+)\)`)
-
 type FilterOperatorType string
 type FilterLogicalOperator string
 
@@ -21,6 +17,9 @@ const (
 	FilterOperatorTypeEQ    FilterOperatorType = "eq"
 	FilterOperatorTypeGT    FilterOperatorType = "gt"
 	FilterOperatorTypeLT    FilterOperatorType = "lt"
+	FilterOperatorTypeGTE   FilterOperatorType = "gte"
+	FilterOperatorTypeLTE   FilterOperatorType = "lte"
+	FilterOperatorTypeNE    FilterOperatorType = "ne"
 	FilterOperatorTypeSince FilterOperatorType = "since"
 	FilterOperatorTypeUntil FilterOperatorType = "until"
 )
@@ -29,14 +28,19 @@ const (
 	FilterLogicalOperatorTypeAND FilterLogicalOperator = "and"
 )
 
+var filterOperatorCodes = map[FilterOperatorType]string{
+	FilterOperatorTypeEQ:    "=",
+	FilterOperatorTypeGT:    ">",
+	FilterOperatorTypeLT:    "<",
+	FilterOperatorTypeGTE:   ">=",
+	FilterOperatorTypeLTE:   "<=",
+	FilterOperatorTypeNE:    "!=",
+	FilterOperatorTypeSince: ">=",
+	FilterOperatorTypeUntil: "<=",
+}
+
 func (fot FilterOperatorType) Code() string {
-	code, ok := map[FilterOperatorType]string{
-		"eq":    "=",
-		"gt":    ">",
-		"lt":    "<",
-		"since": ">=",
-		"until": "<=",
-	}[fot]
+	code, ok := filterOperatorCodes[fot]
 	if !ok {
 		return "="
 	}
@@ -46,7 +50,7 @@ func (fot FilterOperatorType) Code() string {
 type FilterOption struct {
 	Column                []string
 	Operator              FilterOperatorType
-	Values                []string // Values are [ValuesLogicalOperator]ed together (only AND, OR, default OR)
+	Values                []string // Values are [ValuesLogicalOperator]ed together (only AND, OR, default OR), ANDed for FilterOperatorTypeNE
 	ValuesLogicalOperator FilterLogicalOperator
 }
 
@@ -74,6 +78,10 @@ func (fo FilterOption) isSupported(supportedFields map[string]bool) bool {
 func (fo *FilterOption) setWildcardColumns(supportedFields map[string]bool) {
 	fo.Column = make([]string, 0, len(supportedFields))
 	for field := range supportedFields {
+		// fields supported with operators only can't be searched by value
+		if strings.Contains(field, "[") {
+			continue
+		}
 		fo.Column = append(fo.Column, field)
 	}
 }
@@ -113,21 +121,12 @@ func ParseFilterOptions(values url.Values) []FilterOption {
 			continue
 		}
 
-		matches := filterRegex.FindStringSubmatch(filterKey)
-		if matches == nil || len(matches) < 4 {
-			continue
-		}
-
-		filterColumn := matches[1]
-		filterColumn = strings.TrimSpace(filterColumn)
-		if filterColumn == "" {
+		filterColumn, filterOperator, ok := parseFilterKey(filterKey)
+		if !ok {
 			continue
 		}
 		filterColumns := strings.Split(filterColumn, "|")
 
-		filterOperator := matches[3]
-		filterOperator = strings.TrimSpace(filterOperator)
-
 		fo := FilterOption{
 			Column:                filterColumns,
 			Operator:              FilterOperatorType(filterOperator),
@@ -164,3 +163,44 @@ func SplitFilters(options []FilterOption, keys map[string]bool) ([]FilterOption,
 	}
 	return these, other
 }
+
+// parseFilterKey returns the column and the optional operator of a "filter[column][operator]" key.
+// Columns may contain word characters, "|" and "*", operators word characters only.
+func parseFilterKey(key string) (column, operator string, ok bool) {
+	if !strings.HasPrefix(key, "filter") {
+		return "", "", false
+	}
+	column, rest, ok := cutBracket(key[len("filter"):], isFilterColumnChar)
+	if !ok {
+		return "", "", false
+	}
+	operator, _, _ = cutBracket(rest, isWordChar)
+	return column, operator, true
+}
+
+// cutBracket returns the non-empty content of a "[...]" prefix of s if all content chars are valid and the rest of s.
+func cutBracket(s string, valid func(c byte) bool) (content, rest string, ok bool) {
+	if len(s) == 0 || s[0] != '[' {
+		return "", s, false
+	}
+	for i := 1; i < len(s); i++ {
+		if s[i] == ']' {
+			if i == 1 {
+				return "", s, false
+			}
+			return s[1:i], s[i+1:], true
+		}
+		if !valid(s[i]) {
+			return "", s, false
+		}
+	}
+	return "", s, false
+}
+
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_'
+}
+
+func isFilterColumnChar(c byte) bool {
+	return isWordChar(c) || c == '|' || c == '*'
+}
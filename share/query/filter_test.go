@@ -98,7 +98,7 @@ func TestValidateFilterOptions(t *testing.T) {
 					Values: []string{"val1"},
 				},
 			},
-			SupportedFilterFields:       map[string]bool{"field1": true, "field2": true},
+			SupportedFilterFields:       map[string]bool{"field1": true, "field2": true, "field2[gt]": true},
 			ExpectedAPIErrors:           nil,
 			ExpectedFilterOptionColumns: []string{"field1", "field2"},
 		},
@@ -145,6 +145,31 @@ func TestParseFilterOptions(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name: "comparison operators",
+			Query: map[string][]string{
+				"filter[num_cpus][gte]":  {"4"},
+				"filter[mem_total][lte]": {"8589934592"},
+				"filter[os_kernel][ne]":  {"windows"},
+			},
+			ExpectedFilterOptions: []FilterOption{
+				{
+					Column:   []string{"num_cpus"},
+					Operator: FilterOperatorTypeGTE,
+					Values:   []string{"4"},
+				},
+				{
+					Column:   []string{"mem_total"},
+					Operator: FilterOperatorTypeLTE,
+					Values:   []string{"8589934592"},
+				},
+				{
+					Column:   []string{"os_kernel"},
+					Operator: FilterOperatorTypeNE,
+					Values:   []string{"windows"},
+				},
+			},
+		},
 		{
 			Name: "filter fields with sub filter, not known sub filter",
 			Query: map[string][]string{
@@ -357,3 +382,49 @@ func TestSplitFilters(t *testing.T) {
 	assert.Equal(t, options[0:1], opt1)
 	assert.Equal(t, options[1:2], opt2)
 }
+
+func TestParseFilterKey(t *testing.T) {
+	testCases := []struct {
+		Key              string
+		ExpectedColumn   string
+		ExpectedOperator string
+		ExpectedOK       bool
+	}{
+		{Key: "filter[name]", ExpectedColumn: "name", ExpectedOK: true},
+		{Key: "filter[timestamp][gt]", ExpectedColumn: "timestamp", ExpectedOperator: "gt", ExpectedOK: true},
+		{Key: "filter[name|os_full_name]", ExpectedColumn: "name|os_full_name", ExpectedOK: true},
+		{Key: "filter[*]", ExpectedColumn: "*", ExpectedOK: true},
+		{Key: "filter[name][]", ExpectedColumn: "name", ExpectedOK: true},
+		{Key: "filter[name][g-t]", ExpectedColumn: "name", ExpectedOK: true},
+		{Key: "filter[name]trailing", ExpectedColumn: "name", ExpectedOK: true},
+		{Key: "filter[]"},
+		{Key: "filter"},
+		{Key: "filter[name"},
+		{Key: "filter[na-me]"},
+		{Key: "filters[name]"},
+		{Key: "sort"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Key, func(t *testing.T) {
+			column, operator, ok := parseFilterKey(tc.Key)
+
+			assert.Equal(t, tc.ExpectedOK, ok)
+			assert.Equal(t, tc.ExpectedColumn, column)
+			assert.Equal(t, tc.ExpectedOperator, operator)
+		})
+	}
+}
+
+func BenchmarkParseFilterOptions(b *testing.B) {
+	values := map[string][]string{
+		"filter[name|os_full_name]": {"and(*linux*, *ubuntu*)"},
+		"filter[timestamp][gt]":     {"1634303188"},
+		"filter[tags]":              {"Linux,Datacenter 1"},
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ParseFilterOptions(values)
+	}
+}
@@ -21,27 +21,30 @@ func GetListOptions(req *http.Request) *ListOptions {
 func NewOptions(req *http.Request, sortsDefault map[string][]string, filtersDefault map[string][]string, fieldsDefault map[string][]string) *ListOptions {
 	qOptions := &ListOptions{}
 
-	sorts := ParseSortOptions(req.URL.Query())
+	// parse the query once, Query() parses the raw query on each call
+	query := req.URL.Query()
+
+	sorts := ParseSortOptions(query)
 	if len(sorts) > 0 {
 		qOptions.Sorts = sorts
 	} else {
 		qOptions.Sorts = ParseSortOptions(sortsDefault)
 	}
-	filters := ParseFilterOptions(req.URL.Query())
+	filters := ParseFilterOptions(query)
 	if len(filters) > 0 {
 		qOptions.Filters = filters
 	} else {
 		qOptions.Filters = ParseFilterOptions(filtersDefault)
 	}
 
-	fields := ParseFieldsOptions(req.URL.Query())
+	fields := ParseFieldsOptions(query)
 	if len(fields) > 0 {
 		qOptions.Fields = fields
 	} else {
 		qOptions.Fields = ParseFieldsOptions(fieldsDefault)
 	}
 
-	qOptions.Pagination = ParsePagination(req.URL.Query())
+	qOptions.Pagination = ParsePagination(query)
 
 	return qOptions
 }
@@ -101,10 +104,9 @@ func getFilterValues(values []string) ([]string, FilterLogicalOperator) {
 	for i := range values {
 		value := strings.TrimSpace(values[i])
 
-		matchesAndOr := valuesLogicalOpsblock.FindStringSubmatch(value)
-		if matchesAndOr != nil {
-			op = FilterLogicalOperator(matchesAndOr[1])
-			value = matchesAndOr[2]
+		if logicalOp, block, ok := cutLogicalOpsBlock(value); ok {
+			op = logicalOp
+			value = block
 		}
 
 		operands := strings.Split(value, ",")
@@ -115,3 +117,27 @@ func getFilterValues(values []string) ([]string, FilterLogicalOperator) {
 	}
 	return outValues, op
 }
+
+// cutLogicalOpsBlock returns the operator and the content of an "and(...)" or "or(...)" block at the start of value.
+// The block ends with the last closing bracket on the first line, the rest of the line is ignored.
+func cutLogicalOpsBlock(value string) (FilterLogicalOperator, string, bool) {
+	var op FilterLogicalOperator
+	switch {
+	case strings.HasPrefix(value, "and("):
+		op = FilterLogicalOperatorTypeAND
+	case strings.HasPrefix(value, "or("):
+		op = FilterLogicalOperatorTypeOR
+	default:
+		return "", "", false
+	}
+
+	block := value[len(op)+1:]
+	if i := strings.IndexByte(block, '\n'); i >= 0 {
+		block = block[:i]
+	}
+	end := strings.LastIndexByte(block, ')')
+	if end < 1 {
+		return "", "", false
+	}
+	return op, block[:end], true
+}
@@ -257,3 +257,41 @@ func TestValidateListOptionsNoFieldsNoPagination(t *testing.T) {
 	assert.Nil(t, options.Fields)
 	assert.Nil(t, options.Pagination)
 }
+
+func TestCutLogicalOpsBlock(t *testing.T) {
+	testCases := []struct {
+		Value            string
+		ExpectedOperator FilterLogicalOperator
+		ExpectedBlock    string
+		ExpectedOK       bool
+	}{
+		{Value: "and(a,b)", ExpectedOperator: FilterLogicalOperatorTypeAND, ExpectedBlock: "a,b", ExpectedOK: true},
+		{Value: "or(a,b)", ExpectedOperator: FilterLogicalOperatorTypeOR, ExpectedBlock: "a,b", ExpectedOK: true},
+		{Value: "and(a,(b))", ExpectedOperator: FilterLogicalOperatorTypeAND, ExpectedBlock: "a,(b)", ExpectedOK: true},
+		{Value: "and(a)b", ExpectedOperator: FilterLogicalOperatorTypeAND, ExpectedBlock: "a", ExpectedOK: true},
+		{Value: "and()"},
+		{Value: "and(a"},
+		{Value: "xor(a,b)"},
+		{Value: "a,b"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Value, func(t *testing.T) {
+			op, block, ok := cutLogicalOpsBlock(tc.Value)
+
+			assert.Equal(t, tc.ExpectedOK, ok)
+			assert.Equal(t, tc.ExpectedOperator, op)
+			assert.Equal(t, tc.ExpectedBlock, block)
+		})
+	}
+}
+
+func BenchmarkNewOptions(b *testing.B) {
+	req, err := http.NewRequest(http.MethodGet, "/clients?sort=-name&filter[os_kernel]=linux&filter[name|hostname]=or(*web*,*db*)&fields[clients]=id,name,os&page[limit]=50&page[offset]=100", nil)
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		NewOptions(req, nil, nil, nil)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -59,6 +60,12 @@ func matchesFilter(valueMap map[string]interface{}, filter FilterOption) (bool,
 				if matches[filterValue] { // this filter was already "assigned" to a match
 					continue
 				}
+				if filter.Operator.isComparison() {
+					if compares(clientFieldValueToMatchStr, filter.Operator, filterValue) {
+						matches[filterValue] = true
+					}
+					continue
+				}
 				hasUnescapedWildCard := strings.Contains(filterValue, "*")
 				if !hasUnescapedWildCard {
 					if strings.EqualFold(filterValue, clientFieldValueToMatchStr) {
@@ -82,6 +89,10 @@ func matchesFilter(valueMap map[string]interface{}, filter FilterOption) (bool,
 		}
 	}
 
+	if filter.Operator == FilterOperatorTypeNE {
+		// a value must differ from all excluded values
+		return len(matches) == 0, nil
+	}
 	switch filter.ValuesLogicalOperator {
 	case FilterLogicalOperatorTypeAND:
 		return len(matches) == len(filter.Values), nil
@@ -89,6 +100,46 @@ func matchesFilter(valueMap map[string]interface{}, filter FilterOption) (bool,
 	return len(matches) > 0, nil
 }
 
+func (fot FilterOperatorType) isComparison() bool {
+	switch fot {
+	case FilterOperatorTypeGT, FilterOperatorTypeLT, FilterOperatorTypeGTE, FilterOperatorTypeLTE:
+		return true
+	}
+	return false
+}
+
+// compares returns the result of "value <op> filterValue", numbers are compared numerically, other values as strings.
+func compares(value string, op FilterOperatorType, filterValue string) bool {
+	cmp := strings.Compare(value, filterValue)
+	v, errV := strconv.ParseFloat(value, 64)
+	f, errF := strconv.ParseFloat(filterValue, 64)
+	if errV == nil && errF == nil {
+		switch {
+		case v < f:
+			cmp = -1
+		case v > f:
+			cmp = 1
+		default:
+			cmp = 0
+		}
+	} else if value == "" || value == "<nil>" {
+		// missing values don't compare
+		return false
+	}
+
+	switch op {
+	case FilterOperatorTypeGT:
+		return cmp > 0
+	case FilterOperatorTypeLT:
+		return cmp < 0
+	case FilterOperatorTypeGTE:
+		return cmp >= 0
+	case FilterOperatorTypeLTE:
+		return cmp <= 0
+	}
+	return false
+}
+
 func toMap(v interface{}) (map[string]interface{}, error) {
 	bytes, err := json.Marshal(v)
 	if err != nil {
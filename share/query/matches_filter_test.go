@@ -191,3 +191,94 @@ func TestMatchesFiltersUnsupported(t *testing.T) {
 	})
 	assert.EqualError(t, err, "unsupported filter column: other")
 }
+
+func TestMatchesFiltersComparison(t *testing.T) {
+	value := struct {
+		Name     string `json:"name"`
+		NumCPUs  int    `json:"num_cpus"`
+		MemTotal uint64 `json:"mem_total"`
+		Tags     []int  `json:"tags"`
+	}{
+		Name:     "abcde",
+		NumCPUs:  4,
+		MemTotal: 8589934592,
+		Tags:     []int{123, 456},
+	}
+	testCases := []struct {
+		Name           string
+		Filter         query.FilterOption
+		ExpectedResult bool
+	}{
+		{
+			Name:           "gt",
+			Filter:         query.FilterOption{Column: []string{"num_cpus"}, Operator: query.FilterOperatorTypeGT, Values: []string{"2"}},
+			ExpectedResult: true,
+		},
+		{
+			Name:           "gt equal",
+			Filter:         query.FilterOption{Column: []string{"num_cpus"}, Operator: query.FilterOperatorTypeGT, Values: []string{"4"}},
+			ExpectedResult: false,
+		},
+		{
+			Name:           "gte equal",
+			Filter:         query.FilterOption{Column: []string{"num_cpus"}, Operator: query.FilterOperatorTypeGTE, Values: []string{"4"}},
+			ExpectedResult: true,
+		},
+		{
+			Name:           "numeric not lexical",
+			Filter:         query.FilterOption{Column: []string{"num_cpus"}, Operator: query.FilterOperatorTypeLT, Values: []string{"16"}},
+			ExpectedResult: true,
+		},
+		{
+			Name:           "lte large number",
+			Filter:         query.FilterOption{Column: []string{"mem_total"}, Operator: query.FilterOperatorTypeLTE, Values: []string{"8589934592"}},
+			ExpectedResult: true,
+		},
+		{
+			Name:           "lt large number",
+			Filter:         query.FilterOption{Column: []string{"mem_total"}, Operator: query.FilterOperatorTypeLT, Values: []string{"4294967296"}},
+			ExpectedResult: false,
+		},
+		{
+			Name:           "array any element",
+			Filter:         query.FilterOption{Column: []string{"tags"}, Operator: query.FilterOperatorTypeGT, Values: []string{"400"}},
+			ExpectedResult: true,
+		},
+		{
+			Name:           "string",
+			Filter:         query.FilterOption{Column: []string{"name"}, Operator: query.FilterOperatorTypeGT, Values: []string{"abc"}},
+			ExpectedResult: true,
+		},
+		{
+			Name:           "and",
+			Filter:         query.FilterOption{Column: []string{"num_cpus"}, Operator: query.FilterOperatorTypeGT, Values: []string{"2", "8"}, ValuesLogicalOperator: query.FilterLogicalOperatorTypeAND},
+			ExpectedResult: false,
+		},
+		{
+			Name:           "ne",
+			Filter:         query.FilterOption{Column: []string{"num_cpus"}, Operator: query.FilterOperatorTypeNE, Values: []string{"2", "8"}},
+			ExpectedResult: true,
+		},
+		{
+			Name:           "ne matching value",
+			Filter:         query.FilterOption{Column: []string{"num_cpus"}, Operator: query.FilterOperatorTypeNE, Values: []string{"2", "4"}},
+			ExpectedResult: false,
+		},
+		{
+			Name:           "ne wildcard",
+			Filter:         query.FilterOption{Column: []string{"name"}, Operator: query.FilterOperatorTypeNE, Values: []string{"ab*"}},
+			ExpectedResult: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := query.MatchesFilters(value, []query.FilterOption{tc.Filter})
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedResult, result)
+		})
+	}
+}
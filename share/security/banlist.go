@@ -56,8 +56,8 @@ func NewMaxBadAttemptsBanList(maxBadAttempts int, banDuration time.Duration, log
 	}
 }
 
-// AddBadAttempt registers a bad attempt of a visitor.
-func (l *MaxBadAttemptsBanList) AddBadAttempt(visitorKey string) {
+// AddBadAttempt registers a bad attempt of a visitor, it returns true if the visitor got banned by this attempt.
+func (l *MaxBadAttemptsBanList) AddBadAttempt(visitorKey string) (banned bool) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
@@ -76,7 +76,10 @@ func (l *MaxBadAttemptsBanList) AddBadAttempt(visitorKey string) {
 		}
 		v.banTime = &t
 		v.badAttempts = 0
+		return true
 	}
+
+	return false
 }
 
 // AddSuccessAttempt registers a successful attempt of a visitor.
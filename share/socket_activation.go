@@ -0,0 +1,81 @@
+package chshare
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// listenFDsStart is the first file descriptor passed by systemd, see sd_listen_fds(3)
+	listenFDsStart = 3
+
+	ActivatedServerSocket = "server"
+	ActivatedAPISocket    = "api"
+)
+
+var (
+	activationOnce      sync.Once
+	activationListeners map[string]net.Listener
+	activationErr       error
+)
+
+// ActivatedListener returns the listener passed by systemd socket activation with the given FileDescriptorName.
+// A nil listener is returned if the process wasn't started by socket activation or there is no such socket.
+func ActivatedListener(name string) (net.Listener, error) {
+	activationOnce.Do(func() {
+		activationListeners, activationErr = activatedListeners(os.Getenv, os.Getpid(), listenFDsStart)
+		// make sure processes started by the server don't inherit the sockets
+		_ = os.Unsetenv("LISTEN_PID")
+		_ = os.Unsetenv("LISTEN_FDS")
+		_ = os.Unsetenv("LISTEN_FDNAMES")
+	})
+	if activationErr != nil {
+		return nil, activationErr
+	}
+	return activationListeners[name], nil
+}
+
+func activatedListeners(getenv func(string) string, pid int, startFD int) (map[string]net.Listener, error) {
+	listenPID, err := strconv.Atoi(getenv("LISTEN_PID"))
+	if err != nil || listenPID != pid {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, nil
+	}
+	names := strings.Split(getenv("LISTEN_FDNAMES"), ":")
+
+	listeners := make(map[string]net.Listener, n)
+	for i := 0; i < n; i++ {
+		fd := startFD + i
+		name := "unknown"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		f := os.NewFile(uintptr(fd), name)
+		// the listener uses a duplicate of the file descriptor
+		l, err := net.FileListener(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("socket activation: file descriptor %d (%s) is not a listening socket: %w", fd, name, err)
+		}
+		if _, ok := listeners[name]; ok {
+			_ = l.Close()
+			return nil, fmt.Errorf("socket activation: more than one socket named %q, set a unique FileDescriptorName", name)
+		}
+		listeners[name] = l
+	}
+	return listeners, nil
+}
+
+// WithListener makes the server serve on the given listener instead of listening on the address.
+func WithListener(l net.Listener) ServerOption {
+	return func(s *HTTPServer) {
+		s.activatedListener = l
+	}
+}
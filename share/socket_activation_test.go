@@ -0,0 +1,74 @@
+package chshare
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivatedListeners(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	testCases := []struct {
+		name      string
+		env       map[string]string
+		wantNames []string
+		wantErr   string
+	}{
+		{
+			name: "not activated",
+			env:  map[string]string{},
+		},
+		{
+			name: "other process",
+			env:  map[string]string{"LISTEN_PID": "1", "LISTEN_FDS": "1", "LISTEN_FDNAMES": "api"},
+		},
+		{
+			name:      "named socket",
+			env:       map[string]string{"LISTEN_PID": "100", "LISTEN_FDS": "1", "LISTEN_FDNAMES": "api"},
+			wantNames: []string{"api"},
+		},
+		{
+			name:      "unnamed socket",
+			env:       map[string]string{"LISTEN_PID": "100", "LISTEN_FDS": "1"},
+			wantNames: []string{"unknown"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// a duplicate of the listener's file descriptor stands in for the one passed by systemd
+			dup, err := l.(*net.TCPListener).File()
+			require.NoError(t, err)
+			defer dup.Close()
+
+			listeners, err := activatedListeners(func(key string) string { return tc.env[key] }, 100, int(dup.Fd()))
+
+			require.NoError(t, err)
+			names := make([]string, 0, len(listeners))
+			for name, activated := range listeners {
+				names = append(names, name)
+				assert.Equal(t, l.Addr().String(), activated.Addr().String())
+				activated.Close()
+			}
+			assert.ElementsMatch(t, tc.wantNames, names)
+		})
+	}
+
+}
+
+func TestActivatedListenersNoSocket(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "fd")
+	require.NoError(t, err)
+	fd := int(f.Fd())
+	env := map[string]string{"LISTEN_PID": "100", "LISTEN_FDS": "1", "LISTEN_FDNAMES": "server"}
+
+	_, err = activatedListeners(func(key string) string { return env[key] }, 100, fd)
+
+	assert.ErrorContains(t, err, "socket activation: file descriptor "+strconv.Itoa(fd)+" (server) is not a listening socket")
+}
@@ -45,7 +45,11 @@ func decodeStringArray(src reflect.Type, dst reflect.Type, srcVal interface{}) (
 		return srcVal, nil
 	}
 
-	str := srcVal.(string)
+	return parseStringList(srcVal.(string))
+}
+
+// parseStringList parses a comma separated list optionally enclosed in square brackets.
+func parseStringList(str string) ([]string, error) {
 	str = strings.TrimPrefix(str, "[")
 	str = strings.TrimSuffix(str, "]")
 	if str == "" {
@@ -67,7 +71,12 @@ var decoderConfigOptions = []viper.DecoderConfigOption{viper.DecodeHook(decodeHo
 
 // DecodeViperConfig tries to load viper config from either a file or reader and env variables
 // then decodes all values into given cfg variable. cfg must be a pointer.
-func DecodeViperConfig(v *viper.Viper, cfg interface{}, cfgReader io.Reader) error {
+func DecodeViperConfig(v *viper.Viper, cfg interface{}, cfgReader io.Reader, opts ...DecodeOption) error {
+	o := &decodeOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	if cfgReader == nil {
 		if err := v.ReadInConfig(); err != nil {
 			if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
@@ -80,6 +89,12 @@ func DecodeViperConfig(v *viper.Viper, cfg interface{}, cfgReader io.Reader) err
 		}
 	}
 
+	if o.envPrefix != "" {
+		if err := mergeEnvVars(v, cfg, o.envPrefix); err != nil {
+			return fmt.Errorf("error reading env variables: %w", err)
+		}
+	}
+
 	readEnvVars(v)
 
 	if err := v.Unmarshal(cfg, decoderConfigOptions...); err != nil {
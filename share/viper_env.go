@@ -0,0 +1,216 @@
+package chshare
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// EnvFileSuffix is appended to the name of an env var to read the value of the option from the given file instead,
+// e.g. RPORTD_API_JWT_SECRET__FILE=/run/secrets/jwt_secret.
+const EnvFileSuffix = "__FILE"
+
+var envKeyReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+type DecodeOption func(o *decodeOptions)
+
+type decodeOptions struct {
+	envPrefix string
+}
+
+// WithEnvPrefix makes every option of the config configurable by an env var named after the prefix, the section and
+// the key of the option, e.g. RPORTD_API_JWT_SECRET for jwt_secret in [api]. Env vars take precedence over the
+// config file, command line args take precedence over env vars.
+func WithEnvPrefix(prefix string) DecodeOption {
+	return func(o *decodeOptions) {
+		o.envPrefix = prefix
+	}
+}
+
+// EnvVarName returns the name of the env var of a config key like "api.jwt_secret".
+func EnvVarName(prefix, key string) string {
+	return strings.ToUpper(prefix + "_" + envKeyReplacer.Replace(key))
+}
+
+// mergeEnvVars merges the values of env vars into the config layer of viper, so they override the config file
+// but not command line args.
+func mergeEnvVars(v *viper.Viper, cfg interface{}, prefix string) error {
+	values := make(map[string]interface{})
+	for _, key := range configKeys(cfg) {
+		name := EnvVarName(prefix, key.Name)
+		envVal, ok, err := lookupEnvValue(name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		// viper ignores values of a different type than in the config file when merging, so values are converted
+		// to the types used by the config file
+		val, err := convertEnvValue(envVal, key.Type)
+		if err != nil {
+			return fmt.Errorf("invalid value of %s: %w", name, err)
+		}
+
+		// build the nested map of sections viper expects
+		section := values
+		path := strings.Split(key.Name, ".")
+		for _, p := range path[:len(path)-1] {
+			next, ok := section[p].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				section[p] = next
+			}
+			section = next
+		}
+		section[path[len(path)-1]] = val
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+	return v.MergeConfigMap(values)
+}
+
+// lookupEnvValue returns the value of the env var or the content of the file given by the env var with the __FILE
+// suffix. Empty values are ignored.
+func lookupEnvValue(name string) (string, bool, error) {
+	val, ok := os.LookupEnv(name)
+	if ok && val == "" {
+		ok = false
+	}
+
+	filePath, fileOK := os.LookupEnv(name + EnvFileSuffix)
+	if !fileOK || filePath == "" {
+		return val, ok, nil
+	}
+	if ok {
+		return "", false, fmt.Errorf("only one of %s and %s can be set", name, name+EnvFileSuffix)
+	}
+	secret, err := ReadSecretFile(filePath)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s: %w", name+EnvFileSuffix, err)
+	}
+	return secret, true, nil
+}
+
+// ReadSecretFile returns the content of a file holding a secret, e.g. mounted by docker or kubernetes, without the
+// trailing newline.
+func ReadSecretFile(name string) (string, error) {
+	content, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// convertEnvValue converts the value of an env var to the type the option has in a TOML config file.
+func convertEnvValue(val string, t reflect.Type) (interface{}, error) {
+	if t == durationType {
+		return val, nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return strconv.ParseBool(val)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseInt(val, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(val, 64)
+	case reflect.Slice:
+		list, err := parseStringList(val)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]interface{}, 0, len(list))
+		for _, item := range list {
+			values = append(values, item)
+		}
+		return values, nil
+	case reflect.Map:
+		m := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(val), &m); err != nil {
+			return nil, fmt.Errorf("expected a JSON object: %w", err)
+		}
+		return m, nil
+	default:
+		return val, nil
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+type configKey struct {
+	// Name is the full key like "api.jwt_secret".
+	Name string
+	Type reflect.Type
+}
+
+// configKeys returns the keys of all options of a config struct given by their mapstructure tags.
+func configKeys(cfg interface{}) []configKey {
+	var keys []configKey
+	collectConfigKeys(reflect.TypeOf(cfg), "", &keys)
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Name < keys[j].Name
+	})
+	return keys
+}
+
+func collectConfigKeys(t reflect.Type, prefix string, keys *[]configKey) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag, ok := f.Tag.Lookup("mapstructure")
+		if !ok {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if opts == "squash" {
+			collectConfigKeys(ft, prefix, keys)
+			continue
+		}
+		if name == "" {
+			continue
+		}
+		if isConfigSection(ft) {
+			collectConfigKeys(ft, prefix+name+".", keys)
+			continue
+		}
+		*keys = append(*keys, configKey{Name: prefix + name, Type: ft})
+	}
+}
+
+// isConfigSection returns true for structs with options. Other structs like logger.LogOutput are decoded from a
+// single value.
+func isConfigSection(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup("mapstructure"); ok {
+			return true
+		}
+	}
+	return false
+}
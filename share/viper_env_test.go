@@ -0,0 +1,175 @@
+package chshare
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEnvNested struct {
+	Mode string `mapstructure:"mode"`
+}
+
+type testEnvServer struct {
+	Address  string           `mapstructure:"address"`
+	Ports    []string         `mapstructure:"used_ports"`
+	Timeout  time.Duration    `mapstructure:"timeout"`
+	Enabled  bool             `mapstructure:"enabled"`
+	Limits   map[string]int64 `mapstructure:"limits"`
+	internal string
+
+	Nested testEnvNested `mapstructure:",squash"`
+}
+
+type testEnvConfig struct {
+	Server testEnvServer  `mapstructure:"server"`
+	Plugin *testEnvNested `mapstructure:"plus-plugin"`
+	Ignore string         `mapstructure:"-"`
+}
+
+const testEnvConfigFile = `
+[server]
+  address = "0.0.0.0:8080"
+  used_ports = ["20000-30000"]
+  mode = "file"
+  enabled = false
+  limits = { "/commands" = 512 }
+`
+
+func TestConfigKeys(t *testing.T) {
+	stringType := reflect.TypeOf("")
+	assert.Equal(t, []configKey{
+		{Name: "plus-plugin.mode", Type: stringType},
+		{Name: "server.address", Type: stringType},
+		{Name: "server.enabled", Type: reflect.TypeOf(true)},
+		{Name: "server.limits", Type: reflect.TypeOf(map[string]int64{})},
+		{Name: "server.mode", Type: stringType},
+		{Name: "server.timeout", Type: durationType},
+		{Name: "server.used_ports", Type: reflect.TypeOf([]string{})},
+	}, configKeys(&testEnvConfig{}))
+}
+
+func TestDecodeViperConfigWithEnv(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "address")
+	require.NoError(t, os.WriteFile(secretFile, []byte("10.0.0.1:8080\n"), 0600))
+	fileConfig := func(address string) testEnvConfig {
+		return testEnvConfig{
+			Server: testEnvServer{
+				Address: address,
+				Ports:   []string{"20000-30000"},
+				Limits:  map[string]int64{"/commands": 512},
+				Nested:  testEnvNested{Mode: "file"},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		env     map[string]string
+		flag    string
+		want    testEnvConfig
+		wantErr string
+	}{
+		{
+			name: "config file only",
+			want: fileConfig("0.0.0.0:8080"),
+		},
+		{
+			name: "env overrides config file",
+			env: map[string]string{
+				"TEST_SERVER_ADDRESS":    "127.0.0.1:9090",
+				"TEST_SERVER_USED_PORTS": "1000-2000,3000",
+				"TEST_SERVER_TIMEOUT":    "5s",
+				"TEST_SERVER_ENABLED":    "true",
+				"TEST_SERVER_LIMITS":     `{"/scripts": 1024}`,
+				"TEST_SERVER_MODE":       "env",
+				"TEST_PLUS_PLUGIN_MODE":  "plugin",
+			},
+			want: testEnvConfig{
+				Server: testEnvServer{
+					Address: "127.0.0.1:9090",
+					Ports:   []string{"1000-2000", "3000"},
+					Timeout: 5 * time.Second,
+					Enabled: true,
+					Limits:  map[string]int64{"/commands": 512, "/scripts": 1024},
+					Nested:  testEnvNested{Mode: "env"},
+				},
+				Plugin: &testEnvNested{Mode: "plugin"},
+			},
+		},
+		{
+			name: "value from file",
+			env:  map[string]string{"TEST_SERVER_ADDRESS__FILE": secretFile},
+			want: fileConfig("10.0.0.1:8080"),
+		},
+		{
+			name: "flag overrides env",
+			env:  map[string]string{"TEST_SERVER_ADDRESS": "127.0.0.1:9090"},
+			flag: "192.168.0.1:8080",
+			want: fileConfig("192.168.0.1:8080"),
+		},
+		{
+			name: "value and file",
+			env: map[string]string{
+				"TEST_SERVER_ADDRESS":       "127.0.0.1:9090",
+				"TEST_SERVER_ADDRESS__FILE": secretFile,
+			},
+			wantErr: "error reading env variables: only one of TEST_SERVER_ADDRESS and TEST_SERVER_ADDRESS__FILE can be set",
+		},
+		{
+			name:    "missing file",
+			env:     map[string]string{"TEST_SERVER_ADDRESS__FILE": filepath.Join(t.TempDir(), "missing")},
+			wantErr: "error reading env variables: failed to read TEST_SERVER_ADDRESS__FILE",
+		},
+		{
+			name:    "invalid map",
+			env:     map[string]string{"TEST_SERVER_LIMITS": "/scripts=1024"},
+			wantErr: "error reading env variables: invalid value of TEST_SERVER_LIMITS: expected a JSON object",
+		},
+		{
+			name:    "invalid bool",
+			env:     map[string]string{"TEST_SERVER_ENABLED": "maybe"},
+			wantErr: "error reading env variables: invalid value of TEST_SERVER_ENABLED: strconv.ParseBool",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			v := viper.New()
+			v.SetConfigType("toml")
+			if tc.flag != "" {
+				flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+				flags.String("addr", "", "")
+				require.NoError(t, flags.Parse([]string{"--addr", tc.flag}))
+				require.NoError(t, v.BindPFlag("server.address", flags.Lookup("addr")))
+			}
+
+			cfg := testEnvConfig{}
+			err := DecodeViperConfig(v, &cfg, strings.NewReader(testEnvConfigFile), WithEnvPrefix("TEST"))
+
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, cfg)
+		})
+	}
+}
+
+func TestEnvVarName(t *testing.T) {
+	assert.Equal(t, "RPORTD_API_JWT_SECRET", EnvVarName("RPORTD", "api.jwt_secret"))
+	assert.Equal(t, "RPORTD_PLUS_LICENSE_ID", EnvVarName("RPORTD", "plus-license.id"))
+}